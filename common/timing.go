@@ -0,0 +1,49 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package common
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Names of the stages timed with the timing hook.
+const (
+	TimingLoadXrefs     = "core.LoadXrefs"
+	TimingLoadStructure = "model.LoadStructure"
+	TimingLoadPage      = "model.LoadPage"
+	TimingWrite         = "model.Write"
+	TimingParseContent  = "contentstream.Parse"
+	TimingExtractText   = "extractor.ExtractText"
+)
+
+// TimingHook is called with the name of a stage of the processing (Timing constants) and its duration, when the
+// stage ends.  It may be called concurrently.
+type TimingHook func(stage string, elapsed time.Duration)
+
+var timingHook atomic.Value
+
+// SetTimingHook sets the hook called with the durations of the stages of parsing, extraction and writing, e.g. for
+// collecting metrics to detect performance regressions.  Nil removes the hook.
+func SetTimingHook(hook TimingHook) {
+	timingHook.Store(hook)
+}
+
+// StartTiming starts timing the stage and returns the function ending it, which calls the timing hook:
+//
+//	defer common.StartTiming(common.TimingWrite)()
+//
+// Without hook, the stage is not timed.
+func StartTiming(stage string) func() {
+	hook, _ := timingHook.Load().(TimingHook)
+	if hook == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		hook(stage, time.Since(start))
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+)
+
+// Creates an annotation of each type, writes them on a page and checks the subtypes, rectangles and appearance
+// streams of the annotations read back.
+func TestCreateAnnotations(t *testing.T) {
+	quad := [4]draw.Point{{X: 100, Y: 700}, {X: 200, Y: 700}, {X: 200, Y: 712}, {X: 100, Y: 712}}
+	annotations := []*pdf.PdfAnnotation{}
+	add := func(annot *pdf.PdfAnnotation, err error) {
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		annotations = append(annotations, annot)
+	}
+	for _, typ := range []TextMarkupType{TextMarkupHighlight, TextMarkupUnderline, TextMarkupStrikeOut,
+		TextMarkupSquiggly} {
+		add(CreateTextMarkupAnnotation(TextMarkupAnnotationDef{Type: typ, Quads: [][4]draw.Point{quad},
+			Opacity: 1, Author: "Reviewer"}))
+	}
+	add(CreateFreeTextAnnotation(FreeTextAnnotationDef{X: 100, Y: 500, Width: 150, Height: 60,
+		Text: "A comment wrapped over several lines", BorderEnabled: true, BorderWidth: 1, Opacity: 1}))
+	add(CreateInkAnnotation(InkAnnotationDef{Paths: [][]draw.Point{{{X: 10, Y: 10}, {X: 20, Y: 30}},
+		{{X: 40, Y: 40}}}, LineWidth: 2, Opacity: 0.5}))
+	black := pdf.NewPdfColorDeviceRGB(0, 0, 0)
+	add(CreateLineAnnotation(LineAnnotationDef{X1: 10, Y1: 100, X2: 110, Y2: 100, LineColor: black, LineWidth: 1,
+		Opacity: 1}))
+	add(CreateRectangleAnnotation(RectangleAnnotationDef{X: 300, Y: 300, Width: 50, Height: 50, BorderEnabled: true,
+		BorderWidth: 1, BorderColor: black, Opacity: 1}))
+	add(CreateCircleAnnotation(CircleAnnotationDef{X: 400, Y: 300, Width: 50, Height: 50, BorderEnabled: true,
+		BorderWidth: 1, BorderColor: black, Opacity: 1}))
+	add(CreatePolygonAnnotation(PolygonAnnotationDef{Vertices: []draw.Point{{X: 10, Y: 200}, {X: 60, Y: 200},
+		{X: 35, Y: 250}}, Closed: true, FillEnabled: true, Opacity: 1}))
+	add(CreatePolygonAnnotation(PolygonAnnotationDef{Vertices: []draw.Point{{X: 100, Y: 200}, {X: 150, Y: 250}},
+		Opacity: 1}))
+	add(CreateStampAnnotation(StampAnnotationDef{X: 300, Y: 600, Width: 150, Height: 40, Name: "NotApproved",
+		Opacity: 1}))
+	add(CreateFileAttachmentAnnotation(FileAttachmentAnnotationDef{X: 500, Y: 700,
+		File: &pdf.EmbeddedFile{Name: "notes.txt", Data: []byte("Notes")}}))
+	add(CreatePopupAnnotation(annotations[0], PopupAnnotationDef{X: 300, Y: 700, Width: 200, Height: 100}))
+
+	page := pdf.NewPdfPage()
+	page.MediaBox = &pdf.PdfRectangle{Urx: 612, Ury: 792}
+	page.Resources = pdf.NewPdfPageResources()
+	page.Annotations = annotations
+	w := pdf.NewPdfWriter()
+	err := w.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	err = w.Write(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := pdf.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err = reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := []string{"Highlight", "Underline", "StrikeOut", "Squiggly", "FreeText", "Ink", "Line", "Square",
+		"Circle", "Polygon", "PolyLine", "Stamp", "FileAttachment", "Popup"}
+	if len(page.Annotations) != len(expected) {
+		t.Fatalf("Wrong number of annotations %d", len(page.Annotations))
+	}
+	for i, annot := range page.Annotations {
+		dict := pdfcore.TraceToDirectObject(annot.GetContainingPdfObject()).(*pdfcore.PdfObjectDictionary)
+		if subtype, _ := dict.Get("Subtype").(*pdfcore.PdfObjectName); subtype == nil ||
+			string(*subtype) != expected[i] {
+			t.Errorf("Wrong subtype of annotation %d: %v", i, dict.Get("Subtype"))
+			continue
+		}
+		rect, ok := pdfcore.TraceToDirectObject(annot.Rect).(*pdfcore.PdfObjectArray)
+		if !ok {
+			t.Errorf("Missing rectangle of %s", expected[i])
+		} else if _, err := pdf.NewPdfRectangle(*rect); err != nil {
+			t.Errorf("Invalid rectangle of %s: %v", expected[i], err)
+		}
+		if expected[i] == "Popup" {
+			continue
+		}
+		ap, ok := pdfcore.TraceToDirectObject(annot.AP).(*pdfcore.PdfObjectDictionary)
+		if !ok {
+			t.Errorf("Missing appearance of %s", expected[i])
+			continue
+		}
+		if _, ok := pdfcore.TraceToDirectObject(ap.Get("N")).(*pdfcore.PdfObjectStream); !ok {
+			t.Errorf("Missing normal appearance stream of %s", expected[i])
+		}
+	}
+
+	// The highlight with its popup.
+	markup := page.Annotations[0].GetMarkup()
+	if markup == nil || markup.Popup == nil {
+		t.Fatalf("Missing popup of the highlight")
+	}
+	if author, _ := markup.T.(*pdfcore.PdfObjectString); author == nil || string(*author) != "Reviewer" {
+		t.Errorf("Wrong author %v", markup.T)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"math"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// The appearance streams of the annotations below are drawn in page coordinates, with the bounding box of the form
+// XObject equal to the annotation rectangle, so that the form is displayed without transformation.
+
+// Name of the Helvetica font resource of the appearance streams showing text, also used in the default
+// appearance strings (DA) of free text annotations.
+const helveticaName = "Helv"
+
+// Returns the color, or the default color if nil.
+func colorOrDefault(color, defaultColor *pdf.PdfColorDeviceRGB) *pdf.PdfColorDeviceRGB {
+	if color == nil {
+		return defaultColor
+	}
+	return color
+}
+
+// Returns the color array of an RGB color, for the C and IC entries of annotations.
+func rgbArray(color *pdf.PdfColorDeviceRGB) *pdfcore.PdfObjectArray {
+	return pdfcore.MakeArrayFromFloats([]float64{color.R(), color.G(), color.B()})
+}
+
+// Sets the text and the author of a markup annotation, if not empty.
+func setMarkupInfo(annot *pdf.PdfAnnotation, author, contents string) {
+	if contents != "" {
+		annot.Contents = pdfcore.MakeTextString(contents)
+	}
+	if markup := annot.GetMarkup(); markup != nil && author != "" {
+		markup.T = pdfcore.MakeTextString(author)
+	}
+}
+
+// Adds a graphics state with the opacity (Alpha value 0-1) and the blend mode (Normal if empty) to the resources of
+// an appearance stream, and returns its name, empty if not needed.
+func addGraphicsState(resources *pdf.PdfPageResources, opacity float64, blendMode string) (pdfcore.PdfObjectName,
+	error) {
+	if opacity >= 1.0 && blendMode == "" {
+		return "", nil
+	}
+	gsState := pdfcore.MakeDict()
+	if opacity < 1.0 {
+		gsState.Set("ca", pdfcore.MakeFloat(opacity))
+		gsState.Set("CA", pdfcore.MakeFloat(opacity))
+	}
+	if blendMode != "" {
+		gsState.Set("BM", pdfcore.MakeName(blendMode))
+	}
+	err := resources.AddExtGState("gs1", gsState)
+	if err != nil {
+		common.Log.Debug("Unable to add extgstate gs1")
+		return "", err
+	}
+	return "gs1", nil
+}
+
+// Returns the appearance dictionary with the normal appearance (N) drawing the content in page coordinates, with the
+// bounding box of the annotation rectangle.
+func makeAppearance(content []byte, bbox *pdf.PdfRectangle, resources *pdf.PdfPageResources) (
+	*pdfcore.PdfObjectDictionary, error) {
+	form := pdf.NewXObjectForm()
+	form.Resources = resources
+	err := form.SetContentStream(content, nil)
+	if err != nil {
+		return nil, err
+	}
+	form.BBox = bbox.ToPdfObject()
+
+	apDict := pdfcore.MakeDict()
+	apDict.Set("N", form.ToPdfObject())
+	return apDict, nil
+}
+
+// Returns the bounding box of the points enlarged by margin on each side.
+func pointsBBox(points []draw.Point, margin float64) *pdf.PdfRectangle {
+	bbox := &pdf.PdfRectangle{Llx: math.Inf(1), Lly: math.Inf(1), Urx: math.Inf(-1), Ury: math.Inf(-1)}
+	for _, p := range points {
+		bbox.Llx = math.Min(bbox.Llx, p.X)
+		bbox.Lly = math.Min(bbox.Lly, p.Y)
+		bbox.Urx = math.Max(bbox.Urx, p.X)
+		bbox.Ury = math.Max(bbox.Ury, p.Y)
+	}
+	bbox.Llx -= margin
+	bbox.Lly -= margin
+	bbox.Urx += margin
+	bbox.Ury += margin
+	return bbox
+}
+
+// Returns the array of the coordinates of the points (x1 y1 x2 y2 ...).
+func pointsArray(points []draw.Point) *pdfcore.PdfObjectArray {
+	values := []float64{}
+	for _, p := range points {
+		values = append(values, p.X, p.Y)
+	}
+	return pdfcore.MakeArrayFromFloats(values)
+}
+
+// Returns the width of the text in the standard font, in thousandths of the font size.
+func textWidth(font fonts.Font, text string) float64 {
+	encoder := textencoding.NewWinAnsiTextEncoder()
+	width := 0.0
+	for _, r := range text {
+		glyph, found := encoder.RuneToGlyph(r)
+		if !found {
+			continue
+		}
+		if metrics, found := font.GetGlyphCharMetrics(glyph); found {
+			width += metrics.Wx
+		}
+	}
+	return width
+}
+
+// Returns the lines of the text, wrapped at spaces to the width (thousandths of the font size) in Helvetica.
+func wrapText(text string, width float64) []string {
+	helvetica := fonts.NewFontHelvetica()
+	lines := []string{}
+	for _, paragraph := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			if line != "" && textWidth(helvetica, line+" "+word) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Returns the text encoded for showing with the standard fonts of the appearance streams (WinAnsiEncoding).
+func encodeText(text string) pdfcore.PdfObjectString {
+	return pdfcore.PdfObjectString(textencoding.NewWinAnsiTextEncoder().Encode(text))
+}
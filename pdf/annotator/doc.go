@@ -7,4 +7,8 @@
 // streams.  It goes beyond the models package which includes definitions of basic annotation models, in that it
 // can create the appearance streams which specify the exact appearance as needed by many pdf viewers for consistent
 // appearance of the annotations.
+//
+// Annotations are defined by the XxxAnnotationDef types and created by the corresponding CreateXxxAnnotation
+// functions: text markup (highlight, underline, strikeout and squiggly), free text, ink, line, rectangle, circle,
+// polygon and polyline, stamp, popup and file attachment annotations.
 package annotator
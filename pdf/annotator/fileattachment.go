@@ -0,0 +1,92 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcontent "github.com/unidoc/unidoc/pdf/contentstream"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+)
+
+// Defines a file attachment annotation: a file embedded in the annotation, shown as an icon in a box with a lower
+// left corner at (X,Y) (20x20 by default).  The Icon is the name of the icon for viewers with their own icons,
+// PushPin (default), Paperclip, Graph or Tag.  The appearance stream shows a page with a folded corner, blue by
+// default.
+type FileAttachmentAnnotationDef struct {
+	X        float64
+	Y        float64
+	Width    float64
+	Height   float64
+	File     *pdf.EmbeddedFile
+	Icon     string
+	Color    *pdf.PdfColorDeviceRGB
+	Author   string
+	Contents string
+}
+
+// Creates a file attachment annotation object with appearance stream that can be added to page PDF annotations.
+func CreateFileAttachmentAnnotation(fileDef FileAttachmentAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if fileDef.File == nil {
+		common.Log.Debug("ERROR: File attachment annotation without file")
+		return nil, errors.New("Missing file")
+	}
+	fs, err := fileDef.File.ToFileSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	fileAnnotation := pdf.NewPdfAnnotationFileAttachment()
+	fileAnnotation.FS = fs
+	if fileDef.Icon == "" {
+		fileDef.Icon = "PushPin"
+	}
+	fileAnnotation.Name = pdfcore.MakeName(fileDef.Icon)
+	if fileDef.Width <= 0 || fileDef.Height <= 0 {
+		fileDef.Width, fileDef.Height = 20, 20
+	}
+	color := colorOrDefault(fileDef.Color, pdf.NewPdfColorDeviceRGB(0, 0.3, 0.8))
+	fileAnnotation.C = rgbArray(color)
+	if fileDef.Contents == "" {
+		fileDef.Contents = fileDef.File.Name
+	}
+	setMarkupInfo(fileAnnotation.PdfAnnotation, fileDef.Author, fileDef.Contents)
+
+	// Make the appearance stream (for uniform appearance).
+	apDict, bbox, err := makeFileAttachmentAnnotationAppearanceStream(fileDef, color)
+	if err != nil {
+		return nil, err
+	}
+	fileAnnotation.AP = apDict
+	fileAnnotation.Rect = bbox.ToPdfObject()
+
+	return fileAnnotation.PdfAnnotation, nil
+}
+
+func makeFileAttachmentAnnotationAppearanceStream(fileDef FileAttachmentAnnotationDef,
+	color *pdf.PdfColorDeviceRGB) (*pdfcore.PdfObjectDictionary, *pdf.PdfRectangle, error) {
+	bbox := &pdf.PdfRectangle{Llx: fileDef.X, Lly: fileDef.Y, Urx: fileDef.X + fileDef.Width,
+		Ury: fileDef.Y + fileDef.Height}
+
+	// A page with a folded upper right corner, filled white, in the middle 3/4 of the box.
+	w, h := 0.6*fileDef.Width, 0.75*fileDef.Height
+	x, y := bbox.Llx+(fileDef.Width-w)/2, bbox.Lly+(fileDef.Height-h)/2
+	fold := w / 3
+	creator := pdfcontent.NewContentCreator()
+	creator.Add_q()
+	creator.Add_rg(1, 1, 1).Add_RG(color.R(), color.G(), color.B()).Add_w(fileDef.Height / 20).Add_j("1")
+	creator.Add_m(x, y).Add_l(x+w, y).Add_l(x+w, y+h-fold).Add_l(x+w-fold, y+h).Add_l(x, y+h).Add_h().Add_B()
+	creator.Add_m(x+w-fold, y+h).Add_l(x+w-fold, y+h-fold).Add_l(x+w, y+h-fold).Add_S()
+	creator.Add_Q()
+
+	apDict, err := makeAppearance(creator.Bytes(), bbox, pdf.NewPdfPageResources())
+	if err != nil {
+		return nil, nil, err
+	}
+	return apDict, bbox, nil
+}
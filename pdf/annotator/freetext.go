@@ -0,0 +1,146 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcontent "github.com/unidoc/unidoc/pdf/contentstream"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// Defines a free text annotation: text displayed directly on the page in a box with a lower left corner at (X,Y),
+// wrapped to its width, with optional border and filling color.  The text is in Helvetica, black and 12 points by
+// default.
+type FreeTextAnnotationDef struct {
+	X             float64
+	Y             float64
+	Width         float64
+	Height        float64
+	Text          string
+	FontSize      float64
+	TextColor     *pdf.PdfColorDeviceRGB
+	FillEnabled   bool // Show fill?
+	FillColor     *pdf.PdfColorDeviceRGB
+	BorderEnabled bool // Show border?
+	BorderWidth   float64
+	BorderColor   *pdf.PdfColorDeviceRGB
+	Opacity       float64 // Alpha value (0-1).
+	Author        string
+}
+
+// Creates a free text annotation object with appearance stream that can be added to page PDF annotations.
+func CreateFreeTextAnnotation(textDef FreeTextAnnotationDef) (*pdf.PdfAnnotation, error) {
+	textAnnotation := pdf.NewPdfAnnotationFreeText()
+
+	if textDef.FontSize <= 0 {
+		textDef.FontSize = 12
+	}
+	black := pdf.NewPdfColorDeviceRGB(0, 0, 0)
+	textColor := colorOrDefault(textDef.TextColor, black)
+
+	// Default appearance, for viewers regenerating the appearance when the text is edited.
+	textAnnotation.DA = pdfcore.MakeString(fmt.Sprintf("/%s %g Tf %g %g %g rg", helveticaName, textDef.FontSize,
+		textColor.R(), textColor.G(), textColor.B()))
+	if textDef.FillEnabled {
+		textAnnotation.C = rgbArray(colorOrDefault(textDef.FillColor, pdf.NewPdfColorDeviceRGB(1, 1, 1)))
+	}
+	bs := pdf.NewBorderStyle()
+	if textDef.BorderEnabled {
+		bs.SetBorderWidth(textDef.BorderWidth)
+	} else {
+		bs.SetBorderWidth(0)
+	}
+	textAnnotation.BS = bs.ToPdfObject()
+
+	if textDef.Opacity < 1.0 {
+		textAnnotation.CA = pdfcore.MakeFloat(textDef.Opacity)
+	}
+	setMarkupInfo(textAnnotation.PdfAnnotation, textDef.Author, textDef.Text)
+
+	// Make the appearance stream (for uniform appearance).
+	apDict, bbox, err := makeFreeTextAnnotationAppearanceStream(textDef, textColor)
+	if err != nil {
+		return nil, err
+	}
+	textAnnotation.AP = apDict
+	textAnnotation.Rect = bbox.ToPdfObject()
+
+	return textAnnotation.PdfAnnotation, nil
+}
+
+func makeFreeTextAnnotationAppearanceStream(textDef FreeTextAnnotationDef, textColor *pdf.PdfColorDeviceRGB) (
+	*pdfcore.PdfObjectDictionary, *pdf.PdfRectangle, error) {
+	resources := pdf.NewPdfPageResources()
+	gsName, err := addGraphicsState(resources, textDef.Opacity, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	err = resources.SetFontByName(helveticaName, fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		common.Log.Debug("Unable to add font %s", helveticaName)
+		return nil, nil, err
+	}
+
+	bbox := &pdf.PdfRectangle{Llx: textDef.X, Lly: textDef.Y, Urx: textDef.X + textDef.Width,
+		Ury: textDef.Y + textDef.Height}
+	creator := pdfcontent.NewContentCreator()
+	creator.Add_q()
+	if gsName != "" {
+		creator.Add_gs(gsName)
+	}
+
+	// The border is within the box.
+	border := 0.0
+	if textDef.BorderEnabled {
+		border = textDef.BorderWidth
+	}
+	if textDef.FillEnabled || border > 0 {
+		creator.Add_re(bbox.Llx+border/2, bbox.Lly+border/2, textDef.Width-border, textDef.Height-border)
+		if textDef.FillEnabled {
+			fill := colorOrDefault(textDef.FillColor, pdf.NewPdfColorDeviceRGB(1, 1, 1))
+			creator.Add_rg(fill.R(), fill.G(), fill.B())
+		}
+		if border > 0 {
+			stroke := colorOrDefault(textDef.BorderColor, pdf.NewPdfColorDeviceRGB(0, 0, 0))
+			creator.Add_RG(stroke.R(), stroke.G(), stroke.B()).Add_w(border)
+		}
+		switch {
+		case textDef.FillEnabled && border > 0:
+			creator.Add_B()
+		case textDef.FillEnabled:
+			creator.Add_f()
+		default:
+			creator.Add_S()
+		}
+	}
+
+	// Lines from the top, clipped to the inside of the border.
+	padding := border + 2
+	fontSize := textDef.FontSize
+	leading := 1.15 * fontSize
+	lines := wrapText(textDef.Text, (textDef.Width-2*padding)*1000/fontSize)
+	creator.Add_re(bbox.Llx+border, bbox.Lly+border, textDef.Width-2*border, textDef.Height-2*border).Add_W().Add_n()
+	creator.Add_BT().Add_Tf(helveticaName, fontSize).Add_TL(leading)
+	creator.Add_rg(textColor.R(), textColor.G(), textColor.B())
+	creator.Add_Td(bbox.Llx+padding, bbox.Ury-padding-0.8*fontSize)
+	for i, line := range lines {
+		if i > 0 {
+			creator.Add_Tstar()
+		}
+		creator.Add_Tj(encodeText(line))
+	}
+	creator.Add_ET().Add_Q()
+
+	apDict, err := makeAppearance(creator.Bytes(), bbox, resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return apDict, bbox, nil
+}
@@ -0,0 +1,99 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcontent "github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+)
+
+// Defines an ink annotation: freehand strokes, each a path through a list of points, with a specified line width
+// (1 by default), color (black by default) and opacity.
+type InkAnnotationDef struct {
+	Paths     [][]draw.Point
+	LineColor *pdf.PdfColorDeviceRGB
+	LineWidth float64
+	Opacity   float64 // Alpha value (0-1).
+	Author    string
+	Contents  string
+}
+
+// Creates an ink annotation object with appearance stream that can be added to page PDF annotations.
+func CreateInkAnnotation(inkDef InkAnnotationDef) (*pdf.PdfAnnotation, error) {
+	points := []draw.Point{}
+	inkList := pdfcore.MakeArray()
+	for _, path := range inkDef.Paths {
+		points = append(points, path...)
+		inkList.Append(pointsArray(path))
+	}
+	if len(points) == 0 {
+		common.Log.Debug("ERROR: Ink annotation without points")
+		return nil, errors.New("Missing points")
+	}
+	if inkDef.LineWidth <= 0 {
+		inkDef.LineWidth = 1
+	}
+
+	inkAnnotation := pdf.NewPdfAnnotationInk()
+	inkAnnotation.InkList = inkList
+
+	color := colorOrDefault(inkDef.LineColor, pdf.NewPdfColorDeviceRGB(0, 0, 0))
+	inkAnnotation.C = rgbArray(color)
+	bs := pdf.NewBorderStyle()
+	bs.SetBorderWidth(inkDef.LineWidth)
+	inkAnnotation.BS = bs.ToPdfObject()
+	if inkDef.Opacity < 1.0 {
+		inkAnnotation.CA = pdfcore.MakeFloat(inkDef.Opacity)
+	}
+	setMarkupInfo(inkAnnotation.PdfAnnotation, inkDef.Author, inkDef.Contents)
+
+	// Make the appearance stream (for uniform appearance).
+	resources := pdf.NewPdfPageResources()
+	gsName, err := addGraphicsState(resources, inkDef.Opacity, "")
+	if err != nil {
+		return nil, err
+	}
+	creator := pdfcontent.NewContentCreator()
+	creator.Add_q()
+	if gsName != "" {
+		creator.Add_gs(gsName)
+	}
+	// Round caps and joins, as drawn with a pen.
+	creator.Add_RG(color.R(), color.G(), color.B()).Add_w(inkDef.LineWidth).Add_J("1").Add_j("1")
+	for _, path := range inkDef.Paths {
+		drawPolyline(creator, path)
+	}
+	creator.Add_S().Add_Q()
+
+	bbox := pointsBBox(points, inkDef.LineWidth/2)
+	inkAnnotation.AP, err = makeAppearance(creator.Bytes(), bbox, resources)
+	if err != nil {
+		return nil, err
+	}
+	inkAnnotation.Rect = bbox.ToPdfObject()
+
+	return inkAnnotation.PdfAnnotation, nil
+}
+
+// Adds the subpath through the points to the path under construction.  A single point is drawn as a dot with the
+// round caps.
+func drawPolyline(creator *pdfcontent.ContentCreator, points []draw.Point) {
+	for i, p := range points {
+		if i == 0 {
+			creator.Add_m(p.X, p.Y)
+		} else {
+			creator.Add_l(p.X, p.Y)
+		}
+	}
+	if len(points) == 1 {
+		creator.Add_l(points[0].X, points[0].Y)
+	}
+}
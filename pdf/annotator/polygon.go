@@ -0,0 +1,101 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcontent "github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+)
+
+// Defines a polygon through the vertices, closed and optionally filled, or a polyline (open polygon) if not
+// Closed.  The lines have a specified width (1 by default), color (black by default) and opacity.
+type PolygonAnnotationDef struct {
+	Vertices    []draw.Point
+	Closed      bool
+	LineColor   *pdf.PdfColorDeviceRGB
+	LineWidth   float64
+	FillEnabled bool // Show fill? (closed polygons only)
+	FillColor   *pdf.PdfColorDeviceRGB
+	Opacity     float64 // Alpha value (0-1).
+	Author      string
+	Contents    string
+}
+
+// Creates a polygon or polyline annotation object with appearance stream that can be added to page PDF
+// annotations.
+func CreatePolygonAnnotation(polyDef PolygonAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if len(polyDef.Vertices) < 2 {
+		common.Log.Debug("ERROR: Polygon annotation with %d vertices", len(polyDef.Vertices))
+		return nil, errors.New("Not enough vertices")
+	}
+	if polyDef.LineWidth <= 0 {
+		polyDef.LineWidth = 1
+	}
+	lineColor := colorOrDefault(polyDef.LineColor, pdf.NewPdfColorDeviceRGB(0, 0, 0))
+	fill := polyDef.Closed && polyDef.FillEnabled
+	fillColor := colorOrDefault(polyDef.FillColor, pdf.NewPdfColorDeviceRGB(1, 1, 1))
+
+	bs := pdf.NewBorderStyle()
+	bs.SetBorderWidth(polyDef.LineWidth)
+	var annot *pdf.PdfAnnotation
+	if polyDef.Closed {
+		polygon := pdf.NewPdfAnnotationPolygon()
+		polygon.Vertices = pointsArray(polyDef.Vertices)
+		polygon.BS = bs.ToPdfObject()
+		if fill {
+			polygon.IC = rgbArray(fillColor)
+		}
+		annot = polygon.PdfAnnotation
+	} else {
+		polyline := pdf.NewPdfAnnotationPolyLine()
+		polyline.Vertices = pointsArray(polyDef.Vertices)
+		polyline.BS = bs.ToPdfObject()
+		annot = polyline.PdfAnnotation
+	}
+	annot.C = rgbArray(lineColor)
+	if polyDef.Opacity < 1.0 {
+		annot.GetMarkup().CA = pdfcore.MakeFloat(polyDef.Opacity)
+	}
+	setMarkupInfo(annot, polyDef.Author, polyDef.Contents)
+
+	// Make the appearance stream (for uniform appearance).
+	resources := pdf.NewPdfPageResources()
+	gsName, err := addGraphicsState(resources, polyDef.Opacity, "")
+	if err != nil {
+		return nil, err
+	}
+	creator := pdfcontent.NewContentCreator()
+	creator.Add_q()
+	if gsName != "" {
+		creator.Add_gs(gsName)
+	}
+	creator.Add_RG(lineColor.R(), lineColor.G(), lineColor.B()).Add_w(polyDef.LineWidth)
+	drawPolyline(creator, polyDef.Vertices)
+	switch {
+	case fill:
+		creator.Add_rg(fillColor.R(), fillColor.G(), fillColor.B()).Add_b()
+	case polyDef.Closed:
+		creator.Add_s()
+	default:
+		creator.Add_S()
+	}
+	creator.Add_Q()
+
+	// Enlarged for the miter joins of sharp angles.
+	bbox := pointsBBox(polyDef.Vertices, 5*polyDef.LineWidth)
+	annot.AP, err = makeAppearance(creator.Bytes(), bbox, resources)
+	if err != nil {
+		return nil, err
+	}
+	annot.Rect = bbox.ToPdfObject()
+
+	return annot, nil
+}
@@ -0,0 +1,45 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+)
+
+// Defines the pop-up window of a markup annotation, displaying its text, with a lower left corner at (X,Y) and
+// initially open or closed.
+type PopupAnnotationDef struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	Open   bool
+}
+
+// Creates a popup annotation for the parent markup annotation, and sets it as the popup of the parent.  Both
+// annotations must be added to the page PDF annotations.  Viewers draw the popup window themselves, so no
+// appearance stream is needed.
+func CreatePopupAnnotation(parent *pdf.PdfAnnotation, popupDef PopupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	markup := parent.GetMarkup()
+	if markup == nil {
+		common.Log.Debug("ERROR: Popup for an annotation without markup (%T)", parent.GetContext())
+		return nil, errors.New("Not a markup annotation")
+	}
+
+	popupAnnotation := pdf.NewPdfAnnotationPopup()
+	popupAnnotation.Parent = parent.GetContainingPdfObject()
+	popupAnnotation.Open = pdfcore.MakeBool(popupDef.Open)
+	rect := pdf.PdfRectangle{Llx: popupDef.X, Lly: popupDef.Y, Urx: popupDef.X + popupDef.Width,
+		Ury: popupDef.Y + popupDef.Height}
+	popupAnnotation.Rect = rect.ToPdfObject()
+	markup.Popup = popupAnnotation
+
+	return popupAnnotation.PdfAnnotation, nil
+}
@@ -0,0 +1,133 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcontent "github.com/unidoc/unidoc/pdf/contentstream"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// Defines a rubber stamp annotation in a box with a lower left corner at (X,Y): the text in a frame, in red by
+// default.  The Name is the icon of the stamp, one of the standard names Approved, Experimental, NotApproved, AsIs,
+// Expired, NotForPublicRelease, Confidential, Final, Sold, Departmental, ForComment, TopSecret, Draft (default) and
+// ForPublicRelease, or a custom name.  The text defaults to the name in capitals, e.g. "NOT APPROVED".
+type StampAnnotationDef struct {
+	X        float64
+	Y        float64
+	Width    float64
+	Height   float64
+	Name     string
+	Text     string
+	Color    *pdf.PdfColorDeviceRGB
+	Opacity  float64 // Alpha value (0-1).
+	Author   string
+	Contents string
+}
+
+// Name of the Helvetica-Bold font resource of the appearance streams of stamps.
+const stampFontName = "HeBo"
+
+// Creates a rubber stamp annotation object with appearance stream that can be added to page PDF annotations.
+func CreateStampAnnotation(stampDef StampAnnotationDef) (*pdf.PdfAnnotation, error) {
+	stampAnnotation := pdf.NewPdfAnnotationStamp()
+
+	if stampDef.Name == "" {
+		stampDef.Name = "Draft"
+	}
+	if stampDef.Text == "" {
+		stampDef.Text = stampText(stampDef.Name)
+	}
+	stampAnnotation.Name = pdfcore.MakeName(stampDef.Name)
+
+	color := colorOrDefault(stampDef.Color, pdf.NewPdfColorDeviceRGB(0.8, 0, 0))
+	stampAnnotation.C = rgbArray(color)
+	if stampDef.Opacity < 1.0 {
+		stampAnnotation.CA = pdfcore.MakeFloat(stampDef.Opacity)
+	}
+	setMarkupInfo(stampAnnotation.PdfAnnotation, stampDef.Author, stampDef.Contents)
+
+	// Make the appearance stream (for uniform appearance).
+	apDict, bbox, err := makeStampAnnotationAppearanceStream(stampDef, color)
+	if err != nil {
+		return nil, err
+	}
+	stampAnnotation.AP = apDict
+	stampAnnotation.Rect = bbox.ToPdfObject()
+
+	return stampAnnotation.PdfAnnotation, nil
+}
+
+// Returns the text of a stamp name: the words of the name in capitals, e.g. "NOT APPROVED" for NotApproved.
+func stampText(name string) string {
+	var text []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			text = append(text, ' ')
+		}
+		text = append(text, r)
+	}
+	return strings.ToUpper(string(text))
+}
+
+func makeStampAnnotationAppearanceStream(stampDef StampAnnotationDef, color *pdf.PdfColorDeviceRGB) (
+	*pdfcore.PdfObjectDictionary, *pdf.PdfRectangle, error) {
+	resources := pdf.NewPdfPageResources()
+	gsName, err := addGraphicsState(resources, stampDef.Opacity, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	err = resources.SetFontByName(stampFontName, fonts.NewFontHelveticaBold().ToPdfObject())
+	if err != nil {
+		common.Log.Debug("Unable to add font %s", stampFontName)
+		return nil, nil, err
+	}
+
+	bbox := &pdf.PdfRectangle{Llx: stampDef.X, Lly: stampDef.Y, Urx: stampDef.X + stampDef.Width,
+		Ury: stampDef.Y + stampDef.Height}
+	creator := pdfcontent.NewContentCreator()
+	creator.Add_q()
+	if gsName != "" {
+		creator.Add_gs(gsName)
+	}
+
+	// Double frame, with a line width proportional to the height.
+	lineWidth := math.Max(stampDef.Height/20, 0.5)
+	creator.Add_RG(color.R(), color.G(), color.B()).Add_rg(color.R(), color.G(), color.B())
+	creator.Add_w(lineWidth)
+	for _, inset := range []float64{lineWidth / 2, 2.5 * lineWidth} {
+		creator.Add_re(bbox.Llx+inset, bbox.Lly+inset, stampDef.Width-2*inset, stampDef.Height-2*inset).Add_S()
+	}
+
+	// The text centered, as large as fits in the frame (on the cap height of Helvetica).
+	padding := 4 * lineWidth
+	fontSize := (stampDef.Height - 2*padding) / 0.718
+	width := textWidth(fonts.NewFontHelveticaBold(), stampDef.Text)
+	if width*fontSize/1000 > stampDef.Width-2*padding && width > 0 {
+		fontSize = (stampDef.Width - 2*padding) * 1000 / width
+	}
+	if fontSize > 0 {
+		creator.Add_BT().Add_Tf(stampFontName, fontSize)
+		creator.Add_Td(bbox.Llx+(stampDef.Width-width*fontSize/1000)/2,
+			bbox.Lly+(stampDef.Height-0.718*fontSize)/2)
+		creator.Add_Tj(encodeText(stampDef.Text)).Add_ET()
+	}
+	creator.Add_Q()
+
+	apDict, err := makeAppearance(creator.Bytes(), bbox, resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return apDict, bbox, nil
+}
@@ -0,0 +1,182 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	pdfcontent "github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	pdfcore "github.com/unidoc/unidoc/pdf/core"
+	pdf "github.com/unidoc/unidoc/pdf/model"
+)
+
+// The type of text markup annotation: highlighted, underlined, struck out or squiggly-underlined text.
+type TextMarkupType int
+
+const (
+	TextMarkupHighlight TextMarkupType = iota
+	TextMarkupUnderline
+	TextMarkupStrikeOut
+	TextMarkupSquiggly
+)
+
+// Defines a text markup annotation over text covered by quadrilaterals, e.g. the quadrilaterals of the text marks
+// or search matches of the extractor package, with a specified color (yellow for highlights and red otherwise by
+// default) and opacity.
+type TextMarkupAnnotationDef struct {
+	Type TextMarkupType
+	// The quadrilaterals covering the text, one for each line: lower left, lower right, upper right and upper left
+	// corners with respect to the text direction.
+	Quads    [][4]draw.Point
+	Color    *pdf.PdfColorDeviceRGB
+	Opacity  float64 // Alpha value (0-1).
+	Author   string
+	Contents string
+}
+
+// Creates a text markup annotation object with appearance stream that can be added to page PDF annotations.
+func CreateTextMarkupAnnotation(markupDef TextMarkupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if len(markupDef.Quads) == 0 {
+		common.Log.Debug("ERROR: Text markup annotation without quadrilaterals")
+		return nil, errors.New("Missing quadrilaterals")
+	}
+
+	// The quadrilaterals are in the order of the corners used by viewers: upper left, upper right, lower left and
+	// lower right.
+	points := []draw.Point{}
+	for _, quad := range markupDef.Quads {
+		points = append(points, quad[3], quad[2], quad[0], quad[1])
+	}
+	quadPoints := pointsArray(points)
+
+	var annot *pdf.PdfAnnotation
+	defaultColor := pdf.NewPdfColorDeviceRGB(1, 0, 0)
+	switch markupDef.Type {
+	case TextMarkupHighlight:
+		highlight := pdf.NewPdfAnnotationHighlight()
+		highlight.QuadPoints = quadPoints
+		annot = highlight.PdfAnnotation
+		defaultColor = pdf.NewPdfColorDeviceRGB(1, 1, 0)
+	case TextMarkupUnderline:
+		underline := pdf.NewPdfAnnotationUnderline()
+		underline.QuadPoints = quadPoints
+		annot = underline.PdfAnnotation
+	case TextMarkupStrikeOut:
+		strikeOut := pdf.NewPdfAnnotationStrikeOut()
+		strikeOut.QuadPoints = quadPoints
+		annot = strikeOut.PdfAnnotation
+	case TextMarkupSquiggly:
+		squiggly := pdf.NewPdfAnnotationSquiggly()
+		squiggly.QuadPoints = quadPoints
+		annot = squiggly.PdfAnnotation
+	default:
+		common.Log.Debug("ERROR: Invalid text markup type %d", markupDef.Type)
+		return nil, errors.New("Invalid text markup type")
+	}
+
+	color := colorOrDefault(markupDef.Color, defaultColor)
+	annot.C = rgbArray(color)
+	if markupDef.Opacity < 1.0 {
+		annot.GetMarkup().CA = pdfcore.MakeFloat(markupDef.Opacity)
+	}
+	setMarkupInfo(annot, markupDef.Author, markupDef.Contents)
+
+	// Make the appearance stream (for uniform appearance).
+	apDict, bbox, err := makeTextMarkupAnnotationAppearanceStream(markupDef, color)
+	if err != nil {
+		return nil, err
+	}
+	annot.AP = apDict
+	annot.Rect = bbox.ToPdfObject()
+
+	return annot, nil
+}
+
+func makeTextMarkupAnnotationAppearanceStream(markupDef TextMarkupAnnotationDef, color *pdf.PdfColorDeviceRGB) (
+	*pdfcore.PdfObjectDictionary, *pdf.PdfRectangle, error) {
+	resources := pdf.NewPdfPageResources()
+
+	// Highlights darken the text below rather than covering it.
+	blendMode := ""
+	if markupDef.Type == TextMarkupHighlight {
+		blendMode = "Multiply"
+	}
+	gsName, err := addGraphicsState(resources, markupDef.Opacity, blendMode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	creator := pdfcontent.NewContentCreator()
+	creator.Add_q()
+	if gsName != "" {
+		creator.Add_gs(gsName)
+	}
+	creator.Add_rg(color.R(), color.G(), color.B()).Add_RG(color.R(), color.G(), color.B())
+
+	points := []draw.Point{}
+	for _, quad := range markupDef.Quads {
+		points = append(points, quad[:]...)
+
+		// The base line of the quadrilateral and its height.
+		ll, lr, ul := quad[0], quad[1], quad[3]
+		height := math.Hypot(ul.X-ll.X, ul.Y-ll.Y)
+		at := func(p draw.Point, offset float64) draw.Point {
+			// Point at the offset (fraction of the height) from the base line.
+			return draw.NewPoint(p.X+(ul.X-ll.X)*offset, p.Y+(ul.Y-ll.Y)*offset)
+		}
+
+		switch markupDef.Type {
+		case TextMarkupHighlight:
+			creator.Add_m(quad[0].X, quad[0].Y)
+			for _, p := range quad[1:] {
+				creator.Add_l(p.X, p.Y)
+			}
+			creator.Add_h().Add_f()
+		case TextMarkupUnderline, TextMarkupStrikeOut:
+			offset := 0.1
+			if markupDef.Type == TextMarkupStrikeOut {
+				offset = 0.45
+			}
+			p1, p2 := at(ll, offset), at(lr, offset)
+			creator.Add_w(height/14).Add_m(p1.X, p1.Y).Add_l(p2.X, p2.Y).Add_S()
+		case TextMarkupSquiggly:
+			// Zigzag along the base line, with a period of a quarter of the height.
+			length := math.Hypot(lr.X-ll.X, lr.Y-ll.Y)
+			if length == 0 {
+				continue
+			}
+			step := height / 8
+			steps := int(math.Ceil(length / step))
+			creator.Add_w(height / 20)
+			for i := 0; i <= steps; i++ {
+				t := math.Min(float64(i)*step/length, 1)
+				base := draw.NewPoint(ll.X+(lr.X-ll.X)*t, ll.Y+(lr.Y-ll.Y)*t)
+				offset := 0.04
+				if i%2 == 1 {
+					offset = 0.12
+				}
+				p := at(base, offset)
+				if i == 0 {
+					creator.Add_m(p.X, p.Y)
+				} else {
+					creator.Add_l(p.X, p.Y)
+				}
+			}
+			creator.Add_S()
+		}
+	}
+	creator.Add_Q()
+
+	bbox := pointsBBox(points, 1)
+	apDict, err := makeAppearance(creator.Bytes(), bbox, resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return apDict, bbox, nil
+}
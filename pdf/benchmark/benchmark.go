@@ -0,0 +1,288 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package benchmark
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Operation is an operation benchmarked on the documents.
+type Operation struct {
+	// The name of the operation, in the results.
+	Name string
+
+	// prepare prepares the operation on the document, outside the timing, and returns the timed operation, which
+	// stops the timer of the benchmark while preparing each iteration if needed.
+	prepare func(data []byte) (func(b *testing.B) error, error)
+}
+
+// The operations benchmarked.
+var (
+	// Open parses the document and loads its pages.
+	Open = Operation{Name: "open", prepare: prepareOpen}
+
+	// Extract extracts the text of the pages of the opened document.
+	Extract = Operation{Name: "extract", prepare: prepareExtract}
+
+	// Write writes the pages of the opened document to a new document.
+	Write = Operation{Name: "write", prepare: prepareWrite}
+)
+
+// Operations returns all the operations benchmarked: Open, Extract and Write.
+func Operations() []Operation {
+	return []Operation{Open, Extract, Write}
+}
+
+// Benchmark benchmarks the operation on each document of the corpus, as sub-benchmarks named by the documents, with
+// the allocations reported.  Benchmark functions of the user run it on their corpus:
+//
+//	func BenchmarkExtract(b *testing.B) {
+//	    benchmark.Benchmark(b, corpus, benchmark.Extract)
+//	}
+func Benchmark(b *testing.B, corpus Corpus, op Operation) {
+	for _, name := range corpus.Names() {
+		b.Run(name, func(b *testing.B) {
+			run, err := prepare(corpus, name, op)
+			if err != nil {
+				b.Fatalf("%s: %v", name, err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := run(b); err != nil {
+					b.Fatalf("%s: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+// Result is the result of benchmarking an operation on a document.
+type Result struct {
+	Document  string
+	Operation string
+
+	// The number of iterations, and the time and the memory allocated per operation.
+	N           int
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// Run benchmarks the operations on each document of the corpus, outside of tests, e.g. for recording baselines to
+// compare later results with.  Returns an error if a document cannot be loaded or an operation fails.
+func Run(corpus Corpus, ops ...Operation) ([]Result, error) {
+	var results []Result
+	for _, name := range corpus.Names() {
+		for _, op := range ops {
+			run, err := prepare(corpus, name, op)
+			if err != nil {
+				return nil, err
+			}
+
+			var runErr error
+			r := testing.Benchmark(func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N && runErr == nil; i++ {
+					runErr = run(b)
+				}
+			})
+			if runErr != nil {
+				common.Log.Debug("ERROR: %s of %s failed: %v", op.Name, name, runErr)
+				return nil, runErr
+			}
+
+			results = append(results, Result{
+				Document:    name,
+				Operation:   op.Name,
+				N:           r.N,
+				NsPerOp:     r.NsPerOp(),
+				AllocsPerOp: r.AllocsPerOp(),
+				BytesPerOp:  r.AllocedBytesPerOp(),
+			})
+		}
+	}
+	return results, nil
+}
+
+// Regression is a metric of an operation on a document exceeding its baseline.
+type Regression struct {
+	Document  string
+	Operation string
+
+	// The metric ("ns/op", "allocs/op" or "B/op"), and its baseline and current values.
+	Metric   string
+	Baseline int64
+	Current  int64
+}
+
+// Compare compares the results with the baseline results, and returns the metrics exceeding their baseline by
+// more than the tolerance (e.g. 0.1 for 10%).  Results without baseline are ignored.
+func Compare(baseline, results []Result, tolerance float64) []Regression {
+	type key struct{ document, operation string }
+	base := map[key]Result{}
+	for _, r := range baseline {
+		base[key{r.Document, r.Operation}] = r
+	}
+
+	var regressions []Regression
+	for _, r := range results {
+		b, has := base[key{r.Document, r.Operation}]
+		if !has {
+			continue
+		}
+		metrics := []struct {
+			name              string
+			baseline, current int64
+		}{
+			{"ns/op", b.NsPerOp, r.NsPerOp},
+			{"allocs/op", b.AllocsPerOp, r.AllocsPerOp},
+			{"B/op", b.BytesPerOp, r.BytesPerOp},
+		}
+		for _, m := range metrics {
+			if float64(m.current) > float64(m.baseline)*(1+tolerance) {
+				regressions = append(regressions, Regression{
+					Document:  r.Document,
+					Operation: r.Operation,
+					Metric:    m.name,
+					Baseline:  m.baseline,
+					Current:   m.current,
+				})
+			}
+		}
+	}
+	return regressions
+}
+
+// prepare loads the document of the corpus and prepares the operation on it.
+func prepare(corpus Corpus, name string, op Operation) (func(b *testing.B) error, error) {
+	data, err := corpus.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return op.prepare(data)
+}
+
+// openPages opens the document, decrypted with the empty password if encrypted, and loads its pages.
+func openPages(data []byte) ([]*model.PdfPage, error) {
+	reader, err := model.NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	isEncrypted, err := reader.IsEncrypted()
+	if err != nil {
+		return nil, err
+	}
+	if isEncrypted {
+		auth, err := reader.Decrypt([]byte(""))
+		if err != nil {
+			return nil, err
+		}
+		if !auth {
+			return nil, errors.New("Encrypted document requires a password")
+		}
+	}
+
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]*model.PdfPage, 0, numPages)
+	for i := 1; i <= numPages; i++ {
+		page, err := reader.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+func prepareOpen(data []byte) (func(b *testing.B) error, error) {
+	return func(b *testing.B) error {
+		_, err := openPages(data)
+		return err
+	}, nil
+}
+
+func prepareExtract(data []byte) (func(b *testing.B) error, error) {
+	pages, err := openPages(data)
+	if err != nil {
+		return nil, err
+	}
+	return func(b *testing.B) error {
+		for _, page := range pages {
+			e, err := extractor.New(page)
+			if err != nil {
+				return err
+			}
+			_, err = e.ExtractText()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func prepareWrite(data []byte) (func(b *testing.B) error, error) {
+	_, err := openPages(data)
+	if err != nil {
+		return nil, err
+	}
+	return func(b *testing.B) error {
+		// The pages are reloaded, as writing modifies them.
+		b.StopTimer()
+		pages, err := openPages(data)
+		b.StartTimer()
+		if err != nil {
+			return err
+		}
+
+		w := model.NewPdfWriter()
+		for _, page := range pages {
+			err := w.AddPage(page)
+			if err != nil {
+				return err
+			}
+		}
+		return w.Write(&discard{})
+	}, nil
+}
+
+// discard is a WriteSeeker discarding the data written, for timing the writing without the output.
+type discard struct {
+	offset, size int64
+}
+
+func (d *discard) Write(p []byte) (int, error) {
+	d.offset += int64(len(p))
+	if d.offset > d.size {
+		d.size = d.offset
+	}
+	return len(p), nil
+}
+
+func (d *discard) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += d.offset
+	case io.SeekEnd:
+		offset += d.size
+	}
+	if offset < 0 {
+		return d.offset, errors.New("Invalid offset")
+	}
+	d.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package benchmark
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+const testDir = "../../testfiles"
+
+func TestRun(t *testing.T) {
+	corpus, err := NewDirCorpus(testDir)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	names := corpus.Names()
+	if len(names) != 3 || names[0] != "lorem.pdf" {
+		t.Fatalf("Wrong corpus %v", names)
+	}
+	data, err := corpus.Load("minimal.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	var mu sync.Mutex
+	stages := map[string]int{}
+	common.SetTimingHook(func(stage string, elapsed time.Duration) {
+		mu.Lock()
+		stages[stage]++
+		mu.Unlock()
+	})
+	defer common.SetTimingHook(nil)
+
+	results, err := Run(MemoryCorpus{"minimal": data}, Operations()...)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Wrong number of results %d", len(results))
+	}
+	for i, op := range Operations() {
+		r := results[i]
+		if r.Document != "minimal" || r.Operation != op.Name || r.N == 0 || r.NsPerOp <= 0 || r.AllocsPerOp <= 0 {
+			t.Errorf("Wrong result %+v", r)
+		}
+	}
+	for _, stage := range []string{common.TimingLoadXrefs, common.TimingLoadStructure, common.TimingLoadPage,
+		common.TimingParseContent, common.TimingExtractText, common.TimingWrite} {
+		if stages[stage] == 0 {
+			t.Errorf("Stage %s not timed", stage)
+		}
+	}
+
+	if _, err := Run(MemoryCorpus{"invalid": []byte("%PDF-1.4")}, Open); err == nil {
+		t.Errorf("Invalid document not detected")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []Result{
+		{Document: "a", Operation: "open", NsPerOp: 1000, AllocsPerOp: 10, BytesPerOp: 100},
+		{Document: "b", Operation: "open", NsPerOp: 1000, AllocsPerOp: 10, BytesPerOp: 100},
+	}
+	results := []Result{
+		{Document: "a", Operation: "open", NsPerOp: 1050, AllocsPerOp: 20, BytesPerOp: 100},
+		{Document: "b", Operation: "open", NsPerOp: 2000, AllocsPerOp: 10, BytesPerOp: 90},
+		{Document: "c", Operation: "open", NsPerOp: 2000, AllocsPerOp: 10, BytesPerOp: 90},
+	}
+	regressions := Compare(baseline, results, 0.1)
+	if len(regressions) != 2 {
+		t.Fatalf("Wrong regressions %+v", regressions)
+	}
+	if r := regressions[0]; r.Document != "a" || r.Metric != "allocs/op" || r.Baseline != 10 || r.Current != 20 {
+		t.Errorf("Wrong regression %+v", r)
+	}
+	if r := regressions[1]; r.Document != "b" || r.Metric != "ns/op" {
+		t.Errorf("Wrong regression %+v", r)
+	}
+}
+
+func BenchmarkTestFiles(b *testing.B) {
+	corpus, err := NewDirCorpus(testDir)
+	if err != nil {
+		b.Fatalf("Error: %v", err)
+	}
+	for _, op := range Operations() {
+		b.Run(op.Name, func(b *testing.B) {
+			Benchmark(b, corpus, op)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package benchmark
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Corpus is a set of PDF documents the benchmarks are run on, supplied by the user, e.g. documents representative
+// of a workload.
+type Corpus interface {
+	// Names returns the names of the documents, in the order they are benchmarked.
+	Names() []string
+
+	// Load returns the content of the document.
+	Load(name string) ([]byte, error)
+}
+
+// dirCorpus is a corpus of the PDF files in a directory.
+type dirCorpus struct {
+	dir   string
+	names []string
+}
+
+// NewDirCorpus returns the corpus of the PDF files (.pdf) in the directory and its subdirectories, named by their
+// paths relative to the directory.
+func NewDirCorpus(dir string) (Corpus, error) {
+	c := &dirCorpus{dir: dir}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".pdf") {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		c.names = append(c.names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(c.names)
+	return c, nil
+}
+
+// Names returns the paths of the files relative to the directory.
+func (c *dirCorpus) Names() []string {
+	return c.names
+}
+
+// Load reads the file.
+func (c *dirCorpus) Load(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(c.dir, name))
+}
+
+// MemoryCorpus is a corpus of documents in memory, by name.
+type MemoryCorpus map[string][]byte
+
+// Names returns the names of the documents, sorted.
+func (c MemoryCorpus) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the document.
+func (c MemoryCorpus) Load(name string) ([]byte, error) {
+	data, has := c[name]
+	if !has {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
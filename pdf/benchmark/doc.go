@@ -0,0 +1,15 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+//
+// Package benchmark benchmarks the opening, the text extraction and the writing of a corpus of PDF documents
+// supplied by the user, with the time and the allocations per operation, so that performance regressions on a
+// workload can be detected, e.g. when upgrading.
+//
+// The benchmarks run as sub-benchmarks of the benchmark functions of the user (Benchmark), or programmatically
+// (Run), and the results are compared with baseline results (Compare).  The durations of the stages of the
+// processing are reported in more detail by the timing hook of the common package (common.SetTimingHook).
+//
+package benchmark
@@ -13,12 +13,12 @@ type Circle struct {
 	Y             float64
 	Width         float64
 	Height        float64
-	FillEnabled   bool // Show fill?
-	FillColor     *pdf.PdfColorDeviceRGB
-	BorderEnabled bool // Show border?
+	FillEnabled   bool                   // Show fill?
+	FillColor     *pdf.PdfColorDeviceRGB // Keeps the current fill color if nil.
+	BorderEnabled bool                   // Show border?
 	BorderWidth   float64
-	BorderColor   *pdf.PdfColorDeviceRGB
-	Opacity       float64 // Alpha value (0-1).
+	BorderColor   *pdf.PdfColorDeviceRGB // Keeps the current stroking color if nil.
+	Opacity       float64                // Alpha value (0-1).
 }
 
 // Draw a circle. Can specify a graphics state (gsName) for setting opacity etc.  Otherwise leave empty ("").
@@ -52,11 +52,13 @@ func (c Circle) Draw(gsName string) ([]byte, *pdf.PdfRectangle, error) {
 
 	creator.Add_q()
 
-	if c.FillEnabled {
+	if c.FillEnabled && c.FillColor != nil {
 		creator.Add_rg(c.FillColor.R(), c.FillColor.G(), c.FillColor.B())
 	}
 	if c.BorderEnabled {
-		creator.Add_RG(c.BorderColor.R(), c.BorderColor.G(), c.BorderColor.B())
+		if c.BorderColor != nil {
+			creator.Add_RG(c.BorderColor.R(), c.BorderColor.G(), c.BorderColor.B())
+		}
 		creator.Add_w(c.BorderWidth)
 	}
 	if len(gsName) > 1 {
@@ -104,12 +106,12 @@ type Rectangle struct {
 	Y             float64
 	Width         float64
 	Height        float64
-	FillEnabled   bool // Show fill?
-	FillColor     *pdf.PdfColorDeviceRGB
-	BorderEnabled bool // Show border?
+	FillEnabled   bool                   // Show fill?
+	FillColor     *pdf.PdfColorDeviceRGB // Keeps the current fill color if nil.
+	BorderEnabled bool                   // Show border?
 	BorderWidth   float64
-	BorderColor   *pdf.PdfColorDeviceRGB
-	Opacity       float64 // Alpha value (0-1).
+	BorderColor   *pdf.PdfColorDeviceRGB // Keeps the current stroking color if nil.
+	Opacity       float64                // Alpha value (0-1).
 }
 
 // Draw the circle. Can specify a graphics state (gsName) for setting opacity etc.  Otherwise leave empty ("").
@@ -130,11 +132,13 @@ func (rect Rectangle) Draw(gsName string) ([]byte, *pdf.PdfRectangle, error) {
 	creator := pdfcontent.NewContentCreator()
 
 	creator.Add_q()
-	if rect.FillEnabled {
+	if rect.FillEnabled && rect.FillColor != nil {
 		creator.Add_rg(rect.FillColor.R(), rect.FillColor.G(), rect.FillColor.B())
 	}
 	if rect.BorderEnabled {
-		creator.Add_RG(rect.BorderColor.R(), rect.BorderColor.G(), rect.BorderColor.B())
+		if rect.BorderColor != nil {
+			creator.Add_RG(rect.BorderColor.R(), rect.BorderColor.G(), rect.BorderColor.B())
+		}
 		creator.Add_w(rect.BorderWidth)
 	}
 	if len(gsName) > 1 {
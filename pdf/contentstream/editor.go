@@ -0,0 +1,238 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ContentStreamEditor edits the operations of a content stream: operations can be inserted, removed and replaced,
+// and the edited operations serialized back to a content stream.
+//
+// Example: removing the image "Im1" from a page.
+//
+//	editor, err := NewContentStreamEditorFromPage(page)
+//	...
+//	editor.RemoveXObject("Im1")
+//	err = editor.ApplyToPage(page, core.NewFlateEncoder())
+type ContentStreamEditor struct {
+	operations ContentStreamOperations
+}
+
+// NewContentStreamEditor parses the content stream and returns an editor for its operations.
+func NewContentStreamEditor(contents string) (*ContentStreamEditor, error) {
+	operations, err := NewContentStreamParser(contents).Parse()
+	if err != nil {
+		common.Log.Debug("Unable to parse content stream: %v", err)
+		return nil, err
+	}
+	return &ContentStreamEditor{operations: *operations}, nil
+}
+
+// NewContentStreamEditorFromPage returns an editor for the operations of the content streams of the page.
+func NewContentStreamEditorFromPage(page *model.PdfPage) (*ContentStreamEditor, error) {
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	return NewContentStreamEditor(contents)
+}
+
+// Operations returns the operations of the content stream.  The operations can be modified in place, but the
+// returned slice is a copy: use Insert, Remove and Replace to change the list of operations.
+func (this *ContentStreamEditor) Operations() ContentStreamOperations {
+	return append(ContentStreamOperations{}, this.operations...)
+}
+
+// Len returns the number of operations.
+func (this *ContentStreamEditor) Len() int {
+	return len(this.operations)
+}
+
+// Find returns the indices of the operations for which match returns true.
+func (this *ContentStreamEditor) Find(match func(op *ContentStreamOperation) bool) []int {
+	indices := []int{}
+	for i, op := range this.operations {
+		if match(op) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Insert inserts the operations before the operation at index.  An index equal to Len() appends the operations.
+func (this *ContentStreamEditor) Insert(index int, ops ...*ContentStreamOperation) error {
+	if index < 0 || index > len(this.operations) {
+		common.Log.Debug("Insert index out of range (%d)", index)
+		return errors.New("Range check error")
+	}
+	operations := append(ContentStreamOperations{}, this.operations[:index]...)
+	operations = append(operations, ops...)
+	this.operations = append(operations, this.operations[index:]...)
+	return nil
+}
+
+// Remove removes the operation at index.
+func (this *ContentStreamEditor) Remove(index int) error {
+	return this.Replace(index)
+}
+
+// Replace replaces the operation at index with the operations (none to remove it).
+func (this *ContentStreamEditor) Replace(index int, ops ...*ContentStreamOperation) error {
+	if index < 0 || index >= len(this.operations) {
+		common.Log.Debug("Replace index out of range (%d)", index)
+		return errors.New("Range check error")
+	}
+	operations := append(ContentStreamOperations{}, this.operations[:index]...)
+	operations = append(operations, ops...)
+	this.operations = append(operations, this.operations[index+1:]...)
+	return nil
+}
+
+// Edit calls edit for each operation and replaces the operation with the returned operations.  Return the operation
+// itself to keep it unchanged, and nil to remove it.
+func (this *ContentStreamEditor) Edit(edit func(op *ContentStreamOperation) []*ContentStreamOperation) {
+	operations := ContentStreamOperations{}
+	for _, op := range this.operations {
+		operations = append(operations, edit(op)...)
+	}
+	this.operations = operations
+}
+
+// RemoveXObject removes the operations drawing the XObject (image or form) with the resource name, and returns the
+// number of operations removed.
+func (this *ContentStreamEditor) RemoveXObject(name PdfObjectName) int {
+	removed := 0
+	this.Edit(func(op *ContentStreamOperation) []*ContentStreamOperation {
+		if op.Operand == "Do" && len(op.Params) == 1 {
+			if opName, ok := op.Params[0].(*PdfObjectName); ok && *opName == name {
+				removed++
+				return nil
+			}
+		}
+		return []*ContentStreamOperation{op}
+	})
+	return removed
+}
+
+// Returns true for the operators setting the non-stroking (fill) color or color space.
+func isFillColorOperand(operand string) bool {
+	switch operand {
+	case "cs", "sc", "scn", "g", "rg", "k":
+		return true
+	}
+	return false
+}
+
+// SetTextFillColor sets the fill color of all text to the RGB color.  The fill color operations within text objects
+// are replaced, and the fill color in effect outside of text objects is restored after each text object, so other
+// content keeps its color.  Text drawn with stroking render modes keeps its stroke color.
+func (this *ContentStreamEditor) SetTextFillColor(r, g, b float64) {
+	// The operations setting the fill color outside of text objects, and their graphics state stack (q/Q).  Nil is
+	// the initial DeviceGray black.
+	var fill []*ContentStreamOperation
+	fillStack := [][]*ContentStreamOperation{}
+
+	inText := false
+	this.Edit(func(op *ContentStreamOperation) []*ContentStreamOperation {
+		switch {
+		case op.Operand == "BT":
+			inText = true
+			return []*ContentStreamOperation{op, {Operand: "rg", Params: makeParamsFromFloats([]float64{r, g, b})}}
+		case op.Operand == "ET":
+			inText = false
+			if fill == nil {
+				return []*ContentStreamOperation{op, {Operand: "g", Params: makeParamsFromFloats([]float64{0})}}
+			}
+			return append([]*ContentStreamOperation{op}, fill...)
+		case inText && isFillColorOperand(op.Operand):
+			return nil
+		case op.Operand == "q":
+			fillStack = append(fillStack, fill)
+		case op.Operand == "Q":
+			if len(fillStack) > 0 {
+				fill = fillStack[len(fillStack)-1]
+				fillStack = fillStack[:len(fillStack)-1]
+			}
+		case op.Operand == "sc" || op.Operand == "scn":
+			// The color in the current color space, which is set by the first operation if any.
+			if len(fill) > 0 && fill[0].Operand == "cs" {
+				fill = []*ContentStreamOperation{fill[0], op}
+			} else {
+				fill = []*ContentStreamOperation{op}
+			}
+		case isFillColorOperand(op.Operand):
+			fill = []*ContentStreamOperation{op}
+		}
+		return []*ContentStreamOperation{op}
+	})
+}
+
+// Validate checks that the operations form a valid content stream structure: balanced graphics state saving and
+// restoring (q/Q), text objects (BT/ET) which are not nested, and no q/Q within text objects.
+func (this *ContentStreamEditor) Validate() error {
+	depth := 0
+	inText := false
+	for i, op := range this.operations {
+		switch op.Operand {
+		case "q", "Q":
+			if inText {
+				common.Log.Debug("Operation %d: %s within text object", i, op.Operand)
+				return errors.New("Graphics state operator within text object")
+			}
+			if op.Operand == "q" {
+				depth++
+			} else if depth--; depth < 0 {
+				common.Log.Debug("Operation %d: Q without q", i)
+				return errors.New("Unbalanced graphics state operators")
+			}
+		case "BT":
+			if inText {
+				common.Log.Debug("Operation %d: nested text object", i)
+				return errors.New("Nested text object")
+			}
+			inText = true
+		case "ET":
+			if !inText {
+				common.Log.Debug("Operation %d: ET outside of text object", i)
+				return errors.New("Unbalanced text object operators")
+			}
+			inText = false
+		}
+	}
+
+	if inText {
+		return errors.New("Unterminated text object")
+	}
+	if depth != 0 {
+		return errors.New("Unbalanced graphics state operators")
+	}
+	return nil
+}
+
+// Bytes returns the content stream of the operations.
+func (this *ContentStreamEditor) Bytes() []byte {
+	return this.operations.Bytes()
+}
+
+// String returns the content stream of the operations as a string.
+func (this *ContentStreamEditor) String() string {
+	return string(this.operations.Bytes())
+}
+
+// ApplyToPage validates the operations and replaces the content streams of the page with a single content stream
+// encoded with the encoder (raw if nil).
+func (this *ContentStreamEditor) ApplyToPage(page *model.PdfPage, encoder StreamEncoder) error {
+	err := this.Validate()
+	if err != nil {
+		return err
+	}
+	return page.SetContentStreams([]string{this.String()}, encoder)
+}
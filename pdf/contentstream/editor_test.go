@@ -0,0 +1,84 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Returns the operands of the operations separated by spaces.
+func operandsString(operations ContentStreamOperations) string {
+	operands := []string{}
+	for _, op := range operations {
+		operands = append(operands, op.Operand)
+	}
+	return strings.Join(operands, " ")
+}
+
+func TestContentStreamEditor(t *testing.T) {
+	contents := "q /Im1 Do Q q 1 0 0 rg 0 0 10 10 re f BT /F1 12 Tf 0 0 1 rg (Hi) Tj ET 0 0 10 10 re f Q /Im2 Do /Im1 Do"
+	editor, err := NewContentStreamEditor(contents)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if removed := editor.RemoveXObject("Im1"); removed != 2 {
+		t.Errorf("Removed %d operations, expected 2", removed)
+	}
+	if indices := editor.Find(func(op *ContentStreamOperation) bool { return op.Operand == "Do" }); len(indices) != 1 {
+		t.Errorf("Incorrect Do operations: %v", indices)
+	}
+
+	editor.SetTextFillColor(0, 1, 0)
+	expected := "q Q q rg re f BT rg Tf Tj ET rg re f Q Do"
+	if operandsString(editor.Operations()) != expected {
+		t.Errorf("Incorrect operations: %s (expected %s)", operandsString(editor.Operations()), expected)
+	}
+	// The text is green, and the red fill color is restored after the text object.
+	ops := editor.Operations()
+	green, _ := getNumberAsFloat(ops[7].Params[1])
+	red, _ := getNumberAsFloat(ops[11].Params[0])
+	if green != 1 || red != 1 {
+		t.Errorf("Incorrect colors: %v %v", ops[7].Params, ops[11].Params)
+	}
+
+	// Insert, replace and remove.
+	err = editor.Insert(editor.Len(), &ContentStreamOperation{Operand: "q"}, &ContentStreamOperation{Operand: "Q"})
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	err = editor.Replace(0, &ContentStreamOperation{Operand: "q"}, &ContentStreamOperation{Operand: "q"})
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	if err := editor.Validate(); err == nil {
+		t.Errorf("Expected unbalanced q/Q")
+	}
+	if err := editor.Remove(0); err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	if err := editor.Remove(editor.Len()); err == nil {
+		t.Errorf("Expected range error")
+	}
+
+	// Serialized back to a page.
+	page := model.NewPdfPage()
+	err = editor.ApplyToPage(page, core.NewFlateEncoder())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	parsed, err := NewContentStreamEditorFromPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if operandsString(parsed.Operations()) != expected+" q Q" {
+		t.Errorf("Incorrect operations: %s", operandsString(parsed.Operations()))
+	}
+}
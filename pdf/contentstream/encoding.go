@@ -54,22 +54,118 @@ func newEncoderFromInlineImage(inlineImage *ContentStreamInlineImage) (core.Stre
 		}
 	}
 
-	if *filterName == "AHx" {
+	return newEncoderFromInlineImageFilter(inlineImage, string(*filterName), nil, nil)
+}
+
+// Abbreviations of the standard filter names used in inline images (Table 94 p. 224 PDF32000_2008).
+var inlineImageFilterAbbreviations = map[string]string{
+	core.StreamEncodingFilterNameASCIIHex:  "AHx",
+	core.StreamEncodingFilterNameASCII85:   "A85",
+	core.StreamEncodingFilterNameLZW:       "LZW",
+	core.StreamEncodingFilterNameFlate:     "Fl",
+	core.StreamEncodingFilterNameRunLength: "RL",
+	core.StreamEncodingFilterNameCCITTFax:  "CCF",
+	core.StreamEncodingFilterNameDCT:       "DCT",
+}
+
+// Returns the full name of an inline image filter, which may be abbreviated.
+func expandInlineImageFilterName(name string) string {
+	for full, abbreviation := range inlineImageFilterAbbreviations {
+		if name == abbreviation {
+			return full
+		}
+	}
+	return name
+}
+
+// Creates the encoder for a single filter of an inline image.  The decodeParams are used if provided, otherwise
+// they are taken from the inline image.  For filters following others in a filter array, mencoder holds the
+// preceding filters.
+func newEncoderFromInlineImageFilter(inlineImage *ContentStreamInlineImage, filterName string,
+	decodeParams *core.PdfObjectDictionary, mencoder *core.MultiEncoder) (core.StreamEncoder, error) {
+	switch expandInlineImageFilterName(filterName) {
+	case core.StreamEncodingFilterNameASCIIHex:
 		return core.NewASCIIHexEncoder(), nil
-	} else if *filterName == "A85" {
+	case core.StreamEncodingFilterNameASCII85:
 		return core.NewASCII85Encoder(), nil
-	} else if *filterName == "DCT" {
-		return newDCTEncoderFromInlineImage(inlineImage)
-	} else if *filterName == "Fl" {
-		return newFlateEncoderFromInlineImage(inlineImage, nil)
-	} else if *filterName == "LZW" {
-		return newLZWEncoderFromInlineImage(inlineImage, nil)
-	} else if *filterName == "CCF" {
-		return core.NewCCITTFaxEncoder(), nil
-	} else {
-		common.Log.Debug("Unsupported inline image encoding filter name : %s", *filterName)
-		return nil, errors.New("Unsupported inline encoding method")
+	case core.StreamEncodingFilterNameDCT:
+		return newDCTEncoderFromInlineImage(inlineImage, mencoder)
+	case core.StreamEncodingFilterNameFlate:
+		return newFlateEncoderFromInlineImage(inlineImage, decodeParams)
+	case core.StreamEncodingFilterNameLZW:
+		return newLZWEncoderFromInlineImage(inlineImage, decodeParams)
+	case core.StreamEncodingFilterNameRunLength:
+		return core.NewRunLengthEncoder(), nil
+	case core.StreamEncodingFilterNameCCITTFax:
+		return newCCITTFaxEncoderFromInlineImage(inlineImage, decodeParams)
 	}
+
+	common.Log.Debug("Unsupported inline image encoding filter name : %s", filterName)
+	return nil, errors.New("Unsupported inline encoding method")
+}
+
+// Returns the decode parameters dictionary of an inline image with a single filter, or nil if not set.
+func getInlineImageDecodeParams(inlineImage *ContentStreamInlineImage) (*core.PdfObjectDictionary, error) {
+	obj := inlineImage.DecodeParms
+	if arr, isArr := obj.(*core.PdfObjectArray); isArr {
+		if len(*arr) != 1 {
+			common.Log.Debug("Error: DecodeParms array length != 1 (%d)", len(*arr))
+			return nil, errors.New("Range check error")
+		}
+		obj = (*arr)[0]
+	}
+
+	switch t := obj.(type) {
+	case nil, *core.PdfObjectNull:
+		return nil, nil
+	case *core.PdfObjectDictionary:
+		return t, nil
+	}
+
+	common.Log.Debug("Error: DecodeParms not a dictionary (%T)", obj)
+	return nil, fmt.Errorf("Invalid DecodeParms")
+}
+
+// Create a new CCITTFax decoder from an inline image object, getting the encoding parameters from the
+// DecodeParms dictionary.
+func newCCITTFaxEncoderFromInlineImage(inlineImage *ContentStreamInlineImage, decodeParams *core.PdfObjectDictionary) (*core.CCITTFaxEncoder, error) {
+	encoder := core.NewCCITTFaxEncoder()
+
+	// If decodeParams not provided, see if we can get from the inline image directly.
+	if decodeParams == nil {
+		dp, err := getInlineImageDecodeParams(inlineImage)
+		if err != nil {
+			return nil, err
+		}
+		decodeParams = dp
+	}
+	if decodeParams == nil {
+		return encoder, nil
+	}
+
+	getInt := func(key core.PdfObjectName, def int) int {
+		if val, ok := decodeParams.Get(key).(*core.PdfObjectInteger); ok {
+			return int(*val)
+		}
+		return def
+	}
+	getBool := func(key core.PdfObjectName, def bool) bool {
+		if val, ok := decodeParams.Get(key).(*core.PdfObjectBool); ok {
+			return bool(*val)
+		}
+		return def
+	}
+	encoder.K = getInt("K", encoder.K)
+	encoder.EndOfLine = getBool("EndOfLine", encoder.EndOfLine)
+	encoder.EncodedByteAlign = getBool("EncodedByteAlign", encoder.EncodedByteAlign)
+	encoder.Columns = getInt("Columns", encoder.Columns)
+	encoder.Rows = getInt("Rows", encoder.Rows)
+	encoder.EndOfBlock = getBool("EndOfBlock", encoder.EndOfBlock)
+	encoder.BlackIs1 = getBool("BlackIs1", encoder.BlackIs1)
+	encoder.DamagedRowsBeforeError = getInt("DamagedRowsBeforeError", encoder.DamagedRowsBeforeError)
+
+	common.Log.Trace("CCITTFax encoder: %+v", encoder)
+	return encoder, nil
 }
 
 // Create a new flate decoder from an inline image object, getting all the encoding parameters
@@ -78,17 +174,13 @@ func newEncoderFromInlineImage(inlineImage *ContentStreamInlineImage) (core.Stre
 func newFlateEncoderFromInlineImage(inlineImage *ContentStreamInlineImage, decodeParams *core.PdfObjectDictionary) (*core.FlateEncoder, error) {
 	encoder := core.NewFlateEncoder()
 
-	// If decodeParams not provided, see if we can get from the stream.
+	// If decodeParams not provided, see if we can get from the inline image directly.
 	if decodeParams == nil {
-		obj := inlineImage.DecodeParms
-		if obj != nil {
-			dp, isDict := obj.(*core.PdfObjectDictionary)
-			if !isDict {
-				common.Log.Debug("Error: DecodeParms not a dictionary (%T)", obj)
-				return nil, fmt.Errorf("Invalid DecodeParms")
-			}
-			decodeParams = dp
+		dp, err := getInlineImageDecodeParams(inlineImage)
+		if err != nil {
+			return nil, err
 		}
+		decodeParams = dp
 	}
 	if decodeParams == nil {
 		// Can safely return here if no decode params, as the following depend on the decode params.
@@ -156,14 +248,11 @@ func newLZWEncoderFromInlineImage(inlineImage *ContentStreamInlineImage, decodeP
 
 	// If decodeParams not provided, see if we can get from the inline image directly.
 	if decodeParams == nil {
-		if inlineImage.DecodeParms != nil {
-			dp, isDict := inlineImage.DecodeParms.(*core.PdfObjectDictionary)
-			if !isDict {
-				common.Log.Debug("Error: DecodeParms not a dictionary (%T)", inlineImage.DecodeParms)
-				return nil, fmt.Errorf("Invalid DecodeParms")
-			}
-			decodeParams = dp
+		dp, err := getInlineImageDecodeParams(inlineImage)
+		if err != nil {
+			return nil, err
 		}
+		decodeParams = dp
 	}
 
 	if decodeParams == nil {
@@ -248,12 +337,22 @@ func newLZWEncoderFromInlineImage(inlineImage *ContentStreamInlineImage, decodeP
 }
 
 // Create a new DCT encoder/decoder based on an inline image, getting all the encoding parameters
-// from the stream object dictionary entry and the image data itself.
-func newDCTEncoderFromInlineImage(inlineImage *ContentStreamInlineImage) (*core.DCTEncoder, error) {
+// from the stream object dictionary entry and the image data itself.  If the DCT filter follows other filters,
+// mencoder holds these, and is used to decode the data first.
+func newDCTEncoderFromInlineImage(inlineImage *ContentStreamInlineImage, mencoder *core.MultiEncoder) (*core.DCTEncoder, error) {
 	// Start with default settings.
 	encoder := core.NewDCTEncoder()
 
-	bufReader := bytes.NewReader(inlineImage.stream)
+	data := inlineImage.stream
+	if mencoder != nil {
+		decoded, err := mencoder.DecodeBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	}
+
+	bufReader := bytes.NewReader(data)
 
 	cfg, err := jpeg.DecodeConfig(bufReader)
 	//img, _, err := goimage.Decode(bufReader)
@@ -359,29 +458,18 @@ func newMultiEncoderFromInlineImage(inlineImage *ContentStreamInlineImage) (*cor
 			dParams = dict
 		}
 
-		if *name == core.StreamEncodingFilterNameFlate || *name == "Fl" {
-			// XXX: need to separate out the DecodeParms..
-			encoder, err := newFlateEncoderFromInlineImage(inlineImage, dParams)
-			if err != nil {
-				return nil, err
-			}
-			mencoder.AddEncoder(encoder)
-		} else if *name == core.StreamEncodingFilterNameLZW {
-			encoder, err := newLZWEncoderFromInlineImage(inlineImage, dParams)
-			if err != nil {
-				return nil, err
-			}
-			mencoder.AddEncoder(encoder)
-		} else if *name == core.StreamEncodingFilterNameASCIIHex {
-			encoder := core.NewASCIIHexEncoder()
-			mencoder.AddEncoder(encoder)
-		} else if *name == core.StreamEncodingFilterNameASCII85 || *name == "A85" {
-			encoder := core.NewASCII85Encoder()
-			mencoder.AddEncoder(encoder)
-		} else {
+		if dParams == nil {
+			// No parameters for this filter.  Not looked up from the inline image, as these describe the whole
+			// filter array.
+			dParams = core.MakeDict()
+		}
+
+		encoder, err := newEncoderFromInlineImageFilter(inlineImage, string(*name), dParams, mencoder)
+		if err != nil {
 			common.Log.Error("Unsupported filter %s", *name)
-			return nil, fmt.Errorf("Invalid filter in multi filter array")
+			return nil, err
 		}
+		mencoder.AddEncoder(encoder)
 	}
 
 	return mencoder, nil
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/unidoc/unidoc/common"
 	"github.com/unidoc/unidoc/pdf/core"
@@ -58,14 +59,58 @@ func NewInlineImageFromImage(img model.Image, encoder core.StreamEncoder) (*Cont
 	}
 
 	inlineImage.stream = encoded
+	inlineImage.setFilter(encoder)
 
-	filterName := encoder.GetFilterName()
-	if filterName != core.StreamEncodingFilterNameRaw {
-		inlineImage.Filter = core.MakeName(filterName)
+	return &inlineImage, nil
+}
+
+// Set compression filter.  Decodes with the current filters and encodes the data with the new filter, e.g. to
+// convert inline images with filters which cannot be encoded (CCITTFax, DCT) when rewriting content streams.
+func (this *ContentStreamInlineImage) SetFilter(encoder core.StreamEncoder) error {
+	current, err := newEncoderFromInlineImage(this)
+	if err != nil {
+		return err
+	}
+	decoded, err := current.DecodeBytes(this.stream)
+	if err != nil {
+		return err
 	}
-	// XXX/FIXME: Add decode params?
 
-	return &inlineImage, nil
+	encoded, err := encoder.EncodeBytes(decoded)
+	if err != nil {
+		return err
+	}
+
+	this.stream = encoded
+	this.setFilter(encoder)
+	return nil
+}
+
+// Sets the Filter and DecodeParms entries for the encoder, with abbreviated filter names.
+func (this *ContentStreamInlineImage) setFilter(encoder core.StreamEncoder) {
+	names := []core.PdfObject{}
+	for _, name := range strings.Fields(encoder.GetFilterName()) {
+		if name == core.StreamEncodingFilterNameRaw {
+			continue
+		}
+		if abbreviation, has := inlineImageFilterAbbreviations[name]; has {
+			name = abbreviation
+		}
+		names = append(names, core.MakeName(name))
+	}
+
+	this.Filter = nil
+	this.DecodeParms = nil
+	if len(names) == 1 {
+		this.Filter = names[0]
+	} else if len(names) > 1 {
+		this.Filter = core.MakeArray(names...)
+	}
+	if len(names) > 0 {
+		if decodeParams := encoder.MakeDecodeParams(); decodeParams != nil {
+			this.DecodeParms = decodeParams
+		}
+	}
 }
 
 func (this *ContentStreamInlineImage) String() string {
@@ -110,6 +155,12 @@ func (this *ContentStreamInlineImage) DefaultWriteString() string {
 	// Write out the parameters
 	s := ""
 
+	// Data containing the end marker would end the image early when parsed, so is written ASCIIHex encoded.
+	stream, filter, decodeParms := this.stream, this.Filter, this.DecodeParms
+	if containsInlineImageEnd(stream) {
+		stream, filter, decodeParms = this.hexEncoded()
+	}
+
 	if this.BitsPerComponent != nil {
 		s += "/BPC " + this.BitsPerComponent.DefaultWriteString() + "\n"
 	}
@@ -119,11 +170,11 @@ func (this *ContentStreamInlineImage) DefaultWriteString() string {
 	if this.Decode != nil {
 		s += "/D " + this.Decode.DefaultWriteString() + "\n"
 	}
-	if this.DecodeParms != nil {
-		s += "/DP " + this.DecodeParms.DefaultWriteString() + "\n"
+	if decodeParms != nil {
+		s += "/DP " + decodeParms.DefaultWriteString() + "\n"
 	}
-	if this.Filter != nil {
-		s += "/F " + this.Filter.DefaultWriteString() + "\n"
+	if filter != nil {
+		s += "/F " + filter.DefaultWriteString() + "\n"
 	}
 	if this.Height != nil {
 		s += "/H " + this.Height.DefaultWriteString() + "\n"
@@ -143,12 +194,49 @@ func (this *ContentStreamInlineImage) DefaultWriteString() string {
 	output.WriteString(s)
 
 	output.WriteString("ID ")
-	output.Write(this.stream)
+	output.Write(stream)
 	output.WriteString("\nEI\n")
 
 	return output.String()
 }
 
+// Returns true if the image data contains "EI" surrounded by whitespace (or at the end of the data), which
+// marks the end of the inline image.
+func containsInlineImageEnd(data []byte) bool {
+	for i := 0; i+2 < len(data); i++ {
+		if core.IsWhiteSpace(data[i]) && data[i+1] == 'E' && data[i+2] == 'I' &&
+			(i+3 == len(data) || core.IsWhiteSpace(data[i+3])) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the image data encoded with the ASCIIHex filter in addition to the current filters, and the
+// corresponding Filter and DecodeParms entries.
+func (this *ContentStreamInlineImage) hexEncoded() ([]byte, core.PdfObject, core.PdfObject) {
+	// ASCIIHex encoding does not fail.
+	encoded, _ := core.NewASCIIHexEncoder().EncodeBytes(this.stream)
+
+	filter := core.MakeArray(core.MakeName("AHx"))
+	switch t := this.Filter.(type) {
+	case *core.PdfObjectName:
+		filter = core.MakeArray(core.MakeName("AHx"), t)
+	case *core.PdfObjectArray:
+		filter = core.MakeArray(append([]core.PdfObject{core.MakeName("AHx")}, *t...)...)
+	}
+
+	decodeParms := this.DecodeParms
+	switch t := this.DecodeParms.(type) {
+	case *core.PdfObjectDictionary:
+		decodeParms = core.MakeArray(core.MakeNull(), t)
+	case *core.PdfObjectArray:
+		decodeParms = core.MakeArray(append([]core.PdfObject{core.MakeNull()}, *t...)...)
+	}
+
+	return encoded, filter, decodeParms
+}
+
 func (this *ContentStreamInlineImage) GetColorSpace(resources *model.PdfPageResources) (model.PdfColorspace, error) {
 	if this.ColorSpace == nil {
 		// Default.
@@ -339,11 +427,11 @@ func (this *ContentStreamParser) ParseInlineImage() (*ContentStreamInlineImage,
 				im.Filter = valueObj
 			} else if *param == "H" || *param == "Height" {
 				im.Height = valueObj
-			} else if *param == "IM" {
+			} else if *param == "IM" || *param == "ImageMask" {
 				im.ImageMask = valueObj
 			} else if *param == "Intent" {
 				im.Intent = valueObj
-			} else if *param == "I" {
+			} else if *param == "I" || *param == "Interpolate" {
 				im.Interpolate = valueObj
 			} else if *param == "W" || *param == "Width" {
 				im.Width = valueObj
@@ -383,6 +471,19 @@ func (this *ContentStreamParser) ParseInlineImage() (*ContentStreamInlineImage,
 				im.stream = []byte{}
 				state := 0
 				var skipBytes []byte
+
+				// Called when c (the last byte of skipBytes) does not continue "<ws>EI<ws>".  The skipped bytes
+				// are part of the data, except c if it is whitespace, which may precede the "EI".
+				restart := func(c byte) int {
+					if core.IsWhiteSpace(c) {
+						im.stream = append(im.stream, skipBytes[:len(skipBytes)-1]...)
+						skipBytes = []byte{c}
+						return 1
+					}
+					im.stream = append(im.stream, skipBytes...)
+					skipBytes = []byte{} // Clear.
+					return 0
+				}
 				for {
 					c, err := this.reader.ReadByte()
 					if err != nil {
@@ -403,23 +504,14 @@ func (this *ContentStreamParser) ParseInlineImage() (*ContentStreamInlineImage,
 						if c == 'E' {
 							state = 2
 						} else {
-							im.stream = append(im.stream, skipBytes...)
-							skipBytes = []byte{} // Clear.
-							// Need an extra check to decide if we fall back to state 0 or 1.
-							if core.IsWhiteSpace(c) {
-								state = 1
-							} else {
-								state = 0
-							}
+							state = restart(c)
 						}
 					} else if state == 2 {
 						skipBytes = append(skipBytes, c)
 						if c == 'I' {
 							state = 3
 						} else {
-							im.stream = append(im.stream, skipBytes...)
-							skipBytes = []byte{} // Clear.
-							state = 0
+							state = restart(c)
 						}
 					} else if state == 3 {
 						skipBytes = append(skipBytes, c)
@@ -434,9 +526,7 @@ func (this *ContentStreamParser) ParseInlineImage() (*ContentStreamInlineImage,
 							return &im, nil
 						} else {
 							// Seems like "<ws>EI" was part of the data.
-							im.stream = append(im.stream, skipBytes...)
-							skipBytes = []byte{} // Clear.
-							state = 0
+							state = restart(c)
 						}
 					}
 				}
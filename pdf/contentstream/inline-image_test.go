@@ -0,0 +1,96 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Parses content with a single inline image.
+func parseInlineImage(t *testing.T, content string) *ContentStreamInlineImage {
+	operations, err := NewContentStreamParser(content).Parse()
+	if err != nil {
+		t.Errorf("Failed to parse: %v", err)
+		return nil
+	}
+	if len(*operations) != 1 || (*operations)[0].Operand != "BI" {
+		t.Errorf("Expected a single inline image: %v", *operations)
+		return nil
+	}
+	return (*operations)[0].Params[0].(*ContentStreamInlineImage)
+}
+
+// Parses and decodes an inline image, and checks the decoded data.
+func checkInlineImage(t *testing.T, content string, expected []byte) *ContentStreamInlineImage {
+	iimg := parseInlineImage(t, content)
+	if iimg == nil {
+		return nil
+	}
+	img, err := iimg.ToImage(model.NewPdfPageResources())
+	if err != nil {
+		t.Errorf("Failed to decode image: %v", err)
+		return nil
+	}
+	if !bytes.Equal(img.Data, expected) {
+		t.Errorf("Wrong data (% x), expected (% x)", img.Data, expected)
+		return nil
+	}
+	return iimg
+}
+
+func TestInlineImageFilters(t *testing.T) {
+	// CCITT Group 4 encoded 8x2 stencil mask.
+	ccitt := "BI /W 8 /H 2 /IM true /F /CCF /DP << /K -1 /Columns 8 /Rows 2 >> ID " +
+		"\x2F\x78\x00\x80\x08\nEI\n"
+	iimg := checkInlineImage(t, ccitt, []byte{0xC7, 0xC7})
+	if iimg == nil {
+		return
+	}
+
+	// Rewritten with another filter, as CCITT encoding is not supported.
+	err := iimg.SetFilter(core.NewRunLengthEncoder())
+	if err != nil {
+		t.Errorf("Failed to set filter: %v", err)
+		return
+	}
+	if name, ok := iimg.Filter.(*core.PdfObjectName); !ok || *name != "RL" {
+		t.Errorf("Wrong filter %v", iimg.Filter)
+	}
+	if iimg.DecodeParms != nil {
+		t.Errorf("Unexpected decode params %v", iimg.DecodeParms)
+	}
+	checkInlineImage(t, "BI\n"+iimg.DefaultWriteString(), []byte{0xC7, 0xC7})
+
+	// Full key and filter names, and filter arrays.
+	checkInlineImage(t, "BI /Width 4 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray /ImageMask false "+
+		"/Filter /RunLengthDecode ID \x03abcd\x80\nEI\n", []byte("abcd"))
+	checkInlineImage(t, "BI /W 4 /H 1 /BPC 8 /CS /G /F [/AHx /RL] /DP [null null] ID 036162636480>\nEI\n",
+		[]byte("abcd"))
+}
+
+func TestInlineImageRewriteData(t *testing.T) {
+	testcases := [][]byte{
+		// End marker within the data.
+		[]byte("ab EI cd"),
+		[]byte("abc\nEI"),
+		// Whitespace before the end marker written.
+		[]byte("abc \n"),
+	}
+
+	for _, data := range testcases {
+		iimg := &ContentStreamInlineImage{
+			Width:            core.MakeInteger(int64(len(data))),
+			Height:           core.MakeInteger(1),
+			BitsPerComponent: core.MakeInteger(8),
+			stream:           data,
+		}
+		checkInlineImage(t, "BI\n"+iimg.DefaultWriteString(), data)
+	}
+}
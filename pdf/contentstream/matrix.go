@@ -0,0 +1,130 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/pdf/core"
+)
+
+// Matrix is an affine transformation matrix [a b c d e f] as used by the cm and Tm operators.
+// It represents the 3x3 matrix
+//
+//	| a b 0 |
+//	| c d 0 |
+//	| e f 1 |
+//
+// and transforms a point (x, y) into (a*x + c*y + e, b*x + d*y + f).
+type Matrix [6]float64
+
+// IdentityMatrix returns the identity transformation matrix.
+func IdentityMatrix() Matrix {
+	return Matrix{1, 0, 0, 1, 0, 0}
+}
+
+// NewMatrix returns a transformation matrix with the components a, b, c, d, e, f.
+func NewMatrix(a, b, c, d, e, f float64) Matrix {
+	return Matrix{a, b, c, d, e, f}
+}
+
+// TranslationMatrix returns a matrix translating by (tx, ty).
+func TranslationMatrix(tx, ty float64) Matrix {
+	return Matrix{1, 0, 0, 1, tx, ty}
+}
+
+// ScaleMatrix returns a matrix scaling by sx and sy along the x and y axes.
+func ScaleMatrix(sx, sy float64) Matrix {
+	return Matrix{sx, 0, 0, sy, 0, 0}
+}
+
+// RotationMatrix returns a matrix rotating counter-clockwise by angleDeg degrees.
+func RotationMatrix(angleDeg float64) Matrix {
+	angle := angleDeg * math.Pi / 180.0
+	cos := math.Cos(angle)
+	sin := math.Sin(angle)
+	return Matrix{cos, sin, -sin, cos, 0, 0}
+}
+
+// NewMatrixFromPdfObjects returns the matrix represented by the 6 numeric objects, e.g. the operands
+// of a cm or Tm operator or a /Matrix array.
+func NewMatrixFromPdfObjects(objs []core.PdfObject) (Matrix, error) {
+	if len(objs) != 6 {
+		return IdentityMatrix(), errors.New("Invalid number of matrix components")
+	}
+	var m Matrix
+	for i, obj := range objs {
+		val, err := getNumberAsFloat(core.TraceToDirectObject(obj))
+		if err != nil {
+			return IdentityMatrix(), err
+		}
+		m[i] = val
+	}
+	return m, nil
+}
+
+// Mult returns the product m*b, i.e. the transformation m followed by b.
+func (m Matrix) Mult(b Matrix) Matrix {
+	return Matrix{
+		m[0]*b[0] + m[1]*b[2],
+		m[0]*b[1] + m[1]*b[3],
+		m[2]*b[0] + m[3]*b[2],
+		m[2]*b[1] + m[3]*b[3],
+		m[4]*b[0] + m[5]*b[2] + b[4],
+		m[4]*b[1] + m[5]*b[3] + b[5],
+	}
+}
+
+// Transform applies the transformation to the point (x, y).
+func (m Matrix) Transform(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// Translation returns the translation components (e, f) of the matrix.
+func (m Matrix) Translation() (float64, float64) {
+	return m[4], m[5]
+}
+
+// ScalingFactorX returns the length of the unit vector along the x axis after transformation.
+func (m Matrix) ScalingFactorX() float64 {
+	return math.Hypot(m[0], m[1])
+}
+
+// ScalingFactorY returns the length of the unit vector along the y axis after transformation.
+func (m Matrix) ScalingFactorY() float64 {
+	return math.Hypot(m[2], m[3])
+}
+
+// Angle returns the rotation angle of the x axis in degrees (counter-clockwise).
+func (m Matrix) Angle() float64 {
+	return math.Atan2(m[1], m[0]) * 180.0 / math.Pi
+}
+
+// Inverse returns the inverse transformation. The boolean is false if the matrix is not invertible.
+func (m Matrix) Inverse() (Matrix, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return IdentityMatrix(), false
+	}
+	return Matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}, true
+}
+
+// ToPdfObjects returns the matrix components as a list of float objects, e.g. for use as cm operands.
+func (m Matrix) ToPdfObjects() []core.PdfObject {
+	objs := []core.PdfObject{}
+	for _, val := range m {
+		objs = append(objs, core.MakeFloat(val))
+	}
+	return objs
+}
@@ -0,0 +1,124 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+// OperatorCategory is a category of content stream operators (Table 51 p. 111 PDF32000_2008).
+type OperatorCategory int
+
+const (
+	// General graphics state: w, J, j, M, d, ri, i, gs.
+	OperatorCategoryGeneralGraphicsState OperatorCategory = iota
+	// Special graphics state: q, Q, cm.
+	OperatorCategorySpecialGraphicsState
+	// Path construction: m, l, c, v, y, h, re.
+	OperatorCategoryPathConstruction
+	// Path painting: S, s, f, F, f*, B, B*, b, b*, n.
+	OperatorCategoryPathPainting
+	// Clipping paths: W, W*.
+	OperatorCategoryClipping
+	// Text objects: BT, ET.
+	OperatorCategoryTextObject
+	// Text state: Tc, Tw, Tz, TL, Tf, Tr, Ts.
+	OperatorCategoryTextState
+	// Text positioning: Td, TD, Tm, T*.
+	OperatorCategoryTextPositioning
+	// Text showing: Tj, TJ, ', ".
+	OperatorCategoryTextShowing
+	// Type 3 fonts: d0, d1.
+	OperatorCategoryType3Font
+	// Color: CS, cs, SC, SCN, sc, scn, G, g, RG, rg, K, k.
+	OperatorCategoryColor
+	// Shading patterns: sh.
+	OperatorCategoryShading
+	// Inline images: BI (the image is the operand of BI).
+	OperatorCategoryInlineImage
+	// XObjects: Do.
+	OperatorCategoryXObject
+	// Marked content: MP, DP, BMC, BDC, EMC.
+	OperatorCategoryMarkedContent
+	// Compatibility: BX, EX.
+	OperatorCategoryCompatibility
+)
+
+var operatorCategories = map[string]OperatorCategory{
+	"w": OperatorCategoryGeneralGraphicsState, "J": OperatorCategoryGeneralGraphicsState,
+	"j": OperatorCategoryGeneralGraphicsState, "M": OperatorCategoryGeneralGraphicsState,
+	"d": OperatorCategoryGeneralGraphicsState, "ri": OperatorCategoryGeneralGraphicsState,
+	"i": OperatorCategoryGeneralGraphicsState, "gs": OperatorCategoryGeneralGraphicsState,
+
+	"q": OperatorCategorySpecialGraphicsState, "Q": OperatorCategorySpecialGraphicsState,
+	"cm": OperatorCategorySpecialGraphicsState,
+
+	"m": OperatorCategoryPathConstruction, "l": OperatorCategoryPathConstruction,
+	"c": OperatorCategoryPathConstruction, "v": OperatorCategoryPathConstruction,
+	"y": OperatorCategoryPathConstruction, "h": OperatorCategoryPathConstruction,
+	"re": OperatorCategoryPathConstruction,
+
+	"S": OperatorCategoryPathPainting, "s": OperatorCategoryPathPainting, "f": OperatorCategoryPathPainting,
+	"F": OperatorCategoryPathPainting, "f*": OperatorCategoryPathPainting, "B": OperatorCategoryPathPainting,
+	"B*": OperatorCategoryPathPainting, "b": OperatorCategoryPathPainting, "b*": OperatorCategoryPathPainting,
+	"n": OperatorCategoryPathPainting,
+
+	"W": OperatorCategoryClipping, "W*": OperatorCategoryClipping,
+
+	"BT": OperatorCategoryTextObject, "ET": OperatorCategoryTextObject,
+
+	"Tc": OperatorCategoryTextState, "Tw": OperatorCategoryTextState, "Tz": OperatorCategoryTextState,
+	"TL": OperatorCategoryTextState, "Tf": OperatorCategoryTextState, "Tr": OperatorCategoryTextState,
+	"Ts": OperatorCategoryTextState,
+
+	"Td": OperatorCategoryTextPositioning, "TD": OperatorCategoryTextPositioning,
+	"Tm": OperatorCategoryTextPositioning, "T*": OperatorCategoryTextPositioning,
+
+	"Tj": OperatorCategoryTextShowing, "TJ": OperatorCategoryTextShowing, "'": OperatorCategoryTextShowing,
+	"\"": OperatorCategoryTextShowing,
+
+	"d0": OperatorCategoryType3Font, "d1": OperatorCategoryType3Font,
+
+	"CS": OperatorCategoryColor, "cs": OperatorCategoryColor, "SC": OperatorCategoryColor,
+	"SCN": OperatorCategoryColor, "sc": OperatorCategoryColor, "scn": OperatorCategoryColor,
+	"G": OperatorCategoryColor, "g": OperatorCategoryColor, "RG": OperatorCategoryColor,
+	"rg": OperatorCategoryColor, "K": OperatorCategoryColor, "k": OperatorCategoryColor,
+
+	"sh": OperatorCategoryShading,
+
+	"BI": OperatorCategoryInlineImage, "ID": OperatorCategoryInlineImage, "EI": OperatorCategoryInlineImage,
+
+	"Do": OperatorCategoryXObject,
+
+	"MP": OperatorCategoryMarkedContent, "DP": OperatorCategoryMarkedContent,
+	"BMC": OperatorCategoryMarkedContent, "BDC": OperatorCategoryMarkedContent,
+	"EMC": OperatorCategoryMarkedContent,
+
+	"BX": OperatorCategoryCompatibility, "EX": OperatorCategoryCompatibility,
+}
+
+// GetOperatorCategory returns the category of the content stream operator.  Returns false if the operator is
+// unknown.
+func GetOperatorCategory(operand string) (OperatorCategory, bool) {
+	category, has := operatorCategories[operand]
+	return category, has
+}
+
+// IsText returns true for the categories of operators related to text: text objects, state, positioning and
+// showing.
+func (category OperatorCategory) IsText() bool {
+	switch category {
+	case OperatorCategoryTextObject, OperatorCategoryTextState, OperatorCategoryTextPositioning,
+		OperatorCategoryTextShowing:
+		return true
+	}
+	return false
+}
+
+// IsPath returns true for the categories of operators related to paths: construction, painting and clipping.
+func (category OperatorCategory) IsPath() bool {
+	switch category {
+	case OperatorCategoryPathConstruction, OperatorCategoryPathPainting, OperatorCategoryClipping:
+		return true
+	}
+	return false
+}
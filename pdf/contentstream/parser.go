@@ -37,6 +37,8 @@ func NewContentStreamParser(contentStr string) *ContentStreamParser {
 
 // Parses all commands in content stream, returning a list of operation data.
 func (this *ContentStreamParser) Parse() (*ContentStreamOperations, error) {
+	defer common.StartTiming(common.TimingParseContent)()
+
 	operations := ContentStreamOperations{}
 
 	for {
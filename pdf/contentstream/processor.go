@@ -13,13 +13,63 @@ import (
 	. "github.com/unidoc/unidoc/pdf/model"
 )
 
-// Basic graphics state implementation.
-// Initially only implementing and tracking a portion of the information specified.  Easy to add more.
+// Graphics state implementation (Table 52 p. 121 PDF32000_2008), including the text state parameters.
+// Tracks the parameters set by content stream operators and the corresponding ExtGState entries.
 type GraphicsState struct {
 	ColorspaceStroking    PdfColorspace
 	ColorspaceNonStroking PdfColorspace
 	ColorStroking         PdfColor
 	ColorNonStroking      PdfColor
+
+	// Current transformation matrix (cm).
+	CTM Matrix
+
+	// Line parameters (w, J, j, M, d).
+	LineWidth  float64
+	LineCap    int
+	LineJoin   int
+	MiterLimit float64
+	DashArray  []float64
+	DashPhase  float64
+
+	// Rendering intent (ri) and flatness tolerance (i).
+	RenderingIntent string
+	Flatness        float64
+
+	// Constant alpha for stroking (CA) and non-stroking (ca) operations.
+	StrokeAlpha float64
+	FillAlpha   float64
+
+	// Text state parameters (Tc, Tw, Tz, TL, Tf, Tr, Ts).  The font is the name of the font resource.
+	CharSpacing       float64
+	WordSpacing       float64
+	HorizontalScaling float64
+	Leading           float64
+	Font              PdfObjectName
+	FontSize          float64
+	TextRenderMode    int
+	TextRise          float64
+}
+
+// newGraphicsState returns the graphics state with the initial values of the parameters.
+func newGraphicsState() GraphicsState {
+	gs := GraphicsState{}
+	gs.CTM = IdentityMatrix()
+	gs.LineWidth = 1
+	gs.MiterLimit = 10
+	gs.RenderingIntent = "RelativeColorimetric"
+	gs.Flatness = 1
+	gs.StrokeAlpha = 1
+	gs.FillAlpha = 1
+	gs.HorizontalScaling = 100
+	return gs
+}
+
+// MarkedContent is an entry of the marked content stack (BMC or BDC operator).
+type MarkedContent struct {
+	Tag PdfObjectName
+	// The property list (dictionary or name of a resource in the Properties subdictionary), nil for BMC.
+	Properties PdfObject
 }
 
 type GraphicStateStack []GraphicsState
@@ -42,15 +92,25 @@ type ContentStreamProcessor struct {
 	operations    []*ContentStreamOperation
 	graphicsState GraphicsState
 
+	// Marked content sequences entered with BMC/BDC and not yet ended with EMC.
+	markedContent []MarkedContent
+
 	handlers     []HandlerEntry
 	currentIndex int
+
+	// Depth of Form XObject recursion, 0 for the processor of a page.
+	depth int
 }
 
+// MaxFormDepth is the maximum depth of nested Form XObjects processed, guarding against cyclic references.
+const MaxFormDepth = 20
+
 type HandlerFunc func(op *ContentStreamOperation, gs GraphicsState, resources *PdfPageResources) error
 
 type HandlerEntry struct {
 	Condition HandlerConditionEnum
 	Operand   string
+	Category  OperatorCategory
 	Handler   HandlerFunc
 }
 
@@ -64,9 +124,15 @@ func (this HandlerConditionEnum) Operand() bool {
 	return this == HandlerConditionEnumOperand
 }
 
+// Category returns true for handlers of an operator category.
+func (this HandlerConditionEnum) Category() bool {
+	return this == HandlerConditionEnumCategory
+}
+
 const (
 	HandlerConditionEnumOperand     HandlerConditionEnum = iota
 	HandlerConditionEnumAllOperands HandlerConditionEnum = iota
+	HandlerConditionEnumCategory    HandlerConditionEnum = iota
 )
 
 func NewContentStreamProcessor(ops []*ContentStreamOperation) *ContentStreamProcessor {
@@ -74,9 +140,7 @@ func NewContentStreamProcessor(ops []*ContentStreamOperation) *ContentStreamProc
 	csp.graphicsStack = GraphicStateStack{}
 
 	// Set defaults..
-	gs := GraphicsState{}
-
-	csp.graphicsState = gs
+	csp.graphicsState = newGraphicsState()
 
 	csp.handlers = []HandlerEntry{}
 	csp.currentIndex = 0
@@ -93,22 +157,158 @@ func (csp *ContentStreamProcessor) AddHandler(condition HandlerConditionEnum, op
 	csp.handlers = append(csp.handlers, entry)
 }
 
+// AddCategoryHandler adds a handler called for all operators of the category, e.g. OperatorCategoryTextShowing.
+// The handlers are called after the processor has updated the graphics state for the operator.
+func (csp *ContentStreamProcessor) AddCategoryHandler(category OperatorCategory, handler HandlerFunc) {
+	entry := HandlerEntry{}
+	entry.Condition = HandlerConditionEnumCategory
+	entry.Category = category
+	entry.Handler = handler
+	csp.handlers = append(csp.handlers, entry)
+}
+
+// DrawnForm is a Form XObject drawn by a Do operation, processed by ProcessWithForms.
+type DrawnForm struct {
+	// The resource name of the form, and the form.
+	Name    PdfObjectName
+	XObject *XObjectForm
+
+	// The resources the form is processed with.  Forms without resources inherit the resources of the content
+	// stream drawing them.
+	Resources *PdfPageResources
+}
+
+// HandlerFactory registers the handlers on the processor of the content stream of the page (form is nil) or of a
+// Form XObject drawn by it, when the processing of the content stream starts.  The processor of a form starts with
+// the graphics state of the Do operation, with the form matrix concatenated to the CTM, see GetGraphicsState.
+// It returns a function called once the content stream is processed, e.g. for restoring a state saved for the
+// form, or nil.  Returning ErrSkipForm skips the form.
+type HandlerFactory func(processor *ContentStreamProcessor, form *DrawnForm) (func() error, error)
+
+// ErrSkipForm is returned by a HandlerFactory for a Form XObject which is not to be processed.
+var ErrSkipForm = errors.New("Skip form")
+
+// ProcessWithForms processes the operations like Process, and the contents of the Form XObjects drawn with the Do
+// operator, nested up to MaxFormDepth.  The handlers of each content stream are registered by the factory, and the
+// forms are processed after the handlers of the Do operation are called.  Nil resources are processed as empty
+// resources.
+func (csp *ContentStreamProcessor) ProcessWithForms(resources *PdfPageResources, factory HandlerFactory) error {
+	if resources == nil {
+		resources = NewPdfPageResources()
+	}
+	return csp.processWithForms(resources, factory, nil)
+}
+
+// processWithForms processes the content stream of the page or of the form drawn.
+func (csp *ContentStreamProcessor) processWithForms(resources *PdfPageResources, factory HandlerFactory,
+	drawn *DrawnForm) error {
+	done, err := factory(csp, drawn)
+	if err == ErrSkipForm {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	csp.AddCategoryHandler(OperatorCategoryXObject,
+		func(op *ContentStreamOperation, gs GraphicsState, resources *PdfPageResources) error {
+			form, drawn, err := csp.newFormProcessor(op, gs, resources)
+			if err != nil || form == nil {
+				return err
+			}
+			return form.processWithForms(drawn.Resources, factory, drawn)
+		})
+
+	err = csp.Process(resources)
+	if err != nil || done == nil {
+		return err
+	}
+	return done()
+}
+
+// newFormProcessor returns a processor of the contents of the Form XObject drawn by the Do operation op, starting
+// with the graphics state gs of the operation with the form matrix concatenated to the CTM, so the CTM of the
+// handlers maps the form space to the space of the page.  Returns a nil processor if op does not draw a Form
+// XObject.
+func (csp *ContentStreamProcessor) newFormProcessor(op *ContentStreamOperation, gs GraphicsState,
+	resources *PdfPageResources) (*ContentStreamProcessor, *DrawnForm, error) {
+	if len(op.Params) != 1 {
+		return nil, nil, nil
+	}
+	name, ok := op.Params[0].(*PdfObjectName)
+	if !ok {
+		return nil, nil, nil
+	}
+	stream, xtype := resources.GetXObjectByName(*name)
+	if xtype != XObjectTypeForm {
+		return nil, nil, nil
+	}
+	if csp.depth >= MaxFormDepth {
+		common.Log.Debug("Form XObjects nested too deep")
+		return nil, nil, errors.New("Form XObject recursion limit exceeded")
+	}
+
+	xform, err := NewXObjectFormFromStream(stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := xform.GetContentStream()
+	if err != nil {
+		return nil, nil, err
+	}
+	operations, err := NewContentStreamParser(string(content)).Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	drawn := &DrawnForm{Name: *name, XObject: xform, Resources: xform.Resources}
+	if drawn.Resources == nil {
+		drawn.Resources = resources
+	}
+	if arr, ok := TraceToDirectObject(xform.Matrix).(*PdfObjectArray); ok {
+		m, err := NewMatrixFromPdfObjects(*arr)
+		if err == nil {
+			gs.CTM = m.Mult(gs.CTM)
+		}
+	}
+
+	form := NewContentStreamProcessor(*operations)
+	form.graphicsState = gs
+	form.depth = csp.depth + 1
+	return form, drawn, nil
+}
+
+// GetGraphicsState returns the current graphics state, e.g. the initial state of a form processor.
+func (csp *ContentStreamProcessor) GetGraphicsState() GraphicsState {
+	return csp.graphicsState
+}
+
+// GetMarkedContentStack returns the marked content sequences which the current operator is part of, from the
+// outermost to the innermost.  BMC and BDC handlers see the sequence they begin on the stack, and EMC handlers the
+// sequence they end.
+func (csp *ContentStreamProcessor) GetMarkedContentStack() []MarkedContent {
+	return csp.markedContent
+}
+
 func (csp *ContentStreamProcessor) getColorspace(name string, resources *PdfPageResources) (PdfColorspace, error) {
+	// Device colorspaces are replaced by the default colorspaces if defined.
 	switch name {
 	case "DeviceGray":
-		return NewPdfColorspaceDeviceGray(), nil
+		return resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceGray()), nil
 	case "DeviceRGB":
-		return NewPdfColorspaceDeviceRGB(), nil
+		return resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceRGB()), nil
 	case "DeviceCMYK":
-		return NewPdfColorspaceDeviceCMYK(), nil
+		return resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceCMYK()), nil
 	case "Pattern":
 		return NewPdfColorspaceSpecialPattern(), nil
 	}
 
 	// Next check the colorspace dictionary.
-	cs, has := resources.ColorSpace.Colorspaces[name]
-	if has {
-		return cs, nil
+	if resources != nil && resources.ColorSpace != nil {
+		cs, has := resources.ColorSpace.Colorspaces[name]
+		if has {
+			return cs, nil
+		}
 	}
 
 	// Lastly check other potential colormaps.
@@ -195,20 +395,25 @@ func (csp *ContentStreamProcessor) getInitialColor(cs PdfColorspace) (PdfColor,
 
 // Process the entire operations.
 func (this *ContentStreamProcessor) Process(resources *PdfPageResources) error {
-	// Initialize graphics state
-	this.graphicsState.ColorspaceStroking = NewPdfColorspaceDeviceGray()
-	this.graphicsState.ColorspaceNonStroking = NewPdfColorspaceDeviceGray()
-	this.graphicsState.ColorStroking = NewPdfColorDeviceGray(0)
-	this.graphicsState.ColorNonStroking = NewPdfColorDeviceGray(0)
+	// Initialize graphics state, unless inherited by a form.
+	if this.depth == 0 {
+		this.initColors(resources)
+	}
 
 	for _, op := range this.operations {
 		var err error
 
 		// Internal handling.
 		switch op.Operand {
+		case "BMC", "BDC":
+			this.handleCommand_BMC(op)
 		case "q":
 			this.graphicsStack.Push(this.graphicsState)
 		case "Q":
+			if len(this.graphicsStack) == 0 {
+				common.Log.Debug("Unbalanced Q operator - skipping")
+				break
+			}
 			this.graphicsState = this.graphicsStack.Pop()
 
 		// Color operations (Table 74 p. 179)
@@ -236,6 +441,18 @@ func (this *ContentStreamProcessor) Process(resources *PdfPageResources) error {
 			err = this.handleCommand_K(op, resources)
 		case "k":
 			err = this.handleCommand_k(op, resources)
+
+		// Graphics state operations (Table 57 p. 127).
+		case "cm":
+			this.handleCommand_cm(op)
+		case "w", "J", "j", "M", "i", "ri", "d":
+			this.handleCommand_lineParams(op)
+		case "gs":
+			this.handleCommand_gs(op, resources)
+
+		// Text state operations (Table 105 p. 244), and the text state parameters set by TD and ".
+		case "Tc", "Tw", "Tz", "TL", "Tf", "Tr", "Ts", "TD", "\"":
+			this.handleCommand_textState(op)
 		}
 		if err != nil {
 			common.Log.Debug("Processor handling error (%s): %v", op.Operand, err)
@@ -244,23 +461,54 @@ func (this *ContentStreamProcessor) Process(resources *PdfPageResources) error {
 		}
 
 		// Check if have external handler also, and process if so.
+		category, hasCategory := GetOperatorCategory(op.Operand)
 		for _, entry := range this.handlers {
 			var err error
 			if entry.Condition.All() {
 				err = entry.Handler(op, this.graphicsState, resources)
 			} else if entry.Condition.Operand() && op.Operand == entry.Operand {
 				err = entry.Handler(op, this.graphicsState, resources)
+			} else if entry.Condition.Category() && hasCategory && category == entry.Category {
+				err = entry.Handler(op, this.graphicsState, resources)
 			}
 			if err != nil {
 				common.Log.Debug("Processor handler error: %v", err)
 				return err
 			}
 		}
+
+		if op.Operand == "EMC" {
+			if len(this.markedContent) == 0 {
+				common.Log.Debug("Unbalanced EMC operator - skipping")
+			} else {
+				this.markedContent = this.markedContent[:len(this.markedContent)-1]
+			}
+		}
 	}
 
 	return nil
 }
 
+// initColors sets the initial colorspaces and colors: DeviceGray black, or the default gray colorspace if defined.
+func (this *ContentStreamProcessor) initColors(resources *PdfPageResources) {
+	this.graphicsState.ColorspaceStroking = NewPdfColorspaceDeviceGray()
+	this.graphicsState.ColorspaceNonStroking = NewPdfColorspaceDeviceGray()
+	this.graphicsState.ColorStroking = NewPdfColorDeviceGray(0)
+	this.graphicsState.ColorNonStroking = NewPdfColorDeviceGray(0)
+
+	// Default gray colorspace if defined.
+	gray := this.graphicsState.ColorspaceStroking
+	if cs := resources.ResolveDeviceColorspace(gray); cs != gray {
+		color, err := this.getInitialColor(cs)
+		if err == nil {
+			this.graphicsState.ColorspaceStroking = cs
+			this.graphicsState.ColorspaceNonStroking = cs
+			this.graphicsState.ColorStroking = color
+			this.graphicsState.ColorNonStroking = color
+		}
+	}
+}
+
 // CS: Set the current color space for stroking operations.
 func (csp *ContentStreamProcessor) handleCommand_CS(op *ContentStreamOperation, resources *PdfPageResources) error {
 	if len(op.Params) < 1 {
@@ -423,7 +671,7 @@ func (this *ContentStreamProcessor) handleCommand_scn(op *ContentStreamOperation
 // G: Set the stroking colorspace to DeviceGray, and the color to the specified graylevel (range [0-1]).
 // gray G
 func (this *ContentStreamProcessor) handleCommand_G(op *ContentStreamOperation, resources *PdfPageResources) error {
-	cs := NewPdfColorspaceDeviceGray()
+	cs := resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceGray())
 	if len(op.Params) != cs.GetNumComponents() {
 		common.Log.Debug("Invalid number of parameters for SC")
 		common.Log.Debug("Number %d not matching colorspace %T", len(op.Params), cs)
@@ -444,7 +692,7 @@ func (this *ContentStreamProcessor) handleCommand_G(op *ContentStreamOperation,
 // g: Same as G, but for non-stroking colorspace and color (range [0-1]).
 // gray g
 func (this *ContentStreamProcessor) handleCommand_g(op *ContentStreamOperation, resources *PdfPageResources) error {
-	cs := NewPdfColorspaceDeviceGray()
+	cs := resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceGray())
 	if len(op.Params) != cs.GetNumComponents() {
 		common.Log.Debug("Invalid number of parameters for SC")
 		common.Log.Debug("Number %d not matching colorspace %T", len(op.Params), cs)
@@ -465,7 +713,7 @@ func (this *ContentStreamProcessor) handleCommand_g(op *ContentStreamOperation,
 // RG: Sets the stroking colorspace to DeviceRGB and the stroking color to r,g,b. [0-1] ranges.
 // r g b RG
 func (this *ContentStreamProcessor) handleCommand_RG(op *ContentStreamOperation, resources *PdfPageResources) error {
-	cs := NewPdfColorspaceDeviceRGB()
+	cs := resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceRGB())
 	if len(op.Params) != cs.GetNumComponents() {
 		common.Log.Debug("Invalid number of parameters for SC")
 		common.Log.Debug("Number %d not matching colorspace %T", len(op.Params), cs)
@@ -485,7 +733,7 @@ func (this *ContentStreamProcessor) handleCommand_RG(op *ContentStreamOperation,
 
 // rg: Same as RG but for non-stroking colorspace, color.
 func (this *ContentStreamProcessor) handleCommand_rg(op *ContentStreamOperation, resources *PdfPageResources) error {
-	cs := NewPdfColorspaceDeviceRGB()
+	cs := resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceRGB())
 	if len(op.Params) != cs.GetNumComponents() {
 		common.Log.Debug("Invalid number of parameters for SC")
 		common.Log.Debug("Number %d not matching colorspace %T", len(op.Params), cs)
@@ -506,7 +754,7 @@ func (this *ContentStreamProcessor) handleCommand_rg(op *ContentStreamOperation,
 // K: Sets the stroking colorspace to DeviceCMYK and the stroking color to c,m,y,k. [0-1] ranges.
 // c m y k K
 func (this *ContentStreamProcessor) handleCommand_K(op *ContentStreamOperation, resources *PdfPageResources) error {
-	cs := NewPdfColorspaceDeviceCMYK()
+	cs := resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceCMYK())
 	if len(op.Params) != cs.GetNumComponents() {
 		common.Log.Debug("Invalid number of parameters for SC")
 		common.Log.Debug("Number %d not matching colorspace %T", len(op.Params), cs)
@@ -526,7 +774,7 @@ func (this *ContentStreamProcessor) handleCommand_K(op *ContentStreamOperation,
 
 // k: Same as K but for non-stroking colorspace, color.
 func (this *ContentStreamProcessor) handleCommand_k(op *ContentStreamOperation, resources *PdfPageResources) error {
-	cs := NewPdfColorspaceDeviceCMYK()
+	cs := resources.ResolveDeviceColorspace(NewPdfColorspaceDeviceCMYK())
 	if len(op.Params) != cs.GetNumComponents() {
 		common.Log.Debug("Invalid number of parameters for SC")
 		common.Log.Debug("Number %d not matching colorspace %T", len(op.Params), cs)
@@ -543,3 +791,220 @@ func (this *ContentStreamProcessor) handleCommand_k(op *ContentStreamOperation,
 
 	return nil
 }
+
+// BMC/BDC: Begin a marked content sequence.
+// tag BMC, tag properties BDC
+func (this *ContentStreamProcessor) handleCommand_BMC(op *ContentStreamOperation) {
+	entry := MarkedContent{}
+	if len(op.Params) > 0 {
+		if tag, ok := op.Params[0].(*PdfObjectName); ok {
+			entry.Tag = *tag
+		}
+	}
+	if op.Operand == "BDC" && len(op.Params) > 1 {
+		entry.Properties = op.Params[1]
+	}
+	this.markedContent = append(this.markedContent, entry)
+}
+
+// cm: Concatenate the matrix to the current transformation matrix.
+// a b c d e f cm
+func (this *ContentStreamProcessor) handleCommand_cm(op *ContentStreamOperation) {
+	m, err := NewMatrixFromPdfObjects(op.Params)
+	if err != nil {
+		common.Log.Debug("Invalid cm operands, skipping: %v", err)
+		return
+	}
+	this.graphicsState.CTM = m.Mult(this.graphicsState.CTM)
+}
+
+// w, J, j, M, i: Set the line width, cap style, join style, miter limit and flatness.
+// ri: Set the rendering intent.  d: Set the dash pattern.
+func (this *ContentStreamProcessor) handleCommand_lineParams(op *ContentStreamOperation) {
+	gs := &this.graphicsState
+
+	switch op.Operand {
+	case "ri":
+		if len(op.Params) == 1 {
+			if name, ok := op.Params[0].(*PdfObjectName); ok {
+				gs.RenderingIntent = string(*name)
+				return
+			}
+		}
+	case "d":
+		if len(op.Params) == 2 {
+			arr, ok := op.Params[0].(*PdfObjectArray)
+			phase, err := getNumberAsFloat(op.Params[1])
+			if ok && err == nil {
+				dashes, err := arr.ToFloat64Array()
+				if err == nil {
+					gs.DashArray, gs.DashPhase = dashes, phase
+					return
+				}
+			}
+		}
+	default:
+		if len(op.Params) == 1 {
+			val, err := getNumberAsFloat(op.Params[0])
+			if err == nil {
+				switch op.Operand {
+				case "w":
+					gs.LineWidth = val
+				case "J":
+					gs.LineCap = int(val)
+				case "j":
+					gs.LineJoin = int(val)
+				case "M":
+					gs.MiterLimit = val
+				case "i":
+					gs.Flatness = val
+				}
+				return
+			}
+		}
+	}
+
+	common.Log.Debug("Invalid %s operands, skipping: %v", op.Operand, op.Params)
+}
+
+// gs: Set the parameters of the graphics state from an ExtGState resource.
+// name gs
+func (this *ContentStreamProcessor) handleCommand_gs(op *ContentStreamOperation, resources *PdfPageResources) {
+	if len(op.Params) != 1 {
+		common.Log.Debug("Invalid gs operands, skipping")
+		return
+	}
+	name, ok := op.Params[0].(*PdfObjectName)
+	if !ok || resources == nil {
+		common.Log.Debug("Invalid gs operand, skipping")
+		return
+	}
+	obj, has := resources.GetExtGState(*name)
+	if !has {
+		common.Log.Debug("ExtGState %s not found, skipping", *name)
+		return
+	}
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ExtGState %s not a dictionary, skipping", *name)
+		return
+	}
+
+	gs := &this.graphicsState
+	for _, key := range dict.Keys() {
+		val := TraceToDirectObject(dict.Get(key))
+		num, numErr := getNumberAsFloat(val)
+		switch key {
+		case "LW":
+			if numErr == nil {
+				gs.LineWidth = num
+			}
+		case "LC":
+			if numErr == nil {
+				gs.LineCap = int(num)
+			}
+		case "LJ":
+			if numErr == nil {
+				gs.LineJoin = int(num)
+			}
+		case "ML":
+			if numErr == nil {
+				gs.MiterLimit = num
+			}
+		case "FL":
+			if numErr == nil {
+				gs.Flatness = num
+			}
+		case "CA":
+			if numErr == nil {
+				gs.StrokeAlpha = num
+			}
+		case "ca":
+			if numErr == nil {
+				gs.FillAlpha = num
+			}
+		case "RI":
+			if ri, ok := val.(*PdfObjectName); ok {
+				gs.RenderingIntent = string(*ri)
+			}
+		case "D":
+			// [dashArray dashPhase]
+			if arr, ok := val.(*PdfObjectArray); ok && len(*arr) == 2 {
+				dashArr, ok := TraceToDirectObject((*arr)[0]).(*PdfObjectArray)
+				phase, err := getNumberAsFloat(TraceToDirectObject((*arr)[1]))
+				if ok && err == nil {
+					if dashes, err := dashArr.ToFloat64Array(); err == nil {
+						gs.DashArray, gs.DashPhase = dashes, phase
+					}
+				}
+			}
+		}
+	}
+}
+
+// Tc, Tw, Tz, TL, Tr, Ts: Set the text state parameter.  Tf: Set the font and font size.
+// TD: Set the leading to the negated vertical offset.  ": Set the word and character spacing.
+func (this *ContentStreamProcessor) handleCommand_textState(op *ContentStreamOperation) {
+	gs := &this.graphicsState
+
+	switch op.Operand {
+	case "TD":
+		if len(op.Params) == 2 {
+			ty, err := getNumberAsFloat(op.Params[1])
+			if err == nil {
+				gs.Leading = -ty
+				return
+			}
+		}
+		common.Log.Debug("Invalid TD operands, skipping: %v", op.Params)
+		return
+	case "\"":
+		if len(op.Params) == 3 {
+			aw, err1 := getNumberAsFloat(op.Params[0])
+			ac, err2 := getNumberAsFloat(op.Params[1])
+			if err1 == nil && err2 == nil {
+				gs.WordSpacing, gs.CharSpacing = aw, ac
+				return
+			}
+		}
+		common.Log.Debug("Invalid \" operands, skipping: %v", op.Params)
+		return
+	}
+
+	if op.Operand == "Tf" {
+		if len(op.Params) == 2 {
+			name, ok := op.Params[0].(*PdfObjectName)
+			size, err := getNumberAsFloat(op.Params[1])
+			if ok && err == nil {
+				gs.Font, gs.FontSize = *name, size
+				return
+			}
+		}
+		common.Log.Debug("Invalid Tf operands, skipping: %v", op.Params)
+		return
+	}
+
+	if len(op.Params) != 1 {
+		common.Log.Debug("Invalid %s operands, skipping: %v", op.Operand, op.Params)
+		return
+	}
+	val, err := getNumberAsFloat(op.Params[0])
+	if err != nil {
+		common.Log.Debug("Invalid %s operand, skipping: %v", op.Operand, op.Params[0])
+		return
+	}
+	switch op.Operand {
+	case "Tc":
+		gs.CharSpacing = val
+	case "Tw":
+		gs.WordSpacing = val
+	case "Tz":
+		gs.HorizontalScaling = val
+	case "TL":
+		gs.Leading = val
+	case "Tr":
+		gs.TextRenderMode = int(val)
+	case "Ts":
+		gs.TextRise = val
+	}
+}
@@ -0,0 +1,240 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Test that colors set in device colorspaces are interpreted in the default colorspaces of the resources.
+func TestProcessorDefaultColorspaces(t *testing.T) {
+	resources := model.NewPdfPageResources()
+
+	err := resources.SetDefaultRGB(model.NewPdfColorspaceDeviceRGB())
+	if err == nil {
+		t.Fatalf("DefaultRGB should not accept a device colorspace")
+	}
+	iccGray, _ := model.NewPdfColorspaceICCBased(1)
+	iccGray.Alternate = model.NewPdfColorspaceDeviceGray()
+	err = resources.SetDefaultRGB(iccGray)
+	if err == nil {
+		t.Fatalf("DefaultRGB should not accept a single component colorspace")
+	}
+
+	iccRGB, _ := model.NewPdfColorspaceICCBased(3)
+	iccRGB.Alternate = model.NewPdfColorspaceDeviceRGB()
+	err = resources.SetDefaultRGB(iccRGB)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	cstreamParser := NewContentStreamParser("1 0 0 rg 0 0 1 RG 0.5 g /DeviceRGB cs")
+	operations, err := cstreamParser.Parse()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	colorspaces := map[string]model.PdfColorspace{}
+	processor := NewContentStreamProcessor(*operations)
+	processor.AddHandler(HandlerConditionEnumAllOperands, "",
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			switch op.Operand {
+			case "rg", "g", "cs":
+				colorspaces[op.Operand] = gs.ColorspaceNonStroking
+			case "RG":
+				colorspaces[op.Operand] = gs.ColorspaceStroking
+			}
+			return nil
+		})
+	err = processor.Process(resources)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	for _, operand := range []string{"rg", "RG", "cs"} {
+		if colorspaces[operand] != iccRGB {
+			t.Errorf("%s: DefaultRGB not used (%T)", operand, colorspaces[operand])
+		}
+	}
+	// No DefaultGray defined.
+	if _, isGray := colorspaces["g"].(*model.PdfColorspaceDeviceGray); !isGray {
+		t.Errorf("g: expected DeviceGray (%T)", colorspaces["g"])
+	}
+}
+
+// Test the graphics state tracking, category handlers and the marked content stack.
+func TestProcessorGraphicsState(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	extGState := core.MakeDict()
+	extGState.Set("LW", core.MakeFloat(3))
+	extGState.Set("ca", core.MakeFloat(0.5))
+	err := resources.AddExtGState("GS1", extGState)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	contents := `/Span <</ActualText (x)>> BDC
+q 2 0 0 2 10 20 cm 0.5 w [3 1] 0 d /GS1 gs
+BT /F1 12 Tf 2 Tr (a) Tj ET
+0 0 m 10 10 l S
+Q
+EMC
+BT (b) Tj ET`
+	cstreamParser := NewContentStreamParser(contents)
+	operations, err := cstreamParser.Parse()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	processor := NewContentStreamProcessor(*operations)
+	shown := []GraphicsState{}
+	tags := []string{}
+	processor.AddCategoryHandler(OperatorCategoryTextShowing,
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			shown = append(shown, gs)
+			tag := ""
+			for _, mc := range processor.GetMarkedContentStack() {
+				tag += string(mc.Tag)
+			}
+			tags = append(tags, tag)
+			return nil
+		})
+	painted := 0
+	processor.AddCategoryHandler(OperatorCategoryPathPainting,
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			painted++
+			if gs.LineWidth != 3 || len(gs.DashArray) != 2 || gs.FillAlpha != 0.5 {
+				t.Errorf("Invalid line parameters: %v %v %v", gs.LineWidth, gs.DashArray, gs.FillAlpha)
+			}
+			return nil
+		})
+	err = processor.Process(resources)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if len(shown) != 2 || painted != 1 {
+		t.Fatalf("Invalid number of handler calls: %d, %d", len(shown), painted)
+	}
+	gs := shown[0]
+	if gs.CTM != NewMatrix(2, 0, 0, 2, 10, 20) || gs.Font != "F1" || gs.FontSize != 12 || gs.TextRenderMode != 2 {
+		t.Errorf("Invalid state inside q/Q: %+v", gs)
+	}
+	if tags[0] != "Span" {
+		t.Errorf("Invalid marked content stack: %q", tags[0])
+	}
+
+	// The state is restored by Q and the marked content ended by EMC.
+	gs = shown[1]
+	if gs.CTM != IdentityMatrix() || gs.LineWidth != 1 || gs.FillAlpha != 1 || gs.TextRenderMode != 0 {
+		t.Errorf("Invalid state after Q: %+v", gs)
+	}
+	if tags[1] != "" {
+		t.Errorf("Marked content not ended: %q", tags[1])
+	}
+}
+
+// Test that Form XObjects are processed with the graphics state of the Do operation and the form matrix, and that
+// cyclic forms are stopped.
+func TestProcessorForms(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	xform := model.NewXObjectForm()
+	xform.Matrix = core.MakeArrayFromFloats([]float64{1, 0, 0, 1, 5, 5})
+	err := xform.SetContentStream([]byte("q 2 w Q BT 14 TL 1 2 (x) \" 0 -10 TD (y) Tj ET"), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetXObjectFormByName("Fm1", xform)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	operations, err := NewContentStreamParser("q 2 0 0 2 0 0 cm 1 0 0 rg /Fm1 Do Q").Parse()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	processor := NewContentStreamProcessor(*operations)
+	shown := []GraphicsState{}
+	done := []string{}
+	err = processor.ProcessWithForms(resources, func(processor *ContentStreamProcessor, form *DrawnForm) (func() error, error) {
+		if form == nil {
+			return func() error {
+				done = append(done, "page")
+				return nil
+			}, nil
+		}
+		if form.Name != "Fm1" || form.Resources != resources {
+			t.Errorf("Invalid form %+v", form)
+		}
+		processor.AddCategoryHandler(OperatorCategoryTextShowing,
+			func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+				shown = append(shown, gs)
+				return nil
+			})
+		return func() error {
+			done = append(done, string(form.Name))
+			return nil
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if strings.Join(done, " ") != "Fm1 page" {
+		t.Errorf("Invalid processing order %v", done)
+	}
+	if len(shown) != 2 {
+		t.Fatalf("Invalid number of handler calls: %d", len(shown))
+	}
+	gs := shown[0]
+	if gs.CTM != NewMatrix(2, 0, 0, 2, 10, 10) || gs.LineWidth != 1 {
+		t.Errorf("Invalid form state: %+v", gs)
+	}
+	if rgb, ok := gs.ColorNonStroking.(*model.PdfColorDeviceRGB); !ok || rgb.R() != 1 {
+		t.Errorf("Fill color not inherited: %v", gs.ColorNonStroking)
+	}
+	if gs.WordSpacing != 1 || gs.CharSpacing != 2 || gs.Leading != 14 {
+		t.Errorf("Invalid text state for \": %+v", gs)
+	}
+	if shown[1].Leading != 10 {
+		t.Errorf("Invalid leading after TD: %v", shown[1].Leading)
+	}
+
+	// A form drawing itself.
+	err = xform.SetContentStream([]byte("/Fm1 Do"), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetXObjectFormByName("Fm1", xform)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	operations, err = NewContentStreamParser("/Fm1 Do").Parse()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	noHandlers := func(processor *ContentStreamProcessor, form *DrawnForm) (func() error, error) {
+		return nil, nil
+	}
+	if err = NewContentStreamProcessor(*operations).ProcessWithForms(resources, noHandlers); err == nil {
+		t.Errorf("Missing error for a cyclic form")
+	}
+
+	// Skipped forms are not processed.
+	skipAll := func(processor *ContentStreamProcessor, form *DrawnForm) (func() error, error) {
+		if form != nil {
+			return nil, ErrSkipForm
+		}
+		return nil, nil
+	}
+	if err = NewContentStreamProcessor(*operations).ProcessWithForms(resources, skipAll); err != nil {
+		t.Errorf("Error: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Maximum depth of the forms without resources whose content is scanned for the resources of the page they use.
+const maxResourceFormDepth = 10
+
+// resourceUsage is the set of the names of the resources used by content streams, by resource category.
+type resourceUsage map[PdfObjectName]map[PdfObjectName]bool
+
+// add marks the resource of the category as used if the operand is a name.
+func (usage resourceUsage) add(category PdfObjectName, obj PdfObject) {
+	name, ok := obj.(*PdfObjectName)
+	if !ok {
+		return
+	}
+	if usage[category] == nil {
+		usage[category] = map[PdfObjectName]bool{}
+	}
+	usage[category][*name] = true
+}
+
+// collect marks the resources used by the operations.  The forms without resources of their own use those of the
+// page, and are scanned too.
+func (usage resourceUsage) collect(operations ContentStreamOperations, resources *model.PdfPageResources, depth int) {
+	for _, op := range operations {
+		params := op.Params
+		switch op.Operand {
+		case "Tf":
+			if len(params) == 2 {
+				usage.add("Font", params[0])
+			}
+		case "Do":
+			if len(params) == 1 {
+				usage.add("XObject", params[0])
+				if name, ok := params[0].(*PdfObjectName); ok {
+					usage.collectForm(*name, resources, depth)
+				}
+			}
+		case "gs":
+			if len(params) == 1 {
+				usage.add("ExtGState", params[0])
+			}
+		case "cs", "CS":
+			if len(params) == 1 {
+				usage.add("ColorSpace", params[0])
+			}
+		case "scn", "SCN":
+			if len(params) > 0 {
+				usage.add("Pattern", params[len(params)-1])
+			}
+		case "sh":
+			if len(params) == 1 {
+				usage.add("Shading", params[0])
+			}
+		case "BDC", "DP":
+			if len(params) == 2 {
+				usage.add("Properties", params[1])
+			}
+		case "BI":
+			if len(params) == 1 {
+				if img, ok := params[0].(*ContentStreamInlineImage); ok {
+					usage.add("ColorSpace", img.ColorSpace)
+				}
+			}
+		}
+	}
+}
+
+// collectForm marks the resources used by the form XObject with the name if it has no resources of its own.
+func (usage resourceUsage) collectForm(name PdfObjectName, resources *model.PdfPageResources, depth int) {
+	if resources == nil || depth >= maxResourceFormDepth {
+		return
+	}
+	stream, xtype := resources.GetXObjectByName(name)
+	if stream == nil || xtype != model.XObjectTypeForm || stream.PdfObjectDictionary.Get("Resources") != nil {
+		return
+	}
+	content, err := DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("Unable to decode form %s: %v", name, err)
+		return
+	}
+	operations, err := NewContentStreamParser(string(content)).Parse()
+	if err != nil {
+		common.Log.Debug("Unable to parse form %s: %v", name, err)
+		return
+	}
+	usage.collect(*operations, resources, depth+1)
+}
+
+// RemoveUnusedResources removes the fonts, XObjects, graphics states, color spaces, patterns, shadings and marked
+// content properties which the content of the page does not use from its resources, e.g. after editing or
+// redacting the content.  The resource dictionaries are replaced rather than modified, as they can be shared with
+// other pages.  Returns the number of resources removed.
+func RemoveUnusedResources(page *model.PdfPage) (int, error) {
+	resources := page.Resources
+	if resources == nil {
+		return 0, nil
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		return 0, err
+	}
+	operations, err := NewContentStreamParser(contents).Parse()
+	if err != nil {
+		common.Log.Debug("Unable to parse content stream: %v", err)
+		return 0, err
+	}
+	usage := resourceUsage{}
+	usage.collect(*operations, resources, 0)
+
+	removed := 0
+	filter := func(obj PdfObject, category PdfObjectName) PdfObject {
+		dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+		if !ok {
+			return obj
+		}
+		filtered := MakeDict()
+		for _, key := range dict.Keys() {
+			if usage[category][key] {
+				filtered.Set(key, dict.Get(key))
+			}
+		}
+		if len(filtered.Keys()) == len(dict.Keys()) {
+			return obj
+		}
+		removed += len(dict.Keys()) - len(filtered.Keys())
+		return filtered
+	}
+	resources.Font = filter(resources.Font, "Font")
+	resources.XObject = filter(resources.XObject, "XObject")
+	resources.ExtGState = filter(resources.ExtGState, "ExtGState")
+	resources.Pattern = filter(resources.Pattern, "Pattern")
+	resources.Shading = filter(resources.Shading, "Shading")
+	resources.Properties = filter(resources.Properties, "Properties")
+
+	// The default color spaces are used by the device color spaces.
+	if cs := resources.ColorSpace; cs != nil {
+		colorspaces := model.NewPdfPageResourcesColorspaces()
+		for _, name := range cs.Names {
+			if usage["ColorSpace"][PdfObjectName(name)] || strings.HasPrefix(name, "Default") {
+				colorspaces.Set(PdfObjectName(name), cs.Colorspaces[name])
+			}
+		}
+		if len(colorspaces.Names) != len(cs.Names) {
+			removed += len(cs.Names) - len(colorspaces.Names)
+			resources.ColorSpace = colorspaces
+		}
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// resourceKeys returns the sorted names of the resources of a category.
+func resourceKeys(obj core.PdfObject) string {
+	names := []string{}
+	if dict, ok := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary); ok {
+		for _, key := range dict.Keys() {
+			names = append(names, string(key))
+		}
+	}
+	sort.Strings(names)
+	return fmt.Sprint(names)
+}
+
+func TestRemoveUnusedResources(t *testing.T) {
+	page := model.NewPdfPage()
+	page.Resources = model.NewPdfPageResources()
+	resources := page.Resources
+
+	fonts := core.MakeDict()
+	for _, name := range []core.PdfObjectName{"F1", "F2", "F3"} {
+		fonts.Set(name, core.MakeDict())
+	}
+	resources.Font = core.MakeIndirectObject(fonts)
+
+	// The form without resources uses the font F2 of the page.
+	form := model.NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, 10, 10})
+	if err := form.SetContentStream([]byte("BT /F2 10 Tf (b) Tj ET"), nil); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := resources.SetXObjectFormByName("Fm1", form); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	unused := model.NewXObjectForm()
+	unused.BBox = core.MakeArrayFromFloats([]float64{0, 0, 10, 10})
+	if err := resources.SetXObjectFormByName("Fm2", unused); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	resources.AddExtGState("GS1", core.MakeDict())
+	resources.AddExtGState("GS2", core.MakeDict())
+	resources.SetColorspaceByName("CS1", model.NewPdfColorspaceDeviceGray())
+	resources.SetColorspaceByName("CS2", model.NewPdfColorspaceDeviceRGB())
+	resources.SetColorspaceByName("DefaultRGB", model.NewPdfColorspaceDeviceRGB())
+	properties := core.MakeDict()
+	properties.Set("MC0", core.MakeDict())
+	properties.Set("MC1", core.MakeDict())
+	resources.Properties = properties
+
+	contents := "/GS1 gs /CS1 cs 0.5 sc BT /F1 12 Tf (a) Tj ET /Fm1 Do /OC /MC0 BDC EMC"
+	if err := page.SetContentStreams([]string{contents}, nil); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	removed, err := RemoveUnusedResources(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if removed != 5 {
+		t.Errorf("Removed %d resources, expected 5", removed)
+	}
+	for _, tc := range []struct {
+		category core.PdfObject
+		expected string
+	}{
+		{resources.Font, "[F1 F2]"},
+		{resources.XObject, "[Fm1]"},
+		{resources.ExtGState, "[GS1]"},
+		{resources.ColorSpace.ToPdfObject(), "[CS1 DefaultRGB]"},
+		{resources.Properties, "[MC0]"},
+	} {
+		if keys := resourceKeys(tc.category); keys != tc.expected {
+			t.Errorf("Resources %s, expected %s", keys, tc.expected)
+		}
+	}
+	// The dictionaries, possibly shared, are not modified.
+	if keys := resourceKeys(fonts); keys != "[F1 F2 F3]" {
+		t.Errorf("Original fonts modified: %s", keys)
+	}
+
+	if removed, err = RemoveUnusedResources(page); err != nil || removed != 0 {
+		t.Errorf("Removed %d resources again (%v)", removed, err)
+	}
+}
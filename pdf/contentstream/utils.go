@@ -13,6 +13,19 @@ import (
 	"github.com/unidoc/unidoc/pdf/model"
 )
 
+// getNumberAsFloat can retrieve numeric values from PdfObject (both integer/float).
+func getNumberAsFloat(obj core.PdfObject) (float64, error) {
+	if fObj, ok := obj.(*core.PdfObjectFloat); ok {
+		return float64(*fObj), nil
+	}
+
+	if iObj, ok := obj.(*core.PdfObjectInteger); ok {
+		return float64(*iObj), nil
+	}
+
+	return 0, errors.New("Not a number")
+}
+
 func makeParamsFromFloats(vals []float64) []core.PdfObject {
 	params := []core.PdfObject{}
 	for _, val := range vals {
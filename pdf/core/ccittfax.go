@@ -0,0 +1,645 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// CCITT Group 3 and Group 4 facsimile decoding and encoding (ITU-T T.4 and T.6).
+
+// ccittCode is an entry of a CCITT code table: the code bits as a string and the run length or mode it represents.
+type ccittCode struct {
+	bits string
+	val  int
+}
+
+// Terminating and makeup codes for white runs.
+var ccittWhiteCodes = []ccittCode{
+	{"00110101", 0}, {"000111", 1}, {"0111", 2}, {"1000", 3}, {"1011", 4}, {"1100", 5}, {"1110", 6},
+	{"1111", 7}, {"10011", 8}, {"10100", 9}, {"00111", 10}, {"01000", 11}, {"001000", 12}, {"000011", 13},
+	{"110100", 14}, {"110101", 15}, {"101010", 16}, {"101011", 17}, {"0100111", 18}, {"0001100", 19},
+	{"0001000", 20}, {"0010111", 21}, {"0000011", 22}, {"0000100", 23}, {"0101000", 24}, {"0101011", 25},
+	{"0010011", 26}, {"0100100", 27}, {"0011000", 28}, {"00000010", 29}, {"00000011", 30}, {"00011010", 31},
+	{"00011011", 32}, {"00010010", 33}, {"00010011", 34}, {"00010100", 35}, {"00010101", 36}, {"00010110", 37},
+	{"00010111", 38}, {"00101000", 39}, {"00101001", 40}, {"00101010", 41}, {"00101011", 42}, {"00101100", 43},
+	{"00101101", 44}, {"00000100", 45}, {"00000101", 46}, {"00001010", 47}, {"00001011", 48}, {"01010010", 49},
+	{"01010011", 50}, {"01010100", 51}, {"01010101", 52}, {"00100100", 53}, {"00100101", 54}, {"01011000", 55},
+	{"01011001", 56}, {"01011010", 57}, {"01011011", 58}, {"01001010", 59}, {"01001011", 60}, {"00110010", 61},
+	{"00110011", 62}, {"00110100", 63},
+	{"11011", 64}, {"10010", 128}, {"010111", 192}, {"0110111", 256}, {"00110110", 320}, {"00110111", 384},
+	{"01100100", 448}, {"01100101", 512}, {"01101000", 576}, {"01100111", 640}, {"011001100", 704},
+	{"011001101", 768}, {"011010010", 832}, {"011010011", 896}, {"011010100", 960}, {"011010101", 1024},
+	{"011010110", 1088}, {"011010111", 1152}, {"011011000", 1216}, {"011011001", 1280}, {"011011010", 1344},
+	{"011011011", 1408}, {"010011000", 1472}, {"010011001", 1536}, {"010011010", 1600}, {"011000", 1664},
+	{"010011011", 1728},
+}
+
+// Terminating and makeup codes for black runs.
+var ccittBlackCodes = []ccittCode{
+	{"0000110111", 0}, {"010", 1}, {"11", 2}, {"10", 3}, {"011", 4}, {"0011", 5}, {"0010", 6}, {"00011", 7},
+	{"000101", 8}, {"000100", 9}, {"0000100", 10}, {"0000101", 11}, {"0000111", 12}, {"00000100", 13},
+	{"00000111", 14}, {"000011000", 15}, {"0000010111", 16}, {"0000011000", 17}, {"0000001000", 18},
+	{"00001100111", 19}, {"00001101000", 20}, {"00001101100", 21}, {"00000110111", 22}, {"00000101000", 23},
+	{"00000010111", 24}, {"00000011000", 25}, {"000011001010", 26}, {"000011001011", 27}, {"000011001100", 28},
+	{"000011001101", 29}, {"000001101000", 30}, {"000001101001", 31}, {"000001101010", 32},
+	{"000001101011", 33}, {"000011010010", 34}, {"000011010011", 35}, {"000011010100", 36},
+	{"000011010101", 37}, {"000011010110", 38}, {"000011010111", 39}, {"000001101100", 40},
+	{"000001101101", 41}, {"000011011010", 42}, {"000011011011", 43}, {"000001010100", 44},
+	{"000001010101", 45}, {"000001010110", 46}, {"000001010111", 47}, {"000001100100", 48},
+	{"000001100101", 49}, {"000001010010", 50}, {"000001010011", 51}, {"000000100100", 52},
+	{"000000110111", 53}, {"000000111000", 54}, {"000000100111", 55}, {"000000101000", 56},
+	{"000001011000", 57}, {"000001011001", 58}, {"000000101011", 59}, {"000000101100", 60},
+	{"000001011010", 61}, {"000001100110", 62}, {"000001100111", 63},
+	{"0000001111", 64}, {"000011001000", 128}, {"000011001001", 192}, {"000001011011", 256},
+	{"000000110011", 320}, {"000000110100", 384}, {"000000110101", 448}, {"0000001101100", 512},
+	{"0000001101101", 576}, {"0000001001010", 640}, {"0000001001011", 704}, {"0000001001100", 768},
+	{"0000001001101", 832}, {"0000001110010", 896}, {"0000001110011", 960}, {"0000001110100", 1024},
+	{"0000001110101", 1088}, {"0000001110110", 1152}, {"0000001110111", 1216}, {"0000001010010", 1280},
+	{"0000001010011", 1344}, {"0000001010100", 1408}, {"0000001010101", 1472}, {"0000001011010", 1536},
+	{"0000001011011", 1600}, {"0000001100100", 1664}, {"0000001100101", 1728},
+}
+
+// Extended makeup codes shared by white and black runs.
+var ccittExtendedCodes = []ccittCode{
+	{"00000001000", 1792}, {"00000001100", 1856}, {"00000001101", 1920}, {"000000010010", 1984},
+	{"000000010011", 2048}, {"000000010100", 2112}, {"000000010101", 2176}, {"000000010110", 2240},
+	{"000000010111", 2304}, {"000000011100", 2368}, {"000000011101", 2432}, {"000000011110", 2496},
+	{"000000011111", 2560},
+}
+
+// Two-dimensional coding modes.
+const (
+	ccittModePass = iota
+	ccittModeHorizontal
+	ccittModeV0
+	ccittModeVR1
+	ccittModeVR2
+	ccittModeVR3
+	ccittModeVL1
+	ccittModeVL2
+	ccittModeVL3
+	ccittModeExtension
+)
+
+var ccittModeCodes = []ccittCode{
+	{"0001", ccittModePass}, {"001", ccittModeHorizontal}, {"1", ccittModeV0}, {"011", ccittModeVR1},
+	{"000011", ccittModeVR2}, {"0000011", ccittModeVR3}, {"010", ccittModeVL1}, {"000010", ccittModeVL2},
+	{"0000010", ccittModeVL3}, {"0000001", ccittModeExtension},
+}
+
+// Vertical mode offsets of a1 relative to b1.
+var ccittVerticalOffsets = map[int]int{
+	ccittModeV0: 0, ccittModeVR1: 1, ccittModeVR2: 2, ccittModeVR3: 3, ccittModeVL1: -1, ccittModeVL2: -2,
+	ccittModeVL3: -3,
+}
+
+// ccittCodeKey identifies a code by its length and value.
+type ccittCodeKey struct {
+	length int
+	value  uint32
+}
+
+// ccittTable is a lookup table for decoding codes.
+type ccittTable map[ccittCodeKey]int
+
+func makeCCITTTable(codeLists ...[]ccittCode) ccittTable {
+	table := ccittTable{}
+	for _, codes := range codeLists {
+		for _, code := range codes {
+			value := uint32(0)
+			for _, b := range code.bits {
+				value = value<<1 | uint32(b-'0')
+			}
+			table[ccittCodeKey{len(code.bits), value}] = code.val
+		}
+	}
+	return table
+}
+
+var (
+	ccittWhiteTable = makeCCITTTable(ccittWhiteCodes, ccittExtendedCodes)
+	ccittBlackTable = makeCCITTTable(ccittBlackCodes, ccittExtendedCodes)
+	ccittModeTable  = makeCCITTTable(ccittModeCodes)
+)
+
+// Maximum length of the codes in bits.
+const ccittMaxCodeLength = 13
+
+var errCCITTInvalidCode = errors.New("Invalid CCITT code")
+
+// ccittBitReader reads the encoded data bit by bit, most significant bit first.
+type ccittBitReader struct {
+	data []byte
+	pos  int // Bit position.
+}
+
+func (r *ccittBitReader) eof() bool {
+	return r.pos >= len(r.data)*8
+}
+
+// peek returns the next n bits (zero padded past the end of data) without consuming them.
+func (r *ccittBitReader) peek(n int) uint32 {
+	value := uint32(0)
+	for i := 0; i < n; i++ {
+		pos := r.pos + i
+		bit := uint32(0)
+		if pos < len(r.data)*8 {
+			bit = uint32(r.data[pos/8]>>uint(7-pos%8)) & 1
+		}
+		value = value<<1 | bit
+	}
+	return value
+}
+
+func (r *ccittBitReader) skip(n int) {
+	r.pos += n
+}
+
+// align moves to the next byte boundary.
+func (r *ccittBitReader) align() {
+	r.pos = (r.pos + 7) / 8 * 8
+}
+
+// readCode reads a code from the table.
+func (r *ccittBitReader) readCode(table ccittTable) (int, error) {
+	for length := 1; length <= ccittMaxCodeLength; length++ {
+		if r.pos+length > len(r.data)*8 {
+			break
+		}
+		if val, has := table[ccittCodeKey{length, r.peek(length)}]; has {
+			r.skip(length)
+			return val, nil
+		}
+	}
+	return 0, errCCITTInvalidCode
+}
+
+// readRun reads a run length: makeup codes followed by a terminating code.
+func (r *ccittBitReader) readRun(black bool) (int, error) {
+	table := ccittWhiteTable
+	if black {
+		table = ccittBlackTable
+	}
+
+	run := 0
+	for {
+		val, err := r.readCode(table)
+		if err != nil {
+			return run, err
+		}
+		run += val
+		if val < 64 {
+			return run, nil
+		}
+	}
+}
+
+// skipEOL skips an end of line code (000000000001) preceded by any number of fill zero bits.
+// Returns true if an EOL was found.
+func (r *ccittBitReader) skipEOL() bool {
+	zeros := 0
+	for r.pos+zeros < len(r.data)*8 && r.peekAt(zeros) == 0 {
+		zeros++
+	}
+	if zeros < 11 || r.pos+zeros >= len(r.data)*8 {
+		return false
+	}
+	r.skip(zeros + 1)
+	return true
+}
+
+// peekAt returns the bit at offset from the current position.
+func (r *ccittBitReader) peekAt(offset int) byte {
+	pos := r.pos + offset
+	return (r.data[pos/8] >> uint(7-pos%8)) & 1
+}
+
+// ccittDecode decodes CCITT encoded data with the parameters of the encoder.  Returns the image data with 1 bit per
+// pixel, each row padded to a whole byte.
+func ccittDecode(encoded []byte, params *CCITTFaxEncoder) ([]byte, error) {
+	columns := params.Columns
+	if columns <= 0 {
+		return nil, errors.New("Invalid CCITT columns")
+	}
+	rowBytes := (columns + 7) / 8
+
+	r := &ccittBitReader{data: encoded}
+	decoded := []byte{}
+
+	// Changing elements of the reference line (all white for the first line).
+	ref := []int{}
+	numRows := 0
+	for params.Rows <= 0 || numRows < params.Rows {
+		if params.K < 0 {
+			if numRows > 0 && params.EncodedByteAlign {
+				r.align()
+			}
+			// End of facsimile block: 2 EOLs.
+			if r.peek(24) == 0x001001 {
+				break
+			}
+		} else {
+			foundEOL := r.skipEOL()
+			if foundEOL && (r.peek(12) == 0x001 || params.K > 0 && r.peek(13) == 0x1001) {
+				// Return to control: multiple EOLs (each followed by a tag bit in mixed mode).
+				break
+			}
+			if params.EncodedByteAlign && (foundEOL || numRows > 0) {
+				// Encoded lines begin on a byte boundary.
+				r.align()
+			}
+		}
+		if r.eof() {
+			break
+		}
+
+		twoDimensional := params.K < 0
+		if params.K > 0 {
+			// Tag bit: 1 for one-dimensional, 0 for two-dimensional coding of the line.
+			twoDimensional = r.peek(1) == 0
+			r.skip(1)
+		}
+
+		var changes []int
+		var err error
+		if twoDimensional {
+			changes, err = ccittDecodeRow2D(r, ref, columns)
+		} else {
+			changes, err = ccittDecodeRow1D(r, columns)
+		}
+		if err != nil {
+			common.Log.Debug("ERROR: CCITT decoding failed at row %d: %v", numRows, err)
+			if numRows == 0 {
+				return nil, err
+			}
+			break
+		}
+
+		decoded = append(decoded, ccittRowBits(changes, columns, params.BlackIs1)...)
+		ref = changes
+		numRows++
+	}
+
+	// Fill missing rows with white.
+	for params.Rows > 0 && numRows < params.Rows {
+		decoded = append(decoded, ccittRowBits(nil, columns, params.BlackIs1)...)
+		numRows++
+	}
+
+	common.Log.Trace("CCITT decoded %d rows of %d bytes", numRows, rowBytes)
+	return decoded, nil
+}
+
+// ccittDecodeRow1D decodes a one-dimensionally (modified Huffman) coded row.  Returns the changing elements.
+func ccittDecodeRow1D(r *ccittBitReader, columns int) ([]int, error) {
+	changes := []int{}
+	pos := 0
+	black := false
+	for pos < columns {
+		run, err := r.readRun(black)
+		if err != nil {
+			return nil, err
+		}
+		pos += run
+		if pos > columns {
+			pos = columns
+		}
+		changes = append(changes, pos)
+		black = !black
+	}
+	return changes, nil
+}
+
+// ccittDecodeRow2D decodes a two-dimensionally coded row with respect to the changing elements of the reference
+// line.  Returns the changing elements of the row.
+func ccittDecodeRow2D(r *ccittBitReader, ref []int, columns int) ([]int, error) {
+	changes := []int{}
+	a0 := -1
+	black := false
+
+	for a0 < columns {
+		mode, err := r.readCode(ccittModeTable)
+		if err != nil {
+			return nil, err
+		}
+
+		b1, b2 := ccittFindB(ref, a0, black, columns)
+		switch mode {
+		case ccittModePass:
+			a0 = b2
+		case ccittModeHorizontal:
+			start := a0
+			if start < 0 {
+				start = 0
+			}
+			run1, err := r.readRun(black)
+			if err != nil {
+				return nil, err
+			}
+			run2, err := r.readRun(!black)
+			if err != nil {
+				return nil, err
+			}
+			a1 := ccittClamp(start+run1, start, columns)
+			a2 := ccittClamp(a1+run2, a1, columns)
+			changes = append(changes, a1, a2)
+			a0 = a2
+		case ccittModeExtension:
+			common.Log.Debug("ERROR: Unsupported CCITT uncompressed mode")
+			return nil, errCCITTInvalidCode
+		default:
+			a1 := ccittClamp(b1+ccittVerticalOffsets[mode], a0, columns)
+			if a1 < 0 {
+				a1 = 0
+			}
+			changes = append(changes, a1)
+			a0 = a1
+			black = !black
+		}
+	}
+	return changes, nil
+}
+
+// ccittFindB finds the changing elements b1 and b2 on the reference line: b1 is the first changing element to the
+// right of a0 with the opposite color of the color of a0, and b2 the next changing element following b1.
+func ccittFindB(ref []int, a0 int, black bool, columns int) (int, int) {
+	// Changing elements at even indices are changes to black, odd ones changes to white.
+	i := 0
+	for i < len(ref) && (ref[i] <= a0 || (i%2 == 1) != black) {
+		i++
+	}
+	b1 := columns
+	if i < len(ref) {
+		b1 = ref[i]
+	}
+	b2 := columns
+	if i+1 < len(ref) {
+		b2 = ref[i+1]
+	}
+	return b1, b2
+}
+
+func ccittClamp(val, min, max int) int {
+	if val < min {
+		return min
+	}
+	if val > max {
+		return max
+	}
+	return val
+}
+
+// ccittRowBits returns the packed bits of a row given by its changing elements.
+func ccittRowBits(changes []int, columns int, blackIs1 bool) []byte {
+	row := make([]byte, (columns+7)/8)
+	if !blackIs1 {
+		// White pixels are 1.
+		for i := range row {
+			row[i] = 0xff
+		}
+	}
+
+	for i := 0; i < len(changes); i += 2 {
+		start := changes[i]
+		end := columns
+		if i+1 < len(changes) {
+			end = changes[i+1]
+		}
+		for x := start; x < end && x < columns; x++ {
+			if blackIs1 {
+				row[x/8] |= 0x80 >> uint(x%8)
+			} else {
+				row[x/8] &^= 0x80 >> uint(x%8)
+			}
+		}
+	}
+	return row
+}
+
+// ccittEncodeTable maps run lengths or modes to their code bits.
+type ccittEncodeTable map[int]string
+
+func makeCCITTEncodeTable(codeLists ...[]ccittCode) ccittEncodeTable {
+	table := ccittEncodeTable{}
+	for _, codes := range codeLists {
+		for _, code := range codes {
+			table[code.val] = code.bits
+		}
+	}
+	return table
+}
+
+var (
+	ccittWhiteEncodeTable = makeCCITTEncodeTable(ccittWhiteCodes, ccittExtendedCodes)
+	ccittBlackEncodeTable = makeCCITTEncodeTable(ccittBlackCodes, ccittExtendedCodes)
+	ccittModeEncodeTable  = makeCCITTEncodeTable(ccittModeCodes)
+)
+
+// End of line code.
+const ccittEOL = "000000000001"
+
+// ccittBitWriter writes the encoded data bit by bit, most significant bit first.
+type ccittBitWriter struct {
+	data []byte
+	pos  int // Bit position.
+}
+
+func (w *ccittBitWriter) writeBits(bits string) {
+	for _, b := range bits {
+		if w.pos%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if b == '1' {
+			w.data[w.pos/8] |= 0x80 >> uint(w.pos%8)
+		}
+		w.pos++
+	}
+}
+
+// align moves to the next byte boundary, padding with 0 bits.
+func (w *ccittBitWriter) align() {
+	w.pos = (w.pos + 7) / 8 * 8
+}
+
+// writeEOL writes an end of line code, preceded by fill bits so that it ends on a byte boundary if aligned.
+func (w *ccittBitWriter) writeEOL(aligned bool) {
+	for aligned && (w.pos+len(ccittEOL))%8 != 0 {
+		w.writeBits("0")
+	}
+	w.writeBits(ccittEOL)
+}
+
+// writeRun writes a run length: makeup codes followed by a terminating code.
+func (w *ccittBitWriter) writeRun(run int, black bool) {
+	table := ccittWhiteEncodeTable
+	if black {
+		table = ccittBlackEncodeTable
+	}
+	for run >= 2560+64 {
+		w.writeBits(table[2560])
+		run -= 2560
+	}
+	if run >= 64 {
+		w.writeBits(table[run-run%64])
+		run %= 64
+	}
+	w.writeBits(table[run])
+}
+
+// ccittEncode encodes image data with 1 bit per pixel, each row padded to a whole byte, with the parameters of the
+// encoder.
+func ccittEncode(data []byte, params *CCITTFaxEncoder) ([]byte, error) {
+	columns := params.Columns
+	if columns <= 0 {
+		return nil, errors.New("Invalid CCITT columns")
+	}
+	rowBytes := (columns + 7) / 8
+	rows := len(data) / rowBytes
+	if params.Rows > rows {
+		common.Log.Debug("ERROR: CCITT image data too short (%d bytes for %d rows)", len(data), params.Rows)
+		return nil, errors.New("Image data too short")
+	}
+	if params.Rows > 0 {
+		rows = params.Rows
+	}
+
+	w := &ccittBitWriter{}
+	ref := []int{}
+	for y := 0; y < rows; y++ {
+		changes := ccittRowChanges(data[y*rowBytes:(y+1)*rowBytes], columns, params.BlackIs1)
+
+		twoDimensional := params.K < 0
+		if params.K < 0 {
+			if y > 0 && params.EncodedByteAlign {
+				w.align()
+			}
+		} else {
+			if params.EndOfLine {
+				w.writeEOL(params.EncodedByteAlign)
+			} else if y > 0 && params.EncodedByteAlign {
+				w.align()
+			}
+			if params.K > 0 {
+				// Every K-th row is coded one-dimensionally.
+				twoDimensional = y%params.K != 0
+				if twoDimensional {
+					w.writeBits("0")
+				} else {
+					w.writeBits("1")
+				}
+			}
+		}
+
+		if twoDimensional {
+			ccittEncodeRow2D(w, changes, ref, columns)
+		} else {
+			ccittEncodeRow1D(w, changes, columns)
+		}
+		ref = changes
+	}
+
+	if params.EndOfBlock {
+		if params.K < 0 {
+			// End of facsimile block.
+			if params.EncodedByteAlign {
+				w.align()
+			}
+			w.writeBits(ccittEOL + ccittEOL)
+		} else {
+			// Return to control: 6 EOLs (each followed by a tag bit in mixed mode).
+			for i := 0; i < 6; i++ {
+				w.writeBits(ccittEOL)
+				if params.K > 0 {
+					w.writeBits("1")
+				}
+			}
+		}
+	}
+
+	common.Log.Trace("CCITT encoded %d rows in %d bytes", rows, len(w.data))
+	return w.data, nil
+}
+
+// ccittRowChanges returns the changing elements of a row of packed bits: the positions of the pixels of a different
+// color than the previous one, the pixel before the row being white.
+func ccittRowChanges(row []byte, columns int, blackIs1 bool) []int {
+	changes := []int{}
+	black := false
+	for x := 0; x < columns; x++ {
+		isBlack := (row[x/8]>>uint(7-x%8))&1 == 1
+		if !blackIs1 {
+			isBlack = !isBlack
+		}
+		if isBlack != black {
+			changes = append(changes, x)
+			black = isBlack
+		}
+	}
+	return changes
+}
+
+// ccittEncodeRow1D codes a row one-dimensionally (modified Huffman): runs of alternating colors, starting with white.
+func ccittEncodeRow1D(w *ccittBitWriter, changes []int, columns int) {
+	pos := 0
+	black := false
+	for i := 0; i <= len(changes); i++ {
+		end := columns
+		if i < len(changes) {
+			end = changes[i]
+		}
+		w.writeRun(end-pos, black)
+		pos = end
+		black = !black
+	}
+}
+
+// ccittEncodeRow2D codes a row two-dimensionally with respect to the changing elements of the reference line.
+func ccittEncodeRow2D(w *ccittBitWriter, changes, ref []int, columns int) {
+	a0 := -1
+	black := false
+	for a0 < columns {
+		a1 := ccittNextChange(changes, a0, columns)
+		b1, b2 := ccittFindB(ref, a0, black, columns)
+		if b2 < a1 {
+			w.writeBits(ccittModeEncodeTable[ccittModePass])
+			a0 = b2
+			continue
+		}
+		if offset := a1 - b1; offset >= -3 && offset <= 3 {
+			for mode, modeOffset := range ccittVerticalOffsets {
+				if modeOffset == offset {
+					w.writeBits(ccittModeEncodeTable[mode])
+				}
+			}
+			a0 = a1
+			black = !black
+			continue
+		}
+
+		a2 := ccittNextChange(changes, a1, columns)
+		start := a0
+		if start < 0 {
+			start = 0
+		}
+		w.writeBits(ccittModeEncodeTable[ccittModeHorizontal])
+		w.writeRun(a1-start, black)
+		w.writeRun(a2-a1, !black)
+		a0 = a2
+	}
+}
+
+// ccittNextChange returns the first changing element after pos, columns if none.
+func ccittNextChange(changes []int, pos, columns int) int {
+	for _, x := range changes {
+		if x > pos {
+			return x
+		}
+	}
+	return columns
+}
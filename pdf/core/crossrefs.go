@@ -63,109 +63,120 @@ type ObjectStreams map[int]ObjectStream
 // TODO (v3): Unexport.
 type ObjectCache map[int]PdfObject
 
-// Get an object from an object stream.
-func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObject, error) {
-	var bufReader *bytes.Reader
-	var objstm ObjectStream
-	var cached bool
+// loadObjectStream loads the object stream with number sobjNumber, with the offsets of its objects, cached.
+func (parser *PdfParser) loadObjectStream(sobjNumber int) (ObjectStream, error) {
+	if objstm, cached := parser.objstms[sobjNumber]; cached {
+		return objstm, nil
+	}
 
-	objstm, cached = parser.objstms[sobjNumber]
-	if !cached {
-		soi, err := parser.LookupByNumber(sobjNumber)
-		if err != nil {
-			common.Log.Debug("Missing object stream with number %d", sobjNumber)
-			return nil, err
-		}
+	soi, err := parser.LookupByNumber(sobjNumber)
+	if err != nil {
+		common.Log.Debug("Missing object stream with number %d", sobjNumber)
+		return ObjectStream{}, err
+	}
 
-		so, ok := soi.(*PdfObjectStream)
-		if !ok {
-			return nil, errors.New("Invalid object stream")
-		}
+	so, ok := soi.(*PdfObjectStream)
+	if !ok {
+		return ObjectStream{}, errors.New("Invalid object stream")
+	}
 
-		if parser.crypter != nil && !parser.crypter.isDecrypted(so) {
-			return nil, errors.New("Need to decrypt the stream")
-		}
+	if parser.crypter != nil && !parser.crypter.isDecrypted(so) {
+		return ObjectStream{}, errors.New("Need to decrypt the stream")
+	}
 
-		sod := so.PdfObjectDictionary
-		common.Log.Trace("so d: %s\n", *sod)
-		name, ok := sod.Get("Type").(*PdfObjectName)
-		if !ok {
-			common.Log.Debug("ERROR: Object stream should always have a Type")
-			return nil, errors.New("Object stream missing Type")
-		}
-		if strings.ToLower(string(*name)) != "objstm" {
-			common.Log.Debug("ERROR: Object stream type shall always be ObjStm !")
-			return nil, errors.New("Object stream type != ObjStm")
-		}
+	sod := so.PdfObjectDictionary
+	common.Log.Trace("so d: %s\n", *sod)
+	name, ok := sod.Get("Type").(*PdfObjectName)
+	if !ok {
+		common.Log.Debug("ERROR: Object stream should always have a Type")
+		return ObjectStream{}, errors.New("Object stream missing Type")
+	}
+	if strings.ToLower(string(*name)) != "objstm" {
+		common.Log.Debug("ERROR: Object stream type shall always be ObjStm !")
+		return ObjectStream{}, errors.New("Object stream type != ObjStm")
+	}
 
-		N, ok := sod.Get("N").(*PdfObjectInteger)
-		if !ok {
-			return nil, errors.New("Invalid N in stream dictionary")
+	N, ok := sod.Get("N").(*PdfObjectInteger)
+	if !ok {
+		return ObjectStream{}, errors.New("Invalid N in stream dictionary")
+	}
+	firstOffset, ok := sod.Get("First").(*PdfObjectInteger)
+	if !ok {
+		return ObjectStream{}, errors.New("Invalid First in stream dictionary")
+	}
+
+	common.Log.Trace("type: %s number of objects: %d", name, *N)
+	ds, err := DecodeStream(so)
+	if err != nil {
+		return ObjectStream{}, err
+	}
+
+	common.Log.Trace("Decoded: %s", ds)
+
+	// Temporarily change the reader object to this decoded buffer.
+	// Change back afterwards.
+	bakOffset := parser.GetFileOffset()
+	defer func() {
+		parser.SetFileOffset(bakOffset)
+		parser.inObjectStream = false
+	}()
+
+	parser.reader = bufio.NewReader(bytes.NewReader(ds))
+	parser.inObjectStream = true
+
+	common.Log.Trace("Parsing offset map")
+	// Load the offset map (relative to the beginning of the stream...)
+	offsets := map[int]int64{}
+	// Object list and offsets.
+	for i := 0; i < int(*N); i++ {
+		parser.skipSpaces()
+		// Object number.
+		obj, err := parser.parseNumber()
+		if err != nil {
+			return ObjectStream{}, err
 		}
-		firstOffset, ok := sod.Get("First").(*PdfObjectInteger)
+		onum, ok := obj.(*PdfObjectInteger)
 		if !ok {
-			return nil, errors.New("Invalid First in stream dictionary")
+			return ObjectStream{}, errors.New("Invalid object stream offset table")
 		}
 
-		common.Log.Trace("type: %s number of objects: %d", name, *N)
-		ds, err := DecodeStream(so)
+		parser.skipSpaces()
+		// Offset.
+		obj, err = parser.parseNumber()
 		if err != nil {
-			return nil, err
+			return ObjectStream{}, err
+		}
+		offset, ok := obj.(*PdfObjectInteger)
+		if !ok {
+			return ObjectStream{}, errors.New("Invalid object stream offset table")
 		}
 
-		common.Log.Trace("Decoded: %s", ds)
-
-		// Temporarily change the reader object to this decoded buffer.
-		// Change back afterwards.
-		bakOffset := parser.GetFileOffset()
-		defer func() { parser.SetFileOffset(bakOffset) }()
-
-		bufReader = bytes.NewReader(ds)
-		parser.reader = bufio.NewReader(bufReader)
+		common.Log.Trace("obj %d offset %d", *onum, *offset)
+		offsets[int(*onum)] = int64(*firstOffset + *offset)
+	}
 
-		common.Log.Trace("Parsing offset map")
-		// Load the offset map (relative to the beginning of the stream...)
-		offsets := map[int]int64{}
-		// Object list and offsets.
-		for i := 0; i < int(*N); i++ {
-			parser.skipSpaces()
-			// Object number.
-			obj, err := parser.parseNumber()
-			if err != nil {
-				return nil, err
-			}
-			onum, ok := obj.(*PdfObjectInteger)
-			if !ok {
-				return nil, errors.New("Invalid object stream offset table")
-			}
+	objstm := ObjectStream{N: int(*N), ds: ds, offsets: offsets}
+	parser.objstms[sobjNumber] = objstm
+	return objstm, nil
+}
 
-			parser.skipSpaces()
-			// Offset.
-			obj, err = parser.parseNumber()
-			if err != nil {
-				return nil, err
-			}
-			offset, ok := obj.(*PdfObjectInteger)
-			if !ok {
-				return nil, errors.New("Invalid object stream offset table")
-			}
+// Get an object from an object stream.
+func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObject, error) {
+	objstm, err := parser.loadObjectStream(sobjNumber)
+	if err != nil {
+		return nil, err
+	}
 
-			common.Log.Trace("obj %d offset %d", *onum, *offset)
-			offsets[int(*onum)] = int64(*firstOffset + *offset)
-		}
+	// Temporarily change the reader object to this decoded buffer.
+	// Point back afterwards.
+	bakOffset := parser.GetFileOffset()
+	defer func() {
+		parser.SetFileOffset(bakOffset)
+		parser.inObjectStream = false
+	}()
 
-		objstm = ObjectStream{N: int(*N), ds: ds, offsets: offsets}
-		parser.objstms[sobjNumber] = objstm
-	} else {
-		// Temporarily change the reader object to this decoded buffer.
-		// Point back afterwards.
-		bakOffset := parser.GetFileOffset()
-		defer func() { parser.SetFileOffset(bakOffset) }()
-
-		bufReader = bytes.NewReader(objstm.ds)
-		// Temporarily change the reader object to this decoded buffer.
-		parser.reader = bufio.NewReader(bufReader)
-	}
+	bufReader := bytes.NewReader(objstm.ds)
+	parser.inObjectStream = true
 
 	offset := objstm.offsets[objNum]
 	common.Log.Trace("ACTUAL offset[%d] = %d", objNum, offset)
@@ -40,16 +40,33 @@ type PdfCrypt struct {
 	CryptFilters CryptFilters
 	StreamFilter string
 	StringFilter string
+	// Crypt filter of embedded file streams (EFF), by default the stream filter.  Documents where only the
+	// embedded files are encrypted use the Identity filter for other streams and strings.
+	EmbeddedFileFilter string
+	// Recipients of the public-key security handler (Adobe.PubSec): DER encoded PKCS#7 enveloped data.
+	Recipients [][]byte
+
+	// Authenticated with the owner password, granting full access.
+	ownerAuthenticated bool
 
 	parser *PdfParser
 }
 
-// AccessPermissions is a list of access permissions for a PDF file.
+// AccessPermissions is a list of access permissions for a PDF file (P entry of the encryption dictionary, or
+// permissions of a recipient of the public-key security handler).  The permissions are only granted to users
+// opening the file with the user password, the owner has full access.
 type AccessPermissions struct {
-	Printing        bool
-	Modify          bool
+	// Allow printing, possibly in low resolution only (see FullPrintQuality).
+	Printing bool
+
+	// Allow modifying the contents by other operations than annotating, filling forms and assembling.
+	Modify bool
+
+	// Allow copying or extracting text and graphics.
 	ExtractGraphics bool
-	Annotate        bool
+
+	// Allow adding or modifying annotations, and filling forms.
+	Annotate bool
 
 	// Allow form filling, if annotation is disabled?  If annotation enabled, is not looked at.
 	FillForms         bool
@@ -62,6 +79,89 @@ type AccessPermissions struct {
 	FullPrintQuality bool
 }
 
+// Permission is an operation on a document which may be restricted by its access permissions.
+type Permission int
+
+const (
+	// PermissionPrint is printing the document, possibly in low resolution only.
+	PermissionPrint Permission = iota
+	// PermissionPrintHighRes is printing the document in full quality.
+	PermissionPrintHighRes
+	// PermissionModify is modifying the contents of the document.
+	PermissionModify
+	// PermissionCopy is copying or extracting text and graphics.
+	PermissionCopy
+	// PermissionAnnotate is adding or modifying annotations.
+	PermissionAnnotate
+	// PermissionFillForms is filling in form fields, including signature fields.
+	PermissionFillForms
+	// PermissionAccessibility is extracting text and graphics for accessibility purposes (e.g. screen readers).
+	PermissionAccessibility
+	// PermissionAssemble is inserting, rotating or deleting pages and creating bookmarks or thumbnails.
+	PermissionAssemble
+)
+
+// String returns the name of the permission.
+func (p Permission) String() string {
+	switch p {
+	case PermissionPrint:
+		return "Print"
+	case PermissionPrintHighRes:
+		return "PrintHighRes"
+	case PermissionModify:
+		return "Modify"
+	case PermissionCopy:
+		return "Copy"
+	case PermissionAnnotate:
+		return "Annotate"
+	case PermissionFillForms:
+		return "FillForms"
+	case PermissionAccessibility:
+		return "Accessibility"
+	case PermissionAssemble:
+		return "Assemble"
+	}
+	return fmt.Sprintf("Permission(%d)", int(p))
+}
+
+// FullAccessPermissions returns the permissions granting all operations, the access of the owner of a document.
+func FullAccessPermissions() AccessPermissions {
+	return AccessPermissions{
+		Printing:          true,
+		Modify:            true,
+		ExtractGraphics:   true,
+		Annotate:          true,
+		FillForms:         true,
+		DisabilityExtract: true,
+		RotateInsert:      true,
+		FullPrintQuality:  true,
+	}
+}
+
+// Allows returns true if the permissions allow an operation.  Some operations are implied by others, e.g. filling
+// forms is allowed when annotating is, and assembling when modifying is (Table 22 of the PDF reference).
+func (perms AccessPermissions) Allows(p Permission) bool {
+	switch p {
+	case PermissionPrint:
+		return perms.Printing
+	case PermissionPrintHighRes:
+		return perms.Printing && perms.FullPrintQuality
+	case PermissionModify:
+		return perms.Modify
+	case PermissionCopy:
+		return perms.ExtractGraphics
+	case PermissionAnnotate:
+		return perms.Annotate
+	case PermissionFillForms:
+		return perms.FillForms || perms.Annotate
+	case PermissionAccessibility:
+		return perms.DisabilityExtract || perms.ExtractGraphics
+	case PermissionAssemble:
+		return perms.RotateInsert || perms.Modify
+	}
+	return false
+}
+
 const padding = "\x28\xBF\x4E\x5E\x4E\x75\x8A\x41\x64\x00\x4E\x56\xFF" +
 	"\xFA\x01\x08\x2E\x2E\x00\xB6\xD0\x68\x3E\x80\x2F\x0C" +
 	"\xA9\xFE\x64\x53\x69\x7A"
@@ -191,9 +291,58 @@ func (crypt *PdfCrypt) LoadCryptFilters(ed *PdfObjectDictionary) error {
 		crypt.StreamFilter = string(*stmf)
 	}
 
+	// EFF embedded file streams filter.
+	crypt.EmbeddedFileFilter = crypt.StreamFilter
+	if eff, ok := ed.Get("EFF").(*PdfObjectName); ok {
+		if _, exists := crypt.CryptFilters[string(*eff)]; !exists {
+			return fmt.Errorf("Crypt filter for EFF not specified in CF dictionary (%s)", *eff)
+		}
+		crypt.EmbeddedFileFilter = string(*eff)
+	}
+
 	return nil
 }
 
+// EncryptsEmbeddedFilesOnly returns true if only the embedded file streams of the document are encrypted, i.e.
+// streams and strings use the Identity crypt filter.  The content of such documents can be read without
+// authentication, the password is only required for accessing the embedded files.
+func (crypt *PdfCrypt) EncryptsEmbeddedFilesOnly() bool {
+	return crypt.V >= 4 && crypt.StreamFilter == "Identity" && crypt.StringFilter == "Identity" &&
+		crypt.EmbeddedFileFilter != "Identity"
+}
+
+// Returns the name of the crypt filter of a stream (V4): the stream or embedded file filter by default, unless the
+// first filter of the stream is a Crypt filter.  Metadata streams are not encrypted if EncryptMetadata is false.
+func (crypt *PdfCrypt) getStreamFilter(dict *PdfObjectDictionary) string {
+	streamFilter := crypt.StreamFilter
+	if typename, ok := dict.Get("Type").(*PdfObjectName); ok {
+		if *typename == "EmbeddedFile" {
+			streamFilter = crypt.EmbeddedFileFilter
+		} else if *typename == "Metadata" && !crypt.EncryptMetadata {
+			return "Identity"
+		}
+	}
+	common.Log.Trace("this.StreamFilter = %s", streamFilter)
+
+	// Crypt filter can only be the first entry.
+	filters, decodeParams, err := getStreamFilters(dict)
+	if err == nil && len(filters) > 0 && filters[0] == StreamEncodingFilterNameCrypt {
+		// Crypt filter overriding the default.
+		// Default option is Identity.
+		streamFilter = "Identity"
+
+		// Check if valid crypt filter specified in the decode params.
+		if encoder, err := newCryptEncoderFromStream(nil, decodeParams[0]); err == nil {
+			if _, ok := crypt.CryptFilters[encoder.Name]; ok {
+				common.Log.Trace("Using stream filter %s", encoder.Name)
+				streamFilter = encoder.Name
+			}
+		}
+	}
+
+	return streamFilter
+}
+
 // PdfCryptMakeNew makes the document crypt handler based on the encryption dictionary
 // and trailer dictionary. Returns an error on failure to process.
 func PdfCryptMakeNew(parser *PdfParser, ed, trailer *PdfObjectDictionary) (PdfCrypt, error) {
@@ -208,14 +357,13 @@ func PdfCryptMakeNew(parser *PdfParser, ed, trailer *PdfObjectDictionary) (PdfCr
 		common.Log.Debug("ERROR Crypt dictionary missing required Filter field!")
 		return crypter, errors.New("Required crypt field Filter missing")
 	}
-	if *filter != "Standard" {
+	if *filter != "Standard" && *filter != "Adobe.PubSec" {
 		common.Log.Debug("ERROR Unsupported filter (%s)", *filter)
 		return crypter, errors.New("Unsupported Filter")
 	}
 	crypter.Filter = string(*filter)
 
-	subfilter, ok := ed.Get("SubFilter").(*PdfObjectString)
-	if ok {
+	if subfilter, ok := ed.Get("SubFilter").(*PdfObjectString); ok {
 		crypter.Subfilter = string(*subfilter)
 		common.Log.Debug("Using subfilter %s", subfilter)
 	}
@@ -250,6 +398,15 @@ func PdfCryptMakeNew(parser *PdfParser, ed, trailer *PdfObjectDictionary) (PdfCr
 		crypter.V = 0
 	}
 
+	if crypter.Filter == "Adobe.PubSec" {
+		// The permissions and encryption key are enveloped for each recipient.
+		if err := crypter.loadPubSec(ed); err != nil {
+			return crypter, err
+		}
+		crypter.P = -1
+		return crypter, nil
+	}
+
 	R, ok := ed.Get("R").(*PdfObjectInteger)
 	if !ok {
 		return crypter, errors.New("Encrypt dictionary missing R")
@@ -343,9 +500,10 @@ func (crypt *PdfCrypt) GetAccessPermissions() AccessPermissions {
 	return perms
 }
 
-// GetP returns the P entry to be used in Encrypt dictionary based on AccessPermissions settings.
+// GetP returns the P entry to be used in Encrypt dictionary based on AccessPermissions settings.  The reserved
+// bits 7-8 and 13-32 are set as required.
 func (perms AccessPermissions) GetP() int32 {
-	var P int32 = 0
+	var P int32 = -1 &^ 0xf3f
 
 	if perms.Printing { // bit 3
 		P |= (1 << 2)
@@ -379,6 +537,11 @@ func (crypt *PdfCrypt) authenticate(password []byte) (bool, error) {
 	// Also build the encryption/decryption key.
 
 	crypt.Authenticated = false
+	crypt.ownerAuthenticated = false
+	if crypt.Filter == "Adobe.PubSec" {
+		// Public-key security handler: authenticated with a certificate.
+		return false, nil
+	}
 
 	// Try user password.
 	common.Log.Trace("Debugging authentication - user pass")
@@ -403,6 +566,7 @@ func (crypt *PdfCrypt) authenticate(password []byte) (bool, error) {
 	if authenticated {
 		common.Log.Trace("this.Authenticated = True")
 		crypt.Authenticated = true
+		crypt.ownerAuthenticated = true
 		return true, nil
 	}
 
@@ -417,6 +581,9 @@ func (crypt *PdfCrypt) authenticate(password []byte) (bool, error) {
 // An error is returned if there was a problem performing the authentication.
 func (crypt *PdfCrypt) checkAccessRights(password []byte) (bool, AccessPermissions, error) {
 	perms := AccessPermissions{}
+	if crypt.Filter == "Adobe.PubSec" {
+		return false, perms, nil
+	}
 
 	// Try owner password -> full rights.
 	isOwner, err := crypt.Alg7(password)
@@ -425,15 +592,7 @@ func (crypt *PdfCrypt) checkAccessRights(password []byte) (bool, AccessPermissio
 	}
 	if isOwner {
 		// owner -> full rights.
-		perms.Annotate = true
-		perms.DisabilityExtract = true
-		perms.ExtractGraphics = true
-		perms.FillForms = true
-		perms.FullPrintQuality = true
-		perms.Modify = true
-		perms.Printing = true
-		perms.RotateInsert = true
-		return true, perms, nil
+		return true, FullAccessPermissions(), nil
 	}
 
 	// Try user password.
@@ -643,37 +802,11 @@ func (crypt *PdfCrypt) Decrypt(obj PdfObject, parentObjNum, parentGenNum int64)
 		genNum := (*so).GenerationNumber
 		common.Log.Trace("Decrypting stream %d %d !", objNum, genNum)
 
-		// TODO: Check for crypt filter (V4).
-		// The Crypt filter shall be the first filter in the Filter array entry.
-
 		dict := so.PdfObjectDictionary
 
 		streamFilter := "Default" // Default RC4.
 		if crypt.V >= 4 {
-			streamFilter = crypt.StreamFilter
-			common.Log.Trace("this.StreamFilter = %s", crypt.StreamFilter)
-
-			if filters, ok := dict.Get("Filter").(*PdfObjectArray); ok {
-				// Crypt filter can only be the first entry.
-				if firstFilter, ok := (*filters)[0].(*PdfObjectName); ok {
-					if *firstFilter == "Crypt" {
-						// Crypt filter overriding the default.
-						// Default option is Identity.
-						streamFilter = "Identity"
-
-						// Check if valid crypt filter specified in the decode params.
-						if decodeParams, ok := dict.Get("DecodeParms").(*PdfObjectDictionary); ok {
-							if filterName, ok := decodeParams.Get("Name").(*PdfObjectName); ok {
-								if _, ok := crypt.CryptFilters[string(*filterName)]; ok {
-									common.Log.Trace("Using stream filter %s", *filterName)
-									streamFilter = string(*filterName)
-								}
-							}
-						}
-					}
-				}
-			}
-
+			streamFilter = crypt.getStreamFilter(dict)
 			common.Log.Trace("with %s filter", streamFilter)
 			if streamFilter == "Identity" {
 				// Identity: pass unchanged.
@@ -681,6 +814,14 @@ func (crypt *PdfCrypt) Decrypt(obj PdfObject, parentObjNum, parentGenNum int64)
 			}
 		}
 
+		if !crypt.Authenticated {
+			// The key is not known yet, e.g. for the embedded files of a document where only the embedded files are
+			// encrypted: leave the stream to be decrypted after authentication.
+			common.Log.Debug("Stream %d %d not decrypted: not authenticated", objNum, genNum)
+			delete(crypt.DecryptedObjects, so)
+			return nil
+		}
+
 		err := crypt.Decrypt(so.PdfObjectDictionary, objNum, genNum)
 		if err != nil {
 			return err
@@ -691,6 +832,13 @@ func (crypt *PdfCrypt) Decrypt(obj PdfObject, parentObjNum, parentGenNum int64)
 			return err
 		}
 
+		if !so.IsLoaded() {
+			// Decrypted when loaded.
+			so.lazy.decrypt = func(data []byte) ([]byte, error) {
+				return crypt.decryptBytes(data, streamFilter, okey)
+			}
+			return nil
+		}
 		so.Stream, err = crypt.decryptBytes(so.Stream, streamFilter, okey)
 		if err != nil {
 			return err
@@ -890,39 +1038,11 @@ func (crypt *PdfCrypt) Encrypt(obj PdfObject, parentObjNum, parentGenNum int64)
 		genNum := (*so).GenerationNumber
 		common.Log.Trace("Encrypting stream %d %d !", objNum, genNum)
 
-		// TODO: Check for crypt filter (V4).
-		// The Crypt filter shall be the first filter in the Filter array entry.
-
 		dict := so.PdfObjectDictionary
 
 		streamFilter := "Default" // Default RC4.
 		if crypt.V >= 4 {
-			// For now.  Need to change when we add support for more than
-			// Identity / RC4.
-			streamFilter = crypt.StreamFilter
-			common.Log.Trace("this.StreamFilter = %s", crypt.StreamFilter)
-
-			if filters, ok := dict.Get("Filter").(*PdfObjectArray); ok {
-				// Crypt filter can only be the first entry.
-				if firstFilter, ok := (*filters)[0].(*PdfObjectName); ok {
-					if *firstFilter == "Crypt" {
-						// Crypt filter overriding the default.
-						// Default option is Identity.
-						streamFilter = "Identity"
-
-						// Check if valid crypt filter specified in the decode params.
-						if decodeParams, ok := dict.Get("DecodeParms").(*PdfObjectDictionary); ok {
-							if filterName, ok := decodeParams.Get("Name").(*PdfObjectName); ok {
-								if _, ok := crypt.CryptFilters[string(*filterName)]; ok {
-									common.Log.Trace("Using stream filter %s", *filterName)
-									streamFilter = string(*filterName)
-								}
-							}
-						}
-					}
-				}
-			}
-
+			streamFilter = crypt.getStreamFilter(dict)
 			common.Log.Trace("with %s filter", streamFilter)
 			if streamFilter == "Identity" {
 				// Identity: pass unchanged.
@@ -940,6 +1060,9 @@ func (crypt *PdfCrypt) Encrypt(obj PdfObject, parentObjNum, parentGenNum int64)
 			return err
 		}
 
+		if err := so.Load(); err != nil {
+			return err
+		}
 		so.Stream, err = crypt.encryptBytes(so.Stream, streamFilter, okey)
 		if err != nil {
 			return err
@@ -0,0 +1,464 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// Public-key security handler (Adobe.PubSec, section 7.6.4 of the PDF reference).  The file encryption key is
+// derived from a 20 byte seed which is enveloped (PKCS#7 EnvelopedData) together with the permissions for each
+// recipient in the Recipients array.
+
+// PubSecRecipient is a recipient of a document encrypted with the public-key security handler: the certificate
+// of the recipient (with an RSA public key) and the permissions granted to the recipient.
+type PubSecRecipient struct {
+	Certificate *x509.Certificate
+	Permissions AccessPermissions
+}
+
+var (
+	oidPKCS7Data          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7EnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+	oidRSAEncryption      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidDESEDE3CBC         = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// pkcs7ContentInfo is the outer structure of the entries of the Recipients array.
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type pkcs7EnvelopedData struct {
+	Version              int
+	OriginatorInfo       asn1.RawValue   `asn1:"optional,tag:0"`
+	RecipientInfos       []asn1.RawValue `asn1:"set"`
+	EncryptedContentInfo pkcs7EncryptedContentInfo
+}
+
+type pkcs7KeyTransRecipientInfo struct {
+	Version                int
+	RecipientIdentifier    asn1.RawValue
+	KeyEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedKey           []byte
+}
+
+type pkcs7IssuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type pkcs7EncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// Loads the public-key security handler entries of the encryption dictionary: the sub filter and the Recipients
+// array, which is in the crypt filter dictionaries for V4.
+func (crypt *PdfCrypt) loadPubSec(ed *PdfObjectDictionary) error {
+	switch subfilter := ed.Get("SubFilter").(type) {
+	case *PdfObjectName:
+		crypt.Subfilter = string(*subfilter)
+	case *PdfObjectString:
+		crypt.Subfilter = string(*subfilter)
+	}
+	if crypt.Subfilter != "adbe.pkcs7.s3" && crypt.Subfilter != "adbe.pkcs7.s4" &&
+		crypt.Subfilter != "adbe.pkcs7.s5" {
+		common.Log.Debug("ERROR Unsupported public-key sub filter (%s)", crypt.Subfilter)
+		return errors.New("Unsupported SubFilter")
+	}
+
+	crypt.EncryptMetadata = true
+	recipients := ed.Get("Recipients")
+	if crypt.V == 4 {
+		cf, err := crypt.resolve(ed.Get("CF"))
+		if err != nil {
+			return err
+		}
+		cfDict, ok := cf.(*PdfObjectDictionary)
+		if !ok {
+			return errors.New("Invalid CF")
+		}
+		filterName := crypt.getKeyFilter()
+		filter, err := crypt.resolve(cfDict.Get(PdfObjectName(filterName)))
+		if err != nil {
+			return err
+		}
+		filterDict, ok := filter.(*PdfObjectDictionary)
+		if !ok {
+			return fmt.Errorf("Invalid crypt filter (%s)", filterName)
+		}
+		recipients = filterDict.Get("Recipients")
+		if em, ok := filterDict.Get("EncryptMetadata").(*PdfObjectBool); ok {
+			crypt.EncryptMetadata = bool(*em)
+		}
+	}
+
+	recipients, err := crypt.resolve(recipients)
+	if err != nil {
+		return err
+	}
+	var entries []PdfObject
+	switch t := recipients.(type) {
+	case *PdfObjectArray:
+		entries = *t
+	case *PdfObjectString:
+		entries = []PdfObject{t}
+	default:
+		common.Log.Debug("ERROR Public-key encryption missing Recipients")
+		return errors.New("Encrypt dictionary missing Recipients")
+	}
+	crypt.Recipients = nil
+	for _, entry := range entries {
+		entry, err := crypt.resolve(entry)
+		if err != nil {
+			return err
+		}
+		str, ok := entry.(*PdfObjectString)
+		if !ok {
+			return fmt.Errorf("Invalid Recipients entry (%T)", entry)
+		}
+		crypt.Recipients = append(crypt.Recipients, []byte(*str))
+	}
+	return nil
+}
+
+// Resolves a reference in the encryption dictionary.
+func (crypt *PdfCrypt) resolve(obj PdfObject) (PdfObject, error) {
+	if ref, isRef := obj.(*PdfObjectReference); isRef {
+		o, err := crypt.parser.LookupByReference(*ref)
+		if err != nil {
+			common.Log.Debug("Error looking up reference %v", ref)
+			return nil, err
+		}
+		return TraceToDirectObject(o), nil
+	}
+	return TraceToDirectObject(obj), nil
+}
+
+// Returns the name of the crypt filter defining the file encryption key (V4): the first of the stream, string
+// and embedded file filters which is not Identity.
+func (crypt *PdfCrypt) getKeyFilter() string {
+	for _, name := range []string{crypt.StreamFilter, crypt.StringFilter, crypt.EmbeddedFileFilter} {
+		if name != "" && name != "Identity" {
+			return name
+		}
+	}
+	return "Identity"
+}
+
+// Returns the length of the file encryption key in bytes.
+func (crypt *PdfCrypt) pubSecKeyLength() int {
+	if crypt.V == 4 {
+		if cf := crypt.CryptFilters[crypt.getKeyFilter()]; cf.Length > 0 {
+			return cf.Length
+		}
+		return 16
+	}
+	return crypt.Length / 8
+}
+
+// Computes the file encryption key from the seed: SHA-1 of the seed, the Recipients entries and 0xffffffff if the
+// metadata is not encrypted.
+func (crypt *PdfCrypt) makePubSecKey(seed []byte) []byte {
+	h := sha1.New()
+	h.Write(seed)
+	for _, recipient := range crypt.Recipients {
+		h.Write(recipient)
+	}
+	if crypt.V == 4 && !crypt.EncryptMetadata {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	key := h.Sum(nil)
+
+	n := crypt.pubSecKeyLength()
+	if n > len(key) {
+		n = len(key)
+	}
+	return key[:n]
+}
+
+// Opens the envelope of the Recipients array addressed to the certificate with the private key.  Returns the seed
+// and the permissions (P) of the recipient, or nil if the certificate is not a recipient.
+func (crypt *PdfCrypt) openPubSec(cert *x509.Certificate, key crypto.PrivateKey) ([]byte, int, error) {
+	decrypter, ok := key.(crypto.Decrypter)
+	if !ok {
+		common.Log.Debug("ERROR Private key cannot decrypt (%T)", key)
+		return nil, 0, errors.New("Unsupported private key")
+	}
+
+	for _, recipient := range crypt.Recipients {
+		content, err := openEnvelope(recipient, cert, decrypter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if content == nil {
+			continue
+		}
+		if len(content) < 24 {
+			return nil, 0, fmt.Errorf("Invalid enveloped seed length (%d)", len(content))
+		}
+		P := int(int32(uint32(content[20])<<24 | uint32(content[21])<<16 | uint32(content[22])<<8 |
+			uint32(content[23])))
+		return content[:20], P, nil
+	}
+	return nil, 0, nil
+}
+
+// Check whether the certificate and its private key can be used to decrypt the document (public-key security
+// handler) and build the encryption key.
+func (crypt *PdfCrypt) authenticateCertificate(cert *x509.Certificate, key crypto.PrivateKey) (bool, error) {
+	crypt.Authenticated = false
+	crypt.ownerAuthenticated = false
+	if crypt.Filter != "Adobe.PubSec" {
+		return false, errors.New("Not encrypted with the public-key security handler")
+	}
+
+	seed, P, err := crypt.openPubSec(cert, key)
+	if err != nil || seed == nil {
+		return false, err
+	}
+	crypt.P = P
+	crypt.EncryptionKey = crypt.makePubSecKey(seed)
+	crypt.Authenticated = true
+	return true, nil
+}
+
+// Check the access rights and permissions of a recipient of a document encrypted with the public-key security
+// handler.
+func (crypt *PdfCrypt) checkAccessRightsCertificate(cert *x509.Certificate, key crypto.PrivateKey) (bool,
+	AccessPermissions, error) {
+	if crypt.Filter != "Adobe.PubSec" {
+		return false, AccessPermissions{}, errors.New("Not encrypted with the public-key security handler")
+	}
+
+	seed, P, err := crypt.openPubSec(cert, key)
+	if err != nil || seed == nil {
+		return false, AccessPermissions{}, err
+	}
+	perms := (&PdfCrypt{P: P}).GetAccessPermissions()
+	return true, perms, nil
+}
+
+// InitPubSec generates a random seed and the Recipients entries enveloping it for each recipient with its
+// permissions, and derives the encryption key (public-key security handler).  The Filter, V and crypt filters must
+// be set beforehand.
+func (crypt *PdfCrypt) InitPubSec(recipients []PubSecRecipient) error {
+	if len(recipients) == 0 {
+		return errors.New("No recipients")
+	}
+
+	seed := make([]byte, 20)
+	if _, err := rand.Read(seed); err != nil {
+		return err
+	}
+
+	crypt.Recipients = nil
+	for _, recipient := range recipients {
+		P := uint32(recipient.Permissions.GetP())
+		content := append(append([]byte{}, seed...), byte(P>>24), byte(P>>16), byte(P>>8), byte(P))
+		envelope, err := makeEnvelope(content, recipient.Certificate)
+		if err != nil {
+			return err
+		}
+		crypt.Recipients = append(crypt.Recipients, envelope)
+	}
+	crypt.EncryptionKey = crypt.makePubSecKey(seed)
+	return nil
+}
+
+// Decrypts the content of a PKCS#7 EnvelopedData if addressed to the certificate (RSA key transport).  Returns nil
+// if not a recipient.
+func openEnvelope(data []byte, cert *x509.Certificate, key crypto.Decrypter) ([]byte, error) {
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(data, &info); err != nil {
+		common.Log.Debug("ERROR Invalid Recipients entry (%s)", err)
+		return nil, err
+	}
+	if !info.ContentType.Equal(oidPKCS7EnvelopedData) {
+		return nil, errors.New("Recipients entry not enveloped data")
+	}
+	var envelope pkcs7EnvelopedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &envelope); err != nil {
+		common.Log.Debug("ERROR Invalid enveloped data (%s)", err)
+		return nil, err
+	}
+
+	for _, ri := range envelope.RecipientInfos {
+		// Only key transport recipients (SEQUENCE) are supported.
+		if ri.Class != asn1.ClassUniversal || ri.Tag != asn1.TagSequence {
+			continue
+		}
+		var ktri pkcs7KeyTransRecipientInfo
+		if _, err := asn1.Unmarshal(ri.FullBytes, &ktri); err != nil {
+			return nil, err
+		}
+		if !isRecipient(ktri.RecipientIdentifier, cert) {
+			continue
+		}
+		if !ktri.KeyEncryptionAlgorithm.Algorithm.Equal(oidRSAEncryption) {
+			return nil, fmt.Errorf("Unsupported key encryption algorithm (%v)", ktri.KeyEncryptionAlgorithm.Algorithm)
+		}
+		contentKey, err := key.Decrypt(rand.Reader, ktri.EncryptedKey, nil)
+		if err != nil {
+			common.Log.Debug("ERROR Failed to decrypt the content key (%s)", err)
+			return nil, err
+		}
+		return decryptContent(envelope.EncryptedContentInfo, contentKey)
+	}
+	return nil, nil
+}
+
+// Returns true if the recipient identifier (issuer and serial number or subject key identifier) matches the
+// certificate.
+func isRecipient(rid asn1.RawValue, cert *x509.Certificate) bool {
+	if rid.Class == asn1.ClassContextSpecific && rid.Tag == 0 {
+		return len(cert.SubjectKeyId) > 0 && bytes.Equal(rid.Bytes, cert.SubjectKeyId)
+	}
+	var ias pkcs7IssuerAndSerialNumber
+	if _, err := asn1.Unmarshal(rid.FullBytes, &ias); err != nil {
+		return false
+	}
+	return bytes.Equal(ias.Issuer.FullBytes, cert.RawIssuer) && ias.SerialNumber != nil &&
+		ias.SerialNumber.Cmp(cert.SerialNumber) == 0
+}
+
+// Decrypts the content of an EnvelopedData with the content encryption key (Triple DES or AES in CBC mode).
+func decryptContent(eci pkcs7EncryptedContentInfo, key []byte) ([]byte, error) {
+	alg := eci.ContentEncryptionAlgorithm
+	var block cipher.Block
+	var err error
+	switch {
+	case alg.Algorithm.Equal(oidDESEDE3CBC):
+		block, err = des.NewTripleDESCipher(key)
+	case alg.Algorithm.Equal(oidAES128CBC), alg.Algorithm.Equal(oidAES192CBC), alg.Algorithm.Equal(oidAES256CBC):
+		block, err = aes.NewCipher(key)
+	default:
+		return nil, fmt.Errorf("Unsupported content encryption algorithm (%v)", alg.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &iv); err != nil || len(iv) != block.BlockSize() {
+		return nil, errors.New("Invalid content encryption IV")
+	}
+
+	buf := eci.EncryptedContent.Bytes
+	if eci.EncryptedContent.IsCompound {
+		// Constructed encoding (BER): concatenation of octet strings.
+		buf = nil
+		rest := eci.EncryptedContent.Bytes
+		for len(rest) > 0 {
+			var part []byte
+			rest, err = asn1.Unmarshal(rest, &part)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, part...)
+		}
+	}
+	if len(buf) == 0 || len(buf)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("Invalid encrypted content length (%d)", len(buf))
+	}
+
+	content := make([]byte, len(buf))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(content, buf)
+	padLen := int(content[len(content)-1])
+	if padLen == 0 || padLen > block.BlockSize() {
+		return nil, errors.New("Invalid content padding")
+	}
+	return content[:len(content)-padLen], nil
+}
+
+// Makes a PKCS#7 EnvelopedData with the content encrypted for the certificate (AES-256 in CBC mode, RSA key
+// transport).
+func makeEnvelope(content []byte, cert *x509.Certificate) ([]byte, error) {
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		common.Log.Debug("ERROR Unsupported recipient public key (%T)", cert.PublicKey)
+		return nil, errors.New("Recipient certificate without RSA public key")
+	}
+
+	key := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padLen := aes.BlockSize - len(content)%aes.BlockSize
+	buf := append(append([]byte{}, content...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(buf, buf)
+
+	encryptedKey, err := rsa.EncryptPKCS1v15(rand.Reader, pub, key)
+	if err != nil {
+		return nil, err
+	}
+	rid, err := asn1.Marshal(pkcs7IssuerAndSerialNumber{
+		Issuer:       asn1.RawValue{FullBytes: cert.RawIssuer},
+		SerialNumber: cert.SerialNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ri, err := asn1.Marshal(pkcs7KeyTransRecipientInfo{
+		RecipientIdentifier:    asn1.RawValue{FullBytes: rid},
+		KeyEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue},
+		EncryptedKey:           encryptedKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := asn1.Marshal(pkcs7EnvelopedData{
+		RecipientInfos: []asn1.RawValue{{FullBytes: ri}},
+		EncryptedContentInfo: pkcs7EncryptedContentInfo{
+			ContentType: oidPKCS7Data,
+			ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  oidAES256CBC,
+				Parameters: asn1.RawValue{FullBytes: ivParam},
+			},
+			EncryptedContent: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: buf},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidPKCS7EnvelopedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: envelope},
+	})
+}
@@ -8,6 +8,12 @@
 package core
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
 
 	"github.com/unidoc/unidoc/common"
@@ -210,3 +216,83 @@ func TestDecryption1(t *testing.T) {
 		return
 	}
 }
+
+func TestPubSecKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(7), Subject: pkix.Name{CommonName: "Test"}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	crypter := PdfCrypt{Filter: "Adobe.PubSec", V: 4, EncryptMetadata: true}
+	crypter.CryptFilters = CryptFilters{"DefaultCryptFilter": {Cfm: "AESV2", Length: 16}}
+	crypter.StreamFilter = "DefaultCryptFilter"
+	perms := AccessPermissions{Printing: true, FillForms: true}
+	if err := crypter.InitPubSec([]PubSecRecipient{{Certificate: cert, Permissions: perms}}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(crypter.EncryptionKey) != 16 || len(crypter.Recipients) != 1 {
+		t.Fatalf("Wrong key %x (%d recipients)", crypter.EncryptionKey, len(crypter.Recipients))
+	}
+
+	decrypter := crypter
+	decrypter.EncryptionKey = nil
+	ok, err := decrypter.authenticateCertificate(cert, key)
+	if err != nil || !ok {
+		t.Fatalf("Failed to authenticate (%v)", err)
+	}
+	if !bytes.Equal(decrypter.EncryptionKey, crypter.EncryptionKey) {
+		t.Errorf("Wrong key %x != %x", decrypter.EncryptionKey, crypter.EncryptionKey)
+	}
+	if decrypter.GetAccessPermissions() != perms {
+		t.Errorf("Wrong permissions %+v", decrypter.GetAccessPermissions())
+	}
+
+	// The key depends on whether the metadata is encrypted.
+	decrypter.EncryptMetadata = false
+	if _, err := decrypter.authenticateCertificate(cert, key); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if bytes.Equal(decrypter.EncryptionKey, crypter.EncryptionKey) {
+		t.Errorf("Key ignores EncryptMetadata")
+	}
+}
+
+func TestAccessPermissions(t *testing.T) {
+	perms := AccessPermissions{Printing: true, Annotate: true, RotateInsert: true}
+	P := perms.GetP()
+	// Reserved bits 7-8 and 13-32 set, 1-2 clear.
+	if uint32(P)&0xfffff0c0 != 0xfffff0c0 || P&3 != 0 {
+		t.Errorf("Wrong reserved bits %x", uint32(P))
+	}
+	if decoded := (&PdfCrypt{P: int(P)}).GetAccessPermissions(); decoded != perms {
+		t.Errorf("Wrong decoded permissions %+v", decoded)
+	}
+
+	expected := map[Permission]bool{
+		PermissionPrint:         true,
+		PermissionPrintHighRes:  false,
+		PermissionModify:        false,
+		PermissionCopy:          false,
+		PermissionAnnotate:      true,
+		PermissionFillForms:     true,
+		PermissionAccessibility: false,
+		PermissionAssemble:      true,
+	}
+	for p, exp := range expected {
+		if perms.Allows(p) != exp {
+			t.Errorf("%s allowed: %v", p, !exp)
+		}
+		if !FullAccessPermissions().Allows(p) {
+			t.Errorf("%s not allowed with full access", p)
+		}
+	}
+}
@@ -13,9 +13,10 @@ package core
 // - RunLength
 // - ASCII Hex
 // - ASCII85
-// - CCITT Fax (dummy)
-// - JBIG2 (dummy)
+// - CCITT Fax
+// - JBIG2 (decoding only)
 // - JPX (dummy)
+// - Crypt (the data is encrypted by the security handler)
 
 import (
 	"bytes"
@@ -46,6 +47,7 @@ const (
 	StreamEncodingFilterNameCCITTFax  = "CCITTFaxDecode"
 	StreamEncodingFilterNameJBIG2     = "JBIG2Decode"
 	StreamEncodingFilterNameJPX       = "JPXDecode"
+	StreamEncodingFilterNameCrypt     = "Crypt"
 	StreamEncodingFilterNameRaw       = "Raw"
 )
 
@@ -65,6 +67,7 @@ type StreamEncoder interface {
 
 // Flate encoding.
 type FlateEncoder struct {
+	// 1: no prediction, 2: TIFF predictor 2, 10 to 14: PNG predictor for all rows, 15: PNG predictor of each row.
 	Predictor        int
 	BitsPerComponent int
 	// For predictors
@@ -79,7 +82,6 @@ func NewFlateEncoder() *FlateEncoder {
 	// Default (No prediction)
 	encoder.Predictor = 1
 
-	// Currently only supporting 8.
 	encoder.BitsPerComponent = 8
 
 	encoder.Colors = 1
@@ -91,8 +93,9 @@ func NewFlateEncoder() *FlateEncoder {
 // Set the predictor function.  Specify the number of columns per row.
 // The columns indicates the number of samples per row.
 // Used for grouping data together for compression.
+// Sets the PNG Sub predictor (11); Predictor can be set to any predictor supported (see predictor.go), e.g. 15 for the
+// best PNG predictor of each row.
 func (this *FlateEncoder) SetPredictor(columns int) {
-	// Only supporting PNG sub predictor for encoding.
 	this.Predictor = 11
 	this.Columns = columns
 }
@@ -242,199 +245,29 @@ func (this *FlateEncoder) DecodeBytes(encoded []byte) ([]byte, error) {
 
 	common.Log.Trace("En: % x\n", encoded)
 	common.Log.Trace("De: % x\n", outBuf.Bytes())
+	common.Log.Trace("Predictor: %d", this.Predictor)
 
-	return outBuf.Bytes(), nil
+	return predictorDecode(outBuf.Bytes(), this.predictorParams())
 }
 
 // Decode a FlateEncoded stream object and give back decoded bytes.
 func (this *FlateEncoder) DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
-	// TODO: Handle more filter bytes and support more values of BitsPerComponent.
-
 	common.Log.Trace("FlateDecode stream")
-	common.Log.Trace("Predictor: %d", this.Predictor)
-	if this.BitsPerComponent != 8 {
-		return nil, fmt.Errorf("Invalid BitsPerComponent=%d (only 8 supported)", this.BitsPerComponent)
-	}
-
-	outData, err := this.DecodeBytes(streamObj.Stream)
-	if err != nil {
-		return nil, err
-	}
-	common.Log.Trace("En: % x\n", streamObj.Stream)
-	common.Log.Trace("De: % x\n", outData)
-
-	if this.Predictor > 1 {
-		if this.Predictor == 2 { // TIFF encoding: Needs some tests.
-			common.Log.Trace("Tiff encoding")
-			common.Log.Trace("Colors: %d", this.Colors)
-
-			rowLength := int(this.Columns) * this.Colors
-			if rowLength < 1 {
-				// No data. Return empty set.
-				return []byte{}, nil
-			}
-			rows := len(outData) / rowLength
-			if len(outData)%rowLength != 0 {
-				common.Log.Debug("ERROR: TIFF encoding: Invalid row length...")
-				return nil, fmt.Errorf("Invalid row length (%d/%d)", len(outData), rowLength)
-			}
-			if rowLength%this.Colors != 0 {
-				return nil, fmt.Errorf("Invalid row length (%d) for colors %d", rowLength, this.Colors)
-			}
-			if rowLength > len(outData) {
-				common.Log.Debug("Row length cannot be longer than data length (%d/%d)", rowLength, len(outData))
-				return nil, errors.New("Range check error")
-			}
-			common.Log.Trace("inp outData (%d): % x", len(outData), outData)
-
-			pOutBuffer := bytes.NewBuffer(nil)
-
-			// 0-255  -255 255 ; 0-255=-255;
-			for i := 0; i < rows; i++ {
-				rowData := outData[rowLength*i : rowLength*(i+1)]
-				// Predicts the same as the sample to the left.
-				// Interleaved by colors.
-				for j := this.Colors; j < rowLength; j++ {
-					rowData[j] = byte(int(rowData[j]+rowData[j-this.Colors]) % 256)
-				}
-				pOutBuffer.Write(rowData)
-			}
-			pOutData := pOutBuffer.Bytes()
-			common.Log.Trace("POutData (%d): % x", len(pOutData), pOutData)
-			return pOutData, nil
-		} else if this.Predictor >= 10 && this.Predictor <= 15 {
-			common.Log.Trace("PNG Encoding")
-			// Columns represents the number of samples per row; Each sample can contain multiple color
-			// components.
-			rowLength := int(this.Columns*this.Colors + 1) // 1 byte to specify predictor algorithms per row.
-			rows := len(outData) / rowLength
-			if len(outData)%rowLength != 0 {
-				return nil, fmt.Errorf("Invalid row length (%d/%d)", len(outData), rowLength)
-			}
-			if rowLength > len(outData) {
-				common.Log.Debug("Row length cannot be longer than data length (%d/%d)", rowLength, len(outData))
-				return nil, errors.New("Range check error")
-			}
-
-			pOutBuffer := bytes.NewBuffer(nil)
-
-			common.Log.Trace("Predictor columns: %d", this.Columns)
-			common.Log.Trace("Length: %d / %d = %d rows", len(outData), rowLength, rows)
-			prevRowData := make([]byte, rowLength)
-			for i := 0; i < rowLength; i++ {
-				prevRowData[i] = 0
-			}
-
-			for i := 0; i < rows; i++ {
-				rowData := outData[rowLength*i : rowLength*(i+1)]
-
-				fb := rowData[0]
-				switch fb {
-				case 0:
-					// No prediction. (No operation).
-				case 1:
-					// Sub: Predicts the same as the sample to the left.
-					for j := 2; j < rowLength; j++ {
-						rowData[j] = byte(int(rowData[j]+rowData[j-1]) % 256)
-					}
-				case 2:
-					// Up: Predicts the same as the sample above
-					for j := 1; j < rowLength; j++ {
-						rowData[j] = byte(int(rowData[j]+prevRowData[j]) % 256)
-					}
-				case 3:
-					// Avg: Predicts the same as the average of the sample to the left and above.
-					for j := 1; j < rowLength; j++ {
-						if j == 1 {
-							rowData[j] = byte(int(rowData[j]+prevRowData[j]) % 256)
-						} else {
-							avg := (rowData[j-1] + prevRowData[j]) / 2
-							rowData[j] = byte(int(rowData[j]+avg) % 256)
-						}
-					}
-				case 4:
-					// Paeth: a nonlinear function of the sample above, the sample to the left and the sample
-					// to the upper left.
-					for j := 2; j < rowLength; j++ {
-						a := rowData[j-1]     // left
-						b := prevRowData[j]   // above
-						c := prevRowData[j-1] // upper left
-
-						p := int(a + b - c)
-						pa := absInt(p - int(a))
-						pb := absInt(p - int(b))
-						pc := absInt(p - int(c))
-
-						if pa <= pb && pa <= pc {
-							// Use a (left).
-							rowData[j] = byte(int(rowData[j]+a) % 256)
-						} else if pb <= pc {
-							// Use b (upper).
-							rowData[j] = byte(int(rowData[j]+b) % 256)
-						} else {
-							// Use c (upper left).
-							rowData[j] = byte(int(rowData[j]+c) % 256)
-						}
-					}
-
-				default:
-					common.Log.Debug("ERROR: Invalid filter byte (%d) @row %d", fb, i)
-					return nil, fmt.Errorf("Invalid filter byte (%d)", fb)
-				}
-
-				for i := 0; i < rowLength; i++ {
-					prevRowData[i] = rowData[i]
-				}
-				pOutBuffer.Write(rowData[1:])
-			}
-			pOutData := pOutBuffer.Bytes()
-			return pOutData, nil
-		} else {
-			common.Log.Debug("ERROR: Unsupported predictor (%d)", this.Predictor)
-			return nil, fmt.Errorf("Unsupported predictor (%d)", this.Predictor)
-		}
-	}
+	return this.DecodeBytes(streamObj.Stream)
+}
 
-	return outData, nil
+// predictorParams returns the parameters of the predictor of the encoder.
+func (this *FlateEncoder) predictorParams() predictorParams {
+	return predictorParams{predictor: this.Predictor, columns: this.Columns, colors: this.Colors,
+		bpc: this.BitsPerComponent}
 }
 
 // Encode a bytes array and return the encoded value based on the encoder parameters.
 func (this *FlateEncoder) EncodeBytes(data []byte) ([]byte, error) {
-	if this.Predictor != 1 && this.Predictor != 11 {
-		common.Log.Debug("Encoding error: FlateEncoder Predictor = 1, 11 only supported")
-		return nil, ErrUnsupportedEncodingParameters
-	}
-
-	if this.Predictor == 11 {
-		// The length of each output row in number of samples.
-		// N.B. Each output row has one extra sample as compared to the input to indicate the
-		// predictor type.
-		rowLength := int(this.Columns)
-		rows := len(data) / rowLength
-		if len(data)%rowLength != 0 {
-			common.Log.Error("Invalid column length")
-			return nil, errors.New("Invalid row length")
-		}
-
-		pOutBuffer := bytes.NewBuffer(nil)
-
-		tmpData := make([]byte, rowLength)
-
-		for i := 0; i < rows; i++ {
-			rowData := data[rowLength*i : rowLength*(i+1)]
-
-			// PNG SUB method.
-			// Sub: Predicts the same as the sample to the left.
-			tmpData[0] = rowData[0]
-			for j := 1; j < rowLength; j++ {
-				tmpData[j] = byte(int(rowData[j]-rowData[j-1]) % 256)
-			}
-
-			pOutBuffer.WriteByte(1) // sub method
-			pOutBuffer.Write(tmpData)
-		}
-
-		data = pOutBuffer.Bytes()
+	data, err := predictorEncode(data, this.predictorParams())
+	if err != nil {
+		common.Log.Debug("Encoding error: %v", err)
+		return nil, err
 	}
 
 	var b bytes.Buffer
@@ -463,7 +296,6 @@ func NewLZWEncoder() *LZWEncoder {
 	// Default (No prediction)
 	encoder.Predictor = 1
 
-	// Currently only supporting 8.
 	encoder.BitsPerComponent = 8
 
 	encoder.Colors = 1
@@ -550,7 +382,12 @@ func newLZWEncoderFromStream(streamObj *PdfObjectStream, decodeParams *PdfObject
 	// implementations use a different mechanisms. Essentially this chooses
 	// which LZW implementation to use.
 	// The default is 1 (one code early)
+	// It belongs to the decode parameters, but is also accepted in the stream dictionary.
 	obj := encDict.Get("EarlyChange")
+	if decodeParams != nil && decodeParams.Get("EarlyChange") != nil {
+		obj = decodeParams.Get("EarlyChange")
+	}
+	obj = TraceToDirectObject(obj)
 	if obj != nil {
 		earlyChange, ok := obj.(*PdfObjectInteger)
 		if !ok {
@@ -642,147 +479,39 @@ func (this *LZWEncoder) DecodeBytes(encoded []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return outBuf.Bytes(), nil
+	common.Log.Trace(" IN: (%d) % x", len(encoded), encoded)
+	common.Log.Trace("OUT: (%d) % x", outBuf.Len(), outBuf.Bytes())
+	common.Log.Trace("Predictor: %d", this.Predictor)
+
+	return predictorDecode(outBuf.Bytes(), this.predictorParams())
 }
 
 func (this *LZWEncoder) DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
-	// Revamp this support to handle TIFF predictor (2).
-	// Also handle more filter bytes and check
-	// BitsPerComponent.  Default value is 8, currently we are only
-	// supporting that one.
-
 	common.Log.Trace("LZW Decoding")
-	common.Log.Trace("Predictor: %d", this.Predictor)
-
-	outData, err := this.DecodeBytes(streamObj.Stream)
-	if err != nil {
-		return nil, err
-	}
-
-	common.Log.Trace(" IN: (%d) % x", len(streamObj.Stream), streamObj.Stream)
-	common.Log.Trace("OUT: (%d) % x", len(outData), outData)
-
-	if this.Predictor > 1 {
-		if this.Predictor == 2 { // TIFF encoding: Needs some tests.
-			common.Log.Trace("Tiff encoding")
-
-			rowLength := int(this.Columns) * this.Colors
-			if rowLength < 1 {
-				// No data. Return empty set.
-				return []byte{}, nil
-			}
-
-			rows := len(outData) / rowLength
-			if len(outData)%rowLength != 0 {
-				common.Log.Debug("ERROR: TIFF encoding: Invalid row length...")
-				return nil, fmt.Errorf("Invalid row length (%d/%d)", len(outData), rowLength)
-			}
-
-			if rowLength%this.Colors != 0 {
-				return nil, fmt.Errorf("Invalid row length (%d) for colors %d", rowLength, this.Colors)
-			}
-
-			if rowLength > len(outData) {
-				common.Log.Debug("Row length cannot be longer than data length (%d/%d)", rowLength, len(outData))
-				return nil, errors.New("Range check error")
-			}
-			common.Log.Trace("inp outData (%d): % x", len(outData), outData)
-
-			pOutBuffer := bytes.NewBuffer(nil)
-
-			// 0-255  -255 255 ; 0-255=-255;
-			for i := 0; i < rows; i++ {
-				rowData := outData[rowLength*i : rowLength*(i+1)]
-				// Predicts the same as the sample to the left.
-				// Interleaved by colors.
-				for j := this.Colors; j < rowLength; j++ {
-					rowData[j] = byte(int(rowData[j]+rowData[j-this.Colors]) % 256)
-				}
-				// GH: Appears that this is not working as expected...
-
-				pOutBuffer.Write(rowData)
-			}
-			pOutData := pOutBuffer.Bytes()
-			common.Log.Trace("POutData (%d): % x", len(pOutData), pOutData)
-			return pOutData, nil
-		} else if this.Predictor >= 10 && this.Predictor <= 15 {
-			common.Log.Trace("PNG Encoding")
-			// Columns represents the number of samples per row; Each sample can contain multiple color
-			// components.
-			rowLength := int(this.Columns*this.Colors + 1) // 1 byte to specify predictor algorithms per row.
-			if rowLength < 1 {
-				// No data. Return empty set.
-				return []byte{}, nil
-			}
-			rows := len(outData) / rowLength
-			if len(outData)%rowLength != 0 {
-				return nil, fmt.Errorf("Invalid row length (%d/%d)", len(outData), rowLength)
-			}
-			if rowLength > len(outData) {
-				common.Log.Debug("Row length cannot be longer than data length (%d/%d)", rowLength, len(outData))
-				return nil, errors.New("Range check error")
-			}
-
-			pOutBuffer := bytes.NewBuffer(nil)
-
-			common.Log.Trace("Predictor columns: %d", this.Columns)
-			common.Log.Trace("Length: %d / %d = %d rows", len(outData), rowLength, rows)
-			prevRowData := make([]byte, rowLength)
-			for i := 0; i < rowLength; i++ {
-				prevRowData[i] = 0
-			}
-
-			for i := 0; i < rows; i++ {
-				rowData := outData[rowLength*i : rowLength*(i+1)]
-
-				fb := rowData[0]
-				switch fb {
-				case 0:
-					// No prediction. (No operation).
-				case 1:
-					// Sub: Predicts the same as the sample to the left.
-					for j := 2; j < rowLength; j++ {
-						rowData[j] = byte(int(rowData[j]+rowData[j-1]) % 256)
-					}
-				case 2:
-					// Up: Predicts the same as the sample above
-					for j := 1; j < rowLength; j++ {
-						rowData[j] = byte(int(rowData[j]+prevRowData[j]) % 256)
-					}
-				default:
-					common.Log.Debug("ERROR: Invalid filter byte (%d)", fb)
-					return nil, fmt.Errorf("Invalid filter byte (%d)", fb)
-				}
-
-				for i := 0; i < rowLength; i++ {
-					prevRowData[i] = rowData[i]
-				}
-				pOutBuffer.Write(rowData[1:])
-			}
-			pOutData := pOutBuffer.Bytes()
-			return pOutData, nil
-		} else {
-			common.Log.Debug("ERROR: Unsupported predictor (%d)", this.Predictor)
-			return nil, fmt.Errorf("Unsupported predictor (%d)", this.Predictor)
-		}
-	}
+	return this.DecodeBytes(streamObj.Stream)
+}
 
-	return outData, nil
+// predictorParams returns the parameters of the predictor of the encoder.
+func (this *LZWEncoder) predictorParams() predictorParams {
+	return predictorParams{predictor: this.Predictor, columns: this.Columns, colors: this.Colors,
+		bpc: this.BitsPerComponent}
 }
 
-// Support for encoding LZW.  Currently not supporting predictors (raw compressed data only).
-// Only supports the Early change = 1 algorithm (compress/lzw) as the other implementation
+// Support for encoding LZW, with the predictor applied first.
+// Only supports the Early change = 0 algorithm (compress/lzw) as the other implementation
 // does not have a write method.
 // TODO: Consider refactoring compress/lzw to allow both.
 func (this *LZWEncoder) EncodeBytes(data []byte) ([]byte, error) {
-	if this.Predictor != 1 {
-		return nil, fmt.Errorf("LZW Predictor = 1 only supported yet")
-	}
-
 	if this.EarlyChange == 1 {
 		return nil, fmt.Errorf("LZW Early Change = 0 only supported yet")
 	}
 
+	data, err := predictorEncode(data, this.predictorParams())
+	if err != nil {
+		common.Log.Debug("Encoding error: %v", err)
+		return nil, err
+	}
+
 	var b bytes.Buffer
 	w := lzw0.NewWriter(&b, lzw0.MSB, 8)
 	w.Write(data)
@@ -834,8 +563,7 @@ func (this *DCTEncoder) MakeStreamDict() *PdfObjectDictionary {
 
 // Create a new DCT encoder/decoder from a stream object, getting all the encoding parameters
 // from the stream object dictionary entry and the image data itself.
-// TODO: Support if used with other filters [ASCII85Decode FlateDecode DCTDecode]...
-// need to apply the other filters prior to this one...
+// If used with other filters, e.g. [ASCII85Decode DCTDecode], multiEnc has the filters to apply before this one.
 func newDCTEncoderFromStream(streamObj *PdfObjectStream, multiEnc *MultiEncoder) (*DCTEncoder, error) {
 	// Start with default settings.
 	encoder := NewDCTEncoder()
@@ -847,17 +575,17 @@ func newDCTEncoderFromStream(streamObj *PdfObjectStream, multiEnc *MultiEncoder)
 	}
 
 	// If using DCTDecode in combination with other filters, make sure to decode that first...
-	encoded := streamObj.Stream
+	// Only the beginning of the data is read for the configuration, lazy streams are not loaded.
+	var bufReader io.Reader
+	var err error
 	if multiEnc != nil {
-		e, err := multiEnc.DecodeBytes(encoded)
-		if err != nil {
-			return nil, err
-		}
-		encoded = e
-
+		bufReader, err = newDecodeReader(multiEnc, streamObj)
+	} else {
+		bufReader, err = streamObj.RawReader()
+	}
+	if err != nil {
+		return nil, err
 	}
-
-	bufReader := bytes.NewReader(encoded)
 
 	cfg, err := jpeg.DecodeConfig(bufReader)
 	//img, _, err := goimage.Decode(bufReader)
@@ -1525,12 +1253,93 @@ func (this *RawEncoder) EncodeBytes(data []byte) ([]byte, error) {
 }
 
 //
-// CCITTFax encoder/decoder (dummy, for now)
+// CCITTFax encoder/decoder
 //
-type CCITTFaxEncoder struct{}
+type CCITTFaxEncoder struct {
+	// K < 0: Pure two-dimensional encoding (Group 4), K = 0: one-dimensional encoding (Group 3, 1-D),
+	// K > 0: Mixed one- and two-dimensional encoding (Group 3, 2-D).
+	K int
+
+	// Whether end of line codes are required to be present.
+	EndOfLine bool
+
+	// Whether each encoded row begins at a byte boundary.
+	EncodedByteAlign bool
 
+	// Width and height of the image in pixels.  Rows may be 0 (unknown).
+	Columns int
+	Rows    int
+
+	// Whether the data is terminated by an end of block pattern.
+	EndOfBlock bool
+
+	// Whether 1 bits represent black pixels (otherwise 0 bits are black).
+	BlackIs1 bool
+
+	// Number of damaged rows tolerated.
+	DamagedRowsBeforeError int
+}
+
+// NewCCITTFaxEncoder makes a new CCITTFax encoder with default parameters (Group 3 1-D, 1728 columns).
 func NewCCITTFaxEncoder() *CCITTFaxEncoder {
-	return &CCITTFaxEncoder{}
+	encoder := &CCITTFaxEncoder{}
+	encoder.Columns = 1728
+	encoder.EndOfBlock = true
+	return encoder
+}
+
+// Create a new CCITTFax decoder from a stream object, getting the encoding parameters from the DecodeParms
+// stream object dictionary entry.
+func newCCITTFaxEncoderFromStream(streamObj *PdfObjectStream, decodeParams *PdfObjectDictionary) (*CCITTFaxEncoder,
+	error) {
+	encoder := NewCCITTFaxEncoder()
+
+	encDict := streamObj.PdfObjectDictionary
+	if encDict == nil {
+		return encoder, nil
+	}
+
+	// If decodeParams not provided, see if we can get from the stream.
+	if decodeParams == nil {
+		obj := TraceToDirectObject(encDict.Get("DecodeParms"))
+		if arr, isArr := obj.(*PdfObjectArray); isArr {
+			if len(*arr) != 1 {
+				common.Log.Debug("Error: DecodeParms array length != 1 (%d)", len(*arr))
+				return nil, errors.New("Range check error")
+			}
+			obj = TraceToDirectObject((*arr)[0])
+		}
+		if dp, isDict := obj.(*PdfObjectDictionary); isDict {
+			decodeParams = dp
+		}
+	}
+	if decodeParams == nil {
+		return encoder, nil
+	}
+
+	getInt := func(key PdfObjectName, def int) int {
+		if val, ok := TraceToDirectObject(decodeParams.Get(key)).(*PdfObjectInteger); ok {
+			return int(*val)
+		}
+		return def
+	}
+	getBool := func(key PdfObjectName, def bool) bool {
+		if val, ok := TraceToDirectObject(decodeParams.Get(key)).(*PdfObjectBool); ok {
+			return bool(*val)
+		}
+		return def
+	}
+	encoder.K = getInt("K", encoder.K)
+	encoder.EndOfLine = getBool("EndOfLine", encoder.EndOfLine)
+	encoder.EncodedByteAlign = getBool("EncodedByteAlign", encoder.EncodedByteAlign)
+	encoder.Columns = getInt("Columns", encoder.Columns)
+	encoder.Rows = getInt("Rows", encoder.Rows)
+	encoder.EndOfBlock = getBool("EndOfBlock", encoder.EndOfBlock)
+	encoder.BlackIs1 = getBool("BlackIs1", encoder.BlackIs1)
+	encoder.DamagedRowsBeforeError = getInt("DamagedRowsBeforeError", encoder.DamagedRowsBeforeError)
+
+	common.Log.Trace("CCITTFax encoder: %+v", encoder)
+	return encoder, nil
 }
 
 func (this *CCITTFaxEncoder) GetFilterName() string {
@@ -1538,38 +1347,103 @@ func (this *CCITTFaxEncoder) GetFilterName() string {
 }
 
 func (this *CCITTFaxEncoder) MakeDecodeParams() PdfObject {
-	return nil
+	decodeParams := MakeDict()
+	if this.K != 0 {
+		decodeParams.Set("K", MakeInteger(int64(this.K)))
+	}
+	if this.EndOfLine {
+		decodeParams.Set("EndOfLine", MakeBool(true))
+	}
+	if this.EncodedByteAlign {
+		decodeParams.Set("EncodedByteAlign", MakeBool(true))
+	}
+	if this.Columns != 1728 {
+		decodeParams.Set("Columns", MakeInteger(int64(this.Columns)))
+	}
+	if this.Rows != 0 {
+		decodeParams.Set("Rows", MakeInteger(int64(this.Rows)))
+	}
+	if !this.EndOfBlock {
+		decodeParams.Set("EndOfBlock", MakeBool(false))
+	}
+	if this.BlackIs1 {
+		decodeParams.Set("BlackIs1", MakeBool(true))
+	}
+	if len(decodeParams.Keys()) == 0 {
+		return nil
+	}
+	return decodeParams
 }
 
 // Make a new instance of an encoding dictionary for a stream object.
 func (this *CCITTFaxEncoder) MakeStreamDict() *PdfObjectDictionary {
-	return MakeDict()
+	dict := MakeDict()
+	dict.Set("Filter", MakeName(this.GetFilterName()))
+
+	decodeParams := this.MakeDecodeParams()
+	if decodeParams != nil {
+		dict.Set("DecodeParms", decodeParams)
+	}
+
+	return dict
 }
 
+// DecodeBytes decodes CCITT encoded data into image data with 1 bit per pixel.
 func (this *CCITTFaxEncoder) DecodeBytes(encoded []byte) ([]byte, error) {
-	common.Log.Debug("Error: Attempting to use unsupported encoding %s", this.GetFilterName())
-	return encoded, ErrNoCCITTFaxDecode
+	return ccittDecode(encoded, this)
 }
 
 func (this *CCITTFaxEncoder) DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
-	common.Log.Debug("Error: Attempting to use unsupported encoding %s", this.GetFilterName())
-	return streamObj.Stream, ErrNoCCITTFaxDecode
+	return this.DecodeBytes(streamObj.Stream)
 }
 
+// EncodeBytes encodes image data with 1 bit per pixel, each row padded to a whole byte, Columns pixels wide.  Rows
+// is the number of rows encoded if not 0, all the rows of the data otherwise.
 func (this *CCITTFaxEncoder) EncodeBytes(data []byte) ([]byte, error) {
-	common.Log.Debug("Error: Attempting to use unsupported encoding %s", this.GetFilterName())
-	return data, ErrNoCCITTFaxDecode
+	return ccittEncode(data, this)
 }
 
 //
-// JBIG2 encoder/decoder (dummy, for now)
+// JBIG2 encoder/decoder (decoding only)
 //
-type JBIG2Encoder struct{}
+type JBIG2Encoder struct {
+	// The decoded data of the JBIG2Globals stream: the segments shared by several images, e.g. symbol dictionaries.
+	Globals []byte
+}
 
 func NewJBIG2Encoder() *JBIG2Encoder {
 	return &JBIG2Encoder{}
 }
 
+// Create a new JBIG2 decoding encoder from a stream object, getting the JBIG2Globals stream from the decode parameters.
+func newJBIG2EncoderFromStream(streamObj *PdfObjectStream, decodeParams *PdfObjectDictionary) (*JBIG2Encoder, error) {
+	encoder := NewJBIG2Encoder()
+
+	// If decodeParams not provided, see if we can get from the stream.
+	if decodeParams == nil && streamObj.PdfObjectDictionary != nil {
+		obj := TraceToDirectObject(streamObj.PdfObjectDictionary.Get("DecodeParms"))
+		if arr, isArr := obj.(*PdfObjectArray); isArr && len(*arr) == 1 {
+			obj = TraceToDirectObject((*arr)[0])
+		}
+		if dp, isDict := obj.(*PdfObjectDictionary); isDict {
+			decodeParams = dp
+		}
+	}
+	if decodeParams == nil {
+		return encoder, nil
+	}
+
+	if globals, ok := TraceToDirectObject(decodeParams.Get("JBIG2Globals")).(*PdfObjectStream); ok {
+		data, err := DecodeStream(globals)
+		if err != nil {
+			common.Log.Debug("ERROR: Failed decoding the JBIG2Globals stream: %v", err)
+			return nil, err
+		}
+		encoder.Globals = data
+	}
+	return encoder, nil
+}
+
 func (this *JBIG2Encoder) GetFilterName() string {
 	return StreamEncodingFilterNameJBIG2
 }
@@ -1583,14 +1457,13 @@ func (this *JBIG2Encoder) MakeStreamDict() *PdfObjectDictionary {
 	return MakeDict()
 }
 
+// DecodeBytes decodes JBIG2 encoded data into image data with 1 bit per pixel, 0 being black.
 func (this *JBIG2Encoder) DecodeBytes(encoded []byte) ([]byte, error) {
-	common.Log.Debug("Error: Attempting to use unsupported encoding %s", this.GetFilterName())
-	return encoded, ErrNoJBIG2Decode
+	return jbig2Decode(encoded, this.Globals)
 }
 
 func (this *JBIG2Encoder) DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
-	common.Log.Debug("Error: Attempting to use unsupported encoding %s", this.GetFilterName())
-	return streamObj.Stream, ErrNoJBIG2Decode
+	return this.DecodeBytes(streamObj.Stream)
 }
 
 func (this *JBIG2Encoder) EncodeBytes(data []byte) ([]byte, error) {
@@ -1635,6 +1508,79 @@ func (this *JPXEncoder) EncodeBytes(data []byte) ([]byte, error) {
 	return data, ErrNoJPXDecode
 }
 
+//
+// Crypt filter (7.4.10): the data is encrypted with the crypt filter of the security handler named in the decode
+// parameters, Identity (not encrypted) by default.  The data is encrypted and decrypted by the security handler
+// when the document is written and read, so the filter passes it unchanged.
+//
+type CryptEncoder struct {
+	// Name of the crypt filter in the CF dictionary of the encryption dictionary.
+	Name string
+}
+
+func NewCryptEncoder() *CryptEncoder {
+	return &CryptEncoder{Name: "Identity"}
+}
+
+// Create a new crypt filter encoder from a stream object, getting the name of the crypt filter from the decode
+// parameters.
+func newCryptEncoderFromStream(streamObj *PdfObjectStream, decodeParams *PdfObjectDictionary) (*CryptEncoder, error) {
+	encoder := NewCryptEncoder()
+	if decodeParams == nil {
+		return encoder, nil
+	}
+
+	obj := TraceToDirectObject(decodeParams.Get("Name"))
+	if obj == nil {
+		return encoder, nil
+	}
+	name, ok := obj.(*PdfObjectName)
+	if !ok {
+		common.Log.Debug("ERROR: Crypt filter name not a name (%T)", obj)
+		return nil, fmt.Errorf("Invalid crypt filter name")
+	}
+	encoder.Name = string(*name)
+	return encoder, nil
+}
+
+func (this *CryptEncoder) GetFilterName() string {
+	return StreamEncodingFilterNameCrypt
+}
+
+func (this *CryptEncoder) MakeDecodeParams() PdfObject {
+	if this.Name == "" || this.Name == "Identity" {
+		return nil
+	}
+	decodeParams := MakeDict()
+	decodeParams.Set("Type", MakeName("CryptFilterDecodeParms"))
+	decodeParams.Set("Name", MakeName(this.Name))
+	return decodeParams
+}
+
+// Make a new instance of an encoding dictionary for a stream object.
+func (this *CryptEncoder) MakeStreamDict() *PdfObjectDictionary {
+	dict := MakeDict()
+	dict.Set("Filter", MakeName(this.GetFilterName()))
+
+	decodeParams := this.MakeDecodeParams()
+	if decodeParams != nil {
+		dict.Set("DecodeParms", decodeParams)
+	}
+	return dict
+}
+
+func (this *CryptEncoder) DecodeBytes(encoded []byte) ([]byte, error) {
+	return encoded, nil
+}
+
+func (this *CryptEncoder) DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
+	return streamObj.Stream, nil
+}
+
+func (this *CryptEncoder) EncodeBytes(data []byte) ([]byte, error) {
+	return data, nil
+}
+
 //
 // Multi encoder: support serial encoding.
 //
@@ -1650,110 +1596,24 @@ func NewMultiEncoder() *MultiEncoder {
 	return &encoder
 }
 
+// Create a new multi encoder from a stream object, with the encoders of its filters and their decode parameters,
+// in the order they are applied to decode the data.
 func newMultiEncoderFromStream(streamObj *PdfObjectStream) (*MultiEncoder, error) {
 	mencoder := NewMultiEncoder()
 
-	encDict := streamObj.PdfObjectDictionary
-	if encDict == nil {
-		// No encoding dictionary.
-		return mencoder, nil
-	}
-
-	// Prepare the decode params array (one for each filter type)
-	// Optional, not always present.
-	var decodeParamsDict *PdfObjectDictionary
-	decodeParamsArray := []PdfObject{}
-	obj := encDict.Get("DecodeParms")
-	if obj != nil {
-		// If it is a dictionary, assume it applies to all
-		dict, isDict := obj.(*PdfObjectDictionary)
-		if isDict {
-			decodeParamsDict = dict
-		}
-
-		// If it is an array, assume there is one for each
-		arr, isArray := obj.(*PdfObjectArray)
-		if isArray {
-			for _, dictObj := range *arr {
-				dictObj = TraceToDirectObject(dictObj)
-				if dict, is := dictObj.(*PdfObjectDictionary); is {
-					decodeParamsArray = append(decodeParamsArray, dict)
-				} else {
-					decodeParamsArray = append(decodeParamsArray, MakeDict())
-				}
-			}
-		}
-	}
-
-	obj = encDict.Get("Filter")
-	if obj == nil {
-		return nil, fmt.Errorf("Filter missing")
-	}
-
-	array, ok := obj.(*PdfObjectArray)
-	if !ok {
-		return nil, fmt.Errorf("Multi filter can only be made from array")
+	filters, decodeParams, err := getStreamFilters(streamObj.PdfObjectDictionary)
+	if err != nil {
+		return nil, err
 	}
 
-	for idx, obj := range *array {
-		name, ok := obj.(*PdfObjectName)
-		if !ok {
-			return nil, fmt.Errorf("Multi filter array element not a name")
-		}
-
-		var dp PdfObject
-
-		// If decode params dict is set, use it.  Otherwise take from array..
-		if decodeParamsDict != nil {
-			dp = decodeParamsDict
-		} else {
-			// Only get the dp if provided.  Oftentimes there is no decode params dict
-			// provided.
-			if len(decodeParamsArray) > 0 {
-				if idx >= len(decodeParamsArray) {
-					return nil, fmt.Errorf("Missing elements in decode params array")
-				}
-				dp = decodeParamsArray[idx]
-			}
-		}
-
-		var dParams *PdfObjectDictionary
-		if dict, is := dp.(*PdfObjectDictionary); is {
-			dParams = dict
-		}
-
-		common.Log.Trace("Next name: %s, dp: %v, dParams: %v", *name, dp, dParams)
-		if *name == StreamEncodingFilterNameFlate {
-			// XXX: need to separate out the DecodeParms..
-			encoder, err := newFlateEncoderFromStream(streamObj, dParams)
-			if err != nil {
-				return nil, err
-			}
-			mencoder.AddEncoder(encoder)
-		} else if *name == StreamEncodingFilterNameLZW {
-			encoder, err := newLZWEncoderFromStream(streamObj, dParams)
-			if err != nil {
-				return nil, err
-			}
-			mencoder.AddEncoder(encoder)
-		} else if *name == StreamEncodingFilterNameASCIIHex {
-			encoder := NewASCIIHexEncoder()
-			mencoder.AddEncoder(encoder)
-		} else if *name == StreamEncodingFilterNameASCII85 {
-			encoder := NewASCII85Encoder()
-			mencoder.AddEncoder(encoder)
-		} else if *name == StreamEncodingFilterNameDCT {
-			encoder, err := newDCTEncoderFromStream(streamObj, mencoder)
-			if err != nil {
-				return nil, err
-			}
-			mencoder.AddEncoder(encoder)
-			common.Log.Trace("Added DCT encoder...")
-			common.Log.Trace("Multi encoder: %#v", mencoder)
-		} else {
-			common.Log.Error("Unsupported filter %s", *name)
-			return nil, fmt.Errorf("Invalid filter in multi filter array")
+	for idx, name := range filters {
+		common.Log.Trace("Next name: %s, dParams: %v", name, decodeParams[idx])
+		encoder, err := newEncoderFromFilter(streamObj, name, decodeParams[idx], mencoder)
+		if err != nil {
+			common.Log.Debug("ERROR: Filter %s in multi filter array: %v", name, err)
+			return nil, err
 		}
+		mencoder.AddEncoder(encoder)
 	}
 
 	return mencoder, nil
@@ -1798,7 +1658,12 @@ func (this *MultiEncoder) AddEncoder(encoder StreamEncoder) {
 
 func (this *MultiEncoder) MakeStreamDict() *PdfObjectDictionary {
 	dict := MakeDict()
-	dict.Set("Filter", MakeName(this.GetFilterName()))
+
+	filters := PdfObjectArray{}
+	for _, encoder := range this.encoders {
+		filters = append(filters, MakeName(encoder.GetFilterName()))
+	}
+	dict.Set("Filter", &filters)
 
 	// Pass all values from children, except Filter and DecodeParms.
 	for _, encoder := range this.encoders {
@@ -7,7 +7,9 @@ package core
 
 import (
 	"encoding/base64"
+	"io/ioutil"
 	"testing"
+	"testing/iotest"
 
 	"github.com/unidoc/unidoc/common"
 )
@@ -245,3 +247,246 @@ func TestMultiEncoder(t *testing.T) {
 		return
 	}
 }
+
+// Test CCITT fax decoding of a 8x2 image with two white pixels, three black and three white on each row.
+func TestCCITTFaxDecoding(t *testing.T) {
+	expected := []byte{0xC7, 0xC7}
+
+	testcases := []struct {
+		K       int
+		Encoded []byte
+	}{
+		// One-dimensional coding: white 2 (0111), black 3 (10), white 3 (1000) on each row.
+		{0, []byte{0x7A, 0x1E, 0x80}},
+		// Group 4: horizontal mode and V0 on the first row, V0 only on the second one, followed by the EOFB.
+		{-1, []byte{0x2F, 0x78, 0x00, 0x80, 0x08}},
+	}
+
+	for _, tcase := range testcases {
+		encoder := NewCCITTFaxEncoder()
+		encoder.K = tcase.K
+		encoder.Columns = 8
+		encoder.Rows = 2
+
+		decoded, err := encoder.DecodeBytes(tcase.Encoded)
+		if err != nil {
+			t.Errorf("K=%d: failed to decode data: %v", tcase.K, err)
+			return
+		}
+		if !compareSlices(decoded, expected) {
+			t.Errorf("K=%d: slices not matching (% x vs % x)", tcase.K, decoded, expected)
+			return
+		}
+
+		// BlackIs1 inverts the decoded bits.
+		encoder.BlackIs1 = true
+		decoded, err = encoder.DecodeBytes(tcase.Encoded)
+		if err != nil {
+			t.Errorf("K=%d: failed to decode data: %v", tcase.K, err)
+			return
+		}
+		if !compareSlices(decoded, []byte{0x38, 0x38}) {
+			t.Errorf("K=%d: slices not matching (% x)", tcase.K, decoded)
+			return
+		}
+	}
+}
+
+// Test CCITT fax encoding of the image of TestCCITTFaxDecoding.
+func TestCCITTFaxEncoding(t *testing.T) {
+	testcases := []struct {
+		K          int
+		EndOfBlock bool
+		Encoded    []byte
+	}{
+		{0, false, []byte{0x7A, 0x1E, 0x80}},
+		{-1, true, []byte{0x2F, 0x78, 0x00, 0x80, 0x08}},
+	}
+
+	for _, tcase := range testcases {
+		encoder := NewCCITTFaxEncoder()
+		encoder.K = tcase.K
+		encoder.Columns = 8
+		encoder.EndOfBlock = tcase.EndOfBlock
+
+		encoded, err := encoder.EncodeBytes([]byte{0xC7, 0xC7})
+		if err != nil {
+			t.Fatalf("K=%d: failed to encode data: %v", tcase.K, err)
+		}
+		if !compareSlices(encoded, tcase.Encoded) {
+			t.Errorf("K=%d: slices not matching (% x vs % x)", tcase.K, encoded, tcase.Encoded)
+		}
+	}
+}
+
+// Test CCITT fax encoding and decoding back with the encoding parameters.
+func TestCCITTFaxEncodingRoundTrip(t *testing.T) {
+	testcases := []struct {
+		K                int
+		EndOfLine        bool
+		EncodedByteAlign bool
+		EndOfBlock       bool
+		BlackIs1         bool
+		Columns          int
+	}{
+		{-1, false, false, true, false, 203},
+		{-1, false, true, true, true, 203},
+		{-1, false, false, false, false, 3000},
+		{0, false, false, true, false, 203},
+		{0, true, true, true, true, 203},
+		{0, false, true, false, false, 3000},
+		{4, false, false, true, false, 203},
+		{4, true, false, true, true, 203},
+		{4, true, true, false, false, 203},
+	}
+
+	for _, tcase := range testcases {
+		// Rows of random runs, similar to the previous row.
+		rowBytes := (tcase.Columns + 7) / 8
+		rows := 60
+		data := make([]byte, rowBytes*rows)
+		seed := uint32(tcase.Columns)
+		white := byte(1)
+		if tcase.BlackIs1 {
+			white = 0
+		}
+		for y := 0; y < rows; y++ {
+			bit := white
+			for x := 0; x < rowBytes*8; x++ {
+				seed = seed*1103515245 + 12345
+				if x >= tcase.Columns {
+					// Padded with white as decoded.
+					bit = white
+				} else if y > 0 && seed>>16%4 != 0 {
+					bit = data[(y-1)*rowBytes+x/8] >> uint(7-x%8) & 1
+				} else if seed>>20%32 == 0 {
+					bit ^= 1
+				}
+				data[y*rowBytes+x/8] |= bit << uint(7-x%8)
+			}
+		}
+
+		encoder := NewCCITTFaxEncoder()
+		encoder.K = tcase.K
+		encoder.EndOfLine = tcase.EndOfLine
+		encoder.EncodedByteAlign = tcase.EncodedByteAlign
+		encoder.EndOfBlock = tcase.EndOfBlock
+		encoder.BlackIs1 = tcase.BlackIs1
+		encoder.Columns = tcase.Columns
+		encoded, err := encoder.EncodeBytes(data)
+		if err != nil {
+			t.Fatalf("%+v: failed to encode data: %v", tcase, err)
+		}
+		if len(encoded) >= len(data) {
+			t.Errorf("%+v: data not compressed (%d bytes)", tcase, len(encoded))
+		}
+
+		// Decoded with the parameters of the stream dictionary.
+		stream := &PdfObjectStream{PdfObjectDictionary: encoder.MakeStreamDict(), Stream: encoded}
+		decoded, err := DecodeStream(stream)
+		if err != nil {
+			t.Fatalf("%+v: failed to decode data: %v", tcase, err)
+		}
+		if !compareSlices(decoded, data) {
+			t.Errorf("%+v: slices not matching", tcase)
+		}
+	}
+}
+
+// Test the predictors of the Flate and LZW encoders, decoded back with the stream dictionary.
+func TestPredictorEncoding(t *testing.T) {
+	testcases := []struct {
+		Predictor        int
+		Colors           int
+		BitsPerComponent int
+	}{
+		{2, 3, 8}, {2, 1, 16}, {2, 3, 4}, {2, 1, 1},
+		{10, 3, 8}, {11, 3, 8}, {12, 3, 8}, {13, 3, 8}, {14, 3, 8}, {15, 3, 8},
+		{15, 1, 16}, {15, 1, 4}, {14, 4, 2},
+	}
+
+	columns, rows := 37, 20
+	for _, tcase := range testcases {
+		// Smooth gradients, compressing much better with predictors.
+		rowBytes := (columns*tcase.Colors*tcase.BitsPerComponent + 7) / 8
+		data := make([]byte, rowBytes*rows)
+		for y := 0; y < rows; y++ {
+			for x := 0; x < rowBytes; x++ {
+				data[y*rowBytes+x] = byte(3*x + 5*y + x*y/7)
+			}
+		}
+
+		for _, lzw := range []bool{false, true} {
+			var encoder StreamEncoder
+			if lzw {
+				lzwEncoder := NewLZWEncoder()
+				lzwEncoder.EarlyChange = 0
+				lzwEncoder.Predictor = tcase.Predictor
+				lzwEncoder.Colors = tcase.Colors
+				lzwEncoder.BitsPerComponent = tcase.BitsPerComponent
+				lzwEncoder.Columns = columns
+				encoder = lzwEncoder
+			} else {
+				flateEncoder := NewFlateEncoder()
+				flateEncoder.Predictor = tcase.Predictor
+				flateEncoder.Colors = tcase.Colors
+				flateEncoder.BitsPerComponent = tcase.BitsPerComponent
+				flateEncoder.Columns = columns
+				encoder = flateEncoder
+			}
+
+			encoded, err := encoder.EncodeBytes(data)
+			if err != nil {
+				t.Fatalf("%+v, LZW %v: failed to encode data: %v", tcase, lzw, err)
+			}
+			stream := &PdfObjectStream{PdfObjectDictionary: encoder.MakeStreamDict(), Stream: encoded}
+			decoded, err := DecodeStream(stream)
+			if err != nil {
+				t.Fatalf("%+v, LZW %v: failed to decode data: %v", tcase, lzw, err)
+			}
+			if !compareSlices(decoded, data) {
+				t.Errorf("%+v, LZW %v: slices not matching", tcase, lzw)
+			}
+
+			// Decoded row by row as read.
+			r, err := NewDecodeReader(stream)
+			if err != nil {
+				t.Fatalf("%+v, LZW %v: failed to decode data: %v", tcase, lzw, err)
+			}
+			decoded, err = ioutil.ReadAll(iotest.OneByteReader(r))
+			if err != nil {
+				t.Fatalf("%+v, LZW %v: failed to read data: %v", tcase, lzw, err)
+			}
+			if !compareSlices(decoded, data) {
+				t.Errorf("%+v, LZW %v: read slices not matching", tcase, lzw)
+			}
+		}
+	}
+
+	// The PNG predictor of each row compresses better than no prediction.
+	data := make([]byte, 300*100*3)
+	for i := range data {
+		data[i] = byte(i%900/3 + i/900)
+	}
+	encoder := NewFlateEncoder()
+	plain, err := encoder.EncodeBytes(data)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	encoder.Predictor = 15
+	encoder.Colors = 3
+	encoder.Columns = 300
+	predicted, err := encoder.EncodeBytes(data)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(predicted) >= len(plain) {
+		t.Errorf("Predictor not compressing better (%d >= %d)", len(predicted), len(plain))
+	}
+
+	// Rows of the wrong length.
+	encoder.Columns = 299
+	if _, err := encoder.EncodeBytes(data); err == nil {
+		t.Errorf("No error with rows of the wrong length")
+	}
+}
@@ -0,0 +1,360 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Inspection of the objects of a document, e.g. for debugging and tooling: iteration over the indirect objects with
+// their classification (ObjectIterator), resolution of the references when needed (Resolve) and printing of the
+// objects in the PDF syntax (PrintObject).
+
+// ObjectClass is the class of a PDF object (7.3).
+type ObjectClass string
+
+// Object classes.
+const (
+	ClassNull       ObjectClass = "null"
+	ClassBoolean    ObjectClass = "boolean"
+	ClassInteger    ObjectClass = "integer"
+	ClassReal       ObjectClass = "real"
+	ClassString     ObjectClass = "string"
+	ClassName       ObjectClass = "name"
+	ClassArray      ObjectClass = "array"
+	ClassDictionary ObjectClass = "dictionary"
+	ClassStream     ObjectClass = "stream"
+	ClassReference  ObjectClass = "reference"
+)
+
+// ClassOf returns the class of an object, of its contents for indirect objects.
+func ClassOf(obj PdfObject) ObjectClass {
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		return ClassOf(t.PdfObject)
+	case *PdfObjectBool:
+		return ClassBoolean
+	case *PdfObjectInteger:
+		return ClassInteger
+	case *PdfObjectFloat:
+		return ClassReal
+	case *PdfObjectString:
+		return ClassString
+	case *PdfObjectName:
+		return ClassName
+	case *PdfObjectArray:
+		return ClassArray
+	case *PdfObjectDictionary:
+		return ClassDictionary
+	case *PdfObjectStream:
+		return ClassStream
+	case *PdfObjectReference:
+		return ClassReference
+	}
+	return ClassNull
+}
+
+// ObjectInfo describes an indirect object of a document: its location and classification.
+type ObjectInfo struct {
+	ObjectNumber     int64
+	GenerationNumber int64
+
+	// Offset of the object in the file, 0 for the objects of object streams, which are identified by the number of
+	// their object stream (0 otherwise) and their index in it.
+	Offset       int64
+	ObjectStream int64
+	StreamIndex  int
+
+	Class ObjectClass
+	// Type and Subtype entries of dictionaries and streams, empty if none.
+	Type    string
+	Subtype string
+}
+
+func (info ObjectInfo) String() string {
+	str := fmt.Sprintf("%d %d obj: %s", info.ObjectNumber, info.GenerationNumber, info.Class)
+	if info.Type != "" {
+		str += " /" + info.Type
+	}
+	if info.Subtype != "" {
+		str += " /" + info.Subtype
+	}
+	return str
+}
+
+// ObjectIterator iterates over the indirect objects of a document by object number.  The objects are parsed when
+// reached, and the references in them are not resolved (see PdfParser.Resolve).
+//
+//	it := parser.Objects()
+//	for it.Next() {
+//		if it.Err() != nil {
+//			// Object which could not be parsed.
+//			continue
+//		}
+//		fmt.Println(it.Info())
+//	}
+type ObjectIterator struct {
+	parser *PdfParser
+	nums   []int
+	pos    int
+
+	obj  PdfObject
+	info ObjectInfo
+	err  error
+}
+
+// Objects returns an iterator over the indirect objects of the document.
+func (parser *PdfParser) Objects() *ObjectIterator {
+	return &ObjectIterator{parser: parser, nums: parser.GetObjectNums(), pos: -1}
+}
+
+// Next advances to the next object, and returns false when there are no more objects.
+func (it *ObjectIterator) Next() bool {
+	it.pos++
+	if it.pos >= len(it.nums) {
+		it.obj, it.info, it.err = nil, ObjectInfo{}, nil
+		return false
+	}
+
+	num := it.nums[it.pos]
+	xref := it.parser.xrefs[num]
+	it.info = ObjectInfo{ObjectNumber: int64(num), GenerationNumber: int64(xref.generation)}
+	if xref.xtype == XREF_OBJECT_STREAM {
+		it.info.ObjectStream = int64(xref.osObjNumber)
+		it.info.StreamIndex = xref.osObjIndex
+	} else {
+		it.info.Offset = xref.offset
+	}
+
+	it.obj, it.err = it.parser.LookupByNumber(num)
+	if it.err != nil {
+		it.obj = nil
+		return true
+	}
+	it.info.Class = ClassOf(it.obj)
+	var dict *PdfObjectDictionary
+	switch t := it.obj.(type) {
+	case *PdfIndirectObject:
+		dict, _ = t.PdfObject.(*PdfObjectDictionary)
+	case *PdfObjectStream:
+		dict = t.PdfObjectDictionary
+	}
+	if dict != nil {
+		if name, ok := dict.Get("Type").(*PdfObjectName); ok {
+			it.info.Type = string(*name)
+		}
+		if name, ok := dict.Get("Subtype").(*PdfObjectName); ok {
+			it.info.Subtype = string(*name)
+		}
+	}
+	return true
+}
+
+// Object returns the current object: an indirect object or a stream, nil if it could not be parsed.
+func (it *ObjectIterator) Object() PdfObject {
+	return it.obj
+}
+
+// Info returns the description of the current object.  Only the number and location are set if the object could
+// not be parsed.
+func (it *ObjectIterator) Info() ObjectInfo {
+	return it.info
+}
+
+// Err returns the error parsing the current object, nil if it was parsed.
+func (it *ObjectIterator) Err() error {
+	return it.err
+}
+
+// Resolve returns the object referenced by obj as found in the document, an indirect object or a stream, looking it
+// up if obj is a reference (indirect objects containing a reference are resolved in turn).  Other objects are
+// returned as is.  References to missing objects resolve to null.
+func (parser *PdfParser) Resolve(obj PdfObject) (PdfObject, error) {
+	visited := map[int64]bool{}
+	for {
+		ref, ok := obj.(*PdfObjectReference)
+		if !ok {
+			if ind, isInd := obj.(*PdfIndirectObject); isInd {
+				if ref, ok = ind.PdfObject.(*PdfObjectReference); !ok {
+					return obj, nil
+				}
+			} else {
+				return obj, nil
+			}
+		}
+		if visited[ref.ObjectNumber] {
+			return nil, errors.New("Circular reference")
+		}
+		visited[ref.ObjectNumber] = true
+
+		var err error
+		obj, err = parser.LookupByReference(*ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// StreamDataMode is how the data of streams is printed by PrintObject.
+type StreamDataMode int
+
+// Stream data modes.
+const (
+	StreamDataNone    StreamDataMode = iota // Only the length of the data, as a comment.
+	StreamDataRaw                           // The data as in the file (encoded).
+	StreamDataDecoded                       // The decoded data.
+)
+
+// PrintOptions are the options of PrintObject.
+type PrintOptions struct {
+	// Indentation of the entries of dictionaries and arrays, two spaces if empty.
+	Indent     string
+	StreamData StreamDataMode
+}
+
+// PrintObject prints an object in the PDF syntax to w, with the entries of the dictionaries on separate lines.
+// Indirect objects and streams are printed with their obj and endobj keywords, and as references when contained in
+// other objects.  The options can be nil for the default options.
+func PrintObject(w io.Writer, obj PdfObject, opts *PrintOptions) error {
+	p := objectPrinter{indent: "  "}
+	if opts != nil {
+		p.opts = *opts
+		if opts.Indent != "" {
+			p.indent = opts.Indent
+		}
+	}
+
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		fmt.Fprintf(&p.buf, "%d %d obj\n", t.ObjectNumber, t.GenerationNumber)
+		p.printValue(t.PdfObject, 0)
+		p.buf.WriteString("\nendobj\n")
+	case *PdfObjectStream:
+		fmt.Fprintf(&p.buf, "%d %d obj\n", t.ObjectNumber, t.GenerationNumber)
+		p.printValue(t.PdfObjectDictionary, 0)
+		p.buf.WriteString("\n")
+		if err := p.printStreamData(t); err != nil {
+			return err
+		}
+		p.buf.WriteString("endobj\n")
+	default:
+		p.printValue(obj, 0)
+		p.buf.WriteString("\n")
+	}
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+// FormatObject returns an object printed in the PDF syntax as by PrintObject.
+func FormatObject(obj PdfObject, opts *PrintOptions) string {
+	var buf bytes.Buffer
+	PrintObject(&buf, obj, opts)
+	return buf.String()
+}
+
+// objectPrinter prints objects for PrintObject.
+type objectPrinter struct {
+	opts   PrintOptions
+	indent string
+	buf    bytes.Buffer
+}
+
+// printValue prints an object at the nesting depth.
+func (p *objectPrinter) printValue(obj PdfObject, depth int) {
+	switch t := obj.(type) {
+	case nil:
+		p.buf.WriteString("null")
+	case *PdfIndirectObject:
+		fmt.Fprintf(&p.buf, "%d %d R", t.ObjectNumber, t.GenerationNumber)
+	case *PdfObjectStream:
+		fmt.Fprintf(&p.buf, "%d %d R", t.ObjectNumber, t.GenerationNumber)
+	case *PdfObjectDictionary:
+		if len(t.Keys()) == 0 {
+			p.buf.WriteString("<< >>")
+			return
+		}
+		p.buf.WriteString("<<\n")
+		for _, key := range t.Keys() {
+			p.buf.WriteString(strings.Repeat(p.indent, depth+1))
+			p.buf.WriteString(key.DefaultWriteString())
+			p.buf.WriteString(" ")
+			p.printValue(t.Get(key), depth+1)
+			p.buf.WriteString("\n")
+		}
+		p.buf.WriteString(strings.Repeat(p.indent, depth))
+		p.buf.WriteString(">>")
+	case *PdfObjectArray:
+		// On a single line unless containing dictionaries.
+		multiline := false
+		for _, elem := range *t {
+			if _, ok := elem.(*PdfObjectDictionary); ok {
+				multiline = true
+			}
+		}
+		if !multiline {
+			p.buf.WriteString("[")
+			for i, elem := range *t {
+				if i > 0 {
+					p.buf.WriteString(" ")
+				}
+				p.printValue(elem, depth)
+			}
+			p.buf.WriteString("]")
+			return
+		}
+		p.buf.WriteString("[\n")
+		for _, elem := range *t {
+			p.buf.WriteString(strings.Repeat(p.indent, depth+1))
+			p.printValue(elem, depth+1)
+			p.buf.WriteString("\n")
+		}
+		p.buf.WriteString(strings.Repeat(p.indent, depth))
+		p.buf.WriteString("]")
+	default:
+		p.buf.WriteString(obj.DefaultWriteString())
+	}
+}
+
+// printStreamData prints the data of a stream as specified by the options.
+func (p *objectPrinter) printStreamData(stream *PdfObjectStream) error {
+	var data []byte
+	switch p.opts.StreamData {
+	case StreamDataNone:
+		length := int64(len(stream.Stream))
+		if !stream.IsLoaded() {
+			length = stream.lazy.length
+		}
+		fmt.Fprintf(&p.buf, "%% %d bytes of stream data\n", length)
+		return nil
+	case StreamDataRaw:
+		r, err := stream.RawReader()
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, r); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	case StreamDataDecoded:
+		var err error
+		data, err = DecodeStream(stream)
+		if err != nil {
+			return err
+		}
+	}
+	p.buf.WriteString("stream\n")
+	p.buf.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		p.buf.WriteString("\n")
+	}
+	p.buf.WriteString("endstream\n")
+	return nil
+}
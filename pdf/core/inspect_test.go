@@ -0,0 +1,117 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+)
+
+// makeInspectTestFile returns a document with a catalog, a page tree with a page, a Flate encoded content stream
+// and a reference chain.
+func makeInspectTestFile() []byte {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write([]byte("0 0 10 10 re f\n"))
+	zw.Close()
+
+	objects := []string{
+		"<</Type /Catalog /Pages 2 0 R>>",
+		"<</Type /Pages /Kids [3 0 R] /Count 1>>",
+		"<</Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Annots [<</Subtype /Text /Rect [0 0 1 1]>>]>>",
+		fmt.Sprintf("<</Length %d /Filter /FlateDecode>>\nstream\n%s\nendstream", z.Len(), z.Bytes()),
+		"6 0 R",
+		"(end)",
+	}
+	return testutils.MakePdf(objects)
+}
+
+func TestObjectIterator(t *testing.T) {
+	parser, err := NewParser(bytes.NewReader(makeInspectTestFile()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	infos := []string{}
+	it := parser.Objects()
+	for it.Next() {
+		if it.Err() != nil {
+			t.Fatalf("Error: %v", it.Err())
+		}
+		if it.Info().Offset == 0 {
+			t.Errorf("Missing offset %+v", it.Info())
+		}
+		infos = append(infos, it.Info().String())
+	}
+	expected := []string{
+		"1 0 obj: dictionary /Catalog",
+		"2 0 obj: dictionary /Pages",
+		"3 0 obj: dictionary /Page",
+		"4 0 obj: stream",
+		"5 0 obj: reference",
+		"6 0 obj: string",
+	}
+	if fmt.Sprint(infos) != fmt.Sprint(expected) {
+		t.Errorf("Wrong objects %q", infos)
+	}
+
+	// The reference chain resolves to the string object.
+	obj, err := parser.Resolve(&PdfObjectReference{ObjectNumber: 5})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if ind, ok := obj.(*PdfIndirectObject); !ok || ind.ObjectNumber != 6 {
+		t.Errorf("Wrong object %v", obj)
+	}
+}
+
+func TestPrintObject(t *testing.T) {
+	parser, err := NewParser(bytes.NewReader(makeInspectTestFile()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	page, err := parser.LookupByNumber(3)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := `3 0 obj
+<<
+  /Type /Page
+  /Parent 2 0 R
+  /MediaBox [0 0 612 792]
+  /Contents 4 0 R
+  /Annots [
+    <<
+      /Subtype /Text
+      /Rect [0 0 1 1]
+    >>
+  ]
+>>
+endobj
+`
+	if str := FormatObject(page, nil); str != expected {
+		t.Errorf("Wrong page printed:\n%s", str)
+	}
+
+	contents, err := parser.LookupByNumber(4)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	str := FormatObject(contents, &PrintOptions{Indent: "\t", StreamData: StreamDataDecoded})
+	expected = "4 0 obj\n<<\n\t/Length %d\n\t/Filter /FlateDecode\n>>\nstream\n0 0 10 10 re f\nendstream\nendobj\n"
+	if length := len(contents.(*PdfObjectStream).Stream); str != fmt.Sprintf(expected, length) {
+		t.Errorf("Wrong contents printed:\n%s", str)
+	}
+	str = FormatObject(contents, nil)
+	if !bytes.Contains([]byte(str), []byte("bytes of stream data")) {
+		t.Errorf("Wrong contents printed:\n%s", str)
+	}
+}
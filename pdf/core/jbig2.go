@@ -0,0 +1,793 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// JBIG2 decoding (ITU-T T.88) of the embedded streams of PDF, whose segments are those of the first page of a JBIG2 file
+// without the file header, the global segments (e.g. symbol dictionaries shared by several images) being in the
+// JBIG2Globals stream.
+//
+// Supported: generic regions (arithmetic and MMR coding), symbol dictionaries and text regions with arithmetic coding
+// and without refinement.  The data with Huffman or refinement coding cannot be decoded, other segments, e.g. halftone
+// regions, are skipped.
+
+// Segment types (7.3).
+const (
+	jbig2SymbolDictionary            = 0
+	jbig2IntermediateTextRegion      = 4
+	jbig2ImmediateTextRegion         = 6
+	jbig2ImmediateLosslessTextRegion = 7
+	jbig2IntermediateGenericRegion   = 36
+	jbig2ImmediateGenericRegion      = 38
+	jbig2ImmediateLosslessGeneric    = 39
+	jbig2PageInformation             = 48
+	jbig2EndOfPage                   = 49
+	jbig2EndOfStripe                 = 50
+	jbig2EndOfFile                   = 51
+)
+
+// Maximum number of pixels of a bitmap.
+const jbig2MaxPixels = 1 << 30
+
+var errJBIG2Truncated = errors.New("JBIG2 data truncated")
+
+var (
+	errJBIG2Huffman    = errors.New("JBIG2 Huffman coding not supported")
+	errJBIG2Refinement = errors.New("JBIG2 refinement coding not supported")
+)
+
+// jbig2Bitmap is a bilevel image, black pixels being 1, each row starting at a byte boundary.
+type jbig2Bitmap struct {
+	width, height int
+	rowBytes      int
+	data          []byte
+}
+
+func newJBIG2Bitmap(width, height int) (*jbig2Bitmap, error) {
+	if width < 0 || height < 0 || width*height > jbig2MaxPixels {
+		common.Log.Debug("ERROR: Invalid JBIG2 bitmap size %dx%d", width, height)
+		return nil, errors.New("Invalid JBIG2 bitmap size")
+	}
+	rowBytes := (width + 7) / 8
+	return &jbig2Bitmap{width: width, height: height, rowBytes: rowBytes, data: make([]byte, rowBytes*height)}, nil
+}
+
+// get returns the pixel, 0 outside the bitmap.
+func (b *jbig2Bitmap) get(x, y int) int {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return 0
+	}
+	return int(b.data[y*b.rowBytes+x/8]>>uint(7-x%8)) & 1
+}
+
+// set sets the pixel, ignored outside the bitmap.
+func (b *jbig2Bitmap) set(x, y, v int) {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return
+	}
+	mask := byte(0x80) >> uint(x%8)
+	if v != 0 {
+		b.data[y*b.rowBytes+x/8] |= mask
+	} else {
+		b.data[y*b.rowBytes+x/8] &^= mask
+	}
+}
+
+// fill sets all the pixels to v.
+func (b *jbig2Bitmap) fill(v int) {
+	val := byte(0)
+	if v != 0 {
+		val = 0xff
+	}
+	for i := range b.data {
+		b.data[i] = val
+	}
+}
+
+// Combination operators (6.2.1).
+const (
+	jbig2CombOr      = 0
+	jbig2CombAnd     = 1
+	jbig2CombXor     = 2
+	jbig2CombXnor    = 3
+	jbig2CombReplace = 4
+)
+
+// compose combines the bitmap src into b, with its top left corner at (x, y).
+func (b *jbig2Bitmap) compose(src *jbig2Bitmap, x, y, op int) {
+	for sy := 0; sy < src.height; sy++ {
+		dy := y + sy
+		if dy < 0 || dy >= b.height {
+			continue
+		}
+		for sx := 0; sx < src.width; sx++ {
+			dx := x + sx
+			if dx < 0 || dx >= b.width {
+				continue
+			}
+			s, d := src.get(sx, sy), b.get(dx, dy)
+			switch op {
+			case jbig2CombOr:
+				d |= s
+			case jbig2CombAnd:
+				d &= s
+			case jbig2CombXor:
+				d ^= s
+			case jbig2CombXnor:
+				d = 1 - (d ^ s)
+			default:
+				d = s
+			}
+			b.set(dx, dy, d)
+		}
+	}
+}
+
+// jbig2Segment is a segment of JBIG2 data (7.2).
+type jbig2Segment struct {
+	number  uint32
+	segType int
+	refs    []uint32
+	page    uint32
+	data    []byte
+}
+
+// jbig2Reader reads the big-endian fields of JBIG2 data.
+type jbig2Reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *jbig2Reader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.data) {
+		r.err = errJBIG2Truncated
+		return make([]byte, n)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *jbig2Reader) uint8() uint8 {
+	return r.bytes(1)[0]
+}
+
+func (r *jbig2Reader) uint16() uint16 {
+	return binary.BigEndian.Uint16(r.bytes(2))
+}
+
+func (r *jbig2Reader) uint32() uint32 {
+	return binary.BigEndian.Uint32(r.bytes(4))
+}
+
+// int8 reads a signed byte, e.g. of the coordinates of the adaptive template pixels.
+func (r *jbig2Reader) int8() int {
+	return int(int8(r.uint8()))
+}
+
+// parseJBIG2Segments parses the segments of embedded JBIG2 data: segment headers each followed by the segment data.
+func parseJBIG2Segments(data []byte) ([]*jbig2Segment, error) {
+	segments := []*jbig2Segment{}
+	r := &jbig2Reader{data: data}
+	for r.pos < len(data) {
+		seg := &jbig2Segment{}
+		seg.number = r.uint32()
+		flags := r.uint8()
+		seg.segType = int(flags & 0x3f)
+
+		// Referred-to segments, in the short form for up to 4 segments.
+		countAndRetain := r.uint8()
+		count := int(countAndRetain >> 5)
+		if count == 7 {
+			r.pos--
+			count = int(r.uint32() & 0x1fffffff)
+			r.bytes((count + 8) / 8)
+		}
+		for i := 0; i < count && r.err == nil; i++ {
+			switch {
+			case seg.number <= 256:
+				seg.refs = append(seg.refs, uint32(r.uint8()))
+			case seg.number <= 65536:
+				seg.refs = append(seg.refs, uint32(r.uint16()))
+			default:
+				seg.refs = append(seg.refs, r.uint32())
+			}
+		}
+		if flags&0x40 != 0 {
+			seg.page = r.uint32()
+		} else {
+			seg.page = uint32(r.uint8())
+		}
+
+		length := r.uint32()
+		if r.err != nil {
+			common.Log.Debug("ERROR: Truncated JBIG2 segment header")
+			return nil, r.err
+		}
+		if length == 0xffffffff {
+			if seg.segType != jbig2ImmediateGenericRegion {
+				common.Log.Debug("ERROR: JBIG2 segment of type %d of unknown length", seg.segType)
+				return nil, errors.New("JBIG2 segment of unknown length")
+			}
+			n, err := jbig2GenericRegionLength(data[r.pos:])
+			if err != nil {
+				return nil, err
+			}
+			length = uint32(n)
+		}
+		seg.data = r.bytes(int(length))
+		if r.err != nil {
+			common.Log.Debug("ERROR: Truncated JBIG2 segment %d (%d bytes)", seg.number, length)
+			return nil, r.err
+		}
+		common.Log.Trace("JBIG2 segment %d: type %d, refs %v, page %d, %d bytes", seg.number, seg.segType, seg.refs,
+			seg.page, len(seg.data))
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// jbig2GenericRegionLength returns the length of the data of an immediate generic region segment of unknown length
+// (7.2.7): up to the end marker of the coded data, 0x0000 for MMR and 0xffac for arithmetic coding, followed by the
+// number of rows.
+func jbig2GenericRegionLength(data []byte) (int, error) {
+	// Region segment information field and generic region flags.
+	if len(data) < 18 {
+		return 0, errJBIG2Truncated
+	}
+	marker := []byte{0xff, 0xac}
+	if data[17]&1 != 0 {
+		marker = []byte{0, 0}
+	}
+	for i := 18; i+6 <= len(data); i++ {
+		if data[i] == marker[0] && data[i+1] == marker[1] {
+			return i + 6, nil
+		}
+	}
+	common.Log.Debug("ERROR: No end of JBIG2 generic region")
+	return 0, errJBIG2Truncated
+}
+
+// jbig2RegionInfo is the region segment information field (7.4.1).
+type jbig2RegionInfo struct {
+	width, height int
+	x, y          int
+	combOp        int
+}
+
+func readJBIG2RegionInfo(r *jbig2Reader) jbig2RegionInfo {
+	info := jbig2RegionInfo{}
+	info.width = int(r.uint32())
+	info.height = int(r.uint32())
+	info.x = int(r.uint32())
+	info.y = int(r.uint32())
+	info.combOp = int(r.uint8() & 7)
+	return info
+}
+
+// jbig2Decoder decodes the page of JBIG2 segments.
+type jbig2Decoder struct {
+	// The symbols exported by the symbol dictionary segments, by segment number.
+	symbols map[uint32][]*jbig2Bitmap
+
+	page          *jbig2Bitmap
+	pageNumber    uint32
+	defaultPixel  int
+	heightUnknown bool
+	done          bool
+}
+
+// jbig2Decode decodes embedded JBIG2 data, with the data of the JBIG2Globals stream if any, into the image of its page
+// with 1 bit per pixel, 0 being black as for the DeviceGray colorspace.
+func jbig2Decode(encoded, globals []byte) ([]byte, error) {
+	d := &jbig2Decoder{symbols: map[uint32][]*jbig2Bitmap{}}
+	for _, data := range [][]byte{globals, encoded} {
+		segments, err := parseJBIG2Segments(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, seg := range segments {
+			if d.done {
+				break
+			}
+			if err := d.decodeSegment(seg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if d.page == nil {
+		common.Log.Debug("ERROR: No JBIG2 page information")
+		return nil, errors.New("No JBIG2 page")
+	}
+
+	decoded := make([]byte, len(d.page.data))
+	for i, b := range d.page.data {
+		decoded[i] = ^b
+	}
+	return decoded, nil
+}
+
+// decodeSegment decodes a segment, composing its regions on the page.
+func (d *jbig2Decoder) decodeSegment(seg *jbig2Segment) error {
+	if seg.page != 0 && d.pageNumber != 0 && seg.page != d.pageNumber {
+		// Another page.
+		return nil
+	}
+
+	switch seg.segType {
+	case jbig2SymbolDictionary:
+		return d.decodeSymbolDictionary(seg)
+	case jbig2IntermediateTextRegion, jbig2ImmediateTextRegion, jbig2ImmediateLosslessTextRegion:
+		return d.decodeTextRegion(seg)
+	case jbig2IntermediateGenericRegion, jbig2ImmediateGenericRegion, jbig2ImmediateLosslessGeneric:
+		return d.decodeGenericRegion(seg)
+	case jbig2PageInformation:
+		return d.decodePageInformation(seg)
+	case jbig2EndOfStripe:
+		r := &jbig2Reader{data: seg.data}
+		end := int(r.uint32())
+		if r.err == nil && d.page != nil && d.heightUnknown && end+1 > d.page.height {
+			return d.growPage(end + 1)
+		}
+	case jbig2EndOfPage, jbig2EndOfFile:
+		if d.page != nil {
+			d.done = true
+		}
+	case 40, 42, 43:
+		// Refinement regions.
+		common.Log.Debug("ERROR: Unsupported JBIG2 refinement region")
+		return errJBIG2Refinement
+	case 16, 20, 22, 23:
+		// Pattern dictionaries and halftone regions.
+		common.Log.Debug("Unsupported JBIG2 segment type %d skipped", seg.segType)
+	}
+	return nil
+}
+
+// decodePageInformation decodes the page information segment (7.4.8), creating the page.
+func (d *jbig2Decoder) decodePageInformation(seg *jbig2Segment) error {
+	if d.page != nil {
+		return nil
+	}
+	r := &jbig2Reader{data: seg.data}
+	width := r.uint32()
+	height := r.uint32()
+	r.uint32() // Resolutions.
+	r.uint32()
+	flags := r.uint8()
+	if r.err != nil {
+		return r.err
+	}
+	if height == 0xffffffff {
+		// Striped page, the height is known with the end of stripe segments.
+		height = 0
+		d.heightUnknown = true
+	}
+	page, err := newJBIG2Bitmap(int(width), int(height))
+	if err != nil {
+		return err
+	}
+	d.defaultPixel = int(flags>>2) & 1
+	page.fill(d.defaultPixel)
+	d.page = page
+	d.pageNumber = seg.page
+	return nil
+}
+
+// growPage makes the page of unknown height taller.
+func (d *jbig2Decoder) growPage(height int) error {
+	page, err := newJBIG2Bitmap(d.page.width, height)
+	if err != nil {
+		return err
+	}
+	page.fill(d.defaultPixel)
+	copy(page.data, d.page.data)
+	d.page = page
+	return nil
+}
+
+// placeRegion composes the bitmap of a region on the page.
+func (d *jbig2Decoder) placeRegion(info jbig2RegionInfo, bitmap *jbig2Bitmap) error {
+	if d.page == nil {
+		common.Log.Debug("ERROR: JBIG2 region before the page information")
+		return errors.New("JBIG2 region without page")
+	}
+	if d.heightUnknown && info.y+bitmap.height > d.page.height {
+		if err := d.growPage(info.y + bitmap.height); err != nil {
+			return err
+		}
+	}
+	d.page.compose(bitmap, info.x, info.y, info.combOp)
+	return nil
+}
+
+// jbig2Pixel is a pixel of a template, relative to the pixel decoded.  at is the index of the adaptive template pixel
+// it stands for, -1 for the fixed pixels.
+type jbig2Pixel struct {
+	x, y int
+	at   int
+}
+
+// The generic region templates (6.2.5.3), from the most significant bit of the context.
+var jbig2GenericTemplates = [4][]jbig2Pixel{
+	{{0, 0, 3}, {-1, -2, -1}, {0, -2, -1}, {1, -2, -1}, {0, 0, 2}, {0, 0, 1}, {-2, -1, -1}, {-1, -1, -1},
+		{0, -1, -1}, {1, -1, -1}, {2, -1, -1}, {0, 0, 0}, {-4, 0, -1}, {-3, 0, -1}, {-2, 0, -1}, {-1, 0, -1}},
+	{{-1, -2, -1}, {0, -2, -1}, {1, -2, -1}, {2, -2, -1}, {-2, -1, -1}, {-1, -1, -1}, {0, -1, -1}, {1, -1, -1},
+		{2, -1, -1}, {0, 0, 0}, {-3, 0, -1}, {-2, 0, -1}, {-1, 0, -1}},
+	{{-1, -2, -1}, {0, -2, -1}, {1, -2, -1}, {-2, -1, -1}, {-1, -1, -1}, {0, -1, -1}, {1, -1, -1}, {0, 0, 0},
+		{-2, 0, -1}, {-1, 0, -1}},
+	{{-3, -1, -1}, {-2, -1, -1}, {-1, -1, -1}, {0, -1, -1}, {1, -1, -1}, {0, 0, 0}, {-4, 0, -1}, {-3, 0, -1},
+		{-2, 0, -1}, {-1, 0, -1}},
+}
+
+// The contexts of the typical prediction bit of each template (6.2.5.7).
+var jbig2TPGDONContexts = [4]int{0x9b25, 0x0795, 0x00e5, 0x0195}
+
+// jbig2GenericParams are the parameters of the generic region decoding procedure (6.2).
+type jbig2GenericParams struct {
+	width, height int
+	template      int
+	tpgdon        bool
+	at            [][2]int // Adaptive template pixels.
+}
+
+// readJBIG2AT reads n adaptive template pixels.
+func readJBIG2AT(r *jbig2Reader, n int) [][2]int {
+	at := make([][2]int, n)
+	for i := range at {
+		at[i][0] = r.int8()
+		at[i][1] = r.int8()
+	}
+	return at
+}
+
+// decodeJBIG2Generic decodes a bitmap with arithmetic coding, the contexts cx being shared by the bitmaps of a symbol
+// dictionary.
+func decodeJBIG2Generic(d *mqDecoder, cx []uint8, p jbig2GenericParams) (*jbig2Bitmap, error) {
+	bitmap, err := newJBIG2Bitmap(p.width, p.height)
+	if err != nil {
+		return nil, err
+	}
+	template := make([]jbig2Pixel, len(jbig2GenericTemplates[p.template]))
+	copy(template, jbig2GenericTemplates[p.template])
+	for i, px := range template {
+		if px.at >= 0 {
+			if px.at >= len(p.at) {
+				return nil, errors.New("Missing JBIG2 adaptive template pixel")
+			}
+			template[i].x, template[i].y = p.at[px.at][0], p.at[px.at][1]
+		}
+	}
+
+	ltp := 0
+	for y := 0; y < p.height; y++ {
+		if p.tpgdon {
+			// Typical prediction: the row is the same as the previous one.
+			ltp ^= d.decodeBit(cx, jbig2TPGDONContexts[p.template])
+			if ltp == 1 {
+				if y > 0 {
+					copy(bitmap.data[y*bitmap.rowBytes:(y+1)*bitmap.rowBytes],
+						bitmap.data[(y-1)*bitmap.rowBytes:y*bitmap.rowBytes])
+				}
+				continue
+			}
+		}
+		for x := 0; x < p.width; x++ {
+			context := 0
+			for _, px := range template {
+				context = context<<1 | bitmap.get(x+px.x, y+px.y)
+			}
+			if d.decodeBit(cx, context) == 1 {
+				bitmap.set(x, y, 1)
+			}
+		}
+	}
+	return bitmap, nil
+}
+
+// decodeJBIG2MMR decodes a bitmap coded with MMR (CCITT Group 4).
+func decodeJBIG2MMR(data []byte, width, height int) (*jbig2Bitmap, error) {
+	bitmap, err := newJBIG2Bitmap(width, height)
+	if err != nil || width == 0 || height == 0 {
+		return bitmap, err
+	}
+	params := NewCCITTFaxEncoder()
+	params.K = -1
+	params.Columns = width
+	params.Rows = height
+	params.BlackIs1 = true
+	decoded, err := ccittDecode(data, params)
+	if err != nil {
+		return nil, err
+	}
+	copy(bitmap.data, decoded)
+	return bitmap, nil
+}
+
+// decodeGenericRegion decodes a generic region segment (7.4.6).
+func (d *jbig2Decoder) decodeGenericRegion(seg *jbig2Segment) error {
+	r := &jbig2Reader{data: seg.data}
+	info := readJBIG2RegionInfo(r)
+	flags := r.uint8()
+	mmr := flags&1 != 0
+	p := jbig2GenericParams{width: info.width, height: info.height, template: int(flags>>1) & 3,
+		tpgdon: flags&8 != 0}
+	if !mmr {
+		n := 1
+		if p.template == 0 {
+			n = 4
+		}
+		p.at = readJBIG2AT(r, n)
+	}
+	if r.err != nil {
+		return r.err
+	}
+	data := seg.data[r.pos:]
+	if uint32(info.height) == 0xffffffff && len(data) >= 4 {
+		// Segment of unknown length, the number of rows following the data.
+		p.height = int(binary.BigEndian.Uint32(data[len(data)-4:]))
+		data = data[:len(data)-4]
+	}
+
+	var bitmap *jbig2Bitmap
+	var err error
+	if mmr {
+		bitmap, err = decodeJBIG2MMR(data, p.width, p.height)
+	} else {
+		bitmap, err = decodeJBIG2Generic(newMQDecoder(data), make([]uint8, 1<<16), p)
+	}
+	if err != nil {
+		return err
+	}
+	if seg.segType == jbig2IntermediateGenericRegion {
+		common.Log.Debug("JBIG2 intermediate region placed without refinement")
+	}
+	return d.placeRegion(info, bitmap)
+}
+
+// decodeSymbolDictionary decodes a symbol dictionary segment (7.4.2), with arithmetic coding and without refinement.
+func (d *jbig2Decoder) decodeSymbolDictionary(seg *jbig2Segment) error {
+	r := &jbig2Reader{data: seg.data}
+	flags := r.uint16()
+	if flags&1 != 0 {
+		common.Log.Debug("ERROR: Unsupported JBIG2 symbol dictionary with Huffman coding")
+		return errJBIG2Huffman
+	}
+	if flags&2 != 0 {
+		common.Log.Debug("ERROR: Unsupported JBIG2 symbol dictionary with refinement")
+		return errJBIG2Refinement
+	}
+	if flags&0x100 != 0 {
+		common.Log.Debug("JBIG2 symbol dictionary contexts of the previous dictionary not reused")
+	}
+	p := jbig2GenericParams{template: int(flags>>10) & 3}
+	n := 1
+	if p.template == 0 {
+		n = 4
+	}
+	p.at = readJBIG2AT(r, n)
+	numExported := int(r.uint32())
+	numNew := int(r.uint32())
+	if r.err != nil {
+		return r.err
+	}
+
+	inputs := d.referredSymbols(seg)
+	if numNew > jbig2MaxPixels || numExported > len(inputs)+numNew {
+		common.Log.Debug("ERROR: Invalid JBIG2 symbol counts (%d exported, %d new)", numExported, numNew)
+		return errors.New("Invalid JBIG2 symbol dictionary")
+	}
+
+	mq := newMQDecoder(seg.data[r.pos:])
+	cx := make([]uint8, 1<<16)
+	iadh, iadw, iaex := &jbig2IntDecoder{}, &jbig2IntDecoder{}, &jbig2IntDecoder{}
+
+	// Height classes of symbols, in order of increasing height (6.5.5).
+	symbols := make([]*jbig2Bitmap, 0, numNew)
+	height := 0
+	for len(symbols) < numNew {
+		dh, ok := iadh.decode(mq)
+		if !ok {
+			return errors.New("Invalid JBIG2 symbol height")
+		}
+		height += dh
+		width := 0
+		for {
+			dw, ok := iadw.decode(mq)
+			if !ok {
+				// End of the height class.
+				break
+			}
+			width += dw
+			if len(symbols) >= numNew || width < 0 || height < 0 {
+				common.Log.Debug("ERROR: Invalid JBIG2 symbol %d (%dx%d)", len(symbols), width, height)
+				return errors.New("Invalid JBIG2 symbol")
+			}
+			p.width, p.height = width, height
+			bitmap, err := decodeJBIG2Generic(mq, cx, p)
+			if err != nil {
+				return err
+			}
+			symbols = append(symbols, bitmap)
+		}
+	}
+
+	// Exported symbols (6.5.10): runs of symbols alternately not exported and exported.
+	all := append(inputs, symbols...)
+	exported := make([]*jbig2Bitmap, 0, numExported)
+	export := false
+	for i := 0; i < len(all); {
+		run, ok := iaex.decode(mq)
+		if !ok || run < 0 || i+run > len(all) {
+			common.Log.Debug("ERROR: Invalid JBIG2 export run length %d", run)
+			return errors.New("Invalid JBIG2 exported symbols")
+		}
+		if export {
+			exported = append(exported, all[i:i+run]...)
+		}
+		i += run
+		export = !export
+	}
+	d.symbols[seg.number] = exported
+	return nil
+}
+
+// referredSymbols returns the symbols of the symbol dictionaries the segment refers to.
+func (d *jbig2Decoder) referredSymbols(seg *jbig2Segment) []*jbig2Bitmap {
+	symbols := []*jbig2Bitmap{}
+	for _, ref := range seg.refs {
+		if refSymbols, has := d.symbols[ref]; has {
+			symbols = append(symbols, refSymbols...)
+		}
+	}
+	return symbols
+}
+
+// Reference corners of the symbols of text regions (6.4.5).
+const (
+	jbig2CornerBottomLeft  = 0
+	jbig2CornerTopLeft     = 1
+	jbig2CornerBottomRight = 2
+	jbig2CornerTopRight    = 3
+)
+
+// decodeTextRegion decodes a text region segment (7.4.3), with arithmetic coding and without refinement.
+func (d *jbig2Decoder) decodeTextRegion(seg *jbig2Segment) error {
+	r := &jbig2Reader{data: seg.data}
+	info := readJBIG2RegionInfo(r)
+	flags := r.uint16()
+	if flags&1 != 0 {
+		common.Log.Debug("ERROR: Unsupported JBIG2 text region with Huffman coding")
+		return errJBIG2Huffman
+	}
+	if flags&2 != 0 {
+		common.Log.Debug("ERROR: Unsupported JBIG2 text region with refinement")
+		return errJBIG2Refinement
+	}
+	strips := 1 << uint(flags>>2&3)
+	corner := int(flags>>4) & 3
+	transposed := flags&0x40 != 0
+	combOp := int(flags>>7) & 3
+	defaultPixel := int(flags>>9) & 1
+	dsOffset := int(flags>>10) & 0x1f
+	if dsOffset > 15 {
+		dsOffset -= 32
+	}
+	numInstances := int(r.uint32())
+	if r.err != nil {
+		return r.err
+	}
+
+	symbols := d.referredSymbols(seg)
+	codeLen := uint(0)
+	for 1<<codeLen < len(symbols) {
+		codeLen++
+	}
+	if codeLen > 24 {
+		return errors.New("Too many JBIG2 symbols")
+	}
+
+	region, err := newJBIG2Bitmap(info.width, info.height)
+	if err != nil {
+		return err
+	}
+	region.fill(defaultPixel)
+
+	mq := newMQDecoder(seg.data[r.pos:])
+	iadt, iafs, iads, iait := &jbig2IntDecoder{}, &jbig2IntDecoder{}, &jbig2IntDecoder{}, &jbig2IntDecoder{}
+	iaid := newJBIG2IDDecoder(codeLen)
+
+	decodeInt := func(id *jbig2IntDecoder) (int, error) {
+		v, ok := id.decode(mq)
+		if !ok {
+			return 0, errors.New("Unexpected JBIG2 out-of-band value")
+		}
+		return v, nil
+	}
+
+	// Decoding procedure of 6.4.5.
+	dt, err := decodeInt(iadt)
+	if err != nil {
+		return err
+	}
+	stripT := -dt * strips
+	firstS := 0
+	for instances := 0; instances < numInstances; {
+		dt, err := decodeInt(iadt)
+		if err != nil {
+			return err
+		}
+		stripT += dt * strips
+
+		dfs, err := decodeInt(iafs)
+		if err != nil {
+			return err
+		}
+		firstS += dfs
+		curS := firstS
+		for first := true; instances < numInstances; first = false {
+			if !first {
+				ids, ok := iads.decode(mq)
+				if !ok {
+					// End of the strip.
+					break
+				}
+				curS += ids + dsOffset
+			}
+			curT := 0
+			if strips > 1 {
+				if curT, err = decodeInt(iait); err != nil {
+					return err
+				}
+			}
+			t := stripT + curT
+			id := iaid.decode(mq)
+			if id >= len(symbols) {
+				common.Log.Debug("ERROR: Invalid JBIG2 symbol ID %d (%d symbols)", id, len(symbols))
+				return fmt.Errorf("Invalid JBIG2 symbol ID %d", id)
+			}
+			symbol := symbols[id]
+
+			w, h := symbol.width, symbol.height
+			if !transposed && (corner == jbig2CornerTopRight || corner == jbig2CornerBottomRight) {
+				curS += w - 1
+			} else if transposed && (corner == jbig2CornerBottomLeft || corner == jbig2CornerBottomRight) {
+				curS += h - 1
+			}
+			x, y := curS, t
+			if transposed {
+				x, y = t, curS
+			}
+			// The corner of the symbol at (x, y).
+			if corner == jbig2CornerTopRight || corner == jbig2CornerBottomRight {
+				x -= w - 1
+			}
+			if corner == jbig2CornerBottomLeft || corner == jbig2CornerBottomRight {
+				y -= h - 1
+			}
+			region.compose(symbol, x, y, combOp)
+			if !transposed && (corner == jbig2CornerTopLeft || corner == jbig2CornerBottomLeft) {
+				curS += w - 1
+			} else if transposed && (corner == jbig2CornerTopLeft || corner == jbig2CornerTopRight) {
+				curS += h - 1
+			}
+			instances++
+		}
+	}
+
+	if seg.segType == jbig2IntermediateTextRegion {
+		common.Log.Debug("JBIG2 intermediate region placed without refinement")
+	}
+	return d.placeRegion(info, region)
+}
@@ -0,0 +1,210 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+// Arithmetic (MQ) decoding of JBIG2 (ITU-T T.88 Annex E) and its integer decoding procedures (Annex A).
+
+// mqState is an entry of the probability estimation table: the LPS probability, the next states after an MPS and an
+// LPS, and whether the MPS switches after an LPS.
+type mqState struct {
+	qe         uint32
+	nmps, nlps uint8
+	switchMPS  bool
+}
+
+// Table E.1.
+var mqStates = [47]mqState{
+	{0x5601, 1, 1, true}, {0x3401, 2, 6, false}, {0x1801, 3, 9, false}, {0x0ac1, 4, 12, false},
+	{0x0521, 5, 29, false}, {0x0221, 38, 33, false}, {0x5601, 7, 6, true}, {0x5401, 8, 14, false},
+	{0x4801, 9, 14, false}, {0x3801, 10, 14, false}, {0x3001, 11, 17, false}, {0x2401, 12, 18, false},
+	{0x1c01, 13, 20, false}, {0x1601, 29, 21, false}, {0x5601, 15, 14, true}, {0x5401, 16, 14, false},
+	{0x5101, 17, 15, false}, {0x4801, 18, 16, false}, {0x3801, 19, 17, false}, {0x3401, 20, 18, false},
+	{0x3001, 21, 19, false}, {0x2801, 22, 19, false}, {0x2401, 23, 20, false}, {0x2201, 24, 21, false},
+	{0x1c01, 25, 22, false}, {0x1801, 26, 23, false}, {0x1601, 27, 24, false}, {0x1401, 28, 25, false},
+	{0x1201, 29, 26, false}, {0x1101, 30, 27, false}, {0x0ac1, 31, 28, false}, {0x09c1, 32, 29, false},
+	{0x08a1, 33, 30, false}, {0x0521, 34, 31, false}, {0x0441, 35, 32, false}, {0x02a1, 36, 33, false},
+	{0x0221, 37, 34, false}, {0x0141, 38, 35, false}, {0x0111, 39, 36, false}, {0x0085, 40, 37, false},
+	{0x0049, 41, 38, false}, {0x0025, 42, 39, false}, {0x0015, 43, 40, false}, {0x0009, 44, 41, false},
+	{0x0005, 45, 42, false}, {0x0001, 45, 43, false}, {0x5601, 46, 46, false},
+}
+
+// mqDecoder is the arithmetic decoder of a JBIG2 segment.  The contexts are kept by the callers, each as the index of
+// its state in mqStates shifted left by one, with the MPS in the low bit.
+type mqDecoder struct {
+	data        []byte
+	pos         int
+	chigh, clow uint32
+	a           uint32
+	ct          int
+}
+
+// newMQDecoder returns the decoder of the data (INITDEC).
+func newMQDecoder(data []byte) *mqDecoder {
+	d := &mqDecoder{data: data}
+	d.chigh = uint32(d.byteAt(0))
+	d.byteIn()
+	d.chigh = (d.chigh<<7)&0xffff | (d.clow>>9)&0x7f
+	d.clow = (d.clow << 7) & 0xffff
+	d.ct -= 7
+	d.a = 0x8000
+	return d
+}
+
+// byteAt returns the byte at the position, 0xff past the end of the data as the decoder expects.
+func (d *mqDecoder) byteAt(pos int) byte {
+	if pos < len(d.data) {
+		return d.data[pos]
+	}
+	return 0xff
+}
+
+// byteIn reads the next byte of the data (BYTEIN), skipping the stuffed bits after 0xff.
+func (d *mqDecoder) byteIn() {
+	if d.byteAt(d.pos) == 0xff {
+		if d.byteAt(d.pos+1) > 0x8f {
+			// Marker: 1 bits from now on.
+			d.clow += 0xff00
+			d.ct = 8
+		} else {
+			d.pos++
+			d.clow += uint32(d.byteAt(d.pos)) << 9
+			d.ct = 7
+		}
+	} else {
+		d.pos++
+		d.clow += uint32(d.byteAt(d.pos)) << 8
+		d.ct = 8
+	}
+	if d.clow > 0xffff {
+		d.chigh += d.clow >> 16
+		d.clow &= 0xffff
+	}
+}
+
+// decodeBit decodes a bit with the context cx[i] (DECODE), updated.
+func (d *mqDecoder) decodeBit(cx []uint8, i int) int {
+	index := cx[i] >> 1
+	mps := int(cx[i] & 1)
+	state := &mqStates[index]
+	qe := state.qe
+
+	var bit int
+	a := d.a - qe
+	if d.chigh < qe {
+		// LPS_EXCHANGE.
+		if a < qe {
+			bit = mps
+			index = state.nmps
+		} else {
+			bit = 1 - mps
+			if state.switchMPS {
+				mps = bit
+			}
+			index = state.nlps
+		}
+		a = qe
+	} else {
+		d.chigh -= qe
+		if a&0x8000 != 0 {
+			d.a = a
+			return mps
+		}
+		// MPS_EXCHANGE.
+		if a < qe {
+			bit = 1 - mps
+			if state.switchMPS {
+				mps = bit
+			}
+			index = state.nlps
+		} else {
+			bit = mps
+			index = state.nmps
+		}
+	}
+
+	// RENORMD.
+	for {
+		if d.ct == 0 {
+			d.byteIn()
+		}
+		a <<= 1
+		d.chigh = (d.chigh<<1)&0xffff | (d.clow>>15)&1
+		d.clow = (d.clow << 1) & 0xffff
+		d.ct--
+		if a&0x8000 != 0 {
+			break
+		}
+	}
+	d.a = a
+	cx[i] = index<<1 | uint8(mps)
+	return bit
+}
+
+// jbig2IntDecoder is an arithmetic integer decoding procedure (IADH, IADW, ...), with its contexts.
+type jbig2IntDecoder struct {
+	cx [512]uint8
+}
+
+// decode decodes an integer, returning false for the out-of-band value (OOB).
+func (id *jbig2IntDecoder) decode(d *mqDecoder) (int, bool) {
+	prev := 1
+	readBits := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			bit := d.decodeBit(id.cx[:], prev)
+			if prev < 256 {
+				prev = prev<<1 | bit
+			} else {
+				prev = (prev<<1|bit)&511 | 256
+			}
+			v = v<<1 | bit
+		}
+		return v
+	}
+
+	sign := readBits(1)
+	var v int
+	switch {
+	case readBits(1) == 0:
+		v = readBits(2)
+	case readBits(1) == 0:
+		v = readBits(4) + 4
+	case readBits(1) == 0:
+		v = readBits(6) + 20
+	case readBits(1) == 0:
+		v = readBits(8) + 84
+	case readBits(1) == 0:
+		v = readBits(12) + 340
+	default:
+		v = readBits(32) + 4436
+	}
+	if sign == 1 {
+		if v == 0 {
+			return 0, false
+		}
+		return -v, true
+	}
+	return v, true
+}
+
+// jbig2IDDecoder is the symbol ID decoding procedure (IAID) for codes of a length.
+type jbig2IDDecoder struct {
+	cx      []uint8
+	codeLen uint
+}
+
+func newJBIG2IDDecoder(codeLen uint) *jbig2IDDecoder {
+	return &jbig2IDDecoder{cx: make([]uint8, 1<<(codeLen+1)), codeLen: codeLen}
+}
+
+// decode decodes a symbol ID.
+func (id *jbig2IDDecoder) decode(d *mqDecoder) int {
+	prev := 1
+	for i := uint(0); i < id.codeLen; i++ {
+		prev = prev<<1 | d.decodeBit(id.cx, prev)
+	}
+	return prev - 1<<id.codeLen
+}
@@ -0,0 +1,496 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// mqEncoder is the arithmetic encoder of T.88 Annex E.2, to make test data.
+type mqEncoder struct {
+	a, c uint32
+	ct   int
+	out  []byte // The first byte precedes the coded data.
+}
+
+func newMQEncoder() *mqEncoder {
+	return &mqEncoder{a: 0x8000, ct: 12, out: []byte{0}}
+}
+
+func (e *mqEncoder) encodeBit(cx []uint8, i int, bit int) {
+	state := &mqStates[cx[i]>>1]
+	mps := int(cx[i] & 1)
+	e.a -= state.qe
+	if bit == mps {
+		if e.a&0x8000 != 0 {
+			e.c += state.qe
+			return
+		}
+		if e.a < state.qe {
+			e.a = state.qe
+		} else {
+			e.c += state.qe
+		}
+		cx[i] = state.nmps<<1 | uint8(mps)
+	} else {
+		if e.a < state.qe {
+			e.c += state.qe
+		} else {
+			e.a = state.qe
+		}
+		if state.switchMPS {
+			mps = 1 - mps
+		}
+		cx[i] = state.nlps<<1 | uint8(mps)
+	}
+	for {
+		e.a <<= 1
+		e.c <<= 1
+		e.ct--
+		if e.ct == 0 {
+			e.byteOut()
+		}
+		if e.a&0x8000 != 0 {
+			break
+		}
+	}
+}
+
+func (e *mqEncoder) byteOut() {
+	last := len(e.out) - 1
+	if e.out[last] != 0xff {
+		if e.c < 0x8000000 {
+			e.out = append(e.out, byte(e.c>>19))
+			e.c &= 0x7ffff
+			e.ct = 8
+			return
+		}
+		// Carry.
+		e.out[last]++
+		if e.out[last] != 0xff {
+			e.c &= 0x7ffffff
+			e.out = append(e.out, byte(e.c>>19))
+			e.c &= 0x7ffff
+			e.ct = 8
+			return
+		}
+		e.c &= 0x7ffffff
+	}
+	e.out = append(e.out, byte(e.c>>20))
+	e.c &= 0xfffff
+	e.ct = 7
+}
+
+func (e *mqEncoder) flush() []byte {
+	temp := e.c + e.a
+	e.c |= 0xffff
+	if e.c >= temp {
+		e.c -= 0x8000
+	}
+	e.c <<= uint(e.ct)
+	e.byteOut()
+	e.c <<= uint(e.ct)
+	e.byteOut()
+	if e.out[len(e.out)-1] != 0xff {
+		e.out = append(e.out, 0xff)
+	}
+	e.out = append(e.out, 0xac)
+	return e.out[1:]
+}
+
+// encodeInt encodes an integer with the contexts of an integer decoding procedure, OOB if oob.
+func (e *mqEncoder) encodeInt(id *jbig2IntDecoder, v int, oob bool) {
+	prev := 1
+	put := func(bits, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bit := bits >> uint(i) & 1
+			e.encodeBit(id.cx[:], prev, bit)
+			if prev < 256 {
+				prev = prev<<1 | bit
+			} else {
+				prev = (prev<<1|bit)&511 | 256
+			}
+		}
+	}
+	sign, mag := 0, v
+	if v < 0 || oob {
+		sign, mag = 1, -v
+	}
+	put(sign, 1)
+	switch {
+	case mag < 4:
+		put(0, 1)
+		put(mag, 2)
+	case mag < 20:
+		put(2, 2)
+		put(mag-4, 4)
+	case mag < 84:
+		put(6, 3)
+		put(mag-20, 6)
+	case mag < 340:
+		put(14, 4)
+		put(mag-84, 8)
+	case mag < 4436:
+		put(30, 5)
+		put(mag-340, 12)
+	default:
+		put(31, 5)
+		put(mag-4436, 32)
+	}
+}
+
+func (e *mqEncoder) encodeID(id *jbig2IDDecoder, v int) {
+	prev := 1
+	for i := int(id.codeLen) - 1; i >= 0; i-- {
+		bit := v >> uint(i) & 1
+		e.encodeBit(id.cx, prev, bit)
+		prev = prev<<1 | bit
+	}
+}
+
+// encodeGeneric encodes the bitmap as decodeJBIG2Generic decodes it.
+func (e *mqEncoder) encodeGeneric(cx []uint8, bitmap *jbig2Bitmap, template int, tpgdon bool, at [][2]int) {
+	pixels := make([]jbig2Pixel, len(jbig2GenericTemplates[template]))
+	copy(pixels, jbig2GenericTemplates[template])
+	for i, px := range pixels {
+		if px.at >= 0 {
+			pixels[i].x, pixels[i].y = at[px.at][0], at[px.at][1]
+		}
+	}
+	ltp := 0
+	for y := 0; y < bitmap.height; y++ {
+		if tpgdon {
+			typical := 1
+			for x := 0; x < bitmap.width; x++ {
+				if bitmap.get(x, y) != bitmap.get(x, y-1) {
+					typical = 0
+				}
+			}
+			e.encodeBit(cx, jbig2TPGDONContexts[template], typical^ltp)
+			ltp = typical
+			if ltp == 1 {
+				continue
+			}
+		}
+		for x := 0; x < bitmap.width; x++ {
+			context := 0
+			for _, px := range pixels {
+				context = context<<1 | bitmap.get(x+px.x, y+px.y)
+			}
+			e.encodeBit(cx, context, bitmap.get(x, y))
+		}
+	}
+}
+
+// jbig2TestSegment returns a segment with its header, associated with page 1.
+func jbig2TestSegment(number uint32, segType int, refs []byte, data []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, number)
+	buf.WriteByte(byte(segType))
+	buf.WriteByte(byte(len(refs) << 5))
+	buf.Write(refs)
+	buf.WriteByte(1)
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// jbig2TestRegionInfo returns a region segment information field, with the OR combination operator.
+func jbig2TestRegionInfo(width, height, x, y int) []byte {
+	var buf bytes.Buffer
+	for _, v := range []int{width, height, x, y} {
+		binary.Write(&buf, binary.BigEndian, uint32(v))
+	}
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+func jbig2TestPageInfo(width, height int) []byte {
+	var buf bytes.Buffer
+	for _, v := range []int{width, height, 0, 0} {
+		binary.Write(&buf, binary.BigEndian, uint32(v))
+	}
+	buf.Write([]byte{0, 0, 0})
+	return buf.Bytes()
+}
+
+// jbig2TestBitmap returns a bitmap of the rows, '1' being black.
+func jbig2TestBitmap(t *testing.T, rows ...string) *jbig2Bitmap {
+	bitmap, err := newJBIG2Bitmap(len(rows[0]), len(rows))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for y, row := range rows {
+		for x, c := range row {
+			if c == '1' {
+				bitmap.set(x, y, 1)
+			}
+		}
+	}
+	return bitmap
+}
+
+// jbig2Inverted returns the data of the bitmap with 0 for black, as decoded.
+func jbig2Inverted(bitmap *jbig2Bitmap) []byte {
+	data := make([]byte, len(bitmap.data))
+	for i, b := range bitmap.data {
+		data[i] = ^b
+	}
+	return data
+}
+
+func TestMQDecoder(t *testing.T) {
+	bits := []int{}
+	seed := uint32(7)
+	for i := 0; i < 5000; i++ {
+		seed = seed*1103515245 + 12345
+		// Mostly 0 bits, with runs of 1 bits.
+		bit := 0
+		if seed>>16%10 == 0 || (i/500)%3 == 2 {
+			bit = 1
+		}
+		bits = append(bits, bit)
+	}
+
+	e := newMQEncoder()
+	cx := make([]uint8, 4)
+	for i, bit := range bits {
+		e.encodeBit(cx, i%4, bit)
+	}
+	data := e.flush()
+	if len(data) >= len(bits)/8 {
+		t.Errorf("Data not compressed (%d bytes)", len(data))
+	}
+
+	d := newMQDecoder(data)
+	cx = make([]uint8, 4)
+	for i, bit := range bits {
+		if decoded := d.decodeBit(cx, i%4); decoded != bit {
+			t.Fatalf("Wrong bit %d: %d", i, decoded)
+		}
+	}
+
+	e = newMQEncoder()
+	id := &jbig2IntDecoder{}
+	values := []int{0, 3, -4, 19, 20, 83, -84, 339, 4435, 100000}
+	for _, v := range values {
+		e.encodeInt(id, v, false)
+	}
+	e.encodeInt(id, 0, true)
+	d = newMQDecoder(e.flush())
+	id = &jbig2IntDecoder{}
+	for _, v := range values {
+		if decoded, ok := id.decode(d); !ok || decoded != v {
+			t.Errorf("Wrong integer %d (%v), expected %d", decoded, ok, v)
+		}
+	}
+	if _, ok := id.decode(d); ok {
+		t.Errorf("No out-of-band value")
+	}
+}
+
+func TestMQDecoderTestSequence(t *testing.T) {
+	// The test sequence of T.88 Annex H.2, coded with a single context, and the coded data.
+	bits := []byte{
+		0x00, 0x02, 0x00, 0x51, 0x00, 0x00, 0x00, 0xc0, 0x03, 0x52, 0x87, 0x2a, 0xaa, 0xaa, 0xaa, 0xaa,
+		0x82, 0xc0, 0x20, 0x00, 0xfc, 0xd7, 0x9e, 0xf6, 0xbf, 0x7f, 0xed, 0x90, 0x4f, 0x46, 0xa3, 0xbf,
+	}
+	coded := []byte{
+		0x84, 0xc7, 0x3b, 0xfc, 0xe1, 0xa1, 0x43, 0x04, 0x02, 0x20, 0x00, 0x00, 0x41, 0x0d, 0xbb, 0x86,
+		0xf4, 0x31, 0x7f, 0xff, 0x88, 0xff, 0x37, 0x47, 0x1a, 0xdb, 0x6a, 0xdf, 0xff, 0xac,
+	}
+
+	d := newMQDecoder(coded)
+	cx := make([]uint8, 1)
+	for i := 0; i < len(bits)*8; i++ {
+		bit := int(bits[i/8]>>uint(7-i%8)) & 1
+		if decoded := d.decodeBit(cx, 0); decoded != bit {
+			t.Fatalf("Wrong bit %d: %d", i, decoded)
+		}
+	}
+
+	// The encoder making the data of the other tests.
+	e := newMQEncoder()
+	cx = make([]uint8, 1)
+	for i := 0; i < len(bits)*8; i++ {
+		e.encodeBit(cx, 0, int(bits[i/8]>>uint(7-i%8))&1)
+	}
+	if data := e.flush(); !bytes.Equal(data, coded) {
+		t.Errorf("Wrong coded data % x", data)
+	}
+}
+
+func TestJBIG2GenericRegion(t *testing.T) {
+	rows := []string{}
+	for y := 0; y < 24; y++ {
+		row := make([]byte, 37)
+		for x := range row {
+			row[x] = '0'
+			// A disc and stripes, with duplicated rows for the typical prediction.
+			dx, dy := x-18, y/2*2-12
+			if dx*dx+dy*dy < 80 || (x+y/4)%9 == 0 {
+				row[x] = '1'
+			}
+		}
+		rows = append(rows, string(row))
+	}
+	region := jbig2TestBitmap(t, rows...)
+
+	for template := 0; template < 4; template++ {
+		for _, tpgdon := range []bool{false, true} {
+			at := [][2]int{{3, -1}, {-3, -1}, {2, -2}, {-2, -2}}
+			if template > 0 {
+				at = [][2]int{{2, -1}}
+			}
+			e := newMQEncoder()
+			e.encodeGeneric(make([]uint8, 1<<16), region, template, tpgdon, at)
+
+			flags := byte(template << 1)
+			if tpgdon {
+				flags |= 8
+			}
+			data := append(jbig2TestRegionInfo(region.width, region.height, 3, 2), flags)
+			for _, px := range at {
+				data = append(data, byte(int8(px[0])), byte(int8(px[1])))
+			}
+			data = append(data, e.flush()...)
+
+			encoded := jbig2TestSegment(0, jbig2PageInformation, nil, jbig2TestPageInfo(42, 30))
+			encoded = append(encoded, jbig2TestSegment(1, jbig2ImmediateGenericRegion, nil, data)...)
+			encoded = append(encoded, jbig2TestSegment(2, jbig2EndOfPage, nil, nil)...)
+
+			decoded, err := NewJBIG2Encoder().DecodeBytes(encoded)
+			if err != nil {
+				t.Fatalf("Error: %v", err)
+			}
+			page, _ := newJBIG2Bitmap(42, 30)
+			page.compose(region, 3, 2, jbig2CombOr)
+			if !bytes.Equal(decoded, jbig2Inverted(page)) {
+				t.Errorf("Template %d, TPGDON %v: wrong image", template, tpgdon)
+			}
+		}
+	}
+}
+
+func TestJBIG2MMRRegion(t *testing.T) {
+	// Two white rows: vertical mode V0 on an all white reference line.
+	data := append(jbig2TestRegionInfo(8, 2, 0, 0), 1, 0xc0)
+	encoded := jbig2TestSegment(0, jbig2PageInformation, nil, jbig2TestPageInfo(8, 2))
+	encoded = append(encoded, jbig2TestSegment(1, jbig2ImmediateGenericRegion, nil, data)...)
+	decoded, err := NewJBIG2Encoder().DecodeBytes(encoded)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(decoded, []byte{0xff, 0xff}) {
+		t.Errorf("Wrong image % x", decoded)
+	}
+}
+
+func TestJBIG2TextRegion(t *testing.T) {
+	symbols := []*jbig2Bitmap{
+		jbig2TestBitmap(t, "11", "10", "11"),
+		jbig2TestBitmap(t, "010", "111", "010"),
+	}
+	at := [][2]int{{3, -1}, {-3, -1}, {2, -2}, {-2, -2}}
+
+	// Symbol dictionary of one height class in the globals, both symbols exported.
+	e := newMQEncoder()
+	iadh, iadw, iaex := &jbig2IntDecoder{}, &jbig2IntDecoder{}, &jbig2IntDecoder{}
+	cx := make([]uint8, 1<<16)
+	e.encodeInt(iadh, 3, false)
+	e.encodeInt(iadw, 2, false)
+	e.encodeGeneric(cx, symbols[0], 0, false, at)
+	e.encodeInt(iadw, 1, false)
+	e.encodeGeneric(cx, symbols[1], 0, false, at)
+	e.encodeInt(iadw, 0, true)
+	e.encodeInt(iaex, 0, false)
+	e.encodeInt(iaex, 2, false)
+	dict := []byte{0, 0}
+	for _, px := range at {
+		dict = append(dict, byte(int8(px[0])), byte(int8(px[1])))
+	}
+	dict = append(dict, 0, 0, 0, 2, 0, 0, 0, 2)
+	globals := jbig2TestSegment(0, jbig2SymbolDictionary, nil, append(dict, e.flush()...))
+
+	// Text region: two strips, the reference corner being the top left corner of the symbols.
+	e = newMQEncoder()
+	iadt, iafs, iads := &jbig2IntDecoder{}, &jbig2IntDecoder{}, &jbig2IntDecoder{}
+	iaid := newJBIG2IDDecoder(1)
+	e.encodeInt(iadt, 0, false)
+	e.encodeInt(iadt, 2, false)
+	e.encodeInt(iafs, 1, false)
+	e.encodeID(iaid, 0)
+	e.encodeInt(iads, 1, false)
+	e.encodeID(iaid, 1)
+	e.encodeInt(iads, 0, true)
+	e.encodeInt(iadt, 4, false)
+	e.encodeInt(iafs, 3, false)
+	e.encodeID(iaid, 1)
+	e.encodeInt(iads, 0, true)
+	text := append(jbig2TestRegionInfo(12, 10, 2, 1), 0, 0x10, 0, 0, 0, 3)
+	text = append(text, e.flush()...)
+
+	encoded := jbig2TestSegment(1, jbig2PageInformation, nil, jbig2TestPageInfo(16, 12))
+	encoded = append(encoded, jbig2TestSegment(2, jbig2ImmediateTextRegion, []byte{0}, text)...)
+	encoded = append(encoded, jbig2TestSegment(3, jbig2EndOfPage, nil, nil)...)
+
+	// Decoded through the stream of an image with the JBIG2Globals stream.
+	globalsStream := &PdfObjectStream{PdfObjectDictionary: MakeDict(), Stream: globals}
+	decodeParams := MakeDict()
+	decodeParams.Set("JBIG2Globals", globalsStream)
+	stream := &PdfObjectStream{PdfObjectDictionary: MakeDict(), Stream: encoded}
+	stream.Set("Filter", MakeName(StreamEncodingFilterNameJBIG2))
+	stream.Set("DecodeParms", decodeParams)
+	decoded, err := DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := jbig2TestBitmap(t,
+		"0000000000000000",
+		"0000000000000000",
+		"0000000000000000",
+		"0001101000000000",
+		"0001011100000000",
+		"0001101000000000",
+		"0000000000000000",
+		"0000000100000000",
+		"0000001110000000",
+		"0000000100000000",
+		"0000000000000000",
+		"0000000000000000")
+	if !bytes.Equal(decoded, jbig2Inverted(expected)) {
+		t.Errorf("Wrong image % x", decoded)
+	}
+}
+
+func TestJBIG2Unsupported(t *testing.T) {
+	testcases := []struct {
+		name    string
+		segment []byte
+		err     error
+	}{
+		{"Huffman symbol dictionary", jbig2TestSegment(1, jbig2SymbolDictionary, nil,
+			append([]byte{0, 1}, 0, 0, 0, 1, 0, 0, 0, 1)), errJBIG2Huffman},
+		{"Symbol dictionary with refinement", jbig2TestSegment(1, jbig2SymbolDictionary, nil,
+			append([]byte{0, 2}, 0, 0, 0, 1, 0, 0, 0, 1)), errJBIG2Refinement},
+		{"Huffman text region", jbig2TestSegment(1, jbig2ImmediateTextRegion, nil,
+			append(jbig2TestRegionInfo(8, 8, 0, 0), 0, 1, 0, 0, 0, 0)), errJBIG2Huffman},
+		{"Text region with refinement", jbig2TestSegment(1, jbig2ImmediateTextRegion, nil,
+			append(jbig2TestRegionInfo(8, 8, 0, 0), 0, 2, 0, 0, 0, 0)), errJBIG2Refinement},
+		{"Refinement region", jbig2TestSegment(1, 42, nil,
+			append(jbig2TestRegionInfo(8, 8, 0, 0), 0, 0xff, 0xff, 0xff, 0xff)), errJBIG2Refinement},
+	}
+	for _, tc := range testcases {
+		encoded := jbig2TestSegment(0, jbig2PageInformation, nil, jbig2TestPageInfo(8, 8))
+		encoded = append(encoded, tc.segment...)
+		if _, err := NewJBIG2Encoder().DecodeBytes(encoded); err != tc.err {
+			t.Errorf("%s: wrong error %v", tc.name, err)
+		}
+	}
+}
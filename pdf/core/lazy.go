@@ -0,0 +1,89 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// lazyStream is the location of the data of a stream in the file, read when accessed.
+type lazyStream struct {
+	ra     io.ReaderAt
+	offset int64
+	length int64
+
+	// Decrypts the data read, for the streams of encrypted documents.
+	decrypt func(data []byte) ([]byte, error)
+}
+
+// SetLazyStreams sets the parser to leave the data of the streams of minLength bytes or more in the file when parsing
+// them: the data is read when accessed with PdfObjectStream.Load and PdfObjectStream.RawReader, and with DecodeStream
+// and NewDecodeReader.  Large documents, e.g. scanned documents with hundreds of MB of images, can then be processed
+// without loading all their streams in memory.
+//
+// The data is read with ReadAt, the io.ReadSeeker of the parser needs to be an io.ReaderAt as well, e.g. an os.File,
+// or an io.SectionReader of a memory mapped file.  It is read from the file when accessed, which must be kept open and
+// unchanged.  A minLength of 0 (default) disables the lazy streams.
+func (parser *PdfParser) SetLazyStreams(minLength int64) error {
+	if minLength > 0 {
+		if _, ok := parser.rs.(io.ReaderAt); !ok {
+			common.Log.Debug("ERROR: Lazy streams need an io.ReaderAt (%T)", parser.rs)
+			return errors.New("Lazy streams not supported by the reader")
+		}
+	}
+	parser.lazyStreamLength = minLength
+	return nil
+}
+
+// IsLoaded returns true if the data of the stream is in Stream, false for a lazy stream not loaded yet.
+func (stream *PdfObjectStream) IsLoaded() bool {
+	return stream.lazy == nil
+}
+
+// Load reads the data of a lazy stream from the file into Stream.  Nothing is done if the data is loaded already.
+func (stream *PdfObjectStream) Load() error {
+	if stream.lazy == nil {
+		return nil
+	}
+
+	data := make([]byte, stream.lazy.length)
+	n, err := stream.lazy.ra.ReadAt(data, stream.lazy.offset)
+	if n < len(data) {
+		common.Log.Debug("ERROR: Failed reading stream %d (%d/%d bytes): %v", stream.ObjectNumber, n, len(data), err)
+		return errors.New("Failed reading stream")
+	}
+	if stream.lazy.decrypt != nil {
+		data, err = stream.lazy.decrypt(data)
+		if err != nil {
+			return err
+		}
+		// Update the length based on the decrypted stream.
+		stream.Set("Length", MakeInteger(int64(len(data))))
+	}
+
+	stream.Stream = data
+	stream.lazy = nil
+	return nil
+}
+
+// RawReader returns a reader of the data of the stream as it is in the document, without decoding it.  The data of a
+// lazy stream is read from the file as it is read, except for the streams of encrypted documents which are loaded to
+// be decrypted (updating their Length).
+func (stream *PdfObjectStream) RawReader() (io.Reader, error) {
+	if stream.lazy != nil && stream.lazy.decrypt != nil {
+		if err := stream.Load(); err != nil {
+			return nil, err
+		}
+	}
+	if stream.lazy == nil {
+		return bytes.NewReader(stream.Stream), nil
+	}
+	return io.NewSectionReader(stream.lazy.ra, stream.lazy.offset, stream.lazy.length), nil
+}
@@ -0,0 +1,111 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// Lenient parsing of the malformed documents written by some producers, in lenient mode (see NewParserLenient):
+// - the keywords R and obj not separated from the numbers before them, e.g. "12 0R" or "12 0obj",
+// - numbers with repeated signs, e.g. "--5" (negative if any of the signs is a minus), or without digits, e.g. "-"
+//   (parsed as 0),
+// - names with invalid characters after the number sign '#', kept as is instead of a hexadecimal code,
+// - streams whose Length is wrong, missing or invalid, not followed by the endstream keyword: the data is found by
+//   scanning for endstream,
+// - dictionaries with duplicate keys, the last value being used.
+// Each of these is recorded as a diagnostic (see PdfParser.Diagnostics).
+
+var reReferenceLenient = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s*R`)
+var reIndirectObjectLenient = regexp.MustCompile(`(\d+)\s+(\d+)\s*obj`)
+
+// ParseDiagnostic is a problem of a malformed document worked around when parsing in lenient mode.
+type ParseDiagnostic struct {
+	// Offset in the file where the problem was found, -1 in object streams.
+	Offset  int64
+	Message string
+}
+
+func (diag ParseDiagnostic) String() string {
+	if diag.Offset < 0 {
+		return fmt.Sprintf("object stream: %s", diag.Message)
+	}
+	return fmt.Sprintf("offset %d: %s", diag.Offset, diag.Message)
+}
+
+// NewParserLenient creates a new parser for a PDF file as NewParser, in lenient mode: the common bugs of the
+// producers of malformed documents are tolerated, and reported by Diagnostics.
+func NewParserLenient(rs io.ReadSeeker) (*PdfParser, error) {
+	return newParser(rs, true)
+}
+
+// IsLenient returns true if the parser is in lenient mode.
+func (parser *PdfParser) IsLenient() bool {
+	return parser.lenient
+}
+
+// Diagnostics returns the problems worked around when parsing in lenient mode, in the order found.
+func (parser *PdfParser) Diagnostics() []ParseDiagnostic {
+	return parser.diagnostics
+}
+
+// diagnose records a problem worked around in lenient mode, at the current position.
+func (parser *PdfParser) diagnose(format string, args ...interface{}) {
+	diag := ParseDiagnostic{Offset: -1, Message: fmt.Sprintf(format, args...)}
+	if !parser.inObjectStream {
+		diag.Offset = parser.GetFileOffset()
+	}
+	common.Log.Debug("Lenient parsing: %s", diag)
+	parser.diagnostics = append(parser.diagnostics, diag)
+}
+
+// lenientStreamLength returns the length of the stream data starting at offset start: length if the data is
+// followed by the endstream keyword, otherwise found by scanning for endstream, without the end of line marker
+// before it.  A negative length is a missing or invalid Length.  The position is restored to start.
+func (parser *PdfParser) lenientStreamLength(start, length int64) (int64, error) {
+	defer parser.SetFileOffset(start)
+
+	if length >= 0 && start+length <= parser.fileSize {
+		parser.SetFileOffset(start + length)
+		parser.skipSpaces()
+		if bb, _ := parser.reader.Peek(9); string(bb) == "endstream" {
+			return length, nil
+		}
+	}
+
+	parser.SetFileOffset(start)
+	keyword := []byte("endstream")
+	var data []byte
+	for !bytes.HasSuffix(data, keyword) {
+		b, err := parser.reader.ReadByte()
+		if err != nil {
+			common.Log.Debug("ERROR: Stream without endstream at %d", start)
+			return 0, errors.New("Missing endstream")
+		}
+		data = append(data, b)
+	}
+	n := len(data) - len(keyword)
+	if n > 0 && data[n-1] == '\n' {
+		n--
+	}
+	if n > 0 && data[n-1] == '\r' {
+		n--
+	}
+
+	parser.SetFileOffset(start)
+	if length < 0 {
+		parser.diagnose("Missing or invalid stream Length, %d found", n)
+	} else {
+		parser.diagnose("Wrong stream Length %d, %d found", length, n)
+	}
+	return int64(n), nil
+}
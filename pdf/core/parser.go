@@ -8,6 +8,8 @@ package core
 import (
 	"bufio"
 	"bytes"
+	"crypto"
+	"crypto/x509"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -40,6 +42,7 @@ type PdfParser struct {
 	rs               io.ReadSeeker
 	reader           *bufio.Reader
 	fileSize         int64
+	xrefOffset       int64
 	xrefs            XrefTable
 	objstms          ObjectStreams
 	trailer          *PdfObjectDictionary
@@ -47,6 +50,14 @@ type PdfParser struct {
 	crypter          *PdfCrypt
 	repairsAttempted bool // Avoid multiple attempts for repair.
 
+	// Minimum length of the streams left in the file when parsed, 0 if disabled (see SetLazyStreams).
+	lazyStreamLength int64
+
+	// Lenient mode, and the problems worked around (see NewParserLenient).
+	lenient        bool
+	diagnostics    []ParseDiagnostic
+	inObjectStream bool // Parsing the objects of an object stream, not at a file position.
+
 	// Tracker for reference lookups when looking up Length entry of stream objects.
 	// The Length entries of stream objects are a special case, as they can require recursive parsing, i.e. look up
 	// the length reference (if not object) prior to reading the actual stream.  This has risks of endless looping.
@@ -70,6 +81,25 @@ func (parser *PdfParser) GetTrailer() *PdfObjectDictionary {
 	return parser.trailer
 }
 
+// GetXrefOffset returns the file offset of the last cross reference section (startxref), which is referred to
+// by the Prev entry of the trailer of an incremental update.
+func (parser *PdfParser) GetXrefOffset() int64 {
+	return parser.xrefOffset
+}
+
+// WriteOriginal writes the unmodified contents of the parsed file to w, e.g. as the base of an incremental update.
+// Returns the number of bytes written.
+func (parser *PdfParser) WriteOriginal(w io.Writer) (int64, error) {
+	_, err := parser.rs.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	// Subsequent reads need to seek to the object position (resets the buffered reader).
+	parser.reader = bufio.NewReader(parser.rs)
+
+	return io.CopyN(w, parser.rs, parser.fileSize)
+}
+
 // Skip over any spaces.
 func (parser *PdfParser) skipSpaces() (int, error) {
 	cnt := 0
@@ -201,15 +231,24 @@ func (parser *PdfParser) parseName() (PdfObjectName, error) {
 				break // Looks like start of next statement.
 			} else if bb[0] == '#' {
 				hexcode, err := parser.reader.Peek(3)
-				if err != nil {
+				if err != nil && !parser.lenient {
 					return PdfObjectName(r.String()), err
 				}
-				parser.reader.Discard(3)
-
-				code, err := hex.DecodeString(string(hexcode[1:3]))
+				var code []byte
+				if err == nil {
+					code, err = hex.DecodeString(string(hexcode[1:3]))
+				}
 				if err != nil {
-					return PdfObjectName(r.String()), err
+					if !parser.lenient {
+						return PdfObjectName(r.String()), err
+					}
+					// Not a hexadecimal code: keep the number sign.
+					parser.diagnose("Invalid character code in name %s#", r.String())
+					parser.reader.ReadByte()
+					r.WriteByte('#')
+					continue
 				}
+				parser.reader.Discard(3)
 				r.Write(code)
 			} else {
 				b, _ := parser.reader.ReadByte()
@@ -244,6 +283,26 @@ func (parser *PdfParser) parseNumber() (PdfObject, error) {
 	isFloat := false
 	allowSigns := true
 	var r bytes.Buffer
+	if parser.lenient {
+		// Repeated signs: negative if any of them is a minus.
+		negative, signs := false, 0
+		for {
+			bb, err := parser.reader.Peek(1)
+			if err != nil || (bb[0] != '-' && bb[0] != '+') {
+				break
+			}
+			b, _ := parser.reader.ReadByte()
+			negative = negative || b == '-'
+			signs++
+		}
+		if signs > 1 {
+			parser.diagnose("Number with %d signs", signs)
+		}
+		if negative {
+			r.WriteByte('-')
+		}
+		allowSigns = signs == 0
+	}
 	for {
 		common.Log.Trace("Parsing number \"%s\"", r.String())
 		bb, err := parser.reader.Peek(1)
@@ -280,6 +339,11 @@ func (parser *PdfParser) parseNumber() (PdfObject, error) {
 		}
 	}
 
+	if parser.lenient && !bytes.ContainsAny(r.Bytes(), "0123456789") {
+		parser.diagnose("Number without digits \"%s\"", r.String())
+		return MakeInteger(0), nil
+	}
+
 	if isFloat {
 		fVal, err := strconv.ParseFloat(r.String(), 64)
 		if err != nil {
@@ -466,7 +530,7 @@ func (parser *PdfParser) parseBool() (PdfObjectBool, error) {
 func parseReference(refStr string) (PdfObjectReference, error) {
 	objref := PdfObjectReference{}
 
-	result := reReference.FindStringSubmatch(string(refStr))
+	result := reReferenceLenient.FindStringSubmatch(string(refStr))
 	if len(result) < 3 {
 		common.Log.Debug("Error parsing reference")
 		return objref, errors.New("Unable to parse reference")
@@ -543,6 +607,12 @@ func (parser *PdfParser) parseObject() (PdfObject, error) {
 
 			// Match reference.
 			result1 := reReference.FindStringSubmatch(string(peekStr))
+			if len(result1) <= 1 && parser.lenient {
+				result1 = reReferenceLenient.FindStringSubmatch(string(peekStr))
+				if len(result1) > 1 {
+					parser.diagnose("No space before R in reference \"%s\"", result1[0])
+				}
+			}
 			if len(result1) > 1 {
 				bb, _ = parser.reader.ReadBytes('R')
 				common.Log.Trace("-> !Ref: '%s'", string(bb[:]))
@@ -567,6 +637,11 @@ func (parser *PdfParser) parseObject() (PdfObject, error) {
 				return num, err
 			}
 
+			if parser.lenient && (bb[0] == '-' || bb[0] == '+' || bb[0] == '.') {
+				// Number without digits.
+				return parser.parseNumber()
+			}
+
 			common.Log.Debug("ERROR Unknown (peek \"%s\")", peekStr)
 			return nil, errors.New("Object parsing error - unexpected pattern")
 		}
@@ -635,6 +710,9 @@ func (parser *PdfParser) ParseDict() (*PdfObjectDictionary, error) {
 		if err != nil {
 			return nil, err
 		}
+		if parser.lenient && dict.Get(keyName) != nil {
+			parser.diagnose("Duplicate key %s in dictionary, last value used", keyName)
+		}
 		dict.Set(keyName, val)
 
 		common.Log.Trace("dict[%s] = %s", keyName, val.String())
@@ -1199,6 +1277,8 @@ func (parser *PdfParser) loadXrefs() (*PdfObjectDictionary, error) {
 			return nil, err
 		}
 	}
+	parser.xrefOffset = offsetXref
+
 	// Read the xref.
 	parser.rs.Seek(int64(offsetXref), io.SeekStart)
 	parser.reader = bufio.NewReader(parser.rs)
@@ -1326,7 +1406,15 @@ func (parser *PdfParser) ParseIndirectObject() (PdfObject, error) {
 	}
 	common.Log.Trace("(indirect obj peek \"%s\"", string(bb))
 
-	indices := reIndirectObject.FindStringSubmatchIndex(string(bb))
+	reObject := reIndirectObject
+	indices := reObject.FindStringSubmatchIndex(string(bb))
+	if len(indices) < 6 && parser.lenient {
+		reObject = reIndirectObjectLenient
+		indices = reObject.FindStringSubmatchIndex(string(bb))
+		if len(indices) >= 6 {
+			parser.diagnose("No space before obj in object header \"%s\"", bb[indices[0]:indices[1]])
+		}
+	}
 	if len(indices) < 6 {
 		common.Log.Debug("ERROR: Unable to find object signature (%s)", string(bb))
 		return &indirect, errors.New("Unable to detect indirect object signature")
@@ -1344,7 +1432,7 @@ func (parser *PdfParser) ParseIndirectObject() (PdfObject, error) {
 	}
 	common.Log.Trace("textline: %s", hb)
 
-	result := reIndirectObject.FindStringSubmatch(string(hb))
+	result := reObject.FindStringSubmatch(string(hb))
 	if len(result) < 3 {
 		common.Log.Debug("ERROR: Unable to find object signature (%s)", string(hb))
 		return &indirect, errors.New("Unable to detect indirect object signature")
@@ -1422,16 +1510,22 @@ func (parser *PdfParser) ParseIndirectObject() (PdfObject, error) {
 					slo, err := parser.traceStreamLength(dict.Get("Length"))
 					if err != nil {
 						common.Log.Debug("Fail to trace stream length: %v", err)
-						return nil, err
+						if !parser.lenient {
+							return nil, err
+						}
 					}
 					common.Log.Trace("Stream length? %s", slo)
 
 					pstreamLength, ok := slo.(*PdfObjectInteger)
 					if !ok {
-						return nil, errors.New("Stream length needs to be an integer")
+						if !parser.lenient {
+							return nil, errors.New("Stream length needs to be an integer")
+						}
+						// Found by scanning for endstream.
+						pstreamLength = MakeInteger(-1)
 					}
 					streamLength := *pstreamLength
-					if streamLength < 0 {
+					if streamLength < 0 && !parser.lenient {
 						return nil, errors.New("Stream needs to be longer than 0")
 					}
 
@@ -1454,22 +1548,43 @@ func (parser *PdfParser) ParseIndirectObject() (PdfObject, error) {
 						dict.Set("Length", MakeInteger(newLength))
 					}
 
+					if parser.lenient {
+						length, err := parser.lenientStreamLength(streamStartOffset, int64(streamLength))
+						if err != nil {
+							return nil, err
+						}
+						if length != int64(streamLength) {
+							streamLength = PdfObjectInteger(length)
+							dict.Set("Length", MakeInteger(length))
+						}
+					}
+
 					// Make sure is less than actual file size.
 					if int64(streamLength) > parser.fileSize {
 						common.Log.Debug("ERROR: Stream length cannot be larger than file size")
 						return nil, errors.New("Invalid stream length, larger than file size")
 					}
 
-					stream := make([]byte, streamLength)
-					_, err = parser.ReadAtLeast(stream, int(streamLength))
-					if err != nil {
-						common.Log.Debug("ERROR stream (%d): %X", len(stream), stream)
-						common.Log.Debug("ERROR: %v", err)
-						return nil, err
+					streamobj := PdfObjectStream{}
+					if parser.lazyStreamLength > 0 && int64(streamLength) >= parser.lazyStreamLength {
+						// Lazy stream: read when accessed.
+						streamobj.lazy = &lazyStream{
+							ra:     parser.rs.(io.ReaderAt),
+							offset: streamStartOffset,
+							length: int64(streamLength),
+						}
+						parser.SetFileOffset(streamStartOffset + int64(streamLength))
+					} else {
+						stream := make([]byte, streamLength)
+						_, err = parser.ReadAtLeast(stream, int(streamLength))
+						if err != nil {
+							common.Log.Debug("ERROR stream (%d): %X", len(stream), stream)
+							common.Log.Debug("ERROR: %v", err)
+							return nil, err
+						}
+						streamobj.Stream = stream
 					}
 
-					streamobj := PdfObjectStream{}
-					streamobj.Stream = stream
 					streamobj.PdfObjectDictionary = indirect.PdfObject.(*PdfObjectDictionary)
 					streamobj.ObjectNumber = indirect.ObjectNumber
 					streamobj.GenerationNumber = indirect.GenerationNumber
@@ -1509,14 +1624,20 @@ func NewParserFromString(txt string) *PdfParser {
 // NewParser creates a new parser for a PDF file via ReadSeeker. Loads the cross reference stream and trailer.
 // An error is returned on failure.
 func NewParser(rs io.ReadSeeker) (*PdfParser, error) {
-	parser := &PdfParser{}
+	return newParser(rs, false)
+}
+
+func newParser(rs io.ReadSeeker, lenient bool) (*PdfParser, error) {
+	parser := &PdfParser{lenient: lenient}
 
 	parser.rs = rs
 	parser.ObjCache = make(ObjectCache)
 	parser.streamLengthReferenceLookupInProgress = map[int64]bool{}
 
 	// Start by reading the xrefs (from bottom).
+	stopTiming := common.StartTiming(common.TimingLoadXrefs)
 	trailer, err := parser.loadXrefs()
+	stopTiming()
 	if err != nil {
 		common.Log.Debug("ERROR: Failed to load xref table! %s", err)
 		return nil, err
@@ -1607,6 +1728,28 @@ func (parser *PdfParser) Decrypt(password []byte) (bool, error) {
 	return authenticated, err
 }
 
+// DecryptWithCertificate attempts to decrypt a PDF file encrypted with the public-key security handler
+// (Adobe.PubSec) with a recipient certificate and its private key.  Returns true if successful, false if the
+// certificate is not a recipient of the document.
+func (parser *PdfParser) DecryptWithCertificate(cert *x509.Certificate, key crypto.PrivateKey) (bool, error) {
+	if parser.crypter == nil {
+		return false, errors.New("Check encryption first")
+	}
+
+	return parser.crypter.authenticateCertificate(cert, key)
+}
+
+// CheckAccessRightsWithCertificate checks the access rights and permissions granted to a recipient certificate of a
+// PDF file encrypted with the public-key security handler.
+func (parser *PdfParser) CheckAccessRightsWithCertificate(cert *x509.Certificate, key crypto.PrivateKey) (bool,
+	AccessPermissions, error) {
+	if parser.crypter == nil {
+		return parser.CheckAccessRights(nil)
+	}
+
+	return parser.crypter.checkAccessRightsCertificate(cert, key)
+}
+
 // CheckAccessRights checks access rights and permissions for a specified password. If either user/owner password is
 // specified, full rights are granted, otherwise the access rights are specified by the Permissions flag.
 //
@@ -1617,17 +1760,18 @@ func (parser *PdfParser) CheckAccessRights(password []byte) (bool, AccessPermiss
 	// Also build the encryption/decryption key.
 	if parser.crypter == nil {
 		// If the crypter is not set, the file is not encrypted and we can assume full access permissions.
-		perms := AccessPermissions{}
-		perms.Printing = true
-		perms.Modify = true
-		perms.FillForms = true
-		perms.RotateInsert = true
-		perms.ExtractGraphics = true
-		perms.DisabilityExtract = true
-		perms.Annotate = true
-		perms.FullPrintQuality = true
-		return true, perms, nil
+		return true, FullAccessPermissions(), nil
 	}
 
 	return parser.crypter.checkAccessRights(password)
 }
+
+// GetAccessPermissions returns the access permissions granted by the authentication of the document: full access
+// if it is not encrypted or was decrypted with the owner password, otherwise the permissions of the document (or
+// of the recipient for the public-key security handler).
+func (parser *PdfParser) GetAccessPermissions() AccessPermissions {
+	if parser.crypter == nil || parser.crypter.ownerAuthenticated {
+		return FullAccessPermissions()
+	}
+	return parser.crypter.GetAccessPermissions()
+}
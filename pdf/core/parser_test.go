@@ -796,3 +796,81 @@ func TestMinimalPDFFile(t *testing.T) {
 	}
 }
 */
+
+func TestLenientParsing(t *testing.T) {
+	testcases := []struct {
+		raw         string
+		expected    string
+		strict      bool // Parsed as expected in strict mode.
+		diagnostics int
+	}{
+		{"--5 ", "-5", false, 1},
+		{"+-2.5 ", "-2.500000", false, 1},
+		{"- ", "0", false, 1},
+		{"[1 0R 2 0 R]", "[Ref(1 0), Ref(2 0)]", false, 1},
+		{"/A#G1 ", "A#G1", false, 1},
+		{"/A#41 ", "AA", true, 0},
+		{"<</A 1 /B 2 /A 3>>", "Dict(\"A\": 3, \"B\": 2, )", true, 1},
+	}
+	for _, tcase := range testcases {
+		parser := makeParserForText(tcase.raw)
+		obj, err := parser.parseObject()
+		if parsed := err == nil && obj.String() == tcase.expected; parsed != tcase.strict {
+			t.Errorf("Wrong strict parsing of %q: %v (%v)", tcase.raw, obj, err)
+		}
+
+		parser = makeParserForText(tcase.raw)
+		parser.lenient = true
+		obj, err = parser.parseObject()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if obj.String() != tcase.expected {
+			t.Errorf("%q parsed as %s, expected %s", tcase.raw, obj, tcase.expected)
+		}
+		if len(parser.Diagnostics()) != tcase.diagnostics {
+			t.Errorf("Wrong diagnostics for %q: %v", tcase.raw, parser.Diagnostics())
+		}
+	}
+}
+
+func TestLenientStreamLength(t *testing.T) {
+	testcases := []struct {
+		raw      string
+		expected string
+	}{
+		{"1 0 obj\n<</Length 20>>\nstream\nabcd\nendstream\nendobj\n", "abcd"},
+		{"1 0 obj\n<</Length 2>>\nstream\r\nabcd\r\nendstream\nendobj\n", "abcd"},
+		{"1 0 obj\n<</Length 2 0 R>>\nstream\nabcdendstream\nendobj\n", "abcd"},
+		{"1 0obj\n<<>>\nstream\nab\ncd\nendstream\nendobj\n", "ab\ncd"},
+	}
+	for _, tcase := range testcases {
+		parser := makeParserForText(tcase.raw)
+		parser.streamLengthReferenceLookupInProgress = map[int64]bool{}
+		obj, err := parser.ParseIndirectObject()
+		if stream, ok := obj.(*PdfObjectStream); err == nil && ok && string(stream.Stream) == tcase.expected {
+			t.Errorf("%q parsed in strict mode", tcase.raw)
+		}
+
+		parser = makeParserForText(tcase.raw)
+		parser.streamLengthReferenceLookupInProgress = map[int64]bool{}
+		parser.lenient = true
+		obj, err = parser.ParseIndirectObject()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		stream, ok := obj.(*PdfObjectStream)
+		if !ok {
+			t.Fatalf("Not a stream %q", tcase.raw)
+		}
+		if string(stream.Stream) != tcase.expected {
+			t.Errorf("Wrong stream data %q, expected %q", stream.Stream, tcase.expected)
+		}
+		if length, ok := stream.Get("Length").(*PdfObjectInteger); !ok || int(*length) != len(tcase.expected) {
+			t.Errorf("Wrong Length %v", stream.Get("Length"))
+		}
+		if len(parser.Diagnostics()) == 0 {
+			t.Errorf("No diagnostics for %q", tcase.raw)
+		}
+	}
+}
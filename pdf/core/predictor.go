@@ -0,0 +1,343 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// Predictors of the FlateDecode and LZWDecode filters (7.4.4.4): the samples of images are replaced by their
+// difference with a prediction from the neighbouring samples, which compresses much better.
+//
+// Predictor 1 is no prediction, 2 the TIFF predictor 2 (difference with the sample to the left) and 10 to 15 the PNG
+// predictors, each row starting with the byte of the PNG filter type used: 10 None, 11 Sub, 12 Up, 13 Average, 14 Paeth
+// for all the rows, and 15 the filter selected for each row when encoding.
+
+// PNG filter types.
+const (
+	pngFilterNone    = 0
+	pngFilterSub     = 1
+	pngFilterUp      = 2
+	pngFilterAverage = 3
+	pngFilterPaeth   = 4
+)
+
+// predictorParams are the parameters of a predictor from the decode parameters: the number of samples per row
+// (Columns), of color components per sample (Colors) and of bits per component.
+type predictorParams struct {
+	predictor int
+	columns   int
+	colors    int
+	bpc       int
+}
+
+// check checks the parameters are valid.
+func (p predictorParams) check() error {
+	if p.columns < 1 || p.colors < 1 {
+		common.Log.Debug("ERROR: Invalid predictor columns (%d) or colors (%d)", p.columns, p.colors)
+		return errors.New("Invalid predictor parameters")
+	}
+	switch p.bpc {
+	case 1, 2, 4, 8, 16:
+	default:
+		common.Log.Debug("ERROR: Invalid predictor bits per component (%d)", p.bpc)
+		return fmt.Errorf("Invalid BitsPerComponent=%d", p.bpc)
+	}
+	return nil
+}
+
+// rowBytes returns the number of bytes of a row of samples, without the PNG filter type byte.
+func (p predictorParams) rowBytes() int {
+	return (p.columns*p.colors*p.bpc + 7) / 8
+}
+
+// pixelBytes returns the number of bytes of a sample, at least 1: the distance to the byte of the sample to the left
+// for the PNG filters.
+func (p predictorParams) pixelBytes() int {
+	return (p.colors*p.bpc + 7) / 8
+}
+
+// predictorEncode applies the predictor to the data.
+func predictorEncode(data []byte, p predictorParams) ([]byte, error) {
+	if p.predictor <= 1 {
+		return data, nil
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	rowLength := p.rowBytes()
+	if len(data)%rowLength != 0 {
+		common.Log.Debug("ERROR: Invalid data length %d for rows of %d bytes", len(data), rowLength)
+		return nil, errors.New("Invalid row length")
+	}
+	rows := len(data) / rowLength
+
+	switch {
+	case p.predictor == 2:
+		out := make([]byte, len(data))
+		for i := 0; i < rows; i++ {
+			tiffPredictRow(out[i*rowLength:(i+1)*rowLength], data[i*rowLength:(i+1)*rowLength], p, true)
+		}
+		return out, nil
+	case p.predictor >= 10 && p.predictor <= 15:
+		out := make([]byte, 0, len(data)+rows)
+		bpp := p.pixelBytes()
+		prev := make([]byte, rowLength)
+		filtered := make([]byte, rowLength)
+		best := make([]byte, rowLength)
+		for i := 0; i < rows; i++ {
+			row := data[i*rowLength : (i+1)*rowLength]
+			filter := p.predictor - 10
+			if p.predictor == 15 {
+				// Heuristic of the PNG specification: the filter minimizing the sum of the absolute values of the
+				// differences, as signed bytes.
+				bestSum := -1
+				for f := pngFilterNone; f <= pngFilterPaeth; f++ {
+					pngFilterRow(filtered, row, prev, bpp, f)
+					sum := 0
+					for _, b := range filtered {
+						sum += absInt(int(int8(b)))
+					}
+					if bestSum < 0 || sum < bestSum {
+						bestSum = sum
+						filter = f
+						copy(best, filtered)
+					}
+				}
+			} else {
+				pngFilterRow(best, row, prev, bpp, filter)
+			}
+			out = append(out, byte(filter))
+			out = append(out, best...)
+			prev = row
+		}
+		return out, nil
+	}
+	common.Log.Debug("ERROR: Unsupported predictor (%d)", p.predictor)
+	return nil, fmt.Errorf("Unsupported predictor (%d)", p.predictor)
+}
+
+// predictorDecode reverts the predictor applied to the data.
+func predictorDecode(data []byte, p predictorParams) ([]byte, error) {
+	if p.predictor <= 1 {
+		return data, nil
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.predictor == 2:
+		rowLength := p.rowBytes()
+		if len(data)%rowLength != 0 {
+			common.Log.Debug("ERROR: TIFF encoding: Invalid row length...")
+			return nil, fmt.Errorf("Invalid row length (%d/%d)", len(data), rowLength)
+		}
+		out := make([]byte, len(data))
+		for i := 0; i < len(data)/rowLength; i++ {
+			tiffPredictRow(out[i*rowLength:(i+1)*rowLength], data[i*rowLength:(i+1)*rowLength], p, false)
+		}
+		return out, nil
+	case p.predictor >= 10 && p.predictor <= 15:
+		// 1 byte to specify the filter of each row.
+		rowLength := p.rowBytes() + 1
+		if len(data)%rowLength != 0 {
+			return nil, fmt.Errorf("Invalid row length (%d/%d)", len(data), rowLength)
+		}
+		if rowLength > len(data) {
+			common.Log.Debug("Row length cannot be longer than data length (%d/%d)", rowLength, len(data))
+			return nil, errors.New("Range check error")
+		}
+		rows := len(data) / rowLength
+		out := make([]byte, rows*(rowLength-1))
+		bpp := p.pixelBytes()
+		prev := make([]byte, rowLength-1)
+		for i := 0; i < rows; i++ {
+			filter := int(data[i*rowLength])
+			if filter > pngFilterPaeth {
+				common.Log.Debug("ERROR: Invalid filter byte (%d) @row %d", filter, i)
+				return nil, fmt.Errorf("Invalid filter byte (%d)", filter)
+			}
+			row := out[i*(rowLength-1) : (i+1)*(rowLength-1)]
+			pngUnfilterRow(row, data[i*rowLength+1:(i+1)*rowLength], prev, bpp, filter)
+			prev = row
+		}
+		return out, nil
+	}
+	common.Log.Debug("ERROR: Unsupported predictor (%d)", p.predictor)
+	return nil, fmt.Errorf("Unsupported predictor (%d)", p.predictor)
+}
+
+// pngPaeth returns the Paeth predictor of the bytes to the left, above and to the upper left.
+func pngPaeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa := absInt(p - int(a))
+	pb := absInt(p - int(b))
+	pc := absInt(p - int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+// pngPrediction returns the prediction of the byte j of the row by the PNG filter, from the bytes of the row
+// before j and the previous row.
+func pngPrediction(row, prev []byte, j, bpp, filter int) byte {
+	var left, upLeft byte
+	if j >= bpp {
+		left = row[j-bpp]
+		upLeft = prev[j-bpp]
+	}
+	switch filter {
+	case pngFilterSub:
+		return left
+	case pngFilterUp:
+		return prev[j]
+	case pngFilterAverage:
+		return byte((int(left) + int(prev[j])) / 2)
+	case pngFilterPaeth:
+		return pngPaeth(left, prev[j], upLeft)
+	}
+	return 0
+}
+
+// pngFilterRow filters the row with the previous row prev into out.
+func pngFilterRow(out, row, prev []byte, bpp, filter int) {
+	for j := range row {
+		out[j] = row[j] - pngPrediction(row, prev, j, bpp, filter)
+	}
+}
+
+// pngUnfilterRow reverts the filter of the filtered row into row, with the previous row prev decoded.
+func pngUnfilterRow(row, filtered, prev []byte, bpp, filter int) {
+	for j := range filtered {
+		row[j] = filtered[j] + pngPrediction(row, prev, j, bpp, filter)
+	}
+}
+
+// tiffPredictRow applies (encode) or reverts the TIFF predictor 2 on a row of samples in into out: the difference
+// of each color component with the same component of the sample to the left.
+func tiffPredictRow(out, in []byte, p predictorParams, encode bool) {
+	if p.bpc == 8 {
+		for j := range in {
+			out[j] = in[j]
+			if j >= p.colors {
+				if encode {
+					out[j] = in[j] - in[j-p.colors]
+				} else {
+					out[j] = in[j] + out[j-p.colors]
+				}
+			}
+		}
+		return
+	}
+
+	// Samples of other sizes.
+	mask := uint32(1)<<uint(p.bpc) - 1
+	get := func(data []byte, i int) uint32 {
+		if p.bpc == 16 {
+			return uint32(data[2*i])<<8 | uint32(data[2*i+1])
+		}
+		bit := i * p.bpc
+		return uint32(data[bit/8]>>uint(8-p.bpc-bit%8)) & mask
+	}
+	set := func(data []byte, i int, val uint32) {
+		if p.bpc == 16 {
+			data[2*i], data[2*i+1] = byte(val>>8), byte(val)
+			return
+		}
+		bit := i * p.bpc
+		shift := uint(8 - p.bpc - bit%8)
+		data[bit/8] = data[bit/8]&^byte(mask<<shift) | byte((val&mask)<<shift)
+	}
+	copy(out, in)
+	for i := p.colors; i < p.columns*p.colors; i++ {
+		if encode {
+			set(out, i, get(in, i)-get(in, i-p.colors))
+		} else {
+			set(out, i, get(in, i)+get(out, i-p.colors))
+		}
+	}
+}
+
+// predictorReader reverts the predictor applied to the data of a reader, row by row as the data is read.
+type predictorReader struct {
+	r    io.Reader
+	p    predictorParams
+	in   []byte // Encoded row, with the PNG filter type byte first.
+	row  []byte // Current decoded row.
+	prev []byte // Previous decoded row.
+	pos  int    // Position of the data in row not read yet.
+}
+
+// newPredictorReader returns a reader reverting the predictor of the data read from r, r itself if there is no
+// predictor.
+func newPredictorReader(r io.Reader, p predictorParams) (io.Reader, error) {
+	if p.predictor <= 1 {
+		return r, nil
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	if p.predictor != 2 && (p.predictor < 10 || p.predictor > 15) {
+		common.Log.Debug("ERROR: Unsupported predictor (%d)", p.predictor)
+		return nil, fmt.Errorf("Unsupported predictor (%d)", p.predictor)
+	}
+
+	rowLength := p.rowBytes()
+	pr := &predictorReader{r: r, p: p, row: make([]byte, rowLength), prev: make([]byte, rowLength)}
+	pr.pos = rowLength
+	if p.predictor >= 10 {
+		// 1 byte to specify the filter of each row.
+		rowLength++
+	}
+	pr.in = make([]byte, rowLength)
+	return pr, nil
+}
+
+func (pr *predictorReader) Read(p []byte) (int, error) {
+	if pr.pos >= len(pr.row) {
+		if err := pr.nextRow(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, pr.row[pr.pos:])
+	pr.pos += n
+	return n, nil
+}
+
+// nextRow reads and decodes the next row.
+func (pr *predictorReader) nextRow() error {
+	_, err := io.ReadFull(pr.r, pr.in)
+	if err == io.ErrUnexpectedEOF {
+		common.Log.Debug("ERROR: Incomplete row of %d bytes", len(pr.in))
+		return errors.New("Invalid row length")
+	}
+	if err != nil {
+		return err
+	}
+
+	pr.row, pr.prev = pr.prev, pr.row
+	if pr.p.predictor == 2 {
+		tiffPredictRow(pr.row, pr.in, pr.p, false)
+	} else {
+		filter := int(pr.in[0])
+		if filter > pngFilterPaeth {
+			common.Log.Debug("ERROR: Invalid filter byte (%d)", filter)
+			return fmt.Errorf("Invalid filter byte (%d)", filter)
+		}
+		pngUnfilterRow(pr.row, pr.in[1:], pr.prev, pr.p.pixelBytes(), filter)
+	}
+	pr.pos = 0
+	return nil
+}
@@ -8,6 +8,8 @@ package core
 import (
 	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/unidoc/unidoc/common"
 )
@@ -66,6 +68,9 @@ type PdfObjectStream struct {
 	PdfObjectReference
 	*PdfObjectDictionary
 	Stream []byte
+
+	// Location of the data in the file for lazy streams until loaded (see PdfParser.SetLazyStreams).
+	lazy *lazyStream
 }
 
 // MakeDict creates and returns an empty PdfObjectDictionary.
@@ -88,6 +93,12 @@ func MakeInteger(val int64) *PdfObjectInteger {
 	return &num
 }
 
+// MakeBool creates a PdfObjectBool from a bool.
+func MakeBool(val bool) *PdfObjectBool {
+	b := PdfObjectBool(val)
+	return &b
+}
+
 // MakeArray creates an PdfObjectArray from a list of PdfObjects.
 func MakeArray(objects ...PdfObject) *PdfObjectArray {
 	array := PdfObjectArray{}
@@ -203,9 +214,54 @@ func (float *PdfObjectFloat) String() string {
 	return fmt.Sprintf("%f", *float)
 }
 
-// DefaultWriteString outputs the object as it is to be written to file.
+// DefaultWriteString outputs the object as it is to be written to file, in the number format set.
 func (float *PdfObjectFloat) DefaultWriteString() string {
-	return fmt.Sprintf("%f", *float)
+	return FormatFloat(float64(*float))
+}
+
+// NumberFormat specifies how real numbers are written to content streams and dictionaries.
+type NumberFormat struct {
+	// The number of digits after the decimal point.
+	Precision int
+
+	// Trim the trailing zeros of the fractional part, and the decimal point of integral values, e.g. 1.5 and 2
+	// instead of 1.500000 and 2.000000, for smaller files.
+	TrimZeros bool
+}
+
+// The number format of the real numbers written, by default with 6 digits.
+var numberFormat = NumberFormat{Precision: 6}
+
+// SetNumberFormat sets the format of the real numbers written to content streams and dictionaries, e.g. fixed for
+// byte-stable output in regression tests.  The precision is limited to 0 to 10 digits.  Not to be changed while
+// writing.
+func SetNumberFormat(format NumberFormat) {
+	if format.Precision < 0 {
+		format.Precision = 0
+	} else if format.Precision > 10 {
+		format.Precision = 10
+	}
+	numberFormat = format
+}
+
+// GetNumberFormat returns the format of the real numbers written.
+func GetNumberFormat() NumberFormat {
+	return numberFormat
+}
+
+// FormatFloat returns the real number as written to content streams and dictionaries, in the number format set.
+func FormatFloat(val float64) string {
+	s := strconv.FormatFloat(val, 'f', numberFormat.Precision, 64)
+	if !numberFormat.TrimZeros {
+		return s
+	}
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
 }
 
 func (str *PdfObjectString) String() string {
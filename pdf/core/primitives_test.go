@@ -0,0 +1,31 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import "testing"
+
+func TestNumberFormat(t *testing.T) {
+	defer SetNumberFormat(GetNumberFormat())
+
+	arr := MakeArrayFromFloats([]float64{1.5, 2, -0.0000001, 1.0 / 3})
+	if s := arr.DefaultWriteString(); s != "[1.500000 2.000000 -0.000000 0.333333]" {
+		t.Errorf("Wrong default format %s", s)
+	}
+
+	for _, tc := range []struct {
+		format   NumberFormat
+		expected string
+	}{
+		{NumberFormat{Precision: 2}, "[1.50 2.00 -0.00 0.33]"},
+		{NumberFormat{Precision: 3, TrimZeros: true}, "[1.5 2 0 0.333]"},
+		{NumberFormat{Precision: -1, TrimZeros: true}, "[2 2 0 0]"},
+	} {
+		SetNumberFormat(tc.format)
+		if s := arr.DefaultWriteString(); s != tc.expected {
+			t.Errorf("Format %+v: %s, expected %s", tc.format, s, tc.expected)
+		}
+	}
+}
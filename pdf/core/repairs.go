@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 
 	"bufio"
 	"io"
@@ -104,6 +105,17 @@ func (parser *PdfParser) repairRebuildXrefsTopDown() (*XrefTable, error) {
 	}
 	parser.repairsAttempted = true
 
+	xrefTable, _, err := parser.repairScanFile()
+	if err != nil {
+		return nil, err
+	}
+	return &xrefTable, nil
+}
+
+// Scans the entire file from top down for "<num> <generation> obj" patterns and "trailer" keywords.  Returns the
+// xref table of the objects found, with the last one in the file for each object number and generation, and the
+// offsets following the trailer keywords.
+func (parser *PdfParser) repairScanFile() (XrefTable, []int64, error) {
 	// Go to beginning, reset reader.
 	parser.rs.Seek(0, os.SEEK_SET)
 	parser.reader = bufio.NewReader(parser.rs)
@@ -113,16 +125,21 @@ func (parser *PdfParser) repairRebuildXrefsTopDown() (*XrefTable, error) {
 	last := make([]byte, bufLen)
 
 	xrefTable := XrefTable{}
+	trailerOffsets := []int64{}
 	for {
 		b, err := parser.reader.ReadByte()
 		if err != nil {
 			if err == io.EOF {
 				break
 			} else {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
+		if b == 'r' && string(last[bufLen-6:]) == "traile" {
+			trailerOffsets = append(trailerOffsets, parser.GetFileOffset())
+		}
+
 		// Format:
 		// object number - whitespace - generation number - obj
 		// e.g. "12 0 obj"
@@ -163,11 +180,12 @@ func (parser *PdfParser) repairRebuildXrefsTopDown() (*XrefTable, error) {
 			objNum, genNum, err := parseObjectNumberFromString(string(objstr))
 			if err != nil {
 				common.Log.Debug("Unable to parse object number: %v", err)
-				return nil, err
+				return nil, nil, err
 			}
 
-			// Create and insert the XREF entry if not existing, or the generation number is higher.
-			if curXref, has := xrefTable[objNum]; !has || curXref.generation < genNum {
+			// Create and insert the XREF entry if not existing, or the generation number is not lower: later
+			// definitions of an object (incremental updates) replace the earlier ones.
+			if curXref, has := xrefTable[objNum]; !has || curXref.generation <= genNum {
 				// Make the entry for the cross ref table.
 				xrefEntry := XrefObject{}
 				xrefEntry.xtype = XREF_TABLE_ENTRY
@@ -181,7 +199,7 @@ func (parser *PdfParser) repairRebuildXrefsTopDown() (*XrefTable, error) {
 		last = append(last[1:bufLen], b)
 	}
 
-	return &xrefTable, nil
+	return xrefTable, trailerOffsets, nil
 }
 
 // Look for first sign of xref table from end of file.
@@ -284,3 +302,286 @@ func (parser *PdfParser) seekPdfVersionTopDown() (int, int, error) {
 
 	return 0, 0, errors.New("Version not found")
 }
+
+// RepairReport reports what was reconstructed when repairing a document with broken or missing cross references or
+// trailer (see PdfParser.Repair).
+type RepairReport struct {
+	// Number of objects found in the file, and among them in object streams.
+	Objects           int
+	CompressedObjects int
+	// Number of objects found in the file which could not be parsed, and were left out.
+	InvalidObjects int
+	// Number of trailer dictionaries (of xref tables or streams) found in the file, 0 if the trailer was rebuilt
+	// from the objects only.
+	Trailers int
+	// True if the catalog was recovered by looking for the Catalog object, the trailer not referencing a valid one.
+	CatalogRecovered bool
+	// True if the document information dictionary was recovered by looking for it, without trailer.
+	InfoRecovered bool
+}
+
+func (report *RepairReport) String() string {
+	str := fmt.Sprintf("%d objects (%d in object streams), %d invalid", report.Objects, report.CompressedObjects,
+		report.InvalidObjects)
+	if report.Trailers > 0 {
+		str += fmt.Sprintf(", trailer from %d trailer dictionaries", report.Trailers)
+	} else {
+		str += ", trailer rebuilt"
+	}
+	if report.CatalogRecovered {
+		str += ", catalog recovered"
+	}
+	if report.InfoRecovered {
+		str += ", document information recovered"
+	}
+	return str
+}
+
+// NewParserWithRepair creates a new parser for a PDF file as NewParser, repairing the document with Repair if its
+// cross references or trailer are broken or missing, or its catalog cannot be loaded.  The report is nil if the
+// document did not need to be repaired.
+func NewParserWithRepair(rs io.ReadSeeker) (*PdfParser, *RepairReport, error) {
+	parser, err := NewParser(rs)
+	if err == nil {
+		if _, err = parser.repairCatalog(parser.trailer); err == nil {
+			return parser, nil, nil
+		}
+	} else {
+		parser = &PdfParser{rs: rs, ObjCache: make(ObjectCache)}
+		parser.streamLengthReferenceLookupInProgress = map[int64]bool{}
+	}
+
+	common.Log.Debug("Repairing the document: %v", err)
+	report, err := parser.Repair()
+	if err != nil {
+		return nil, nil, err
+	}
+	return parser, report, nil
+}
+
+// Repair rebuilds the cross references and the trailer of a document by scanning the file for its objects, when they
+// are broken or missing, e.g. in truncated or badly edited files, as other readers do.  The last definition of each
+// object in the file is used, including the objects of the object streams (except for encrypted documents, where
+// they cannot be decoded before decryption).  The trailer is rebuilt from the trailer dictionaries found in the file,
+// and the catalog is recovered by looking for the Catalog object if the trailer does not reference a valid one.
+func (parser *PdfParser) Repair() (*RepairReport, error) {
+	fSize, err := parser.rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	parser.fileSize = fSize
+
+	xrefs, trailerOffsets, err := parser.repairScanFile()
+	if err != nil {
+		common.Log.Debug("ERROR: Failed scanning the file: %v", err)
+		return nil, err
+	}
+	// Avoid repairing again when looking up the objects.
+	parser.repairsAttempted = true
+	parser.xrefs = xrefs
+	parser.objstms = ObjectStreams{}
+	parser.ObjCache = ObjectCache{}
+	if parser.majorVersion == 0 {
+		parser.majorVersion, parser.minorVersion, err = parser.parsePdfVersion()
+		if err != nil {
+			common.Log.Debug("Version not found: %v", err)
+		}
+	}
+
+	// Check the objects in the order of the file, keeping the last catalog and document information candidates.
+	report := &RepairReport{}
+	nums := []int{}
+	for num := range xrefs {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return xrefs[nums[i]].offset < xrefs[nums[j]].offset })
+
+	type trailerDict struct {
+		offset int64
+		dict   *PdfObjectDictionary
+	}
+	trailers := []trailerDict{}
+	objstms := []int{}
+	catalog, info := 0, 0
+	// position returns the position of an object in the file, of its object stream if compressed.
+	position := func(num int) int64 {
+		xref := parser.xrefs[num]
+		if xref.xtype == XREF_OBJECT_STREAM {
+			return parser.xrefs[xref.osObjNumber].offset
+		}
+		return xref.offset
+	}
+	check := func(num int) {
+		obj, _, err := parser.lookupByNumber(num, false)
+		if err == nil {
+			var realNum int64
+			realNum, _, err = getObjectNumber(obj)
+			if err == nil && int(realNum) != num {
+				err = fmt.Errorf("Object number %d instead of %d", realNum, num)
+			}
+		}
+		if err != nil {
+			common.Log.Debug("Invalid object %d: %v", num, err)
+			report.InvalidObjects++
+			delete(parser.xrefs, num)
+			return
+		}
+
+		var dict *PdfObjectDictionary
+		switch t := obj.(type) {
+		case *PdfIndirectObject:
+			dict, _ = t.PdfObject.(*PdfObjectDictionary)
+		case *PdfObjectStream:
+			dict = t.PdfObjectDictionary
+		}
+		if dict == nil {
+			return
+		}
+		typ, _ := dict.Get("Type").(*PdfObjectName)
+		switch {
+		case typ == nil:
+			if isRepairInfoDict(dict) && (info == 0 || position(num) >= position(info)) {
+				info = num
+			}
+		case *typ == "Catalog":
+			if catalog == 0 || position(num) >= position(catalog) {
+				catalog = num
+			}
+		case *typ == "ObjStm":
+			objstms = append(objstms, num)
+		case *typ == "XRef":
+			trailers = append(trailers, trailerDict{position(num), dict})
+		}
+	}
+	for _, num := range nums {
+		check(num)
+	}
+
+	// The objects of the object streams, in the order of the file, unless defined again after the object stream.
+	for _, osNum := range objstms {
+		objstm, err := parser.loadObjectStream(osNum)
+		if err != nil {
+			common.Log.Debug("Object stream %d not loaded: %v", osNum, err)
+			continue
+		}
+		onums := []int{}
+		for num := range objstm.offsets {
+			onums = append(onums, num)
+		}
+		sort.Slice(onums, func(i, j int) bool { return objstm.offsets[onums[i]] < objstm.offsets[onums[j]] })
+		for idx, num := range onums {
+			if xref, has := parser.xrefs[num]; has && position(num) > parser.xrefs[osNum].offset {
+				continue
+			} else if has && xref.xtype == XREF_TABLE_ENTRY {
+				delete(parser.ObjCache, num)
+			}
+			parser.xrefs[num] = XrefObject{xtype: XREF_OBJECT_STREAM, objectNumber: num, osObjNumber: osNum,
+				osObjIndex: idx}
+			check(num)
+			if _, has := parser.xrefs[num]; has {
+				report.CompressedObjects++
+			}
+		}
+	}
+	report.Objects = len(parser.xrefs)
+
+	// The trailer dictionaries of xref tables, with the ones of the xref streams, the later ones replacing the
+	// entries of the earlier ones.
+	for _, offset := range trailerOffsets {
+		parser.SetFileOffset(offset)
+		parser.skipSpaces()
+		dict, err := parser.ParseDict()
+		if err != nil {
+			common.Log.Debug("Invalid trailer at %d: %v", offset, err)
+			continue
+		}
+		trailers = append(trailers, trailerDict{offset, dict})
+	}
+	sort.Slice(trailers, func(i, j int) bool { return trailers[i].offset < trailers[j].offset })
+	report.Trailers = len(trailers)
+	found := MakeDict()
+	for _, t := range trailers {
+		for _, key := range []PdfObjectName{"Root", "Info", "Encrypt", "ID"} {
+			if val := t.dict.Get(key); val != nil {
+				found.Set(key, val)
+			}
+		}
+	}
+
+	maxNum := 0
+	for num := range parser.xrefs {
+		if num > maxNum {
+			maxNum = num
+		}
+	}
+	trailer := MakeDict()
+	trailer.Set("Size", MakeInteger(int64(maxNum+1)))
+	for _, key := range found.Keys() {
+		trailer.Set(key, found.Get(key))
+	}
+
+	if _, err := parser.repairCatalog(trailer); err != nil {
+		if catalog == 0 {
+			common.Log.Debug("ERROR: Catalog not found")
+			return nil, errors.New("Catalog not found")
+		}
+		common.Log.Debug("Catalog recovered: object %d (%v)", catalog, err)
+		trailer.Set("Root", &PdfObjectReference{ObjectNumber: int64(catalog),
+			GenerationNumber: int64(parser.xrefs[catalog].generation)})
+		report.CatalogRecovered = true
+	}
+	if trailer.Get("Info") == nil && report.Trailers == 0 && info != 0 {
+		trailer.Set("Info", &PdfObjectReference{ObjectNumber: int64(info),
+			GenerationNumber: int64(parser.xrefs[info].generation)})
+		report.InfoRecovered = true
+	}
+	parser.trailer = trailer
+
+	// The objects are parsed again when looked up, decrypted if needed.
+	parser.ObjCache = ObjectCache{}
+	common.Log.Debug("Document repaired: %s", report)
+	return report, nil
+}
+
+// repairCatalog returns the catalog dictionary referenced by the trailer, or an error if it is not valid.
+func (parser *PdfParser) repairCatalog(trailer *PdfObjectDictionary) (*PdfObjectDictionary, error) {
+	if trailer == nil {
+		return nil, errors.New("Missing trailer")
+	}
+	root, ok := trailer.Get("Root").(*PdfObjectReference)
+	if !ok {
+		return nil, errors.New("Missing Root")
+	}
+	obj, _, err := parser.lookupByNumber(int(root.ObjectNumber), false)
+	if err != nil {
+		return nil, err
+	}
+	ind, ok := obj.(*PdfIndirectObject)
+	if !ok {
+		return nil, errors.New("Invalid catalog")
+	}
+	dict, ok := ind.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return nil, errors.New("Invalid catalog")
+	}
+	if typ, ok := dict.Get("Type").(*PdfObjectName); !ok || *typ != "Catalog" {
+		return nil, errors.New("Invalid catalog type")
+	}
+	if _, ok := dict.Get("Pages").(*PdfObjectReference); !ok {
+		return nil, errors.New("Catalog without Pages")
+	}
+	return dict, nil
+}
+
+// isRepairInfoDict returns true if the dictionary without Type looks like a document information dictionary.
+func isRepairInfoDict(dict *PdfObjectDictionary) bool {
+	if dict.Get("Parent") != nil || dict.Get("Kids") != nil {
+		return false
+	}
+	for _, key := range []PdfObjectName{"Producer", "Creator", "CreationDate", "ModDate"} {
+		if dict.Get(key) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// makeRepairTestFile returns a document with an object stream redefining the page and holding the document
+// information, an invalid object and the tail (cross references and trailer) given.
+func makeRepairTestFile(tail string) []byte {
+	objs := "<</Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Rotate 90>> <</Producer (test)>>"
+	header := "3 0 5 65 "
+	content := header + objs
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	buf.WriteString("1 0 obj\n<</Type /Catalog /Pages 2 0 R>>\nendobj\n")
+	buf.WriteString("2 0 obj\n<</Type /Pages /Kids [3 0 R] /Count 1>>\nendobj\n")
+	buf.WriteString("3 0 obj\n<</Type /Page /Parent 2 0 R /MediaBox [0 0 612 792]>>\nendobj\n")
+	fmt.Fprintf(&buf, "4 0 obj\n<</Type /ObjStm /N 2 /First %d /Length %d>>\nstream\n%s\nendstream\nendobj\n",
+		len(header), len(content), content)
+	buf.WriteString("6 0 obj\n<</Type /Page /Parent\nendobj\n")
+	buf.WriteString(tail)
+	return buf.Bytes()
+}
+
+func TestRepair(t *testing.T) {
+	// Broken cross references, the trailer referencing a missing catalog.
+	data := makeRepairTestFile("xref\n0 1\nbroken\ntrailer\n<</Size 7 /Root 9 0 R>>\nstartxref\n9999\n%%EOF\n")
+	parser, report, err := NewParserWithRepair(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if report == nil {
+		t.Fatalf("Document not repaired")
+	}
+	expected := RepairReport{Objects: 5, CompressedObjects: 2, InvalidObjects: 1, Trailers: 1, CatalogRecovered: true}
+	if *report != expected {
+		t.Errorf("Wrong report %+v", *report)
+	}
+	if root, ok := parser.GetTrailer().Get("Root").(*PdfObjectReference); !ok || root.ObjectNumber != 1 {
+		t.Errorf("Wrong Root %v", parser.GetTrailer().Get("Root"))
+	}
+	if parser.majorVersion != 1 || parser.minorVersion != 5 {
+		t.Errorf("Wrong version %d.%d", parser.majorVersion, parser.minorVersion)
+	}
+
+	// The page of the object stream replaces the earlier one.
+	obj, err := parser.LookupByNumber(3)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if rotate, ok := page.Get("Rotate").(*PdfObjectInteger); !ok || *rotate != 90 {
+		t.Errorf("Wrong page %s", page)
+	}
+	if obj, err := parser.LookupByNumber(6); err != nil {
+		t.Fatalf("Error: %v", err)
+	} else if _, ok := obj.(*PdfObjectNull); !ok {
+		t.Errorf("Invalid object not left out: %v", obj)
+	}
+
+	// No cross references and trailer: the document information is recovered.
+	data = makeRepairTestFile("")
+	parser, report, err = NewParserWithRepair(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected = RepairReport{Objects: 5, CompressedObjects: 2, InvalidObjects: 1, CatalogRecovered: true,
+		InfoRecovered: true}
+	if report == nil || *report != expected {
+		t.Fatalf("Wrong report %+v", report)
+	}
+	if info, ok := parser.GetTrailer().Get("Info").(*PdfObjectReference); !ok || info.ObjectNumber != 5 {
+		t.Errorf("Wrong Info %v", parser.GetTrailer().Get("Info"))
+	}
+}
+
+func TestRepairNotNeeded(t *testing.T) {
+	data := []byte("%PDF-1.4\n1 0 obj\n<</Type /Catalog /Pages 2 0 R>>\nendobj\n" +
+		"2 0 obj\n<</Type /Pages /Kids [] /Count 0>>\nendobj\n")
+	xref := len(data)
+	data = append(data, fmt.Sprintf("xref\n0 3\n0000000000 65535 f\r\n0000000009 00000 n\r\n0000000056 00000 n\r\n"+
+		"trailer\n<</Size 3 /Root 1 0 R>>\nstartxref\n%d\n%%%%EOF\n", xref)...)
+	_, report, err := NewParserWithRepair(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if report != nil {
+		t.Errorf("Document repaired: %s", report)
+	}
+}
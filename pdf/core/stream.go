@@ -6,77 +6,138 @@
 package core
 
 import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
 	"fmt"
+	"io"
+	"io/ioutil"
+
+	lzw1 "golang.org/x/image/tiff/lzw"
 
 	"github.com/unidoc/unidoc/common"
 )
 
-// NewEncoderFromStream creates a StreamEncoder based on the stream's dictionary.
+// NewEncoderFromStream creates a StreamEncoder based on the stream's dictionary: the encoder of its filter, or a
+// MultiEncoder applying its filters in order if it has several.
 func NewEncoderFromStream(streamObj *PdfObjectStream) (StreamEncoder, error) {
-	filterObj := TraceToDirectObject(streamObj.PdfObjectDictionary.Get("Filter"))
-	if filterObj == nil {
+	filters, decodeParams, err := getStreamFilters(streamObj.PdfObjectDictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(filters) {
+	case 0:
 		// No filter, return raw data back.
 		return NewRawEncoder(), nil
+	case 1:
+		return newEncoderFromFilter(streamObj, filters[0], decodeParams[0], nil)
 	}
 
-	if _, isNull := filterObj.(*PdfObjectNull); isNull {
-		// Filter is null -> raw data.
-		return NewRawEncoder(), nil
+	menc, err := newMultiEncoderFromStream(streamObj)
+	if err != nil {
+		common.Log.Error("Failed creating multi encoder: %v", err)
+		return nil, err
+	}
+
+	common.Log.Trace("Multi enc: %s\n", menc)
+	return menc, nil
+}
+
+// getStreamFilters returns the filters of a stream dictionary in the order they are applied to decode the data, with
+// the decode parameters of each filter (an empty dictionary for the filters without parameters).
+func getStreamFilters(dict *PdfObjectDictionary) ([]PdfObjectName, []*PdfObjectDictionary, error) {
+	if dict == nil {
+		return nil, nil, nil
 	}
 
 	// The filter should be a name or an array with a list of filter names.
-	method, ok := filterObj.(*PdfObjectName)
-	if !ok {
-		array, ok := filterObj.(*PdfObjectArray)
-		if !ok {
-			return nil, fmt.Errorf("Filter not a Name or Array object")
-		}
-		if len(*array) == 0 {
-			// Empty array -> indicates raw filter (no filter).
-			return NewRawEncoder(), nil
+	var filters []PdfObjectName
+	switch t := TraceToDirectObject(dict.Get("Filter")).(type) {
+	case nil, *PdfObjectNull:
+		// No filter.
+		return nil, nil, nil
+	case *PdfObjectName:
+		filters = append(filters, *t)
+	case *PdfObjectArray:
+		for _, obj := range *t {
+			name, ok := TraceToDirectObject(obj).(*PdfObjectName)
+			if !ok {
+				common.Log.Debug("ERROR: Filter array member not a name (%T)", obj)
+				return nil, nil, fmt.Errorf("Filter array member not a Name object")
+			}
+			filters = append(filters, *name)
 		}
+	default:
+		return nil, nil, fmt.Errorf("Filter not a Name or Array object")
+	}
 
-		if len(*array) != 1 {
-			menc, err := newMultiEncoderFromStream(streamObj)
-			if err != nil {
-				common.Log.Error("Failed creating multi encoder: %v", err)
-				return nil, err
+	// The decode parameters: a dictionary for a single filter, otherwise an array with an entry for each filter,
+	// null for the filters using the default parameters.
+	decodeParams := make([]*PdfObjectDictionary, len(filters))
+	switch t := TraceToDirectObject(dict.Get("DecodeParms")).(type) {
+	case nil, *PdfObjectNull:
+	case *PdfObjectDictionary:
+		if len(filters) > 1 {
+			common.Log.Debug("DecodeParms dictionary for %d filters: used for each filter", len(filters))
+		}
+		for i := range decodeParams {
+			decodeParams[i] = t
+		}
+	case *PdfObjectArray:
+		if len(*t) != len(filters) {
+			common.Log.Debug("DecodeParms array length (%d) != number of filters (%d)", len(*t), len(filters))
+		}
+		for i, obj := range *t {
+			if i >= len(filters) {
+				break
+			}
+			if dp, isDict := TraceToDirectObject(obj).(*PdfObjectDictionary); isDict {
+				decodeParams[i] = dp
 			}
-
-			common.Log.Trace("Multi enc: %s\n", menc)
-			return menc, nil
 		}
-
-		// Single element.
-		filterObj = (*array)[0]
-		method, ok = filterObj.(*PdfObjectName)
-		if !ok {
-			return nil, fmt.Errorf("Filter array member not a Name object")
+	default:
+		common.Log.Debug("ERROR: DecodeParms not a dictionary or an array (%T)", t)
+		return nil, nil, fmt.Errorf("Invalid DecodeParms")
+	}
+	for i := range decodeParams {
+		if decodeParams[i] == nil {
+			decodeParams[i] = MakeDict()
 		}
 	}
 
-	if *method == StreamEncodingFilterNameFlate {
-		return newFlateEncoderFromStream(streamObj, nil)
-	} else if *method == StreamEncodingFilterNameLZW {
-		return newLZWEncoderFromStream(streamObj, nil)
-	} else if *method == StreamEncodingFilterNameDCT {
-		return newDCTEncoderFromStream(streamObj, nil)
-	} else if *method == StreamEncodingFilterNameRunLength {
-		return newRunLengthEncoderFromStream(streamObj, nil)
-	} else if *method == StreamEncodingFilterNameASCIIHex {
+	return filters, decodeParams, nil
+}
+
+// newEncoderFromFilter creates the encoder of a filter of a stream with its decode parameters.  The filters applied
+// before it to decode the stream, if any, are in previous.  The abbreviations of the filter names of inline images
+// are accepted as well.
+func newEncoderFromFilter(streamObj *PdfObjectStream, method PdfObjectName, decodeParams *PdfObjectDictionary,
+	previous *MultiEncoder) (StreamEncoder, error) {
+	switch method {
+	case StreamEncodingFilterNameFlate, "Fl":
+		return newFlateEncoderFromStream(streamObj, decodeParams)
+	case StreamEncodingFilterNameLZW, "LZW":
+		return newLZWEncoderFromStream(streamObj, decodeParams)
+	case StreamEncodingFilterNameDCT, "DCT":
+		return newDCTEncoderFromStream(streamObj, previous)
+	case StreamEncodingFilterNameRunLength, "RL":
+		return newRunLengthEncoderFromStream(streamObj, decodeParams)
+	case StreamEncodingFilterNameASCIIHex, "AHx":
 		return NewASCIIHexEncoder(), nil
-	} else if *method == StreamEncodingFilterNameASCII85 || *method == "A85" {
+	case StreamEncodingFilterNameASCII85, "A85":
 		return NewASCII85Encoder(), nil
-	} else if *method == StreamEncodingFilterNameCCITTFax {
-		return NewCCITTFaxEncoder(), nil
-	} else if *method == StreamEncodingFilterNameJBIG2 {
-		return NewJBIG2Encoder(), nil
-	} else if *method == StreamEncodingFilterNameJPX {
+	case StreamEncodingFilterNameCCITTFax, "CCF":
+		return newCCITTFaxEncoderFromStream(streamObj, decodeParams)
+	case StreamEncodingFilterNameJBIG2:
+		return newJBIG2EncoderFromStream(streamObj, decodeParams)
+	case StreamEncodingFilterNameJPX:
 		return NewJPXEncoder(), nil
-	} else {
-		common.Log.Debug("ERROR: Unsupported encoding method!")
-		return nil, fmt.Errorf("Unsupported encoding method (%s)", *method)
+	case StreamEncodingFilterNameCrypt:
+		return newCryptEncoderFromStream(streamObj, decodeParams)
 	}
+	common.Log.Debug("ERROR: Unsupported encoding method!")
+	return nil, fmt.Errorf("Unsupported encoding method (%s)", method)
 }
 
 // DecodeStream decodes the stream data and returns the decoded data.
@@ -91,6 +152,16 @@ func DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
 	}
 	common.Log.Trace("Encoder: %#v\n", encoder)
 
+	if !streamObj.IsLoaded() {
+		// Lazy stream: decoded as read from the file, without loading it.
+		r, err := newDecodeReader(encoder, streamObj)
+		if err != nil {
+			common.Log.Debug("Stream decoding failed: %v", err)
+			return nil, err
+		}
+		return ioutil.ReadAll(r)
+	}
+
 	decoded, err := encoder.DecodeStream(streamObj)
 	if err != nil {
 		common.Log.Debug("Stream decoding failed: %v", err)
@@ -100,21 +171,95 @@ func DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
 	return decoded, nil
 }
 
+// NewDecodeReader returns a reader of the decoded data of the stream, decoding the data as it is read.  The data of
+// lazy streams is read from the file (see PdfParser.SetLazyStreams).  The Flate and LZW filters (with predictors)
+// decode the data as it is read, the other filters decode all the data they read in memory.
+func NewDecodeReader(streamObj *PdfObjectStream) (io.Reader, error) {
+	encoder, err := NewEncoderFromStream(streamObj)
+	if err != nil {
+		common.Log.Debug("Stream decoding failed: %v", err)
+		return nil, err
+	}
+	return newDecodeReader(encoder, streamObj)
+}
+
+// newDecodeReader returns a reader of the data of the stream decoded with the encoder.
+func newDecodeReader(encoder StreamEncoder, streamObj *PdfObjectStream) (io.Reader, error) {
+	r, err := streamObj.RawReader()
+	if err != nil {
+		return nil, err
+	}
+
+	encoders := []StreamEncoder{encoder}
+	if menc, is := encoder.(*MultiEncoder); is {
+		encoders = menc.encoders
+	}
+	for _, enc := range encoders {
+		switch t := enc.(type) {
+		case *RawEncoder, *CryptEncoder:
+			// Passed unchanged.
+		case *FlateEncoder:
+			zr, err := zlib.NewReader(r)
+			if err != nil {
+				common.Log.Debug("Decoding error %v", err)
+				return nil, err
+			}
+			r, err = newPredictorReader(zr, t.predictorParams())
+			if err != nil {
+				return nil, err
+			}
+		case *LZWEncoder:
+			var lr io.Reader
+			if t.EarlyChange == 1 {
+				lr = lzw1.NewReader(r, lzw1.MSB, 8)
+			} else {
+				lr = lzw.NewReader(r, lzw.MSB, 8)
+			}
+			r, err = newPredictorReader(lr, t.predictorParams())
+			if err != nil {
+				return nil, err
+			}
+		default:
+			// Decoded in memory.
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := enc.DecodeBytes(data)
+			if err != nil {
+				return nil, err
+			}
+			r = bytes.NewReader(decoded)
+		}
+	}
+	return r, nil
+}
+
 // EncodeStream encodes the stream data using the encoded specified by the stream's dictionary.
 func EncodeStream(streamObj *PdfObjectStream) error {
 	common.Log.Trace("Encode stream")
 
+	if err := streamObj.Load(); err != nil {
+		return err
+	}
+
 	encoder, err := NewEncoderFromStream(streamObj)
 	if err != nil {
 		common.Log.Debug("Stream decoding failed: %v", err)
 		return err
 	}
 
-	if lzwenc, is := encoder.(*LZWEncoder); is {
-		// If LZW:
-		// Make sure to use EarlyChange 0.. We do not have write support for 1 yet.
-		lzwenc.EarlyChange = 0
-		streamObj.PdfObjectDictionary.Set("EarlyChange", MakeInteger(0))
+	encoders := []StreamEncoder{encoder}
+	if menc, is := encoder.(*MultiEncoder); is {
+		encoders = menc.encoders
+	}
+	for _, enc := range encoders {
+		if lzwenc, is := enc.(*LZWEncoder); is {
+			// If LZW:
+			// Make sure to use EarlyChange 0.. We do not have write support for 1 yet.
+			lzwenc.EarlyChange = 0
+			streamObj.PdfObjectDictionary.Set("EarlyChange", MakeInteger(0))
+		}
 	}
 
 	common.Log.Trace("Encoder: %+v\n", encoder)
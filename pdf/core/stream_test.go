@@ -175,3 +175,182 @@ endobj`
 	}
 
 }
+
+// Test a stream with a filter pipeline starting with a Crypt filter, with decode parameters for each filter.
+func TestCryptFilterPipeline(t *testing.T) {
+	flate := NewFlateEncoder()
+	flate.Predictor = 12
+	flate.Columns = 4
+	data := []byte("\x01\x02\x03\x04\x02\x03\x04\x05\x03\x04\x05\x06")
+	compressed, err := flate.EncodeBytes(data)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	encoded, err := NewASCIIHexEncoder().EncodeBytes(compressed)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	rawText := `99 0 obj
+<<
+/DecodeParms [<< /Type /CryptFilterDecodeParms /Name /Identity >> null << /Predictor 12 /Columns 4 >>]
+/Filter [/Crypt 100 0 R /FlateDecode]
+/Length ` + fmt.Sprintf("%d", len(encoded)) + `
+>>
+stream
+` + string(encoded) + `
+endstream
+endobj`
+
+	parser := PdfParser{}
+	parser.rs, parser.reader, parser.fileSize = makeReaderForText(rawText)
+	obj, err := parser.ParseIndirectObject()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	stream, ok := obj.(*PdfObjectStream)
+	if !ok {
+		t.Fatalf("Not a stream (%T)", obj)
+	}
+	// The filter name as an indirect object.
+	filters := stream.Get("Filter").(*PdfObjectArray)
+	(*filters)[1] = &PdfIndirectObject{PdfObject: MakeName(StreamEncodingFilterNameASCIIHex)}
+
+	encoder, err := NewEncoderFromStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if encoder.GetFilterName() != "Crypt ASCIIHexDecode FlateDecode" {
+		t.Errorf("Wrong filters %s", encoder.GetFilterName())
+	}
+	decoded, err := DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Wrong data % x", decoded)
+	}
+
+	crypt := PdfCrypt{V: 4, StreamFilter: "StdCF", CryptFilters: CryptFilters{"Identity": CryptFilter{}, "StdCF": CryptFilter{}}}
+	if filter := crypt.getStreamFilter(stream.PdfObjectDictionary); filter != "Identity" {
+		t.Errorf("Wrong crypt filter %s", filter)
+	}
+	stream.Set("Filter", MakeName(StreamEncodingFilterNameASCIIHex))
+	if filter := crypt.getStreamFilter(stream.PdfObjectDictionary); filter != "StdCF" {
+		t.Errorf("Wrong crypt filter %s", filter)
+	}
+}
+
+// Test the stream dictionary of a multi encoder decodes its data.
+func TestMultiEncoderStreamDict(t *testing.T) {
+	crypt := NewCryptEncoder()
+	crypt.Name = "StdCF"
+	lzw := NewLZWEncoder()
+	lzw.EarlyChange = 0
+	lzw.Predictor = 2
+	lzw.Colors = 3
+	lzw.Columns = 2
+
+	encoder := NewMultiEncoder()
+	encoder.AddEncoder(crypt)
+	encoder.AddEncoder(NewASCII85Encoder())
+	encoder.AddEncoder(lzw)
+
+	data := []byte("\x10\x20\x30\x11\x21\x31\x12\x22\x32\x13\x23\x33")
+	encoded, err := encoder.EncodeBytes(data)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	stream := &PdfObjectStream{PdfObjectDictionary: encoder.MakeStreamDict(), Stream: encoded}
+	if filters, ok := stream.Get("Filter").(*PdfObjectArray); !ok || len(*filters) != 3 {
+		t.Fatalf("Wrong filters %v", stream.Get("Filter"))
+	}
+	decodeParams, ok := stream.Get("DecodeParms").(*PdfObjectArray)
+	if !ok || len(*decodeParams) != 3 {
+		t.Fatalf("Wrong decode parameters %v", stream.Get("DecodeParms"))
+	}
+	if _, isNull := (*decodeParams)[1].(*PdfObjectNull); !isNull {
+		t.Errorf("Decode parameters of ASCII85Decode not null: %v", (*decodeParams)[1])
+	}
+
+	decoder, err := NewEncoderFromStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if menc, ok := decoder.(*MultiEncoder); !ok || menc.encoders[0].(*CryptEncoder).Name != "StdCF" {
+		t.Errorf("Wrong encoder %#v", decoder)
+	}
+	decoded, err := DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Wrong data % x", decoded)
+	}
+}
+
+// Test a lazy stream is read from the file when accessed.
+func TestLazyStream(t *testing.T) {
+	data := bytes.Repeat([]byte("\x10\x20\x30\x40"), 500)
+	flate := NewFlateEncoder()
+	flate.Predictor = 11
+	flate.Columns = 4
+	encoded, err := flate.EncodeBytes(data)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	rawText := `1 0 obj
+<< /Filter /FlateDecode /DecodeParms << /Predictor 11 /Columns 4 >> /Length ` + fmt.Sprintf("%d", len(encoded)) + ` >>
+stream
+` + string(encoded) + `
+endstream
+endobj
+2 0 obj
+<< /Length 5 >>
+stream
+small
+endstream
+endobj`
+
+	parser := NewParserFromString(rawText)
+	if err := parser.SetLazyStreams(10); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	obj, err := parser.ParseIndirectObject()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	stream := obj.(*PdfObjectStream)
+	if stream.IsLoaded() || stream.Stream != nil {
+		t.Fatalf("Lazy stream loaded")
+	}
+
+	// The next object is parsed after the data, small streams are loaded.
+	obj, err = parser.ParseIndirectObject()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if small := obj.(*PdfObjectStream); !small.IsLoaded() || string(small.Stream) != "small" {
+		t.Errorf("Wrong small stream %q", small.Stream)
+	}
+
+	decoded, err := DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Wrong decoded data")
+	}
+	if stream.IsLoaded() {
+		t.Errorf("Lazy stream loaded by decoding")
+	}
+
+	if err := stream.Load(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !stream.IsLoaded() || !bytes.Equal(stream.Stream, encoded) {
+		t.Errorf("Wrong loaded data")
+	}
+}
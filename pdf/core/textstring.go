@@ -0,0 +1,126 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The characters of PDFDocEncoding (Annex D.2) whose codes differ from Latin-1.  The codes 0x7F, 0x9F and 0xAD are
+// undefined.
+var pdfDocEncodingDiffs = map[byte]rune{
+	0x18: '˘', 0x19: 'ˇ', 0x1a: 'ˆ', 0x1b: '˙', 0x1c: '˝', 0x1d: '˛', 0x1e: '˚',
+	0x1f: '˜', 0x80: '•', 0x81: '†', 0x82: '‡', 0x83: '…', 0x84: '—', 0x85: '–',
+	0x86: 'ƒ', 0x87: '⁄', 0x88: '‹', 0x89: '›', 0x8a: '−', 0x8b: '‰', 0x8c: '„',
+	0x8d: '“', 0x8e: '”', 0x8f: '‘', 0x90: '’', 0x91: '‚', 0x92: '™', 0x93: 'ﬁ',
+	0x94: 'ﬂ', 0x95: 'Ł', 0x96: 'Œ', 0x97: 'Š', 0x98: 'Ÿ', 0x99: 'Ž', 0x9a: 'ı',
+	0x9b: 'ł', 0x9c: 'œ', 0x9d: 'š', 0x9e: 'ž', 0xa0: '€',
+}
+
+// The PDFDocEncoding codes of the characters of pdfDocEncodingDiffs.
+var pdfDocEncodingCodes = map[rune]byte{}
+
+func init() {
+	for code, r := range pdfDocEncodingDiffs {
+		pdfDocEncodingCodes[r] = code
+	}
+}
+
+// DecodeTextString decodes the bytes of a text string (7.9.2.2 Text String Type) to UTF-8: in UTF-16BE if starting
+// with its byte order mark, in UTF-8 if starting with its byte order mark (PDF 2.0), and in PDFDocEncoding otherwise.
+// UTF-16LE with a byte order mark, written by some producers, is also decoded.
+func DecodeTextString(s string) string {
+	switch {
+	case len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff:
+		return decodeUTF16(s[2:], true)
+	case len(s) >= 2 && s[0] == 0xff && s[1] == 0xfe:
+		return decodeUTF16(s[2:], false)
+	case len(s) >= 3 && s[0] == 0xef && s[1] == 0xbb && s[2] == 0xbf && utf8.ValidString(s[3:]):
+		return s[3:]
+	}
+
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		if r, has := pdfDocEncodingDiffs[s[i]]; has {
+			runes[i] = r
+		} else {
+			// Latin-1, also for the undefined codes.
+			runes[i] = rune(s[i])
+		}
+	}
+	return string(runes)
+}
+
+// decodeUTF16 decodes UTF-16 text, big or little endian, ignoring a trailing odd byte.
+func decodeUTF16(s string, bigEndian bool) string {
+	codes := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		if bigEndian {
+			codes = append(codes, uint16(s[i])<<8|uint16(s[i+1]))
+		} else {
+			codes = append(codes, uint16(s[i+1])<<8|uint16(s[i]))
+		}
+	}
+	return string(utf16.Decode(codes))
+}
+
+// EncodeTextString encodes the UTF-8 text as the bytes of a text string: in PDFDocEncoding if all its characters
+// have a code in it, otherwise in UTF-16BE with a byte order mark.
+func EncodeTextString(text string) string {
+	b := make([]byte, 0, len(text))
+	for _, r := range text {
+		code, ok := pdfDocEncodingCode(r)
+		if !ok {
+			return encodeUTF16(text)
+		}
+		b = append(b, code)
+	}
+	return string(b)
+}
+
+// pdfDocEncodingCode returns the PDFDocEncoding code of the character, and whether it has one.
+func pdfDocEncodingCode(r rune) (byte, bool) {
+	if code, has := pdfDocEncodingCodes[r]; has {
+		return code, true
+	}
+	if r >= 0x100 || (r >= 0x18 && r < 0x20) || (r >= 0x7f && r <= 0xa0) || r == 0xad {
+		return 0, false
+	}
+	return byte(r), true
+}
+
+// encodeUTF16 encodes the text in UTF-16BE with a byte order mark.
+func encodeUTF16(text string) string {
+	b := []byte{0xfe, 0xff}
+	for _, code := range utf16.Encode([]rune(text)) {
+		b = append(b, byte(code>>8), byte(code))
+	}
+	return string(b)
+}
+
+// MakeTextString creates a PdfObjectString of the UTF-8 text, encoded as a text string (EncodeTextString).
+func MakeTextString(text string) *PdfObjectString {
+	str := PdfObjectString(EncodeTextString(text))
+	return &str
+}
+
+// Decoded returns the text of the string decoded as a text string (DecodeTextString), e.g. for the values of the
+// document information dictionary, outline item titles and form field values.  The bytes of the string are returned
+// by String.
+func (str *PdfObjectString) Decoded() string {
+	return DecodeTextString(string(*str))
+}
+
+// GetTextString returns the decoded text of a string object, or of the string an indirect object contains, and
+// whether the object is a string.
+func GetTextString(obj PdfObject) (string, bool) {
+	str, ok := TraceToDirectObject(obj).(*PdfObjectString)
+	if !ok {
+		return "", false
+	}
+	return str.Decoded(), true
+}
@@ -0,0 +1,54 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestTextString(t *testing.T) {
+	for _, tc := range []struct {
+		encoded string
+		text    string
+	}{
+		{"Report", "Report"},
+		// PDFDocEncoding: Latin-1 with typographic characters.
+		{"Caf\xe9 \x93 \x84 \xa0 \x92", "Café ﬁ — € ™"},
+		{"\xfe\xff\x04\x1f\x04\x40\x04\x38", "При"},
+		{"\xfe\xff\xd8\x3d\xde\x00", "😀"},
+	} {
+		if text := DecodeTextString(tc.encoded); text != tc.text {
+			t.Errorf("Decoded %q as %q, expected %q", tc.encoded, text, tc.text)
+		}
+		if encoded := EncodeTextString(tc.text); encoded != tc.encoded {
+			t.Errorf("Encoded %q as %q, expected %q", tc.text, encoded, tc.encoded)
+		}
+	}
+
+	// Byte order marks of UTF-16LE and UTF-8 are decoded too.
+	if text := DecodeTextString("\xff\xfe\x1f\x04\x40\x04"); text != "Пр" {
+		t.Errorf("Wrong UTF-16LE text %q", text)
+	}
+	if text := DecodeTextString("\xef\xbb\xbfПр"); text != "Пр" {
+		t.Errorf("Wrong UTF-8 text %q", text)
+	}
+
+	// Control characters with PDFDocEncoding codes of other characters are encoded in UTF-16.
+	if encoded := EncodeTextString("a\x1fb"); encoded != "\xfe\xff\x00a\x00\x1f\x00b" {
+		t.Errorf("Wrong encoding %q", encoded)
+	}
+
+	str := MakeTextString("Café ™")
+	if string(*str) != "Caf\xe9 \x92" || str.Decoded() != "Café ™" {
+		t.Errorf("Wrong text string %q", string(*str))
+	}
+	if text, ok := GetTextString(MakeIndirectObject(str)); !ok || text != "Café ™" {
+		t.Errorf("Wrong text of indirect string %q", text)
+	}
+	if _, ok := GetTextString(MakeName("Name")); ok {
+		t.Errorf("Name taken for a string")
+	}
+}
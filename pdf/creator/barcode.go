@@ -0,0 +1,272 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+
+	"github.com/unidoc/unidoc/pdf/contentstream"
+)
+
+// Default sizes of barcodes (points), for printing on labels.
+const (
+	barcodeDefaultModuleWidth = 1.0
+	barcodeDefaultBarHeight   = 50.0
+)
+
+// Barcode represents a 1D barcode (Code 128, EAN-13) or a 2D barcode (QR code), drawn as vector rectangles so that
+// it prints sharp at any resolution.  The barcode is surrounded by a quiet zone, the blank margin needed by
+// scanners: on the left and right of 1D barcodes, and on all sides of 2D barcodes.
+// Implements the Drawable interface.
+type Barcode struct {
+	code barcode.Barcode
+
+	// Number of modules (narrowest bars or squares) across and down the code, without the quiet zone.
+	cols, rows int
+	twoD       bool
+
+	// Width of a module, height of the bars of 1D codes (points), and width of the quiet zone (modules).
+	moduleWidth float64
+	barHeight   float64
+	quietZone   int
+
+	color      Color
+	background Color
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos float64
+	yPos float64
+
+	// Margins to be applied around the block when drawing on Page.
+	margins margins
+}
+
+// NewBarcode creates a barcode drawable from a code encoded with the github.com/boombuler/barcode packages, e.g.
+// for the other symbologies, with a quiet zone of 10 modules for 1D codes and 4 modules for 2D codes.
+func NewBarcode(code barcode.Barcode) *Barcode {
+	bounds := code.Bounds()
+
+	b := &Barcode{}
+	b.code = code
+	b.cols = bounds.Dx()
+	b.rows = bounds.Dy()
+	b.twoD = code.Metadata().Dimensions == 2
+	b.moduleWidth = barcodeDefaultModuleWidth
+	b.barHeight = barcodeDefaultBarHeight
+	if b.twoD {
+		b.quietZone = 4
+	} else {
+		b.quietZone = 10
+	}
+	b.color = ColorBlack
+	b.positioning = positionRelative
+	return b
+}
+
+// NewQRCode creates a QR code of the text, with the error correction level (qr.L, qr.M, qr.Q or qr.H, restoring
+// about 7, 15, 25 and 30% of a damaged code).  The most compact encoding of the text is used.
+func NewQRCode(text string, level qr.ErrorCorrectionLevel) (*Barcode, error) {
+	code, err := qr.Encode(text, level, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+	return NewBarcode(code), nil
+}
+
+// NewCode128 creates a Code 128 barcode of the text (ASCII, up to 80 characters), e.g. for shipping labels.
+func NewCode128(text string) (*Barcode, error) {
+	code, err := code128.Encode(text)
+	if err != nil {
+		return nil, err
+	}
+	return NewBarcode(code), nil
+}
+
+var ean13Digits = regexp.MustCompile(`^[0-9]{12,13}$`)
+
+// NewEAN13 creates an EAN-13 barcode of a product number: 12 digits, to which the check digit is added, or 13
+// digits with the check digit verified.  The quiet zone is 11 modules.
+func NewEAN13(number string) (*Barcode, error) {
+	if !ean13Digits.MatchString(number) {
+		return nil, errors.New("EAN-13 number must have 12 or 13 digits")
+	}
+	code, err := ean.Encode(number)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBarcode(code)
+	b.quietZone = 11
+	return b, nil
+}
+
+// Content returns the data encoded in the barcode, with the check digit for EAN-13.
+func (b *Barcode) Content() string {
+	return b.code.Content()
+}
+
+// SetModuleWidth sets the width of a module (points): the narrowest bar of 1D codes, or the side of the squares of
+// 2D codes.
+func (b *Barcode) SetModuleWidth(w float64) {
+	b.moduleWidth = w
+}
+
+// SetWidth sets the module width so that the barcode, with its quiet zone, is w wide.
+func (b *Barcode) SetWidth(w float64) {
+	b.moduleWidth = w / float64(b.cols+2*b.quietZone)
+}
+
+// SetBarHeight sets the height of the bars of 1D codes.  2D codes are as high as wide.
+func (b *Barcode) SetBarHeight(h float64) {
+	b.barHeight = h
+}
+
+// SetQuietZone sets the width of the quiet zone, in modules.
+func (b *Barcode) SetQuietZone(modules int) {
+	b.quietZone = modules
+}
+
+// SetColor sets the color of the bars or dark modules (black by default).
+func (b *Barcode) SetColor(col Color) {
+	b.color = col
+}
+
+// SetBackgroundColor sets the color the barcode and its quiet zone are filled with, transparent by default.
+func (b *Barcode) SetBackgroundColor(col Color) {
+	b.background = col
+}
+
+// Width returns the width of the barcode, with its quiet zone.
+func (b *Barcode) Width() float64 {
+	return float64(b.cols+2*b.quietZone) * b.moduleWidth
+}
+
+// Height returns the height of the barcode, with its quiet zone for 2D codes.
+func (b *Barcode) Height() float64 {
+	if b.twoD {
+		return float64(b.rows+2*b.quietZone) * b.moduleWidth
+	}
+	return b.barHeight
+}
+
+// SetMargins sets the margins for the barcode (in relative mode): left, right, top, bottom.
+func (b *Barcode) SetMargins(left, right, top, bottom float64) {
+	b.margins.left = left
+	b.margins.right = right
+	b.margins.top = top
+	b.margins.bottom = bottom
+}
+
+// GetMargins returns the barcode's margins: left, right, top, bottom.
+func (b *Barcode) GetMargins() (float64, float64, float64, float64) {
+	return b.margins.left, b.margins.right, b.margins.top, b.margins.bottom
+}
+
+// SetPos sets the absolute position of the upper left corner of the quiet zone. Changes object positioning to
+// absolute.
+func (b *Barcode) SetPos(x, y float64) {
+	b.positioning = positionAbsolute
+	b.xPos = x
+	b.yPos = y
+}
+
+// isDark returns whether the module at (x, y) of the code is dark.
+func (b *Barcode) isDark(x, y int) bool {
+	bounds := b.code.Bounds()
+	r, g, bl, _ := b.code.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+	return r+g+bl < 3*0x8000
+}
+
+// draw adds the operations drawing the barcode with its upper left corner at (x, y) in PDF coordinates.
+func (b *Barcode) draw(cc *contentstream.ContentCreator, x, y float64) {
+	w := b.moduleWidth
+	cc.Add_q()
+	if b.background != nil {
+		cc.Add_rg(b.background.ToRGB()).
+			Add_re(x, y-b.Height(), b.Width(), b.Height()).
+			Add_f()
+	}
+
+	// The runs of dark modules of each row as a rectangle, rows of 1D codes spanning the bar height.
+	cc.Add_rg(b.color.ToRGB())
+	x += float64(b.quietZone) * w
+	rowHeight := w
+	if b.twoD {
+		y -= float64(b.quietZone) * w
+	} else {
+		rowHeight = b.barHeight / float64(b.rows)
+	}
+	for row := 0; row < b.rows; row++ {
+		rowY := y - float64(row+1)*rowHeight
+		for col := 0; col < b.cols; {
+			if !b.isDark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < b.cols && b.isDark(col, row) {
+				col++
+			}
+			cc.Add_re(x+float64(start)*w, rowY, float64(col-start)*w, rowHeight)
+		}
+	}
+	cc.Add_f().Add_Q()
+}
+
+// GeneratePageBlocks generates the page blocks.  Draws the barcode on a block, on a new page if it does not fit in
+// the remaining height of the page in relative mode.  Implements the Drawable interface.
+func (b *Barcode) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	blocks := []*Block{}
+	origCtx := ctx
+
+	blk := NewBlock(ctx.PageWidth, ctx.PageHeight)
+	if b.positioning.isRelative() {
+		if b.Height() > ctx.Height {
+			// Goes out of the bounds.  Write on a new template instead and create a new context at upper
+			// left corner.
+			blocks = append(blocks, blk)
+			blk = NewBlock(ctx.PageWidth, ctx.PageHeight)
+
+			ctx.Page++
+			newContext := ctx
+			newContext.Y = ctx.Margins.top
+			newContext.X = ctx.Margins.left + b.margins.left
+			newContext.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom - b.margins.bottom
+			newContext.Width = ctx.PageWidth - ctx.Margins.left - ctx.Margins.right - b.margins.left - b.margins.right
+			ctx = newContext
+		} else {
+			ctx.Y += b.margins.top
+			ctx.Height -= b.margins.top + b.margins.bottom
+			ctx.X += b.margins.left
+			ctx.Width -= b.margins.left + b.margins.right
+		}
+	} else {
+		// Absolute.
+		ctx.X = b.xPos
+		ctx.Y = b.yPos
+	}
+
+	cc := contentstream.NewContentCreator()
+	b.draw(cc, ctx.X, ctx.PageHeight-ctx.Y)
+	blk.addContents(cc.Operations())
+	blocks = append(blocks, blk)
+
+	if b.positioning.isAbsolute() {
+		// Absolute drawing should not affect context.
+		return blocks, origCtx, nil
+	}
+	ctx.Y += b.Height() + b.margins.bottom
+	ctx.Height -= b.Height() + b.margins.bottom
+	return blocks, ctx, nil
+}
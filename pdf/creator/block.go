@@ -34,11 +34,26 @@ type Block struct {
 	width  float64
 	height float64
 
-	// Rotation angle.
-	angle float64
+	// Transformation about the upper left corner, nil if none.
+	transform *contentstream.Matrix
 
 	// Margins to be applied around the block when drawing on Page.
 	margins margins
+
+	// Baselines of the text lines drawn on the block, measured from the top of the block.
+	// Used for numbering lines in the page margin.
+	lines []float64
+
+	// Link areas on the block, added as link annotations to the page the block is drawn on.
+	links []blockLink
+}
+
+// blockLink is a link area of a block, with the rectangle in the coordinates of the block (from its lower left
+// corner) and the target of the link, or the area of a form field widget, added to the form by the field function.
+type blockLink struct {
+	rect model.PdfRectangle
+	linkTarget
+	field addFieldFunc
 }
 
 // NewBlock creates a new Block with specified width and height.
@@ -88,12 +103,56 @@ func NewBlockFromPage(page *model.PdfPage) (*Block, error) {
 	b.width = mbox.Urx - mbox.Llx
 	b.height = mbox.Ury - mbox.Lly
 
+	if unit := page.GetUserUnit(); unit != 1 {
+		// Blocks are in points: scale up the larger units of oversized pages.
+		b.Scale(unit, unit)
+	}
+
 	return b, nil
 }
 
-// SetAngle sets the rotation angle in degrees.
+// SetAngle sets the rotation angle in degrees (counter-clockwise), about the upper left corner of the block.
+// Replaces any other transformation of the block.
 func (blk *Block) SetAngle(angleDeg float64) {
-	blk.angle = angleDeg
+	blk.transform = nil
+	if angleDeg != 0 {
+		blk.Rotate(angleDeg)
+	}
+}
+
+// Rotate rotates the block by angleDeg degrees (counter-clockwise) about its upper left corner.
+func (blk *Block) Rotate(angleDeg float64) {
+	blk.transform = addTransform(blk.transform, contentstream.RotationMatrix(angleDeg))
+}
+
+// RotateAbout rotates the block by angleDeg degrees (counter-clockwise) about the point (x, y), from its upper left
+// corner.
+func (blk *Block) RotateAbout(angleDeg, x, y float64) {
+	blk.transform = addTransform(blk.transform, rotationAbout(angleDeg, x, y))
+}
+
+// ScaleAbout scales the block by sx, sy about the point (x, y), from its upper left corner, when drawn.  Unlike Scale,
+// the width and height of the block are unchanged.
+func (blk *Block) ScaleAbout(sx, sy, x, y float64) {
+	blk.transform = addTransform(blk.transform, scaleAbout(sx, sy, x, y))
+}
+
+// Skew skews the block, slanting its vertical lines by angleXDeg and its horizontal lines by angleYDeg degrees
+// (counter-clockwise), about its upper left corner.
+func (blk *Block) Skew(angleXDeg, angleYDeg float64) {
+	blk.transform = addTransform(blk.transform, skewMatrix(angleXDeg, angleYDeg))
+}
+
+// Transform applies the transformation matrix m, in the coordinates of the upper left corner of the block (y up).
+func (blk *Block) Transform(m contentstream.Matrix) {
+	blk.transform = addTransform(blk.transform, m)
+}
+
+// BoundingBox returns the bounding box of the transformed block, from the upper left corner of the block: the offsets
+// of its left and top sides (positive to the right and down), and its width and height.  In relative mode, the
+// bounding box is placed at the current position.
+func (blk *Block) BoundingBox() (left, top, width, height float64) {
+	return transformedBBox(blk.transform, blk.width, blk.height)
 }
 
 // duplicate duplicates the block with a new copy of the operations list.
@@ -109,6 +168,9 @@ func (blk *Block) duplicate() *Block {
 	}
 	dup.contents = &dupContents
 
+	dup.lines = append([]float64{}, blk.lines...)
+	dup.links = append([]blockLink{}, blk.links...)
+
 	return dup
 }
 
@@ -117,47 +179,75 @@ func (blk *Block) duplicate() *Block {
 func (blk *Block) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	blocks := []*Block{}
 
+	left, top, _, height := blk.BoundingBox()
+	x, y := blk.xPos, blk.yPos
 	if blk.positioning.isRelative() {
-		// Draw at current ctx.X, ctx.Y position
-		dup := blk.duplicate()
-		cc := contentstream.NewContentCreator()
-		cc.Translate(ctx.X, ctx.PageHeight-ctx.Y-blk.height)
-		if blk.angle != 0 {
-			// Make the rotation about the upper left corner.
-			// XXX/TODO: Account for rotation origin. (Consider).
-			cc.Translate(0, blk.Height())
-			cc.RotateDeg(blk.angle)
-			cc.Translate(0, -blk.Height())
+		if blk.transform != nil && height > ctx.Height && height <= ctx.PageHeight-ctx.Margins.top-ctx.Margins.bottom {
+			// The transformed block does not fit on the page: draw it on the next page.
+			blocks = append(blocks, NewBlock(ctx.PageWidth, ctx.PageHeight))
+			ctx.Page++
+			ctx.X = ctx.Margins.left
+			ctx.Y = ctx.Margins.top
+			ctx.Width = ctx.PageWidth - ctx.Margins.left - ctx.Margins.right
+			ctx.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom
 		}
-		contents := append(*cc.Operations(), *dup.contents...)
-		contents.WrapIfNeeded()
-		dup.contents = &contents
 
-		blocks = append(blocks, dup)
+		// Draw with the bounding box at the current ctx.X, ctx.Y position.
+		x, y = ctx.X-left, ctx.Y-top
+	}
 
-		ctx.Y += blk.height
-	} else {
-		// Absolute. Draw at blk.xPos, blk.yPos position
-		dup := blk.duplicate()
-		cc := contentstream.NewContentCreator()
-		cc.Translate(blk.xPos, ctx.PageHeight-blk.yPos-blk.height)
-		if blk.angle != 0 {
-			// Make the rotation about the upper left corner.
-			// XXX/TODO: Consider supporting specification of rotation origin.
-			cc.Translate(0, blk.Height())
-			cc.RotateDeg(blk.angle)
-			cc.Translate(0, -blk.Height())
-		}
-		contents := append(*cc.Operations(), *dup.contents...)
-		contents.WrapIfNeeded()
-		dup.contents = &contents
+	dup := blk.duplicate()
+	cc := contentstream.NewContentCreator()
+	cc.Translate(x, ctx.PageHeight-y-blk.height)
+	if blk.transform != nil {
+		// Transform about the upper left corner.
+		m := *blk.transform
+		cc.Translate(0, blk.height)
+		cc.Add_cm(m[0], m[1], m[2], m[3], m[4], m[5])
+		cc.Translate(0, -blk.height)
+	}
+	contents := append(*cc.Operations(), *dup.contents...)
+	contents.WrapIfNeeded()
+	dup.contents = &contents
+	dup.translateLines(y)
+	dup.translateLinks(x, ctx.PageHeight-y-blk.height)
+
+	blocks = append(blocks, dup)
 
-		blocks = append(blocks, dup)
+	if blk.positioning.isRelative() {
+		ctx.Y += height
 	}
 
 	return blocks, ctx, nil
 }
 
+// translateLines moves the recorded text line baselines down by dy. The baselines are dropped if the block
+// is transformed as they no longer map to horizontal lines on the page.
+func (blk *Block) translateLines(dy float64) {
+	if blk.transform != nil {
+		blk.lines = nil
+		return
+	}
+	for i := range blk.lines {
+		blk.lines[i] += dy
+	}
+}
+
+// translateLinks moves the link areas by dx, dy.  The links are dropped if the block is transformed.
+func (blk *Block) translateLinks(dx, dy float64) {
+	if blk.transform != nil {
+		blk.links = nil
+		return
+	}
+	for i := range blk.links {
+		r := &blk.links[i].rect
+		r.Llx += dx
+		r.Urx += dx
+		r.Lly += dy
+		r.Ury += dy
+	}
+}
+
 // Height returns the Block's height.
 func (blk *Block) Height() float64 {
 	return blk.height
@@ -222,6 +312,17 @@ func (blk *Block) Scale(sx, sy float64) {
 
 	blk.width *= sx
 	blk.height *= sy
+
+	for i := range blk.lines {
+		blk.lines[i] *= sy
+	}
+	for i := range blk.links {
+		r := &blk.links[i].rect
+		r.Llx *= sx
+		r.Urx *= sx
+		r.Lly *= sy
+		r.Ury *= sy
+	}
 }
 
 // ScaleToWidth scales the Block to a specified width, maintaining the same aspect ratio.
@@ -244,21 +345,36 @@ func (blk *Block) translate(tx, ty float64) {
 
 	*blk.contents = append(*ops, *blk.contents...)
 	blk.contents.WrapIfNeeded()
+
+	for i := range blk.links {
+		r := &blk.links[i].rect
+		r.Llx += tx
+		r.Urx += tx
+		r.Lly -= ty
+		r.Ury -= ty
+	}
 }
 
 // drawToPage draws the block on a PdfPage. Generates the content streams and appends to the PdfPage's content
-// stream and links needed resources.
-func (blk *Block) drawToPage(page *model.PdfPage) error {
+// stream and links needed resources, and adds the form fields of the block to the form.  Returns the links to pages,
+// whose destinations are set when the pages of the document are final.
+func (blk *Block) drawToPage(page *model.PdfPage, form *model.PdfAcroForm) ([]pageLink, error) {
+	if unit := page.GetUserUnit(); unit != 1 {
+		// Scaled to the larger units of oversized pages.
+		blk = blk.duplicate()
+		blk.Scale(1/unit, 1/unit)
+	}
+
 	// Check if Page contents are wrapped - if not wrap it.
 	content, err := page.GetAllContentStreams()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	contentParser := contentstream.NewContentStreamParser(content)
 	ops, err := contentParser.Parse()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	ops.WrapIfNeeded()
 
@@ -270,15 +386,34 @@ func (blk *Block) drawToPage(page *model.PdfPage) error {
 	// Merge the contents into ops.
 	err = mergeContents(ops, page.Resources, blk.contents, blk.resources)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = page.SetContentStreams([]string{string(ops.Bytes())}, core.NewFlateEncoder())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	pageLinks := []pageLink{}
+	for _, link := range blk.links {
+		if link.field != nil {
+			if err := link.field(form, page, link.rect); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		annot := model.NewPdfAnnotationLink()
+		annot.Rect = link.rect.ToPdfObject()
+		annot.Border = core.MakeArrayFromFloats([]float64{0, 0, 0})
+		if link.action != nil {
+			annot.A = link.action
+		} else {
+			pageLinks = append(pageLinks, pageLink{annot, link.linkTarget})
+		}
+		page.Annotations = append(page.Annotations, annot.PdfAnnotation)
+	}
+
+	return pageLinks, nil
 }
 
 // Draw draws the drawable d on the block.
@@ -306,6 +441,8 @@ func (blk *Block) Draw(d Drawable) error {
 		if err != nil {
 			return err
 		}
+		blk.lines = append(blk.lines, newBlock.lines...)
+		blk.links = append(blk.links, newBlock.links...)
 	}
 
 	return nil
@@ -327,6 +464,8 @@ func (blk *Block) DrawWithContext(d Drawable, ctx DrawContext) error {
 		if err != nil {
 			return err
 		}
+		blk.lines = append(blk.lines, newBlock.lines...)
+		blk.links = append(blk.links, newBlock.links...)
 	}
 
 	return nil
@@ -335,7 +474,12 @@ func (blk *Block) DrawWithContext(d Drawable, ctx DrawContext) error {
 // mergeBlocks appends another block onto the block.
 func (blk *Block) mergeBlocks(toAdd *Block) error {
 	err := mergeContents(blk.contents, blk.resources, toAdd.contents, toAdd.resources)
-	return err
+	if err != nil {
+		return err
+	}
+	blk.lines = append(blk.lines, toAdd.lines...)
+	blk.links = append(blk.links, toAdd.links...)
+	return nil
 }
 
 // mergeContents merges contents and content streams.
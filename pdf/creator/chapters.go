@@ -115,7 +115,7 @@ func (chap *Chapter) Add(d Drawable) error {
 	case *Chapter:
 		common.Log.Debug("Error: Cannot add chapter to a chapter")
 		return errors.New("Type check error")
-	case *Paragraph, *Image, *Block, *Subchapter, *Table, *PageBreak:
+	case *Paragraph, *StyledParagraph, *Image, *Block, *Subchapter, *Table, *List, *PageBreak:
 		chap.contents = append(chap.contents, d)
 	default:
 		common.Log.Debug("Unsupported: %T", d)
@@ -138,17 +138,19 @@ func (chap *Chapter) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 		ctx.Height -= chap.margins.top
 	}
 
+	headingY := ctx.Y
 	blocks, ctx, err := chap.heading.GeneratePageBlocks(ctx)
 	if err != nil {
 		return blocks, ctx, err
 	}
 	if len(blocks) > 1 {
 		ctx.Page++ // Did not fit, moved to new Page block.
+		headingY = ctx.Margins.top
 	}
 
 	if chap.includeInTOC {
 		// Add to TOC.
-		chap.toc.add(chap.title, chap.number, 0, ctx.Page)
+		chap.toc.add(chap.title, chap.number, 0, ctx.Page, headingY)
 	}
 
 	for _, d := range chap.contents {
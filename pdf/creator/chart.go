@@ -0,0 +1,562 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+)
+
+// ChartType defines how the values of a Chart are drawn.
+type ChartType int
+
+const (
+	// ChartBar draws the values of the series as bars, grouped by category.
+	ChartBar ChartType = iota
+
+	// ChartLine draws the series as lines through their values in the categories.
+	ChartLine
+
+	// ChartPie draws the values of the first series as the slices of a pie, one per category.
+	ChartPie
+)
+
+// Default colors of the series (or of the slices of pies).
+var chartDefaultColors = []Color{
+	ColorRGBFromHex("#4e79a7"),
+	ColorRGBFromHex("#f28e2b"),
+	ColorRGBFromHex("#e15759"),
+	ColorRGBFromHex("#76b7b2"),
+	ColorRGBFromHex("#59a14f"),
+	ColorRGBFromHex("#edc948"),
+	ColorRGBFromHex("#b07aa1"),
+	ColorRGBFromHex("#ff9da7"),
+	ColorRGBFromHex("#9c755f"),
+	ColorRGBFromHex("#bab0ac"),
+}
+
+// Spacing between the parts of a chart (points).
+const chartPadding = 4
+
+// ChartSeries is a named series of values of a Chart, one per category.
+type ChartSeries struct {
+	name   string
+	values []float64
+	color  Color
+}
+
+// SetColor sets the color the series is drawn with.
+func (s *ChartSeries) SetColor(col Color) {
+	s.color = col
+}
+
+// Chart is a bar, line or pie chart drawn as vector graphics, with its axes, labels and legend.  The values of the
+// series are drawn by category, along the horizontal axis for bars and lines.
+// Implements the Drawable interface.
+type Chart struct {
+	chartType ChartType
+
+	// The size of the chart, including its title, labels and legend.
+	width, height float64
+
+	title      string
+	categories []string
+	series     []*ChartSeries
+
+	// Colors of the slices of pies.
+	palette []Color
+
+	// The style of the labels and legend, and of the title.
+	textStyle  TextStyle
+	titleStyle TextStyle
+
+	legend bool
+	grid   bool
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos float64
+	yPos float64
+
+	// Margins to be applied around the block when drawing on Page.
+	margins margins
+}
+
+// NewChart creates a new chart of the type and size, with a legend and grid lines.
+func NewChart(chartType ChartType, width, height float64) *Chart {
+	c := &Chart{}
+	c.chartType = chartType
+	c.width = width
+	c.height = height
+	c.palette = chartDefaultColors
+	c.textStyle = NewTextStyle()
+	c.textStyle.FontSize = 8
+	c.titleStyle = NewTextStyle()
+	c.titleStyle.FontSize = 12
+	c.legend = true
+	c.grid = true
+	c.positioning = positionRelative
+	return c
+}
+
+// NewBarChart creates a new bar chart of the size.
+func NewBarChart(width, height float64) *Chart {
+	return NewChart(ChartBar, width, height)
+}
+
+// NewLineChart creates a new line chart of the size.
+func NewLineChart(width, height float64) *Chart {
+	return NewChart(ChartLine, width, height)
+}
+
+// NewPieChart creates a new pie chart of the size.
+func NewPieChart(width, height float64) *Chart {
+	return NewChart(ChartPie, width, height)
+}
+
+// SetTitle sets the title drawn above the chart.
+func (c *Chart) SetTitle(title string) {
+	c.title = title
+}
+
+// SetCategories sets the names of the categories of the values.
+func (c *Chart) SetCategories(categories ...string) {
+	c.categories = categories
+}
+
+// AddSeries adds a series of values, one per category, returned to be customized.  The series are colored from the
+// default palette.
+func (c *Chart) AddSeries(name string, values ...float64) *ChartSeries {
+	s := &ChartSeries{name: name, values: values}
+	s.color = chartDefaultColors[len(c.series)%len(chartDefaultColors)]
+	c.series = append(c.series, s)
+	return s
+}
+
+// SetPalette sets the colors of the slices of pie charts, repeated for more categories.
+func (c *Chart) SetPalette(colors ...Color) {
+	if len(colors) > 0 {
+		c.palette = colors
+	}
+}
+
+// SetTextStyle sets the style of the labels and the legend.
+func (c *Chart) SetTextStyle(style TextStyle) {
+	c.textStyle = style
+}
+
+// SetTitleStyle sets the style of the title.
+func (c *Chart) SetTitleStyle(style TextStyle) {
+	c.titleStyle = style
+}
+
+// SetLegend sets whether the legend is drawn below the chart.
+func (c *Chart) SetLegend(legend bool) {
+	c.legend = legend
+}
+
+// SetGridLines sets whether horizontal grid lines are drawn at the ticks of the value axis.
+func (c *Chart) SetGridLines(grid bool) {
+	c.grid = grid
+}
+
+// SetPos sets the absolute position. Changes object positioning to absolute.
+func (c *Chart) SetPos(x, y float64) {
+	c.positioning = positionAbsolute
+	c.xPos = x
+	c.yPos = y
+}
+
+// SetMargins sets the margins of the Chart (in relative mode): left, right, top, bottom.
+func (c *Chart) SetMargins(left, right, top, bottom float64) {
+	c.margins.left = left
+	c.margins.right = right
+	c.margins.top = top
+	c.margins.bottom = bottom
+}
+
+// GetMargins returns the Chart's margins: left, right, top, bottom.
+func (c *Chart) GetMargins() (float64, float64, float64, float64) {
+	return c.margins.left, c.margins.right, c.margins.top, c.margins.bottom
+}
+
+// Width returns the width of the Chart.
+func (c *Chart) Width() float64 {
+	return c.width
+}
+
+// Height returns the height of the Chart.
+func (c *Chart) Height() float64 {
+	return c.height
+}
+
+// chartTicks returns the ticks of a value axis covering the range, at round steps (1, 2 or 5 times a power of 10),
+// and the number of decimals of their labels.
+func chartTicks(lo, hi float64, count int) ([]float64, int) {
+	if hi <= lo {
+		hi = lo + 1
+	}
+	raw := (hi - lo) / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, factor := range []float64{1, 2, 5, 10} {
+		step = factor * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	ticks := []float64{}
+	for v := math.Floor(lo/step) * step; ; v += step {
+		ticks = append(ticks, v)
+		if v >= hi-step*1e-9 {
+			break
+		}
+	}
+	return ticks, decimals
+}
+
+// chartLabel is a text drawn on a chart, at a position from its upper left corner, aligned horizontally on it.
+type chartLabel struct {
+	text  string
+	style TextStyle
+	x, y  float64
+	align TextAlignment
+}
+
+// chartText returns a paragraph of the text of a chart, without wrapping.
+func chartText(text string, style TextStyle) *StyledParagraph {
+	p := NewStyledParagraph(text, style)
+	p.SetEnableWrap(false)
+	return p
+}
+
+// GeneratePageBlocks draws the chart on a block, on a new page if it does not fit in the remaining height of the
+// page in relative mode.  Implements the Drawable interface.
+func (c *Chart) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	if c.chartType == ChartPie && len(c.series) > 1 {
+		common.Log.Debug("Pie chart with %d series, only the first one drawn", len(c.series))
+	}
+
+	blocks := []*Block{}
+	origCtx := ctx
+
+	blk := NewBlock(ctx.PageWidth, ctx.PageHeight)
+	if c.positioning.isRelative() {
+		if c.height > ctx.Height {
+			// Goes out of the bounds.  Write on a new template instead and create a new context at upper
+			// left corner.
+			blocks = append(blocks, blk)
+			blk = NewBlock(ctx.PageWidth, ctx.PageHeight)
+
+			ctx.Page++
+			newContext := ctx
+			newContext.Y = ctx.Margins.top
+			newContext.X = ctx.Margins.left + c.margins.left
+			newContext.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom - c.margins.bottom
+			newContext.Width = ctx.PageWidth - ctx.Margins.left - ctx.Margins.right - c.margins.left - c.margins.right
+			ctx = newContext
+		} else {
+			ctx.Y += c.margins.top
+			ctx.Height -= c.margins.top + c.margins.bottom
+			ctx.X += c.margins.left
+			ctx.Width -= c.margins.left + c.margins.right
+		}
+	} else {
+		// Absolute.
+		ctx.X = c.xPos
+		ctx.Y = c.yPos
+	}
+
+	cc := contentstream.NewContentCreator()
+	labels, err := c.draw(cc, ctx)
+	if err != nil {
+		return nil, ctx, err
+	}
+	blk.addContents(cc.Operations())
+
+	// The labels are drawn over the graphics.
+	for _, label := range labels {
+		p := chartText(label.text, label.style)
+		x := label.x
+		switch label.align {
+		case TextAlignmentCenter:
+			x -= p.Width() / 2
+		case TextAlignmentRight:
+			x -= p.Width()
+		}
+		p.SetPos(ctx.X+x, ctx.Y+label.y)
+		labelBlocks, _, err := p.GeneratePageBlocks(ctx)
+		if err != nil {
+			return nil, ctx, err
+		}
+		for _, labelBlock := range labelBlocks {
+			// Labels are not text lines of the document.
+			labelBlock.lines = nil
+			err := blk.mergeBlocks(labelBlock)
+			if err != nil {
+				return nil, ctx, err
+			}
+		}
+	}
+	blocks = append(blocks, blk)
+
+	if c.positioning.isAbsolute() {
+		// Absolute drawing should not affect context.
+		return blocks, origCtx, nil
+	}
+	ctx.Y += c.height + c.margins.bottom
+	ctx.Height -= c.height + c.margins.bottom
+	return blocks, ctx, nil
+}
+
+// draw draws the graphics of the chart at the position of the context, and returns its labels.
+func (c *Chart) draw(cc *contentstream.ContentCreator, ctx DrawContext) ([]chartLabel, error) {
+	if c.width <= 0 || c.height <= 0 {
+		return nil, errors.New("Invalid chart size")
+	}
+	labels := []chartLabel{}
+	lineHeight := chartText("X", c.textStyle).Height()
+
+	// The title at the top, and the legend at the bottom.
+	top, bottom := 0.0, c.height
+	if c.title != "" {
+		labels = append(labels, chartLabel{c.title, c.titleStyle, c.width / 2, 0, TextAlignmentCenter})
+		top += chartText(c.title, c.titleStyle).Height() + chartPadding
+	}
+	if c.legend {
+		names := []string{}
+		colors := []Color{}
+		if c.chartType == ChartPie {
+			for i, category := range c.categories {
+				names = append(names, category)
+				colors = append(colors, c.palette[i%len(c.palette)])
+			}
+		} else {
+			for _, s := range c.series {
+				names = append(names, s.name)
+				colors = append(colors, s.color)
+			}
+		}
+		bottom -= lineHeight
+		labels = append(labels, c.drawLegend(cc, ctx, names, colors, bottom, lineHeight)...)
+		bottom -= chartPadding
+	}
+
+	// Converts the positions from the upper left corner of the chart to the page coordinates.
+	toPage := func(x, y float64) (float64, float64) {
+		return ctx.X + x, ctx.PageHeight - ctx.Y - y
+	}
+
+	if c.chartType == ChartPie {
+		c.drawPie(cc, toPage, top, bottom)
+		return labels, nil
+	}
+
+	// The value axis with round ticks, including 0.
+	lo, hi := 0.0, 0.0
+	for _, s := range c.series {
+		for _, v := range s.values {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	ticks, decimals := chartTicks(lo, hi, 5)
+	lo, hi = ticks[0], ticks[len(ticks)-1]
+
+	left := 0.0
+	tickLabels := make([]string, len(ticks))
+	for i, tick := range ticks {
+		tickLabels[i] = fmt.Sprintf("%.*f", decimals, tick)
+		left = math.Max(left, chartText(tickLabels[i], c.textStyle).Width())
+	}
+	left += chartPadding
+	right := c.width - chartPadding
+	plotTop := top + lineHeight/2
+	plotBottom := bottom - lineHeight - chartPadding
+	if right <= left || plotBottom <= plotTop {
+		common.Log.Debug("Chart too small (%fx%f)", c.width, c.height)
+		return nil, errors.New("Chart too small for its labels")
+	}
+	valueY := func(v float64) float64 {
+		return plotBottom - (v-lo)/(hi-lo)*(plotBottom-plotTop)
+	}
+
+	// The grid lines and tick labels.
+	for i, tick := range ticks {
+		y := valueY(tick)
+		if c.grid {
+			x1, y1 := toPage(left, y)
+			x2, y2 := toPage(right, y)
+			cc.Add_q().Add_RG(0.85, 0.85, 0.85).Add_w(0.5).Add_m(x1, y1).Add_l(x2, y2).Add_S().Add_Q()
+		}
+		labels = append(labels, chartLabel{tickLabels[i], c.textStyle, left - chartPadding, y - lineHeight/2,
+			TextAlignmentRight})
+	}
+
+	// The categories, with their labels centered under their slots.
+	n := len(c.categories)
+	for _, s := range c.series {
+		if len(s.values) > n {
+			n = len(s.values)
+		}
+	}
+	slot := (right - left) / math.Max(1, float64(n))
+	for i, category := range c.categories {
+		labels = append(labels, chartLabel{category, c.textStyle, left + (float64(i)+0.5)*slot,
+			plotBottom + chartPadding, TextAlignmentCenter})
+	}
+
+	switch c.chartType {
+	case ChartBar:
+		group := slot * 0.8
+		barWidth := group / math.Max(1, float64(len(c.series)))
+		for j, s := range c.series {
+			r, g, b := s.color.ToRGB()
+			cc.Add_q().Add_rg(r, g, b)
+			for i, v := range s.values {
+				x := left + float64(i)*slot + (slot-group)/2 + float64(j)*barWidth
+				y1, y2 := valueY(math.Max(v, 0)), valueY(math.Min(v, 0))
+				px, py := toPage(x, y2)
+				cc.Add_re(px, py, barWidth, y2-y1)
+			}
+			cc.Add_f().Add_Q()
+		}
+	case ChartLine:
+		for _, s := range c.series {
+			if len(s.values) == 0 {
+				continue
+			}
+			r, g, b := s.color.ToRGB()
+			cc.Add_q().Add_RG(r, g, b).Add_rg(r, g, b).Add_w(1.5)
+			for i, v := range s.values {
+				px, py := toPage(left+(float64(i)+0.5)*slot, valueY(v))
+				if i == 0 {
+					cc.Add_m(px, py)
+				} else {
+					cc.Add_l(px, py)
+				}
+			}
+			cc.Add_S()
+			// Markers at the values.
+			for i, v := range s.values {
+				px, py := toPage(left+(float64(i)+0.5)*slot, valueY(v))
+				addChartArc(cc, px, py, 2, 0, 2*math.Pi, true)
+				cc.Add_f()
+			}
+			cc.Add_Q()
+		}
+	}
+
+	// The axes, with the category axis at 0.
+	x0, y0 := toPage(left, plotTop)
+	x1, y1 := toPage(left, plotBottom)
+	cc.Add_q().Add_RG(0, 0, 0).Add_w(0.75).Add_m(x0, y0).Add_l(x1, y1)
+	x0, y0 = toPage(left, valueY(0))
+	x1, y1 = toPage(right, valueY(0))
+	cc.Add_m(x0, y0).Add_l(x1, y1).Add_S().Add_Q()
+	return labels, nil
+}
+
+// drawLegend draws the color swatches of the legend entries in a centered row at y, and returns their labels.
+func (c *Chart) drawLegend(cc *contentstream.ContentCreator, ctx DrawContext, names []string, colors []Color,
+	y, lineHeight float64) []chartLabel {
+	swatch := c.textStyle.FontSize * 0.8
+	total := 0.0
+	widths := make([]float64, len(names))
+	for i, name := range names {
+		widths[i] = swatch + chartPadding + chartText(name, c.textStyle).Width()
+		total += widths[i]
+	}
+	total += float64(len(names)-1) * 3 * chartPadding
+
+	labels := []chartLabel{}
+	x := (c.width - total) / 2
+	for i, name := range names {
+		r, g, b := colors[i].ToRGB()
+		cc.Add_q().Add_rg(r, g, b).
+			Add_re(ctx.X+x, ctx.PageHeight-ctx.Y-y-(lineHeight+swatch)/2, swatch, swatch).
+			Add_f().Add_Q()
+		labels = append(labels, chartLabel{name, c.textStyle, x + swatch + chartPadding, y, TextAlignmentLeft})
+		x += widths[i] + 3*chartPadding
+	}
+	return labels
+}
+
+// drawPie draws the slices of the pie of the first series in the plot area between top and bottom, clockwise from
+// the top.  Negative values are not drawn.
+func (c *Chart) drawPie(cc *contentstream.ContentCreator, toPage func(x, y float64) (float64, float64),
+	top, bottom float64) {
+	if len(c.series) == 0 {
+		return
+	}
+	values := c.series[0].values
+	total := 0.0
+	for _, v := range values {
+		if v > 0 {
+			total += v
+		}
+	}
+	radius := math.Min(c.width, bottom-top) / 2
+	if total <= 0 || radius <= 0 {
+		return
+	}
+
+	cx, cy := toPage(c.width/2, (top+bottom)/2)
+	angle := math.Pi / 2
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		sweep := -v / total * 2 * math.Pi
+		r, g, b := c.palette[i%len(c.palette)].ToRGB()
+		cc.Add_q().Add_rg(r, g, b).Add_RG(1, 1, 1).Add_w(1)
+		if v < total {
+			cc.Add_m(cx, cy)
+			addChartArc(cc, cx, cy, radius, angle, angle+sweep, false)
+		} else {
+			addChartArc(cc, cx, cy, radius, angle, angle+sweep, true)
+		}
+		cc.Add_h().Add_B().Add_Q()
+		angle += sweep
+	}
+}
+
+// addChartArc adds a circular arc of the radius centered at (cx, cy), from angle a1 to a2 (radians,
+// counterclockwise if increasing), with cubic Bézier curves of up to 90 degrees.  The arc starts a new subpath if
+// move, otherwise continues the current one with a line to its start.
+func addChartArc(cc *contentstream.ContentCreator, cx, cy, radius, a1, a2 float64, move bool) {
+	n := int(math.Ceil(math.Abs(a2-a1)/(math.Pi/2) - 1e-9))
+	if n < 1 {
+		n = 1
+	}
+	step := (a2 - a1) / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4) * radius
+
+	x, y := cx+radius*math.Cos(a1), cy+radius*math.Sin(a1)
+	if move {
+		cc.Add_m(x, y)
+	} else {
+		cc.Add_l(x, y)
+	}
+	for i := 0; i < n; i++ {
+		t1 := a1 + float64(i)*step
+		t2 := t1 + step
+		x2, y2 := cx+radius*math.Cos(t2), cy+radius*math.Sin(t2)
+		cc.Add_c(x-k*math.Sin(t1), y+k*math.Cos(t1), x2+k*math.Sin(t2), y2-k*math.Cos(t2), x2, y2)
+		x, y = x2, y2
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"math"
 
 	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
 )
 
 // Color interface represents colors in the PDF creator.
@@ -116,3 +119,134 @@ func ColorRGBFromArithmetic(r, g, b float64) Color {
 	color.b = b
 	return color
 }
+
+// Represents CMYK color values.
+type cmykColor struct {
+	// Arithmetic representation of c,m,y,k (range 0-1).
+	c, m, y, k float64
+}
+
+// ToRGB converts the color to RGB with the naive (uncalibrated) conversion.
+func (col cmykColor) ToRGB() (float64, float64, float64) {
+	r := (1 - col.c) * (1 - col.k)
+	g := (1 - col.m) * (1 - col.k)
+	b := (1 - col.y) * (1 - col.k)
+	return r, g, b
+}
+
+// ColorCMYKFromArithmetic creates a Color from arithmetic (0-1.0) c,m,y,k values.
+// CMYK colors are drawn in the DeviceCMYK color space.
+// Example:
+//   cyan := ColorCMYKFromArithmetic(1.0, 0, 0, 0)
+func ColorCMYKFromArithmetic(c, m, y, k float64) Color {
+	// Ensure is in the range 0-1:
+	c = math.Max(math.Min(c, 1.0), 0.0)
+	m = math.Max(math.Min(m, 1.0), 0.0)
+	y = math.Max(math.Min(y, 1.0), 0.0)
+	k = math.Max(math.Min(k, 1.0), 0.0)
+
+	return cmykColor{c: c, m: m, y: y, k: k}
+}
+
+// ColorCMYKFromPercent creates a Color from percentage (0-100) c,m,y,k values, the way CMYK values are usually
+// specified in print production.
+// Example:
+//   black := ColorCMYKFromPercent(0, 0, 0, 100)
+func ColorCMYKFromPercent(c, m, y, k float64) Color {
+	return ColorCMYKFromArithmetic(c/100.0, m/100.0, y/100.0, k/100.0)
+}
+
+// SpotColor is a named colorant, such as a Pantone ink, drawn in a Separation color space. When the colorant
+// is not available on the output device, the alternate color (scaled by the tint) is used instead.
+// Using the SpotColor itself as a Color paints with the full tint; use Tint for lighter shades.
+type SpotColor struct {
+	name      string
+	alternate Color
+	cs        *model.PdfColorspaceSpecialSeparation
+}
+
+// NewSpotColor creates a spot color with the colorant name and the alternate color used by devices without
+// the colorant. The alternate color should be a CMYK color for print output; other colors are used as RGB.
+func NewSpotColor(name string, alternate Color) *SpotColor {
+	sc := &SpotColor{name: name, alternate: alternate}
+
+	// The tint transform interpolates linearly from white (tint 0) to the alternate color (tint 1).
+	fn := &model.PdfFunctionType2{Domain: []float64{0, 1}, N: 1}
+	if cmyk, ok := alternate.(cmykColor); ok {
+		fn.C0 = []float64{0, 0, 0, 0}
+		fn.C1 = []float64{cmyk.c, cmyk.m, cmyk.y, cmyk.k}
+		sc.cs = model.NewPdfColorspaceSpecialSeparation()
+		sc.cs.AlternateSpace = model.NewPdfColorspaceDeviceCMYK()
+	} else {
+		r, g, b := alternate.ToRGB()
+		fn.C0 = []float64{1, 1, 1}
+		fn.C1 = []float64{r, g, b}
+		sc.cs = model.NewPdfColorspaceSpecialSeparation()
+		sc.cs.AlternateSpace = model.NewPdfColorspaceDeviceRGB()
+	}
+	sc.cs.ColorantName = core.MakeName(name)
+	sc.cs.TintTransform = fn
+	return sc
+}
+
+// Name returns the colorant name of the spot color.
+func (sc *SpotColor) Name() string {
+	return sc.name
+}
+
+// ToRGB returns the RGB representation of the alternate color at full tint.
+func (sc *SpotColor) ToRGB() (float64, float64, float64) {
+	return sc.alternate.ToRGB()
+}
+
+// Tint returns a Color painting the spot color at the tint (0-1.0), where 0 is no ink and 1.0 is the full colorant.
+func (sc *SpotColor) Tint(tint float64) Color {
+	return spotTint{spot: sc, tint: math.Max(math.Min(tint, 1.0), 0.0)}
+}
+
+// Represents a spot color at a given tint.
+type spotTint struct {
+	spot *SpotColor
+	tint float64
+}
+
+func (col spotTint) ToRGB() (float64, float64, float64) {
+	r, g, b := col.spot.alternate.ToRGB()
+	return 1 - col.tint*(1-r), 1 - col.tint*(1-g), 1 - col.tint*(1-b)
+}
+
+// addColorOperators adds the operators setting `col` as the fill color (or the stroking color if `stroke` is true)
+// to the content creator. CMYK colors use the DeviceCMYK operators and spot colors their Separation color space,
+// which is registered in the resources of `blk`. Other colors are set in DeviceRGB.
+func addColorOperators(cc *contentstream.ContentCreator, blk *Block, col Color, stroke bool) {
+	switch c := col.(type) {
+	case cmykColor:
+		if stroke {
+			cc.Add_K(c.c, c.m, c.y, c.k)
+		} else {
+			cc.Add_k(c.c, c.m, c.y, c.k)
+		}
+	case *SpotColor:
+		addSpotColorOperators(cc, blk, c, 1.0, stroke)
+	case spotTint:
+		addSpotColorOperators(cc, blk, c.spot, c.tint, stroke)
+	default:
+		r, g, b := col.ToRGB()
+		if stroke {
+			cc.Add_RG(r, g, b)
+		} else {
+			cc.Add_rg(r, g, b)
+		}
+	}
+}
+
+// addSpotColorOperators selects the Separation color space of the spot color and sets the tint.
+func addSpotColorOperators(cc *contentstream.ContentCreator, blk *Block, sc *SpotColor, tint float64, stroke bool) {
+	csName := core.PdfObjectName("CS" + sc.name)
+	blk.resources.SetColorspaceByName(csName, sc.cs)
+	if stroke {
+		cc.Add_CS(csName).Add_SCN(tint)
+	} else {
+		cc.Add_cs(csName).Add_scn(tint)
+	}
+}
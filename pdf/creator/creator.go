@@ -42,8 +42,39 @@ type Creator struct {
 
 	toc *TableOfContents
 
+	// The headings drawn, in the outline.
+	headings []outlineEntry
+
+	// Link the entries of the table of contents to the headings, and generate the outline tree.
+	tocLinks bool
+	outlines bool
+
 	// Forms.
 	acroForm *model.PdfAcroForm
+
+	// Line numbering and the text line baselines drawn on each page.
+	lineNumbering *LineNumbering
+	pageLines     map[*model.PdfPage][]float64
+
+	// The drawables drawn, for checking the glyph coverage of their text.
+	drawn []Drawable
+
+	// Link annotations going to pages, whose destinations are set when finalizing.
+	pageLinks []pageLink
+
+	// The output of the pages when written once complete, see WriteStream.
+	stream *pageStream
+
+	// Spot colors registered by name.
+	spotColors map[string]*SpotColor
+
+	// The structure tree and the Document element of tagged documents, nil if not tagged.
+	structTree *model.PdfStructTreeRoot
+	structDoc  *model.PdfStructElement
+	roleMap    map[string]string
+
+	// Tab order of the annotations of the pages, e.g. form fields.
+	tabOrder model.PdfTabOrder
 }
 
 // SetForms Add Acroforms to a PDF file.  Sets the specified form for writing.
@@ -64,6 +95,10 @@ type FrontpageFunctionArgs struct {
 type HeaderFunctionArgs struct {
 	PageNum    int
 	TotalPages int
+
+	// Size of the page, which can differ between pages, e.g. imported pages.
+	PageWidth  float64
+	PageHeight float64
 }
 
 // FooterFunctionArgs holds the input arguments to a footer drawing function.
@@ -71,6 +106,10 @@ type HeaderFunctionArgs struct {
 type FooterFunctionArgs struct {
 	PageNum    int
 	TotalPages int
+
+	// Size of the page, which can differ between pages, e.g. imported pages.
+	PageWidth  float64
+	PageHeight float64
 }
 
 // Margins.  Can be page margins, or margins around an element.
@@ -98,6 +137,23 @@ func New() *Creator {
 	return c
 }
 
+// RegisterSpotColor registers a spot color with the colorant name and alternate color, so that it can be looked up
+// with GetSpotColor. Registering a name again replaces the previous spot color.
+func (c *Creator) RegisterSpotColor(name string, alternate Color) *SpotColor {
+	if c.spotColors == nil {
+		c.spotColors = map[string]*SpotColor{}
+	}
+	sc := NewSpotColor(name, alternate)
+	c.spotColors[name] = sc
+	return sc
+}
+
+// GetSpotColor returns the spot color registered with the colorant name.
+func (c *Creator) GetSpotColor(name string) (*SpotColor, bool) {
+	sc, has := c.spotColors[name]
+	return sc, has
+}
+
 // SetPageMargins sets the page margins: left, right, top, bottom.
 // The default page margins are 10% of document width.
 func (c *Creator) SetPageMargins(left, right, top, bottom float64) {
@@ -162,12 +218,14 @@ func (c *Creator) SetPageSize(size PageSize) {
 	c.pageMargins.bottom = m
 }
 
-// DrawHeader sets a function to draw a header on created output pages.
+// DrawHeader sets a function to draw a header on created output pages.  The function is called for each page when
+// writing, once all the pages are created, so the total number of pages is known (e.g. for "Page X of Y").
 func (c *Creator) DrawHeader(drawHeaderFunc func(header *Block, args HeaderFunctionArgs)) {
 	c.drawHeaderFunc = drawHeaderFunc
 }
 
-// DrawFooter sets a function to draw a footer on created output pages.
+// DrawFooter sets a function to draw a footer on created output pages.  Like headers, footers are drawn once all
+// the pages are created.
 func (c *Creator) DrawFooter(drawFooterFunc func(footer *Block, args FooterFunctionArgs)) {
 	c.drawFooterFunc = drawFooterFunc
 }
@@ -214,6 +272,7 @@ func (c *Creator) initContext() {
 
 // NewPage adds a new Page to the Creator and sets as the active Page.
 func (c *Creator) NewPage() {
+	c.completePages()
 	page := c.newPage()
 	c.pages = append(c.pages, page)
 	c.context.Page++
@@ -227,11 +286,14 @@ func (c *Creator) AddPage(page *model.PdfPage) error {
 		return err
 	}
 
-	c.context.X = mbox.Llx + c.pageMargins.left
+	// The layout is in points, scaled to the larger units of oversized pages when drawn.
+	unit := page.GetUserUnit()
+	c.context.X = mbox.Llx*unit + c.pageMargins.left
 	c.context.Y = c.pageMargins.top
-	c.context.PageHeight = mbox.Ury - mbox.Lly
-	c.context.PageWidth = mbox.Urx - mbox.Llx
+	c.context.PageHeight = (mbox.Ury - mbox.Lly) * unit
+	c.context.PageWidth = (mbox.Urx - mbox.Llx) * unit
 
+	c.completePages()
 	c.pages = append(c.pages, page)
 	c.context.Page++
 
@@ -300,6 +362,11 @@ func (c *Creator) finalize() error {
 
 		// Remove the TOC chapter entry.
 		c.toc.entries = c.toc.entries[:len(c.toc.entries)-1]
+	} else if genpages > 0 {
+		// Account for the front Page.
+		for idx := range c.toc.entries {
+			c.toc.entries[idx].PageNumber += genpages
+		}
 	}
 
 	hasFrontPage := false
@@ -350,52 +417,117 @@ func (c *Creator) finalize() error {
 			c.pages = append(tocpages, c.pages...)
 		}
 
+		if c.tocLinks {
+			err := c.addTableOfContentsLinks(blocks, tocpages)
+			if err != nil {
+				common.Log.Debug("Error linking TOC entries: %v", err)
+				return err
+			}
+		}
 	}
 
-	for idx, page := range c.pages {
-		c.setActivePage(page)
-		if c.drawHeaderFunc != nil {
-			// Prepare a block to draw on.
-			// Header is drawn on the top of the page. Has width of the page, but height limited to the page
-			// margin top height.
-			headerBlock := NewBlock(c.pageWidth, c.pageMargins.top)
-			args := HeaderFunctionArgs{
-				PageNum:    idx + 1,
-				TotalPages: totPages,
+	if c.lineNumbering != nil {
+		num := 1
+		for _, page := range c.pages {
+			if c.lineNumbering.restartEachPage {
+				num = 1
 			}
-			c.drawHeaderFunc(headerBlock, args)
-			headerBlock.SetPos(0, 0)
-			err := c.Draw(headerBlock)
+			c.setActivePage(page)
+			var err error
+			num, err = c.drawLineNumbers(c.pageLines[page], num)
 			if err != nil {
-				common.Log.Debug("Error drawing header: %v", err)
+				common.Log.Debug("Error drawing line numbers: %v", err)
 				return err
 			}
-
 		}
-		if c.drawFooterFunc != nil {
-			// Prepare a block to draw on.
-			// Footer is drawn on the bottom of the page. Has width of the page, but height limited to the page
-			// margin bottom height.
-			footerBlock := NewBlock(c.pageWidth, c.pageMargins.bottom)
-			args := FooterFunctionArgs{
-				PageNum:    idx + 1,
-				TotalPages: totPages,
-			}
-			c.drawFooterFunc(footerBlock, args)
-			footerBlock.SetPos(0, c.pageHeight-footerBlock.height)
-			err := c.Draw(footerBlock)
+	}
+
+	if c.drawHeaderFunc != nil || c.drawFooterFunc != nil {
+		for idx, page := range c.pages {
+			err := c.drawHeaderFooter(page, idx+1, totPages)
 			if err != nil {
-				common.Log.Debug("Error drawing footer: %v", err)
 				return err
 			}
 		}
 	}
 
+	err := c.setPageLinkDests()
+	if err != nil {
+		common.Log.Debug("Error linking pages: %v", err)
+		return err
+	}
+
+	for _, page := range c.pages {
+		err := c.orderAnnotations(page)
+		if err != nil {
+			return err
+		}
+	}
+
 	c.finalized = true
 
 	return nil
 }
 
+// drawHeaderFooter draws the header and footer of the page with the number pageNum.
+func (c *Creator) drawHeaderFooter(page *model.PdfPage, pageNum, totPages int) error {
+	c.setActivePage(page)
+
+	// Headers and footers are laid out for the size of each page.
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		common.Log.Debug("Failed to get page mediabox: %v", err)
+		return err
+	}
+	pageWidth := (mbox.Urx - mbox.Llx) * page.GetUserUnit()
+	pageHeight := (mbox.Ury - mbox.Lly) * page.GetUserUnit()
+	c.context.PageWidth = pageWidth
+	c.context.PageHeight = pageHeight
+
+	if c.drawHeaderFunc != nil {
+		// Prepare a block to draw on.
+		// Header is drawn on the top of the page. Has width of the page, but height limited to the page
+		// margin top height.
+		headerBlock := NewBlock(pageWidth, c.pageMargins.top)
+		args := HeaderFunctionArgs{
+			PageNum:    pageNum,
+			TotalPages: totPages,
+			PageWidth:  pageWidth,
+			PageHeight: pageHeight,
+		}
+		c.drawHeaderFunc(headerBlock, args)
+		headerBlock.lines = nil
+		headerBlock.SetPos(0, 0)
+		err := c.draw(headerBlock, nil, "Pagination")
+		if err != nil {
+			common.Log.Debug("Error drawing header: %v", err)
+			return err
+		}
+
+	}
+	if c.drawFooterFunc != nil {
+		// Prepare a block to draw on.
+		// Footer is drawn on the bottom of the page. Has width of the page, but height limited to the page
+		// margin bottom height.
+		footerBlock := NewBlock(pageWidth, c.pageMargins.bottom)
+		args := FooterFunctionArgs{
+			PageNum:    pageNum,
+			TotalPages: totPages,
+			PageWidth:  pageWidth,
+			PageHeight: pageHeight,
+		}
+		c.drawFooterFunc(footerBlock, args)
+		footerBlock.lines = nil
+		footerBlock.SetPos(0, pageHeight-footerBlock.height)
+		err := c.draw(footerBlock, nil, "Pagination")
+		if err != nil {
+			common.Log.Debug("Error drawing footer: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
 // MoveTo moves the drawing context to absolute coordinates (x, y).
 func (c *Creator) MoveTo(x, y float64) {
 	c.context.X = x
@@ -425,15 +557,30 @@ func (c *Creator) MoveDown(dy float64) {
 // Draw draws the Drawable widget to the document.  This can span over 1 or more pages. Additional pages are added if
 // the contents go over the current Page.
 func (c *Creator) Draw(d Drawable) error {
+	if c.structTree == nil {
+		return c.draw(d, nil, "")
+	}
+	return c.draw(d, structElement(d), "")
+}
+
+// draw draws the drawable, marked in tagged documents as the content of the structure element or, if nil, as an
+// artifact of the type.
+func (c *Creator) draw(d Drawable, elem *model.PdfStructElement, artifact string) error {
 	if c.getActivePage() == nil {
 		// Add a new Page if none added already.
 		c.NewPage()
 	}
+	if c.stream == nil {
+		c.drawn = append(c.drawn, d)
+	}
 
 	blocks, ctx, err := d.GeneratePageBlocks(c.context)
 	if err != nil {
 		return err
 	}
+	if h, isHeading := d.(*Heading); isHeading {
+		c.headings = append(c.headings, outlineEntry{h.title, h.level, h.page, h.y})
+	}
 
 	for idx, blk := range blocks {
 		if idx > 0 {
@@ -441,10 +588,21 @@ func (c *Creator) Draw(d Drawable) error {
 		}
 
 		p := c.getActivePage()
-		err := blk.drawToPage(p)
+		if c.acroForm == nil && blk.hasFields() {
+			c.acroForm = model.NewPdfAcroForm()
+		}
+		if c.structTree != nil {
+			blk = c.tagBlock(blk, p, elem, artifact)
+		}
+		pageLinks, err := blk.drawToPage(p, c.acroForm)
 		if err != nil {
 			return err
 		}
+		c.pageLinks = append(c.pageLinks, pageLinks...)
+		c.addPageLines(p, blk.lines)
+	}
+	if elem != nil && len(elem.Kids) > 0 {
+		c.structDoc.AddKid(elem)
 	}
 
 	// Inner elements can affect X, Y position and available height.
@@ -455,8 +613,8 @@ func (c *Creator) Draw(d Drawable) error {
 	return nil
 }
 
-// Write output of creator to io.WriteSeeker interface.
-func (c *Creator) Write(ws io.WriteSeeker) error {
+// Write output of creator to io.Writer interface.
+func (c *Creator) Write(w io.Writer) error {
 	if !c.finalized {
 		c.finalize()
 	}
@@ -488,7 +646,28 @@ func (c *Creator) Write(ws io.WriteSeeker) error {
 		}
 	}
 
-	err := pdfWriter.Write(ws)
+	return c.writeDocument(&pdfWriter, w)
+}
+
+// writeDocument writes the document with the pages added to the PdfWriter, completed with the outlines and the
+// structure tree.
+func (c *Creator) writeDocument(pdfWriter *model.PdfWriter, w io.Writer) error {
+	if c.outlines && len(c.toc.entries)+len(c.headings) > 0 {
+		outlines, err := c.buildOutlines()
+		if err != nil {
+			common.Log.Debug("Failed to build outlines: %v", err)
+			return err
+		}
+		pdfWriter.AddOutlineTree(&outlines.PdfOutlineTreeNode)
+	}
+	if c.structTree != nil {
+		for structType, role := range c.roleMap {
+			c.structTree.RoleMap[structType] = role
+		}
+		pdfWriter.SetStructTreeRoot(c.structTree)
+	}
+
+	err := pdfWriter.Write(w)
 	if err != nil {
 		return err
 	}
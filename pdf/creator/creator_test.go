@@ -10,10 +10,15 @@ package creator
 // if every detail is correct.
 
 import (
+	"bytes"
 	"fmt"
 	goimage "image"
+	"image/color"
 	"io/ioutil"
 	"math"
+	"os"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/boombuler/barcode"
@@ -21,9 +26,11 @@ import (
 
 	"github.com/unidoc/unidoc/common"
 	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
 	"github.com/unidoc/unidoc/pdf/model"
 	"github.com/unidoc/unidoc/pdf/model/fonts"
 	"github.com/unidoc/unidoc/pdf/model/textencoding"
+	"github.com/unidoc/unidoc/pdf/render"
 )
 
 func init() {
@@ -1279,6 +1286,200 @@ func TestHeadersAndFooters(t *testing.T) {
 	}
 }
 
+// Test page numbering footers on pages of different sizes.
+func TestFootersPageSizes(t *testing.T) {
+	c := New()
+	c.NewPage()
+
+	// Imported page of a smaller size.
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 420, Ury: 595}
+	err := c.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	sizes := [][2]float64{}
+	c.DrawFooter(func(footer *Block, args FooterFunctionArgs) {
+		sizes = append(sizes, [2]float64{args.PageWidth, args.PageHeight})
+		p := NewParagraph(fmt.Sprintf("Page %d of %d", args.PageNum, args.TotalPages))
+		p.SetPos(footer.Width()-100, 10)
+		footer.Draw(p)
+	})
+
+	err = c.WriteToFile("/tmp/4_footers_page_sizes.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if len(sizes) != 2 || sizes[0] != [2]float64{612, 792} || sizes[1] != [2]float64{420, 595} {
+		t.Fatalf("Wrong page sizes %v", sizes)
+	}
+
+	// The footer is drawn within the bottom margin of each page.
+	for i, page := range c.pages {
+		e, err := extractor.New(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		marks, err := e.ExtractTextMarks()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		// Spaces are not marked, and the marks are followed by the license watermark.
+		expected := fmt.Sprintf("Page%dof2", i+1)
+		if len(marks) < len(expected) {
+			t.Fatalf("Page %d: missing footer", i+1)
+		}
+		text := ""
+		for _, mark := range marks[:len(expected)] {
+			text += mark.Text
+			if mark.Origin.Y < 0 || mark.Origin.Y > c.pageMargins.bottom || mark.Origin.X > sizes[i][0] {
+				t.Errorf("Page %d: footer text outside of the margin at %v", i+1, mark.Origin)
+				break
+			}
+		}
+		if text != expected {
+			t.Errorf("Page %d: wrong footer text %q", i+1, text)
+		}
+	}
+}
+
+func TestDefaultTableOfContents(t *testing.T) {
+	c := New()
+	c.CreateFrontPage(func(args FrontpageFunctionArgs) {
+		p := NewParagraph("Front page")
+		c.Draw(p)
+	})
+	c.CreateDefaultTableOfContents("Contents")
+	c.SetEnableOutlines(true)
+
+	ch1 := c.NewChapter("Introduction")
+	ch1.Add(NewParagraph("Introduction text"))
+	err := c.Draw(ch1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	ch2 := c.NewChapter("Details")
+	sc1 := c.NewSubchapter(ch2, "First")
+	sc1.Add(NewParagraph("First text"))
+	sc1.Add(NewPageBreak())
+	sc2 := c.NewSubchapter(ch2, "Second")
+	sc2.Add(NewParagraph("Second text"))
+	err = c.Draw(ch2)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	err = c.WriteToFile("/tmp/4_default_toc.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// Front page, contents, 2 pages of chapters.
+	if len(c.pages) != 4 {
+		t.Fatalf("Wrong number of pages %d", len(c.pages))
+	}
+	expected := []struct {
+		title string
+		page  int
+	}{
+		{"1. Introduction", 3},
+		{"2. Details", 3},
+		{"2.1. First", 3},
+		{"2.2. Second", 4},
+	}
+
+	// The entries are listed with dot leaders, and link to the headings.
+	e, err := extractor.New(c.pages[1])
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	text, err := e.ExtractText()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The extracted text is truncated by the license watermark.
+	for _, exp := range expected[:3] {
+		if !regexp.MustCompile(regexp.QuoteMeta(exp.title) + ` \.{10,} ` + fmt.Sprintf("%d", exp.page)).MatchString(text) {
+			t.Errorf("Entry %q missing from the contents:\n%s", exp.title, text)
+		}
+	}
+
+	annots := c.pages[1].Annotations
+	if len(annots) != len(expected) {
+		t.Fatalf("Wrong number of links %d", len(annots))
+	}
+	for i, annot := range annots {
+		link, ok := annot.GetContext().(*model.PdfAnnotationLink)
+		if !ok {
+			t.Fatalf("Annotation not a link (%T)", annot.GetContext())
+		}
+		dest, ok := link.Dest.(*core.PdfObjectArray)
+		if !ok || len(*dest) != 5 {
+			t.Fatalf("Invalid link destination %v", link.Dest)
+		}
+		if (*dest)[0] != c.pages[expected[i].page-1].GetPageAsIndirectObject() {
+			t.Errorf("Link %d to the wrong page", i)
+		}
+	}
+
+	// The outlines nest the subchapters in their chapters.
+	f, err := os.Open("/tmp/4_default_toc.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	nodes, titles, err := reader.GetOutlinesFlattened()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(nodes) != len(expected) {
+		t.Fatalf("Wrong outline items %q", titles)
+	}
+
+	outlineDict := func(obj core.PdfObject) *core.PdfObjectDictionary {
+		dict, ok := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary)
+		if !ok {
+			t.Fatalf("Outline not a dictionary (%T)", obj)
+		}
+		return dict
+	}
+	checkCount := func(dict *core.PdfObjectDictionary, count int64) {
+		if n, ok := dict.Get("Count").(*core.PdfObjectInteger); !ok || int64(*n) != count {
+			t.Errorf("Wrong outline count %v, expected %d", dict.Get("Count"), count)
+		}
+	}
+	root := outlineDict(reader.GetOutlineTree().ToPdfObject())
+	checkCount(root, 4)
+	chapters := []*core.PdfObjectDictionary{}
+	for obj := root.Get("First"); obj != nil; obj = outlineDict(obj).Get("Next") {
+		chapters = append(chapters, outlineDict(obj))
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("Wrong number of chapter outlines %d", len(chapters))
+	}
+	if chapters[0].Get("First") != nil {
+		t.Errorf("Chapter 1 has subchapter outlines")
+	}
+	checkCount(chapters[1], 2)
+	items := []*core.PdfObjectDictionary{chapters[0], chapters[1]}
+	for obj := chapters[1].Get("First"); obj != nil; obj = outlineDict(obj).Get("Next") {
+		items = append(items, outlineDict(obj))
+	}
+	for i, item := range items {
+		title, ok := item.Get("Title").(*core.PdfObjectString)
+		if !ok || string(*title) != expected[i].title {
+			t.Errorf("Outline item %d: title %v, expected %q", i, item.Get("Title"), expected[i].title)
+		}
+	}
+}
+
 func makeQrCodeImage(text string, width float64, oversampling int) (goimage.Image, error) {
 	qrCode, err := qr.Encode(text, qr.M, qr.Auto)
 	if err != nil {
@@ -1429,3 +1630,1325 @@ func TestEncrypting1(t *testing.T) {
 		return
 	}
 }
+
+func TestStyledParagraph(t *testing.T) {
+	c := New()
+
+	style := NewTextStyle()
+	style.FontSize = 12
+	p := NewStyledParagraph("Text in ", style)
+	bold := p.Append("bold Times")
+	bold.Style.Font = fonts.NewFontTimesBold()
+	bold.Style.FontSize = 16
+	bold.Style.Color = ColorRGBFrom8bit(200, 0, 0)
+	p.Append(", E = mc")
+	sup := p.Append("2")
+	sup.Style.VerticalPosition = TextPositionSuperscript
+	struck := p.Append(" struck out")
+	struck.Style.Strikeout = true
+	p.Append(" and a ")
+	p.AppendLink("link to the site", "https://unidoc.io")
+	p.Append(" wrapping over several lines when the paragraph is narrow enough.")
+	p.SetMargins(10, 0, 0, 0)
+
+	// Wrapping within the width, the lines as high as their largest text.
+	p.SetWidth(150)
+	if err := p.wrapText(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(p.lines) < 3 {
+		t.Fatalf("Too few lines %d", len(p.lines))
+	}
+	for i, line := range p.lines {
+		if line.width > 150 {
+			t.Errorf("Line %d wider than the paragraph (%f)", i, line.width)
+		}
+	}
+	if p.lines[0].height != 16 {
+		t.Errorf("First line height %f != 16", p.lines[0].height)
+	}
+	if h := p.lines[len(p.lines)-1].height; h != 12 {
+		t.Errorf("Last line height %f != 12", h)
+	}
+
+	err := c.Draw(p)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The link annotation with the URI action over the link text.
+	page := c.pages[0]
+	if len(page.Annotations) != 1 {
+		t.Fatalf("Wrong number of annotations %d", len(page.Annotations))
+	}
+	link, ok := page.Annotations[0].GetContext().(*model.PdfAnnotationLink)
+	if !ok {
+		t.Fatalf("Annotation not a link (%T)", page.Annotations[0].GetContext())
+	}
+	action, ok := core.TraceToDirectObject(link.A).(*core.PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Link action not a dictionary (%T)", link.A)
+	}
+	if uri, ok := action.Get("URI").(*core.PdfObjectString); !ok || string(*uri) != "https://unidoc.io" {
+		t.Errorf("Wrong link URI %v", action.Get("URI"))
+	}
+
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// Superscript raised and smaller, decorations drawn as rectangles.
+	for _, expected := range []string{"4.200000 Ts", "7.200000 Tf", " re", "0.784314 0.000000 0.000000 rg"} {
+		if !regexp.MustCompile(regexp.QuoteMeta(expected)).MatchString(contents) {
+			t.Errorf("Missing %q in the contents", expected)
+		}
+	}
+
+	err = c.WriteToFile("/tmp/styled_paragraph.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestStyledParagraphPageBreak(t *testing.T) {
+	c := New()
+
+	p := NewStyledParagraph("", NewTextStyle())
+	for i := 0; i < 100; i++ {
+		p.Append(fmt.Sprintf("Line %d\n", i+1))
+	}
+	err := c.Draw(p)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// Lines continue on the following page.
+	if len(c.pages) != 2 {
+		t.Fatalf("Wrong number of pages %d", len(c.pages))
+	}
+	if c.context.Y <= c.pageMargins.top {
+		t.Errorf("Context not advanced on the second page (%f)", c.context.Y)
+	}
+}
+
+func TestCheckGlyphCoverage(t *testing.T) {
+	c := New()
+
+	err := c.Draw(NewParagraph("Covered text, déjà vu"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if missing := c.CheckGlyphCoverage(); len(missing) != 0 {
+		t.Fatalf("Unexpected missing glyphs %v", missing)
+	}
+
+	// Not in WinAnsiEncoding, and not drawn.
+	ch := c.NewChapter("Snowman ☃")
+	ch.Add(NewParagraph("Arrows ← → and the snowman ☃"))
+	table := NewTable(1)
+	cell := table.NewCell()
+	symbol := NewParagraph("abc")
+	symbol.SetFont(fonts.NewFontSymbol())
+	cell.SetContent(symbol)
+	ch.Add(table)
+	c.Draw(ch)
+
+	missing := c.CheckGlyphCoverage()
+	expected := map[string][]rune{
+		"Helvetica": {'←', '→', '☃'},
+		"Symbol":    {'a', 'b', 'c'},
+	}
+	if len(missing) != len(expected) {
+		t.Fatalf("Missing glyphs %q, expected %q", missing, expected)
+	}
+	for name, runes := range expected {
+		if string(missing[name]) != string(runes) {
+			t.Errorf("Missing glyphs of %s %q, expected %q", name, string(missing[name]), string(runes))
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	c := New()
+
+	p := NewParagraph("Link to the site")
+	p.SetLink("https://unidoc.io")
+	err := c.Draw(p)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	imgData, err := ioutil.ReadFile(testImageFile1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	img, err := NewImageFromData(imgData)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	img.SetPos(100, 200)
+	img.ScaleToWidth(100)
+	img.SetPageLink(2, 0, 50)
+	err = c.Draw(img)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	table := NewTable(2)
+	table.SetPos(100, 400)
+	table.SetColumnWidths(0.5, 0.5)
+	for i := 0; i < 2; i++ {
+		cell := table.NewCell()
+		cell.SetContent(NewParagraph(fmt.Sprintf("Cell %d", i+1)))
+	}
+	table.cells[1].SetPageLink(1, 0, 0)
+	err = c.Draw(table)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	c.NewPage()
+	err = c.Draw(NewParagraph("Second page"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	err = c.WriteToFile("/tmp/links.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	page := c.pages[0]
+	if len(page.Annotations) != 3 {
+		t.Fatalf("Wrong number of annotations %d", len(page.Annotations))
+	}
+	links := []*model.PdfAnnotationLink{}
+	for _, annot := range page.Annotations {
+		link, ok := annot.GetContext().(*model.PdfAnnotationLink)
+		if !ok {
+			t.Fatalf("Annotation not a link (%T)", annot.GetContext())
+		}
+		links = append(links, link)
+	}
+
+	// The URI link over the paragraph, at the top left of the page.
+	action, ok := links[0].A.(*core.PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Link action not a dictionary (%T)", links[0].A)
+	}
+	if uri, ok := action.Get("URI").(*core.PdfObjectString); !ok || string(*uri) != "https://unidoc.io" {
+		t.Errorf("Wrong link URI %v", action.Get("URI"))
+	}
+
+	// The links to pages over the image and the cell.
+	expected := []struct {
+		link *model.PdfAnnotationLink
+		rect model.PdfRectangle
+		page int
+		top  float64
+	}{
+		{links[1], model.PdfRectangle{Llx: 100, Lly: 792 - 200 - img.Height(), Urx: 200, Ury: 792 - 200}, 1, 742},
+		// The row grows to fit the paragraph of the cell.
+		{links[2], model.PdfRectangle{Llx: 100 + c.context.Width/2, Lly: 792 - 400 - 15, Urx: 100 + c.context.Width,
+			Ury: 792 - 400}, 0, 792},
+	}
+	for i, exp := range expected {
+		rect, err := model.NewPdfRectangle(*exp.link.Rect.(*core.PdfObjectArray))
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if math.Abs(rect.Llx-exp.rect.Llx) > 0.01 || math.Abs(rect.Lly-exp.rect.Lly) > 0.01 ||
+			math.Abs(rect.Urx-exp.rect.Urx) > 0.01 || math.Abs(rect.Ury-exp.rect.Ury) > 0.01 {
+			t.Errorf("Link %d rectangle %v, expected %v", i, *rect, exp.rect)
+		}
+
+		dest, ok := exp.link.Dest.(*core.PdfObjectArray)
+		if !ok || len(*dest) != 5 {
+			t.Fatalf("Link %d destination invalid (%v)", i, exp.link.Dest)
+		}
+		if (*dest)[0] != c.pages[exp.page].GetPageAsIndirectObject() {
+			t.Errorf("Link %d to the wrong page", i)
+		}
+		if top, ok := (*dest)[3].(*core.PdfObjectFloat); !ok || float64(*top) != exp.top {
+			t.Errorf("Link %d destination top %v, expected %v", i, (*dest)[3], exp.top)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	testcases := []struct {
+		numbering ListNumbering
+		start     int
+		markers   []string
+	}{
+		{ListBullet, 1, []string{"•", "•"}},
+		{ListDecimal, 9, []string{"9.", "10."}},
+		{ListLowerAlpha, 26, []string{"z.", "aa."}},
+		{ListUpperRoman, 3, []string{"III.", "IV."}},
+	}
+	for _, tcase := range testcases {
+		l := NewList()
+		l.SetNumbering(tcase.numbering)
+		l.SetStartNumber(tcase.start)
+		for i := range tcase.markers {
+			if marker := l.marker(i); marker != tcase.markers[i] {
+				t.Errorf("Marker %d %q, expected %q", i, marker, tcase.markers[i])
+			}
+		}
+	}
+
+	c := New()
+	l := NewList()
+	l.SetNumbering(ListDecimal)
+	for i := 0; i < 30; i++ {
+		p := l.AddText(fmt.Sprintf("Item %d with enough text to wrap over several lines of the page, aligned "+
+			"with a hanging indent after the number of the item.", i+1))
+		p.SetMargins(0, 0, 0, 5)
+		if i%10 == 0 {
+			sub := NewList()
+			sub.AddText("Nested item")
+			sub.AddText("Another nested item")
+			err := l.Add(sub)
+			if err != nil {
+				t.Fatalf("Error: %v", err)
+			}
+		}
+	}
+	if err := l.Add(l); err == nil {
+		t.Errorf("List added to itself")
+	}
+
+	err := c.Draw(l)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(c.pages) < 2 {
+		t.Fatalf("List not wrapped across pages (%d pages)", len(c.pages))
+	}
+
+	// The first item with its number on its first line, in the indent.
+	p := l.items[0].(*Paragraph)
+	if p.wrapWidth != c.context.Width-20 {
+		t.Errorf("Item width %f, expected %f", p.wrapWidth, c.context.Width-20)
+	}
+	contents, err := c.pages[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, expected := range []string{"(1.)", "(2.)"} {
+		if !regexp.MustCompile(regexp.QuoteMeta(expected)).MatchString(contents) {
+			t.Errorf("Missing marker %q in the contents", expected)
+		}
+	}
+	// The nested lists with the bullets of their level.
+	if marker := l.items[1].(*List).marker(0); marker != "–" {
+		t.Errorf("Nested list marker %q", marker)
+	}
+	contents, err = c.pages[len(c.pages)-1].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !regexp.MustCompile(regexp.QuoteMeta("(30.)")).MatchString(contents) {
+		t.Errorf("Missing marker of the last item on the last page")
+	}
+
+	err = c.WriteToFile("/tmp/list.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	c := New()
+	logo, err := NewSVGFromData([]byte(`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100">
+		<rect width="200" height="100" rx="20" fill="#336699"/>
+		<circle cx="50" cy="50" r="30" fill="white" stroke="orange" stroke-width="5"/>
+		<path d="M100 80 L130 20 L160 80 Z" fill="gold" fill-opacity="0.8"/>
+		<text x="100" y="95" font-family="Helvetica" font-size="12" fill="white">LOGO</text>
+	</svg>`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if logo.Width() != 150 || logo.Height() != 75 {
+		t.Fatalf("Wrong size %fx%f", logo.Width(), logo.Height())
+	}
+	logo.ScaleToWidth(300)
+	logo.SetMargins(0, 0, 10, 10)
+
+	err = c.Draw(logo)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if c.context.Y != c.pageMargins.top+10+150+10 {
+		t.Errorf("Wrong position after the image %f", c.context.Y)
+	}
+	contents, err := c.pages[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := fmt.Sprintf("2.000000 0.000000 0.000000 2.000000 %f %f cm", c.pageMargins.left,
+		c.pageHeight-c.pageMargins.top-10-150)
+	if !regexp.MustCompile(regexp.QuoteMeta(expected)).MatchString(contents) ||
+		!regexp.MustCompile(regexp.QuoteMeta("/Form1 Do")).MatchString(contents) {
+		t.Errorf("Wrong contents %q", contents)
+	}
+
+	err = c.WriteToFile("/tmp/svg.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestChart(t *testing.T) {
+	ticks, decimals := chartTicks(-3, 17, 5)
+	if fmt.Sprint(ticks) != "[-5 0 5 10 15 20]" || decimals != 0 {
+		t.Errorf("Wrong ticks %v (%d decimals)", ticks, decimals)
+	}
+	ticks, decimals = chartTicks(0, 0.9, 5)
+	if len(ticks) != 6 || decimals != 1 || math.Abs(ticks[5]-1) > 1e-9 {
+		t.Errorf("Wrong ticks %v (%d decimals)", ticks, decimals)
+	}
+
+	c := New()
+	bar := NewBarChart(400, 250)
+	bar.SetTitle("Quarterly revenue")
+	bar.SetCategories("Q1", "Q2", "Q3", "Q4")
+	bar.AddSeries("2017", 12, 15, 9, 17)
+	bar.AddSeries("2018", 14, -3, 11, 16).SetColor(ColorRGBFromHex("#59a14f"))
+	bar.SetMargins(0, 0, 0, 20)
+
+	line := NewLineChart(400, 250)
+	line.SetCategories("Jan", "Feb", "Mar", "Apr", "May")
+	line.AddSeries("Visits", 0.2, 0.45, 0.3, 0.8, 0.65)
+	line.SetMargins(0, 0, 0, 20)
+
+	pie := NewPieChart(300, 250)
+	pie.SetTitle("Market share")
+	pie.SetCategories("A", "B", "C")
+	pie.AddSeries("Share", 50, 30, 20)
+
+	for _, chart := range []*Chart{bar, line, pie} {
+		err := c.Draw(chart)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	if len(c.pages) != 2 {
+		t.Fatalf("Wrong number of pages %d", len(c.pages))
+	}
+
+	contents, err := c.pages[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, expected := range []string{"(Quarterly revenue)", "(Q4)", "(-5)", "(20)", "(2018)", "(Apr)", "(0.8)"} {
+		if !regexp.MustCompile(regexp.QuoteMeta(expected)).MatchString(contents) {
+			t.Errorf("Missing label %q in the contents", expected)
+		}
+	}
+	// The pie chart on the next page.
+	contents, err = c.pages[1].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !regexp.MustCompile(regexp.QuoteMeta("(Market share)")).MatchString(contents) {
+		t.Errorf("Missing pie chart title")
+	}
+
+	err = c.WriteToFile("/tmp/chart.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestBarcode(t *testing.T) {
+	c := New()
+	if _, err := NewEAN13("40063813339"); err == nil {
+		t.Errorf("Invalid EAN-13 number not detected")
+	}
+	if _, err := NewEAN13("4006381333932"); err == nil {
+		t.Errorf("Wrong EAN-13 check digit not detected")
+	}
+	product, err := NewEAN13("400638133393")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if product.Content() != "4006381333931" {
+		t.Errorf("Wrong EAN-13 number %s", product.Content())
+	}
+	// 95 modules and the quiet zones.
+	product.SetWidth(117)
+	if product.Width() != 117 || product.moduleWidth != 1 {
+		t.Errorf("Wrong EAN-13 width %f (module %f)", product.Width(), product.moduleWidth)
+	}
+	product.SetMargins(0, 0, 0, 10)
+
+	shipping, err := NewCode128("SHIP-0012345678")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	shipping.SetModuleWidth(1.5)
+	shipping.SetBarHeight(40)
+	shipping.SetBackgroundColor(ColorWhite)
+	shipping.SetMargins(0, 0, 0, 10)
+
+	link, err := NewQRCode("https://github.com/unidoc/unidoc", qr.M)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	link.SetModuleWidth(4)
+	link.SetQuietZone(2)
+	size := float64(link.cols+4) * 4
+	if link.Width() != size || link.Height() != size {
+		t.Errorf("Wrong QR code size %fx%f", link.Width(), link.Height())
+	}
+
+	for _, b := range []*Barcode{product, shipping, link} {
+		err = c.Draw(b)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	if c.context.Y != c.pageMargins.top+50+10+40+10+size {
+		t.Errorf("Wrong position after the barcodes %f", c.context.Y)
+	}
+
+	contents, err := c.pages[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The first bar of the EAN-13 guard, after the quiet zone.
+	expected := []string{
+		fmt.Sprintf("%f %f 1.000000 50.000000 re", c.pageMargins.left+11, c.pageHeight-c.pageMargins.top-50),
+		fmt.Sprintf("%f %f %f 40.000000 re", c.pageMargins.left, c.pageHeight-c.pageMargins.top-100,
+			shipping.Width()),
+		// The top row of the top left finder pattern of the QR code.
+		fmt.Sprintf("%f %f 28.000000 4.000000 re", c.pageMargins.left+8,
+			c.pageHeight-c.pageMargins.top-110-12),
+	}
+	for _, s := range expected {
+		if !regexp.MustCompile(regexp.QuoteMeta(s)).MatchString(contents) {
+			t.Errorf("Missing %q in contents", s)
+		}
+	}
+
+	err = c.WriteToFile("/tmp/barcode.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestUserUnit(t *testing.T) {
+	// A 1000x500 inch drawing in units of 10 points.
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 7200, Ury: 3600}
+	page.Resources = model.NewPdfPageResources()
+	err := page.SetUserUnit(10)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	block, err := NewBlockFromPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if block.Width() != 72000 || block.Height() != 36000 {
+		t.Errorf("Wrong block size %fx%f", block.Width(), block.Height())
+	}
+
+	c := New()
+	err = c.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if c.context.PageWidth != 72000 || c.context.PageHeight != 36000 {
+		t.Errorf("Wrong page size %fx%f", c.context.PageWidth, c.context.PageHeight)
+	}
+
+	// A 100pt square in the upper left corner, i.e. 10 units.
+	rect := NewRectangle(0, 0, 100, 100)
+	rect.SetFillColor(ColorRed)
+	rect.SetBorderWidth(0)
+	err = c.Draw(rect)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	opt := render.NewOptions()
+	opt.DPI = 0.72
+	img, err := render.RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// 1 pixel per 100 points.
+	if img.Bounds().Dx() != 720 || img.Bounds().Dy() != 360 {
+		t.Fatalf("Wrong image size %v", img.Bounds())
+	}
+	if r, g, _, _ := img.At(0, 0).RGBA(); r != 0xffff || g != 0 {
+		t.Errorf("Square not drawn at the corner")
+	}
+	if r, g, _, _ := img.At(1, 1).RGBA(); r != 0xffff || g != 0xffff {
+		t.Errorf("Square larger than 100pt")
+	}
+}
+
+func TestHeadingOutlines(t *testing.T) {
+	c := New()
+	c.SetEnableOutlines(true)
+	heading := func(level int, text string) *Heading {
+		style := NewTextStyle()
+		style.FontSize = 20 - 2*float64(level)
+		return NewHeading(level, NewStyledParagraph(text, style))
+	}
+
+	for _, d := range []Drawable{
+		heading(1, "Introduction"), heading(2, "Scope  and\naudience"), heading(3, "Readers"),
+		heading(2, "Conventions"), NewPageBreak(), heading(1, "Usage"),
+	} {
+		err := c.Draw(d)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+
+	// A heading not fitting at the bottom of a page is on the next page.
+	p := NewParagraph("Filler")
+	p.SetMargins(0, 0, c.Context().Height-20, 0)
+	err := c.Draw(p)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	last := heading(1, "Appendix")
+	err = c.Draw(last)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if last.page != 3 || last.y != c.pageMargins.top {
+		t.Errorf("Wrong heading position: page %d at %f", last.page, last.y)
+	}
+
+	err = c.WriteToFile("/tmp/heading_outlines.pdf")
+	if err != nil {
+		t.Fatalf("Fail: %v\n", err)
+	}
+	f, err := os.Open("/tmp/heading_outlines.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, titles, err := reader.GetOutlinesFlattened()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The flattened tree lists the children of each node after its items, nested by level.
+	expected := []string{"+", "  Introduction", "  Usage", "  Appendix", "  +", "    Scope and audience",
+		"    Conventions", "    +", "      Readers"}
+	if len(titles) != len(expected) {
+		t.Fatalf("Wrong outline items %q", titles)
+	}
+	for i, title := range titles {
+		if title != expected[i] {
+			t.Errorf("Outline item %d: %q, expected %q", i, title, expected[i])
+		}
+	}
+
+	root := reader.GetOutlineTree().ToPdfObject().(*core.PdfIndirectObject).PdfObject.(*core.PdfObjectDictionary)
+	first := core.TraceToDirectObject(root.Get("First")).(*core.PdfObjectDictionary)
+	if n, ok := first.Get("Count").(*core.PdfObjectInteger); !ok || *n != 3 {
+		t.Errorf("Wrong count of the nested headings %v", first.Get("Count"))
+	}
+}
+
+func TestBlockTransforms(t *testing.T) {
+	tests := []struct {
+		transform func(blk *Block)
+		bbox      [4]float64
+	}{
+		{func(blk *Block) {}, [4]float64{0, 0, 100, 50}},
+		{func(blk *Block) { blk.Rotate(90) }, [4]float64{0, -100, 50, 100}},
+		{func(blk *Block) { blk.SetAngle(-90) }, [4]float64{-50, 0, 50, 100}},
+		{func(blk *Block) { blk.RotateAbout(180, 50, 25) }, [4]float64{0, 0, 100, 50}},
+		{func(blk *Block) { blk.ScaleAbout(2, 2, 50, 25) }, [4]float64{-50, -25, 200, 100}},
+		{func(blk *Block) { blk.Skew(45, 0) }, [4]float64{0, 0, 150, 50}},
+		{func(blk *Block) { blk.Rotate(90); blk.SetAngle(0) }, [4]float64{0, 0, 100, 50}},
+	}
+	for i, test := range tests {
+		blk := NewBlock(100, 50)
+		test.transform(blk)
+		left, top, w, h := blk.BoundingBox()
+		for j, v := range []float64{left, top, w, h} {
+			if math.Abs(v-test.bbox[j]) > 1e-9 {
+				t.Errorf("%d: Wrong bounding box %v, expected %v", i, []float64{left, top, w, h}, test.bbox)
+				break
+			}
+		}
+	}
+}
+
+func TestTransformedPlacement(t *testing.T) {
+	c := New()
+	c.SetPageSize(PageSize{200, 200})
+	c.SetPageMargins(0, 0, 0, 0)
+
+	// A red 100x50 block rotated upright, occupying a 50x100 box in the flow.
+	blk := NewBlock(100, 50)
+	rect := NewRectangle(0, 0, 100, 50)
+	rect.SetFillColor(ColorRed)
+	rect.SetBorderWidth(0)
+	err := blk.Draw(rect)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	blk.Rotate(90)
+	err = c.Draw(blk)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if c.Context().Y != 100 {
+		t.Errorf("Wrong position after the block %v", c.Context().Y)
+	}
+
+	// Not fitting in the remaining 100pt, the second one is on the next page.
+	inner := NewBlock(100, 50)
+	err = inner.Draw(rect)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	rotated := NewTransformed(inner)
+	rotated.RotateAbout(90, 50, 25)
+	if rotated.Width() != 50 || rotated.Height() != 100 {
+		t.Errorf("Wrong size %vx%v", rotated.Width(), rotated.Height())
+	}
+	p := NewParagraph("Filler")
+	p.SetMargins(0, 0, 10, 0)
+	err = c.Draw(p)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = c.Draw(rotated)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(c.pages) != 2 || c.Context().Y != 100 {
+		t.Fatalf("Wrong placement on page %d at %v", len(c.pages), c.Context().Y)
+	}
+
+	for i, page := range c.pages {
+		opt := render.NewOptions()
+		opt.DPI = 72
+		img, err := render.RenderPage(page, opt)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		for _, pt := range []struct {
+			x, y int
+			red  bool
+		}{{25, 50, true}, {45, 95, true}, {75, 25, false}, {25, 105, false}} {
+			r, g, _, _ := img.At(pt.x, pt.y).RGBA()
+			if red := r == 0xffff && g == 0; red != pt.red {
+				t.Errorf("Page %d: wrong color at (%d, %d)", i+1, pt.x, pt.y)
+			}
+		}
+	}
+}
+
+func TestTableTransformedHeader(t *testing.T) {
+	table := NewTable(2)
+	for _, text := range []string{"Quarter", "Revenue"} {
+		p := NewParagraph(text)
+		p.SetEnableWrap(false)
+		header := NewTransformed(p)
+		header.Rotate(90)
+		cell := table.NewCell()
+		cell.SetBorder(CellBorderStyleBox, 1)
+		err := cell.SetContent(header)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if header.Height() < 30 {
+			t.Errorf("Rotated header height %v less than its text width", header.Height())
+		}
+	}
+	for _, text := range []string{"Q1", "1000"} {
+		cell := table.NewCell()
+		cell.SetContent(NewParagraph(text))
+	}
+
+	c := New()
+	err := c.Draw(table)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if table.rowHeights[0] < 30 {
+		t.Errorf("Header row height %v less than the rotated text", table.rowHeights[0])
+	}
+
+	err = c.WriteToFile("/tmp/table_transformed_header.pdf")
+	if err != nil {
+		t.Fatalf("Fail: %v\n", err)
+	}
+}
+
+func TestImageFitAndDPI(t *testing.T) {
+	// A 200x100 image: blue on the left half, red on the right half.
+	goimg := goimage.NewRGBA(goimage.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			c := color.RGBA{B: 255, A: 255}
+			if x >= 100 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			goimg.Set(x, y, c)
+		}
+	}
+	img, err := NewImageFromGoImage(goimg)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	img.SetDPI(144)
+	if img.Width() != 100 || img.Height() != 50 {
+		t.Errorf("Wrong size at 144 DPI %vx%v", img.Width(), img.Height())
+	}
+
+	tests := []struct {
+		fit    ImageFit
+		angle  float64
+		pixels map[[2]int]string // Colors of the pixels of the page: (b)lue, (r)ed or (w)hite.
+		y      float64           // Position after the image.
+	}{
+		{ImageFitStretch, 0, map[[2]int]string{{10, 5}: "b", {90, 95}: "r", {10, 105}: "w"}, 100},
+		{ImageFitContain, 0, map[[2]int]string{{10, 10}: "w", {10, 30}: "b", {90, 70}: "r", {10, 90}: "w"}, 100},
+		{ImageFitCover, 0, map[[2]int]string{{10, 5}: "b", {40, 95}: "b", {60, 5}: "r", {110, 5}: "w"}, 100},
+		{ImageFitStretch, 90, map[[2]int]string{{10, 5}: "r", {90, 95}: "b", {10, 105}: "w"}, 100},
+	}
+	for i, test := range tests {
+		c := New()
+		c.SetPageSize(PageSize{200, 200})
+		c.SetPageMargins(0, 0, 0, 0)
+		img.Fit(test.fit, 100, 100)
+		img.SetAngle(test.angle)
+		err = c.Draw(img)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if c.Context().Y != test.y {
+			t.Errorf("%d: Wrong position after the image %v", i, c.Context().Y)
+		}
+
+		opt := render.NewOptions()
+		opt.DPI = 72
+		page, err := render.RenderPage(c.pages[0], opt)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		for pt, expected := range test.pixels {
+			r, g, b, _ := page.At(pt[0], pt[1]).RGBA()
+			got := "?"
+			switch {
+			case r == 0xffff && g == 0xffff && b == 0xffff:
+				got = "w"
+			case r > 0xf000 && g < 0x1000 && b < 0x1000:
+				got = "r"
+			case b > 0xf000 && r < 0x1000 && g < 0x1000:
+				got = "b"
+			}
+			if got != expected {
+				t.Errorf("%d: Wrong color at %v: %s, expected %s", i, pt, got, expected)
+			}
+		}
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	c := New()
+	c.NewPage()
+	x0, y0 := c.context.X, c.context.Y
+
+	// Relative fields one below the other, absolute fields and radio buttons drawn on a block.
+	err := c.Draw(NewTextField("name", 200, 20, model.TextFieldOptions{Value: "Jane Doe"}))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = c.Draw(NewComboBox("country", 100, 20, model.ComboboxFieldOptions{Options: []string{"France", "Germany"}}))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkbox := NewCheckbox("agree", 12, model.CheckboxFieldOptions{Checked: true})
+	checkbox.SetPos(300, 100)
+	if err = c.Draw(checkbox); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	group := NewRadioGroup("size", model.RadioGroupFieldOptions{Value: "L"})
+	blk := NewBlock(100, 20)
+	for i, value := range []string{"S", "M", "L"} {
+		button := group.NewButton(value, 12)
+		button.SetPos(20*float64(i), 0)
+		if err = blk.Draw(button); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	blk.SetPos(300, 200)
+	if err = c.Draw(blk); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// A field not fitting on the page goes on the next page.
+	if err = c.Draw(NewBlock(10, c.context.Height-30)); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = c.Draw(NewSignatureField("signature", 150, 50)); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if err = c.WriteToFile("/tmp/form_fields.pdf"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if c.acroForm == nil || c.acroForm.Fields == nil || len(*c.acroForm.Fields) != 5 {
+		t.Fatalf("Wrong form fields")
+	}
+	if len(c.pages) != 2 || len(c.pages[0].Annotations) != 6 || len(c.pages[1].Annotations) != 1 {
+		t.Fatalf("Wrong widgets")
+	}
+
+	expected := []model.PdfRectangle{
+		{Llx: x0, Lly: 792 - y0 - 20, Urx: x0 + 200, Ury: 792 - y0},
+		{Llx: x0, Lly: 792 - y0 - 40, Urx: x0 + 100, Ury: 792 - y0 - 20},
+		{Llx: 300, Lly: 792 - 112, Urx: 312, Ury: 792 - 100},
+		{Llx: 300, Lly: 792 - 212, Urx: 312, Ury: 792 - 200},
+		{Llx: 320, Lly: 792 - 212, Urx: 332, Ury: 792 - 200},
+		{Llx: 340, Lly: 792 - 212, Urx: 352, Ury: 792 - 200},
+	}
+	for i, exp := range expected {
+		rect, err := model.NewPdfRectangle(*c.pages[0].Annotations[i].Rect.(*core.PdfObjectArray))
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if math.Abs(rect.Llx-exp.Llx) > 0.01 || math.Abs(rect.Lly-exp.Lly) > 0.01 ||
+			math.Abs(rect.Urx-exp.Urx) > 0.01 || math.Abs(rect.Ury-exp.Ury) > 0.01 {
+			t.Errorf("Widget %d rectangle %v, expected %v", i, *rect, exp)
+		}
+	}
+
+	// The form is written with the document.
+	f, err := os.Open("/tmp/form_fields.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if reader.AcroForm == nil {
+		t.Fatalf("Missing form")
+	}
+	names := []string{}
+	for _, field := range reader.AcroForm.AllFields() {
+		names = append(names, field.GetFullName())
+	}
+	if fmt.Sprint(names) != "[name country agree size signature]" {
+		t.Errorf("Wrong fields %v", names)
+	}
+}
+
+func TestFontFamily(t *testing.T) {
+	// Missing faces fall back to the closest face.
+	family := NewFontFamily(fonts.NewFontHelvetica(), fonts.NewFontHelveticaBold(), nil, nil)
+	for _, tc := range []struct {
+		bold, italic bool
+		expected     string
+	}{
+		{false, false, "Helvetica"},
+		{true, false, "Helvetica-Bold"},
+		{false, true, "Helvetica"},
+		{true, true, "Helvetica-Bold"},
+	} {
+		if name := fontName(family.Face(tc.bold, tc.italic)); name != tc.expected {
+			t.Errorf("Face bold %v italic %v: %s, expected %s", tc.bold, tc.italic, name, tc.expected)
+		}
+	}
+	if NewFontFamily(nil, nil, nil, nil).Face(true, true) != nil {
+		t.Errorf("Face of an empty family")
+	}
+
+	family, err := NewFontFamilyFromTTFFiles(testRobotoRegularTTFFile, testRobotoBoldTTFFile, "", "")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if name := fontName(family.Face(true, true)); name != "Roboto-Bold" {
+		t.Errorf("Wrong bold italic face %s", name)
+	}
+	if _, err = NewFontFamilyFromTTFFiles("missing.ttf", "", "", ""); err == nil {
+		t.Errorf("Missing error for a missing font file")
+	}
+
+	// The style toggles select the face of the family.
+	c := New()
+	style := NewTextStyle()
+	style.Family = NewTimesFamily()
+	p := NewStyledParagraph("Regular, ", style)
+	p.Append("bold, ").Style.Bold = true
+	p.Append("italic, ").Style.Italic = true
+	chunk := p.Append("bold italic")
+	chunk.Style.Bold, chunk.Style.Italic = true, true
+	if err = c.Draw(p); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	resources := c.pages[0].Resources
+	used := map[string]bool{}
+	for _, name := range []string{"Font1", "Font2", "Font3", "Font4"} {
+		font, has := resources.GetFontByName(core.PdfObjectName(name))
+		if !has {
+			t.Fatalf("Missing font %s", name)
+		}
+		dict := core.TraceToDirectObject(font).(*core.PdfObjectDictionary)
+		used[dict.Get("BaseFont").String()] = true
+	}
+	for _, name := range []string{"Times-Roman", "Times-Bold", "Times-Italic", "Times-BoldItalic"} {
+		if !used[name] {
+			t.Errorf("Face %s not used (%v)", name, used)
+		}
+	}
+}
+
+func TestWriteStream(t *testing.T) {
+	passes := 0
+	generate := func(c *Creator) error {
+		passes++
+		c.SetEnableOutlines(true)
+		c.DrawFooter(func(footer *Block, args FooterFunctionArgs) {
+			p := NewParagraph(fmt.Sprintf("Page %d of %d", args.PageNum, args.TotalPages))
+			p.SetPos(footer.Width()-100, 10)
+			footer.Draw(p)
+		})
+		for i := 1; i <= 3; i++ {
+			if i > 1 {
+				c.NewPage()
+			}
+			if err := c.Draw(NewHeading(1, NewStyledParagraph(fmt.Sprintf("Section %d", i), NewTextStyle()))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Written to a writer which cannot seek.
+	var buf bytes.Buffer
+	if err := WriteStream(&buf, generate); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if passes != 2 {
+		t.Errorf("Generated %d times, expected 2", passes)
+	}
+
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if numPages != 3 {
+		t.Fatalf("%d pages, expected 3", numPages)
+	}
+	for i := 1; i <= numPages; i++ {
+		page, err := reader.GetPage(i)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		e, err := extractor.New(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		text, err := e.ExtractText()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		for _, expected := range []string{fmt.Sprintf("Section %d", i), fmt.Sprintf("Page %d of 3", i)} {
+			if !strings.Contains(text, expected) {
+				t.Errorf("Page %d: missing %q in %q", i, expected, text)
+			}
+		}
+	}
+	_, titles, err := reader.GetOutlinesFlattened()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The outline tree root and the headings.
+	if len(titles) != 4 || !strings.HasSuffix(titles[3], "Section 3") {
+		t.Errorf("Wrong outlines %v", titles)
+	}
+
+	// Tables of contents need all the pages.
+	err = WriteStream(&buf, func(c *Creator) error {
+		c.CreateDefaultTableOfContents("Contents")
+		return nil
+	})
+	if err == nil {
+		t.Errorf("Missing error for a table of contents")
+	}
+}
+
+func TestLineStyles(t *testing.T) {
+	c := New()
+	line := NewLine(100, 100, 300, 100)
+	line.SetLineWidth(2)
+	line.SetDashPattern([]float64{6, 3}, 0)
+	line.SetLineCap(LineCapRound)
+	line.SetLineJoin(LineJoinBevel)
+	line.SetArrows(ArrowOpen, ArrowClosed)
+	line.SetArrowSize(10, 8)
+	if err := c.Draw(line); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	contents, err := c.pages[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The dashed line stops at the base of the closed arrowhead, the arrowheads are solid.
+	for _, expected := range []string{
+		"1 J\n2 j\n[6.000000 3.000000] 0.000000 d\n",
+		"100.000000 692.000000 m\n290.000000 692.000000 l\nS\n[] 0 d\n",
+		"110.000000 696.000000 m\n100.000000 692.000000 l\n110.000000 688.000000 l\nS\n",
+		"290.000000 696.000000 m\n300.000000 692.000000 l\n290.000000 688.000000 l\nh\nf\n",
+	} {
+		if !strings.Contains(contents, expected) {
+			t.Errorf("Missing %q in %q", expected, contents)
+		}
+	}
+
+	err = c.WriteToFile("/tmp/line_styles.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestParagraphShrinkToFit(t *testing.T) {
+	// A name on a badge: a single line shrunk to the width of the box.
+	name := NewParagraph("Maximilian Alexander Longname")
+	name.SetEnableWrap(false)
+	if err := name.SetShrinkToFit(20, 10); err == nil {
+		t.Errorf("Invalid font size range accepted")
+	}
+	if err := name.SetShrinkToFit(8, 24); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	name.SetWidth(200)
+	if name.fontSize >= 24 || name.fontSize < 8 || name.getTextWidth() > 200*1000 {
+		t.Errorf("Not shrunk to fit: font size %f, width %f", name.fontSize, name.getTextWidth()/1000)
+	}
+	short := NewParagraph("Ann")
+	short.SetEnableWrap(false)
+	short.SetShrinkToFit(8, 24)
+	short.SetWidth(200)
+	if short.fontSize != 24 {
+		t.Errorf("Short text shrunk to %f", short.fontSize)
+	}
+
+	// Wrapped text truncated with an ellipsis in a box of 2 lines at the minimum size.
+	p := NewParagraph(strings.Repeat("lorem ipsum dolor sit amet ", 20))
+	p.SetShrinkToFit(10, 12)
+	p.SetMaxHeight(20)
+	p.SetEllipsis(true)
+	p.SetWidth(100)
+	if p.fontSize != 10 || len(p.textLines) != 2 {
+		t.Fatalf("Wrong fit: font size %f, lines %q", p.fontSize, p.textLines)
+	}
+	last := p.textLines[1]
+	if !strings.HasSuffix(last, "…") || p.getLineWidth(last) > 100*1000 {
+		t.Errorf("Last line not ellipsized: %q", last)
+	}
+
+	// Drawn in a fixed size block.
+	block := NewBlock(100, 20)
+	if err := block.Draw(p); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	c := New()
+	block.SetPos(50, 50)
+	if err := c.Draw(block); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := c.WriteToFile("/tmp/paragraph_fit.pdf"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestPrintColors(t *testing.T) {
+	c := New()
+	spot := c.RegisterSpotColor("PANTONE 185 C", ColorCMYKFromPercent(0, 91, 76, 0))
+	if sc, has := c.GetSpotColor("PANTONE 185 C"); !has || sc != spot {
+		t.Fatalf("Spot color not registered")
+	}
+
+	p := NewParagraph("Cyan text")
+	p.SetColor(ColorCMYKFromArithmetic(1, 0, 0, 0))
+	if err := c.Draw(p); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	rect := NewRectangle(100, 100, 200, 50)
+	rect.SetFillColor(spot.Tint(0.5))
+	rect.SetBorderColor(ColorCMYKFromArithmetic(0, 0, 0, 1))
+	if err := c.Draw(rect); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	style := NewTextStyle()
+	style.Color = spot
+	sp := NewStyledParagraph("Spot text", style)
+	if err := c.Draw(sp); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	page := c.pages[0]
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, expected := range []string{
+		`1\.0+ 0\.0+ 0\.0+ 0\.0+ k`,
+		`0\.0+ 0\.0+ 0\.0+ 1\.0+ K`,
+		`/CSPANTONE#20185#20C cs\s+0\.50+ scn`,
+		`/CSPANTONE#20185#20C cs\s+1\.0+ scn`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(contents) {
+			t.Errorf("Missing %q in the contents", expected)
+		}
+	}
+
+	// A single Separation color space resource for the spot color.
+	cs, has := page.Resources.GetColorspaceByName("CSPANTONE 185 C")
+	if !has {
+		t.Fatalf("Missing spot color space")
+	}
+	sep, ok := cs.(*model.PdfColorspaceSpecialSeparation)
+	if !ok || string(*sep.ColorantName) != "PANTONE 185 C" {
+		t.Fatalf("Wrong spot color space %v", cs)
+	}
+	if _, ok := sep.AlternateSpace.(*model.PdfColorspaceDeviceCMYK); !ok {
+		t.Errorf("Wrong alternate color space %v", sep.AlternateSpace)
+	}
+	if len(page.Resources.ColorSpace.Names) != 1 {
+		t.Errorf("Wrong color spaces %v", page.Resources.ColorSpace.Names)
+	}
+
+	// The RGB approximations.
+	r, g, b := spot.Tint(0.5).ToRGB()
+	if math.Abs(r-1) > 1e-6 || math.Abs(g-0.545) > 1e-6 || math.Abs(b-0.62) > 1e-6 {
+		t.Errorf("Wrong tint RGB %f %f %f", r, g, b)
+	}
+
+	if err := c.WriteToFile("/tmp/print_colors.pdf"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestTagged(t *testing.T) {
+	c := New()
+	c.SetTagged(true)
+	c.SetRoleMap(map[string]string{"Title": "H1"})
+	c.DrawFooter(func(footer *Block, args FooterFunctionArgs) {
+		footer.Draw(NewParagraph(fmt.Sprintf("Page %d", args.PageNum)))
+	})
+
+	if err := c.DrawTagged(NewParagraph("Report"), "Title", ""); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := c.Draw(NewHeading(2, NewStyledParagraph("Summary", NewTextStyle()))); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := c.Draw(NewRectangle(100, 100, 200, 50)); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	c.NewPage()
+	if err := c.DrawTagged(NewParagraph("Chart"), "Figure", "Sales by month"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	contents, err := reader.PageList[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, expected := range []string{
+		`/Title <<\s*/MCID 0\s*>> BDC`,
+		`/H2 <<\s*/MCID 1\s*>> BDC`,
+		`/Artifact BMC`,
+		`/Artifact <<\s*/Type /Pagination\s*>> BDC`,
+		`EMC`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(contents) {
+			t.Errorf("Missing %q in the contents", expected)
+		}
+	}
+
+	root, err := reader.GetStructTreeRoot()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if root == nil || len(root.K) != 1 || root.K[0].S != "Document" || root.RoleMap["Title"] != "H1" {
+		t.Fatalf("Wrong structure tree %+v", root)
+	}
+	kids := root.K[0].Kids
+	if len(kids) != 3 || kids[0].Element.S != "Title" || kids[1].Element.S != "H2" ||
+		kids[2].Element.Alt != "Sales by month" {
+		t.Fatalf("Wrong elements %+v", kids)
+	}
+	figure := kids[2].Element.Kids
+	if len(figure) != 1 || figure[0].Page != reader.PageList[1] || figure[0].MCID != 0 {
+		t.Errorf("Wrong marked content %+v", figure)
+	}
+}
+
+func TestFormTabOrder(t *testing.T) {
+	c := New()
+	c.SetTabOrder(model.TabOrderRow)
+	// Drawn in columns: the fields of the second column first.
+	for i, pos := range [][2]float64{{300, 100}, {300, 150}, {50, 100}, {50, 150}} {
+		field := NewTextField(fmt.Sprintf("field%d", i), 100, 20, model.TextFieldOptions{})
+		field.SetPos(pos[0], pos[1])
+		if err := c.Draw(field); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page := reader.PageList[0]
+	if page.GetTabOrder() != model.TabOrderRow {
+		t.Errorf("Wrong tab order %q", page.GetTabOrder())
+	}
+	lefts := []float64{}
+	for _, annot := range page.Annotations {
+		rect, err := model.NewPdfRectangle(*annot.Rect.(*core.PdfObjectArray))
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		lefts = append(lefts, rect.Llx)
+	}
+	if fmt.Sprint(lefts) != "[50 300 50 300]" {
+		t.Errorf("Wrong widget order %v", lefts)
+	}
+}
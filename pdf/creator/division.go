@@ -34,13 +34,15 @@ func NewDivision() *Division {
 }
 
 // Add adds a VectorDrawable to the Division container.
-// Currently supported VectorDrawables: *Paragraph, *Image.
+// Currently supported VectorDrawables: *Paragraph, *StyledParagraph, *Image.
 func (div *Division) Add(d VectorDrawable) error {
 	supported := false
 
 	switch d.(type) {
 	case *Paragraph:
 		supported = true
+	case *StyledParagraph:
+		supported = true
 	case *Image:
 		supported = true
 	}
@@ -65,6 +67,10 @@ func (div *Division) Height() float64 {
 			p := t
 			compWidth += p.margins.left + p.margins.right
 			compHeight += p.margins.top + p.margins.bottom
+		case *StyledParagraph:
+			p := t
+			compWidth += p.margins.left + p.margins.right
+			compHeight += p.margins.top + p.margins.bottom
 		}
 
 		// Vertical stacking.
@@ -6,8 +6,8 @@
 package creator
 
 import (
+	"github.com/unidoc/unidoc/pdf/contentstream"
 	"github.com/unidoc/unidoc/pdf/contentstream/draw"
-	"github.com/unidoc/unidoc/pdf/model"
 )
 
 // Ellipse defines an ellipse with a center at (xc,yc) and a specified width and height.  The ellipse can have a colored
@@ -18,8 +18,8 @@ type Ellipse struct {
 	yc          float64
 	width       float64
 	height      float64
-	fillColor   *model.PdfColorDeviceRGB
-	borderColor *model.PdfColorDeviceRGB
+	fillColor   Color
+	borderColor Color
 	borderWidth float64
 }
 
@@ -32,7 +32,7 @@ func NewEllipse(xc, yc, width, height float64) *Ellipse {
 	ell.width = width
 	ell.height = height
 
-	ell.borderColor = model.NewPdfColorDeviceRGB(0, 0, 0)
+	ell.borderColor = ColorBlack
 	ell.borderWidth = 1.0
 
 	return ell
@@ -50,18 +50,22 @@ func (ell *Ellipse) SetBorderWidth(bw float64) {
 
 // SetBorderColor sets the border color.
 func (ell *Ellipse) SetBorderColor(col Color) {
-	ell.borderColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	ell.borderColor = col
 }
 
 // SetFillColor sets the fill color.
 func (ell *Ellipse) SetFillColor(col Color) {
-	ell.fillColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	ell.fillColor = col
 }
 
 // GeneratePageBlocks draws the rectangle on a new block representing the page.
 func (ell *Ellipse) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
 
+	// Set the colors before drawing the shape, which then uses the current colors.
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+
 	drawell := draw.Circle{
 		X:           ell.xc - ell.width/2,
 		Y:           ctx.PageHeight - ell.yc - ell.height/2,
@@ -72,11 +76,11 @@ func (ell *Ellipse) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 	}
 	if ell.fillColor != nil {
 		drawell.FillEnabled = true
-		drawell.FillColor = ell.fillColor
+		addColorOperators(cc, block, ell.fillColor, false)
 	}
 	if ell.borderColor != nil {
 		drawell.BorderEnabled = true
-		drawell.BorderColor = ell.borderColor
+		addColorOperators(cc, block, ell.borderColor, true)
 		drawell.BorderWidth = ell.borderWidth
 	}
 
@@ -85,7 +89,7 @@ func (ell *Ellipse) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 		return nil, ctx, err
 	}
 
-	err = block.addContentsByString(string(contents))
+	err = block.addContentsByString(cc.String() + string(contents) + "Q")
 	if err != nil {
 		return nil, ctx, err
 	}
@@ -16,10 +16,10 @@ import (
 type FilledCurve struct {
 	curves        []draw.CubicBezierCurve
 	FillEnabled   bool // Show fill?
-	fillColor     *pdf.PdfColorDeviceRGB
+	fillColor     Color
 	BorderEnabled bool // Show border?
 	BorderWidth   float64
-	borderColor   *pdf.PdfColorDeviceRGB
+	borderColor   Color
 }
 
 // NewFilledCurve returns a instance of filled curve.
@@ -37,17 +37,17 @@ func (fc *FilledCurve) AppendCurve(curve draw.CubicBezierCurve) *FilledCurve {
 
 // SetFillColor sets the fill color for the path.
 func (fc *FilledCurve) SetFillColor(color Color) {
-	fc.fillColor = pdf.NewPdfColorDeviceRGB(color.ToRGB())
+	fc.fillColor = color
 }
 
 // SetBorderColor sets the border color for the path.
 func (fc *FilledCurve) SetBorderColor(color Color) {
-	fc.borderColor = pdf.NewPdfColorDeviceRGB(color.ToRGB())
+	fc.borderColor = color
 }
 
-// draw draws the filled curve. Can specify a graphics state (gsName) for setting opacity etc. Otherwise leave empty ("").
-// Returns the content stream as a byte array, the bounding box and an error on failure.
-func (fc *FilledCurve) draw(gsName string) ([]byte, *pdf.PdfRectangle, error) {
+// draw draws the filled curve on the block. Can specify a graphics state (gsName) for setting opacity etc. Otherwise
+// leave empty (""). Returns the content stream as a byte array, the bounding box and an error on failure.
+func (fc *FilledCurve) draw(blk *Block, gsName string) ([]byte, *pdf.PdfRectangle, error) {
 	bpath := draw.NewCubicBezierPath()
 	for _, c := range fc.curves {
 		bpath = bpath.AppendCurve(c)
@@ -56,11 +56,13 @@ func (fc *FilledCurve) draw(gsName string) ([]byte, *pdf.PdfRectangle, error) {
 	creator := pdfcontent.NewContentCreator()
 	creator.Add_q()
 
-	if fc.FillEnabled {
-		creator.Add_rg(fc.fillColor.R(), fc.fillColor.G(), fc.fillColor.B())
+	if fc.FillEnabled && fc.fillColor != nil {
+		addColorOperators(creator, blk, fc.fillColor, false)
 	}
 	if fc.BorderEnabled {
-		creator.Add_RG(fc.borderColor.R(), fc.borderColor.G(), fc.borderColor.B())
+		if fc.borderColor != nil {
+			addColorOperators(creator, blk, fc.borderColor, true)
+		}
 		creator.Add_w(fc.BorderWidth)
 	}
 	if len(gsName) > 1 {
@@ -103,7 +105,7 @@ func (fc *FilledCurve) draw(gsName string) ([]byte, *pdf.PdfRectangle, error) {
 func (fc *FilledCurve) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
 
-	contents, _, err := fc.draw("")
+	contents, _, err := fc.draw(block, "")
 	err = block.addContentsByString(string(contents))
 	if err != nil {
 		return nil, ctx, err
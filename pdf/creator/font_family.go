@@ -0,0 +1,86 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// FontFamily is a set of faces of a font: regular, bold, italic and bold italic.  Text styles using a family select
+// the face with their Bold and Italic toggles.  Missing faces fall back to the closest face of the family: bold
+// italic to bold then italic, bold and italic to regular.
+type FontFamily struct {
+	Regular    fonts.Font
+	Bold       fonts.Font
+	Italic     fonts.Font
+	BoldItalic fonts.Font
+}
+
+// NewFontFamily creates a font family from its faces, nil for the missing ones.
+func NewFontFamily(regular, bold, italic, boldItalic fonts.Font) *FontFamily {
+	return &FontFamily{
+		Regular:    regular,
+		Bold:       bold,
+		Italic:     italic,
+		BoldItalic: boldItalic,
+	}
+}
+
+// NewFontFamilyFromTTFFiles creates a font family loading its faces from TrueType font files, an empty path for the
+// missing faces.
+func NewFontFamilyFromTTFFiles(regular, bold, italic, boldItalic string) (*FontFamily, error) {
+	faces := []fonts.Font{nil, nil, nil, nil}
+	for i, path := range []string{regular, bold, italic, boldItalic} {
+		if path == "" {
+			continue
+		}
+		font, err := model.NewPdfFontFromTTFFile(path)
+		if err != nil {
+			return nil, err
+		}
+		faces[i] = font
+	}
+	return NewFontFamily(faces[0], faces[1], faces[2], faces[3]), nil
+}
+
+// NewHelveticaFamily returns the family of the standard Helvetica fonts.
+func NewHelveticaFamily() *FontFamily {
+	return NewFontFamily(fonts.NewFontHelvetica(), fonts.NewFontHelveticaBold(), fonts.NewFontHelveticaOblique(),
+		fonts.NewFontHelveticaBoldOblique())
+}
+
+// NewTimesFamily returns the family of the standard Times fonts.
+func NewTimesFamily() *FontFamily {
+	return NewFontFamily(fonts.NewFontTimesRoman(), fonts.NewFontTimesBold(), fonts.NewFontTimesItalic(),
+		fonts.NewFontTimesBoldItalic())
+}
+
+// NewCourierFamily returns the family of the standard Courier fonts.
+func NewCourierFamily() *FontFamily {
+	return NewFontFamily(fonts.NewFontCourier(), fonts.NewFontCourierBold(), fonts.NewFontCourierOblique(),
+		fonts.NewFontCourierBoldOblique())
+}
+
+// Face returns the face of the family for the style, or the closest face if missing.  Nil if the family has no
+// faces.
+func (f *FontFamily) Face(bold, italic bool) fonts.Font {
+	candidates := []fonts.Font{f.Regular, f.Bold, f.Italic}
+	switch {
+	case bold && italic:
+		candidates = []fonts.Font{f.BoldItalic, f.Bold, f.Italic, f.Regular}
+	case bold:
+		candidates = []fonts.Font{f.Bold, f.Regular, f.BoldItalic}
+	case italic:
+		candidates = []fonts.Font{f.Italic, f.Regular, f.BoldItalic}
+	}
+	for _, font := range append(candidates, f.Regular, f.Bold, f.Italic, f.BoldItalic) {
+		if font != nil {
+			return font
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// addFieldFunc adds a field, or a widget of a field, to the form at the rectangle of the page.
+type addFieldFunc func(form *model.PdfAcroForm, page *model.PdfPage, rect model.PdfRectangle) error
+
+// FormField is a field of an interactive form: a text field, check box, radio button, combo box or signature field,
+// with a widget of fixed size.  The field is added to the form of the document when drawn, the form being created if
+// not set by Creator.SetForms.  Each field must be drawn once, except radio buttons which are the widgets of their
+// group.
+// Implements the Drawable interface.
+type FormField struct {
+	add addFieldFunc
+
+	width, height float64
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos, yPos float64
+}
+
+// NewTextField creates a text field with the name (partial name, without periods) and the widget size.
+func NewTextField(name string, width, height float64, opt model.TextFieldOptions) *FormField {
+	return &FormField{
+		add: func(form *model.PdfAcroForm, page *model.PdfPage, rect model.PdfRectangle) error {
+			_, err := form.AddTextField(page, name, rect, opt)
+			return err
+		},
+		width:  width,
+		height: height,
+	}
+}
+
+// NewCheckbox creates a check box with the name and the widget size.
+func NewCheckbox(name string, size float64, opt model.CheckboxFieldOptions) *FormField {
+	return &FormField{
+		add: func(form *model.PdfAcroForm, page *model.PdfPage, rect model.PdfRectangle) error {
+			_, err := form.AddCheckboxField(page, name, rect, opt)
+			return err
+		},
+		width:  size,
+		height: size,
+	}
+}
+
+// NewComboBox creates a combo box with the name and the widget size.
+func NewComboBox(name string, width, height float64, opt model.ComboboxFieldOptions) *FormField {
+	return &FormField{
+		add: func(form *model.PdfAcroForm, page *model.PdfPage, rect model.PdfRectangle) error {
+			_, err := form.AddComboboxField(page, name, rect, opt)
+			return err
+		},
+		width:  width,
+		height: height,
+	}
+}
+
+// NewSignatureField creates an unsigned signature field with the name and the widget size.
+func NewSignatureField(name string, width, height float64) *FormField {
+	return &FormField{
+		add: func(form *model.PdfAcroForm, page *model.PdfPage, rect model.PdfRectangle) error {
+			_, err := form.AddSignatureField(page, name, rect)
+			return err
+		},
+		width:  width,
+		height: height,
+	}
+}
+
+// RadioGroup is a group of radio buttons, of which one at most is selected.
+type RadioGroup struct {
+	name string
+	opt  model.RadioGroupFieldOptions
+
+	// The field, once a button is drawn.
+	field *model.PdfField
+}
+
+// NewRadioGroup creates a group of radio buttons with the name.  The buttons are created by NewButton.
+func NewRadioGroup(name string, opt model.RadioGroupFieldOptions) *RadioGroup {
+	return &RadioGroup{name: name, opt: opt}
+}
+
+// NewButton creates a radio button of the group with the value and the widget size.
+func (g *RadioGroup) NewButton(value string, size float64) *FormField {
+	return &FormField{
+		add: func(form *model.PdfAcroForm, page *model.PdfPage, rect model.PdfRectangle) error {
+			if g.field == nil {
+				field, err := form.AddRadioGroupField(g.name, g.opt)
+				if err != nil {
+					return err
+				}
+				g.field = field
+			}
+			return form.AddRadioButton(g.field, page, rect, value)
+		},
+		width:  size,
+		height: size,
+	}
+}
+
+// SetPos sets the absolute position of the widget.  Changes object positioning to absolute.
+func (f *FormField) SetPos(x, y float64) {
+	f.positioning = positionAbsolute
+	f.xPos = x
+	f.yPos = y
+}
+
+// Width returns the width of the widget.
+func (f *FormField) Width() float64 {
+	return f.width
+}
+
+// Height returns the height of the widget.
+func (f *FormField) Height() float64 {
+	return f.height
+}
+
+// GeneratePageBlocks generates a block with the area of the widget, placed at the current position in relative
+// mode, or on the next page if it does not fit.  Implements the Drawable interface.
+func (f *FormField) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	var blocks []*Block
+	if f.positioning.isRelative() && f.height > ctx.Height {
+		blocks = append(blocks, NewBlock(ctx.PageWidth, ctx.PageHeight))
+		ctx.Page++
+		ctx.X = ctx.Margins.left
+		ctx.Y = ctx.Margins.top
+		ctx.Width = ctx.PageWidth - ctx.Margins.left - ctx.Margins.right
+		ctx.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom
+	}
+
+	blk := NewBlock(f.width, f.height)
+	blk.links = append(blk.links, blockLink{
+		rect:  model.PdfRectangle{Llx: 0, Lly: 0, Urx: f.width, Ury: f.height},
+		field: f.add,
+	})
+	blk.positioning = f.positioning
+	blk.xPos, blk.yPos = f.xPos, f.yPos
+	newBlocks, ctx, err := blk.GeneratePageBlocks(ctx)
+	if err != nil {
+		return nil, ctx, err
+	}
+	return append(blocks, newBlocks...), ctx, nil
+}
+
+// hasFields returns true if the block has form field areas.
+func (blk *Block) hasFields() bool {
+	for _, link := range blk.links {
+		if link.field != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTabOrder sets the order in which the form fields and other annotations of the pages are navigated with the
+// keyboard.  With the row or column order, the annotations of each page are also sorted in that order once the page is
+// complete, so that viewers not applying the tab order of the pages navigate them in the same order.  By default, the
+// annotations are navigated in the order they are drawn.
+func (c *Creator) SetTabOrder(order model.PdfTabOrder) {
+	c.tabOrder = order
+}
+
+// orderAnnotations applies the tab order to the annotations of a complete page.
+func (c *Creator) orderAnnotations(page *model.PdfPage) error {
+	if c.tabOrder == model.TabOrderNone || len(page.Annotations) == 0 {
+		return nil
+	}
+	if c.tabOrder == model.TabOrderRow || c.tabOrder == model.TabOrderColumn {
+		return page.OrderAnnotations(c.tabOrder)
+	}
+	page.SetTabOrder(c.tabOrder)
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// CheckGlyphCoverage checks that every character of the text drawn with Draw, in paragraphs, styled paragraphs,
+// chapter and subchapter headings, divisions, tables and charts, is covered by the font it is drawn with: the text
+// encoding maps it to a glyph the font has metrics for.  Characters which are not covered would be drawn as
+// missing (.notdef) glyphs, or fail to draw.
+// Returns the missing characters, sorted, by font name.  Empty if all text is covered.
+func (c *Creator) CheckGlyphCoverage() map[string][]rune {
+	missing := map[string]map[rune]bool{}
+	for _, d := range c.drawn {
+		collectMissingGlyphs(d, missing)
+	}
+
+	coverage := map[string][]rune{}
+	for name, runes := range missing {
+		list := []rune{}
+		for r := range runes {
+			list = append(list, r)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i] < list[j]
+		})
+		coverage[name] = list
+	}
+	return coverage
+}
+
+// collectMissingGlyphs adds the characters of the text of the drawable which its fonts do not cover, by font name.
+func collectMissingGlyphs(d Drawable, missing map[string]map[rune]bool) {
+	switch t := d.(type) {
+	case *Paragraph:
+		addMissingGlyphs(t.text, t.textFont, t.encoder, missing)
+	case *StyledParagraph:
+		for _, chunk := range t.chunks {
+			addMissingGlyphs(chunk.Text, chunk.Style.font(), t.encoder, missing)
+		}
+	case *Chapter:
+		collectMissingGlyphs(t.heading, missing)
+		for _, content := range t.contents {
+			collectMissingGlyphs(content, missing)
+		}
+	case *Subchapter:
+		collectMissingGlyphs(t.heading, missing)
+		for _, content := range t.contents {
+			collectMissingGlyphs(content, missing)
+		}
+	case *Division:
+		for _, component := range t.components {
+			collectMissingGlyphs(component, missing)
+		}
+	case *Table:
+		for _, cell := range t.cells {
+			if cell.content != nil {
+				collectMissingGlyphs(cell.content, missing)
+			}
+		}
+	case *Chart:
+		// The labels are drawn with the default encoding of styled paragraphs.
+		encoder := textencoding.NewWinAnsiTextEncoder()
+		addMissingGlyphs(t.title, t.titleStyle.font(), encoder, missing)
+		for _, category := range t.categories {
+			addMissingGlyphs(category, t.textStyle.font(), encoder, missing)
+		}
+		for _, s := range t.series {
+			addMissingGlyphs(s.name, t.textStyle.font(), encoder, missing)
+		}
+	}
+}
+
+// addMissingGlyphs adds the characters of the text which the font does not cover with the encoding.
+func addMissingGlyphs(text string, font fonts.Font, encoder textencoding.TextEncoder,
+	missing map[string]map[rune]bool) {
+	if font == nil || encoder == nil {
+		return
+	}
+
+	name := ""
+	for _, r := range text {
+		glyph, found := encoder.RuneToGlyph(r)
+		if found {
+			if glyph == "controlLF" {
+				continue
+			}
+			_, found = font.GetGlyphCharMetrics(glyph)
+		}
+		if found {
+			continue
+		}
+
+		if name == "" {
+			name = fontName(font)
+		}
+		if missing[name] == nil {
+			missing[name] = map[rune]bool{}
+		}
+		missing[name][r] = true
+	}
+}
+
+// fontName returns the name of the font: its base font name, or its type if it has none.
+func fontName(font fonts.Font) string {
+	if dict, ok := core.TraceToDirectObject(font.ToPdfObject()).(*core.PdfObjectDictionary); ok {
+		if name, ok := core.TraceToDirectObject(dict.Get("BaseFont")).(*core.PdfObjectName); ok {
+			return string(*name)
+		}
+	}
+	return fmt.Sprintf("%T", font)
+}
@@ -0,0 +1,401 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// Grid is a container component positioning drawables in a rows X columns layout, e.g. for dashboard-style
+// reports.  The columns share the width of the grid, and the rows its height if set, according to their weights.
+// Unlike Table, the items have no borders or backgrounds and the grid is drawn on a single page.
+type Grid struct {
+	rows int
+	cols int
+
+	// Column and row weights: the share of each in the available width/height.
+	colWeights []float64
+	rowWeights []float64
+
+	// Size of the grid.  When the width is 0 the grid fills the available width, when the height is 0 the rows
+	// are sized to fit their content.
+	width  float64
+	height float64
+
+	// Horizontal and vertical gaps between the columns and rows.
+	colGap float64
+	rowGap float64
+
+	// Items of the grid.
+	items []*GridItem
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos, yPos float64
+
+	// Margins to be applied around the grid when drawing on Page.
+	margins margins
+}
+
+// GridItem is a drawable positioned in a grid, spanning one or several rows and columns.
+type GridItem struct {
+	content VectorDrawable
+
+	// The row and column (1-based) which the item starts from.
+	row, col int
+
+	// Row, column span.
+	rowspan int
+	colspan int
+
+	// Alignment of the content in the item area.
+	horizontalAlignment CellHorizontalAlignment
+	verticalAlignment   CellVerticalAlignment
+}
+
+// NewGrid creates a new Grid with a specified number of rows and columns, all of equal weight.
+func NewGrid(rows, cols int) *Grid {
+	g := &Grid{}
+	g.rows = rows
+	g.cols = cols
+
+	g.colWeights = make([]float64, cols)
+	for i := range g.colWeights {
+		g.colWeights[i] = 1
+	}
+	g.rowWeights = make([]float64, rows)
+	for i := range g.rowWeights {
+		g.rowWeights[i] = 1
+	}
+
+	return g
+}
+
+// SetColumnWeights sets the weights of the columns: each column gets the fraction weight / (sum of weights) of
+// the width available to the columns.
+// The number of weights must match number of columns, otherwise an error is returned.
+func (g *Grid) SetColumnWeights(weights ...float64) error {
+	if err := checkGridWeights(weights, g.cols); err != nil {
+		return err
+	}
+	g.colWeights = weights
+	return nil
+}
+
+// SetRowWeights sets the weights of the rows, sharing the height of the grid when specified with SetHeight.
+// The number of weights must match number of rows, otherwise an error is returned.
+func (g *Grid) SetRowWeights(weights ...float64) error {
+	if err := checkGridWeights(weights, g.rows); err != nil {
+		return err
+	}
+	g.rowWeights = weights
+	return nil
+}
+
+// checkGridWeights checks that there are n non-negative weights with a positive sum.
+func checkGridWeights(weights []float64, n int) error {
+	if len(weights) != n {
+		common.Log.Debug("Mismatching number of weights %d != %d", len(weights), n)
+		return errors.New("Range check error")
+	}
+	sum := 0.0
+	for _, w := range weights {
+		if w < 0 {
+			common.Log.Debug("Negative weight %f", w)
+			return errors.New("Range check error")
+		}
+		sum += w
+	}
+	if sum <= 0 {
+		common.Log.Debug("Weights sum to 0")
+		return errors.New("Range check error")
+	}
+	return nil
+}
+
+// SetWidth sets the width of the grid, by default the available width.
+func (g *Grid) SetWidth(width float64) {
+	g.width = width
+}
+
+// SetHeight sets the height of the grid, shared by the rows according to their weights.  By default (0) each
+// row is as high as its highest item.
+func (g *Grid) SetHeight(height float64) {
+	g.height = height
+}
+
+// SetGaps sets the horizontal gap between columns and the vertical gap between rows.
+func (g *Grid) SetGaps(colGap, rowGap float64) {
+	g.colGap = colGap
+	g.rowGap = rowGap
+}
+
+// SetMargins sets the margins of the grid (used in relative positioning mode).
+func (g *Grid) SetMargins(left, right, top, bottom float64) {
+	g.margins.left = left
+	g.margins.right = right
+	g.margins.top = top
+	g.margins.bottom = bottom
+}
+
+// GetMargins returns the left, right, top, bottom Margins.
+func (g *Grid) GetMargins() (float64, float64, float64, float64) {
+	return g.margins.left, g.margins.right, g.margins.top, g.margins.bottom
+}
+
+// SetPos sets the absolute position of the grid (upper left corner) and changes to absolute positioning mode.
+func (g *Grid) SetPos(x, y float64) {
+	g.positioning = positionAbsolute
+	g.xPos = x
+	g.yPos = y
+}
+
+// Add adds a drawable to the grid at the specified row and column (1-based).
+func (g *Grid) Add(d VectorDrawable, row, col int) (*GridItem, error) {
+	return g.AddSpan(d, row, col, 1, 1)
+}
+
+// AddSpan adds a drawable to the grid at the specified row and column (1-based), spanning several rows and
+// columns.  Items may overlap, they are drawn in the order added.
+func (g *Grid) AddSpan(d VectorDrawable, row, col, rowspan, colspan int) (*GridItem, error) {
+	if d == nil {
+		return nil, errors.New("Nil grid item")
+	}
+	if row < 1 || col < 1 || rowspan < 1 || colspan < 1 || row+rowspan-1 > g.rows || col+colspan-1 > g.cols {
+		common.Log.Debug("Grid item %d,%d spanning %dx%d outside of %dx%d grid", row, col, rowspan, colspan,
+			g.rows, g.cols)
+		return nil, errors.New("Range check error")
+	}
+
+	item := &GridItem{
+		content: d,
+		row:     row,
+		col:     col,
+		rowspan: rowspan,
+		colspan: colspan,
+	}
+	g.items = append(g.items, item)
+	return item, nil
+}
+
+// SetHorizontalAlignment sets the horizontal alignment of the content in the item area (left by default).
+func (item *GridItem) SetHorizontalAlignment(halign CellHorizontalAlignment) {
+	item.horizontalAlignment = halign
+}
+
+// SetVerticalAlignment sets the vertical alignment of the content in the item area (top by default).
+func (item *GridItem) SetVerticalAlignment(valign CellVerticalAlignment) {
+	item.verticalAlignment = valign
+}
+
+// Width returns the width of the grid when set, otherwise 0 as the grid fills the available width.
+func (g *Grid) Width() float64 {
+	return g.width
+}
+
+// Height returns the height of the grid, excluding the margins.
+func (g *Grid) Height() float64 {
+	_, rowHeights := g.layout(g.width)
+	return sumGridSizes(rowHeights, g.rowGap)
+}
+
+// layout returns the widths of the columns and the heights of the rows for the grid width (if 0, the items are
+// not wrapped).
+func (g *Grid) layout(width float64) ([]float64, []float64) {
+	colWidths := distributeGridSize(g.colWeights, width-g.colGap*float64(g.cols-1))
+	if g.height > 0 {
+		return colWidths, distributeGridSize(g.rowWeights, g.height-g.rowGap*float64(g.rows-1))
+	}
+
+	// Rows sized to their content: the items spanning several rows grow the last of them if needed.
+	rowHeights := make([]float64, g.rows)
+	for _, multi := range []bool{false, true} {
+		for _, item := range g.items {
+			if (item.rowspan > 1) != multi {
+				continue
+			}
+			if width > 0 {
+				_, w := item.horizontalExtent(colWidths, g.colGap)
+				wrapGridItem(item.content, w)
+			}
+			_, h := gridItemSize(item.content)
+			avail := sumGridSizes(rowHeights[item.row-1:item.row+item.rowspan-1], g.rowGap)
+			if h > avail {
+				rowHeights[item.row+item.rowspan-2] += h - avail
+			}
+		}
+	}
+	return colWidths, rowHeights
+}
+
+// horizontalExtent returns the offset and width of an item for the column widths.
+func (item *GridItem) horizontalExtent(sizes []float64, gap float64) (float64, float64) {
+	offset := sumGridSizes(sizes[:item.col-1], gap)
+	if item.col > 1 {
+		offset += gap
+	}
+	return offset, sumGridSizes(sizes[item.col-1:item.col+item.colspan-1], gap)
+}
+
+// verticalExtent returns the offset and height of an item for the row heights.
+func (item *GridItem) verticalExtent(rowHeights []float64, gap float64) (float64, float64) {
+	offset := sumGridSizes(rowHeights[:item.row-1], gap)
+	if item.row > 1 {
+		offset += gap
+	}
+	return offset, sumGridSizes(rowHeights[item.row-1:item.row+item.rowspan-1], gap)
+}
+
+// distributeGridSize divides a size between weights.
+func distributeGridSize(weights []float64, size float64) []float64 {
+	size = math.Max(size, 0)
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	sizes := make([]float64, len(weights))
+	for i, w := range weights {
+		if sum > 0 {
+			sizes[i] = w / sum * size
+		}
+	}
+	return sizes
+}
+
+// sumGridSizes returns the total of the sizes separated by gaps.
+func sumGridSizes(sizes []float64, gap float64) float64 {
+	if len(sizes) == 0 {
+		return 0
+	}
+	total := gap * float64(len(sizes)-1)
+	for _, s := range sizes {
+		total += s
+	}
+	return total
+}
+
+// wrapGridItem wraps paragraphs to the width of their item area.
+func wrapGridItem(d VectorDrawable, w float64) {
+	switch t := d.(type) {
+	case *Paragraph:
+		if t.enableWrap {
+			t.SetWidth(w - t.margins.left - t.margins.right)
+		}
+	case *StyledParagraph:
+		if t.enableWrap {
+			t.SetWidth(w - t.margins.left - t.margins.right)
+		}
+	}
+}
+
+// gridItemSize returns the width and height of the content of an item including its margins.
+func gridItemSize(d VectorDrawable) (float64, float64) {
+	var m margins
+	switch t := d.(type) {
+	case *Paragraph:
+		m = t.margins
+	case *StyledParagraph:
+		m = t.margins
+	case *Image:
+		m = t.margins
+	case *Division:
+		m = t.margins
+	case *Grid:
+		m = t.margins
+	}
+	return d.Width() + m.left + m.right, d.Height() + m.top + m.bottom
+}
+
+// GeneratePageBlocks generates the page blocks for the Grid.  In relative mode, the grid is drawn on the next
+// page if it does not fit in the space left on the current page.
+func (g *Grid) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	blocks := []*Block{}
+
+	origCtx := ctx
+	if g.positioning.isAbsolute() {
+		ctx.X = g.xPos
+		ctx.Y = g.yPos
+	} else {
+		// Relative mode: add margins.
+		ctx.X += g.margins.left
+		ctx.Y += g.margins.top
+		ctx.Width -= g.margins.left + g.margins.right
+		ctx.Height -= g.margins.top + g.margins.bottom
+	}
+
+	width := g.width
+	if width <= 0 {
+		width = ctx.Width
+	}
+	colWidths, rowHeights := g.layout(width)
+	height := sumGridSizes(rowHeights, g.rowGap)
+
+	if g.positioning.isRelative() && height > ctx.Height &&
+		height <= ctx.PageHeight-ctx.Margins.top-ctx.Margins.bottom-g.margins.top-g.margins.bottom {
+		// Does not fit on the current page: draw it on the next page.
+		blocks = append(blocks, NewBlock(ctx.PageWidth, ctx.PageHeight))
+		ctx.Page++
+		ctx.X = ctx.Margins.left + g.margins.left
+		ctx.Y = ctx.Margins.top + g.margins.top
+		ctx.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom - g.margins.top - g.margins.bottom
+	}
+
+	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
+	for _, item := range g.items {
+		xrel, w := item.horizontalExtent(colWidths, g.colGap)
+		yrel, h := item.verticalExtent(rowHeights, g.rowGap)
+		wrapGridItem(item.content, w)
+
+		itemCtx := ctx
+		itemCtx.X = ctx.X + xrel
+		itemCtx.Y = ctx.Y + yrel
+		itemCtx.Width = w
+		itemCtx.Height = ctx.PageHeight - itemCtx.Y - ctx.Margins.bottom
+
+		// Account for the alignment.
+		cw, ch := gridItemSize(item.content)
+		if item.content.Width() > 0 && cw < w {
+			switch item.horizontalAlignment {
+			case CellHorizontalAlignmentCenter:
+				itemCtx.X += (w - cw) / 2
+				itemCtx.Width = cw
+			case CellHorizontalAlignmentRight:
+				itemCtx.X += w - cw
+				itemCtx.Width = cw
+			}
+		}
+		if ch < h {
+			switch item.verticalAlignment {
+			case CellVerticalAlignmentMiddle:
+				itemCtx.Y += (h - ch) / 2
+			case CellVerticalAlignmentBottom:
+				itemCtx.Y += h - ch
+			}
+		}
+
+		if err := block.DrawWithContext(item.content, itemCtx); err != nil {
+			common.Log.Debug("Error drawing grid item: %v", err)
+			return nil, origCtx, err
+		}
+	}
+	blocks = append(blocks, block)
+
+	if g.positioning.isAbsolute() {
+		return blocks, origCtx, nil
+	}
+
+	// Relative mode: continue below the grid.
+	ctx.X = origCtx.X
+	ctx.Width = origCtx.Width
+	ctx.Y += height + g.margins.bottom
+	ctx.Height = ctx.PageHeight - ctx.Y - ctx.Margins.bottom
+	return blocks, ctx, nil
+}
@@ -0,0 +1,73 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGridLayout(t *testing.T) {
+	grid := NewGrid(2, 3)
+	if err := grid.SetColumnWeights(1, 2); err == nil {
+		t.Fatalf("Fail: expected range check error for mismatching number of weights")
+	}
+	if err := grid.SetColumnWeights(1, 2, 1); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	grid.SetGaps(10, 5)
+	if _, err := grid.AddSpan(NewParagraph("Outside"), 2, 2, 1, 3); err == nil {
+		t.Fatalf("Fail: expected range check error for item outside of the grid")
+	}
+
+	p := NewParagraph("Title")
+	p.SetMargins(0, 0, 2, 2)
+	if _, err := grid.AddSpan(p, 1, 1, 1, 3); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	rect := NewBlock(50, 40)
+	item, err := grid.AddSpan(rect, 1, 3, 2, 1)
+	if err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	item.SetHorizontalAlignment(CellHorizontalAlignmentCenter)
+
+	colWidths, rowHeights := grid.layout(420)
+	for i, exp := range []float64{100, 200, 100} {
+		if math.Abs(colWidths[i]-exp) > 1e-6 {
+			t.Errorf("Fail: column %d width %f, expected %f", i+1, colWidths[i], exp)
+		}
+	}
+	// The first row fits the paragraph, the second row the remaining height of the block spanning both.
+	h1 := p.Height() + 4
+	if math.Abs(rowHeights[0]-h1) > 1e-6 || math.Abs(rowHeights[1]-(40-h1-5)) > 1e-6 {
+		t.Errorf("Fail: row heights %v", rowHeights)
+	}
+	if xrel, w := item.horizontalExtent(colWidths, 10); xrel != 320 || w != 100 {
+		t.Errorf("Fail: item at %f width %f", xrel, w)
+	}
+
+	// Fixed height shared by the row weights.
+	grid.SetHeight(105)
+	if err := grid.SetRowWeights(2, 1); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	_, rowHeights = grid.layout(420)
+	if math.Abs(rowHeights[0]-200.0/3) > 1e-6 || math.Abs(rowHeights[1]-100.0/3) > 1e-6 {
+		t.Errorf("Fail: row heights %v", rowHeights)
+	}
+	if math.Abs(grid.Height()-105) > 1e-6 {
+		t.Errorf("Fail: grid height %f", grid.Height())
+	}
+
+	c := New()
+	if err := c.Draw(grid); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if err := c.WriteToFile("/tmp/grid_layout.pdf"); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+}
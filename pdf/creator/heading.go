@@ -0,0 +1,91 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"strings"
+)
+
+// Heading is a heading of a level (1 to 6, 1 being the top level), drawn as a styled paragraph.  The headings
+// drawn with the creator are included in the document outline when outlines are enabled, nested by level with the
+// chapters and subchapters (levels 1 and 2).
+// Implements the Drawable interface.
+type Heading struct {
+	para  *StyledParagraph
+	level int
+	title string
+
+	// Position where the heading was last drawn: the page number and the top of its first line.
+	page int
+	y    float64
+}
+
+// NewHeading creates a heading of the level drawn as the paragraph, titled by the text of the paragraph in the
+// outline.
+func NewHeading(level int, para *StyledParagraph) *Heading {
+	if level < 1 {
+		level = 1
+	}
+
+	var title strings.Builder
+	for _, chunk := range para.Chunks() {
+		title.WriteString(chunk.Text)
+	}
+	return &Heading{
+		para:  para,
+		level: level,
+		title: strings.Join(strings.Fields(title.String()), " "),
+	}
+}
+
+// Level returns the level of the heading.
+func (h *Heading) Level() int {
+	return h.level
+}
+
+// Title returns the title of the heading in the outline.
+func (h *Heading) Title() string {
+	return h.title
+}
+
+// SetTitle sets the title of the heading in the outline.
+func (h *Heading) SetTitle(title string) {
+	h.title = title
+}
+
+// Paragraph returns the paragraph drawing the heading.
+func (h *Heading) Paragraph() *StyledParagraph {
+	return h.para
+}
+
+// SetMargins sets the margins of the heading: left, right, top, bottom.
+func (h *Heading) SetMargins(left, right, top, bottom float64) {
+	h.para.SetMargins(left, right, top, bottom)
+}
+
+// GetMargins returns the margins of the heading: left, right, top, bottom.
+func (h *Heading) GetMargins() (float64, float64, float64, float64) {
+	return h.para.GetMargins()
+}
+
+// GeneratePageBlocks draws the paragraph of the heading, and records its position.  Implements the Drawable
+// interface.
+func (h *Heading) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	_, _, top, _ := h.para.GetMargins()
+	h.page = ctx.Page
+	h.y = ctx.Y + top
+
+	blocks, newCtx, err := h.para.GeneratePageBlocks(ctx)
+	if err != nil {
+		return blocks, ctx, err
+	}
+	if len(blocks) > 1 && len(*blocks[0].contents) == 0 {
+		// Did not fit, moved to a new page.
+		h.page++
+		h.y = ctx.Margins.top
+	}
+	return blocks, newCtx, nil
+}
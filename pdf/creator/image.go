@@ -9,8 +9,8 @@ import (
 	"bytes"
 	"fmt"
 	goimage "image"
-
 	"io/ioutil"
+	"math"
 
 	"github.com/unidoc/unidoc/common"
 	"github.com/unidoc/unidoc/pdf/contentstream"
@@ -29,6 +29,9 @@ type Image struct {
 	// The dimensions of the image. As to be placed on the PDF.
 	width, height float64
 
+	// How the image is fitted in the box of its dimensions.
+	fit ImageFit
+
 	// The original dimensions of the image (pixel based).
 	origWidth, origHeight float64
 
@@ -50,8 +53,30 @@ type Image struct {
 
 	// Encoder
 	encoder core.StreamEncoder
+
+	// The target of the link over the image, if any.
+	link *linkTarget
+
+	// The color key mask ranges, if any.
+	colorKeyMask []int
 }
 
+// ImageFit defines how an image is fitted in the box of its width and height.
+type ImageFit int
+
+// Images are stretched to their box by default, or scaled keeping their aspect ratio to be contained in their box
+// or to cover it.
+const (
+	// Stretch the image to the box.
+	ImageFitStretch ImageFit = iota
+
+	// Scale the image to fit in the box, centered, leaving space on its sides or above and below.
+	ImageFitContain
+
+	// Scale the image to cover the box, centered, clipping its sides or top and bottom.
+	ImageFitCover
+)
+
 // NewImage create a new image from a unidoc image (model.Image).
 func NewImage(img *model.Image) (*Image, error) {
 	image := &Image{}
@@ -129,6 +154,25 @@ func (img *Image) SetOpacity(opacity float64) {
 	img.opacity = opacity
 }
 
+// SetColorKeyMask makes the pixels of the image with colors in the ranges transparent, e.g. a solid background.  The
+// ranges are pairs of minimum and maximum sample values for each color component: [0 10 240 255 0 10] masks green
+// in an 8 bit RGB image.  Ignored for images with an alpha channel.
+func (img *Image) SetColorKeyMask(ranges []int) {
+	img.colorKeyMask = ranges
+	img.xobj = nil
+}
+
+// SetLink makes the image a link opening the URI.
+func (img *Image) SetLink(uri string) {
+	img.link = newURILink(uri)
+}
+
+// SetPageLink makes the image a link going to the position (x, y), from the upper left corner, on the page of the
+// document with the specified number (from 1, including the front page and table of contents).
+func (img *Image) SetPageLink(page int, x, y float64) {
+	img.link = newPageLink(page, x, y)
+}
+
 // SetMargins sets the margins for the Image (in relative mode): left, right, top, bottom.
 func (img *Image) SetMargins(left, right, top, bottom float64) {
 	img.margins.left = left
@@ -146,8 +190,15 @@ func (img *Image) GetMargins() (float64, float64, float64, float64) {
 func (img *Image) makeXObject() error {
 	encoder := img.encoder
 	if encoder == nil {
-		// Default: Use flate encoder.
-		encoder = core.NewFlateEncoder()
+		// Default: Use flate encoder, with the PNG predictor of each row.
+		flate := core.NewFlateEncoder()
+		if img.img.Width > 0 && img.img.ColorComponents > 0 {
+			flate.Predictor = 15
+			flate.Columns = int(img.img.Width)
+			flate.Colors = img.img.ColorComponents
+			flate.BitsPerComponent = int(img.img.BitsPerComponent)
+		}
+		encoder = flate
 	}
 
 	// Create the XObject image.
@@ -156,6 +207,12 @@ func (img *Image) makeXObject() error {
 		common.Log.Error("Failed to create xobject image: %s", err)
 		return err
 	}
+	if img.colorKeyMask != nil && ximg.SMask == nil {
+		err = ximg.SetColorKeyMask(img.colorKeyMask)
+		if err != nil {
+			return err
+		}
+	}
 
 	img.xobj = ximg
 	return nil
@@ -173,7 +230,7 @@ func (img *Image) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, er
 
 	blk := NewBlock(ctx.PageWidth, ctx.PageHeight)
 	if img.positioning.isRelative() {
-		if img.height > ctx.Height {
+		if _, _, _, h := img.boundingBox(); h > ctx.Height {
 			// Goes out of the bounds.  Write on a new template instead and create a new context at upper
 			// left corner.
 
@@ -201,6 +258,10 @@ func (img *Image) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, er
 	}
 
 	// Place the Image on the template at position (x,y) based on the ctx.
+	if img.angle == 0 {
+		// Rotated images are not linked.
+		blk.addLink(img.link, ctx.X, ctx.Y, img.Width(), img.Height())
+	}
 	ctx, err := drawImageOnBlock(blk, img, ctx)
 	if err != nil {
 		return nil, ctx, err
@@ -258,11 +319,49 @@ func (img *Image) SetHeight(h float64) {
 	img.height = h
 }
 
-// SetAngle sets Image rotation angle in degrees.
+// Fit sets the box of the Image to width x height, the image being fitted in it by the mode.
+func (img *Image) Fit(mode ImageFit, width, height float64) {
+	img.fit = mode
+	img.width = width
+	img.height = height
+}
+
+// SetDPI sets the Image's document size from its resolution in dots (pixels) per inch, e.g. 300 for an image
+// scanned at 300 DPI to be printed at its original size.
+func (img *Image) SetDPI(dpi float64) {
+	img.width = img.origWidth * PPI / dpi
+	img.height = img.origHeight * PPI / dpi
+}
+
+// SetAngle sets Image rotation angle in degrees (counter-clockwise), about its upper left corner.  In relative mode,
+// the bounding box of the rotated image is placed at the current position.
 func (img *Image) SetAngle(angle float64) {
 	img.angle = angle
 }
 
+// boundingBox returns the bounding box of the rotated image, from its upper left corner: the offsets of its left and
+// top sides (positive to the right and down), and its width and height.
+func (img *Image) boundingBox() (left, top, width, height float64) {
+	if img.angle == 0 {
+		return transformedBBox(nil, img.width, img.height)
+	}
+	rot := contentstream.RotationMatrix(img.angle)
+	return transformedBBox(&rot, img.width, img.height)
+}
+
+// imageRect returns the rectangle of the image fitted in its box, from the lower left corner of the box.
+func (img *Image) imageRect() (x, y, width, height float64) {
+	if img.fit == ImageFitStretch || img.origWidth == 0 || img.origHeight == 0 {
+		return 0, 0, img.width, img.height
+	}
+	scale := math.Min(img.width/img.origWidth, img.height/img.origHeight)
+	if img.fit == ImageFitCover {
+		scale = math.Max(img.width/img.origWidth, img.height/img.origHeight)
+	}
+	width, height = img.origWidth*scale, img.origHeight*scale
+	return (img.width - width) / 2, (img.height - height) / 2, width, height
+}
+
 // Draw the image onto the specified blk.
 func drawImageOnBlock(blk *Block, img *Image, ctx DrawContext) (DrawContext, error) {
 	origCtx := ctx
@@ -302,8 +401,14 @@ func drawImageOnBlock(blk *Block, img *Image, ctx DrawContext) (DrawContext, err
 		return ctx, err
 	}
 
-	xPos := ctx.X
-	yPos := ctx.PageHeight - ctx.Y - img.Height()
+	// The position of the upper left corner, with the bounding box of the rotated image at the current position in
+	// relative mode.
+	left, top, _, height := img.boundingBox()
+	xPos, yPos := ctx.X, ctx.Y
+	if img.positioning.isRelative() {
+		xPos -= left
+		yPos -= top
+	}
 	angle := img.angle
 
 	// Create content stream to add to the Page contents.
@@ -311,7 +416,7 @@ func drawImageOnBlock(blk *Block, img *Image, ctx DrawContext) (DrawContext, err
 
 	contentCreator.Add_gs(gsName) // Set graphics state.
 
-	contentCreator.Translate(xPos, yPos)
+	contentCreator.Translate(xPos, ctx.PageHeight-yPos-img.Height())
 	if angle != 0 {
 		// Make the rotation about the upper left corner.
 		contentCreator.Translate(0, img.Height())
@@ -319,8 +424,14 @@ func drawImageOnBlock(blk *Block, img *Image, ctx DrawContext) (DrawContext, err
 		contentCreator.Translate(0, -img.Height())
 	}
 
+	x, y, w, h := img.imageRect()
+	if img.fit == ImageFitCover {
+		// Clip the image to its box.
+		contentCreator.Add_re(0, 0, img.Width(), img.Height()).Add_W().Add_n()
+	}
 	contentCreator.
-		Scale(img.Width(), img.Height()).
+		Translate(x, y).
+		Scale(w, h).
 		Add_Do(imgName) // Draw the image.
 
 	ops := contentCreator.Operations()
@@ -329,8 +440,8 @@ func drawImageOnBlock(blk *Block, img *Image, ctx DrawContext) (DrawContext, err
 	blk.addContents(ops)
 
 	if img.positioning.isRelative() {
-		ctx.Y += img.Height()
-		ctx.Height -= img.Height()
+		ctx.Y += height
+		ctx.Height -= height
 		return ctx, nil
 	}
 	// Absolute positioning - return original context.
@@ -8,20 +8,70 @@ package creator
 import (
 	"math"
 
-	"github.com/unidoc/unidoc/pdf/contentstream/draw"
-	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+)
+
+// LineCapStyle is the shape of the ends of lines and of the dashes of dashed lines.
+type LineCapStyle int
+
+const (
+	// LineCapButt ends the line at its end point.
+	LineCapButt LineCapStyle = iota
+
+	// LineCapRound ends the line with a semicircle around its end point.
+	LineCapRound
+
+	// LineCapSquare extends the line beyond its end point by half its width.
+	LineCapSquare
+)
+
+// LineJoinStyle is the shape of the corners of open arrowheads.
+type LineJoinStyle int
+
+const (
+	LineJoinMiter LineJoinStyle = iota
+	LineJoinRound
+	LineJoinBevel
+)
+
+// ArrowStyle is the style of an arrowhead at an end of a line.
+type ArrowStyle int
+
+const (
+	// ArrowNone is a line end without arrowhead.
+	ArrowNone ArrowStyle = iota
+
+	// ArrowOpen is an arrowhead of two strokes.
+	ArrowOpen
+
+	// ArrowClosed is a filled triangular arrowhead.
+	ArrowClosed
 )
 
 // Line defines a line between point 1 (X1,Y1) and point 2 (X2,Y2).  The line ending styles can be none (regular line),
-// or arrows at either end.  The line also has a specified width, color and opacity.
+// or arrows at either end.  The line also has a specified width, color, dash pattern and cap and join styles.
 // Implements the Drawable interface and can be drawn on PDF using the Creator.
 type Line struct {
 	x1        float64
 	y1        float64
 	x2        float64
 	y2        float64
-	lineColor *model.PdfColorDeviceRGB
+	lineColor Color
 	lineWidth float64
+
+	// Dash pattern, solid if no dash array.
+	dashArray []float64
+	dashPhase float64
+
+	lineCap  LineCapStyle
+	lineJoin LineJoinStyle
+
+	// Arrowheads at point 1 and point 2, and their size, scaled with the line width if 0.
+	arrow1      ArrowStyle
+	arrow2      ArrowStyle
+	arrowLength float64
+	arrowWidth  float64
 }
 
 // NewLine creates a new Line with default parameters between (x1,y1) to (x2,y2).
@@ -33,7 +83,7 @@ func NewLine(x1, y1, x2, y2 float64) *Line {
 	l.x2 = x2
 	l.y2 = y2
 
-	l.lineColor = model.NewPdfColorDeviceRGB(0, 0, 0)
+	l.lineColor = ColorBlack
 	l.lineWidth = 1.0
 
 	return l
@@ -50,9 +100,40 @@ func (l *Line) SetLineWidth(lw float64) {
 }
 
 // SetColor sets the line color.
-// Use ColorRGBFromHex, ColorRGBFrom8bit or ColorRGBFromArithmetic to make the color object.
+// Use ColorRGBFromHex, ColorRGBFrom8bit, ColorRGBFromArithmetic, ColorCMYKFromArithmetic or a SpotColor to make
+// the color object.
 func (l *Line) SetColor(col Color) {
-	l.lineColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	l.lineColor = col
+}
+
+// SetDashPattern sets the dash pattern: the lengths of the alternating dashes and gaps, and the distance into the
+// pattern at which the line starts.  The line is solid if the dash array is empty.  Arrowheads are not dashed.
+func (l *Line) SetDashPattern(dashArray []float64, phase float64) {
+	l.dashArray = append([]float64{}, dashArray...)
+	l.dashPhase = phase
+}
+
+// SetLineCap sets the shape of the ends of the line and of its dashes.  The default is LineCapButt.
+func (l *Line) SetLineCap(lineCap LineCapStyle) {
+	l.lineCap = lineCap
+}
+
+// SetLineJoin sets the shape of the corners of open arrowheads.  The default is LineJoinMiter.
+func (l *Line) SetLineJoin(lineJoin LineJoinStyle) {
+	l.lineJoin = lineJoin
+}
+
+// SetArrows sets the arrowheads at point 1 and point 2.
+func (l *Line) SetArrows(arrow1, arrow2 ArrowStyle) {
+	l.arrow1 = arrow1
+	l.arrow2 = arrow2
+}
+
+// SetArrowSize sets the length of the arrowheads along the line and their width across it.  By default, they are
+// scaled with the line width.
+func (l *Line) SetArrowSize(length, width float64) {
+	l.arrowLength = length
+	l.arrowWidth = width
 }
 
 // Length calculates and returns the line length.
@@ -60,31 +141,96 @@ func (l *Line) Length() float64 {
 	return math.Sqrt(math.Pow(l.x2-l.x1, 2.0) + math.Pow(l.y2-l.y1, 2.0))
 }
 
+// arrowSize returns the length and width of the arrowheads.
+func (l *Line) arrowSize() (float64, float64) {
+	length, width := l.arrowLength, l.arrowWidth
+	if length <= 0 {
+		length = 4*l.lineWidth + 4
+	}
+	if width <= 0 {
+		width = 0.8 * length
+	}
+	return length, width
+}
+
 // GeneratePageBlocks draws the line on a new block representing the page. Implements the Drawable interface.
 func (l *Line) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
 
-	drawline := draw.Line{
-		LineWidth:        l.lineWidth,
-		Opacity:          1.0,
-		LineColor:        l.lineColor,
-		LineEndingStyle1: draw.LineEndingStyleNone,
-		LineEndingStyle2: draw.LineEndingStyleNone,
-		X1:               l.x1,
-		Y1:               ctx.PageHeight - l.y1,
-		X2:               l.x2,
-		Y2:               ctx.PageHeight - l.y2,
+	x1, y1 := l.x1, ctx.PageHeight-l.y1
+	x2, y2 := l.x2, ctx.PageHeight-l.y2
+	length := l.Length()
+
+	// The unit vector from point 1 to point 2, and the normal.
+	ux, uy := 0.0, 0.0
+	if length > 0 {
+		ux, uy = (x2-x1)/length, (y2-y1)/length
+	}
+	nx, ny := -uy, ux
+	arrowLength, arrowWidth := l.arrowSize()
+	if length == 0 {
+		// Without direction for the arrowheads.
+		arrowLength = 0
 	}
 
-	contents, _, err := drawline.Draw("")
-	if err != nil {
-		return nil, ctx, err
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	addColorOperators(cc, block, l.lineColor, true)
+	addColorOperators(cc, block, l.lineColor, false)
+	cc.Add_w(l.lineWidth)
+	addLineOperation(cc, "J", core.MakeInteger(int64(l.lineCap)))
+	addLineOperation(cc, "j", core.MakeInteger(int64(l.lineJoin)))
+
+	// The line stops at the base of closed arrowheads.
+	start, end := 0.0, length
+	if l.arrow1 == ArrowClosed {
+		start += arrowLength
+	}
+	if l.arrow2 == ArrowClosed {
+		end -= arrowLength
+	}
+	dashed := len(l.dashArray) > 0
+	if dashed {
+		addLineOperation(cc, "d", core.MakeArrayFromFloats(l.dashArray), core.MakeFloat(l.dashPhase))
+	}
+	if end > start || length == 0 {
+		cc.Add_m(x1+ux*start, y1+uy*start).Add_l(x1+ux*end, y1+uy*end).Add_S()
 	}
 
-	err = block.addContentsByString(string(contents))
-	if err != nil {
-		return nil, ctx, err
+	for _, arrow := range []struct {
+		style  ArrowStyle
+		x, y   float64
+		dx, dy float64 // Direction of the arrow.
+	}{
+		{l.arrow1, x1, y1, -ux, -uy},
+		{l.arrow2, x2, y2, ux, uy},
+	} {
+		if arrow.style == ArrowNone || arrowLength == 0 {
+			continue
+		}
+		if dashed {
+			addLineOperation(cc, "d", core.MakeArray(), core.MakeInteger(0))
+			dashed = false
+		}
+		baseX, baseY := arrow.x-arrow.dx*arrowLength, arrow.y-arrow.dy*arrowLength
+		cc.Add_m(baseX+nx*arrowWidth/2, baseY+ny*arrowWidth/2).
+			Add_l(arrow.x, arrow.y).
+			Add_l(baseX-nx*arrowWidth/2, baseY-ny*arrowWidth/2)
+		if arrow.style == ArrowClosed {
+			cc.Add_h().Add_f()
+		} else {
+			cc.Add_S()
+		}
 	}
+	cc.Add_Q()
 
+	block.addContents(cc.Operations())
 	return []*Block{block}, ctx, nil
 }
+
+// addLineOperation adds the operation with its operands, for operators whose operands are not supported by the
+// ContentCreator.
+func addLineOperation(cc *contentstream.ContentCreator, operand string, params ...core.PdfObject) {
+	ops := cc.Operations()
+	*ops = append(*ops, &contentstream.ContentStreamOperation{Operand: operand, Params: params})
+}
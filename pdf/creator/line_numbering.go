@@ -0,0 +1,138 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// LineNumbering defines how text lines are numbered in the left page margin, as required for pleading paper
+// and some contract formats.  The numbers are placed at the baselines of the paragraph lines drawn with the Creator.
+type LineNumbering struct {
+	// Number every n-th line (1 numbers every line).
+	interval int
+
+	// Restart the numbering from 1 on each page.
+	restartEachPage bool
+
+	// The font, font size and color of the numbers.
+	textFont fonts.Font
+	fontSize float64
+	color    Color
+
+	// Distance between the right edge of the numbers and the left page margin.
+	gap float64
+}
+
+// NewLineNumbering returns a new LineNumbering with default parameters: every line numbered, numbering
+// restarted on each page, Helvetica 8pt in black and 10 points between the numbers and the text.
+func NewLineNumbering() *LineNumbering {
+	ln := &LineNumbering{}
+	ln.interval = 1
+	ln.restartEachPage = true
+	ln.textFont = fonts.NewFontHelvetica()
+	ln.fontSize = 8
+	ln.color = ColorBlack
+	ln.gap = 10
+	return ln
+}
+
+// SetInterval sets the numbering interval, i.e. only every n-th line is numbered.
+func (ln *LineNumbering) SetInterval(n int) {
+	if n < 1 {
+		n = 1
+	}
+	ln.interval = n
+}
+
+// SetRestartEachPage sets whether the numbering restarts from 1 on each page, or continues across pages.
+func (ln *LineNumbering) SetRestartEachPage(restart bool) {
+	ln.restartEachPage = restart
+}
+
+// SetFont sets the font of the line numbers.
+func (ln *LineNumbering) SetFont(font fonts.Font) {
+	ln.textFont = font
+}
+
+// SetFontSize sets the font size of the line numbers.
+func (ln *LineNumbering) SetFontSize(fontSize float64) {
+	ln.fontSize = fontSize
+}
+
+// SetColor sets the color of the line numbers.
+func (ln *LineNumbering) SetColor(col Color) {
+	ln.color = col
+}
+
+// SetGap sets the distance between the line numbers and the left page margin.
+func (ln *LineNumbering) SetGap(gap float64) {
+	ln.gap = gap
+}
+
+// SetLineNumbering enables numbering of the text lines in the left page margin of all pages.
+// Set to nil to disable.
+func (c *Creator) SetLineNumbering(ln *LineNumbering) {
+	c.lineNumbering = ln
+}
+
+// addPageLines registers the text line baselines of a block drawn on page.
+func (c *Creator) addPageLines(page *model.PdfPage, lines []float64) {
+	if len(lines) == 0 {
+		return
+	}
+	if c.pageLines == nil {
+		c.pageLines = map[*model.PdfPage][]float64{}
+	}
+	c.pageLines[page] = append(c.pageLines[page], lines...)
+}
+
+// drawLineNumbers draws the line numbers for the lines on the currently active page.  The number of the first
+// line is given by num and the number following the last line on the page is returned.
+func (c *Creator) drawLineNumbers(lines []float64, num int) (int, error) {
+	ln := c.lineNumbering
+	if len(lines) == 0 {
+		return num, nil
+	}
+
+	// Sort the baselines top to bottom and skip duplicates (e.g. table cells in the same row).
+	sorted := append([]float64{}, lines...)
+	sort.Float64s(sorted)
+
+	block := NewBlock(c.pageWidth, c.pageHeight)
+	last := math.Inf(-1)
+	for _, y := range sorted {
+		if y-last < 0.5 {
+			continue
+		}
+		last = y
+
+		if num%ln.interval == 0 {
+			p := NewParagraph(fmt.Sprintf("%d", num))
+			p.SetFont(ln.textFont)
+			p.SetFontSize(ln.fontSize)
+			p.SetColor(ln.color)
+			p.SetEnableWrap(false)
+			p.SetPos(c.pageMargins.left-ln.gap-p.getTextWidth()/1000.0, y-ln.fontSize)
+
+			err := block.Draw(p)
+			if err != nil {
+				return num, err
+			}
+		}
+		num++
+	}
+
+	// The numbers themselves are not text lines to be numbered.
+	block.lines = nil
+	block.SetPos(0, 0)
+	return num, c.Draw(block)
+}
@@ -0,0 +1,53 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"testing"
+)
+
+func TestLineNumbering(t *testing.T) {
+	c := New()
+
+	ln := NewLineNumbering()
+	ln.SetInterval(5)
+	c.SetLineNumbering(ln)
+
+	for i := 0; i < 20; i++ {
+		p := NewParagraph("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor " +
+			"incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation " +
+			"ullamco laboris nisi ut aliquip ex ea commodo consequat.")
+		p.SetLineHeight(2)
+		p.SetMargins(0, 0, 0, 10)
+		err := c.Draw(p)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+
+	if len(c.pages) < 2 {
+		t.Errorf("Expected multiple pages (got %d)", len(c.pages))
+		return
+	}
+	lines := c.pageLines[c.pages[0]]
+	if len(lines) == 0 {
+		t.Errorf("No lines recorded on first page")
+		return
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] <= lines[i-1] {
+			t.Errorf("Line baselines not increasing: %v", lines)
+			return
+		}
+	}
+
+	err := c.WriteToFile("/tmp/line_numbering.pdf")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Characters that are not allowed at the start of a line (kinsoku shori): closing brackets, punctuation,
+// iteration marks, the prolonged sound mark and small kana.
+const lineStartProhibited = ")]}>,.:;!?%" +
+	"、。，．・：；？！‼⁇⁈⁉゛゜ヽヾゝゞ々〻ー" +
+	"）］｝〕〉》」』】〙〗〟’”｠»" +
+	"ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ" +
+	"‐゠–〜～"
+
+// Characters that are not allowed at the end of a line: opening brackets and quotes.
+const lineEndProhibited = "([{<" +
+	"（［｛〔〈《「『【〘〖〝‘“｟«"
+
+// isCJK returns true if the rune belongs to a script which is written without spaces between words
+// (Chinese and Japanese), i.e. lines can be broken between any two characters.
+func isCJK(r rune) bool {
+	switch {
+	case unicode.Is(unicode.Han, r), unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+		return true
+	case r >= 0x3000 && r <= 0x303F:
+		// CJK symbols and punctuation.
+		return true
+	case r >= 0xFF00 && r <= 0xFFEF:
+		// Halfwidth and fullwidth forms.
+		return true
+	}
+	return false
+}
+
+// canBreakBetween returns true if a line can be broken between the runes prev and next without a space,
+// i.e. next starts a new line.  Breaks are allowed next to CJK characters, unless prohibited by the kinsoku rules.
+func canBreakBetween(prev, next rune) bool {
+	if !isCJK(prev) && !isCJK(next) {
+		return false
+	}
+	if unicode.IsSpace(next) || strings.ContainsRune(lineStartProhibited, next) {
+		return false
+	}
+	if strings.ContainsRune(lineEndProhibited, prev) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// Encoder mapping all non-ASCII runes to uniXXXX glyph names, for testing the wrapping of CJK text.
+type testCJKEncoder struct {
+	textencoding.TextEncoder
+}
+
+func (enc testCJKEncoder) RuneToGlyph(val rune) (string, bool) {
+	if val > 0x7f {
+		return fmt.Sprintf("uni%04X", val), true
+	}
+	return enc.TextEncoder.RuneToGlyph(val)
+}
+
+// Font with fullwidth (1000) uniXXXX glyphs.
+type testCJKFont struct {
+	fonts.Font
+}
+
+func (font testCJKFont) GetGlyphCharMetrics(glyph string) (fonts.CharMetrics, bool) {
+	if len(glyph) == 7 && glyph[:3] == "uni" {
+		return fonts.CharMetrics{GlyphName: glyph, Wx: 1000}, true
+	}
+	return font.Font.GetGlyphCharMetrics(glyph)
+}
+
+func TestParagraphWrapCJK(t *testing.T) {
+	testcases := []struct {
+		text  string
+		width float64 // In characters.
+		lines []string
+	}{
+		// Breaks between ideographs without spaces.
+		{"日本語の文章を折り返す", 4, []string{"日本語の", "文章を折", "り返す"}},
+		// No line-start prohibited characters at line start: the preceding character moves along.
+		{"日本語。文章", 3, []string{"日本", "語。文", "章"}},
+		{"あいうぇお", 3, []string{"あい", "うぇお"}},
+		// No line-end prohibited characters at line end.
+		{"日本「語」です", 3, []string{"日本", "「語」", "です"}},
+	}
+
+	for _, tcase := range testcases {
+		p := NewParagraph(tcase.text)
+		p.SetFontSize(10)
+		p.textFont = testCJKFont{fonts.NewFontHelvetica()}
+		p.encoder = testCJKEncoder{textencoding.NewWinAnsiTextEncoder()}
+		p.SetEnableWrap(true)
+		p.SetWidth(tcase.width*10 + 0.5)
+
+		if len(p.textLines) != len(tcase.lines) {
+			t.Errorf("Fail: %q wrapped to %q, expected %q", tcase.text, p.textLines, tcase.lines)
+			continue
+		}
+		for i := range tcase.lines {
+			if p.textLines[i] != tcase.lines[i] {
+				t.Errorf("Fail: %q wrapped to %q, expected %q", tcase.text, p.textLines, tcase.lines)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// linkTarget is the target of a link: an action performed when activated (e.g. opening a URI), or a position on a
+// page of the document.
+type linkTarget struct {
+	action core.PdfObject
+
+	// Page number (from 1) in the document, and the position from the upper left corner of the page.
+	page int
+	x, y float64
+}
+
+// newURILink returns a link target opening the URI.
+func newURILink(uri string) *linkTarget {
+	action := core.MakeDict()
+	action.Set("S", core.MakeName("URI"))
+	action.Set("URI", core.MakeString(uri))
+	return &linkTarget{action: action}
+}
+
+// newPageLink returns a link target going to the position (x, y) from the upper left corner of the page.
+func newPageLink(page int, x, y float64) *linkTarget {
+	return &linkTarget{page: page, x: x, y: y}
+}
+
+// addLink adds a link area to the block for the target, at the rectangle at (x, y) from the upper left corner of the
+// block, of the specified size.
+func (blk *Block) addLink(target *linkTarget, x, y, width, height float64) {
+	if target == nil {
+		return
+	}
+	blk.links = append(blk.links, blockLink{
+		rect: model.PdfRectangle{
+			Llx: x,
+			Lly: blk.height - y - height,
+			Urx: x + width,
+			Ury: blk.height - y,
+		},
+		linkTarget: *target,
+	})
+}
+
+// pageLink is a link annotation going to a page of the document, whose destination is set when the pages are
+// final.
+type pageLink struct {
+	annot *model.PdfAnnotationLink
+	linkTarget
+}
+
+// setPageLinkDests sets the destinations of the links to pages, once the pages of the document are final.
+func (c *Creator) setPageLinkDests() error {
+	for _, link := range c.pageLinks {
+		idx := link.page - 1
+		if idx < 0 || idx >= len(c.pages) {
+			common.Log.Debug("Link to page %d out of range (%d pages)", link.page, len(c.pages))
+			continue
+		}
+		page := c.pages[idx]
+		mbox, err := page.GetMediaBox()
+		if err != nil {
+			return err
+		}
+		unit := page.GetUserUnit()
+		link.annot.Dest = core.MakeArray(page.GetPageAsIndirectObject(), core.MakeName("XYZ"),
+			core.MakeFloat(mbox.Llx+link.x/unit), core.MakeFloat(mbox.Ury-link.y/unit), core.MakeNull())
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// ListNumbering defines how the items of a List are marked: with a bullet, or numbered.
+type ListNumbering int
+
+const (
+	ListBullet ListNumbering = iota
+	ListDecimal
+	ListLowerAlpha
+	ListUpperAlpha
+	ListLowerRoman
+	ListUpperRoman
+)
+
+// Default bullets of the nesting levels of lists, repeated for deeper levels.
+var listDefaultBullets = []string{"•", "–", "·"}
+
+// Space between the marker of an item and its content (points).
+const listMarkerGap = 4
+
+// List is a list of items marked with bullets or numbers, which can be nested in other lists.  The items are
+// drawn with a hanging indent: the markers in the indent, on the first line of the items.  Lists wrap across pages
+// between and within the items.
+// By default occupies the available width in the drawing context.
+type List struct {
+	items []Drawable
+
+	// The marking of the items, with the bullet or the format of the numbers (with %s for the number).
+	numbering ListNumbering
+	bullet    string
+	format    string
+	start     int
+
+	// The style and the text encoder of the markers.
+	markerStyle   TextStyle
+	markerEncoder textencoding.TextEncoder
+
+	// Indentation of the items, in which the markers are drawn.
+	indent float64
+
+	// Nesting level of the list (0 for top level lists), determining the default bullet.
+	level int
+
+	// Margins to be applied around the block when drawing on Page.
+	margins margins
+}
+
+// NewList creates a new bulleted list, with the items indented by 20 points.
+func NewList() *List {
+	l := &List{}
+	l.numbering = ListBullet
+	l.format = "%s."
+	l.start = 1
+	l.markerStyle = NewTextStyle()
+	l.markerEncoder = textencoding.NewWinAnsiTextEncoder()
+	l.indent = 20
+	return l
+}
+
+// Add adds an item to the list: a paragraph, styled paragraph, image, table, division or a nested list.  Nested
+// lists are indented, without markers of their own.
+func (l *List) Add(item Drawable) error {
+	switch t := item.(type) {
+	case *Paragraph, *StyledParagraph, *Image, *Table, *Division:
+	case *List:
+		if t == l {
+			return errors.New("List cannot contain itself")
+		}
+	default:
+		common.Log.Debug("Unsupported list item type %T", item)
+		return errors.New("Unsupported type in List")
+	}
+
+	l.items = append(l.items, item)
+	return nil
+}
+
+// AddText adds a paragraph of the text as an item, returned to be customized.
+func (l *List) AddText(text string) *Paragraph {
+	p := NewParagraph(text)
+	l.items = append(l.items, p)
+	return p
+}
+
+// SetBullet marks the items with the bullet.  Glyphs not in the WinAnsi encoding need a marker font and encoder
+// with them, e.g. ZapfDingbats.
+func (l *List) SetBullet(bullet string) {
+	l.numbering = ListBullet
+	l.bullet = bullet
+}
+
+// SetNumbering sets how the items are marked: with bullets, or numbered (decimal, alphabetic or roman).
+func (l *List) SetNumbering(numbering ListNumbering) {
+	l.numbering = numbering
+}
+
+// SetNumberFormat sets the format of the numbers of numbered lists, with %s replaced by the number (default "%s.").
+func (l *List) SetNumberFormat(format string) {
+	l.format = format
+}
+
+// SetStartNumber sets the number of the first item of numbered lists (default 1).
+func (l *List) SetStartNumber(start int) {
+	l.start = start
+}
+
+// SetMarkerStyle sets the style the markers are drawn with.
+func (l *List) SetMarkerStyle(style TextStyle) {
+	l.markerStyle = style
+}
+
+// SetMarkerEncoder sets the text encoding of the markers, for bullets drawn with symbolic fonts.
+func (l *List) SetMarkerEncoder(encoder textencoding.TextEncoder) {
+	l.markerEncoder = encoder
+}
+
+// SetIndent sets the indentation of the items, in which the markers are drawn.
+func (l *List) SetIndent(indent float64) {
+	l.indent = indent
+}
+
+// SetMargins sets the List's margins.
+func (l *List) SetMargins(left, right, top, bottom float64) {
+	l.margins.left = left
+	l.margins.right = right
+	l.margins.top = top
+	l.margins.bottom = bottom
+}
+
+// GetMargins returns the List's margins: left, right, top, bottom.
+func (l *List) GetMargins() (float64, float64, float64, float64) {
+	return l.margins.left, l.margins.right, l.margins.top, l.margins.bottom
+}
+
+// Width is not used as a List is designed to fill into the available width.  Returns 0.
+func (l *List) Width() float64 {
+	return 0
+}
+
+// Height returns the height of the List, with the items stacked on top of each other.  Does not include the margins
+// of the List.
+func (l *List) Height() float64 {
+	h := float64(0.0)
+	for _, item := range l.items {
+		if vd, ok := item.(interface {
+			Height() float64
+		}); ok {
+			h += vd.Height()
+		}
+		switch t := item.(type) {
+		case *Paragraph:
+			h += t.margins.top + t.margins.bottom
+		case *StyledParagraph:
+			h += t.margins.top + t.margins.bottom
+		case *List:
+			h += t.margins.top + t.margins.bottom
+		}
+	}
+	return h
+}
+
+// marker returns the marker of the item with the index, the bullet or the formatted number.
+func (l *List) marker(idx int) string {
+	num := l.start + idx
+	switch l.numbering {
+	case ListDecimal:
+		return fmt.Sprintf(l.format, fmt.Sprintf("%d", num))
+	case ListLowerAlpha:
+		return fmt.Sprintf(l.format, strings.ToLower(alphaNumber(num)))
+	case ListUpperAlpha:
+		return fmt.Sprintf(l.format, alphaNumber(num))
+	case ListLowerRoman:
+		return fmt.Sprintf(l.format, strings.ToLower(romanNumber(num)))
+	case ListUpperRoman:
+		return fmt.Sprintf(l.format, romanNumber(num))
+	}
+	if l.bullet != "" {
+		return l.bullet
+	}
+	return listDefaultBullets[l.level%len(listDefaultBullets)]
+}
+
+// alphaNumber returns the number in letters: A to Z, then AA to ZZ, etc.
+func alphaNumber(num int) string {
+	if num < 1 {
+		return fmt.Sprintf("%d", num)
+	}
+	s := ""
+	for num > 0 {
+		num--
+		s = string(rune('A'+num%26)) + s
+		num /= 26
+	}
+	return s
+}
+
+// romanNumber returns the number in roman numerals, or decimal if out of their range (1 to 3999).
+func romanNumber(num int) string {
+	if num < 1 || num > 3999 {
+		return fmt.Sprintf("%d", num)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	s := ""
+	for i, value := range values {
+		for num >= value {
+			s += symbols[i]
+			num -= value
+		}
+	}
+	return s
+}
+
+// GeneratePageBlocks generates the page blocks.  Multiple blocks are generated if the items wrap over multiple
+// pages.  The marker of an item is drawn on the baseline of its first line of text, or at its top.
+// Implements the Drawable interface.
+func (l *List) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	origCtx := ctx
+
+	// Account for the List margins.
+	ctx.X += l.margins.left
+	ctx.Y += l.margins.top
+	ctx.Width -= l.margins.left + l.margins.right
+	ctx.Height -= l.margins.top
+
+	pageblocks := []*Block{}
+	num := 0
+	for _, item := range l.items {
+		itemCtx := ctx
+		itemCtx.X += l.indent
+		itemCtx.Width -= l.indent
+
+		sub, isList := item.(*List)
+		if isList {
+			sub.level = l.level + 1
+		}
+
+		newblocks, updCtx, err := item.GeneratePageBlocks(itemCtx)
+		if err != nil {
+			common.Log.Debug("Error generating page blocks: %v", err)
+			return nil, ctx, err
+		}
+		if len(newblocks) < 1 {
+			continue
+		}
+
+		if !isList {
+			err := l.drawMarker(newblocks, num, ctx)
+			if err != nil {
+				return nil, ctx, err
+			}
+			num++
+		}
+
+		if len(pageblocks) > 0 {
+			// Merge the first block with the current block and append the rest.
+			pageblocks[len(pageblocks)-1].mergeBlocks(newblocks[0])
+			pageblocks = append(pageblocks, newblocks[1:]...)
+		} else {
+			pageblocks = append(pageblocks, newblocks...)
+		}
+
+		ctx.Page = updCtx.Page
+		ctx.Y = updCtx.Y
+		ctx.Height = updCtx.Height
+	}
+
+	ctx.X = origCtx.X
+	ctx.Width = origCtx.Width
+	ctx.Y += l.margins.bottom
+	ctx.Height -= l.margins.bottom
+	return pageblocks, ctx, nil
+}
+
+// drawMarker draws the marker of the item with the index on the page blocks of the item, in the indent of the list
+// at the position of the context.  The marker is drawn next to the first line of the item, on the first block with
+// contents.
+func (l *List) drawMarker(blocks []*Block, idx int, ctx DrawContext) error {
+	blk := blocks[0]
+	top := ctx.Y
+	for i, b := range blocks {
+		if len(*b.contents) > 0 {
+			blk = b
+			if i > 0 {
+				top = ctx.Margins.top
+			}
+			break
+		}
+	}
+
+	p := NewStyledParagraph(l.marker(idx), l.markerStyle)
+	p.SetEncoder(l.markerEncoder)
+	p.SetEnableWrap(false)
+	y := top
+	if len(blk.lines) > 0 {
+		// On the baseline of the first line.
+		y = blk.lines[0] - p.Height()
+	}
+	p.SetPos(ctx.X+l.indent-listMarkerGap-p.Width(), y)
+
+	markerBlocks, _, err := p.GeneratePageBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, markerBlock := range markerBlocks {
+		// Markers are not text lines of the items.
+		markerBlock.lines = nil
+		err := blk.mergeBlocks(markerBlock)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
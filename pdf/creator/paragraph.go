@@ -8,11 +8,11 @@ package creator
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/unidoc/unidoc/common"
 	"github.com/unidoc/unidoc/pdf/contentstream"
 	"github.com/unidoc/unidoc/pdf/core"
-	"github.com/unidoc/unidoc/pdf/model"
 	"github.com/unidoc/unidoc/pdf/model/fonts"
 	"github.com/unidoc/unidoc/pdf/model/textencoding"
 )
@@ -36,7 +36,7 @@ type Paragraph struct {
 	lineHeight float64
 
 	// The text color.
-	color model.PdfColorDeviceRGB
+	color Color
 
 	// Text alignment: Align left/right/center/justify.
 	alignment TextAlignment
@@ -68,6 +68,16 @@ type Paragraph struct {
 
 	// Text lines after wrapping to available width.
 	textLines []string
+
+	// The target of the link over the paragraph, if any.
+	link *linkTarget
+
+	// Fitting of the text into its box: the range of font sizes to shrink within (0 if disabled), truncation with
+	// an ellipsis and the height of the box (0 if unlimited).  The width of the box is the wrap width.
+	minFontSize float64
+	maxFontSize float64
+	ellipsis    bool
+	maxHeight   float64
 }
 
 // NewParagraph create a new text paragraph. Uses default parameters: Helvetica, WinAnsiEncoding and wrap enabled
@@ -152,9 +162,22 @@ func (p *Paragraph) SetEnableWrap(enableWrap bool) {
 // 3. Make Paragraph blue with arithmetic (0-1) rgb components.
 //      p.SetColor(creator.ColorRGBFromArithmetic(0, 0, 1.0)
 //
+// 4. Make Paragraph cyan in CMYK, for print output.
+//      p.SetColor(creator.ColorCMYKFromArithmetic(1.0, 0, 0, 0)
+//
 func (p *Paragraph) SetColor(col Color) {
-	pdfColor := model.NewPdfColorDeviceRGB(col.ToRGB())
-	p.color = *pdfColor
+	p.color = col
+}
+
+// SetLink makes the paragraph a link opening the URI.
+func (p *Paragraph) SetLink(uri string) {
+	p.link = newURILink(uri)
+}
+
+// SetPageLink makes the paragraph a link going to the position (x, y), from the upper left corner, on the page of
+// the document with the specified number (from 1, including the front page and table of contents).
+func (p *Paragraph) SetPageLink(page int, x, y float64) {
+	p.link = newPageLink(page, x, y)
 }
 
 // SetPos sets absolute positioning with specified coordinates.
@@ -182,6 +205,36 @@ func (p *Paragraph) GetMargins() (float64, float64, float64, float64) {
 	return p.margins.left, p.margins.right, p.margins.top, p.margins.bottom
 }
 
+// SetShrinkToFit enables reducing the font size for the text to fit in its box: the largest font size in the range
+// [minFontSize, maxFontSize] is used for which the text fits in the width (when not wrapped) and in the height of
+// the box, see SetMaxHeight.  Useful for labels, badges or certificates with variable-length data.  Shrinking is
+// disabled if maxFontSize is 0.
+func (p *Paragraph) SetShrinkToFit(minFontSize, maxFontSize float64) error {
+	if maxFontSize != 0 && (minFontSize <= 0 || maxFontSize < minFontSize) {
+		common.Log.Debug("Invalid font size range %f-%f", minFontSize, maxFontSize)
+		return errors.New("Range check error")
+	}
+	p.minFontSize = minFontSize
+	p.maxFontSize = maxFontSize
+	if maxFontSize > 0 {
+		p.fontSize = maxFontSize
+	}
+	return nil
+}
+
+// SetEllipsis enables truncating the text which does not fit in its box (at the minimum font size when shrinking
+// to fit), ending the last line drawn with an ellipsis.
+func (p *Paragraph) SetEllipsis(ellipsis bool) {
+	p.ellipsis = ellipsis
+}
+
+// SetMaxHeight sets the height of the box the text is fitted into with SetShrinkToFit and SetEllipsis, e.g. the
+// height of the Block it is drawn on.  By default (0) only the width is limited: the text is fitted on a single
+// line of the paragraph width when wrapping is disabled.
+func (p *Paragraph) SetMaxHeight(height float64) {
+	p.maxHeight = height
+}
+
 // SetWidth sets the the Paragraph width. This is essentially the wrapping width, i.e. the width the text can extend to
 // prior to wrapping over to next line.
 func (p *Paragraph) SetWidth(width float64) {
@@ -235,9 +288,111 @@ func (p *Paragraph) getTextWidth() float64 {
 	return w
 }
 
+// subParagraph returns a copy of the paragraph containing only the specified lines of its wrapped text.
+func (p *Paragraph) subParagraph(lines []string) *Paragraph {
+	sub := *p
+	sub.text = strings.Join(lines, "\n")
+	sub.textLines = append([]string{}, lines...)
+	return &sub
+}
+
+// wrapText wraps the text into lines, and fits them into the box of the paragraph if enabled.
+func (p *Paragraph) wrapText() error {
+	if p.maxFontSize <= 0 && !p.ellipsis {
+		return p.wrapLines()
+	}
+
+	if p.maxFontSize > 0 {
+		// Shrink by steps of 0.5 point until the text fits.
+		for p.fontSize = p.maxFontSize; ; p.fontSize -= 0.5 {
+			if p.fontSize <= p.minFontSize {
+				p.fontSize = p.minFontSize
+			}
+			if err := p.wrapLines(); err != nil {
+				return err
+			}
+			if p.fontSize == p.minFontSize || p.fitsBox() {
+				break
+			}
+		}
+	} else if err := p.wrapLines(); err != nil {
+		return err
+	}
+
+	if p.ellipsis && !p.fitsBox() {
+		return p.truncateLines()
+	}
+	return nil
+}
+
+// fitsBox returns true if the wrapped lines fit in the box of the paragraph.
+func (p *Paragraph) fitsBox() bool {
+	if h := p.maxHeight; h > 0 && float64(len(p.textLines))*p.lineHeight*p.fontSize > h+1e-6 {
+		return false
+	}
+	if !p.enableWrap && p.wrapWidth > 0 {
+		for _, line := range p.textLines {
+			if p.getLineWidth(line) > p.wrapWidth*1000.0+1e-6 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// truncateLines keeps the lines fitting in the box of the paragraph, ending the truncated ones with an ellipsis.
+func (p *Paragraph) truncateLines() error {
+	ellipsis := "\u2026"
+	if _, found := p.encoder.RuneToGlyph('\u2026'); !found {
+		ellipsis = "..."
+	}
+
+	// The last line kept is truncated if the text continues on the hidden lines.
+	last := -1
+	if p.maxHeight > 0 {
+		n := int(p.maxHeight/(p.lineHeight*p.fontSize) + 1e-6)
+		if n < 1 {
+			n = 1
+		}
+		if n < len(p.textLines) {
+			p.textLines = p.textLines[:n]
+			last = n - 1
+		}
+	}
+
+	maxWidth := p.wrapWidth * 1000.0
+	for i, line := range p.textLines {
+		fits := p.wrapWidth <= 0 || p.getLineWidth(line) <= maxWidth+1e-6
+		if fits && i != last {
+			continue
+		}
+		runes := []rune(strings.TrimRight(line, " "))
+		for len(runes) > 0 && p.wrapWidth > 0 && p.getLineWidth(string(runes)+ellipsis) > maxWidth+1e-6 {
+			runes = runes[:len(runes)-1]
+		}
+		p.textLines[i] = strings.TrimRight(string(runes), " ") + ellipsis
+	}
+	return nil
+}
+
+// getLineWidth calculates the width of a line of text in thousandths of points, ignoring unsupported runes.
+func (p *Paragraph) getLineWidth(line string) float64 {
+	w := float64(0.0)
+	for _, r := range line {
+		glyph, found := p.encoder.RuneToGlyph(r)
+		if !found || glyph == "controlLF" {
+			continue
+		}
+		if metrics, found := p.textFont.GetGlyphCharMetrics(glyph); found {
+			w += p.fontSize * metrics.Wx
+		}
+	}
+	return w
+}
+
 // Simple algorithm to wrap the text into lines (greedy algorithm - fill the lines).
 // XXX/TODO: Consider the Knuth/Plass algorithm or an alternative.
-func (p *Paragraph) wrapText() error {
+func (p *Paragraph) wrapLines() error {
 	if !p.enableWrap {
 		p.textLines = []string{p.text}
 		return nil
@@ -278,16 +433,24 @@ func (p *Paragraph) wrapText() error {
 		w := p.fontSize * metrics.Wx
 		if lineWidth+w > p.wrapWidth*1000.0 {
 			// Goes out of bounds: Wrap.
-			// Breaks on the character.
-			// XXX/TODO: when goes outside: back up to next space, otherwise break on the character.
+			// Breaks at the last break opportunity: after a space or, for CJK text, between characters
+			// (following the kinsoku rules), otherwise breaks on the character.
 			idx := -1
 			for i := len(glyphs) - 1; i >= 0; i-- {
-				if glyphs[i] == "space" {
+				if glyphs[i] == "space" && i > 0 {
+					idx = i
+					break
+				}
+				next := val
+				if i+1 < len(line) {
+					next = line[i+1]
+				}
+				if canBreakBetween(line[i], next) {
 					idx = i
 					break
 				}
 			}
-			if idx > 0 {
+			if idx >= 0 && idx < len(line)-1 {
 				p.textLines = append(p.textLines, string(line[0:idx+1]))
 
 				line = line[idx+1:]
@@ -370,6 +533,10 @@ func (p *Paragraph) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 	}
 
 	// Place the Paragraph on the template at position (x,y) based on the ctx.
+	if p.angle == 0 {
+		// Rotated paragraphs are not linked.
+		blk.addLink(p.link, ctx.X, ctx.Y, p.wrapWidth, p.Height())
+	}
 	ctx, err := drawParagraphOnBlock(blk, p, ctx)
 	if err != nil {
 		common.Log.Debug("ERROR: %v", err)
@@ -416,9 +583,9 @@ func drawParagraphOnBlock(blk *Block, p *Paragraph, ctx DrawContext) (DrawContex
 		cc.RotateDeg(p.angle)
 	}
 
-	cc.Add_BT().
-		Add_rg(p.color.R(), p.color.G(), p.color.B()).
-		Add_Tf(fontName, p.fontSize).
+	cc.Add_BT()
+	addColorOperators(cc, blk, p.color, false)
+	cc.Add_Tf(fontName, p.fontSize).
 		Add_TL(p.fontSize * p.lineHeight)
 
 	for idx, line := range p.textLines {
@@ -505,6 +672,10 @@ func drawParagraphOnBlock(blk *Block, p *Paragraph, ctx DrawContext) (DrawContex
 		}
 
 		cc.Add_TJ(objs...)
+
+		if p.angle == 0 {
+			blk.lines = append(blk.lines, ctx.Y+float64(idx+1)*p.fontSize*p.lineHeight)
+		}
 	}
 	cc.Add_ET()
 	cc.Add_Q()
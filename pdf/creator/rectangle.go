@@ -6,8 +6,8 @@
 package creator
 
 import (
+	"github.com/unidoc/unidoc/pdf/contentstream"
 	"github.com/unidoc/unidoc/pdf/contentstream/draw"
-	"github.com/unidoc/unidoc/pdf/model"
 )
 
 // Rectangle defines a rectangle with upper left corner at (x,y) and a specified width and height.  The rectangle
@@ -18,8 +18,8 @@ type Rectangle struct {
 	y           float64
 	width       float64
 	height      float64
-	fillColor   *model.PdfColorDeviceRGB
-	borderColor *model.PdfColorDeviceRGB
+	fillColor   Color
+	borderColor Color
 	borderWidth float64
 }
 
@@ -32,7 +32,7 @@ func NewRectangle(x, y, width, height float64) *Rectangle {
 	rect.width = width
 	rect.height = height
 
-	rect.borderColor = model.NewPdfColorDeviceRGB(0, 0, 0)
+	rect.borderColor = ColorBlack
 	rect.borderWidth = 1.0
 
 	return rect
@@ -50,18 +50,22 @@ func (rect *Rectangle) SetBorderWidth(bw float64) {
 
 // SetBorderColor sets border color.
 func (rect *Rectangle) SetBorderColor(col Color) {
-	rect.borderColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	rect.borderColor = col
 }
 
 // SetFillColor sets the fill color.
 func (rect *Rectangle) SetFillColor(col Color) {
-	rect.fillColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	rect.fillColor = col
 }
 
 // GeneratePageBlocks draws the rectangle on a new block representing the page. Implements the Drawable interface.
 func (rect *Rectangle) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
 
+	// Set the colors before drawing the shape, which then uses the current colors.
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+
 	drawrect := draw.Rectangle{
 		Opacity: 1.0,
 		X:       rect.x,
@@ -71,11 +75,11 @@ func (rect *Rectangle) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContex
 	}
 	if rect.fillColor != nil {
 		drawrect.FillEnabled = true
-		drawrect.FillColor = rect.fillColor
+		addColorOperators(cc, block, rect.fillColor, false)
 	}
 	if rect.borderColor != nil && rect.borderWidth > 0 {
 		drawrect.BorderEnabled = true
-		drawrect.BorderColor = rect.borderColor
+		addColorOperators(cc, block, rect.borderColor, true)
 		drawrect.BorderWidth = rect.borderWidth
 	}
 
@@ -84,7 +88,7 @@ func (rect *Rectangle) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContex
 		return nil, ctx, err
 	}
 
-	err = block.addContentsByString(string(contents))
+	err = block.addContentsByString(cc.String() + string(contents) + "Q")
 	if err != nil {
 		return nil, ctx, err
 	}
@@ -0,0 +1,177 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"io"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// pageStream is the output of the pages of a creator writing them once complete.
+type pageStream struct {
+	w io.Writer
+
+	// The writer of the pages, nil on the counting pass whose pages are discarded.
+	writer *model.PdfWriter
+
+	totalPages int
+	started    bool
+
+	// The number of pages written or discarded, and the number of the next line when numbering lines.
+	flushed int
+	lineNum int
+
+	// The first error, reported once the document is generated.
+	err error
+}
+
+// WriteStream writes the document generated by the function to w, without keeping all its pages in memory, for large
+// documents such as server-generated reports.  The document is generated twice, each time on a new creator: the
+// first pass counts the pages, discarding them once complete, the second writes each page once complete, with its
+// header and footer drawn knowing the total number of pages.  The function must generate the same pages on both
+// passes, e.g. loading again the pages it imports.
+//
+// Front pages and tables of contents are not supported, links to following pages have no destination, and
+// CheckGlyphCoverage is not available in the function.
+func WriteStream(w io.Writer, generate func(c *Creator) error) error {
+	c := New()
+	c.stream = &pageStream{lineNum: 1}
+	err := c.generateStream(generate)
+	if err != nil {
+		return err
+	}
+	totalPages := len(c.pages)
+
+	pdfWriter := model.NewPdfWriter()
+	c = New()
+	c.stream = &pageStream{w: w, writer: &pdfWriter, totalPages: totalPages, lineNum: 1}
+	err = c.generateStream(generate)
+	if err != nil {
+		return err
+	}
+	if len(c.pages) != totalPages {
+		common.Log.Debug("ERROR: %d pages generated, %d on the first pass", len(c.pages), totalPages)
+		return errors.New("Different pages generated on the second pass")
+	}
+
+	// Form fields.
+	if c.acroForm != nil {
+		err = pdfWriter.SetForms(c.acroForm)
+		if err != nil {
+			common.Log.Debug("Failure: %v", err)
+			return err
+		}
+	}
+
+	return c.writeDocument(&pdfWriter, w)
+}
+
+// generateStream generates the document of a pass, completing its last pages.
+func (c *Creator) generateStream(generate func(c *Creator) error) error {
+	err := generate(c)
+	if err != nil {
+		return err
+	}
+	if c.genFrontPageFunc != nil || c.genTableOfContentFunc != nil {
+		return errors.New("Front page and table of contents not supported when streaming")
+	}
+
+	c.completePages()
+	return c.stream.err
+}
+
+// completePages writes the pages complete when streaming, i.e. all the pages created so far before adding a page or
+// once the document is generated, or discards them on the counting pass.  Errors are kept until the end of the pass.
+func (c *Creator) completePages() {
+	if c.stream == nil || c.stream.err != nil {
+		return
+	}
+
+	// The drawing context of the active page is kept.
+	ctx := c.context
+	c.stream.err = c.flushPages()
+	c.context = ctx
+	c.setActivePage(nil)
+}
+
+// flushPages draws the line numbers, headers and footers of the pages not written yet and writes them.
+func (c *Creator) flushPages() error {
+	s := c.stream
+	if s.writer == nil {
+		for ; s.flushed < len(c.pages); s.flushed++ {
+			delete(c.pageLines, c.pages[s.flushed])
+			c.pages[s.flushed] = nil
+		}
+		return nil
+	}
+
+	// The links going to pages created so far.
+	var pending []pageLink
+	links := c.pageLinks
+	c.pageLinks = nil
+	for _, link := range links {
+		if link.page > len(c.pages) {
+			pending = append(pending, link)
+		} else {
+			c.pageLinks = append(c.pageLinks, link)
+		}
+	}
+	err := c.setPageLinkDests()
+	c.pageLinks = pending
+	if err != nil {
+		common.Log.Debug("Error linking pages: %v", err)
+		return err
+	}
+
+	// Pdf Writer access hook, e.g. to encrypt, before writing the first pages.
+	if !s.started {
+		s.started = true
+		if c.pdfWriterAccessFunc != nil {
+			err := c.pdfWriterAccessFunc(s.writer)
+			if err != nil {
+				common.Log.Debug("Failure: %v", err)
+				return err
+			}
+		}
+	}
+
+	for ; s.flushed < len(c.pages); s.flushed++ {
+		page := c.pages[s.flushed]
+		if c.lineNumbering != nil {
+			if c.lineNumbering.restartEachPage {
+				s.lineNum = 1
+			}
+			c.setActivePage(page)
+			s.lineNum, err = c.drawLineNumbers(c.pageLines[page], s.lineNum)
+			if err != nil {
+				common.Log.Debug("Error drawing line numbers: %v", err)
+				return err
+			}
+			delete(c.pageLines, page)
+		}
+		if c.drawHeaderFunc != nil || c.drawFooterFunc != nil {
+			err = c.drawHeaderFooter(page, s.flushed+1, s.totalPages)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = c.orderAnnotations(page)
+		if err != nil {
+			return err
+		}
+		err = s.writer.AddPage(page)
+		if err != nil {
+			common.Log.Debug("Failed to add Page: %v", err)
+			return err
+		}
+	}
+
+	return s.writer.FlushPages(s.w)
+}
@@ -0,0 +1,530 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// StyledParagraph represents text made of chunks with different styles (fonts, sizes, colors, decorations), which
+// can wrap across lines and pages.  Chunks can link to URIs.
+// By default occupies the available width in the drawing context.
+type StyledParagraph struct {
+	// The chunks of text.
+	chunks []*TextChunk
+
+	// The style of the appended chunks.
+	defaultStyle TextStyle
+
+	// The text encoder which can convert the text (as runes) into a series of glyphs.
+	encoder textencoding.TextEncoder
+
+	// The line relative height (default 1), a factor of the largest font size of each line.
+	lineHeight float64
+
+	// Text alignment: Align left/right/center/justify.
+	alignment TextAlignment
+
+	// Wrapping properties.
+	enableWrap  bool
+	wrapWidth   float64
+	defaultWrap bool
+
+	// Margins to be applied around the block when drawing on Page.
+	margins margins
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos float64
+	yPos float64
+
+	// Text lines after wrapping to available width.
+	lines []styledLine
+}
+
+// TextChunk is a chunk of text of a StyledParagraph drawn with a style.
+type TextChunk struct {
+	Text  string
+	Style TextStyle
+
+	// The URI the chunk links to, if a link.
+	uri string
+}
+
+// styledLine is a line of wrapped text, made of segments of the chunks.
+type styledLine struct {
+	segments []styledSegment
+
+	// Width of the text, without the trailing spaces, and the number of spaces within.
+	width  float64
+	spaces int
+
+	// Height of the line: the line height times the largest font size.
+	height float64
+
+	// Whether the line ends the paragraph or with a line feed, i.e. is not justified.
+	last bool
+}
+
+// styledSegment is the part of a chunk on a line.
+type styledSegment struct {
+	chunk *TextChunk
+	text  []rune
+	width float64
+}
+
+// NewStyledParagraph creates a new styled paragraph with the text as a first chunk of the style, used as the style
+// of the appended chunks.  Uses WinAnsiEncoding and wrap enabled by default.
+func NewStyledParagraph(text string, style TextStyle) *StyledParagraph {
+	p := &StyledParagraph{}
+	p.defaultStyle = style
+	p.encoder = textencoding.NewWinAnsiTextEncoder()
+	p.lineHeight = 1.0
+	p.alignment = TextAlignmentLeft
+
+	p.enableWrap = true
+	p.defaultWrap = true
+	p.positioning = positionRelative
+
+	if text != "" {
+		p.Append(text)
+	}
+
+	return p
+}
+
+// Append adds a chunk of text with the style of the paragraph, which can be changed on the returned chunk.
+func (p *StyledParagraph) Append(text string) *TextChunk {
+	chunk := &TextChunk{Text: text, Style: p.defaultStyle}
+	p.chunks = append(p.chunks, chunk)
+	p.lines = nil
+	return chunk
+}
+
+// AppendLink adds a chunk of text linking to the URI, with the style of the paragraph in blue and underlined, which
+// can be changed on the returned chunk.
+func (p *StyledParagraph) AppendLink(text, uri string) *TextChunk {
+	chunk := p.Append(text)
+	chunk.uri = uri
+	chunk.Style.Color = ColorRGBFrom8bit(0, 0, 238)
+	chunk.Style.Underline = true
+	return chunk
+}
+
+// Chunks returns the chunks of text of the paragraph.
+func (p *StyledParagraph) Chunks() []*TextChunk {
+	return p.chunks
+}
+
+// SetEncoder sets the text encoding.
+func (p *StyledParagraph) SetEncoder(encoder textencoding.TextEncoder) {
+	p.encoder = encoder
+	p.lines = nil
+}
+
+// SetLineHeight sets the line height (1.0 default), relative to the largest font size of each line.
+func (p *StyledParagraph) SetLineHeight(lineheight float64) {
+	p.lineHeight = lineheight
+	p.lines = nil
+}
+
+// SetTextAlignment sets the horizontal alignment of the text within the space provided.
+func (p *StyledParagraph) SetTextAlignment(align TextAlignment) {
+	p.alignment = align
+}
+
+// SetEnableWrap sets the line wrapping enabled flag.
+func (p *StyledParagraph) SetEnableWrap(enableWrap bool) {
+	p.enableWrap = enableWrap
+	p.defaultWrap = false
+	p.lines = nil
+}
+
+// SetMargins sets the StyledParagraph's margins.
+func (p *StyledParagraph) SetMargins(left, right, top, bottom float64) {
+	p.margins.left = left
+	p.margins.right = right
+	p.margins.top = top
+	p.margins.bottom = bottom
+}
+
+// GetMargins returns the StyledParagraph's margins: left, right, top, bottom.
+func (p *StyledParagraph) GetMargins() (float64, float64, float64, float64) {
+	return p.margins.left, p.margins.right, p.margins.top, p.margins.bottom
+}
+
+// SetPos sets absolute positioning with specified coordinates.
+func (p *StyledParagraph) SetPos(x, y float64) {
+	p.positioning = positionAbsolute
+	p.xPos = x
+	p.yPos = y
+}
+
+// SetWidth sets the width of the paragraph, i.e. the width the text can extend to prior to wrapping over to next
+// line.
+func (p *StyledParagraph) SetWidth(width float64) {
+	p.wrapWidth = width
+	p.lines = nil
+}
+
+// Width returns the width of the StyledParagraph.
+func (p *StyledParagraph) Width() float64 {
+	if p.enableWrap {
+		return p.wrapWidth
+	}
+	if err := p.wrapText(); err != nil {
+		return 0
+	}
+	w := float64(0.0)
+	for _, line := range p.lines {
+		if line.width > w {
+			w = line.width
+		}
+	}
+	return w
+}
+
+// Height returns the height of the StyledParagraph, with the text wrapped within the width.  Does not include
+// Margins.
+func (p *StyledParagraph) Height() float64 {
+	if err := p.wrapText(); err != nil {
+		return 0
+	}
+	h := float64(0.0)
+	for _, line := range p.lines {
+		h += line.height
+	}
+	return h
+}
+
+// styledGlyph is a glyph of a chunk.
+type styledGlyph struct {
+	chunk *TextChunk
+	r     rune
+	width float64
+	space bool
+}
+
+// Wraps the text into lines with the greedy algorithm, breaking after spaces or between CJK characters.  The
+// lines are kept until the text or the layout changes.
+func (p *StyledParagraph) wrapText() error {
+	if p.lines != nil {
+		return nil
+	}
+
+	lines := []styledLine{}
+	var line []styledGlyph
+	lineWidth := float64(0.0)
+	lineHeight := p.defaultStyle.FontSize * p.lineHeight
+
+	addLine := func(glyphs []styledGlyph, last bool) {
+		lines = append(lines, p.makeLine(glyphs, last, lineHeight))
+	}
+
+	for _, chunk := range p.chunks {
+		style := chunk.Style
+		font := style.font()
+		if font == nil {
+			common.Log.Debug("ERROR: Text chunk without a font")
+			return errors.New("Missing font")
+		}
+		font.SetEncoder(p.encoder)
+		lineHeight = style.FontSize * p.lineHeight
+
+		for _, r := range chunk.Text {
+			glyph, found := p.encoder.RuneToGlyph(r)
+			if !found {
+				common.Log.Debug("Error! Glyph not found for rune: %v\n", r)
+				return errors.New("Glyph not found for rune")
+			}
+
+			// Newline wrapping.
+			if glyph == "controlLF" {
+				addLine(line, true)
+				line = nil
+				lineWidth = 0
+				continue
+			}
+
+			metrics, found := font.GetGlyphCharMetrics(glyph)
+			if !found {
+				common.Log.Debug("Glyph char metrics not found! %s\n", glyph)
+				return errors.New("Glyph char metrics missing")
+			}
+			g := styledGlyph{chunk: chunk, r: r, width: style.drawnSize() * metrics.Wx / 1000.0, space: glyph == "space"}
+
+			if p.enableWrap && !g.space && lineWidth+g.width > p.wrapWidth && len(line) > 0 {
+				// Breaks at the last break opportunity: after a space or, for CJK text, between characters
+				// (following the kinsoku rules), otherwise breaks on the character.
+				idx := -1
+				for i := len(line) - 1; i >= 0; i-- {
+					if line[i].space && i > 0 {
+						idx = i
+						break
+					}
+					next := r
+					if i+1 < len(line) {
+						next = line[i+1].r
+					}
+					if canBreakBetween(line[i].r, next) {
+						idx = i
+						break
+					}
+				}
+				if idx < 0 {
+					idx = len(line) - 1
+				}
+				addLine(line[:idx+1], false)
+				line = append([]styledGlyph{}, line[idx+1:]...)
+				lineWidth = 0
+				for _, lg := range line {
+					lineWidth += lg.width
+				}
+			}
+
+			line = append(line, g)
+			lineWidth += g.width
+		}
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		addLine(line, true)
+	}
+
+	p.lines = lines
+	return nil
+}
+
+// Makes a line of the glyphs, grouped in segments of the chunks.  The height of an empty line is the height of the
+// text before.
+func (p *StyledParagraph) makeLine(glyphs []styledGlyph, last bool, height float64) styledLine {
+	// Trailing spaces are not drawn.
+	end := len(glyphs)
+	for end > 0 && glyphs[end-1].space {
+		end--
+	}
+
+	line := styledLine{last: last, height: height}
+	if end > 0 {
+		line.height = 0
+	}
+	for _, g := range glyphs[:end] {
+		if n := len(line.segments); n == 0 || line.segments[n-1].chunk != g.chunk {
+			line.segments = append(line.segments, styledSegment{chunk: g.chunk})
+			if h := g.chunk.Style.FontSize * p.lineHeight; h > line.height {
+				line.height = h
+			}
+		}
+		seg := &line.segments[len(line.segments)-1]
+		seg.text = append(seg.text, g.r)
+		seg.width += g.width
+		line.width += g.width
+		if g.space {
+			line.spaces++
+		}
+	}
+	return line
+}
+
+// GeneratePageBlocks generates the page blocks.  Multiple blocks are generated if the contents wrap over
+// multiple pages: the lines which do not fit continue on the next page. Implements the Drawable interface.
+func (p *StyledParagraph) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	origContext := ctx
+	blocks := []*Block{}
+
+	blk := NewBlock(ctx.PageWidth, ctx.PageHeight)
+	if p.positioning.isRelative() {
+		// Account for Paragraph Margins.
+		ctx.X += p.margins.left
+		ctx.Y += p.margins.top
+		ctx.Width -= p.margins.left + p.margins.right
+		ctx.Height -= p.margins.top + p.margins.bottom
+
+		// Use available space.
+		p.SetWidth(ctx.Width)
+	} else {
+		// Absolute.
+		if p.wrapWidth == 0 {
+			// Use necessary space.
+			wrap := p.enableWrap
+			p.enableWrap = false
+			p.SetWidth(p.Width())
+			p.enableWrap = wrap
+		}
+		ctx.X = p.xPos
+		ctx.Y = p.yPos
+	}
+
+	err := p.wrapText()
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	fontNames := map[interface{}]core.PdfObjectName{}
+	drawn := 0
+	for _, line := range p.lines {
+		// A line higher than the page is drawn at the top of a page.
+		atTop := drawn == 0 && ctx.Y <= ctx.Margins.top+p.margins.top
+		if p.positioning.isRelative() && line.height > ctx.Height && !atTop {
+			// Goes out of the bounds.  Continue on a new template at the upper left corner of a new page.
+			blocks = append(blocks, blk)
+			blk = NewBlock(ctx.PageWidth, ctx.PageHeight)
+
+			ctx.Page++
+			ctx.Y = ctx.Margins.top
+			ctx.X = ctx.Margins.left + p.margins.left
+			ctx.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom - p.margins.bottom
+			ctx.Width = ctx.PageWidth - ctx.Margins.left - ctx.Margins.right - p.margins.left - p.margins.right
+			drawn = 0
+		}
+
+		err := p.drawLine(blk, line, ctx, fontNames)
+		if err != nil {
+			common.Log.Debug("ERROR: %v", err)
+			return nil, ctx, err
+		}
+		ctx.Y += line.height
+		ctx.Height -= line.height
+		drawn++
+	}
+	blocks = append(blocks, blk)
+
+	if p.positioning.isRelative() {
+		ctx.Y += p.margins.bottom
+		ctx.Height -= p.margins.bottom
+		ctx.X -= p.margins.left // Move back.
+		ctx.Width = origContext.Width
+		return blocks, ctx, nil
+	}
+	// Absolute: not changing the context.
+	return blocks, origContext, nil
+}
+
+// Draws a line on the block, with the top of the line at the position of the context.
+func (p *StyledParagraph) drawLine(blk *Block, line styledLine, ctx DrawContext,
+	fontNames map[interface{}]core.PdfObjectName) error {
+	baseline := ctx.PageHeight - ctx.Y - line.height
+
+	// Horizontal alignment within the wrap width.
+	x := ctx.X
+	extraSpace := float64(0.0)
+	switch p.alignment {
+	case TextAlignmentRight:
+		x += p.wrapWidth - line.width
+	case TextAlignmentCenter:
+		x += (p.wrapWidth - line.width) / 2
+	case TextAlignmentJustify:
+		if !line.last && line.spaces > 0 {
+			extraSpace = (p.wrapWidth - line.width) / float64(line.spaces)
+		}
+	}
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	decorations := contentstream.NewContentCreator()
+	for _, seg := range line.segments {
+		style := seg.chunk.Style
+		size := style.drawnSize()
+
+		fontName, err := p.registerFont(blk, style.font(), fontNames)
+		if err != nil {
+			return err
+		}
+
+		objs := []core.PdfObject{}
+		encStr := ""
+		spaces := 0
+		for _, runeVal := range seg.text {
+			encStr += string(p.encoder.Encode(string(runeVal)))
+			if glyph, _ := p.encoder.RuneToGlyph(runeVal); glyph == "space" {
+				spaces++
+				if extraSpace != 0 {
+					objs = append(objs, core.MakeString(encStr), core.MakeFloat(-extraSpace*1000.0/size))
+					encStr = ""
+				}
+			}
+		}
+		if len(encStr) > 0 {
+			objs = append(objs, core.MakeString(encStr))
+		}
+
+		cc.Add_BT()
+		addColorOperators(cc, blk, style.Color, false)
+		cc.Add_Tf(fontName, size).
+			Add_Ts(style.rise()).
+			Add_Td(x, baseline).
+			Add_TJ(objs...).
+			Add_ET()
+
+		width := seg.width + float64(spaces)*extraSpace
+		rise := baseline + style.rise()
+		if style.Underline {
+			addColorOperators(decorations, blk, style.Color, false)
+			decorations.Add_re(x, rise+underlineOffsetRatio*size, width, decorationWidthRatio*size).
+				Add_f()
+		}
+		if style.Strikeout {
+			addColorOperators(decorations, blk, style.Color, false)
+			decorations.Add_re(x, rise+strikeoutOffsetRatio*size, width, decorationWidthRatio*size).
+				Add_f()
+		}
+		if seg.chunk.uri != "" {
+			blk.links = append(blk.links, blockLink{
+				rect:       model.PdfRectangle{Llx: x, Lly: rise - 0.25*size, Urx: x + width, Ury: rise + size},
+				linkTarget: *newURILink(seg.chunk.uri),
+			})
+		}
+
+		x += width
+	}
+	*cc.Operations() = append(*cc.Operations(), *decorations.Operations()...)
+	cc.Add_Q()
+
+	ops := cc.Operations()
+	ops.WrapIfNeeded()
+	blk.addContents(ops)
+
+	blk.lines = append(blk.lines, ctx.Y+line.height)
+	return nil
+}
+
+// Adds the font to the resources of the block, once for each font.
+func (p *StyledParagraph) registerFont(blk *Block, font fonts.Font,
+	fontNames map[interface{}]core.PdfObjectName) (core.PdfObjectName, error) {
+	// Fonts which cannot be compared are added for each use.
+	comparable := reflect.TypeOf(font).Comparable()
+	if comparable {
+		if name, has := fontNames[font]; has {
+			return name, nil
+		}
+	}
+
+	// Find a free name for the font.
+	num := 1
+	fontName := core.PdfObjectName(fmt.Sprintf("Font%d", num))
+	for blk.resources.HasFontByName(fontName) {
+		num++
+		fontName = core.PdfObjectName(fmt.Sprintf("Font%d", num))
+	}
+	err := blk.resources.SetFontByName(fontName, font.ToPdfObject())
+	if err != nil {
+		return "", err
+	}
+	if comparable {
+		fontNames[font] = fontName
+	}
+	return fontName, nil
+}
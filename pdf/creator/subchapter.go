@@ -124,7 +124,7 @@ func (subchap *Subchapter) Add(d Drawable) {
 	switch d.(type) {
 	case *Chapter, *Subchapter:
 		common.Log.Debug("Error: Cannot add chapter or subchapter to a subchapter")
-	case *Paragraph, *Image, *Block, *Table, *PageBreak:
+	case *Paragraph, *StyledParagraph, *Image, *Block, *Table, *List, *PageBreak:
 		subchap.contents = append(subchap.contents, d)
 	default:
 		common.Log.Debug("Unsupported: %T", d)
@@ -144,16 +144,18 @@ func (subchap *Subchapter) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawCo
 		ctx.Height -= subchap.margins.top
 	}
 
+	headingY := ctx.Y
 	blocks, ctx, err := subchap.heading.GeneratePageBlocks(ctx)
 	if err != nil {
 		return blocks, ctx, err
 	}
 	if len(blocks) > 1 {
 		ctx.Page++ // did not fit - moved to next Page.
+		headingY = ctx.Margins.top
 	}
 	if subchap.includeInTOC {
 		// Add to TOC.
-		subchap.toc.add(subchap.title, subchap.chapterNum, subchap.subchapterNum, ctx.Page)
+		subchap.toc.add(subchap.title, subchap.chapterNum, subchap.subchapterNum, ctx.Page, headingY)
 	}
 
 	for _, d := range subchap.contents {
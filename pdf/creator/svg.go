@@ -0,0 +1,182 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/svg"
+)
+
+// SVG represents a vector image in SVG format, drawn as a Form XObject.  The original size of the image is its
+// size in points (96 SVG pixels per inch).
+// Implements the Drawable interface.
+type SVG struct {
+	xform *model.XObjectForm
+
+	// The dimensions of the image, as to be placed on the PDF.
+	width, height float64
+
+	// The original dimensions of the image (points).
+	origWidth, origHeight float64
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos float64
+	yPos float64
+
+	// Margins to be applied around the block when drawing on Page.
+	margins margins
+}
+
+// NewSVGFromData creates an SVG image from SVG data.
+func NewSVGFromData(data []byte) (*SVG, error) {
+	img, err := svg.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	xform, err := img.ToXObjectForm()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SVG{}
+	s.xform = xform
+	s.origWidth = img.Width()
+	s.origHeight = img.Height()
+	s.width = s.origWidth
+	s.height = s.origHeight
+	s.positioning = positionRelative
+	return s, nil
+}
+
+// NewSVGFromFile creates an SVG image from an SVG file.
+func NewSVGFromFile(path string) (*SVG, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSVGFromData(data)
+}
+
+// Height returns the SVG image's document height.
+func (s *SVG) Height() float64 {
+	return s.height
+}
+
+// Width returns the SVG image's document width.
+func (s *SVG) Width() float64 {
+	return s.width
+}
+
+// SetMargins sets the margins for the SVG image (in relative mode): left, right, top, bottom.
+func (s *SVG) SetMargins(left, right, top, bottom float64) {
+	s.margins.left = left
+	s.margins.right = right
+	s.margins.top = top
+	s.margins.bottom = bottom
+}
+
+// GetMargins returns the SVG image's margins: left, right, top, bottom.
+func (s *SVG) GetMargins() (float64, float64, float64, float64) {
+	return s.margins.left, s.margins.right, s.margins.top, s.margins.bottom
+}
+
+// SetPos sets the absolute position. Changes object positioning to absolute.
+func (s *SVG) SetPos(x, y float64) {
+	s.positioning = positionAbsolute
+	s.xPos = x
+	s.yPos = y
+}
+
+// Scale scales the SVG image by a constant factor, both width and height.
+func (s *SVG) Scale(xFactor, yFactor float64) {
+	s.width = xFactor * s.width
+	s.height = yFactor * s.height
+}
+
+// ScaleToWidth scales the SVG image to a specified width w, maintaining the aspect ratio.
+func (s *SVG) ScaleToWidth(w float64) {
+	ratio := s.height / s.width
+	s.width = w
+	s.height = w * ratio
+}
+
+// ScaleToHeight scales the SVG image to a specified height h, maintaining the aspect ratio.
+func (s *SVG) ScaleToHeight(h float64) {
+	ratio := s.width / s.height
+	s.height = h
+	s.width = h * ratio
+}
+
+// GeneratePageBlocks generates the page blocks.  Draws the SVG image on a block, on a new page if it does not fit
+// in the remaining height of the page in relative mode.  Implements the Drawable interface.
+func (s *SVG) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	blocks := []*Block{}
+	origCtx := ctx
+
+	blk := NewBlock(ctx.PageWidth, ctx.PageHeight)
+	if s.positioning.isRelative() {
+		if s.height > ctx.Height {
+			// Goes out of the bounds.  Write on a new template instead and create a new context at upper
+			// left corner.
+			blocks = append(blocks, blk)
+			blk = NewBlock(ctx.PageWidth, ctx.PageHeight)
+
+			ctx.Page++
+			newContext := ctx
+			newContext.Y = ctx.Margins.top
+			newContext.X = ctx.Margins.left + s.margins.left
+			newContext.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom - s.margins.bottom
+			newContext.Width = ctx.PageWidth - ctx.Margins.left - ctx.Margins.right - s.margins.left - s.margins.right
+			ctx = newContext
+		} else {
+			ctx.Y += s.margins.top
+			ctx.Height -= s.margins.top + s.margins.bottom
+			ctx.X += s.margins.left
+			ctx.Width -= s.margins.left + s.margins.right
+		}
+	} else {
+		// Absolute.
+		ctx.X = s.xPos
+		ctx.Y = s.yPos
+	}
+
+	// Find a free name for the form.
+	num := 1
+	formName := core.PdfObjectName(fmt.Sprintf("Form%d", num))
+	for blk.resources.HasXObjectByName(formName) {
+		num++
+		formName = core.PdfObjectName(fmt.Sprintf("Form%d", num))
+	}
+	err := blk.resources.SetXObjectFormByName(formName, s.xform)
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	// The form is drawn from its lower left corner, scaled from its original size.
+	cc := contentstream.NewContentCreator()
+	cc.Add_q().
+		Add_cm(s.width/s.origWidth, 0, 0, s.height/s.origHeight, ctx.X, ctx.PageHeight-ctx.Y-s.height).
+		Add_Do(formName).
+		Add_Q()
+	blk.addContents(cc.Operations())
+	blocks = append(blocks, blk)
+
+	if s.positioning.isAbsolute() {
+		// Absolute drawing should not affect context.
+		return blocks, origCtx, nil
+	}
+	ctx.Y += s.height + s.margins.bottom
+	ctx.Height -= s.height + s.margins.bottom
+	return blocks, ctx, nil
+}
@@ -7,9 +7,9 @@ package creator
 
 import (
 	"errors"
+	"math"
 
 	"github.com/unidoc/unidoc/common"
-	"github.com/unidoc/unidoc/pdf/model"
 )
 
 // Table allows organizing content in an rows X columns matrix, which can spawn across multiple pages.
@@ -26,15 +26,25 @@ type Table struct {
 	// Column width fractions: should add up to 1.
 	colWidths []float64
 
+	// Fixed column widths in points, 0 for the columns sharing the remaining width by their fractions.
+	colFixedWidths []float64
+
 	// Row heights.
 	rowHeights []float64
 
+	// Rows whose cells are allowed to be split across pages.
+	rowAllowSplit []bool
+
 	// Default row height.
 	defaultRowHeight float64
 
 	// Content cells.
 	cells []*TableCell
 
+	// Header rows (1-based, inclusive) repeated at the top of each page the table continues on, 0 if none.
+	headerStartRow int
+	headerEndRow   int
+
 	// Positioning: relative / absolute.
 	positioning positioning
 
@@ -61,6 +71,7 @@ func NewTable(cols int) *Table {
 	}
 
 	t.rowHeights = []float64{}
+	t.rowAllowSplit = []bool{}
 
 	// Default row height
 	// XXX/TODO: Base on contents instead?
@@ -81,6 +92,72 @@ func (table *Table) SetColumnWidths(widths ...float64) error {
 	}
 
 	table.colWidths = widths
+	table.colFixedWidths = nil
+
+	return nil
+}
+
+// SetFixedColumnWidths sets column widths in points.  Columns with a width of 0 share the remaining width of the
+// table according to their fractional widths (see SetColumnWidths).
+// The number of width inputs must match number of columns, otherwise an error is returned.
+func (table *Table) SetFixedColumnWidths(widths ...float64) error {
+	if len(widths) != table.cols {
+		common.Log.Debug("Mismatching number of widths and columns")
+		return errors.New("Range check error")
+	}
+	for _, w := range widths {
+		if w < 0 {
+			common.Log.Debug("Negative column width %f", w)
+			return errors.New("Range check error")
+		}
+	}
+
+	table.colFixedWidths = widths
+
+	return nil
+}
+
+// getColumnWidths returns the widths of the columns in points for the table width.
+func (table *Table) getColumnWidths(tableWidth float64) []float64 {
+	widths := make([]float64, table.cols)
+	if table.colFixedWidths == nil {
+		for i := range widths {
+			widths[i] = table.colWidths[i] * tableWidth
+		}
+		return widths
+	}
+
+	// The relative columns share the width remaining after the fixed ones in proportion to their fractions.
+	remaining := tableWidth
+	fractions := float64(0.0)
+	for i, w := range table.colFixedWidths {
+		if w > 0 {
+			remaining -= w
+		} else {
+			fractions += table.colWidths[i]
+		}
+	}
+	remaining = math.Max(remaining, 0)
+	for i, w := range table.colFixedWidths {
+		if w > 0 {
+			widths[i] = w
+		} else if fractions > 0 {
+			widths[i] = table.colWidths[i] / fractions * remaining
+		}
+	}
+	return widths
+}
+
+// SetHeaderRows sets the rows (1-based, inclusive) forming the header of the table, which is repeated at the top
+// of each page the table continues on.
+func (table *Table) SetHeaderRows(startrow, endrow int) error {
+	if startrow < 1 || endrow < startrow {
+		common.Log.Debug("Invalid header rows %d-%d", startrow, endrow)
+		return errors.New("Range check error")
+	}
+
+	table.headerStartRow = startrow
+	table.headerEndRow = endrow
 
 	return nil
 }
@@ -118,6 +195,18 @@ func (table *Table) SetRowHeight(row int, h float64) error {
 	return nil
 }
 
+// SetRowAllowSplit sets whether the cells of the specified row can be split across pages.  When allowed, a row
+// that does not fit on the remaining part of a page is split between lines of the paragraphs it contains, and the
+// paragraphs continue at the top of the next page.  Otherwise (default) the whole row is moved to the next page.
+func (table *Table) SetRowAllowSplit(row int, allow bool) error {
+	if row < 1 || row > len(table.rowAllowSplit) {
+		return errors.New("Range check error")
+	}
+
+	table.rowAllowSplit[row-1] = allow
+	return nil
+}
+
 // CurRow returns the currently active cell's row number.
 func (table *Table) CurRow() int {
 	curRow := (table.curCell-1)/table.cols + 1
@@ -169,26 +258,16 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 	startrow := 0
 
 	// Prepare for drawing: Calculate cell dimensions, row, cell heights.
+	colWidths := table.getColumnWidths(tableWidth)
 	for _, cell := range table.cells {
-		// Get total width fraction
-		wf := float64(0.0)
-		for i := 0; i < cell.colspan; i++ {
-			wf += table.colWidths[cell.col+i-1]
-		}
-		// Get x pos relative to table upper left corner.
-		xrel := float64(0.0)
-		for i := 0; i < cell.col-1; i++ {
-			xrel += table.colWidths[i] * tableWidth
-		}
+		// Get x pos relative to table upper left corner, and the width.
+		xrel, w := cell.getHorizontalExtent(colWidths)
 		// Get y pos relative to table upper left corner.
 		yrel := float64(0.0)
 		for i := startrow; i < cell.row-1; i++ {
 			yrel += table.rowHeights[i]
 		}
 
-		// Calculate the width out of available width.
-		w := wf * tableWidth
-
 		// Get total height.
 		h := float64(0.0)
 		for i := 0; i < cell.rowspan; i++ {
@@ -210,6 +289,19 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 				// Add diff to last row.
 				table.rowHeights[cell.row+cell.rowspan-2] += diffh
 			}
+		case *StyledParagraph:
+			p := t
+			if p.enableWrap {
+				p.SetWidth(w - cell.indent)
+			}
+
+			newh := p.Height() + p.margins.top + p.margins.bottom
+			newh += 0.5 * p.defaultStyle.FontSize * p.lineHeight
+			if newh > h {
+				diffh := newh - h
+				// Add diff to last row.
+				table.rowHeights[cell.row+cell.rowspan-2] += diffh
+			}
 		case *Image:
 			img := t
 			newh := img.Height() + img.margins.top + img.margins.bottom
@@ -218,6 +310,14 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 				// Add diff to last row.
 				table.rowHeights[cell.row+cell.rowspan-2] += diffh
 			}
+		case *Transformed:
+			// The bounding box of the transformed content, e.g. rotated header text.
+			newh := t.Height()
+			if newh > h {
+				diffh := newh - h
+				// Add diff to last row.
+				table.rowHeights[cell.row+cell.rowspan-2] += diffh
+			}
 		case *Division:
 			div := t
 
@@ -257,66 +357,39 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 	}
 
 	// Draw cells.
-	// row height, cell height
-	for _, cell := range table.cells {
-		// Get total width fraction
-		wf := float64(0.0)
-		for i := 0; i < cell.colspan; i++ {
-			wf += table.colWidths[cell.col+i-1]
-		}
-		// Get x pos relative to table upper left corner.
-		xrel := float64(0.0)
-		for i := 0; i < cell.col-1; i++ {
-			xrel += table.colWidths[i] * tableWidth
-		}
-		// Get y pos relative to table upper left corner.
-		yrel := float64(0.0)
-		for i := startrow; i < cell.row-1; i++ {
-			yrel += table.rowHeights[i]
-		}
-
-		// Calculate the width out of available width.
-		w := wf * tableWidth
-
-		// Get total height.
-		h := float64(0.0)
-		for i := 0; i < cell.rowspan; i++ {
-			h += table.rowHeights[cell.row+i-1]
+	// Work with copies of the cells and row heights, as rows that are allowed to split across pages are divided
+	// into two rows when drawn.
+	cells := append([]*TableCell{}, table.cells...)
+	rowHeights := append([]float64{}, table.rowHeights...)
+	rowAllowSplit := append([]bool{}, table.rowAllowSplit...)
+
+	// The header cells repeated on the following pages, and the height of the header.
+	headerCells := []*TableCell{}
+	headerHeight := float64(0.0)
+	if table.headerStartRow > 0 && table.headerEndRow <= table.rows {
+		for _, cell := range table.cells {
+			if cell.row >= table.headerStartRow && cell.row+cell.rowspan-1 <= table.headerEndRow {
+				headerCells = append(headerCells, cell)
+			}
 		}
-
-		ctx.Height = origHeight - yrel
-
-		if h > ctx.Height {
-			// Go to next page.
-			blocks = append(blocks, block)
-			block = NewBlock(ctx.PageWidth, ctx.PageHeight)
-			ulX = ctx.Margins.left
-			ulY = ctx.Margins.top
-			ctx.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom
-
-			startrow = cell.row - 1
-			yrel = 0
+		for i := table.headerStartRow - 1; i < table.headerEndRow; i++ {
+			headerHeight += table.rowHeights[i]
 		}
+	}
 
-		// Height should be how much space there is left of the page.
+	// drawCell draws the cell with its upper left corner at (x, y) and the given size.
+	drawCell := func(cell *TableCell, ctx DrawContext, w, h float64) {
 		ctx.Width = w
-		ctx.X = ulX + xrel
-		ctx.Y = ulY + yrel
+		block.addLink(cell.link, ctx.X, ctx.Y, w, h)
 
 		if cell.backgroundColor != nil {
 			// Draw background (fill)
 			rect := NewRectangle(ctx.X, ctx.Y, w, h)
-			r := cell.backgroundColor.R()
-			g := cell.backgroundColor.G()
-			b := cell.backgroundColor.B()
-			rect.SetFillColor(ColorRGBFromArithmetic(r, g, b))
+			rect.SetFillColor(cell.backgroundColor)
 			if cell.borderStyle != CellBorderStyleNone {
 				// and border.
 				rect.SetBorderWidth(cell.borderWidth)
-				r := cell.borderColor.R()
-				g := cell.borderColor.G()
-				b := cell.borderColor.B()
-				rect.SetBorderColor(ColorRGBFromArithmetic(r, g, b))
+				rect.SetBorderColor(cell.borderColor)
 			} else {
 				rect.SetBorderWidth(0)
 			}
@@ -328,10 +401,7 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 			// Draw border (no fill).
 			rect := NewRectangle(ctx.X, ctx.Y, w, h)
 			rect.SetBorderWidth(cell.borderWidth)
-			r := cell.borderColor.R()
-			g := cell.borderColor.G()
-			b := cell.borderColor.B()
-			rect.SetBorderColor(ColorRGBFromArithmetic(r, g, b))
+			rect.SetBorderColor(cell.borderColor)
 			err := block.Draw(rect)
 			if err != nil {
 				common.Log.Debug("Error: %v\n", err)
@@ -383,6 +453,88 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 				common.Log.Debug("Error: %v\n", err)
 			}
 		}
+	}
+
+	// row height, cell height
+	for idx := 0; idx < len(cells); idx++ {
+		cell := cells[idx]
+		// Get x pos relative to table upper left corner, and the width.
+		xrel, w := cell.getHorizontalExtent(colWidths)
+		// Get y pos relative to table upper left corner.
+		yrel := float64(0.0)
+		for i := startrow; i < cell.row-1; i++ {
+			yrel += rowHeights[i]
+		}
+
+		// Get total height.
+		h := float64(0.0)
+		for i := 0; i < cell.rowspan; i++ {
+			h += rowHeights[cell.row+i-1]
+		}
+
+		ctx.Height = origHeight - yrel
+
+		// Rows joined by cells spanning several rows are kept together on a page: the page break is decided
+		// on the first cell of the first of these rows.
+		needed := h
+		if idx == 0 || cells[idx-1].row != cell.row {
+			needed = math.Max(needed, rowGroupHeight(cells, rowHeights, cell.row))
+		}
+
+		if needed > ctx.Height {
+			if rowAllowSplit[cell.row-1] && needed == h {
+				splitCells, splitHeights, splitAllow, ok := splitTableRow(cells, rowHeights, rowAllowSplit,
+					cell.row, ctx.Height)
+				if ok {
+					// Draw the row again, now consisting of the part fitting on the current page.
+					cells, rowHeights, rowAllowSplit = splitCells, splitHeights, splitAllow
+					idx--
+					continue
+				}
+			}
+
+			// Go to next page.
+			blocks = append(blocks, block)
+			block = NewBlock(ctx.PageWidth, ctx.PageHeight)
+			ulX = ctx.Margins.left
+			ulY = ctx.Margins.top
+			ctx.Height = ctx.PageHeight - ctx.Margins.top - ctx.Margins.bottom
+			origHeight = ctx.Height
+
+			startrow = cell.row - 1
+			yrel = 0
+
+			// Repeat the header rows above the continued table.
+			if len(headerCells) > 0 && cell.row > table.headerEndRow {
+				for _, hcell := range headerCells {
+					hxrel, hw := hcell.getHorizontalExtent(colWidths)
+					hyrel := float64(0.0)
+					for i := table.headerStartRow - 1; i < hcell.row-1; i++ {
+						hyrel += table.rowHeights[i]
+					}
+					hh := float64(0.0)
+					for i := 0; i < hcell.rowspan; i++ {
+						hh += table.rowHeights[hcell.row+i-1]
+					}
+
+					hctx := ctx
+					hctx.X = ulX + hxrel
+					hctx.Y = ulY + hyrel
+					hctx.Height = origHeight - hyrel
+					drawCell(hcell, hctx, hw, hh)
+				}
+				ulY += headerHeight
+				origHeight -= headerHeight
+				ctx.Height = origHeight
+			}
+		}
+
+		// Height should be how much space there is left of the page.
+		ctx.Width = w
+		ctx.X = ulX + xrel
+		ctx.Y = ulY + yrel
+
+		drawCell(cell, ctx, w, h)
 
 		ctx.Y += h
 	}
@@ -402,6 +554,131 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 	return blocks, ctx, nil
 }
 
+// rowGroupHeight returns the height of the rows starting at the specified row (1-based) which are joined by cells
+// spanning several rows, and thus cannot be separated by a page break.
+func rowGroupHeight(cells []*TableCell, rowHeights []float64, row int) float64 {
+	end := row
+	for _, cell := range cells {
+		if cell.row >= row && cell.row <= end && cell.row+cell.rowspan-1 > end {
+			end = cell.row + cell.rowspan - 1
+		}
+	}
+
+	h := float64(0.0)
+	for i := row - 1; i < end && i < len(rowHeights); i++ {
+		h += rowHeights[i]
+	}
+	return h
+}
+
+// splitTableRow splits the specified row (1-based) into two rows: the first one with height avail, containing
+// the paragraph lines that fit within it, and the second one containing the remaining lines.  The rows following
+// the split row are shifted down by one.  Returns the updated cells, row heights and split flags, and false if the
+// row cannot be split (spanning cells or no content fitting within avail).
+func splitTableRow(cells []*TableCell, rowHeights []float64, rowAllowSplit []bool, row int, avail float64) (
+	[]*TableCell, []float64, []bool, bool) {
+	start := -1
+	end := len(cells)
+	for i, cell := range cells {
+		if cell.row < row && cell.row+cell.rowspan-1 >= row {
+			// Cell from a previous row spans into the row.
+			return cells, rowHeights, rowAllowSplit, false
+		}
+		if cell.row == row {
+			if cell.rowspan > 1 {
+				return cells, rowHeights, rowAllowSplit, false
+			}
+			if start < 0 {
+				start = i
+			}
+		}
+		if cell.row > row {
+			end = i
+			break
+		}
+	}
+	if start < 0 {
+		return cells, rowHeights, rowAllowSplit, false
+	}
+
+	heads := []*TableCell{}
+	tails := []*TableCell{}
+	tailHeight := float64(0.0)
+	hasHead := false
+	for _, cell := range cells[start:end] {
+		head := *cell
+		tail := *cell
+		tail.row = row + 1
+		tail.content = nil
+
+		switch t := cell.content.(type) {
+		case nil:
+		case *Paragraph:
+			p := t
+			lh := p.fontSize * p.lineHeight
+			// Vertical space used in addition to the lines, see the calculation of the row heights.
+			pad := p.margins.bottom + p.margins.bottom + 0.5*lh
+
+			if len(p.textLines) == 0 {
+				p.wrapText()
+			}
+			n := int((avail - pad) / lh)
+			if n >= len(p.textLines) {
+				hasHead = true
+				break
+			}
+			if n < 1 {
+				// No line fits: the whole paragraph continues on the next page.
+				head.content = nil
+				tail.content = p
+				tailHeight = math.Max(tailHeight, p.Height()+pad)
+				break
+			}
+
+			first := p.subParagraph(p.textLines[:n])
+			rest := p.subParagraph(p.textLines[n:])
+			head.content = first
+			tail.content = rest
+			tailHeight = math.Max(tailHeight, rest.Height()+pad)
+			hasHead = true
+		default:
+			// Other content is not split, and moved to the next page if it does not fit.
+			if cell.content.Height() > avail {
+				head.content = nil
+				tail.content = cell.content
+				tailHeight = math.Max(tailHeight, cell.content.Height())
+			} else {
+				hasHead = true
+			}
+		}
+
+		heads = append(heads, &head)
+		tails = append(tails, &tail)
+	}
+	if !hasHead {
+		return cells, rowHeights, rowAllowSplit, false
+	}
+
+	newCells := append([]*TableCell{}, cells[:start]...)
+	newCells = append(newCells, heads...)
+	newCells = append(newCells, tails...)
+	for _, cell := range cells[end:] {
+		shifted := *cell
+		shifted.row++
+		newCells = append(newCells, &shifted)
+	}
+
+	h := rowHeights[row-1]
+	newHeights := append([]float64{}, rowHeights[:row-1]...)
+	newHeights = append(newHeights, avail, math.Max(h-avail, tailHeight))
+	newHeights = append(newHeights, rowHeights[row:]...)
+
+	newAllowSplit := append([]bool{}, rowAllowSplit[:row]...)
+	newAllowSplit = append(newAllowSplit, rowAllowSplit[row-1:]...)
+
+	return newCells, newHeights, newAllowSplit, true
+}
+
 // CellBorderStyle defines the table cell's border style.
 type CellBorderStyle int
 
@@ -447,11 +724,11 @@ const (
 // TableCell defines a table cell which can contain a Drawable as content.
 type TableCell struct {
 	// Background
-	backgroundColor *model.PdfColorDeviceRGB
+	backgroundColor Color
 
 	// Border
 	borderStyle CellBorderStyle
-	borderColor *model.PdfColorDeviceRGB
+	borderColor Color
 	borderWidth float64
 
 	// The row and column which the cell starts from.
@@ -473,18 +750,42 @@ type TableCell struct {
 
 	// Table reference
 	table *Table
+
+	// The target of the link over the cell, if any.
+	link *linkTarget
 }
 
 // NewCell makes a new cell and inserts into the table at current position in the table.
 func (table *Table) NewCell() *TableCell {
+	return table.NewMultiCell(1, 1)
+}
+
+// NewMultiCell makes a new cell spanning the specified number of rows and columns, and inserts it into the table at
+// the next free position in the table.  Positions covered by cells spanning from previous rows are skipped over,
+// and the column span is limited to the remaining columns of the row.
+func (table *Table) NewMultiCell(rowspan, colspan int) *TableCell {
+	if rowspan < 1 {
+		rowspan = 1
+	}
+	if colspan < 1 {
+		colspan = 1
+	}
+
 	table.curCell++
+	for table.isCellCovered(table.CurRow(), table.CurCol()) {
+		table.curCell++
+	}
 
-	curRow := (table.curCell-1)/table.cols + 1
-	for curRow > table.rows {
+	curRow := table.CurRow()
+	curCol := table.CurCol()
+	if curCol+colspan-1 > table.cols {
+		colspan = table.cols - curCol + 1
+	}
+	for curRow+rowspan-1 > table.rows {
 		table.rows++
 		table.rowHeights = append(table.rowHeights, table.defaultRowHeight)
+		table.rowAllowSplit = append(table.rowAllowSplit, false)
 	}
-	curCol := (table.curCell-1)%(table.cols) + 1
 
 	cell := &TableCell{}
 	cell.row = curRow
@@ -494,16 +795,17 @@ func (table *Table) NewCell() *TableCell {
 	cell.indent = 5
 
 	cell.borderStyle = CellBorderStyleNone
-	cell.borderColor = model.NewPdfColorDeviceRGB(0, 0, 0)
+	cell.borderColor = ColorBlack
 
 	// Alignment defaults.
 	cell.horizontalAlignment = CellHorizontalAlignmentLeft
 	cell.verticalAlignment = CellVerticalAlignmentTop
 
-	cell.rowspan = 1
-	cell.colspan = 1
+	cell.rowspan = rowspan
+	cell.colspan = colspan
 
 	table.cells = append(table.cells, cell)
+	table.curCell += colspan - 1
 
 	// Keep reference to the table.
 	cell.table = table
@@ -511,6 +813,16 @@ func (table *Table) NewCell() *TableCell {
 	return cell
 }
 
+// isCellCovered returns true if the position at the specified row and column is covered by an existing cell.
+func (table *Table) isCellCovered(row, col int) bool {
+	for _, cell := range table.cells {
+		if row >= cell.row && row < cell.row+cell.rowspan && col >= cell.col && col < cell.col+cell.colspan {
+			return true
+		}
+	}
+	return false
+}
+
 // SkipCells skips over a specified number of cells in the table.
 func (table *Table) SkipCells(num int) {
 	if num < 0 {
@@ -571,26 +883,47 @@ func (cell *TableCell) SetBorder(style CellBorderStyle, width float64) {
 
 // SetBorderColor sets the cell's border color.
 func (cell *TableCell) SetBorderColor(col Color) {
-	cell.borderColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	cell.borderColor = col
 }
 
 // SetBackgroundColor sets the cell's background color.
 func (cell *TableCell) SetBackgroundColor(col Color) {
-	cell.backgroundColor = model.NewPdfColorDeviceRGB(col.ToRGB())
+	cell.backgroundColor = col
 }
 
 // Width returns the cell's width based on the input draw context.
 func (cell *TableCell) Width(ctx DrawContext) float64 {
-	fraction := float64(0.0)
-	for j := 0; j < cell.colspan; j++ {
-		fraction += cell.table.colWidths[cell.col+j-1]
-	}
-	w := ctx.Width * fraction
+	_, w := cell.getHorizontalExtent(cell.table.getColumnWidths(ctx.Width))
 	return w
 }
 
+// getHorizontalExtent returns the x position of the cell relative to the left side of the table and its width,
+// for the given column widths.
+func (cell *TableCell) getHorizontalExtent(colWidths []float64) (float64, float64) {
+	x := float64(0.0)
+	for i := 0; i < cell.col-1; i++ {
+		x += colWidths[i]
+	}
+	w := float64(0.0)
+	for i := 0; i < cell.colspan; i++ {
+		w += colWidths[cell.col+i-1]
+	}
+	return x, w
+}
+
+// SetLink makes the cell a link opening the URI.
+func (cell *TableCell) SetLink(uri string) {
+	cell.link = newURILink(uri)
+}
+
+// SetPageLink makes the cell a link going to the position (x, y), from the upper left corner, on the page of the
+// document with the specified number (from 1, including the front page and table of contents).
+func (cell *TableCell) SetPageLink(page int, x, y float64) {
+	cell.link = newPageLink(page, x, y)
+}
+
 // SetContent sets the cell's content.  The content is a VectorDrawable, i.e. a Drawable with a known height and width.
-// The currently supported VectorDrawable is: *Paragraph.
+// The currently supported VectorDrawables are: *Paragraph, *StyledParagraph, *Image, *Division, *Transformed.
 func (cell *TableCell) SetContent(vd VectorDrawable) error {
 	switch t := vd.(type) {
 	case *Paragraph:
@@ -599,11 +932,20 @@ func (cell *TableCell) SetContent(vd VectorDrawable) error {
 			t.enableWrap = false // No wrapping.
 		}
 
+		cell.content = vd
+	case *StyledParagraph:
+		if t.defaultWrap {
+			// Default paragraph settings in table: no wrapping.
+			t.enableWrap = false
+		}
+
 		cell.content = vd
 	case *Image:
 		cell.content = vd
 	case *Division:
 		cell.content = vd
+	case *Transformed:
+		cell.content = vd
 	default:
 		common.Log.Debug("Error: unsupported cell content type %T\n", vd)
 		return errors.New("Type check error")
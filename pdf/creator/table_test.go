@@ -8,6 +8,7 @@ package creator
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"testing"
 
 	"github.com/unidoc/unidoc/pdf/model/fonts"
@@ -228,3 +229,207 @@ func TestTableWithDiv(t *testing.T) {
 		t.Fatalf("Fail: %v\n", err)
 	}
 }
+
+func TestTableRowAllowSplit(t *testing.T) {
+	c := New()
+
+	text := ""
+	for i := 0; i < 100; i++ {
+		text += fmt.Sprintf("Line %d of a tall paragraph cell which continues on the next page.\n", i+1)
+	}
+
+	table := NewTable(2)
+	table.SetColumnWidths(0.2, 0.8)
+
+	cell := table.NewCell()
+	cell.SetBorder(CellBorderStyleBox, 1)
+	cell.SetContent(NewParagraph("Header"))
+	cell = table.NewCell()
+	cell.SetBorder(CellBorderStyleBox, 1)
+	cell.SetContent(NewParagraph("Value"))
+
+	for _, txt := range []string{"Tall", text} {
+		p := NewParagraph(txt)
+		p.SetEnableWrap(true)
+		cell := table.NewCell()
+		cell.SetBorder(CellBorderStyleBox, 1)
+		cell.SetContent(p)
+	}
+
+	err := table.SetRowAllowSplit(3, true)
+	if err == nil {
+		t.Errorf("Fail: expected range check error for non-existing row")
+		return
+	}
+	err = table.SetRowAllowSplit(2, true)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	ctx := DrawContext{
+		Width:      c.Width() - c.pageMargins.left - c.pageMargins.right,
+		Height:     c.Height() - c.pageMargins.top - c.pageMargins.bottom,
+		X:          c.pageMargins.left,
+		Y:          c.pageMargins.top,
+		PageWidth:  c.Width(),
+		PageHeight: c.Height(),
+		Margins:    c.pageMargins,
+	}
+	blocks, _, err := table.GeneratePageBlocks(ctx)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if len(blocks) < 2 {
+		t.Errorf("Fail: expected the table to wrap over multiple pages (%d)", len(blocks))
+		return
+	}
+
+	// The paragraph lines are split across the pages, the first page holds the header row and the first part
+	// of the tall row.
+	numLines := 0
+	for i, blk := range blocks {
+		if len(blk.lines) == 0 {
+			t.Errorf("Fail: no lines on page %d", i+1)
+			return
+		}
+		numLines += len(blk.lines)
+	}
+	// Header (2) + "Tall" (1) + 100 lines.
+	if numLines != 103 {
+		t.Errorf("Fail: unexpected number of lines %d", numLines)
+		return
+	}
+	if len(blocks[0].lines) < 10 {
+		t.Errorf("Fail: tall row not split on first page (%d lines)", len(blocks[0].lines))
+		return
+	}
+
+	err = c.Draw(table)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	err = c.WriteToFile("/tmp/table_row_split.pdf")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+}
+
+func TestTableColumnWidthsAndSpans(t *testing.T) {
+	table := NewTable(3)
+	table.SetColumnWidths(0.2, 0.5, 0.3)
+	err := table.SetFixedColumnWidths(100, 0)
+	if err == nil {
+		t.Errorf("Fail: expected range check error for mismatching number of widths")
+		return
+	}
+	err = table.SetFixedColumnWidths(100, 0, 0)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	// A 2x2 cell, followed by cells filling the positions it does not cover.
+	cells := []*TableCell{table.NewMultiCell(2, 2), table.NewCell(), table.NewCell(), table.NewMultiCell(1, 5)}
+	expected := []struct {
+		row, col, rowspan, colspan int
+		width                      float64
+	}{
+		{1, 1, 2, 2, 350},
+		{1, 3, 1, 1, 150},
+		{2, 3, 1, 1, 150},
+		{3, 1, 1, 3, 500},
+	}
+	ctx := DrawContext{Width: 500}
+	for i, cell := range cells {
+		exp := expected[i]
+		if cell.row != exp.row || cell.col != exp.col || cell.rowspan != exp.rowspan || cell.colspan != exp.colspan {
+			t.Errorf("Fail: cell %d at %d,%d spanning %dx%d", i, cell.row, cell.col, cell.rowspan, cell.colspan)
+		}
+		if w := cell.Width(ctx); math.Abs(w-exp.width) > 1e-6 {
+			t.Errorf("Fail: cell %d width %f, expected %f", i, w, exp.width)
+		}
+	}
+	if table.rows != 3 {
+		t.Errorf("Fail: unexpected number of rows %d", table.rows)
+	}
+}
+
+func TestTableHeaderRows(t *testing.T) {
+	c := New()
+
+	table := NewTable(2)
+	for _, txt := range []string{"Name", "Value"} {
+		cell := table.NewCell()
+		cell.SetBorder(CellBorderStyleBox, 1)
+		cell.SetBackgroundColor(ColorRGBFrom8bit(200, 200, 200))
+		cell.SetContent(NewParagraph(txt))
+	}
+	for i := 0; i < 100; i++ {
+		cell := table.NewMultiCell(1, 2)
+		cell.SetBorder(CellBorderStyleBox, 1)
+		cell.SetVerticalAlignment(CellVerticalAlignmentMiddle)
+		cell.SetContent(NewParagraph(fmt.Sprintf("Row %d", i+1)))
+	}
+	err := table.SetHeaderRows(1, 0)
+	if err == nil {
+		t.Errorf("Fail: expected range check error for invalid header rows")
+		return
+	}
+	err = table.SetHeaderRows(1, 1)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	ctx := DrawContext{
+		Width:      c.Width() - c.pageMargins.left - c.pageMargins.right,
+		Height:     c.Height() - c.pageMargins.top - c.pageMargins.bottom,
+		X:          c.pageMargins.left,
+		Y:          c.pageMargins.top,
+		PageWidth:  c.Width(),
+		PageHeight: c.Height(),
+		Margins:    c.pageMargins,
+	}
+	blocks, _, err := table.GeneratePageBlocks(ctx)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if len(blocks) < 2 {
+		t.Errorf("Fail: expected the table to wrap over multiple pages (%d)", len(blocks))
+		return
+	}
+
+	// The header (2 lines) is drawn on each page above the 100 rows.
+	numLines := 0
+	for _, blk := range blocks {
+		numLines += len(blk.lines)
+	}
+	if numLines != 100+2*len(blocks) {
+		t.Errorf("Fail: unexpected number of lines %d on %d pages", numLines, len(blocks))
+		return
+	}
+	for i, blk := range blocks[1:] {
+		if len(blk.lines) < 2 || blk.lines[0] > c.pageMargins.top+table.rowHeights[0] {
+			t.Errorf("Fail: header not repeated at the top of page %d", i+2)
+			return
+		}
+	}
+
+	err = c.Draw(table)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	err = c.WriteToFile("/tmp/table_header_rows.pdf")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// SetTagged enables tagging, for accessible documents: the contents drawn are marked as the contents of structure
+// elements (e.g. P for paragraphs or Figure for images), children of a Document element, in the order they are drawn,
+// and the drawables with no meaning of their own, such as shapes, headers and footers, are marked as artifacts.  Must
+// be set before drawing.
+func (c *Creator) SetTagged(tagged bool) {
+	if !tagged {
+		c.structTree = nil
+		c.structDoc = nil
+		return
+	}
+	if c.structTree == nil {
+		c.structTree = model.NewPdfStructTreeRoot()
+		c.structDoc = model.NewPdfStructElement("Document")
+		c.structTree.K = append(c.structTree.K, c.structDoc)
+	}
+}
+
+// SetRoleMap maps the structure types used with DrawTagged to the standard structure types, e.g. "Title" to "H1".
+// Only used when tagging is enabled.
+func (c *Creator) SetRoleMap(roleMap map[string]string) {
+	c.roleMap = roleMap
+}
+
+// StructTreeRoot returns the structure tree of the document when tagging is enabled, for adding elements to it, or
+// nil if not.
+func (c *Creator) StructTreeRoot() *model.PdfStructTreeRoot {
+	return c.structTree
+}
+
+// DrawTagged draws the drawable like Draw, marked as the content of a structure element of the type, with the
+// alternate description alt if not empty, e.g. for figures.  The type can be a custom type mapped to a standard type
+// with SetRoleMap.  Drawn like Draw if tagging is not enabled.
+func (c *Creator) DrawTagged(d Drawable, structType, alt string) error {
+	if c.structTree == nil {
+		return c.Draw(d)
+	}
+	elem := model.NewPdfStructElement(structType)
+	elem.Alt = alt
+	return c.draw(d, elem, "")
+}
+
+// structElement returns the structure element whose content the drawable is marked as by default, or nil if it is
+// marked as an artifact.
+func structElement(d Drawable) *model.PdfStructElement {
+	structType := ""
+	switch t := d.(type) {
+	case *Paragraph, *StyledParagraph:
+		structType = "P"
+	case *Heading:
+		structType = fmt.Sprintf("H%d", t.level)
+		if t.level > 6 {
+			structType = "H6"
+		}
+	case *Image, *Chart, *SVG, *Barcode:
+		structType = "Figure"
+	case *Table, *Grid:
+		structType = "Table"
+	case *List:
+		structType = "L"
+	case *Chapter, *Subchapter:
+		structType = "Sect"
+	case *Division, *Block:
+		structType = "Div"
+	}
+	if structType == "" {
+		return nil
+	}
+	return model.NewPdfStructElement(structType)
+}
+
+// tagBlock returns the block with its contents marked as the next marked content of the element on the page or, if
+// the element is nil, as an artifact of the type if not empty.  Blocks with no content are returned unchanged.
+func (c *Creator) tagBlock(blk *Block, page *model.PdfPage, elem *model.PdfStructElement,
+	artifact string) *Block {
+	if len(*blk.contents) == 0 {
+		return blk
+	}
+
+	var begin *contentstream.ContentStreamOperation
+	switch {
+	case elem != nil:
+		mcid := c.structTree.NewMCID(page)
+		elem.AddMarkedContent(page, mcid)
+		props := core.MakeDict()
+		props.Set("MCID", core.MakeInteger(int64(mcid)))
+		begin = &contentstream.ContentStreamOperation{
+			Operand: "BDC",
+			Params:  []core.PdfObject{core.MakeName(elem.S), props},
+		}
+	case artifact != "":
+		props := core.MakeDict()
+		props.Set("Type", core.MakeName(artifact))
+		begin = &contentstream.ContentStreamOperation{
+			Operand: "BDC",
+			Params:  []core.PdfObject{core.MakeName("Artifact"), props},
+		}
+	default:
+		begin = &contentstream.ContentStreamOperation{
+			Operand: "BMC",
+			Params:  []core.PdfObject{core.MakeName("Artifact")},
+		}
+	}
+
+	dup := blk.duplicate()
+	ops := contentstream.ContentStreamOperations{begin}
+	ops = append(ops, *dup.contents...)
+	ops = append(ops, &contentstream.ContentStreamOperation{Operand: "EMC"})
+	*dup.contents = ops
+	return dup
+}
@@ -0,0 +1,88 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// TextVerticalPosition is the position of text relative to the baseline.
+type TextVerticalPosition int
+
+const (
+	TextPositionNormal TextVerticalPosition = iota
+	TextPositionSuperscript
+	TextPositionSubscript
+)
+
+// Size and rise of superscript and subscript text, relative to the font size.
+const (
+	scriptSizeRatio      = 0.6
+	superscriptRiseRatio = 0.35
+	subscriptRiseRatio   = -0.15
+	decorationWidthRatio = 0.06
+	underlineOffsetRatio = -0.12
+	strikeoutOffsetRatio = 0.3
+)
+
+// TextStyle defines the style of a chunk of text in a StyledParagraph.
+type TextStyle struct {
+	// The font to draw the text with, if no font family is set.
+	Font fonts.Font
+
+	// The font family to draw the text with, in the face selected by the Bold and Italic toggles.
+	Family       *FontFamily
+	Bold, Italic bool
+
+	// The font size (points).  Superscript and subscript text is drawn smaller.
+	FontSize float64
+
+	// The text color.
+	Color Color
+
+	// Lines drawn under and through the text, in the text color.
+	Underline bool
+	Strikeout bool
+
+	// Superscript or subscript text.
+	VerticalPosition TextVerticalPosition
+}
+
+// NewTextStyle returns the default text style: black Helvetica of size 10.
+func NewTextStyle() TextStyle {
+	return TextStyle{
+		Font:     fonts.NewFontHelvetica(),
+		FontSize: 10,
+		Color:    ColorRGBFrom8bit(0, 0, 0),
+	}
+}
+
+// font returns the font the text is drawn with: the face of the family if set, the font otherwise.
+func (style TextStyle) font() fonts.Font {
+	if style.Family != nil {
+		return style.Family.Face(style.Bold, style.Italic)
+	}
+	return style.Font
+}
+
+// drawnSize returns the size the text is drawn with.
+func (style TextStyle) drawnSize() float64 {
+	if style.VerticalPosition != TextPositionNormal {
+		return style.FontSize * scriptSizeRatio
+	}
+	return style.FontSize
+}
+
+// rise returns the displacement of the text from the baseline.
+func (style TextStyle) rise() float64 {
+	switch style.VerticalPosition {
+	case TextPositionSuperscript:
+		return style.FontSize * superscriptRiseRatio
+	case TextPositionSubscript:
+		return style.FontSize * subscriptRiseRatio
+	}
+	return 0
+}
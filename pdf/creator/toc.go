@@ -5,6 +5,18 @@
 
 package creator
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
 // TableOfContents provides an overview over chapters and subchapters when creating a document with Creator.
 type TableOfContents struct {
 	entries []TableOfContentsEntry
@@ -23,12 +35,13 @@ func (toc *TableOfContents) Entries() []TableOfContentsEntry {
 }
 
 // Add a TOC entry.
-func (toc *TableOfContents) add(title string, chapter, subchapter, pageNum int) {
+func (toc *TableOfContents) add(title string, chapter, subchapter, pageNum int, y float64) {
 	entry := TableOfContentsEntry{}
 	entry.Title = title
 	entry.Chapter = chapter
 	entry.Subchapter = subchapter
 	entry.PageNumber = pageNum
+	entry.y = y
 
 	toc.entries = append(toc.entries, entry)
 }
@@ -40,4 +53,251 @@ type TableOfContentsEntry struct {
 	Chapter    int
 	Subchapter int // 0 if chapter
 	PageNumber int // Page number
+
+	// Position of the heading, from the top of the page.
+	y float64
+}
+
+// numberedTitle returns the title prefixed with the chapter and subchapter numbers, e.g. "1.2. Title".
+func (entry TableOfContentsEntry) numberedTitle() string {
+	if entry.Subchapter == 0 {
+		return fmt.Sprintf("%d. %s", entry.Chapter, entry.Title)
+	}
+	return fmt.Sprintf("%d.%d. %s", entry.Chapter, entry.Subchapter, entry.Title)
+}
+
+// Layout of the default table of contents.
+const (
+	tocEntryFontSize  = 12
+	tocEntryIndent    = 20 // Indentation of the subchapter entries.
+	tocEntryLineSpace = 1.5
+)
+
+// CreateDefaultTableOfContents sets the table of contents to be generated as a chapter with the specified title,
+// listing the chapters and subchapters with dot leaders up to their page numbers.  Each entry links to the heading
+// it refers to.
+func (c *Creator) CreateDefaultTableOfContents(title string) {
+	c.CreateTableOfContents(func(toc *TableOfContents) (*Chapter, error) {
+		ch := c.NewChapter(title)
+		ch.GetHeading().SetFontSize(20)
+		ch.GetHeading().SetMargins(0, 0, 0, 20)
+
+		width := c.context.Width - ch.margins.left - ch.margins.right
+		for _, entry := range toc.entries {
+			indent := float64(0)
+			if entry.Subchapter != 0 {
+				indent = tocEntryIndent
+			}
+
+			p := NewParagraph("")
+			p.SetFontSize(tocEntryFontSize)
+			p.SetLineHeight(tocEntryLineSpace)
+			p.SetMargins(indent, 0, 0, 0)
+			p.SetEnableWrap(false)
+			p.SetTextAlignment(TextAlignmentRight)
+			p.SetText(tocLeaderLine(p, entry.numberedTitle(), fmt.Sprintf("%d", entry.PageNumber), width-indent))
+
+			err := ch.Add(p)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return ch, nil
+	})
+	c.tocLinks = true
+}
+
+// tocLeaderLine returns the line of a table of contents entry: the title and the page number separated by as many
+// dots as fit in the width, for the font of the paragraph.
+func tocLeaderLine(p *Paragraph, title, pageNum string, width float64) string {
+	textWidth := func(text string) float64 {
+		p.SetText(text)
+		return p.getTextWidth() / 1000.0
+	}
+
+	line := title + " " + pageNum
+	dotWidth := textWidth(".")
+	if dotWidth <= 0 {
+		return line
+	}
+	// Keeping a space on both sides of the dots.
+	dots := int(math.Floor((width - textWidth(title+"  "+pageNum)) / dotWidth))
+	if dots <= 0 {
+		return line
+	}
+	return title + " " + strings.Repeat(".", dots) + " " + pageNum
+}
+
+// addTableOfContentsLinks adds link annotations over the entries of the default table of contents, drawn on the
+// pages as the blocks.  The entries are the last lines of the blocks.
+func (c *Creator) addTableOfContentsLinks(blocks []*Block, pages []*model.PdfPage) error {
+	type entryLine struct {
+		page     *model.PdfPage
+		baseline float64
+	}
+	lines := []entryLine{}
+	for i, blk := range blocks {
+		for _, y := range blk.lines {
+			lines = append(lines, entryLine{pages[i], y})
+		}
+	}
+
+	entries := c.toc.entries
+	if len(lines) < len(entries) {
+		common.Log.Debug("ERROR: Table of contents has fewer lines than entries (%d < %d)", len(lines), len(entries))
+		return errors.New("Range check error")
+	}
+	lines = lines[len(lines)-len(entries):]
+
+	for i, entry := range entries {
+		dest, err := c.tocEntryDest(entry)
+		if err != nil {
+			return err
+		}
+		if dest == nil {
+			continue
+		}
+
+		page := lines[i].page
+		mbox, err := page.GetMediaBox()
+		if err != nil {
+			return err
+		}
+		// The layout is in points, and the page in the larger units of oversized pages.
+		unit := page.GetUserUnit()
+		baseline := mbox.Ury - lines[i].baseline/unit
+		left := c.pageMargins.left
+		if entry.Subchapter != 0 {
+			left += tocEntryIndent
+		}
+
+		link := model.NewPdfAnnotationLink()
+		link.Rect = core.MakeArrayFromFloats([]float64{
+			mbox.Llx + left/unit, baseline - 0.25*tocEntryFontSize/unit,
+			mbox.Urx - c.pageMargins.right/unit, baseline + tocEntryFontSize/unit,
+		})
+		link.Border = core.MakeArrayFromFloats([]float64{0, 0, 0})
+		link.Dest = dest
+		page.Annotations = append(page.Annotations, link.PdfAnnotation)
+	}
+	return nil
+}
+
+// tocEntryDest returns the destination of the heading of the entry: the position of the heading on its page, or
+// nil if the page is not in the document.
+func (c *Creator) tocEntryDest(entry TableOfContentsEntry) (core.PdfObject, error) {
+	return c.pageDest(entry.PageNumber, entry.y, entry.Title)
+}
+
+// pageDest returns the destination of the titled heading at the position from the top of the page, or nil if the
+// page is not in the document.
+func (c *Creator) pageDest(pageNum int, y float64, title string) (core.PdfObject, error) {
+	idx := pageNum - 1
+	if idx < 0 || idx >= len(c.pages) {
+		common.Log.Debug("Heading %q page %d out of range", title, pageNum)
+		return nil, nil
+	}
+	page := c.pages[idx]
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.MakeArray(page.GetPageAsIndirectObject(), core.MakeName("XYZ"), core.MakeFloat(mbox.Llx),
+		core.MakeFloat(mbox.Ury-y/page.GetUserUnit()), core.MakeNull()), nil
+}
+
+// SetEnableOutlines sets whether the document outline (bookmarks) is generated from the chapters, subchapters and
+// headings.  The outline items link to the headings, with the subchapters nested in their chapters and the headings
+// nested by level.
+func (c *Creator) SetEnableOutlines(enable bool) {
+	c.outlines = enable
+}
+
+// outlineEntry is an entry of the document outline: a chapter, a subchapter or a heading drawn.
+type outlineEntry struct {
+	title string
+	level int
+
+	// Position of the heading: page number and position from the top of the page.
+	page int
+	y    float64
+}
+
+// buildOutlines builds the outline tree of the table of contents entries and the headings, in the order of their
+// positions.
+func (c *Creator) buildOutlines() (*model.PdfOutline, error) {
+	var entries []outlineEntry
+	for _, entry := range c.toc.entries {
+		level := 1
+		if entry.Subchapter != 0 {
+			level = 2
+		}
+		entries = append(entries, outlineEntry{entry.numberedTitle(), level, entry.PageNumber, entry.y})
+	}
+	entries = append(entries, c.headings...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].page != entries[j].page {
+			return entries[i].page < entries[j].page
+		}
+		return entries[i].y < entries[j].y
+	})
+
+	tree := model.NewPdfOutlineTree()
+
+	// Appends the item as the last child of the parent.
+	lastChild := map[*model.PdfOutlineTreeNode]*model.PdfOutlineItem{}
+	appendItem := func(parent *model.PdfOutlineTreeNode, item *model.PdfOutlineItem) {
+		item.Parent = parent
+		if prev, has := lastChild[parent]; has {
+			prev.Next = &item.PdfOutlineTreeNode
+			item.Prev = &prev.PdfOutlineTreeNode
+		} else {
+			parent.First = &item.PdfOutlineTreeNode
+		}
+		parent.Last = &item.PdfOutlineTreeNode
+		lastChild[parent] = item
+	}
+
+	// The items of the enclosing levels, in which the items are nested as open items.
+	type openItem struct {
+		item  *model.PdfOutlineItem
+		level int
+	}
+	var stack []openItem
+	total := int64(0)
+	for _, entry := range entries {
+		dest, err := c.pageDest(entry.page, entry.y, entry.title)
+		if err != nil {
+			return nil, err
+		}
+		if dest == nil {
+			continue
+		}
+
+		item := model.NewPdfOutlineItem()
+		item.Title = core.MakeTextString(entry.title)
+		item.Dest = dest
+		total++
+
+		for len(stack) > 0 && stack[len(stack)-1].level >= entry.level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			appendItem(&tree.PdfOutlineTreeNode, item)
+		} else {
+			appendItem(&stack[len(stack)-1].item.PdfOutlineTreeNode, item)
+			for _, open := range stack {
+				count := int64(1)
+				if open.item.Count != nil {
+					count += *open.item.Count
+				}
+				open.item.Count = &count
+			}
+		}
+		stack = append(stack, openItem{item, entry.level})
+	}
+	tree.Count = &total
+
+	return tree, nil
 }
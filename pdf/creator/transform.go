@@ -0,0 +1,144 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"math"
+
+	"github.com/unidoc/unidoc/pdf/contentstream"
+)
+
+// Transformations of blocks and drawables.  The transformations are in the coordinates of the upper left corner of
+// the block, with angles counter-clockwise as in PDF, and the points given as positions from the upper left corner
+// (y down) as elsewhere in the creator.  Successive transformations are applied in order.
+
+// addTransform returns the transformation t followed by m, t being nil for no transformation.
+func addTransform(t *contentstream.Matrix, m contentstream.Matrix) *contentstream.Matrix {
+	if t != nil {
+		m = t.Mult(m)
+	}
+	return &m
+}
+
+// aboutPoint returns the transformation m about the point (x, y) from the upper left corner.
+func aboutPoint(m contentstream.Matrix, x, y float64) contentstream.Matrix {
+	return contentstream.TranslationMatrix(-x, y).Mult(m).Mult(contentstream.TranslationMatrix(x, -y))
+}
+
+// rotationAbout returns the rotation by angleDeg degrees about the point (x, y).
+func rotationAbout(angleDeg, x, y float64) contentstream.Matrix {
+	return aboutPoint(contentstream.RotationMatrix(angleDeg), x, y)
+}
+
+// scaleAbout returns the scaling by sx, sy about the point (x, y).
+func scaleAbout(sx, sy, x, y float64) contentstream.Matrix {
+	return aboutPoint(contentstream.ScaleMatrix(sx, sy), x, y)
+}
+
+// skewMatrix returns the skew of the x axis by angleYDeg and of the y axis by angleXDeg degrees, i.e. horizontal
+// lines are slanted by angleYDeg and vertical lines by angleXDeg, counter-clockwise.
+func skewMatrix(angleXDeg, angleYDeg float64) contentstream.Matrix {
+	return contentstream.NewMatrix(1, math.Tan(angleYDeg*math.Pi/180), -math.Tan(angleXDeg*math.Pi/180), 1, 0, 0)
+}
+
+// transformedBBox returns the bounding box of the width x height rectangle, from the upper left corner, transformed
+// by t: the offsets of its left and top sides from the upper left corner of the rectangle (positive to the right and
+// down), and its width and height.
+func transformedBBox(t *contentstream.Matrix, width, height float64) (left, top, w, h float64) {
+	if t == nil {
+		return 0, 0, width, height
+	}
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, pt := range [][2]float64{{0, 0}, {width, 0}, {0, -height}, {width, -height}} {
+		x, y := t.Transform(pt[0], pt[1])
+		xMin, xMax = math.Min(xMin, x), math.Max(xMax, x)
+		yMin, yMax = math.Min(yMin, y), math.Max(yMax, y)
+	}
+	return xMin, -yMax, xMax - xMin, yMax - yMin
+}
+
+// Transformed is a VectorDrawable drawn with a transformation: rotated, scaled or skewed about any point.  Its width
+// and height are those of the bounding box of the transformed drawable, which is placed at the current position in
+// relative mode, and moved to the next page if it does not fit.  In absolute mode, the upper left corner of the
+// drawable before transformation is placed at the position.
+// Implements the Drawable interface.
+type Transformed struct {
+	drawable VectorDrawable
+
+	transform *contentstream.Matrix
+
+	// Positioning: relative / absolute.
+	positioning positioning
+
+	// Absolute coordinates (when in absolute mode).
+	xPos, yPos float64
+}
+
+// NewTransformed returns the drawable d drawn with a transformation, initially none.
+func NewTransformed(d VectorDrawable) *Transformed {
+	return &Transformed{drawable: d}
+}
+
+// Rotate rotates the drawable by angleDeg degrees (counter-clockwise) about its upper left corner.
+func (t *Transformed) Rotate(angleDeg float64) {
+	t.transform = addTransform(t.transform, contentstream.RotationMatrix(angleDeg))
+}
+
+// RotateAbout rotates the drawable by angleDeg degrees (counter-clockwise) about the point (x, y), from its upper
+// left corner.
+func (t *Transformed) RotateAbout(angleDeg, x, y float64) {
+	t.transform = addTransform(t.transform, rotationAbout(angleDeg, x, y))
+}
+
+// ScaleAbout scales the drawable by sx, sy about the point (x, y), from its upper left corner.
+func (t *Transformed) ScaleAbout(sx, sy, x, y float64) {
+	t.transform = addTransform(t.transform, scaleAbout(sx, sy, x, y))
+}
+
+// Skew skews the drawable, slanting its vertical lines by angleXDeg and its horizontal lines by angleYDeg degrees
+// (counter-clockwise), about its upper left corner.
+func (t *Transformed) Skew(angleXDeg, angleYDeg float64) {
+	t.transform = addTransform(t.transform, skewMatrix(angleXDeg, angleYDeg))
+}
+
+// Transform applies the transformation matrix m, in the coordinates of the upper left corner of the drawable (y up).
+func (t *Transformed) Transform(m contentstream.Matrix) {
+	t.transform = addTransform(t.transform, m)
+}
+
+// SetPos sets the absolute position of the upper left corner of the drawable before transformation.  Changes object
+// positioning to absolute.
+func (t *Transformed) SetPos(x, y float64) {
+	t.positioning = positionAbsolute
+	t.xPos = x
+	t.yPos = y
+}
+
+// Width returns the width of the bounding box of the transformed drawable.
+func (t *Transformed) Width() float64 {
+	_, _, w, _ := transformedBBox(t.transform, t.drawable.Width(), t.drawable.Height())
+	return w
+}
+
+// Height returns the height of the bounding box of the transformed drawable.
+func (t *Transformed) Height() float64 {
+	_, _, _, h := transformedBBox(t.transform, t.drawable.Width(), t.drawable.Height())
+	return h
+}
+
+// GeneratePageBlocks draws the drawable on a block, and the block transformed.  The drawable must fit on a block of
+// its size, i.e. not wrap across pages.  Implements the Drawable interface.
+func (t *Transformed) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
+	blk := NewBlock(t.drawable.Width(), t.drawable.Height())
+	if err := blk.Draw(t.drawable); err != nil {
+		return nil, ctx, err
+	}
+	blk.transform = t.transform
+	blk.positioning = t.positioning
+	blk.xPos, blk.yPos = t.xPos, t.yPos
+	return blk.GeneratePageBlocks(ctx)
+}
@@ -0,0 +1,89 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+	"strings"
+
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ContentBBox returns the bounding box of the visible content of the page in page coordinates, within the page box
+// (crop box or media box): the text except whitespace and invisible text (rendering modes 3 and 7), the painted
+// paths with half their line width for the strokes and limited to their clipping paths, and the images.  Returns
+// false if the page has no visible content, e.g. for auto-cropping pages to their content.
+func (e *Extractor) ContentBBox() (model.PdfRectangle, bool, error) {
+	var bbox model.PdfRectangle
+	found := false
+	add := func(r model.PdfRectangle) {
+		if e.pageBox != nil {
+			r = intersectRect(r, *e.pageBox)
+		}
+		if r.Urx < r.Llx || r.Ury < r.Lly {
+			return
+		}
+		if found {
+			bbox = unionRect(bbox, r)
+		} else {
+			bbox = r
+			found = true
+		}
+	}
+
+	textMarks, err := e.ExtractTextMarks()
+	if err != nil {
+		return bbox, false, err
+	}
+	for _, mark := range textMarks {
+		if mark.RenderMode == 3 || mark.RenderMode == 7 || strings.TrimSpace(mark.Text) == "" {
+			continue
+		}
+		add(mark.BBox)
+	}
+
+	pathMarks, err := e.ExtractPaths()
+	if err != nil {
+		return bbox, false, err
+	}
+	for _, mark := range pathMarks {
+		if len(mark.Segments) == 0 {
+			continue
+		}
+		r := mark.BBox
+		if mark.Stroke {
+			r.Llx -= mark.LineWidth / 2
+			r.Lly -= mark.LineWidth / 2
+			r.Urx += mark.LineWidth / 2
+			r.Ury += mark.LineWidth / 2
+		}
+		for _, clip := range mark.Clip {
+			r = intersectRect(r, pathBBox(clip.Segments))
+		}
+		add(r)
+	}
+
+	imageMarks, err := e.ExtractImages()
+	if err != nil {
+		return bbox, false, err
+	}
+	for _, mark := range imageMarks {
+		add(mark.BBox)
+	}
+
+	return bbox, found, nil
+}
+
+// intersectRect returns the intersection of the rectangle a with the rectangle b (not necessarily normalized), with
+// Urx < Llx or Ury < Lly if they do not intersect.
+func intersectRect(a, b model.PdfRectangle) model.PdfRectangle {
+	return model.PdfRectangle{
+		Llx: math.Max(a.Llx, math.Min(b.Llx, b.Urx)),
+		Lly: math.Max(a.Lly, math.Min(b.Lly, b.Ury)),
+		Urx: math.Min(a.Urx, math.Max(b.Llx, b.Urx)),
+		Ury: math.Min(a.Ury, math.Max(b.Lly, b.Ury)),
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+const testBBoxContents = `
+q BT /F1 12 Tf 100 700 Td (Text) Tj ET Q
+q BT /F1 12 Tf 3 Tr 10 780 Td (Hidden) Tj ET Q
+q 4 w 50 100 m 150 100 l S Q
+q 200 200 10 10 re W n 0 0 500 500 re f Q
+q 20 0 0 20 400 50 cm BI /W 1 /H 1 /CS /G /BPC 8 ID x EI Q
+q 700 0 10 10 re f Q
+`
+
+func TestContentBBox(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testBBoxContents
+	e.resources = resources
+	e.pageBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+
+	bbox, found, err := e.ContentBBox()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !found {
+		t.Fatalf("No content found")
+	}
+	// The stroke at the left, the image at the bottom and right, the text at the top, and the rectangle off the page
+	// and the hidden text ignored.
+	if math.Abs(bbox.Llx-48) > 0.01 || math.Abs(bbox.Lly-50) > 0.01 || math.Abs(bbox.Urx-420) > 0.01 ||
+		bbox.Ury < 708 || bbox.Ury > 715 {
+		t.Errorf("Wrong bounding box %+v", bbox)
+	}
+
+	e.contents = "q BT /F1 12 Tf 3 Tr 10 780 Td (Hidden) Tj 0 Tr 0 -20 Td (  ) Tj ET Q"
+	_, found, err = e.ContentBBox()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if found {
+		t.Errorf("Invisible content found")
+	}
+}
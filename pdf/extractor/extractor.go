@@ -5,12 +5,27 @@
 
 package extractor
 
-import "github.com/unidoc/unidoc/pdf/model"
+import (
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/model"
+)
 
 // Extractor stores and offers functionality for extracting content from PDF pages.
 type Extractor struct {
 	contents  string
 	resources *model.PdfPageResources
+
+	// The visible region of the page (crop box or media box), if known.
+	pageBox *model.PdfRectangle
+
+	// The size of the page units in points.
+	userUnit float64
+
+	// The language of the document, for text not marked with a language.
+	lang string
+
+	// The handlers of the content registered.
+	handlers contentHandlers
 }
 
 // New returns an Extractor instance for extracting content from the input PDF page.
@@ -24,5 +39,41 @@ func New(page *model.PdfPage) (*Extractor, error) {
 	e.contents = contents
 	e.resources = page.Resources
 
+	e.pageBox, err = page.GetCropBox()
+	if err != nil {
+		// The media box is inheritable and may be missing in broken documents.
+		e.pageBox = page.CropBox
+	}
+	e.userUnit = page.GetUserUnit()
+
 	return e, nil
 }
+
+// UserUnit returns the size of the units of the page coordinates and font sizes extracted, in points (1/72 inch).
+// It is 1 except on oversized pages, e.g. CAD drawings larger than 200 inches, where the page coordinates multiplied
+// by the user unit are the physical dimensions in points.
+func (e *Extractor) UserUnit() float64 {
+	if e.userUnit <= 0 {
+		return 1
+	}
+	return e.userUnit
+}
+
+// SetLanguage sets the language of the document (e.g. from PdfReader.GetLanguage), as the language of the text
+// marks not inside marked content sequences with a language (Lang property).
+func (e *Extractor) SetLanguage(lang string) {
+	e.lang = lang
+}
+
+// processContents processes the content stream of the page and of the Form XObjects it draws, with the handlers
+// registered by the factory.
+func (e *Extractor) processContents(factory contentstream.HandlerFactory) error {
+	cstreamParser := contentstream.NewContentStreamParser(e.contents)
+	operations, err := cstreamParser.Parse()
+	if err != nil {
+		return err
+	}
+
+	processor := contentstream.NewContentStreamProcessor(*operations)
+	return processor.ProcessWithForms(e.resources, factory)
+}
@@ -0,0 +1,349 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/cmap"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// textFont contains the information needed to decode and measure text drawn with a font: the character code
+// length, the mapping to unicode and the glyph widths.
+type textFont struct {
+	// The font resource name and the BaseFont of the font dictionary.
+	name     string
+	baseFont string
+
+	// The font dictionary.
+	dict *core.PdfObjectDictionary
+
+	// Composite (Type0) fonts use multi-byte character codes, mapped to CIDs by the encoding CMap.  2 byte codes
+	// mapped to the same CIDs (Identity-H and Identity-V) if the encoding is nil.
+	composite bool
+	encoding  *cmap.CMap
+
+	// Mapping from character codes to unicode (ToUnicode CMap).
+	toUnicode *cmap.CMap
+
+	// Encoder for simple fonts without a ToUnicode CMap.
+	encoder textencoding.TextEncoder
+
+	// Simple fonts: widths of codes firstChar...
+	firstChar int
+	widths    []float64
+
+	// Composite fonts: widths by CID and the default width.
+	cidWidths    map[int]float64
+	defaultWidth float64
+
+	// Standard 14 font metrics for simple fonts without widths.
+	stdFont fonts.Font
+
+	// Scaling from glyph space to text space (0.001 except for Type3 fonts).
+	glyphScale float64
+
+	// Font ascent and descent in glyph space units.
+	ascent  float64
+	descent float64
+
+	// Style of the font, from the font descriptor or the font name.
+	bold   bool
+	italic bool
+}
+
+// newTextFont loads a textFont from a font dictionary object.
+func newTextFont(name string, obj core.PdfObject) *textFont {
+	font := &textFont{
+		name:         name,
+		defaultWidth: 1000,
+		glyphScale:   0.001,
+		ascent:       800,
+		descent:      -200,
+	}
+
+	dict, ok := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("Font not a dictionary (%T)", obj)
+		font.encoder = textencoding.NewWinAnsiTextEncoder()
+		return font
+	}
+	font.dict = dict
+
+	if baseFont, ok := core.TraceToDirectObject(dict.Get("BaseFont")).(*core.PdfObjectName); ok {
+		font.baseFont = string(*baseFont)
+	}
+
+	if toUnicode, ok := core.TraceToDirectObject(dict.Get("ToUnicode")).(*core.PdfObjectStream); ok {
+		decoded, err := core.DecodeStream(toUnicode)
+		if err == nil {
+			font.toUnicode, err = cmap.LoadCmapFromData(decoded)
+		}
+		if err != nil {
+			common.Log.Debug("Unable to load ToUnicode CMap: %v", err)
+			font.toUnicode = nil
+		}
+	}
+
+	subtype := ""
+	if name, ok := core.TraceToDirectObject(dict.Get("Subtype")).(*core.PdfObjectName); ok {
+		subtype = string(*name)
+	}
+
+	descriptor := dict
+	if subtype == "Type0" {
+		font.composite = true
+		font.cidWidths = map[int]float64{}
+		font.loadEncodingCMap(dict.Get("Encoding"))
+		descendants, ok := core.TraceToDirectObject(dict.Get("DescendantFonts")).(*core.PdfObjectArray)
+		if ok && len(*descendants) > 0 {
+			if cidFont, ok := core.TraceToDirectObject((*descendants)[0]).(*core.PdfObjectDictionary); ok {
+				descriptor = cidFont
+				font.loadCIDWidths(cidFont)
+			}
+		}
+	} else {
+		if subtype == "Type3" {
+			if m, ok := core.TraceToDirectObject(dict.Get("FontMatrix")).(*core.PdfObjectArray); ok {
+				if vals, err := m.ToFloat64Array(); err == nil && len(vals) == 6 {
+					font.glyphScale = vals[0]
+				}
+			}
+		}
+		if firstChar, err := getNumberAsFloat(core.TraceToDirectObject(dict.Get("FirstChar"))); err == nil {
+			font.firstChar = int(firstChar)
+		}
+		if widths, ok := core.TraceToDirectObject(dict.Get("Widths")).(*core.PdfObjectArray); ok {
+			for _, w := range *widths {
+				val, _ := getNumberAsFloat(core.TraceToDirectObject(w))
+				font.widths = append(font.widths, val)
+			}
+		}
+		font.stdFont, font.encoder = getStandardFont(font.baseFont)
+	}
+
+	if fd, ok := core.TraceToDirectObject(descriptor.Get("FontDescriptor")).(*core.PdfObjectDictionary); ok {
+		if ascent, err := getNumberAsFloat(core.TraceToDirectObject(fd.Get("Ascent"))); err == nil && ascent != 0 {
+			font.ascent = ascent
+		}
+		if descent, err := getNumberAsFloat(core.TraceToDirectObject(fd.Get("Descent"))); err == nil && descent != 0 {
+			font.descent = descent
+		}
+		font.loadStyle(fd)
+	}
+	// Styles are commonly indicated by the font name only, e.g. Helvetica-Bold.
+	baseFont := strings.ToLower(font.baseFont)
+	for _, weight := range []string{"bold", "black", "heavy", "demi"} {
+		if strings.Contains(baseFont, weight) {
+			font.bold = true
+		}
+	}
+	if strings.Contains(baseFont, "italic") || strings.Contains(baseFont, "oblique") {
+		font.italic = true
+	}
+
+	return font
+}
+
+// loadStyle sets the style of the font from the flags, weight and italic angle of the font descriptor.
+func (font *textFont) loadStyle(fd *core.PdfObjectDictionary) {
+	if flags, err := getNumberAsFloat(core.TraceToDirectObject(fd.Get("Flags"))); err == nil {
+		// Italic (bit 7) and ForceBold (bit 19).
+		font.italic = int(flags)&(1<<6) != 0
+		font.bold = int(flags)&(1<<18) != 0
+	}
+	if weight, err := getNumberAsFloat(core.TraceToDirectObject(fd.Get("FontWeight"))); err == nil && weight >= 600 {
+		font.bold = true
+	}
+	if angle, err := getNumberAsFloat(core.TraceToDirectObject(fd.Get("ItalicAngle"))); err == nil && angle != 0 {
+		font.italic = true
+	}
+}
+
+// loadEncodingCMap loads the encoding CMap of a composite font, embedded as a stream.  The predefined CMaps other
+// than Identity-H and Identity-V are not available, and are assumed to be Identity as well.
+func (font *textFont) loadEncodingCMap(obj core.PdfObject) {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectName:
+		if *t != "Identity-H" && *t != "Identity-V" {
+			common.Log.Debug("Predefined CMap %s not supported, assuming Identity", *t)
+		}
+	case *core.PdfObjectStream:
+		decoded, err := core.DecodeStream(t)
+		if err == nil {
+			font.encoding, err = cmap.LoadCmapFromData(decoded)
+		}
+		if err != nil {
+			common.Log.Debug("Unable to load encoding CMap: %v", err)
+			font.encoding = nil
+		}
+	}
+}
+
+// loadCIDWidths loads the /W and /DW entries of a CIDFont dictionary.
+func (font *textFont) loadCIDWidths(cidFont *core.PdfObjectDictionary) {
+	if dw, err := getNumberAsFloat(core.TraceToDirectObject(cidFont.Get("DW"))); err == nil {
+		font.defaultWidth = dw
+	}
+
+	w, ok := core.TraceToDirectObject(cidFont.Get("W")).(*core.PdfObjectArray)
+	if !ok {
+		return
+	}
+
+	// The W array consists of entries of the form: c [w1 w2 ... wn] or cfirst clast w.
+	arr := *w
+	for i := 0; i < len(arr); {
+		first, err := getNumberAsFloat(core.TraceToDirectObject(arr[i]))
+		if err != nil || i+1 >= len(arr) {
+			return
+		}
+		if widths, ok := core.TraceToDirectObject(arr[i+1]).(*core.PdfObjectArray); ok {
+			for j, wObj := range *widths {
+				val, _ := getNumberAsFloat(core.TraceToDirectObject(wObj))
+				font.cidWidths[int(first)+j] = val
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(arr) {
+			return
+		}
+		last, err := getNumberAsFloat(core.TraceToDirectObject(arr[i+1]))
+		if err != nil {
+			return
+		}
+		val, _ := getNumberAsFloat(core.TraceToDirectObject(arr[i+2]))
+		for cid := int(first); cid <= int(last); cid++ {
+			font.cidWidths[cid] = val
+		}
+		i += 3
+	}
+}
+
+// getStandardFont returns the standard 14 font metrics and the built-in encoder for the font named baseFont.
+// The metrics are nil if baseFont is not one of the standard 14 fonts.
+func getStandardFont(baseFont string) (fonts.Font, textencoding.TextEncoder) {
+	// Subset fonts are prefixed with a tag, e.g. ABCDEF+Helvetica.
+	if idx := strings.Index(baseFont, "+"); idx == 6 {
+		baseFont = baseFont[idx+1:]
+	}
+
+	switch baseFont {
+	case "Courier":
+		return fonts.NewFontCourier(), textencoding.NewWinAnsiTextEncoder()
+	case "Courier-Bold":
+		return fonts.NewFontCourierBold(), textencoding.NewWinAnsiTextEncoder()
+	case "Courier-BoldOblique":
+		return fonts.NewFontCourierBoldOblique(), textencoding.NewWinAnsiTextEncoder()
+	case "Courier-Oblique":
+		return fonts.NewFontCourierOblique(), textencoding.NewWinAnsiTextEncoder()
+	case "Helvetica":
+		return fonts.NewFontHelvetica(), textencoding.NewWinAnsiTextEncoder()
+	case "Helvetica-Bold":
+		return fonts.NewFontHelveticaBold(), textencoding.NewWinAnsiTextEncoder()
+	case "Helvetica-BoldOblique":
+		return fonts.NewFontHelveticaBoldOblique(), textencoding.NewWinAnsiTextEncoder()
+	case "Helvetica-Oblique":
+		return fonts.NewFontHelveticaOblique(), textencoding.NewWinAnsiTextEncoder()
+	case "Times-Roman":
+		return fonts.NewFontTimesRoman(), textencoding.NewWinAnsiTextEncoder()
+	case "Times-Bold":
+		return fonts.NewFontTimesBold(), textencoding.NewWinAnsiTextEncoder()
+	case "Times-BoldItalic":
+		return fonts.NewFontTimesBoldItalic(), textencoding.NewWinAnsiTextEncoder()
+	case "Times-Italic":
+		return fonts.NewFontTimesItalic(), textencoding.NewWinAnsiTextEncoder()
+	case "Symbol":
+		return fonts.NewFontSymbol(), textencoding.NewSymbolEncoder()
+	case "ZapfDingbats":
+		return fonts.NewFontZapfDingbats(), textencoding.NewZapfDingbatsEncoder()
+	}
+
+	return nil, textencoding.NewWinAnsiTextEncoder()
+}
+
+// splitCharcodes splits the string data of a text showing operator into the individual character codes, of the
+// lengths given by the codespace ranges of the encoding CMap for composite fonts.
+func (font *textFont) splitCharcodes(data []byte) [][]byte {
+	codes := [][]byte{}
+	for i := 0; i < len(data); {
+		n := 1
+		if font.encoding != nil {
+			n = font.encoding.CharcodeLength(data[i:])
+		} else if font.composite {
+			n = 2
+		}
+		end := i + n
+		if end > len(data) {
+			end = len(data)
+		}
+		codes = append(codes, data[i:end])
+		i = end
+	}
+	return codes
+}
+
+// charcodeValue returns the numeric value of a character code.
+func charcodeValue(code []byte) int {
+	val := 0
+	for _, b := range code {
+		val = val<<8 | int(b)
+	}
+	return val
+}
+
+// toUnicodeText returns the unicode text represented by a character code.
+func (font *textFont) toUnicodeText(code []byte) string {
+	if font.toUnicode != nil {
+		return font.toUnicode.CharcodeBytesToUnicode(code)
+	}
+	if font.composite {
+		// No ToUnicode mapping: best effort interpretation of the code as UCS-2.
+		return string(rune(charcodeValue(code)))
+	}
+	if r, ok := font.encoder.CharcodeToRune(code[0]); ok {
+		return string(r)
+	}
+	return string(rune(code[0]))
+}
+
+// glyphWidth returns the width of the glyph for the character code in glyph space units (normally 1/1000 of
+// text space units).
+func (font *textFont) glyphWidth(code []byte) float64 {
+	val := charcodeValue(code)
+	if font.composite {
+		cid := val
+		if font.encoding != nil {
+			// Codes not mapped by the encoding are mapped to CID 0.
+			cid, _ = font.encoding.CharcodeToCID(code)
+		}
+		if w, has := font.cidWidths[cid]; has {
+			return w
+		}
+		return font.defaultWidth
+	}
+
+	idx := val - font.firstChar
+	if idx >= 0 && idx < len(font.widths) {
+		return font.widths[idx]
+	}
+
+	if font.stdFont != nil {
+		if glyph, ok := font.encoder.CharcodeToGlyph(code[0]); ok {
+			if metrics, ok := font.stdFont.GetGlyphCharMetrics(glyph); ok {
+				return metrics.Wx
+			}
+		}
+	}
+
+	common.Log.Trace("Width not found for code %d in font %s", val, font.name)
+	return 0
+}
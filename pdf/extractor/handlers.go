@@ -0,0 +1,171 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ContentState is the state of the extraction when a handler registered on the extractor is called: the graphics
+// state tracked and the position in the content of the page.  It is only valid during the call.
+type ContentState struct {
+	// The graphics state, including the colors and the text state parameters.
+	GraphicsState contentstream.GraphicsState
+
+	// The current transformation matrix from the user space to the page coordinates, accounting for the Form
+	// XObjects containing the operation.  Same as the CTM of the graphics state.
+	CTM contentstream.Matrix
+
+	// The text matrix and the text line matrix, inside text objects.
+	TextMatrix contentstream.Matrix
+	LineMatrix contentstream.Matrix
+
+	// The resources of the content stream of the operation.
+	Resources *model.PdfPageResources
+
+	// The location of the operation in the content streams of the page.
+	Location ContentLocation
+
+	// The marked content sequences which the operation is part of, from the outermost to the innermost, including
+	// those of the content streams drawing the Form XObject containing the operation.
+	MarkedContent []contentstream.MarkedContent
+
+	// The natural language and the marked-content identifier (-1 if none) of the content, as for the text marks.
+	Lang string
+	MCID int
+
+	// The number of text marks extracted so far, including those of the operation, i.e. the index of the next mark.
+	NumMarks int
+}
+
+// Properties returns the property list of a marked content sequence, either given inline or as a named resource of
+// the content stream.
+func (s *ContentState) Properties(mc contentstream.MarkedContent) (*core.PdfObjectDictionary, bool) {
+	return markedContentProperties(mc, s.Resources)
+}
+
+// OperatorHandler is a handler of the content stream operations, called after the operation is processed by the
+// extractor, and before the contents of the Form XObject drawn by a Do operation.  Returning an error stops the
+// extraction.
+type OperatorHandler func(op *contentstream.ContentStreamOperation, state *ContentState) error
+
+// MarkedContentEvent is the kind of marked content operation a MarkedContentHandler is called for.
+type MarkedContentEvent int
+
+const (
+	// Start of a marked content sequence (BMC and BDC), the sequence being the innermost of state.MarkedContent.
+	MarkedContentBegin MarkedContentEvent = iota
+	// End of a marked content sequence (EMC), the sequence being the innermost of state.MarkedContent.
+	MarkedContentEnd
+	// Marked content point (MP and DP).
+	MarkedContentPoint
+)
+
+// MarkedContentHandler is a handler of the marked content sequences and points, e.g. for capturing the tags of a
+// producer.  Returning an error stops the extraction.
+type MarkedContentHandler func(mc contentstream.MarkedContent, event MarkedContentEvent, state *ContentState) error
+
+// operatorHandlerEntry is an operator handler registered, for an operator or for all if empty.
+type operatorHandlerEntry struct {
+	operand string
+	handler OperatorHandler
+}
+
+// markedContentHandlerEntry is a marked content handler registered, for a tag or for all if empty.
+type markedContentHandlerEntry struct {
+	tag     core.PdfObjectName
+	handler MarkedContentHandler
+}
+
+// contentHandlers are the handlers registered on an extractor.
+type contentHandlers struct {
+	operators     []operatorHandlerEntry
+	markedContent []markedContentHandlerEntry
+}
+
+// AddOperatorHandler registers a handler of the operations with the operator, or of all operations if operand is
+// empty.  The handlers are called in the order they are registered while the text marks are extracted (e.g. by
+// ExtractTextMarks, ExtractText or Words), for the operations of the page and of the Form XObjects it draws.
+func (e *Extractor) AddOperatorHandler(operand string, handler OperatorHandler) {
+	e.handlers.operators = append(e.handlers.operators, operatorHandlerEntry{operand, handler})
+}
+
+// AddMarkedContentHandler registers a handler of the marked content sequences and points with the tag, or of all of
+// them if tag is empty.  The handlers are called like the operator handlers (see AddOperatorHandler).
+func (e *Extractor) AddMarkedContentHandler(tag string, handler MarkedContentHandler) {
+	entry := markedContentHandlerEntry{core.PdfObjectName(tag), handler}
+	e.handlers.markedContent = append(e.handlers.markedContent, entry)
+}
+
+// empty returns true if no handlers are registered.
+func (h *contentHandlers) empty() bool {
+	return h == nil || len(h.operators) == 0 && len(h.markedContent) == 0
+}
+
+// callHandlers calls the handlers registered for an operation processed.  Stack is the marked content stack of the
+// content stream of the operation.
+func (col *textMarkCollector) callHandlers(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+	resources *model.PdfPageResources, stack []contentstream.MarkedContent) error {
+	state := &ContentState{
+		GraphicsState: gs,
+		CTM:           gs.CTM,
+		TextMatrix:    col.textMatrix,
+		LineMatrix:    col.lineMatrix,
+		Resources:     resources,
+		Location:      col.location,
+		MarkedContent: append(append([]contentstream.MarkedContent{}, col.markedContent...), stack...),
+		Lang:          col.lang,
+		MCID:          col.mcid,
+		NumMarks:      len(col.marks),
+	}
+
+	for _, entry := range col.handlers.operators {
+		if entry.operand != "" && entry.operand != op.Operand {
+			continue
+		}
+		if err := entry.handler(op, state); err != nil {
+			return err
+		}
+	}
+
+	var mc contentstream.MarkedContent
+	var event MarkedContentEvent
+	switch op.Operand {
+	case "BMC", "BDC", "EMC":
+		if len(stack) == 0 {
+			return nil
+		}
+		mc, event = stack[len(stack)-1], MarkedContentBegin
+		if op.Operand == "EMC" {
+			event = MarkedContentEnd
+		}
+	case "MP", "DP":
+		if len(op.Params) == 0 {
+			return nil
+		}
+		tag, ok := op.Params[0].(*core.PdfObjectName)
+		if !ok {
+			return nil
+		}
+		mc, event = contentstream.MarkedContent{Tag: *tag}, MarkedContentPoint
+		if len(op.Params) > 1 {
+			mc.Properties = op.Params[1]
+		}
+	default:
+		return nil
+	}
+	for _, entry := range col.handlers.markedContent {
+		if entry.tag != "" && entry.tag != mc.Tag {
+			continue
+		}
+		if err := entry.handler(mc, event, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+func TestContentHandlers(t *testing.T) {
+	form := model.NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, 100, 100})
+	err := form.SetContentStream([]byte("/Prod <</Kind (note)>> BDC BT /F1 12 Tf 10 10 Td (In) Tj ET EMC /Pt MP"), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	resources := model.NewPdfPageResources()
+	resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	resources.SetXObjectFormByName("Fm1", form)
+	contents := "/Span <</MCID 0>> BDC q 2 0 0 2 0 0 cm /Fm1 Do Q EMC BT /F1 10 Tf 0 0 Td (Out) Tj ET"
+
+	e := Extractor{contents: contents, resources: resources}
+	fontSizes := []float64{}
+	e.AddOperatorHandler("Tf", func(op *contentstream.ContentStreamOperation, state *ContentState) error {
+		fontSizes = append(fontSizes, state.GraphicsState.FontSize)
+		return nil
+	})
+	events := []string{}
+	e.AddMarkedContentHandler("", func(mc contentstream.MarkedContent, event MarkedContentEvent,
+		state *ContentState) error {
+		tags := []string{}
+		for _, entry := range state.MarkedContent {
+			tags = append(tags, string(entry.Tag))
+		}
+		events = append(events, fmt.Sprintf("%s %d %s %d", mc.Tag, event, strings.Join(tags, "/"), state.NumMarks))
+		return nil
+	})
+	kinds := []string{}
+	e.AddMarkedContentHandler("Prod", func(mc contentstream.MarkedContent, event MarkedContentEvent,
+		state *ContentState) error {
+		if event != MarkedContentBegin {
+			return nil
+		}
+		if state.CTM[0] != 2 || state.Location.Forms[0] != "Fm1" || state.MCID != 0 {
+			t.Errorf("Wrong state %v", state)
+		}
+		props, ok := state.Properties(mc)
+		if !ok {
+			t.Fatalf("No properties")
+		}
+		kind, _ := props.Get("Kind").(*core.PdfObjectString)
+		kinds = append(kinds, string(*kind))
+		return nil
+	})
+
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(marks) != 5 {
+		t.Fatalf("Wrong number of marks %d", len(marks))
+	}
+	if fmt.Sprint(fontSizes) != "[12 10]" {
+		t.Errorf("Wrong font sizes %v", fontSizes)
+	}
+	expected := []string{"Span 0 Span 0", "Prod 0 Span/Prod 0", "Prod 1 Span/Prod 2", "Pt 2 Span 2", "Span 1 Span 2"}
+	if strings.Join(events, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Wrong events %q", events)
+	}
+	if len(kinds) != 1 || kinds[0] != "note" {
+		t.Errorf("Wrong kinds %q", kinds)
+	}
+
+	// Errors of the handlers stop the extraction.
+	e.AddOperatorHandler("", func(op *contentstream.ContentStreamOperation, state *ContentState) error {
+		if op.Operand == "Do" {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	if _, err := e.ExtractTextMarks(); err == nil || err.Error() != "stop" {
+		t.Errorf("Wrong error %v", err)
+	}
+}
@@ -0,0 +1,488 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"errors"
+	goimage "image"
+	gocolor "image/color"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ImageMark represents an image drawn on the page, either an image XObject or an inline image, along with its
+// placement.
+type ImageMark struct {
+	// The decoded image samples and the colorspace for interpreting them.
+	Image      *model.Image
+	ColorSpace model.PdfColorspace
+
+	// The Decode array of the image, mapping sample values to colorspace component ranges.  Nil for the default.
+	Decode []float64
+
+	// The XObject resource name of the image.  Empty for inline images.
+	Name   string
+	Inline bool
+
+	// The stream of the image XObject.  Nil for inline images.
+	Stream *core.PdfObjectStream
+
+	// Stencil masks (ImageMask) are painted with the fill color at the time of drawing.
+	ImageMask bool
+	Color     model.PdfColor
+
+	// The soft mask (SMask) of the image with its Decode array, if any.  The soft mask samples are gray levels
+	// specifying the opacity of the image.
+	SMask       *model.Image
+	SMaskDecode []float64
+
+	// The color key mask (Mask array) of the image, if any: pairs of minimum and maximum sample values for each
+	// color component.  Pixels with all components in the ranges are not painted.
+	ColorKeyMask []int
+
+	// The current transformation matrix when drawn, which maps the unit square to the image placement.
+	CTM contentstream.Matrix
+
+	// The quadrilateral of the image placement in page coordinates: the lower left, lower right, upper right and
+	// upper left corners of the image, i.e. the last row of the image is drawn along the first edge.
+	Quad [4]draw.Point
+
+	// The axis aligned bounding box of the quadrilateral.
+	BBox model.PdfRectangle
+
+	// The fill color converted to RGB, for stencil masks.
+	fillRGB gocolor.NRGBA
+}
+
+// imageMarkCollector collects image marks while processing content streams.
+type imageMarkCollector struct {
+	marks []ImageMark
+
+	// Marks of image XObjects by stream, as images are commonly drawn repeatedly.
+	cache map[*core.PdfObjectStream]*ImageMark
+}
+
+// ExtractImages processes the content streams and returns all images drawn on the page as ImageMarks in the order
+// in which they appear in the content stream.  Images drawn inside Form XObjects are included.  Images that cannot
+// be decoded, e.g. with unsupported filters such as JPXDecode, are skipped.
+func (e *Extractor) ExtractImages() ([]ImageMark, error) {
+	col := &imageMarkCollector{}
+	col.cache = map[*core.PdfObjectStream]*ImageMark{}
+
+	err := e.processContents(col.addHandlers)
+	if err != nil {
+		return col.marks, err
+	}
+
+	return col.marks, nil
+}
+
+// addHandlers registers the handlers collecting the images of a content stream.
+func (col *imageMarkCollector) addHandlers(processor *contentstream.ContentStreamProcessor,
+	form *contentstream.DrawnForm) (func() error, error) {
+	processor.AddCategoryHandler(contentstream.OperatorCategoryInlineImage, col.handleInlineImage)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryXObject, col.handleXObject)
+	return nil, nil
+}
+
+// handleInlineImage collects the mark of an inline image (BI).
+func (col *imageMarkCollector) handleInlineImage(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	if len(op.Params) != 1 {
+		return nil
+	}
+	iimg, ok := op.Params[0].(*contentstream.ContentStreamInlineImage)
+	if !ok {
+		return nil
+	}
+	mark, err := newInlineImageMark(iimg, resources)
+	if err != nil {
+		common.Log.Debug("Skipping inline image: %v", err)
+		return nil
+	}
+	col.addMark(*mark, gs)
+	return nil
+}
+
+// handleXObject collects the mark of an image XObject drawn with the Do operator.
+func (col *imageMarkCollector) handleXObject(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+	resources *model.PdfPageResources) error {
+	if len(op.Params) != 1 {
+		return nil
+	}
+	name, ok := op.Params[0].(*core.PdfObjectName)
+	if !ok {
+		return nil
+	}
+	stream, xtype := resources.GetXObjectByName(*name)
+	if xtype != model.XObjectTypeImage {
+		return nil
+	}
+	mark, has := col.cache[stream]
+	if !has {
+		var err error
+		mark, err = newXObjectImageMark(stream)
+		if err != nil {
+			common.Log.Debug("Skipping image %s: %v", *name, err)
+		}
+		col.cache[stream] = mark
+	}
+	if mark != nil {
+		m := *mark
+		m.Name = string(*name)
+		m.Stream = stream
+		col.addMark(m, gs)
+	}
+
+	return nil
+}
+
+// addMark adds an image mark placed with the current transformation matrix.
+func (col *imageMarkCollector) addMark(mark ImageMark, gs contentstream.GraphicsState) {
+	mark.Place(gs.CTM, gs)
+	col.marks = append(col.marks, mark)
+}
+
+// Place sets the placement of the image drawn with the transformation matrix ctm, and the color of stencil masks
+// from the graphics state gs.
+func (m *ImageMark) Place(ctm contentstream.Matrix, gs contentstream.GraphicsState) {
+	m.CTM = ctm
+	corners := [4][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	for i, c := range corners {
+		x, y := ctm.Transform(c[0], c[1])
+		m.Quad[i] = draw.NewPoint(x, y)
+	}
+	m.BBox = quadBBox(m.Quad)
+
+	if m.ImageMask {
+		m.Color = gs.ColorNonStroking
+		rgb, _ := getRGB(gs.ColorspaceNonStroking, gs.ColorNonStroking)
+		m.fillRGB = gocolor.NRGBA{R: toByte(rgb[0]), G: toByte(rgb[1]), B: toByte(rgb[2]), A: 0xff}
+	}
+}
+
+// NewImageMark loads and decodes an image XObject and its soft mask.  The returned mark is not placed on the page,
+// see Place.
+func NewImageMark(stream *core.PdfObjectStream) (*ImageMark, error) {
+	return newXObjectImageMark(stream)
+}
+
+// NewInlineImageMark decodes an inline image with the resources of the content stream.  The returned mark is not
+// placed on the page, see Place.
+func NewInlineImageMark(iimg *contentstream.ContentStreamInlineImage, resources *model.PdfPageResources) (
+	*ImageMark, error) {
+	return newInlineImageMark(iimg, resources)
+}
+
+// newXObjectImageMark loads and decodes an image XObject and its soft mask.
+func newXObjectImageMark(stream *core.PdfObjectStream) (*ImageMark, error) {
+	ximg, err := model.NewXObjectImageFromStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	mark := &ImageMark{}
+	if b, ok := core.TraceToDirectObject(ximg.ImageMask).(*core.PdfObjectBool); ok && bool(*b) {
+		// Stencil masks are 1 bit per pixel without a colorspace.
+		mark.ImageMask = true
+		bpc := int64(1)
+		ximg.BitsPerComponent = &bpc
+		ximg.ColorSpace = model.NewPdfColorspaceDeviceGray()
+	}
+
+	img, err := ximg.ToImage()
+	if err != nil {
+		return nil, err
+	}
+	mark.Image = img
+	mark.ColorSpace = ximg.ColorSpace
+	mark.Decode, err = getDecodeArray(ximg.Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	if !mark.ImageMask {
+		mark.ColorKeyMask, err = ximg.GetColorKeyMask()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if smaskStream, ok := core.TraceToDirectObject(ximg.SMask).(*core.PdfObjectStream); ok && !mark.ImageMask {
+		smask, err := model.NewXObjectImageFromStream(smaskStream)
+		if err != nil {
+			return nil, err
+		}
+		mark.SMask, err = smask.ToImage()
+		if err != nil {
+			return nil, err
+		}
+		mark.SMaskDecode, err = getDecodeArray(smask.Decode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mark, nil
+}
+
+// newInlineImageMark decodes an inline image.
+func newInlineImageMark(iimg *contentstream.ContentStreamInlineImage, resources *model.PdfPageResources) (*ImageMark, error) {
+	img, err := iimg.ToImage(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	mark := &ImageMark{Image: img, Inline: true}
+	mark.ImageMask, err = iimg.IsMask()
+	if err != nil {
+		return nil, err
+	}
+	if mark.ImageMask {
+		mark.ColorSpace = model.NewPdfColorspaceDeviceGray()
+	} else {
+		mark.ColorSpace, err = iimg.GetColorSpace(resources)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mark.Decode, err = getDecodeArray(iimg.Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	return mark, nil
+}
+
+// getDecodeArray returns the values of a Decode array, or nil if not specified.
+func getDecodeArray(obj core.PdfObject) ([]float64, error) {
+	obj = core.TraceToDirectObject(obj)
+	if obj == nil {
+		return nil, nil
+	}
+	arr, ok := obj.(*core.PdfObjectArray)
+	if !ok {
+		common.Log.Debug("Invalid Decode object (%T)", obj)
+		return nil, errors.New("Type check error")
+	}
+	return arr.ToFloat64Array()
+}
+
+// ToGoImage converts the image to a Go image with the colors converted to RGB.  Indexed palettes, the Decode array,
+// the color key mask and the soft mask are applied, and stencil masks are rendered in their fill color on a transparent background.
+func (m *ImageMark) ToGoImage() (goimage.Image, error) {
+	img := m.Image
+	if img == nil {
+		return nil, errors.New("No image data")
+	}
+	width, height := int(img.Width), int(img.Height)
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("Invalid image dimensions")
+	}
+
+	out := goimage.NewNRGBA(goimage.Rect(0, 0, width, height))
+	samples := newSampleReader(img)
+
+	if m.ImageMask {
+		// Sample value 0 paints the fill color, unless inverted by the Decode array [1 0].
+		paint := uint32(0)
+		if len(m.Decode) >= 2 && m.Decode[0] > m.Decode[1] {
+			paint = 1
+		}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if samples.get(x, y, 0) == paint {
+					out.SetNRGBA(x, y, m.fillRGB)
+				}
+			}
+		}
+		return out, nil
+	}
+
+	cs := m.ColorSpace
+	if cs == nil {
+		cs = model.NewPdfColorspaceDeviceGray()
+	}
+	numComps := img.ColorComponents
+	if numComps != cs.GetNumComponents() {
+		common.Log.Debug("Image components (%d) not matching colorspace %s", numComps, cs)
+		return nil, errors.New("Colorspace mismatch")
+	}
+	decode := m.Decode
+	if len(decode) != 2*numComps {
+		if _, isIndexed := cs.(*model.PdfColorspaceSpecialIndexed); isIndexed {
+			decode = []float64{0, float64(samples.maxVal)}
+		} else {
+			decode = cs.DecodeArray()
+		}
+	}
+
+	// Converted colors by sample values, for images with few distinct sample values.
+	var cache map[[4]uint32]gocolor.NRGBA
+	if numComps <= 4 && numComps*int(img.BitsPerComponent) <= 16 {
+		cache = map[[4]uint32]gocolor.NRGBA{}
+	}
+
+	// The soft mask takes precedence over the color key mask.
+	colorKey := m.ColorKeyMask
+	if m.SMask != nil {
+		colorKey = nil
+	} else if len(colorKey) != 2*numComps {
+		if colorKey != nil {
+			common.Log.Debug("Color key mask %v not matching %d components, ignored", colorKey, numComps)
+		}
+		colorKey = nil
+	}
+
+	vals := make([]float64, numComps)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var key [4]uint32
+			masked := colorKey != nil
+			for i := 0; i < numComps; i++ {
+				s := samples.get(x, y, i)
+				if i < 4 {
+					key[i] = s
+				}
+				vals[i] = decode[2*i] + float64(s)*(decode[2*i+1]-decode[2*i])/float64(samples.maxVal)
+				if masked && (int(s) < colorKey[2*i] || int(s) > colorKey[2*i+1]) {
+					masked = false
+				}
+			}
+			if masked {
+				// Transparent, as initialized.
+				continue
+			}
+
+			c, has := cache[key]
+			if !has {
+				var err error
+				c, err = colorToNRGBA(cs, vals)
+				if err != nil {
+					return nil, err
+				}
+				if cache != nil {
+					cache[key] = c
+				}
+			}
+			out.SetNRGBA(x, y, c)
+		}
+	}
+
+	if m.SMask != nil {
+		m.applySMask(out)
+	}
+
+	return out, nil
+}
+
+// applySMask sets the alpha channel of the image from the soft mask, scaled to the image size if needed.
+func (m *ImageMark) applySMask(out *goimage.NRGBA) {
+	smask := m.SMask
+	sw, sh := int(smask.Width), int(smask.Height)
+	if sw <= 0 || sh <= 0 {
+		return
+	}
+	samples := newSampleReader(smask)
+	d0, d1 := 0.0, 1.0
+	if len(m.SMaskDecode) == 2 {
+		d0, d1 = m.SMaskDecode[0], m.SMaskDecode[1]
+	}
+
+	bounds := out.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		sy := y * sh / bounds.Dy()
+		for x := 0; x < bounds.Dx(); x++ {
+			sx := x * sw / bounds.Dx()
+			s := samples.get(sx, sy, 0)
+			alpha := d0 + float64(s)*(d1-d0)/float64(samples.maxVal)
+			c := out.NRGBAAt(x, y)
+			c.A = toByte(alpha)
+			out.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+// colorToNRGBA converts the color component values in the colorspace to an opaque RGB color.
+func colorToNRGBA(cs model.PdfColorspace, vals []float64) (gocolor.NRGBA, error) {
+	switch cs.(type) {
+	case *model.PdfColorspaceDeviceGray:
+		g := toByte(vals[0])
+		return gocolor.NRGBA{R: g, G: g, B: g, A: 0xff}, nil
+	case *model.PdfColorspaceDeviceRGB:
+		return gocolor.NRGBA{R: toByte(vals[0]), G: toByte(vals[1]), B: toByte(vals[2]), A: 0xff}, nil
+	}
+
+	color, err := cs.ColorFromFloats(vals)
+	if err != nil {
+		common.Log.Debug("Invalid image color %v in %s: %v", vals, cs, err)
+		return gocolor.NRGBA{}, err
+	}
+	rgb, err := cs.ColorToRGB(color)
+	if err != nil {
+		return gocolor.NRGBA{}, err
+	}
+	c, ok := rgb.(*model.PdfColorDeviceRGB)
+	if !ok {
+		common.Log.Debug("Unexpected RGB color type %T", rgb)
+		return gocolor.NRGBA{}, errors.New("Type check error")
+	}
+	return gocolor.NRGBA{R: toByte(c.R()), G: toByte(c.G()), B: toByte(c.B()), A: 0xff}, nil
+}
+
+// toByte converts a value in the range [0, 1] to a byte, clamping values outside the range.
+func toByte(val float64) uint8 {
+	return uint8(math.Max(0, math.Min(1, val))*255 + 0.5)
+}
+
+// sampleReader reads the samples of image data with rows padded to whole bytes.
+type sampleReader struct {
+	data     []byte
+	bpc      int
+	numComps int
+	rowBytes int
+	maxVal   uint32
+}
+
+func newSampleReader(img *model.Image) *sampleReader {
+	r := &sampleReader{data: img.Data, bpc: int(img.BitsPerComponent), numComps: img.ColorComponents}
+	if r.bpc <= 0 {
+		r.bpc = 8
+	}
+	if r.bpc > 16 {
+		r.bpc = 16
+	}
+	r.rowBytes = (int(img.Width)*r.numComps*r.bpc + 7) / 8
+	r.maxVal = uint32(1)<<uint(r.bpc) - 1
+	return r
+}
+
+// get returns the sample of component comp of pixel (x, y).  Missing data is read as 0.
+func (r *sampleReader) get(x, y, comp int) uint32 {
+	pos := y*r.rowBytes*8 + (x*r.numComps+comp)*r.bpc
+	val := uint32(0)
+	for i := 0; i < r.bpc; {
+		idx := (pos + i) / 8
+		if idx >= len(r.data) {
+			return 0
+		}
+		// Read as many bits as available in the current byte.
+		offset := (pos + i) % 8
+		n := 8 - offset
+		if n > r.bpc-i {
+			n = r.bpc - i
+		}
+		bits := (uint32(r.data[idx]) >> uint(8-offset-n)) & (1<<uint(n) - 1)
+		val = val<<uint(n) | bits
+		i += n
+	}
+	return val
+}
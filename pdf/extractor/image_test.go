@@ -0,0 +1,150 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	gocolor "image/color"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+const testImageContents = "q 30 0 0 10 100 200 cm /Im1 Do Q\n" +
+	"q 0 1 0 rg 16 0 0 4 0 0 cm /Im2 Do Q\n" +
+	"BI /W 2 /H 1 /CS /RGB /BPC 8 ID \xfe\x01\x01\x01\xfe\x01 EI\n"
+
+// makeTestImageStream returns an image XObject stream with the specified dictionary entries and data.
+func makeTestImageStream(entries map[core.PdfObjectName]core.PdfObject, data []byte) *core.PdfObjectStream {
+	dict := core.MakeDict()
+	dict.Set("Type", core.MakeName("XObject"))
+	dict.Set("Subtype", core.MakeName("Image"))
+	for key, val := range entries {
+		dict.Set(key, val)
+	}
+	dict.Set("Length", core.MakeInteger(int64(len(data))))
+	return &core.PdfObjectStream{PdfObjectDictionary: dict, Stream: data}
+}
+
+func TestImageExtraction(t *testing.T) {
+	// 3x1 indexed image, inverted by the Decode array to blue, red, blue, with a soft mask.
+	smask := makeTestImageStream(map[core.PdfObjectName]core.PdfObject{
+		"Width":            core.MakeInteger(3),
+		"Height":           core.MakeInteger(1),
+		"ColorSpace":       core.MakeName("DeviceGray"),
+		"BitsPerComponent": core.MakeInteger(8),
+	}, []byte{0xff, 0x80, 0x00})
+	palette := core.PdfObjectString("\xff\x00\x00\x00\x00\xff")
+	im1 := makeTestImageStream(map[core.PdfObjectName]core.PdfObject{
+		"Width":            core.MakeInteger(3),
+		"Height":           core.MakeInteger(1),
+		"ColorSpace":       core.MakeArray(core.MakeName("Indexed"), core.MakeName("DeviceRGB"), core.MakeInteger(1), &palette),
+		"BitsPerComponent": core.MakeInteger(1),
+		"Decode":           core.MakeArray(core.MakeInteger(1), core.MakeInteger(0)),
+		"SMask":            smask,
+	}, []byte{0x40})
+
+	// 8x2 CCITT G4 encoded stencil mask: two white, three black and three white pixels on each row.
+	im2 := makeTestImageStream(map[core.PdfObjectName]core.PdfObject{
+		"Width":       core.MakeInteger(8),
+		"Height":      core.MakeInteger(2),
+		"ImageMask":   core.MakeBool(true),
+		"Filter":      core.MakeName("CCITTFaxDecode"),
+		"DecodeParms": core.MakeDict(),
+	}, []byte{0x2F, 0x78, 0x00, 0x80, 0x08})
+	params := im2.Get("DecodeParms").(*core.PdfObjectDictionary)
+	params.Set("K", core.MakeInteger(-1))
+	params.Set("Columns", core.MakeInteger(8))
+	params.Set("Rows", core.MakeInteger(2))
+
+	resources := model.NewPdfPageResources()
+	if err := resources.SetXObjectByName("Im1", im1); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := resources.SetXObjectByName("Im2", im2); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testImageContents
+	e.resources = resources
+
+	marks, err := e.ExtractImages()
+	if err != nil {
+		t.Fatalf("Error extracting images: %v", err)
+	}
+	if len(marks) != 3 {
+		t.Fatalf("Expected 3 images, got %d", len(marks))
+	}
+
+	// Placement.
+	if marks[0].Name != "Im1" || marks[0].Inline {
+		t.Errorf("Invalid first image name %q (inline %v)", marks[0].Name, marks[0].Inline)
+	}
+	bbox := marks[0].BBox
+	if bbox.Llx != 100 || bbox.Lly != 200 || bbox.Urx != 130 || bbox.Ury != 210 {
+		t.Errorf("Invalid first image bbox %+v", bbox)
+	}
+	if !marks[2].Inline || marks[2].Name != "" {
+		t.Errorf("Third image not inline")
+	}
+
+	testcases := []struct {
+		mark   int
+		x, y   int
+		expect gocolor.NRGBA
+	}{
+		{0, 0, 0, gocolor.NRGBA{0, 0, 0xff, 0xff}},
+		{0, 1, 0, gocolor.NRGBA{0xff, 0, 0, 0x80}},
+		{0, 2, 0, gocolor.NRGBA{0, 0, 0xff, 0}},
+		{1, 1, 1, gocolor.NRGBA{}},
+		{1, 2, 1, gocolor.NRGBA{0, 0xff, 0, 0xff}},
+		{1, 4, 0, gocolor.NRGBA{0, 0xff, 0, 0xff}},
+		{1, 5, 0, gocolor.NRGBA{}},
+		{2, 0, 0, gocolor.NRGBA{0xfe, 0x01, 0x01, 0xff}},
+		{2, 1, 0, gocolor.NRGBA{0x01, 0xfe, 0x01, 0xff}},
+	}
+	for _, tcase := range testcases {
+		img, err := marks[tcase.mark].ToGoImage()
+		if err != nil {
+			t.Errorf("Image %d: conversion failed: %v", tcase.mark, err)
+			continue
+		}
+		c := gocolor.NRGBAModel.Convert(img.At(tcase.x, tcase.y)).(gocolor.NRGBA)
+		if c != tcase.expect {
+			t.Errorf("Image %d (%d,%d): %+v != %+v", tcase.mark, tcase.x, tcase.y, c, tcase.expect)
+		}
+	}
+}
+
+func TestImageColorKeyMask(t *testing.T) {
+	// 3x1 RGB image with a white background color key masked.
+	stream := makeTestImageStream(map[core.PdfObjectName]core.PdfObject{
+		"Width":            core.MakeInteger(3),
+		"Height":           core.MakeInteger(1),
+		"ColorSpace":       core.MakeName("DeviceRGB"),
+		"BitsPerComponent": core.MakeInteger(8),
+		"Mask":             core.MakeArrayFromIntegers([]int{250, 255, 250, 255, 250, 255}),
+	}, []byte{0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0xfa, 0xfe, 0xfb})
+	mark, err := NewImageMark(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(mark.ColorKeyMask) != 6 {
+		t.Fatalf("Wrong color key mask %v", mark.ColorKeyMask)
+	}
+	img, err := mark.ToGoImage()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := []gocolor.NRGBA{{}, {0xff, 0, 0, 0xff}, {}}
+	for x, exp := range expected {
+		c := gocolor.NRGBAModel.Convert(img.At(x, 0)).(gocolor.NRGBA)
+		if c != exp {
+			t.Errorf("Pixel %d: %+v != %+v", x, c, exp)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+	"strings"
+
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// InvisibleReason is the reason why content drawn on a page is not visible.
+type InvisibleReason int
+
+const (
+	// InvisibleRenderMode is text drawn with the invisible text rendering modes 3 or 7 (neither filled nor
+	// stroked), as used for the text layer of OCRed scans.
+	InvisibleRenderMode InvisibleReason = iota
+
+	// InvisibleColor is text drawn in the color of its background, e.g. white text on a white page.
+	InvisibleColor
+
+	// InvisibleSize is text with a font size too small to be legible.
+	InvisibleSize
+
+	// InvisibleOffPage is content drawn outside of the visible region of the page (crop box).
+	InvisibleOffPage
+)
+
+func (reason InvisibleReason) String() string {
+	switch reason {
+	case InvisibleRenderMode:
+		return "render mode"
+	case InvisibleColor:
+		return "color"
+	case InvisibleSize:
+		return "size"
+	case InvisibleOffPage:
+		return "off page"
+	}
+	return "unknown"
+}
+
+// Detection thresholds.
+const (
+	// Font size in points below which text is considered not legible.
+	minVisibleFontSize = 1.0
+	// Maximum difference of RGB color components considered the same color.
+	colorTolerance = 0.02
+)
+
+// InvisibleContent represents content drawn on the page which is not visible to the reader.
+type InvisibleContent struct {
+	Reason InvisibleReason
+
+	// The invisible text and its marks, for text content.  Consecutive characters on the same line invisible for
+	// the same reason are reported together.
+	Text  string
+	Marks []TextMark
+
+	// The image, for images drawn off page.
+	Image *ImageMark
+
+	// The bounding box of the content in page coordinates.
+	BBox model.PdfRectangle
+}
+
+// FindInvisibleContent analyzes the page for content which is drawn but not visible: text with an invisible
+// rendering mode, text in the same color as its background, text too small to be legible, and text and images
+// drawn outside of the page.  Such content is typical for the text layer of OCRed scans, but can also be used to
+// inject text for spam.  Whitespace is not reported.
+//
+// The background of text is the page (assumed white) or a filled rectangle drawn before the text.  Other shapes,
+// images and clipping paths are not taken into account.
+func (e *Extractor) FindInvisibleContent() ([]InvisibleContent, error) {
+	col := newTextMarkCollector()
+	col.lang = e.lang
+	err := e.processContents(col.addHandlers)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []InvisibleContent{}
+	var cur *InvisibleContent
+	for i, mark := range col.marks {
+		if cur != nil && !followsOnLine(cur.Marks[len(cur.Marks)-1], mark) {
+			cur = nil
+		}
+		if strings.TrimSpace(mark.Text) == "" {
+			// Whitespace neither ends nor starts a run.
+			if cur != nil {
+				cur.Text += mark.Text
+				cur.Marks = append(cur.Marks, mark)
+			}
+			continue
+		}
+
+		reason, invisible := e.textInvisibleReason(mark, col.backgroundRGB(mark, i))
+		if !invisible {
+			cur = nil
+			continue
+		}
+		if cur != nil && cur.Reason == reason {
+			cur.Text += mark.Text
+			cur.Marks = append(cur.Marks, mark)
+			cur.BBox = unionRect(cur.BBox, mark.BBox)
+			continue
+		}
+		items = append(items, InvisibleContent{Reason: reason, Text: mark.Text, Marks: []TextMark{mark}, BBox: mark.BBox})
+		cur = &items[len(items)-1]
+	}
+
+	// Trailing whitespace of the runs.
+	for i := range items {
+		item := &items[i]
+		for len(item.Marks) > 0 && strings.TrimSpace(item.Marks[len(item.Marks)-1].Text) == "" {
+			item.Text = strings.TrimSuffix(item.Text, item.Marks[len(item.Marks)-1].Text)
+			item.Marks = item.Marks[:len(item.Marks)-1]
+		}
+	}
+
+	if e.pageBox != nil {
+		images, err := e.ExtractImages()
+		if err != nil {
+			return nil, err
+		}
+		for i := range images {
+			if !rectsOverlap(images[i].BBox, *e.pageBox) {
+				items = append(items, InvisibleContent{Reason: InvisibleOffPage, Image: &images[i], BBox: images[i].BBox})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// textInvisibleReason returns the reason why the text mark is invisible on the background color, if it is.
+func (e *Extractor) textInvisibleReason(mark TextMark, background [3]float64) (InvisibleReason, bool) {
+	switch {
+	case mark.RenderMode == 3 || mark.RenderMode == 7:
+		return InvisibleRenderMode, true
+	case e.pageBox != nil && !rectsOverlap(mark.BBox, *e.pageBox):
+		return InvisibleOffPage, true
+	case mark.FontSize*e.UserUnit() < minVisibleFontSize:
+		return InvisibleSize, true
+	}
+
+	// Filled text in the color of the background.  Stroked text modes are visible by their outline, which may be
+	// in another color, so are not checked.
+	if (mark.RenderMode == 0 || mark.RenderMode == 4) && mark.fillRGBKnown {
+		same := true
+		for i := range background {
+			if math.Abs(mark.fillRGB[i]-background[i]) > colorTolerance {
+				same = false
+			}
+		}
+		if same {
+			return InvisibleColor, true
+		}
+	}
+
+	return 0, false
+}
+
+// backgroundRGB returns the color of the background at the center of the i-th mark: the color of the last
+// filled rectangle drawn before the mark which contains the center, or white.
+func (col *textMarkCollector) backgroundRGB(mark TextMark, i int) [3]float64 {
+	x := (mark.BBox.Llx + mark.BBox.Urx) / 2
+	y := (mark.BBox.Lly + mark.BBox.Ury) / 2
+	background := [3]float64{1, 1, 1}
+	for _, fill := range col.fills {
+		if fill.numMarks > i {
+			break
+		}
+		if x >= fill.bbox.Llx && x <= fill.bbox.Urx && y >= fill.bbox.Lly && y <= fill.bbox.Ury {
+			background = fill.rgb
+		}
+	}
+	return background
+}
+
+// followsOnLine returns true if mark follows the last mark on the same line.
+func followsOnLine(last, mark TextMark) bool {
+	fontSize := math.Max(last.FontSize, mark.FontSize)
+	along, perp := baselineOffset(last.Origin, last.End, mark.Origin)
+	return math.Abs(perp) <= baselineThreshold*fontSize && along <= lineGapThreshold*fontSize &&
+		along >= -wordGapThreshold*fontSize
+}
+
+// rectsOverlap returns true if the rectangles intersect.
+func rectsOverlap(a, b model.PdfRectangle) bool {
+	return a.Llx < b.Urx && b.Llx < a.Urx && a.Lly < b.Ury && b.Lly < a.Ury
+}
@@ -0,0 +1,75 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+const testInvisibleContents = `
+q BT /F1 12 Tf 10 700 Td (Visible) Tj ET Q
+q BT /F1 12 Tf 3 Tr 10 680 Td (Hidden) Tj ET Q
+q BT /F1 12 Tf 1 1 1 rg 10 660 Td (White) Tj ET Q
+q 0 0 1 rg 0 600 200 40 re f Q
+q BT /F1 12 Tf 0 0 1 rg 10 610 Td (Blue) Tj 1 1 0 rg ( Yellow) Tj ET Q
+q BT /F1 0.1 Tf 10 590 Td (Tiny) Tj ET Q
+q BT /F1 12 Tf 10 -100 Td (Below) Tj ET Q
+`
+
+func TestFindInvisibleContent(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testInvisibleContents
+	e.resources = resources
+	e.pageBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+
+	items, err := e.FindInvisibleContent()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := []struct {
+		text   string
+		reason InvisibleReason
+	}{
+		{"Hidden", InvisibleRenderMode},
+		{"White", InvisibleColor},
+		{"Blue", InvisibleColor},
+		{"Tiny", InvisibleSize},
+		{"Below", InvisibleOffPage},
+	}
+	if len(items) != len(expected) {
+		for _, item := range items {
+			t.Logf("%q: %s", item.Text, item.Reason)
+		}
+		t.Fatalf("Expected %d items, got %d", len(expected), len(items))
+	}
+	for i, exp := range expected {
+		if items[i].Text != exp.text || items[i].Reason != exp.reason {
+			t.Errorf("Item %d: %q (%s) != %q (%s)", i, items[i].Text, items[i].Reason, exp.text, exp.reason)
+		}
+	}
+
+	// The font sizes of oversized pages are in larger units: legible text.
+	e.userUnit = 20
+	items, err = e.FindInvisibleContent()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, item := range items {
+		if item.Reason == InvisibleSize {
+			t.Errorf("%q invisible with user unit %f", item.Text, e.UserUnit())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Paragraph segmentation thresholds, as fractions of the font size.
+const (
+	// Maximum distance between the baselines of consecutive lines of a paragraph.
+	paragraphLineSpacingThreshold = 1.7
+	// Maximum difference of the font sizes of consecutive lines of a paragraph.
+	paragraphFontSizeTolerance = 0.2
+)
+
+// TextParagraph represents a paragraph: a sequence of lines of text with the same font size, each below the
+// previous one with regular line spacing.
+type TextParagraph struct {
+	// The text of the paragraph, with the lines joined by spaces.  Words hyphenated at the end of a line are joined.
+	Text string
+
+	// The lines of the paragraph.
+	Lines []TextLine
+
+	// The bounding box of the paragraph in page coordinates.
+	BBox model.PdfRectangle
+
+	// The largest font size of the lines of the paragraph.
+	FontSize float64
+
+	// Whether all characters of the paragraph are drawn with a bold or italic font.
+	Bold   bool
+	Italic bool
+}
+
+// Paragraphs returns the paragraphs of text on the page, in content stream order.  Lines are grouped into
+// paragraphs when they follow each other, horizontally overlapping, with regular line spacing and font size.  Only
+// horizontal text is grouped, vertical and rotated lines form paragraphs of their own.
+func (e *Extractor) Paragraphs() ([]TextParagraph, error) {
+	lines, err := e.Lines()
+	if err != nil {
+		return nil, err
+	}
+	return groupParagraphs(lines), nil
+}
+
+// groupParagraphs groups lines into paragraphs.
+func groupParagraphs(lines []TextLine) []TextParagraph {
+	paragraphs := []TextParagraph{}
+	var cur *TextParagraph
+	for _, line := range lines {
+		if cur != nil && !continuesParagraph(cur, line) {
+			cur = nil
+		}
+
+		if cur == nil {
+			paragraphs = append(paragraphs, TextParagraph{Text: line.Text, BBox: line.BBox, Bold: true, Italic: true})
+			cur = &paragraphs[len(paragraphs)-1]
+		} else {
+			cur.Text = joinLines(cur.Text, line.Text)
+			cur.BBox = unionRect(cur.BBox, line.BBox)
+		}
+		cur.Lines = append(cur.Lines, line)
+		cur.FontSize = math.Max(cur.FontSize, line.FontSize)
+		for _, word := range line.Words {
+			for _, mark := range word.Marks {
+				if mark.font == nil || !mark.font.bold {
+					cur.Bold = false
+				}
+				if mark.font == nil || !mark.font.italic {
+					cur.Italic = false
+				}
+			}
+		}
+	}
+	return paragraphs
+}
+
+// continuesParagraph returns true if the line is the next line of the paragraph.
+func continuesParagraph(paragraph *TextParagraph, line TextLine) bool {
+	last := paragraph.Lines[len(paragraph.Lines)-1]
+	if last.Origin.Y == last.End.Y && line.Origin.Y == line.End.Y {
+		fontSize := math.Max(last.FontSize, line.FontSize)
+		spacing := last.Origin.Y - line.Origin.Y
+		return spacing > 0 && spacing <= paragraphLineSpacingThreshold*fontSize &&
+			math.Abs(last.FontSize-line.FontSize) <= paragraphFontSizeTolerance*fontSize &&
+			line.BBox.Llx < paragraph.BBox.Urx && paragraph.BBox.Llx < line.BBox.Urx
+	}
+	return false
+}
+
+// joinLines appends the text of a line to the text of a paragraph.
+func joinLines(text, line string) string {
+	first, _ := utf8.DecodeRuneInString(line)
+	if strings.HasSuffix(text, "-") && len(text) > 1 && unicode.IsLower(first) {
+		// Hyphenated word.
+		return text[:len(text)-1] + line
+	}
+	return text + " " + line
+}
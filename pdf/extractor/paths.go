@@ -0,0 +1,308 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// PathSegmentType is the type of a path segment.
+type PathSegmentType int
+
+const (
+	// PathMoveTo starts a new subpath at its point (m).
+	PathMoveTo PathSegmentType = iota
+	// PathLineTo appends a straight line to its point (l).
+	PathLineTo
+	// PathCurveTo appends a cubic Bezier curve with two control points and the end point (c, v, y).
+	PathCurveTo
+	// PathClose closes the current subpath (h).
+	PathClose
+)
+
+// PathSegment is a segment of a path in page coordinates.
+type PathSegment struct {
+	Type PathSegmentType
+
+	// The points of the segment: the point for move and line segments, the two control points followed by the
+	// end point for curves, and none for close segments.
+	Points []draw.Point
+}
+
+// ClipPath is a path intersected with the clipping region (W or W*).
+type ClipPath struct {
+	Segments []PathSegment
+	EvenOdd  bool
+}
+
+// PathMark represents a path painted on the page (stroked, filled or both), with the transformation to page
+// coordinates applied.
+type PathMark struct {
+	// The segments of the path in page coordinates.
+	Segments []PathSegment
+
+	// The painting of the path: stroke and/or fill, and the fill rule (even-odd or nonzero winding number).
+	Stroke  bool
+	Fill    bool
+	EvenOdd bool
+
+	// The stroke and fill colors, and their RGB components.
+	StrokeColor model.PdfColor
+	FillColor   model.PdfColor
+	StrokeRGB   [3]float64
+	FillRGB     [3]float64
+
+	// The line width in page units, i.e. scaled by the current transformation matrix.
+	LineWidth float64
+
+	// The clipping paths in effect when painted.  The painted area is the intersection of the clipping paths.
+	Clip []ClipPath
+
+	// The bounding box of the path in page coordinates, including the control points of curves.
+	BBox model.PdfRectangle
+}
+
+// pathMarkCollector collects path marks while processing content streams.
+type pathMarkCollector struct {
+	marks []PathMark
+
+	// The clipping paths, which are not tracked by the content stream processor, and their stack (q/Q).
+	clip      []ClipPath
+	clipStack [][]ClipPath
+
+	// The path under construction, its current point, and the pending clipping operator (W or W*).
+	path        []PathSegment
+	current     draw.Point
+	subpathHead draw.Point
+	clipping    bool
+	clipEvenOdd bool
+}
+
+// ExtractPaths processes the content streams and returns all paths painted on the page as PathMarks in the order in
+// which they appear in the content stream, with coordinates in page space.  Paths drawn inside Form XObjects are
+// included.  Paths which are only used for clipping are not returned, but are listed in the Clip field of the
+// paths painted within them.
+func (e *Extractor) ExtractPaths() ([]PathMark, error) {
+	col := &pathMarkCollector{}
+
+	err := e.processContents(col.addHandlers)
+	if err != nil {
+		return col.marks, err
+	}
+
+	return col.marks, nil
+}
+
+// addHandlers registers the handlers collecting the paths of a content stream.  Forms are drawn within the clipping
+// paths of the Do operation, which are restored once the form is processed.
+func (col *pathMarkCollector) addHandlers(processor *contentstream.ContentStreamProcessor,
+	form *contentstream.DrawnForm) (func() error, error) {
+	processor.AddCategoryHandler(contentstream.OperatorCategorySpecialGraphicsState, col.handleSpecialGraphicsState)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryPathConstruction, col.handlePathConstruction)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryClipping, col.handleClipping)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryPathPainting,
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+			col.paintPath(op.Operand, gs)
+			return nil
+		})
+	if form == nil {
+		return nil, nil
+	}
+
+	savedClip, savedStack := col.clip, col.clipStack
+	col.clipStack = nil
+	return func() error {
+		col.clip, col.clipStack = savedClip, savedStack
+		return nil
+	}, nil
+}
+
+// handleSpecialGraphicsState saves (q) and restores (Q) the clipping paths.
+func (col *pathMarkCollector) handleSpecialGraphicsState(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	switch op.Operand {
+	case "q":
+		col.clipStack = append(col.clipStack, col.clip)
+	case "Q":
+		if len(col.clipStack) > 0 {
+			col.clip = col.clipStack[len(col.clipStack)-1]
+			col.clipStack = col.clipStack[:len(col.clipStack)-1]
+		}
+	}
+	return nil
+}
+
+// handleClipping sets the pending clipping operator (W or W*), applied by the next path painting operator.
+func (col *pathMarkCollector) handleClipping(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+	resources *model.PdfPageResources) error {
+	col.clipping = true
+	col.clipEvenOdd = op.Operand == "W*"
+	return nil
+}
+
+// handlePathConstruction adds the segments of a path construction operator to the current path.
+func (col *pathMarkCollector) handlePathConstruction(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	ctm := gs.CTM
+	switch op.Operand {
+	case "m", "l":
+		vals, err := getOperands(op, 2)
+		if err != nil {
+			return nil
+		}
+		p := transform(ctm, vals[0], vals[1])
+		if op.Operand == "m" {
+			col.moveTo(p)
+		} else {
+			col.lineTo(p)
+		}
+	case "c", "v", "y":
+		n := 6
+		if op.Operand != "c" {
+			n = 4
+		}
+		vals, err := getOperands(op, n)
+		if err != nil {
+			return nil
+		}
+		points := []draw.Point{}
+		for i := 0; i < n; i += 2 {
+			points = append(points, transform(ctm, vals[i], vals[i+1]))
+		}
+		switch op.Operand {
+		case "v":
+			// The first control point is the current point.
+			points = append([]draw.Point{col.current}, points...)
+		case "y":
+			// The second control point is the end point.
+			points = append(points, points[1])
+		}
+		col.path = append(col.path, PathSegment{Type: PathCurveTo, Points: points})
+		col.current = points[2]
+	case "h":
+		col.closePath()
+	case "re":
+		vals, err := getOperands(op, 4)
+		if err != nil {
+			return nil
+		}
+		x, y, w, h := vals[0], vals[1], vals[2], vals[3]
+		col.moveTo(transform(ctm, x, y))
+		col.lineTo(transform(ctm, x+w, y))
+		col.lineTo(transform(ctm, x+w, y+h))
+		col.lineTo(transform(ctm, x, y+h))
+		col.closePath()
+	}
+
+	return nil
+}
+
+// transform transforms a point in user space to page coordinates with the CTM.
+func transform(ctm contentstream.Matrix, x, y float64) draw.Point {
+	px, py := ctm.Transform(x, y)
+	return draw.NewPoint(px, py)
+}
+
+func (col *pathMarkCollector) moveTo(p draw.Point) {
+	col.path = append(col.path, PathSegment{Type: PathMoveTo, Points: []draw.Point{p}})
+	col.current = p
+	col.subpathHead = p
+}
+
+func (col *pathMarkCollector) lineTo(p draw.Point) {
+	col.path = append(col.path, PathSegment{Type: PathLineTo, Points: []draw.Point{p}})
+	col.current = p
+}
+
+func (col *pathMarkCollector) closePath() {
+	col.path = append(col.path, PathSegment{Type: PathClose})
+	col.current = col.subpathHead
+}
+
+// paintPath ends the current path with the painting operator, adding a mark for stroked or filled paths and
+// applying a pending clipping operator.
+func (col *pathMarkCollector) paintPath(operand string, gs contentstream.GraphicsState) {
+	if operand == "s" || operand == "b" || operand == "b*" {
+		col.closePath()
+	}
+
+	if operand != "n" && len(col.path) > 0 {
+		mark := PathMark{Segments: col.path, Clip: col.clip}
+		switch operand {
+		case "S", "s":
+			mark.Stroke = true
+		case "f", "F", "f*":
+			mark.Fill = true
+		default:
+			mark.Stroke = true
+			mark.Fill = true
+		}
+		mark.EvenOdd = operand == "f*" || operand == "B*" || operand == "b*"
+
+		if mark.Stroke {
+			mark.StrokeColor = gs.ColorStroking
+			mark.StrokeRGB, _ = getRGB(gs.ColorspaceStroking, gs.ColorStroking)
+		}
+		if mark.Fill {
+			mark.FillColor = gs.ColorNonStroking
+			mark.FillRGB, _ = getRGB(gs.ColorspaceNonStroking, gs.ColorNonStroking)
+		}
+
+		// Scale the line width by the geometric mean of the scaling of the CTM.
+		m := gs.CTM
+		mark.LineWidth = gs.LineWidth * math.Sqrt(math.Abs(m[0]*m[3]-m[1]*m[2]))
+
+		mark.BBox = pathBBox(col.path)
+		col.marks = append(col.marks, mark)
+	}
+
+	if col.clipping && len(col.path) > 0 {
+		// The clipping paths are copied, as the slice is shared with the saved states and earlier marks.
+		clip := append([]ClipPath{}, col.clip...)
+		col.clip = append(clip, ClipPath{Segments: col.path, EvenOdd: col.clipEvenOdd})
+	}
+
+	col.path = nil
+	col.clipping = false
+}
+
+// getOperands returns the n numeric operands of the operation.
+func getOperands(op *contentstream.ContentStreamOperation, n int) ([]float64, error) {
+	if len(op.Params) != n {
+		common.Log.Debug("%s: invalid number of operands (%d)", op.Operand, len(op.Params))
+		return nil, errors.New("Invalid number of operands")
+	}
+	vals := []float64{}
+	for _, param := range op.Params {
+		val, err := getNumberAsFloat(param)
+		if err != nil {
+			common.Log.Debug("%s: invalid operand (%T)", op.Operand, param)
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+// pathBBox returns the bounding box of the points of the path segments.
+func pathBBox(segments []PathSegment) model.PdfRectangle {
+	bbox := model.PdfRectangle{Llx: math.Inf(1), Lly: math.Inf(1), Urx: math.Inf(-1), Ury: math.Inf(-1)}
+	for _, seg := range segments {
+		for _, p := range seg.Points {
+			bbox.Llx = math.Min(bbox.Llx, p.X)
+			bbox.Lly = math.Min(bbox.Lly, p.Y)
+			bbox.Urx = math.Max(bbox.Urx, p.X)
+			bbox.Ury = math.Max(bbox.Ury, p.Y)
+		}
+	}
+	return bbox
+}
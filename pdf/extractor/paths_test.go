@@ -0,0 +1,84 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+const testPathContents = `
+q 2 0 0 2 10 10 cm 0.5 w 1 0 0 RG 0 0 m 10 0 l 10 10 5 15 0 10 c S Q
+0 0 1 rg 0 0 100 100 re W n
+20 20 10 10 re f
+`
+
+func TestExtractPaths(t *testing.T) {
+	e := Extractor{}
+	e.contents = testPathContents
+
+	marks, err := e.ExtractPaths()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(marks) != 2 {
+		t.Fatalf("Expected 2 paths, got %d", len(marks))
+	}
+
+	// Stroked path with the CTM applied.
+	mark := marks[0]
+	if !mark.Stroke || mark.Fill {
+		t.Errorf("First path should be stroked only")
+	}
+	expected := []PathSegment{
+		{PathMoveTo, []draw.Point{draw.NewPoint(10, 10)}},
+		{PathLineTo, []draw.Point{draw.NewPoint(30, 10)}},
+		{PathCurveTo, []draw.Point{draw.NewPoint(30, 30), draw.NewPoint(20, 40), draw.NewPoint(10, 30)}},
+	}
+	if len(mark.Segments) != len(expected) {
+		t.Fatalf("Expected %d segments, got %d", len(expected), len(mark.Segments))
+	}
+	for i, seg := range expected {
+		if mark.Segments[i].Type != seg.Type || len(mark.Segments[i].Points) != len(seg.Points) {
+			t.Errorf("Segment %d mismatch: %+v", i, mark.Segments[i])
+			continue
+		}
+		for j, p := range seg.Points {
+			if mark.Segments[i].Points[j] != p {
+				t.Errorf("Segment %d point %d: %v != %v", i, j, mark.Segments[i].Points[j], p)
+			}
+		}
+	}
+	if mark.LineWidth != 1 {
+		t.Errorf("Line width %f != 1", mark.LineWidth)
+	}
+	if mark.StrokeRGB != [3]float64{1, 0, 0} {
+		t.Errorf("Stroke color %v", mark.StrokeRGB)
+	}
+	if mark.BBox != (model.PdfRectangle{Llx: 10, Lly: 10, Urx: 30, Ury: 40}) {
+		t.Errorf("Invalid bbox %+v", mark.BBox)
+	}
+	if len(mark.Clip) != 0 {
+		t.Errorf("First path should not be clipped")
+	}
+
+	// Filled path within the clipping rectangle.
+	mark = marks[1]
+	if mark.Stroke || !mark.Fill {
+		t.Errorf("Second path should be filled only")
+	}
+	if mark.FillRGB != [3]float64{0, 0, 1} {
+		t.Errorf("Fill color %v", mark.FillRGB)
+	}
+	if len(mark.Segments) != 5 || mark.Segments[4].Type != PathClose {
+		t.Errorf("Invalid rectangle segments %+v", mark.Segments)
+	}
+	if len(mark.Clip) != 1 || len(mark.Clip[0].Segments) != 5 || mark.Clip[0].EvenOdd {
+		t.Errorf("Invalid clipping paths %+v", mark.Clip)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// contentPresence records whether content streams show text or draw images, scanning the operations only: the
+// graphics state is not tracked and the fonts and images are not loaded.
+type contentPresence struct {
+	text   bool
+	images bool
+
+	// Form XObjects already scanned, as forms are commonly drawn repeatedly.
+	forms map[*core.PdfObjectStream]bool
+
+	// Depth of Form XObject recursion.
+	depth int
+}
+
+// HasText returns true if the page shows text, including text drawn inside Form XObjects and invisible text such
+// as the text layer added to scanned pages by OCR.  Only the text showing operators of the content streams are
+// checked for non-empty strings, without loading the fonts or mapping the text to unicode, so it is much faster
+// than extracting the text, e.g. for deciding which pages of a document need OCR.
+func (e *Extractor) HasText() (bool, error) {
+	p, err := e.scanPresence()
+	if err != nil {
+		return false, err
+	}
+	return p.text, nil
+}
+
+// IsImageOnly returns true if the page draws images (XObject or inline images) but shows no text, such as scanned
+// pages without a text layer.  See HasText.
+func (e *Extractor) IsImageOnly() (bool, error) {
+	p, err := e.scanPresence()
+	if err != nil {
+		return false, err
+	}
+	return p.images && !p.text, nil
+}
+
+// scanPresence scans the content streams of the page until text is found.
+func (e *Extractor) scanPresence() (*contentPresence, error) {
+	p := &contentPresence{forms: map[*core.PdfObjectStream]bool{}}
+	err := p.scan(e.contents, e.resources)
+	return p, err
+}
+
+// scan scans the content stream contents with the specified resources.
+func (p *contentPresence) scan(contents string, resources *model.PdfPageResources) error {
+	operations, err := contentstream.NewContentStreamParser(contents).Parse()
+	if err != nil {
+		return err
+	}
+
+	for _, op := range *operations {
+		switch op.Operand {
+		case "Tj", "'", "\"":
+			if len(op.Params) > 0 && hasString(op.Params[len(op.Params)-1]) {
+				p.text = true
+			}
+		case "TJ":
+			if len(op.Params) == 1 {
+				if arr, ok := op.Params[0].(*core.PdfObjectArray); ok {
+					for _, obj := range *arr {
+						if hasString(obj) {
+							p.text = true
+							break
+						}
+					}
+				}
+			}
+		case "BI":
+			p.images = true
+		case "Do":
+			if len(op.Params) != 1 || resources == nil {
+				break
+			}
+			name, ok := op.Params[0].(*core.PdfObjectName)
+			if !ok {
+				break
+			}
+			stream, xtype := resources.GetXObjectByName(*name)
+			switch xtype {
+			case model.XObjectTypeImage:
+				p.images = true
+			case model.XObjectTypeForm:
+				err := p.scanForm(stream, resources)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if p.text {
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanForm scans the contents of a Form XObject drawn with the Do operator.
+func (p *contentPresence) scanForm(stream *core.PdfObjectStream, resources *model.PdfPageResources) error {
+	if p.forms[stream] {
+		return nil
+	}
+	p.forms[stream] = true
+	if p.depth >= contentstream.MaxFormDepth {
+		common.Log.Debug("Form XObjects nested too deep")
+		return errors.New("Form XObject recursion limit exceeded")
+	}
+
+	xform, err := model.NewXObjectFormFromStream(stream)
+	if err != nil {
+		return err
+	}
+	content, err := xform.GetContentStream()
+	if err != nil {
+		return err
+	}
+
+	formResources := xform.Resources
+	if formResources == nil {
+		// Forms without resources inherit the resources of the page.
+		formResources = resources
+	}
+	p.depth++
+	err = p.scan(string(content), formResources)
+	p.depth--
+	return err
+}
+
+// hasString returns true if obj is a non-empty string.
+func hasString(obj core.PdfObject) bool {
+	str, ok := obj.(*core.PdfObjectString)
+	return ok && len(*str) > 0
+}
@@ -0,0 +1,62 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+func TestTextPresence(t *testing.T) {
+	image := makeTestImageStream(map[core.PdfObjectName]core.PdfObject{
+		"Width":            core.MakeInteger(1),
+		"Height":           core.MakeInteger(1),
+		"ColorSpace":       core.MakeName("DeviceGray"),
+		"BitsPerComponent": core.MakeInteger(8),
+	}, []byte{0x80})
+	form := model.NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, 100, 100})
+	err := form.SetContentStream([]byte("BT /F1 10 Tf 3 Tr [(OCR) -250 (text)] TJ ET"), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	resources := model.NewPdfPageResources()
+	resources.SetXObjectByName("Im1", image)
+	resources.SetXObjectFormByName("Fm1", form)
+
+	testcases := []struct {
+		contents  string
+		hasText   bool
+		imageOnly bool
+	}{
+		{"", false, false},
+		{"0 0 100 100 re f", false, false},
+		{"q 100 0 0 100 0 0 cm /Im1 Do Q", false, true},
+		{"BI /W 1 /H 1 /CS /G /BPC 8 ID \x80 EI", false, true},
+		// Empty strings show no text.
+		{"/Im1 Do BT /F1 10 Tf () Tj [()] TJ ET", false, true},
+		{"/Im1 Do BT /F1 10 Tf (Hello) Tj ET", true, false},
+		{"BT /F1 10 Tf 0 0 (Hello) \" ET", true, false},
+		// Invisible text layer of a scanned page, in a form.
+		{"/Im1 Do /Fm1 Do", true, false},
+	}
+	for _, tcase := range testcases {
+		e := Extractor{contents: tcase.contents, resources: resources}
+		hasText, err := e.HasText()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		imageOnly, err := e.IsImageOnly()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if hasText != tcase.hasText || imageOnly != tcase.imageOnly {
+			t.Errorf("%q: has text %v, image only %v", tcase.contents, hasText, imageOnly)
+		}
+	}
+}
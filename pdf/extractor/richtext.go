@@ -0,0 +1,135 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// StyledRun is a run of text drawn with the same style.
+type StyledRun struct {
+	// The text of the run.  Words are separated by spaces and lines by newlines.
+	Text string
+
+	// The font resource name, base font name, and effective font size in page units.
+	FontName string
+	BaseFont string
+	FontSize float64
+
+	// The style of the font, from the font descriptor or the font name.
+	Bold   bool
+	Italic bool
+
+	// The fill color of the text and its RGB components.
+	Color model.PdfColor
+	RGB   [3]float64
+
+	// The bounding box of the run in page coordinates.
+	BBox model.PdfRectangle
+}
+
+// RichContent is the content of a page region with formatting, e.g. for copying with formatting to the clipboard.
+type RichContent struct {
+	// The plain text of the region.  Words are separated by spaces and lines by newlines.
+	Text string
+
+	// The text of the region split into runs of the same style, in reading order.
+	Runs []StyledRun
+
+	// The images overlapping the region.
+	Images []ImageMark
+}
+
+// ExtractRichContent returns the text in the region of the page as styled runs (text, fonts and colors), along with
+// the images drawn in the region.  Characters are included if the center of their bounding box lies within the
+// region, and images if they overlap it.
+func (e *Extractor) ExtractRichContent(region model.PdfRectangle) (*RichContent, error) {
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		return nil, err
+	}
+
+	inRegion := []TextMark{}
+	for _, mark := range marks {
+		x := (mark.BBox.Llx + mark.BBox.Urx) / 2
+		y := (mark.BBox.Lly + mark.BBox.Ury) / 2
+		if x >= region.Llx && x <= region.Urx && y >= region.Lly && y <= region.Ury {
+			inRegion = append(inRegion, mark)
+		}
+	}
+
+	content := &RichContent{}
+	var text bytes.Buffer
+	var cur *StyledRun
+	for i, line := range groupLines(groupWords(inRegion)) {
+		for j, word := range line.Words {
+			sep := ""
+			if j > 0 {
+				sep = " "
+			} else if i > 0 {
+				sep = "\n"
+			}
+			for _, mark := range word.Marks {
+				text.WriteString(sep)
+				text.WriteString(mark.Text)
+
+				run := newStyledRun(mark)
+				if cur != nil && sameStyle(cur, &run) {
+					cur.Text += sep + mark.Text
+					cur.BBox = unionRect(cur.BBox, mark.BBox)
+				} else {
+					// Separators end the previous run.
+					if cur != nil {
+						cur.Text += sep
+					}
+					content.Runs = append(content.Runs, run)
+					cur = &content.Runs[len(content.Runs)-1]
+				}
+				sep = ""
+			}
+		}
+	}
+	content.Text = text.String()
+
+	images, err := e.ExtractImages()
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		if rectsOverlap(img.BBox, region) {
+			content.Images = append(content.Images, img)
+		}
+	}
+
+	return content, nil
+}
+
+// newStyledRun returns a run with the text and the style of a text mark.
+func newStyledRun(mark TextMark) StyledRun {
+	run := StyledRun{
+		Text:     mark.Text,
+		FontName: mark.FontName,
+		BaseFont: mark.BaseFont,
+		FontSize: mark.FontSize,
+		Color:    mark.Color,
+		RGB:      mark.fillRGB,
+		BBox:     mark.BBox,
+	}
+	if mark.font != nil {
+		run.Bold = mark.font.bold
+		run.Italic = mark.font.italic
+	}
+	return run
+}
+
+// sameStyle returns true if the runs have the same font, size and color.
+func sameStyle(a, b *StyledRun) bool {
+	return a.FontName == b.FontName && a.BaseFont == b.BaseFont && math.Abs(a.FontSize-b.FontSize) < 0.05 &&
+		a.Bold == b.Bold && a.Italic == b.Italic && a.RGB == b.RGB
+}
@@ -0,0 +1,74 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+const testRichContents = "BT /F1 12 Tf 10 700 Td (Hello ) Tj /F2 12 Tf (bold) Tj /F1 12 Tf ( world) Tj ET\n" +
+	"q 1 0 0 rg BT /F1 12 Tf 10 680 Td (Red) Tj ET Q\n" +
+	"BT /F1 12 Tf 10 500 Td (Outside) Tj ET\n" +
+	"q 20 0 0 20 300 690 cm BI /W 1 /H 1 /CS /G /BPC 8 ID \x80 EI Q\n"
+
+func TestExtractRichContent(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetFontByName("F2", fonts.NewFontHelveticaBold().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testRichContents
+	e.resources = resources
+
+	content, err := e.ExtractRichContent(model.PdfRectangle{Llx: 0, Lly: 600, Urx: 400, Ury: 720})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if content.Text != "Hello bold world\nRed" {
+		t.Errorf("Text mismatch (%q)", content.Text)
+	}
+
+	expected := []struct {
+		text string
+		font string
+		bold bool
+		rgb  [3]float64
+	}{
+		{"Hello ", "F1", false, [3]float64{0, 0, 0}},
+		{"bold ", "F2", true, [3]float64{0, 0, 0}},
+		{"world\n", "F1", false, [3]float64{0, 0, 0}},
+		{"Red", "F1", false, [3]float64{1, 0, 0}},
+	}
+	if len(content.Runs) != len(expected) {
+		for _, run := range content.Runs {
+			t.Logf("Run %q", run.Text)
+		}
+		t.Fatalf("Expected %d runs, got %d", len(expected), len(content.Runs))
+	}
+	for i, exp := range expected {
+		run := content.Runs[i]
+		if run.Text != exp.text || run.FontName != exp.font || run.Bold != exp.bold || run.RGB != exp.rgb {
+			t.Errorf("Run %d mismatch: %q %s bold=%v %v", i, run.Text, run.FontName, run.Bold, run.RGB)
+		}
+		if run.FontSize != 12 {
+			t.Errorf("Run %d: font size %f", i, run.FontSize)
+		}
+	}
+
+	if len(content.Images) != 1 || !content.Images[0].Inline {
+		t.Errorf("Expected the inline image in the region, got %d images", len(content.Images))
+	}
+}
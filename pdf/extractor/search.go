@@ -0,0 +1,160 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// TextMatch represents a match of a search on a page.
+type TextMatch struct {
+	// The page number of the match (1-based).  Zero for matches found with Extractor.Search.
+	PageNum int
+
+	// The matched text.  Words are separated by spaces and lines by newlines.
+	Text string
+
+	// The quadrilaterals covering the match in page coordinates, one for each line the match spans.  The corners
+	// are ordered as for TextMark.Quad, as needed for the QuadPoints of highlight annotations.
+	Quads [][4]draw.Point
+
+	// The bounding box of the match in page coordinates.
+	BBox model.PdfRectangle
+}
+
+// Search finds the matches of the regular expression in the text of the page.  The page text is searched with the
+// words of each line separated by single spaces and the lines separated by newlines, so matches can span across
+// text showing operations and line breaks.
+func (e *Extractor) Search(re *regexp.Regexp) ([]TextMatch, error) {
+	lines, err := e.Lines()
+	if err != nil {
+		return nil, err
+	}
+
+	// Position of each mark in the searched text.
+	type markPos struct {
+		start, end int
+		line       int
+		mark       *TextMark
+	}
+	positions := []markPos{}
+
+	var buf bytes.Buffer
+	for i := range lines {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		for j := range lines[i].Words {
+			if j > 0 {
+				buf.WriteString(" ")
+			}
+			word := &lines[i].Words[j]
+			for k := range word.Marks {
+				mark := &word.Marks[k]
+				start := buf.Len()
+				buf.WriteString(mark.Text)
+				positions = append(positions, markPos{start: start, end: buf.Len(), line: i, mark: mark})
+			}
+		}
+	}
+	text := buf.String()
+
+	matches := []TextMatch{}
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		match := TextMatch{Text: text[loc[0]:loc[1]]}
+
+		// Quad spanning the matched marks of each line.
+		line := -1
+		var first, last *TextMark
+		addQuad := func() {
+			if first == nil {
+				return
+			}
+			quad := [4]draw.Point{first.Quad[0], last.Quad[1], last.Quad[2], first.Quad[3]}
+			match.Quads = append(match.Quads, quad)
+			if len(match.Quads) == 1 {
+				match.BBox = quadBBox(quad)
+			} else {
+				match.BBox = unionRect(match.BBox, quadBBox(quad))
+			}
+		}
+		for _, pos := range positions {
+			if pos.end <= loc[0] || pos.start >= loc[1] {
+				continue
+			}
+			if pos.line != line {
+				addQuad()
+				line = pos.line
+				first = pos.mark
+			}
+			last = pos.mark
+		}
+		addQuad()
+
+		if len(match.Quads) > 0 {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches, nil
+}
+
+// SearchText finds the occurrences of the literal text on the page.  Whitespace in the text matches any
+// whitespace in the page text, including line breaks.
+func (e *Extractor) SearchText(text string) ([]TextMatch, error) {
+	return e.Search(literalRegexp(text))
+}
+
+// Search finds the matches of the regular expression in the text of all pages of the document loaded by reader.
+// See Extractor.Search.
+func Search(reader *model.PdfReader, re *regexp.Regexp) ([]TextMatch, error) {
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []TextMatch{}
+	for i := 1; i <= numPages; i++ {
+		page, err := reader.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		e, err := New(page)
+		if err != nil {
+			return nil, err
+		}
+		pageMatches, err := e.Search(re)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range pageMatches {
+			match.PageNum = i
+			matches = append(matches, match)
+		}
+	}
+
+	return matches, nil
+}
+
+// SearchText finds the occurrences of the literal text in all pages of the document loaded by reader.
+// See Extractor.SearchText.
+func SearchText(reader *model.PdfReader, text string) ([]TextMatch, error) {
+	return Search(reader, literalRegexp(text))
+}
+
+// literalRegexp returns a regular expression matching the literal text, with any whitespace matching any whitespace.
+func literalRegexp(text string) *regexp.Regexp {
+	parts := strings.Fields(text)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile(strings.Join(parts, `\s+`))
+}
@@ -0,0 +1,311 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ErrNotTagged is returned when extracting the text of a document by its logical structure if the document has no
+// structure tree.  The text can then be extracted page by page.
+var ErrNotTagged = errors.New("Document has no structure tree")
+
+// TextBlock is a block of text of the logical structure of a tagged document: a paragraph, a heading, a list item, a
+// table cell or a figure.
+type TextBlock struct {
+	// The standard structure type of the block, e.g. "P", "H1", "LBody", "TD" or "Figure", the custom types being
+	// mapped by the role map of the document.
+	Type string
+
+	// The text of the block: the replacement text (ActualText) of the element if set, the alternate description
+	// (Alt) of figures and formulas if set, otherwise the text of its content, with the lines joined by spaces.
+	Text string
+
+	// The alternate description of the element (Alt), if set.
+	Alt string
+
+	// The natural language of the block (Lang of the element or the closest ancestor specifying one, or of the
+	// document), empty if unknown.
+	Lang string
+
+	// The number of the page of the first content of the block (from 1), 0 if it has no content.
+	PageNum int
+
+	// For table cells (TH and TD), the number of the table in the document (from 1), and the row and column of the
+	// cell in the table (from 0).  Table is 0 for the other blocks.
+	Table  int
+	Row    int
+	Column int
+}
+
+// Maximum number of role map lookups when mapping a structure type, guarding against cyclic role maps.
+const maxRoleMapping = 10
+
+// blockTypes are the standard structure types whose content forms a block of text.
+var blockTypes = map[string]bool{
+	"P": true, "H": true, "H1": true, "H2": true, "H3": true, "H4": true, "H5": true, "H6": true,
+	"Lbl": true, "LBody": true, "TH": true, "TD": true, "Caption": true, "BlockQuote": true, "Title": true,
+	"Figure": true, "Formula": true, "Form": true,
+}
+
+// structText extracts the text blocks of a structure tree.
+type structText struct {
+	reader  *model.PdfReader
+	roleMap map[string]string
+	lang    string
+
+	// The numbers of the pages, and the text marks of their marked content sequences by MCID, once extracted.
+	pageNums map[*model.PdfPage]int
+	pageText map[*model.PdfPage]map[int][]TextMark
+
+	// The tables being walked, innermost last, and the number of tables walked.
+	tables    []*tableCursor
+	numTables int
+
+	blocks []TextBlock
+}
+
+// tableCursor is the position in a table being walked.
+type tableCursor struct {
+	num         int
+	row, column int
+}
+
+// ExtractTextBlocks returns the text blocks of the document loaded by reader in the order of its logical structure,
+// which is the reading order, following the structure tree instead of the layout of the pages.  The content marked as
+// artifacts, e.g. page headers and footers, is not part of the structure and is not included.  Returns ErrNotTagged if
+// the document has no structure tree.
+func ExtractTextBlocks(reader *model.PdfReader) ([]TextBlock, error) {
+	root, err := reader.GetStructTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, ErrNotTagged
+	}
+	lang, err := reader.GetLanguage()
+	if err != nil {
+		common.Log.Debug("Document language ignored: %v", err)
+	}
+
+	s := &structText{
+		reader:   reader,
+		roleMap:  root.RoleMap,
+		lang:     lang,
+		pageNums: map[*model.PdfPage]int{},
+		pageText: map[*model.PdfPage]map[int][]TextMark{},
+		blocks:   []TextBlock{},
+	}
+	for i, page := range reader.PageList {
+		s.pageNums[page] = i + 1
+	}
+	for _, elem := range root.K {
+		err := s.walk(elem, lang)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.blocks, nil
+}
+
+// ExtractStructuredText returns the text of the document loaded by reader in the order of its logical structure: the
+// text blocks (see ExtractTextBlocks) on separate lines, with the cells of table rows separated by tabs.  Returns
+// ErrNotTagged if the document has no structure tree.
+func ExtractStructuredText(reader *model.PdfReader) (string, error) {
+	blocks, err := ExtractTextBlocks(reader)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			prev := blocks[i-1]
+			if block.Table != 0 && block.Table == prev.Table && block.Row == prev.Row {
+				sb.WriteString("\t")
+			} else {
+				sb.WriteString("\n")
+			}
+		}
+		sb.WriteString(block.Text)
+	}
+	return sb.String(), nil
+}
+
+// standardType returns the standard structure type of a type, mapped by the role map.
+func (s *structText) standardType(structType string) string {
+	for i := 0; i < maxRoleMapping; i++ {
+		mapped, has := s.roleMap[structType]
+		if !has || mapped == structType {
+			break
+		}
+		structType = mapped
+	}
+	return structType
+}
+
+// walk adds the blocks of the element and its descendants.  Lang is the language of the parent element.
+func (s *structText) walk(elem *model.PdfStructElement, lang string) error {
+	structType := s.standardType(elem.S)
+	if elem.Lang != "" {
+		lang = elem.Lang
+	}
+
+	switch structType {
+	case "Table":
+		s.numTables++
+		s.tables = append(s.tables, &tableCursor{num: s.numTables, row: -1})
+		defer func() { s.tables = s.tables[:len(s.tables)-1] }()
+	case "TR":
+		if len(s.tables) > 0 {
+			table := s.tables[len(s.tables)-1]
+			table.row++
+			table.column = 0
+		}
+	}
+
+	if blockTypes[structType] {
+		return s.addBlock(elem, structType, lang)
+	}
+
+	// Grouping elements, and the content of other elements between their child elements.
+	run := &model.PdfStructElement{S: elem.S}
+	flush := func() error {
+		if len(run.Kids) == 0 {
+			return nil
+		}
+		err := s.addBlock(run, structType, lang)
+		run.Kids = nil
+		return err
+	}
+	for _, kid := range elem.Kids {
+		if kid.Element == nil {
+			run.Kids = append(run.Kids, kid)
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if err := s.walk(kid.Element, lang); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// addBlock adds the block of the element, with the text of all its content.
+func (s *structText) addBlock(elem *model.PdfStructElement, structType, lang string) error {
+	block := TextBlock{Type: structType, Alt: elem.Alt, Lang: lang}
+	if structType == "TH" || structType == "TD" {
+		if len(s.tables) > 0 {
+			table := s.tables[len(s.tables)-1]
+			block.Table, block.Row, block.Column = table.num, table.row, table.column
+			table.column++
+		}
+	}
+
+	text, pageNum, err := s.elementText(elem, 0)
+	if err != nil {
+		return err
+	}
+	block.PageNum = pageNum
+	switch {
+	case elem.ActualText != "":
+		block.Text = elem.ActualText
+	case elem.Alt != "" && (structType == "Figure" || structType == "Formula"):
+		block.Text = elem.Alt
+	default:
+		block.Text = text
+	}
+	if block.Text == "" && block.Alt == "" {
+		return nil
+	}
+	s.blocks = append(s.blocks, block)
+	return nil
+}
+
+// elementText returns the text of the content of the element, and the number of the page of its first content.
+func (s *structText) elementText(elem *model.PdfStructElement, depth int) (string, int, error) {
+	text, pageNum := "", 0
+	appendText := func(str string) {
+		if str == "" {
+			return
+		}
+		if text == "" {
+			text = str
+			return
+		}
+		text = joinLines(text, str)
+	}
+
+	for _, kid := range elem.Kids {
+		var str string
+		kidPage := 0
+		switch {
+		case kid.Element != nil:
+			if depth >= maxStructTextDepth {
+				common.Log.Debug("Structure elements nested too deep")
+				continue
+			}
+			var err error
+			str, kidPage, err = s.elementText(kid.Element, depth+1)
+			if err != nil {
+				return "", 0, err
+			}
+			if kid.Element.ActualText != "" {
+				str = kid.Element.ActualText
+			}
+		case kid.Object == nil && kid.Page != nil:
+			marks, err := s.markedContent(kid.Page, kid.MCID)
+			if err != nil {
+				return "", 0, err
+			}
+			for _, line := range groupLines(groupWords(marks)) {
+				if str == "" {
+					str = line.Text
+				} else {
+					str = joinLines(str, line.Text)
+				}
+			}
+			kidPage = s.pageNums[kid.Page]
+		}
+		if pageNum == 0 {
+			pageNum = kidPage
+		}
+		appendText(str)
+	}
+	return text, pageNum, nil
+}
+
+// Maximum depth of the elements whose text is extracted within a block.
+const maxStructTextDepth = 50
+
+// markedContent returns the text marks of the marked content sequence of the page with the MCID.
+func (s *structText) markedContent(page *model.PdfPage, mcid int) ([]TextMark, error) {
+	byMCID, has := s.pageText[page]
+	if !has {
+		e, err := New(page)
+		if err != nil {
+			return nil, err
+		}
+		e.SetLanguage(s.lang)
+		marks, err := e.ExtractTextMarks()
+		if err != nil {
+			return nil, err
+		}
+		byMCID = map[int][]TextMark{}
+		for _, mark := range marks {
+			if mark.mcid >= 0 {
+				byMCID[mark.mcid] = append(byMCID[mark.mcid], mark)
+			}
+		}
+		s.pageText[page] = byMCID
+	}
+	return byMCID[mcid], nil
+}
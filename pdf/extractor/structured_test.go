@@ -0,0 +1,123 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// The content is drawn in a different order than the reading order.
+const testTaggedContents = "/Artifact BMC BT /F1 8 Tf 10 10 Td (Page 1) Tj ET EMC\n" +
+	"/P <</MCID 2>> BDC BT /F1 12 Tf 10 600 Td (Second para-) Tj 0 -14 Td (graph text) Tj ET EMC\n" +
+	"/Title <</MCID 0>> BDC BT /F1 20 Tf 10 700 Td (Report) Tj ET EMC\n" +
+	"/P <</MCID 1>> BDC BT /F1 12 Tf 10 650 Td (First) Tj ET EMC\n" +
+	"/TD <</MCID 3>> BDC BT /F1 12 Tf 10 500 Td (A1) Tj ET EMC\n" +
+	"/TD <</MCID 4>> BDC BT /F1 12 Tf 100 500 Td (B1) Tj ET EMC\n" +
+	"/TD <</MCID 5>> BDC BT /F1 12 Tf 10 480 Td (A2) Tj ET EMC\n" +
+	"/TD <</MCID 6>> BDC BT /F1 12 Tf 100 480 Td (B2) Tj ET EMC\n" +
+	"/Figure <</MCID 7>> BDC 10 300 100 100 re f EMC\n"
+
+func TestExtractStructuredText(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+	page.Resources = model.NewPdfPageResources()
+	if err := page.Resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject()); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page.AddContentStreamByString(testTaggedContents)
+
+	root := model.NewPdfStructTreeRoot()
+	root.RoleMap["Title"] = "H1"
+	doc := model.NewPdfStructElement("Document")
+	doc.Lang = "en-US"
+	root.K = append(root.K, doc)
+	addElement := func(parent *model.PdfStructElement, structType string) *model.PdfStructElement {
+		elem := model.NewPdfStructElement(structType)
+		parent.AddKid(elem)
+		return elem
+	}
+	addContent := func(parent *model.PdfStructElement, structType string) *model.PdfStructElement {
+		elem := addElement(parent, structType)
+		elem.AddMarkedContent(page, root.NewMCID(page))
+		return elem
+	}
+	addContent(doc, "Title")
+	addContent(doc, "P")
+	addContent(doc, "P")
+	table := addElement(doc, "Table")
+	for i := 0; i < 2; i++ {
+		row := addElement(table, "TR")
+		addContent(row, "TD")
+		addContent(row, "TD")
+	}
+	figure := addContent(doc, "Figure")
+	figure.Alt = "Logo"
+
+	w := model.NewPdfWriter()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w.SetStructTreeRoot(root)
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	text, err := ExtractStructuredText(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := "Report\nFirst\nSecond paragraph text\nA1\tB1\nA2\tB2\nLogo"
+	if text != expected {
+		t.Errorf("Text mismatch %q, expected %q", text, expected)
+	}
+
+	blocks, err := ExtractTextBlocks(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(blocks) != 8 {
+		t.Fatalf("Wrong number of blocks %d", len(blocks))
+	}
+	if b := blocks[0]; b.Type != "H1" || b.Lang != "en-US" || b.PageNum != 1 {
+		t.Errorf("Wrong heading %+v", b)
+	}
+	if b := blocks[6]; b.Type != "TD" || b.Table != 1 || b.Row != 1 || b.Column != 1 || b.Text != "B2" {
+		t.Errorf("Wrong cell %+v", b)
+	}
+	if b := blocks[7]; b.Type != "Figure" || b.Alt != "Logo" {
+		t.Errorf("Wrong figure %+v", b)
+	}
+}
+
+func TestExtractStructuredTextNotTagged(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+	page.Resources = model.NewPdfPageResources()
+	w := model.NewPdfWriter()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if _, err := ExtractStructuredText(reader); err != ErrNotTagged {
+		t.Errorf("Expected ErrNotTagged, got %v", err)
+	}
+}
@@ -22,6 +22,8 @@ import (
 // The text is processed linearly e.g. in the order in which it appears. A best effort is done to add
 // spaces and newlines.
 func (e *Extractor) ExtractText() (string, error) {
+	defer common.StartTiming(common.TimingExtractText)()
+
 	var buf bytes.Buffer
 
 	cstreamParser := contentstream.NewContentStreamParser(e.contents)
@@ -7,7 +7,13 @@ package extractor
 
 import (
 	"flag"
+	"math"
+	"regexp"
 	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
 )
 
 func init() {
@@ -41,3 +47,304 @@ func TestTextExtraction1(t *testing.T) {
 		return
 	}
 }
+
+const testContents2 = `
+BT
+/F1 10 Tf
+1 0 0 1 100 700 Tm
+(He) Tj
+[(l) -1000 (o)] TJ
+ET
+`
+
+func TestTextMarks(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testContents2
+	e.resources = resources
+
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		t.Fatalf("Error extracting text marks: %v", err)
+	}
+	if len(marks) != 4 {
+		t.Fatalf("Incorrect number of marks: %d", len(marks))
+	}
+
+	text := ""
+	for _, mark := range marks {
+		text += mark.Text
+	}
+	if text != "Helo" {
+		t.Errorf("Text mismatch (%s)", text)
+	}
+
+	// Helvetica widths: H=722, e=556, l=222.  The TJ offset moves 10 points to the right.
+	expectedX := []float64{100, 107.22, 112.78, 125}
+	for i, mark := range marks {
+		if math.Abs(mark.Origin.X-expectedX[i]) > 1e-6 || math.Abs(mark.Origin.Y-700) > 1e-6 {
+			t.Errorf("Mark %d: incorrect origin %v (expected x=%f)", i, mark.Origin, expectedX[i])
+		}
+		if mark.FontSize != 10 || mark.BaseFont != "Helvetica" {
+			t.Errorf("Mark %d: incorrect font %s %f", i, mark.BaseFont, mark.FontSize)
+		}
+		if mark.BBox.Ury <= mark.BBox.Lly || mark.BBox.Urx <= mark.BBox.Llx {
+			t.Errorf("Mark %d: invalid bbox %+v", i, mark.BBox)
+		}
+	}
+}
+
+func TestTextMarkLanguage(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	props := core.MakeDict()
+	props.Set("P1", core.MakeDict())
+	props.Get("P1").(*core.PdfObjectDictionary).Set("Lang", core.MakeString("fr"))
+	resources.Properties = props
+
+	e := Extractor{}
+	e.contents = `BT /F1 10 Tf (a) Tj /Span << /Lang (de) >> BDC (b) Tj /Span /P1 BDC (c) Tj EMC ` +
+		`/Span << /MCID 0 >> BDC (d) Tj EMC EMC (e) Tj ET`
+	e.resources = resources
+	e.SetLanguage("en")
+
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		t.Fatalf("Error extracting text marks: %v", err)
+	}
+	expected := []string{"en", "de", "fr", "de", "en"}
+	if len(marks) != len(expected) {
+		t.Fatalf("Incorrect number of marks: %d", len(marks))
+	}
+	for i, mark := range marks {
+		if mark.Lang != expected[i] {
+			t.Errorf("Mark %d (%s): language %q != %q", i, mark.Text, mark.Lang, expected[i])
+		}
+	}
+}
+
+// testEncodingCMap is the encoding CMap of a composite font with 1 and 2 byte codes, not mapped to the same CIDs.
+const testEncodingCMap = `
+/CIDInit /ProcSet findresource begin
+12 dict begin begincmap
+/CMapName /Test-H def
+/CMapType 1 def
+2 begincodespacerange
+<00> <80>
+<8140> <9ffc>
+endcodespacerange
+2 begincidrange
+<20> <7e> 1
+<8140> <817e> 633
+endcidrange
+endcmap
+`
+
+func TestTextMarksCompositeFont(t *testing.T) {
+	encoding, err := core.MakeStream([]byte(testEncodingCMap), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	cidFont := core.MakeDict()
+	cidFont.Set("Type", core.MakeName("Font"))
+	cidFont.Set("Subtype", core.MakeName("CIDFontType2"))
+	cidFont.Set("DW", core.MakeInteger(1000))
+	cidFont.Set("W", core.MakeArray(core.MakeInteger(2), core.MakeArrayFromIntegers([]int{500}),
+		core.MakeInteger(634), core.MakeArrayFromIntegers([]int{250})))
+	font := core.MakeDict()
+	font.Set("Type", core.MakeName("Font"))
+	font.Set("Subtype", core.MakeName("Type0"))
+	font.Set("BaseFont", core.MakeName("Test"))
+	font.Set("Encoding", encoding)
+	font.Set("DescendantFonts", core.MakeArray(cidFont))
+	resources := model.NewPdfPageResources()
+	if err := resources.SetFontByName("F1", font); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = "BT /F1 10 Tf 100 700 Td <21814122> Tj ET"
+	e.resources = resources
+
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		t.Fatalf("Error extracting text marks: %v", err)
+	}
+	if len(marks) != 3 {
+		t.Fatalf("Incorrect number of marks: %d", len(marks))
+	}
+	// Codes 0x21, 0x8141 and 0x22: CIDs 2 (width 500), 634 (width 250) and 3 (default width).
+	expectedCodes := []string{"\x21", "\x81\x41", "\x22"}
+	expectedX := []float64{100, 105, 107.5}
+	for i, mark := range marks {
+		if string(mark.CharCodes) != expectedCodes[i] {
+			t.Errorf("Mark %d: incorrect codes % X", i, mark.CharCodes)
+		}
+		if math.Abs(mark.Origin.X-expectedX[i]) > 1e-6 {
+			t.Errorf("Mark %d: incorrect origin %v (expected x=%f)", i, mark.Origin, expectedX[i])
+		}
+	}
+	if math.Abs(marks[2].End.X-117.5) > 1e-6 {
+		t.Errorf("Incorrect end %v", marks[2].End)
+	}
+}
+
+const testContents3 = `
+BT
+/F1 10 Tf
+12 TL
+100 700 Td
+(Hello world) Tj
+T*
+(Second) Tj
+[(li) 20 (ne)] TJ
+ET
+`
+
+func TestTextWordsAndLines(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testContents3
+	e.resources = resources
+
+	words, err := e.Words()
+	if err != nil {
+		t.Fatalf("Error extracting words: %v", err)
+	}
+	expected := []string{"Hello", "world", "Secondline"}
+	if len(words) != len(expected) {
+		t.Fatalf("Incorrect number of words: %d", len(words))
+	}
+	for i, word := range words {
+		if word.Text != expected[i] {
+			t.Errorf("Word %d mismatch: %s (expected %s)", i, word.Text, expected[i])
+		}
+		if word.BBox.Urx <= word.BBox.Llx || word.BBox.Ury <= word.BBox.Lly {
+			t.Errorf("Word %d: invalid bbox %+v", i, word.BBox)
+		}
+	}
+	if words[0].Origin.X != 100 || words[0].Origin.Y != 700 || words[2].Origin.Y != 688 {
+		t.Errorf("Incorrect word origins: %v %v", words[0].Origin, words[2].Origin)
+	}
+	if words[1].BBox.Llx <= words[0].BBox.Urx {
+		t.Errorf("Words overlap: %+v %+v", words[0].BBox, words[1].BBox)
+	}
+
+	lines, err := e.Lines()
+	if err != nil {
+		t.Fatalf("Error extracting lines: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Incorrect number of lines: %d", len(lines))
+	}
+	if lines[0].Text != "Hello world" || lines[1].Text != "Secondline" {
+		t.Errorf("Line text mismatch: %q %q", lines[0].Text, lines[1].Text)
+	}
+	if lines[0].BBox.Lly <= lines[1].BBox.Lly {
+		t.Errorf("Incorrect line order: %+v %+v", lines[0].BBox, lines[1].BBox)
+	}
+}
+
+const testParagraphContents = `
+BT
+/F2 18 Tf
+100 700 Td
+(Title) Tj
+/F1 10 Tf
+0 -30 Td
+(First paragraph with a hyph-) Tj
+0 -12 Td
+(enated word) Tj
+0 -36 Td
+(Second paragraph) Tj
+ET
+`
+
+func TestTextParagraphs(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetFontByName("F2", fonts.NewFontHelveticaBold().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testParagraphContents
+	e.resources = resources
+
+	paragraphs, err := e.Paragraphs()
+	if err != nil {
+		t.Fatalf("Error extracting paragraphs: %v", err)
+	}
+	expected := []string{"Title", "First paragraph with a hyphenated word", "Second paragraph"}
+	if len(paragraphs) != len(expected) {
+		t.Fatalf("Incorrect number of paragraphs: %d", len(paragraphs))
+	}
+	for i, paragraph := range paragraphs {
+		if paragraph.Text != expected[i] {
+			t.Errorf("Paragraph %d mismatch: %q (expected %q)", i, paragraph.Text, expected[i])
+		}
+	}
+	if !paragraphs[0].Bold || paragraphs[1].Bold || paragraphs[0].FontSize != 18 || paragraphs[1].FontSize != 10 {
+		t.Errorf("Incorrect paragraph styles: %+v %+v", paragraphs[0], paragraphs[1])
+	}
+	if len(paragraphs[1].Lines) != 2 {
+		t.Errorf("Incorrect number of lines: %d", len(paragraphs[1].Lines))
+	}
+}
+
+func TestTextSearch(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	err := resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	e := Extractor{}
+	e.contents = testContents3
+	e.resources = resources
+
+	// Match spanning a line break and text showing operations.
+	matches, err := e.SearchText("world  Second")
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("Incorrect number of matches: %d", len(matches))
+	}
+	if matches[0].Text != "world\nSecond" || len(matches[0].Quads) != 2 {
+		t.Fatalf("Incorrect match: %q (%d quads)", matches[0].Text, len(matches[0].Quads))
+	}
+	if matches[0].Quads[0][0].Y != 698 || matches[0].Quads[1][0].Y != 686 {
+		t.Errorf("Incorrect quads: %v", matches[0].Quads)
+	}
+
+	matches, err = e.Search(regexp.MustCompile(`l+`))
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("Incorrect number of matches: %d", len(matches))
+	}
+	// "li" shown with separate strings in a TJ array.
+	bbox := matches[2].BBox
+	if matches[2].Text != "l" || bbox.Llx < 100 || bbox.Urx-bbox.Llx > 3 {
+		t.Errorf("Incorrect match: %q %+v", matches[2].Text, bbox)
+	}
+}
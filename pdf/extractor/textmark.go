@@ -0,0 +1,507 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// TextMark represents a single character drawn on the page, along with its style and position.
+type TextMark struct {
+	// The unicode text of the character.  Typically a single rune, but can be more (e.g. for ligatures).
+	Text string
+
+	// The original character code bytes as drawn by the text showing operator.
+	CharCodes []byte
+
+	// The font resource name and the base font name of the font used.
+	FontName string
+	BaseFont string
+
+	// The effective font size in page units, i.e. accounting for text and graphics transformations.
+	FontSize float64
+
+	// The fill color of the text (non-stroking color) and the text rendering mode (Tr).
+	Color      model.PdfColor
+	RenderMode int
+
+	// The quadrilateral covering the glyph in page coordinates: lower left, lower right, upper right and upper left
+	// corners (with respect to the text direction).
+	Quad [4]draw.Point
+
+	// The axis aligned bounding box of the quadrilateral.
+	BBox model.PdfRectangle
+
+	// The baseline start and end points of the glyph in page coordinates.
+	Origin draw.Point
+	End    draw.Point
+
+	// The text showing operation drawing the character, the index of the string in its operands (or in the array
+	// of TJ operations) and the offset of the character code in the string.
+	Location    ContentLocation
+	StringIndex int
+	Offset      int
+
+	// The horizontal displacement of the glyph in thousandths of text space units, including character and word
+	// spacing: a number -Displacement in a TJ array moves the text position as the glyph does.
+	Displacement float64
+
+	// The natural language of the text (e.g. "en-US"): the Lang property of the innermost marked content sequence
+	// specifying one, or the language of the document set on the extractor.  Empty if unknown.
+	Lang string
+
+	// The font of the character.
+	font *textFont
+
+	// The marked-content identifier of the innermost marked content sequence with one, -1 if none.
+	mcid int
+
+	// The fill color converted to RGB, if the conversion succeeded.
+	fillRGB      [3]float64
+	fillRGBKnown bool
+}
+
+// textMarkCollector collects text marks while processing content streams.
+type textMarkCollector struct {
+	marks []TextMark
+
+	// The text matrix and the text line matrix, which are not part of the graphics state.
+	textMatrix contentstream.Matrix
+	lineMatrix contentstream.Matrix
+
+	// Fonts loaded by font dictionary, shared between content streams.
+	fontCache map[core.PdfObject]*textFont
+
+	// Rectangles of the current path in page coordinates.  pathOther is set when the path contains other segments.
+	pathRects []model.PdfRectangle
+	pathOther bool
+
+	// Filled rectangles in drawing order, for determining the background of the text.
+	fills []filledRect
+
+	// The location of the current operation, and the index of the current string of TJ arrays.
+	location    ContentLocation
+	stringIndex int
+
+	// The language and the marked-content identifier of the current operation.
+	lang string
+	mcid int
+
+	// The handlers registered on the extractor, the marked content stack of the current content stream and the
+	// marked content sequences of the content streams drawing it.
+	handlers      *contentHandlers
+	stack         []contentstream.MarkedContent
+	markedContent []contentstream.MarkedContent
+}
+
+// ContentLocation is the location of an operation in the content streams of a page.
+type ContentLocation struct {
+	// The resource names of the nested Form XObjects containing the operation, starting from the page content
+	// stream.  Empty for operations of the page content stream.
+	Forms []core.PdfObjectName
+
+	// The index of the operation among the operations of its content stream.
+	Operation int
+}
+
+// filledRect is a rectangle filled with an opaque color.
+type filledRect struct {
+	bbox model.PdfRectangle
+	rgb  [3]float64
+
+	// The number of marks drawn before the rectangle.
+	numMarks int
+}
+
+// ExtractTextMarks processes the content streams and returns all characters drawn on the page as TextMarks in the
+// order in which they appear in the content stream.  Text drawn inside Form XObjects is included.
+func (e *Extractor) ExtractTextMarks() ([]TextMark, error) {
+	col := newTextMarkCollector()
+	col.lang = e.lang
+	col.handlers = &e.handlers
+	err := e.processContents(col.addHandlers)
+	if err != nil {
+		return col.marks, err
+	}
+
+	return col.marks, nil
+}
+
+func newTextMarkCollector() *textMarkCollector {
+	col := &textMarkCollector{}
+	col.textMatrix = contentstream.IdentityMatrix()
+	col.lineMatrix = contentstream.IdentityMatrix()
+	col.fontCache = map[core.PdfObject]*textFont{}
+	col.mcid = -1
+	return col
+}
+
+// addHandlers registers the handlers collecting the text marks of a content stream.  The location, the language and
+// the marked content of a form are those of the Do operation drawing it, restored once the form is processed.
+func (col *textMarkCollector) addHandlers(processor *contentstream.ContentStreamProcessor,
+	form *contentstream.DrawnForm) (func() error, error) {
+	done := col.enterForm(form)
+
+	// The language of the content outside marked content sequences, e.g. of the sequence drawing a form.
+	baseLang, baseMCID := col.lang, col.mcid
+
+	index := 0
+	processor.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+			col.location.Operation = index
+			index++
+			col.stringIndex = 0
+			col.stack = processor.GetMarkedContentStack()
+			col.lang = markedContentLanguage(col.stack, resources, baseLang)
+			col.mcid = markedContentID(col.stack, resources, baseMCID)
+			return nil
+		})
+	processor.AddCategoryHandler(contentstream.OperatorCategoryPathConstruction, col.handlePathConstruction)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryPathPainting, col.handlePathPainting)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryTextObject, col.handleTextObject)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryTextPositioning, col.handleTextPositioning)
+	processor.AddCategoryHandler(contentstream.OperatorCategoryTextShowing, col.handleTextShowing)
+	if !col.handlers.empty() {
+		// Called after the marks of the operation are collected, and before the form drawn by a Do operation is
+		// processed.
+		processor.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+			func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+				return col.callHandlers(op, gs, resources, processor.GetMarkedContentStack())
+			})
+	}
+
+	return done, nil
+}
+
+// handlePathConstruction tracks the rectangles of the current path (re), for the background of the text.
+func (col *textMarkCollector) handlePathConstruction(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	if op.Operand == "re" {
+		col.addPathRect(op, gs.CTM)
+	} else {
+		col.pathOther = true
+	}
+	return nil
+}
+
+// handlePathPainting records the rectangles of the current path when filled, and ends the path.
+func (col *textMarkCollector) handlePathPainting(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	switch op.Operand {
+	case "f", "F", "f*", "B", "B*", "b", "b*":
+		if !col.pathOther {
+			rgb, _ := getRGB(gs.ColorspaceNonStroking, gs.ColorNonStroking)
+			for _, rect := range col.pathRects {
+				col.fills = append(col.fills, filledRect{bbox: rect, rgb: rgb, numMarks: len(col.marks)})
+			}
+		}
+	}
+	col.pathRects, col.pathOther = nil, false
+	return nil
+}
+
+// handleTextObject resets the text and line matrices at the beginning of a text object (BT).
+func (col *textMarkCollector) handleTextObject(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	if op.Operand == "BT" {
+		col.textMatrix = contentstream.IdentityMatrix()
+		col.lineMatrix = contentstream.IdentityMatrix()
+	}
+	return nil
+}
+
+// handleTextPositioning updates the text and line matrices for a text positioning operator.
+func (col *textMarkCollector) handleTextPositioning(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	switch op.Operand {
+	case "Td", "TD":
+		if len(op.Params) != 2 {
+			common.Log.Debug("%s: invalid number of operands", op.Operand)
+			return nil
+		}
+		tx, err1 := getNumberAsFloat(op.Params[0])
+		ty, err2 := getNumberAsFloat(op.Params[1])
+		if err1 != nil || err2 != nil {
+			common.Log.Debug("%s: invalid operands", op.Operand)
+			return nil
+		}
+		col.moveLine(tx, ty)
+	case "Tm":
+		m, err := contentstream.NewMatrixFromPdfObjects(op.Params)
+		if err != nil {
+			common.Log.Debug("Invalid Tm operands: %v", err)
+			return nil
+		}
+		col.textMatrix = m
+		col.lineMatrix = m
+	case "T*":
+		col.moveLine(0, -gs.Leading)
+	}
+	return nil
+}
+
+// handleTextShowing collects the marks of a text showing operator.  The word and character spacing set by the "
+// operator are already in the graphics state.
+func (col *textMarkCollector) handleTextShowing(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+	switch op.Operand {
+	case "Tj":
+		if len(op.Params) < 1 {
+			return nil
+		}
+		str, ok := op.Params[0].(*core.PdfObjectString)
+		if !ok {
+			common.Log.Debug("Tj: operand not a string (%T)", op.Params[0])
+			return nil
+		}
+		col.showText([]byte(*str), gs, resources)
+	case "'":
+		if len(op.Params) < 1 {
+			return nil
+		}
+		col.moveLine(0, -gs.Leading)
+		if str, ok := op.Params[0].(*core.PdfObjectString); ok {
+			col.showText([]byte(*str), gs, resources)
+		}
+	case "\"":
+		if len(op.Params) < 3 {
+			return nil
+		}
+		col.moveLine(0, -gs.Leading)
+		col.stringIndex = 2
+		if str, ok := op.Params[2].(*core.PdfObjectString); ok {
+			col.showText([]byte(*str), gs, resources)
+		}
+	case "TJ":
+		if len(op.Params) < 1 {
+			return nil
+		}
+		arr, ok := op.Params[0].(*core.PdfObjectArray)
+		if !ok {
+			common.Log.Debug("TJ: operand not an array (%T)", op.Params[0])
+			return nil
+		}
+		for i, obj := range *arr {
+			col.stringIndex = i
+			switch v := obj.(type) {
+			case *core.PdfObjectString:
+				col.showText([]byte(*v), gs, resources)
+			case *core.PdfObjectFloat, *core.PdfObjectInteger:
+				val, _ := getNumberAsFloat(v)
+				tx := -val / 1000 * gs.FontSize * gs.HorizontalScaling / 100
+				col.textMatrix = contentstream.TranslationMatrix(tx, 0).Mult(col.textMatrix)
+			}
+		}
+	}
+	return nil
+}
+
+// addPathRect adds the rectangle of a re operator to the current path.
+func (col *textMarkCollector) addPathRect(op *contentstream.ContentStreamOperation, m contentstream.Matrix) {
+	if len(op.Params) != 4 {
+		common.Log.Debug("re: invalid number of operands")
+		return
+	}
+	vals := [4]float64{}
+	for i, param := range op.Params {
+		val, err := getNumberAsFloat(param)
+		if err != nil {
+			common.Log.Debug("re: invalid operand")
+			return
+		}
+		vals[i] = val
+	}
+
+	if !(m[1] == 0 && m[2] == 0) && !(m[0] == 0 && m[3] == 0) {
+		// Rotated or skewed rectangles are not tracked.
+		col.pathOther = true
+		return
+	}
+	x, y, w, h := vals[0], vals[1], vals[2], vals[3]
+	quad := [4]draw.Point{}
+	for i, c := range [4][2]float64{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}} {
+		px, py := m.Transform(c[0], c[1])
+		quad[i] = draw.NewPoint(px, py)
+	}
+	col.pathRects = append(col.pathRects, quadBBox(quad))
+}
+
+// moveLine moves to the start of the next line offset by (tx, ty).
+func (col *textMarkCollector) moveLine(tx, ty float64) {
+	col.lineMatrix = contentstream.TranslationMatrix(tx, ty).Mult(col.lineMatrix)
+	col.textMatrix = col.lineMatrix
+}
+
+// getFont returns the font with the specified resource name.
+func (col *textMarkCollector) getFont(name core.PdfObjectName, resources *model.PdfPageResources) *textFont {
+	obj, found := resources.GetFontByName(name)
+	if !found {
+		common.Log.Debug("Font %s not found in resources", name)
+		return newTextFont(string(name), nil)
+	}
+	if font, has := col.fontCache[obj]; has {
+		return font
+	}
+	font := newTextFont(string(name), obj)
+	col.fontCache[obj] = font
+	return font
+}
+
+// showText adds the marks for the string data of a text showing operator and advances the text matrix.
+func (col *textMarkCollector) showText(data []byte, gs contentstream.GraphicsState,
+	resources *model.PdfPageResources) {
+	var font *textFont
+	if gs.Font == "" {
+		common.Log.Debug("Text shown without a font")
+		font = newTextFont("", nil)
+	} else {
+		font = col.getFont(gs.Font, resources)
+	}
+	hScaling := gs.HorizontalScaling / 100
+
+	fillRGB, fillRGBKnown := getRGB(gs.ColorspaceNonStroking, gs.ColorNonStroking)
+
+	offset := 0
+	for _, code := range font.splitCharcodes(data) {
+		// Text rendering matrix: [Tfs*Th 0 0 Tfs 0 Trise] x Tm x CTM.
+		trm := contentstream.NewMatrix(gs.FontSize*hScaling, 0, 0, gs.FontSize, 0, gs.TextRise).
+			Mult(col.textMatrix).Mult(gs.CTM)
+
+		w := font.glyphWidth(code) * font.glyphScale
+		asc := font.ascent * 0.001
+		desc := font.descent * 0.001
+
+		mark := TextMark{
+			Text:         font.toUnicodeText(code),
+			CharCodes:    append([]byte{}, code...),
+			FontName:     font.name,
+			BaseFont:     font.baseFont,
+			FontSize:     trm.ScalingFactorY(),
+			Color:        gs.ColorNonStroking,
+			RenderMode:   gs.TextRenderMode,
+			Lang:         col.lang,
+			mcid:         col.mcid,
+			font:         font,
+			fillRGB:      fillRGB,
+			fillRGBKnown: fillRGBKnown,
+		}
+		corners := [4][2]float64{{0, desc}, {w, desc}, {w, asc}, {0, asc}}
+		for i, c := range corners {
+			x, y := trm.Transform(c[0], c[1])
+			mark.Quad[i] = draw.NewPoint(x, y)
+		}
+		mark.BBox = quadBBox(mark.Quad)
+		ox, oy := trm.Transform(0, 0)
+		ex, ey := trm.Transform(w, 0)
+		mark.Origin = draw.NewPoint(ox, oy)
+		mark.End = draw.NewPoint(ex, ey)
+
+		// Advance: tx = (w0*Tfs + Tc + Tw) * Th, where Tw applies to single byte code 32 only.
+		tx := w*gs.FontSize + gs.CharSpacing
+		if len(code) == 1 && code[0] == 32 {
+			tx += gs.WordSpacing
+		}
+		tx *= hScaling
+
+		mark.Location = ContentLocation{
+			Forms:     append([]core.PdfObjectName{}, col.location.Forms...),
+			Operation: col.location.Operation,
+		}
+		mark.StringIndex = col.stringIndex
+		mark.Offset = offset
+		if gs.FontSize*hScaling != 0 {
+			mark.Displacement = tx * 1000 / (gs.FontSize * hScaling)
+		}
+		offset += len(code)
+
+		col.marks = append(col.marks, mark)
+		col.textMatrix = contentstream.TranslationMatrix(tx, 0).Mult(col.textMatrix)
+	}
+}
+
+// enterForm sets the state for processing the form drawn by the current Do operation, and returns the function
+// restoring the state.  Nil for the page.
+func (col *textMarkCollector) enterForm(form *contentstream.DrawnForm) func() error {
+	if form == nil {
+		return nil
+	}
+
+	savedTextMatrix, savedLineMatrix := col.textMatrix, col.lineMatrix
+	savedLocation, savedLang, savedMCID := col.location, col.lang, col.mcid
+	savedMarkedContent := col.markedContent
+	col.location.Forms = append(append([]core.PdfObjectName{}, savedLocation.Forms...), form.Name)
+	col.markedContent = append(append([]contentstream.MarkedContent{}, savedMarkedContent...), col.stack...)
+	return func() error {
+		col.textMatrix, col.lineMatrix = savedTextMatrix, savedLineMatrix
+		col.location = savedLocation
+		col.lang = savedLang
+		col.mcid = savedMCID
+		col.markedContent = savedMarkedContent
+		return nil
+	}
+}
+
+// markedContentLanguage returns the Lang property of the innermost marked content sequence of the stack specifying
+// one, or lang if none does.
+func markedContentLanguage(stack []contentstream.MarkedContent, resources *model.PdfPageResources, lang string) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		dict, ok := markedContentProperties(stack[i], resources)
+		if !ok {
+			continue
+		}
+		if str, ok := core.TraceToDirectObject(dict.Get("Lang")).(*core.PdfObjectString); ok {
+			return core.DecodeTextString(string(*str))
+		}
+	}
+	return lang
+}
+
+// markedContentID returns the MCID property of the innermost marked content sequence of the stack specifying one, or
+// mcid if none does.
+func markedContentID(stack []contentstream.MarkedContent, resources *model.PdfPageResources, mcid int) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		dict, ok := markedContentProperties(stack[i], resources)
+		if !ok {
+			continue
+		}
+		if id, ok := core.TraceToDirectObject(dict.Get("MCID")).(*core.PdfObjectInteger); ok {
+			return int(*id)
+		}
+	}
+	return mcid
+}
+
+// markedContentProperties returns the property list of a marked content sequence, either an inline dictionary or a
+// named resource (Properties).
+func markedContentProperties(mc contentstream.MarkedContent, resources *model.PdfPageResources) (
+	*core.PdfObjectDictionary, bool) {
+	props := core.TraceToDirectObject(mc.Properties)
+	if name, ok := props.(*core.PdfObjectName); ok && resources != nil {
+		if dict, ok := core.TraceToDirectObject(resources.Properties).(*core.PdfObjectDictionary); ok {
+			props = core.TraceToDirectObject(dict.Get(*name))
+		}
+	}
+	dict, ok := props.(*core.PdfObjectDictionary)
+	return dict, ok
+}
+
+// quadBBox returns the axis aligned bounding box of a quadrilateral.
+func quadBBox(quad [4]draw.Point) model.PdfRectangle {
+	bbox := model.PdfRectangle{Llx: quad[0].X, Lly: quad[0].Y, Urx: quad[0].X, Ury: quad[0].Y}
+	for _, p := range quad[1:] {
+		bbox.Llx = math.Min(bbox.Llx, p.X)
+		bbox.Lly = math.Min(bbox.Lly, p.Y)
+		bbox.Urx = math.Max(bbox.Urx, p.X)
+		bbox.Ury = math.Max(bbox.Ury, p.Y)
+	}
+	return bbox
+}
@@ -10,8 +10,10 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/unidoc/unidoc/common"
 	"github.com/unidoc/unidoc/common/license"
 	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
 )
 
 // getNumberAsFloat can retrieve numeric values from PdfObject (both integer/float).
@@ -27,6 +29,23 @@ func getNumberAsFloat(obj core.PdfObject) (float64, error) {
 	return 0, errors.New("Not a number")
 }
 
+// getRGB converts a color in the colorspace to RGB components.  Returns black and false if the conversion fails.
+func getRGB(cs model.PdfColorspace, color model.PdfColor) ([3]float64, bool) {
+	if cs == nil || color == nil {
+		return [3]float64{}, false
+	}
+	rgb, err := cs.ColorToRGB(color)
+	if err != nil {
+		common.Log.Debug("Color conversion to RGB failed: %v", err)
+		return [3]float64{}, false
+	}
+	c, ok := rgb.(*model.PdfColorDeviceRGB)
+	if !ok {
+		return [3]float64{}, false
+	}
+	return [3]float64{c.R(), c.G(), c.B()}, true
+}
+
 func procBuf(buf *bytes.Buffer) {
 	if isTesting {
 		return
@@ -0,0 +1,174 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+	"strings"
+
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Segmentation thresholds, as fractions of the font size.
+const (
+	// Horizontal gap between characters that separates words.
+	wordGapThreshold = 0.15
+	// Vertical offset between characters or words that indicates a new baseline.
+	baselineThreshold = 0.4
+	// Horizontal gap between words that separates lines on the same baseline (e.g. columns).
+	lineGapThreshold = 3.0
+)
+
+// TextWord represents a word: a sequence of characters on the same baseline without whitespace or gaps between them.
+type TextWord struct {
+	// The text of the word.
+	Text string
+
+	// The marks of the characters of the word.
+	Marks []TextMark
+
+	// The bounding box of the word in page coordinates.
+	BBox model.PdfRectangle
+
+	// The baseline start and end points of the word in page coordinates.
+	Origin draw.Point
+	End    draw.Point
+
+	// The largest font size of the characters of the word.
+	FontSize float64
+}
+
+// TextLine represents a line of text: a sequence of words on the same baseline.
+type TextLine struct {
+	// The text of the line, with the words separated by spaces.
+	Text string
+
+	// The words of the line.
+	Words []TextWord
+
+	// The bounding box of the line in page coordinates.
+	BBox model.PdfRectangle
+
+	// The baseline start and end points of the line in page coordinates.
+	Origin draw.Point
+	End    draw.Point
+
+	// The largest font size of the words of the line.
+	FontSize float64
+}
+
+// Words returns the words of the text on the page, in content stream order.  Characters are grouped into words
+// based on whitespace and the gaps between the glyphs, derived from the font metrics.
+func (e *Extractor) Words() ([]TextWord, error) {
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		return nil, err
+	}
+	return groupWords(marks), nil
+}
+
+// Lines returns the lines of text on the page, in content stream order.  Words are grouped into lines when they
+// follow each other on the same baseline without a large gap.
+func (e *Extractor) Lines() ([]TextLine, error) {
+	words, err := e.Words()
+	if err != nil {
+		return nil, err
+	}
+	return groupLines(words), nil
+}
+
+// groupWords groups text marks into words.
+func groupWords(marks []TextMark) []TextWord {
+	words := []TextWord{}
+	var cur *TextWord
+	for _, mark := range marks {
+		if strings.TrimSpace(mark.Text) == "" && mark.Text != "" {
+			// Whitespace ends the word.
+			cur = nil
+			continue
+		}
+
+		if cur != nil {
+			last := cur.Marks[len(cur.Marks)-1]
+			fontSize := math.Max(last.FontSize, mark.FontSize)
+			along, perp := baselineOffset(last.Origin, last.End, mark.Origin)
+			if math.Abs(perp) > baselineThreshold*fontSize || along > wordGapThreshold*fontSize ||
+				along < -wordGapThreshold*fontSize {
+				cur = nil
+			}
+		}
+
+		if cur == nil {
+			words = append(words, TextWord{BBox: mark.BBox, Origin: mark.Origin})
+			cur = &words[len(words)-1]
+		}
+		cur.Marks = append(cur.Marks, mark)
+		cur.Text += mark.Text
+		cur.BBox = unionRect(cur.BBox, mark.BBox)
+		cur.End = mark.End
+		cur.FontSize = math.Max(cur.FontSize, mark.FontSize)
+	}
+	return words
+}
+
+// groupLines groups words into lines.
+func groupLines(words []TextWord) []TextLine {
+	lines := []TextLine{}
+	var cur *TextLine
+	for _, word := range words {
+		if cur != nil {
+			last := cur.Words[len(cur.Words)-1]
+			fontSize := math.Max(last.FontSize, word.FontSize)
+			along, perp := baselineOffset(cur.Origin, last.End, word.Origin)
+			if math.Abs(perp) > baselineThreshold*fontSize || along > lineGapThreshold*fontSize ||
+				along < -wordGapThreshold*fontSize {
+				cur = nil
+			}
+		}
+
+		if cur == nil {
+			lines = append(lines, TextLine{BBox: word.BBox, Origin: word.Origin})
+			cur = &lines[len(lines)-1]
+		} else {
+			cur.Text += " "
+		}
+		cur.Words = append(cur.Words, word)
+		cur.Text += word.Text
+		cur.BBox = unionRect(cur.BBox, word.BBox)
+		cur.End = word.End
+		cur.FontSize = math.Max(cur.FontSize, word.FontSize)
+	}
+	return lines
+}
+
+// baselineOffset returns the offset of point p from the end point of the baseline from origin to end, along the
+// baseline direction and perpendicular to it.
+func baselineOffset(origin, end, p draw.Point) (float64, float64) {
+	dx := end.X - origin.X
+	dy := end.Y - origin.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		// Zero width glyph: assume horizontal text.
+		dx, dy, length = 1, 0, 1
+	}
+	dx /= length
+	dy /= length
+
+	vx := p.X - end.X
+	vy := p.Y - end.Y
+	return vx*dx + vy*dy, vy*dx - vx*dy
+}
+
+// unionRect returns the smallest rectangle containing both rectangles.
+func unionRect(a, b model.PdfRectangle) model.PdfRectangle {
+	return model.PdfRectangle{
+		Llx: math.Min(a.Llx, b.Llx),
+		Lly: math.Min(a.Lly, b.Lly),
+		Urx: math.Max(a.Urx, b.Urx),
+		Ury: math.Max(a.Ury, b.Ury),
+	}
+}
@@ -0,0 +1,560 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fdf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Annotation is a markup annotation of a page, e.g. a review comment, as exchanged in XFDF.  Coordinates are in the
+// default user space of the page, and dates in the PDF date format, e.g. D:20180423144817+02'00'.
+type Annotation struct {
+	// The annotation subtype, e.g. Highlight, Text or Ink.
+	Type string
+
+	// Index of the page (from 0).
+	Page int
+
+	Rect model.PdfRectangle
+
+	// Unique name of the annotation (NM), referenced by its replies.
+	Name string
+
+	// The name of the annotation this annotation replies to (IRT), and the type of the reply (RT): R (reply, the
+	// default) or Group.
+	InReplyTo string
+	ReplyType string
+
+	Author   string
+	Subject  string
+	Contents string
+
+	// The rich text of the contents (RC), a XHTML fragment.
+	RichContents string
+
+	Modified string
+	Created  string
+
+	// Annotation flags (F), e.g. 4 for printing.
+	Flags int
+
+	// The color (C) and the interior color (IC) components: gray, RGB or CMYK.
+	Color         []float64
+	InteriorColor []float64
+
+	// Constant opacity (CA), 0 for the default of 1 (opaque).
+	Opacity float64
+
+	// Border width (W of BS), nil for the default of 1.
+	BorderWidth *float64
+
+	// Icon of text and stamp annotations (Name), e.g. Comment or Approved.
+	Icon string
+
+	// Quadrilaterals of text markup and redaction annotations (QuadPoints), 8 coordinates each.
+	QuadPoints []float64
+
+	// End points of line annotations (L): x1 y1 x2 y2.
+	Line []float64
+
+	// Line ending styles of line and polyline annotations (LE), e.g. OpenArrow.
+	LineEndings []string
+
+	// Vertices of polygon and polyline annotations: x1 y1 x2 y2 ...
+	Vertices []float64
+
+	// Paths of ink annotations (InkList), each x1 y1 x2 y2 ...
+	InkList [][]float64
+
+	// Default appearance string (DA), default style string (DS) and justification (Q: 0 left, 1 centered, 2
+	// right) of free text annotations.
+	DefaultAppearance string
+	DefaultStyle      string
+	Justification     int
+
+	// Symbol of caret annotations (Sy): P for a paragraph symbol, or None.
+	Symbol string
+
+	// The pop-up window of the annotation, if any.
+	Popup *Popup
+}
+
+// Popup is the pop-up window of a markup annotation, displaying its text.
+type Popup struct {
+	Rect model.PdfRectangle
+	Open bool
+}
+
+// annotationEntries are the markup part and the entries specific to the annotation types exchanged in XFDF, nil
+// when not applicable to the type.
+type annotationEntries struct {
+	subtype string
+	markup  *model.PdfAnnotationMarkup
+
+	name, quadPoints, ic, bs, l, le, vertices, inkList, da, ds, q, sy *core.PdfObject
+}
+
+// getAnnotationEntries returns the entries of an annotation, or nil if the annotation type is not exchanged in
+// XFDF, e.g. links, popups or file attachments.
+func getAnnotationEntries(annot *model.PdfAnnotation) *annotationEntries {
+	switch t := annot.GetContext().(type) {
+	case *model.PdfAnnotationText:
+		return &annotationEntries{subtype: "Text", markup: t.PdfAnnotationMarkup, name: &t.Name}
+	case *model.PdfAnnotationFreeText:
+		return &annotationEntries{subtype: "FreeText", markup: t.PdfAnnotationMarkup, bs: &t.BS, da: &t.DA,
+			ds: &t.DS, q: &t.Q}
+	case *model.PdfAnnotationLine:
+		return &annotationEntries{subtype: "Line", markup: t.PdfAnnotationMarkup, ic: &t.IC, bs: &t.BS, l: &t.L,
+			le: &t.LE}
+	case *model.PdfAnnotationSquare:
+		return &annotationEntries{subtype: "Square", markup: t.PdfAnnotationMarkup, ic: &t.IC, bs: &t.BS}
+	case *model.PdfAnnotationCircle:
+		return &annotationEntries{subtype: "Circle", markup: t.PdfAnnotationMarkup, ic: &t.IC, bs: &t.BS}
+	case *model.PdfAnnotationPolygon:
+		return &annotationEntries{subtype: "Polygon", markup: t.PdfAnnotationMarkup, ic: &t.IC, bs: &t.BS,
+			vertices: &t.Vertices}
+	case *model.PdfAnnotationPolyLine:
+		return &annotationEntries{subtype: "PolyLine", markup: t.PdfAnnotationMarkup, ic: &t.IC, bs: &t.BS,
+			le: &t.LE, vertices: &t.Vertices}
+	case *model.PdfAnnotationHighlight:
+		return &annotationEntries{subtype: "Highlight", markup: t.PdfAnnotationMarkup, quadPoints: &t.QuadPoints}
+	case *model.PdfAnnotationUnderline:
+		return &annotationEntries{subtype: "Underline", markup: t.PdfAnnotationMarkup, quadPoints: &t.QuadPoints}
+	case *model.PdfAnnotationSquiggly:
+		return &annotationEntries{subtype: "Squiggly", markup: t.PdfAnnotationMarkup, quadPoints: &t.QuadPoints}
+	case *model.PdfAnnotationStrikeOut:
+		return &annotationEntries{subtype: "StrikeOut", markup: t.PdfAnnotationMarkup, quadPoints: &t.QuadPoints}
+	case *model.PdfAnnotationCaret:
+		return &annotationEntries{subtype: "Caret", markup: t.PdfAnnotationMarkup, sy: &t.Sy}
+	case *model.PdfAnnotationStamp:
+		return &annotationEntries{subtype: "Stamp", markup: t.PdfAnnotationMarkup, name: &t.Name}
+	case *model.PdfAnnotationInk:
+		return &annotationEntries{subtype: "Ink", markup: t.PdfAnnotationMarkup, bs: &t.BS, inkList: &t.InkList}
+	case *model.PdfAnnotationRedact:
+		return &annotationEntries{subtype: "Redact", markup: t.PdfAnnotationMarkup, ic: &t.IC,
+			quadPoints: &t.QuadPoints}
+	}
+	return nil
+}
+
+// newPdfAnnotation returns a new annotation of the subtype, or nil if the type is not exchanged in XFDF.
+func newPdfAnnotation(subtype string) *model.PdfAnnotation {
+	switch subtype {
+	case "Text":
+		return model.NewPdfAnnotationText().PdfAnnotation
+	case "FreeText":
+		return model.NewPdfAnnotationFreeText().PdfAnnotation
+	case "Line":
+		return model.NewPdfAnnotationLine().PdfAnnotation
+	case "Square":
+		return model.NewPdfAnnotationSquare().PdfAnnotation
+	case "Circle":
+		return model.NewPdfAnnotationCircle().PdfAnnotation
+	case "Polygon":
+		return model.NewPdfAnnotationPolygon().PdfAnnotation
+	case "PolyLine":
+		return model.NewPdfAnnotationPolyLine().PdfAnnotation
+	case "Highlight":
+		return model.NewPdfAnnotationHighlight().PdfAnnotation
+	case "Underline":
+		return model.NewPdfAnnotationUnderline().PdfAnnotation
+	case "Squiggly":
+		return model.NewPdfAnnotationSquiggly().PdfAnnotation
+	case "StrikeOut":
+		return model.NewPdfAnnotationStrikeOut().PdfAnnotation
+	case "Caret":
+		return model.NewPdfAnnotationCaret().PdfAnnotation
+	case "Stamp":
+		return model.NewPdfAnnotationStamp().PdfAnnotation
+	case "Ink":
+		return model.NewPdfAnnotationInk().PdfAnnotation
+	case "Redact":
+		return model.NewPdfAnnotationRedact().PdfAnnotation
+	}
+	return nil
+}
+
+// NewDataFromAnnotations returns the markup annotations of the pages, with their pop-up windows and replies.  The
+// annotations which are not exchanged in XFDF (file attachments and sounds) are skipped.  Annotations without a
+// name are given one, unique in the document.
+func NewDataFromAnnotations(pages []*model.PdfPage) (*Data, error) {
+	data := &Data{}
+	names := map[string]bool{}
+	irts := []core.PdfObject{}
+	indexes := map[core.PdfObject]int{}
+	numbers := map[int64]int{}
+	for i, page := range pages {
+		for _, annot := range page.Annotations {
+			entries := getAnnotationEntries(annot)
+			if entries == nil {
+				continue
+			}
+			a, err := newAnnotationFromPdf(annot, entries)
+			if err != nil {
+				return nil, err
+			}
+			a.Page = i
+			names[a.Name] = true
+
+			container := annot.GetContainingPdfObject()
+			indexes[container] = len(data.Annotations)
+			if ind, ok := container.(*core.PdfIndirectObject); ok && ind.ObjectNumber > 0 {
+				numbers[ind.ObjectNumber] = len(data.Annotations)
+			}
+			irts = append(irts, entries.markup.IRT)
+			data.Annotations = append(data.Annotations, *a)
+		}
+	}
+
+	for i := range data.Annotations {
+		for n := i + 1; data.Annotations[i].Name == ""; n++ {
+			if name := fmt.Sprintf("annot%d", n); !names[name] {
+				data.Annotations[i].Name = name
+				names[name] = true
+			}
+		}
+	}
+
+	// The replies refer to the annotations by name.
+	for i, irt := range irts {
+		if irt == nil {
+			continue
+		}
+		j, found := indexes[irt]
+		switch t := irt.(type) {
+		case *core.PdfIndirectObject:
+			if !found && t.ObjectNumber > 0 {
+				j, found = numbers[t.ObjectNumber]
+			}
+		case *core.PdfObjectReference:
+			j, found = numbers[t.ObjectNumber]
+		}
+		if !found {
+			common.Log.Debug("Reply to an annotation not exported: %s", irt)
+			continue
+		}
+		data.Annotations[i].InReplyTo = data.Annotations[j].Name
+	}
+	return data, nil
+}
+
+// newAnnotationFromPdf returns the annotation of a PDF annotation with its entries.
+func newAnnotationFromPdf(annot *model.PdfAnnotation, entries *annotationEntries) (*Annotation, error) {
+	a := &Annotation{Type: entries.subtype}
+	rect, err := getNumbers(annot.Rect)
+	if err != nil {
+		return nil, err
+	}
+	if len(rect) != 4 {
+		common.Log.Debug("ERROR: Invalid annotation rectangle %v", rect)
+		return nil, errors.New("Invalid annotation rectangle")
+	}
+	a.Rect = model.PdfRectangle{Llx: rect[0], Lly: rect[1], Urx: rect[2], Ury: rect[3]}
+
+	a.Name = getText(annot.NM)
+	a.Contents = getText(annot.Contents)
+	a.Modified = getText(annot.M)
+	if flags, ok := core.TraceToDirectObject(annot.F).(*core.PdfObjectInteger); ok {
+		a.Flags = int(*flags)
+	}
+	if a.Color, err = getNumbers(annot.C); err != nil {
+		return nil, err
+	}
+
+	markup := entries.markup
+	a.Author = getText(markup.T)
+	a.Subject = getText(markup.Subj)
+	a.RichContents = getText(markup.RC)
+	a.Created = getText(markup.CreationDate)
+	if rt, ok := core.TraceToDirectObject(markup.RT).(*core.PdfObjectName); ok && *rt == "Group" {
+		a.ReplyType = "Group"
+	}
+	if ca, ok := getNumber(markup.CA); ok {
+		a.Opacity = ca
+	}
+	if markup.Popup != nil {
+		rect, err := getNumbers(markup.Popup.Rect)
+		if err != nil {
+			return nil, err
+		}
+		if len(rect) == 4 {
+			a.Popup = &Popup{Rect: model.PdfRectangle{Llx: rect[0], Lly: rect[1], Urx: rect[2], Ury: rect[3]}}
+			if open, ok := core.TraceToDirectObject(markup.Popup.Open).(*core.PdfObjectBool); ok {
+				a.Popup.Open = bool(*open)
+			}
+		}
+	}
+
+	if entries.ic != nil {
+		if a.InteriorColor, err = getNumbers(*entries.ic); err != nil {
+			return nil, err
+		}
+	}
+	if entries.bs != nil {
+		if bs, ok := core.TraceToDirectObject(*entries.bs).(*core.PdfObjectDictionary); ok {
+			if w, ok := getNumber(bs.Get("W")); ok {
+				a.BorderWidth = &w
+			}
+		}
+	}
+	if entries.name != nil {
+		a.Icon = getName(*entries.name)
+	}
+	for _, entry := range []struct {
+		obj    *core.PdfObject
+		values *[]float64
+	}{{entries.quadPoints, &a.QuadPoints}, {entries.l, &a.Line}, {entries.vertices, &a.Vertices}} {
+		if entry.obj != nil {
+			if *entry.values, err = getNumbers(*entry.obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if entries.le != nil {
+		if arr, ok := core.TraceToDirectObject(*entries.le).(*core.PdfObjectArray); ok {
+			for _, obj := range *arr {
+				a.LineEndings = append(a.LineEndings, getName(obj))
+			}
+		}
+	}
+	if entries.inkList != nil {
+		if arr, ok := core.TraceToDirectObject(*entries.inkList).(*core.PdfObjectArray); ok {
+			for _, obj := range *arr {
+				path, err := getNumbers(obj)
+				if err != nil {
+					return nil, err
+				}
+				a.InkList = append(a.InkList, path)
+			}
+		}
+	}
+	if entries.da != nil {
+		a.DefaultAppearance = getText(*entries.da)
+		a.DefaultStyle = getText(*entries.ds)
+		if q, ok := core.TraceToDirectObject(*entries.q).(*core.PdfObjectInteger); ok {
+			a.Justification = int(*q)
+		}
+	}
+	if entries.sy != nil {
+		a.Symbol = getName(*entries.sy)
+	}
+	return a, nil
+}
+
+// AddAnnotations adds the annotations to the pages, by index, with their pop-up windows and the references of the
+// replies to the annotations they reply to.  The annotations have no appearance streams: Acrobat and most viewers
+// draw markup annotations from their entries, or they can be created with the annotator package.
+func (data *Data) AddAnnotations(pages []*model.PdfPage) error {
+	annotations := map[string]*model.PdfAnnotation{}
+	replies := map[*model.PdfAnnotationMarkup]string{}
+	for i := range data.Annotations {
+		a := &data.Annotations[i]
+		if a.Page < 0 || a.Page >= len(pages) {
+			common.Log.Debug("ERROR: Annotation page %d out of range", a.Page)
+			return errors.New("Annotation page out of range")
+		}
+		annot, entries, err := a.toPdfAnnotation()
+		if err != nil {
+			return err
+		}
+
+		page := pages[a.Page]
+		annot.P = page.GetPageAsIndirectObject()
+		page.Annotations = append(page.Annotations, annot)
+		if a.Popup != nil {
+			popup := model.NewPdfAnnotationPopup()
+			popup.Rect = a.Popup.Rect.ToPdfObject()
+			popup.Open = core.MakeBool(a.Popup.Open)
+			popup.Parent = annot.GetContainingPdfObject()
+			popup.P = annot.P
+			entries.markup.Popup = popup
+			page.Annotations = append(page.Annotations, popup.PdfAnnotation)
+		}
+
+		if a.Name != "" {
+			annotations[a.Name] = annot
+		}
+		if a.InReplyTo != "" {
+			replies[entries.markup] = a.InReplyTo
+		}
+	}
+
+	for markup, name := range replies {
+		annot, found := annotations[name]
+		if !found {
+			common.Log.Debug("ERROR: Reply to a missing annotation %q", name)
+			return errors.New("Reply to a missing annotation")
+		}
+		markup.IRT = annot.GetContainingPdfObject()
+	}
+	return nil
+}
+
+// toPdfAnnotation returns the PDF annotation of the annotation, with its entries.
+func (a *Annotation) toPdfAnnotation() (*model.PdfAnnotation, *annotationEntries, error) {
+	annot := newPdfAnnotation(a.Type)
+	if annot == nil {
+		common.Log.Debug("ERROR: Unsupported annotation type %s", a.Type)
+		return nil, nil, errors.New("Unsupported annotation type")
+	}
+	entries := getAnnotationEntries(annot)
+	for _, color := range [][]float64{a.Color, a.InteriorColor} {
+		if n := len(color); n != 0 && n != 1 && n != 3 && n != 4 {
+			common.Log.Debug("ERROR: Invalid annotation color %v", color)
+			return nil, nil, errors.New("Invalid annotation color")
+		}
+	}
+
+	annot.Rect = a.Rect.ToPdfObject()
+	if a.Name != "" {
+		annot.NM = core.MakeTextString(a.Name)
+	}
+	if a.Contents != "" {
+		annot.Contents = core.MakeTextString(a.Contents)
+	}
+	if a.Modified != "" {
+		annot.M = core.MakeString(a.Modified)
+	}
+	if a.Flags != 0 {
+		annot.F = core.MakeInteger(int64(a.Flags))
+	}
+	if len(a.Color) > 0 {
+		annot.C = core.MakeArrayFromFloats(a.Color)
+	}
+
+	markup := entries.markup
+	if a.Author != "" {
+		markup.T = core.MakeTextString(a.Author)
+	}
+	if a.Subject != "" {
+		markup.Subj = core.MakeTextString(a.Subject)
+	}
+	if a.RichContents != "" {
+		markup.RC = core.MakeTextString(a.RichContents)
+	}
+	if a.Created != "" {
+		markup.CreationDate = core.MakeString(a.Created)
+	}
+	if a.ReplyType == "Group" {
+		markup.RT = core.MakeName(a.ReplyType)
+	}
+	if a.Opacity > 0 && a.Opacity < 1 {
+		markup.CA = core.MakeFloat(a.Opacity)
+	}
+
+	if entries.ic != nil && len(a.InteriorColor) > 0 {
+		*entries.ic = core.MakeArrayFromFloats(a.InteriorColor)
+	}
+	if entries.bs != nil && a.BorderWidth != nil {
+		bs := model.NewBorderStyle()
+		bs.SetBorderWidth(*a.BorderWidth)
+		*entries.bs = bs.ToPdfObject()
+	}
+	if entries.name != nil && a.Icon != "" {
+		*entries.name = core.MakeName(a.Icon)
+	}
+	if entries.quadPoints != nil {
+		if len(a.QuadPoints)%8 != 0 || len(a.QuadPoints) == 0 && a.Type != "Redact" {
+			common.Log.Debug("ERROR: Invalid annotation quadrilaterals %v", a.QuadPoints)
+			return nil, nil, errors.New("Invalid annotation quadrilaterals")
+		}
+		if len(a.QuadPoints) > 0 {
+			*entries.quadPoints = core.MakeArrayFromFloats(a.QuadPoints)
+		}
+	}
+	if entries.l != nil {
+		if len(a.Line) != 4 {
+			common.Log.Debug("ERROR: Invalid line %v", a.Line)
+			return nil, nil, errors.New("Invalid line")
+		}
+		*entries.l = core.MakeArrayFromFloats(a.Line)
+	}
+	if entries.le != nil && len(a.LineEndings) > 0 {
+		arr := core.MakeArray()
+		for _, name := range a.LineEndings {
+			arr.Append(core.MakeName(name))
+		}
+		*entries.le = arr
+	}
+	if entries.vertices != nil {
+		if len(a.Vertices) < 4 || len(a.Vertices)%2 != 0 {
+			common.Log.Debug("ERROR: Invalid vertices %v", a.Vertices)
+			return nil, nil, errors.New("Invalid vertices")
+		}
+		*entries.vertices = core.MakeArrayFromFloats(a.Vertices)
+	}
+	if entries.inkList != nil {
+		if len(a.InkList) == 0 {
+			common.Log.Debug("ERROR: Ink annotation without paths")
+			return nil, nil, errors.New("Invalid ink list")
+		}
+		inkList := core.MakeArray()
+		for _, path := range a.InkList {
+			if len(path) == 0 || len(path)%2 != 0 {
+				common.Log.Debug("ERROR: Invalid ink path %v", path)
+				return nil, nil, errors.New("Invalid ink list")
+			}
+			inkList.Append(core.MakeArrayFromFloats(path))
+		}
+		*entries.inkList = inkList
+	}
+	if entries.da != nil {
+		if a.DefaultAppearance != "" {
+			*entries.da = core.MakeString(a.DefaultAppearance)
+		}
+		if a.DefaultStyle != "" {
+			*entries.ds = core.MakeTextString(a.DefaultStyle)
+		}
+		if a.Justification != 0 {
+			*entries.q = core.MakeInteger(int64(a.Justification))
+		}
+	}
+	if entries.sy != nil && a.Symbol != "" {
+		*entries.sy = core.MakeName(a.Symbol)
+	}
+	return annot, entries, nil
+}
+
+// getNumbers returns the numbers of an array, or nil if the object is not an array.
+func getNumbers(obj core.PdfObject) ([]float64, error) {
+	arr, ok := core.TraceToDirectObject(obj).(*core.PdfObjectArray)
+	if !ok {
+		return nil, nil
+	}
+	return arr.GetAsFloat64Slice()
+}
+
+// getNumber returns the value of a number object.
+func getNumber(obj core.PdfObject) (float64, bool) {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectInteger:
+		return float64(*t), true
+	case *core.PdfObjectFloat:
+		return float64(*t), true
+	}
+	return 0, false
+}
+
+// getText returns the text of a text string, or "" if the object is not a string.
+func getText(obj core.PdfObject) string {
+	if str, ok := core.TraceToDirectObject(obj).(*core.PdfObjectString); ok {
+		return str.Decoded()
+	}
+	return ""
+}
+
+// getName returns the name of a name object, or "" if the object is not a name.
+func getName(obj core.PdfObject) string {
+	if name, ok := core.TraceToDirectObject(obj).(*core.PdfObjectName); ok {
+		return string(*name)
+	}
+	return ""
+}
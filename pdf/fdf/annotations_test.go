@@ -0,0 +1,169 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fdf
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Acrobat style review comments: a highlight with its pop-up window and a reply, an ink drawing, an arrow, a free
+// text and a file attachment (not supported).
+const testXFDFAnnots = `<?xml version="1.0" encoding="UTF-8"?>
+<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">
+  <annots>
+    <highlight page="0" rect="98,698,202,714" name="h1" title="Alice" subject="Highlight" color="#FFFF00"
+        date="D:20180423144817+02'00'" creationdate="D:20180423144800+02'00'" flags="print" opacity="0.5"
+        coords="100,712,200,712,100,700,200,700">
+      <contents>Check this</contents>
+      <contents-richtext><body xmlns="http://www.w3.org/1999/xhtml"><p>Check <b>this</b></p></body></contents-richtext>
+      <popup page="0" rect="300,600,500,700" open="yes"/>
+    </highlight>
+    <text page="0" rect="100,720,120,740" name="r1" inreplyto="h1" title="Bob" icon="Comment" flags="print">
+      <contents>Done</contents>
+    </text>
+    <ink page="0" rect="9,9,41,41" name="i1" color="#0000FF" width="2">
+      <inklist>
+        <gesture>10,10;20,30</gesture>
+        <gesture>30,30;40,40</gesture>
+      </inklist>
+    </ink>
+    <line page="0" rect="5,95,115,105" name="l1" color="#FF0000" start="10,100" end="110,100" head="None"
+        tail="OpenArrow"/>
+    <freetext page="0" rect="100,500,250,560" name="f1" justification="centered" width="1">
+      <contents>Note</contents>
+      <defaultappearance>/Helv 12 Tf 0 g</defaultappearance>
+    </freetext>
+    <fileattachment page="0" rect="10,10,30,30" name="a1"/>
+  </annots>
+</xfdf>
+`
+
+func TestXFDFAnnotations(t *testing.T) {
+	data, err := ParseXFDF(strings.NewReader(testXFDFAnnots))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(data.Annotations) != 5 {
+		t.Fatalf("Wrong number of annotations %d", len(data.Annotations))
+	}
+	width := 2.0
+	ink := Annotation{Type: "Ink", Rect: model.PdfRectangle{Llx: 9, Lly: 9, Urx: 41, Ury: 41}, Name: "i1",
+		Color: []float64{0, 0, 1}, BorderWidth: &width, InkList: [][]float64{{10, 10, 20, 30}, {30, 30, 40, 40}}}
+	if !reflect.DeepEqual(data.Annotations[2], ink) {
+		t.Errorf("Wrong ink annotation %+v", data.Annotations[2])
+	}
+	highlight := data.Annotations[0]
+	if highlight.Flags != 4 || highlight.Opacity != 0.5 || len(highlight.QuadPoints) != 8 ||
+		highlight.Popup == nil || !highlight.Popup.Open ||
+		highlight.RichContents != `<body xmlns="http://www.w3.org/1999/xhtml"><p>Check <b>this</b></p></body>` {
+		t.Errorf("Wrong highlight %+v", highlight)
+	}
+	if reply := data.Annotations[1]; reply.InReplyTo != "h1" || reply.Icon != "Comment" {
+		t.Errorf("Wrong reply %+v", reply)
+	}
+	if line := data.Annotations[3]; !reflect.DeepEqual(line.Line, []float64{10, 100, 110, 100}) ||
+		!reflect.DeepEqual(line.LineEndings, []string{"None", "OpenArrow"}) {
+		t.Errorf("Wrong line %+v", line)
+	}
+	if text := data.Annotations[4]; text.Justification != 1 || text.DefaultAppearance != "/Helv 12 Tf 0 g" {
+		t.Errorf("Wrong free text %+v", text)
+	}
+
+	// Applied to a blank page, and exported again.
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	page.Resources = model.NewPdfPageResources()
+	if err = data.AddAnnotations([]*model.PdfPage{page}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w := model.NewPdfWriter()
+	if err = w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err = reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The annotations and the popup.
+	if len(page.Annotations) != 6 {
+		t.Fatalf("Wrong number of page annotations %d", len(page.Annotations))
+	}
+	text, ok := page.Annotations[2].GetContext().(*model.PdfAnnotationText)
+	if !ok || text.IRT == nil {
+		t.Fatalf("Missing reply %v", page.Annotations[2])
+	}
+	if irt, ok := core.TraceToDirectObject(text.IRT).(*core.PdfObjectDictionary); !ok ||
+		getText(irt.Get("NM")) != "h1" {
+		t.Errorf("Wrong reply to %v", text.IRT)
+	}
+
+	exported, err := NewDataFromAnnotations(reader.PageList)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !reflect.DeepEqual(exported.Annotations, data.Annotations) {
+		t.Errorf("Wrong exported annotations %+v, expected %+v", exported.Annotations, data.Annotations)
+	}
+
+	buf.Reset()
+	if err = exported.WriteXFDF(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<gesture>10,10;20,30</gesture>`) {
+		t.Errorf("Wrong XFDF %s", buf.String())
+	}
+	parsed, err := ParseXFDF(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Annotations, data.Annotations) {
+		t.Errorf("Wrong parsed annotations %+v, expected %+v", parsed.Annotations, data.Annotations)
+	}
+}
+
+func TestAnnotationNames(t *testing.T) {
+	page := model.NewPdfPage()
+	for _, name := range []string{"", "annot1", ""} {
+		text := model.NewPdfAnnotationText()
+		text.Rect = core.MakeArrayFromFloats([]float64{0, 0, 10, 10})
+		if name != "" {
+			text.NM = core.MakeTextString(name)
+		}
+		page.Annotations = append(page.Annotations, text.PdfAnnotation)
+	}
+	page.Annotations = append(page.Annotations, model.NewPdfAnnotationLink().PdfAnnotation)
+
+	data, err := NewDataFromAnnotations([]*model.PdfPage{page})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	names := []string{}
+	for _, a := range data.Annotations {
+		names = append(names, a.Name)
+	}
+	if strings.Join(names, " ") != "annot2 annot1 annot3" {
+		t.Errorf("Wrong names %v", names)
+	}
+
+	data.Annotations[0].InReplyTo = "missing"
+	if err = data.AddAnnotations([]*model.PdfPage{model.NewPdfPage()}); err == nil {
+		t.Errorf("Missing error for a reply to a missing annotation")
+	}
+}
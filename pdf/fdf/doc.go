@@ -0,0 +1,30 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package fdf imports and exports the values of interactive form fields in the Forms Data Format (FDF) and its XML
+// variant (XFDF), to exchange form data with Acrobat and other tools, and the markup annotations of documents in
+// XFDF, e.g. review comments.
+//
+// Example: filling a form with the values of an XFDF file.
+//
+//	data, err := fdf.ParseXFDF(f)
+//	...
+//	err = data.Fill(pdfReader)
+//	...
+//	// Write the pages and the form of pdfReader with a PdfWriter, or fill an incremental update with
+//	// data.Fill(tx) on a transaction started with pdfReader.Begin().
+//
+// Example: exporting the review comments of a document and applying them to another copy.
+//
+//	data, err := fdf.NewDataFromAnnotations(pdfReader.PageList)
+//	...
+//	err = data.WriteXFDF(w)
+//	...
+//	data, err = fdf.ParseXFDF(r)
+//	...
+//	err = data.AddAnnotations(otherReader.PageList)
+//	...
+//	// Write the pages of otherReader with a PdfWriter.
+package fdf
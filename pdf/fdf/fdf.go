@@ -0,0 +1,256 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Field is the value of a form field.
+type Field struct {
+	// The fully qualified name of the field, i.e. the partial names separated by periods.
+	Name string
+
+	// The value: the text of text and choice fields, or the appearance state of a button, e.g. Yes or Off.
+	Value string
+
+	// The value is a button state, exported as a name.
+	Button bool
+}
+
+// Data is the form data of a FDF or XFDF file, and the annotations of a XFDF file.
+type Data struct {
+	// The file of the form, if specified.
+	File string
+
+	Fields []Field
+
+	// The markup annotations, exchanged in XFDF only.
+	Annotations []Annotation
+}
+
+// FieldValueSetter sets the value of form fields, e.g. *model.PdfReader or *model.PdfTransaction.
+type FieldValueSetter interface {
+	SetFieldValue(name, value string) error
+}
+
+// NewDataFromForm returns the values of the terminal fields of the form which have a value.
+func NewDataFromForm(form *model.PdfAcroForm) *Data {
+	data := &Data{}
+	if form == nil {
+		return data
+	}
+	for _, field := range form.AllFields() {
+		switch v := core.TraceToDirectObject(field.V).(type) {
+		case *core.PdfObjectString:
+			data.Fields = append(data.Fields, Field{Name: field.GetFullName(), Value: v.Decoded()})
+		case *core.PdfObjectName:
+			data.Fields = append(data.Fields, Field{Name: field.GetFullName(), Value: string(*v), Button: true})
+		}
+	}
+	return data
+}
+
+// Fill sets the values of the fields, e.g. of the form of a PdfReader.  Stops at the first field which cannot be
+// set, e.g. missing from the form.
+func (data *Data) Fill(setter FieldValueSetter) error {
+	for _, field := range data.Fields {
+		if err := setter.SetFieldValue(field.Name, field.Value); err != nil {
+			common.Log.Debug("ERROR: Unable to set field %q: %v", field.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Regular expression matching the header of an indirect object.
+var reFDFObject = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj`)
+
+// ParseFDF parses a FDF file.  Field values given as arrays, e.g. the selected options of list boxes, are imported
+// as their first element.
+func ParseFDF(r io.Reader) (*Data, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(b, " \t\r\n"), []byte("%FDF-")) {
+		return nil, errors.New("Not a FDF file")
+	}
+
+	// The objects, read sequentially as FDF files usually have no cross reference table.
+	objects := map[int64]core.PdfObject{}
+	for _, loc := range reFDFObject.FindAllIndex(b, -1) {
+		obj, err := core.NewParserFromString(string(b[loc[0]:])).ParseIndirectObject()
+		if err != nil {
+			common.Log.Debug("Unable to parse FDF object at %d: %v", loc[0], err)
+			continue
+		}
+		if ind, ok := obj.(*core.PdfIndirectObject); ok {
+			objects[ind.ObjectNumber] = ind.PdfObject
+		}
+	}
+	resolve := func(obj core.PdfObject) core.PdfObject {
+		for i := 0; i < 10; i++ {
+			ref, ok := obj.(*core.PdfObjectReference)
+			if !ok {
+				break
+			}
+			obj = objects[ref.ObjectNumber]
+		}
+		return core.TraceToDirectObject(obj)
+	}
+
+	pos := bytes.LastIndex(b, []byte("trailer"))
+	if pos < 0 {
+		return nil, errors.New("Missing FDF trailer")
+	}
+	trailer, err := core.NewParserFromString(string(bytes.TrimLeft(b[pos+len("trailer"):], " \t\r\n"))).ParseDict()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := resolve(trailer.Get("Root")).(*core.PdfObjectDictionary)
+	if !ok {
+		return nil, errors.New("Missing FDF catalog")
+	}
+	fdf, ok := resolve(root.Get("FDF")).(*core.PdfObjectDictionary)
+	if !ok {
+		return nil, errors.New("Missing FDF dictionary")
+	}
+
+	data := &Data{}
+	switch f := resolve(fdf.Get("F")).(type) {
+	case *core.PdfObjectString:
+		data.File = f.Decoded()
+	case *core.PdfObjectDictionary:
+		for _, key := range []core.PdfObjectName{"UF", "F"} {
+			if s, ok := resolve(f.Get(key)).(*core.PdfObjectString); ok {
+				data.File = s.Decoded()
+				break
+			}
+		}
+	}
+	if fields, ok := resolve(fdf.Get("Fields")).(*core.PdfObjectArray); ok {
+		data.addFDFFields(fields, "", resolve, 0)
+	}
+	return data, nil
+}
+
+// addFDFFields adds the values of the fields of the array and of their kids, descendants of the field with the
+// name parentName.
+func (data *Data) addFDFFields(fields *core.PdfObjectArray, parentName string,
+	resolve func(obj core.PdfObject) core.PdfObject, depth int) {
+	if depth > 32 {
+		return
+	}
+	for _, obj := range *fields {
+		dict, ok := resolve(obj).(*core.PdfObjectDictionary)
+		if !ok {
+			continue
+		}
+		name := parentName
+		if t, ok := resolve(dict.Get("T")).(*core.PdfObjectString); ok {
+			if name != "" {
+				name += "."
+			}
+			name += t.Decoded()
+		}
+
+		v := resolve(dict.Get("V"))
+		if arr, ok := v.(*core.PdfObjectArray); ok && len(*arr) > 0 {
+			v = resolve((*arr)[0])
+		}
+		switch t := v.(type) {
+		case *core.PdfObjectString:
+			data.Fields = append(data.Fields, Field{Name: name, Value: t.Decoded()})
+		case *core.PdfObjectName:
+			data.Fields = append(data.Fields, Field{Name: name, Value: string(*t), Button: true})
+		}
+
+		if kids, ok := resolve(dict.Get("Kids")).(*core.PdfObjectArray); ok {
+			data.addFDFFields(kids, name, resolve, depth+1)
+		}
+	}
+}
+
+// fieldNode is a field of the hierarchy of the exported fields.
+type fieldNode struct {
+	name  string
+	field *Field
+	kids  []*fieldNode
+}
+
+// fieldTree returns the hierarchy of the fields, from their fully qualified names.
+func (data *Data) fieldTree() []*fieldNode {
+	root := &fieldNode{}
+	for i := range data.Fields {
+		node := root
+		for _, part := range strings.Split(data.Fields[i].Name, ".") {
+			var kid *fieldNode
+			for _, k := range node.kids {
+				if k.name == part {
+					kid = k
+					break
+				}
+			}
+			if kid == nil {
+				kid = &fieldNode{name: part}
+				node.kids = append(node.kids, kid)
+			}
+			node = kid
+		}
+		node.field = &data.Fields[i]
+	}
+	return root.kids
+}
+
+// WriteFDF writes the data as a FDF file, the fields in their hierarchy.
+func (data *Data) WriteFDF(w io.Writer) error {
+	var makeFields func(nodes []*fieldNode) *core.PdfObjectArray
+	makeFields = func(nodes []*fieldNode) *core.PdfObjectArray {
+		arr := core.MakeArray()
+		for _, node := range nodes {
+			dict := core.MakeDict()
+			dict.Set("T", core.MakeTextString(node.name))
+			if node.field != nil {
+				if node.field.Button {
+					dict.Set("V", core.MakeName(node.field.Value))
+				} else {
+					dict.Set("V", core.MakeTextString(node.field.Value))
+				}
+			}
+			if len(node.kids) > 0 {
+				dict.Set("Kids", makeFields(node.kids))
+			}
+			arr.Append(dict)
+		}
+		return arr
+	}
+
+	fdf := core.MakeDict()
+	if data.File != "" {
+		fdf.Set("F", core.MakeTextString(data.File))
+	}
+	fdf.Set("Fields", makeFields(data.fieldTree()))
+	root := core.MakeDict()
+	root.Set("FDF", fdf)
+
+	var buf bytes.Buffer
+	buf.WriteString("%FDF-1.2\n%\xe2\xe3\xcf\xd3\n")
+	fmt.Fprintf(&buf, "1 0 obj\n%s\nendobj\n", root.DefaultWriteString())
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
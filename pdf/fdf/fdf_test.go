@@ -0,0 +1,174 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fdf
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// An Acrobat style FDF file, with a hierarchy of fields, a file specification and a list box selection.
+const testFDF = `%FDF-1.2
+%âãÏÓ
+1 0 obj
+<< /FDF << /F << /Type /Filespec /F (form.pdf) /UF (form.pdf) >> /Fields [
+  << /T (address) /Kids [<< /T (city) /V (Paris) >> << /T (street) /V 2 0 R >>] >>
+  << /T (agree) /V /Yes >>
+  << /T (colors) /V [(red) (green)] >>
+  << /T (name) /V (\376\377\000J\000o\000s\000\351) >>
+] >> >>
+endobj
+2 0 obj
+(1 rue de Rivoli)
+endobj
+trailer
+<< /Root 1 0 R >>
+%%EOF
+`
+
+// The data of testFDF.
+var testData = &Data{
+	File: "form.pdf",
+	Fields: []Field{
+		{Name: "address.city", Value: "Paris"},
+		{Name: "address.street", Value: "1 rue de Rivoli"},
+		{Name: "agree", Value: "Yes", Button: true},
+		{Name: "colors", Value: "red"},
+		{Name: "name", Value: "José"},
+	},
+}
+
+func TestParseFDF(t *testing.T) {
+	data, err := ParseFDF(strings.NewReader(testFDF))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if fmt.Sprint(data) != fmt.Sprint(testData) {
+		t.Errorf("Wrong data %v, expected %v", data, testData)
+	}
+
+	if _, err = ParseFDF(strings.NewReader("%PDF-1.4\n")); err == nil {
+		t.Errorf("Missing error for a PDF file")
+	}
+}
+
+func TestWriteFDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testData.WriteFDF(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The fields in their hierarchy.
+	if !strings.Contains(buf.String(), "/T (address)/Kids [") || !strings.Contains(buf.String(), "/V /Yes") {
+		t.Errorf("Wrong FDF %q", buf.String())
+	}
+	data, err := ParseFDF(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if fmt.Sprint(data) != fmt.Sprint(testData) {
+		t.Errorf("Wrong data %v, expected %v", data, testData)
+	}
+}
+
+func TestXFDF(t *testing.T) {
+	xfdf := `<?xml version="1.0" encoding="UTF-8"?>
+<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">
+  <f href="form.pdf"/>
+  <fields>
+    <field name="address">
+      <field name="city"><value>Paris</value></field>
+      <field name="street"><value>1 rue de Rivoli</value></field>
+    </field>
+    <field name="agree"><value>Yes</value></field>
+    <field name="colors"><value>red</value><value>green</value></field>
+    <field name="name"><value>José</value></field>
+  </fields>
+</xfdf>
+`
+	data, err := ParseXFDF(strings.NewReader(xfdf))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// Button states are not distinguished in XFDF.
+	expected := *testData
+	expected.Fields = append([]Field{}, testData.Fields...)
+	expected.Fields[2].Button = false
+	if fmt.Sprint(data) != fmt.Sprint(&expected) {
+		t.Errorf("Wrong data %v, expected %v", data, &expected)
+	}
+
+	var buf bytes.Buffer
+	if err = data.WriteXFDF(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">`) {
+		t.Errorf("Wrong XFDF %q", buf.String())
+	}
+	data, err = ParseXFDF(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if fmt.Sprint(data) != fmt.Sprint(&expected) {
+		t.Errorf("Wrong data %v, expected %v", data, &expected)
+	}
+}
+
+func TestFillForm(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 300, Ury: 300}
+	page.Resources = model.NewPdfPageResources()
+	form := model.NewPdfAcroForm()
+	_, err := form.AddTextField(page, "name", model.PdfRectangle{Llx: 10, Lly: 250, Urx: 210, Ury: 270},
+		model.TextFieldOptions{})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, err = form.AddCheckboxField(page, "agree", model.PdfRectangle{Llx: 10, Lly: 220, Urx: 22, Ury: 232},
+		model.CheckboxFieldOptions{})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	f, err := ioutil.TempFile("", "fdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	w := model.NewPdfWriter()
+	if err = w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = w.SetForms(form); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = w.Write(f); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	data := &Data{Fields: []Field{{Name: "name", Value: "José"}, {Name: "agree", Value: "Yes", Button: true}}}
+	if err = data.Fill(reader); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if exported := NewDataFromForm(reader.AcroForm); fmt.Sprint(exported) != fmt.Sprint(data) {
+		t.Errorf("Wrong form data %v, expected %v", exported, data)
+	}
+
+	data.Fields = append(data.Fields, Field{Name: "missing", Value: "value"})
+	if err = data.Fill(reader); err == nil {
+		t.Errorf("Missing error for a missing field")
+	}
+}
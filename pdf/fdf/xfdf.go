@@ -0,0 +1,485 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fdf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// The XFDF namespace.
+const xfdfNamespace = "http://ns.adobe.com/xfdf/"
+
+// xfdfDocument is the root element of a XFDF file.
+type xfdfDocument struct {
+	XMLName xml.Name    `xml:"xfdf"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Space   string      `xml:"xml:space,attr,omitempty"`
+	File    *xfdfFile   `xml:"f"`
+	Fields  []xfdfField `xml:"fields>field"`
+	Annots  *xfdfAnnots `xml:"annots"`
+}
+
+// xfdfFile is the element specifying the file of the form.
+type xfdfFile struct {
+	Href string `xml:"href,attr"`
+}
+
+// xfdfField is a field element, with its values and kids.
+type xfdfField struct {
+	Name   string      `xml:"name,attr"`
+	Values []string    `xml:"value"`
+	Kids   []xfdfField `xml:"field"`
+}
+
+// xfdfAnnots is the element of the annotations.
+type xfdfAnnots struct {
+	Annotations []xfdfAnnotation `xml:",any"`
+}
+
+// xfdfAnnotation is an annotation element, named after the annotation subtype in lowercase, e.g. highlight.
+type xfdfAnnotation struct {
+	XMLName       xml.Name
+	Page          int    `xml:"page,attr"`
+	Rect          string `xml:"rect,attr"`
+	Name          string `xml:"name,attr,omitempty"`
+	InReplyTo     string `xml:"inreplyto,attr,omitempty"`
+	ReplyType     string `xml:"replyType,attr,omitempty"`
+	Title         string `xml:"title,attr,omitempty"`
+	Subject       string `xml:"subject,attr,omitempty"`
+	Date          string `xml:"date,attr,omitempty"`
+	CreationDate  string `xml:"creationdate,attr,omitempty"`
+	Flags         string `xml:"flags,attr,omitempty"`
+	Color         string `xml:"color,attr,omitempty"`
+	InteriorColor string `xml:"interior-color,attr,omitempty"`
+	Opacity       string `xml:"opacity,attr,omitempty"`
+	Width         string `xml:"width,attr,omitempty"`
+	Icon          string `xml:"icon,attr,omitempty"`
+	Coords        string `xml:"coords,attr,omitempty"`
+	Start         string `xml:"start,attr,omitempty"`
+	End           string `xml:"end,attr,omitempty"`
+	Head          string `xml:"head,attr,omitempty"`
+	Tail          string `xml:"tail,attr,omitempty"`
+	Justification string `xml:"justification,attr,omitempty"`
+	Symbol        string `xml:"symbol,attr,omitempty"`
+
+	Contents          string        `xml:"contents,omitempty"`
+	RichContents      *xfdfRichText `xml:"contents-richtext"`
+	DefaultAppearance string        `xml:"defaultappearance,omitempty"`
+	DefaultStyle      string        `xml:"defaultstyle,omitempty"`
+	Vertices          string        `xml:"vertices,omitempty"`
+	InkList           *xfdfInkList  `xml:"inklist"`
+	Popup             *xfdfPopup    `xml:"popup"`
+}
+
+// xfdfRichText is the rich text of the contents, XHTML elements.
+type xfdfRichText struct {
+	XHTML string `xml:",innerxml"`
+}
+
+// xfdfInkList is the list of the paths of an ink annotation, "x1,y1;x2,y2;...".
+type xfdfInkList struct {
+	Gestures []string `xml:"gesture"`
+}
+
+// xfdfPopup is the element of the pop-up window of an annotation.
+type xfdfPopup struct {
+	Page int    `xml:"page,attr"`
+	Rect string `xml:"rect,attr"`
+	Open string `xml:"open,attr,omitempty"`
+}
+
+// The names of the annotation flags in XFDF, by bit position (from 1).
+var xfdfFlags = []string{"invisible", "hidden", "print", "nozoom", "norotate", "noview", "readonly", "locked",
+	"togglenoview", "lockedcontents"}
+
+// The justifications of free text annotations in XFDF, by value of Q.
+var xfdfJustifications = []string{"left", "centered", "right"}
+
+// The types of the annotations exchanged in XFDF.
+var xfdfAnnotationTypes = []string{"Text", "FreeText", "Line", "Square", "Circle", "Polygon", "PolyLine",
+	"Highlight", "Underline", "Squiggly", "StrikeOut", "Caret", "Stamp", "Ink", "Redact"}
+
+// ParseXFDF parses a XFDF file.  Fields with several values, e.g. the selected options of list boxes, are imported
+// with their first value.  Annotations of types which are not supported, e.g. file attachments, are skipped.
+func ParseXFDF(r io.Reader) (*Data, error) {
+	doc := &xfdfDocument{}
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, err
+	}
+	if doc.XMLName.Local != "xfdf" {
+		return nil, errors.New("Not a XFDF file")
+	}
+
+	data := &Data{}
+	if doc.File != nil {
+		data.File = doc.File.Href
+	}
+	var addFields func(fields []xfdfField, parentName string)
+	addFields = func(fields []xfdfField, parentName string) {
+		for _, field := range fields {
+			name := field.Name
+			if parentName != "" {
+				name = parentName + "." + name
+			}
+			if len(field.Values) > 0 {
+				data.Fields = append(data.Fields, Field{Name: name, Value: field.Values[0]})
+			}
+			addFields(field.Kids, name)
+		}
+	}
+	addFields(doc.Fields, "")
+
+	if doc.Annots != nil {
+		for i := range doc.Annots.Annotations {
+			a, err := doc.Annots.Annotations[i].toAnnotation()
+			if err != nil {
+				return nil, err
+			}
+			if a != nil {
+				data.Annotations = append(data.Annotations, *a)
+			}
+		}
+	}
+	return data, nil
+}
+
+// WriteXFDF writes the data as a XFDF file, the fields in their hierarchy, followed by the annotations.
+func (data *Data) WriteXFDF(w io.Writer) error {
+	var makeFields func(nodes []*fieldNode) []xfdfField
+	makeFields = func(nodes []*fieldNode) []xfdfField {
+		fields := []xfdfField{}
+		for _, node := range nodes {
+			field := xfdfField{Name: node.name}
+			if node.field != nil {
+				field.Values = []string{node.field.Value}
+			}
+			field.Kids = makeFields(node.kids)
+			fields = append(fields, field)
+		}
+		return fields
+	}
+
+	doc := &xfdfDocument{Xmlns: xfdfNamespace, Space: "preserve", Fields: makeFields(data.fieldTree())}
+	if data.File != "" {
+		doc.File = &xfdfFile{Href: data.File}
+	}
+	if len(data.Annotations) > 0 {
+		doc.Annots = &xfdfAnnots{}
+		for i := range data.Annotations {
+			doc.Annots.Annotations = append(doc.Annots.Annotations, newXFDFAnnotation(&data.Annotations[i]))
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// newXFDFAnnotation returns the XFDF element of an annotation.
+func newXFDFAnnotation(a *Annotation) xfdfAnnotation {
+	x := xfdfAnnotation{
+		XMLName:           xml.Name{Local: strings.ToLower(a.Type)},
+		Page:              a.Page,
+		Rect:              formatRect(a.Rect),
+		Name:              a.Name,
+		InReplyTo:         a.InReplyTo,
+		Title:             a.Author,
+		Subject:           a.Subject,
+		Date:              a.Modified,
+		CreationDate:      a.Created,
+		Color:             formatColor(a.Color),
+		InteriorColor:     formatColor(a.InteriorColor),
+		Icon:              a.Icon,
+		Coords:            formatNumbers(a.QuadPoints, ","),
+		Contents:          a.Contents,
+		DefaultAppearance: a.DefaultAppearance,
+		DefaultStyle:      a.DefaultStyle,
+		Vertices:          formatPoints(a.Vertices),
+	}
+	if a.InReplyTo != "" && a.ReplyType == "Group" {
+		x.ReplyType = "group"
+	}
+	flags := []string{}
+	for i, name := range xfdfFlags {
+		if a.Flags&(1<<uint(i)) != 0 {
+			flags = append(flags, name)
+		}
+	}
+	x.Flags = strings.Join(flags, ",")
+	if a.Opacity > 0 && a.Opacity < 1 {
+		x.Opacity = formatNumbers([]float64{a.Opacity}, "")
+	}
+	if a.BorderWidth != nil {
+		x.Width = formatNumbers([]float64{*a.BorderWidth}, "")
+	}
+	if len(a.Line) == 4 {
+		x.Start = formatNumbers(a.Line[:2], ",")
+		x.End = formatNumbers(a.Line[2:], ",")
+	}
+	if len(a.LineEndings) > 0 {
+		x.Head = a.LineEndings[0]
+	}
+	if len(a.LineEndings) > 1 {
+		x.Tail = a.LineEndings[1]
+	}
+	if a.Type == "FreeText" && a.Justification > 0 && a.Justification < len(xfdfJustifications) {
+		x.Justification = xfdfJustifications[a.Justification]
+	}
+	switch a.Symbol {
+	case "P":
+		x.Symbol = "paragraph"
+	case "None":
+		x.Symbol = "none"
+	}
+	// The rich text is written as is, when well-formed.
+	if a.RichContents != "" {
+		if isXML(a.RichContents) {
+			x.RichContents = &xfdfRichText{XHTML: a.RichContents}
+		} else {
+			common.Log.Debug("Rich text of annotation %s not well-formed", a.Name)
+		}
+	}
+	if len(a.InkList) > 0 {
+		x.InkList = &xfdfInkList{}
+		for _, path := range a.InkList {
+			x.InkList.Gestures = append(x.InkList.Gestures, formatPoints(path))
+		}
+	}
+	if a.Popup != nil {
+		x.Popup = &xfdfPopup{Page: a.Page, Rect: formatRect(a.Popup.Rect), Open: "no"}
+		if a.Popup.Open {
+			x.Popup.Open = "yes"
+		}
+	}
+	return x
+}
+
+// toAnnotation returns the annotation of an element, or nil if the annotation type is not supported.
+func (x *xfdfAnnotation) toAnnotation() (*Annotation, error) {
+	a := &Annotation{
+		Page:              x.Page,
+		Name:              x.Name,
+		InReplyTo:         x.InReplyTo,
+		Author:            x.Title,
+		Subject:           x.Subject,
+		Modified:          x.Date,
+		Created:           x.CreationDate,
+		Icon:              x.Icon,
+		Contents:          x.Contents,
+		DefaultAppearance: x.DefaultAppearance,
+		DefaultStyle:      x.DefaultStyle,
+	}
+	for _, subtype := range xfdfAnnotationTypes {
+		if strings.EqualFold(subtype, x.XMLName.Local) {
+			a.Type = subtype
+		}
+	}
+	if a.Type == "" {
+		common.Log.Debug("Skipping unsupported XFDF annotation %s", x.XMLName.Local)
+		return nil, nil
+	}
+
+	var err error
+	if a.Rect, err = parseRect(x.Rect); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(x.ReplyType, "group") {
+		a.ReplyType = "Group"
+	}
+	for _, name := range strings.Split(x.Flags, ",") {
+		for i, flag := range xfdfFlags {
+			if strings.EqualFold(strings.TrimSpace(name), flag) {
+				a.Flags |= 1 << uint(i)
+			}
+		}
+	}
+	if a.Color, err = parseColor(x.Color); err != nil {
+		return nil, err
+	}
+	if a.InteriorColor, err = parseColor(x.InteriorColor); err != nil {
+		return nil, err
+	}
+	if x.Opacity != "" {
+		if a.Opacity, err = strconv.ParseFloat(x.Opacity, 64); err != nil {
+			return nil, err
+		}
+	}
+	if x.Width != "" {
+		width, err := strconv.ParseFloat(x.Width, 64)
+		if err != nil {
+			return nil, err
+		}
+		a.BorderWidth = &width
+	}
+	if a.QuadPoints, err = parseNumbers(x.Coords); err != nil {
+		return nil, err
+	}
+	if x.Start != "" || x.End != "" {
+		if a.Line, err = parseNumbers(x.Start + "," + x.End); err != nil {
+			return nil, err
+		}
+	}
+	if x.Head != "" || x.Tail != "" {
+		a.LineEndings = []string{"None", "None"}
+		if x.Head != "" {
+			a.LineEndings[0] = x.Head
+		}
+		if x.Tail != "" {
+			a.LineEndings[1] = x.Tail
+		}
+	}
+	if a.Vertices, err = parseNumbers(x.Vertices); err != nil {
+		return nil, err
+	}
+	if x.InkList != nil {
+		for _, gesture := range x.InkList.Gestures {
+			path, err := parseNumbers(gesture)
+			if err != nil {
+				return nil, err
+			}
+			a.InkList = append(a.InkList, path)
+		}
+	}
+	for i, justification := range xfdfJustifications {
+		if strings.EqualFold(x.Justification, justification) {
+			a.Justification = i
+		}
+	}
+	switch strings.ToLower(x.Symbol) {
+	case "paragraph":
+		a.Symbol = "P"
+	case "none":
+		a.Symbol = "None"
+	}
+	if x.RichContents != nil {
+		a.RichContents = strings.TrimSpace(x.RichContents.XHTML)
+	}
+	if x.Popup != nil {
+		a.Popup = &Popup{Open: strings.EqualFold(x.Popup.Open, "yes")}
+		if a.Popup.Rect, err = parseRect(x.Popup.Rect); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// formatNumbers returns the numbers separated by sep.
+func formatNumbers(values []float64, sep string) string {
+	strs := []string{}
+	for _, v := range values {
+		strs = append(strs, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return strings.Join(strs, sep)
+}
+
+// formatPoints returns the coordinates of points (x1 y1 x2 y2 ...) as "x1,y1;x2,y2;...".
+func formatPoints(values []float64) string {
+	points := []string{}
+	for i := 0; i+1 < len(values); i += 2 {
+		points = append(points, formatNumbers(values[i:i+2], ","))
+	}
+	return strings.Join(points, ";")
+}
+
+// parseNumbers parses numbers separated by commas, semicolons or spaces, nil if none.
+func parseNumbers(s string) ([]float64, error) {
+	var values []float64
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	}) {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// formatRect returns the rectangle as "x1,y1,x2,y2".
+func formatRect(rect model.PdfRectangle) string {
+	return formatNumbers([]float64{rect.Llx, rect.Lly, rect.Urx, rect.Ury}, ",")
+}
+
+// parseRect parses a rectangle "x1,y1,x2,y2".
+func parseRect(s string) (model.PdfRectangle, error) {
+	values, err := parseNumbers(s)
+	if err != nil {
+		return model.PdfRectangle{}, err
+	}
+	if len(values) != 4 {
+		common.Log.Debug("ERROR: Invalid XFDF rectangle %q", s)
+		return model.PdfRectangle{}, errors.New("Invalid rectangle")
+	}
+	return model.PdfRectangle{Llx: values[0], Lly: values[1], Urx: values[2], Ury: values[3]}, nil
+}
+
+// formatColor returns the color of gray, RGB or CMYK components as "#RRGGBB", or "" if none.
+func formatColor(color []float64) string {
+	var r, g, b float64
+	switch len(color) {
+	case 1:
+		r, g, b = color[0], color[0], color[0]
+	case 3:
+		r, g, b = color[0], color[1], color[2]
+	case 4:
+		k := 1 - color[3]
+		r, g, b = (1-color[0])*k, (1-color[1])*k, (1-color[2])*k
+	default:
+		return ""
+	}
+	component := func(v float64) int {
+		if v < 0 {
+			return 0
+		} else if v > 1 {
+			return 255
+		}
+		return int(v*255 + 0.5)
+	}
+	return fmt.Sprintf("#%02X%02X%02X", component(r), component(g), component(b))
+}
+
+// parseColor parses a color "#RRGGBB" as RGB components, nil if empty.
+func parseColor(s string) ([]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	rgb, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 || s[0] != '#' {
+		common.Log.Debug("ERROR: Invalid XFDF color %q", s)
+		return nil, errors.New("Invalid color")
+	}
+	return []float64{float64(rgb>>16) / 255, float64(rgb>>8&0xff) / 255, float64(rgb&0xff) / 255}, nil
+}
+
+// isXML returns true if the string is well-formed XML content.
+func isXML(s string) bool {
+	decoder := xml.NewDecoder(strings.NewReader(s))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
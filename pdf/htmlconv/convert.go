@@ -0,0 +1,864 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package htmlconv
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/creator"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// Options are the options of the conversion.
+type Options struct {
+	// The font size of the body text in points, and its line height relative to the font size.
+	FontSize   float64
+	LineHeight float64
+
+	// The width available for the content in points, to which images and rules are limited.  Draw sets it to the
+	// width of the drawing context of the creator when 0.
+	Width float64
+
+	// The directory the relative paths of images are resolved from.
+	BaseDir string
+}
+
+// DefaultOptions returns the default options: 10 point text with a line height of 1.2, and images in the current
+// directory.
+func DefaultOptions() Options {
+	return Options{
+		FontSize:   10,
+		LineHeight: 1.2,
+		BaseDir:    ".",
+	}
+}
+
+// Elements which are not drawn.
+var skippedElements = map[string]bool{
+	"head": true, "title": true, "script": true, "style": true, "meta": true, "link": true, "noscript": true,
+	"template": true, "iframe": true, "object": true, "embed": true, "input": true, "button": true,
+	"select": true, "textarea": true, "colgroup": true, "col": true,
+}
+
+// Elements laid out as blocks, the others being inline.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "center": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "html": true, "li": true, "main": true, "nav": true, "p": true, "pre": true,
+	"section": true, "caption": true,
+}
+
+// Levels of the heading elements.
+var headingLevels = map[string]int{"h1": 1, "h2": 2, "h3": 3, "h4": 4, "h5": 5, "h6": 6}
+
+// Convert converts the HTML document to creator drawables, to be drawn in order with Creator.Draw.  The
+// elements of the body are mapped to drawables: headings to headings (in the outline when enabled), paragraphs and
+// the other blocks to styled paragraphs, lists to lists, tables to tables and images to images.  Returns an error if the document cannot be parsed or an
+// image cannot be loaded.
+func Convert(data []byte, opts Options) ([]creator.Drawable, error) {
+	if opts.FontSize <= 0 || opts.LineHeight <= 0 || opts.Width < 0 {
+		common.Log.Debug("ERROR: Invalid options %+v", opts)
+		return nil, errors.New("Range check error")
+	}
+
+	root, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if body := root.find("body"); body != nil {
+		root = body
+	}
+
+	s := style{
+		fontSize:   opts.FontSize,
+		color:      creator.ColorBlack,
+		lineHeight: opts.LineHeight,
+	}
+	c := &converter{opts: opts, encoder: textencoding.NewWinAnsiTextEncoder()}
+	box := newContainer(opts.Width, s)
+	box.outline = true
+	err = c.convertChildren(root, s, box)
+	if err != nil {
+		return nil, err
+	}
+	box.flush()
+	return box.drawables, nil
+}
+
+// Draw converts the HTML document and draws it with the creator, from the current position.
+func Draw(c *creator.Creator, data []byte, opts Options) error {
+	if opts.Width == 0 {
+		opts.Width = c.Context().Width
+	}
+	drawables, err := Convert(data, opts)
+	if err != nil {
+		return err
+	}
+	for _, d := range drawables {
+		err := c.Draw(d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// converter converts the elements of an HTML document.
+type converter struct {
+	opts Options
+
+	// The encoder of the text, with which text not encodable is replaced.
+	encoder textencoding.TextEncoder
+}
+
+// marginSetter is a drawable with margins.
+type marginSetter interface {
+	SetMargins(left, right, top, bottom float64)
+}
+
+// container collects the drawables of the blocks in an element: the body, a list item or a table cell.
+type container struct {
+	drawables []creator.Drawable
+
+	// The available width, and the indentation of the blocks in it from the margins of the enclosing blocks.
+	width       float64
+	left, right float64
+
+	// The vertical space before the next block: the collapsed bottom and top margins of the blocks in between.
+	// The top margins of the first blocks are dropped.
+	space   float64
+	started bool
+
+	// The paragraph of the inline content being collected, the style of its block, and whether it ends with
+	// whitespace (collapsed with the following whitespace).
+	para       *creator.StyledParagraph
+	blockStyle style
+	endsSpace  bool
+
+	// Whether the paragraphs of headings are drawn as creator headings, in the outline (in the body), and the level
+	// of the heading being converted, if any.
+	outline      bool
+	headingLevel int
+}
+
+// newContainer returns a container of the width, for the blocks of the style.
+func newContainer(width float64, s style) *container {
+	return &container{width: width, blockStyle: s, endsSpace: true}
+}
+
+// availWidth returns the width available for the blocks, or 0 if unknown.
+func (box *container) availWidth() float64 {
+	if box.width <= 0 {
+		return 0
+	}
+	return math.Max(box.width-box.left-box.right, 1)
+}
+
+// addSpace adds a margin between the blocks, collapsed with the adjacent margins.
+func (box *container) addSpace(space float64) {
+	if box.started {
+		box.space = math.Max(box.space, space)
+	}
+}
+
+// add adds a block drawable, after the space of the margins.
+func (box *container) add(d creator.Drawable) {
+	box.flush()
+	if m, ok := d.(marginSetter); ok {
+		m.SetMargins(box.left, box.right, box.space, 0)
+	}
+	box.drawables = append(box.drawables, d)
+	box.space = 0
+	box.started = true
+}
+
+// flush ends the paragraph of the inline content, if any.
+func (box *container) flush() {
+	p := box.para
+	if p == nil {
+		return
+	}
+	box.para = nil
+	box.endsSpace = true
+
+	chunks := p.Chunks()
+	for len(chunks) > 0 {
+		last := chunks[len(chunks)-1]
+		last.Text = strings.TrimRight(last.Text, " ")
+		if last.Text != "" {
+			break
+		}
+		chunks = chunks[:len(chunks)-1]
+	}
+	if len(chunks) == 0 {
+		// Only whitespace.
+		return
+	}
+	if box.outline && box.headingLevel > 0 {
+		box.add(creator.NewHeading(box.headingLevel, p))
+		return
+	}
+	box.add(p)
+}
+
+// paragraph returns the paragraph of the inline content, started with the style of the block.
+func (box *container) paragraph() *creator.StyledParagraph {
+	if box.para == nil {
+		s := box.blockStyle
+		box.para = creator.NewStyledParagraph("", s.textStyle())
+		box.para.SetTextAlignment(s.align)
+		box.para.SetLineHeight(s.lineHeight)
+	}
+	return box.para
+}
+
+// convertChildren converts the children of the element into the container.
+func (c *converter) convertChildren(n *node, s style, box *container) error {
+	for _, child := range n.children {
+		err := c.convertNode(child, s, box)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertNode converts the node into the container, in the style of its parent.
+func (c *converter) convertNode(n *node, parent style, box *container) error {
+	if n.tag == textNode {
+		c.addText(box, n.text, parent)
+		return nil
+	}
+	if skippedElements[n.tag] {
+		return nil
+	}
+	decls := n.declarations()
+	if decls["display"] == "none" {
+		return nil
+	}
+	s := computeStyle(parent, decls, c.opts.FontSize)
+	if n.tag == "a" {
+		if href := n.attrs["href"]; href != "" && !strings.HasPrefix(href, "#") {
+			s.uri = href
+		}
+	}
+
+	if isPageBreak(decls, "before") {
+		box.add(creator.NewPageBreak())
+	}
+
+	var err error
+	switch {
+	case n.tag == "br":
+		c.appendText(box, "\n", s)
+		box.endsSpace = true
+	case n.tag == "img":
+		err = c.convertImage(n, decls, s, box)
+	case n.tag == "hr":
+		c.convertRule(decls, s, box)
+	case n.tag == "ul" || n.tag == "ol":
+		err = c.convertList(n, decls, s, box)
+	case n.tag == "table":
+		err = c.convertTable(n, decls, s, box)
+	case blockElements[n.tag] || decls["display"] == "block":
+		err = c.convertBlock(n, decls, s, box)
+	default:
+		err = c.convertChildren(n, s, box)
+	}
+	if err != nil {
+		return err
+	}
+
+	if isPageBreak(decls, "after") {
+		box.add(creator.NewPageBreak())
+	}
+	return nil
+}
+
+// isPageBreak returns whether the declarations force a page break before or after the element.
+func isPageBreak(decls map[string]string, side string) bool {
+	return decls["page-break-"+side] == "always" || decls["break-"+side] == "page"
+}
+
+// margins returns the margins of the element: top, right, bottom and left.
+func (c *converter) margins(decls map[string]string, s style, box *container) (float64, float64, float64, float64) {
+	var m [4]float64
+	for i, side := range []string{"top", "right", "bottom", "left"} {
+		if l, ok := parseLength(decls["margin-"+side], box.availWidth(), s.fontSize, c.opts.FontSize); ok {
+			m[i] = l
+		}
+		// Padding is added to the margins, as the boxes are not drawn.
+		if l, ok := parseLength(decls["padding-"+side], box.availWidth(), s.fontSize, c.opts.FontSize); ok {
+			m[i] += l
+		}
+	}
+	return m[0], m[1], m[2], m[3]
+}
+
+// convertBlock converts a block element into the container: its inline content into paragraphs in its style,
+// and its block children, indented by its margins.
+func (c *converter) convertBlock(n *node, decls map[string]string, s style, box *container) error {
+	box.flush()
+	top, right, bottom, left := c.margins(decls, s, box)
+	box.addSpace(top)
+
+	origStyle, origLevel := box.blockStyle, box.headingLevel
+	box.left += left
+	box.right += right
+	box.blockStyle = s
+	if level, isHeading := headingLevels[n.tag]; isHeading {
+		box.headingLevel = level
+	}
+	err := c.convertChildren(n, s, box)
+	box.flush()
+	box.left -= left
+	box.right -= right
+	box.blockStyle, box.headingLevel = origStyle, origLevel
+
+	box.addSpace(bottom)
+	return err
+}
+
+// addText adds the text of a text node to the inline content, with the whitespace collapsed unless preformatted.
+func (c *converter) addText(box *container, text string, s style) {
+	if s.pre {
+		text = strings.Replace(text, "\r\n", "\n", -1)
+		text = strings.Replace(text, "\t", "    ", -1)
+		if box.para == nil {
+			// A newline following the start tag is ignored.
+			text = strings.TrimPrefix(text, "\n")
+		}
+	} else {
+		var b strings.Builder
+		for _, r := range text {
+			if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+				if !box.endsSpace {
+					b.WriteRune(' ')
+					box.endsSpace = true
+				}
+				continue
+			}
+			b.WriteRune(r)
+			box.endsSpace = false
+		}
+		text = b.String()
+	}
+	if text == "" {
+		return
+	}
+	c.appendText(box, text, s)
+}
+
+// appendText appends the text to the inline content, with the characters not in the encoding of the paragraphs
+// replaced.
+func (c *converter) appendText(box *container, text string, s style) {
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return ' '
+		case r == '\u00ad' || r == '\u200b' || r == '\ufeff':
+			return -1
+		case r == '\n':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		}
+		if _, found := c.encoder.RuneToGlyph(r); !found {
+			common.Log.Debug("Character %q not in the encoding, replaced", r)
+			return '?'
+		}
+		return r
+	}, text)
+
+	p := box.paragraph()
+	var chunk *creator.TextChunk
+	if s.uri != "" {
+		chunk = p.AppendLink(text, s.uri)
+	} else {
+		chunk = p.Append(text)
+	}
+	chunk.Style = s.textStyle()
+}
+
+// convertImage converts an image, drawn as a block scaled to its specified size, or to the available width if
+// larger.  Images are loaded from data URIs or from files.
+func (c *converter) convertImage(n *node, decls map[string]string, s style, box *container) error {
+	src := n.attrs["src"]
+	var img *creator.Image
+	var err error
+	switch {
+	case strings.HasPrefix(src, "data:"):
+		comma := strings.Index(src, ",")
+		if comma < 0 || !strings.HasSuffix(src[:comma], ";base64") {
+			common.Log.Debug("ERROR: Unsupported image data URI")
+			return errors.New("Unsupported image data")
+		}
+		data, err := base64.StdEncoding.DecodeString(src[comma+1:])
+		if err != nil {
+			return err
+		}
+		img, err = creator.NewImageFromData(data)
+		if err != nil {
+			return err
+		}
+	case strings.Contains(src, "://"):
+		common.Log.Debug("Remote image %s not loaded", src)
+		return nil
+	default:
+		path := src
+		if strings.HasPrefix(path, "file:") {
+			u, err := url.Parse(path)
+			if err != nil {
+				return err
+			}
+			path = u.Path
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.opts.BaseDir, path)
+		}
+		img, err = creator.NewImageFromFile(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The natural size of images is in pixels.
+	w, h := img.Width()*pointsPerPixel, img.Height()*pointsPerPixel
+	width, hasWidth := parseLength(decls["width"], box.availWidth(), s.fontSize, c.opts.FontSize)
+	height, hasHeight := parseLength(decls["height"], 0, s.fontSize, c.opts.FontSize)
+	switch {
+	case hasWidth && hasHeight:
+		w, h = width, height
+	case hasWidth:
+		w, h = width, h*width/w
+	case hasHeight:
+		w, h = w*height/h, height
+	}
+	if avail := box.availWidth(); avail > 0 && w > avail {
+		w, h = avail, h*avail/w
+	}
+	img.SetWidth(w)
+	img.SetHeight(h)
+
+	box.flush()
+	box.add(img)
+	if s.uri != "" {
+		img.SetLink(s.uri)
+	}
+	return nil
+}
+
+// convertRule converts a horizontal rule, drawn across the available width.
+func (c *converter) convertRule(decls map[string]string, s style, box *container) {
+	top, _, bottom, _ := c.margins(decls, s, box)
+	box.flush()
+	box.addSpace(top)
+	width := box.availWidth()
+	if width == 0 {
+		common.Log.Debug("Rule not drawn without a width")
+		return
+	}
+
+	// The margins are in the block, which has none.
+	lineWidth := 0.75
+	block := creator.NewBlock(box.left+width, box.space+lineWidth)
+	line := creator.NewLine(box.left, box.space+lineWidth/2, box.left+width, box.space+lineWidth/2)
+	line.SetLineWidth(lineWidth)
+	color := creator.ColorRGBFrom8bit(0x80, 0x80, 0x80)
+	if col, ok := parseColor(decls["color"]); ok {
+		color = col
+	}
+	line.SetColor(color)
+	block.Draw(line)
+
+	box.drawables = append(box.drawables, block)
+	box.space = 0
+	box.started = true
+	box.addSpace(bottom)
+}
+
+// convertItems converts the content of a list item or a table cell into its own container.
+func (c *converter) convertItems(n *node, s style, width float64) (*container, error) {
+	box := newContainer(width, s)
+	err := c.convertChildren(n, s, box)
+	if err != nil {
+		return nil, err
+	}
+	box.flush()
+	return box, nil
+}
+
+// List numberings of the list-style-type values and type attributes.
+var listNumberings = map[string]creator.ListNumbering{
+	"decimal": creator.ListDecimal, "1": creator.ListDecimal,
+	"lower-alpha": creator.ListLowerAlpha, "lower-latin": creator.ListLowerAlpha, "a": creator.ListLowerAlpha,
+	"upper-alpha": creator.ListUpperAlpha, "upper-latin": creator.ListUpperAlpha, "A": creator.ListUpperAlpha,
+	"lower-roman": creator.ListLowerRoman, "i": creator.ListLowerRoman,
+	"upper-roman": creator.ListUpperRoman, "I": creator.ListUpperRoman,
+}
+
+// convertList converts an ordered or unordered list.  Items with several blocks are divisions, except the nested
+// lists which are items of their own.
+func (c *converter) convertList(n *node, decls map[string]string, s style, box *container) error {
+	top, right, bottom, left := c.margins(decls, s, box)
+	box.flush()
+	box.addSpace(top)
+
+	list := creator.NewList()
+	markerStyle := s.textStyle()
+	markerStyle.Underline = false
+	markerStyle.Strikeout = false
+	list.SetMarkerStyle(markerStyle)
+	if n.tag == "ol" {
+		list.SetNumbering(creator.ListDecimal)
+		if start, err := strconv.Atoi(n.attrs["start"]); err == nil {
+			list.SetStartNumber(start)
+		}
+	}
+	listType := n.attrs["type"]
+	if t, has := decls["list-style-type"]; has {
+		listType = t
+	}
+	if numbering, ok := listNumberings[listType]; ok {
+		list.SetNumbering(numbering)
+	} else if listType == "none" {
+		list.SetBullet("")
+	}
+
+	width := box.availWidth()
+	if width > 0 {
+		width = math.Max(width-left-right-20, 1)
+	}
+	for _, child := range n.children {
+		if child.tag != "li" {
+			continue
+		}
+		itemDecls := child.declarations()
+		itemStyle := computeStyle(s, itemDecls, c.opts.FontSize)
+		item, err := c.convertItems(child, itemStyle, width)
+		if err != nil {
+			return err
+		}
+
+		var group []creator.VectorDrawable
+		addGroup := func() error {
+			switch len(group) {
+			case 0:
+				return nil
+			case 1:
+				err := list.Add(group[0])
+				group = nil
+				return err
+			}
+			div := creator.NewDivision()
+			for _, d := range group {
+				if err := div.Add(d); err != nil {
+					return err
+				}
+			}
+			group = nil
+			return list.Add(div)
+		}
+		for _, d := range item.drawables {
+			switch t := d.(type) {
+			case *creator.StyledParagraph, *creator.Image:
+				group = append(group, t.(creator.VectorDrawable))
+			case *creator.List, *creator.Table:
+				if err := addGroup(); err != nil {
+					return err
+				}
+				if err := list.Add(t); err != nil {
+					return err
+				}
+			default:
+				common.Log.Debug("Unsupported %T in list item", d)
+			}
+		}
+		if len(item.drawables) == 0 {
+			group = append(group, creator.NewStyledParagraph(" ", itemStyle.textStyle()))
+		}
+		if err := addGroup(); err != nil {
+			return err
+		}
+	}
+
+	box.left += left
+	box.right += right
+	box.add(list)
+	box.left -= left
+	box.right -= right
+	box.addSpace(bottom)
+	return nil
+}
+
+// convertTable converts a table, with the rows of its header repeated on each page, and the borders and the
+// backgrounds of the table and its cells.
+func (c *converter) convertTable(n *node, decls map[string]string, s style, box *container) error {
+	box.flush()
+
+	// The rows, and the number of rows of the header.
+	var rows []*node
+	headerRows := 0
+	for _, child := range n.children {
+		switch child.tag {
+		case "caption":
+			if err := c.convertBlock(child, child.declarations(), computeStyle(s, child.declarations(),
+				c.opts.FontSize), box); err != nil {
+				return err
+			}
+		case "tr":
+			rows = append(rows, child)
+		case "thead", "tbody", "tfoot":
+			for _, row := range child.children {
+				if row.tag != "tr" {
+					continue
+				}
+				if child.tag == "thead" && len(rows) == headerRows {
+					headerRows++
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	// The number of columns and the spans of the cells.
+	cols := 0
+	for _, row := range rows {
+		count := 0
+		for _, cell := range row.children {
+			if cell.tag == "td" || cell.tag == "th" {
+				count += spanAttr(cell, "colspan")
+			}
+		}
+		if count > cols {
+			cols = count
+		}
+	}
+	if cols == 0 {
+		return nil
+	}
+
+	top, right, bottom, left := c.margins(decls, s, box)
+	avail := box.availWidth()
+	if avail > 0 {
+		avail = math.Max(avail-left-right, 1)
+		if w, ok := parseLength(decls["width"], avail, s.fontSize, c.opts.FontSize); ok && w < avail {
+			right += avail - w
+			avail = w
+		}
+	}
+
+	borderWidth, borderColor := c.border(decls, n.attrs["border"], s)
+	tableBackground, hasTableBackground := parseColor(decls["background-color"])
+	indent := 5.0
+	if padding, err := strconv.ParseFloat(n.attrs["cellpadding"], 64); err == nil {
+		indent = padding * pointsPerPixel
+	}
+
+	table := creator.NewTable(cols)
+	c.setColumnWidths(table, rows, cols, avail, s)
+	for _, row := range rows {
+		rowDecls := row.declarations()
+		rowStyle := computeStyle(s, rowDecls, c.opts.FontSize)
+		rowBackground, hasRowBackground := parseColor(rowDecls["background-color"])
+
+		for _, child := range row.children {
+			if child.tag != "td" && child.tag != "th" {
+				continue
+			}
+			cellDecls := child.declarations()
+			cellStyle := computeStyle(rowStyle, cellDecls, c.opts.FontSize)
+			colspan := spanAttr(child, "colspan")
+			cell := table.NewMultiCell(spanAttr(child, "rowspan"), colspan)
+			cell.SetIndent(indent)
+
+			width := 0.0
+			if avail > 0 {
+				width = math.Max(avail*float64(colspan)/float64(cols)-2*indent, 1)
+			}
+			content, err := c.convertItems(child, cellStyle, width)
+			if err != nil {
+				return err
+			}
+			err = setCellContent(cell, content.drawables, cellStyle)
+			if err != nil {
+				return err
+			}
+
+			switch cellDecls["vertical-align"] {
+			case "top":
+				cell.SetVerticalAlignment(creator.CellVerticalAlignmentTop)
+			case "bottom":
+				cell.SetVerticalAlignment(creator.CellVerticalAlignmentBottom)
+			default:
+				cell.SetVerticalAlignment(creator.CellVerticalAlignmentMiddle)
+			}
+			if background, ok := parseColor(cellDecls["background-color"]); ok {
+				cell.SetBackgroundColor(background)
+			} else if hasRowBackground {
+				cell.SetBackgroundColor(rowBackground)
+			} else if hasTableBackground {
+				cell.SetBackgroundColor(tableBackground)
+			}
+			width, color := c.border(cellDecls, "", cellStyle)
+			if width == 0 && borderWidth > 0 {
+				width, color = borderWidth, borderColor
+			}
+			if width > 0 {
+				cell.SetBorder(creator.CellBorderStyleBox, width)
+				cell.SetBorderColor(color)
+			}
+		}
+
+		// The row ends at the last column.
+		if col := table.CurCol(); col < cols {
+			table.SkipCells(cols - col)
+		}
+	}
+	if headerRows > 0 && headerRows < len(rows) {
+		table.SetHeaderRows(1, headerRows)
+	}
+
+	box.addSpace(top)
+	box.left += left
+	box.right += right
+	box.add(table)
+	box.left -= left
+	box.right -= right
+	box.addSpace(bottom)
+	return nil
+}
+
+// spanAttr returns the row or column span of the table cell, 1 by default.
+func spanAttr(n *node, attr string) int {
+	span, err := strconv.Atoi(n.attrs[attr])
+	if err != nil || span < 1 {
+		return 1
+	}
+	return span
+}
+
+// border returns the width and the color of the border of the declarations or of the border attribute of tables
+// (pixels), 0 if none.
+func (c *converter) border(decls map[string]string, attr string, s style) (float64, creator.Color) {
+	width := 0.0
+	color := creator.ColorBlack
+	if border, err := strconv.ParseFloat(attr, 64); err == nil {
+		width = border * pointsPerPixel
+	}
+	if border, has := decls["border"]; has {
+		width = 0
+		for _, part := range strings.Fields(border) {
+			switch part {
+			case "none", "hidden":
+				return 0, color
+			case "thin":
+				width = pointsPerPixel
+			case "medium":
+				width = 3 * pointsPerPixel
+			case "thick":
+				width = 5 * pointsPerPixel
+			}
+			if l, ok := parseLength(part, 0, s.fontSize, c.opts.FontSize); ok {
+				width = l
+			} else if col, ok := parseColor(part); ok {
+				color = col
+			} else if width == 0 && (part == "solid" || part == "dashed" || part == "dotted" || part == "double") {
+				width = pointsPerPixel
+			}
+		}
+	}
+	return width, color
+}
+
+// setColumnWidths sets the widths of the columns specified in the cells of the first row of the table, as
+// percentages (fractions of the table width) or lengths (fixed widths).
+func (c *converter) setColumnWidths(table *creator.Table, rows []*node, cols int, avail float64, s style) {
+	if len(rows) == 0 {
+		return
+	}
+	fractions := make([]float64, cols)
+	fixed := make([]float64, cols)
+	col := 0
+	hasFixed, allFractions := false, true
+	for _, cell := range rows[0].children {
+		if cell.tag != "td" && cell.tag != "th" {
+			continue
+		}
+		span := spanAttr(cell, "colspan")
+		width := cell.declarations()["width"]
+		for i := 0; i < span && col < cols; i++ {
+			if strings.HasSuffix(width, "%") {
+				if f, err := strconv.ParseFloat(strings.TrimSuffix(width, "%"), 64); err == nil {
+					fractions[col] = f / 100 / float64(span)
+				}
+			} else if l, ok := parseLength(width, 0, s.fontSize, c.opts.FontSize); ok {
+				fixed[col] = l / float64(span)
+				hasFixed = true
+			}
+			if fractions[col] == 0 {
+				allFractions = false
+			}
+			col++
+		}
+	}
+
+	if allFractions && col == cols {
+		table.SetColumnWidths(fractions...)
+	} else if hasFixed {
+		table.SetFixedColumnWidths(fixed...)
+	}
+}
+
+// setCellContent sets the content of the table cell to its blocks: a paragraph, an image, or a division of them.
+// Lists and tables are not supported in cells.
+func setCellContent(cell *creator.TableCell, drawables []creator.Drawable, s style) error {
+	var content []creator.VectorDrawable
+	for _, d := range drawables {
+		switch t := d.(type) {
+		case *creator.StyledParagraph:
+			// Wrapped within the cell.
+			t.SetEnableWrap(true)
+			content = append(content, t)
+		case *creator.Image:
+			content = append(content, t)
+		default:
+			common.Log.Debug("Unsupported %T in table cell", d)
+		}
+	}
+
+	switch len(content) {
+	case 0:
+		return nil
+	case 1:
+		if _, isImage := content[0].(*creator.Image); isImage {
+			switch s.align {
+			case creator.TextAlignmentCenter:
+				cell.SetHorizontalAlignment(creator.CellHorizontalAlignmentCenter)
+			case creator.TextAlignmentRight:
+				cell.SetHorizontalAlignment(creator.CellHorizontalAlignmentRight)
+			}
+		}
+		return cell.SetContent(content[0])
+	}
+	div := creator.NewDivision()
+	for _, d := range content {
+		err := div.Add(d)
+		if err != nil {
+			return err
+		}
+	}
+	return cell.SetContent(div)
+}
@@ -0,0 +1,21 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+//
+// Package htmlconv converts HTML documents to creator drawables, e.g. for generating reports from HTML templates.
+//
+// A constrained subset of HTML and CSS is supported: headings, paragraphs and the other block elements, inline
+// formatting (bold, italic, underline, strikeout, sub- and superscripts, fonts, colors and links), line breaks,
+// ordered and unordered lists, tables (with spans, header rows, borders, backgrounds and column widths), images
+// (files or data URIs) and horizontal rules.  The style attributes, the presentational attributes and the default
+// styles of the elements are applied, with lengths in CSS units (px being 0.75 points) and the standard fonts
+// mapped from the font families.  Style sheets, floats and positioning are ignored, and the text must be
+// encodable in WinAnsiEncoding.
+//
+//   c := creator.New()
+//   c.NewPage()
+//   err := htmlconv.Draw(c, []byte("<h1>Report</h1><p>Total: <b>42</b></p>"), htmlconv.DefaultOptions())
+//
+package htmlconv
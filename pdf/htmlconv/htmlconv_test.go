@@ -0,0 +1,168 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package htmlconv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/creator"
+)
+
+func TestParse(t *testing.T) {
+	// Implied end tags, void elements, entities and unmatched end tags.
+	root, err := parse([]byte(`<P>One &amp; <br>two<p>three</span><ul><li>a<li>b</ul>` +
+		`<table><tr><td>1<td>2<tr><td>3</table>`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var tags []string
+	for _, child := range root.children {
+		tags = append(tags, child.tag)
+	}
+	if len(tags) != 4 || tags[0] != "p" || tags[1] != "p" || tags[2] != "ul" || tags[3] != "table" {
+		t.Fatalf("Wrong elements %v", tags)
+	}
+	p := root.children[0]
+	if len(p.children) != 3 || p.children[0].text != "One & " || p.children[1].tag != "br" {
+		t.Errorf("Wrong paragraph %+v", p.children)
+	}
+	if items := root.children[2].children; len(items) != 2 || items[1].children[0].text != "b" {
+		t.Errorf("Wrong list items")
+	}
+	table := root.children[3]
+	if len(table.children) != 2 || len(table.children[0].children) != 2 || len(table.children[1].children) != 1 {
+		t.Errorf("Wrong table rows")
+	}
+}
+
+func TestStyle(t *testing.T) {
+	lengths := []struct {
+		s      string
+		length float64
+	}{
+		{"16px", 12}, {"12pt", 12}, {"1in", 72}, {"2.54cm", 72}, {"2em", 20}, {"1rem", 12}, {"50%", 50}, {"4", 3},
+	}
+	for _, l := range lengths {
+		length, ok := parseLength(l.s, 100, 10, 12)
+		if !ok || math.Abs(length-l.length) > 1e-6 {
+			t.Errorf("Length %q: %f, expected %f", l.s, length, l.length)
+		}
+	}
+	if _, ok := parseLength("auto", 100, 10, 12); ok {
+		t.Errorf("Invalid length not detected")
+	}
+
+	root, err := parse([]byte(`<h2 style="color: #f00; margin: 1px 2px 3px; font-family: Times, serif">` +
+		`<i>x</i></h2>`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	h2 := root.children[0]
+	decls := h2.declarations()
+	if decls["margin-top"] != "1px" || decls["margin-right"] != "2px" || decls["margin-bottom"] != "3px" ||
+		decls["margin-left"] != "2px" || decls["font-weight"] != "bold" {
+		t.Errorf("Wrong declarations %v", decls)
+	}
+	base := style{fontSize: 10, color: creator.ColorBlack, lineHeight: 1.2}
+	s := computeStyle(base, decls, 10)
+	s = computeStyle(s, h2.children[0].declarations(), 10)
+	if s.fontSize != 15 || !s.bold || !s.italic || s.family != familySerif {
+		t.Errorf("Wrong style %+v", s)
+	}
+	if r, g, b := s.color.ToRGB(); r != 1 || g != 0 || b != 0 {
+		t.Errorf("Wrong color %f %f %f", r, g, b)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 20; y++ {
+			img.Set(x, y, color.RGBA{0, 0, 255, 255})
+		}
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	src := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	html := `<html><head><title>Report</title><style>p { color: red }</style></head><body>
+<h1>Quarterly   report</h1>
+<p style="text-align: justify">Sales <b>grew</b> by <span style="color: #008000">12%</span>,
+see <a href="https://example.com/">details</a>.<br>Second line</p>
+<ol type="a"><li>First<li>Second<ul><li>Nested</ul></ol>
+<table border="1" style="width: 50%">
+<thead><tr><th>Region<th>Total</tr></thead>
+<tbody><tr><td>North<td align="right" style="background-color: #eeeeee">10</tr>
+<tr><td colspan="2"><img src="` + src + `" width="20"></tr></tbody>
+</table>
+<hr>
+<div style="display: none">Hidden</div>
+<p style="page-break-before: always">Last page</p>
+</body></html>`
+
+	c := creator.New()
+	c.NewPage()
+	err = Draw(c, []byte(html), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// Rules are drawn across the width.
+	opts := DefaultOptions()
+	opts.Width = 500
+	drawables, err := Convert([]byte(html), opts)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// Heading, paragraph, list, table, rule, page break and paragraph.
+	if len(drawables) != 7 {
+		t.Fatalf("Wrong number of drawables %d", len(drawables))
+	}
+	if h, ok := drawables[0].(*creator.Heading); !ok || h.Level() != 1 || h.Title() != "Quarterly report" {
+		t.Errorf("Heading not converted: %T", drawables[0])
+	}
+	p, ok := drawables[1].(*creator.StyledParagraph)
+	if !ok {
+		t.Fatalf("Paragraph not converted: %T", drawables[1])
+	}
+	text := ""
+	for _, chunk := range p.Chunks() {
+		text += chunk.Text
+	}
+	if text != "Sales grew by 12%, see details.\nSecond line" {
+		t.Errorf("Wrong text %q", text)
+	}
+	if _, ok := drawables[3].(*creator.Table); !ok {
+		t.Errorf("Table not converted: %T", drawables[3])
+	}
+
+	err = c.WriteToFile("/tmp/html.pdf")
+	if err != nil {
+		t.Fatalf("Fail: %v\n", err)
+	}
+	if c.Context().Page != 2 {
+		t.Errorf("Wrong number of pages %d", c.Context().Page)
+	}
+
+	out, err := ioutil.ReadFile("/tmp/html.pdf")
+	if err != nil {
+		t.Fatalf("Fail: %v\n", err)
+	}
+	if !regexp.MustCompile(regexp.QuoteMeta("(https://example.com/)")).Match(out) {
+		t.Errorf("Link not written")
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package htmlconv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// node is an element of the HTML document, with its attributes and children.
+type node struct {
+	tag      string
+	attrs    map[string]string
+	children []*node
+	parent   *node
+
+	// The character data of text nodes.
+	text string
+}
+
+// Name of the text nodes, which are children of the elements with their character data.
+const textNode = "#text"
+
+// Elements without content or end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// Elements which close an open paragraph.
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "div": true, "dl": true,
+	"fieldset": true, "figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "ul": true,
+}
+
+// Elements whose end tags are optional: the open elements of the same kind closed by a start tag, and the
+// elements delimiting the search.
+var impliedEnds = map[string]struct {
+	closes, scope []string
+}{
+	"li":    {[]string{"li"}, []string{"ul", "ol"}},
+	"dt":    {[]string{"dt", "dd"}, []string{"dl"}},
+	"dd":    {[]string{"dt", "dd"}, []string{"dl"}},
+	"tr":    {[]string{"tr"}, []string{"table", "thead", "tbody", "tfoot"}},
+	"td":    {[]string{"td", "th"}, []string{"tr", "table"}},
+	"th":    {[]string{"td", "th"}, []string{"tr", "table"}},
+	"thead": {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"tbody": {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"tfoot": {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+}
+
+// parse parses the HTML document into a tree, under a root node.  The parsing is lenient: tag and attribute names
+// are case insensitive, void elements and the optional end tags of paragraphs, list items and table parts are
+// implied, and unmatched end tags are ignored.
+func parse(data []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	root := &node{tag: "#root", attrs: map[string]string{}}
+	stack := []*node{root}
+	current := func() *node {
+		return stack[len(stack)-1]
+	}
+	// closeTo closes the open elements up to the last element with the tag, searching up to the scope elements.
+	closeTo := func(tags, scope []string) {
+		for i := len(stack) - 1; i > 0; i-- {
+			if hasString(scope, stack[i].tag) {
+				return
+			}
+			if hasString(tags, stack[i].tag) {
+				stack = stack[:i]
+				return
+			}
+		}
+	}
+
+	for {
+		// Raw tokens, as the nesting of the elements is checked here.
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid HTML: %v", err)
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{tag: strings.ToLower(t.Name.Local), attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				n.attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+
+			if closesParagraph[n.tag] {
+				closeTo([]string{"p"}, []string{"li", "td", "th", "div", "blockquote"})
+			}
+			if implied, ok := impliedEnds[n.tag]; ok {
+				closeTo(implied.closes, implied.scope)
+			}
+
+			n.parent = current()
+			n.parent.children = append(n.parent.children, n)
+			if !voidElements[n.tag] {
+				stack = append(stack, n)
+			}
+		case xml.EndElement:
+			closeTo([]string{strings.ToLower(t.Name.Local)}, nil)
+		case xml.CharData:
+			parent := current()
+			parent.children = append(parent.children, &node{tag: textNode, text: string(t), parent: parent})
+		}
+	}
+
+	return root, nil
+}
+
+// hasString returns whether the list contains the string.
+func hasString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the first element with the tag in the tree of the node, or nil.
+func (n *node) find(tag string) *node {
+	if n.tag == tag {
+		return n
+	}
+	for _, child := range n.children {
+		if found := child.find(tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
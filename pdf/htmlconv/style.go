@@ -0,0 +1,402 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package htmlconv
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/pdf/creator"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// Points per CSS pixel: 72 points and 96 pixels per inch.
+const pointsPerPixel = 0.75
+
+// The default styles of the elements, as in the style sheets of browsers.
+var defaultStyles = map[string]string{
+	"h1":         "font-size: 2em; font-weight: bold; margin: 0.67em 0",
+	"h2":         "font-size: 1.5em; font-weight: bold; margin: 0.83em 0",
+	"h3":         "font-size: 1.17em; font-weight: bold; margin: 1em 0",
+	"h4":         "font-weight: bold; margin: 1.33em 0",
+	"h5":         "font-size: 0.83em; font-weight: bold; margin: 1.67em 0",
+	"h6":         "font-size: 0.67em; font-weight: bold; margin: 2.33em 0",
+	"p":          "margin: 1em 0",
+	"ul":         "margin: 1em 0",
+	"ol":         "margin: 1em 0",
+	"dl":         "margin: 1em 0",
+	"dd":         "margin-left: 40px",
+	"blockquote": "margin: 1em 40px",
+	"figure":     "margin: 1em 40px",
+	"pre":        "font-family: monospace; white-space: pre; margin: 1em 0",
+	"hr":         "margin: 0.5em 0",
+	"center":     "text-align: center",
+	"th":         "font-weight: bold; text-align: center",
+	"b":          "font-weight: bold",
+	"strong":     "font-weight: bold",
+	"dt":         "font-weight: bold",
+	"i":          "font-style: italic",
+	"em":         "font-style: italic",
+	"cite":       "font-style: italic",
+	"var":        "font-style: italic",
+	"address":    "font-style: italic",
+	"u":          "text-decoration: underline",
+	"ins":        "text-decoration: underline",
+	"s":          "text-decoration: line-through",
+	"strike":     "text-decoration: line-through",
+	"del":        "text-decoration: line-through",
+	"code":       "font-family: monospace",
+	"kbd":        "font-family: monospace",
+	"samp":       "font-family: monospace",
+	"tt":         "font-family: monospace",
+	"sub":        "vertical-align: sub",
+	"sup":        "vertical-align: super",
+	"small":      "font-size: smaller",
+	"big":        "font-size: larger",
+	"a":          "color: #0000ee; text-decoration: underline",
+}
+
+// Font sizes of the absolute size keywords, relative to the root font size.
+var fontSizeKeywords = map[string]float64{
+	"xx-small": 0.5625, "x-small": 0.625, "small": 0.8125, "medium": 1, "large": 1.125, "x-large": 1.5,
+	"xx-large": 2,
+}
+
+// Font families of the standard fonts.
+const (
+	familySans = iota
+	familySerif
+	familyMono
+)
+
+// style is the inherited style of the text of an element.
+type style struct {
+	family       int
+	bold, italic bool
+	fontSize     float64
+	color        creator.Color
+
+	underline, strikeout bool
+	verticalPosition     creator.TextVerticalPosition
+
+	align      creator.TextAlignment
+	lineHeight float64
+
+	// Whether whitespace is kept as is, instead of collapsed.
+	pre bool
+
+	// The URI of the link the text is in, if any.
+	uri string
+}
+
+// textStyle returns the creator style of text drawn with the style.
+func (s style) textStyle() creator.TextStyle {
+	ts := creator.NewTextStyle()
+	ts.Font = standardFont(s.family, s.bold, s.italic)
+	ts.FontSize = s.fontSize
+	ts.Color = s.color
+	ts.Underline = s.underline
+	ts.Strikeout = s.strikeout
+	ts.VerticalPosition = s.verticalPosition
+	return ts
+}
+
+// standardFont returns the standard font of the family with the style.
+func standardFont(family int, bold, italic bool) fonts.Font {
+	switch family {
+	case familySerif:
+		switch {
+		case bold && italic:
+			return fonts.NewFontTimesBoldItalic()
+		case bold:
+			return fonts.NewFontTimesBold()
+		case italic:
+			return fonts.NewFontTimesItalic()
+		}
+		return fonts.NewFontTimesRoman()
+	case familyMono:
+		switch {
+		case bold && italic:
+			return fonts.NewFontCourierBoldOblique()
+		case bold:
+			return fonts.NewFontCourierBold()
+		case italic:
+			return fonts.NewFontCourierOblique()
+		}
+		return fonts.NewFontCourier()
+	}
+	switch {
+	case bold && italic:
+		return fonts.NewFontHelveticaBoldOblique()
+	case bold:
+		return fonts.NewFontHelveticaBold()
+	case italic:
+		return fonts.NewFontHelveticaOblique()
+	}
+	return fonts.NewFontHelvetica()
+}
+
+// declarations returns the CSS declarations of the element: its default style, its presentational attributes and
+// its style attribute, by order of precedence.
+func (n *node) declarations() map[string]string {
+	decls := map[string]string{}
+	parseDeclarations(defaultStyles[n.tag], decls)
+
+	if align, has := n.attrs["align"]; has && n.tag != "img" && n.tag != "table" {
+		decls["text-align"] = align
+	}
+	if valign, has := n.attrs["valign"]; has {
+		decls["vertical-align"] = valign
+	}
+	if bgcolor, has := n.attrs["bgcolor"]; has {
+		decls["background-color"] = bgcolor
+	}
+	if n.tag == "font" {
+		if color, has := n.attrs["color"]; has {
+			decls["color"] = color
+		}
+		if face, has := n.attrs["face"]; has {
+			decls["font-family"] = face
+		}
+	}
+	for _, attr := range []string{"width", "height"} {
+		if value, has := n.attrs[attr]; has {
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				// Presentational lengths are in pixels.
+				value += "px"
+			}
+			decls[attr] = value
+		}
+	}
+
+	parseDeclarations(n.attrs["style"], decls)
+	return decls
+}
+
+// parseDeclarations parses CSS declarations (property: value; ...) into the map.  The shorthand margin property
+// is expanded.
+func parseDeclarations(css string, decls map[string]string) {
+	for _, decl := range strings.Split(css, ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+		value = strings.TrimSpace(strings.TrimSuffix(value, "!important"))
+
+		if property == "margin" {
+			// One to four values: top, right, bottom, left, with the missing values from their opposite sides.
+			values := strings.Fields(value)
+			if len(values) == 0 || len(values) > 4 {
+				continue
+			}
+			switch len(values) {
+			case 1:
+				values = append(values, values[0], values[0], values[0])
+			case 2:
+				values = append(values, values[0], values[1])
+			case 3:
+				values = append(values, values[1])
+			}
+			decls["margin-top"] = values[0]
+			decls["margin-right"] = values[1]
+			decls["margin-bottom"] = values[2]
+			decls["margin-left"] = values[3]
+			continue
+		}
+		decls[property] = value
+	}
+}
+
+// computeStyle returns the style of the element with the declarations, inheriting the style of its parent.
+func computeStyle(parent style, decls map[string]string, rootFontSize float64) style {
+	s := parent
+
+	if size, has := decls["font-size"]; has {
+		switch size {
+		case "smaller":
+			s.fontSize = parent.fontSize / 1.2
+		case "larger":
+			s.fontSize = parent.fontSize * 1.2
+		default:
+			if factor, ok := fontSizeKeywords[size]; ok {
+				s.fontSize = rootFontSize * factor
+				break
+			}
+			if l, ok := parseLength(size, parent.fontSize, parent.fontSize, rootFontSize); ok && l > 0 {
+				s.fontSize = l
+			}
+		}
+	}
+	if weight, has := decls["font-weight"]; has {
+		switch weight {
+		case "bold", "bolder":
+			s.bold = true
+		case "normal", "lighter":
+			s.bold = false
+		default:
+			if w, err := strconv.Atoi(weight); err == nil {
+				s.bold = w >= 600
+			}
+		}
+	}
+	if fontStyle, has := decls["font-style"]; has {
+		s.italic = fontStyle == "italic" || fontStyle == "oblique"
+	}
+	if family, has := decls["font-family"]; has {
+		s.family = parseFontFamily(family)
+	}
+	if color, has := decls["color"]; has {
+		if c, ok := parseColor(color); ok {
+			s.color = c
+		}
+	}
+	if decoration, has := decls["text-decoration"]; has {
+		s.underline = strings.Contains(decoration, "underline")
+		s.strikeout = strings.Contains(decoration, "line-through")
+	}
+	if align, has := decls["vertical-align"]; has {
+		switch align {
+		case "super":
+			s.verticalPosition = creator.TextPositionSuperscript
+		case "sub":
+			s.verticalPosition = creator.TextPositionSubscript
+		case "baseline":
+			s.verticalPosition = creator.TextPositionNormal
+		}
+	}
+	if align, has := decls["text-align"]; has {
+		switch strings.ToLower(align) {
+		case "left", "start":
+			s.align = creator.TextAlignmentLeft
+		case "right", "end":
+			s.align = creator.TextAlignmentRight
+		case "center", "middle":
+			s.align = creator.TextAlignmentCenter
+		case "justify":
+			s.align = creator.TextAlignmentJustify
+		}
+	}
+	if lineHeight, has := decls["line-height"]; has {
+		if f, err := strconv.ParseFloat(lineHeight, 64); err == nil && f > 0 {
+			s.lineHeight = f
+		} else if lineHeight == "normal" {
+			s.lineHeight = 1.2
+		} else if l, ok := parseLength(lineHeight, s.fontSize, s.fontSize, rootFontSize); ok && l > 0 {
+			s.lineHeight = l / s.fontSize
+		}
+	}
+	if whiteSpace, has := decls["white-space"]; has {
+		s.pre = strings.HasPrefix(whiteSpace, "pre")
+	}
+	return s
+}
+
+// parseFontFamily returns the standard font family closest to the first known family of the list.
+func parseFontFamily(list string) int {
+	for _, family := range strings.Split(list, ",") {
+		family = strings.ToLower(strings.Trim(strings.TrimSpace(family), `"'`))
+		switch {
+		case family == "monospace" || strings.Contains(family, "courier") || strings.Contains(family, "mono") ||
+			family == "consolas":
+			return familyMono
+		case family == "serif" || strings.Contains(family, "times") || family == "georgia" ||
+			family == "garamond":
+			return familySerif
+		case family == "sans-serif" || family == "helvetica" || family == "arial" || family == "verdana" ||
+			strings.Contains(family, "sans"):
+			return familySans
+		}
+	}
+	return familySans
+}
+
+// parseLength parses a CSS length in points, with percentages of the base length, and em and rem units relative
+// to the font size of the element and of the document.
+func parseLength(s string, base, fontSize, rootFontSize float64) (float64, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	units := map[string]float64{
+		"px": pointsPerPixel, "pt": 1, "pc": 12, "in": 72, "cm": 72 / 2.54, "mm": 72 / 25.4,
+		"rem": rootFontSize, "em": fontSize, "ex": fontSize / 2, "%": base / 100,
+	}
+	factor := pointsPerPixel
+	for _, unit := range []string{"px", "pt", "pc", "in", "cm", "mm", "rem", "em", "ex", "%"} {
+		if strings.HasSuffix(s, unit) {
+			s = strings.TrimSuffix(s, unit)
+			factor = units[unit]
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * factor, true
+}
+
+// Named colors.
+var colorNames = map[string]string{
+	"black": "#000000", "silver": "#c0c0c0", "gray": "#808080", "grey": "#808080", "white": "#ffffff",
+	"maroon": "#800000", "red": "#ff0000", "purple": "#800080", "fuchsia": "#ff00ff", "magenta": "#ff00ff",
+	"green": "#008000", "lime": "#00ff00", "olive": "#808000", "yellow": "#ffff00", "navy": "#000080",
+	"blue": "#0000ff", "teal": "#008080", "aqua": "#00ffff", "cyan": "#00ffff", "orange": "#ffa500",
+	"brown": "#a52a2a", "pink": "#ffc0cb", "gold": "#ffd700", "darkgray": "#a9a9a9", "darkgrey": "#a9a9a9",
+	"lightgray": "#d3d3d3", "lightgrey": "#d3d3d3", "darkblue": "#00008b", "darkred": "#8b0000",
+	"darkgreen": "#006400", "lightblue": "#add8e6", "lightgreen": "#90ee90", "lightyellow": "#ffffe0",
+	"whitesmoke": "#f5f5f5", "gainsboro": "#dcdcdc", "steelblue": "#4682b4", "indigo": "#4b0082",
+}
+
+// parseColor parses a CSS color: #rgb, #rrggbb, rgb() or a color name.  Transparent colors are not parsed.
+func parseColor(s string) (creator.Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if hex, ok := colorNames[s]; ok {
+		s = hex
+	}
+
+	switch {
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return nil, false
+		}
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, false
+		}
+		return creator.ColorRGBFrom8bit(byte(rgb>>16), byte(rgb>>8), byte(rgb)), true
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return nil, false
+		}
+		var rgb [3]float64
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			var err error
+			if strings.HasSuffix(part, "%") {
+				rgb[i], err = strconv.ParseFloat(strings.TrimSuffix(part, "%"), 64)
+				rgb[i] /= 100
+			} else {
+				rgb[i], err = strconv.ParseFloat(part, 64)
+				rgb[i] /= 255
+			}
+			if err != nil {
+				return nil, false
+			}
+			if rgb[i] < 0 {
+				rgb[i] = 0
+			} else if rgb[i] > 1 {
+				rgb[i] = 1
+			}
+		}
+		return creator.ColorRGBFromArithmetic(rgb[0], rgb[1], rgb[2]), true
+	}
+	return nil, false
+}
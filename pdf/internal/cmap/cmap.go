@@ -27,6 +27,9 @@ type CMap struct {
 	name       string
 	ctype      int
 	codespaces []codespace
+
+	// Mapping of character codes to CIDs (cidrange and cidchar sections), for the encodings of composite fonts.
+	cidRanges []cidRange
 }
 
 // codespace represents a single codespace range used in the CMap.
@@ -36,6 +39,25 @@ type codespace struct {
 	high     uint64
 }
 
+// contains returns true if each byte of the code is within the range of the corresponding bytes of low and high.
+func (cs codespace) contains(code []byte) bool {
+	for i, b := range code {
+		shift := uint(8 * (cs.numBytes - 1 - i))
+		if uint64(b) < (cs.low>>shift)&0xff || uint64(b) > (cs.high>>shift)&0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// cidRange maps the character codes from low to high to consecutive CIDs from cid.
+type cidRange struct {
+	numBytes int
+	low      uint64
+	high     uint64
+	cid      int
+}
+
 // Name returns the name of the CMap.
 func (cmap *CMap) Name() string {
 	return cmap.name
@@ -77,6 +99,45 @@ func (cmap *CMap) CharcodeBytesToUnicode(src []byte) string {
 	return buf.String()
 }
 
+// CharcodeLength returns the number of bytes of the character code at the start of the data, from the codespace
+// ranges: the shortest code within a codespace range (see 9.7.6.2 of the PDF specification).  The codes within no
+// range have the length of the shortest range, 1 if the CMap has no codespace ranges.
+func (cmap *CMap) CharcodeLength(data []byte) int {
+	for n := 1; n <= 4 && n <= len(data); n++ {
+		for _, cs := range cmap.codespaces {
+			if cs.numBytes == n && cs.contains(data[:n]) {
+				return n
+			}
+		}
+	}
+
+	shortest := 0
+	for _, cs := range cmap.codespaces {
+		if shortest == 0 || cs.numBytes < shortest {
+			shortest = cs.numBytes
+		}
+	}
+	if shortest == 0 {
+		return 1
+	}
+	return shortest
+}
+
+// CharcodeToCID returns the CID of a character code mapped by the cidrange and cidchar sections of the CMap.  False if
+// the code is not mapped, the code being then mapped to CID 0 (.notdef).
+func (cmap *CMap) CharcodeToCID(code []byte) (int, bool) {
+	var val uint64
+	for _, b := range code {
+		val = val<<8 | uint64(b)
+	}
+	for _, r := range cmap.cidRanges {
+		if r.numBytes == len(code) && val >= r.low && val <= r.high {
+			return r.cid + int(val-r.low), true
+		}
+	}
+	return 0, false
+}
+
 // CharcodeToUnicode converts a single character code to unicode string.
 // Note that CharcodeBytesToUnicode is typically more efficient.
 func (cmap *CMap) CharcodeToUnicode(srcCode uint64) string {
@@ -149,6 +210,16 @@ func (cmap *CMap) parse() error {
 				if err != nil {
 					return err
 				}
+			} else if op.Operand == begincidchar {
+				err := cmap.parseCidRanges(endcidchar, false)
+				if err != nil {
+					return err
+				}
+			} else if op.Operand == begincidrange {
+				err := cmap.parseCidRanges(endcidrange, true)
+				if err != nil {
+					return err
+				}
 			}
 		} else if n, isName := o.(cmapName); isName {
 			if n.Name == cmapname {
@@ -393,7 +464,7 @@ func (cmap *CMap) parseBfrange() error {
 			i := uint64(0)
 			for sc := srcCodeFrom; sc <= srcCodeTo; sc++ {
 				r := target + i
-				cmap.codeMap[numBytes-1][sc] = string(r)
+				cmap.codeMap[numBytes-1][sc] = string(rune(r))
 				i++
 			}
 		default:
@@ -403,3 +474,55 @@ func (cmap *CMap) parseBfrange() error {
 
 	return nil
 }
+
+// parseCidRanges parses a cidrange section of a CMap file, <srcCodeFrom> <srcCodeTo> CID entries, or a cidchar
+// section, <srcCode> CID entries.
+func (cmap *CMap) parseCidRanges(endOperand string, isRange bool) error {
+	for {
+		codes := []cmapHexString{}
+		n := 1
+		if isRange {
+			n = 2
+		}
+		for len(codes) < n {
+			o, err := cmap.parseObject()
+			if err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			switch v := o.(type) {
+			case cmapOperand:
+				if v.Operand == endOperand && len(codes) == 0 {
+					return nil
+				}
+				return errors.New("Unexpected operand")
+			case cmapHexString:
+				codes = append(codes, v)
+			default:
+				return errors.New("Unexpected type")
+			}
+		}
+
+		o, err := cmap.parseObject()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		cid, ok := o.(cmapInt)
+		if !ok {
+			return errors.New("CID not an integer")
+		}
+
+		numBytes := codes[0].numBytes
+		if numBytes <= 0 || numBytes > 4 {
+			return errors.New("Invalid code length")
+		}
+		r := cidRange{numBytes: numBytes, low: hexToUint64(codes[0]), cid: int(cid.val)}
+		r.high = hexToUint64(codes[len(codes)-1])
+		cmap.cidRanges = append(cmap.cidRanges, r)
+	}
+}
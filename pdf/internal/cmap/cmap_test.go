@@ -324,3 +324,57 @@ func TestCMapParser3(t *testing.T) {
 		}
 	}
 }
+
+// cmapData4 is a CMap mapping 1 and 2 byte codes to CIDs, as the encoding of a composite font.
+const cmapData4 = `
+/CIDInit /ProcSet findresource begin
+12 dict begin begincmap
+/CMapName /test-cid def
+/CMapType 1 def
+2 begincodespacerange
+<00> <80>
+<8140> <9ffc>
+endcodespacerange
+2 begincidrange
+<20> <7e> 1
+<8140> <817e> 633
+endcidrange
+1 begincidchar
+<9ffc> 7000
+endcidchar
+endcmap
+`
+
+// TestCMapCIDs tests the code lengths from the codespace ranges and the mapping of codes to CIDs.
+func TestCMapCIDs(t *testing.T) {
+	cmap, err := LoadCmapFromData([]byte(cmapData4))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The second byte of 2 byte codes is within the range of its codespace, e.g. 0x41 as a 1 byte code.
+	data := []byte{0x41, 0x81, 0x41, 0x9f, 0xfc, 0x81, 0x30, 0xff}
+	expected := []struct {
+		length int
+		cid    int
+		mapped bool
+	}{
+		{1, 34, true},
+		{2, 634, true},
+		{2, 7000, true},
+		{1, 0, false},
+		{1, 17, true},
+		{1, 0, false},
+	}
+	for i, exp := range expected {
+		n := cmap.CharcodeLength(data)
+		cid, mapped := cmap.CharcodeToCID(data[:n])
+		if n != exp.length || cid != exp.cid || mapped != exp.mapped {
+			t.Errorf("Code %d: length %d, CID %d (%v)", i, n, cid, mapped)
+		}
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		t.Errorf("Remaining data % X", data)
+	}
+}
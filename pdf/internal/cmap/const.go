@@ -15,6 +15,10 @@ const (
 	endbfchar           = "endbfchar"
 	beginbfrange        = "beginbfrange"
 	endbfrange          = "endbfrange"
+	begincidchar        = "begincidchar"
+	endcidchar          = "endcidchar"
+	begincidrange       = "begincidrange"
+	endcidrange         = "endcidrange"
 
 	cmapname = "CMapName"
 	cmaptype = "CMapType"
@@ -0,0 +1,41 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package testutils provides the helpers shared by the tests of the pdf packages.  It only depends on the standard
+// library, so that it can be used by the tests of any package, including core.
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MakePdf returns a PDF document with the objects, numbered from 1, the first being the catalog.
+func MakePdf(objects []string) []byte {
+	return MakePdfWithTrailer(objects, "")
+}
+
+// MakePdfWithTrailer returns a PDF document with the objects, numbered from 1, the first being the catalog, and the
+// entries of the trailer in addition to Size and Root, e.g. "/Info 2 0 R".
+func MakePdfWithTrailer(objects []string, trailer string) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n")
+	offsets := []int{}
+	for i, obj := range objects {
+		offsets = append(offsets, buf.Len())
+		buf.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj))
+	}
+	xrefOffset := buf.Len()
+	buf.WriteString(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1))
+	for _, offset := range offsets {
+		buf.WriteString(fmt.Sprintf("%.10d 00000 n\r\n", offset))
+	}
+	if trailer != "" {
+		trailer = " " + trailer
+	}
+	buf.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R%s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1,
+		trailer, xrefOffset))
+	return buf.Bytes()
+}
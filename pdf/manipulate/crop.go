@@ -0,0 +1,61 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"math"
+
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// AutoCrop sets the crop box of the page to the bounding box of its visible content (see Extractor.ContentBBox),
+// enlarged by margin points on each side and limited to the media box.  The crop box is not changed if the page
+// has no visible content.
+func AutoCrop(page *model.PdfPage, margin float64) error {
+	e, err := extractor.New(page)
+	if err != nil {
+		return err
+	}
+	bbox, found, err := e.ContentBBox()
+	if err != nil || !found {
+		return err
+	}
+	mediaBox, err := page.GetMediaBox()
+	if err != nil {
+		return err
+	}
+
+	margin /= e.UserUnit()
+	cropBox := &model.PdfRectangle{
+		Llx: math.Max(bbox.Llx-margin, math.Min(mediaBox.Llx, mediaBox.Urx)),
+		Lly: math.Max(bbox.Lly-margin, math.Min(mediaBox.Lly, mediaBox.Ury)),
+		Urx: math.Min(bbox.Urx+margin, math.Max(mediaBox.Llx, mediaBox.Urx)),
+		Ury: math.Min(bbox.Ury+margin, math.Max(mediaBox.Lly, mediaBox.Ury)),
+	}
+	return page.SetCropBox(cropBox)
+}
+
+// AutoCropPages copies the document with the crop boxes of the pages set to their visible content (see AutoCrop).
+func AutoCropPages(reader *model.PdfReader, margin float64) (*model.PdfWriter, error) {
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBuilder(reader)
+	for pageNum := 1; pageNum <= numPages; pageNum++ {
+		err := b.addPage(0, pageNum, 0)
+		if err != nil {
+			return nil, err
+		}
+		err = AutoCrop(b.pages[len(b.pages)-1].copy, margin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.write()
+}
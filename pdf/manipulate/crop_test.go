@@ -0,0 +1,27 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"testing"
+)
+
+func TestAutoCropPages(t *testing.T) {
+	w, err := AutoCropPages(newTestReader(t), 5)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader := readBack(t, w)
+	if len(reader.PageList) != 3 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	// The filled 10x10 square at the origin with the margin limited to the media box.
+	for i, page := range reader.PageList {
+		if str := formatRect(page.CropBox); str != "[0 0 15 15]" {
+			t.Errorf("Wrong crop box of page %d: %s", i+1, str)
+		}
+	}
+}
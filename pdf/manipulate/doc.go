@@ -0,0 +1,38 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package manipulate rearranges the pages of PDF documents: splitting, merging, reordering, rotating and deleting
+// pages.  The pages are copied to new documents with their inherited attributes (resources, boxes and rotation from
+// the page tree), and the references to pages are rewritten: the destinations of the link annotations, the outline
+// items and the named destinations point at the copies of the pages, and those pointing at pages not in the new
+// document are removed.
+//
+// Impose places several pages on each page of a new document, for N-up layouts and booklets, ScalePages scales the
+// pages to another paper size, and AutoCropPages crops the pages to their visible content.
+//
+// The functions return PdfWriters, which are written with Write.  The input readers must not be modified until
+// then, as the copies share the contents and resources of their pages.
+//
+// Example: extracting the pages 1 to 3 and 4 to the end of a document to two documents.
+//
+//	numPages, err := pdfReader.GetNumPages()
+//	...
+//	writers, err := manipulate.Split(pdfReader, []manipulate.PageRange{{1, 3}, {4, numPages}})
+//	...
+//	err = writers[0].Write(firstFile)
+//	...
+//	err = writers[1].Write(secondFile)
+//
+// Example: merging documents.
+//
+//	w, err := manipulate.Merge(pdfReader1, pdfReader2)
+//	...
+//	err = w.Write(outputFile)
+//
+// Example: a 4-up layout on letter size pages.
+//
+//	opts := manipulate.ImposeOptions{Columns: 2, Rows: 2, Width: 612, Height: 792, Gutter: 18, Margin: 36}
+//	w, err := manipulate.Impose(pdfReader, opts)
+package manipulate
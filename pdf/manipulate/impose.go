@@ -0,0 +1,231 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// PageBox is a boundary of the pages (14.11.2 Page Boundaries), the region of the pages placed by Impose.  The
+// boundaries missing from a page default to its crop box, and the crop box to the media box.
+type PageBox int
+
+const (
+	CropBox PageBox = iota // The visible region of the page, the default.
+	MediaBox
+	BleedBox
+	TrimBox
+	ArtBox
+)
+
+// Scaling is how the pages are scaled to the cells of the output pages by Impose.
+type Scaling int
+
+const (
+	ScaleFit    Scaling = iota // Scaled to fit the cells, keeping their aspect ratio, the default.
+	ScaleShrink                // Scaled down to fit the cells if larger, not enlarged.
+	ScaleNone                  // Not scaled, clipped to the cells.
+)
+
+// ImposeOptions are the layout of the output pages of Impose.  The zero value is a 2-up layout without margins.
+type ImposeOptions struct {
+	// Number of cells of the output pages, placed by row from the upper left corner.  2 columns and 1 row if zero.
+	Columns int
+	Rows    int
+
+	// Size of the output pages in points.  If zero, the size of the grid of cells of the size of the first page.
+	Width  float64
+	Height float64
+
+	// Space between the cells, and between the cells and the edges of the output pages, in points.
+	Gutter float64
+	Margin float64
+
+	Box     PageBox
+	Scaling Scaling
+
+	// Saddle-stitch booklet ordering, with 2 cells per output page: the output pages are the front and back sides of
+	// sheets to stack and fold in the middle, the pages being completed with blank pages to a multiple of 4.
+	Booklet bool
+}
+
+// Impose places several pages of the document on each page of a new document, drawn as Form XObjects centered in
+// the cells of a grid, e.g. for 2-up and 4-up layouts, or booklets printed double-sided and folded.  The
+// annotations of the pages are not kept.
+func Impose(reader *model.PdfReader, opts ImposeOptions) (*model.PdfWriter, error) {
+	cols, rows := opts.Columns, opts.Rows
+	if cols == 0 && rows == 0 {
+		cols, rows = 2, 1
+	}
+	if cols < 1 || rows < 1 {
+		common.Log.Debug("ERROR: Invalid imposition grid %dx%d", cols, rows)
+		return nil, model.ErrRangeError
+	}
+	if opts.Booklet && cols*rows != 2 {
+		common.Log.Debug("ERROR: Booklet imposition with %d cells per page", cols*rows)
+		return nil, errors.New("Booklet imposition requires 2 cells per page")
+	}
+
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	if numPages == 0 {
+		return nil, errors.New("No pages to impose")
+	}
+	// The pages (from 0) in the order of the cells, -1 for blank cells.
+	order := []int{}
+	if opts.Booklet {
+		order = bookletOrder(numPages)
+	} else {
+		for i := 0; i < numPages; i++ {
+			order = append(order, i)
+		}
+	}
+
+	width, height := opts.Width, opts.Height
+	if width <= 0 || height <= 0 {
+		page, err := reader.GetPage(1)
+		if err != nil {
+			return nil, err
+		}
+		_, pw, ph, err := pageForm(page, opts.Box)
+		if err != nil {
+			return nil, err
+		}
+		width = float64(cols)*pw + float64(cols-1)*opts.Gutter + 2*opts.Margin
+		height = float64(rows)*ph + float64(rows-1)*opts.Gutter + 2*opts.Margin
+	}
+	cellW := (width - 2*opts.Margin - float64(cols-1)*opts.Gutter) / float64(cols)
+	cellH := (height - 2*opts.Margin - float64(rows-1)*opts.Gutter) / float64(rows)
+	if cellW <= 0 || cellH <= 0 {
+		common.Log.Debug("ERROR: Invalid cell size %fx%f", cellW, cellH)
+		return nil, errors.New("Margins and gutter larger than the page")
+	}
+
+	w := model.NewPdfWriter()
+	cells := cols * rows
+	for start := 0; start < len(order); start += cells {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: width, Ury: height}
+		page.Resources = model.NewPdfPageResources()
+
+		cc := contentstream.NewContentCreator()
+		for cell := 0; cell < cells && start+cell < len(order); cell++ {
+			if order[start+cell] < 0 {
+				continue
+			}
+			src, err := reader.GetPage(order[start+cell] + 1)
+			if err != nil {
+				return nil, err
+			}
+			xform, pw, ph, err := pageForm(src, opts.Box)
+			if err != nil {
+				return nil, err
+			}
+			name := core.PdfObjectName(fmt.Sprintf("P%d", cell+1))
+			err = page.Resources.SetXObjectFormByName(name, xform)
+			if err != nil {
+				return nil, err
+			}
+
+			col, row := cell%cols, cell/cols
+			x := opts.Margin + float64(col)*(cellW+opts.Gutter)
+			y := height - opts.Margin - float64(row+1)*cellH - float64(row)*opts.Gutter
+			scale := 1.0
+			switch opts.Scaling {
+			case ScaleFit:
+				scale = math.Min(cellW/pw, cellH/ph)
+			case ScaleShrink:
+				scale = math.Min(1, math.Min(cellW/pw, cellH/ph))
+			}
+
+			cc.Add_q().
+				Add_re(x, y, cellW, cellH).Add_W().Add_n().
+				Add_cm(scale, 0, 0, scale, x+(cellW-scale*pw)/2, y+(cellH-scale*ph)/2).
+				Add_Do(name).
+				Add_Q()
+		}
+		page.AddContentStreamByString(cc.String())
+
+		err := w.AddPage(page)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &w, nil
+}
+
+// bookletOrder returns the pages (from 0, -1 for blank pages) on the sides of the sheets of a saddle-stitched
+// booklet: for each sheet from the outside, the front side with the last and first pages, and the back side with
+// the second and second to last pages.
+func bookletOrder(numPages int) []int {
+	n := (numPages + 3) / 4 * 4
+	page := func(i int) int {
+		if i < numPages {
+			return i
+		}
+		return -1
+	}
+	order := []int{}
+	for i := 0; i < n/4; i++ {
+		order = append(order, page(n-1-2*i), page(2*i), page(2*i+1), page(n-2-2*i))
+	}
+	return order
+}
+
+// pageForm returns the region of the page as a Form XObject (see PdfPage.ToXObjectForm), with the rotation
+// inherited from the page tree, and the width and height of the region as displayed.
+func pageForm(page *model.PdfPage, box PageBox) (*model.XObjectForm, float64, float64, error) {
+	region := page.CropBox
+	switch box {
+	case MediaBox:
+		region = nil
+	case BleedBox:
+		if page.BleedBox != nil {
+			region = page.BleedBox
+		}
+	case TrimBox:
+		if page.TrimBox != nil {
+			region = page.TrimBox
+		}
+	case ArtBox:
+		if page.ArtBox != nil {
+			region = page.ArtBox
+		}
+	}
+	if region == nil {
+		var err error
+		region, err = page.GetMediaBox()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	// A copy with the region as crop box, keeping the Parent for the inherited resources.
+	dup := page.Duplicate()
+	dup.CropBox = region
+	rotate := pageRotation(page)
+	dup.Rotate = &rotate
+	xform, err := dup.ToXObjectForm()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	w, h := region.Urx-region.Llx, region.Ury-region.Lly
+	if rotate%180 != 0 {
+		w, h = h, w
+	}
+	unit := page.GetUserUnit()
+	return xform, w * unit, h * unit, nil
+}
@@ -0,0 +1,142 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// newImposeTestReader returns a document of 200x100 pages, whose content is the page number as a gray level.
+func newImposeTestReader(t *testing.T, numPages int) *model.PdfReader {
+	w := model.NewPdfWriter()
+	for i := 1; i <= numPages; i++ {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 100}
+		page.Resources = model.NewPdfPageResources()
+		page.AddContentStreamByString(fmt.Sprintf("%d g 0 0 200 100 re f", i))
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	return readBack(t, &w)
+}
+
+// describeCells returns the pages placed on the page, by XObject name, as the gray level of their content.
+func describeCells(t *testing.T, page *model.PdfPage) []string {
+	cells := []string{}
+	for i := 1; i <= 4; i++ {
+		name := core.PdfObjectName(fmt.Sprintf("P%d", i))
+		if !page.Resources.HasXObjectByName(name) {
+			continue
+		}
+		xform, err := page.Resources.GetXObjectFormByName(name)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		content, err := xform.GetContentStream()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		cells = append(cells, fmt.Sprintf("%s:%s", name, strings.SplitN(string(content), " ", 2)[0]))
+	}
+	return cells
+}
+
+func TestImpose2Up(t *testing.T) {
+	w, err := Impose(newImposeTestReader(t, 3), ImposeOptions{})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader := readBack(t, w)
+	if len(reader.PageList) != 2 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+
+	mediaBox, err := reader.PageList[0].GetMediaBox()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if mediaBox.Urx != 400 || mediaBox.Ury != 100 {
+		t.Errorf("Wrong page size %v", mediaBox)
+	}
+	expected := [][]string{{"P1:1", "P2:2"}, {"P1:3"}}
+	for i, page := range reader.PageList {
+		if cells := describeCells(t, page); fmt.Sprint(cells) != fmt.Sprint(expected[i]) {
+			t.Errorf("Wrong cells on page %d: %v", i+1, cells)
+		}
+	}
+	content, err := reader.PageList[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(content, "1.000000 0.000000 0.000000 1.000000 200.000000 0.000000 cm") {
+		t.Errorf("Wrong placement of the second page %q", content)
+	}
+}
+
+func TestImpose4Up(t *testing.T) {
+	opts := ImposeOptions{Columns: 2, Rows: 2, Width: 612, Height: 792, Gutter: 18, Margin: 36}
+	w, err := Impose(newImposeTestReader(t, 4), opts)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader := readBack(t, w)
+	if len(reader.PageList) != 1 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	if cells := describeCells(t, reader.PageList[0]); fmt.Sprint(cells) != "[P1:1 P2:2 P3:3 P4:4]" {
+		t.Errorf("Wrong cells %v", cells)
+	}
+
+	// Cells of 261x351, the pages scaled by 1.305 to 261x130.5 and centered vertically.
+	content, err := reader.PageList[0].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, op := range []string{
+		"36.000000 405.000000 261.000000 351.000000 re W n " +
+			"1.305000 0.000000 0.000000 1.305000 36.000000 515.250000 cm /P1 Do",
+		"315.000000 36.000000 261.000000 351.000000 re W n " +
+			"1.305000 0.000000 0.000000 1.305000 315.000000 146.250000 cm /P4 Do",
+	} {
+		if !strings.Contains(strings.Replace(content, "\n", " ", -1), op) {
+			t.Errorf("Missing %q in %q", op, content)
+		}
+	}
+}
+
+func TestImposeBooklet(t *testing.T) {
+	if order := bookletOrder(8); fmt.Sprint(order) != "[7 0 1 6 5 2 3 4]" {
+		t.Errorf("Wrong booklet order %v", order)
+	}
+
+	w, err := Impose(newImposeTestReader(t, 3), ImposeOptions{Booklet: true})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader := readBack(t, w)
+	// A sheet, the blank fourth page on the front.
+	expected := [][]string{{"P2:1"}, {"P1:2", "P2:3"}}
+	if len(reader.PageList) != len(expected) {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	for i, page := range reader.PageList {
+		if cells := describeCells(t, page); fmt.Sprint(cells) != fmt.Sprint(expected[i]) {
+			t.Errorf("Wrong cells on page %d: %v", i+1, cells)
+		}
+	}
+
+	_, err = Impose(newImposeTestReader(t, 3), ImposeOptions{Columns: 2, Rows: 2, Booklet: true})
+	if err == nil {
+		t.Errorf("Booklet with 4 cells per page should fail")
+	}
+}
@@ -0,0 +1,476 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// PageRange is a range of pages of a document, numbered from 1, including the First and Last pages.
+type PageRange struct {
+	First int
+	Last  int
+}
+
+// contains returns true if the page number is in one of the ranges.
+func contains(ranges []PageRange, pageNum int) bool {
+	for _, r := range ranges {
+		if pageNum >= r.First && pageNum <= r.Last {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRanges returns an error if a range is empty or not within the pages of the document.
+func checkRanges(ranges []PageRange, numPages int) error {
+	for _, r := range ranges {
+		if r.First < 1 || r.Last < r.First || r.Last > numPages {
+			common.Log.Debug("ERROR: Invalid page range %d-%d (%d pages)", r.First, r.Last, numPages)
+			return model.ErrRangeError
+		}
+	}
+	return nil
+}
+
+// Split copies the ranges of pages of the document to separate documents, in the order of the ranges.
+func Split(reader *model.PdfReader, ranges []PageRange) ([]*model.PdfWriter, error) {
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	err = checkRanges(ranges, numPages)
+	if err != nil {
+		return nil, err
+	}
+
+	writers := []*model.PdfWriter{}
+	for _, r := range ranges {
+		b := newBuilder(reader)
+		for pageNum := r.First; pageNum <= r.Last; pageNum++ {
+			err := b.addPage(0, pageNum, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		w, err := b.write()
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, w)
+	}
+	return writers, nil
+}
+
+// Merge copies the pages of the documents one after the other to a document.  The outlines of the documents are
+// concatenated, and the named destinations defined by several documents are renamed with a numeric suffix.
+func Merge(readers ...*model.PdfReader) (*model.PdfWriter, error) {
+	b := newBuilder(readers...)
+	for doc, reader := range readers {
+		numPages, err := reader.GetNumPages()
+		if err != nil {
+			return nil, err
+		}
+		for pageNum := 1; pageNum <= numPages; pageNum++ {
+			err := b.addPage(doc, pageNum, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.write()
+}
+
+// Reorder copies the pages of the document in a new order: the permutation lists the numbers of the pages in their
+// new order, each page once.
+func Reorder(reader *model.PdfReader, permutation []int) (*model.PdfWriter, error) {
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	if len(permutation) != numPages {
+		common.Log.Debug("ERROR: Permutation of %d pages, document of %d pages", len(permutation), numPages)
+		return nil, model.ErrRangeError
+	}
+
+	b := newBuilder(reader)
+	seen := map[int]bool{}
+	for _, pageNum := range permutation {
+		if pageNum < 1 || pageNum > numPages || seen[pageNum] {
+			common.Log.Debug("ERROR: Invalid page %d in permutation", pageNum)
+			return nil, model.ErrRangeError
+		}
+		seen[pageNum] = true
+		err := b.addPage(0, pageNum, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.write()
+}
+
+// RotatePages copies the document with the pages in the ranges, or all the pages if none, rotated clockwise by the
+// angle in degrees, a multiple of 90.  The angle is added to the current rotation of the pages.
+func RotatePages(reader *model.PdfReader, angle int64, ranges []PageRange) (*model.PdfWriter, error) {
+	if angle%90 != 0 {
+		common.Log.Debug("ERROR: Rotation angle %d not a multiple of 90", angle)
+		return nil, errors.New("Rotation angle not a multiple of 90")
+	}
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	err = checkRanges(ranges, numPages)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBuilder(reader)
+	for pageNum := 1; pageNum <= numPages; pageNum++ {
+		pageAngle := int64(0)
+		if len(ranges) == 0 || contains(ranges, pageNum) {
+			pageAngle = angle
+		}
+		err := b.addPage(0, pageNum, pageAngle)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.write()
+}
+
+// DeletePages copies the document without the pages in the ranges.
+func DeletePages(reader *model.PdfReader, ranges []PageRange) (*model.PdfWriter, error) {
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	err = checkRanges(ranges, numPages)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBuilder(reader)
+	for pageNum := 1; pageNum <= numPages; pageNum++ {
+		if contains(ranges, pageNum) {
+			continue
+		}
+		err := b.addPage(0, pageNum, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.write()
+}
+
+// pageCopy is a page of an input document and its copy in the new document.
+type pageCopy struct {
+	doc  int
+	page *model.PdfPage
+	copy *model.PdfPage
+
+	// Transformation of the content of the page on its copy, applied to its annotations, nil if none.
+	matrix *matrix
+}
+
+// builder copies pages of input documents to a new document, with the references to the pages rewritten to their
+// copies.
+type builder struct {
+	readers []*model.PdfReader
+
+	// The copies of the pages in the order of the new document, and by input document and page object.
+	pages  []pageCopy
+	copies []map[core.PdfObject]*model.PdfPage
+
+	// Names of the named destinations in the new document, by input document and name.
+	names []map[string]string
+}
+
+func newBuilder(readers ...*model.PdfReader) *builder {
+	b := &builder{readers: readers}
+	for range readers {
+		b.copies = append(b.copies, map[core.PdfObject]*model.PdfPage{})
+		b.names = append(b.names, map[string]string{})
+	}
+	return b
+}
+
+// addPage copies a page of an input document, rotated clockwise by the angle.
+func (b *builder) addPage(doc int, pageNum int, angle int64) error {
+	page, err := b.readers[doc].GetPage(pageNum)
+	if err != nil {
+		return err
+	}
+
+	// The copy keeps the Parent of the page, for the attributes inherited from the page tree to be copied when added
+	// to the document.
+	dup := page.Duplicate()
+	if arr, ok := core.TraceToDirectObject(page.Contents).(*core.PdfObjectArray); ok {
+		// Content streams are appended to the array in place, e.g. the watermark of unlicensed copies.
+		dup.Contents = core.MakeArray(*arr...)
+	}
+	// Copied with the destinations rewritten when written (see copyAnnotations).
+	dup.Annotations = nil
+	if angle != 0 {
+		rotate := ((pageRotation(page)+angle)%360 + 360) % 360
+		dup.Rotate = &rotate
+	}
+
+	b.pages = append(b.pages, pageCopy{doc: doc, page: page, copy: dup})
+	b.copies[doc][page.GetPageAsIndirectObject()] = dup
+	return nil
+}
+
+// pageRotation returns the rotation of the page, inherited from the page tree if not set on the page.
+func pageRotation(page *model.PdfPage) int64 {
+	if page.Rotate != nil {
+		return *page.Rotate
+	}
+	if rotate, ok := core.TraceToDirectObject(inheritedAttribute(page, "Rotate")).(*core.PdfObjectInteger); ok {
+		return int64(*rotate)
+	}
+	return 0
+}
+
+// inheritedAttribute returns the value of an attribute of the page inherited from the page tree, nil if not set by
+// its ancestors.
+func inheritedAttribute(page *model.PdfPage, key core.PdfObjectName) core.PdfObject {
+	visited := map[core.PdfObject]bool{}
+	node := page.Parent
+	for node != nil && !visited[node] {
+		visited[node] = true
+		dict, ok := core.TraceToDirectObject(node).(*core.PdfObjectDictionary)
+		if !ok {
+			break
+		}
+		if obj := dict.Get(key); obj != nil {
+			return obj
+		}
+		node = dict.Get("Parent")
+	}
+	return nil
+}
+
+// write returns a writer with the copies of the pages, their annotations, the named destinations and the outlines.
+func (b *builder) write() (*model.PdfWriter, error) {
+	w := model.NewPdfWriter()
+
+	// The names are needed for rewriting the destinations of the annotations and outlines.
+	err := b.copyNamedDestinations(&w)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range b.pages {
+		err := b.copyAnnotations(c)
+		if err != nil {
+			return nil, err
+		}
+		err = w.AddPage(c.copy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outline, err := b.copyOutlines()
+	if err != nil {
+		return nil, err
+	}
+	w.SetOutline(outline)
+	return &w, nil
+}
+
+// copyNamedDestinations adds the named destinations to the copied pages to the writer.  The names defined by
+// several documents are suffixed with a number for the documents after the first.
+func (b *builder) copyNamedDestinations(w *model.PdfWriter) error {
+	used := map[string]bool{}
+	for doc, reader := range b.readers {
+		dests, err := reader.GetNamedDestinations()
+		if err != nil {
+			return err
+		}
+		names := []string{}
+		for name := range dests {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			dest, ok := b.mapDest(doc, dests[name])
+			if !ok {
+				common.Log.Trace("Named destination %s not in the document", name)
+				continue
+			}
+			newName := name
+			for n := 2; used[newName]; n++ {
+				newName = fmt.Sprintf("%s-%d", name, n)
+			}
+			used[newName] = true
+			b.names[doc][name] = newName
+			w.AddNamedDestination(newName, dest)
+		}
+	}
+	return nil
+}
+
+// mapDest returns the destination rewritten for the new document: explicit destinations to the copies of the
+// pages, and names to the names in the new document.  Returns false if the destination is not in the new document.
+func (b *builder) mapDest(doc int, dest core.PdfObject) (core.PdfObject, bool) {
+	switch t := core.TraceToDirectObject(dest).(type) {
+	case *core.PdfObjectArray:
+		if len(*t) == 0 {
+			return nil, false
+		}
+		if _, isPage := (*t)[0].(*core.PdfIndirectObject); !isPage {
+			// Page number, in the destinations of other documents.
+			return dest, true
+		}
+		page, ok := b.copies[doc][(*t)[0]]
+		if !ok {
+			return nil, false
+		}
+		arr := core.MakeArray(page.GetPageAsIndirectObject())
+		*arr = append(*arr, (*t)[1:]...)
+		return arr, true
+	case *core.PdfObjectName:
+		// Names of the Dests dictionary, which are written to the Dests name tree.
+		if name, ok := b.names[doc][string(*t)]; ok {
+			return core.MakeString(name), true
+		}
+	case *core.PdfObjectString:
+		if name, ok := b.names[doc][string(*t)]; ok {
+			return core.MakeString(name), true
+		}
+	}
+	return nil, false
+}
+
+// mapAction returns the action rewritten for the new document, a copy with its destination rewritten for GoTo
+// actions.  Returns false if the destination of a GoTo action is not in the new document.
+func (b *builder) mapAction(doc int, action core.PdfObject) (core.PdfObject, bool) {
+	dict, ok := core.TraceToDirectObject(action).(*core.PdfObjectDictionary)
+	if !ok {
+		return action, true
+	}
+	if s, ok := core.TraceToDirectObject(dict.Get("S")).(*core.PdfObjectName); !ok || *s != "GoTo" {
+		return action, true
+	}
+	dest, ok := b.mapDest(doc, dict.Get("D"))
+	if !ok {
+		return nil, false
+	}
+	copied := core.MakeDict()
+	copied.Merge(dict)
+	copied.Set("D", dest)
+	return copied, true
+}
+
+// copyAnnotations sets copies of the annotations of the page on its copy, with their destinations and page
+// rewritten.  The links to pages not in the new document are removed.
+func (b *builder) copyAnnotations(c pageCopy) error {
+	if c.page.Annotations == nil {
+		return nil
+	}
+
+	copies := map[core.PdfObject]*core.PdfIndirectObject{}
+	annots := core.MakeArray()
+	for _, annot := range c.page.Annotations {
+		var obj core.PdfObject
+		if ctx := annot.GetContext(); ctx != nil {
+			obj = ctx.ToPdfObject()
+		} else {
+			obj = annot.ToPdfObject()
+		}
+		ind, ok := obj.(*core.PdfIndirectObject)
+		if !ok {
+			common.Log.Debug("ERROR: Annotation not an indirect object (%T)", obj)
+			return model.ErrTypeError
+		}
+		dict, ok := ind.PdfObject.(*core.PdfObjectDictionary)
+		if !ok {
+			common.Log.Debug("ERROR: Annotation not a dictionary (%T)", ind.PdfObject)
+			return model.ErrTypeError
+		}
+
+		copied := core.MakeDict()
+		copied.Merge(dict)
+		if copied.Get("P") != nil {
+			copied.Set("P", c.copy.GetPageAsIndirectObject())
+		}
+		if c.matrix != nil {
+			c.matrix.transformAnnotation(copied)
+		}
+		removed := false
+		if dest := copied.Get("Dest"); dest != nil {
+			if mapped, ok := b.mapDest(c.doc, dest); ok {
+				copied.Set("Dest", mapped)
+			} else {
+				copied.Remove("Dest")
+				removed = true
+			}
+		}
+		if action := copied.Get("A"); action != nil {
+			if mapped, ok := b.mapAction(c.doc, action); ok {
+				copied.Set("A", mapped)
+			} else {
+				copied.Remove("A")
+				removed = true
+			}
+		}
+		if subtype, ok := copied.Get("Subtype").(*core.PdfObjectName); ok && *subtype == "Link" && removed {
+			common.Log.Trace("Link to a page not in the document removed")
+			continue
+		}
+
+		copiedObj := core.MakeIndirectObject(copied)
+		copies[ind] = copiedObj
+		annots.Append(copiedObj)
+	}
+
+	// References between the annotations of the page, e.g. pop-up annotations and their parent.  Form fields, the
+	// parents of widget annotations, are not copied.
+	for _, obj := range *annots {
+		dict := obj.(*core.PdfIndirectObject).PdfObject.(*core.PdfObjectDictionary)
+		for _, key := range []core.PdfObjectName{"Parent", "Popup", "IRT"} {
+			ref := dict.Get(key)
+			if ref == nil {
+				continue
+			}
+			if copiedObj, ok := copies[ref]; ok {
+				dict.Set(key, copiedObj)
+			} else if key != "Parent" {
+				dict.Remove(key)
+			}
+		}
+	}
+
+	c.copy.GetPageDict().Set("Annots", annots)
+	return nil
+}
+
+// copyOutlines returns the outline of the new document with the items of the outlines of the input documents.  The
+// items are kept if their destination is in the new document, if they have an action other than GoTo, or if some of
+// their children are kept.
+func (b *builder) copyOutlines() (*model.Outline, error) {
+	outline := model.NewOutline()
+	for doc, reader := range b.readers {
+		docOutline, err := reader.GetOutline()
+		if err != nil {
+			return nil, err
+		}
+		docOutline.MapDestinations(func(dest core.PdfObject) (core.PdfObject, bool) {
+			return b.mapDest(doc, dest)
+		})
+		outline.Items = append(outline.Items, docOutline.Items...)
+	}
+	return outline, nil
+}
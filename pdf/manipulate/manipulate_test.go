@@ -0,0 +1,217 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// makeTestPdf returns a document of 3 pages inheriting their media box and rotation from the page tree, except the
+// rotation of the last one, with links from the first page to the last one and from the second page to a named
+// destination to the first one, outline items to the first and last pages and named destinations to them.
+func makeTestPdf() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 8 0 R /Names << /Dests << /Names [(p1) [3 0 R /Fit] (p3) [5 0 R /Fit]] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 612 792] /Rotate 90 /Resources << >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Annots [7 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Annots [<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /Dest (p1) >>] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Rotate 0 >>",
+		"<< /Length 15 >>\nstream\n0 0 10 10 re f\nendstream",
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /P 3 0 R /Dest [5 0 R /Fit] >>",
+		"<< /Type /Outlines /First 9 0 R /Last 10 0 R /Count 2 >>",
+		"<< /Title (One) /Parent 8 0 R /Next 10 0 R /Dest [3 0 R /Fit] >>",
+		"<< /Title (Three) /Parent 8 0 R /Prev 9 0 R /A << /S /GoTo /D [5 0 R /Fit] >> >>",
+	}
+	return testutils.MakePdf(objects)
+}
+
+func newTestReader(t *testing.T) *model.PdfReader {
+	reader, err := model.NewPdfReader(bytes.NewReader(makeTestPdf()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return reader
+}
+
+// readBack writes the document and reads it.
+func readBack(t *testing.T, w *model.PdfWriter) *model.PdfReader {
+	var buf bytes.Buffer
+	err := w.Write(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return reader
+}
+
+// pageIndex returns the index of the page of an explicit destination, -1 if not a page of the document.
+func pageIndex(reader *model.PdfReader, dest core.PdfObject) int {
+	arr, ok := core.TraceToDirectObject(dest).(*core.PdfObjectArray)
+	if !ok || len(*arr) == 0 {
+		return -1
+	}
+	for i := range reader.PageList {
+		if (*arr)[0] == reader.PageList[i].GetPageAsIndirectObject() {
+			return i
+		}
+	}
+	return -1
+}
+
+// describe returns the rotation and links of the pages, the outline items and the named destinations of the
+// document, with the pages by index.
+func describe(t *testing.T, reader *model.PdfReader) string {
+	dests, err := reader.GetNamedDestinations()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	names := []string{}
+	for name, dest := range dests {
+		names = append(names, fmt.Sprintf("%s:%d", name, pageIndex(reader, dest)))
+	}
+	sort.Strings(names)
+
+	pages := []string{}
+	for _, page := range reader.PageList {
+		mediaBox, err := page.GetMediaBox()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		str := fmt.Sprintf("%.0fx%.0f", mediaBox.Urx, mediaBox.Ury)
+		if page.Rotate != nil {
+			str += fmt.Sprintf(" rotate %d", *page.Rotate)
+		}
+		for _, annot := range page.Annotations {
+			link, ok := annot.GetContext().(*model.PdfAnnotationLink)
+			if !ok {
+				continue
+			}
+			switch t := core.TraceToDirectObject(link.Dest).(type) {
+			case *core.PdfObjectString:
+				str += fmt.Sprintf(" link %s", string(*t))
+			default:
+				str += fmt.Sprintf(" link %d", pageIndex(reader, link.Dest))
+			}
+		}
+		pages = append(pages, str)
+	}
+
+	data, err := reader.GetOutlinesJSON()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	items := []*model.PdfOutlineJSONItem{}
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	outlines := []string{}
+	for _, item := range items {
+		outlines = append(outlines, fmt.Sprintf("%s:%d", item.Title, item.Page))
+	}
+
+	return fmt.Sprintf("pages %q outlines %v names %v", pages, outlines, names)
+}
+
+func TestSplit(t *testing.T) {
+	writers, err := Split(newTestReader(t), []PageRange{{1, 2}, {3, 3}})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(writers) != 2 {
+		t.Fatalf("Wrong number of documents %d", len(writers))
+	}
+
+	expected := []string{
+		// The link to the third page is removed.
+		`pages ["612x792 rotate 90" "612x792 rotate 90 link p1"] outlines [One:0] names [p1:0]`,
+		`pages ["612x792 rotate 0"] outlines [Three:0] names [p3:0]`,
+	}
+	for i, w := range writers {
+		if str := describe(t, readBack(t, w)); str != expected[i] {
+			t.Errorf("Wrong document %d: %s", i+1, str)
+		}
+	}
+
+	_, err = Split(newTestReader(t), []PageRange{{2, 4}})
+	if err == nil {
+		t.Errorf("Range out of the document should fail")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	w, err := Merge(newTestReader(t), newTestReader(t))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := `pages ["612x792 rotate 90 link 2" "612x792 rotate 90 link p1" "612x792 rotate 0" ` +
+		`"612x792 rotate 90 link 5" "612x792 rotate 90 link p1-2" "612x792 rotate 0"] ` +
+		`outlines [One:0 Three:2 One:3 Three:5] names [p1-2:3 p1:0 p3-2:5 p3:2]`
+	if str := describe(t, readBack(t, w)); str != expected {
+		t.Errorf("Wrong document: %s", str)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	w, err := Reorder(newTestReader(t), []int{3, 1, 2})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := `pages ["612x792 rotate 0" "612x792 rotate 90 link 0" "612x792 rotate 90 link p1"] ` +
+		`outlines [One:1 Three:0] names [p1:1 p3:0]`
+	if str := describe(t, readBack(t, w)); str != expected {
+		t.Errorf("Wrong document: %s", str)
+	}
+
+	_, err = Reorder(newTestReader(t), []int{1, 1, 2})
+	if err == nil {
+		t.Errorf("Page repeated in the permutation should fail")
+	}
+}
+
+func TestRotatePages(t *testing.T) {
+	w, err := RotatePages(newTestReader(t), -90, []PageRange{{2, 3}})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := `pages ["612x792 rotate 90 link 2" "612x792 rotate 0 link p1" "612x792 rotate 270"] ` +
+		`outlines [One:0 Three:2] names [p1:0 p3:2]`
+	if str := describe(t, readBack(t, w)); str != expected {
+		t.Errorf("Wrong document: %s", str)
+	}
+
+	_, err = RotatePages(newTestReader(t), 45, nil)
+	if err == nil {
+		t.Errorf("Rotation by 45 degrees should fail")
+	}
+}
+
+func TestDeletePages(t *testing.T) {
+	w, err := DeletePages(newTestReader(t), []PageRange{{1, 1}})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The link to the named destination of the deleted page is removed.
+	expected := `pages ["612x792 rotate 90" "612x792 rotate 0"] outlines [Three:1] names [p3:1]`
+	if str := describe(t, readBack(t, w)); str != expected {
+		t.Errorf("Wrong document: %s", str)
+	}
+}
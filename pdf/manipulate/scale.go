@@ -0,0 +1,164 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ScalePages copies the document with the pages scaled to the paper size of width by height points, e.g. 595 by
+// 842 for A4.  The content is scaled keeping its aspect ratio and centered, and the pages keep their orientation:
+// the paper size is swapped for the pages wider than high, accounting for their rotation (Rotate).  The media box
+// is set to the paper size, and the other boxes of the pages and the rectangles and points of the annotations are
+// transformed with the content.
+func ScalePages(reader *model.PdfReader, width, height float64) (*model.PdfWriter, error) {
+	if width <= 0 || height <= 0 {
+		common.Log.Debug("ERROR: Invalid paper size %fx%f", width, height)
+		return nil, errors.New("Invalid paper size")
+	}
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBuilder(reader)
+	for pageNum := 1; pageNum <= numPages; pageNum++ {
+		err := b.addPage(0, pageNum, 0)
+		if err != nil {
+			return nil, err
+		}
+		err = scalePage(&b.pages[len(b.pages)-1], width, height)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.write()
+}
+
+// scalePage scales the copy of the page to the paper size, wrapping its content streams in a transformation.
+func scalePage(c *pageCopy, width, height float64) error {
+	mediaBox, err := c.page.GetMediaBox()
+	if err != nil {
+		return err
+	}
+	w, h := mediaBox.Urx-mediaBox.Llx, mediaBox.Ury-mediaBox.Lly
+	if w <= 0 || h <= 0 {
+		common.Log.Debug("ERROR: Invalid page size %fx%f", w, h)
+		return errors.New("Invalid page size")
+	}
+
+	// The pages are rotated after the transformation, so that the paper size has the orientation of the media box
+	// in the user space for the pages to keep their orientation as displayed.
+	if (w > h) != (width > height) {
+		width, height = height, width
+	}
+	scale := math.Min(width/w, height/h)
+	m := &matrix{scale, 0, 0, scale, (width-scale*w)/2 - scale*mediaBox.Llx, (height-scale*h)/2 - scale*mediaBox.Lly}
+	c.matrix = m
+
+	page := c.copy
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: width, Ury: height}
+	if page.CropBox == nil {
+		// Not inherited from the page tree when added to the document, as not scaled.
+		if arr, ok := core.TraceToDirectObject(inheritedAttribute(c.page, "CropBox")).(*core.PdfObjectArray); ok {
+			page.CropBox, err = model.NewPdfRectangle(*arr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, box := range []**model.PdfRectangle{&page.CropBox, &page.BleedBox, &page.TrimBox, &page.ArtBox} {
+		if *box != nil {
+			*box = m.transformRect(*box)
+		}
+	}
+
+	prefix, err := core.MakeStream(contentstream.NewContentCreator().Add_q().Add_cm(m[0], m[1], m[2], m[3], m[4],
+		m[5]).Bytes(), nil)
+	if err != nil {
+		return err
+	}
+	suffix, err := core.MakeStream(contentstream.NewContentCreator().Add_Q().Bytes(), nil)
+	if err != nil {
+		return err
+	}
+	contents := core.MakeArray(prefix)
+	if arr, ok := core.TraceToDirectObject(page.Contents).(*core.PdfObjectArray); ok {
+		*contents = append(*contents, *arr...)
+	} else if page.Contents != nil {
+		contents.Append(page.Contents)
+	}
+	contents.Append(suffix)
+	page.Contents = contents
+	return nil
+}
+
+// matrix is a transformation matrix [a b c d e f] (8.3.4 Transformation Matrices).
+type matrix [6]float64
+
+// transform returns the point transformed by the matrix.
+func (m *matrix) transform(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// transformRect returns the rectangle transformed by the matrix, normalized.
+func (m *matrix) transformRect(r *model.PdfRectangle) *model.PdfRectangle {
+	x1, y1 := m.transform(r.Llx, r.Lly)
+	x2, y2 := m.transform(r.Urx, r.Ury)
+	return &model.PdfRectangle{Llx: math.Min(x1, x2), Lly: math.Min(y1, y2), Urx: math.Max(x1, x2),
+		Ury: math.Max(y1, y2)}
+}
+
+// transformPoints returns the array of point coordinates (x1 y1 x2 y2 ...) transformed by the matrix, false if not
+// an array of numbers.
+func (m *matrix) transformPoints(obj core.PdfObject) (*core.PdfObjectArray, bool) {
+	arr, ok := core.TraceToDirectObject(obj).(*core.PdfObjectArray)
+	if !ok || len(*arr)%2 != 0 {
+		return nil, false
+	}
+	values, err := arr.ToFloat64Array()
+	if err != nil {
+		return nil, false
+	}
+	for i := 0; i+1 < len(values); i += 2 {
+		values[i], values[i+1] = m.transform(values[i], values[i+1])
+	}
+	return core.MakeArrayFromFloats(values), true
+}
+
+// transformAnnotation transforms the rectangle and the points of the annotation dictionary by the matrix, setting
+// new arrays.
+func (m *matrix) transformAnnotation(dict *core.PdfObjectDictionary) {
+	if arr, ok := core.TraceToDirectObject(dict.Get("Rect")).(*core.PdfObjectArray); ok {
+		if rect, err := model.NewPdfRectangle(*arr); err == nil {
+			dict.Set("Rect", m.transformRect(rect).ToPdfObject())
+		}
+	}
+	// Quadrilaterals (link and text markup annotations), vertices (polygons), line and callout line end points.
+	for _, key := range []core.PdfObjectName{"QuadPoints", "Vertices", "L", "CL"} {
+		if arr, ok := m.transformPoints(dict.Get(key)); ok {
+			dict.Set(key, arr)
+		}
+	}
+	// Paths of the ink annotations.
+	if inkList, ok := core.TraceToDirectObject(dict.Get("InkList")).(*core.PdfObjectArray); ok {
+		paths := core.MakeArray()
+		for _, path := range *inkList {
+			arr, ok := m.transformPoints(path)
+			if !ok {
+				return
+			}
+			paths.Append(arr)
+		}
+		dict.Set("InkList", paths)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package manipulate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// makeScaleTestPdf returns a document of letter size pages: in portrait orientation with an inherited crop box and
+// a link, in landscape orientation, and in portrait orientation rotated to landscape.
+func makeScaleTestPdf() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 612 792] /CropBox [10 10 602 782] /Resources << >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Annots [<< /Type /Annot /Subtype /Link /Rect [0 0 612 792] /QuadPoints [0 0 612 0 612 792 0 792] /Dest [5 0 R /Fit] >>] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 792 612] /CropBox [0 0 792 612] /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Rotate 90 /Contents 6 0 R >>",
+		"<< /Length 15 >>\nstream\n0 0 10 10 re f\nendstream",
+	}
+	return testutils.MakePdf(objects)
+}
+
+// formatRect returns the coordinates of the rectangle rounded to 0.01.
+func formatRect(r *model.PdfRectangle) string {
+	if r == nil {
+		return "nil"
+	}
+	round := func(v float64) float64 {
+		return math.Floor(v*100+0.5) / 100
+	}
+	return fmt.Sprintf("[%g %g %g %g]", round(r.Llx), round(r.Lly), round(r.Urx), round(r.Ury))
+}
+
+func TestScalePages(t *testing.T) {
+	reader, err := model.NewPdfReader(bytes.NewReader(makeScaleTestPdf()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w, err := ScalePages(reader, 595, 842)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader = readBack(t, w)
+	if len(reader.PageList) != 3 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+
+	// Scaled by 595/612, and centered in the other direction.
+	expected := []string{
+		"media [0 0 595 842] crop [9.72 45.72 585.28 796.28] rotate 0",
+		"media [0 0 842 595] crop [36 0 806 595] rotate 0",
+		"media [0 0 595 842] crop [9.72 45.72 585.28 796.28] rotate 90",
+	}
+	for i, page := range reader.PageList {
+		rotate := int64(0)
+		if page.Rotate != nil {
+			rotate = *page.Rotate
+		}
+		str := fmt.Sprintf("media %s crop %s rotate %d", formatRect(page.MediaBox), formatRect(page.CropBox), rotate)
+		if str != expected[i] {
+			t.Errorf("Wrong page %d: %s", i+1, str)
+		}
+
+		content, err := page.GetAllContentStreams()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if !strings.HasPrefix(content, "q\n0.972222 0.000000 0.000000 0.972222 ") {
+			t.Errorf("Wrong content of page %d: %q", i+1, content)
+		}
+	}
+
+	// The link is transformed and still points at the third page.
+	if len(reader.PageList[0].Annotations) != 1 {
+		t.Fatalf("Wrong annotations %v", reader.PageList[0].Annotations)
+	}
+	link, ok := reader.PageList[0].Annotations[0].GetContext().(*model.PdfAnnotationLink)
+	if !ok {
+		t.Fatalf("Not a link %v", reader.PageList[0].Annotations[0])
+	}
+	rect, err := model.NewPdfRectangle(*core.TraceToDirectObject(link.Rect).(*core.PdfObjectArray))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if str := formatRect(rect); str != "[0 36 595 806]" {
+		t.Errorf("Wrong link rectangle %s", str)
+	}
+	points, err := core.TraceToDirectObject(link.QuadPoints).(*core.PdfObjectArray).ToFloat64Array()
+	if err != nil || len(points) != 8 || math.Abs(points[5]-806) > 0.01 {
+		t.Errorf("Wrong link points %v", link.QuadPoints)
+	}
+	if pageIndex(reader, link.Dest) != 2 {
+		t.Errorf("Wrong link destination %v", link.Dest)
+	}
+
+	_, err = ScalePages(reader, 0, 842)
+	if err == nil {
+		t.Errorf("Invalid paper size should fail")
+	}
+}
@@ -0,0 +1,471 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package mdconv
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of blocks.
+const (
+	blockParagraph = iota
+	blockHeading
+	blockCode
+	blockQuote
+	blockList
+	blockItem
+	blockRule
+	blockTable
+	blockHTML
+)
+
+// block is a block of the document structure.
+type block struct {
+	kind int
+
+	// The inline content of paragraphs and headings, or the literal content of code and HTML blocks.
+	text string
+
+	// The level of headings, and the info string of fenced code blocks.
+	level int
+	info  string
+
+	// The blocks in block quotes, lists and list items.
+	children []*block
+
+	// Lists: whether ordered with the start number, and whether tight (items without paragraphs).
+	ordered bool
+	start   int
+	tight   bool
+
+	// Tables: the cells of the rows, the first being the header row, and the alignments of the columns.
+	rows  [][]string
+	align []string
+}
+
+// linkRef is a link reference definition.
+type linkRef struct {
+	dest, title string
+}
+
+// blockParser parses the block structure of a document, collecting its link reference definitions.
+type blockParser struct {
+	refs map[string]linkRef
+}
+
+var (
+	atxHeadingRe = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematicRe   = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextRe     = regexp.MustCompile(`^(=+|-+)[ \t]*$`)
+	fenceRe      = regexp.MustCompile("^(`{3,}|~{3,})[ \t]*(.*)$")
+	bulletRe     = regexp.MustCompile(`^([-+*])( +|$)`)
+	orderedRe    = regexp.MustCompile(`^([0-9]{1,9})([.)])( +|$)`)
+	htmlBlockRe  = regexp.MustCompile(`^<(?:[A-Za-z/!?])`)
+	tableDelimRe = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	linkRefDefRe = regexp.MustCompile(`^\[((?:[^\[\]\\]|\\.)+)\]:[ \t]*(?:<([^<>\n]*)>|(\S+))(?:\s+(?:"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'|\(((?:[^()\\]|\\.)*)\)))?[ \t]*(?:\n|$)`)
+	blankLineRe  = regexp.MustCompile(`^[ \t]*$`)
+	whitespaceRe = regexp.MustCompile(`\s+`)
+)
+
+// splitLines splits the document into lines, with the tabs expanded to the tab stops of 4 columns.
+func splitLines(data string) []string {
+	data = strings.Replace(data, "\r\n", "\n", -1)
+	data = strings.Replace(data, "\r", "\n", -1)
+	lines := strings.Split(data, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "\t") {
+			continue
+		}
+		var b strings.Builder
+		col := 0
+		for _, r := range line {
+			if r == '\t' {
+				n := 4 - col%4
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			b.WriteRune(r)
+			col++
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// isBlank returns whether the line is empty or whitespace.
+func isBlank(line string) bool {
+	return blankLineRe.MatchString(line)
+}
+
+// indentation returns the number of leading spaces of the line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// stripIndent removes up to n leading spaces of the line.
+func stripIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+// listMarker is the marker of a list item.
+type listMarker struct {
+	ordered bool
+	// The bullet character, or the delimiter of ordered items ('.' or ')').
+	char  byte
+	start int
+	// The indentation of the content of the item.
+	contentIndent int
+	empty         bool
+}
+
+// parseListMarker parses the list item marker starting the line, if any.
+func parseListMarker(line string) (listMarker, bool) {
+	indent := indentation(line)
+	if indent > 3 {
+		return listMarker{}, false
+	}
+	rest := line[indent:]
+	var m listMarker
+	var markerLen, spaces int
+	if match := bulletRe.FindStringSubmatch(rest); match != nil {
+		m.char = match[1][0]
+		markerLen, spaces = 1, len(match[2])
+	} else if match := orderedRe.FindStringSubmatch(rest); match != nil {
+		m.ordered = true
+		m.start, _ = strconv.Atoi(match[1])
+		m.char = match[2][0]
+		markerLen, spaces = len(match[1])+1, len(match[3])
+	} else {
+		return listMarker{}, false
+	}
+	m.empty = isBlank(rest[markerLen:])
+	if m.empty || spaces > 4 {
+		// Indented code in the item, or an empty item, starts after a single space.
+		spaces = 1
+	}
+	m.contentIndent = indent + markerLen + spaces
+	return m, true
+}
+
+// interruptsParagraph returns whether the line starts a block interrupting a paragraph.
+func interruptsParagraph(line string) bool {
+	if indentation(line) > 3 {
+		return false
+	}
+	rest := strings.TrimLeft(line, " ")
+	if atxHeadingRe.MatchString(rest) || thematicRe.MatchString(rest) || fenceRe.MatchString(rest) ||
+		strings.HasPrefix(rest, ">") || htmlBlockRe.MatchString(rest) {
+		return true
+	}
+	// Only non-empty bullet items and ordered items starting at 1.
+	if m, ok := parseListMarker(line); ok && !m.empty && (!m.ordered || m.start == 1) {
+		return true
+	}
+	return false
+}
+
+// parse parses the lines into blocks.
+func (p *blockParser) parse(lines []string) []*block {
+	var blocks []*block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+
+		indent := indentation(line)
+		if indent >= 4 {
+			// Indented code, up to the last indented line.
+			var code []string
+			end := i
+			for j := i; j < len(lines) && (isBlank(lines[j]) || indentation(lines[j]) >= 4); j++ {
+				code = append(code, stripIndent(lines[j], 4))
+				if !isBlank(lines[j]) {
+					end = j + 1
+				}
+			}
+			code = code[:end-i]
+			blocks = append(blocks, &block{kind: blockCode, text: strings.Join(code, "\n") + "\n"})
+			i = end
+			continue
+		}
+
+		rest := line[indent:]
+		if match := fenceRe.FindStringSubmatch(rest); match != nil &&
+			!(match[1][0] == '`' && strings.Contains(match[2], "`")) {
+			fence := match[1]
+			var code []string
+			i++
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				if indentation(l) <= 3 && strings.HasPrefix(strings.TrimLeft(l, " "), fence) &&
+					strings.Trim(strings.TrimLeft(l, " "), string(fence[0])+" ") == "" {
+					i++
+					break
+				}
+				code = append(code, stripIndent(l, indent))
+			}
+			text := strings.Join(code, "\n")
+			if len(code) > 0 {
+				text += "\n"
+			}
+			info := strings.Fields(match[2])
+			b := &block{kind: blockCode, text: text}
+			if len(info) > 0 {
+				b.info = info[0]
+			}
+			blocks = append(blocks, b)
+			continue
+		}
+
+		if match := atxHeadingRe.FindStringSubmatch(rest); match != nil {
+			blocks = append(blocks, &block{kind: blockHeading, level: len(match[1]), text: match[2]})
+			i++
+			continue
+		}
+
+		if thematicRe.MatchString(rest) {
+			blocks = append(blocks, &block{kind: blockRule})
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(rest, ">") {
+			// The lines with the marker, and the lazy continuation lines of paragraphs.
+			var quoted []string
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				l := strings.TrimLeft(lines[i], " ")
+				if indentation(lines[i]) <= 3 && strings.HasPrefix(l, ">") {
+					l = strings.TrimPrefix(l[1:], " ")
+				} else if len(quoted) > 0 && interruptsParagraph(lines[i]) {
+					break
+				}
+				quoted = append(quoted, l)
+			}
+			blocks = append(blocks, &block{kind: blockQuote, children: p.parse(quoted)})
+			continue
+		}
+
+		if m, ok := parseListMarker(line); ok {
+			var list *block
+			list, i = p.parseList(lines, i, m)
+			blocks = append(blocks, list)
+			continue
+		}
+
+		if htmlBlockRe.MatchString(rest) {
+			var html []string
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				html = append(html, lines[i])
+			}
+			blocks = append(blocks, &block{kind: blockHTML, text: strings.Join(html, "\n")})
+			continue
+		}
+
+		if strings.Contains(line, "|") && i+1 < len(lines) && tableDelimRe.MatchString(lines[i+1]) {
+			header := splitTableRow(line)
+			delims := splitTableRow(lines[i+1])
+			if len(header) == len(delims) {
+				var table *block
+				table, i = parseTable(lines, i, header, delims)
+				blocks = append(blocks, table)
+				continue
+			}
+		}
+
+		// A paragraph, up to a blank line or a block interrupting it, or a setext heading.
+		para := []string{strings.TrimLeft(line, " ")}
+		level := 0
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			if isBlank(l) {
+				break
+			}
+			if match := setextRe.FindStringSubmatch(strings.TrimLeft(l, " ")); match != nil && indentation(l) <= 3 {
+				level = 1
+				if match[1][0] == '-' {
+					level = 2
+				}
+				i++
+				break
+			}
+			if interruptsParagraph(l) {
+				break
+			}
+			para = append(para, strings.TrimLeft(l, " "))
+		}
+
+		text := p.parseLinkRefDefs(strings.Join(para, "\n"))
+		switch {
+		case level > 0 && text != "":
+			blocks = append(blocks, &block{kind: blockHeading, level: level, text: text})
+		case level == 2:
+			// Only link reference definitions before the line: a thematic break.
+			blocks = append(blocks, &block{kind: blockRule})
+		case text != "":
+			blocks = append(blocks, &block{kind: blockParagraph, text: text})
+		}
+	}
+	return blocks
+}
+
+// parseList parses the list starting with the item with the marker at the line, and returns it with the index of
+// the line following it.
+func (p *blockParser) parseList(lines []string, i int, m listMarker) (*block, int) {
+	list := &block{kind: blockList, ordered: m.ordered, start: m.start, tight: true}
+	for {
+		// The first line of the item, and the following lines indented to its content or lazy continuations.
+		var itemLines []string
+		if !m.empty {
+			itemLines = append(itemLines, lines[i][m.contentIndent:])
+		}
+		lastBlank := m.empty
+		i++
+		for ; i < len(lines); i++ {
+			l := lines[i]
+			if isBlank(l) {
+				if len(itemLines) == 0 {
+					// An item can begin with at most one blank line.
+					break
+				}
+				itemLines = append(itemLines, "")
+				lastBlank = true
+				continue
+			}
+			if indentation(l) >= m.contentIndent {
+				itemLines = append(itemLines, stripIndent(l, m.contentIndent))
+				lastBlank = false
+				continue
+			}
+			if !lastBlank && !interruptsParagraph(l) && !thematicRe.MatchString(strings.TrimLeft(l, " ")) {
+				if _, isItem := parseListMarker(l); !isItem {
+					itemLines = append(itemLines, strings.TrimLeft(l, " "))
+					continue
+				}
+			}
+			break
+		}
+
+		// Trailing blank lines are between the items, or after the list.
+		end := len(itemLines)
+		for end > 0 && itemLines[end-1] == "" {
+			end--
+		}
+		blankAfter := end < len(itemLines)
+		for _, l := range itemLines[:end] {
+			if l == "" {
+				// Blank line between the blocks of the item.
+				list.tight = false
+			}
+		}
+		list.children = append(list.children, &block{kind: blockItem, children: p.parse(itemLines[:end])})
+
+		if i >= len(lines) {
+			break
+		}
+		next, ok := parseListMarker(lines[i])
+		if !ok || next.ordered != m.ordered || next.char != m.char || thematicRe.MatchString(strings.TrimLeft(lines[i], " ")) {
+			break
+		}
+		if blankAfter {
+			list.tight = false
+		}
+		m = next
+	}
+	return list, i
+}
+
+// parseTable parses the table with the header row at the line, and returns it with the index of the line following
+// it.
+func parseTable(lines []string, i int, header, delims []string) (*block, int) {
+	table := &block{kind: blockTable, rows: [][]string{header}}
+	for _, d := range delims {
+		d = strings.TrimSpace(d)
+		switch {
+		case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+			table.align = append(table.align, "center")
+		case strings.HasSuffix(d, ":"):
+			table.align = append(table.align, "right")
+		case strings.HasPrefix(d, ":"):
+			table.align = append(table.align, "left")
+		default:
+			table.align = append(table.align, "")
+		}
+	}
+
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && !interruptsParagraph(lines[i]); i++ {
+		row := splitTableRow(lines[i])
+		// Rows are cut or padded to the number of columns.
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		table.rows = append(table.rows, row[:len(header)])
+	}
+	return table, i
+}
+
+// splitTableRow splits the row of a table into its cells, at the pipes not escaped.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseLinkRefDefs collects the link reference definitions at the start of the paragraph, and returns the rest of
+// the paragraph.
+func (p *blockParser) parseLinkRefDefs(text string) string {
+	for {
+		match := linkRefDefRe.FindStringSubmatchIndex(text)
+		if match == nil {
+			return text
+		}
+		group := func(n int) string {
+			if match[2*n] < 0 {
+				return ""
+			}
+			return text[match[2*n]:match[2*n+1]]
+		}
+		label := normalizeLabel(group(1))
+		if _, has := p.refs[label]; !has && label != "" {
+			// The first definition of a label has precedence.
+			dest := group(2) + group(3)
+			title := group(4) + group(5) + group(6)
+			p.refs[label] = linkRef{dest: unescape(dest), title: unescape(title)}
+		}
+		text = text[match[1]:]
+	}
+}
+
+// normalizeLabel normalizes a link label for matching: case folded, with the whitespace collapsed.
+func normalizeLabel(label string) string {
+	return strings.ToLower(whitespaceRe.ReplaceAllString(strings.TrimSpace(label), " "))
+}
@@ -0,0 +1,20 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+//
+// Package mdconv converts Markdown (CommonMark) documents to creator drawables, laid out with the paragraph, list,
+// table and image components by way of the htmlconv package.
+//
+// The CommonMark blocks are supported (ATX and setext headings, paragraphs, block quotes, ordered and bullet
+// lists, indented and fenced code blocks, thematic breaks, HTML blocks and link reference definitions), as well as
+// the GitHub pipe tables.  The inlines are supported as well: emphasis, strong emphasis, strikethrough, code spans,
+// links, images, autolinks, raw HTML, entities, escapes and hard line breaks.  The code blocks and code spans are
+// drawn in a monospace font, and the headings are included in the document outline.
+//
+//   c := creator.New()
+//   c.NewPage()
+//   err := mdconv.Draw(c, []byte("# Report\n\nTotal: **42**\n"), mdconv.DefaultOptions())
+//
+package mdconv
@@ -0,0 +1,414 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package mdconv
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	entityRe     = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	autolinkRe   = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\s]*)>`)
+	emailRe      = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)*)>`)
+	inlineHTMLRe = regexp.MustCompile(`^(?:</?[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|<!--[\s\S]*?-->)`)
+	linkTitleRe  = regexp.MustCompile(`^\s*(?:"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'|\(((?:[^()\\]|\\.)*)\))\s*\)`)
+	asciiPunctRe = regexp.MustCompile(`\\([!-/:-@\[-` + "`" + `{-~])`)
+)
+
+// inlineNode is an element of inline content being converted: HTML, or a run of emphasis delimiters.
+type inlineNode struct {
+	html string
+
+	// The delimiter character of runs (*, _ or ~), the number of delimiters left and originally in the run, and
+	// whether the run can open or close emphasis.
+	delim               byte
+	count, origCount    int
+	canOpen, canClose   bool
+	openTags, closeTags string
+}
+
+// inlineParser converts inline content to HTML.
+type inlineParser struct {
+	refs map[string]linkRef
+}
+
+// render converts the inline content to HTML.
+func (p *inlineParser) render(text string) string {
+	text = strings.TrimSpace(text)
+	var nodes []*inlineNode
+	var buf strings.Builder
+	flushText := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, &inlineNode{html: buf.String()})
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			// Hard line break.
+			buf.WriteString("<br>\n")
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			buf.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '\n':
+			// Hard line break after two spaces, soft line break otherwise.
+			trimmed := strings.TrimRight(buf.String(), " ")
+			buf.Reset()
+			buf.WriteString(trimmed)
+			if strings.HasSuffix(text[:i], "  ") {
+				buf.WriteString("<br>")
+			}
+			buf.WriteByte('\n')
+			i++
+			for i < len(text) && text[i] == ' ' {
+				i++
+			}
+			continue
+		case c == '`':
+			n := runLength(text, i, '`')
+			if end := findCodeSpanEnd(text, i+n, n); end >= 0 {
+				code := strings.Replace(text[i+n:end], "\n", " ", -1)
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+					code = code[1 : len(code)-1]
+				}
+				buf.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i = end + n
+			} else {
+				buf.WriteString(text[i : i+n])
+				i += n
+			}
+			continue
+		case c == '<':
+			if m := autolinkRe.FindStringSubmatch(text[i:]); m != nil {
+				buf.WriteString(`<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if m := emailRe.FindStringSubmatch(text[i:]); m != nil {
+				buf.WriteString(`<a href="mailto:` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if m := inlineHTMLRe.FindString(text[i:]); m != "" {
+				buf.WriteString(m)
+				i += len(m)
+				continue
+			}
+		case c == '&':
+			if m := entityRe.FindString(text[i:]); m != "" {
+				buf.WriteString(m)
+				i += len(m)
+				continue
+			}
+		case c == '[' || (c == '!' && i+1 < len(text) && text[i+1] == '['):
+			if link, n := p.parseLink(text[i:]); n > 0 {
+				buf.WriteString(link)
+				i += n
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(text, i, c)
+			flushText()
+			nodes = append(nodes, newDelimRun(text, i, n))
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		buf.WriteString(html.EscapeString(string(r)))
+		i += size
+	}
+	flushText()
+
+	processEmphasis(nodes)
+	var out strings.Builder
+	for _, n := range nodes {
+		if n.delim == 0 {
+			out.WriteString(n.html)
+			continue
+		}
+		// The delimiters matched by closers are their first ones, and those matched by openers their last ones.
+		out.WriteString(n.closeTags)
+		out.WriteString(strings.Repeat(string(n.delim), n.count))
+		out.WriteString(n.openTags)
+	}
+	return out.String()
+}
+
+// runLength returns the number of consecutive characters c from i.
+func runLength(text string, i int, c byte) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// findCodeSpanEnd returns the index of the run of n backticks closing a code span from i, or -1.
+func findCodeSpanEnd(text string, i, n int) int {
+	for i < len(text) {
+		j := strings.IndexByte(text[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		j += i
+		m := runLength(text, j, '`')
+		if m == n {
+			return j
+		}
+		i = j + m
+	}
+	return -1
+}
+
+// isASCIIPunct returns whether the character is ASCII punctuation, which can be escaped.
+func isASCIIPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
+
+// newDelimRun returns the run of n delimiters at i, and whether it can open or close emphasis, by whether it is
+// left or right flanking.
+func newDelimRun(text string, i, n int) *inlineNode {
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(text[:i])
+	}
+	if i+n < len(text) {
+		after, _ = utf8.DecodeRuneInString(text[i+n:])
+	}
+	isPunct := func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}
+	left := !unicode.IsSpace(after) && (!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	right := !unicode.IsSpace(before) && (!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	c := text[i]
+	node := &inlineNode{delim: c, count: n, origCount: n, canOpen: left, canClose: right}
+	if c == '_' {
+		// Intraword underscores are not emphasis.
+		node.canOpen = left && (!right || isPunct(before))
+		node.canClose = right && (!left || isPunct(after))
+	}
+	return node
+}
+
+// processEmphasis matches the delimiter runs, closers with the nearest openers of the same character, into
+// emphasis (em, strong, del).  The tags are added to the runs, and the delimiters matched removed from them.
+func processEmphasis(nodes []*inlineNode) {
+	for ci, closer := range nodes {
+		for closer.delim != 0 && closer.canClose && closer.count > 0 {
+			oi := -1
+			for j := ci - 1; j >= 0; j-- {
+				o := nodes[j]
+				if o.delim != closer.delim || !o.canOpen || o.count == 0 {
+					continue
+				}
+				// A run which can both open and close does not match when the sum of the lengths is a multiple of 3.
+				if (o.canClose || closer.canOpen) && (o.origCount+closer.origCount)%3 == 0 &&
+					!(o.origCount%3 == 0 && closer.origCount%3 == 0) {
+					continue
+				}
+				oi = j
+				break
+			}
+			if oi < 0 {
+				break
+			}
+			opener := nodes[oi]
+
+			n := 1
+			if opener.count >= 2 && closer.count >= 2 {
+				n = 2
+			}
+			tag := "em"
+			switch {
+			case closer.delim == '~':
+				tag = "del"
+			case n == 2:
+				tag = "strong"
+			}
+			opener.count -= n
+			closer.count -= n
+			opener.openTags = "<" + tag + ">" + opener.openTags
+			closer.closeTags += "</" + tag + ">"
+
+			// The runs in between are not emphasis.
+			for j := oi + 1; j < ci; j++ {
+				nodes[j].canOpen = false
+				nodes[j].canClose = false
+			}
+		}
+	}
+}
+
+// parseLink parses the inline link or image ([text](dest "title"), or a reference to a definition) at the start
+// of the text, and returns its HTML with the length parsed, 0 if none.
+func (p *inlineParser) parseLink(text string) (string, int) {
+	image := text[0] == '!'
+	start := 1
+	if image {
+		start = 2
+	}
+
+	// The closing bracket, not escaped nor in code spans.
+	depth := 1
+	end := -1
+	for i := start; i < len(text) && end < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '`':
+			n := runLength(text, i, '`')
+			if e := findCodeSpanEnd(text, i+n, n); e >= 0 {
+				i = e + n - 1
+			} else {
+				i += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return "", 0
+	}
+	label := text[start:end]
+	rest := text[end+1:]
+
+	var dest, title string
+	n := 0
+	switch {
+	case strings.HasPrefix(rest, "("):
+		var ok bool
+		dest, title, n, ok = parseLinkDest(rest)
+		if !ok {
+			return "", 0
+		}
+	case strings.HasPrefix(rest, "["):
+		close := strings.IndexByte(rest, ']')
+		if close < 0 {
+			return "", 0
+		}
+		refLabel := rest[1:close]
+		if refLabel == "" {
+			// Collapsed reference.
+			refLabel = label
+		}
+		ref, has := p.refs[normalizeLabel(refLabel)]
+		if !has {
+			return "", 0
+		}
+		dest, title, n = ref.dest, ref.title, close+1
+	default:
+		// Shortcut reference.
+		ref, has := p.refs[normalizeLabel(label)]
+		if !has {
+			return "", 0
+		}
+		dest, title = ref.dest, ref.title
+	}
+
+	attrTitle := ""
+	if title != "" {
+		attrTitle = ` title="` + html.EscapeString(title) + `"`
+	}
+	length := end + 1 + n
+	if image {
+		return `<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(label)) + `"` +
+			attrTitle + `>`, length
+	}
+	return `<a href="` + html.EscapeString(dest) + `"` + attrTitle + `>` + p.render(label) + `</a>`, length
+}
+
+// parseLinkDest parses the destination and the title of an inline link, "(dest "title")", and returns them with
+// the length parsed.
+func parseLinkDest(text string) (string, string, int, bool) {
+	i := 1
+	for i < len(text) && (text[i] == ' ' || text[i] == '\n') {
+		i++
+	}
+
+	var dest string
+	if i < len(text) && text[i] == '<' {
+		close := strings.IndexByte(text[i:], '>')
+		if close < 0 {
+			return "", "", 0, false
+		}
+		dest = text[i+1 : i+close]
+		i += close + 1
+	} else {
+		// Up to whitespace, with balanced parentheses.
+		depth := 0
+		j := i
+		for ; j < len(text); j++ {
+			c := text[j]
+			if c == '\\' && j+1 < len(text) {
+				j++
+				continue
+			}
+			if c == ' ' || c == '\n' || c < ' ' {
+				break
+			}
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest = text[i:j]
+		i = j
+	}
+
+	rest := text[i:]
+	trimmed := strings.TrimLeft(rest, " \n")
+	if strings.HasPrefix(trimmed, ")") {
+		return unescape(dest), "", i + len(rest) - len(trimmed) + 1, true
+	}
+	if len(trimmed) == len(rest) {
+		// A title must be separated by whitespace.
+		return "", "", 0, false
+	}
+	m := linkTitleRe.FindStringSubmatch(trimmed)
+	if m == nil {
+		return "", "", 0, false
+	}
+	title := m[1] + m[2] + m[3]
+	return unescape(dest), unescape(title), i + len(rest) - len(trimmed) + len(m[0]), true
+}
+
+// plainText returns the text of inline content without its markup, e.g. for the description of images.
+func plainText(text string) string {
+	text = unescape(text)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '_', '`', '[', ']', '~':
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// unescape removes the backslashes escaping punctuation, and decodes the entities.
+func unescape(s string) string {
+	return html.UnescapeString(asciiPunctRe.ReplaceAllString(s, "$1"))
+}
@@ -0,0 +1,138 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package mdconv
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/unidoc/unidoc/pdf/creator"
+	"github.com/unidoc/unidoc/pdf/htmlconv"
+)
+
+// Options are the options of the conversion: the options of the layout of the HTML the document is converted to,
+// and whether the document outline is generated from the headings.
+type Options struct {
+	htmlconv.Options
+
+	Outlines bool
+}
+
+// DefaultOptions returns the default options: the default layout of HTML, with the outline generated.
+func DefaultOptions() Options {
+	return Options{Options: htmlconv.DefaultOptions(), Outlines: true}
+}
+
+// ToHTML converts the Markdown document to HTML.
+func ToHTML(data []byte) []byte {
+	bp := &blockParser{refs: map[string]linkRef{}}
+	blocks := bp.parse(splitLines(string(data)))
+
+	r := &renderer{inline: &inlineParser{refs: bp.refs}}
+	r.blocks(blocks, false)
+	return []byte(r.out.String())
+}
+
+// Convert converts the Markdown document to creator drawables, to be drawn in order with Creator.Draw: the headings
+// to headings, the paragraphs to styled paragraphs, the code blocks to paragraphs in a monospace font, the lists to
+// lists, the tables to tables and the images to images.
+func Convert(data []byte, opts Options) ([]creator.Drawable, error) {
+	return htmlconv.Convert(ToHTML(data), opts.Options)
+}
+
+// Draw converts the Markdown document and draws it with the creator, from the current position.  The outline of
+// the document is generated from the headings if enabled in the options.
+func Draw(c *creator.Creator, data []byte, opts Options) error {
+	if opts.Outlines {
+		c.SetEnableOutlines(true)
+	}
+	return htmlconv.Draw(c, ToHTML(data), opts.Options)
+}
+
+// renderer renders blocks to HTML.
+type renderer struct {
+	out    strings.Builder
+	inline *inlineParser
+}
+
+// blocks renders the blocks, the paragraphs without p elements in the items of tight lists.
+func (r *renderer) blocks(blocks []*block, tight bool) {
+	for _, b := range blocks {
+		r.block(b, tight)
+	}
+}
+
+// block renders a block.
+func (r *renderer) block(b *block, tight bool) {
+	switch b.kind {
+	case blockParagraph:
+		if tight {
+			r.out.WriteString(r.inline.render(b.text) + "\n")
+			return
+		}
+		r.out.WriteString("<p>" + r.inline.render(b.text) + "</p>\n")
+	case blockHeading:
+		fmt.Fprintf(&r.out, "<h%d>%s</h%d>\n", b.level, r.inline.render(b.text), b.level)
+	case blockCode:
+		class := ""
+		if b.info != "" {
+			class = ` class="language-` + html.EscapeString(unescape(b.info)) + `"`
+		}
+		r.out.WriteString("<pre><code" + class + ">" + html.EscapeString(b.text) + "</code></pre>\n")
+	case blockQuote:
+		r.out.WriteString("<blockquote>\n")
+		r.blocks(b.children, false)
+		r.out.WriteString("</blockquote>\n")
+	case blockList:
+		tag := "ul"
+		if b.ordered {
+			tag = "ol"
+		}
+		r.out.WriteString("<" + tag)
+		if b.ordered && b.start != 1 {
+			fmt.Fprintf(&r.out, ` start="%d"`, b.start)
+		}
+		r.out.WriteString(">\n")
+		for _, item := range b.children {
+			r.out.WriteString("<li>")
+			r.blocks(item.children, b.tight)
+			r.out.WriteString("</li>\n")
+		}
+		r.out.WriteString("</" + tag + ">\n")
+	case blockRule:
+		r.out.WriteString("<hr>\n")
+	case blockTable:
+		r.out.WriteString("<table border=\"1\">\n")
+		for i, row := range b.rows {
+			cellTag := "td"
+			if i == 0 {
+				cellTag = "th"
+				r.out.WriteString("<thead>\n")
+			} else if i == 1 {
+				r.out.WriteString("<tbody>\n")
+			}
+			r.out.WriteString("<tr>")
+			for j, cell := range row {
+				r.out.WriteString("<" + cellTag)
+				if b.align[j] != "" {
+					r.out.WriteString(` style="text-align: ` + b.align[j] + `"`)
+				}
+				r.out.WriteString(">" + r.inline.render(cell) + "</" + cellTag + ">")
+			}
+			r.out.WriteString("</tr>\n")
+			if i == 0 {
+				r.out.WriteString("</thead>\n")
+			}
+		}
+		if len(b.rows) > 1 {
+			r.out.WriteString("</tbody>\n")
+		}
+		r.out.WriteString("</table>\n")
+	case blockHTML:
+		r.out.WriteString(b.text + "\n")
+	}
+}
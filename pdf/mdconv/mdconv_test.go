@@ -0,0 +1,110 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package mdconv
+
+import (
+	"os"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/creator"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+func TestToHTML(t *testing.T) {
+	testcases := []struct {
+		markdown string
+		html     string
+	}{
+		{"# Title #\n\nSub\n---\n", "<h1>Title</h1>\n<h2>Sub</h2>\n"},
+		{"one\ntwo  \nthree\\\nfour\n", "<p>one\ntwo<br>\nthree<br>\nfour</p>\n"},
+		{"***both*** *a **b** c* ~~gone~~\n", "<p><em><strong>both</strong></em> <em>a <strong>b</strong> c</em> <del>gone</del></p>\n"},
+		{"snake_case_name and _it_\n", "<p>snake_case_name and <em>it</em></p>\n"},
+		{"*unclosed and 2 * 3\n", "<p>*unclosed and 2 * 3</p>\n"},
+		{"`` a ` b `` \\*x\\* &amp; <i>raw</i>\n", "<p><code>a ` b</code> *x* &amp; <i>raw</i></p>\n"},
+		{"[link](/url \"T\") [ref] ![img](a.png) <https://x.org>\n\n[ref]: /r\n",
+			"<p><a href=\"/url\" title=\"T\">link</a> <a href=\"/r\">ref</a> <img src=\"a.png\" alt=\"img\"> " +
+				"<a href=\"https://x.org\">https://x.org</a></p>\n"},
+		{"```go\nx := a < b\n```\n\n    indented\n", "<pre><code class=\"language-go\">x := a &lt; b\n</code></pre>\n" +
+			"<pre><code>indented\n</code></pre>\n"},
+		{"- a\n- \n  b\n\n3. x\n\n4. y\n", "<ul>\n<li>a\n</li>\n<li>b\n</li>\n</ul>\n" +
+			"<ol start=\"3\">\n<li><p>x</p>\n</li>\n<li><p>y</p>\n</li>\n</ol>\n"},
+		{"> quote\nlazy\n\n***\n", "<blockquote>\n<p>quote\nlazy</p>\n</blockquote>\n<hr>\n"},
+		{"| a | b |\n|:--|--:|\n| 1 | `\\|` |\n", "<table border=\"1\">\n<thead>\n<tr><th style=\"text-align: left\">a</th>" +
+			"<th style=\"text-align: right\">b</th></tr>\n</thead>\n<tbody>\n<tr><td style=\"text-align: left\">1</td>" +
+			"<td style=\"text-align: right\"><code>|</code></td></tr>\n</tbody>\n</table>\n"},
+	}
+
+	for _, tcase := range testcases {
+		html := string(ToHTML([]byte(tcase.markdown)))
+		if html != tcase.html {
+			t.Errorf("Wrong HTML of %q: %q (expected %q)", tcase.markdown, html, tcase.html)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	markdown := `# Manual
+
+Some *text* with ` + "`code`" + `.
+
+## Install
+
+` + "```" + `
+go get github.com/unidoc/unidoc
+` + "```" + `
+
+## Usage
+
+1. First
+2. Second
+
+| Name | Value |
+|------|-------|
+| a    | 1     |
+`
+
+	drawables, err := Convert([]byte(markdown), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var headings []string
+	for _, d := range drawables {
+		if h, ok := d.(*creator.Heading); ok {
+			headings = append(headings, h.Title())
+		}
+	}
+	if len(headings) != 3 || headings[0] != "Manual" || headings[2] != "Usage" {
+		t.Errorf("Wrong headings %v", headings)
+	}
+
+	c := creator.New()
+	c.NewPage()
+	err = Draw(c, []byte(markdown), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = c.WriteToFile("/tmp/markdown.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	f, err := os.Open("/tmp/markdown.pdf")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, titles, err := reader.GetOutlinesFlattened()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(titles) != 5 || titles[1] != "  Manual" || titles[3] != "    Install" {
+		t.Errorf("Wrong outline %q", titles)
+	}
+}
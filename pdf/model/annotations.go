@@ -43,6 +43,12 @@ func (this *PdfAnnotation) SetContext(ctx PdfModel) {
 	this.context = ctx
 }
 
+// GetMarkup returns the markup part of markup annotations (12.5.6.2 Markup Annotations), e.g. with the author and the
+// popup, or nil for other annotations.
+func (this *PdfAnnotation) GetMarkup() *PdfAnnotationMarkup {
+	return getAnnotationMarkup(this)
+}
+
 func (this *PdfAnnotation) String() string {
 	s := ""
 
@@ -0,0 +1,299 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfAnnotationJSON is a markup annotation in JSON form, for exchanging the annotations of a document with review
+// tools.  Coordinates are in the default user space of the page.
+type PdfAnnotationJSON struct {
+	// Annotation subtype, e.g. Text, Highlight or Square.
+	Type string `json:"type"`
+
+	// Index of the page (from 0).
+	Page int `json:"page"`
+
+	Rect       []float64 `json:"rect"`
+	QuadPoints []float64 `json:"quadpoints,omitempty"`
+
+	// Color components: gray, RGB or CMYK.
+	Color []float64 `json:"color,omitempty"`
+
+	// Unique name of the annotation on the page.
+	Name string `json:"name,omitempty"`
+
+	Contents string `json:"contents,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+
+	Modified *time.Time `json:"modified,omitempty"`
+	Created  *time.Time `json:"created,omitempty"`
+}
+
+// Returns the markup part of markup annotations (12.5.6.2 Markup Annotations), or nil for other annotations.
+func getAnnotationMarkup(annot *PdfAnnotation) *PdfAnnotationMarkup {
+	switch t := annot.GetContext().(type) {
+	case *PdfAnnotationText:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationFreeText:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationLine:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationSquare:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationCircle:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationPolygon:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationPolyLine:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationHighlight:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationUnderline:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationSquiggly:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationStrikeOut:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationCaret:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationStamp:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationInk:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationFileAttachment:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationSound:
+		return t.PdfAnnotationMarkup
+	case *PdfAnnotationRedact:
+		return t.PdfAnnotationMarkup
+	}
+	return nil
+}
+
+// Returns the quadrilaterals of the text markup and redaction annotations, or nil for other annotations.
+func getAnnotationQuadPoints(annot *PdfAnnotation) *PdfObject {
+	switch t := annot.GetContext().(type) {
+	case *PdfAnnotationHighlight:
+		return &t.QuadPoints
+	case *PdfAnnotationUnderline:
+		return &t.QuadPoints
+	case *PdfAnnotationSquiggly:
+		return &t.QuadPoints
+	case *PdfAnnotationStrikeOut:
+		return &t.QuadPoints
+	case *PdfAnnotationRedact:
+		return &t.QuadPoints
+	}
+	return nil
+}
+
+// GetAnnotationsJSON returns the markup annotations of the pages of the document in JSON form.  Other annotations
+// (links, widgets, popups, etc.) are not included.
+func (this *PdfReader) GetAnnotationsJSON() ([]byte, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	annotations := []*PdfAnnotationJSON{}
+	for i, page := range this.PageList {
+		for _, annot := range page.Annotations {
+			markup := getAnnotationMarkup(annot)
+			if markup == nil {
+				continue
+			}
+
+			dict, ok := annot.primitive.PdfObject.(*PdfObjectDictionary)
+			if !ok {
+				return nil, ErrTypeError
+			}
+			subtype, ok := TraceToDirectObject(dict.Get("Subtype")).(*PdfObjectName)
+			if !ok {
+				common.Log.Debug("ERROR: Annotation without subtype")
+				return nil, ErrTypeError
+			}
+
+			a := &PdfAnnotationJSON{Type: string(*subtype), Page: i}
+			var err error
+			a.Rect, err = getAnnotationNumbers(annot.Rect)
+			if err != nil {
+				return nil, err
+			}
+			if len(a.Rect) != 4 {
+				common.Log.Debug("ERROR: Invalid annotation rectangle %v", a.Rect)
+				return nil, ErrRangeError
+			}
+			if quadPoints := getAnnotationQuadPoints(annot); quadPoints != nil {
+				a.QuadPoints, err = getAnnotationNumbers(*quadPoints)
+				if err != nil {
+					return nil, err
+				}
+			}
+			a.Color, err = getAnnotationNumbers(annot.C)
+			if err != nil {
+				return nil, err
+			}
+
+			a.Name = getAnnotationText(annot.NM)
+			a.Contents = getAnnotationText(annot.Contents)
+			a.Author = getAnnotationText(markup.T)
+			a.Subject = getAnnotationText(markup.Subj)
+			a.Modified = getAnnotationTime(annot.M)
+			a.Created = getAnnotationTime(markup.CreationDate)
+
+			annotations = append(annotations, a)
+		}
+	}
+	return json.MarshalIndent(annotations, "", "  ")
+}
+
+// Returns the numbers of an array, or nil if the object is not an array.
+func getAnnotationNumbers(obj PdfObject) ([]float64, error) {
+	arr, ok := TraceToDirectObject(obj).(*PdfObjectArray)
+	if !ok {
+		return nil, nil
+	}
+	values := []float64{}
+	for _, elem := range *arr {
+		value, err := getNumberAsFloat(TraceToDirectObject(elem))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Returns the text of a text string, or "" if the object is not a string.
+func getAnnotationText(obj PdfObject) string {
+	str, ok := TraceToDirectObject(obj).(*PdfObjectString)
+	if !ok {
+		return ""
+	}
+	return str.Decoded()
+}
+
+// Returns the time of a date, or nil if the object is not a valid date.
+func getAnnotationTime(obj PdfObject) *time.Time {
+	str, ok := TraceToDirectObject(obj).(*PdfObjectString)
+	if !ok {
+		return nil
+	}
+	date, err := NewPdfDate(string(*str))
+	if err != nil {
+		common.Log.Debug("Invalid annotation date %s", *str)
+		return nil
+	}
+	t := date.toTime()
+	return &t
+}
+
+// AddAnnotationsFromJSON adds the markup annotations in JSON form to the pages of the document, by index.  The
+// supported types are the Text, Square, Circle, Highlight, Underline, Squiggly, StrikeOut, Caret, Stamp and Redact
+// annotations; the other types need more than their rectangle.
+func AddAnnotationsFromJSON(data []byte, pages []*PdfPage) error {
+	annotations := []*PdfAnnotationJSON{}
+	err := json.Unmarshal(data, &annotations)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range annotations {
+		if a.Page < 0 || a.Page >= len(pages) {
+			common.Log.Debug("ERROR: Annotation page %d out of range", a.Page)
+			return ErrRangeError
+		}
+		if len(a.Rect) != 4 {
+			common.Log.Debug("ERROR: Invalid annotation rectangle %v", a.Rect)
+			return ErrRangeError
+		}
+		if n := len(a.Color); n != 0 && n != 1 && n != 3 && n != 4 {
+			common.Log.Debug("ERROR: Invalid annotation color %v", a.Color)
+			return ErrRangeError
+		}
+
+		var annot *PdfAnnotation
+		var markup *PdfAnnotationMarkup
+		switch a.Type {
+		case "Text":
+			text := NewPdfAnnotationText()
+			annot, markup = text.PdfAnnotation, text.PdfAnnotationMarkup
+		case "Square":
+			square := NewPdfAnnotationSquare()
+			annot, markup = square.PdfAnnotation, square.PdfAnnotationMarkup
+		case "Circle":
+			circle := NewPdfAnnotationCircle()
+			annot, markup = circle.PdfAnnotation, circle.PdfAnnotationMarkup
+		case "Highlight":
+			highlight := NewPdfAnnotationHighlight()
+			annot, markup = highlight.PdfAnnotation, highlight.PdfAnnotationMarkup
+		case "Underline":
+			underline := NewPdfAnnotationUnderline()
+			annot, markup = underline.PdfAnnotation, underline.PdfAnnotationMarkup
+		case "Squiggly":
+			squiggly := NewPdfAnnotationSquiggly()
+			annot, markup = squiggly.PdfAnnotation, squiggly.PdfAnnotationMarkup
+		case "StrikeOut":
+			strikeOut := NewPdfAnnotationStrikeOut()
+			annot, markup = strikeOut.PdfAnnotation, strikeOut.PdfAnnotationMarkup
+		case "Caret":
+			caret := NewPdfAnnotationCaret()
+			annot, markup = caret.PdfAnnotation, caret.PdfAnnotationMarkup
+		case "Stamp":
+			stamp := NewPdfAnnotationStamp()
+			annot, markup = stamp.PdfAnnotation, stamp.PdfAnnotationMarkup
+		case "Redact":
+			redact := NewPdfAnnotationRedact()
+			annot, markup = redact.PdfAnnotation, redact.PdfAnnotationMarkup
+		default:
+			common.Log.Debug("ERROR: Unsupported annotation type %s", a.Type)
+			return errors.New("Unsupported annotation type")
+		}
+
+		page := pages[a.Page]
+		annot.P = page.GetPageAsIndirectObject()
+		annot.Rect = MakeArrayFromFloats(a.Rect)
+		if quadPoints := getAnnotationQuadPoints(annot); quadPoints != nil {
+			if len(a.QuadPoints) == 0 || len(a.QuadPoints)%8 != 0 {
+				common.Log.Debug("ERROR: Invalid annotation quadrilaterals %v", a.QuadPoints)
+				return ErrRangeError
+			}
+			*quadPoints = MakeArrayFromFloats(a.QuadPoints)
+		}
+		if a.Color != nil {
+			annot.C = MakeArrayFromFloats(a.Color)
+		}
+
+		if a.Name != "" {
+			annot.NM = MakeTextString(a.Name)
+		}
+		if a.Contents != "" {
+			annot.Contents = MakeTextString(a.Contents)
+		}
+		if a.Author != "" {
+			markup.T = MakeTextString(a.Author)
+		}
+		if a.Subject != "" {
+			markup.Subj = MakeTextString(a.Subject)
+		}
+		if a.Modified != nil {
+			annot.M = MakeString(a.Modified.Format(pdfDateLayout))
+		}
+		if a.Created != nil {
+			markup.CreationDate = MakeString(a.Created.Format(pdfDateLayout))
+		}
+
+		page.Annotations = append(page.Annotations, annot)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnnotationsJSON(t *testing.T) {
+	input := `[
+  {"type": "Highlight", "page": 0, "rect": [10, 10, 50, 20], "quadpoints": [10, 20, 50, 20, 10, 10, 50, 10],
+   "color": [1, 1, 0], "name": "h1", "contents": "Important", "author": "Reviewer",
+   "modified": "2018-03-04T10:20:30Z", "created": "2018-03-01T08:00:00Z"},
+  {"type": "Text", "page": 1, "rect": [60, 60, 80, 80], "contents": "Commentaire à revoir", "subject": "Note"}
+]`
+
+	w := NewPdfWriter()
+	pages := []*PdfPage{}
+	for i := 0; i < 2; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 100}
+		page.Resources = NewPdfPageResources()
+		pages = append(pages, page)
+	}
+	// A link is not a markup annotation.
+	pages[0].Annotations = append(pages[0].Annotations, NewPdfAnnotationLink().PdfAnnotation)
+
+	err := AddAnnotationsFromJSON([]byte(input), pages)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, page := range pages {
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+
+	invalid := []string{
+		`[{"type": "Text", "page": 2, "rect": [0, 0, 1, 1]}]`,
+		`[{"type": "Text", "page": 0, "rect": [0, 0, 1]}]`,
+		`[{"type": "Highlight", "page": 0, "rect": [0, 0, 1, 1], "quadpoints": [0, 0, 1, 1]}]`,
+		`[{"type": "Ink", "page": 0, "rect": [0, 0, 1, 1]}]`,
+	}
+	for _, data := range invalid {
+		if err := AddAnnotationsFromJSON([]byte(data), pages); err == nil {
+			t.Errorf("Invalid annotation not detected: %s", data)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	output, err := reader.GetAnnotationsJSON()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The same annotations, back from the document.
+	var expected, annotations []*PdfAnnotationJSON
+	err = json.Unmarshal([]byte(input), &expected)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = json.Unmarshal(output, &annotations)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(annotations) != len(expected) {
+		t.Fatalf("Wrong annotations JSON %s", output)
+	}
+	for i := range expected {
+		a, exp := annotations[i], expected[i]
+		for _, times := range [][2]**time.Time{{&a.Modified, &exp.Modified}, {&a.Created, &exp.Created}} {
+			if *times[0] != nil && *times[1] != nil && (*times[0]).Equal(**times[1]) {
+				*times[0] = *times[1]
+			}
+		}
+		if !reflect.DeepEqual(a, exp) {
+			t.Errorf("Wrong annotation %d JSON %s", i, output)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfAppender writes changes to a loaded document as an incremental update appended after the original bytes of the
+// file.  The original document is not rewritten, so that existing signatures remain valid and large documents are
+// updated at the cost of the changes only.  Objects are modified, added and removed as in a PdfTransaction.
+//
+// Example:
+//   appender, err := model.NewPdfAppender(reader)
+//   err = appender.AddPage(page)            // E.g. a page loaded from another document.
+//   err = appender.AddAnnotation(1, annot)
+//   appender.Sign(signer)                   // Optional.
+//   err = appender.Write(w)
+type PdfAppender struct {
+	*PdfTransaction
+
+	signer Signer
+}
+
+// Signer computes the signature value (Contents) of a signature dictionary, e.g. a detached PKCS#7 signature
+// (adbe.pkcs7.detached) of the signed byte ranges of the document.
+type Signer interface {
+	// InitSignature sets the entries of the signature dictionary other than ByteRange and Contents, e.g. Filter,
+	// SubFilter, M and Name.
+	InitSignature(sig *PdfObjectDictionary) error
+
+	// Sign returns the signature value of the signed data: the document except the Contents of the signature.
+	Sign(data []byte) ([]byte, error)
+
+	// MaxSize returns the maximum size of the signature value in bytes, reserved in the document before signing.
+	MaxSize() int
+}
+
+// NewPdfAppender returns an appender for incremental updates of the document loaded by the reader.  Incremental
+// updates are not supported for encrypted documents.
+func NewPdfAppender(reader *PdfReader) (*PdfAppender, error) {
+	tx, err := reader.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &PdfAppender{PdfTransaction: tx}, nil
+}
+
+// AddPage appends a page to the end of the document.  The page can be new or loaded from another document: the
+// objects of other documents that the page refers to are copied to the update, and the attributes it inherits from
+// its page tree are set on the page.
+func (a *PdfAppender) AddPage(page *PdfPage) error {
+	if a.closed {
+		return errors.New("Transaction closed")
+	}
+
+	pageObj, ok := page.ToPdfObject().(*PdfIndirectObject)
+	if !ok {
+		return errors.New("Page should be an indirect object")
+	}
+	pDict, ok := pageObj.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return errors.New("Page object should be a dictionary")
+	}
+	pDict = copyObject(pDict).(*PdfObjectDictionary)
+	if err := inheritPageFields(pDict); err != nil {
+		return err
+	}
+	pDict.Remove("Parent")
+
+	catalog, err := a.getCatalog()
+	if err != nil {
+		return err
+	}
+	pagesNum, ok := getObjectNumber(catalog.Get("Pages"))
+	if !ok {
+		common.Log.Debug("ERROR: Pages not an indirect object (%T)", catalog.Get("Pages"))
+		return errors.New("Invalid Pages obj")
+	}
+	pagesObj, err := a.GetObject(pagesNum)
+	if err != nil {
+		return err
+	}
+	pages, ok := pagesObj.(*PdfIndirectObject)
+	if !ok {
+		return ErrTypeError
+	}
+	pagesDict, ok := pages.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return errors.New("Invalid Pages obj (not a dict)")
+	}
+	obj, err := a.getEntry(pagesDict, "Kids", func() PdfObject { return MakeArray() })
+	if err != nil {
+		return err
+	}
+	kids, ok := obj.(*PdfObjectArray)
+	if !ok {
+		return errors.New("Invalid Pages Kids obj (not an array)")
+	}
+
+	// The new page replaces the page object in the references from its annotations (P).
+	newPage := &PdfIndirectObject{}
+	copies := map[PdfObject]PdfObject{pageObj: newPage}
+	newPage.PdfObject = a.importObject(pDict, copies)
+	pDict.Set("Parent", pages)
+
+	*kids = append(*kids, newPage)
+	count := int64(0)
+	if c, ok := traceDirect(a.reader, pagesDict.Get("Count")).(*PdfObjectInteger); ok {
+		count = int64(*c)
+	}
+	pagesDict.Set("Count", MakeInteger(count+1))
+	return nil
+}
+
+// AddAnnotation adds an annotation to the page with the specified number (starting from 1).
+func (a *PdfAppender) AddAnnotation(pageNum int, annot *PdfAnnotation) error {
+	if a.closed {
+		return errors.New("Transaction closed")
+	}
+	if pageNum < 1 || pageNum > len(a.reader.pageList) {
+		return errors.New("Invalid page number")
+	}
+
+	page := a.reader.pageList[pageNum-1]
+	pageDict, err := a.getDict(page.ObjectNumber)
+	if err != nil {
+		return err
+	}
+	obj, err := a.getEntry(pageDict, "Annots", func() PdfObject { return MakeArray() })
+	if err != nil {
+		return err
+	}
+	annots, ok := obj.(*PdfObjectArray)
+	if !ok {
+		return ErrTypeError
+	}
+
+	annot.P = page
+	var annotObj PdfObject
+	if ctx := annot.GetContext(); ctx != nil {
+		annotObj = ctx.ToPdfObject()
+	} else {
+		annotObj = annot.ToPdfObject()
+	}
+	*annots = append(*annots, a.importObject(annotObj, map[PdfObject]PdfObject{}))
+	return nil
+}
+
+// Sign sets the signer of the update: Write adds an invisible signature field on the first page, whose value is
+// signed by the signer over the whole updated document.
+func (a *PdfAppender) Sign(signer Signer) {
+	a.signer = signer
+}
+
+// Write writes the original document followed by the incremental update to w, signed if a signer is set, and
+// closes the appender.
+func (a *PdfAppender) Write(w io.Writer) error {
+	if a.signer != nil {
+		return a.commitSigned(w, "Signature", a.signer)
+	}
+	return a.Commit(w)
+}
+
+// CommitSigned commits the transaction like Commit, signed by the signer with an invisible signature field on the
+// first page covering the whole updated document.
+func (tx *PdfTransaction) CommitSigned(w io.Writer, signer Signer) error {
+	return tx.commitSigned(w, "Signature", signer)
+}
+
+// byteRangePlaceholder is the ByteRange of a signature written before its offsets are known.
+const byteRangePlaceholder = "[0 ********** ********** **********]"
+
+// sigPlaceholder is a value of a signature dictionary written with a fixed width, replaced once the document is
+// written.
+type sigPlaceholder string
+
+func (p sigPlaceholder) String() string {
+	return string(p)
+}
+
+func (p sigPlaceholder) DefaultWriteString() string {
+	return string(p)
+}
+
+// commitSigned commits the transaction with a new signature field named by the prefix and a number not used in the
+// form, whose signature value is computed by the signer once the update is written.
+func (tx *PdfTransaction) commitSigned(w io.Writer, namePrefix string, signer Signer) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+	reader := tx.reader
+	if len(reader.pageList) == 0 {
+		return errors.New("No pages")
+	}
+
+	catalog, err := tx.getCatalog()
+	if err != nil {
+		return err
+	}
+	obj, err := tx.getEntry(catalog, "AcroForm", func() PdfObject { return MakeDict() })
+	if err != nil {
+		return err
+	}
+	acroForm, ok := obj.(*PdfObjectDictionary)
+	if !ok {
+		return ErrTypeError
+	}
+	obj, err = tx.getEntry(acroForm, "Fields", func() PdfObject { return MakeArray() })
+	if err != nil {
+		return err
+	}
+	fields, ok := obj.(*PdfObjectArray)
+	if !ok {
+		return ErrTypeError
+	}
+	sigFlags := int64(3)
+	if flags, ok := traceDirect(reader, acroForm.Get("SigFlags")).(*PdfObjectInteger); ok {
+		sigFlags |= int64(*flags)
+	}
+	acroForm.Set("SigFlags", MakeInteger(sigFlags))
+
+	page := reader.pageList[0]
+	pageDict, err := tx.getDict(page.ObjectNumber)
+	if err != nil {
+		return err
+	}
+	obj, err = tx.getEntry(pageDict, "Annots", func() PdfObject { return MakeArray() })
+	if err != nil {
+		return err
+	}
+	annots, ok := obj.(*PdfObjectArray)
+	if !ok {
+		return ErrTypeError
+	}
+
+	// A field name not used in the form.
+	names := map[string]bool{}
+	if reader.AcroForm != nil {
+		for _, field := range reader.AcroForm.AllFields() {
+			names[field.GetFullName()] = true
+		}
+	}
+	name := ""
+	for i := 1; name == "" || names[name]; i++ {
+		name = fmt.Sprintf("%s%d", namePrefix, i)
+	}
+
+	size := signer.MaxSize()
+	contentsPlaceholder := "<" + strings.Repeat("0", 2*size) + ">"
+	sig := MakeDict()
+	if err = signer.InitSignature(sig); err != nil {
+		return err
+	}
+	sig.Set("ByteRange", sigPlaceholder(byteRangePlaceholder))
+	sig.Set("Contents", sigPlaceholder(contentsPlaceholder))
+
+	field := MakeDict()
+	field.Set("FT", MakeName("Sig"))
+	field.Set("T", MakeString(name))
+	field.Set("V", MakeIndirectObject(sig))
+	field.Set("Type", MakeName("Annot"))
+	field.Set("Subtype", MakeName("Widget"))
+	field.Set("Rect", MakeArrayFromIntegers([]int{0, 0, 0, 0}))
+	// Hidden and locked.
+	field.Set("F", MakeInteger(132))
+	field.Set("P", page)
+	fieldObj := MakeIndirectObject(field)
+	*fields = append(*fields, fieldObj)
+	*annots = append(*annots, fieldObj)
+
+	var buf bytes.Buffer
+	if err = tx.Commit(&buf); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+
+	// The signed ranges are the whole document except the Contents.
+	start := bytes.LastIndex(data, []byte(contentsPlaceholder))
+	byteRangeStart := bytes.LastIndex(data, []byte(byteRangePlaceholder))
+	if start < 0 || byteRangeStart < 0 {
+		return errors.New("Signature placeholder not found")
+	}
+	end := start + len(contentsPlaceholder)
+	byteRange := fmt.Sprintf("[0 %d %d %d", start, end, len(data)-end)
+	byteRange += strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)-1) + "]"
+	copy(data[byteRangeStart:], byteRange)
+
+	signed := make([]byte, 0, len(data)-(end-start))
+	signed = append(signed, data[:start]...)
+	signed = append(signed, data[end:]...)
+	value, err := signer.Sign(signed)
+	if err != nil {
+		return err
+	}
+	if len(value) > size {
+		common.Log.Debug("ERROR: Signature of %d bytes, %d reserved", len(value), size)
+		return errors.New("Signature too large")
+	}
+	hex.Encode(data[start+1:], value)
+
+	_, err = w.Write(data)
+	return err
+}
+
+// importObject returns obj with the indirect objects of other documents that it refers to replaced by copies,
+// numbered on Commit.  The objects of the document, and new objects, are kept.  Copies already made are looked up in
+// copies.  The direct objects contained in obj are modified in place.
+func (tx *PdfTransaction) importObject(obj PdfObject, copies map[PdfObject]PdfObject) PdfObject {
+	if c, has := copies[obj]; has {
+		return c
+	}
+
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		if t.ObjectNumber == 0 {
+			copies[t] = t
+			t.PdfObject = tx.importObject(t.PdfObject, copies)
+			return t
+		}
+		if tx.isDocumentObject(t.ObjectNumber, t) {
+			return t
+		}
+		ind := &PdfIndirectObject{}
+		copies[t] = ind
+		ind.PdfObject = tx.importObject(copyObject(t.PdfObject), copies)
+		return ind
+	case *PdfObjectStream:
+		if t.ObjectNumber == 0 {
+			copies[t] = t
+			tx.importObject(t.PdfObjectDictionary, copies)
+			return t
+		}
+		if tx.isDocumentObject(t.ObjectNumber, t) {
+			return t
+		}
+		if err := t.Load(); err != nil {
+			common.Log.Debug("ERROR: Failed loading stream %d: %v", t.ObjectNumber, err)
+		}
+		stream := &PdfObjectStream{}
+		copies[t] = stream
+		stream.PdfObjectDictionary = copyObject(t.PdfObjectDictionary).(*PdfObjectDictionary)
+		stream.Stream = append([]byte{}, t.Stream...)
+		tx.importObject(stream.PdfObjectDictionary, copies)
+		return stream
+	case *PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			t.Set(key, tx.importObject(t.Get(key), copies))
+		}
+	case *PdfObjectArray:
+		for i, val := range *t {
+			(*t)[i] = tx.importObject(val, copies)
+		}
+	}
+	return obj
+}
+
+// isDocumentObject returns true if obj is the object with the number in the document, or its staged copy.
+func (tx *PdfTransaction) isDocumentObject(objNum int64, obj PdfObject) bool {
+	if tx.changes[objNum] == obj {
+		return true
+	}
+	orig, err := tx.reader.GetIndirectObjectByNumber(int(objNum))
+	return err == nil && orig == obj
+}
+
+// getObjectNumber returns the object number of a reference, indirect or stream object.
+func getObjectNumber(obj PdfObject) (int64, bool) {
+	switch t := obj.(type) {
+	case *PdfObjectReference:
+		return t.ObjectNumber, true
+	case *PdfIndirectObject:
+		return t.ObjectNumber, true
+	case *PdfObjectStream:
+		return t.ObjectNumber, true
+	}
+	return 0, false
+}
@@ -0,0 +1,179 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+)
+
+// testSigner signs with the SHA-256 digest of the signed data.
+type testSigner struct{}
+
+func (s *testSigner) InitSignature(sig *PdfObjectDictionary) error {
+	sig.Set("Type", MakeName("Sig"))
+	sig.Set("Filter", MakeName("Test"))
+	return nil
+}
+
+func (s *testSigner) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return digest[:], nil
+}
+
+func (s *testSigner) MaxSize() int {
+	return 64
+}
+
+func TestAppenderAddPage(t *testing.T) {
+	original := makeCoveredTestPdf(0)
+	reader, err := NewPdfReader(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// A page inheriting its media box and resources from the page tree of another document.
+	other, err := NewPdfReader(bytes.NewReader(testutils.MakePdf([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 200 300] /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		"<< /Length 8 >>\nstream\n1 0 0 rg\nendstream",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	})))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err := other.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	appender, err := NewPdfAppender(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = appender.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	annot := NewPdfAnnotationText()
+	annot.Rect = MakeArrayFromIntegers([]int{10, 10, 30, 30})
+	annot.Contents = MakeString("Note")
+	if err = appender.AddAnnotation(1, annot.PdfAnnotation); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = appender.AddAnnotation(3, annot.PdfAnnotation); err == nil {
+		t.Errorf("Annotation added to missing page")
+	}
+	var buf bytes.Buffer
+	if err = appender.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data := buf.Bytes()
+
+	// The original document is kept, including its signature.
+	if !bytes.HasPrefix(data, original) {
+		t.Fatalf("Not an incremental update")
+	}
+	coverage := getCoverage(t, data)
+	if coverage.End != len(original) {
+		t.Errorf("Wrong coverage %+v", coverage)
+	}
+
+	reader, err = NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if num, _ := reader.GetNumPages(); num != 2 {
+		t.Fatalf("Wrong page count %d", num)
+	}
+	added, err := reader.GetPage(2)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if added.MediaBox == nil || added.MediaBox.Urx != 200 || added.MediaBox.Ury != 300 {
+		t.Errorf("Wrong media box %v", added.MediaBox)
+	}
+	if !added.HasFontByName("F1") {
+		t.Errorf("Font resource missing")
+	}
+	content, err := added.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if content != "1 0 0 rg" {
+		t.Errorf("Wrong content %q", content)
+	}
+
+	first, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(first.Annotations) != 1 {
+		t.Fatalf("Wrong annotations %v", first.Annotations)
+	}
+	text, ok := first.Annotations[0].GetContext().(*PdfAnnotationText)
+	if !ok || text.Contents.String() != "Note" {
+		t.Errorf("Wrong annotation %v", first.Annotations[0])
+	}
+}
+
+func TestAppenderSign(t *testing.T) {
+	original := makeTestPdf()
+	reader, err := NewPdfReader(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	appender, err := NewPdfAppender(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	appender.Sign(&testSigner{})
+	var buf bytes.Buffer
+	if err = appender.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, original) {
+		t.Fatalf("Not an incremental update")
+	}
+
+	reader, err = NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var sig *PdfObjectDictionary
+	for _, field := range reader.AcroForm.AllFields() {
+		if field.GetFullName() == "Signature1" {
+			sig, _ = TraceToDirectObject(field.V).(*PdfObjectDictionary)
+		}
+	}
+	if sig == nil {
+		t.Fatalf("Signature field not found")
+	}
+	if filter, ok := sig.Get("Filter").(*PdfObjectName); !ok || *filter != "Test" {
+		t.Errorf("Wrong filter %v", sig.Get("Filter"))
+	}
+
+	byteRange, end := checkByteRange(data, sig)
+	if end != len(data) {
+		t.Fatalf("Wrong ByteRange %v", byteRange)
+	}
+	h := sha256.New()
+	h.Write(data[:byteRange[1]])
+	h.Write(data[byteRange[2]:])
+	contents := []byte(*sig.Get("Contents").(*PdfObjectString))
+	if !bytes.Equal(contents[:sha256.Size], h.Sum(nil)) {
+		t.Errorf("Wrong signature value")
+	}
+
+	if err = appender.Write(&buf); err == nil {
+		t.Errorf("Appender written twice")
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// Default width of the glyphs of a CIDFont (DW).
+const cidFontDefaultWidth = 1000
+
+// Minimum number of consecutive CIDs with the same width written as a range (c_first c_last w) in the W array,
+// rather than listed individually (c [w1 w2 ...]).
+const cidWidthsMinRange = 4
+
+// MakeCIDFontWidths builds the default width (DW) and the widths array (W) of a CIDFont from the widths of the
+// CIDs, in glyph space units (1/1000 of text space).  The most common width is used as default and is omitted from
+// the W array.  Consecutive CIDs are grouped, with runs of the same width written as ranges.  The DW entry can be
+// omitted from the CIDFont dictionary if the default width is 1000.
+func MakeCIDFontWidths(widths map[uint16]float64) (float64, *PdfObjectArray) {
+	// The most common width, preferring the standard default and then smaller widths on ties.
+	counts := map[float64]int{}
+	for _, w := range widths {
+		counts[w]++
+	}
+	vals := []float64{}
+	for w := range counts {
+		vals = append(vals, w)
+	}
+	sort.Float64s(vals)
+	dw := float64(cidFontDefaultWidth)
+	for _, w := range vals {
+		if counts[w] > counts[dw] {
+			dw = w
+		}
+	}
+
+	cids := []int{}
+	for cid, w := range widths {
+		if w != dw {
+			cids = append(cids, int(cid))
+		}
+	}
+	sort.Ints(cids)
+
+	// Number of consecutive CIDs starting at index i with the same width.
+	sameWidths := func(i int) int {
+		n := 1
+		for i+n < len(cids) && cids[i+n] == cids[i]+n && widths[uint16(cids[i+n])] == widths[uint16(cids[i])] {
+			n++
+		}
+		return n
+	}
+
+	arr := MakeArray()
+	for i := 0; i < len(cids); {
+		if n := sameWidths(i); n >= cidWidthsMinRange {
+			*arr = append(*arr, MakeInteger(int64(cids[i])), MakeInteger(int64(cids[i+n-1])),
+				makeWidthObject(widths[uint16(cids[i])]))
+			i += n
+			continue
+		}
+
+		list := MakeArray()
+		start := i
+		for i < len(cids) && (i == start || cids[i] == cids[i-1]+1) {
+			if i > start && sameWidths(i) >= cidWidthsMinRange {
+				break
+			}
+			list.Append(makeWidthObject(widths[uint16(cids[i])]))
+			i++
+		}
+		*arr = append(*arr, MakeInteger(int64(cids[start])), list)
+	}
+
+	return dw, arr
+}
+
+// Makes an integer object for whole widths and a float object otherwise.
+func makeWidthObject(w float64) PdfObject {
+	if w == math.Trunc(w) {
+		return MakeInteger(int64(w))
+	}
+	return MakeFloat(w)
+}
+
+// ParseCIDFontWidths returns the default width and the widths of the CIDs listed in the DW and W entries of a
+// CIDFont.  Either entry may be nil.
+func ParseCIDFontWidths(dwObj PdfObject, wObj PdfObject) (float64, map[uint16]float64, error) {
+	dw := float64(cidFontDefaultWidth)
+	if dwObj = TraceToDirectObject(dwObj); dwObj != nil {
+		val, err := getNumberAsFloat(dwObj)
+		if err != nil {
+			common.Log.Debug("Invalid DW (%T)", dwObj)
+			return 0, nil, ErrTypeError
+		}
+		dw = val
+	}
+
+	widths := map[uint16]float64{}
+	wObj = TraceToDirectObject(wObj)
+	if wObj == nil {
+		return dw, widths, nil
+	}
+	arr, ok := wObj.(*PdfObjectArray)
+	if !ok {
+		common.Log.Debug("Invalid W (%T)", wObj)
+		return 0, nil, ErrTypeError
+	}
+
+	for i := 0; i < len(*arr); {
+		first, err := getNumberAsFloat(TraceToDirectObject((*arr)[i]))
+		if err != nil || i+1 >= len(*arr) {
+			common.Log.Debug("Invalid W entry at %d", i)
+			return 0, nil, ErrRangeError
+		}
+
+		// c [w1 w2 ... wn]
+		if list, isList := TraceToDirectObject((*arr)[i+1]).(*PdfObjectArray); isList {
+			vals, err := list.ToFloat64Array()
+			if err != nil {
+				common.Log.Debug("Invalid W widths list at %d", i)
+				return 0, nil, err
+			}
+			for j, w := range vals {
+				widths[uint16(int(first)+j)] = w
+			}
+			i += 2
+			continue
+		}
+
+		// c_first c_last w
+		if i+2 >= len(*arr) {
+			common.Log.Debug("Invalid W range at %d", i)
+			return 0, nil, ErrRangeError
+		}
+		vals, err := getNumbersAsFloat([]PdfObject{TraceToDirectObject((*arr)[i+1]), TraceToDirectObject((*arr)[i+2])})
+		if err != nil {
+			common.Log.Debug("Invalid W range at %d", i)
+			return 0, nil, err
+		}
+		for cid := int(first); cid <= int(vals[0]); cid++ {
+			widths[uint16(cid)] = vals[1]
+		}
+		i += 3
+	}
+
+	return dw, widths, nil
+}
+
+// ValidateCIDFontWidths checks the widths of the CIDs of a CIDFontType2 font against the horizontal metrics (hmtx)
+// of its TrueType font program.  The cidToGID map gives the glyph index of the CIDs, or is nil for the Identity
+// mapping.  Widths may differ by up to one glyph space unit from the metrics due to rounding.
+func ValidateCIDFontWidths(widths map[uint16]float64, ttf *fonts.TtfType, cidToGID map[uint16]uint16) error {
+	if ttf.UnitsPerEm == 0 {
+		common.Log.Debug("Invalid font program: units per em not set")
+		return ErrRangeError
+	}
+	k := 1000.0 / float64(ttf.UnitsPerEm)
+
+	cids := []int{}
+	for cid := range widths {
+		cids = append(cids, int(cid))
+	}
+	sort.Ints(cids)
+
+	for _, c := range cids {
+		cid := uint16(c)
+		gid := cid
+		if cidToGID != nil {
+			var has bool
+			if gid, has = cidToGID[cid]; !has {
+				common.Log.Debug("CID %d not mapped to a glyph", cid)
+				return fmt.Errorf("CID %d not mapped to a glyph", cid)
+			}
+		}
+		if int(gid) >= len(ttf.Widths) {
+			common.Log.Debug("Glyph %d of CID %d not in font program (%d glyphs)", gid, cid, len(ttf.Widths))
+			return fmt.Errorf("Glyph %d of CID %d not in font program", gid, cid)
+		}
+
+		expected := k * float64(ttf.Widths[gid])
+		if math.Abs(widths[cid]-expected) > 1 {
+			common.Log.Debug("Width of CID %d (%v) does not match font program (%v)", cid, widths[cid], expected)
+			return fmt.Errorf("Width of CID %d does not match font program", cid)
+		}
+	}
+
+	return nil
+}
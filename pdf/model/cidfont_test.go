@@ -0,0 +1,80 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+func TestCIDFontWidths(t *testing.T) {
+	widths := map[uint16]float64{
+		1: 500, 2: 500, 3: 500, 4: 500, 5: 500, // Default width.
+		10: 250, 11: 300, 12: 350, // Listed.
+		20: 600, 21: 600, 22: 600, 23: 600, // Range.
+		24: 700, 30: 500.5,
+	}
+
+	dw, w := MakeCIDFontWidths(widths)
+	if dw != 500 {
+		t.Errorf("Wrong default width %v", dw)
+	}
+	expected := "[10 [250 300 350] 20 23 600 24 [700] 30 [500.500000]]"
+	if w.DefaultWriteString() != expected {
+		t.Errorf("Wrong W array %s, expected %s", w.DefaultWriteString(), expected)
+	}
+
+	parsedDW, parsed, err := ParseCIDFontWidths(core.MakeFloat(dw), w)
+	if err != nil {
+		t.Errorf("Failed to parse widths: %v", err)
+		return
+	}
+	if parsedDW != dw {
+		t.Errorf("Wrong parsed default width %v", parsedDW)
+	}
+	for cid, width := range widths {
+		if width == dw {
+			continue
+		}
+		if parsed[cid] != width {
+			t.Errorf("Wrong parsed width for CID %d: %v != %v", cid, parsed[cid], width)
+		}
+	}
+	if len(parsed) != 9 {
+		t.Errorf("Wrong number of parsed widths %d", len(parsed))
+	}
+
+	// Without widths, the standard default is used.
+	dw, w = MakeCIDFontWidths(map[uint16]float64{})
+	if dw != 1000 || len(*w) != 0 {
+		t.Errorf("Wrong empty widths: %v %s", dw, w.DefaultWriteString())
+	}
+}
+
+func TestValidateCIDFontWidths(t *testing.T) {
+	ttf := &fonts.TtfType{UnitsPerEm: 2048, Widths: []uint16{1024, 1229, 2048}}
+
+	if err := ValidateCIDFontWidths(map[uint16]float64{0: 500, 1: 600, 2: 1000}, ttf, nil); err != nil {
+		t.Errorf("Unexpected validation failure: %v", err)
+	}
+	if err := ValidateCIDFontWidths(map[uint16]float64{1: 700}, ttf, nil); err == nil {
+		t.Errorf("Expected width mismatch")
+	}
+	if err := ValidateCIDFontWidths(map[uint16]float64{3: 500}, ttf, nil); err == nil {
+		t.Errorf("Expected glyph out of range")
+	}
+
+	// With a CID to glyph mapping.
+	cidToGID := map[uint16]uint16{100: 2}
+	if err := ValidateCIDFontWidths(map[uint16]float64{100: 1000}, ttf, cidToGID); err != nil {
+		t.Errorf("Unexpected validation failure: %v", err)
+	}
+	if err := ValidateCIDFontWidths(map[uint16]float64{101: 1000}, ttf, cidToGID); err == nil {
+		t.Errorf("Expected unmapped CID")
+	}
+}
@@ -14,4 +14,6 @@ var (
 	ErrInvalidAttribute         = errors.New("Invalid attribute")
 	ErrTypeError                = errors.New("Type check error")
 	ErrRangeError               = errors.New("Range check error")
+	ErrPermissionDenied         = errors.New("Permission denied")
+	ErrInvalidPageBox           = errors.New("Invalid page box")
 )
@@ -0,0 +1,169 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Number of parameters of the views of explicit destinations (Table 151 - p. 366).
+var destinationParams = map[PdfObjectName]int{
+	"XYZ":   3, // left top zoom
+	"Fit":   0,
+	"FitH":  1, // top
+	"FitV":  1, // left
+	"FitR":  4, // left bottom right top
+	"FitB":  0,
+	"FitBH": 1, // top
+	"FitBV": 1, // left
+}
+
+// PdfDestination is an explicit destination (12.3.2.2 Explicit Destinations): a page and the view of the page.
+type PdfDestination struct {
+	// The page object, or the page number (from 0) in the destinations of remote go-to actions.
+	Page PdfObject
+
+	// The view of the page: XYZ, Fit, FitH, FitV, FitR, FitB, FitBH or FitBV.
+	Fit PdfObjectName
+
+	// The parameters of the view, e.g. the left, top and zoom of XYZ.  Nil for the values left unchanged (null).
+	Params []*float64
+}
+
+// NewPdfDestinationFromObject returns the explicit destination of a destination array.  The missing parameters of
+// the view are nil.
+func NewPdfDestinationFromObject(obj PdfObject) (*PdfDestination, error) {
+	arr, ok := TraceToDirectObject(obj).(*PdfObjectArray)
+	if !ok || len(*arr) < 2 {
+		common.Log.Debug("ERROR: Invalid destination %v", obj)
+		return nil, ErrTypeError
+	}
+	fit, ok := TraceToDirectObject((*arr)[1]).(*PdfObjectName)
+	if !ok {
+		common.Log.Debug("ERROR: Invalid destination view %v", (*arr)[1])
+		return nil, ErrTypeError
+	}
+	numParams, ok := destinationParams[*fit]
+	if !ok {
+		common.Log.Debug("ERROR: Invalid destination view %s", *fit)
+		return nil, ErrInvalidAttribute
+	}
+
+	dest := &PdfDestination{Page: (*arr)[0], Fit: *fit, Params: make([]*float64, numParams)}
+	for i := range dest.Params {
+		if i+2 >= len(*arr) {
+			break
+		}
+		value, err := getNumberAsFloatOrNull(TraceToDirectObject((*arr)[i+2]))
+		if err != nil {
+			common.Log.Debug("Invalid destination parameter %v", (*arr)[i+2])
+			continue
+		}
+		dest.Params[i] = value
+	}
+	return dest, nil
+}
+
+// ToPdfObject returns the destination array.  The missing parameters of the view are null.
+func (this *PdfDestination) ToPdfObject() PdfObject {
+	arr := MakeArray(this.Page, MakeName(string(this.Fit)))
+	numParams := destinationParams[this.Fit]
+	for i := 0; i < numParams; i++ {
+		if i < len(this.Params) && this.Params[i] != nil {
+			arr.Append(MakeFloat(*this.Params[i]))
+		} else {
+			arr.Append(MakeNull())
+		}
+	}
+	return arr
+}
+
+// ResolveDestination returns the explicit destination of a destination of the document: an explicit destination
+// array, the name (name or string) of a named destination, or a destination dictionary (D entry), as found in the
+// link annotations, outline items and GoTo actions.  Returns nil if the destination is invalid or the name is not
+// defined.
+func (this *PdfReader) ResolveDestination(dest PdfObject) (*PdfDestination, error) {
+	dest, err := this.traceToObject(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+	switch t := TraceToDirectObject(dest).(type) {
+	case *PdfObjectName:
+		name = string(*t)
+	case *PdfObjectString:
+		name = string(*t)
+	}
+	if name != "" {
+		dest, err = this.lookupNamedDestination(name)
+		if err != nil {
+			return nil, err
+		}
+		if dest == nil {
+			common.Log.Debug("Named destination %q not defined", name)
+			return nil, nil
+		}
+	}
+	if dict, ok := TraceToDirectObject(dest).(*PdfObjectDictionary); ok {
+		dest, err = this.traceToObject(dict.Get("D"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = this.traverseObjectData(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := NewPdfDestinationFromObject(dest)
+	if err != nil {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// Returns the destination of the name in the Dests dictionary of the catalog or the Dests name tree, nil if not
+// defined.
+func (this *PdfReader) lookupNamedDestination(name string) (PdfObject, error) {
+	obj, err := this.traceToObject(this.catalog.Get("Dests"))
+	if err != nil {
+		return nil, err
+	}
+	if dests, ok := TraceToDirectObject(obj).(*PdfObjectDictionary); ok {
+		if dest := dests.Get(PdfObjectName(name)); dest != nil {
+			return this.traceToObject(dest)
+		}
+	}
+
+	entries, err := this.loadNameTree("Dests")
+	if err != nil {
+		return nil, err
+	}
+	if dest, ok := entries[name]; ok {
+		return this.traceToObject(dest)
+	}
+	return nil, nil
+}
+
+// GetPageNumber returns the number (from 1) of the page object in the document, e.g. of the page of a destination,
+// and 0 if not a page of the document.
+func (this *PdfReader) GetPageNumber(page PdfObject) int {
+	for i, pageObj := range this.pageList {
+		if pageObj == page {
+			return i + 1
+		}
+	}
+	if ref, ok := page.(*PdfObjectReference); ok {
+		for i, pageObj := range this.pageList {
+			if pageObj.ObjectNumber == ref.ObjectNumber && pageObj.GenerationNumber == ref.GenerationNumber {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
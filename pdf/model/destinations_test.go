@@ -0,0 +1,91 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestResolveDestination(t *testing.T) {
+	w := NewPdfWriter()
+	pages := []*PdfPage{}
+	for i := 0; i < 2; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 100}
+		page.Resources = NewPdfPageResources()
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		pages = append(pages, page)
+	}
+
+	top := 50.0
+	dest := &PdfDestination{Page: pages[1].GetPageAsIndirectObject(), Fit: "XYZ", Params: []*float64{nil, &top}}
+	w.AddNamedDestination("second", dest.ToPdfObject())
+	// Destination dictionary in the Dests dictionary of the catalog.
+	dests := MakeDict()
+	dict := MakeDict()
+	dict.Set("D", MakeArray(pages[0].GetPageAsIndirectObject(), MakeName("FitH"), MakeInteger(20)))
+	dests.Set("first", dict)
+	w.catalog.Set("Dests", dests)
+
+	var buf bytes.Buffer
+	err := w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	d, err := reader.ResolveDestination(MakeString("second"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if d == nil || reader.GetPageNumber(d.Page) != 2 || d.Fit != "XYZ" || len(d.Params) != 3 ||
+		d.Params[0] != nil || d.Params[1] == nil || *d.Params[1] != 50 || d.Params[2] != nil {
+		t.Errorf("Wrong destination %+v", d)
+	}
+
+	d, err = reader.ResolveDestination(MakeName("first"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if d == nil || reader.GetPageNumber(d.Page) != 1 || d.Fit != "FitH" || len(d.Params) != 1 ||
+		*d.Params[0] != 20 {
+		t.Errorf("Wrong destination %+v", d)
+	}
+
+	// Explicit destination, written back with the missing parameters.
+	d, err = reader.ResolveDestination(MakeArray(reader.pageList[0], MakeName("FitR"), MakeInteger(1)))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if d == nil || reader.GetPageNumber(d.Page) != 1 {
+		t.Fatalf("Wrong destination %+v", d)
+	}
+	if arr := d.ToPdfObject().(*PdfObjectArray); len(*arr) != 6 || (*arr)[5].String() != "null" {
+		t.Errorf("Wrong destination array %v", arr)
+	}
+
+	// Undefined name and invalid view.
+	d, err = reader.ResolveDestination(MakeString("third"))
+	if err != nil || d != nil {
+		t.Errorf("Undefined destination resolved %+v (%v)", d, err)
+	}
+	d, err = reader.ResolveDestination(MakeArray(reader.pageList[0], MakeName("Zoom")))
+	if err != nil || d != nil {
+		t.Errorf("Invalid destination resolved %+v (%v)", d, err)
+	}
+	if reader.GetPageNumber(MakeDict()) != 0 {
+		t.Errorf("Not a page of the document")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// GetDocInfo returns the values of the document information dictionary (Info) by key, e.g. Title, Author or
+// CreationDate, decoded as text strings.  Entries other than strings are skipped.  Empty if the document has no
+// information dictionary.
+func (this *PdfReader) GetDocInfo() (map[string]string, error) {
+	info := map[string]string{}
+	trailer, err := this.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := traceDirect(this, trailer.Get("Info")).(*PdfObjectDictionary)
+	if !ok {
+		return info, nil
+	}
+	for _, key := range dict.Keys() {
+		if val, ok := GetTextString(traceDirect(this, dict.Get(key))); ok {
+			info[string(key)] = val
+		}
+	}
+	return info, nil
+}
+
+// SetDocInfo sets the value of the entry of the document information dictionary (Info) with the key, e.g. Title or
+// Author, encoded as a text string.  The entry is removed if the value is empty.
+func (this *PdfWriter) SetDocInfo(key, value string) {
+	dict := this.infoObj.PdfObject.(*PdfObjectDictionary)
+	if value == "" {
+		dict.Remove(PdfObjectName(key))
+		return
+	}
+	dict.Set(PdfObjectName(key), MakeTextString(value))
+}
+
+// GetDocInfo returns the values of the document information dictionary (Info) to be written by key, including the
+// default Producer and Creator.
+func (this *PdfWriter) GetDocInfo() map[string]string {
+	info := map[string]string{}
+	dict := this.infoObj.PdfObject.(*PdfObjectDictionary)
+	for _, key := range dict.Keys() {
+		if val, ok := GetTextString(dict.Get(key)); ok {
+			info[string(key)] = val
+		}
+	}
+	return info
+}
+
+// GetMetadata returns the XMP metadata of the document (the Metadata stream of the catalog), decoded.  Nil if the
+// document has no metadata stream.
+func (this *PdfReader) GetMetadata() ([]byte, error) {
+	stream, ok := traceDirect(this, this.catalog.Get("Metadata")).(*PdfObjectStream)
+	if !ok {
+		return nil, nil
+	}
+	return DecodeStream(stream)
+}
@@ -0,0 +1,287 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfDSS is the document security store (DSS) of a document: the validation data of its signatures, i.e. the DER
+// encoded certificates, OCSP responses and CRLs needed to validate them once the certificates have expired or been
+// revoked (Long-Term Validation, PAdES).  Embedding the validation data of a signature and then adding a document
+// timestamp keeps the signature verifiable.
+type PdfDSS struct {
+	Certs [][]byte
+	OCSPs [][]byte
+	CRLs  [][]byte
+
+	// The validation data of each signature, by VRI key (GetVRIKey).
+	VRI map[string]*PdfVRI
+}
+
+// PdfVRI is the validation data related to a single signature, also contained in the DSS.
+type PdfVRI struct {
+	Certs [][]byte
+	OCSPs [][]byte
+	CRLs  [][]byte
+}
+
+// NewPdfDSS returns a new empty document security store.
+func NewPdfDSS() *PdfDSS {
+	return &PdfDSS{VRI: map[string]*PdfVRI{}}
+}
+
+// GetVRIKey returns the key of the validation data of the signature in the VRI dictionary: the uppercase hexadecimal
+// SHA-1 digest of the signature value, i.e. the bytes of its Contents.
+func GetVRIKey(signatureContents []byte) string {
+	digest := sha1.Sum(signatureContents)
+	return strings.ToUpper(hex.EncodeToString(digest[:]))
+}
+
+// AddValidationData adds the certificates, OCSP responses and CRLs to the DSS, skipping those already present.  If
+// the signature value is not nil, they are also recorded as the validation data of that signature.
+func (this *PdfDSS) AddValidationData(signatureContents []byte, certs, ocsps, crls [][]byte) {
+	this.Certs = appendUnique(this.Certs, certs)
+	this.OCSPs = appendUnique(this.OCSPs, ocsps)
+	this.CRLs = appendUnique(this.CRLs, crls)
+	if signatureContents == nil {
+		return
+	}
+
+	if this.VRI == nil {
+		this.VRI = map[string]*PdfVRI{}
+	}
+	key := GetVRIKey(signatureContents)
+	vri, has := this.VRI[key]
+	if !has {
+		vri = &PdfVRI{}
+		this.VRI[key] = vri
+	}
+	vri.Certs = appendUnique(vri.Certs, certs)
+	vri.OCSPs = appendUnique(vri.OCSPs, ocsps)
+	vri.CRLs = appendUnique(vri.CRLs, crls)
+}
+
+// appendUnique appends the entries not contained in list to it.
+func appendUnique(list [][]byte, entries [][]byte) [][]byte {
+	for _, entry := range entries {
+		found := false
+		for _, existing := range list {
+			if bytes.Equal(existing, entry) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// GetDSS returns the document security store of the document, or nil if it has none.
+func (this *PdfReader) GetDSS() (*PdfDSS, error) {
+	dict, ok := traceDirect(this, this.catalog.Get("DSS")).(*PdfObjectDictionary)
+	if !ok {
+		return nil, nil
+	}
+
+	dss := NewPdfDSS()
+	var err error
+	if dss.Certs, err = this.loadDSSStreams(dict.Get("Certs")); err != nil {
+		return nil, err
+	}
+	if dss.OCSPs, err = this.loadDSSStreams(dict.Get("OCSPs")); err != nil {
+		return nil, err
+	}
+	if dss.CRLs, err = this.loadDSSStreams(dict.Get("CRLs")); err != nil {
+		return nil, err
+	}
+
+	if vriDict, ok := traceDirect(this, dict.Get("VRI")).(*PdfObjectDictionary); ok {
+		for _, key := range vriDict.Keys() {
+			entry, ok := traceDirect(this, vriDict.Get(key)).(*PdfObjectDictionary)
+			if !ok {
+				common.Log.Debug("Invalid VRI entry %s, skipping", key)
+				continue
+			}
+			vri := &PdfVRI{}
+			if vri.Certs, err = this.loadDSSStreams(entry.Get("Cert")); err != nil {
+				return nil, err
+			}
+			if vri.OCSPs, err = this.loadDSSStreams(entry.Get("OCSP")); err != nil {
+				return nil, err
+			}
+			if vri.CRLs, err = this.loadDSSStreams(entry.Get("CRL")); err != nil {
+				return nil, err
+			}
+			dss.VRI[strings.ToUpper(string(key))] = vri
+		}
+	}
+
+	return dss, nil
+}
+
+// loadDSSStreams returns the decoded data of the streams of an array of the DSS.
+func (this *PdfReader) loadDSSStreams(obj PdfObject) ([][]byte, error) {
+	arr, ok := traceDirect(this, obj).(*PdfObjectArray)
+	if !ok {
+		return nil, nil
+	}
+
+	list := [][]byte{}
+	for _, elem := range *arr {
+		streamObj, err := this.traceToObject(elem)
+		if err != nil {
+			return nil, err
+		}
+		stream, ok := streamObj.(*PdfObjectStream)
+		if !ok {
+			common.Log.Debug("ERROR: DSS entry not a stream (%T)", streamObj)
+			return nil, errors.New("Invalid DSS entry")
+		}
+		data, err := DecodeStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, data)
+	}
+	return list, nil
+}
+
+// ToPdfObject returns the DSS dictionary.  Identical entries are written once and shared between the arrays of the
+// DSS and the VRI dictionaries.
+func (this *PdfDSS) ToPdfObject() (PdfObject, error) {
+	streams := map[string]*PdfObjectStream{}
+	makeArray := func(list [][]byte) (*PdfObjectArray, error) {
+		arr := PdfObjectArray{}
+		for _, data := range list {
+			stream, has := streams[string(data)]
+			if !has {
+				var err error
+				stream, err = MakeStream(data, NewFlateEncoder())
+				if err != nil {
+					return nil, err
+				}
+				streams[string(data)] = stream
+			}
+			arr = append(arr, stream)
+		}
+		return &arr, nil
+	}
+
+	dict := MakeDict()
+	dict.Set("Type", MakeName("DSS"))
+	for _, entry := range []struct {
+		key  PdfObjectName
+		list [][]byte
+	}{
+		{"Certs", this.Certs},
+		{"OCSPs", this.OCSPs},
+		{"CRLs", this.CRLs},
+	} {
+		if len(entry.list) == 0 {
+			continue
+		}
+		arr, err := makeArray(entry.list)
+		if err != nil {
+			return nil, err
+		}
+		dict.Set(entry.key, arr)
+	}
+
+	if len(this.VRI) > 0 {
+		keys := []string{}
+		for key := range this.VRI {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		vriDict := MakeDict()
+		for _, key := range keys {
+			vri := this.VRI[key]
+			entryDict := MakeDict()
+			for _, entry := range []struct {
+				key  PdfObjectName
+				list [][]byte
+			}{
+				{"Cert", vri.Certs},
+				{"OCSP", vri.OCSPs},
+				{"CRL", vri.CRLs},
+			} {
+				if len(entry.list) == 0 {
+					continue
+				}
+				arr, err := makeArray(entry.list)
+				if err != nil {
+					return nil, err
+				}
+				entryDict.Set(entry.key, arr)
+			}
+			vriDict.Set(PdfObjectName(strings.ToUpper(key)), entryDict)
+		}
+		dict.Set("VRI", vriDict)
+	}
+
+	return MakeIndirectObject(dict), nil
+}
+
+// SetDSS sets the document security store of the document in the update, replacing the existing one if any.  Use
+// GetDSS to extend the existing validation data.
+func (tx *PdfTransaction) SetDSS(dss *PdfDSS) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+
+	obj, err := dss.ToPdfObject()
+	if err != nil {
+		return err
+	}
+	catalog, err := tx.getCatalog()
+	if err != nil {
+		return err
+	}
+	catalog.Set("DSS", obj)
+	return nil
+}
+
+// getCatalog returns the catalog dictionary staged for modification.
+func (tx *PdfTransaction) getCatalog() (*PdfObjectDictionary, error) {
+	trailer, err := tx.reader.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := trailer.Get("Root").(*PdfObjectReference)
+	if !ok {
+		common.Log.Debug("ERROR: Catalog not an indirect object (%T)", trailer.Get("Root"))
+		return nil, errors.New("Invalid catalog")
+	}
+	return tx.getDict(root.ObjectNumber)
+}
+
+// getDict returns the dictionary of the indirect object with the number, staged for modification.
+func (tx *PdfTransaction) getDict(objNum int64) (*PdfObjectDictionary, error) {
+	obj, err := tx.GetObject(objNum)
+	if err != nil {
+		return nil, err
+	}
+	ind, ok := obj.(*PdfIndirectObject)
+	if !ok {
+		return nil, ErrTypeError
+	}
+	dict, ok := ind.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return nil, ErrTypeError
+	}
+	return dict, nil
+}
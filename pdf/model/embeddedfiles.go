@@ -0,0 +1,286 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// EmbeddedFile is a file embedded in a PDF document (attachment), listed in the EmbeddedFiles name tree of the
+// document catalog.
+type EmbeddedFile struct {
+	// The name of the file.
+	Name string
+
+	// Optional description of the file.
+	Description string
+
+	// Optional MIME type of the file, e.g. "application/pdf".
+	MimeType string
+
+	// The content of the file.
+	Data []byte
+
+	// Optional modification time of the file.
+	ModTime time.Time
+
+	// Optional relationship of the file to the document (PDF 2.0 and PDF/A-3 associated files).  Files with a
+	// relationship are listed in the AF array of the catalog.
+	AFRelationship AFRelationship
+}
+
+// AFRelationship is the relationship of an associated file to the document.
+type AFRelationship string
+
+const (
+	// The original source material of the content, e.g. a spreadsheet of a chart.
+	AFRelationshipSource AFRelationship = "Source"
+	// Information used to derive a visual presentation, e.g. the data of a table.
+	AFRelationshipData AFRelationship = "Data"
+	// An alternative representation of the content, e.g. audio.
+	AFRelationshipAlternative AFRelationship = "Alternative"
+	// A supplemental representation of the original source or data.
+	AFRelationshipSupplement AFRelationship = "Supplement"
+	// The relationship is not known or none of the above.
+	AFRelationshipUnspecified AFRelationship = "Unspecified"
+)
+
+// Layout of PDF dates (see 7.9.4 Dates) for time.Format and time.Parse.
+const pdfDateLayout = "D:20060102150405Z07'00'"
+
+// Makes the file specification dictionary of the embedded file, with a flate encoded embedded file stream.
+func (this *EmbeddedFile) toPdfObject() (*PdfIndirectObject, error) {
+	stream, err := MakeStream(this.Data, NewFlateEncoder())
+	if err != nil {
+		return nil, err
+	}
+	stream.PdfObjectDictionary.Set("Type", MakeName("EmbeddedFile"))
+	if this.MimeType != "" {
+		stream.PdfObjectDictionary.Set("Subtype", MakeName(this.MimeType))
+	}
+	params := MakeDict()
+	params.Set("Size", MakeInteger(int64(len(this.Data))))
+	if !this.ModTime.IsZero() {
+		params.Set("ModDate", MakeString(this.ModTime.Format(pdfDateLayout)))
+	}
+	stream.PdfObjectDictionary.Set("Params", params)
+
+	ef := MakeDict()
+	ef.Set("F", stream)
+
+	dict := MakeDict()
+	dict.Set("Type", MakeName("Filespec"))
+	dict.Set("F", MakeString(this.Name))
+	dict.Set("UF", MakeString(this.Name))
+	if this.Description != "" {
+		dict.Set("Desc", MakeString(this.Description))
+	}
+	dict.Set("EF", ef)
+	if this.AFRelationship != "" {
+		dict.Set("AFRelationship", MakeName(string(this.AFRelationship)))
+	}
+
+	return MakeIndirectObject(dict), nil
+}
+
+// ToFileSpec returns the file specification dictionary of the file with its embedded file stream, e.g. for the FS
+// entry of file attachment annotations.
+func (this *EmbeddedFile) ToFileSpec() (*PdfIndirectObject, error) {
+	return this.toPdfObject()
+}
+
+// AddEmbeddedFile embeds a file in the document.  With the EmbeddedFilesOnly encryption option, only the embedded
+// files are encrypted.
+func (this *PdfWriter) AddEmbeddedFile(file *EmbeddedFile) {
+	this.embeddedFiles = append(this.embeddedFiles, file)
+}
+
+// Sets the EmbeddedFiles name tree in the names dictionary of the catalog, with the names sorted as required.
+func (this *PdfWriter) writeEmbeddedFiles() error {
+	files := append([]*EmbeddedFile{}, this.embeddedFiles...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	names := MakeArray()
+	af := MakeArray()
+	for _, file := range files {
+		filespec, err := file.toPdfObject()
+		if err != nil {
+			common.Log.Debug("ERROR: Unable to embed file %s: %v", file.Name, err)
+			return err
+		}
+		*names = append(*names, MakeString(file.Name), filespec)
+		if file.AFRelationship != "" {
+			af.Append(filespec)
+		}
+	}
+
+	// The associated files of the document.
+	if len(*af) > 0 {
+		this.catalog.Set("AF", af)
+		err := this.addObjects(af)
+		if err != nil {
+			return err
+		}
+	}
+
+	return this.setNameTree("EmbeddedFiles", names)
+}
+
+// GetEmbeddedFiles returns the files embedded in the document, listed in the EmbeddedFiles name tree.  If only the
+// embedded files of the document are encrypted, the document must be decrypted first.
+func (this *PdfReader) GetEmbeddedFiles() ([]*EmbeddedFile, error) {
+	crypter := this.parser.GetCrypter()
+	if crypter != nil && !this.parser.IsAuthenticated() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	files := []*EmbeddedFile{}
+	namesDict, ok := TraceToDirectObject(this.catalog.Get("Names")).(*PdfObjectDictionary)
+	if !ok {
+		return files, nil
+	}
+	obj, err := this.traceToObject(namesDict.Get("EmbeddedFiles"))
+	if err != nil {
+		return nil, err
+	}
+	tree, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		return files, nil
+	}
+
+	err = this.loadEmbeddedFiles(tree, &files, map[PdfObject]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Loads the embedded files of a node of the EmbeddedFiles name tree and its kids.
+func (this *PdfReader) loadEmbeddedFiles(node *PdfObjectDictionary, files *[]*EmbeddedFile, visited map[PdfObject]bool) error {
+	if visited[node] {
+		common.Log.Debug("ERROR: Circular reference in name tree")
+		return errors.New("Circular reference in name tree")
+	}
+	visited[node] = true
+
+	if names, ok := TraceToDirectObject(node.Get("Names")).(*PdfObjectArray); ok {
+		for i := 0; i+1 < len(*names); i += 2 {
+			obj, err := this.traceToObject((*names)[i+1])
+			if err != nil {
+				return err
+			}
+			dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+			if !ok {
+				common.Log.Debug("ERROR: Invalid file specification (%T)", obj)
+				return ErrTypeError
+			}
+			file, err := this.newEmbeddedFileFromDict(dict)
+			if err != nil {
+				return err
+			}
+			if file == nil {
+				continue
+			}
+			if file.Name == "" {
+				if name, ok := TraceToDirectObject((*names)[i]).(*PdfObjectString); ok {
+					file.Name = string(*name)
+				}
+			}
+			*files = append(*files, file)
+		}
+	}
+
+	if kids, ok := TraceToDirectObject(node.Get("Kids")).(*PdfObjectArray); ok {
+		for _, kid := range *kids {
+			obj, err := this.traceToObject(kid)
+			if err != nil {
+				return err
+			}
+			kidDict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+			if !ok {
+				common.Log.Debug("ERROR: Invalid name tree node (%T)", obj)
+				return ErrTypeError
+			}
+			err = this.loadEmbeddedFiles(kidDict, files, visited)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Loads an embedded file from a file specification dictionary.  Returns nil if the file specification does not
+// have an embedded file stream.
+func (this *PdfReader) newEmbeddedFileFromDict(dict *PdfObjectDictionary) (*EmbeddedFile, error) {
+	ef, ok := TraceToDirectObject(dict.Get("EF")).(*PdfObjectDictionary)
+	if !ok {
+		return nil, nil
+	}
+	streamObj := ef.Get("UF")
+	if streamObj == nil {
+		streamObj = ef.Get("F")
+	}
+	obj, err := this.traceToObject(streamObj)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*PdfObjectStream)
+	if !ok {
+		common.Log.Debug("ERROR: Invalid embedded file stream (%T)", obj)
+		return nil, ErrTypeError
+	}
+
+	// The stream may have been loaded before authentication and left encrypted.
+	if crypter := this.parser.GetCrypter(); crypter != nil {
+		err = crypter.Decrypt(stream, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data, err := DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to decode embedded file: %v", err)
+		return nil, err
+	}
+
+	file := &EmbeddedFile{Data: data}
+	for _, key := range []PdfObjectName{"UF", "F"} {
+		if name, ok := TraceToDirectObject(dict.Get(key)).(*PdfObjectString); ok {
+			file.Name = string(*name)
+			break
+		}
+	}
+	if desc, ok := TraceToDirectObject(dict.Get("Desc")).(*PdfObjectString); ok {
+		file.Description = string(*desc)
+	}
+	if subtype, ok := TraceToDirectObject(stream.PdfObjectDictionary.Get("Subtype")).(*PdfObjectName); ok {
+		file.MimeType = string(*subtype)
+	}
+	if rel, ok := TraceToDirectObject(dict.Get("AFRelationship")).(*PdfObjectName); ok {
+		file.AFRelationship = AFRelationship(*rel)
+	}
+	if params, ok := TraceToDirectObject(stream.PdfObjectDictionary.Get("Params")).(*PdfObjectDictionary); ok {
+		if modDate, ok := TraceToDirectObject(params.Get("ModDate")).(*PdfObjectString); ok {
+			modTime, err := time.Parse(pdfDateLayout, string(*modDate))
+			if err != nil {
+				common.Log.Debug("Invalid embedded file modification date %s", *modDate)
+			} else {
+				file.ModTime = modTime
+			}
+		}
+	}
+
+	return file, nil
+}
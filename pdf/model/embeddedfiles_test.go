@@ -0,0 +1,187 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Writes a single page document with an embedded file, encrypted if options are given.
+func writeEmbeddedFileDoc(t *testing.T, file *EmbeddedFile, options *EncryptOptions) []byte {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.Resources = NewPdfPageResources()
+	err := page.SetContentStreams([]string{"BT /F1 12 Tf 10 10 Td (Cover) Tj ET"}, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = w.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w.AddEmbeddedFile(file)
+	if options != nil {
+		err = w.Encrypt([]byte("secret"), []byte("owner"), options)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// Minimal io.WriteSeeker over a buffer, only supporting appending.
+type writeSeeker struct {
+	buf *bytes.Buffer
+}
+
+func (ws *writeSeeker) Write(p []byte) (int, error) {
+	return ws.buf.Write(p)
+}
+
+func (ws *writeSeeker) Seek(offset int64, whence int) (int64, error) {
+	return int64(ws.buf.Len()), nil
+}
+
+// Checks the embedded files of a document.
+func checkEmbeddedFiles(t *testing.T, reader *PdfReader, expected *EmbeddedFile) {
+	files, err := reader.GetEmbeddedFiles()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 embedded file, got %d", len(files))
+	}
+	file := files[0]
+	if file.Name != expected.Name || file.Description != expected.Description || file.MimeType != expected.MimeType {
+		t.Errorf("Wrong embedded file %s %q %s", file.Name, file.Description, file.MimeType)
+	}
+	if !bytes.Equal(file.Data, expected.Data) {
+		t.Errorf("Wrong embedded file data %q", file.Data)
+	}
+}
+
+// Checks the content of the page of a document (followed by the license watermark).
+func checkCoverPage(t *testing.T, reader *PdfReader) {
+	page, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.HasPrefix(contents, "BT /F1 12 Tf 10 10 Td (Cover) Tj ET") {
+		t.Errorf("Wrong page content %q", contents)
+	}
+}
+
+func TestEmbeddedFiles(t *testing.T) {
+	file := &EmbeddedFile{
+		Name:        "payload.txt",
+		Description: "Payload",
+		MimeType:    "text/plain",
+		Data:        []byte("Secret payload data"),
+	}
+	data := writeEmbeddedFileDoc(t, file, nil)
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkCoverPage(t, reader)
+	checkEmbeddedFiles(t, reader, file)
+}
+
+// Test a document where only the embedded files are encrypted (envelope): the pages can be read without password.
+func TestEncryptedEmbeddedFiles(t *testing.T) {
+	file := &EmbeddedFile{
+		Name: "payload.txt",
+		Data: []byte("Secret payload data"),
+	}
+	data := writeEmbeddedFileDoc(t, file, &EncryptOptions{EmbeddedFilesOnly: true})
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	crypter := reader.parser.GetCrypter()
+	if crypter == nil || !crypter.EncryptsEmbeddedFilesOnly() {
+		t.Fatalf("Expected only the embedded files to be encrypted")
+	}
+	if crypter.CryptFilters[crypter.EmbeddedFileFilter].Cfm != "AESV2" {
+		t.Errorf("Wrong embedded file filter %s", crypter.EmbeddedFileFilter)
+	}
+	checkCoverPage(t, reader)
+
+	_, err = reader.GetEmbeddedFiles()
+	if err == nil {
+		t.Errorf("Embedded files should not be accessible without password")
+	}
+
+	success, err := reader.Decrypt([]byte("wrong"))
+	if err != nil || success {
+		t.Fatalf("Decrypted with wrong password (%v)", err)
+	}
+	success, err = reader.Decrypt([]byte("secret"))
+	if err != nil || !success {
+		t.Fatalf("Failed to decrypt (%v)", err)
+	}
+	checkCoverPage(t, reader)
+	checkEmbeddedFiles(t, reader, file)
+	if n, _ := reader.GetNumPages(); len(reader.PageList) != n {
+		t.Errorf("Page list (%d) does not match page count (%d)", len(reader.PageList), n)
+	}
+}
+
+// Test associated files: files with a relationship are listed in the AF array of the catalog.
+func TestAssociatedFiles(t *testing.T) {
+	modTime := time.Date(2018, 3, 14, 15, 9, 26, 0, time.FixedZone("", 3600))
+	file := &EmbeddedFile{
+		Name:           "data.csv",
+		MimeType:       "text/csv",
+		Data:           []byte("a,b\n1,2\n"),
+		ModTime:        modTime,
+		AFRelationship: AFRelationshipSource,
+	}
+	data := writeEmbeddedFileDoc(t, file, nil)
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkEmbeddedFiles(t, reader, file)
+	files, _ := reader.GetEmbeddedFiles()
+	if files[0].AFRelationship != AFRelationshipSource || !files[0].ModTime.Equal(modTime) {
+		t.Errorf("Wrong associated file %s %v", files[0].AFRelationship, files[0].ModTime)
+	}
+
+	af, ok := TraceToDirectObject(reader.catalog.Get("AF")).(*PdfObjectArray)
+	if !ok || len(*af) != 1 {
+		t.Fatalf("Wrong AF array %v", reader.catalog.Get("AF"))
+	}
+	obj, err := reader.traceToObject((*af)[0])
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	filespec, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Wrong associated file specification %v", (*af)[0])
+	}
+	if name, ok := filespec.Get("F").(*PdfObjectString); !ok || string(*name) != "data.csv" {
+		t.Errorf("Wrong associated file specification %v", filespec)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// FacturXProfile is the conformance level of a Factur-X (ZUGFeRD 2) invoice.
+type FacturXProfile string
+
+const (
+	FacturXMinimum   FacturXProfile = "MINIMUM"
+	FacturXBasicWL   FacturXProfile = "BASIC WL"
+	FacturXBasic     FacturXProfile = "BASIC"
+	FacturXEN16931   FacturXProfile = "EN 16931"
+	FacturXExtended  FacturXProfile = "EXTENDED"
+	FacturXXRechnung FacturXProfile = "XRECHNUNG"
+)
+
+// The Factur-X XMP extension schema namespace.
+const facturXNamespace = "urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#"
+
+// isValid returns true if the profile is a known Factur-X profile.
+func (profile FacturXProfile) isValid() bool {
+	switch profile {
+	case FacturXMinimum, FacturXBasicWL, FacturXBasic, FacturXEN16931, FacturXExtended, FacturXXRechnung:
+		return true
+	}
+	return false
+}
+
+// fileName returns the name of the invoice attachment required for the profile.
+func (profile FacturXProfile) fileName() string {
+	if profile == FacturXXRechnung {
+		return "xrechnung.xml"
+	}
+	return "factur-x.xml"
+}
+
+// NewFacturXAttachment returns the attachment of a Factur-X invoice XML (CII) of a profile.  The MINIMUM and BASIC WL
+// profiles do not contain a full invoice and are attached as Data, the others as an Alternative of the document.
+func NewFacturXAttachment(xml []byte, profile FacturXProfile) *EmbeddedFile {
+	file := &EmbeddedFile{
+		Name:           profile.fileName(),
+		Description:    "Factur-X invoice",
+		MimeType:       "text/xml",
+		Data:           xml,
+		AFRelationship: AFRelationshipAlternative,
+	}
+	if profile == FacturXMinimum || profile == FacturXBasicWL {
+		file.AFRelationship = AFRelationshipData
+	}
+	return file
+}
+
+// MakeFacturXMetadata returns the XMP metadata of a Factur-X invoice document of a profile: the PDF/A-3B
+// identification and the Factur-X properties with their extension schema.
+func MakeFacturXMetadata(profile FacturXProfile) []byte {
+	property := func(name, description string) string {
+		return fmt.Sprintf(`
+              <rdf:li rdf:parseType="Resource">
+                <pdfaProperty:name>%s</pdfaProperty:name>
+                <pdfaProperty:valueType>Text</pdfaProperty:valueType>
+                <pdfaProperty:category>external</pdfaProperty:category>
+                <pdfaProperty:description>%s</pdfaProperty:description>
+              </rdf:li>`, name, description)
+	}
+
+	return []byte(`<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+  <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+    <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+      <pdfaid:part>3</pdfaid:part>
+      <pdfaid:conformance>B</pdfaid:conformance>
+    </rdf:Description>
+    <rdf:Description rdf:about="" xmlns:fx="` + facturXNamespace + `">
+      <fx:DocumentType>INVOICE</fx:DocumentType>
+      <fx:DocumentFileName>` + profile.fileName() + `</fx:DocumentFileName>
+      <fx:Version>1.0</fx:Version>
+      <fx:ConformanceLevel>` + string(profile) + `</fx:ConformanceLevel>
+    </rdf:Description>
+    <rdf:Description rdf:about=""
+        xmlns:pdfaExtension="http://www.aiim.org/pdfa/ns/extension/"
+        xmlns:pdfaSchema="http://www.aiim.org/pdfa/ns/schema#"
+        xmlns:pdfaProperty="http://www.aiim.org/pdfa/ns/property#">
+      <pdfaExtension:schemas>
+        <rdf:Bag>
+          <rdf:li rdf:parseType="Resource">
+            <pdfaSchema:schema>Factur-X PDFA Extension Schema</pdfaSchema:schema>
+            <pdfaSchema:namespaceURI>` + facturXNamespace + `</pdfaSchema:namespaceURI>
+            <pdfaSchema:prefix>fx</pdfaSchema:prefix>
+            <pdfaSchema:property>
+              <rdf:Seq>` +
+		property("DocumentFileName", "The name of the embedded XML document") +
+		property("DocumentType", "The type of the hybrid document in capital letters, e.g. INVOICE or ORDER") +
+		property("Version", "The actual version of the standard applying to the embedded XML document") +
+		property("ConformanceLevel", "The conformance level of the embedded XML document") + `
+              </rdf:Seq>
+            </pdfaSchema:property>
+          </rdf:li>
+        </rdf:Bag>
+      </pdfaExtension:schemas>
+    </rdf:Description>
+  </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`)
+}
+
+// AddFacturXInvoice attaches a Factur-X invoice XML to the document and sets the corresponding metadata.  The
+// document itself must otherwise conform to PDF/A-3 (embedded fonts, output intent), which is not checked.
+func (this *PdfWriter) AddFacturXInvoice(xml []byte, profile FacturXProfile) error {
+	if !profile.isValid() {
+		common.Log.Debug("ERROR: Invalid Factur-X profile %s", profile)
+		return ErrInvalidAttribute
+	}
+
+	this.AddEmbeddedFile(NewFacturXAttachment(xml, profile))
+	err := this.SetMetadata(MakeFacturXMetadata(profile))
+	if err != nil {
+		return err
+	}
+
+	// PDF/A-3 is based on PDF 1.7.
+	if this.majorVersion == 1 && this.minorVersion < 7 {
+		this.SetVersion(1, 7)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestFacturXInvoice(t *testing.T) {
+	invoice := []byte(`<?xml version="1.0" encoding="UTF-8"?><rsm:CrossIndustryInvoice/>`)
+
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.Resources = NewPdfPageResources()
+	err := w.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = w.AddFacturXInvoice(invoice, FacturXProfile("PREMIUM"))
+	if err == nil {
+		t.Errorf("Invoice with an invalid profile added")
+	}
+	err = w.AddFacturXInvoice(invoice, FacturXEN16931)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.7")) {
+		t.Errorf("Wrong version %q", buf.Bytes()[:8])
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkEmbeddedFiles(t, reader, &EmbeddedFile{
+		Name:        "factur-x.xml",
+		Description: "Factur-X invoice",
+		MimeType:    "text/xml",
+		Data:        invoice,
+	})
+	files, _ := reader.GetEmbeddedFiles()
+	if files[0].AFRelationship != AFRelationshipAlternative {
+		t.Errorf("Wrong relationship %s", files[0].AFRelationship)
+	}
+
+	obj, err := reader.traceToObject(reader.catalog.Get("Metadata"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	metadata, ok := obj.(*PdfObjectStream)
+	if !ok {
+		t.Fatalf("Missing metadata")
+	}
+	xmp := string(metadata.Stream)
+	for _, s := range []string{"<pdfaid:part>3</pdfaid:part>", "<fx:ConformanceLevel>EN 16931</fx:ConformanceLevel>",
+		"<fx:DocumentFileName>factur-x.xml</fx:DocumentFileName>"} {
+		if !strings.Contains(xmp, s) {
+			t.Errorf("Metadata missing %s", s)
+		}
+	}
+
+	if file := NewFacturXAttachment(invoice, FacturXMinimum); file.AFRelationship != AFRelationshipData {
+		t.Errorf("Wrong relationship of minimum profile %s", file.AFRelationship)
+	}
+	if file := NewFacturXAttachment(invoice, FacturXXRechnung); file.Name != "xrechnung.xml" {
+		t.Errorf("Wrong XRechnung file name %s", file.Name)
+	}
+}
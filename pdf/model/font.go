@@ -6,8 +6,9 @@
 package model
 
 import (
+	"bytes"
 	"errors"
-
+	"fmt"
 	"io/ioutil"
 
 	"github.com/unidoc/unidoc/common"
@@ -33,6 +34,19 @@ func (font PdfFont) SetEncoder(encoder textencoding.TextEncoder) {
 	}
 }
 
+// UpdateWidths regenerates the FirstChar, LastChar and Widths entries of the underlying font from its encoder and
+// the glyph metrics of the embedded font program.  Called automatically by SetEncoder; call after replacing the font
+// program, e.g. by a subset.
+func (font PdfFont) UpdateWidths() error {
+	switch t := font.context.(type) {
+	case *pdfFontTrueType:
+		return t.updateWidths(nil)
+	}
+
+	common.Log.Debug("Unsupported font (%T) - widths not updated", font.context)
+	return errors.New("Unsupported font type")
+}
+
 func (font PdfFont) GetGlyphCharMetrics(glyph string) (fonts.CharMetrics, bool) {
 	switch t := font.context.(type) {
 	case *pdfFontTrueType:
@@ -42,7 +56,10 @@ func (font PdfFont) GetGlyphCharMetrics(glyph string) (fonts.CharMetrics, bool)
 	return fonts.CharMetrics{}, false
 }
 
-func newPdfFontFromPdfObject(obj core.PdfObject) (*PdfFont, error) {
+// NewPdfFontFromPdfObject loads the font from its dictionary, either a *PdfIndirectObject or a
+// *PdfObjectDictionary.  TrueType fonts are fully loaded; fonts of the other types are loaded as is, with their font
+// descriptor (of the descendant CIDFont for Type0 fonts), to give access to their font programs.
+func NewPdfFontFromPdfObject(obj core.PdfObject) (*PdfFont, error) {
 	font := &PdfFont{}
 
 	dictObj := obj
@@ -67,15 +84,15 @@ func newPdfFontFromPdfObject(obj core.PdfObject) (*PdfFont, error) {
 		return nil, errors.New("Required attribute missing")
 	}
 
-	obj = d.Get("Subtype")
-	if obj == nil {
+	subtypeObj := d.Get("Subtype")
+	if subtypeObj == nil {
 		common.Log.Debug("Incompatibility ERROR: Subtype (Required) missing")
 		return nil, errors.New("Required attribute missing")
 	}
 
-	subtype, ok := core.TraceToDirectObject(obj).(*core.PdfObjectName)
+	subtype, ok := core.TraceToDirectObject(subtypeObj).(*core.PdfObjectName)
 	if !ok {
-		common.Log.Debug("Incompatibility ERROR: subtype not a name (%T) ", obj)
+		common.Log.Debug("Incompatibility ERROR: subtype not a name (%T) ", subtypeObj)
 		return nil, errors.New("Type check error")
 	}
 
@@ -83,11 +100,19 @@ func newPdfFontFromPdfObject(obj core.PdfObject) (*PdfFont, error) {
 	case "TrueType":
 		truefont, err := newPdfFontTrueTypeFromPdfObject(obj)
 		if err != nil {
-			common.Log.Debug("Error loading truetype font: %v", truefont)
+			common.Log.Debug("Error loading truetype font: %v", err)
 			return nil, err
 		}
 
 		font.context = truefont
+	case "Type0", "Type1", "MMType1", "Type3", "CIDFontType0", "CIDFontType2":
+		generic, err := newPdfFontGenericFromPdfObject(obj, d)
+		if err != nil {
+			common.Log.Debug("Error loading %s font: %v", subtype.String(), err)
+			return nil, err
+		}
+
+		font.context = generic
 	default:
 		common.Log.Debug("Unsupported font type: %s", subtype.String())
 		return nil, errors.New("Unsupported font type")
@@ -100,6 +125,8 @@ func (font PdfFont) ToPdfObject() core.PdfObject {
 	switch f := font.context.(type) {
 	case *pdfFontTrueType:
 		return f.ToPdfObject()
+	case *pdfFontGeneric:
+		return f.ToPdfObject()
 	}
 
 	// If not supported, return null..
@@ -107,6 +134,85 @@ func (font PdfFont) ToPdfObject() core.PdfObject {
 	return core.MakeNull()
 }
 
+// fontDescriptor returns the font descriptor of the underlying font, or nil if none.
+func (font PdfFont) fontDescriptor() *PdfFontDescriptor {
+	switch f := font.context.(type) {
+	case *pdfFontTrueType:
+		return f.FontDescriptor
+	case *pdfFontGeneric:
+		return f.FontDescriptor
+	}
+	return nil
+}
+
+// ExtractFontProgram returns the font program embedded in the font, with its format.  The format is detected from
+// the content of the font program, which takes precedence over the font file entry of the font descriptor in case of
+// mismatch.
+func (font PdfFont) ExtractFontProgram() (*fonts.FontProgram, error) {
+	descriptor := font.fontDescriptor()
+	if descriptor == nil {
+		return nil, errors.New("No embedded font program")
+	}
+	return descriptor.ExtractFontProgram()
+}
+
+// SetFontProgram embeds the font program in the font, replacing the current one.  The font program should be of the
+// font type: a Type 1 or CFF font program for Type 1 fonts, a TrueType font program for TrueType fonts, and OpenType
+// font programs for either.
+func (font PdfFont) SetFontProgram(prog *fonts.FontProgram) error {
+	descriptor := font.fontDescriptor()
+	if descriptor == nil {
+		return errors.New("Font descriptor missing")
+	}
+	return descriptor.SetFontProgram(prog)
+}
+
+// pdfFontGeneric is a font of a type not fully supported, kept as loaded.
+type pdfFontGeneric struct {
+	FontDescriptor *PdfFontDescriptor
+
+	dict       *core.PdfObjectDictionary
+	descendant *core.PdfObjectDictionary // Dictionary with the font descriptor entry.
+	container  *core.PdfIndirectObject
+}
+
+func newPdfFontGenericFromPdfObject(obj core.PdfObject, d *core.PdfObjectDictionary) (*pdfFontGeneric, error) {
+	font := &pdfFontGeneric{dict: d}
+	if ind, is := obj.(*core.PdfIndirectObject); is {
+		font.container = ind
+	}
+
+	// The font descriptor of Type0 fonts is that of their descendant CIDFont.
+	font.descendant = d
+	if arr, ok := core.TraceToDirectObject(d.Get("DescendantFonts")).(*core.PdfObjectArray); ok && len(*arr) > 0 {
+		if dd, ok := core.TraceToDirectObject((*arr)[0]).(*core.PdfObjectDictionary); ok {
+			font.descendant = dd
+		}
+	}
+
+	if obj := font.descendant.Get("FontDescriptor"); obj != nil {
+		descriptor, err := newPdfFontDescriptorFromPdfObject(obj)
+		if err != nil {
+			common.Log.Debug("Error loading font descriptor: %v", err)
+			return nil, err
+		}
+		font.FontDescriptor = descriptor
+	}
+
+	return font, nil
+}
+
+// ToPdfObject returns the font dictionary as loaded, with the font descriptor updated.
+func (this *pdfFontGeneric) ToPdfObject() core.PdfObject {
+	if this.FontDescriptor != nil {
+		this.descendant.Set("FontDescriptor", this.FontDescriptor.ToPdfObject())
+	}
+	if this.container != nil {
+		return this.container
+	}
+	return this.dict
+}
+
 type pdfFontTrueType struct {
 	Encoder textencoding.TextEncoder
 
@@ -125,11 +231,138 @@ type pdfFontTrueType struct {
 	Encoding       core.PdfObject
 	ToUnicode      core.PdfObject
 
+	// Metrics of the font program, if loaded.
+	ttf *fonts.TtfType
+
 	container *core.PdfIndirectObject
 }
 
-func (font pdfFontTrueType) SetEncoder(encoder textencoding.TextEncoder) {
+// SetEncoder sets the encoder of the font and regenerates the Encoding and the widths to match it.
+func (font *pdfFontTrueType) SetEncoder(encoder textencoding.TextEncoder) {
+	// The current widths by glyph name, for fonts without a font program to load metrics from.
+	var glyphWidths map[string]float64
+	if font.Encoder != nil {
+		glyphWidths = map[string]float64{}
+		for i, w := range font.charWidths {
+			if glyph, has := font.Encoder.CharcodeToGlyph(byte(font.firstChar + i)); has {
+				glyphWidths[glyph] = w
+			}
+		}
+	}
+
 	font.Encoder = encoder
+	font.Encoding = encoder.ToPdfObject()
+
+	err := font.updateWidths(glyphWidths)
+	if err != nil {
+		common.Log.Debug("Unable to update widths: %v", err)
+	}
+}
+
+// updateWidths regenerates FirstChar, LastChar and Widths from the encoder and the metrics of the font program.
+// If the font program is not available, the widths are looked up by glyph name in glyphWidths.  The character
+// code range spans the codes mapped to glyphs with known widths, and codes within it without a width get the
+// missing width of the font descriptor.
+func (font *pdfFontTrueType) updateWidths(glyphWidths map[string]float64) error {
+	if font.Encoder == nil {
+		common.Log.Debug("Font has no encoder")
+		return errors.New("Encoder not set")
+	}
+
+	if font.FontDescriptor != nil {
+		if stream, ok := core.TraceToDirectObject(font.FontDescriptor.FontFile2).(*core.PdfObjectStream); ok {
+			data, err := core.DecodeStream(stream)
+			if err != nil {
+				common.Log.Debug("Unable to decode font program: %v", err)
+				return err
+			}
+			ttf, err := fonts.TtfParseReader(bytes.NewReader(data))
+			if err != nil {
+				common.Log.Debug("Unable to parse font program: %v", err)
+				return err
+			}
+			font.ttf = &ttf
+		}
+	}
+
+	if font.ttf == nil && glyphWidths == nil {
+		common.Log.Debug("No glyph metrics to regenerate the widths from")
+		return errors.New("Glyph metrics not available")
+	}
+
+	missingWidth := 0.0
+	if font.FontDescriptor != nil {
+		if val, err := getNumberAsFloat(core.TraceToDirectObject(font.FontDescriptor.MissingWidth)); err == nil {
+			missingWidth = val
+		}
+	}
+
+	k := 1.0
+	if font.ttf != nil {
+		if font.ttf.UnitsPerEm == 0 || len(font.ttf.Widths) == 0 {
+			common.Log.Debug("Invalid font program metrics")
+			return errors.New("Range check error")
+		}
+		k = 1000.0 / float64(font.ttf.UnitsPerEm)
+		if font.FontDescriptor == nil || font.FontDescriptor.MissingWidth == nil {
+			missingWidth = k * float64(font.ttf.Widths[0])
+		}
+	}
+
+	widths := map[int]float64{}
+	firstChar, lastChar := -1, -1
+	for code := 0; code <= 255; code++ {
+		var width float64
+		if font.ttf != nil {
+			r, has := font.Encoder.CharcodeToRune(byte(code))
+			if !has {
+				continue
+			}
+			pos, has := font.ttf.Chars[uint16(r)]
+			if !has || int(pos) >= len(font.ttf.Widths) {
+				continue
+			}
+			width = k * float64(font.ttf.Widths[pos])
+		} else {
+			glyph, has := font.Encoder.CharcodeToGlyph(byte(code))
+			if !has {
+				continue
+			}
+			width, has = glyphWidths[glyph]
+			if !has {
+				continue
+			}
+		}
+
+		widths[code] = width
+		if firstChar < 0 {
+			firstChar = code
+		}
+		lastChar = code
+	}
+
+	if firstChar < 0 {
+		common.Log.Debug("No character codes of the encoding have glyphs in the font")
+		return errors.New("Range check error")
+	}
+
+	vals := []float64{}
+	for code := firstChar; code <= lastChar; code++ {
+		if w, has := widths[code]; has {
+			vals = append(vals, w)
+		} else {
+			vals = append(vals, missingWidth)
+		}
+	}
+
+	font.firstChar = firstChar
+	font.lastChar = lastChar
+	font.charWidths = vals
+	font.FirstChar = core.MakeInteger(int64(firstChar))
+	font.LastChar = core.MakeInteger(int64(lastChar))
+	font.Widths = &core.PdfIndirectObject{PdfObject: core.MakeArrayFromFloats(vals)}
+
+	return nil
 }
 
 func (font pdfFontTrueType) GetGlyphCharMetrics(glyph string) (fonts.CharMetrics, bool) {
@@ -298,65 +531,27 @@ func (this *pdfFontTrueType) ToPdfObject() core.PdfObject {
 	return this.container
 }
 
+// NewPdfFontFromTTFFile loads a TrueType font from a file, to embed in documents.  Fonts whose license does not
+// allow embedding are refused, unless allowed by the font license policy (see SetFontLicensePolicy).
 func NewPdfFontFromTTFFile(filePath string) (*PdfFont, error) {
 	ttf, err := fonts.TtfParse(filePath)
 	if err != nil {
 		common.Log.Debug("Error loading ttf font: %v", err)
 		return nil, err
 	}
+	err = checkFontLicense(ttf.PostScriptName, ttf.Permissions)
+	if err != nil {
+		return nil, err
+	}
 
 	truefont := &pdfFontTrueType{}
-
-	truefont.Encoder = textencoding.NewWinAnsiTextEncoder()
-	truefont.firstChar = 32
-	truefont.lastChar = 255
-
 	truefont.BaseFont = core.MakeName(ttf.PostScriptName)
-	truefont.FirstChar = core.MakeInteger(32)
-	truefont.LastChar = core.MakeInteger(255)
-
-	k := 1000.0 / float64(ttf.UnitsPerEm)
 
 	if len(ttf.Widths) <= 0 {
 		return nil, errors.New("Missing required attribute (Widths)")
 	}
 
-	missingWidth := k * float64(ttf.Widths[0])
-	vals := []float64{}
-
-	for charcode := 32; charcode <= 255; charcode++ {
-		runeVal, found := truefont.Encoder.CharcodeToRune(byte(charcode))
-		if !found {
-			common.Log.Debug("Rune not found (charcode: %d)", charcode)
-			vals = append(vals, missingWidth)
-			continue
-		}
-
-		pos, ok := ttf.Chars[uint16(runeVal)]
-		if !ok {
-			common.Log.Debug("Rune not in TTF Chars")
-			vals = append(vals, missingWidth)
-			continue
-		}
-
-		w := k * float64(ttf.Widths[pos])
-
-		vals = append(vals, w)
-	}
-
-	truefont.Widths = &core.PdfIndirectObject{PdfObject: core.MakeArrayFromFloats(vals)}
-
-	if len(vals) < (255 - 32 + 1) {
-		common.Log.Debug("Invalid length of widths, %d < %d", len(vals), 255-32+1)
-		return nil, errors.New("Range check error")
-	}
-
-	truefont.charWidths = vals[:255-32+1]
-
-	// Default.
-	// XXX/FIXME TODO: Only use the encoder object.
-
-	truefont.Encoding = core.MakeName("WinAnsiEncoding")
+	k := 1000.0 / float64(ttf.UnitsPerEm)
 
 	descriptor := &PdfFontDescriptor{}
 	descriptor.Ascent = core.MakeFloat(k * float64(ttf.TypoAscender))
@@ -399,6 +594,13 @@ func NewPdfFontFromTTFFile(filePath string) (*PdfFont, error) {
 
 	// Build Font.
 	truefont.FontDescriptor = descriptor
+	truefont.ttf = &ttf
+
+	// Default.
+	truefont.SetEncoder(textencoding.NewWinAnsiTextEncoder())
+	if truefont.Widths == nil {
+		return nil, errors.New("Range check error")
+	}
 
 	font := &PdfFont{}
 	font.context = truefont
@@ -611,3 +813,99 @@ func (this *PdfFontDescriptor) ToPdfObject() core.PdfObject {
 
 	return this.container
 }
+
+// ExtractFontProgram returns the embedded font program (FontFile, FontFile2 or FontFile3), decoded, with its format.
+func (this *PdfFontDescriptor) ExtractFontProgram() (*fonts.FontProgram, error) {
+	var obj core.PdfObject
+	format := fonts.FontProgramUnknown
+	switch {
+	case this.FontFile != nil:
+		obj, format = this.FontFile, fonts.FontProgramType1
+	case this.FontFile2 != nil:
+		obj, format = this.FontFile2, fonts.FontProgramTrueType
+	case this.FontFile3 != nil:
+		obj = this.FontFile3
+	default:
+		return nil, errors.New("No embedded font program")
+	}
+
+	stream, ok := core.TraceToDirectObject(obj).(*core.PdfObjectStream)
+	if !ok {
+		common.Log.Debug("ERROR: Font file not a stream (%T)", obj)
+		return nil, errors.New("Type check error")
+	}
+	if name, ok := core.TraceToDirectObject(stream.PdfObjectDictionary.Get("Subtype")).(*core.PdfObjectName); ok &&
+		format == fonts.FontProgramUnknown {
+		switch string(*name) {
+		case "Type1C":
+			format = fonts.FontProgramCFF
+		case "CIDFontType0C":
+			format = fonts.FontProgramCIDFontType0C
+		case "OpenType":
+			format = fonts.FontProgramOpenType
+		}
+	}
+
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to decode font file: %v", err)
+		return nil, err
+	}
+
+	// The content is more reliable than the entry, except for OpenType fonts with TrueType outlines.
+	detected := fonts.DetectFontProgramFormat(data)
+	if detected != fonts.FontProgramUnknown && detected != format &&
+		!(format == fonts.FontProgramOpenType && detected == fonts.FontProgramTrueType) {
+		common.Log.Debug("Font program of format %s embedded as %s", detected, format)
+		format = detected
+	}
+
+	return &fonts.FontProgram{Format: format, Data: data}, nil
+}
+
+// SetFontProgram embeds the font program, compressed, under the font file entry of its format, replacing the
+// embedded one.
+func (this *PdfFontDescriptor) SetFontProgram(prog *fonts.FontProgram) error {
+	data := prog.Data
+	var lengths [3]int
+	if prog.Format == fonts.FontProgramType1 {
+		var err error
+		data, lengths, err = fonts.Type1Segments(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	stream, err := core.MakeStream(data, core.NewFlateEncoder())
+	if err != nil {
+		common.Log.Debug("Unable to make stream: %v", err)
+		return err
+	}
+
+	var fontFile, fontFile2, fontFile3 core.PdfObject
+	switch prog.Format {
+	case fonts.FontProgramType1:
+		for i, length := range lengths {
+			stream.PdfObjectDictionary.Set(core.PdfObjectName(fmt.Sprintf("Length%d", i+1)),
+				core.MakeInteger(int64(length)))
+		}
+		fontFile = stream
+	case fonts.FontProgramTrueType:
+		stream.PdfObjectDictionary.Set("Length1", core.MakeInteger(int64(len(data))))
+		fontFile2 = stream
+	case fonts.FontProgramCFF:
+		stream.PdfObjectDictionary.Set("Subtype", core.MakeName("Type1C"))
+		fontFile3 = stream
+	case fonts.FontProgramCIDFontType0C:
+		stream.PdfObjectDictionary.Set("Subtype", core.MakeName("CIDFontType0C"))
+		fontFile3 = stream
+	case fonts.FontProgramOpenType:
+		stream.PdfObjectDictionary.Set("Subtype", core.MakeName("OpenType"))
+		fontFile3 = stream
+	default:
+		return errors.New("Unsupported font program format")
+	}
+
+	this.FontFile, this.FontFile2, this.FontFile3 = fontFile, fontFile2, fontFile3
+	return nil
+}
@@ -0,0 +1,250 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+func checkFontWidths(t *testing.T, font *pdfFontTrueType, firstChar, lastChar int, widths []float64) {
+	if font.firstChar != firstChar || font.lastChar != lastChar {
+		t.Errorf("Wrong range %d-%d, expected %d-%d", font.firstChar, font.lastChar, firstChar, lastChar)
+		return
+	}
+	if len(font.charWidths) != len(widths) {
+		t.Errorf("Wrong widths %v, expected %v", font.charWidths, widths)
+		return
+	}
+	for i := range widths {
+		if font.charWidths[i] != widths[i] {
+			t.Errorf("Wrong widths %v, expected %v", font.charWidths, widths)
+			return
+		}
+	}
+
+	d, ok := font.ToPdfObject().(*core.PdfIndirectObject).PdfObject.(*core.PdfObjectDictionary)
+	if !ok {
+		t.Errorf("Font not a dictionary")
+		return
+	}
+	if fc, ok := d.Get("FirstChar").(*core.PdfObjectInteger); !ok || int(*fc) != firstChar {
+		t.Errorf("Wrong FirstChar %v", d.Get("FirstChar"))
+	}
+	if lc, ok := d.Get("LastChar").(*core.PdfObjectInteger); !ok || int(*lc) != lastChar {
+		t.Errorf("Wrong LastChar %v", d.Get("LastChar"))
+	}
+	arr, ok := core.TraceToDirectObject(d.Get("Widths")).(*core.PdfObjectArray)
+	if !ok || len(*arr) != len(widths) {
+		t.Errorf("Wrong Widths %v", d.Get("Widths"))
+	}
+}
+
+func TestFontWidthsRegeneration(t *testing.T) {
+	// Font program metrics with glyphs for A, B, D and alpha (2000 units per em).
+	ttf := &fonts.TtfType{
+		UnitsPerEm: 2000,
+		Widths:     []uint16{1000, 1200, 1400, 1000, 1600},
+		Chars:      map[uint16]uint16{'A': 1, 'B': 2, 'D': 4, 0x03B1: 3},
+	}
+
+	font := &pdfFontTrueType{ttf: ttf}
+	font.FontDescriptor = &PdfFontDescriptor{MissingWidth: core.MakeFloat(250)}
+	font.SetEncoder(textencoding.NewWinAnsiTextEncoder())
+
+	// C is missing in the font and gets the missing width.
+	checkFontWidths(t, font, 'A', 'D', []float64{600, 700, 250, 800})
+	if name, ok := font.Encoding.(*core.PdfObjectName); !ok || *name != "WinAnsiEncoding" {
+		t.Errorf("Wrong encoding %v", font.Encoding)
+	}
+
+	// In the Symbol encoding, A is Alpha and a is alpha.
+	font.SetEncoder(textencoding.NewSymbolEncoder())
+	checkFontWidths(t, font, 'a', 'a', []float64{500})
+
+	// Without the font program, the widths of the glyphs are kept.
+	font = &pdfFontTrueType{ttf: ttf}
+	font.SetEncoder(textencoding.NewWinAnsiTextEncoder())
+	font.ttf = nil
+	font.SetEncoder(textencoding.NewWinAnsiTextEncoder())
+	checkFontWidths(t, font, 'A', 'D', []float64{600, 700, 500, 800})
+
+	// Regenerating without any metrics fails.
+	pdfFont := PdfFont{context: &pdfFontTrueType{Encoder: textencoding.NewWinAnsiTextEncoder()}}
+	if err := pdfFont.UpdateWidths(); err == nil {
+		t.Errorf("Expected failure without metrics")
+	}
+}
+
+func TestFontProgramExtraction(t *testing.T) {
+	pfa := []byte("%!PS-AdobeFont-1.0: Test\ncurrentfile eexec\n0123abcd\n" + strings.Repeat("0", 64) + "\ncleartomark\n")
+	cff := []byte{1, 0, 4, 1}
+
+	// A Type1 font with its font program in FontFile, and a Type0 font with a misdeclared CFF font program.
+	descriptor := core.MakeDict()
+	descriptor.Set("Type", core.MakeName("FontDescriptor"))
+	descriptor.Set("FontName", core.MakeName("Test"))
+	type1 := core.MakeDict()
+	type1.Set("Type", core.MakeName("Font"))
+	type1.Set("Subtype", core.MakeName("Type1"))
+	type1.Set("FontDescriptor", &core.PdfIndirectObject{PdfObject: descriptor})
+
+	font, err := NewPdfFontFromPdfObject(type1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if _, err := font.ExtractFontProgram(); err == nil {
+		t.Errorf("Font program of a font without embedded font program")
+	}
+	if err := font.SetFontProgram(&fonts.FontProgram{Format: fonts.FontProgramType1, Data: pfa}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	d, ok := font.ToPdfObject().(*core.PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Font not a dictionary")
+	}
+	fd := core.TraceToDirectObject(d.Get("FontDescriptor")).(*core.PdfObjectDictionary)
+	stream, ok := core.TraceToDirectObject(fd.Get("FontFile")).(*core.PdfObjectStream)
+	if !ok {
+		t.Fatalf("FontFile missing")
+	}
+	for key, length := range map[core.PdfObjectName]int64{"Length1": 43, "Length2": 9, "Length3": 77} {
+		if v, ok := stream.PdfObjectDictionary.Get(key).(*core.PdfObjectInteger); !ok || int64(*v) != length {
+			t.Errorf("Wrong %s %v, expected %d", key, stream.PdfObjectDictionary.Get(key), length)
+		}
+	}
+
+	font, err = NewPdfFontFromPdfObject(d)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	prog, err := font.ExtractFontProgram()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if prog.Format != fonts.FontProgramType1 || string(prog.Data) != string(pfa) {
+		t.Errorf("Wrong font program %s %q", prog.Format, prog.Data)
+	}
+
+	fontFile, _ := core.MakeStream([]byte{0, 1, 0, 0, 0, 0}, nil)
+	fontFile.PdfObjectDictionary.Set("Subtype", core.MakeName("Type1C"))
+	cidDescriptor := core.MakeDict()
+	cidDescriptor.Set("FontFile3", fontFile)
+	cidFont := core.MakeDict()
+	cidFont.Set("Type", core.MakeName("Font"))
+	cidFont.Set("Subtype", core.MakeName("CIDFontType0"))
+	cidFont.Set("FontDescriptor", cidDescriptor)
+	type0 := core.MakeDict()
+	type0.Set("Type", core.MakeName("Font"))
+	type0.Set("Subtype", core.MakeName("Type0"))
+	type0.Set("DescendantFonts", core.MakeArray(cidFont))
+
+	font, err = NewPdfFontFromPdfObject(&core.PdfIndirectObject{PdfObject: type0})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	prog, err = font.ExtractFontProgram()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if prog.Format != fonts.FontProgramTrueType {
+		t.Errorf("Wrong detected format %s", prog.Format)
+	}
+
+	if err := font.SetFontProgram(&fonts.FontProgram{Format: fonts.FontProgramCFF, Data: cff}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	font.ToPdfObject()
+	fd = core.TraceToDirectObject(cidFont.Get("FontDescriptor")).(*core.PdfObjectDictionary)
+	stream, ok = core.TraceToDirectObject(fd.Get("FontFile3")).(*core.PdfObjectStream)
+	if !ok {
+		t.Fatalf("FontFile3 missing")
+	}
+	if subtype, ok := stream.PdfObjectDictionary.Get("Subtype").(*core.PdfObjectName); !ok || *subtype != "Type1C" {
+		t.Errorf("Wrong FontFile3 Subtype %v", stream.PdfObjectDictionary.Get("Subtype"))
+	}
+}
+
+// writeTestTTFFile writes a copy of the TrueType font file with the embedding permissions of its OS/2 table
+// replaced, and returns its path.
+func writeTestTTFFile(t *testing.T, path string, perms fonts.EmbeddingPermissions) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		entry := data[12+16*i:]
+		if string(entry[:4]) == "OS/2" {
+			binary.BigEndian.PutUint16(data[binary.BigEndian.Uint32(entry[8:])+8:], uint16(perms))
+		}
+	}
+
+	f, err := ioutil.TempFile("", "font")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return f.Name()
+}
+
+func TestFontLicense(t *testing.T) {
+	const robotoFile = "../../testfiles/roboto/Roboto-Regular.ttf"
+	defer SetFontLicensePolicy(FontLicensePolicy{})
+
+	testcases := []struct {
+		perms      fonts.EmbeddingPermissions
+		embeddable bool
+	}{
+		{0, true},
+		{fonts.EmbeddingRestricted, false},
+		{fonts.EmbeddingRestricted | fonts.EmbeddingPreviewPrint, true},
+		{fonts.EmbeddingEditable | fonts.EmbeddingNoSubsetting, true},
+		{fonts.EmbeddingEditable | fonts.EmbeddingBitmapOnly, false},
+	}
+	for _, tcase := range testcases {
+		path := writeTestTTFFile(t, robotoFile, tcase.perms)
+		defer os.Remove(path)
+
+		SetFontLicensePolicy(FontLicensePolicy{})
+		_, err := NewPdfFontFromTTFFile(path)
+		if tcase.embeddable && err != nil {
+			t.Errorf("fsType 0x%04x: %v", uint16(tcase.perms), err)
+		} else if !tcase.embeddable && err != ErrFontNotEmbeddable {
+			t.Errorf("fsType 0x%04x: embedding not refused (%v)", uint16(tcase.perms), err)
+		}
+
+		// Warned, then embedded.
+		warned := ""
+		SetFontLicensePolicy(FontLicensePolicy{Check: func(name string, perms fonts.EmbeddingPermissions) error {
+			warned = name
+			return nil
+		}})
+		if _, err := NewPdfFontFromTTFFile(path); err != nil {
+			t.Errorf("fsType 0x%04x: %v", uint16(tcase.perms), err)
+		}
+		if (warned != "") == tcase.embeddable || (warned != "" && warned != "Roboto-Regular") {
+			t.Errorf("fsType 0x%04x: wrong warning %q", uint16(tcase.perms), warned)
+		}
+
+		SetFontLicensePolicy(FontLicensePolicy{Override: true, Check: func(string, fonts.EmbeddingPermissions) error {
+			return ErrFontNotEmbeddable
+		}})
+		if _, err := NewPdfFontFromTTFFile(path); err != nil {
+			t.Errorf("fsType 0x%04x: not overridden (%v)", uint16(tcase.perms), err)
+		}
+	}
+}
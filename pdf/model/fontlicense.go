@@ -0,0 +1,56 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// ErrFontNotEmbeddable is returned when embedding a font program whose license does not allow embedding.
+var ErrFontNotEmbeddable = errors.New("Font license does not allow embedding")
+
+// FontLicenseCheck is called before embedding a font program whose license does not allow embedding (see
+// fonts.EmbeddingPermissions), with the PostScript name of the font.  Returning nil embeds the font anyway, e.g.
+// after warning the user, and returning an error refuses to embed it.
+type FontLicenseCheck func(fontName string, perms fonts.EmbeddingPermissions) error
+
+// FontLicensePolicy controls the embedding of fonts with restricted licenses.  The zero value refuses to embed
+// them with ErrFontNotEmbeddable.
+type FontLicensePolicy struct {
+	// Embed the fonts regardless of their license, e.g. for users holding the rights to embed them.
+	Override bool
+	// Decides whether to embed the fonts with restricted licenses.  Nil refuses them.
+	Check FontLicenseCheck
+}
+
+var fontLicensePolicy atomic.Value
+
+// SetFontLicensePolicy sets the policy applied when loading fonts to embed, such as with NewPdfFontFromTTFFile.
+func SetFontLicensePolicy(policy FontLicensePolicy) {
+	fontLicensePolicy.Store(policy)
+}
+
+// checkFontLicense returns an error if the font with the permissions must not be embedded, according to the font
+// license policy.
+func checkFontLicense(fontName string, perms fonts.EmbeddingPermissions) error {
+	if perms.Embeddable() {
+		return nil
+	}
+	policy, _ := fontLicensePolicy.Load().(FontLicensePolicy)
+	if policy.Override {
+		common.Log.Debug("Embedding font %s with restricted license (fsType 0x%04x)", fontName, uint16(perms))
+		return nil
+	}
+	if policy.Check != nil {
+		return policy.Check(fontName, perms)
+	}
+	common.Log.Debug("ERROR: Font %s license does not allow embedding (fsType 0x%04x)", fontName, uint16(perms))
+	return ErrFontNotEmbeddable
+}
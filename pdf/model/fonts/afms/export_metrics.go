@@ -21,7 +21,7 @@ import (
 
 func main() {
 	filepath := flag.String("file", "", "AFM input file")
-	method := flag.String("method", "charmetrics", "charmetrics/charcodes/glyph-to-charcode")
+	method := flag.String("method", "charmetrics", "charmetrics/charcodes/glyph-to-charcode/bboxes/kerning")
 
 	flag.Parse()
 
@@ -38,6 +38,10 @@ func main() {
 		err = runCharcodeToGlyphRetrievalOnFile(*filepath)
 	case "glyph-to-charcode":
 		err = runGlyphToCharcodeRetrievalOnFile(*filepath)
+	case "bboxes":
+		err = runBBoxesOnFile(*filepath)
+	case "kerning":
+		err = runKerningOnFile(*filepath)
 	}
 
 	if err != nil {
@@ -72,6 +76,54 @@ func runCharmetricsOnFile(path string) error {
 	return nil
 }
 
+// Generate a glyph to bounding box map.
+func runBBoxesOnFile(path string) error {
+	bboxes, err := GetGlyphBBoxesFromAfmFile(path)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{}
+	for key := range bboxes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	fmt.Printf("var xxfontGlyphBBoxes map[string][4]float64 = map[string][4]float64{\n")
+	for _, key := range keys {
+		bbox := bboxes[key]
+		fmt.Printf("\t\"%s\":\t{%g, %g, %g, %g},\n", key, bbox[0], bbox[1], bbox[2], bbox[3])
+	}
+	fmt.Printf("}\n")
+	return nil
+}
+
+// Generate a kerning pair to kerning adjustment map.
+func runKerningOnFile(path string) error {
+	kerning, err := GetKerningFromAfmFile(path)
+	if err != nil {
+		return err
+	}
+
+	keys := []fonts.KernPair{}
+	for key := range kerning {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Left != keys[j].Left {
+			return keys[i].Left < keys[j].Left
+		}
+		return keys[i].Right < keys[j].Right
+	})
+	fmt.Printf("var xxfontKerning map[KernPair]float64 = map[KernPair]float64{\n")
+	for _, key := range keys {
+		fmt.Printf("\t{\"%s\", \"%s\"}:\t%g,\n", key.Left, key.Right, kerning[key])
+	}
+	fmt.Printf("}\n")
+	return nil
+}
+
 func runCharcodeToGlyphRetrievalOnFile(afmpath string) error {
 	charcodeToGlyphMap, err := GetCharcodeToGlyphEncodingFromAfmFile(afmpath)
 	if err != nil {
@@ -293,3 +345,107 @@ func GetCharcodeToGlyphEncodingFromAfmFile(filename string) (map[byte]string, er
 
 	return charcodeToGlypMap, nil
 }
+
+func GetGlyphBBoxesFromAfmFile(filename string) (map[string][4]float64, error) {
+	bboxes := map[string][4]float64{}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	readingCharMetrics := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		parts := strings.Split(line, " ")
+		if len(parts) < 1 {
+			continue
+		}
+		if !readingCharMetrics && parts[0] == "StartCharMetrics" {
+			readingCharMetrics = true
+			continue
+		}
+		if readingCharMetrics && parts[0] == "EndCharMetrics" {
+			break
+		}
+		if !readingCharMetrics || parts[0] != "C" {
+			continue
+		}
+
+		var glyph string
+		var bbox [4]float64
+		for _, part := range strings.Split(line, ";") {
+			args := strings.Fields(part)
+			if len(args) < 1 {
+				continue
+			}
+
+			switch args[0] {
+			case "N":
+				if len(args) != 2 {
+					pdfcommon.Log.Debug("Failed C line: %s", line)
+					return nil, errors.New("Invalid C line")
+				}
+				glyph = args[1]
+			case "B":
+				if len(args) != 5 {
+					pdfcommon.Log.Debug("B: Invalid number of args != 4 (%s)\n", line)
+					return nil, errors.New("Invalid range")
+				}
+				for i := range bbox {
+					bbox[i], err = strconv.ParseFloat(args[i+1], 64)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		if len(glyph) > 0 {
+			bboxes[glyph] = bbox
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return bboxes, nil
+}
+
+func GetKerningFromAfmFile(filename string) (map[fonts.KernPair]float64, error) {
+	kerning := map[fonts.KernPair]float64{}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		args := strings.Fields(scanner.Text())
+		if len(args) < 1 || args[0] != "KPX" {
+			continue
+		}
+		if len(args) != 4 {
+			pdfcommon.Log.Debug("KPX: Invalid number of args != 3 (%s)\n", scanner.Text())
+			return nil, errors.New("Invalid range")
+		}
+		x, err := strconv.ParseFloat(args[3], 64)
+		if err != nil {
+			return nil, err
+		}
+		kerning[fonts.KernPair{Left: args[1], Right: args[2]}] = x
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return kerning, nil
+}
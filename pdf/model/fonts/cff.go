@@ -0,0 +1,981 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+// Reading and writing of CFF font programs (Compact Font Format, Adobe Technical Note #5176).
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// CFF DICT operators.  The two byte operators (12 x) are 1200+x.
+const (
+	cffVersion            = 0
+	cffNotice             = 1
+	cffFullName           = 2
+	cffFamilyName         = 3
+	cffWeight             = 4
+	cffFontBBox           = 5
+	cffBlueValues         = 6
+	cffOtherBlues         = 7
+	cffFamilyBlues        = 8
+	cffFamilyOtherBlues   = 9
+	cffStdHW              = 10
+	cffStdVW              = 11
+	cffCharset            = 15
+	cffEncoding           = 16
+	cffCharStrings        = 17
+	cffPrivate            = 18
+	cffSubrs              = 19
+	cffDefaultWidthX      = 20
+	cffNominalWidthX      = 21
+	cffCopyright          = 1200
+	cffIsFixedPitch       = 1201
+	cffItalicAngle        = 1202
+	cffUnderlinePosition  = 1203
+	cffUnderlineThickness = 1204
+	cffCharstringType     = 1206
+	cffFontMatrix         = 1207
+	cffBlueScale          = 1209
+	cffBlueShift          = 1210
+	cffBlueFuzz           = 1211
+	cffStemSnapH          = 1212
+	cffStemSnapV          = 1213
+	cffForceBold          = 1214
+	cffLanguageGroup      = 1217
+	cffExpansionFactor    = 1218
+	cffROS                = 1230
+	cffFDArray            = 1236
+	cffFDSelect           = 1237
+)
+
+// cffOffsetOperators are the operators with offsets as operands, written in the fixed 5 byte form so that the size
+// of the dictionaries does not depend on the offsets.
+var cffOffsetOperators = map[int]bool{
+	cffCharset: true, cffEncoding: true, cffCharStrings: true, cffPrivate: true, cffSubrs: true,
+	cffFDArray: true, cffFDSelect: true,
+}
+
+// cffStandardStrings are the predefined strings of CFF, with the SIDs 0 to 390.
+var cffStandardStrings = []string{
+	".notdef", "space", "exclam", "quotedbl", "numbersign", "dollar", "percent", "ampersand", "quoteright",
+	"parenleft", "parenright", "asterisk", "plus", "comma", "hyphen", "period", "slash", "zero", "one", "two",
+	"three", "four", "five", "six", "seven", "eight", "nine", "colon", "semicolon", "less", "equal", "greater",
+	"question", "at", "A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S",
+	"T", "U", "V", "W", "X", "Y", "Z", "bracketleft", "backslash", "bracketright", "asciicircum", "underscore",
+	"quoteleft", "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t",
+	"u", "v", "w", "x", "y", "z", "braceleft", "bar", "braceright", "asciitilde", "exclamdown", "cent", "sterling",
+	"fraction", "yen", "florin", "section", "currency", "quotesingle", "quotedblleft", "guillemotleft",
+	"guilsinglleft", "guilsinglright", "fi", "fl", "endash", "dagger", "daggerdbl", "periodcentered", "paragraph",
+	"bullet", "quotesinglbase", "quotedblbase", "quotedblright", "guillemotright", "ellipsis", "perthousand",
+	"questiondown", "grave", "acute", "circumflex", "tilde", "macron", "breve", "dotaccent", "dieresis", "ring",
+	"cedilla", "hungarumlaut", "ogonek", "caron", "emdash", "AE", "ordfeminine", "Lslash", "Oslash", "OE",
+	"ordmasculine", "ae", "dotlessi", "lslash", "oslash", "oe", "germandbls", "onesuperior", "logicalnot", "mu",
+	"trademark", "Eth", "onehalf", "plusminus", "Thorn", "onequarter", "divide", "brokenbar", "degree", "thorn",
+	"threequarters", "twosuperior", "registered", "minus", "eth", "multiply", "threesuperior", "copyright",
+	"Aacute", "Acircumflex", "Adieresis", "Agrave", "Aring", "Atilde", "Ccedilla", "Eacute", "Ecircumflex",
+	"Edieresis", "Egrave", "Iacute", "Icircumflex", "Idieresis", "Igrave", "Ntilde", "Oacute", "Ocircumflex",
+	"Odieresis", "Ograve", "Otilde", "Scaron", "Uacute", "Ucircumflex", "Udieresis", "Ugrave", "Yacute",
+	"Ydieresis", "Zcaron", "aacute", "acircumflex", "adieresis", "agrave", "aring", "atilde", "ccedilla", "eacute",
+	"ecircumflex", "edieresis", "egrave", "iacute", "icircumflex", "idieresis", "igrave", "ntilde", "oacute",
+	"ocircumflex", "odieresis", "ograve", "otilde", "scaron", "uacute", "ucircumflex", "udieresis", "ugrave",
+	"yacute", "ydieresis", "zcaron", "exclamsmall", "Hungarumlautsmall", "dollaroldstyle", "dollarsuperior",
+	"ampersandsmall", "Acutesmall", "parenleftsuperior", "parenrightsuperior", "twodotenleader", "onedotenleader",
+	"zerooldstyle", "oneoldstyle", "twooldstyle", "threeoldstyle", "fouroldstyle", "fiveoldstyle", "sixoldstyle",
+	"sevenoldstyle", "eightoldstyle", "nineoldstyle", "commasuperior", "threequartersemdash", "periodsuperior",
+	"questionsmall", "asuperior", "bsuperior", "centsuperior", "dsuperior", "esuperior", "isuperior", "lsuperior",
+	"msuperior", "nsuperior", "osuperior", "rsuperior", "ssuperior", "tsuperior", "ff", "ffi", "ffl",
+	"parenleftinferior", "parenrightinferior", "Circumflexsmall", "hyphensuperior", "Gravesmall", "Asmall",
+	"Bsmall", "Csmall", "Dsmall", "Esmall", "Fsmall", "Gsmall", "Hsmall", "Ismall", "Jsmall", "Ksmall", "Lsmall",
+	"Msmall", "Nsmall", "Osmall", "Psmall", "Qsmall", "Rsmall", "Ssmall", "Tsmall", "Usmall", "Vsmall", "Wsmall",
+	"Xsmall", "Ysmall", "Zsmall", "colonmonetary", "onefitted", "rupiah", "Tildesmall", "exclamdownsmall",
+	"centoldstyle", "Lslashsmall", "Scaronsmall", "Zcaronsmall", "Dieresissmall", "Brevesmall", "Caronsmall",
+	"Dotaccentsmall", "Macronsmall", "figuredash", "hypheninferior", "Ogoneksmall", "Ringsmall", "Cedillasmall",
+	"questiondownsmall", "oneeighth", "threeeighths", "fiveeighths", "seveneighths", "onethird", "twothirds",
+	"zerosuperior", "foursuperior", "fivesuperior", "sixsuperior", "sevensuperior", "eightsuperior",
+	"ninesuperior", "zeroinferior", "oneinferior", "twoinferior", "threeinferior", "fourinferior", "fiveinferior",
+	"sixinferior", "seveninferior", "eightinferior", "nineinferior", "centinferior", "dollarinferior",
+	"periodinferior", "commainferior", "Agravesmall", "Aacutesmall", "Acircumflexsmall", "Atildesmall",
+	"Adieresissmall", "Aringsmall", "AEsmall", "Ccedillasmall", "Egravesmall", "Eacutesmall", "Ecircumflexsmall",
+	"Edieresissmall", "Igravesmall", "Iacutesmall", "Icircumflexsmall", "Idieresissmall", "Ethsmall",
+	"Ntildesmall", "Ogravesmall", "Oacutesmall", "Ocircumflexsmall", "Otildesmall", "Odieresissmall", "OEsmall",
+	"Oslashsmall", "Ugravesmall", "Uacutesmall", "Ucircumflexsmall", "Udieresissmall", "Yacutesmall",
+	"Thornsmall", "Ydieresissmall", "001.000", "001.001", "001.002", "001.003", "Black", "Bold", "Book", "Light",
+	"Medium", "Regular", "Roman", "Semibold",
+}
+
+// cffStandardSIDs maps the predefined strings to their SIDs.
+var cffStandardSIDs = func() map[string]int {
+	sids := make(map[string]int, len(cffStandardStrings))
+	for sid, s := range cffStandardStrings {
+		sids[s] = sid
+	}
+	return sids
+}()
+
+// standardEncodingCodes are the codes of the Standard Encoding above 126, of the glyphs with the SIDs 96 to 149.
+// The codes 32 to 126 are those of the glyphs with the SIDs 1 to 95.
+var standardEncodingCodes = []int{
+	161, 162, 163, 164, 165, 166, 167, 168, 169, 170, 171, 172, 173, 174, 175, 177, 178, 179, 180, 182, 183, 184,
+	185, 186, 187, 188, 189, 191, 193, 194, 195, 196, 197, 198, 199, 200, 202, 203, 205, 206, 207, 208, 225, 227,
+	232, 233, 234, 235, 241, 245, 248, 249, 250, 251,
+}
+
+// standardEncoding returns the glyph names of the codes of the Standard Encoding.
+func standardEncoding() map[int]string {
+	enc := map[int]string{}
+	for code := 32; code <= 126; code++ {
+		enc[code] = cffStandardStrings[code-31]
+	}
+	for i, code := range standardEncodingCodes {
+		enc[code] = cffStandardStrings[96+i]
+	}
+	return enc
+}
+
+// cffDict is a CFF DICT: the operands by operator.
+type cffDict map[int][]float64
+
+// get returns the first operand of the operator, or def if not set.
+func (d cffDict) get(op int, def float64) float64 {
+	if v, has := d[op]; has && len(v) > 0 {
+		return v[0]
+	}
+	return def
+}
+
+// readCFFIndex reads the INDEX at the offset, and returns its items with the offset following it.
+func readCFFIndex(data []byte, off int) ([][]byte, int, error) {
+	if off < 0 || off+2 > len(data) {
+		return nil, 0, errors.New("INDEX out of range")
+	}
+	count := int(binary.BigEndian.Uint16(data[off:]))
+	if count == 0 {
+		return nil, off + 2, nil
+	}
+	if off+3 > len(data) {
+		return nil, 0, errors.New("INDEX out of range")
+	}
+	offSize := int(data[off+2])
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, errors.New("Invalid INDEX offset size")
+	}
+	offsetsStart := off + 3
+	base := offsetsStart + (count+1)*offSize - 1
+	if base >= len(data) {
+		return nil, 0, errors.New("INDEX out of range")
+	}
+	readOffset := func(i int) int {
+		v := 0
+		for _, b := range data[offsetsStart+i*offSize : offsetsStart+(i+1)*offSize] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+
+	items := make([][]byte, count)
+	prev := readOffset(0)
+	for i := 0; i < count; i++ {
+		next := readOffset(i + 1)
+		if prev < 1 || next < prev || base+next > len(data) {
+			return nil, 0, errors.New("Invalid INDEX offsets")
+		}
+		items[i] = data[base+prev : base+next]
+		prev = next
+	}
+	return items, base + prev, nil
+}
+
+// writeCFFIndex writes the items as an INDEX.
+func writeCFFIndex(buf *bytes.Buffer, items [][]byte) {
+	if len(items) == 0 {
+		buf.Write([]byte{0, 0})
+		return
+	}
+	total := 1
+	for _, item := range items {
+		total += len(item)
+	}
+	offSize := 1
+	for limit := 0xff; total > limit && offSize < 4; limit = limit<<8 | 0xff {
+		offSize++
+	}
+
+	binary.Write(buf, binary.BigEndian, uint16(len(items)))
+	buf.WriteByte(byte(offSize))
+	writeOffset := func(v int) {
+		for i := offSize - 1; i >= 0; i-- {
+			buf.WriteByte(byte(v >> uint(8*i)))
+		}
+	}
+	off := 1
+	writeOffset(off)
+	for _, item := range items {
+		off += len(item)
+		writeOffset(off)
+	}
+	for _, item := range items {
+		buf.Write(item)
+	}
+}
+
+// parseCFFDict parses the DICT data.
+func parseCFFDict(data []byte) (cffDict, error) {
+	dict := cffDict{}
+	var operands []float64
+	for i := 0; i < len(data); {
+		b := int(data[i])
+		switch {
+		case b <= 21:
+			op := b
+			i++
+			if b == 12 {
+				if i >= len(data) {
+					return nil, errors.New("Truncated DICT operator")
+				}
+				op = 1200 + int(data[i])
+				i++
+			}
+			dict[op] = operands
+			operands = nil
+		case b == 28:
+			if i+3 > len(data) {
+				return nil, errors.New("Truncated DICT operand")
+			}
+			operands = append(operands, float64(int16(binary.BigEndian.Uint16(data[i+1:]))))
+			i += 3
+		case b == 29:
+			if i+5 > len(data) {
+				return nil, errors.New("Truncated DICT operand")
+			}
+			operands = append(operands, float64(int32(binary.BigEndian.Uint32(data[i+1:]))))
+			i += 5
+		case b == 30:
+			v, n, err := parseCFFReal(data[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, v)
+			i += 1 + n
+		case b >= 32 && b <= 246:
+			operands = append(operands, float64(b-139))
+			i++
+		case b >= 247 && b <= 254:
+			if i+2 > len(data) {
+				return nil, errors.New("Truncated DICT operand")
+			}
+			if b <= 250 {
+				operands = append(operands, float64((b-247)*256+int(data[i+1])+108))
+			} else {
+				operands = append(operands, float64(-(b-251)*256-int(data[i+1])-108))
+			}
+			i += 2
+		default:
+			common.Log.Debug("ERROR: Invalid DICT byte %d", b)
+			return nil, errors.New("Invalid DICT data")
+		}
+	}
+	return dict, nil
+}
+
+// parseCFFReal parses the nibbles of a real number operand, and returns it with the number of bytes read.
+func parseCFFReal(data []byte) (float64, int, error) {
+	var s strings.Builder
+	for i, b := range data {
+		for _, nibble := range []byte{b >> 4, b & 0xf} {
+			switch {
+			case nibble <= 9:
+				s.WriteByte('0' + nibble)
+			case nibble == 0xa:
+				s.WriteByte('.')
+			case nibble == 0xb:
+				s.WriteByte('E')
+			case nibble == 0xc:
+				s.WriteString("E-")
+			case nibble == 0xe:
+				s.WriteByte('-')
+			case nibble == 0xf:
+				v, err := strconv.ParseFloat(s.String(), 64)
+				if err != nil {
+					return 0, 0, errors.New("Invalid real number")
+				}
+				return v, i + 1, nil
+			default:
+				return 0, 0, errors.New("Invalid real number")
+			}
+		}
+	}
+	return 0, 0, errors.New("Truncated real number")
+}
+
+// writeCFFDict writes the DICT, with the ROS first as required and the offsets in the fixed size form.
+func writeCFFDict(dict cffDict) []byte {
+	ops := make([]int, 0, len(dict))
+	for op := range dict {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i] == cffROS || ops[j] == cffROS {
+			return ops[i] == cffROS
+		}
+		return ops[i] < ops[j]
+	})
+
+	var buf bytes.Buffer
+	for _, op := range ops {
+		for _, v := range dict[op] {
+			if cffOffsetOperators[op] {
+				buf.WriteByte(29)
+				binary.Write(&buf, binary.BigEndian, int32(v))
+				continue
+			}
+			writeCFFDictNumber(&buf, v)
+		}
+		if op >= 1200 {
+			buf.WriteByte(12)
+			buf.WriteByte(byte(op - 1200))
+		} else {
+			buf.WriteByte(byte(op))
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeCFFDictNumber writes a DICT operand in its shortest form.
+func writeCFFDictNumber(buf *bytes.Buffer, v float64) {
+	if v != math.Trunc(v) || math.Abs(v) > math.MaxInt32 {
+		s := strings.ToUpper(strconv.FormatFloat(v, 'g', -1, 64))
+		s = strings.Replace(s, "E+", "E", 1)
+		var nibbles []byte
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case c >= '0' && c <= '9':
+				nibbles = append(nibbles, c-'0')
+			case c == '.':
+				nibbles = append(nibbles, 0xa)
+			case c == 'E' && i+1 < len(s) && s[i+1] == '-':
+				nibbles = append(nibbles, 0xc)
+				i++
+			case c == 'E':
+				nibbles = append(nibbles, 0xb)
+			case c == '-':
+				nibbles = append(nibbles, 0xe)
+			}
+		}
+		nibbles = append(nibbles, 0xf)
+		if len(nibbles)%2 == 1 {
+			nibbles = append(nibbles, 0xf)
+		}
+		buf.WriteByte(30)
+		for i := 0; i < len(nibbles); i += 2 {
+			buf.WriteByte(nibbles[i]<<4 | nibbles[i+1])
+		}
+		return
+	}
+
+	n := int(v)
+	switch {
+	case n >= -107 && n <= 107:
+		buf.WriteByte(byte(n + 139))
+	case n >= 108 && n <= 1131:
+		n -= 108
+		buf.Write([]byte{byte(n>>8 + 247), byte(n)})
+	case n >= -1131 && n <= -108:
+		n = -n - 108
+		buf.Write([]byte{byte(n>>8 + 251), byte(n)})
+	case n >= -32768 && n <= 32767:
+		buf.WriteByte(28)
+		binary.Write(buf, binary.BigEndian, int16(n))
+	default:
+		buf.WriteByte(29)
+		binary.Write(buf, binary.BigEndian, int32(n))
+	}
+}
+
+// cffPrivateDict is a Private DICT, with its local subroutines.
+type cffPrivateDict struct {
+	dict  cffDict
+	subrs [][]byte
+}
+
+// cffFont is a parsed CFF font program, the first of its font set.
+type cffFont struct {
+	name        string
+	top         cffDict
+	strings     [][]byte
+	gsubrs      [][]byte
+	charStrings [][]byte
+
+	// The SIDs of the glyph names, or the CIDs of the glyphs of CID-keyed fonts, by glyph index.
+	charset []int
+	isCID   bool
+
+	// The glyph indices of the codes of the encoding of name-keyed fonts.
+	encoding map[int]int
+
+	// The Private DICT of name-keyed fonts, or of the font DICTs of CID-keyed fonts with their font matrices and
+	// the index of the font DICT of each glyph.
+	privates   []cffPrivateDict
+	fdMatrices [][]float64
+	fdSelect   []int
+}
+
+// parseCFF parses the CFF font program.
+func parseCFF(data []byte) (*cffFont, error) {
+	if len(data) < 4 || data[0] != 1 {
+		return nil, errors.New("Not a CFF font program")
+	}
+	names, off, err := readCFFIndex(data, int(data[2]))
+	if err != nil {
+		return nil, err
+	}
+	topDicts, off, err := readCFFIndex(data, off)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 || len(topDicts) == 0 {
+		return nil, errors.New("Empty CFF font set")
+	}
+
+	font := &cffFont{name: string(names[0])}
+	font.strings, off, err = readCFFIndex(data, off)
+	if err != nil {
+		return nil, err
+	}
+	font.gsubrs, _, err = readCFFIndex(data, off)
+	if err != nil {
+		return nil, err
+	}
+	font.top, err = parseCFFDict(topDicts[0])
+	if err != nil {
+		return nil, err
+	}
+	if font.top.get(cffCharstringType, 2) != 2 {
+		return nil, errors.New("Unsupported charstring type")
+	}
+
+	charStringsOff, has := font.top[cffCharStrings]
+	if !has || len(charStringsOff) == 0 {
+		return nil, errors.New("Missing CharStrings")
+	}
+	font.charStrings, _, err = readCFFIndex(data, int(charStringsOff[0]))
+	if err != nil {
+		return nil, err
+	}
+	numGlyphs := len(font.charStrings)
+	if numGlyphs == 0 {
+		return nil, errors.New("No glyphs")
+	}
+
+	if err := font.parseCharset(data, numGlyphs); err != nil {
+		return nil, err
+	}
+
+	_, font.isCID = font.top[cffROS]
+	if !font.isCID {
+		private, err := parseCFFPrivate(data, font.top)
+		if err != nil {
+			return nil, err
+		}
+		font.privates = []cffPrivateDict{private}
+		if err := font.parseEncoding(data); err != nil {
+			return nil, err
+		}
+		return font, nil
+	}
+
+	fdArrayOff, has := font.top[cffFDArray]
+	if !has || len(fdArrayOff) == 0 {
+		return nil, errors.New("Missing FDArray")
+	}
+	fdArray, _, err := readCFFIndex(data, int(fdArrayOff[0]))
+	if err != nil {
+		return nil, err
+	}
+	for _, fd := range fdArray {
+		fdDict, err := parseCFFDict(fd)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parseCFFPrivate(data, fdDict)
+		if err != nil {
+			return nil, err
+		}
+		font.privates = append(font.privates, private)
+		font.fdMatrices = append(font.fdMatrices, fdDict[cffFontMatrix])
+	}
+	if len(font.privates) == 0 {
+		return nil, errors.New("Empty FDArray")
+	}
+	fdSelectOff, has := font.top[cffFDSelect]
+	if !has || len(fdSelectOff) == 0 {
+		return nil, errors.New("Missing FDSelect")
+	}
+	if err := font.parseFDSelect(data, int(fdSelectOff[0]), numGlyphs); err != nil {
+		return nil, err
+	}
+	return font, nil
+}
+
+// parseCFFPrivate parses the Private DICT of the font DICT, with its local subroutines.
+func parseCFFPrivate(data []byte, dict cffDict) (cffPrivateDict, error) {
+	private := cffPrivateDict{dict: cffDict{}}
+	loc, has := dict[cffPrivate]
+	if !has || len(loc) < 2 {
+		return private, nil
+	}
+	size, off := int(loc[0]), int(loc[1])
+	if size < 0 || off < 0 || off+size > len(data) {
+		return private, errors.New("Private DICT out of range")
+	}
+	var err error
+	private.dict, err = parseCFFDict(data[off : off+size])
+	if err != nil {
+		return private, err
+	}
+	if subrs, has := private.dict[cffSubrs]; has && len(subrs) > 0 {
+		private.subrs, _, err = readCFFIndex(data, off+int(subrs[0]))
+		if err != nil {
+			return private, err
+		}
+	}
+	return private, nil
+}
+
+// parseCharset parses the charset of the font.
+func (font *cffFont) parseCharset(data []byte, numGlyphs int) error {
+	font.charset = make([]int, numGlyphs)
+	off := int(font.top.get(cffCharset, 0))
+	switch off {
+	case 0:
+		// ISOAdobe.
+		for gid := range font.charset {
+			if gid < 229 {
+				font.charset[gid] = gid
+			}
+		}
+		return nil
+	case 1, 2:
+		common.Log.Debug("ERROR: Unsupported expert charset")
+		return errors.New("Unsupported charset")
+	}
+
+	if off >= len(data) {
+		return errors.New("Charset out of range")
+	}
+	format := data[off]
+	pos := off + 1
+	readCard16 := func() (int, error) {
+		if pos+2 > len(data) {
+			return 0, errors.New("Charset out of range")
+		}
+		v := int(binary.BigEndian.Uint16(data[pos:]))
+		pos += 2
+		return v, nil
+	}
+	switch format {
+	case 0:
+		for gid := 1; gid < numGlyphs; gid++ {
+			sid, err := readCard16()
+			if err != nil {
+				return err
+			}
+			font.charset[gid] = sid
+		}
+	case 1, 2:
+		for gid := 1; gid < numGlyphs; {
+			first, err := readCard16()
+			if err != nil {
+				return err
+			}
+			var nLeft int
+			if format == 1 {
+				if pos >= len(data) {
+					return errors.New("Charset out of range")
+				}
+				nLeft = int(data[pos])
+				pos++
+			} else if nLeft, err = readCard16(); err != nil {
+				return err
+			}
+			for i := 0; i <= nLeft && gid < numGlyphs; i++ {
+				font.charset[gid] = first + i
+				gid++
+			}
+		}
+	default:
+		return errors.New("Invalid charset format")
+	}
+	return nil
+}
+
+// parseEncoding parses the encoding of a name-keyed font.
+func (font *cffFont) parseEncoding(data []byte) error {
+	font.encoding = map[int]int{}
+	gids := map[int]int{}
+	for gid, sid := range font.charset {
+		if _, has := gids[sid]; !has {
+			gids[sid] = gid
+		}
+	}
+
+	off := int(font.top.get(cffEncoding, 0))
+	switch off {
+	case 0:
+		for code, name := range standardEncoding() {
+			if gid, has := gids[cffStandardSIDs[name]]; has && gid > 0 {
+				font.encoding[code] = gid
+			}
+		}
+		return nil
+	case 1:
+		// The expert encoding is only used by expert fonts, without Unicode glyph names.
+		return nil
+	}
+
+	if off >= len(data) {
+		return errors.New("Encoding out of range")
+	}
+	format := data[off]
+	pos := off + 1
+	if pos >= len(data) {
+		return errors.New("Encoding out of range")
+	}
+	switch format & 0x7f {
+	case 0:
+		n := int(data[pos])
+		pos++
+		if pos+n > len(data) {
+			return errors.New("Encoding out of range")
+		}
+		for i := 0; i < n; i++ {
+			font.encoding[int(data[pos+i])] = i + 1
+		}
+		pos += n
+	case 1:
+		nRanges := int(data[pos])
+		pos++
+		gid := 1
+		for i := 0; i < nRanges; i++ {
+			if pos+2 > len(data) {
+				return errors.New("Encoding out of range")
+			}
+			first, nLeft := int(data[pos]), int(data[pos+1])
+			pos += 2
+			for code := first; code <= first+nLeft && code < 256; code++ {
+				font.encoding[code] = gid
+				gid++
+			}
+		}
+	default:
+		return errors.New("Invalid encoding format")
+	}
+
+	if format&0x80 != 0 && pos < len(data) {
+		// Supplements: additional codes of the glyphs.
+		nSups := int(data[pos])
+		pos++
+		for i := 0; i < nSups && pos+3 <= len(data); i++ {
+			code, sid := int(data[pos]), int(binary.BigEndian.Uint16(data[pos+1:]))
+			pos += 3
+			if gid, has := gids[sid]; has {
+				font.encoding[code] = gid
+			}
+		}
+	}
+	return nil
+}
+
+// parseFDSelect parses the font DICT indices of the glyphs of a CID-keyed font.
+func (font *cffFont) parseFDSelect(data []byte, off, numGlyphs int) error {
+	if off >= len(data) {
+		return errors.New("FDSelect out of range")
+	}
+	font.fdSelect = make([]int, numGlyphs)
+	switch data[off] {
+	case 0:
+		if off+1+numGlyphs > len(data) {
+			return errors.New("FDSelect out of range")
+		}
+		for gid := range font.fdSelect {
+			font.fdSelect[gid] = int(data[off+1+gid])
+		}
+	case 3:
+		if off+3 > len(data) {
+			return errors.New("FDSelect out of range")
+		}
+		nRanges := int(binary.BigEndian.Uint16(data[off+1:]))
+		pos := off + 3
+		if pos+nRanges*3+2 > len(data) {
+			return errors.New("FDSelect out of range")
+		}
+		for i := 0; i < nRanges; i++ {
+			first := int(binary.BigEndian.Uint16(data[pos:]))
+			fd := int(data[pos+2])
+			end := int(binary.BigEndian.Uint16(data[pos+3:]))
+			for gid := first; gid < end && gid < numGlyphs; gid++ {
+				font.fdSelect[gid] = fd
+			}
+			pos += 3
+		}
+	default:
+		return errors.New("Invalid FDSelect format")
+	}
+	for _, fd := range font.fdSelect {
+		if fd >= len(font.privates) {
+			return errors.New("FDSelect out of range")
+		}
+	}
+	return nil
+}
+
+// sidString returns the string of the SID.
+func (font *cffFont) sidString(sid int) string {
+	if sid < len(cffStandardStrings) {
+		return cffStandardStrings[sid]
+	}
+	if i := sid - len(cffStandardStrings); i < len(font.strings) {
+		return string(font.strings[i])
+	}
+	return ""
+}
+
+// topString returns the string of the SID operand of the Top DICT operator.
+func (font *cffFont) topString(op int) string {
+	if v, has := font.top[op]; has && len(v) > 0 {
+		return font.sidString(int(v[0]))
+	}
+	return ""
+}
+
+// glyphName returns the name of the glyph of a name-keyed font.
+func (font *cffFont) glyphName(gid int) string {
+	if font.isCID || gid >= len(font.charset) {
+		return ""
+	}
+	return font.sidString(font.charset[gid])
+}
+
+// private returns the Private DICT of the glyph.
+func (font *cffFont) private(gid int) *cffPrivateDict {
+	if font.isCID && gid < len(font.fdSelect) {
+		return &font.privates[font.fdSelect[gid]]
+	}
+	return &font.privates[0]
+}
+
+// fontMatrix returns the font matrix, the product of the matrices of the Top DICT and of the first font DICT for
+// CID-keyed fonts.
+func (font *cffFont) fontMatrix() []float64 {
+	top, hasTop := font.top[cffFontMatrix]
+	if hasTop && len(top) != 6 {
+		hasTop = false
+	}
+	if font.isCID && len(font.fdMatrices) > 0 && len(font.fdMatrices[0]) == 6 {
+		fd := font.fdMatrices[0]
+		if !hasTop {
+			return fd
+		}
+		return []float64{
+			fd[0]*top[0] + fd[1]*top[2], fd[0]*top[1] + fd[1]*top[3],
+			fd[2]*top[0] + fd[3]*top[2], fd[2]*top[1] + fd[3]*top[3],
+			fd[4]*top[0] + fd[5]*top[2] + top[4], fd[4]*top[1] + fd[5]*top[3] + top[5],
+		}
+	}
+	if hasTop {
+		return top
+	}
+	return []float64{0.001, 0, 0, 0.001, 0, 0}
+}
+
+// cffBuilder builds a name-keyed CFF font program.
+type cffBuilder struct {
+	name    string
+	top     cffDict
+	private cffDict
+
+	// The glyph names and charstrings, the first glyph being .notdef.
+	glyphs      []string
+	charStrings [][]byte
+
+	// The codes of the glyphs, or nil for the Standard Encoding.
+	encoding map[int]string
+
+	strings []string
+	sids    map[string]int
+}
+
+// sid returns the SID of the string, adding it to the strings of the font if not predefined.
+func (b *cffBuilder) sid(s string) int {
+	if sid, has := cffStandardSIDs[s]; has {
+		return sid
+	}
+	if sid, has := b.sids[s]; has {
+		return sid
+	}
+	if b.sids == nil {
+		b.sids = map[string]int{}
+	}
+	sid := len(cffStandardStrings) + len(b.strings)
+	b.strings = append(b.strings, s)
+	b.sids[s] = sid
+	return sid
+}
+
+// setString sets the SID operand of the Top DICT operator, if the string is not empty.
+func (b *cffBuilder) setString(op int, s string) {
+	if s != "" {
+		b.top[op] = []float64{float64(b.sid(s))}
+	}
+}
+
+// bytes writes the font program.
+func (b *cffBuilder) bytes() []byte {
+	// The glyphs in the encoding are ordered first by code, as required by the encoding formats.
+	var encodingData []byte
+	if b.encoding != nil {
+		b.orderEncodedGlyphs()
+		encodingData = b.encodingData()
+	}
+
+	var charset bytes.Buffer
+	charset.WriteByte(0)
+	for _, name := range b.glyphs[1:] {
+		binary.Write(&charset, binary.BigEndian, uint16(b.sid(name)))
+	}
+	var charStrings bytes.Buffer
+	writeCFFIndex(&charStrings, b.charStrings)
+	private := writeCFFDict(b.private)
+
+	// The offsets are written in fixed size, so the size of the Top DICT is known before they are.
+	b.top[cffCharset] = []float64{0}
+	b.top[cffCharStrings] = []float64{0}
+	b.top[cffPrivate] = []float64{0, 0}
+	if encodingData != nil {
+		b.top[cffEncoding] = []float64{0}
+	}
+	var strs [][]byte
+	for _, s := range b.strings {
+		strs = append(strs, []byte(s))
+	}
+	var head bytes.Buffer
+	head.Write([]byte{1, 0, 4, 4})
+	writeCFFIndex(&head, [][]byte{[]byte(b.name)})
+	topSize := len(writeCFFDict(b.top))
+	var tail bytes.Buffer
+	writeCFFIndex(&tail, strs)
+	writeCFFIndex(&tail, nil)
+
+	// The Top DICT INDEX has a single item, with the offsets of the size depending on the size of the item.
+	var sizer bytes.Buffer
+	writeCFFIndex(&sizer, [][]byte{make([]byte, topSize)})
+	off := head.Len() + sizer.Len() + tail.Len()
+
+	b.top[cffCharset] = []float64{float64(off)}
+	off += charset.Len()
+	if encodingData != nil {
+		b.top[cffEncoding] = []float64{float64(off)}
+		off += len(encodingData)
+	}
+	b.top[cffCharStrings] = []float64{float64(off)}
+	off += charStrings.Len()
+	b.top[cffPrivate] = []float64{float64(len(private)), float64(off)}
+
+	var out bytes.Buffer
+	out.Write(head.Bytes())
+	writeCFFIndex(&out, [][]byte{writeCFFDict(b.top)})
+	out.Write(tail.Bytes())
+	out.Write(charset.Bytes())
+	out.Write(encodingData)
+	out.Write(charStrings.Bytes())
+	out.Write(private)
+	return out.Bytes()
+}
+
+// orderEncodedGlyphs orders the glyphs with codes after .notdef, by their lowest code.
+func (b *cffBuilder) orderEncodedGlyphs() {
+	lowest := map[string]int{}
+	for code, name := range b.encoding {
+		if c, has := lowest[name]; !has || code < c {
+			lowest[name] = code
+		}
+	}
+	order := make([]int, len(b.glyphs)-1)
+	for i := range order {
+		order[i] = i + 1
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		ci, encodedI := lowest[b.glyphs[order[i]]]
+		cj, encodedJ := lowest[b.glyphs[order[j]]]
+		if encodedI != encodedJ {
+			return encodedI
+		}
+		return encodedI && ci < cj
+	})
+
+	glyphs := []string{b.glyphs[0]}
+	charStrings := [][]byte{b.charStrings[0]}
+	for _, i := range order {
+		glyphs = append(glyphs, b.glyphs[i])
+		charStrings = append(charStrings, b.charStrings[i])
+	}
+	b.glyphs, b.charStrings = glyphs, charStrings
+}
+
+// encodingData returns the encoding in format 0, with the additional codes of the glyphs as supplements.  The
+// glyphs with codes must be ordered first.
+func (b *cffBuilder) encodingData() []byte {
+	gids := map[string]int{}
+	for gid, name := range b.glyphs {
+		if _, has := gids[name]; !has {
+			gids[name] = gid
+		}
+	}
+	codes := make([]int, 0, len(b.encoding))
+	for code := range b.encoding {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	var primary []byte
+	var supplements [][2]int
+	for _, code := range codes {
+		gid, has := gids[b.encoding[code]]
+		if !has || gid == 0 || code > 255 {
+			continue
+		}
+		if gid == len(primary)+1 {
+			primary = append(primary, byte(code))
+		} else {
+			supplements = append(supplements, [2]int{code, b.sid(b.encoding[code])})
+		}
+	}
+
+	var buf bytes.Buffer
+	format := byte(0)
+	if len(supplements) > 0 {
+		format |= 0x80
+	}
+	buf.WriteByte(format)
+	buf.WriteByte(byte(len(primary)))
+	buf.Write(primary)
+	if len(supplements) > 0 {
+		buf.WriteByte(byte(len(supplements)))
+		for _, sup := range supplements {
+			buf.WriteByte(byte(sup[0]))
+			binary.Write(&buf, binary.BigEndian, uint16(sup[1]))
+		}
+	}
+	return buf.Bytes()
+}
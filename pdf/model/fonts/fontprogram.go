@@ -0,0 +1,188 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// FontProgramFormat is the format of a font program (font file).
+type FontProgramFormat int
+
+const (
+	FontProgramUnknown       FontProgramFormat = iota
+	FontProgramType1                           // Type 1 font program, PFA or PFB (FontFile).
+	FontProgramTrueType                        // TrueType font program (FontFile2).
+	FontProgramCFF                             // Name-keyed CFF font program (FontFile3 Type1C).
+	FontProgramCIDFontType0C                   // CID-keyed CFF font program (FontFile3 CIDFontType0C).
+	FontProgramOpenType                        // OpenType font program with CFF outlines (FontFile3 OpenType).
+)
+
+// String returns the name of the format.
+func (format FontProgramFormat) String() string {
+	switch format {
+	case FontProgramType1:
+		return "Type1"
+	case FontProgramTrueType:
+		return "TrueType"
+	case FontProgramCFF:
+		return "CFF"
+	case FontProgramCIDFontType0C:
+		return "CIDFontType0C"
+	case FontProgramOpenType:
+		return "OpenType"
+	}
+	return "Unknown"
+}
+
+// Extension returns the usual file name extension of font programs of the format, with the leading dot.
+func (format FontProgramFormat) Extension() string {
+	switch format {
+	case FontProgramType1:
+		return ".pfb"
+	case FontProgramTrueType:
+		return ".ttf"
+	case FontProgramCFF, FontProgramCIDFontType0C:
+		return ".cff"
+	case FontProgramOpenType:
+		return ".otf"
+	}
+	return ".bin"
+}
+
+// FontProgram is a font program with its format.
+type FontProgram struct {
+	Format FontProgramFormat
+	Data   []byte
+}
+
+// NewFontProgram returns the font program of the data, with the format detected from its content.
+func NewFontProgram(data []byte) *FontProgram {
+	return &FontProgram{Format: DetectFontProgramFormat(data), Data: data}
+}
+
+// DetectFontProgramFormat detects the format of the font program from its header.  OpenType fonts with TrueType
+// outlines are reported as TrueType.
+func DetectFontProgramFormat(data []byte) FontProgramFormat {
+	switch {
+	case bytes.HasPrefix(data, []byte("%!PS-AdobeFont")), bytes.HasPrefix(data, []byte("%!FontType1")),
+		len(data) > 6 && data[0] == 0x80 && data[1] == 0x01:
+		return FontProgramType1
+	case bytes.HasPrefix(data, []byte("OTTO")):
+		return FontProgramOpenType
+	case bytes.HasPrefix(data, []byte{0, 1, 0, 0}), bytes.HasPrefix(data, []byte("true")):
+		return FontProgramTrueType
+	case len(data) > 4 && data[0] == 1 && data[2] >= 4:
+		font, err := parseCFF(data)
+		if err != nil {
+			return FontProgramUnknown
+		}
+		if font.isCID {
+			return FontProgramCIDFontType0C
+		}
+		return FontProgramCFF
+	}
+	return FontProgramUnknown
+}
+
+// ToCFF returns the font program converted to a CFF font program.  Type 1 font programs are converted, the CFF
+// table of OpenType fonts is extracted and CFF font programs are returned as is.
+func (prog *FontProgram) ToCFF() (*FontProgram, error) {
+	switch prog.Format {
+	case FontProgramCFF, FontProgramCIDFontType0C:
+		return prog, nil
+	case FontProgramType1:
+		data, err := Type1ToCFF(prog.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &FontProgram{Format: FontProgramCFF, Data: data}, nil
+	case FontProgramOpenType:
+		data := sfntTable(prog.Data, "CFF ")
+		if data == nil {
+			return nil, errors.New("CFF table missing")
+		}
+		return NewFontProgram(data), nil
+	}
+	return nil, errors.New("Unsupported font program conversion")
+}
+
+// ToOpenType returns the font program converted to an OpenType (or TrueType) font.  Type 1 and CFF font programs are
+// wrapped in OpenType fonts with CFF outlines, TrueType and OpenType fonts are returned as is.
+func (prog *FontProgram) ToOpenType() (*FontProgram, error) {
+	switch prog.Format {
+	case FontProgramTrueType, FontProgramOpenType:
+		return prog, nil
+	case FontProgramType1, FontProgramCFF, FontProgramCIDFontType0C:
+		cff, err := prog.ToCFF()
+		if err != nil {
+			return nil, err
+		}
+		data, err := CFFToOpenType(cff.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &FontProgram{Format: FontProgramOpenType, Data: data}, nil
+	}
+	return nil, errors.New("Unsupported font program conversion")
+}
+
+// Type1ToCFF converts the Type 1 font program, in PFA or PFB form, to a CFF font program.  The charstrings are
+// converted to Type 2 charstrings with the subroutines, flex and accented characters (seac) expanded, and without
+// hint replacement.
+func Type1ToCFF(data []byte) ([]byte, error) {
+	font, err := parseType1(data)
+	if err != nil {
+		return nil, err
+	}
+	return font.toCFF(), nil
+}
+
+// Type1Segments returns the Type 1 font program in the form embedded in PDF files, with the lengths of its
+// cleartext, encrypted and trailer portions (Length1, Length2 and Length3 of the font file).  The segments of PFB
+// font programs are concatenated; PFA font programs are returned as is.
+func Type1Segments(data []byte) ([]byte, [3]int, error) {
+	var lengths [3]int
+	if len(data) > 0 && data[0] == 0x80 {
+		var out []byte
+		for i := 0; len(data) >= 6 && data[0] == 0x80 && data[1] != 3; i++ {
+			n := int(binary.LittleEndian.Uint32(data[2:]))
+			if n < 0 || 6+n > len(data) {
+				return nil, lengths, errors.New("Invalid PFB segment")
+			}
+			out = append(out, data[6:6+n]...)
+			lengths[minInt(i, 2)] += n
+			data = data[6+n:]
+		}
+		return out, lengths, nil
+	}
+
+	clear := bytes.Index(data, []byte("eexec"))
+	if clear < 0 {
+		return nil, lengths, errors.New("Missing eexec section")
+	}
+	clear += len("eexec")
+	for clear < len(data) && isSpace(data[clear]) {
+		clear++
+	}
+
+	// The trailer: the lines of zeros and cleartomark.
+	end := len(data)
+	if mark := bytes.LastIndex(data, []byte("cleartomark")); mark > clear {
+		end = mark
+		for end > clear {
+			line := bytes.LastIndexAny(data[clear:end-1], "\r\n") + clear + 1
+			if len(bytes.Trim(data[line:end], "0 \t\r\n")) > 0 {
+				break
+			}
+			end = line
+		}
+	}
+	lengths = [3]int{clear, end - clear, len(data) - end}
+	return data, lengths, nil
+}
@@ -0,0 +1,282 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// t1Encrypt encrypts the data with the key, with n leading bytes.
+func t1Encrypt(data []byte, key uint16, n int) []byte {
+	r := key
+	out := []byte{}
+	for _, p := range append(make([]byte, n), data...) {
+		c := p ^ byte(r>>8)
+		r = (uint16(c)+r)*52845 + 22719
+		out = append(out, c)
+	}
+	return out
+}
+
+// t1Charstring encodes the Type 1 charstring of numbers and operators (names, two byte operators as "12 x").
+func t1Charstring(code string) []byte {
+	ops := map[string][]byte{
+		"hstem": {1}, "vstem": {3}, "rlineto": {5}, "rrcurveto": {8}, "closepath": {9}, "callsubr": {10},
+		"return": {11}, "hsbw": {13}, "endchar": {14}, "rmoveto": {21}, "seac": {12, 6}, "callothersubr": {12, 16},
+		"pop": {12, 17}, "setcurrentpoint": {12, 33},
+	}
+	var out []byte
+	for _, tok := range strings.Fields(code) {
+		if op, ok := ops[tok]; ok {
+			out = append(out, op...)
+			continue
+		}
+		var v int
+		fmt.Sscan(tok, &v)
+		switch {
+		case v >= -107 && v <= 107:
+			out = append(out, byte(v+139))
+		case v >= 108 && v <= 1131:
+			out = append(out, byte(247+(v-108)/256), byte((v-108)%256))
+		case v >= -1131 && v <= -108:
+			out = append(out, byte(251+(-v-108)/256), byte((-v-108)%256))
+		default:
+			out = append(out, 255, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(out[len(out)-4:], uint32(int32(v)))
+		}
+	}
+	return out
+}
+
+// makeTestType1Font returns a Type 1 font program (PFA) with the glyphs: A a box, B the box of a subroutine, C a
+// flex, acute a bar and Aacute the accented character of A and acute.
+func makeTestType1Font() []byte {
+	subrs := []string{
+		"3 0 callothersubr pop pop setcurrentpoint return",
+		"0 1 callothersubr return",
+		"0 2 callothersubr return",
+		"return",
+		"0 0 rmoveto 500 0 rlineto 0 700 rlineto -500 0 rlineto closepath return",
+	}
+	glyphs := []struct{ name, code string }{
+		{".notdef", "0 500 hsbw endchar"},
+		{"A", "50 600 hsbw 0 50 hstem 0 0 rmoveto 500 0 rlineto 0 700 rlineto -500 0 rlineto closepath endchar"},
+		{"B", "50 600 hsbw 4 callsubr endchar"},
+		{"C", "0 600 hsbw 100 0 rmoveto 1 callsubr 150 0 rmoveto 2 callsubr -100 50 rmoveto 2 callsubr " +
+			"50 50 rmoveto 2 callsubr 50 0 rmoveto 2 callsubr 50 0 rmoveto 2 callsubr 50 -50 rmoveto 2 callsubr " +
+			"50 -50 rmoveto 2 callsubr 50 400 0 0 callsubr closepath endchar"},
+		{"acute", "100 300 hsbw 0 750 rmoveto 100 0 rlineto 0 50 rlineto -100 0 rlineto closepath endchar"},
+		{"Aacute", "50 600 hsbw 100 150 0 65 194 seac"},
+	}
+
+	var private bytes.Buffer
+	private.WriteString("dup /Private 8 dict dup begin\n/RD{string currentfile exch readstring pop}executeonly def\n")
+	private.WriteString("/ND{noaccess def}executeonly def\n/NP{noaccess put}executeonly def\n/lenIV 4 def\n")
+	private.WriteString("/BlueValues [-10 0 700 710] def\n/StdHW [50] def\n/StdVW [80] def\n")
+	private.WriteString(fmt.Sprintf("/Subrs %d array\n", len(subrs)))
+	for i, subr := range subrs {
+		cs := t1Encrypt(t1Charstring(subr), 4330, 4)
+		private.WriteString(fmt.Sprintf("dup %d %d RD ", i, len(cs)))
+		private.Write(cs)
+		private.WriteString(" NP\n")
+	}
+	private.WriteString(fmt.Sprintf("ND\n2 index /CharStrings %d dict dup begin\n", len(glyphs)))
+	for _, g := range glyphs {
+		cs := t1Encrypt(t1Charstring(g.code), 4330, 4)
+		private.WriteString(fmt.Sprintf("/%s %d RD ", g.name, len(cs)))
+		private.Write(cs)
+		private.WriteString(" ND\n")
+	}
+	private.WriteString("end\nend\nreadonly put\nnoaccess put\ndup /FontName get exch definefont pop\n")
+	private.WriteString("mark currentfile closefile\n")
+
+	var font bytes.Buffer
+	font.WriteString("%!PS-AdobeFont-1.0: Test 001.000\n12 dict begin\n/FontName /Test def\n/FontType 1 def\n")
+	font.WriteString("/FontMatrix [0.001 0 0 0.001 0 0] readonly def\n/FontBBox {0 0 600 800} readonly def\n")
+	font.WriteString("/Encoding StandardEncoding def\n")
+	font.WriteString("/FontInfo 3 dict dup begin\n/FamilyName (Test) readonly def\n/Weight (Bold) readonly def\n")
+	font.WriteString("end readonly def\ncurrentdict end\ncurrentfile eexec\n")
+	encrypted := t1Encrypt(private.Bytes(), 55665, 4)
+	for i := 0; i < len(encrypted); i += 32 {
+		end := i + 32
+		if end > len(encrypted) {
+			end = len(encrypted)
+		}
+		font.WriteString(fmt.Sprintf("%x\n", encrypted[i:end]))
+	}
+	for i := 0; i < 8; i++ {
+		font.WriteString(strings.Repeat("0", 64) + "\n")
+	}
+	font.WriteString("cleartomark\n")
+	return font.Bytes()
+}
+
+func TestType1ToCFF(t *testing.T) {
+	t1 := makeTestType1Font()
+	if format := DetectFontProgramFormat(t1); format != FontProgramType1 {
+		t.Fatalf("Wrong format %s", format)
+	}
+	t1Font, err := parseType1(t1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	cff, err := Type1ToCFF(t1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if format := DetectFontProgramFormat(cff); format != FontProgramCFF {
+		t.Fatalf("Wrong format %s", format)
+	}
+	font, err := parseCFF(cff)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if font.name != "Test" || font.topString(cffFamilyName) != "Test" || font.topString(cffWeight) != "Bold" {
+		t.Errorf("Wrong names %q %q %q", font.name, font.topString(cffFamilyName), font.topString(cffWeight))
+	}
+	if bbox := font.top[cffFontBBox]; len(bbox) != 4 || bbox[3] != 800 {
+		t.Errorf("Wrong FontBBox %v", bbox)
+	}
+	if blues := font.privates[0].dict[cffBlueValues]; len(blues) != 4 || blues[0] != -10 || blues[3] != 10 {
+		t.Errorf("Wrong BlueValues (delta encoded) %v", blues)
+	}
+	if font.glyphName(0) != ".notdef" || len(font.charStrings) != 6 {
+		t.Fatalf("Wrong glyphs %d", len(font.charStrings))
+	}
+	if gid, ok := font.encoding[65]; !ok || font.glyphName(gid) != "A" {
+		t.Errorf("Wrong encoding of A: %d", gid)
+	}
+
+	expected := map[string][5]float64{
+		"A":      {600, 50, 0, 550, 700},
+		"B":      {600, 50, 0, 550, 700},
+		"C":      {600, 150, 0, 400, 100},
+		"acute":  {300, 100, 750, 200, 800},
+		"Aacute": {600, 50, 0, 550, 800},
+	}
+	for gid := 1; gid < len(font.charStrings); gid++ {
+		name := font.glyphName(gid)
+		outline, err := font.outline(gid)
+		if err != nil {
+			t.Errorf("Error %s: %v", name, err)
+			continue
+		}
+		t1Outline, err := t1Font.outline(name)
+		if err != nil {
+			t.Errorf("Error %s: %v", name, err)
+			continue
+		}
+		x0, y0, x1, y1, _ := outline.bounds()
+		got := [5]float64{outline.width, x0, y0, x1, y1}
+		if got != expected[name] {
+			t.Errorf("Wrong metrics of %s: %v, expected %v", name, got, expected[name])
+		}
+		if len(outline.segments) != len(t1Outline.segments) {
+			t.Errorf("Wrong segments of %s: %v, expected %v", name, outline.segments, t1Outline.segments)
+		}
+	}
+}
+
+func TestCFFToOpenType(t *testing.T) {
+	prog, err := NewFontProgram(makeTestType1Font()).ToOpenType()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if prog.Format != FontProgramOpenType || DetectFontProgramFormat(prog.Data) != FontProgramOpenType {
+		t.Fatalf("Wrong format %s", prog.Format)
+	}
+	otf := prog.Data
+	if sfntChecksum(otf) != 0xb1b0afba {
+		t.Errorf("Wrong font checksum")
+	}
+
+	for _, tag := range []string{"CFF ", "OS/2", "cmap", "head", "hhea", "hmtx", "maxp", "name", "post"} {
+		if sfntTable(otf, tag) == nil {
+			t.Errorf("Table %q missing", tag)
+		}
+	}
+	head := sfntTable(otf, "head")
+	if unitsPerEm := binary.BigEndian.Uint16(head[18:]); unitsPerEm != 1000 {
+		t.Errorf("Wrong unitsPerEm %d", unitsPerEm)
+	}
+	if numGlyphs := binary.BigEndian.Uint16(sfntTable(otf, "maxp")[4:]); numGlyphs != 6 {
+		t.Errorf("Wrong numGlyphs %d", numGlyphs)
+	}
+	if !bytes.Contains(sfntTable(otf, "name"), []byte{0, 'T', 0, 'e', 0, 's', 0, 't'}) {
+		t.Errorf("Font name missing")
+	}
+
+	// The CFF table is the converted font program.
+	cff, err := prog.ToCFF()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	font, err := parseCFF(cff.Data)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The glyphs of the characters, and their widths.
+	cmap := sfntTable(otf, "cmap")
+	sub := cmap[12:]
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	hmtx := sfntTable(otf, "hmtx")
+	for _, r := range []rune{'A', 'B', 'C', 0xb4, 0xc1} {
+		gid := -1
+		for i := 0; i < segCount; i++ {
+			end := rune(binary.BigEndian.Uint16(sub[14+2*i:]))
+			start := rune(binary.BigEndian.Uint16(sub[16+2*segCount+2*i:]))
+			delta := binary.BigEndian.Uint16(sub[16+4*segCount+2*i:])
+			if r >= start && r <= end {
+				gid = int(uint16(r) + delta)
+				break
+			}
+		}
+		if gid <= 0 || gid >= len(font.charStrings) {
+			t.Errorf("Character %q not mapped", r)
+			continue
+		}
+		width := binary.BigEndian.Uint16(hmtx[4*gid:])
+		outline, _ := font.outline(gid)
+		if float64(width) != outline.width {
+			t.Errorf("Wrong width of %q: %d, expected %v", r, width, outline.width)
+		}
+	}
+}
+
+func TestType1Segments(t *testing.T) {
+	t1 := makeTestType1Font()
+	data, lengths, err := Type1Segments(t1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	clear := bytes.Index(t1, []byte("eexec\n")) + len("eexec\n")
+	trailer := bytes.Index(t1, []byte(strings.Repeat("0", 64)))
+	if !bytes.Equal(data, t1) || lengths != [3]int{clear, trailer - clear, len(t1) - trailer} {
+		t.Errorf("Wrong lengths %v", lengths)
+	}
+
+	// The PFB form, of its segments.
+	var pfb bytes.Buffer
+	for i, segment := range [][]byte{t1[:clear], []byte("binary"), t1[trailer:]} {
+		pfb.Write([]byte{0x80, byte(1 + i%2), 0, 0, 0, 0})
+		binary.LittleEndian.PutUint32(pfb.Bytes()[pfb.Len()-4:], uint32(len(segment)))
+		pfb.Write(segment)
+	}
+	pfb.Write([]byte{0x80, 3})
+	data, lengths, err = Type1Segments(pfb.Bytes())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(data) != clear+6+len(t1)-trailer || lengths != [3]int{clear, 6, len(t1) - trailer} {
+		t.Errorf("Wrong PFB lengths %v", lengths)
+	}
+}
@@ -0,0 +1,432 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+// Wrapping of CFF font programs in OpenType fonts.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// CFFToOpenType wraps the CFF font program in an OpenType font, with the tables required besides the CFF table
+// generated from it: the metrics (head, hhea, hmtx, maxp, OS/2, post), the names and the character map.  The
+// character map of name-keyed fonts maps the Unicode characters of the glyph names, or the codes of the encoding
+// (as symbol characters U+F0xx) if none of the names are of Unicode characters.  CID-keyed fonts have an empty
+// character map, their glyphs being selected by CID.
+func CFFToOpenType(data []byte) ([]byte, error) {
+	font, err := parseCFF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := font.fontMatrix()
+	unitsPerEm := 1000
+	if m[0] > 0 {
+		unitsPerEm = int(math.Round(1 / m[0]))
+	}
+	if unitsPerEm < 16 || unitsPerEm > 16384 {
+		return nil, errors.New("Unsupported font matrix")
+	}
+
+	numGlyphs := len(font.charStrings)
+	if numGlyphs > 0xffff {
+		return nil, errors.New("Too many glyphs")
+	}
+	widths := make([]int, numGlyphs)
+	lsbs := make([]int, numGlyphs)
+	var xMin, yMin, xMax, yMax float64
+	hasBounds := false
+	maxWidth, minLSB, minRSB, maxExtent := 0, math.MaxInt16, math.MaxInt16, math.MinInt16
+	totalWidth, nonZero := 0, 0
+	for gid := range widths {
+		outline, err := font.outline(gid)
+		if err != nil {
+			return nil, err
+		}
+		widths[gid] = int(math.Round(outline.width))
+		if widths[gid] > maxWidth {
+			maxWidth = widths[gid]
+		}
+		if widths[gid] > 0 {
+			totalWidth += widths[gid]
+			nonZero++
+		}
+		x0, y0, x1, y1, ok := outline.bounds()
+		if !ok {
+			continue
+		}
+		lsbs[gid] = int(math.Floor(x0))
+		minLSB = minInt(minLSB, lsbs[gid])
+		minRSB = minInt(minRSB, widths[gid]-int(math.Ceil(x1)))
+		if extent := int(math.Ceil(x1)); extent > maxExtent {
+			maxExtent = extent
+		}
+		if !hasBounds {
+			xMin, yMin, xMax, yMax, hasBounds = x0, y0, x1, y1, true
+			continue
+		}
+		xMin, yMin = math.Min(xMin, x0), math.Min(yMin, y0)
+		xMax, yMax = math.Max(xMax, x1), math.Max(yMax, y1)
+	}
+	if !hasBounds {
+		minLSB, minRSB, maxExtent = 0, 0, 0
+	}
+	if bbox := font.top[cffFontBBox]; len(bbox) == 4 && !hasBounds {
+		xMin, yMin, xMax, yMax = bbox[0], bbox[1], bbox[2], bbox[3]
+	}
+	avgWidth := 0
+	if nonZero > 0 {
+		avgWidth = totalWidth / nonZero
+	}
+
+	name := font.name
+	family := font.topString(cffFamilyName)
+	if family == "" {
+		family = name
+	}
+	fullName := font.topString(cffFullName)
+	if fullName == "" {
+		fullName = name
+	}
+	weight := font.topString(cffWeight)
+	italicAngle := font.top.get(cffItalicAngle, 0)
+	bold := strings.Contains(weight, "Bold") || strings.Contains(weight, "Black") ||
+		strings.Contains(weight, "Heavy")
+	subfamily := "Regular"
+	macStyle, fsSelection, weightClass := 0, 0x40, 400
+	switch {
+	case bold && italicAngle != 0:
+		subfamily, macStyle, fsSelection, weightClass = "Bold Italic", 3, 0x21, 700
+	case bold:
+		subfamily, macStyle, fsSelection, weightClass = "Bold", 1, 0x20, 700
+	case italicAngle != 0:
+		subfamily, macStyle, fsSelection = "Italic", 2, 0x01
+	}
+	version := font.topString(cffVersion)
+	if version == "" {
+		version = "1.000"
+	}
+
+	cmap, firstChar, lastChar := buildCmap(font)
+	ascent, descent := int16(math.Round(yMax)), int16(math.Round(yMin))
+
+	var tables = map[string][]byte{"CFF ": data, "cmap": cmap}
+
+	head := struct {
+		Version, FontRevision, CheckSumAdjustment, MagicNumber uint32
+		Flags, UnitsPerEm                                      uint16
+		Created, Modified                                      int64
+		XMin, YMin, XMax, YMax                                 int16
+		MacStyle, LowestRecPPEM                                uint16
+		FontDirectionHint, IndexToLocFormat, GlyphDataFormat   int16
+	}{
+		Version: 0x00010000, FontRevision: 0x00010000, MagicNumber: 0x5f0f3cf5, Flags: 3,
+		UnitsPerEm: uint16(unitsPerEm), XMin: int16(math.Floor(xMin)), YMin: int16(math.Floor(yMin)),
+		XMax: int16(math.Ceil(xMax)), YMax: int16(math.Ceil(yMax)), MacStyle: uint16(macStyle), LowestRecPPEM: 3,
+		FontDirectionHint: 2,
+	}
+	tables["head"] = binaryBytes(head)
+
+	hhea := struct {
+		Version                                             uint32
+		Ascender, Descender, LineGap                        int16
+		AdvanceWidthMax                                     uint16
+		MinLeftSideBearing, MinRightSideBearing, XMaxExtent int16
+		CaretSlopeRise, CaretSlopeRun, CaretOffset          int16
+		Reserved                                            [4]int16
+		MetricDataFormat                                    int16
+		NumberOfHMetrics                                    uint16
+	}{
+		Version: 0x00010000, Ascender: ascent, Descender: descent, AdvanceWidthMax: uint16(maxWidth),
+		MinLeftSideBearing: int16(minLSB), MinRightSideBearing: int16(minRSB), XMaxExtent: int16(maxExtent),
+		CaretSlopeRise: 1, NumberOfHMetrics: uint16(numGlyphs),
+	}
+	if italicAngle != 0 {
+		hhea.CaretSlopeRise = 1000
+		hhea.CaretSlopeRun = int16(math.Round(-1000 * math.Tan(italicAngle*math.Pi/180)))
+	}
+	tables["hhea"] = binaryBytes(hhea)
+
+	var hmtx bytes.Buffer
+	for gid := range widths {
+		binary.Write(&hmtx, binary.BigEndian, uint16(widths[gid]))
+		binary.Write(&hmtx, binary.BigEndian, int16(lsbs[gid]))
+	}
+	tables["hmtx"] = hmtx.Bytes()
+
+	tables["maxp"] = binaryBytes(struct {
+		Version   uint32
+		NumGlyphs uint16
+	}{0x00005000, uint16(numGlyphs)})
+
+	underlinePosition := font.top.get(cffUnderlinePosition, -100)
+	underlineThickness := font.top.get(cffUnderlineThickness, 50)
+	em := float64(unitsPerEm)
+	os2 := struct {
+		Version                                          uint16
+		XAvgCharWidth                                    int16
+		UsWeightClass, UsWidthClass, FsType              uint16
+		YSubscriptXSize, YSubscriptYSize                 int16
+		YSubscriptXOffset, YSubscriptYOffset             int16
+		YSuperscriptXSize, YSuperscriptYSize             int16
+		YSuperscriptXOffset, YSuperscriptYOffset         int16
+		YStrikeoutSize, YStrikeoutPosition, SFamilyClass int16
+		Panose                                           [10]byte
+		UlUnicodeRange                                   [4]uint32
+		AchVendID                                        [4]byte
+		FsSelection, UsFirstCharIndex, UsLastCharIndex   uint16
+		STypoAscender, STypoDescender, STypoLineGap      int16
+		UsWinAscent, UsWinDescent                        uint16
+		UlCodePageRange                                  [2]uint32
+		SxHeight, SCapHeight                             int16
+		UsDefaultChar, UsBreakChar, UsMaxContext         uint16
+	}{
+		Version: 4, XAvgCharWidth: int16(avgWidth), UsWeightClass: uint16(weightClass), UsWidthClass: 5,
+		YSubscriptXSize: int16(0.65 * em), YSubscriptYSize: int16(0.6 * em), YSubscriptYOffset: int16(0.075 * em),
+		YSuperscriptXSize: int16(0.65 * em), YSuperscriptYSize: int16(0.6 * em),
+		YSuperscriptYOffset: int16(0.35 * em), YStrikeoutSize: int16(math.Round(underlineThickness)),
+		YStrikeoutPosition: int16(0.25 * em), AchVendID: [4]byte{'U', 'K', 'W', 'N'},
+		FsSelection: uint16(fsSelection), UsFirstCharIndex: firstChar, UsLastCharIndex: lastChar,
+		STypoAscender: ascent, STypoDescender: descent, UsWinAscent: uint16(maxInt(int(ascent), 0)),
+		UsWinDescent: uint16(maxInt(-int(descent), 0)), UlCodePageRange: [2]uint32{1, 0}, UsBreakChar: 32,
+		UsMaxContext: 1,
+	}
+	tables["OS/2"] = binaryBytes(os2)
+
+	isFixedPitch := uint32(0)
+	if font.top.get(cffIsFixedPitch, 0) != 0 {
+		isFixedPitch = 1
+	}
+	tables["post"] = binaryBytes(struct {
+		Version, ItalicAngle                                 uint32
+		UnderlinePosition, UnderlineThickness                int16
+		IsFixedPitch                                         uint32
+		MinMemType42, MaxMemType42, MinMemType1, MaxMemType1 uint32
+	}{
+		Version: 0x00030000, ItalicAngle: uint32(int32(math.Round(italicAngle * 65536))),
+		UnderlinePosition: int16(math.Round(underlinePosition)), UnderlineThickness: int16(math.Round(underlineThickness)),
+		IsFixedPitch: isFixedPitch,
+	})
+
+	tables["name"] = buildNameTable(map[int]string{
+		1: family, 2: subfamily, 3: name + ";" + version, 4: fullName, 5: "Version " + version, 6: name,
+	})
+
+	return buildSFNT("OTTO", tables), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// binaryBytes returns the big endian binary form of the structure of fixed size fields.
+func binaryBytes(v interface{}) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, v)
+	return buf.Bytes()
+}
+
+// buildCmap builds the character map of the font, and returns it with the first and last characters.
+func buildCmap(font *cffFont) ([]byte, uint16, uint16) {
+	chars := map[int]int{}
+	if !font.isCID {
+		for gid := 1; gid < len(font.charStrings); gid++ {
+			if r, ok := textencoding.GlyphToRune(font.glyphName(gid)); ok && r <= 0xffff {
+				if _, has := chars[int(r)]; !has {
+					chars[int(r)] = gid
+				}
+			}
+		}
+		if len(chars) == 0 {
+			// Symbolic fonts are mapped by the codes of their encoding.
+			for code, gid := range font.encoding {
+				chars[0xf000+code] = gid
+			}
+		}
+	}
+
+	codes := make([]int, 0, len(chars))
+	for code := range chars {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	// Format 4, with a segment per run of consecutive characters of consecutive glyphs, and the final segment.
+	type segment struct{ start, end, delta int }
+	var segments []segment
+	for _, code := range codes {
+		gid := chars[code]
+		if n := len(segments); n > 0 && segments[n-1].end == code-1 && segments[n-1].delta == gid-code {
+			segments[n-1].end = code
+			continue
+		}
+		segments = append(segments, segment{code, code, gid - code})
+	}
+	segments = append(segments, segment{0xffff, 0xffff, 1})
+
+	segCount := len(segments)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= segCount {
+		searchRange *= 2
+		entrySelector++
+	}
+	searchRange *= 2
+
+	var sub bytes.Buffer
+	for _, v := range []int{4, 16 + 8*segCount, 0, 2 * segCount, searchRange, entrySelector, 2*segCount - searchRange} {
+		binary.Write(&sub, binary.BigEndian, uint16(v))
+	}
+	for _, s := range segments {
+		binary.Write(&sub, binary.BigEndian, uint16(s.end))
+	}
+	binary.Write(&sub, binary.BigEndian, uint16(0))
+	for _, s := range segments {
+		binary.Write(&sub, binary.BigEndian, uint16(s.start))
+	}
+	for _, s := range segments {
+		binary.Write(&sub, binary.BigEndian, uint16(s.delta))
+	}
+	for range segments {
+		binary.Write(&sub, binary.BigEndian, uint16(0))
+	}
+
+	var cmap bytes.Buffer
+	encodingID := uint16(1)
+	if len(codes) > 0 && codes[0] >= 0xf000 {
+		encodingID = 0
+	}
+	binary.Write(&cmap, binary.BigEndian, []uint16{0, 1, 3, encodingID})
+	binary.Write(&cmap, binary.BigEndian, uint32(12))
+	cmap.Write(sub.Bytes())
+
+	first, last := uint16(0xffff), uint16(0)
+	if len(codes) > 0 {
+		first, last = uint16(codes[0]), uint16(codes[len(codes)-1])
+	}
+	return cmap.Bytes(), first, last
+}
+
+// buildNameTable builds the naming table with the names by ID, for the Windows platform in English.
+func buildNameTable(names map[int]string) []byte {
+	ids := make([]int, 0, len(names))
+	for id := range names {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var records, storage bytes.Buffer
+	for _, id := range ids {
+		var encoded bytes.Buffer
+		for _, u := range utf16.Encode([]rune(names[id])) {
+			binary.Write(&encoded, binary.BigEndian, u)
+		}
+		binary.Write(&records, binary.BigEndian, []uint16{
+			3, 1, 0x409, uint16(id), uint16(encoded.Len()), uint16(storage.Len()),
+		})
+		storage.Write(encoded.Bytes())
+	}
+
+	var table bytes.Buffer
+	binary.Write(&table, binary.BigEndian, []uint16{0, uint16(len(ids)), uint16(6 + records.Len())})
+	table.Write(records.Bytes())
+	table.Write(storage.Bytes())
+	return table.Bytes()
+}
+
+// sfntChecksum returns the checksum of the table data.
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// buildSFNT builds the font file of the tables, with the checksum adjustment of the head table set.
+func buildSFNT(version string, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		entrySelector++
+	}
+	searchRange *= 16
+
+	var dir, body bytes.Buffer
+	dir.WriteString(version)
+	binary.Write(&dir, binary.BigEndian, []uint16{uint16(n), uint16(searchRange), uint16(entrySelector),
+		uint16(n*16 - searchRange)})
+	offset := 12 + 16*n
+	headOffset := -1
+	for _, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			headOffset = offset
+		}
+		dir.WriteString(tag)
+		binary.Write(&dir, binary.BigEndian, []uint32{sfntChecksum(data), uint32(offset), uint32(len(data))})
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+		offset = 12 + 16*n + body.Len()
+	}
+
+	out := append(dir.Bytes(), body.Bytes()...)
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xb1b0afba-sfntChecksum(out))
+	}
+	return out
+}
+
+// sfntTable returns the data of the table of the font file, or nil if not found.
+func sfntTable(data []byte, tag string) []byte {
+	if len(data) < 12 {
+		return nil
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil
+		}
+		if string(data[rec:rec+4]) != tag {
+			continue
+		}
+		off, length := int(binary.BigEndian.Uint32(data[rec+8:])), int(binary.BigEndian.Uint32(data[rec+12:]))
+		if off < 0 || length < 0 || off+length > len(data) {
+			return nil
+		}
+		return data[off : off+length]
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+/*
+ * The embedded font metrics specified in this file are distributed under the terms listed in
+ * ./afms/MustRead.html.
+ */
+
+package fonts
+
+import (
+	"sort"
+
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// KernPair is a pair of glyphs, by name, with a kerning adjustment when shown one after the other.
+type KernPair struct {
+	Left  string
+	Right string
+}
+
+// StdFontMetrics are the metrics of one of the standard 14 fonts, loaded from its AFM file.  Allows measuring text
+// without a PdfFont or a document.  Dimensions are in glyph space units, i.e. thousandths of the font size.
+type StdFontMetrics struct {
+	FontName           string
+	FontBBox           [4]float64 // Lower left x, y and upper right x, y.
+	ItalicAngle        float64
+	Ascender           float64 // 0 for the symbolic fonts.
+	Descender          float64 // 0 for the symbolic fonts.
+	CapHeight          float64 // 0 for the symbolic fonts.
+	XHeight            float64 // 0 for the symbolic fonts.
+	UnderlinePosition  float64
+	UnderlineThickness float64
+
+	charMetrics map[string]CharMetrics
+	bboxes      map[string][4]float64
+	kerning     map[KernPair]float64
+	encoder     textencoding.TextEncoder
+}
+
+// The metrics of the standard 14 fonts by name.  The oblique faces have the kerning of their upright face.
+var stdFontMetrics = map[string]*StdFontMetrics{
+	"Courier": {
+		FontName: "Courier", FontBBox: [4]float64{-23, -250, 715, 805},
+		Ascender: 629, Descender: -157, CapHeight: 562, XHeight: 426,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: courierCharMetrics, bboxes: courierGlyphBBoxes,
+	},
+	"Courier-Bold": {
+		FontName: "Courier-Bold", FontBBox: [4]float64{-113, -250, 749, 801},
+		Ascender: 629, Descender: -157, CapHeight: 562, XHeight: 439,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: courierBoldCharMetrics, bboxes: courierBoldGlyphBBoxes,
+	},
+	"Courier-BoldOblique": {
+		FontName: "Courier-BoldOblique", FontBBox: [4]float64{-57, -250, 869, 801}, ItalicAngle: -12,
+		Ascender: 629, Descender: -157, CapHeight: 562, XHeight: 439,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: courierBoldObliqueCharMetrics, bboxes: courierBoldObliqueGlyphBBoxes,
+	},
+	"Courier-Oblique": {
+		FontName: "Courier-Oblique", FontBBox: [4]float64{-27, -250, 849, 805}, ItalicAngle: -12,
+		Ascender: 629, Descender: -157, CapHeight: 562, XHeight: 426,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: courierObliqueCharMetrics, bboxes: courierObliqueGlyphBBoxes,
+	},
+	"Helvetica": {
+		FontName: "Helvetica", FontBBox: [4]float64{-166, -225, 1000, 931},
+		Ascender: 718, Descender: -207, CapHeight: 718, XHeight: 523,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: helveticaCharMetrics, bboxes: helveticaGlyphBBoxes, kerning: helveticaKerning,
+	},
+	"Helvetica-Bold": {
+		FontName: "Helvetica-Bold", FontBBox: [4]float64{-170, -228, 1003, 962},
+		Ascender: 718, Descender: -207, CapHeight: 718, XHeight: 532,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: helveticaBoldCharMetrics, bboxes: helveticaBoldGlyphBBoxes, kerning: helveticaBoldKerning,
+	},
+	"Helvetica-BoldOblique": {
+		FontName: "Helvetica-BoldOblique", FontBBox: [4]float64{-174, -228, 1114, 962}, ItalicAngle: -12,
+		Ascender: 718, Descender: -207, CapHeight: 718, XHeight: 532,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: helveticaBoldObliqueCharMetrics, bboxes: helveticaBoldObliqueGlyphBBoxes,
+		kerning: helveticaBoldKerning,
+	},
+	"Helvetica-Oblique": {
+		FontName: "Helvetica-Oblique", FontBBox: [4]float64{-170, -225, 1116, 931}, ItalicAngle: -12,
+		Ascender: 718, Descender: -207, CapHeight: 718, XHeight: 523,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: helveticaObliqueCharMetrics, bboxes: helveticaObliqueGlyphBBoxes, kerning: helveticaKerning,
+	},
+	"Symbol": {
+		FontName: "Symbol", FontBBox: [4]float64{-180, -293, 1090, 1010},
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: symbolCharMetrics, bboxes: symbolGlyphBBoxes,
+		encoder: textencoding.NewSymbolEncoder(),
+	},
+	"Times-Bold": {
+		FontName: "Times-Bold", FontBBox: [4]float64{-168, -218, 1000, 935},
+		Ascender: 683, Descender: -217, CapHeight: 676, XHeight: 461,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: timesBoldCharMetrics, bboxes: timesBoldGlyphBBoxes, kerning: timesBoldKerning,
+	},
+	"Times-BoldItalic": {
+		FontName: "Times-BoldItalic", FontBBox: [4]float64{-200, -218, 996, 921}, ItalicAngle: -15,
+		Ascender: 683, Descender: -217, CapHeight: 669, XHeight: 462,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: timesBoldItalicCharMetrics, bboxes: timesBoldItalicGlyphBBoxes, kerning: timesBoldItalicKerning,
+	},
+	"Times-Italic": {
+		FontName: "Times-Italic", FontBBox: [4]float64{-169, -217, 1010, 883}, ItalicAngle: -15.5,
+		Ascender: 683, Descender: -217, CapHeight: 653, XHeight: 441,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: timesItalicCharMetrics, bboxes: timesItalicGlyphBBoxes, kerning: timesItalicKerning,
+	},
+	"Times-Roman": {
+		FontName: "Times-Roman", FontBBox: [4]float64{-168, -218, 1000, 898},
+		Ascender: 683, Descender: -217, CapHeight: 662, XHeight: 450,
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: timesRomanCharMetrics, bboxes: timesRomanGlyphBBoxes, kerning: timesRomanKerning,
+	},
+	"ZapfDingbats": {
+		FontName: "ZapfDingbats", FontBBox: [4]float64{-1, -143, 981, 820},
+		UnderlinePosition: -100, UnderlineThickness: 50,
+		charMetrics: zapfDingbatsCharMetrics, bboxes: zapfDingbatsGlyphBBoxes,
+		encoder: textencoding.NewZapfDingbatsEncoder(),
+	},
+}
+
+// GetStdFontMetrics returns the metrics of the standard 14 font with the name, e.g. Helvetica-Bold, and whether it is
+// a standard 14 font.
+func GetStdFontMetrics(name string) (*StdFontMetrics, bool) {
+	metrics, has := stdFontMetrics[name]
+	return metrics, has
+}
+
+// GetStdFontNames returns the sorted names of the standard 14 fonts.
+func GetStdFontNames() []string {
+	names := []string{}
+	for name := range stdFontMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetGlyphCharMetrics returns the metrics of the glyph, with the name of the glyph, and whether the font has it.
+func (metrics *StdFontMetrics) GetGlyphCharMetrics(glyph string) (CharMetrics, bool) {
+	charMetrics, has := metrics.charMetrics[glyph]
+	return charMetrics, has
+}
+
+// GetGlyphBBox returns the bounding box of the glyph (lower left x, y and upper right x, y), and whether the font has
+// it.
+func (metrics *StdFontMetrics) GetGlyphBBox(glyph string) ([4]float64, bool) {
+	bbox, has := metrics.bboxes[glyph]
+	return bbox, has
+}
+
+// GetKerning returns the kerning adjustment of the glyph right after the glyph left, usually negative, 0 if none.
+func (metrics *StdFontMetrics) GetKerning(left, right string) float64 {
+	return metrics.kerning[KernPair{Left: left, Right: right}]
+}
+
+// RuneToGlyph returns the name of the glyph of the rune in the font: in WinAnsiEncoding for the text fonts and in the
+// built-in encoding for Symbol and ZapfDingbats.
+func (metrics *StdFontMetrics) RuneToGlyph(r rune) (string, bool) {
+	encoder := metrics.encoder
+	if encoder == nil {
+		encoder = textencoding.NewWinAnsiTextEncoder()
+	}
+	glyph, found := encoder.RuneToGlyph(r)
+	if !found {
+		return "", false
+	}
+	if _, has := metrics.charMetrics[glyph]; !has {
+		return "", false
+	}
+	return glyph, true
+}
+
+// GetStringWidth returns the width of the text shown with the font at the font size, optionally kerned.  Runes
+// without glyph in the font are skipped.
+func (metrics *StdFontMetrics) GetStringWidth(text string, fontSize float64, kerning bool) float64 {
+	width := 0.0
+	previous := ""
+	for _, r := range text {
+		glyph, found := metrics.RuneToGlyph(r)
+		if !found {
+			continue
+		}
+		width += metrics.charMetrics[glyph].Wx
+		if kerning && previous != "" {
+			width += metrics.GetKerning(previous, glyph)
+		}
+		previous = glyph
+	}
+	return width * fontSize / 1000
+}
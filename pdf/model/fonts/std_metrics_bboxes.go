@@ -0,0 +1,4238 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+/*
+ * The embedded font metrics specified in this file are distributed under the terms listed in
+ * ./afms/MustRead.html.
+ */
+
+package fonts
+
+// Courier glyph bounding boxes loaded from afms/Courier.afm.  See afms/MustRead.html for license information.
+var courierGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {3, 0, 597, 562},
+	"AE":             {3, 0, 550, 562},
+	"Aacute":         {3, 0, 597, 805},
+	"Abreve":         {3, 0, 597, 732},
+	"Acircumflex":    {3, 0, 597, 787},
+	"Adieresis":      {3, 0, 597, 753},
+	"Agrave":         {3, 0, 597, 805},
+	"Amacron":        {3, 0, 597, 698},
+	"Aogonek":        {3, -172, 608, 562},
+	"Aring":          {3, 0, 597, 750},
+	"Atilde":         {3, 0, 597, 729},
+	"B":              {43, 0, 559, 562},
+	"C":              {41, -18, 540, 580},
+	"Cacute":         {41, -18, 540, 805},
+	"Ccaron":         {41, -18, 540, 802},
+	"Ccedilla":       {41, -151, 540, 580},
+	"D":              {43, 0, 574, 562},
+	"Dcaron":         {43, 0, 574, 802},
+	"Dcroat":         {30, 0, 574, 562},
+	"Delta":          {6, 0, 598, 688},
+	"E":              {53, 0, 550, 562},
+	"Eacute":         {53, 0, 550, 805},
+	"Ecaron":         {53, 0, 550, 802},
+	"Ecircumflex":    {53, 0, 550, 787},
+	"Edieresis":      {53, 0, 550, 753},
+	"Edotaccent":     {53, 0, 550, 753},
+	"Egrave":         {53, 0, 550, 805},
+	"Emacron":        {53, 0, 550, 698},
+	"Eogonek":        {53, -172, 561, 562},
+	"Eth":            {30, 0, 574, 562},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {53, 0, 545, 562},
+	"G":              {31, -18, 575, 580},
+	"Gbreve":         {31, -18, 575, 732},
+	"Gcommaaccent":   {31, -250, 575, 580},
+	"H":              {32, 0, 568, 562},
+	"I":              {96, 0, 504, 562},
+	"Iacute":         {96, 0, 504, 805},
+	"Icircumflex":    {96, 0, 504, 787},
+	"Idieresis":      {96, 0, 504, 753},
+	"Idotaccent":     {96, 0, 504, 753},
+	"Igrave":         {96, 0, 504, 805},
+	"Imacron":        {96, 0, 504, 698},
+	"Iogonek":        {96, -172, 504, 562},
+	"J":              {34, -18, 566, 562},
+	"K":              {38, 0, 582, 562},
+	"Kcommaaccent":   {38, -250, 582, 562},
+	"L":              {47, 0, 554, 562},
+	"Lacute":         {47, 0, 554, 805},
+	"Lcaron":         {47, 0, 554, 562},
+	"Lcommaaccent":   {47, -250, 554, 562},
+	"Lslash":         {47, 0, 554, 562},
+	"M":              {4, 0, 596, 562},
+	"N":              {7, -13, 593, 562},
+	"Nacute":         {7, -13, 593, 805},
+	"Ncaron":         {7, -13, 593, 802},
+	"Ncommaaccent":   {7, -250, 593, 562},
+	"Ntilde":         {7, -13, 593, 729},
+	"O":              {43, -18, 557, 580},
+	"OE":             {7, 0, 567, 562},
+	"Oacute":         {43, -18, 557, 805},
+	"Ocircumflex":    {43, -18, 557, 787},
+	"Odieresis":      {43, -18, 557, 753},
+	"Ograve":         {43, -18, 557, 805},
+	"Ohungarumlaut":  {43, -18, 580, 805},
+	"Omacron":        {43, -18, 557, 698},
+	"Oslash":         {43, -80, 557, 629},
+	"Otilde":         {43, -18, 557, 729},
+	"P":              {79, 0, 558, 562},
+	"Q":              {43, -138, 557, 580},
+	"R":              {38, 0, 588, 562},
+	"Racute":         {38, 0, 588, 805},
+	"Rcaron":         {38, 0, 588, 802},
+	"Rcommaaccent":   {38, -250, 588, 562},
+	"S":              {72, -20, 529, 580},
+	"Sacute":         {72, -20, 529, 805},
+	"Scaron":         {72, -20, 529, 802},
+	"Scedilla":       {72, -151, 529, 580},
+	"Scommaaccent":   {72, -250, 529, 580},
+	"T":              {38, 0, 563, 562},
+	"Tcaron":         {38, 0, 563, 802},
+	"Tcommaaccent":   {38, -250, 563, 562},
+	"Thorn":          {79, 0, 538, 562},
+	"U":              {17, -18, 583, 562},
+	"Uacute":         {17, -18, 583, 805},
+	"Ucircumflex":    {17, -18, 583, 787},
+	"Udieresis":      {17, -18, 583, 753},
+	"Ugrave":         {17, -18, 583, 805},
+	"Uhungarumlaut":  {17, -18, 590, 805},
+	"Umacron":        {17, -18, 583, 698},
+	"Uogonek":        {17, -172, 583, 562},
+	"Uring":          {17, -18, 583, 760},
+	"V":              {-4, -13, 604, 562},
+	"W":              {-3, -13, 603, 562},
+	"X":              {23, 0, 577, 562},
+	"Y":              {24, 0, 576, 562},
+	"Yacute":         {24, 0, 576, 805},
+	"Ydieresis":      {24, 0, 576, 753},
+	"Z":              {86, 0, 514, 562},
+	"Zacute":         {86, 0, 514, 805},
+	"Zcaron":         {86, 0, 514, 802},
+	"Zdotaccent":     {86, 0, 514, 753},
+	"a":              {53, -15, 559, 441},
+	"aacute":         {53, -15, 559, 672},
+	"abreve":         {53, -15, 559, 609},
+	"acircumflex":    {53, -15, 559, 654},
+	"acute":          {242, 497, 469, 672},
+	"adieresis":      {53, -15, 559, 620},
+	"ae":             {19, -15, 570, 441},
+	"agrave":         {53, -15, 559, 672},
+	"amacron":        {53, -15, 559, 565},
+	"ampersand":      {63, -15, 538, 543},
+	"aogonek":        {53, -172, 587, 441},
+	"aring":          {53, -15, 559, 627},
+	"asciicircum":    {94, 354, 506, 622},
+	"asciitilde":     {63, 197, 540, 320},
+	"asterisk":       {116, 257, 484, 607},
+	"at":             {77, -15, 533, 622},
+	"atilde":         {53, -15, 559, 606},
+	"b":              {14, -15, 575, 629},
+	"backslash":      {118, -80, 482, 629},
+	"bar":            {275, -250, 326, 750},
+	"braceleft":      {182, -108, 437, 622},
+	"braceright":     {163, -108, 418, 622},
+	"bracketleft":    {269, -108, 442, 622},
+	"bracketright":   {158, -108, 331, 622},
+	"breve":          {153, 501, 447, 609},
+	"brokenbar":      {275, -175, 326, 675},
+	"bullet":         {172, 130, 428, 383},
+	"c":              {66, -15, 529, 441},
+	"cacute":         {66, -15, 529, 672},
+	"caron":          {124, 492, 476, 669},
+	"ccaron":         {66, -15, 529, 669},
+	"ccedilla":       {66, -151, 529, 441},
+	"cedilla":        {224, -151, 362, 10},
+	"cent":           {96, -49, 500, 614},
+	"circumflex":     {124, 477, 476, 654},
+	"colon":          {229, -15, 371, 385},
+	"comma":          {181, -112, 344, 122},
+	"commaaccent":    {198, -250, 335, -58},
+	"copyright":      {0, -18, 600, 580},
+	"currency":       {73, 58, 527, 506},
+	"d":              {45, -15, 591, 629},
+	"dagger":         {141, -78, 459, 580},
+	"daggerdbl":      {141, -78, 459, 580},
+	"dcaron":         {45, -15, 715, 629},
+	"dcroat":         {45, -15, 591, 629},
+	"degree":         {123, 269, 477, 622},
+	"dieresis":       {148, 537, 453, 640},
+	"divide":         {87, 48, 513, 467},
+	"dollar":         {105, -126, 496, 662},
+	"dotaccent":      {249, 537, 352, 640},
+	"dotlessi":       {95, 0, 505, 426},
+	"e":              {66, -15, 548, 441},
+	"eacute":         {66, -15, 548, 672},
+	"ecaron":         {66, -15, 548, 669},
+	"ecircumflex":    {66, -15, 548, 654},
+	"edieresis":      {66, -15, 548, 620},
+	"edotaccent":     {66, -15, 548, 620},
+	"egrave":         {66, -15, 548, 672},
+	"eight":          {102, -15, 498, 622},
+	"ellipsis":       {37, -15, 563, 111},
+	"emacron":        {66, -15, 548, 565},
+	"emdash":         {0, 231, 600, 285},
+	"endash":         {75, 231, 525, 285},
+	"eogonek":        {66, -172, 548, 441},
+	"equal":          {80, 138, 520, 376},
+	"eth":            {62, -15, 538, 629},
+	"exclam":         {236, -15, 364, 572},
+	"exclamdown":     {236, -157, 364, 430},
+	"f":              {114, 0, 531, 629},
+	"fi":             {3, 0, 597, 629},
+	"five":           {92, -15, 497, 607},
+	"fl":             {3, 0, 597, 629},
+	"florin":         {4, -143, 539, 622},
+	"four":           {78, 0, 500, 622},
+	"fraction":       {92, -57, 509, 665},
+	"g":              {45, -157, 566, 441},
+	"gbreve":         {45, -157, 566, 609},
+	"gcommaaccent":   {45, -157, 566, 708},
+	"germandbls":     {48, -15, 588, 629},
+	"grave":          {151, 497, 378, 672},
+	"greater":        {66, 42, 544, 472},
+	"greaterequal":   {98, 0, 502, 710},
+	"guillemotleft":  {37, 70, 563, 446},
+	"guillemotright": {37, 70, 563, 446},
+	"guilsinglleft":  {149, 70, 451, 446},
+	"guilsinglright": {149, 70, 451, 446},
+	"h":              {18, 0, 582, 629},
+	"hungarumlaut":   {133, 497, 540, 672},
+	"hyphen":         {103, 231, 497, 285},
+	"i":              {95, 0, 505, 657},
+	"iacute":         {95, 0, 505, 672},
+	"icircumflex":    {94, 0, 505, 654},
+	"idieresis":      {95, 0, 505, 620},
+	"igrave":         {95, 0, 505, 672},
+	"imacron":        {95, 0, 505, 565},
+	"iogonek":        {95, -172, 505, 657},
+	"j":              {82, -157, 410, 657},
+	"k":              {43, 0, 580, 629},
+	"kcommaaccent":   {43, -250, 580, 629},
+	"l":              {95, 0, 505, 629},
+	"lacute":         {95, 0, 505, 805},
+	"lcaron":         {95, 0, 533, 629},
+	"lcommaaccent":   {95, -250, 505, 629},
+	"less":           {41, 42, 519, 472},
+	"lessequal":      {98, 0, 502, 710},
+	"logicalnot":     {87, 108, 513, 369},
+	"lozenge":        {18, 0, 443, 706},
+	"lslash":         {95, 0, 505, 629},
+	"m":              {-5, 0, 605, 441},
+	"macron":         {120, 525, 480, 565},
+	"minus":          {80, 232, 520, 283},
+	"mu":             {21, -157, 562, 426},
+	"multiply":       {87, 43, 515, 470},
+	"n":              {26, 0, 575, 441},
+	"nacute":         {26, 0, 575, 672},
+	"ncaron":         {26, 0, 575, 669},
+	"ncommaaccent":   {26, -250, 575, 441},
+	"nine":           {96, -15, 489, 622},
+	"notequal":       {15, -16, 540, 529},
+	"ntilde":         {26, 0, 575, 606},
+	"numbersign":     {93, -32, 507, 639},
+	"o":              {62, -15, 538, 441},
+	"oacute":         {62, -15, 538, 672},
+	"ocircumflex":    {62, -15, 538, 654},
+	"odieresis":      {62, -15, 538, 620},
+	"oe":             {19, -15, 559, 441},
+	"ogonek":         {211, -172, 407, 4},
+	"ograve":         {62, -15, 538, 672},
+	"ohungarumlaut":  {62, -15, 580, 672},
+	"omacron":        {62, -15, 538, 565},
+	"one":            {96, 0, 505, 622},
+	"onehalf":        {0, -57, 611, 665},
+	"onequarter":     {0, -57, 600, 665},
+	"onesuperior":    {172, 249, 428, 622},
+	"ordfeminine":    {156, 249, 442, 580},
+	"ordmasculine":   {157, 249, 443, 580},
+	"oslash":         {62, -80, 538, 506},
+	"otilde":         {62, -15, 538, 606},
+	"p":              {9, -157, 555, 441},
+	"paragraph":      {50, -78, 511, 562},
+	"parenleft":      {269, -108, 440, 622},
+	"parenright":     {160, -108, 331, 622},
+	"partialdiff":    {17, -38, 459, 710},
+	"percent":        {81, -15, 518, 622},
+	"period":         {229, -15, 371, 109},
+	"periodcentered": {222, 189, 378, 327},
+	"perthousand":    {3, -15, 600, 622},
+	"plus":           {80, 44, 520, 470},
+	"plusminus":      {87, 44, 513, 558},
+	"q":              {45, -157, 591, 441},
+	"question":       {129, -15, 492, 572},
+	"questiondown":   {108, -157, 471, 430},
+	"quotedbl":       {187, 328, 413, 562},
+	"quotedblbase":   {143, -134, 457, 100},
+	"quotedblleft":   {143, 328, 471, 562},
+	"quotedblright":  {143, 328, 457, 562},
+	"quoteleft":      {224, 328, 387, 562},
+	"quoteright":     {213, 328, 376, 562},
+	"quotesinglbase": {213, -134, 376, 100},
+	"quotesingle":    {259, 328, 341, 562},
+	"r":              {60, 0, 559, 441},
+	"racute":         {60, 0, 559, 672},
+	"radical":        {3, -15, 597, 792},
+	"rcaron":         {60, 0, 559, 669},
+	"rcommaaccent":   {60, -250, 559, 441},
+	"registered":     {0, -18, 600, 580},
+	"ring":           {218, 463, 382, 627},
+	"s":              {80, -15, 513, 441},
+	"sacute":         {80, -15, 513, 672},
+	"scaron":         {80, -15, 513, 669},
+	"scedilla":       {80, -151, 513, 441},
+	"scommaaccent":   {80, -250, 513, 441},
+	"section":        {113, -78, 488, 580},
+	"semicolon":      {181, -112, 371, 385},
+	"seven":          {82, 0, 483, 607},
+	"six":            {111, -15, 497, 622},
+	"slash":          {125, -80, 475, 629},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {84, -21, 521, 611},
+	"summation":      {15, -10, 585, 706},
+	"t":              {87, -15, 530, 561},
+	"tcaron":         {87, -15, 530, 717},
+	"tcommaaccent":   {87, -250, 530, 561},
+	"thorn":          {-6, -157, 555, 629},
+	"three":          {75, -15, 466, 622},
+	"threequarters":  {8, -56, 593, 666},
+	"threesuperior":  {155, 240, 406, 622},
+	"tilde":          {105, 489, 503, 606},
+	"trademark":      {-23, 263, 623, 562},
+	"two":            {70, 0, 471, 622},
+	"twosuperior":    {177, 249, 424, 622},
+	"u":              {21, -15, 562, 426},
+	"uacute":         {21, -15, 562, 672},
+	"ucircumflex":    {21, -15, 562, 654},
+	"udieresis":      {21, -15, 562, 620},
+	"ugrave":         {21, -15, 562, 672},
+	"uhungarumlaut":  {21, -15, 580, 672},
+	"umacron":        {21, -15, 562, 565},
+	"underscore":     {0, -125, 600, -75},
+	"uogonek":        {21, -172, 590, 426},
+	"uring":          {21, -15, 562, 627},
+	"v":              {10, -10, 590, 426},
+	"w":              {-4, -10, 604, 426},
+	"x":              {20, 0, 580, 426},
+	"y":              {7, -157, 592, 426},
+	"yacute":         {7, -157, 592, 672},
+	"ydieresis":      {7, -157, 592, 620},
+	"yen":            {26, 0, 574, 562},
+	"z":              {99, 0, 502, 426},
+	"zacute":         {99, 0, 502, 672},
+	"zcaron":         {99, 0, 502, 669},
+	"zdotaccent":     {99, 0, 502, 620},
+	"zero":           {106, -15, 494, 622},
+}
+
+// Courier-Bold glyph bounding boxes loaded from afms/Courier-Bold.afm.  See afms/MustRead.html for license information.
+var courierBoldGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {-9, 0, 609, 562},
+	"AE":             {-29, 0, 602, 562},
+	"Aacute":         {-9, 0, 609, 784},
+	"Abreve":         {-9, 0, 609, 784},
+	"Acircumflex":    {-9, 0, 609, 780},
+	"Adieresis":      {-9, 0, 609, 761},
+	"Agrave":         {-9, 0, 609, 784},
+	"Amacron":        {-9, 0, 609, 708},
+	"Aogonek":        {-9, -199, 625, 562},
+	"Aring":          {-9, 0, 609, 801},
+	"Atilde":         {-9, 0, 609, 759},
+	"B":              {30, 0, 573, 562},
+	"C":              {22, -18, 560, 580},
+	"Cacute":         {22, -18, 560, 784},
+	"Ccaron":         {22, -18, 560, 790},
+	"Ccedilla":       {22, -206, 560, 580},
+	"D":              {30, 0, 594, 562},
+	"Dcaron":         {30, 0, 594, 790},
+	"Dcroat":         {30, 0, 594, 562},
+	"Delta":          {6, 0, 594, 688},
+	"E":              {25, 0, 560, 562},
+	"Eacute":         {25, 0, 560, 784},
+	"Ecaron":         {25, 0, 560, 790},
+	"Ecircumflex":    {25, 0, 560, 780},
+	"Edieresis":      {25, 0, 560, 761},
+	"Edotaccent":     {25, 0, 560, 761},
+	"Egrave":         {25, 0, 560, 784},
+	"Emacron":        {25, 0, 560, 708},
+	"Eogonek":        {25, -199, 576, 562},
+	"Eth":            {30, 0, 594, 562},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {39, 0, 570, 562},
+	"G":              {22, -18, 594, 580},
+	"Gbreve":         {22, -18, 594, 784},
+	"Gcommaaccent":   {22, -250, 594, 580},
+	"H":              {20, 0, 580, 562},
+	"I":              {77, 0, 523, 562},
+	"Iacute":         {77, 0, 523, 784},
+	"Icircumflex":    {77, 0, 523, 780},
+	"Idieresis":      {77, 0, 523, 761},
+	"Idotaccent":     {77, 0, 523, 761},
+	"Igrave":         {77, 0, 523, 784},
+	"Imacron":        {77, 0, 523, 708},
+	"Iogonek":        {77, -199, 523, 562},
+	"J":              {37, -18, 601, 562},
+	"K":              {21, 0, 599, 562},
+	"Kcommaaccent":   {21, -250, 599, 562},
+	"L":              {39, 0, 578, 562},
+	"Lacute":         {39, 0, 578, 784},
+	"Lcaron":         {39, 0, 637, 562},
+	"Lcommaaccent":   {39, -250, 578, 562},
+	"Lslash":         {39, 0, 578, 562},
+	"M":              {-2, 0, 602, 562},
+	"N":              {8, -12, 610, 562},
+	"Nacute":         {8, -12, 610, 784},
+	"Ncaron":         {8, -12, 610, 790},
+	"Ncommaaccent":   {8, -250, 610, 562},
+	"Ntilde":         {8, -12, 610, 759},
+	"O":              {22, -18, 578, 580},
+	"OE":             {-25, 0, 595, 562},
+	"Oacute":         {22, -18, 578, 784},
+	"Ocircumflex":    {22, -18, 578, 780},
+	"Odieresis":      {22, -18, 578, 761},
+	"Ograve":         {22, -18, 578, 784},
+	"Ohungarumlaut":  {22, -18, 628, 784},
+	"Omacron":        {22, -18, 578, 708},
+	"Oslash":         {22, -22, 578, 584},
+	"Otilde":         {22, -18, 578, 759},
+	"P":              {48, 0, 559, 562},
+	"Q":              {32, -138, 578, 580},
+	"R":              {24, 0, 599, 562},
+	"Racute":         {24, 0, 599, 784},
+	"Rcaron":         {24, 0, 599, 790},
+	"Rcommaaccent":   {24, -250, 599, 562},
+	"S":              {47, -22, 553, 582},
+	"Sacute":         {47, -22, 553, 784},
+	"Scaron":         {47, -22, 553, 790},
+	"Scedilla":       {47, -206, 553, 582},
+	"Scommaaccent":   {47, -250, 553, 582},
+	"T":              {21, 0, 579, 562},
+	"Tcaron":         {21, 0, 579, 790},
+	"Tcommaaccent":   {21, -250, 579, 562},
+	"Thorn":          {48, 0, 557, 562},
+	"U":              {4, -18, 596, 562},
+	"Uacute":         {4, -18, 596, 784},
+	"Ucircumflex":    {4, -18, 596, 780},
+	"Udieresis":      {4, -18, 596, 761},
+	"Ugrave":         {4, -18, 596, 784},
+	"Uhungarumlaut":  {4, -18, 638, 784},
+	"Umacron":        {4, -18, 596, 708},
+	"Uogonek":        {4, -199, 596, 562},
+	"Uring":          {4, -18, 596, 801},
+	"V":              {-13, 0, 613, 562},
+	"W":              {-18, 0, 618, 562},
+	"X":              {12, 0, 588, 562},
+	"Y":              {12, 0, 589, 562},
+	"Yacute":         {12, 0, 589, 784},
+	"Ydieresis":      {12, 0, 589, 761},
+	"Z":              {62, 0, 539, 562},
+	"Zacute":         {62, 0, 539, 784},
+	"Zcaron":         {62, 0, 539, 790},
+	"Zdotaccent":     {62, 0, 539, 761},
+	"a":              {35, -15, 570, 454},
+	"aacute":         {35, -15, 570, 661},
+	"abreve":         {35, -15, 570, 661},
+	"acircumflex":    {35, -15, 570, 657},
+	"acute":          {205, 508, 468, 661},
+	"adieresis":      {35, -15, 570, 638},
+	"ae":             {-4, -15, 601, 454},
+	"agrave":         {35, -15, 570, 661},
+	"amacron":        {35, -15, 570, 585},
+	"ampersand":      {36, -15, 546, 543},
+	"aogonek":        {35, -199, 586, 454},
+	"aring":          {35, -15, 570, 678},
+	"asciicircum":    {108, 250, 492, 616},
+	"asciitilde":     {71, 153, 530, 356},
+	"asterisk":       {91, 219, 509, 601},
+	"at":             {16, -15, 584, 616},
+	"atilde":         {35, -15, 570, 636},
+	"b":              {0, -15, 584, 626},
+	"backslash":      {99, -77, 503, 626},
+	"bar":            {255, -250, 345, 750},
+	"braceleft":      {160, -102, 464, 616},
+	"braceright":     {136, -102, 440, 616},
+	"bracketleft":    {245, -102, 475, 616},
+	"bracketright":   {125, -102, 355, 616},
+	"breve":          {83, 468, 517, 631},
+	"brokenbar":      {255, -175, 345, 675},
+	"bullet":         {140, 132, 460, 430},
+	"c":              {40, -15, 545, 459},
+	"cacute":         {40, -15, 545, 661},
+	"caron":          {103, 493, 497, 667},
+	"ccaron":         {40, -15, 545, 667},
+	"ccedilla":       {40, -206, 545, 459},
+	"cedilla":        {205, -206, 387, 0},
+	"cent":           {66, -49, 518, 614},
+	"circumflex":     {103, 483, 497, 657},
+	"colon":          {191, -15, 407, 425},
+	"comma":          {123, -111, 393, 174},
+	"commaaccent":    {205, -250, 397, -57},
+	"copyright":      {0, -18, 600, 580},
+	"currency":       {54, 49, 546, 517},
+	"d":              {20, -15, 591, 626},
+	"dagger":         {106, -70, 494, 580},
+	"daggerdbl":      {106, -70, 494, 580},
+	"dcaron":         {20, -15, 727, 626},
+	"dcroat":         {20, -15, 591, 626},
+	"degree":         {86, 243, 474, 616},
+	"dieresis":       {128, 498, 472, 638},
+	"divide":         {71, 16, 529, 500},
+	"dollar":         {82, -126, 519, 666},
+	"dotaccent":      {230, 498, 370, 638},
+	"dotlessi":       {77, 0, 523, 439},
+	"e":              {40, -15, 563, 454},
+	"eacute":         {40, -15, 563, 661},
+	"ecaron":         {40, -15, 563, 667},
+	"ecircumflex":    {40, -15, 563, 657},
+	"edieresis":      {40, -15, 563, 638},
+	"edotaccent":     {40, -15, 563, 638},
+	"egrave":         {40, -15, 563, 661},
+	"eight":          {83, -15, 517, 616},
+	"ellipsis":       {26, -15, 574, 116},
+	"emacron":        {40, -15, 563, 585},
+	"emdash":         {-10, 203, 610, 313},
+	"endash":         {65, 203, 535, 313},
+	"eogonek":        {40, -199, 563, 454},
+	"equal":          {71, 118, 529, 398},
+	"eth":            {58, -27, 543, 626},
+	"exclam":         {202, -15, 398, 572},
+	"exclamdown":     {202, -146, 398, 449},
+	"f":              {83, 0, 547, 626},
+	"fi":             {12, 0, 593, 626},
+	"five":           {70, -15, 521, 601},
+	"fl":             {12, 0, 593, 626},
+	"florin":         {-30, -131, 572, 616},
+	"four":           {53, 0, 507, 616},
+	"fraction":       {25, -60, 576, 661},
+	"g":              {30, -146, 580, 454},
+	"gbreve":         {30, -146, 580, 661},
+	"gcommaaccent":   {30, -146, 580, 714},
+	"germandbls":     {22, -15, 596, 626},
+	"grave":          {132, 508, 395, 661},
+	"greater":        {77, 15, 534, 501},
+	"greaterequal":   {26, 0, 523, 696},
+	"guillemotleft":  {8, 70, 553, 446},
+	"guillemotright": {47, 70, 592, 446},
+	"guilsinglleft":  {141, 70, 459, 446},
+	"guilsinglright": {141, 70, 459, 446},
+	"h":              {5, 0, 592, 626},
+	"hungarumlaut":   {68, 488, 588, 661},
+	"hyphen":         {100, 203, 500, 313},
+	"i":              {77, 0, 523, 658},
+	"iacute":         {77, 0, 523, 661},
+	"icircumflex":    {73, 0, 523, 657},
+	"idieresis":      {77, 0, 523, 618},
+	"igrave":         {77, 0, 523, 661},
+	"imacron":        {77, 0, 523, 585},
+	"iogonek":        {77, -199, 523, 658},
+	"j":              {63, -146, 440, 658},
+	"k":              {20, 0, 585, 626},
+	"kcommaaccent":   {20, -250, 585, 626},
+	"l":              {77, 0, 523, 626},
+	"lacute":         {77, 0, 523, 801},
+	"lcaron":         {77, 0, 597, 626},
+	"lcommaaccent":   {77, -250, 523, 626},
+	"less":           {66, 15, 523, 501},
+	"lessequal":      {26, 0, 523, 696},
+	"logicalnot":     {71, 103, 529, 413},
+	"lozenge":        {66, 0, 534, 740},
+	"lslash":         {77, 0, 523, 626},
+	"m":              {-22, 0, 626, 454},
+	"macron":         {88, 505, 512, 585},
+	"minus":          {71, 203, 529, 313},
+	"mu":             {-1, -142, 569, 439},
+	"multiply":       {81, 39, 520, 478},
+	"n":              {18, 0, 592, 454},
+	"nacute":         {18, 0, 592, 661},
+	"ncaron":         {18, 0, 592, 667},
+	"ncommaaccent":   {18, -250, 592, 454},
+	"nine":           {79, -15, 510, 616},
+	"notequal":       {12, -47, 537, 563},
+	"ntilde":         {18, 0, 592, 636},
+	"numbersign":     {56, -45, 544, 651},
+	"o":              {30, -15, 570, 454},
+	"oacute":         {30, -15, 570, 661},
+	"ocircumflex":    {30, -15, 570, 657},
+	"odieresis":      {30, -15, 570, 638},
+	"oe":             {-18, -15, 611, 454},
+	"ogonek":         {169, -199, 400, 0},
+	"ograve":         {30, -15, 570, 661},
+	"ohungarumlaut":  {30, -15, 668, 661},
+	"omacron":        {30, -15, 570, 585},
+	"one":            {81, 0, 539, 616},
+	"onehalf":        {-47, -60, 648, 661},
+	"onequarter":     {-56, -60, 656, 661},
+	"onesuperior":    {153, 230, 447, 616},
+	"ordfeminine":    {147, 196, 453, 580},
+	"ordmasculine":   {147, 196, 453, 580},
+	"oslash":         {30, -24, 570, 463},
+	"otilde":         {30, -15, 570, 636},
+	"p":              {-1, -142, 570, 454},
+	"paragraph":      {6, -70, 576, 580},
+	"parenleft":      {219, -102, 461, 616},
+	"parenright":     {139, -102, 381, 616},
+	"partialdiff":    {63, -38, 537, 728},
+	"percent":        {5, -15, 595, 616},
+	"period":         {192, -15, 408, 171},
+	"periodcentered": {196, 165, 404, 351},
+	"perthousand":    {-113, -15, 713, 616},
+	"plus":           {71, 39, 529, 478},
+	"plusminus":      {71, 24, 529, 515},
+	"q":              {20, -142, 591, 454},
+	"question":       {98, -14, 501, 580},
+	"questiondown":   {99, -146, 502, 449},
+	"quotedbl":       {135, 277, 465, 562},
+	"quotedblbase":   {65, -142, 529, 143},
+	"quotedblleft":   {71, 277, 535, 562},
+	"quotedblright":  {61, 277, 525, 562},
+	"quoteleft":      {178, 277, 428, 562},
+	"quoteright":     {171, 277, 423, 562},
+	"quotesinglbase": {175, -142, 427, 143},
+	"quotesingle":    {227, 277, 373, 562},
+	"r":              {47, 0, 580, 454},
+	"racute":         {47, 0, 580, 661},
+	"radical":        {-19, -104, 473, 778},
+	"rcaron":         {47, 0, 580, 667},
+	"rcommaaccent":   {47, -250, 580, 454},
+	"registered":     {0, -18, 600, 580},
+	"ring":           {198, 481, 402, 678},
+	"s":              {68, -17, 535, 459},
+	"sacute":         {68, -17, 535, 661},
+	"scaron":         {68, -17, 535, 667},
+	"scedilla":       {68, -206, 535, 459},
+	"scommaaccent":   {68, -250, 535, 459},
+	"section":        {83, -70, 517, 580},
+	"semicolon":      {123, -111, 408, 425},
+	"seven":          {55, 0, 494, 601},
+	"six":            {90, -15, 521, 616},
+	"slash":          {98, -77, 502, 626},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {72, -28, 558, 611},
+	"summation":      {15, -10, 586, 706},
+	"t":              {47, -15, 532, 562},
+	"tcaron":         {47, -15, 532, 703},
+	"tcommaaccent":   {47, -250, 532, 562},
+	"thorn":          {-14, -142, 570, 626},
+	"three":          {63, -15, 501, 616},
+	"threequarters":  {-47, -60, 648, 661},
+	"threesuperior":  {138, 222, 433, 616},
+	"tilde":          {89, 493, 512, 636},
+	"trademark":      {-9, 230, 749, 562},
+	"two":            {61, 0, 499, 616},
+	"twosuperior":    {143, 230, 436, 616},
+	"u":              {-1, -15, 569, 439},
+	"uacute":         {-1, -15, 569, 661},
+	"ucircumflex":    {-1, -15, 569, 657},
+	"udieresis":      {-1, -15, 569, 638},
+	"ugrave":         {-1, -15, 569, 661},
+	"uhungarumlaut":  {-1, -15, 628, 661},
+	"umacron":        {-1, -15, 569, 585},
+	"underscore":     {0, -125, 600, -75},
+	"uogonek":        {-1, -199, 585, 439},
+	"uring":          {-1, -15, 569, 678},
+	"v":              {-1, 0, 601, 439},
+	"w":              {-18, 0, 618, 439},
+	"x":              {6, 0, 594, 439},
+	"y":              {-4, -142, 601, 439},
+	"yacute":         {-4, -142, 601, 661},
+	"ydieresis":      {-4, -142, 601, 638},
+	"yen":            {10, 0, 590, 562},
+	"z":              {81, 0, 520, 439},
+	"zacute":         {81, 0, 520, 661},
+	"zcaron":         {81, 0, 520, 667},
+	"zdotaccent":     {81, 0, 520, 638},
+	"zero":           {87, -15, 513, 616},
+}
+
+// Courier-BoldOblique glyph bounding boxes loaded from afms/Courier-BoldOblique.afm.  See afms/MustRead.html for license information.
+var courierBoldObliqueGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {-9, 0, 632, 562},
+	"AE":             {-29, 0, 708, 562},
+	"Aacute":         {-9, 0, 655, 784},
+	"Abreve":         {-9, 0, 684, 784},
+	"Acircumflex":    {-9, 0, 632, 780},
+	"Adieresis":      {-9, 0, 632, 761},
+	"Agrave":         {-9, 0, 632, 784},
+	"Amacron":        {-9, 0, 633, 708},
+	"Aogonek":        {-9, -199, 632, 562},
+	"Aring":          {-9, 0, 632, 801},
+	"Atilde":         {-9, 0, 669, 759},
+	"B":              {30, 0, 630, 562},
+	"C":              {74, -18, 675, 580},
+	"Cacute":         {74, -18, 675, 784},
+	"Ccaron":         {74, -18, 689, 790},
+	"Ccedilla":       {74, -206, 675, 580},
+	"D":              {30, 0, 664, 562},
+	"Dcaron":         {30, 0, 664, 790},
+	"Dcroat":         {30, 0, 664, 562},
+	"Delta":          {6, 0, 594, 688},
+	"E":              {25, 0, 670, 562},
+	"Eacute":         {25, 0, 670, 784},
+	"Ecaron":         {25, 0, 670, 790},
+	"Ecircumflex":    {25, 0, 670, 780},
+	"Edieresis":      {25, 0, 670, 761},
+	"Edotaccent":     {25, 0, 670, 761},
+	"Egrave":         {25, 0, 670, 784},
+	"Emacron":        {25, 0, 670, 708},
+	"Eogonek":        {25, -199, 670, 562},
+	"Eth":            {30, 0, 664, 562},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {39, 0, 684, 562},
+	"G":              {74, -18, 675, 580},
+	"Gbreve":         {74, -18, 684, 784},
+	"Gcommaaccent":   {74, -250, 675, 580},
+	"H":              {20, 0, 700, 562},
+	"I":              {77, 0, 643, 562},
+	"Iacute":         {77, 0, 643, 784},
+	"Icircumflex":    {77, 0, 643, 780},
+	"Idieresis":      {77, 0, 643, 761},
+	"Idotaccent":     {77, 0, 643, 761},
+	"Igrave":         {77, 0, 643, 784},
+	"Imacron":        {77, 0, 663, 708},
+	"Iogonek":        {77, -199, 643, 562},
+	"J":              {58, -18, 721, 562},
+	"K":              {21, 0, 692, 562},
+	"Kcommaaccent":   {21, -250, 692, 562},
+	"L":              {39, 0, 636, 562},
+	"Lacute":         {39, 0, 636, 784},
+	"Lcaron":         {39, 0, 757, 562},
+	"Lcommaaccent":   {39, -250, 636, 562},
+	"Lslash":         {39, 0, 636, 562},
+	"M":              {-2, 0, 722, 562},
+	"N":              {8, -12, 730, 562},
+	"Nacute":         {8, -12, 730, 784},
+	"Ncaron":         {8, -12, 730, 790},
+	"Ncommaaccent":   {8, -250, 730, 562},
+	"Ntilde":         {8, -12, 730, 759},
+	"O":              {74, -18, 645, 580},
+	"OE":             {26, 0, 701, 562},
+	"Oacute":         {74, -18, 645, 784},
+	"Ocircumflex":    {74, -18, 645, 780},
+	"Odieresis":      {74, -18, 645, 761},
+	"Ograve":         {74, -18, 645, 784},
+	"Ohungarumlaut":  {74, -18, 795, 784},
+	"Omacron":        {74, -18, 663, 708},
+	"Oslash":         {48, -22, 673, 584},
+	"Otilde":         {74, -18, 669, 759},
+	"P":              {48, 0, 643, 562},
+	"Q":              {83, -138, 636, 580},
+	"R":              {24, 0, 617, 562},
+	"Racute":         {24, 0, 665, 784},
+	"Rcaron":         {24, 0, 659, 790},
+	"Rcommaaccent":   {24, -250, 617, 562},
+	"S":              {54, -22, 673, 582},
+	"Sacute":         {54, -22, 673, 784},
+	"Scaron":         {54, -22, 689, 790},
+	"Scedilla":       {54, -206, 673, 582},
+	"Scommaaccent":   {54, -250, 673, 582},
+	"T":              {86, 0, 679, 562},
+	"Tcaron":         {86, 0, 679, 790},
+	"Tcommaaccent":   {86, -250, 679, 562},
+	"Thorn":          {48, 0, 620, 562},
+	"U":              {101, -18, 716, 562},
+	"Uacute":         {101, -18, 716, 784},
+	"Ucircumflex":    {101, -18, 716, 780},
+	"Udieresis":      {101, -18, 716, 761},
+	"Ugrave":         {101, -18, 716, 784},
+	"Uhungarumlaut":  {101, -18, 805, 784},
+	"Umacron":        {101, -18, 716, 708},
+	"Uogonek":        {101, -199, 716, 562},
+	"Uring":          {101, -18, 716, 801},
+	"V":              {84, 0, 733, 562},
+	"W":              {79, 0, 738, 562},
+	"X":              {12, 0, 690, 562},
+	"Y":              {109, 0, 709, 562},
+	"Yacute":         {109, 0, 709, 784},
+	"Ydieresis":      {109, 0, 709, 761},
+	"Z":              {62, 0, 637, 562},
+	"Zacute":         {62, 0, 665, 784},
+	"Zcaron":         {62, 0, 659, 790},
+	"Zdotaccent":     {62, 0, 637, 761},
+	"a":              {61, -15, 593, 454},
+	"aacute":         {61, -15, 609, 661},
+	"abreve":         {61, -15, 658, 661},
+	"acircumflex":    {61, -15, 607, 657},
+	"acute":          {312, 508, 609, 661},
+	"adieresis":      {61, -15, 595, 638},
+	"ae":             {21, -15, 652, 454},
+	"agrave":         {61, -15, 593, 661},
+	"amacron":        {61, -15, 637, 585},
+	"ampersand":      {61, -15, 595, 543},
+	"aogonek":        {61, -199, 593, 454},
+	"aring":          {61, -15, 593, 678},
+	"asciicircum":    {171, 250, 556, 616},
+	"asciitilde":     {120, 153, 590, 356},
+	"asterisk":       {179, 219, 598, 601},
+	"at":             {65, -15, 642, 616},
+	"atilde":         {61, -15, 643, 636},
+	"b":              {13, -15, 636, 626},
+	"backslash":      {222, -77, 496, 626},
+	"bar":            {201, -250, 505, 750},
+	"braceleft":      {203, -102, 595, 616},
+	"braceright":     {114, -102, 506, 616},
+	"bracketleft":    {223, -102, 606, 616},
+	"bracketright":   {103, -102, 486, 616},
+	"breve":          {217, 468, 652, 631},
+	"brokenbar":      {217, -175, 489, 675},
+	"bullet":         {196, 132, 523, 430},
+	"c":              {81, -15, 631, 459},
+	"cacute":         {81, -15, 649, 661},
+	"caron":          {238, 493, 633, 667},
+	"ccaron":         {81, -15, 633, 667},
+	"ccedilla":       {81, -206, 631, 459},
+	"cedilla":        {168, -206, 368, 0},
+	"cent":           {121, -49, 605, 614},
+	"circumflex":     {212, 483, 607, 657},
+	"colon":          {205, -15, 480, 425},
+	"comma":          {99, -111, 430, 174},
+	"commaaccent":    {151, -250, 385, -57},
+	"copyright":      {53, -18, 667, 580},
+	"currency":       {77, 49, 644, 517},
+	"d":              {60, -15, 645, 626},
+	"dagger":         {175, -70, 586, 580},
+	"daggerdbl":      {121, -70, 587, 580},
+	"dcaron":         {60, -15, 861, 626},
+	"dcroat":         {60, -15, 712, 626},
+	"degree":         {173, 243, 570, 616},
+	"dieresis":       {246, 498, 595, 638},
+	"divide":         {114, 16, 596, 500},
+	"dollar":         {87, -126, 630, 666},
+	"dotaccent":      {348, 498, 493, 638},
+	"dotlessi":       {77, 0, 546, 439},
+	"e":              {81, -15, 605, 454},
+	"eacute":         {81, -15, 609, 661},
+	"ecaron":         {81, -15, 633, 667},
+	"ecircumflex":    {81, -15, 607, 657},
+	"edieresis":      {81, -15, 605, 638},
+	"edotaccent":     {81, -15, 605, 638},
+	"egrave":         {81, -15, 605, 661},
+	"eight":          {115, -15, 604, 616},
+	"ellipsis":       {35, -15, 587, 116},
+	"emacron":        {81, -15, 637, 585},
+	"emdash":         {33, 203, 677, 313},
+	"endash":         {108, 203, 602, 313},
+	"eogonek":        {81, -199, 605, 454},
+	"equal":          {96, 118, 614, 398},
+	"eth":            {93, -27, 661, 626},
+	"exclam":         {215, -15, 495, 572},
+	"exclamdown":     {196, -146, 477, 449},
+	"f":              {83, 0, 677, 626},
+	"fi":             {12, 0, 644, 626},
+	"five":           {77, -15, 621, 601},
+	"fl":             {12, 0, 644, 626},
+	"florin":         {-57, -131, 702, 616},
+	"four":           {81, 0, 559, 616},
+	"fraction":       {22, -60, 708, 661},
+	"g":              {40, -146, 674, 454},
+	"gbreve":         {40, -146, 674, 661},
+	"gcommaaccent":   {40, -146, 674, 714},
+	"germandbls":     {22, -15, 629, 626},
+	"grave":          {272, 508, 503, 661},
+	"greater":        {97, 15, 589, 501},
+	"greaterequal":   {26, 0, 627, 696},
+	"guillemotleft":  {62, 70, 639, 446},
+	"guillemotright": {71, 70, 647, 446},
+	"guilsinglleft":  {195, 70, 545, 446},
+	"guilsinglright": {165, 70, 514, 446},
+	"h":              {18, 0, 615, 626},
+	"hungarumlaut":   {171, 488, 729, 661},
+	"hyphen":         {143, 203, 567, 313},
+	"i":              {77, 0, 546, 658},
+	"iacute":         {77, 0, 609, 661},
+	"icircumflex":    {77, 0, 577, 657},
+	"idieresis":      {77, 0, 561, 618},
+	"igrave":         {77, 0, 546, 661},
+	"imacron":        {77, 0, 575, 585},
+	"iogonek":        {77, -199, 546, 658},
+	"j":              {36, -146, 580, 658},
+	"k":              {33, 0, 643, 626},
+	"kcommaaccent":   {33, -250, 643, 626},
+	"l":              {77, 0, 546, 626},
+	"lacute":         {77, 0, 639, 801},
+	"lcaron":         {77, 0, 731, 626},
+	"lcommaaccent":   {77, -250, 546, 626},
+	"less":           {120, 15, 613, 501},
+	"lessequal":      {26, 0, 671, 696},
+	"logicalnot":     {135, 103, 617, 413},
+	"lozenge":        {145, 0, 614, 740},
+	"lslash":         {77, 0, 587, 626},
+	"m":              {-22, 0, 649, 454},
+	"macron":         {195, 505, 637, 585},
+	"minus":          {114, 203, 596, 313},
+	"mu":             {49, -142, 592, 439},
+	"multiply":       {104, 39, 606, 478},
+	"n":              {18, 0, 615, 454},
+	"nacute":         {18, 0, 639, 661},
+	"ncaron":         {18, 0, 633, 667},
+	"ncommaaccent":   {18, -250, 615, 454},
+	"nine":           {75, -15, 592, 616},
+	"notequal":       {30, -47, 626, 563},
+	"ntilde":         {18, 0, 643, 636},
+	"numbersign":     {88, -45, 641, 651},
+	"o":              {71, -15, 622, 454},
+	"oacute":         {71, -15, 649, 661},
+	"ocircumflex":    {71, -15, 622, 657},
+	"odieresis":      {71, -15, 622, 638},
+	"oe":             {18, -15, 662, 454},
+	"ogonek":         {143, -199, 367, 0},
+	"ograve":         {71, -15, 622, 661},
+	"ohungarumlaut":  {71, -15, 809, 661},
+	"omacron":        {71, -15, 637, 585},
+	"one":            {93, 0, 562, 616},
+	"onehalf":        {22, -60, 716, 661},
+	"onequarter":     {13, -60, 707, 661},
+	"onesuperior":    {212, 230, 514, 616},
+	"ordfeminine":    {188, 196, 526, 580},
+	"ordmasculine":   {188, 196, 543, 580},
+	"oslash":         {54, -24, 638, 463},
+	"otilde":         {71, -15, 643, 636},
+	"p":              {-32, -142, 622, 454},
+	"paragraph":      {61, -70, 700, 580},
+	"parenleft":      {265, -102, 592, 616},
+	"parenright":     {117, -102, 444, 616},
+	"partialdiff":    {91, -38, 627, 728},
+	"percent":        {101, -15, 625, 616},
+	"period":         {206, -15, 427, 171},
+	"periodcentered": {248, 165, 461, 351},
+	"perthousand":    {-45, -15, 743, 616},
+	"plus":           {114, 39, 596, 478},
+	"plusminus":      {76, 24, 614, 515},
+	"q":              {60, -142, 685, 454},
+	"question":       {183, -14, 592, 580},
+	"questiondown":   {100, -146, 509, 449},
+	"quotedbl":       {211, 277, 585, 562},
+	"quotedblbase":   {34, -142, 560, 143},
+	"quotedblleft":   {190, 277, 594, 562},
+	"quotedblright":  {119, 277, 645, 562},
+	"quoteleft":      {297, 277, 487, 562},
+	"quoteright":     {229, 277, 543, 562},
+	"quotesinglbase": {144, -142, 458, 143},
+	"quotesingle":    {303, 277, 493, 562},
+	"r":              {47, 0, 655, 454},
+	"racute":         {47, 0, 655, 661},
+	"radical":        {67, -104, 635, 778},
+	"rcaron":         {47, 0, 655, 667},
+	"rcommaaccent":   {47, -250, 655, 454},
+	"registered":     {53, -18, 667, 580},
+	"ring":           {319, 481, 528, 678},
+	"s":              {66, -17, 608, 459},
+	"sacute":         {66, -17, 609, 661},
+	"scaron":         {66, -17, 633, 667},
+	"scedilla":       {66, -206, 608, 459},
+	"scommaaccent":   {66, -250, 608, 459},
+	"section":        {74, -70, 620, 580},
+	"semicolon":      {99, -111, 481, 425},
+	"seven":          {147, 0, 622, 601},
+	"six":            {135, -15, 652, 616},
+	"slash":          {90, -77, 626, 626},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {106, -28, 650, 611},
+	"summation":      {15, -10, 672, 706},
+	"t":              {118, -15, 567, 562},
+	"tcaron":         {118, -15, 627, 703},
+	"tcommaaccent":   {118, -250, 567, 562},
+	"thorn":          {-32, -142, 622, 626},
+	"three":          {71, -15, 571, 616},
+	"threequarters":  {8, -60, 699, 661},
+	"threesuperior":  {193, 222, 526, 616},
+	"tilde":          {199, 493, 643, 636},
+	"trademark":      {86, 230, 869, 562},
+	"two":            {61, 0, 594, 616},
+	"twosuperior":    {191, 230, 542, 616},
+	"u":              {70, -15, 592, 439},
+	"uacute":         {70, -15, 599, 661},
+	"ucircumflex":    {70, -15, 597, 657},
+	"udieresis":      {70, -15, 595, 638},
+	"ugrave":         {70, -15, 592, 661},
+	"uhungarumlaut":  {70, -15, 769, 661},
+	"umacron":        {70, -15, 637, 585},
+	"underscore":     {-27, -125, 585, -75},
+	"uogonek":        {70, -199, 592, 439},
+	"uring":          {70, -15, 592, 678},
+	"v":              {70, 0, 695, 439},
+	"w":              {53, 0, 712, 439},
+	"x":              {6, 0, 671, 439},
+	"y":              {-21, -142, 695, 439},
+	"yacute":         {-21, -142, 695, 661},
+	"ydieresis":      {-21, -142, 695, 638},
+	"yen":            {98, 0, 710, 562},
+	"z":              {81, 0, 614, 439},
+	"zacute":         {81, 0, 614, 661},
+	"zcaron":         {81, 0, 643, 667},
+	"zdotaccent":     {81, 0, 614, 638},
+	"zero":           {135, -15, 593, 616},
+}
+
+// Courier-Oblique glyph bounding boxes loaded from afms/Courier-Oblique.afm.  See afms/MustRead.html for license information.
+var courierObliqueGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {3, 0, 607, 562},
+	"AE":             {3, 0, 655, 562},
+	"Aacute":         {3, 0, 660, 805},
+	"Abreve":         {3, 0, 607, 732},
+	"Acircumflex":    {3, 0, 607, 787},
+	"Adieresis":      {3, 0, 607, 753},
+	"Agrave":         {3, 0, 607, 805},
+	"Amacron":        {3, 0, 607, 698},
+	"Aogonek":        {3, -172, 607, 562},
+	"Aring":          {3, 0, 607, 750},
+	"Atilde":         {3, 0, 655, 729},
+	"B":              {43, 0, 616, 562},
+	"C":              {93, -18, 655, 580},
+	"Cacute":         {93, -18, 655, 805},
+	"Ccaron":         {93, -18, 672, 802},
+	"Ccedilla":       {93, -151, 658, 580},
+	"D":              {43, 0, 645, 562},
+	"Dcaron":         {43, 0, 645, 802},
+	"Dcroat":         {43, 0, 645, 562},
+	"Delta":          {6, 0, 598, 688},
+	"E":              {53, 0, 660, 562},
+	"Eacute":         {53, 0, 670, 805},
+	"Ecaron":         {53, 0, 660, 802},
+	"Ecircumflex":    {53, 0, 660, 787},
+	"Edieresis":      {53, 0, 660, 753},
+	"Edotaccent":     {53, 0, 660, 753},
+	"Egrave":         {53, 0, 660, 805},
+	"Emacron":        {53, 0, 660, 698},
+	"Eogonek":        {53, -172, 660, 562},
+	"Eth":            {43, 0, 645, 562},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {53, 0, 660, 562},
+	"G":              {83, -18, 645, 580},
+	"Gbreve":         {83, -18, 645, 732},
+	"Gcommaaccent":   {83, -250, 645, 580},
+	"H":              {32, 0, 687, 562},
+	"I":              {96, 0, 623, 562},
+	"Iacute":         {96, 0, 640, 805},
+	"Icircumflex":    {96, 0, 623, 787},
+	"Idieresis":      {96, 0, 623, 753},
+	"Idotaccent":     {96, 0, 623, 753},
+	"Igrave":         {96, 0, 623, 805},
+	"Imacron":        {96, 0, 628, 698},
+	"Iogonek":        {96, -172, 623, 562},
+	"J":              {52, -18, 685, 562},
+	"K":              {38, 0, 671, 562},
+	"Kcommaaccent":   {38, -250, 671, 562},
+	"L":              {47, 0, 607, 562},
+	"Lacute":         {47, 0, 607, 805},
+	"Lcaron":         {47, 0, 632, 562},
+	"Lcommaaccent":   {47, -250, 607, 562},
+	"Lslash":         {47, 0, 607, 562},
+	"M":              {4, 0, 715, 562},
+	"N":              {7, -13, 712, 562},
+	"Nacute":         {7, -13, 712, 805},
+	"Ncaron":         {7, -13, 712, 802},
+	"Ncommaaccent":   {7, -250, 712, 562},
+	"Ntilde":         {7, -13, 712, 729},
+	"O":              {94, -18, 625, 580},
+	"OE":             {59, 0, 672, 562},
+	"Oacute":         {94, -18, 640, 805},
+	"Ocircumflex":    {94, -18, 625, 787},
+	"Odieresis":      {94, -18, 625, 753},
+	"Ograve":         {94, -18, 625, 805},
+	"Ohungarumlaut":  {94, -18, 751, 805},
+	"Omacron":        {94, -18, 628, 698},
+	"Oslash":         {94, -80, 625, 629},
+	"Otilde":         {94, -18, 655, 729},
+	"P":              {79, 0, 644, 562},
+	"Q":              {95, -138, 625, 580},
+	"R":              {38, 0, 598, 562},
+	"Racute":         {38, 0, 670, 805},
+	"Rcaron":         {38, 0, 642, 802},
+	"Rcommaaccent":   {38, -250, 598, 562},
+	"S":              {76, -20, 650, 580},
+	"Sacute":         {76, -20, 650, 805},
+	"Scaron":         {76, -20, 672, 802},
+	"Scedilla":       {76, -151, 650, 580},
+	"Scommaaccent":   {76, -250, 650, 580},
+	"T":              {108, 0, 665, 562},
+	"Tcaron":         {108, 0, 665, 802},
+	"Tcommaaccent":   {108, -250, 665, 562},
+	"Thorn":          {79, 0, 606, 562},
+	"U":              {125, -18, 702, 562},
+	"Uacute":         {125, -18, 702, 805},
+	"Ucircumflex":    {125, -18, 702, 787},
+	"Udieresis":      {125, -18, 702, 753},
+	"Ugrave":         {125, -18, 702, 805},
+	"Uhungarumlaut":  {125, -18, 761, 805},
+	"Umacron":        {125, -18, 702, 698},
+	"Uogonek":        {124, -172, 702, 562},
+	"Uring":          {125, -18, 702, 760},
+	"V":              {105, -13, 723, 562},
+	"W":              {106, -13, 722, 562},
+	"X":              {23, 0, 675, 562},
+	"Y":              {133, 0, 695, 562},
+	"Yacute":         {133, 0, 695, 805},
+	"Ydieresis":      {133, 0, 695, 753},
+	"Z":              {86, 0, 610, 562},
+	"Zacute":         {86, 0, 670, 805},
+	"Zcaron":         {86, 0, 642, 802},
+	"Zdotaccent":     {86, 0, 610, 753},
+	"a":              {76, -15, 569, 441},
+	"aacute":         {76, -15, 612, 672},
+	"abreve":         {76, -15, 576, 609},
+	"acircumflex":    {76, -15, 581, 654},
+	"acute":          {348, 497, 612, 672},
+	"adieresis":      {76, -15, 575, 620},
+	"ae":             {41, -15, 626, 441},
+	"agrave":         {76, -15, 569, 672},
+	"amacron":        {76, -15, 600, 565},
+	"ampersand":      {87, -15, 580, 543},
+	"aogonek":        {76, -172, 569, 441},
+	"aring":          {76, -15, 569, 627},
+	"asciicircum":    {175, 354, 587, 622},
+	"asciitilde":     {116, 197, 600, 320},
+	"asterisk":       {212, 257, 580, 607},
+	"at":             {127, -15, 582, 622},
+	"atilde":         {76, -15, 629, 606},
+	"b":              {29, -15, 625, 629},
+	"backslash":      {249, -80, 468, 629},
+	"bar":            {222, -250, 485, 750},
+	"braceleft":      {233, -108, 569, 622},
+	"braceright":     {140, -108, 477, 622},
+	"bracketleft":    {246, -108, 574, 622},
+	"bracketright":   {135, -108, 463, 622},
+	"breve":          {279, 501, 576, 609},
+	"brokenbar":      {238, -175, 469, 675},
+	"bullet":         {224, 130, 485, 383},
+	"c":              {106, -15, 608, 441},
+	"cacute":         {106, -15, 612, 672},
+	"caron":          {262, 492, 614, 669},
+	"ccaron":         {106, -15, 614, 669},
+	"ccedilla":       {106, -151, 614, 441},
+	"cedilla":        {197, -151, 344, 10},
+	"cent":           {151, -49, 588, 614},
+	"circumflex":     {229, 477, 581, 654},
+	"colon":          {238, -15, 441, 385},
+	"comma":          {157, -112, 370, 122},
+	"commaaccent":    {145, -250, 323, -58},
+	"copyright":      {53, -18, 667, 580},
+	"currency":       {94, 58, 628, 506},
+	"d":              {85, -15, 640, 629},
+	"dagger":         {217, -78, 546, 580},
+	"daggerdbl":      {163, -78, 546, 580},
+	"dcaron":         {85, -15, 849, 629},
+	"dcroat":         {85, -15, 704, 629},
+	"degree":         {214, 269, 576, 622},
+	"dieresis":       {272, 537, 579, 640},
+	"divide":         {136, 48, 573, 467},
+	"dollar":         {108, -126, 596, 662},
+	"dotaccent":      {373, 537, 478, 640},
+	"dotlessi":       {95, 0, 515, 426},
+	"e":              {106, -15, 598, 441},
+	"eacute":         {106, -15, 612, 672},
+	"ecaron":         {106, -15, 614, 669},
+	"ecircumflex":    {106, -15, 598, 654},
+	"edieresis":      {106, -15, 598, 620},
+	"edotaccent":     {106, -15, 598, 620},
+	"egrave":         {106, -15, 598, 672},
+	"eight":          {132, -15, 588, 622},
+	"ellipsis":       {46, -15, 575, 111},
+	"emacron":        {106, -15, 600, 565},
+	"emdash":         {49, 231, 661, 285},
+	"endash":         {124, 231, 586, 285},
+	"eogonek":        {106, -172, 598, 441},
+	"equal":          {109, 138, 600, 376},
+	"eth":            {102, -15, 639, 629},
+	"exclam":         {243, -15, 464, 572},
+	"exclamdown":     {225, -157, 445, 430},
+	"f":              {114, 0, 662, 629},
+	"fi":             {3, 0, 619, 629},
+	"five":           {99, -15, 589, 607},
+	"fl":             {3, 0, 619, 629},
+	"florin":         {-26, -143, 671, 622},
+	"four":           {108, 0, 541, 622},
+	"fraction":       {84, -57, 646, 665},
+	"g":              {61, -157, 657, 441},
+	"gbreve":         {61, -157, 657, 609},
+	"gcommaaccent":   {61, -157, 657, 708},
+	"germandbls":     {48, -15, 617, 629},
+	"grave":          {294, 497, 484, 672},
+	"greater":        {85, 42, 599, 472},
+	"greaterequal":   {98, 0, 594, 710},
+	"guillemotleft":  {92, 70, 652, 446},
+	"guillemotright": {58, 70, 618, 446},
+	"guilsinglleft":  {204, 70, 540, 446},
+	"guilsinglright": {170, 70, 506, 446},
+	"h":              {33, 0, 592, 629},
+	"hungarumlaut":   {239, 497, 683, 672},
+	"hyphen":         {152, 231, 558, 285},
+	"i":              {95, 0, 515, 657},
+	"iacute":         {95, 0, 612, 672},
+	"icircumflex":    {95, 0, 551, 654},
+	"idieresis":      {95, 0, 545, 620},
+	"igrave":         {95, 0, 515, 672},
+	"imacron":        {95, 0, 543, 565},
+	"iogonek":        {95, -172, 515, 657},
+	"j":              {52, -157, 550, 657},
+	"k":              {58, 0, 633, 629},
+	"kcommaaccent":   {58, -250, 633, 629},
+	"l":              {95, 0, 515, 629},
+	"lacute":         {95, 0, 640, 805},
+	"lcaron":         {95, 0, 667, 629},
+	"lcommaaccent":   {95, -250, 515, 629},
+	"less":           {96, 42, 610, 472},
+	"lessequal":      {98, 0, 645, 710},
+	"logicalnot":     {155, 108, 591, 369},
+	"lozenge":        {94, 0, 519, 706},
+	"lslash":         {95, 0, 587, 629},
+	"m":              {-5, 0, 615, 441},
+	"macron":         {232, 525, 600, 565},
+	"minus":          {129, 232, 580, 283},
+	"mu":             {72, -157, 572, 426},
+	"multiply":       {103, 43, 607, 470},
+	"n":              {26, 0, 585, 441},
+	"nacute":         {26, 0, 602, 672},
+	"ncaron":         {26, 0, 614, 669},
+	"ncommaaccent":   {26, -250, 585, 441},
+	"nine":           {93, -15, 574, 622},
+	"notequal":       {43, -16, 621, 529},
+	"ntilde":         {26, 0, 629, 606},
+	"numbersign":     {133, -32, 596, 639},
+	"o":              {102, -15, 588, 441},
+	"oacute":         {102, -15, 612, 672},
+	"ocircumflex":    {102, -15, 588, 654},
+	"odieresis":      {102, -15, 588, 620},
+	"oe":             {54, -15, 615, 441},
+	"ogonek":         {189, -172, 377, 4},
+	"ograve":         {102, -15, 588, 672},
+	"ohungarumlaut":  {102, -15, 723, 672},
+	"omacron":        {102, -15, 600, 565},
+	"one":            {98, 0, 515, 622},
+	"onehalf":        {65, -57, 669, 665},
+	"onequarter":     {65, -57, 674, 665},
+	"onesuperior":    {231, 249, 491, 622},
+	"ordfeminine":    {209, 249, 512, 580},
+	"ordmasculine":   {210, 249, 535, 580},
+	"oslash":         {102, -80, 588, 506},
+	"otilde":         {102, -15, 629, 606},
+	"p":              {-24, -157, 605, 441},
+	"paragraph":      {100, -78, 630, 562},
+	"parenleft":      {313, -108, 572, 622},
+	"parenright":     {137, -108, 396, 622},
+	"partialdiff":    {45, -38, 546, 710},
+	"percent":        {134, -15, 599, 622},
+	"period":         {238, -15, 382, 109},
+	"periodcentered": {275, 189, 434, 327},
+	"perthousand":    {59, -15, 627, 622},
+	"plus":           {129, 44, 580, 470},
+	"plusminus":      {96, 44, 594, 558},
+	"q":              {85, -157, 682, 441},
+	"question":       {222, -15, 583, 572},
+	"questiondown":   {105, -157, 466, 430},
+	"quotedbl":       {273, 328, 532, 562},
+	"quotedblbase":   {115, -134, 478, 100},
+	"quotedblleft":   {262, 328, 541, 562},
+	"quotedblright":  {213, 328, 576, 562},
+	"quoteleft":      {343, 328, 457, 562},
+	"quoteright":     {283, 328, 495, 562},
+	"quotesinglbase": {185, -134, 397, 100},
+	"quotesingle":    {345, 328, 460, 562},
+	"r":              {60, 0, 636, 441},
+	"racute":         {60, 0, 636, 672},
+	"radical":        {85, -15, 765, 792},
+	"rcaron":         {60, 0, 636, 669},
+	"rcommaaccent":   {60, -250, 636, 441},
+	"registered":     {53, -18, 667, 580},
+	"ring":           {332, 463, 500, 627},
+	"s":              {78, -15, 584, 441},
+	"sacute":         {78, -15, 612, 672},
+	"scaron":         {78, -15, 614, 669},
+	"scedilla":       {78, -151, 584, 441},
+	"scommaaccent":   {78, -250, 584, 441},
+	"section":        {104, -78, 590, 580},
+	"semicolon":      {157, -112, 441, 385},
+	"seven":          {182, 0, 612, 607},
+	"six":            {155, -15, 629, 622},
+	"slash":          {112, -80, 604, 629},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {124, -21, 621, 611},
+	"summation":      {15, -10, 670, 706},
+	"t":              {167, -15, 561, 561},
+	"tcaron":         {167, -15, 587, 717},
+	"tcommaaccent":   {165, -250, 561, 561},
+	"thorn":          {-24, -157, 605, 629},
+	"three":          {82, -15, 538, 622},
+	"threequarters":  {73, -56, 659, 666},
+	"threesuperior":  {213, 240, 501, 622},
+	"tilde":          {212, 489, 629, 606},
+	"trademark":      {75, 263, 742, 562},
+	"two":            {70, 0, 568, 622},
+	"twosuperior":    {230, 249, 535, 622},
+	"u":              {101, -15, 572, 426},
+	"uacute":         {101, -15, 602, 672},
+	"ucircumflex":    {101, -15, 572, 654},
+	"udieresis":      {101, -15, 575, 620},
+	"ugrave":         {101, -15, 572, 672},
+	"uhungarumlaut":  {101, -15, 723, 672},
+	"umacron":        {101, -15, 600, 565},
+	"underscore":     {-27, -125, 584, -75},
+	"uogonek":        {101, -172, 572, 426},
+	"uring":          {101, -15, 572, 627},
+	"v":              {90, -10, 681, 426},
+	"w":              {76, -10, 695, 426},
+	"x":              {20, 0, 655, 426},
+	"y":              {-4, -157, 683, 426},
+	"yacute":         {-4, -157, 683, 672},
+	"ydieresis":      {-4, -157, 683, 620},
+	"yen":            {120, 0, 693, 562},
+	"z":              {99, 0, 593, 426},
+	"zacute":         {99, 0, 612, 672},
+	"zcaron":         {99, 0, 624, 669},
+	"zdotaccent":     {99, 0, 593, 620},
+	"zero":           {154, -15, 575, 622},
+}
+
+// Helvetica glyph bounding boxes loaded from afms/Helvetica.afm.  See afms/MustRead.html for license information.
+var helveticaGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {14, 0, 654, 718},
+	"AE":             {8, 0, 951, 718},
+	"Aacute":         {14, 0, 654, 929},
+	"Abreve":         {14, 0, 654, 926},
+	"Acircumflex":    {14, 0, 654, 929},
+	"Adieresis":      {14, 0, 654, 901},
+	"Agrave":         {14, 0, 654, 929},
+	"Amacron":        {14, 0, 654, 879},
+	"Aogonek":        {14, -225, 654, 718},
+	"Aring":          {14, 0, 654, 931},
+	"Atilde":         {14, 0, 654, 917},
+	"B":              {74, 0, 627, 718},
+	"C":              {44, -19, 681, 737},
+	"Cacute":         {44, -19, 681, 929},
+	"Ccaron":         {44, -19, 681, 929},
+	"Ccedilla":       {44, -225, 681, 737},
+	"D":              {81, 0, 674, 718},
+	"Dcaron":         {81, 0, 674, 929},
+	"Dcroat":         {0, 0, 674, 718},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {86, 0, 616, 718},
+	"Eacute":         {86, 0, 616, 929},
+	"Ecaron":         {86, 0, 616, 929},
+	"Ecircumflex":    {86, 0, 616, 929},
+	"Edieresis":      {86, 0, 616, 901},
+	"Edotaccent":     {86, 0, 616, 901},
+	"Egrave":         {86, 0, 616, 929},
+	"Emacron":        {86, 0, 616, 879},
+	"Eogonek":        {86, -220, 633, 718},
+	"Eth":            {0, 0, 674, 718},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {86, 0, 583, 718},
+	"G":              {48, -19, 704, 737},
+	"Gbreve":         {48, -19, 704, 926},
+	"Gcommaaccent":   {48, -225, 704, 737},
+	"H":              {77, 0, 646, 718},
+	"I":              {91, 0, 188, 718},
+	"Iacute":         {91, 0, 292, 929},
+	"Icircumflex":    {-6, 0, 285, 929},
+	"Idieresis":      {13, 0, 266, 901},
+	"Idotaccent":     {91, 0, 188, 901},
+	"Igrave":         {-13, 0, 188, 929},
+	"Imacron":        {-17, 0, 296, 879},
+	"Iogonek":        {-3, -225, 211, 718},
+	"J":              {17, -19, 428, 718},
+	"K":              {76, 0, 663, 718},
+	"Kcommaaccent":   {76, -225, 663, 718},
+	"L":              {76, 0, 537, 718},
+	"Lacute":         {76, 0, 537, 929},
+	"Lcaron":         {76, 0, 537, 718},
+	"Lcommaaccent":   {76, -225, 537, 718},
+	"Lslash":         {-20, 0, 537, 718},
+	"M":              {73, 0, 761, 718},
+	"N":              {76, 0, 646, 718},
+	"Nacute":         {76, 0, 646, 929},
+	"Ncaron":         {76, 0, 646, 929},
+	"Ncommaaccent":   {76, -225, 646, 718},
+	"Ntilde":         {76, 0, 646, 917},
+	"O":              {39, -19, 739, 737},
+	"OE":             {36, -19, 965, 737},
+	"Oacute":         {39, -19, 739, 929},
+	"Ocircumflex":    {39, -19, 739, 929},
+	"Odieresis":      {39, -19, 739, 901},
+	"Ograve":         {39, -19, 739, 929},
+	"Ohungarumlaut":  {39, -19, 739, 929},
+	"Omacron":        {39, -19, 739, 879},
+	"Oslash":         {39, -19, 740, 737},
+	"Otilde":         {39, -19, 739, 917},
+	"P":              {86, 0, 622, 718},
+	"Q":              {39, -56, 739, 737},
+	"R":              {88, 0, 684, 718},
+	"Racute":         {88, 0, 684, 929},
+	"Rcaron":         {88, 0, 684, 929},
+	"Rcommaaccent":   {88, -225, 684, 718},
+	"S":              {49, -19, 620, 737},
+	"Sacute":         {49, -19, 620, 929},
+	"Scaron":         {49, -19, 620, 929},
+	"Scedilla":       {49, -225, 620, 737},
+	"Scommaaccent":   {49, -225, 620, 737},
+	"T":              {14, 0, 597, 718},
+	"Tcaron":         {14, 0, 597, 929},
+	"Tcommaaccent":   {14, -225, 597, 718},
+	"Thorn":          {86, 0, 622, 718},
+	"U":              {79, -19, 644, 718},
+	"Uacute":         {79, -19, 644, 929},
+	"Ucircumflex":    {79, -19, 644, 929},
+	"Udieresis":      {79, -19, 644, 901},
+	"Ugrave":         {79, -19, 644, 929},
+	"Uhungarumlaut":  {79, -19, 644, 929},
+	"Umacron":        {79, -19, 644, 879},
+	"Uogonek":        {79, -225, 644, 718},
+	"Uring":          {79, -19, 644, 931},
+	"V":              {20, 0, 647, 718},
+	"W":              {16, 0, 928, 718},
+	"X":              {19, 0, 648, 718},
+	"Y":              {14, 0, 653, 718},
+	"Yacute":         {14, 0, 653, 929},
+	"Ydieresis":      {14, 0, 653, 901},
+	"Z":              {23, 0, 588, 718},
+	"Zacute":         {23, 0, 588, 929},
+	"Zcaron":         {23, 0, 588, 929},
+	"Zdotaccent":     {23, 0, 588, 901},
+	"a":              {36, -15, 530, 538},
+	"aacute":         {36, -15, 530, 734},
+	"abreve":         {36, -15, 530, 731},
+	"acircumflex":    {36, -15, 530, 734},
+	"acute":          {122, 593, 319, 734},
+	"adieresis":      {36, -15, 530, 706},
+	"ae":             {36, -15, 847, 538},
+	"agrave":         {36, -15, 530, 734},
+	"amacron":        {36, -15, 530, 684},
+	"ampersand":      {44, -15, 645, 718},
+	"aogonek":        {36, -220, 547, 538},
+	"aring":          {36, -15, 530, 756},
+	"asciicircum":    {-14, 264, 483, 688},
+	"asciitilde":     {61, 180, 523, 326},
+	"asterisk":       {39, 431, 349, 718},
+	"at":             {147, -19, 868, 737},
+	"atilde":         {36, -15, 530, 722},
+	"b":              {58, -15, 517, 718},
+	"backslash":      {-17, -19, 295, 737},
+	"bar":            {94, -225, 167, 775},
+	"braceleft":      {42, -196, 292, 722},
+	"braceright":     {42, -196, 292, 722},
+	"bracketleft":    {63, -196, 250, 722},
+	"bracketright":   {28, -196, 215, 722},
+	"breve":          {13, 595, 321, 731},
+	"brokenbar":      {94, -150, 167, 700},
+	"bullet":         {18, 202, 333, 517},
+	"c":              {30, -15, 477, 538},
+	"cacute":         {30, -15, 477, 734},
+	"caron":          {21, 593, 312, 734},
+	"ccaron":         {30, -15, 477, 734},
+	"ccedilla":       {30, -225, 477, 538},
+	"cedilla":        {45, -225, 259, 0},
+	"cent":           {51, -115, 513, 623},
+	"circumflex":     {21, 593, 312, 734},
+	"colon":          {87, 0, 191, 516},
+	"comma":          {87, -147, 191, 106},
+	"commaaccent":    {87, -225, 181, -40},
+	"copyright":      {-14, -19, 752, 737},
+	"currency":       {28, 99, 528, 603},
+	"d":              {35, -15, 499, 718},
+	"dagger":         {43, -159, 514, 718},
+	"daggerdbl":      {43, -159, 514, 718},
+	"dcaron":         {35, -15, 655, 718},
+	"dcroat":         {35, -15, 550, 718},
+	"degree":         {54, 411, 346, 703},
+	"dieresis":       {40, 604, 293, 706},
+	"divide":         {39, -19, 545, 524},
+	"dollar":         {32, -115, 520, 775},
+	"dotaccent":      {121, 604, 212, 706},
+	"dotlessi":       {95, 0, 183, 523},
+	"e":              {40, -15, 516, 538},
+	"eacute":         {40, -15, 516, 734},
+	"ecaron":         {40, -15, 516, 734},
+	"ecircumflex":    {40, -15, 516, 734},
+	"edieresis":      {40, -15, 516, 706},
+	"edotaccent":     {40, -15, 516, 706},
+	"egrave":         {40, -15, 516, 734},
+	"eight":          {38, -19, 517, 703},
+	"ellipsis":       {115, 0, 885, 106},
+	"emacron":        {40, -15, 516, 684},
+	"emdash":         {0, 240, 1000, 313},
+	"endash":         {0, 240, 556, 313},
+	"eogonek":        {40, -225, 516, 538},
+	"equal":          {39, 115, 545, 390},
+	"eth":            {35, -15, 522, 737},
+	"exclam":         {90, 0, 187, 718},
+	"exclamdown":     {118, -195, 215, 523},
+	"f":              {14, 0, 262, 728},
+	"fi":             {14, 0, 434, 728},
+	"five":           {32, -19, 514, 688},
+	"fl":             {14, 0, 432, 728},
+	"florin":         {-11, -207, 501, 737},
+	"four":           {25, 0, 523, 703},
+	"fraction":       {-166, -19, 333, 703},
+	"g":              {40, -220, 499, 538},
+	"gbreve":         {40, -220, 499, 731},
+	"gcommaaccent":   {40, -220, 499, 822},
+	"germandbls":     {67, -15, 571, 728},
+	"grave":          {14, 593, 211, 734},
+	"greater":        {48, 11, 536, 495},
+	"greaterequal":   {26, 0, 523, 674},
+	"guillemotleft":  {97, 108, 459, 446},
+	"guillemotright": {97, 108, 459, 446},
+	"guilsinglleft":  {88, 108, 245, 446},
+	"guilsinglright": {88, 108, 245, 446},
+	"h":              {65, 0, 491, 718},
+	"hungarumlaut":   {31, 593, 409, 734},
+	"hyphen":         {44, 232, 289, 322},
+	"i":              {67, 0, 155, 718},
+	"iacute":         {95, 0, 292, 734},
+	"icircumflex":    {-6, 0, 285, 734},
+	"idieresis":      {13, 0, 266, 706},
+	"igrave":         {-13, 0, 184, 734},
+	"imacron":        {5, 0, 272, 684},
+	"iogonek":        {-31, -225, 183, 718},
+	"j":              {-16, -210, 155, 718},
+	"k":              {67, 0, 501, 718},
+	"kcommaaccent":   {67, -225, 501, 718},
+	"l":              {67, 0, 155, 718},
+	"lacute":         {67, 0, 264, 929},
+	"lcaron":         {67, 0, 311, 718},
+	"lcommaaccent":   {67, -225, 167, 718},
+	"less":           {48, 11, 536, 495},
+	"lessequal":      {26, 0, 523, 674},
+	"logicalnot":     {39, 108, 545, 390},
+	"lozenge":        {10, 0, 462, 728},
+	"lslash":         {-20, 0, 242, 718},
+	"m":              {65, 0, 769, 538},
+	"macron":         {10, 627, 323, 684},
+	"minus":          {39, 216, 545, 289},
+	"mu":             {68, -207, 489, 523},
+	"multiply":       {39, 0, 545, 506},
+	"n":              {65, 0, 491, 538},
+	"nacute":         {65, 0, 491, 734},
+	"ncaron":         {65, 0, 491, 734},
+	"ncommaaccent":   {65, -225, 491, 538},
+	"nine":           {42, -19, 514, 703},
+	"notequal":       {12, -35, 537, 551},
+	"ntilde":         {65, 0, 491, 722},
+	"numbersign":     {28, 0, 529, 688},
+	"o":              {35, -14, 521, 538},
+	"oacute":         {35, -14, 521, 734},
+	"ocircumflex":    {35, -14, 521, 734},
+	"odieresis":      {35, -14, 521, 706},
+	"oe":             {35, -15, 902, 538},
+	"ogonek":         {73, -225, 287, 0},
+	"ograve":         {35, -14, 521, 734},
+	"ohungarumlaut":  {35, -14, 521, 734},
+	"omacron":        {35, -14, 521, 684},
+	"one":            {101, 0, 359, 703},
+	"onehalf":        {43, -19, 773, 703},
+	"onequarter":     {73, -19, 756, 703},
+	"onesuperior":    {43, 281, 222, 703},
+	"ordfeminine":    {24, 405, 346, 737},
+	"ordmasculine":   {25, 405, 341, 737},
+	"oslash":         {28, -22, 537, 545},
+	"otilde":         {35, -14, 521, 722},
+	"p":              {58, -207, 517, 538},
+	"paragraph":      {18, -173, 497, 718},
+	"parenleft":      {68, -207, 299, 733},
+	"parenright":     {34, -207, 265, 733},
+	"partialdiff":    {13, -38, 463, 714},
+	"percent":        {39, -19, 850, 703},
+	"period":         {87, 0, 191, 106},
+	"periodcentered": {77, 190, 202, 315},
+	"perthousand":    {7, -19, 994, 703},
+	"plus":           {39, 0, 545, 505},
+	"plusminus":      {39, 0, 545, 506},
+	"q":              {35, -207, 494, 538},
+	"question":       {56, 0, 492, 727},
+	"questiondown":   {91, -201, 527, 525},
+	"quotedbl":       {70, 463, 285, 718},
+	"quotedblbase":   {26, -149, 295, 106},
+	"quotedblleft":   {38, 470, 307, 725},
+	"quotedblright":  {26, 463, 295, 718},
+	"quoteleft":      {65, 470, 169, 725},
+	"quoteright":     {53, 463, 157, 718},
+	"quotesinglbase": {53, -149, 157, 106},
+	"quotesingle":    {59, 463, 132, 718},
+	"r":              {77, 0, 332, 538},
+	"racute":         {77, 0, 332, 734},
+	"radical":        {-4, -80, 458, 762},
+	"rcaron":         {61, 0, 352, 734},
+	"rcommaaccent":   {77, -225, 332, 538},
+	"registered":     {-14, -19, 752, 737},
+	"ring":           {75, 572, 259, 756},
+	"s":              {32, -15, 464, 538},
+	"sacute":         {32, -15, 464, 734},
+	"scaron":         {32, -15, 464, 734},
+	"scedilla":       {32, -225, 464, 538},
+	"scommaaccent":   {32, -225, 464, 538},
+	"section":        {43, -191, 512, 737},
+	"semicolon":      {87, -147, 191, 516},
+	"seven":          {37, 0, 523, 688},
+	"six":            {38, -19, 518, 703},
+	"slash":          {-17, -19, 295, 737},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {33, -16, 539, 718},
+	"summation":      {15, -10, 586, 706},
+	"t":              {14, -7, 257, 669},
+	"tcaron":         {14, -7, 329, 808},
+	"tcommaaccent":   {14, -225, 257, 669},
+	"thorn":          {58, -207, 517, 718},
+	"three":          {34, -19, 522, 703},
+	"threequarters":  {45, -19, 810, 703},
+	"threesuperior":  {5, 270, 325, 703},
+	"tilde":          {-4, 606, 337, 722},
+	"trademark":      {46, 306, 903, 718},
+	"two":            {26, 0, 507, 703},
+	"twosuperior":    {4, 281, 323, 703},
+	"u":              {68, -15, 489, 523},
+	"uacute":         {68, -15, 489, 734},
+	"ucircumflex":    {68, -15, 489, 734},
+	"udieresis":      {68, -15, 489, 706},
+	"ugrave":         {68, -15, 489, 734},
+	"uhungarumlaut":  {68, -15, 521, 734},
+	"umacron":        {68, -15, 489, 684},
+	"underscore":     {0, -125, 556, -75},
+	"uogonek":        {68, -225, 519, 523},
+	"uring":          {68, -15, 489, 756},
+	"v":              {8, 0, 492, 523},
+	"w":              {14, 0, 709, 523},
+	"x":              {11, 0, 490, 523},
+	"y":              {11, -214, 489, 523},
+	"yacute":         {11, -214, 489, 734},
+	"ydieresis":      {11, -214, 489, 706},
+	"yen":            {3, 0, 553, 688},
+	"z":              {31, 0, 469, 523},
+	"zacute":         {31, 0, 469, 734},
+	"zcaron":         {31, 0, 469, 734},
+	"zdotaccent":     {31, 0, 469, 706},
+	"zero":           {37, -19, 519, 703},
+}
+
+// Helvetica-Bold glyph bounding boxes loaded from afms/Helvetica-Bold.afm.  See afms/MustRead.html for license information.
+var helveticaBoldGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {20, 0, 702, 718},
+	"AE":             {5, 0, 954, 718},
+	"Aacute":         {20, 0, 702, 936},
+	"Abreve":         {20, 0, 702, 936},
+	"Acircumflex":    {20, 0, 702, 936},
+	"Adieresis":      {20, 0, 702, 915},
+	"Agrave":         {20, 0, 702, 936},
+	"Amacron":        {20, 0, 702, 864},
+	"Aogonek":        {20, -224, 742, 718},
+	"Aring":          {20, 0, 702, 962},
+	"Atilde":         {20, 0, 702, 923},
+	"B":              {76, 0, 669, 718},
+	"C":              {44, -19, 684, 737},
+	"Cacute":         {44, -19, 684, 936},
+	"Ccaron":         {44, -19, 684, 936},
+	"Ccedilla":       {44, -228, 684, 737},
+	"D":              {76, 0, 685, 718},
+	"Dcaron":         {76, 0, 685, 936},
+	"Dcroat":         {-5, 0, 685, 718},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {76, 0, 621, 718},
+	"Eacute":         {76, 0, 621, 936},
+	"Ecaron":         {76, 0, 621, 936},
+	"Ecircumflex":    {76, 0, 621, 936},
+	"Edieresis":      {76, 0, 621, 915},
+	"Edotaccent":     {76, 0, 621, 915},
+	"Egrave":         {76, 0, 621, 936},
+	"Emacron":        {76, 0, 621, 864},
+	"Eogonek":        {76, -224, 639, 718},
+	"Eth":            {-5, 0, 685, 718},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {76, 0, 587, 718},
+	"G":              {44, -19, 713, 737},
+	"Gbreve":         {44, -19, 713, 936},
+	"Gcommaaccent":   {44, -228, 713, 737},
+	"H":              {71, 0, 651, 718},
+	"I":              {64, 0, 214, 718},
+	"Iacute":         {64, 0, 329, 936},
+	"Icircumflex":    {-37, 0, 316, 936},
+	"Idieresis":      {-21, 0, 300, 915},
+	"Idotaccent":     {64, 0, 214, 915},
+	"Igrave":         {-50, 0, 214, 936},
+	"Imacron":        {-33, 0, 312, 864},
+	"Iogonek":        {-11, -228, 222, 718},
+	"J":              {22, -18, 484, 718},
+	"K":              {87, 0, 722, 718},
+	"Kcommaaccent":   {87, -228, 722, 718},
+	"L":              {76, 0, 583, 718},
+	"Lacute":         {76, 0, 583, 936},
+	"Lcaron":         {76, 0, 583, 718},
+	"Lcommaaccent":   {76, -228, 583, 718},
+	"Lslash":         {-20, 0, 583, 718},
+	"M":              {69, 0, 765, 718},
+	"N":              {69, 0, 654, 718},
+	"Nacute":         {69, 0, 654, 936},
+	"Ncaron":         {69, 0, 654, 936},
+	"Ncommaaccent":   {69, -228, 654, 718},
+	"Ntilde":         {69, 0, 654, 923},
+	"O":              {44, -19, 734, 737},
+	"OE":             {37, -19, 961, 737},
+	"Oacute":         {44, -19, 734, 936},
+	"Ocircumflex":    {44, -19, 734, 936},
+	"Odieresis":      {44, -19, 734, 915},
+	"Ograve":         {44, -19, 734, 936},
+	"Ohungarumlaut":  {44, -19, 734, 936},
+	"Omacron":        {44, -19, 734, 864},
+	"Oslash":         {33, -27, 744, 745},
+	"Otilde":         {44, -19, 734, 923},
+	"P":              {76, 0, 627, 718},
+	"Q":              {44, -52, 737, 737},
+	"R":              {76, 0, 677, 718},
+	"Racute":         {76, 0, 677, 936},
+	"Rcaron":         {76, 0, 677, 936},
+	"Rcommaaccent":   {76, -228, 677, 718},
+	"S":              {39, -19, 629, 737},
+	"Sacute":         {39, -19, 629, 936},
+	"Scaron":         {39, -19, 629, 936},
+	"Scedilla":       {39, -228, 629, 737},
+	"Scommaaccent":   {39, -228, 629, 737},
+	"T":              {14, 0, 598, 718},
+	"Tcaron":         {14, 0, 598, 936},
+	"Tcommaaccent":   {14, -228, 598, 718},
+	"Thorn":          {76, 0, 627, 718},
+	"U":              {72, -19, 651, 718},
+	"Uacute":         {72, -19, 651, 936},
+	"Ucircumflex":    {72, -19, 651, 936},
+	"Udieresis":      {72, -19, 651, 915},
+	"Ugrave":         {72, -19, 651, 936},
+	"Uhungarumlaut":  {72, -19, 681, 936},
+	"Umacron":        {72, -19, 651, 864},
+	"Uogonek":        {72, -228, 651, 718},
+	"Uring":          {72, -19, 651, 962},
+	"V":              {19, 0, 648, 718},
+	"W":              {16, 0, 929, 718},
+	"X":              {14, 0, 653, 718},
+	"Y":              {15, 0, 653, 718},
+	"Yacute":         {15, 0, 653, 936},
+	"Ydieresis":      {15, 0, 653, 915},
+	"Z":              {25, 0, 586, 718},
+	"Zacute":         {25, 0, 586, 936},
+	"Zcaron":         {25, 0, 586, 936},
+	"Zdotaccent":     {25, 0, 586, 915},
+	"a":              {29, -14, 527, 546},
+	"aacute":         {29, -14, 527, 750},
+	"abreve":         {29, -14, 527, 750},
+	"acircumflex":    {29, -14, 527, 750},
+	"acute":          {108, 604, 356, 750},
+	"adieresis":      {29, -14, 527, 729},
+	"ae":             {29, -14, 858, 546},
+	"agrave":         {29, -14, 527, 750},
+	"amacron":        {29, -14, 527, 678},
+	"ampersand":      {54, -19, 701, 718},
+	"aogonek":        {29, -224, 545, 546},
+	"aring":          {29, -14, 527, 776},
+	"asciicircum":    {62, 323, 522, 698},
+	"asciitilde":     {61, 163, 523, 343},
+	"asterisk":       {27, 387, 362, 718},
+	"at":             {118, -19, 856, 737},
+	"atilde":         {29, -14, 527, 737},
+	"b":              {61, -14, 578, 718},
+	"backslash":      {-33, -19, 311, 737},
+	"bar":            {84, -225, 196, 775},
+	"braceleft":      {48, -196, 365, 722},
+	"braceright":     {24, -196, 341, 722},
+	"bracketleft":    {63, -196, 309, 722},
+	"bracketright":   {24, -196, 270, 722},
+	"breve":          {-2, 604, 335, 750},
+	"brokenbar":      {84, -150, 196, 700},
+	"bullet":         {10, 194, 340, 524},
+	"c":              {34, -14, 524, 546},
+	"cacute":         {34, -14, 524, 750},
+	"caron":          {-10, 604, 343, 750},
+	"ccaron":         {34, -14, 524, 750},
+	"ccedilla":       {34, -228, 524, 546},
+	"cedilla":        {6, -228, 245, 0},
+	"cent":           {34, -118, 524, 628},
+	"circumflex":     {-10, 604, 343, 750},
+	"colon":          {92, 0, 242, 512},
+	"comma":          {64, -168, 214, 146},
+	"commaaccent":    {64, -228, 199, -50},
+	"copyright":      {-11, -19, 749, 737},
+	"currency":       {-3, 76, 559, 636},
+	"d":              {34, -14, 551, 718},
+	"dagger":         {36, -171, 520, 718},
+	"daggerdbl":      {36, -171, 520, 718},
+	"dcaron":         {34, -14, 750, 718},
+	"dcroat":         {34, -14, 650, 718},
+	"degree":         {57, 426, 343, 712},
+	"dieresis":       {6, 614, 327, 729},
+	"divide":         {40, -42, 544, 548},
+	"dollar":         {30, -115, 523, 775},
+	"dotaccent":      {104, 614, 230, 729},
+	"dotlessi":       {69, 0, 209, 532},
+	"e":              {23, -14, 528, 546},
+	"eacute":         {23, -14, 528, 750},
+	"ecaron":         {23, -14, 528, 750},
+	"ecircumflex":    {23, -14, 528, 750},
+	"edieresis":      {23, -14, 528, 729},
+	"edotaccent":     {23, -14, 528, 729},
+	"egrave":         {23, -14, 528, 750},
+	"eight":          {32, -19, 524, 710},
+	"ellipsis":       {92, 0, 908, 146},
+	"emacron":        {23, -14, 528, 678},
+	"emdash":         {0, 227, 1000, 333},
+	"endash":         {0, 227, 556, 333},
+	"eogonek":        {23, -228, 528, 546},
+	"equal":          {40, 87, 544, 419},
+	"eth":            {34, -14, 578, 737},
+	"exclam":         {90, 0, 244, 718},
+	"exclamdown":     {90, -186, 244, 532},
+	"f":              {10, 0, 318, 727},
+	"fi":             {10, 0, 542, 727},
+	"five":           {27, -19, 516, 698},
+	"fl":             {10, 0, 542, 727},
+	"florin":         {-10, -210, 516, 737},
+	"four":           {27, 0, 526, 710},
+	"fraction":       {-170, -19, 336, 710},
+	"g":              {40, -217, 553, 546},
+	"gbreve":         {40, -217, 553, 750},
+	"gcommaaccent":   {40, -217, 553, 850},
+	"germandbls":     {69, -14, 579, 731},
+	"grave":          {-23, 604, 225, 750},
+	"greater":        {38, -8, 546, 514},
+	"greaterequal":   {26, 0, 523, 704},
+	"guillemotleft":  {88, 76, 468, 484},
+	"guillemotright": {88, 76, 468, 484},
+	"guilsinglleft":  {83, 76, 250, 484},
+	"guilsinglright": {83, 76, 250, 484},
+	"h":              {65, 0, 546, 718},
+	"hungarumlaut":   {9, 604, 486, 750},
+	"hyphen":         {27, 215, 306, 345},
+	"i":              {69, 0, 209, 725},
+	"iacute":         {69, 0, 329, 750},
+	"icircumflex":    {-37, 0, 316, 750},
+	"idieresis":      {-21, 0, 300, 729},
+	"igrave":         {-50, 0, 209, 750},
+	"imacron":        {-8, 0, 285, 678},
+	"iogonek":        {16, -224, 249, 725},
+	"j":              {3, -214, 209, 725},
+	"k":              {69, 0, 562, 718},
+	"kcommaaccent":   {69, -228, 562, 718},
+	"l":              {69, 0, 209, 718},
+	"lacute":         {69, 0, 329, 936},
+	"lcaron":         {69, 0, 408, 718},
+	"lcommaaccent":   {69, -228, 213, 718},
+	"less":           {38, -8, 546, 514},
+	"lessequal":      {29, 0, 526, 704},
+	"logicalnot":     {40, 108, 544, 419},
+	"lozenge":        {10, 0, 484, 745},
+	"lslash":         {-18, 0, 296, 718},
+	"m":              {64, 0, 826, 546},
+	"macron":         {-6, 604, 339, 678},
+	"minus":          {40, 197, 544, 309},
+	"mu":             {66, -207, 545, 532},
+	"multiply":       {40, 1, 545, 505},
+	"n":              {65, 0, 546, 546},
+	"nacute":         {65, 0, 546, 750},
+	"ncaron":         {65, 0, 546, 750},
+	"ncommaaccent":   {65, -228, 546, 546},
+	"nine":           {30, -19, 522, 710},
+	"notequal":       {15, -49, 540, 570},
+	"ntilde":         {65, 0, 546, 737},
+	"numbersign":     {18, 0, 538, 698},
+	"o":              {34, -14, 578, 546},
+	"oacute":         {34, -14, 578, 750},
+	"ocircumflex":    {34, -14, 578, 750},
+	"odieresis":      {34, -14, 578, 729},
+	"oe":             {34, -14, 912, 546},
+	"ogonek":         {71, -228, 304, 0},
+	"ograve":         {34, -14, 578, 750},
+	"ohungarumlaut":  {34, -14, 625, 750},
+	"omacron":        {34, -14, 578, 678},
+	"one":            {69, 0, 378, 710},
+	"onehalf":        {26, -19, 794, 710},
+	"onequarter":     {26, -19, 766, 710},
+	"onesuperior":    {26, 283, 237, 710},
+	"ordfeminine":    {22, 401, 347, 737},
+	"ordmasculine":   {6, 401, 360, 737},
+	"oslash":         {22, -29, 589, 560},
+	"otilde":         {34, -14, 578, 737},
+	"p":              {62, -207, 578, 546},
+	"paragraph":      {-8, -191, 539, 700},
+	"parenleft":      {35, -208, 314, 734},
+	"parenright":     {19, -208, 298, 734},
+	"partialdiff":    {11, -21, 494, 750},
+	"percent":        {28, -19, 861, 710},
+	"period":         {64, 0, 214, 146},
+	"periodcentered": {58, 172, 220, 334},
+	"perthousand":    {-3, -19, 1003, 710},
+	"plus":           {40, 0, 544, 506},
+	"plusminus":      {40, 0, 544, 506},
+	"q":              {34, -207, 552, 546},
+	"question":       {60, 0, 556, 727},
+	"questiondown":   {55, -195, 551, 532},
+	"quotedbl":       {98, 447, 376, 718},
+	"quotedblbase":   {64, -146, 436, 127},
+	"quotedblleft":   {64, 454, 436, 727},
+	"quotedblright":  {64, 445, 436, 718},
+	"quoteleft":      {69, 454, 209, 727},
+	"quoteright":     {69, 445, 209, 718},
+	"quotesinglbase": {69, -146, 209, 127},
+	"quotesingle":    {70, 447, 168, 718},
+	"r":              {64, 0, 373, 546},
+	"racute":         {64, 0, 384, 750},
+	"radical":        {10, -46, 512, 850},
+	"rcaron":         {18, 0, 373, 750},
+	"rcommaaccent":   {64, -228, 373, 546},
+	"registered":     {-11, -19, 748, 737},
+	"ring":           {59, 568, 275, 776},
+	"s":              {30, -14, 519, 546},
+	"sacute":         {30, -14, 519, 750},
+	"scaron":         {30, -14, 519, 750},
+	"scedilla":       {30, -228, 519, 546},
+	"scommaaccent":   {30, -228, 519, 546},
+	"section":        {34, -184, 522, 727},
+	"semicolon":      {92, -168, 242, 512},
+	"seven":          {25, 0, 528, 698},
+	"six":            {31, -19, 520, 710},
+	"slash":          {-33, -19, 311, 737},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {28, -16, 541, 718},
+	"summation":      {14, -10, 585, 706},
+	"t":              {10, -6, 309, 676},
+	"tcaron":         {10, -6, 421, 878},
+	"tcommaaccent":   {10, -228, 309, 676},
+	"thorn":          {62, -208, 578, 718},
+	"three":          {27, -19, 516, 710},
+	"threequarters":  {16, -19, 799, 710},
+	"threesuperior":  {8, 271, 326, 710},
+	"tilde":          {-17, 610, 350, 737},
+	"trademark":      {44, 306, 956, 718},
+	"two":            {26, 0, 511, 710},
+	"twosuperior":    {9, 283, 324, 710},
+	"u":              {66, -14, 545, 532},
+	"uacute":         {66, -14, 545, 750},
+	"ucircumflex":    {66, -14, 545, 750},
+	"udieresis":      {66, -14, 545, 729},
+	"ugrave":         {66, -14, 545, 750},
+	"uhungarumlaut":  {66, -14, 625, 750},
+	"umacron":        {66, -14, 545, 678},
+	"underscore":     {0, -125, 556, -75},
+	"uogonek":        {66, -228, 545, 532},
+	"uring":          {66, -14, 545, 776},
+	"v":              {13, 0, 543, 532},
+	"w":              {10, 0, 769, 532},
+	"x":              {15, 0, 541, 532},
+	"y":              {10, -214, 539, 532},
+	"yacute":         {10, -214, 539, 750},
+	"ydieresis":      {10, -214, 539, 729},
+	"yen":            {-9, 0, 565, 698},
+	"z":              {20, 0, 480, 532},
+	"zacute":         {20, 0, 480, 750},
+	"zcaron":         {20, 0, 480, 750},
+	"zdotaccent":     {20, 0, 480, 729},
+	"zero":           {32, -19, 524, 710},
+}
+
+// Helvetica-BoldOblique glyph bounding boxes loaded from afms/Helvetica-BoldOblique.afm.  See afms/MustRead.html for license information.
+var helveticaBoldObliqueGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {20, 0, 702, 718},
+	"AE":             {5, 0, 1100, 718},
+	"Aacute":         {20, 0, 750, 936},
+	"Abreve":         {20, 0, 729, 936},
+	"Acircumflex":    {20, 0, 706, 936},
+	"Adieresis":      {20, 0, 716, 915},
+	"Agrave":         {20, 0, 702, 936},
+	"Amacron":        {20, 0, 718, 864},
+	"Aogonek":        {20, -224, 702, 718},
+	"Aring":          {20, 0, 702, 962},
+	"Atilde":         {20, 0, 741, 923},
+	"B":              {76, 0, 764, 718},
+	"C":              {107, -19, 789, 737},
+	"Cacute":         {107, -19, 789, 936},
+	"Ccaron":         {107, -19, 789, 936},
+	"Ccedilla":       {107, -228, 789, 737},
+	"D":              {76, 0, 777, 718},
+	"Dcaron":         {76, 0, 777, 936},
+	"Dcroat":         {62, 0, 777, 718},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {76, 0, 757, 718},
+	"Eacute":         {76, 0, 757, 936},
+	"Ecaron":         {76, 0, 757, 936},
+	"Ecircumflex":    {76, 0, 757, 936},
+	"Edieresis":      {76, 0, 757, 915},
+	"Edotaccent":     {76, 0, 757, 915},
+	"Egrave":         {76, 0, 757, 936},
+	"Emacron":        {76, 0, 757, 864},
+	"Eogonek":        {76, -224, 757, 718},
+	"Eth":            {62, 0, 777, 718},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {76, 0, 740, 718},
+	"G":              {108, -19, 817, 737},
+	"Gbreve":         {108, -19, 817, 936},
+	"Gcommaaccent":   {108, -228, 817, 737},
+	"H":              {71, 0, 804, 718},
+	"I":              {64, 0, 367, 718},
+	"Iacute":         {64, 0, 528, 936},
+	"Icircumflex":    {64, 0, 484, 936},
+	"Idieresis":      {64, 0, 494, 915},
+	"Idotaccent":     {64, 0, 397, 915},
+	"Igrave":         {64, 0, 367, 936},
+	"Imacron":        {64, 0, 496, 864},
+	"Iogonek":        {-41, -228, 367, 718},
+	"J":              {60, -18, 637, 718},
+	"K":              {87, 0, 858, 718},
+	"Kcommaaccent":   {87, -228, 858, 718},
+	"L":              {76, 0, 611, 718},
+	"Lacute":         {76, 0, 611, 936},
+	"Lcaron":         {76, 0, 643, 718},
+	"Lcommaaccent":   {76, -228, 611, 718},
+	"Lslash":         {34, 0, 611, 718},
+	"M":              {69, 0, 918, 718},
+	"N":              {69, 0, 807, 718},
+	"Nacute":         {69, 0, 807, 936},
+	"Ncaron":         {69, 0, 807, 936},
+	"Ncommaaccent":   {69, -228, 807, 718},
+	"Ntilde":         {69, 0, 807, 923},
+	"O":              {107, -19, 823, 737},
+	"OE":             {99, -19, 1114, 737},
+	"Oacute":         {107, -19, 823, 936},
+	"Ocircumflex":    {107, -19, 823, 936},
+	"Odieresis":      {107, -19, 823, 915},
+	"Ograve":         {107, -19, 823, 936},
+	"Ohungarumlaut":  {107, -19, 908, 936},
+	"Omacron":        {107, -19, 823, 864},
+	"Oslash":         {35, -27, 894, 745},
+	"Otilde":         {107, -19, 823, 923},
+	"P":              {76, 0, 738, 718},
+	"Q":              {107, -52, 823, 737},
+	"R":              {76, 0, 778, 718},
+	"Racute":         {76, 0, 778, 936},
+	"Rcaron":         {76, 0, 778, 936},
+	"Rcommaaccent":   {76, -228, 778, 718},
+	"S":              {81, -19, 718, 737},
+	"Sacute":         {81, -19, 722, 936},
+	"Scaron":         {81, -19, 718, 936},
+	"Scedilla":       {81, -228, 718, 737},
+	"Scommaaccent":   {81, -228, 718, 737},
+	"T":              {140, 0, 751, 718},
+	"Tcaron":         {140, 0, 751, 936},
+	"Tcommaaccent":   {140, -228, 751, 718},
+	"Thorn":          {76, 0, 716, 718},
+	"U":              {116, -19, 804, 718},
+	"Uacute":         {116, -19, 804, 936},
+	"Ucircumflex":    {116, -19, 804, 936},
+	"Udieresis":      {116, -19, 804, 915},
+	"Ugrave":         {116, -19, 804, 936},
+	"Uhungarumlaut":  {116, -19, 880, 936},
+	"Umacron":        {116, -19, 804, 864},
+	"Uogonek":        {116, -228, 804, 718},
+	"Uring":          {116, -19, 804, 962},
+	"V":              {172, 0, 801, 718},
+	"W":              {169, 0, 1082, 718},
+	"X":              {14, 0, 791, 718},
+	"Y":              {168, 0, 806, 718},
+	"Yacute":         {168, 0, 806, 936},
+	"Ydieresis":      {168, 0, 806, 915},
+	"Z":              {25, 0, 737, 718},
+	"Zacute":         {25, 0, 737, 936},
+	"Zcaron":         {25, 0, 737, 936},
+	"Zdotaccent":     {25, 0, 737, 915},
+	"a":              {55, -14, 583, 546},
+	"aacute":         {55, -14, 627, 750},
+	"abreve":         {55, -14, 606, 750},
+	"acircumflex":    {55, -14, 583, 750},
+	"acute":          {236, 604, 515, 750},
+	"adieresis":      {55, -14, 594, 729},
+	"ae":             {56, -14, 923, 546},
+	"agrave":         {55, -14, 583, 750},
+	"amacron":        {55, -14, 595, 678},
+	"ampersand":      {89, -19, 732, 718},
+	"aogonek":        {55, -224, 583, 546},
+	"aring":          {55, -14, 583, 776},
+	"asciicircum":    {131, 323, 591, 698},
+	"asciitilde":     {115, 163, 577, 343},
+	"asterisk":       {146, 387, 481, 718},
+	"at":             {186, -19, 954, 737},
+	"atilde":         {55, -14, 619, 737},
+	"b":              {61, -14, 645, 718},
+	"backslash":      {124, -19, 307, 737},
+	"bar":            {36, -225, 361, 775},
+	"braceleft":      {94, -196, 518, 722},
+	"braceright":     {-18, -196, 407, 722},
+	"bracketleft":    {21, -196, 462, 722},
+	"bracketright":   {-18, -196, 423, 722},
+	"breve":          {156, 604, 494, 750},
+	"brokenbar":      {52, -150, 345, 700},
+	"bullet":         {83, 194, 420, 524},
+	"c":              {79, -14, 599, 546},
+	"cacute":         {79, -14, 627, 750},
+	"caron":          {149, 604, 502, 750},
+	"ccaron":         {79, -14, 614, 750},
+	"ccedilla":       {79, -228, 599, 546},
+	"cedilla":        {-37, -228, 220, 0},
+	"cent":           {79, -118, 599, 628},
+	"circumflex":     {118, 604, 471, 750},
+	"colon":          {92, 0, 351, 512},
+	"comma":          {28, -168, 245, 146},
+	"commaaccent":    {16, -228, 188, -50},
+	"copyright":      {56, -19, 835, 737},
+	"currency":       {27, 76, 680, 636},
+	"d":              {82, -14, 704, 718},
+	"dagger":         {118, -171, 626, 718},
+	"daggerdbl":      {46, -171, 628, 718},
+	"dcaron":         {82, -14, 903, 718},
+	"dcroat":         {82, -14, 789, 718},
+	"degree":         {175, 426, 467, 712},
+	"dieresis":       {137, 614, 482, 729},
+	"divide":         {82, -42, 610, 548},
+	"dollar":         {67, -115, 622, 775},
+	"dotaccent":      {235, 614, 385, 729},
+	"dotlessi":       {69, 0, 322, 532},
+	"e":              {70, -14, 593, 546},
+	"eacute":         {70, -14, 627, 750},
+	"ecaron":         {70, -14, 614, 750},
+	"ecircumflex":    {70, -14, 593, 750},
+	"edieresis":      {70, -14, 594, 729},
+	"edotaccent":     {70, -14, 593, 729},
+	"egrave":         {70, -14, 593, 750},
+	"eight":          {69, -19, 616, 710},
+	"ellipsis":       {92, 0, 939, 146},
+	"emacron":        {70, -14, 595, 678},
+	"emdash":         {48, 227, 1071, 333},
+	"endash":         {48, 227, 627, 333},
+	"eogonek":        {70, -228, 593, 546},
+	"equal":          {58, 87, 633, 419},
+	"eth":            {82, -14, 670, 737},
+	"exclam":         {94, 0, 397, 718},
+	"exclamdown":     {50, -186, 353, 532},
+	"f":              {87, 0, 469, 727},
+	"fi":             {87, 0, 696, 727},
+	"five":           {64, -19, 636, 698},
+	"fl":             {87, 0, 695, 727},
+	"florin":         {-50, -210, 669, 737},
+	"four":           {60, 0, 598, 710},
+	"fraction":       {-174, -19, 487, 710},
+	"g":              {38, -217, 666, 546},
+	"gbreve":         {38, -217, 666, 750},
+	"gcommaaccent":   {38, -217, 666, 850},
+	"germandbls":     {69, -14, 657, 731},
+	"grave":          {136, 604, 353, 750},
+	"greater":        {36, -8, 609, 514},
+	"greaterequal":   {26, 0, 629, 704},
+	"guillemotleft":  {135, 76, 571, 484},
+	"guillemotright": {104, 76, 540, 484},
+	"guilsinglleft":  {130, 76, 353, 484},
+	"guilsinglright": {99, 76, 322, 484},
+	"h":              {65, 0, 629, 718},
+	"hungarumlaut":   {137, 604, 645, 750},
+	"hyphen":         {73, 215, 379, 345},
+	"i":              {69, 0, 363, 725},
+	"iacute":         {69, 0, 488, 750},
+	"icircumflex":    {69, 0, 444, 750},
+	"idieresis":      {69, 0, 455, 729},
+	"igrave":         {69, 0, 326, 750},
+	"imacron":        {69, 0, 429, 678},
+	"iogonek":        {-14, -224, 363, 725},
+	"j":              {-42, -214, 363, 725},
+	"k":              {69, 0, 670, 718},
+	"kcommaaccent":   {69, -228, 670, 718},
+	"l":              {69, 0, 362, 718},
+	"lacute":         {69, 0, 528, 936},
+	"lcaron":         {69, 0, 561, 718},
+	"lcommaaccent":   {30, -228, 362, 718},
+	"less":           {82, -8, 655, 514},
+	"lessequal":      {29, 0, 676, 704},
+	"logicalnot":     {105, 108, 633, 419},
+	"lozenge":        {90, 0, 564, 745},
+	"lslash":         {40, 0, 407, 718},
+	"m":              {64, 0, 909, 546},
+	"macron":         {122, 604, 483, 678},
+	"minus":          {82, 197, 610, 309},
+	"mu":             {22, -207, 658, 532},
+	"multiply":       {57, 1, 635, 505},
+	"n":              {65, 0, 629, 546},
+	"nacute":         {65, 0, 654, 750},
+	"ncaron":         {65, 0, 641, 750},
+	"ncommaaccent":   {65, -228, 629, 546},
+	"nine":           {78, -19, 615, 710},
+	"notequal":       {32, -49, 630, 570},
+	"ntilde":         {65, 0, 646, 737},
+	"numbersign":     {60, 0, 644, 698},
+	"o":              {82, -14, 643, 546},
+	"oacute":         {82, -14, 654, 750},
+	"ocircumflex":    {82, -14, 643, 750},
+	"odieresis":      {82, -14, 643, 729},
+	"oe":             {82, -14, 977, 546},
+	"ogonek":         {41, -228, 264, 0},
+	"ograve":         {82, -14, 643, 750},
+	"ohungarumlaut":  {82, -14, 784, 750},
+	"omacron":        {82, -14, 643, 678},
+	"one":            {173, 0, 529, 710},
+	"onehalf":        {132, -19, 858, 710},
+	"onequarter":     {132, -19, 806, 710},
+	"onesuperior":    {148, 283, 388, 710},
+	"ordfeminine":    {125, 401, 465, 737},
+	"ordmasculine":   {123, 401, 485, 737},
+	"oslash":         {22, -29, 701, 560},
+	"otilde":         {82, -14, 646, 737},
+	"p":              {18, -207, 645, 546},
+	"paragraph":      {98, -191, 688, 700},
+	"parenleft":      {76, -208, 470, 734},
+	"parenright":     {-25, -208, 369, 734},
+	"partialdiff":    {43, -21, 585, 750},
+	"percent":        {136, -19, 901, 710},
+	"period":         {64, 0, 245, 146},
+	"periodcentered": {110, 172, 276, 334},
+	"perthousand":    {76, -19, 1038, 710},
+	"plus":           {82, 0, 610, 506},
+	"plusminus":      {40, 0, 625, 506},
+	"q":              {80, -207, 665, 546},
+	"question":       {165, 0, 671, 727},
+	"questiondown":   {53, -195, 559, 532},
+	"quotedbl":       {193, 447, 529, 718},
+	"quotedblbase":   {36, -146, 463, 127},
+	"quotedblleft":   {160, 454, 588, 727},
+	"quotedblright":  {162, 445, 589, 718},
+	"quoteleft":      {165, 454, 361, 727},
+	"quoteright":     {167, 445, 362, 718},
+	"quotesinglbase": {41, -146, 236, 127},
+	"quotesingle":    {165, 447, 321, 718},
+	"r":              {64, 0, 489, 546},
+	"racute":         {64, 0, 543, 750},
+	"radical":        {112, -46, 689, 850},
+	"rcaron":         {64, 0, 530, 750},
+	"rcommaaccent":   {26, -228, 489, 546},
+	"registered":     {55, -19, 834, 737},
+	"ring":           {200, 568, 420, 776},
+	"s":              {63, -14, 584, 546},
+	"sacute":         {63, -14, 627, 750},
+	"scaron":         {63, -14, 614, 750},
+	"scedilla":       {63, -228, 584, 546},
+	"scommaaccent":   {63, -228, 584, 546},
+	"section":        {61, -184, 598, 727},
+	"semicolon":      {56, -168, 351, 512},
+	"seven":          {125, 0, 676, 698},
+	"six":            {85, -19, 619, 710},
+	"slash":          {-37, -19, 468, 737},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {50, -16, 635, 718},
+	"summation":      {14, -10, 670, 706},
+	"t":              {100, -6, 422, 676},
+	"tcaron":         {100, -6, 608, 878},
+	"tcommaaccent":   {58, -228, 422, 676},
+	"thorn":          {18, -208, 645, 718},
+	"three":          {65, -19, 608, 710},
+	"threequarters":  {99, -19, 839, 710},
+	"threesuperior":  {91, 271, 441, 710},
+	"tilde":          {113, 610, 507, 737},
+	"trademark":      {179, 306, 1109, 718},
+	"two":            {26, 0, 619, 710},
+	"twosuperior":    {69, 283, 449, 710},
+	"u":              {98, -14, 658, 532},
+	"uacute":         {98, -14, 658, 750},
+	"ucircumflex":    {98, -14, 658, 750},
+	"udieresis":      {98, -14, 658, 729},
+	"ugrave":         {98, -14, 658, 750},
+	"uhungarumlaut":  {98, -14, 784, 750},
+	"umacron":        {98, -14, 658, 678},
+	"underscore":     {-27, -125, 540, -75},
+	"uogonek":        {98, -228, 658, 532},
+	"uring":          {98, -14, 658, 776},
+	"v":              {126, 0, 656, 532},
+	"w":              {123, 0, 882, 532},
+	"x":              {15, 0, 648, 532},
+	"y":              {42, -214, 652, 532},
+	"yacute":         {42, -214, 652, 750},
+	"ydieresis":      {42, -214, 652, 729},
+	"yen":            {60, 0, 713, 698},
+	"z":              {20, 0, 583, 532},
+	"zacute":         {20, 0, 599, 750},
+	"zcaron":         {20, 0, 586, 750},
+	"zdotaccent":     {20, 0, 583, 729},
+	"zero":           {86, -19, 617, 710},
+}
+
+// Helvetica-Oblique glyph bounding boxes loaded from afms/Helvetica-Oblique.afm.  See afms/MustRead.html for license information.
+var helveticaObliqueGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {14, 0, 654, 718},
+	"AE":             {8, 0, 1097, 718},
+	"Aacute":         {14, 0, 683, 929},
+	"Abreve":         {14, 0, 685, 926},
+	"Acircumflex":    {14, 0, 654, 929},
+	"Adieresis":      {14, 0, 654, 901},
+	"Agrave":         {14, 0, 654, 929},
+	"Amacron":        {14, 0, 677, 879},
+	"Aogonek":        {14, -225, 654, 718},
+	"Aring":          {14, 0, 654, 931},
+	"Atilde":         {14, 0, 699, 917},
+	"B":              {74, 0, 712, 718},
+	"C":              {108, -19, 782, 737},
+	"Cacute":         {108, -19, 782, 929},
+	"Ccaron":         {108, -19, 782, 929},
+	"Ccedilla":       {108, -225, 782, 737},
+	"D":              {81, 0, 764, 718},
+	"Dcaron":         {81, 0, 764, 929},
+	"Dcroat":         {69, 0, 764, 718},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {86, 0, 762, 718},
+	"Eacute":         {86, 0, 762, 929},
+	"Ecaron":         {86, 0, 762, 929},
+	"Ecircumflex":    {86, 0, 762, 929},
+	"Edieresis":      {86, 0, 762, 901},
+	"Edotaccent":     {86, 0, 762, 901},
+	"Egrave":         {86, 0, 762, 929},
+	"Emacron":        {86, 0, 762, 879},
+	"Eogonek":        {86, -220, 762, 718},
+	"Eth":            {69, 0, 764, 718},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {86, 0, 736, 718},
+	"G":              {111, -19, 799, 737},
+	"Gbreve":         {111, -19, 799, 926},
+	"Gcommaaccent":   {111, -225, 799, 737},
+	"H":              {77, 0, 799, 718},
+	"I":              {91, 0, 341, 718},
+	"Iacute":         {91, 0, 489, 929},
+	"Icircumflex":    {91, 0, 452, 929},
+	"Idieresis":      {91, 0, 458, 901},
+	"Idotaccent":     {91, 0, 377, 901},
+	"Igrave":         {91, 0, 351, 929},
+	"Imacron":        {91, 0, 483, 879},
+	"Iogonek":        {-33, -225, 341, 718},
+	"J":              {47, -19, 581, 718},
+	"K":              {76, 0, 808, 718},
+	"Kcommaaccent":   {76, -225, 808, 718},
+	"L":              {76, 0, 555, 718},
+	"Lacute":         {76, 0, 555, 929},
+	"Lcaron":         {76, 0, 570, 718},
+	"Lcommaaccent":   {76, -225, 555, 718},
+	"Lslash":         {41, 0, 555, 718},
+	"M":              {73, 0, 914, 718},
+	"N":              {76, 0, 799, 718},
+	"Nacute":         {76, 0, 799, 929},
+	"Ncaron":         {76, 0, 799, 929},
+	"Ncommaaccent":   {76, -225, 799, 718},
+	"Ntilde":         {76, 0, 799, 917},
+	"O":              {105, -19, 826, 737},
+	"OE":             {98, -19, 1116, 737},
+	"Oacute":         {105, -19, 826, 929},
+	"Ocircumflex":    {105, -19, 826, 929},
+	"Odieresis":      {105, -19, 826, 901},
+	"Ograve":         {105, -19, 826, 929},
+	"Ohungarumlaut":  {105, -19, 829, 929},
+	"Omacron":        {105, -19, 826, 879},
+	"Oslash":         {43, -19, 890, 737},
+	"Otilde":         {105, -19, 826, 917},
+	"P":              {86, 0, 737, 718},
+	"Q":              {105, -56, 826, 737},
+	"R":              {88, 0, 773, 718},
+	"Racute":         {88, 0, 773, 929},
+	"Rcaron":         {88, 0, 773, 929},
+	"Rcommaaccent":   {88, -225, 773, 718},
+	"S":              {90, -19, 713, 737},
+	"Sacute":         {90, -19, 713, 929},
+	"Scaron":         {90, -19, 713, 929},
+	"Scedilla":       {90, -225, 713, 737},
+	"Scommaaccent":   {90, -225, 713, 737},
+	"T":              {148, 0, 750, 718},
+	"Tcaron":         {148, 0, 750, 929},
+	"Tcommaaccent":   {148, -225, 750, 718},
+	"Thorn":          {86, 0, 712, 718},
+	"U":              {123, -19, 797, 718},
+	"Uacute":         {123, -19, 797, 929},
+	"Ucircumflex":    {123, -19, 797, 929},
+	"Udieresis":      {123, -19, 797, 901},
+	"Ugrave":         {123, -19, 797, 929},
+	"Uhungarumlaut":  {123, -19, 801, 929},
+	"Umacron":        {123, -19, 797, 879},
+	"Uogonek":        {123, -225, 797, 718},
+	"Uring":          {123, -19, 797, 931},
+	"V":              {173, 0, 800, 718},
+	"W":              {169, 0, 1081, 718},
+	"X":              {19, 0, 790, 718},
+	"Y":              {167, 0, 806, 718},
+	"Yacute":         {167, 0, 806, 929},
+	"Ydieresis":      {167, 0, 806, 901},
+	"Z":              {23, 0, 741, 718},
+	"Zacute":         {23, 0, 741, 929},
+	"Zcaron":         {23, 0, 741, 929},
+	"Zdotaccent":     {23, 0, 741, 901},
+	"a":              {61, -15, 559, 538},
+	"aacute":         {61, -15, 587, 734},
+	"abreve":         {61, -15, 578, 731},
+	"acircumflex":    {61, -15, 559, 734},
+	"acute":          {248, 593, 475, 734},
+	"adieresis":      {61, -15, 559, 706},
+	"ae":             {61, -15, 909, 538},
+	"agrave":         {61, -15, 559, 734},
+	"amacron":        {61, -15, 580, 684},
+	"ampersand":      {77, -15, 647, 718},
+	"aogonek":        {61, -220, 559, 538},
+	"aring":          {61, -15, 559, 756},
+	"asciicircum":    {42, 264, 539, 688},
+	"asciitilde":     {111, 180, 580, 326},
+	"asterisk":       {165, 431, 475, 718},
+	"at":             {215, -19, 965, 737},
+	"atilde":         {61, -15, 592, 722},
+	"b":              {58, -15, 584, 718},
+	"backslash":      {140, -19, 291, 737},
+	"bar":            {46, -225, 332, 775},
+	"braceleft":      {92, -196, 445, 722},
+	"braceright":     {0, -196, 354, 722},
+	"bracketleft":    {21, -196, 403, 722},
+	"bracketright":   {-14, -196, 368, 722},
+	"breve":          {167, 595, 476, 731},
+	"brokenbar":      {62, -150, 316, 700},
+	"bullet":         {91, 202, 413, 517},
+	"c":              {74, -15, 553, 538},
+	"cacute":         {74, -15, 559, 734},
+	"caron":          {177, 593, 468, 734},
+	"ccaron":         {74, -15, 553, 734},
+	"ccedilla":       {74, -225, 553, 538},
+	"cedilla":        {2, -225, 232, 0},
+	"cent":           {95, -115, 584, 623},
+	"circumflex":     {147, 593, 438, 734},
+	"colon":          {87, 0, 301, 516},
+	"comma":          {56, -147, 214, 106},
+	"commaaccent":    {39, -225, 172, -40},
+	"copyright":      {54, -19, 837, 737},
+	"currency":       {60, 99, 646, 603},
+	"d":              {84, -15, 652, 718},
+	"dagger":         {135, -159, 622, 718},
+	"daggerdbl":      {52, -159, 623, 718},
+	"dcaron":         {84, -15, 808, 718},
+	"dcroat":         {84, -15, 689, 718},
+	"degree":         {169, 411, 468, 703},
+	"dieresis":       {168, 604, 443, 706},
+	"divide":         {85, -19, 606, 524},
+	"dollar":         {69, -115, 617, 775},
+	"dotaccent":      {249, 604, 362, 706},
+	"dotlessi":       {95, 0, 294, 523},
+	"e":              {84, -15, 578, 538},
+	"eacute":         {84, -15, 587, 734},
+	"ecaron":         {84, -15, 580, 734},
+	"ecircumflex":    {84, -15, 578, 734},
+	"edieresis":      {84, -15, 578, 706},
+	"edotaccent":     {84, -15, 578, 706},
+	"egrave":         {84, -15, 578, 734},
+	"eight":          {74, -19, 607, 703},
+	"ellipsis":       {115, 0, 908, 106},
+	"emacron":        {84, -15, 580, 684},
+	"emdash":         {51, 240, 1067, 313},
+	"endash":         {51, 240, 623, 313},
+	"eogonek":        {84, -225, 578, 538},
+	"equal":          {63, 115, 628, 390},
+	"eth":            {81, -15, 617, 737},
+	"exclam":         {90, 0, 340, 718},
+	"exclamdown":     {77, -195, 326, 523},
+	"f":              {86, 0, 416, 728},
+	"fi":             {86, 0, 587, 728},
+	"five":           {68, -19, 621, 688},
+	"fl":             {86, 0, 585, 728},
+	"florin":         {-52, -207, 654, 737},
+	"four":           {61, 0, 576, 703},
+	"fraction":       {-170, -19, 482, 703},
+	"g":              {42, -220, 610, 538},
+	"gbreve":         {42, -220, 610, 731},
+	"gcommaaccent":   {42, -220, 610, 822},
+	"germandbls":     {67, -15, 658, 728},
+	"grave":          {170, 593, 337, 734},
+	"greater":        {50, 11, 597, 495},
+	"greaterequal":   {26, 0, 620, 674},
+	"guillemotleft":  {146, 108, 554, 446},
+	"guillemotright": {120, 108, 528, 446},
+	"guilsinglleft":  {137, 108, 340, 446},
+	"guilsinglright": {111, 108, 314, 446},
+	"h":              {65, 0, 573, 718},
+	"hungarumlaut":   {157, 593, 565, 734},
+	"hyphen":         {93, 232, 357, 322},
+	"i":              {67, 0, 308, 718},
+	"iacute":         {95, 0, 448, 734},
+	"icircumflex":    {95, 0, 411, 734},
+	"idieresis":      {95, 0, 416, 706},
+	"igrave":         {95, 0, 310, 734},
+	"imacron":        {95, 0, 417, 684},
+	"iogonek":        {-61, -225, 308, 718},
+	"j":              {-60, -210, 308, 718},
+	"k":              {67, 0, 600, 718},
+	"kcommaaccent":   {67, -225, 600, 718},
+	"l":              {67, 0, 308, 718},
+	"lacute":         {67, 0, 461, 929},
+	"lcaron":         {67, 0, 464, 718},
+	"lcommaaccent":   {25, -225, 308, 718},
+	"less":           {94, 11, 641, 495},
+	"lessequal":      {26, 0, 666, 674},
+	"logicalnot":     {106, 108, 628, 390},
+	"lozenge":        {88, 0, 540, 728},
+	"lslash":         {41, 0, 347, 718},
+	"m":              {65, 0, 852, 538},
+	"macron":         {143, 627, 468, 684},
+	"minus":          {85, 216, 606, 289},
+	"mu":             {24, -207, 600, 523},
+	"multiply":       {50, 0, 642, 506},
+	"n":              {65, 0, 573, 538},
+	"nacute":         {65, 0, 587, 734},
+	"ncaron":         {65, 0, 580, 734},
+	"ncommaaccent":   {65, -225, 573, 538},
+	"nine":           {82, -19, 609, 703},
+	"notequal":       {34, -35, 623, 551},
+	"ntilde":         {65, 0, 592, 722},
+	"numbersign":     {73, 0, 631, 688},
+	"o":              {83, -14, 585, 538},
+	"oacute":         {83, -14, 587, 734},
+	"ocircumflex":    {83, -14, 585, 734},
+	"odieresis":      {83, -14, 585, 706},
+	"oe":             {83, -15, 964, 538},
+	"ogonek":         {43, -225, 249, 0},
+	"ograve":         {83, -14, 585, 734},
+	"ohungarumlaut":  {83, -14, 677, 734},
+	"omacron":        {83, -14, 585, 684},
+	"one":            {207, 0, 508, 703},
+	"onehalf":        {114, -19, 839, 703},
+	"onequarter":     {150, -19, 802, 703},
+	"onesuperior":    {166, 281, 371, 703},
+	"ordfeminine":    {127, 405, 449, 737},
+	"ordmasculine":   {141, 405, 468, 737},
+	"oslash":         {29, -22, 647, 545},
+	"otilde":         {83, -14, 602, 722},
+	"p":              {14, -207, 584, 538},
+	"paragraph":      {126, -173, 650, 718},
+	"parenleft":      {108, -207, 454, 733},
+	"parenright":     {-9, -207, 337, 733},
+	"partialdiff":    {41, -38, 550, 714},
+	"percent":        {147, -19, 889, 703},
+	"period":         {87, 0, 214, 106},
+	"periodcentered": {129, 190, 257, 315},
+	"perthousand":    {88, -19, 1029, 703},
+	"plus":           {85, 0, 606, 505},
+	"plusminus":      {39, 0, 618, 506},
+	"q":              {84, -207, 605, 538},
+	"question":       {161, 0, 610, 727},
+	"questiondown":   {85, -201, 534, 525},
+	"quotedbl":       {168, 463, 438, 718},
+	"quotedblbase":   {-6, -149, 318, 106},
+	"quotedblleft":   {138, 470, 461, 725},
+	"quotedblright":  {124, 463, 448, 718},
+	"quoteleft":      {165, 470, 323, 725},
+	"quoteright":     {151, 463, 310, 718},
+	"quotesinglbase": {21, -149, 180, 106},
+	"quotesingle":    {157, 463, 285, 718},
+	"r":              {77, 0, 446, 538},
+	"racute":         {77, 0, 475, 734},
+	"radical":        {79, -80, 617, 762},
+	"rcaron":         {77, 0, 508, 734},
+	"rcommaaccent":   {30, -225, 446, 538},
+	"registered":     {54, -19, 837, 737},
+	"ring":           {214, 572, 402, 756},
+	"s":              {63, -15, 529, 538},
+	"sacute":         {63, -15, 559, 734},
+	"scaron":         {63, -15, 552, 734},
+	"scedilla":       {63, -225, 529, 538},
+	"scommaaccent":   {63, -225, 529, 538},
+	"section":        {76, -191, 584, 737},
+	"semicolon":      {56, -147, 301, 516},
+	"seven":          {137, 0, 669, 688},
+	"six":            {91, -19, 615, 703},
+	"slash":          {-21, -19, 452, 737},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {49, -16, 634, 718},
+	"summation":      {15, -10, 671, 706},
+	"t":              {102, -7, 368, 669},
+	"tcaron":         {102, -7, 501, 808},
+	"tcommaaccent":   {63, -225, 368, 669},
+	"thorn":          {14, -207, 584, 718},
+	"three":          {75, -19, 610, 703},
+	"threequarters":  {130, -19, 861, 703},
+	"threesuperior":  {90, 270, 436, 703},
+	"tilde":          {125, 606, 490, 722},
+	"trademark":      {186, 306, 1056, 718},
+	"two":            {26, 0, 617, 703},
+	"twosuperior":    {64, 281, 449, 703},
+	"u":              {94, -15, 600, 523},
+	"uacute":         {94, -15, 600, 734},
+	"ucircumflex":    {94, -15, 600, 734},
+	"udieresis":      {94, -15, 600, 706},
+	"ugrave":         {94, -15, 600, 734},
+	"uhungarumlaut":  {94, -15, 677, 734},
+	"umacron":        {94, -15, 600, 684},
+	"underscore":     {-27, -125, 540, -75},
+	"uogonek":        {94, -225, 600, 523},
+	"uring":          {94, -15, 600, 756},
+	"v":              {119, 0, 603, 523},
+	"w":              {125, 0, 820, 523},
+	"x":              {11, 0, 594, 523},
+	"y":              {15, -214, 600, 523},
+	"yacute":         {15, -214, 600, 734},
+	"ydieresis":      {15, -214, 600, 706},
+	"yen":            {81, 0, 699, 688},
+	"z":              {31, 0, 571, 523},
+	"zacute":         {31, 0, 571, 734},
+	"zcaron":         {31, 0, 571, 734},
+	"zdotaccent":     {31, 0, 571, 706},
+	"zero":           {93, -19, 608, 703},
+}
+
+// Symbol glyph bounding boxes loaded from afms/Symbol.afm.  See afms/MustRead.html for license information.
+var symbolGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"Alpha":          {4, 0, 684, 673},
+	"Beta":           {29, 0, 592, 673},
+	"Chi":            {-9, 0, 704, 673},
+	"Delta":          {6, 0, 608, 688},
+	"Epsilon":        {32, 0, 617, 673},
+	"Eta":            {39, 0, 729, 673},
+	"Euro":           {20, -12, 714, 685},
+	"Gamma":          {24, 0, 609, 673},
+	"Ifraktur":       {10, -53, 578, 740},
+	"Iota":           {32, 0, 316, 673},
+	"Kappa":          {35, 0, 722, 673},
+	"Lambda":         {6, 0, 680, 688},
+	"Mu":             {28, 0, 887, 673},
+	"Nu":             {29, -8, 720, 673},
+	"Omega":          {34, 0, 736, 688},
+	"Omicron":        {41, -17, 715, 685},
+	"Phi":            {26, 0, 741, 673},
+	"Pi":             {25, 0, 745, 673},
+	"Psi":            {15, 0, 781, 684},
+	"Rfraktur":       {26, -15, 759, 734},
+	"Rho":            {28, 0, 563, 673},
+	"Sigma":          {5, 0, 589, 673},
+	"Tau":            {33, 0, 607, 673},
+	"Theta":          {41, -17, 715, 685},
+	"Upsilon":        {-8, 0, 694, 673},
+	"Upsilon1":       {-2, 0, 610, 685},
+	"Xi":             {40, 0, 599, 673},
+	"Zeta":           {44, 0, 636, 673},
+	"aleph":          {175, -18, 661, 658},
+	"alpha":          {41, -18, 622, 500},
+	"ampersand":      {41, -18, 750, 661},
+	"angle":          {26, 0, 738, 673},
+	"angleleft":      {25, -198, 306, 746},
+	"angleright":     {21, -198, 302, 746},
+	"apple":          {56, -3, 733, 808},
+	"approxequal":    {14, 135, 527, 394},
+	"arrowboth":      {24, -15, 1024, 511},
+	"arrowdblboth":   {27, -20, 1023, 510},
+	"arrowdbldown":   {44, -19, 572, 890},
+	"arrowdblleft":   {30, -15, 939, 513},
+	"arrowdblright":  {45, -20, 954, 508},
+	"arrowdblup":     {39, 2, 567, 911},
+	"arrowdown":      {45, -22, 571, 888},
+	"arrowhorizex":   {-60, 220, 1050, 276},
+	"arrowleft":      {32, -15, 942, 511},
+	"arrowright":     {49, -15, 959, 511},
+	"arrowup":        {45, 0, 571, 910},
+	"arrowvertex":    {280, -120, 336, 1010},
+	"asteriskmath":   {65, 134, 427, 551},
+	"bar":            {65, -293, 135, 707},
+	"beta":           {61, -223, 515, 741},
+	"braceex":        {209, -85, 284, 935},
+	"braceleft":      {58, -183, 397, 673},
+	"braceleftbt":    {209, -75, 445, 935},
+	"braceleftmid":   {20, -85, 284, 935},
+	"bracelefttp":    {209, -85, 445, 925},
+	"braceright":     {79, -183, 418, 673},
+	"bracerightbt":   {48, -75, 284, 935},
+	"bracerightmid":  {209, -85, 473, 935},
+	"bracerighttp":   {48, -85, 284, 925},
+	"bracketleft":    {86, -155, 299, 674},
+	"bracketleftbt":  {0, -80, 349, 926},
+	"bracketleftex":  {0, -79, 77, 925},
+	"bracketlefttp":  {0, -80, 349, 926},
+	"bracketright":   {33, -155, 246, 674},
+	"bracketrightbt": {22, -80, 371, 926},
+	"bracketrightex": {294, -79, 371, 925},
+	"bracketrighttp": {22, -80, 371, 926},
+	"bullet":         {50, 113, 410, 473},
+	"carriagereturn": {15, -16, 602, 629},
+	"chi":            {12, -231, 522, 499},
+	"circlemultiply": {43, -17, 733, 673},
+	"circleplus":     {43, -15, 733, 675},
+	"club":           {86, -26, 660, 533},
+	"colon":          {81, -17, 193, 460},
+	"comma":          {56, -152, 194, 104},
+	"congruent":      {11, 0, 537, 475},
+	"copyrightsans":  {49, -15, 739, 675},
+	"copyrightserif": {51, -15, 741, 675},
+	"degree":         {50, 385, 350, 685},
+	"delta":          {40, -19, 481, 740},
+	"diamond":        {142, -36, 600, 550},
+	"divide":         {10, 71, 536, 456},
+	"dotmath":        {69, 210, 169, 310},
+	"eight":          {56, -14, 445, 685},
+	"element":        {45, 0, 505, 468},
+	"ellipsis":       {111, -17, 889, 95},
+	"emptyset":       {39, -24, 781, 719},
+	"epsilon":        {22, -19, 427, 502},
+	"equal":          {11, 141, 537, 390},
+	"equivalence":    {14, 82, 538, 443},
+	"eta":            {0, -202, 527, 514},
+	"exclam":         {128, -17, 240, 672},
+	"existential":    {25, 0, 478, 707},
+	"five":           {32, -14, 445, 690},
+	"florin":         {2, -193, 494, 686},
+	"four":           {15, 0, 469, 685},
+	"fraction":       {-180, -12, 340, 677},
+	"gamma":          {5, -225, 484, 499},
+	"gradient":       {36, -19, 681, 718},
+	"greater":        {26, 0, 523, 522},
+	"greaterequal":   {29, 0, 526, 639},
+	"heart":          {117, -33, 631, 532},
+	"infinity":       {26, 124, 688, 404},
+	"integral":       {2, -107, 291, 916},
+	"integralbt":     {11, -87, 378, 921},
+	"integralex":     {308, -88, 378, 975},
+	"integraltp":     {308, -88, 675, 920},
+	"intersection":   {40, 0, 732, 509},
+	"iota":           {0, -17, 301, 503},
+	"kappa":          {33, 0, 558, 501},
+	"lambda":         {24, -17, 548, 739},
+	"less":           {26, 0, 523, 522},
+	"lessequal":      {29, 0, 526, 639},
+	"logicaland":     {23, 0, 583, 454},
+	"logicalnot":     {15, 0, 680, 288},
+	"logicalor":      {30, 0, 578, 477},
+	"lozenge":        {18, 0, 466, 745},
+	"minus":          {11, 233, 535, 288},
+	"minute":         {27, 459, 228, 735},
+	"mu":             {33, -223, 567, 500},
+	"multiply":       {17, 8, 533, 524},
+	"nine":           {30, -18, 459, 685},
+	"notelement":     {45, -58, 505, 555},
+	"notequal":       {15, -25, 540, 549},
+	"notsubset":      {36, -70, 690, 540},
+	"nu":             {-9, -16, 475, 507},
+	"numbersign":     {20, -16, 481, 673},
+	"omega":          {42, -17, 684, 500},
+	"omega1":         {12, -18, 671, 583},
+	"omicron":        {35, -19, 501, 499},
+	"one":            {117, 0, 390, 673},
+	"parenleft":      {53, -191, 300, 673},
+	"parenleftbt":    {24, -293, 436, 926},
+	"parenleftex":    {24, -85, 108, 925},
+	"parenlefttp":    {24, -293, 436, 926},
+	"parenright":     {30, -191, 277, 673},
+	"parenrightbt":   {54, -293, 466, 926},
+	"parenrightex":   {382, -85, 466, 925},
+	"parenrighttp":   {54, -293, 466, 926},
+	"partialdiff":    {26, -20, 462, 746},
+	"percent":        {63, -36, 771, 655},
+	"period":         {69, -17, 181, 95},
+	"perpendicular":  {15, 0, 652, 674},
+	"phi":            {28, -224, 492, 673},
+	"phi1":           {36, -224, 587, 499},
+	"pi":             {10, -19, 530, 487},
+	"plus":           {10, 0, 539, 533},
+	"plusminus":      {10, 0, 539, 645},
+	"product":        {25, -101, 803, 751},
+	"propersubset":   {37, 0, 690, 470},
+	"propersuperset": {20, 0, 673, 470},
+	"proportional":   {27, 123, 639, 404},
+	"psi":            {12, -228, 701, 500},
+	"question":       {70, -17, 412, 686},
+	"radical":        {10, -38, 515, 917},
+	"radicalex":      {480, 881, 1090, 917},
+	"reflexsubset":   {37, -125, 690, 470},
+	"reflexsuperset": {20, -125, 673, 470},
+	"registersans":   {50, -20, 740, 670},
+	"registerserif":  {50, -17, 740, 673},
+	"rho":            {50, -230, 490, 499},
+	"second":         {20, 459, 413, 737},
+	"semicolon":      {83, -152, 221, 460},
+	"seven":          {24, -16, 448, 673},
+	"sigma":          {30, -21, 588, 500},
+	"sigma1":         {40, -233, 436, 500},
+	"similar":        {17, 203, 529, 307},
+	"six":            {34, -14, 468, 685},
+	"slash":          {0, -18, 254, 646},
+	"space":          {0, 0, 0, 0},
+	"spade":          {113, -36, 629, 548},
+	"suchthat":       {48, -17, 414, 500},
+	"summation":      {14, -108, 695, 752},
+	"tau":            {10, -19, 418, 500},
+	"therefore":      {163, 0, 701, 487},
+	"theta":          {43, -17, 485, 690},
+	"theta1":         {18, -18, 623, 689},
+	"three":          {43, -14, 435, 685},
+	"trademarksans":  {5, 293, 725, 673},
+	"trademarkserif": {18, 293, 855, 673},
+	"two":            {25, 0, 475, 685},
+	"underscore":     {-2, -125, 502, -75},
+	"union":          {40, -17, 732, 492},
+	"universal":      {31, 0, 681, 705},
+	"upsilon":        {7, -18, 535, 507},
+	"weierstrass":    {159, -211, 870, 573},
+	"xi":             {27, -224, 469, 766},
+	"zero":           {24, -14, 476, 685},
+	"zeta":           {60, -225, 467, 756},
+}
+
+// Times-Bold glyph bounding boxes loaded from afms/Times-Bold.afm.  See afms/MustRead.html for license information.
+var timesBoldGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {9, 0, 689, 690},
+	"AE":             {4, 0, 951, 676},
+	"Aacute":         {9, 0, 689, 923},
+	"Abreve":         {9, 0, 689, 901},
+	"Acircumflex":    {9, 0, 689, 914},
+	"Adieresis":      {9, 0, 689, 877},
+	"Agrave":         {9, 0, 689, 923},
+	"Amacron":        {9, 0, 689, 847},
+	"Aogonek":        {9, -193, 699, 690},
+	"Aring":          {9, 0, 689, 935},
+	"Atilde":         {9, 0, 689, 884},
+	"B":              {16, 0, 619, 676},
+	"C":              {49, -19, 687, 691},
+	"Cacute":         {49, -19, 687, 923},
+	"Ccaron":         {49, -19, 687, 914},
+	"Ccedilla":       {49, -218, 687, 691},
+	"D":              {14, 0, 690, 676},
+	"Dcaron":         {14, 0, 690, 914},
+	"Dcroat":         {6, 0, 690, 676},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {16, 0, 641, 676},
+	"Eacute":         {16, 0, 641, 923},
+	"Ecaron":         {16, 0, 641, 914},
+	"Ecircumflex":    {16, 0, 641, 914},
+	"Edieresis":      {16, 0, 641, 877},
+	"Edotaccent":     {16, 0, 641, 901},
+	"Egrave":         {16, 0, 641, 923},
+	"Emacron":        {16, 0, 641, 847},
+	"Eogonek":        {16, -193, 644, 676},
+	"Eth":            {6, 0, 690, 676},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {16, 0, 583, 676},
+	"G":              {37, -19, 755, 691},
+	"Gbreve":         {37, -19, 755, 901},
+	"Gcommaaccent":   {37, -218, 755, 691},
+	"H":              {21, 0, 759, 676},
+	"I":              {20, 0, 370, 676},
+	"Iacute":         {20, 0, 370, 923},
+	"Icircumflex":    {20, 0, 370, 914},
+	"Idieresis":      {20, 0, 370, 877},
+	"Idotaccent":     {20, 0, 370, 901},
+	"Igrave":         {20, 0, 370, 923},
+	"Imacron":        {20, 0, 370, 847},
+	"Iogonek":        {20, -193, 370, 676},
+	"J":              {3, -96, 479, 676},
+	"K":              {30, 0, 769, 676},
+	"Kcommaaccent":   {30, -218, 769, 676},
+	"L":              {19, 0, 638, 676},
+	"Lacute":         {19, 0, 638, 923},
+	"Lcaron":         {19, 0, 652, 682},
+	"Lcommaaccent":   {19, -218, 638, 676},
+	"Lslash":         {19, 0, 638, 676},
+	"M":              {14, 0, 921, 676},
+	"N":              {16, -18, 701, 676},
+	"Nacute":         {16, -18, 701, 923},
+	"Ncaron":         {16, -18, 701, 914},
+	"Ncommaaccent":   {16, -188, 701, 676},
+	"Ntilde":         {16, -18, 701, 884},
+	"O":              {35, -19, 743, 691},
+	"OE":             {22, -5, 981, 684},
+	"Oacute":         {35, -19, 743, 923},
+	"Ocircumflex":    {35, -19, 743, 914},
+	"Odieresis":      {35, -19, 743, 877},
+	"Ograve":         {35, -19, 743, 923},
+	"Ohungarumlaut":  {35, -19, 743, 923},
+	"Omacron":        {35, -19, 743, 847},
+	"Oslash":         {35, -74, 743, 737},
+	"Otilde":         {35, -19, 743, 884},
+	"P":              {16, 0, 600, 676},
+	"Q":              {35, -176, 743, 691},
+	"R":              {26, 0, 715, 676},
+	"Racute":         {26, 0, 715, 923},
+	"Rcaron":         {26, 0, 715, 914},
+	"Rcommaaccent":   {26, -218, 715, 676},
+	"S":              {35, -19, 513, 692},
+	"Sacute":         {35, -19, 513, 923},
+	"Scaron":         {35, -19, 513, 914},
+	"Scedilla":       {35, -218, 513, 692},
+	"Scommaaccent":   {35, -218, 513, 692},
+	"T":              {31, 0, 636, 676},
+	"Tcaron":         {31, 0, 636, 914},
+	"Tcommaaccent":   {31, -218, 636, 676},
+	"Thorn":          {16, 0, 600, 676},
+	"U":              {16, -19, 701, 676},
+	"Uacute":         {16, -19, 701, 923},
+	"Ucircumflex":    {16, -19, 701, 914},
+	"Udieresis":      {16, -19, 701, 877},
+	"Ugrave":         {16, -19, 701, 923},
+	"Uhungarumlaut":  {16, -19, 701, 923},
+	"Umacron":        {16, -19, 701, 847},
+	"Uogonek":        {16, -193, 701, 676},
+	"Uring":          {16, -19, 701, 935},
+	"V":              {16, -18, 701, 676},
+	"W":              {19, -15, 981, 676},
+	"X":              {16, 0, 699, 676},
+	"Y":              {15, 0, 699, 676},
+	"Yacute":         {15, 0, 699, 923},
+	"Ydieresis":      {15, 0, 699, 877},
+	"Z":              {28, 0, 634, 676},
+	"Zacute":         {28, 0, 634, 923},
+	"Zcaron":         {28, 0, 634, 914},
+	"Zdotaccent":     {28, 0, 634, 901},
+	"a":              {25, -14, 488, 473},
+	"aacute":         {25, -14, 488, 713},
+	"abreve":         {25, -14, 488, 691},
+	"acircumflex":    {25, -14, 488, 704},
+	"acute":          {86, 528, 324, 713},
+	"adieresis":      {25, -14, 488, 667},
+	"ae":             {33, -14, 693, 473},
+	"agrave":         {25, -14, 488, 713},
+	"amacron":        {25, -14, 488, 637},
+	"ampersand":      {62, -16, 787, 691},
+	"aogonek":        {25, -193, 504, 473},
+	"aring":          {25, -14, 488, 740},
+	"asciicircum":    {73, 311, 509, 676},
+	"asciitilde":     {29, 173, 491, 333},
+	"asterisk":       {56, 255, 447, 691},
+	"at":             {108, -19, 822, 691},
+	"atilde":         {25, -14, 488, 674},
+	"b":              {17, -14, 521, 676},
+	"backslash":      {-25, -19, 303, 691},
+	"bar":            {66, -218, 154, 782},
+	"braceleft":      {22, -175, 340, 698},
+	"braceright":     {54, -175, 372, 698},
+	"bracketleft":    {67, -149, 301, 678},
+	"bracketright":   {32, -149, 266, 678},
+	"breve":          {15, 528, 318, 691},
+	"brokenbar":      {66, -143, 154, 707},
+	"bullet":         {35, 198, 315, 478},
+	"c":              {25, -14, 430, 473},
+	"cacute":         {25, -14, 430, 713},
+	"caron":          {-2, 528, 335, 704},
+	"ccaron":         {25, -14, 430, 704},
+	"ccedilla":       {25, -218, 430, 473},
+	"cedilla":        {68, -218, 294, 0},
+	"cent":           {53, -140, 458, 588},
+	"circumflex":     {-2, 528, 335, 704},
+	"colon":          {82, -13, 251, 472},
+	"comma":          {39, -180, 223, 155},
+	"commaaccent":    {47, -218, 203, -50},
+	"copyright":      {26, -19, 721, 691},
+	"currency":       {-26, 61, 526, 613},
+	"d":              {25, -14, 534, 676},
+	"dagger":         {47, -134, 453, 691},
+	"daggerdbl":      {45, -132, 456, 691},
+	"dcaron":         {25, -14, 681, 682},
+	"dcroat":         {25, -14, 534, 676},
+	"degree":         {57, 402, 343, 688},
+	"dieresis":       {-2, 537, 335, 667},
+	"divide":         {33, -31, 537, 537},
+	"dollar":         {29, -99, 472, 750},
+	"dotaccent":      {103, 536, 258, 691},
+	"dotlessi":       {16, 0, 255, 461},
+	"e":              {25, -14, 426, 473},
+	"eacute":         {25, -14, 426, 713},
+	"ecaron":         {25, -14, 426, 704},
+	"ecircumflex":    {25, -14, 426, 704},
+	"edieresis":      {25, -14, 426, 667},
+	"edotaccent":     {25, -14, 426, 691},
+	"egrave":         {25, -14, 426, 713},
+	"eight":          {28, -13, 472, 688},
+	"ellipsis":       {82, -13, 917, 156},
+	"emacron":        {25, -14, 426, 637},
+	"emdash":         {0, 181, 1000, 271},
+	"endash":         {0, 181, 500, 271},
+	"eogonek":        {25, -193, 426, 473},
+	"equal":          {33, 107, 537, 399},
+	"eth":            {25, -14, 476, 691},
+	"exclam":         {81, -13, 251, 691},
+	"exclamdown":     {82, -203, 252, 501},
+	"f":              {14, 0, 389, 691},
+	"fi":             {14, 0, 536, 691},
+	"five":           {22, -8, 470, 676},
+	"fl":             {14, 0, 536, 691},
+	"florin":         {0, -155, 498, 706},
+	"four":           {19, 0, 475, 688},
+	"fraction":       {-168, -12, 329, 688},
+	"g":              {28, -206, 483, 473},
+	"gbreve":         {28, -206, 483, 691},
+	"gcommaaccent":   {28, -206, 483, 829},
+	"germandbls":     {19, -12, 517, 691},
+	"grave":          {8, 528, 246, 713},
+	"greater":        {31, -8, 539, 514},
+	"greaterequal":   {26, 0, 523, 704},
+	"guillemotleft":  {23, 36, 473, 415},
+	"guillemotright": {27, 36, 477, 415},
+	"guilsinglleft":  {51, 36, 305, 415},
+	"guilsinglright": {28, 36, 282, 415},
+	"h":              {16, 0, 534, 676},
+	"hungarumlaut":   {-13, 528, 425, 713},
+	"hyphen":         {44, 171, 287, 287},
+	"i":              {16, 0, 255, 691},
+	"iacute":         {16, 0, 289, 713},
+	"icircumflex":    {-37, 0, 300, 704},
+	"idieresis":      {-37, 0, 300, 667},
+	"igrave":         {-27, 0, 255, 713},
+	"imacron":        {-8, 0, 272, 637},
+	"iogonek":        {16, -193, 274, 691},
+	"j":              {-57, -203, 263, 691},
+	"k":              {22, 0, 543, 676},
+	"kcommaaccent":   {22, -218, 543, 676},
+	"l":              {16, 0, 255, 676},
+	"lacute":         {16, 0, 297, 923},
+	"lcaron":         {16, 0, 412, 682},
+	"lcommaaccent":   {16, -218, 255, 676},
+	"less":           {31, -8, 539, 514},
+	"lessequal":      {29, 0, 526, 704},
+	"logicalnot":     {33, 108, 537, 399},
+	"lozenge":        {10, 0, 484, 745},
+	"lslash":         {-22, 0, 303, 676},
+	"m":              {16, 0, 814, 473},
+	"macron":         {1, 565, 331, 637},
+	"minus":          {33, 209, 537, 297},
+	"mu":             {33, -206, 536, 461},
+	"multiply":       {48, 16, 522, 490},
+	"n":              {21, 0, 539, 473},
+	"nacute":         {21, 0, 539, 713},
+	"ncaron":         {21, 0, 539, 704},
+	"ncommaaccent":   {21, -218, 539, 473},
+	"nine":           {26, -13, 473, 688},
+	"notequal":       {15, -49, 540, 570},
+	"ntilde":         {21, 0, 539, 674},
+	"numbersign":     {4, 0, 496, 700},
+	"o":              {25, -14, 476, 473},
+	"oacute":         {25, -14, 476, 713},
+	"ocircumflex":    {25, -14, 476, 704},
+	"odieresis":      {25, -14, 476, 667},
+	"oe":             {22, -14, 696, 473},
+	"ogonek":         {90, -193, 319, 24},
+	"ograve":         {25, -14, 476, 713},
+	"ohungarumlaut":  {25, -14, 529, 713},
+	"omacron":        {25, -14, 476, 637},
+	"one":            {65, 0, 442, 688},
+	"onehalf":        {-7, -12, 775, 688},
+	"onequarter":     {28, -12, 743, 688},
+	"onesuperior":    {28, 275, 273, 688},
+	"ordfeminine":    {-1, 397, 301, 688},
+	"ordmasculine":   {18, 397, 312, 688},
+	"oslash":         {25, -92, 476, 549},
+	"otilde":         {25, -14, 476, 674},
+	"p":              {19, -205, 524, 473},
+	"paragraph":      {0, -186, 519, 676},
+	"parenleft":      {46, -168, 306, 694},
+	"parenright":     {27, -168, 287, 694},
+	"partialdiff":    {11, -21, 494, 750},
+	"percent":        {124, -14, 877, 692},
+	"period":         {41, -13, 210, 156},
+	"periodcentered": {41, 248, 210, 417},
+	"perthousand":    {7, -29, 995, 706},
+	"plus":           {33, 0, 537, 506},
+	"plusminus":      {33, 0, 537, 506},
+	"q":              {34, -205, 536, 473},
+	"question":       {57, -13, 445, 689},
+	"questiondown":   {55, -201, 443, 501},
+	"quotedbl":       {83, 404, 472, 691},
+	"quotedblbase":   {14, -180, 468, 155},
+	"quotedblleft":   {32, 356, 486, 691},
+	"quotedblright":  {14, 356, 468, 691},
+	"quoteleft":      {70, 356, 254, 691},
+	"quoteright":     {79, 356, 263, 691},
+	"quotesinglbase": {79, -180, 263, 155},
+	"quotesingle":    {75, 404, 204, 691},
+	"r":              {29, 0, 434, 473},
+	"racute":         {29, 0, 434, 713},
+	"radical":        {10, -46, 512, 850},
+	"rcaron":         {29, 0, 434, 704},
+	"rcommaaccent":   {29, -218, 434, 473},
+	"registered":     {26, -19, 721, 691},
+	"ring":           {60, 527, 273, 740},
+	"s":              {25, -14, 361, 473},
+	"sacute":         {25, -14, 361, 713},
+	"scaron":         {25, -14, 363, 704},
+	"scedilla":       {25, -218, 361, 473},
+	"scommaaccent":   {25, -218, 361, 473},
+	"section":        {57, -132, 443, 691},
+	"semicolon":      {82, -180, 266, 472},
+	"seven":          {17, 0, 477, 676},
+	"six":            {28, -13, 475, 688},
+	"slash":          {-24, -19, 302, 691},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {21, -14, 477, 684},
+	"summation":      {14, -10, 585, 706},
+	"t":              {20, -12, 332, 630},
+	"tcaron":         {20, -12, 425, 815},
+	"tcommaaccent":   {20, -218, 332, 630},
+	"thorn":          {19, -205, 524, 676},
+	"three":          {16, -14, 468, 688},
+	"threequarters":  {23, -12, 733, 688},
+	"threesuperior":  {3, 268, 297, 688},
+	"tilde":          {-16, 547, 349, 674},
+	"trademark":      {24, 271, 977, 676},
+	"two":            {17, 0, 478, 688},
+	"twosuperior":    {0, 275, 300, 688},
+	"u":              {16, -14, 537, 461},
+	"uacute":         {16, -14, 537, 713},
+	"ucircumflex":    {16, -14, 537, 704},
+	"udieresis":      {16, -14, 537, 667},
+	"ugrave":         {16, -14, 537, 713},
+	"uhungarumlaut":  {16, -14, 557, 713},
+	"umacron":        {16, -14, 537, 637},
+	"underscore":     {0, -125, 500, -75},
+	"uogonek":        {16, -193, 539, 461},
+	"uring":          {16, -14, 537, 740},
+	"v":              {21, -14, 485, 461},
+	"w":              {23, -14, 707, 461},
+	"x":              {12, 0, 484, 461},
+	"y":              {16, -205, 480, 461},
+	"yacute":         {16, -205, 480, 713},
+	"ydieresis":      {16, -205, 480, 667},
+	"yen":            {-64, 0, 547, 676},
+	"z":              {21, 0, 420, 461},
+	"zacute":         {21, 0, 420, 713},
+	"zcaron":         {21, 0, 420, 704},
+	"zdotaccent":     {21, 0, 420, 691},
+	"zero":           {24, -13, 476, 688},
+}
+
+// Times-BoldItalic glyph bounding boxes loaded from afms/Times-BoldItalic.afm.  See afms/MustRead.html for license information.
+var timesBoldItalicGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {-67, 0, 593, 683},
+	"AE":             {-64, 0, 918, 669},
+	"Aacute":         {-67, 0, 593, 904},
+	"Abreve":         {-67, 0, 593, 885},
+	"Acircumflex":    {-67, 0, 593, 897},
+	"Adieresis":      {-67, 0, 593, 862},
+	"Agrave":         {-67, 0, 593, 904},
+	"Amacron":        {-67, 0, 593, 830},
+	"Aogonek":        {-67, -183, 604, 683},
+	"Aring":          {-67, 0, 593, 921},
+	"Atilde":         {-67, 0, 593, 862},
+	"B":              {-24, 0, 624, 669},
+	"C":              {32, -18, 677, 685},
+	"Cacute":         {32, -18, 677, 904},
+	"Ccaron":         {32, -18, 677, 897},
+	"Ccedilla":       {32, -218, 677, 685},
+	"D":              {-46, 0, 685, 669},
+	"Dcaron":         {-46, 0, 685, 897},
+	"Dcroat":         {-31, 0, 700, 669},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {-27, 0, 653, 669},
+	"Eacute":         {-27, 0, 653, 904},
+	"Ecaron":         {-27, 0, 653, 897},
+	"Ecircumflex":    {-27, 0, 653, 897},
+	"Edieresis":      {-27, 0, 653, 862},
+	"Edotaccent":     {-27, 0, 653, 862},
+	"Egrave":         {-27, 0, 653, 904},
+	"Emacron":        {-27, 0, 653, 830},
+	"Eogonek":        {-27, -183, 653, 669},
+	"Eth":            {-31, 0, 700, 669},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {-13, 0, 660, 669},
+	"G":              {21, -18, 706, 685},
+	"Gbreve":         {21, -18, 706, 885},
+	"Gcommaaccent":   {21, -218, 706, 685},
+	"H":              {-24, 0, 799, 669},
+	"I":              {-32, 0, 406, 669},
+	"Iacute":         {-32, 0, 432, 904},
+	"Icircumflex":    {-32, 0, 450, 897},
+	"Idieresis":      {-32, 0, 450, 862},
+	"Idotaccent":     {-32, 0, 406, 862},
+	"Igrave":         {-32, 0, 406, 904},
+	"Imacron":        {-32, 0, 461, 830},
+	"Iogonek":        {-32, -183, 406, 669},
+	"J":              {-46, -99, 524, 669},
+	"K":              {-21, 0, 702, 669},
+	"Kcommaaccent":   {-21, -218, 702, 669},
+	"L":              {-22, 0, 590, 669},
+	"Lacute":         {-22, 0, 590, 904},
+	"Lcaron":         {-22, 0, 671, 718},
+	"Lcommaaccent":   {-22, -218, 590, 669},
+	"Lslash":         {-22, 0, 590, 669},
+	"M":              {-29, -12, 917, 669},
+	"N":              {-27, -15, 748, 669},
+	"Nacute":         {-27, -15, 748, 904},
+	"Ncaron":         {-27, -15, 748, 897},
+	"Ncommaaccent":   {-27, -218, 748, 669},
+	"Ntilde":         {-27, -15, 748, 862},
+	"O":              {27, -18, 691, 685},
+	"OE":             {23, -8, 946, 677},
+	"Oacute":         {27, -18, 691, 904},
+	"Ocircumflex":    {27, -18, 691, 897},
+	"Odieresis":      {27, -18, 691, 862},
+	"Ograve":         {27, -18, 691, 904},
+	"Ohungarumlaut":  {27, -18, 723, 904},
+	"Omacron":        {27, -18, 691, 830},
+	"Oslash":         {27, -125, 691, 764},
+	"Otilde":         {27, -18, 691, 862},
+	"P":              {-27, 0, 613, 669},
+	"Q":              {27, -208, 691, 685},
+	"R":              {-29, 0, 623, 669},
+	"Racute":         {-29, 0, 623, 904},
+	"Rcaron":         {-29, 0, 623, 897},
+	"Rcommaaccent":   {-29, -218, 623, 669},
+	"S":              {2, -18, 526, 685},
+	"Sacute":         {2, -18, 531, 904},
+	"Scaron":         {2, -18, 553, 897},
+	"Scedilla":       {2, -218, 526, 685},
+	"Scommaaccent":   {2, -218, 526, 685},
+	"T":              {50, 0, 650, 669},
+	"Tcaron":         {50, 0, 650, 897},
+	"Tcommaaccent":   {50, -218, 650, 669},
+	"Thorn":          {-27, 0, 573, 669},
+	"U":              {67, -18, 744, 669},
+	"Uacute":         {67, -18, 744, 904},
+	"Ucircumflex":    {67, -18, 744, 897},
+	"Udieresis":      {67, -18, 744, 862},
+	"Ugrave":         {67, -18, 744, 904},
+	"Uhungarumlaut":  {67, -18, 744, 904},
+	"Umacron":        {67, -18, 744, 830},
+	"Uogonek":        {67, -183, 744, 669},
+	"Uring":          {67, -18, 744, 921},
+	"V":              {65, -18, 715, 669},
+	"W":              {65, -18, 940, 669},
+	"X":              {-24, 0, 694, 669},
+	"Y":              {73, 0, 659, 669},
+	"Yacute":         {73, 0, 659, 904},
+	"Ydieresis":      {73, 0, 659, 862},
+	"Z":              {-11, 0, 590, 669},
+	"Zacute":         {-11, 0, 590, 904},
+	"Zcaron":         {-11, 0, 590, 897},
+	"Zdotaccent":     {-11, 0, 590, 862},
+	"a":              {-21, -14, 455, 462},
+	"aacute":         {-21, -14, 463, 697},
+	"abreve":         {-21, -14, 471, 678},
+	"acircumflex":    {-21, -14, 455, 690},
+	"acute":          {139, 516, 379, 697},
+	"adieresis":      {-21, -14, 476, 655},
+	"ae":             {-5, -13, 673, 462},
+	"agrave":         {-21, -14, 455, 697},
+	"amacron":        {-21, -14, 467, 623},
+	"ampersand":      {5, -19, 699, 682},
+	"aogonek":        {-21, -183, 455, 462},
+	"aring":          {-21, -14, 455, 729},
+	"asciicircum":    {67, 304, 503, 669},
+	"asciitilde":     {54, 173, 516, 333},
+	"asterisk":       {65, 249, 456, 685},
+	"at":             {63, -18, 770, 685},
+	"atilde":         {-21, -14, 491, 655},
+	"b":              {-14, -13, 444, 699},
+	"backslash":      {-1, -18, 279, 685},
+	"bar":            {66, -218, 154, 782},
+	"braceleft":      {5, -187, 436, 686},
+	"braceright":     {-129, -187, 302, 686},
+	"bracketleft":    {-37, -159, 362, 674},
+	"bracketright":   {-56, -157, 343, 674},
+	"breve":          {71, 516, 387, 678},
+	"brokenbar":      {66, -143, 154, 707},
+	"bullet":         {0, 175, 350, 525},
+	"c":              {-5, -13, 392, 462},
+	"cacute":         {-5, -13, 435, 697},
+	"caron":          {79, 516, 411, 690},
+	"ccaron":         {-5, -13, 467, 690},
+	"ccedilla":       {-5, -218, 392, 462},
+	"cedilla":        {-80, -218, 156, 5},
+	"cent":           {42, -143, 439, 576},
+	"circumflex":     {40, 516, 367, 690},
+	"colon":          {23, -13, 264, 459},
+	"comma":          {-60, -182, 144, 134},
+	"commaaccent":    {-36, -218, 131, -50},
+	"copyright":      {30, -18, 718, 685},
+	"currency":       {-26, 34, 526, 586},
+	"d":              {-21, -13, 517, 699},
+	"dagger":         {91, -145, 494, 685},
+	"daggerdbl":      {10, -139, 493, 685},
+	"dcaron":         {-21, -13, 675, 708},
+	"dcroat":         {-21, -13, 552, 699},
+	"degree":         {83, 397, 369, 683},
+	"dieresis":       {55, 550, 402, 684},
+	"divide":         {33, -29, 537, 535},
+	"dollar":         {-20, -100, 497, 733},
+	"dotaccent":      {163, 550, 298, 684},
+	"dotlessi":       {2, -9, 238, 462},
+	"e":              {5, -13, 398, 462},
+	"eacute":         {5, -13, 435, 697},
+	"ecaron":         {5, -13, 467, 690},
+	"ecircumflex":    {5, -13, 423, 690},
+	"edieresis":      {5, -13, 448, 655},
+	"edotaccent":     {5, -13, 398, 655},
+	"egrave":         {5, -13, 398, 697},
+	"eight":          {3, -13, 476, 683},
+	"ellipsis":       {40, -13, 852, 135},
+	"emacron":        {5, -13, 439, 623},
+	"emdash":         {-40, 178, 977, 269},
+	"endash":         {-40, 178, 477, 269},
+	"eogonek":        {5, -183, 398, 462},
+	"equal":          {33, 107, 537, 399},
+	"eth":            {-3, -13, 454, 699},
+	"exclam":         {67, -13, 370, 684},
+	"exclamdown":     {19, -205, 322, 492},
+	"f":              {-169, -205, 446, 698},
+	"fi":             {-188, -205, 514, 703},
+	"five":           {-11, -13, 487, 669},
+	"fl":             {-186, -205, 553, 704},
+	"florin":         {-87, -156, 537, 707},
+	"four":           {-15, 0, 503, 683},
+	"fraction":       {-169, -14, 324, 683},
+	"g":              {-52, -203, 478, 462},
+	"gbreve":         {-52, -203, 478, 678},
+	"gcommaaccent":   {-52, -203, 478, 767},
+	"germandbls":     {-200, -200, 473, 705},
+	"grave":          {85, 516, 297, 697},
+	"greater":        {31, -8, 539, 514},
+	"greaterequal":   {26, 0, 523, 704},
+	"guillemotleft":  {12, 32, 468, 415},
+	"guillemotright": {12, 32, 468, 415},
+	"guilsinglleft":  {32, 32, 303, 415},
+	"guilsinglright": {10, 32, 281, 415},
+	"h":              {-13, -9, 498, 699},
+	"hungarumlaut":   {69, 516, 498, 697},
+	"hyphen":         {2, 166, 271, 282},
+	"i":              {2, -9, 263, 684},
+	"iacute":         {2, -9, 352, 697},
+	"icircumflex":    {-3, -9, 324, 690},
+	"idieresis":      {2, -9, 364, 655},
+	"igrave":         {2, -9, 259, 697},
+	"imacron":        {2, -9, 294, 623},
+	"iogonek":        {-20, -183, 263, 684},
+	"j":              {-189, -207, 279, 684},
+	"k":              {-23, -8, 483, 699},
+	"kcommaaccent":   {-23, -218, 483, 699},
+	"l":              {2, -9, 290, 699},
+	"lacute":         {2, -9, 392, 904},
+	"lcaron":         {2, -9, 448, 708},
+	"lcommaaccent":   {-42, -218, 290, 699},
+	"less":           {31, -8, 539, 514},
+	"lessequal":      {29, 0, 526, 704},
+	"logicalnot":     {51, 108, 555, 399},
+	"lozenge":        {10, 0, 484, 745},
+	"lslash":         {-7, -9, 307, 699},
+	"m":              {-14, -9, 722, 462},
+	"macron":         {51, 553, 393, 623},
+	"minus":          {51, 209, 555, 297},
+	"mu":             {-60, -207, 516, 449},
+	"multiply":       {48, 16, 522, 490},
+	"n":              {-6, -9, 493, 462},
+	"nacute":         {-6, -9, 493, 697},
+	"ncaron":         {-6, -9, 523, 690},
+	"ncommaaccent":   {-6, -218, 493, 462},
+	"nine":           {-12, -10, 475, 683},
+	"notequal":       {15, -49, 540, 570},
+	"ntilde":         {-6, -9, 504, 655},
+	"numbersign":     {-33, 0, 533, 700},
+	"o":              {-3, -13, 441, 462},
+	"oacute":         {-3, -13, 463, 697},
+	"ocircumflex":    {-3, -13, 451, 690},
+	"odieresis":      {-3, -13, 471, 655},
+	"oe":             {6, -13, 674, 462},
+	"ogonek":         {15, -183, 244, 34},
+	"ograve":         {-3, -13, 441, 697},
+	"ohungarumlaut":  {-3, -13, 582, 697},
+	"omacron":        {-3, -13, 462, 623},
+	"one":            {5, 0, 419, 683},
+	"onehalf":        {-9, -14, 723, 683},
+	"onequarter":     {7, -14, 721, 683},
+	"onesuperior":    {30, 274, 301, 683},
+	"ordfeminine":    {16, 399, 330, 685},
+	"ordmasculine":   {56, 400, 347, 685},
+	"oslash":         {-3, -119, 441, 560},
+	"otilde":         {-3, -13, 491, 655},
+	"p":              {-120, -205, 446, 462},
+	"paragraph":      {-57, -193, 562, 669},
+	"parenleft":      {28, -179, 344, 685},
+	"parenright":     {-44, -179, 271, 685},
+	"partialdiff":    {11, -21, 494, 750},
+	"percent":        {39, -10, 793, 692},
+	"period":         {-9, -13, 139, 135},
+	"periodcentered": {51, 257, 199, 405},
+	"perthousand":    {7, -29, 996, 706},
+	"plus":           {33, 0, 537, 506},
+	"plusminus":      {33, 0, 537, 506},
+	"q":              {1, -205, 471, 462},
+	"question":       {79, -13, 470, 684},
+	"questiondown":   {30, -205, 421, 492},
+	"quotedbl":       {136, 398, 536, 685},
+	"quotedblbase":   {-57, -182, 403, 134},
+	"quotedblleft":   {53, 369, 513, 685},
+	"quotedblright":  {53, 369, 513, 685},
+	"quoteleft":      {128, 369, 332, 685},
+	"quoteright":     {98, 369, 302, 685},
+	"quotesinglbase": {-5, -182, 199, 134},
+	"quotesingle":    {128, 398, 268, 685},
+	"r":              {-21, 0, 389, 462},
+	"racute":         {-21, 0, 407, 697},
+	"radical":        {10, -46, 512, 850},
+	"rcaron":         {-21, 0, 424, 690},
+	"rcommaaccent":   {-67, -218, 389, 462},
+	"registered":     {30, -18, 718, 685},
+	"ring":           {127, 516, 340, 729},
+	"s":              {-19, -13, 333, 462},
+	"sacute":         {-19, -13, 407, 697},
+	"scaron":         {-19, -13, 424, 690},
+	"scedilla":       {-19, -218, 333, 462},
+	"scommaaccent":   {-19, -218, 333, 462},
+	"section":        {36, -143, 459, 685},
+	"semicolon":      {-25, -183, 264, 459},
+	"seven":          {52, 0, 525, 669},
+	"six":            {23, -15, 509, 679},
+	"slash":          {-64, -18, 342, 685},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {-32, -12, 510, 683},
+	"summation":      {14, -10, 585, 706},
+	"t":              {-11, -9, 281, 594},
+	"tcaron":         {-11, -9, 434, 754},
+	"tcommaaccent":   {-62, -218, 281, 594},
+	"thorn":          {-120, -205, 446, 699},
+	"three":          {-15, -13, 450, 683},
+	"threequarters":  {7, -14, 726, 683},
+	"threesuperior":  {17, 265, 321, 683},
+	"tilde":          {48, 536, 407, 655},
+	"trademark":      {32, 263, 968, 669},
+	"two":            {-27, 0, 446, 683},
+	"twosuperior":    {2, 274, 313, 683},
+	"u":              {15, -9, 492, 462},
+	"uacute":         {15, -9, 492, 697},
+	"ucircumflex":    {15, -9, 492, 690},
+	"udieresis":      {15, -9, 499, 655},
+	"ugrave":         {15, -9, 492, 697},
+	"uhungarumlaut":  {15, -9, 610, 697},
+	"umacron":        {15, -9, 492, 623},
+	"underscore":     {0, -125, 500, -75},
+	"uogonek":        {15, -183, 492, 462},
+	"uring":          {15, -9, 492, 729},
+	"v":              {16, -13, 401, 462},
+	"w":              {16, -13, 614, 462},
+	"x":              {-46, -13, 469, 462},
+	"y":              {-94, -205, 392, 462},
+	"yacute":         {-94, -205, 435, 697},
+	"ydieresis":      {-94, -205, 443, 655},
+	"yen":            {33, 0, 628, 669},
+	"z":              {-43, -78, 368, 449},
+	"zacute":         {-43, -78, 407, 697},
+	"zcaron":         {-43, -78, 424, 690},
+	"zdotaccent":     {-43, -78, 368, 655},
+	"zero":           {17, -14, 477, 683},
+}
+
+// Times-Italic glyph bounding boxes loaded from afms/Times-Italic.afm.  See afms/MustRead.html for license information.
+var timesItalicGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {-51, 0, 564, 668},
+	"AE":             {-27, 0, 911, 653},
+	"Aacute":         {-51, 0, 564, 876},
+	"Abreve":         {-51, 0, 564, 862},
+	"Acircumflex":    {-51, 0, 564, 873},
+	"Adieresis":      {-51, 0, 564, 818},
+	"Agrave":         {-51, 0, 564, 876},
+	"Amacron":        {-51, 0, 564, 795},
+	"Aogonek":        {-51, -169, 566, 668},
+	"Aring":          {-51, 0, 564, 883},
+	"Atilde":         {-51, 0, 566, 836},
+	"B":              {-8, 0, 588, 653},
+	"C":              {66, -18, 689, 666},
+	"Cacute":         {66, -18, 690, 876},
+	"Ccaron":         {66, -18, 689, 873},
+	"Ccedilla":       {66, -217, 689, 666},
+	"D":              {-8, 0, 700, 653},
+	"Dcaron":         {-8, 0, 700, 873},
+	"Dcroat":         {-8, 0, 700, 653},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {-1, 0, 634, 653},
+	"Eacute":         {-1, 0, 634, 876},
+	"Ecaron":         {-1, 0, 634, 873},
+	"Ecircumflex":    {-1, 0, 634, 873},
+	"Edieresis":      {-1, 0, 634, 818},
+	"Edotaccent":     {-1, 0, 634, 818},
+	"Egrave":         {-1, 0, 634, 876},
+	"Emacron":        {-1, 0, 634, 795},
+	"Eogonek":        {-1, -169, 634, 653},
+	"Eth":            {-8, 0, 700, 653},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {8, 0, 645, 653},
+	"G":              {52, -18, 722, 666},
+	"Gbreve":         {52, -18, 722, 862},
+	"Gcommaaccent":   {52, -217, 722, 666},
+	"H":              {-8, 0, 767, 653},
+	"I":              {-8, 0, 384, 653},
+	"Iacute":         {-8, 0, 433, 876},
+	"Icircumflex":    {-8, 0, 425, 873},
+	"Idieresis":      {-8, 0, 435, 818},
+	"Idotaccent":     {-8, 0, 384, 818},
+	"Igrave":         {-8, 0, 384, 876},
+	"Imacron":        {-8, 0, 441, 795},
+	"Iogonek":        {-8, -169, 384, 653},
+	"J":              {-6, -18, 491, 653},
+	"K":              {7, 0, 722, 653},
+	"Kcommaaccent":   {7, -217, 722, 653},
+	"L":              {-8, 0, 559, 653},
+	"Lacute":         {-8, 0, 559, 876},
+	"Lcaron":         {-8, 0, 586, 653},
+	"Lcommaaccent":   {-8, -217, 559, 653},
+	"Lslash":         {-8, 0, 559, 653},
+	"M":              {-18, 0, 873, 653},
+	"N":              {-20, -15, 727, 653},
+	"Nacute":         {-20, -15, 727, 876},
+	"Ncaron":         {-20, -15, 727, 873},
+	"Ncommaaccent":   {-20, -187, 727, 653},
+	"Ntilde":         {-20, -15, 727, 836},
+	"O":              {60, -18, 699, 666},
+	"OE":             {49, -8, 964, 666},
+	"Oacute":         {60, -18, 699, 876},
+	"Ocircumflex":    {60, -18, 699, 873},
+	"Odieresis":      {60, -18, 699, 818},
+	"Ograve":         {60, -18, 699, 876},
+	"Ohungarumlaut":  {60, -18, 699, 876},
+	"Omacron":        {60, -18, 699, 795},
+	"Oslash":         {60, -105, 699, 722},
+	"Otilde":         {60, -18, 699, 836},
+	"P":              {0, 0, 605, 653},
+	"Q":              {59, -182, 699, 666},
+	"R":              {-13, 0, 588, 653},
+	"Racute":         {-13, 0, 588, 876},
+	"Rcaron":         {-13, 0, 588, 873},
+	"Rcommaaccent":   {-13, -187, 588, 653},
+	"S":              {17, -18, 508, 667},
+	"Sacute":         {17, -18, 508, 876},
+	"Scaron":         {17, -18, 520, 873},
+	"Scedilla":       {17, -217, 508, 667},
+	"Scommaaccent":   {17, -217, 508, 667},
+	"T":              {59, 0, 633, 653},
+	"Tcaron":         {59, 0, 633, 873},
+	"Tcommaaccent":   {59, -217, 633, 653},
+	"Thorn":          {0, 0, 569, 653},
+	"U":              {102, -18, 765, 653},
+	"Uacute":         {102, -18, 765, 876},
+	"Ucircumflex":    {102, -18, 765, 873},
+	"Udieresis":      {102, -18, 765, 818},
+	"Ugrave":         {102, -18, 765, 876},
+	"Uhungarumlaut":  {102, -18, 765, 876},
+	"Umacron":        {102, -18, 765, 795},
+	"Uogonek":        {102, -184, 765, 653},
+	"Uring":          {102, -18, 765, 883},
+	"V":              {76, -18, 688, 653},
+	"W":              {71, -18, 906, 653},
+	"X":              {-29, 0, 655, 653},
+	"Y":              {78, 0, 633, 653},
+	"Yacute":         {78, 0, 633, 876},
+	"Ydieresis":      {78, 0, 633, 818},
+	"Z":              {-6, 0, 606, 653},
+	"Zacute":         {-6, 0, 606, 876},
+	"Zcaron":         {-6, 0, 606, 873},
+	"Zdotaccent":     {-6, 0, 606, 818},
+	"a":              {17, -11, 476, 441},
+	"aacute":         {17, -11, 487, 664},
+	"abreve":         {17, -11, 502, 650},
+	"acircumflex":    {17, -11, 476, 661},
+	"acute":          {180, 494, 403, 664},
+	"adieresis":      {17, -11, 489, 606},
+	"ae":             {23, -11, 640, 441},
+	"agrave":         {17, -11, 476, 664},
+	"amacron":        {17, -11, 495, 583},
+	"ampersand":      {76, -18, 723, 666},
+	"aogonek":        {17, -169, 476, 441},
+	"aring":          {17, -11, 476, 691},
+	"asciicircum":    {0, 301, 422, 666},
+	"asciitilde":     {40, 183, 502, 323},
+	"asterisk":       {128, 255, 492, 666},
+	"at":             {118, -18, 806, 666},
+	"atilde":         {17, -11, 511, 624},
+	"b":              {23, -11, 473, 683},
+	"backslash":      {-41, -18, 319, 666},
+	"bar":            {105, -217, 171, 783},
+	"braceleft":      {51, -177, 407, 687},
+	"braceright":     {-7, -177, 349, 687},
+	"bracketleft":    {21, -153, 391, 663},
+	"bracketright":   {12, -153, 382, 663},
+	"breve":          {117, 492, 418, 650},
+	"brokenbar":      {105, -142, 171, 708},
+	"bullet":         {40, 191, 310, 461},
+	"c":              {30, -11, 425, 441},
+	"cacute":         {30, -11, 459, 664},
+	"caron":          {121, 492, 426, 661},
+	"ccaron":         {30, -11, 482, 661},
+	"ccedilla":       {30, -217, 425, 441},
+	"cedilla":        {-30, -217, 182, 0},
+	"cent":           {77, -143, 472, 560},
+	"circumflex":     {91, 492, 385, 661},
+	"colon":          {50, -11, 261, 441},
+	"comma":          {-4, -129, 135, 101},
+	"commaaccent":    {8, -217, 133, -50},
+	"copyright":      {41, -18, 719, 666},
+	"currency":       {-22, 53, 522, 597},
+	"d":              {15, -13, 527, 683},
+	"dagger":         {101, -159, 488, 666},
+	"daggerdbl":      {22, -143, 491, 666},
+	"dcaron":         {15, -13, 658, 683},
+	"dcroat":         {15, -13, 572, 683},
+	"degree":         {101, 390, 387, 676},
+	"dieresis":       {107, 548, 405, 646},
+	"divide":         {86, -11, 590, 517},
+	"dollar":         {31, -89, 497, 731},
+	"dotaccent":      {207, 548, 305, 646},
+	"dotlessi":       {49, -11, 235, 441},
+	"e":              {31, -11, 412, 441},
+	"eacute":         {31, -11, 459, 664},
+	"ecaron":         {31, -11, 482, 661},
+	"ecircumflex":    {31, -11, 441, 661},
+	"edieresis":      {31, -11, 451, 606},
+	"edotaccent":     {31, -11, 412, 606},
+	"egrave":         {31, -11, 412, 664},
+	"eight":          {30, -7, 493, 676},
+	"ellipsis":       {57, -11, 762, 100},
+	"emacron":        {31, -11, 457, 583},
+	"emdash":         {-6, 197, 894, 243},
+	"endash":         {-6, 197, 505, 243},
+	"eogonek":        {31, -169, 412, 441},
+	"equal":          {86, 120, 590, 386},
+	"eth":            {27, -11, 482, 683},
+	"exclam":         {39, -11, 302, 667},
+	"exclamdown":     {59, -205, 322, 473},
+	"f":              {-147, -207, 424, 678},
+	"fi":             {-141, -207, 481, 681},
+	"five":           {15, -7, 491, 666},
+	"fl":             {-141, -204, 518, 682},
+	"florin":         {25, -182, 507, 682},
+	"four":           {1, 0, 479, 676},
+	"fraction":       {-169, -10, 337, 676},
+	"g":              {8, -206, 472, 441},
+	"gbreve":         {8, -206, 487, 650},
+	"gcommaaccent":   {8, -206, 472, 706},
+	"germandbls":     {-168, -207, 493, 679},
+	"grave":          {121, 492, 311, 664},
+	"greater":        {84, -8, 592, 514},
+	"greaterequal":   {26, 0, 523, 658},
+	"guillemotleft":  {53, 37, 445, 403},
+	"guillemotright": {55, 37, 447, 403},
+	"guilsinglleft":  {51, 37, 281, 403},
+	"guilsinglright": {52, 37, 282, 403},
+	"h":              {19, -9, 478, 683},
+	"hungarumlaut":   {93, 494, 486, 664},
+	"hyphen":         {49, 192, 282, 255},
+	"i":              {49, -11, 264, 654},
+	"iacute":         {49, -11, 355, 664},
+	"icircumflex":    {33, -11, 327, 661},
+	"idieresis":      {49, -11, 352, 606},
+	"igrave":         {49, -11, 284, 664},
+	"imacron":        {46, -11, 311, 583},
+	"iogonek":        {49, -169, 264, 654},
+	"j":              {-124, -207, 276, 654},
+	"k":              {14, -11, 461, 683},
+	"kcommaaccent":   {14, -187, 461, 683},
+	"l":              {41, -11, 279, 683},
+	"lacute":         {41, -11, 395, 876},
+	"lcaron":         {41, -11, 407, 683},
+	"lcommaaccent":   {22, -217, 279, 683},
+	"less":           {84, -8, 592, 514},
+	"lessequal":      {26, 0, 523, 658},
+	"logicalnot":     {86, 108, 590, 386},
+	"lozenge":        {13, 0, 459, 724},
+	"lslash":         {41, -11, 312, 683},
+	"m":              {12, -9, 704, 441},
+	"macron":         {99, 532, 411, 583},
+	"minus":          {86, 220, 590, 286},
+	"mu":             {-30, -209, 497, 428},
+	"multiply":       {93, 8, 582, 497},
+	"n":              {14, -9, 474, 441},
+	"nacute":         {14, -9, 477, 664},
+	"ncaron":         {14, -9, 510, 661},
+	"ncommaaccent":   {14, -187, 474, 441},
+	"nine":           {23, -17, 492, 676},
+	"notequal":       {12, -29, 537, 541},
+	"ntilde":         {14, -9, 476, 624},
+	"numbersign":     {2, 0, 540, 676},
+	"o":              {27, -11, 468, 441},
+	"oacute":         {27, -11, 487, 664},
+	"ocircumflex":    {27, -11, 468, 661},
+	"odieresis":      {27, -11, 489, 606},
+	"oe":             {20, -12, 646, 441},
+	"ogonek":         {20, -169, 203, 40},
+	"ograve":         {27, -11, 468, 664},
+	"ohungarumlaut":  {27, -11, 590, 664},
+	"omacron":        {27, -11, 495, 583},
+	"one":            {49, 0, 409, 676},
+	"onehalf":        {34, -10, 749, 676},
+	"onequarter":     {33, -10, 736, 676},
+	"onesuperior":    {43, 271, 284, 676},
+	"ordfeminine":    {42, 406, 352, 676},
+	"ordmasculine":   {67, 406, 362, 676},
+	"oslash":         {28, -135, 469, 554},
+	"otilde":         {27, -11, 496, 624},
+	"p":              {-75, -205, 469, 441},
+	"paragraph":      {55, -123, 616, 653},
+	"parenleft":      {42, -181, 315, 669},
+	"parenright":     {16, -180, 289, 669},
+	"partialdiff":    {17, -38, 459, 710},
+	"percent":        {79, -13, 790, 676},
+	"period":         {27, -11, 138, 100},
+	"periodcentered": {70, 199, 181, 310},
+	"perthousand":    {25, -19, 1010, 706},
+	"plus":           {86, 0, 590, 506},
+	"plusminus":      {86, 0, 590, 506},
+	"q":              {25, -209, 483, 441},
+	"question":       {132, -12, 472, 664},
+	"questiondown":   {28, -205, 368, 471},
+	"quotedbl":       {144, 421, 432, 666},
+	"quotedblbase":   {57, -129, 405, 101},
+	"quotedblleft":   {166, 436, 514, 666},
+	"quotedblright":  {151, 436, 499, 666},
+	"quoteleft":      {171, 436, 310, 666},
+	"quoteright":     {151, 436, 290, 666},
+	"quotesinglbase": {44, -129, 183, 101},
+	"quotesingle":    {132, 421, 241, 666},
+	"r":              {45, 0, 412, 441},
+	"racute":         {45, 0, 431, 664},
+	"radical":        {2, -60, 452, 768},
+	"rcaron":         {45, 0, 434, 661},
+	"rcommaaccent":   {-3, -217, 412, 441},
+	"registered":     {41, -18, 719, 666},
+	"ring":           {155, 492, 355, 691},
+	"s":              {16, -13, 366, 442},
+	"sacute":         {16, -13, 431, 664},
+	"scaron":         {16, -13, 454, 661},
+	"scedilla":       {16, -217, 366, 442},
+	"scommaaccent":   {16, -217, 366, 442},
+	"section":        {53, -162, 461, 666},
+	"semicolon":      {27, -129, 261, 441},
+	"seven":          {75, -8, 537, 666},
+	"six":            {30, -7, 521, 686},
+	"slash":          {-65, -18, 386, 666},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {10, -6, 517, 670},
+	"summation":      {15, -10, 585, 706},
+	"t":              {37, -11, 296, 546},
+	"tcaron":         {37, -11, 407, 681},
+	"tcommaaccent":   {2, -217, 296, 546},
+	"thorn":          {-75, -205, 469, 683},
+	"three":          {15, -7, 465, 676},
+	"threequarters":  {23, -10, 736, 676},
+	"threesuperior":  {43, 268, 339, 676},
+	"tilde":          {100, 517, 427, 624},
+	"trademark":      {30, 247, 957, 653},
+	"two":            {12, 0, 452, 676},
+	"twosuperior":    {33, 271, 324, 676},
+	"u":              {42, -11, 475, 441},
+	"uacute":         {42, -11, 477, 664},
+	"ucircumflex":    {42, -11, 475, 661},
+	"udieresis":      {42, -11, 479, 606},
+	"ugrave":         {42, -11, 475, 664},
+	"uhungarumlaut":  {42, -11, 580, 664},
+	"umacron":        {42, -11, 485, 583},
+	"underscore":     {0, -125, 500, -75},
+	"uogonek":        {42, -169, 477, 441},
+	"uring":          {42, -11, 475, 691},
+	"v":              {21, -18, 426, 441},
+	"w":              {16, -18, 648, 441},
+	"x":              {-27, -11, 447, 441},
+	"y":              {-24, -206, 426, 441},
+	"yacute":         {-24, -206, 459, 664},
+	"ydieresis":      {-24, -206, 441, 606},
+	"yen":            {27, 0, 603, 653},
+	"z":              {-2, -81, 380, 428},
+	"zacute":         {-2, -81, 431, 664},
+	"zcaron":         {-2, -81, 434, 661},
+	"zdotaccent":     {-2, -81, 380, 606},
+	"zero":           {32, -7, 497, 676},
+}
+
+// Times-Roman glyph bounding boxes loaded from afms/Times-Roman.afm.  See afms/MustRead.html for license information.
+var timesRomanGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"A":              {15, 0, 706, 674},
+	"AE":             {0, 0, 863, 662},
+	"Aacute":         {15, 0, 706, 890},
+	"Abreve":         {15, 0, 706, 876},
+	"Acircumflex":    {15, 0, 706, 886},
+	"Adieresis":      {15, 0, 706, 835},
+	"Agrave":         {15, 0, 706, 890},
+	"Amacron":        {15, 0, 706, 813},
+	"Aogonek":        {15, -165, 738, 674},
+	"Aring":          {15, 0, 706, 898},
+	"Atilde":         {15, 0, 706, 850},
+	"B":              {17, 0, 593, 662},
+	"C":              {28, -14, 633, 676},
+	"Cacute":         {28, -14, 633, 890},
+	"Ccaron":         {28, -14, 633, 886},
+	"Ccedilla":       {28, -215, 633, 676},
+	"D":              {16, 0, 685, 662},
+	"Dcaron":         {16, 0, 685, 886},
+	"Dcroat":         {16, 0, 685, 662},
+	"Delta":          {6, 0, 608, 688},
+	"E":              {12, 0, 597, 662},
+	"Eacute":         {12, 0, 597, 890},
+	"Ecaron":         {12, 0, 597, 886},
+	"Ecircumflex":    {12, 0, 597, 886},
+	"Edieresis":      {12, 0, 597, 835},
+	"Edotaccent":     {12, 0, 597, 835},
+	"Egrave":         {12, 0, 597, 890},
+	"Emacron":        {12, 0, 597, 813},
+	"Eogonek":        {12, -165, 597, 662},
+	"Eth":            {16, 0, 685, 662},
+	"Euro":           {0, 0, 0, 0},
+	"F":              {12, 0, 546, 662},
+	"G":              {32, -14, 709, 676},
+	"Gbreve":         {32, -14, 709, 876},
+	"Gcommaaccent":   {32, -218, 709, 676},
+	"H":              {19, 0, 702, 662},
+	"I":              {18, 0, 315, 662},
+	"Iacute":         {18, 0, 317, 890},
+	"Icircumflex":    {11, 0, 322, 886},
+	"Idieresis":      {18, 0, 315, 835},
+	"Idotaccent":     {18, 0, 315, 835},
+	"Igrave":         {18, 0, 315, 890},
+	"Imacron":        {11, 0, 322, 813},
+	"Iogonek":        {18, -165, 315, 662},
+	"J":              {10, -14, 370, 662},
+	"K":              {34, 0, 723, 662},
+	"Kcommaaccent":   {34, -198, 723, 662},
+	"L":              {12, 0, 598, 662},
+	"Lacute":         {12, 0, 598, 890},
+	"Lcaron":         {12, 0, 598, 676},
+	"Lcommaaccent":   {12, -218, 598, 662},
+	"Lslash":         {12, 0, 598, 662},
+	"M":              {12, 0, 863, 662},
+	"N":              {12, -11, 707, 662},
+	"Nacute":         {12, -11, 707, 890},
+	"Ncaron":         {12, -11, 707, 886},
+	"Ncommaaccent":   {12, -198, 707, 662},
+	"Ntilde":         {12, -11, 707, 850},
+	"O":              {34, -14, 688, 676},
+	"OE":             {30, -6, 885, 668},
+	"Oacute":         {34, -14, 688, 890},
+	"Ocircumflex":    {34, -14, 688, 886},
+	"Odieresis":      {34, -14, 688, 835},
+	"Ograve":         {34, -14, 688, 890},
+	"Ohungarumlaut":  {34, -14, 688, 890},
+	"Omacron":        {34, -14, 688, 813},
+	"Oslash":         {34, -80, 688, 734},
+	"Otilde":         {34, -14, 688, 850},
+	"P":              {16, 0, 542, 662},
+	"Q":              {34, -178, 701, 676},
+	"R":              {17, 0, 659, 662},
+	"Racute":         {17, 0, 659, 890},
+	"Rcaron":         {17, 0, 659, 886},
+	"Rcommaaccent":   {17, -198, 659, 662},
+	"S":              {42, -14, 491, 676},
+	"Sacute":         {42, -14, 491, 890},
+	"Scaron":         {42, -14, 491, 886},
+	"Scedilla":       {42, -215, 491, 676},
+	"Scommaaccent":   {42, -218, 491, 676},
+	"T":              {17, 0, 593, 662},
+	"Tcaron":         {17, 0, 593, 886},
+	"Tcommaaccent":   {17, -218, 593, 662},
+	"Thorn":          {16, 0, 542, 662},
+	"U":              {14, -14, 705, 662},
+	"Uacute":         {14, -14, 705, 890},
+	"Ucircumflex":    {14, -14, 705, 886},
+	"Udieresis":      {14, -14, 705, 835},
+	"Ugrave":         {14, -14, 705, 890},
+	"Uhungarumlaut":  {14, -14, 705, 890},
+	"Umacron":        {14, -14, 705, 813},
+	"Uogonek":        {14, -165, 705, 662},
+	"Uring":          {14, -14, 705, 898},
+	"V":              {16, -11, 697, 662},
+	"W":              {5, -11, 932, 662},
+	"X":              {10, 0, 704, 662},
+	"Y":              {22, 0, 703, 662},
+	"Yacute":         {22, 0, 703, 890},
+	"Ydieresis":      {22, 0, 703, 835},
+	"Z":              {9, 0, 597, 662},
+	"Zacute":         {9, 0, 597, 890},
+	"Zcaron":         {9, 0, 597, 886},
+	"Zdotaccent":     {9, 0, 597, 835},
+	"a":              {37, -10, 442, 460},
+	"aacute":         {37, -10, 442, 678},
+	"abreve":         {37, -10, 442, 664},
+	"acircumflex":    {37, -10, 442, 674},
+	"acute":          {93, 507, 317, 678},
+	"adieresis":      {37, -10, 442, 623},
+	"ae":             {38, -10, 632, 460},
+	"agrave":         {37, -10, 442, 678},
+	"amacron":        {37, -10, 442, 601},
+	"ampersand":      {42, -13, 750, 676},
+	"aogonek":        {37, -165, 469, 460},
+	"aring":          {37, -10, 442, 711},
+	"asciicircum":    {24, 297, 446, 662},
+	"asciitilde":     {40, 183, 502, 323},
+	"asterisk":       {69, 265, 432, 676},
+	"at":             {116, -14, 809, 676},
+	"atilde":         {37, -10, 442, 638},
+	"b":              {3, -10, 468, 683},
+	"backslash":      {-9, -14, 287, 676},
+	"bar":            {67, -218, 133, 782},
+	"braceleft":      {100, -181, 350, 680},
+	"braceright":     {130, -181, 380, 680},
+	"bracketleft":    {88, -156, 299, 662},
+	"bracketright":   {34, -156, 245, 662},
+	"breve":          {26, 507, 307, 664},
+	"brokenbar":      {67, -143, 133, 707},
+	"bullet":         {40, 196, 310, 466},
+	"c":              {25, -10, 412, 460},
+	"cacute":         {25, -10, 413, 678},
+	"caron":          {11, 507, 322, 674},
+	"ccaron":         {25, -10, 412, 674},
+	"ccedilla":       {25, -215, 412, 460},
+	"cedilla":        {52, -215, 261, 0},
+	"cent":           {53, -138, 448, 579},
+	"circumflex":     {11, 507, 322, 674},
+	"colon":          {81, -11, 192, 459},
+	"comma":          {56, -141, 195, 102},
+	"commaaccent":    {59, -218, 184, -50},
+	"copyright":      {38, -14, 722, 676},
+	"currency":       {-22, 58, 522, 602},
+	"d":              {27, -10, 491, 683},
+	"dagger":         {59, -149, 442, 676},
+	"daggerdbl":      {58, -153, 442, 676},
+	"dcaron":         {27, -10, 589, 695},
+	"dcroat":         {27, -10, 500, 683},
+	"degree":         {57, 390, 343, 676},
+	"dieresis":       {18, 581, 315, 681},
+	"divide":         {30, -10, 534, 516},
+	"dollar":         {44, -87, 457, 727},
+	"dotaccent":      {118, 581, 216, 681},
+	"dotlessi":       {16, 0, 253, 460},
+	"e":              {25, -10, 424, 460},
+	"eacute":         {25, -10, 424, 678},
+	"ecaron":         {25, -10, 424, 674},
+	"ecircumflex":    {25, -10, 424, 674},
+	"edieresis":      {25, -10, 424, 623},
+	"edotaccent":     {25, -10, 424, 623},
+	"egrave":         {25, -10, 424, 678},
+	"eight":          {56, -14, 445, 676},
+	"ellipsis":       {111, -11, 888, 100},
+	"emacron":        {25, -10, 424, 601},
+	"emdash":         {0, 201, 1000, 250},
+	"endash":         {0, 201, 500, 250},
+	"eogonek":        {25, -165, 424, 460},
+	"equal":          {30, 120, 534, 386},
+	"eth":            {29, -10, 471, 686},
+	"exclam":         {130, -9, 238, 676},
+	"exclamdown":     {97, -218, 205, 467},
+	"f":              {20, 0, 383, 683},
+	"fi":             {31, 0, 521, 683},
+	"five":           {32, -14, 438, 688},
+	"fl":             {32, 0, 521, 683},
+	"florin":         {7, -189, 490, 676},
+	"four":           {12, 0, 472, 676},
+	"fraction":       {-168, -14, 331, 676},
+	"g":              {28, -218, 470, 460},
+	"gbreve":         {28, -218, 470, 664},
+	"gcommaaccent":   {28, -218, 470, 749},
+	"germandbls":     {12, -9, 468, 683},
+	"grave":          {19, 507, 242, 678},
+	"greater":        {28, -8, 536, 514},
+	"greaterequal":   {26, 0, 523, 666},
+	"guillemotleft":  {42, 33, 456, 416},
+	"guillemotright": {44, 33, 458, 416},
+	"guilsinglleft":  {63, 33, 285, 416},
+	"guilsinglright": {48, 33, 270, 416},
+	"h":              {9, 0, 487, 683},
+	"hungarumlaut":   {-3, 507, 377, 678},
+	"hyphen":         {39, 194, 285, 257},
+	"i":              {16, 0, 253, 683},
+	"iacute":         {16, 0, 290, 678},
+	"icircumflex":    {-16, 0, 295, 674},
+	"idieresis":      {-9, 0, 288, 623},
+	"igrave":         {-8, 0, 253, 678},
+	"imacron":        {6, 0, 271, 601},
+	"iogonek":        {16, -165, 265, 683},
+	"j":              {-70, -218, 194, 683},
+	"k":              {7, 0, 505, 683},
+	"kcommaaccent":   {7, -218, 505, 683},
+	"l":              {19, 0, 257, 683},
+	"lacute":         {19, 0, 290, 890},
+	"lcaron":         {19, 0, 347, 695},
+	"lcommaaccent":   {19, -218, 257, 683},
+	"less":           {28, -8, 536, 514},
+	"lessequal":      {26, 0, 523, 666},
+	"logicalnot":     {30, 108, 534, 386},
+	"lozenge":        {13, 0, 459, 724},
+	"lslash":         {19, 0, 259, 683},
+	"m":              {16, 0, 775, 460},
+	"macron":         {11, 547, 322, 601},
+	"minus":          {30, 220, 534, 286},
+	"mu":             {36, -218, 512, 450},
+	"multiply":       {38, 8, 527, 497},
+	"n":              {16, 0, 485, 460},
+	"nacute":         {16, 0, 485, 678},
+	"ncaron":         {16, 0, 485, 674},
+	"ncommaaccent":   {16, -218, 485, 460},
+	"nine":           {30, -22, 459, 676},
+	"notequal":       {12, -31, 537, 547},
+	"ntilde":         {16, 0, 485, 638},
+	"numbersign":     {5, 0, 496, 662},
+	"o":              {29, -10, 470, 460},
+	"oacute":         {29, -10, 470, 678},
+	"ocircumflex":    {29, -10, 470, 674},
+	"odieresis":      {29, -10, 470, 623},
+	"oe":             {30, -10, 690, 460},
+	"ogonek":         {62, -165, 243, 0},
+	"ograve":         {29, -10, 470, 678},
+	"ohungarumlaut":  {29, -10, 491, 678},
+	"omacron":        {29, -10, 470, 601},
+	"one":            {111, 0, 394, 676},
+	"onehalf":        {31, -14, 746, 676},
+	"onequarter":     {37, -14, 718, 676},
+	"onesuperior":    {57, 270, 248, 676},
+	"ordfeminine":    {4, 394, 270, 676},
+	"ordmasculine":   {6, 394, 304, 676},
+	"oslash":         {29, -112, 470, 551},
+	"otilde":         {29, -10, 470, 638},
+	"p":              {5, -217, 470, 460},
+	"paragraph":      {-22, -154, 450, 662},
+	"parenleft":      {48, -177, 304, 676},
+	"parenright":     {29, -177, 285, 676},
+	"partialdiff":    {17, -38, 459, 710},
+	"percent":        {61, -13, 772, 676},
+	"period":         {70, -11, 181, 100},
+	"periodcentered": {70, 199, 181, 310},
+	"perthousand":    {7, -19, 994, 706},
+	"plus":           {30, 0, 534, 506},
+	"plusminus":      {30, 0, 534, 506},
+	"q":              {24, -217, 488, 460},
+	"question":       {68, -8, 414, 676},
+	"questiondown":   {30, -218, 376, 466},
+	"quotedbl":       {77, 431, 331, 676},
+	"quotedblbase":   {45, -141, 416, 102},
+	"quotedblleft":   {43, 433, 414, 676},
+	"quotedblright":  {30, 433, 401, 676},
+	"quoteleft":      {115, 433, 254, 676},
+	"quoteright":     {79, 433, 218, 676},
+	"quotesinglbase": {79, -141, 218, 102},
+	"quotesingle":    {48, 431, 133, 676},
+	"r":              {5, 0, 335, 460},
+	"racute":         {5, 0, 335, 678},
+	"radical":        {2, -60, 452, 768},
+	"rcaron":         {5, 0, 335, 674},
+	"rcommaaccent":   {5, -218, 335, 460},
+	"registered":     {38, -14, 722, 676},
+	"ring":           {67, 512, 266, 711},
+	"s":              {51, -10, 348, 460},
+	"sacute":         {51, -10, 348, 678},
+	"scaron":         {39, -10, 350, 674},
+	"scedilla":       {51, -215, 348, 460},
+	"scommaaccent":   {51, -218, 348, 460},
+	"section":        {70, -148, 426, 676},
+	"semicolon":      {80, -141, 219, 459},
+	"seven":          {20, -8, 449, 662},
+	"six":            {34, -14, 468, 684},
+	"slash":          {-9, -14, 287, 676},
+	"space":          {0, 0, 0, 0},
+	"sterling":       {12, -8, 490, 676},
+	"summation":      {15, -10, 585, 706},
+	"t":              {13, -10, 279, 579},
+	"tcaron":         {13, -10, 318, 722},
+	"tcommaaccent":   {13, -218, 279, 579},
+	"thorn":          {5, -217, 470, 683},
+	"three":          {43, -14, 431, 676},
+	"threequarters":  {15, -14, 718, 676},
+	"threesuperior":  {15, 262, 291, 676},
+	"tilde":          {1, 532, 331, 638},
+	"trademark":      {30, 256, 957, 662},
+	"two":            {30, 0, 475, 676},
+	"twosuperior":    {1, 270, 296, 676},
+	"u":              {9, -10, 479, 450},
+	"uacute":         {9, -10, 479, 678},
+	"ucircumflex":    {9, -10, 479, 674},
+	"udieresis":      {9, -10, 479, 623},
+	"ugrave":         {9, -10, 479, 678},
+	"uhungarumlaut":  {9, -10, 501, 678},
+	"umacron":        {9, -10, 479, 601},
+	"underscore":     {0, -125, 500, -75},
+	"uogonek":        {9, -155, 487, 450},
+	"uring":          {9, -10, 479, 711},
+	"v":              {19, -14, 477, 450},
+	"w":              {21, -14, 694, 450},
+	"x":              {17, 0, 479, 450},
+	"y":              {14, -218, 475, 450},
+	"yacute":         {14, -218, 475, 678},
+	"ydieresis":      {14, -218, 475, 623},
+	"yen":            {-53, 0, 512, 662},
+	"z":              {27, 0, 418, 450},
+	"zacute":         {27, 0, 418, 678},
+	"zcaron":         {27, 0, 418, 674},
+	"zdotaccent":     {27, 0, 418, 623},
+	"zero":           {24, -14, 476, 676},
+}
+
+// ZapfDingbats glyph bounding boxes loaded from afms/ZapfDingbats.afm.  See afms/MustRead.html for license information.
+var zapfDingbatsGlyphBBoxes map[string][4]float64 = map[string][4]float64{
+	"a1":    {35, 72, 939, 621},
+	"a10":   {35, -14, 657, 705},
+	"a100":  {36, 263, 634, 705},
+	"a101":  {35, -143, 697, 806},
+	"a102":  {56, -14, 488, 706},
+	"a103":  {34, -14, 508, 705},
+	"a104":  {35, 40, 875, 651},
+	"a105":  {35, 50, 876, 642},
+	"a106":  {35, -14, 633, 705},
+	"a107":  {35, -14, 726, 705},
+	"a108":  {0, 121, 758, 569},
+	"a109":  {34, 0, 591, 705},
+	"a11":   {35, 123, 925, 568},
+	"a110":  {35, -14, 659, 705},
+	"a111":  {34, -14, 560, 705},
+	"a112":  {35, 0, 741, 705},
+	"a117":  {34, 138, 655, 553},
+	"a118":  {35, -13, 761, 705},
+	"a119":  {35, -14, 755, 705},
+	"a12":   {35, 134, 904, 559},
+	"a120":  {35, -14, 754, 705},
+	"a121":  {35, -14, 754, 705},
+	"a122":  {35, -14, 754, 705},
+	"a123":  {35, -14, 754, 705},
+	"a124":  {35, -14, 754, 705},
+	"a125":  {35, -14, 754, 705},
+	"a126":  {35, -14, 754, 705},
+	"a127":  {35, -14, 754, 705},
+	"a128":  {35, -14, 754, 705},
+	"a129":  {35, -14, 754, 705},
+	"a13":   {29, -11, 516, 705},
+	"a130":  {35, -14, 754, 705},
+	"a131":  {35, -14, 754, 705},
+	"a132":  {35, -14, 754, 705},
+	"a133":  {35, -14, 754, 705},
+	"a134":  {35, -14, 754, 705},
+	"a135":  {35, -14, 754, 705},
+	"a136":  {35, -14, 754, 705},
+	"a137":  {35, -14, 754, 705},
+	"a138":  {35, -14, 754, 705},
+	"a139":  {35, -14, 754, 705},
+	"a14":   {34, 59, 820, 632},
+	"a140":  {35, -14, 754, 705},
+	"a141":  {35, -14, 754, 705},
+	"a142":  {35, -14, 754, 705},
+	"a143":  {35, -14, 754, 705},
+	"a144":  {35, -14, 754, 705},
+	"a145":  {35, -14, 754, 705},
+	"a146":  {35, -14, 754, 705},
+	"a147":  {35, -14, 754, 705},
+	"a148":  {35, -14, 754, 705},
+	"a149":  {35, -14, 754, 705},
+	"a15":   {35, 50, 876, 642},
+	"a150":  {35, -14, 754, 705},
+	"a151":  {35, -14, 754, 705},
+	"a152":  {35, -14, 754, 705},
+	"a153":  {35, -14, 754, 705},
+	"a154":  {35, -14, 754, 705},
+	"a155":  {35, -14, 754, 705},
+	"a156":  {35, -14, 754, 705},
+	"a157":  {35, -14, 754, 705},
+	"a158":  {35, -14, 754, 705},
+	"a159":  {35, -14, 754, 705},
+	"a16":   {35, 139, 899, 550},
+	"a160":  {35, 58, 860, 634},
+	"a161":  {35, 152, 803, 540},
+	"a162":  {35, 98, 889, 594},
+	"a163":  {34, 152, 981, 540},
+	"a164":  {35, -127, 422, 820},
+	"a165":  {35, 140, 890, 552},
+	"a166":  {35, 166, 884, 526},
+	"a167":  {35, 32, 892, 660},
+	"a168":  {35, 129, 891, 562},
+	"a169":  {35, 128, 893, 563},
+	"a17":   {35, 139, 909, 553},
+	"a170":  {35, 155, 799, 537},
+	"a171":  {35, 93, 838, 599},
+	"a172":  {35, 104, 791, 588},
+	"a173":  {35, 98, 889, 594},
+	"a174":  {35, 0, 882, 692},
+	"a175":  {35, 84, 896, 608},
+	"a176":  {35, 84, 896, 608},
+	"a177":  {35, -99, 429, 791},
+	"a178":  {35, 71, 848, 623},
+	"a179":  {35, 44, 802, 648},
+	"a18":   {35, 104, 938, 587},
+	"a180":  {35, 101, 832, 591},
+	"a181":  {35, 44, 661, 648},
+	"a182":  {35, 77, 840, 619},
+	"a183":  {35, 0, 725, 692},
+	"a184":  {35, 160, 911, 533},
+	"a185":  {35, 207, 830, 481},
+	"a186":  {35, 124, 932, 568},
+	"a187":  {35, 113, 796, 579},
+	"a188":  {36, 118, 838, 578},
+	"a189":  {35, 150, 891, 542},
+	"a19":   {34, -13, 721, 705},
+	"a190":  {35, 76, 931, 616},
+	"a191":  {34, 99, 884, 593},
+	"a192":  {35, 94, 698, 597},
+	"a193":  {35, 44, 802, 648},
+	"a194":  {34, 37, 736, 655},
+	"a195":  {34, -19, 853, 712},
+	"a196":  {35, 94, 698, 597},
+	"a197":  {34, 37, 736, 655},
+	"a198":  {34, -19, 853, 712},
+	"a199":  {35, 101, 832, 591},
+	"a2":    {35, 81, 927, 611},
+	"a20":   {36, -14, 811, 705},
+	"a200":  {35, 44, 661, 648},
+	"a201":  {35, 73, 840, 615},
+	"a202":  {35, 72, 939, 621},
+	"a203":  {35, 0, 727, 692},
+	"a204":  {35, 0, 725, 692},
+	"a205":  {35, 0, 475, 692},
+	"a206":  {35, 0, 375, 692},
+	"a21":   {35, 0, 727, 692},
+	"a22":   {35, 0, 727, 692},
+	"a23":   {-1, -68, 571, 661},
+	"a24":   {36, -13, 642, 705},
+	"a25":   {35, 0, 728, 692},
+	"a26":   {35, 0, 726, 692},
+	"a27":   {35, 0, 725, 692},
+	"a28":   {35, 0, 720, 692},
+	"a29":   {35, -14, 751, 705},
+	"a3":    {35, 0, 945, 692},
+	"a30":   {35, -14, 752, 705},
+	"a31":   {35, -14, 753, 705},
+	"a32":   {35, -14, 756, 705},
+	"a33":   {35, -13, 759, 705},
+	"a34":   {35, -13, 759, 705},
+	"a35":   {35, -14, 782, 705},
+	"a36":   {35, -14, 787, 705},
+	"a37":   {35, -14, 754, 705},
+	"a38":   {35, -14, 807, 705},
+	"a39":   {35, -14, 789, 705},
+	"a4":    {34, 139, 685, 566},
+	"a40":   {35, -14, 798, 705},
+	"a41":   {35, -13, 782, 705},
+	"a42":   {35, -14, 796, 705},
+	"a43":   {35, -14, 888, 705},
+	"a44":   {35, 0, 710, 692},
+	"a45":   {35, 0, 688, 692},
+	"a46":   {35, 0, 714, 692},
+	"a47":   {34, -14, 756, 705},
+	"a48":   {35, -14, 758, 705},
+	"a49":   {35, -14, 661, 706},
+	"a5":    {35, -14, 755, 705},
+	"a50":   {35, -6, 741, 699},
+	"a51":   {35, -7, 734, 699},
+	"a52":   {35, -14, 757, 705},
+	"a53":   {35, 0, 725, 692},
+	"a54":   {35, -13, 672, 704},
+	"a55":   {35, -14, 672, 705},
+	"a56":   {35, -14, 647, 705},
+	"a57":   {35, -14, 666, 705},
+	"a58":   {35, -14, 791, 705},
+	"a59":   {35, -14, 780, 705},
+	"a6":    {35, 0, 460, 692},
+	"a60":   {35, -14, 754, 705},
+	"a61":   {35, -14, 754, 705},
+	"a62":   {34, -14, 673, 705},
+	"a63":   {36, 0, 651, 692},
+	"a64":   {35, 0, 661, 691},
+	"a65":   {35, 0, 655, 692},
+	"a66":   {34, -14, 751, 705},
+	"a67":   {35, -14, 752, 705},
+	"a68":   {35, -14, 678, 705},
+	"a69":   {35, -14, 756, 705},
+	"a7":    {35, 0, 517, 692},
+	"a70":   {36, -14, 751, 705},
+	"a71":   {35, -14, 757, 705},
+	"a72":   {35, -14, 838, 705},
+	"a73":   {35, 0, 726, 692},
+	"a74":   {35, 0, 727, 692},
+	"a75":   {35, 0, 725, 692},
+	"a76":   {35, 0, 858, 705},
+	"a77":   {35, -14, 858, 692},
+	"a78":   {35, -14, 754, 705},
+	"a79":   {35, -14, 749, 705},
+	"a8":    {35, 0, 503, 692},
+	"a81":   {35, -14, 403, 705},
+	"a82":   {35, 0, 104, 692},
+	"a83":   {35, 0, 242, 692},
+	"a84":   {35, 0, 380, 692},
+	"a85":   {35, 0, 475, 692},
+	"a86":   {35, 0, 375, 692},
+	"a87":   {35, -14, 199, 705},
+	"a88":   {35, -14, 199, 705},
+	"a89":   {35, -14, 356, 705},
+	"a9":    {35, 96, 542, 596},
+	"a90":   {35, -14, 355, 705},
+	"a91":   {35, 0, 242, 692},
+	"a92":   {35, 0, 242, 692},
+	"a93":   {35, 0, 283, 692},
+	"a94":   {35, 0, 283, 692},
+	"a95":   {35, 0, 299, 692},
+	"a96":   {35, 0, 299, 692},
+	"a97":   {35, 263, 357, 705},
+	"a98":   {34, 263, 357, 705},
+	"a99":   {35, 263, 633, 705},
+	"space": {0, 0, 0, 0},
+}
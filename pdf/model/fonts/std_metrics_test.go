@@ -0,0 +1,64 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStdFontMetrics(t *testing.T) {
+	if names := GetStdFontNames(); len(names) != 14 {
+		t.Errorf("Wrong standard font names %v", names)
+	}
+	if _, has := GetStdFontMetrics("Arial"); has {
+		t.Errorf("Arial is not a standard font")
+	}
+
+	helvetica, has := GetStdFontMetrics("Helvetica")
+	if !has {
+		t.Fatalf("Missing Helvetica")
+	}
+	if helvetica.CapHeight != 718 || helvetica.FontBBox != [4]float64{-166, -225, 1000, 931} {
+		t.Errorf("Wrong font metrics %+v", *helvetica)
+	}
+	if bbox, has := helvetica.GetGlyphBBox("A"); !has || bbox != [4]float64{14, 0, 654, 718} {
+		t.Errorf("Wrong bbox of A: %v", bbox)
+	}
+	if kern := helvetica.GetKerning("A", "V"); kern != -70 {
+		t.Errorf("Wrong kerning of AV: %v", kern)
+	}
+
+	// 667 + 667 - 70 kerned, at 10 points; the rune without glyph is skipped.
+	for _, tc := range []struct {
+		text     string
+		kerning  bool
+		expected float64
+	}{
+		{"AV", false, 13.34},
+		{"AV", true, 12.64},
+		{"A中V", true, 12.64},
+	} {
+		if width := helvetica.GetStringWidth(tc.text, 10, tc.kerning); math.Abs(width-tc.expected) > 1e-9 {
+			t.Errorf("Width of %q (kerning %t): %v, expected %v", tc.text, tc.kerning, width, tc.expected)
+		}
+	}
+
+	// The oblique face shares the kerning of the upright face.
+	oblique, _ := GetStdFontMetrics("Helvetica-Oblique")
+	if kern := oblique.GetKerning("A", "V"); kern != -70 {
+		t.Errorf("Wrong oblique kerning of AV: %v", kern)
+	}
+
+	// Symbol has its own encoding.
+	symbol, _ := GetStdFontMetrics("Symbol")
+	if glyph, found := symbol.RuneToGlyph('α'); !found || glyph != "alpha" {
+		t.Errorf("Wrong Symbol glyph %q", glyph)
+	}
+	if width := symbol.GetStringWidth("α", 1000, true); width != 631 {
+		t.Errorf("Wrong Symbol width %v", width)
+	}
+}
@@ -25,14 +25,35 @@ package fonts
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
 )
 
+// EmbeddingPermissions are the embedding licensing rights of a font, the fsType field of its OS/2 table.
+type EmbeddingPermissions uint16
+
+// Embedding permission bits (see the OpenType specification, OS/2 table).  Fonts without bits set are installable.
+const (
+	EmbeddingRestricted   EmbeddingPermissions = 0x0002 // Must not be embedded, unless a bit below is also set.
+	EmbeddingPreviewPrint EmbeddingPermissions = 0x0004 // Embedded documents can only be viewed and printed.
+	EmbeddingEditable     EmbeddingPermissions = 0x0008 // Embedded documents can be edited.
+	EmbeddingNoSubsetting EmbeddingPermissions = 0x0100 // Must be embedded in full, not subset.
+	EmbeddingBitmapOnly   EmbeddingPermissions = 0x0200 // Only the bitmaps of the font can be embedded.
+)
+
+// Embeddable returns true if the license of the font allows embedding its outlines, i.e. the font is not
+// restricted (without preview & print or editable permission) nor limited to bitmap embedding.
+func (perms EmbeddingPermissions) Embeddable() bool {
+	restricted := perms&EmbeddingRestricted != 0 && perms&(EmbeddingPreviewPrint|EmbeddingEditable) == 0
+	return !restricted && perms&EmbeddingBitmapOnly == 0
+}
+
 // TtfType contains metrics of a TrueType font.
 type TtfType struct {
 	Embeddable             bool
+	Permissions            EmbeddingPermissions
 	UnitsPerEm             uint16
 	PostScriptName         string
 	Bold                   bool
@@ -50,7 +71,7 @@ type TtfType struct {
 
 type ttfParser struct {
 	rec              TtfType
-	f                *os.File
+	f                io.ReadSeeker
 	tables           map[string]uint32
 	numberOfHMetrics uint16
 	numGlyphs        uint16
@@ -58,11 +79,19 @@ type ttfParser struct {
 
 // TtfParse extracts various metrics from a TrueType font file.
 func TtfParse(fileStr string) (TtfRec TtfType, err error) {
-	var t ttfParser
-	t.f, err = os.Open(fileStr)
+	f, err := os.Open(fileStr)
 	if err != nil {
 		return
 	}
+	defer f.Close()
+	return TtfParseReader(f)
+}
+
+// TtfParseReader extracts various metrics from a TrueType font program, e.g. the contents of an embedded
+// FontFile2 stream.
+func TtfParseReader(r io.ReadSeeker) (TtfRec TtfType, err error) {
+	var t ttfParser
+	t.f = r
 	version, err := t.ReadStr(4)
 	if err != nil {
 		return
@@ -93,7 +122,6 @@ func TtfParse(fileStr string) (TtfRec TtfType, err error) {
 	if err != nil {
 		return
 	}
-	t.f.Close()
 	TtfRec = t.rec
 	return
 }
@@ -299,8 +327,8 @@ func (t *ttfParser) ParseOS2() (err error) {
 	if err == nil {
 		version := t.ReadUShort()
 		t.Skip(3 * 2) // xAvgCharWidth, usWeightClass, usWidthClass
-		fsType := t.ReadUShort()
-		t.rec.Embeddable = (fsType != 2) && (fsType&0x200) == 0
+		t.rec.Permissions = EmbeddingPermissions(t.ReadUShort())
+		t.rec.Embeddable = t.rec.Permissions.Embeddable()
 		t.Skip(11*2 + 10 + 4*4 + 4)
 		fsSelection := t.ReadUShort()
 		t.rec.Bold = (fsSelection & 32) != 0
@@ -0,0 +1,734 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+// Parsing of Type 1 font programs (Adobe Type 1 Font Format), and their conversion to CFF.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+var (
+	t1FontNameRe    = regexp.MustCompile(`/FontName\s*/([^\s/\[\]{}()<>]+)`)
+	t1StringRe      = regexp.MustCompile(`/(FullName|FamilyName|Weight|Notice|version|Copyright)\s*\(((?:[^()\\]|\\.)*)\)`)
+	t1NumberRe      = regexp.MustCompile(`/(ItalicAngle|UnderlinePosition|UnderlineThickness|BlueScale|BlueShift|BlueFuzz|LanguageGroup|ExpansionFactor|lenIV)\s+(-?[0-9.]+(?:[eE]-?[0-9]+)?)\s`)
+	t1BoolRe        = regexp.MustCompile(`/(isFixedPitch|ForceBold)\s+(true|false)`)
+	t1ArrayRe       = regexp.MustCompile(`/(FontMatrix|FontBBox|BlueValues|OtherBlues|FamilyBlues|FamilyOtherBlues|StdHW|StdVW|StemSnapH|StemSnapV)\s*[\[{]([^\]}]*)[\]}]`)
+	t1EncodingRe    = regexp.MustCompile(`/Encoding\s+StandardEncoding`)
+	t1EncodingDupRe = regexp.MustCompile(`dup\s+([0-9]+)\s*/([^\s/\[\]{}()<>]+)\s+put`)
+)
+
+// type1Font is a parsed Type 1 font program.
+type type1Font struct {
+	fontName string
+
+	// The strings, numbers, booleans and arrays of the font dictionary, its FontInfo and Private dictionaries.
+	strings  map[string]string
+	numbers  map[string]float64
+	booleans map[string]bool
+	arrays   map[string][]float64
+
+	// The glyph names of the codes, or nil for the Standard Encoding.
+	encoding map[int]string
+
+	// The decrypted subroutines and charstrings, with the names of the glyphs in order.
+	subrs       [][]byte
+	charStrings map[string][]byte
+	glyphs      []string
+}
+
+// Keys of the eexec and charstring encryption.
+const (
+	t1EexecKey      = 55665
+	t1CharstringKey = 4330
+)
+
+// t1Decrypt decrypts the data encrypted with the key, without the n leading random bytes.
+func t1Decrypt(data []byte, key uint16, n int) []byte {
+	out := make([]byte, len(data))
+	r := key
+	for i, c := range data {
+		out[i] = c ^ byte(r>>8)
+		r = (uint16(c)+r)*52845 + 22719
+	}
+	if n > len(out) {
+		return nil
+	}
+	return out[n:]
+}
+
+// parseType1 parses the Type 1 font program, in the PFA (hexadecimal or binary eexec section) or PFB form.
+func parseType1(data []byte) (*type1Font, error) {
+	if len(data) > 0 && data[0] == 0x80 {
+		var err error
+		data, err = pfbSegments(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	eexec := bytes.Index(data, []byte("eexec"))
+	if eexec < 0 {
+		return nil, errors.New("Missing eexec section")
+	}
+	clear := data[:eexec]
+	encrypted := bytes.TrimLeft(data[eexec+len("eexec"):], " \t\r\n")
+	if len(encrypted) >= 4 && isHex(encrypted[:4]) {
+		// Hexadecimal form, up to the trailer.
+		var digits []byte
+		for _, c := range encrypted {
+			if isHex([]byte{c}) {
+				digits = append(digits, c)
+			} else if !isSpace(c) {
+				break
+			}
+		}
+		if len(digits)%2 == 1 {
+			digits = digits[:len(digits)-1]
+		}
+		decoded := make([]byte, len(digits)/2)
+		hex.Decode(decoded, digits)
+		encrypted = decoded
+	}
+	private := t1Decrypt(encrypted, t1EexecKey, 4)
+
+	font := &type1Font{
+		strings:     map[string]string{},
+		numbers:     map[string]float64{},
+		booleans:    map[string]bool{},
+		arrays:      map[string][]float64{},
+		charStrings: map[string][]byte{},
+	}
+	if m := t1FontNameRe.FindSubmatch(clear); m != nil {
+		font.fontName = string(m[1])
+	}
+
+	// The dictionary entries of the private part are before its binary data.
+	privateText := private
+	if i := bytes.Index(private, []byte("/Subrs")); i >= 0 {
+		privateText = private[:i]
+	} else if i := bytes.Index(private, []byte("/CharStrings")); i >= 0 {
+		privateText = private[:i]
+	}
+	for _, text := range [][]byte{clear, privateText} {
+		font.parseEntries(text)
+	}
+	if !t1EncodingRe.Match(clear) {
+		if i := bytes.Index(clear, []byte("/Encoding")); i >= 0 {
+			font.encoding = map[int]string{}
+			for _, m := range t1EncodingDupRe.FindAllSubmatch(clear[i:], -1) {
+				code, err := strconv.Atoi(string(m[1]))
+				if err == nil && code < 256 {
+					font.encoding[code] = string(m[2])
+				}
+			}
+		}
+	}
+
+	lenIV := 4
+	if v, has := font.numbers["lenIV"]; has {
+		lenIV = int(v)
+	}
+	decrypt := func(cs []byte) []byte {
+		if lenIV < 0 {
+			return cs
+		}
+		return t1Decrypt(cs, t1CharstringKey, lenIV)
+	}
+
+	s := &t1Scanner{data: private}
+	if i := bytes.Index(private, []byte("/Subrs")); i >= 0 {
+		s.pos = i + len("/Subrs")
+		count, _ := s.int()
+		font.subrs = make([][]byte, count)
+		s.token() // array
+		for {
+			tok := s.token()
+			if tok != "dup" {
+				if tok == "NP" || tok == "|" || tok == "noaccess" || tok == "put" || tok == "readonly" {
+					continue
+				}
+				break
+			}
+			index, ok1 := s.int()
+			n, ok2 := s.int()
+			s.token() // RD or -|
+			cs, ok3 := s.binary(n)
+			if !ok1 || !ok2 || !ok3 {
+				return nil, errors.New("Invalid Subrs")
+			}
+			if index >= 0 && index < count {
+				font.subrs[index] = decrypt(cs)
+			}
+		}
+	}
+
+	i := bytes.Index(private[s.pos:], []byte("/CharStrings"))
+	if i < 0 {
+		return nil, errors.New("Missing CharStrings")
+	}
+	s.pos += i + len("/CharStrings")
+	for {
+		tok := s.token()
+		if tok == "" || tok == "end" {
+			break
+		}
+		if !strings.HasPrefix(tok, "/") {
+			continue
+		}
+		name := tok[1:]
+		n, ok := s.int()
+		if !ok {
+			// The dictionary entries before the charstrings, e.g. /.notdef in the declaration of the dictionary.
+			continue
+		}
+		s.token() // RD or -|
+		cs, ok := s.binary(n)
+		if !ok {
+			return nil, errors.New("Invalid CharStrings")
+		}
+		if _, has := font.charStrings[name]; !has {
+			font.glyphs = append(font.glyphs, name)
+		}
+		font.charStrings[name] = decrypt(cs)
+	}
+	if len(font.charStrings) == 0 {
+		return nil, errors.New("No glyphs")
+	}
+	return font, nil
+}
+
+// parseEntries parses the dictionary entries of the text.
+func (font *type1Font) parseEntries(text []byte) {
+	for _, m := range t1StringRe.FindAllSubmatch(text, -1) {
+		font.strings[string(m[1])] = unescapePSString(string(m[2]))
+	}
+	for _, m := range t1NumberRe.FindAllSubmatch(text, -1) {
+		if v, err := strconv.ParseFloat(string(m[2]), 64); err == nil {
+			font.numbers[string(m[1])] = v
+		}
+	}
+	for _, m := range t1BoolRe.FindAllSubmatch(text, -1) {
+		font.booleans[string(m[1])] = string(m[2]) == "true"
+	}
+	for _, m := range t1ArrayRe.FindAllSubmatch(text, -1) {
+		var values []float64
+		for _, field := range strings.Fields(string(m[2])) {
+			if v, err := strconv.ParseFloat(field, 64); err == nil {
+				values = append(values, v)
+			}
+		}
+		font.arrays[string(m[1])] = values
+	}
+}
+
+// pfbSegments returns the data of the ASCII and binary segments of the PFB font program.
+func pfbSegments(data []byte) ([]byte, error) {
+	var out []byte
+	for len(data) >= 2 && data[0] == 0x80 {
+		if data[1] == 3 {
+			return out, nil
+		}
+		if len(data) < 6 {
+			break
+		}
+		n := int(binary.LittleEndian.Uint32(data[2:]))
+		if n < 0 || 6+n > len(data) {
+			return nil, errors.New("Invalid PFB segment")
+		}
+		out = append(out, data[6:6+n]...)
+		data = data[6+n:]
+	}
+	return out, nil
+}
+
+func isHex(data []byte) bool {
+	for _, c := range data {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+// unescapePSString unescapes the PostScript string literal.
+func unescapePSString(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// t1Scanner scans the tokens of the private part of Type 1 font programs.
+type t1Scanner struct {
+	data []byte
+	pos  int
+}
+
+// token returns the next token: a name (with its slash) or a sequence of regular characters, or a delimiter.
+func (s *t1Scanner) token() string {
+	for s.pos < len(s.data) && isSpace(s.data[s.pos]) {
+		s.pos++
+	}
+	if s.pos >= len(s.data) {
+		return ""
+	}
+	start := s.pos
+	if strings.IndexByte("[]{}()<>", s.data[s.pos]) >= 0 {
+		s.pos++
+		return string(s.data[start:s.pos])
+	}
+	s.pos++
+	for s.pos < len(s.data) && !isSpace(s.data[s.pos]) && strings.IndexByte("/[]{}()<>", s.data[s.pos]) < 0 {
+		s.pos++
+	}
+	return string(s.data[start:s.pos])
+}
+
+// int returns the next token as an integer, without consuming it if it is not.
+func (s *t1Scanner) int() (int, bool) {
+	pos := s.pos
+	v, err := strconv.Atoi(s.token())
+	if err != nil {
+		s.pos = pos
+		return 0, false
+	}
+	return v, true
+}
+
+// binary returns the n bytes following the single space after the current token.
+func (s *t1Scanner) binary(n int) ([]byte, bool) {
+	start := s.pos + 1
+	if n < 0 || start+n > len(s.data) {
+		return nil, false
+	}
+	s.pos = start + n
+	return s.data[start:s.pos], true
+}
+
+// type1Interpreter interprets the Type 1 charstrings of a font into outlines.
+type type1Interpreter struct {
+	font *type1Font
+
+	stack   []float64
+	psStack []float64
+	x, y    float64
+	sbx     float64
+	depth   int
+	outline *glyphOutline
+
+	// The start point and the points of the flex in progress, the first being the reference point.
+	flex      bool
+	flexStart glyphPoint
+	flexPts   []glyphPoint
+
+	// Whether the glyph is a component of an accented character, offset by dx, dy, without its metrics.
+	component bool
+	dx, dy    float64
+}
+
+// outline interprets the charstring of the glyph.
+func (font *type1Font) outline(name string) (*glyphOutline, error) {
+	cs, has := font.charStrings[name]
+	if !has {
+		return nil, errors.New("Glyph not found")
+	}
+	t := &type1Interpreter{font: font, outline: &glyphOutline{}}
+	if err := t.run(cs); err != nil && err != errEndChar {
+		// The outline drawn before the error, with the metrics.
+		return t.outline, err
+	}
+	return t.outline, nil
+}
+
+func (t *type1Interpreter) moveTo(x, y float64) {
+	t.x, t.y = x, y
+	t.outline.segments = append(t.outline.segments, glyphSegment{op: 'M', pts: []glyphPoint{{x + t.dx, y + t.dy}}})
+}
+
+func (t *type1Interpreter) lineTo(x, y float64) {
+	t.x, t.y = x, y
+	t.outline.segments = append(t.outline.segments, glyphSegment{op: 'L', pts: []glyphPoint{{x + t.dx, y + t.dy}}})
+}
+
+func (t *type1Interpreter) curveTo(dxa, dya, dxb, dyb, dxc, dyc float64) {
+	x1, y1 := t.x+dxa, t.y+dya
+	x2, y2 := x1+dxb, y1+dyb
+	t.x, t.y = x2+dxc, y2+dyc
+	t.outline.segments = append(t.outline.segments, glyphSegment{op: 'C', pts: []glyphPoint{
+		{x1 + t.dx, y1 + t.dy}, {x2 + t.dx, y2 + t.dy}, {t.x + t.dx, t.y + t.dy},
+	}})
+}
+
+// pop pops n operands, in the order they were pushed.
+func (t *type1Interpreter) pop(n int) ([]float64, error) {
+	if len(t.stack) < n {
+		return nil, errors.New("Charstring stack underflow")
+	}
+	args := append([]float64(nil), t.stack[len(t.stack)-n:]...)
+	t.stack = t.stack[:len(t.stack)-n]
+	return args, nil
+}
+
+// run interprets the charstring.
+func (t *type1Interpreter) run(code []byte) error {
+	t.depth++
+	defer func() { t.depth-- }()
+	if t.depth > type2MaxSubrDepth+1 {
+		return errors.New("Subroutines nested too deep")
+	}
+
+	for i := 0; i < len(code); {
+		v := int(code[i])
+		switch {
+		case v >= 32 && v <= 246:
+			t.stack = append(t.stack, float64(v-139))
+			i++
+			continue
+		case v >= 247 && v <= 254:
+			if i+2 > len(code) {
+				return errors.New("Truncated charstring")
+			}
+			if v <= 250 {
+				t.stack = append(t.stack, float64((v-247)*256+int(code[i+1])+108))
+			} else {
+				t.stack = append(t.stack, float64(-(v-251)*256-int(code[i+1])-108))
+			}
+			i += 2
+			continue
+		case v == 255:
+			if i+5 > len(code) {
+				return errors.New("Truncated charstring")
+			}
+			t.stack = append(t.stack, float64(int32(binary.BigEndian.Uint32(code[i+1:]))))
+			i += 5
+			continue
+		}
+
+		i++
+		if v == 12 {
+			if i >= len(code) {
+				return errors.New("Truncated charstring")
+			}
+			v = 1200 + int(code[i])
+			i++
+		}
+		if err := t.operator(v); err != nil {
+			if err == errReturn {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// operator interprets the operator, the two byte operators (12 x) being 1200+x.
+func (t *type1Interpreter) operator(op int) error {
+	clear := true
+	defer func() {
+		if clear {
+			t.stack = t.stack[:0]
+		}
+	}()
+	need := map[int]int{
+		1: 2, 3: 2, 4: 1, 5: 2, 6: 1, 7: 1, 8: 6, 9: 0, 10: 1, 11: 0, 13: 2, 14: 0, 21: 2, 22: 1, 30: 4, 31: 4,
+		1200: 0, 1201: 6, 1202: 6, 1206: 5, 1207: 4, 1212: 2, 1216: 2, 1217: 0, 1233: 2,
+	}
+	n, known := need[op]
+	if !known {
+		common.Log.Debug("ERROR: Invalid Type 1 charstring operator %d", op)
+		return errors.New("Invalid charstring operator")
+	}
+	if len(t.stack) < n {
+		return errors.New("Charstring stack underflow")
+	}
+	a := t.stack[len(t.stack)-n:]
+
+	switch op {
+	case 13: // hsbw
+		t.sbx = a[0]
+		if !t.component {
+			t.outline.width = a[1]
+		}
+		t.x, t.y = a[0], 0
+	case 1207: // sbw
+		t.sbx = a[0]
+		if !t.component {
+			t.outline.width = a[2]
+		}
+		t.x, t.y = a[0], a[1]
+	case 1: // hstem
+		if !t.component {
+			t.outline.hstems = append(t.outline.hstems, [2]float64{a[0], a[1]})
+		}
+	case 3: // vstem
+		if !t.component {
+			t.outline.vstems = append(t.outline.vstems, [2]float64{a[0] + t.sbx, a[1]})
+		}
+	case 1202: // hstem3
+		if !t.component {
+			for j := 0; j < 6; j += 2 {
+				t.outline.hstems = append(t.outline.hstems, [2]float64{a[j], a[j+1]})
+			}
+		}
+	case 1201: // vstem3
+		if !t.component {
+			for j := 0; j < 6; j += 2 {
+				t.outline.vstems = append(t.outline.vstems, [2]float64{a[j] + t.sbx, a[j+1]})
+			}
+		}
+	case 21, 22, 4: // rmoveto, hmoveto, vmoveto
+		dx, dy := 0.0, 0.0
+		switch op {
+		case 21:
+			dx, dy = a[0], a[1]
+		case 22:
+			dx = a[0]
+		case 4:
+			dy = a[0]
+		}
+		if t.flex {
+			// The points of flex are collected, and drawn as curves at its end.
+			t.x += dx
+			t.y += dy
+			t.flexPts = append(t.flexPts, glyphPoint{t.x, t.y})
+			return nil
+		}
+		t.moveTo(t.x+dx, t.y+dy)
+	case 5: // rlineto
+		t.lineTo(t.x+a[0], t.y+a[1])
+	case 6: // hlineto
+		t.lineTo(t.x+a[0], t.y)
+	case 7: // vlineto
+		t.lineTo(t.x, t.y+a[0])
+	case 8: // rrcurveto
+		t.curveTo(a[0], a[1], a[2], a[3], a[4], a[5])
+	case 30: // vhcurveto
+		t.curveTo(0, a[0], a[1], a[2], a[3], 0)
+	case 31: // hvcurveto
+		t.curveTo(a[0], 0, a[1], a[2], 0, a[3])
+	case 9: // closepath: the current point is unchanged, and Type 2 closes the subpaths implicitly.
+	case 1200: // dotsection
+	case 10: // callsubr
+		clear = false
+		args, _ := t.pop(1)
+		k := int(args[0])
+		if k < 0 || k >= len(t.font.subrs) || t.font.subrs[k] == nil {
+			return errors.New("Subroutine out of range")
+		}
+		return t.run(t.font.subrs[k])
+	case 11: // return
+		clear = false
+		return errReturn
+	case 14: // endchar
+		return errEndChar
+	case 1212: // div
+		clear = false
+		args, _ := t.pop(2)
+		if args[1] == 0 {
+			return errors.New("Division by zero")
+		}
+		t.stack = append(t.stack, args[0]/args[1])
+	case 1216: // callothersubr
+		clear = false
+		return t.callOtherSubr()
+	case 1217: // pop
+		clear = false
+		if len(t.psStack) > 0 {
+			t.stack = append(t.stack, t.psStack[len(t.psStack)-1])
+			t.psStack = t.psStack[:len(t.psStack)-1]
+		}
+	case 1233: // setcurrentpoint
+		t.x, t.y = a[0], a[1]
+	case 1206: // seac
+		return t.seac(a[0], a[1], a[2], int(a[3]), int(a[4]))
+	}
+	return nil
+}
+
+// errReturn returns from a subroutine.
+var errReturn = errors.New("return")
+
+// callOtherSubr interprets the call of an OtherSubr: the flex (0 to 2) and the hint replacement (3) are those of
+// Type 1 fonts, the others are ignored.
+func (t *type1Interpreter) callOtherSubr() error {
+	args, err := t.pop(2)
+	if err != nil {
+		return err
+	}
+	n, subr := int(args[0]), int(args[1])
+	subrArgs, err := t.pop(n)
+	if err != nil {
+		return err
+	}
+
+	t.psStack = t.psStack[:0]
+	switch subr {
+	case 0:
+		if !t.flex || len(t.flexPts) != 7 || n != 3 {
+			common.Log.Debug("ERROR: Invalid flex")
+			return errors.New("Invalid flex")
+		}
+		t.flex = false
+		p := t.flexPts
+		t.x, t.y = t.flexStart.x, t.flexStart.y
+		t.curveTo(p[1].x-t.x, p[1].y-t.y, p[2].x-p[1].x, p[2].y-p[1].y, p[3].x-p[2].x, p[3].y-p[2].y)
+		t.curveTo(p[4].x-t.x, p[4].y-t.y, p[5].x-p[4].x, p[5].y-p[4].y, p[6].x-p[5].x, p[6].y-p[5].y)
+		// The end point is left for pop pop setcurrentpoint.
+		t.psStack = []float64{subrArgs[2], subrArgs[1]}
+	case 1:
+		t.flex = true
+		t.flexStart = glyphPoint{t.x, t.y}
+		t.flexPts = nil
+	case 2:
+	default:
+		for i := len(subrArgs) - 1; i >= 0; i-- {
+			t.psStack = append(t.psStack, subrArgs[i])
+		}
+	}
+	return nil
+}
+
+// seac draws the accented character, of the base and accent characters of the Standard Encoding codes, the accent
+// being offset by adx, ady from the base, less its side bearing asb.
+func (t *type1Interpreter) seac(asb, adx, ady float64, bchar, achar int) error {
+	enc := standardEncoding()
+	base, hasBase := t.font.charStrings[enc[bchar]]
+	accent, hasAccent := t.font.charStrings[enc[achar]]
+	if !hasBase || !hasAccent {
+		common.Log.Debug("ERROR: seac components %d %d not found", bchar, achar)
+		return errors.New("Accented character components not found")
+	}
+
+	sbx := t.sbx
+	t.component = true
+	for _, c := range []struct {
+		cs     []byte
+		dx, dy float64
+	}{{base, 0, 0}, {accent, adx + sbx - asb, ady}} {
+		t.stack = t.stack[:0]
+		t.psStack = t.psStack[:0]
+		t.flex = false
+		t.dx, t.dy = c.dx, c.dy
+		if err := t.run(c.cs); err != nil && err != errEndChar {
+			return err
+		}
+	}
+	return errEndChar
+}
+
+// toCFF converts the font program to CFF, with the charstrings converted to Type 2 charstrings.  The subroutines
+// are inlined, and the hints kept without hint replacement.
+func (font *type1Font) toCFF() []byte {
+	b := &cffBuilder{name: font.fontName, top: cffDict{}, private: cffDict{}, encoding: font.encoding}
+	if b.name == "" {
+		b.name = "Untitled"
+	}
+	for op, key := range map[int]string{
+		cffVersion: "version", cffNotice: "Notice", cffFullName: "FullName", cffFamilyName: "FamilyName",
+		cffWeight: "Weight", cffCopyright: "Copyright",
+	} {
+		b.setString(op, font.strings[key])
+	}
+	if font.booleans["isFixedPitch"] {
+		b.top[cffIsFixedPitch] = []float64{1}
+	}
+	for op, key := range map[int]string{
+		cffItalicAngle: "ItalicAngle", cffUnderlinePosition: "UnderlinePosition",
+		cffUnderlineThickness: "UnderlineThickness",
+	} {
+		if v, has := font.numbers[key]; has {
+			b.top[op] = []float64{v}
+		}
+	}
+	if m := font.arrays["FontMatrix"]; len(m) == 6 && !(m[0] == 0.001 && m[1] == 0 && m[2] == 0 && m[3] == 0.001 &&
+		m[4] == 0 && m[5] == 0) {
+		b.top[cffFontMatrix] = m
+	}
+	if bbox := font.arrays["FontBBox"]; len(bbox) == 4 {
+		b.top[cffFontBBox] = bbox
+	}
+
+	// The blue zones and stem snaps are delta encoded, the standard stems are single numbers.
+	for op, key := range map[int]string{
+		cffBlueValues: "BlueValues", cffOtherBlues: "OtherBlues", cffFamilyBlues: "FamilyBlues",
+		cffFamilyOtherBlues: "FamilyOtherBlues", cffStemSnapH: "StemSnapH", cffStemSnapV: "StemSnapV",
+	} {
+		if values := font.arrays[key]; len(values) > 0 {
+			deltas := make([]float64, len(values))
+			prev := 0.0
+			for i, v := range values {
+				deltas[i] = v - prev
+				prev = v
+			}
+			b.private[op] = deltas
+		}
+	}
+	for op, key := range map[int]string{cffStdHW: "StdHW", cffStdVW: "StdVW"} {
+		if values := font.arrays[key]; len(values) > 0 {
+			b.private[op] = values[:1]
+		}
+	}
+	for op, key := range map[int]string{
+		cffBlueScale: "BlueScale", cffBlueShift: "BlueShift", cffBlueFuzz: "BlueFuzz",
+		cffLanguageGroup: "LanguageGroup", cffExpansionFactor: "ExpansionFactor",
+	} {
+		if v, has := font.numbers[key]; has {
+			b.private[op] = []float64{v}
+		}
+	}
+	if font.booleans["ForceBold"] {
+		b.private[cffForceBold] = []float64{1}
+	}
+
+	names := []string{".notdef"}
+	for _, name := range font.glyphs {
+		if name != ".notdef" {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		outline := &glyphOutline{}
+		if _, has := font.charStrings[name]; has {
+			var err error
+			if outline, err = font.outline(name); err != nil {
+				common.Log.Debug("ERROR: Unable to convert glyph %s: %v", name, err)
+				outline = &glyphOutline{width: outline.width}
+			}
+		}
+		b.glyphs = append(b.glyphs, name)
+		b.charStrings = append(b.charStrings, encodeType2(outline))
+	}
+	return b.bytes()
+}
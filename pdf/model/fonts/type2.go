@@ -0,0 +1,603 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+// Interpretation and writing of Type 2 charstrings, the glyph descriptions of CFF font programs (Adobe Technical
+// Note #5177).
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// glyphPoint is a point of a glyph outline, in glyph space.
+type glyphPoint struct {
+	x, y float64
+}
+
+// glyphSegment is a segment of a glyph outline: a move to, line to or curve to (with two control points) its end
+// point, the last of the points.
+type glyphSegment struct {
+	op  byte // 'M', 'L' or 'C'.
+	pts []glyphPoint
+}
+
+// glyphOutline is the outline of a glyph, with its advance width and stem hints (edge and width).
+type glyphOutline struct {
+	width          float64
+	segments       []glyphSegment
+	hstems, vstems [][2]float64
+}
+
+// bounds returns the bounding box of the points of the outline, and false if it has no points.
+func (o *glyphOutline) bounds() (xMin, yMin, xMax, yMax float64, ok bool) {
+	for _, seg := range o.segments {
+		if seg.op == 'M' {
+			continue
+		}
+		for _, pt := range seg.pts {
+			if !ok {
+				xMin, yMin, xMax, yMax, ok = pt.x, pt.y, pt.x, pt.y, true
+				continue
+			}
+			xMin, xMax = math.Min(xMin, pt.x), math.Max(xMax, pt.x)
+			yMin, yMax = math.Min(yMin, pt.y), math.Max(yMax, pt.y)
+		}
+	}
+	return
+}
+
+// Limits of Type 2 charstrings.
+const (
+	type2MaxArgs      = 48
+	type2MaxSubrDepth = 10
+)
+
+// subrBias returns the bias of the subroutine numbers of the subroutines.
+func subrBias(subrs [][]byte) int {
+	switch {
+	case len(subrs) < 1240:
+		return 107
+	case len(subrs) < 33900:
+		return 1131
+	}
+	return 32768
+}
+
+// type2Interpreter interprets the Type 2 charstrings of a CFF font into outlines.
+type type2Interpreter struct {
+	font    *cffFont
+	private *cffPrivateDict
+
+	stack     []float64
+	transient [32]float64
+	x, y      float64
+	nStems    int
+	hasWidth  bool
+	depth     int
+	outline   *glyphOutline
+}
+
+// outline interprets the charstring of the glyph.
+func (font *cffFont) outline(gid int) (*glyphOutline, error) {
+	if gid < 0 || gid >= len(font.charStrings) {
+		return nil, errors.New("Glyph out of range")
+	}
+	t := &type2Interpreter{font: font, private: font.private(gid), outline: &glyphOutline{}}
+	t.outline.width = t.private.dict.get(cffDefaultWidthX, 0)
+	if err := t.run(font.charStrings[gid]); err != nil && err != errEndChar {
+		return nil, err
+	}
+	return t.outline, nil
+}
+
+// errEndChar ends the interpretation of the charstring.
+var errEndChar = errors.New("endchar")
+
+// takeWidth takes the width from the stack before the first stack clearing operator, when the stack has more than
+// the operands of the operator, or an odd number of operands for the stem operators (n < 0).
+func (t *type2Interpreter) takeWidth(n int) {
+	if t.hasWidth {
+		return
+	}
+	t.hasWidth = true
+	if len(t.stack) > 0 && (n < 0 && len(t.stack)%2 == 1 || n >= 0 && len(t.stack) > n) {
+		t.outline.width = t.private.dict.get(cffNominalWidthX, 0) + t.stack[0]
+		t.stack = t.stack[1:]
+	}
+}
+
+func (t *type2Interpreter) moveTo(dx, dy float64) {
+	t.x += dx
+	t.y += dy
+	t.outline.segments = append(t.outline.segments, glyphSegment{op: 'M', pts: []glyphPoint{{t.x, t.y}}})
+}
+
+func (t *type2Interpreter) lineTo(dx, dy float64) {
+	t.x += dx
+	t.y += dy
+	t.outline.segments = append(t.outline.segments, glyphSegment{op: 'L', pts: []glyphPoint{{t.x, t.y}}})
+}
+
+func (t *type2Interpreter) curveTo(dxa, dya, dxb, dyb, dxc, dyc float64) {
+	pts := make([]glyphPoint, 3)
+	t.x += dxa
+	t.y += dya
+	pts[0] = glyphPoint{t.x, t.y}
+	t.x += dxb
+	t.y += dyb
+	pts[1] = glyphPoint{t.x, t.y}
+	t.x += dxc
+	t.y += dyc
+	pts[2] = glyphPoint{t.x, t.y}
+	t.outline.segments = append(t.outline.segments, glyphSegment{op: 'C', pts: pts})
+}
+
+// stems adds the stem hints of the operands.
+func (t *type2Interpreter) stems(horizontal bool) {
+	pos := 0.0
+	for i := 0; i+1 < len(t.stack); i += 2 {
+		pos += t.stack[i]
+		stem := [2]float64{pos, t.stack[i+1]}
+		pos += t.stack[i+1]
+		if horizontal {
+			t.outline.hstems = append(t.outline.hstems, stem)
+		} else {
+			t.outline.vstems = append(t.outline.vstems, stem)
+		}
+	}
+	t.nStems += len(t.stack) / 2
+	t.stack = t.stack[:0]
+}
+
+// alternatingCurves draws the curves of hvcurveto (starting horizontal) and vhcurveto.
+func (t *type2Interpreter) alternatingCurves(horizontal bool) {
+	args := t.stack
+	for len(args) >= 4 {
+		last := 0.0
+		if len(args) == 5 {
+			last = args[4]
+		}
+		if horizontal {
+			t.curveTo(args[0], 0, args[1], args[2], last, args[3])
+		} else {
+			t.curveTo(0, args[0], args[1], args[2], args[3], last)
+		}
+		args = args[4:]
+		horizontal = !horizontal
+	}
+}
+
+// run interprets the charstring.
+func (t *type2Interpreter) run(code []byte) error {
+	t.depth++
+	defer func() { t.depth-- }()
+	if t.depth > type2MaxSubrDepth {
+		return errors.New("Subroutines nested too deep")
+	}
+
+	for i := 0; i < len(code); {
+		b := code[i]
+		switch {
+		case b == 28:
+			if i+3 > len(code) {
+				return errors.New("Truncated charstring")
+			}
+			t.stack = append(t.stack, float64(int16(binary.BigEndian.Uint16(code[i+1:]))))
+			i += 3
+			continue
+		case b >= 32 && b <= 246:
+			t.stack = append(t.stack, float64(int(b)-139))
+			i++
+			continue
+		case b >= 247 && b <= 254:
+			if i+2 > len(code) {
+				return errors.New("Truncated charstring")
+			}
+			if b <= 250 {
+				t.stack = append(t.stack, float64((int(b)-247)*256+int(code[i+1])+108))
+			} else {
+				t.stack = append(t.stack, float64(-(int(b)-251)*256-int(code[i+1])-108))
+			}
+			i += 2
+			continue
+		case b == 255:
+			if i+5 > len(code) {
+				return errors.New("Truncated charstring")
+			}
+			t.stack = append(t.stack, float64(int32(binary.BigEndian.Uint32(code[i+1:])))/65536)
+			i += 5
+			continue
+		}
+		if len(t.stack) > type2MaxArgs {
+			return errors.New("Charstring stack overflow")
+		}
+
+		i++
+		args := t.stack
+		switch b {
+		case 1, 18: // hstem, hstemhm
+			t.takeWidth(-1)
+			t.stems(true)
+		case 3, 23: // vstem, vstemhm
+			t.takeWidth(-1)
+			t.stems(false)
+		case 19, 20: // hintmask, cntrmask
+			t.takeWidth(-1)
+			t.stems(false)
+			i += (t.nStems + 7) / 8
+		case 21: // rmoveto
+			t.takeWidth(2)
+			if len(t.stack) < 2 {
+				return errors.New("Charstring stack underflow")
+			}
+			t.moveTo(t.stack[0], t.stack[1])
+		case 22: // hmoveto
+			t.takeWidth(1)
+			if len(t.stack) < 1 {
+				return errors.New("Charstring stack underflow")
+			}
+			t.moveTo(t.stack[0], 0)
+		case 4: // vmoveto
+			t.takeWidth(1)
+			if len(t.stack) < 1 {
+				return errors.New("Charstring stack underflow")
+			}
+			t.moveTo(0, t.stack[0])
+		case 5: // rlineto
+			for ; len(args) >= 2; args = args[2:] {
+				t.lineTo(args[0], args[1])
+			}
+		case 6, 7: // hlineto, vlineto
+			horizontal := b == 6
+			for ; len(args) >= 1; args = args[1:] {
+				if horizontal {
+					t.lineTo(args[0], 0)
+				} else {
+					t.lineTo(0, args[0])
+				}
+				horizontal = !horizontal
+			}
+		case 8: // rrcurveto
+			for ; len(args) >= 6; args = args[6:] {
+				t.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+		case 24: // rcurveline
+			for ; len(args) >= 8; args = args[6:] {
+				t.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+			if len(args) >= 2 {
+				t.lineTo(args[0], args[1])
+			}
+		case 25: // rlinecurve
+			for ; len(args) >= 8; args = args[2:] {
+				t.lineTo(args[0], args[1])
+			}
+			if len(args) >= 6 {
+				t.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+		case 26: // vvcurveto
+			dx1 := 0.0
+			if len(args)%2 == 1 {
+				dx1, args = args[0], args[1:]
+			}
+			for ; len(args) >= 4; args = args[4:] {
+				t.curveTo(dx1, args[0], args[1], args[2], 0, args[3])
+				dx1 = 0
+			}
+		case 27: // hhcurveto
+			dy1 := 0.0
+			if len(args)%2 == 1 {
+				dy1, args = args[0], args[1:]
+			}
+			for ; len(args) >= 4; args = args[4:] {
+				t.curveTo(args[0], dy1, args[1], args[2], args[3], 0)
+				dy1 = 0
+			}
+		case 30, 31: // vhcurveto, hvcurveto
+			t.alternatingCurves(b == 31)
+		case 10, 29: // callsubr, callgsubr
+			if len(t.stack) < 1 {
+				return errors.New("Charstring stack underflow")
+			}
+			subrs := t.private.subrs
+			if b == 29 {
+				subrs = t.font.gsubrs
+			}
+			n := int(t.stack[len(t.stack)-1]) + subrBias(subrs)
+			t.stack = t.stack[:len(t.stack)-1]
+			if n < 0 || n >= len(subrs) {
+				return errors.New("Subroutine out of range")
+			}
+			if err := t.run(subrs[n]); err != nil {
+				return err
+			}
+			continue
+		case 11: // return
+			return nil
+		case 14: // endchar
+			// The operands of accented characters (seac) are not width, and the characters are not composed.
+			if len(t.stack) >= 4 {
+				t.takeWidth(4)
+			} else {
+				t.takeWidth(0)
+			}
+			return errEndChar
+		case 12:
+			if i >= len(code) {
+				return errors.New("Truncated charstring")
+			}
+			op := code[i]
+			i++
+			if err := t.escape(op); err != nil {
+				return err
+			}
+			continue
+		default:
+			return errors.New("Invalid charstring operator")
+		}
+		t.stack = t.stack[:0]
+	}
+	return nil
+}
+
+// escape interprets the two byte operator (12 op): the flex and the arithmetic operators.
+func (t *type2Interpreter) escape(op byte) error {
+	args := t.stack
+	need := map[byte]int{
+		34: 7, 35: 13, 36: 9, 37: 11, 3: 2, 4: 2, 5: 1, 9: 1, 10: 2, 11: 2, 12: 2, 14: 1, 15: 2, 18: 1, 20: 2, 21: 1,
+		22: 4, 23: 0, 24: 2, 26: 1, 27: 1, 28: 2, 29: 1, 30: 2,
+	}
+	n, known := need[op]
+	if !known {
+		return errors.New("Invalid charstring operator")
+	}
+	if len(args) < n {
+		return errors.New("Charstring stack underflow")
+	}
+	top := len(args) - n
+	push := func(v ...float64) {
+		t.stack = append(args[:top], v...)
+	}
+	boolean := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	switch op {
+	case 34: // hflex
+		t.curveTo(args[0], 0, args[1], args[2], args[3], 0)
+		t.curveTo(args[4], 0, args[5], -args[2], args[6], 0)
+		t.stack = t.stack[:0]
+	case 35: // flex
+		t.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+		t.curveTo(args[6], args[7], args[8], args[9], args[10], args[11])
+		t.stack = t.stack[:0]
+	case 36: // hflex1
+		t.curveTo(args[0], args[1], args[2], args[3], args[4], 0)
+		t.curveTo(args[5], 0, args[6], args[7], args[8], -(args[1] + args[3] + args[7]))
+		t.stack = t.stack[:0]
+	case 37: // flex1
+		dx, dy := 0.0, 0.0
+		for i := 0; i < 10; i += 2 {
+			dx += args[i]
+			dy += args[i+1]
+		}
+		t.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+		if math.Abs(dx) > math.Abs(dy) {
+			t.curveTo(args[6], args[7], args[8], args[9], args[10], -dy)
+		} else {
+			t.curveTo(args[6], args[7], args[8], args[9], -dx, args[10])
+		}
+		t.stack = t.stack[:0]
+	case 3: // and
+		push(boolean(args[top] != 0 && args[top+1] != 0))
+	case 4: // or
+		push(boolean(args[top] != 0 || args[top+1] != 0))
+	case 5: // not
+		push(boolean(args[top] == 0))
+	case 9: // abs
+		push(math.Abs(args[top]))
+	case 10: // add
+		push(args[top] + args[top+1])
+	case 11: // sub
+		push(args[top] - args[top+1])
+	case 12: // div
+		if args[top+1] == 0 {
+			return errors.New("Division by zero")
+		}
+		push(args[top] / args[top+1])
+	case 14: // neg
+		push(-args[top])
+	case 15: // eq
+		push(boolean(args[top] == args[top+1]))
+	case 18: // drop
+		push()
+	case 20: // put
+		i := int(args[top+1])
+		if i < 0 || i >= len(t.transient) {
+			return errors.New("Transient array index out of range")
+		}
+		t.transient[i] = args[top]
+		push()
+	case 21: // get
+		i := int(args[top])
+		if i < 0 || i >= len(t.transient) {
+			return errors.New("Transient array index out of range")
+		}
+		push(t.transient[i])
+	case 22: // ifelse
+		if args[top+2] <= args[top+3] {
+			push(args[top])
+		} else {
+			push(args[top+1])
+		}
+	case 23: // random
+		push(0.5)
+	case 24: // mul
+		push(args[top] * args[top+1])
+	case 26: // sqrt
+		push(math.Sqrt(math.Abs(args[top])))
+	case 27: // dup
+		push(args[top], args[top])
+	case 28: // exch
+		push(args[top+1], args[top])
+	case 29: // index
+		i := int(args[top])
+		if i < 0 {
+			i = 0
+		}
+		if i >= top {
+			return errors.New("Charstring stack underflow")
+		}
+		push(args[top-1-i])
+	case 30: // roll
+		count, shift := int(args[top]), int(args[top+1])
+		if count < 0 || count > top {
+			return errors.New("Charstring stack underflow")
+		}
+		if count > 0 {
+			rolled := append([]float64(nil), args[top-count:top]...)
+			for j, v := range rolled {
+				args[top-count+((j+shift)%count+count)%count] = v
+			}
+		}
+		push()
+	}
+	return nil
+}
+
+// type2Writer writes Type 2 charstrings.
+type type2Writer struct {
+	buf  bytes.Buffer
+	args []float64
+}
+
+// number writes the operand, as an integer if integral, as a 16.16 fixed point number otherwise.
+func (w *type2Writer) number(v float64) {
+	if r := math.Round(v); math.Abs(v-r) < 1e-6 && math.Abs(r) <= 32767 {
+		n := int(r)
+		switch {
+		case n >= -107 && n <= 107:
+			w.buf.WriteByte(byte(n + 139))
+		case n >= 108 && n <= 1131:
+			n -= 108
+			w.buf.Write([]byte{byte(n>>8 + 247), byte(n)})
+		case n >= -1131 && n <= -108:
+			n = -n - 108
+			w.buf.Write([]byte{byte(n>>8 + 251), byte(n)})
+		default:
+			w.buf.WriteByte(28)
+			binary.Write(&w.buf, binary.BigEndian, int16(n))
+		}
+		return
+	}
+	w.buf.WriteByte(255)
+	binary.Write(&w.buf, binary.BigEndian, int32(math.Round(v*65536)))
+}
+
+// op writes the operator with the operands.
+func (w *type2Writer) op(op byte, args ...float64) {
+	for _, v := range args {
+		w.number(v)
+	}
+	w.buf.WriteByte(op)
+}
+
+// nonOverlappingStems returns the stems sorted by their lower edge, without those overlapping the previous ones,
+// as required without hint replacement.
+func nonOverlappingStems(stems [][2]float64) [][2]float64 {
+	lower := func(s [2]float64) float64 { return math.Min(s[0], s[0]+s[1]) }
+	upper := func(s [2]float64) float64 { return math.Max(s[0], s[0]+s[1]) }
+
+	sorted := append([][2]float64(nil), stems...)
+	sort.SliceStable(sorted, func(i, j int) bool { return lower(sorted[i]) < lower(sorted[j]) })
+	var kept [][2]float64
+	for _, s := range sorted {
+		if len(kept) > 0 && lower(s) <= upper(kept[len(kept)-1]) {
+			continue
+		}
+		if len(kept) == type2MaxArgs/2-1 {
+			break
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// encodeType2 writes the outline as a Type 2 charstring, with the width relative to a nominal width of 0.
+func encodeType2(o *glyphOutline) []byte {
+	w := &type2Writer{}
+	pending := []float64{o.width}
+
+	for _, hint := range []struct {
+		stems [][2]float64
+		op    byte
+	}{{o.hstems, 1}, {o.vstems, 3}} {
+		stems := nonOverlappingStems(hint.stems)
+		if len(stems) == 0 {
+			continue
+		}
+		args := pending
+		pending = nil
+		pos := 0.0
+		for _, s := range stems {
+			args = append(args, s[0]-pos, s[1])
+			pos = s[0] + s[1]
+		}
+		w.op(hint.op, args...)
+	}
+
+	// The consecutive lines and curves are written as single operators, up to the limit of operands.
+	x, y := 0.0, 0.0
+	var runOp byte
+	var run []float64
+	flush := func() {
+		if len(run) > 0 {
+			w.op(runOp, run...)
+		}
+		run = nil
+	}
+	moved := false
+	for _, seg := range o.segments {
+		switch seg.op {
+		case 'M':
+			flush()
+			pt := seg.pts[0]
+			w.op(21, append(pending, pt.x-x, pt.y-y)...)
+			pending = nil
+			x, y = pt.x, pt.y
+			moved = true
+			continue
+		case 'L', 'C':
+			if !moved {
+				w.op(21, append(pending, 0, 0)...)
+				pending = nil
+				moved = true
+			}
+			op := byte(5)
+			if seg.op == 'C' {
+				op = 8
+			}
+			if op != runOp || len(run)+2*len(seg.pts) > type2MaxArgs {
+				flush()
+				runOp = op
+			}
+			for _, pt := range seg.pts {
+				run = append(run, pt.x-x, pt.y-y)
+				x, y = pt.x, pt.y
+			}
+		}
+	}
+	flush()
+	w.op(14, pending...)
+	return w.buf.Bytes()
+}
@@ -0,0 +1,430 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// Field flags (Ff), in addition to FieldFlagReadOnly.
+const (
+	FieldFlagRequired        = 1 << 1
+	FieldFlagNoExport        = 1 << 2
+	FieldFlagMultiline       = 1 << 12 // Text fields.
+	FieldFlagPassword        = 1 << 13 // Text fields.
+	FieldFlagNoToggleToOff   = 1 << 14 // Radio buttons.
+	FieldFlagRadio           = 1 << 15 // Button fields.
+	FieldFlagPushbutton      = 1 << 16 // Button fields.
+	FieldFlagCombo           = 1 << 17 // Choice fields.
+	FieldFlagEdit            = 1 << 18 // Combo boxes.
+	FieldFlagDoNotSpellCheck = 1 << 22 // Text fields and editable combo boxes.
+	FieldFlagDoNotScroll     = 1 << 23 // Text fields.
+	FieldFlagComb            = 1 << 24 // Text fields with MaxLen, shown in as many cells.
+)
+
+// The font of the text of the fields created: Helvetica, in the default resources of the form.
+const formFontName = PdfObjectName("Helv")
+
+// Default font size of the text of the fields created.
+const defaultFieldFontSize = 12
+
+// TextFieldOptions are the options of the text fields created by PdfAcroForm.AddTextField.
+type TextFieldOptions struct {
+	Value string
+
+	// Font size, 12 if 0.
+	FontSize float64
+
+	// Maximum length of the text, unlimited if 0.  With FieldFlagComb, the text is shown in as many cells.
+	MaxLen int
+
+	// Other field flags, e.g. FieldFlagReadOnly, FieldFlagRequired, FieldFlagMultiline or FieldFlagPassword.
+	Flags int64
+}
+
+// CheckboxFieldOptions are the options of the check boxes created by PdfAcroForm.AddCheckboxField.
+type CheckboxFieldOptions struct {
+	Checked bool
+
+	// Other field flags, e.g. FieldFlagReadOnly or FieldFlagRequired.
+	Flags int64
+}
+
+// RadioGroupFieldOptions are the options of the radio button groups created by PdfAcroForm.AddRadioGroupField.
+type RadioGroupFieldOptions struct {
+	// The value of the selected button, none if empty.
+	Value string
+
+	// Other field flags, e.g. FieldFlagReadOnly or FieldFlagNoToggleToOff.
+	Flags int64
+}
+
+// ComboboxFieldOptions are the options of the combo boxes created by PdfAcroForm.AddComboboxField.
+type ComboboxFieldOptions struct {
+	// The options to choose from, and the selected value, none if empty.
+	Options []string
+	Value   string
+
+	// Editable combo boxes allow values other than the options.
+	Editable bool
+
+	// Font size, 12 if 0.
+	FontSize float64
+
+	// Other field flags, e.g. FieldFlagReadOnly or FieldFlagRequired.
+	Flags int64
+}
+
+// AddTextField adds a text field with a widget annotation at the rectangle of the page, with an appearance showing
+// its value.
+func (this *PdfAcroForm) AddTextField(page *PdfPage, name string, rect PdfRectangle, opt TextFieldOptions) (*PdfField, error) {
+	field, err := this.newTerminalField("Tx", name, opt.Flags)
+	if err != nil {
+		return nil, err
+	}
+	fontSize := opt.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFieldFontSize
+	}
+	field.DA = MakeString(fmt.Sprintf("/%s %g Tf 0 g", formFontName, fontSize))
+	if opt.Value != "" {
+		field.V = MakeTextString(opt.Value)
+	}
+	comb := 0
+	if opt.MaxLen > 0 {
+		if len([]rune(opt.Value)) > opt.MaxLen {
+			common.Log.Debug("ERROR: Value %q longer than MaxLen %d", opt.Value, opt.MaxLen)
+			return nil, errors.New("Value too long")
+		}
+		field.setEntry("MaxLen", MakeInteger(int64(opt.MaxLen)))
+		if opt.Flags&FieldFlagComb != 0 && opt.Flags&(FieldFlagMultiline|FieldFlagPassword) == 0 {
+			comb = opt.MaxLen
+		}
+	}
+
+	text := opt.Value
+	if opt.Flags&FieldFlagPassword != 0 {
+		text = strings.Repeat("*", len([]rune(text)))
+	}
+	ap, err := this.makeTextAppearance(rect, text, fontSize, opt.Flags&FieldFlagMultiline != 0, comb)
+	if err != nil {
+		return nil, err
+	}
+	widget, err := this.addWidget(field, page, rect)
+	if err != nil {
+		return nil, err
+	}
+	widget.AP = makeAppearanceDict(ap)
+	this.addField(field)
+	return field, nil
+}
+
+// AddCheckboxField adds a check box with a widget annotation at the rectangle of the page.  Its value is /Yes when
+// checked, and /Off otherwise.
+func (this *PdfAcroForm) AddCheckboxField(page *PdfPage, name string, rect PdfRectangle, opt CheckboxFieldOptions) (*PdfField, error) {
+	field, err := this.newTerminalField("Btn", name, opt.Flags)
+	if err != nil {
+		return nil, err
+	}
+	state := "Off"
+	if opt.Checked {
+		state = "Yes"
+	}
+	field.V = MakeName(state)
+
+	widget, err := this.addWidget(field, page, rect)
+	if err != nil {
+		return nil, err
+	}
+	width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+	err = setButtonAppearances(widget, rect, "Yes", state, fieldBorder(rect), checkMark(width, height))
+	if err != nil {
+		return nil, err
+	}
+	this.addField(field)
+	return field, nil
+}
+
+// AddRadioGroupField adds a group of radio buttons, the buttons being added by AddRadioButton.  Its value is the
+// value of the selected button, or /Off if none.
+func (this *PdfAcroForm) AddRadioGroupField(name string, opt RadioGroupFieldOptions) (*PdfField, error) {
+	field, err := this.newTerminalField("Btn", name, opt.Flags|FieldFlagRadio)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Value != "" {
+		field.V = MakeName(opt.Value)
+	} else {
+		field.V = MakeName("Off")
+	}
+	this.addField(field)
+	return field, nil
+}
+
+// AddRadioButton adds a radio button with the value to the radio button group field, with a widget annotation at
+// the rectangle of the page.  The button is selected if its value is the value of the field.
+func (this *PdfAcroForm) AddRadioButton(field *PdfField, page *PdfPage, rect PdfRectangle, value string) error {
+	ff, _ := TraceToDirectObject(field.Ff).(*PdfObjectInteger)
+	if field.GetFieldType() != "Btn" || ff == nil || int64(*ff)&FieldFlagRadio == 0 {
+		common.Log.Debug("ERROR: Not a radio button field: %s", field.GetFullName())
+		return errors.New("Not a radio button field")
+	}
+	if value == "" || value == "Off" {
+		return errors.New("Invalid radio button value")
+	}
+	state := "Off"
+	if v, ok := TraceToDirectObject(field.V).(*PdfObjectName); ok && string(*v) == value {
+		state = value
+	}
+
+	widget, err := this.addWidget(field, page, rect)
+	if err != nil {
+		return err
+	}
+	width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+	return setButtonAppearances(widget, rect, value, state, radioBackground(width, height), radioDot(width, height))
+}
+
+// AddComboboxField adds a combo box with a widget annotation at the rectangle of the page, with an appearance showing
+// its value.
+func (this *PdfAcroForm) AddComboboxField(page *PdfPage, name string, rect PdfRectangle, opt ComboboxFieldOptions) (*PdfField, error) {
+	flags := opt.Flags | FieldFlagCombo
+	if opt.Editable {
+		flags |= FieldFlagEdit
+	}
+	field, err := this.newTerminalField("Ch", name, flags)
+	if err != nil {
+		return nil, err
+	}
+	fontSize := opt.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFieldFontSize
+	}
+	field.DA = MakeString(fmt.Sprintf("/%s %g Tf 0 g", formFontName, fontSize))
+	options := PdfObjectArray{}
+	for _, option := range opt.Options {
+		options = append(options, MakeTextString(option))
+	}
+	field.setEntry("Opt", &options)
+	if opt.Value != "" {
+		field.V = MakeTextString(opt.Value)
+	}
+
+	ap, err := this.makeTextAppearance(rect, opt.Value, fontSize, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	widget, err := this.addWidget(field, page, rect)
+	if err != nil {
+		return nil, err
+	}
+	widget.AP = makeAppearanceDict(ap)
+	this.addField(field)
+	return field, nil
+}
+
+// AddSignatureField adds an unsigned signature field with a widget annotation at the rectangle of the page.  The
+// rectangle may be empty for an invisible signature.
+func (this *PdfAcroForm) AddSignatureField(page *PdfPage, name string, rect PdfRectangle) (*PdfField, error) {
+	field, err := this.newTerminalField("Sig", name, 0)
+	if err != nil {
+		return nil, err
+	}
+	widget, err := this.addWidget(field, page, rect)
+	if err != nil {
+		return nil, err
+	}
+	if rect.Urx > rect.Llx && rect.Ury > rect.Lly {
+		ap, err := makeFieldAppearance(rect, fieldBorder(rect), nil)
+		if err != nil {
+			return nil, err
+		}
+		widget.AP = makeAppearanceDict(ap)
+	}
+	this.addField(field)
+	return field, nil
+}
+
+// newTerminalField creates a field of the type with the partial name, to be added to the fields of the form by
+// addField.  Returns an error if the form has a field with the name.
+func (this *PdfAcroForm) newTerminalField(fieldType, name string, flags int64) (*PdfField, error) {
+	if name == "" || strings.Contains(name, ".") {
+		common.Log.Debug("ERROR: Invalid field name %q", name)
+		return nil, errors.New("Invalid field name")
+	}
+	if this.Fields != nil {
+		for _, field := range *this.Fields {
+			if field.GetFullName() == name {
+				common.Log.Debug("ERROR: Duplicate field name %q", name)
+				return nil, errors.New("Duplicate field name")
+			}
+		}
+	}
+
+	field := NewPdfField()
+	field.FT = MakeName(fieldType)
+	field.T = MakeTextString(name)
+	if flags != 0 {
+		field.Ff = MakeInteger(flags)
+	}
+	return field, nil
+}
+
+// addField adds the field to the fields of the form.
+func (this *PdfAcroForm) addField(field *PdfField) {
+	if this.Fields == nil {
+		this.Fields = &[]*PdfField{}
+	}
+	*this.Fields = append(*this.Fields, field)
+}
+
+// setEntry sets an entry of the field dictionary not in the model, e.g. MaxLen or Opt.
+func (this *PdfField) setEntry(key PdfObjectName, obj PdfObject) {
+	this.primitive.PdfObject.(*PdfObjectDictionary).Set(key, obj)
+}
+
+// addWidget adds a widget annotation of the field at the rectangle of the page, printed with the page.
+func (this *PdfAcroForm) addWidget(field *PdfField, page *PdfPage, rect PdfRectangle) (*PdfAnnotationWidget, error) {
+	if page == nil {
+		return nil, errors.New("Page required")
+	}
+	widget := NewPdfAnnotationWidget()
+	widget.Rect = rect.ToPdfObject()
+	widget.P = page.GetPageAsIndirectObject()
+	widget.F = MakeInteger(4)
+	widget.Parent = field.GetContainingPdfObject()
+	mk := MakeDict()
+	mk.Set("BC", MakeArrayFromFloats([]float64{0}))
+	mk.Set("BG", MakeArrayFromFloats([]float64{1}))
+	widget.MK = mk
+	field.KidsA = append(field.KidsA, widget.PdfAnnotation)
+	page.Annotations = append(page.Annotations, widget.PdfAnnotation)
+	return widget, nil
+}
+
+// formFont returns the resources with the font of the field text, added to the default resources of the form with
+// the default appearance if needed.
+func (this *PdfAcroForm) formFont() (*PdfPageResources, error) {
+	if this.DR == nil {
+		this.DR = NewPdfPageResources()
+	}
+	font, has := this.DR.GetFontByName(formFontName)
+	if !has {
+		font = fonts.NewFontHelvetica().ToPdfObject()
+		err := this.DR.SetFontByName(formFontName, font)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if this.DA == nil {
+		this.DA = MakeString(fmt.Sprintf("/%s 0 Tf 0 g", formFontName))
+	}
+
+	resources := NewPdfPageResources()
+	err := resources.SetFontByName(formFontName, font)
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// makeTextAppearance returns the appearance of a text field or combo box at the rectangle, showing the text, in comb
+// cells if comb is not 0.
+func (this *PdfAcroForm) makeTextAppearance(rect PdfRectangle, text string, fontSize float64, multiline bool,
+	comb int) (*XObjectForm, error) {
+	resources, err := this.formFont()
+	if err != nil {
+		return nil, err
+	}
+	ta := textAppearance{fontName: formFontName, fontSize: fontSize, operators: "0 g", multiline: multiline,
+		comb: comb, divider: "0 G"}
+	return makeFieldAppearance(rect, fieldBorder(rect)+ta.content(rect, text), resources)
+}
+
+// setButtonAppearances sets the appearances of the check box or radio button widget: the background, with the mark
+// when selected in the state onState, and without in the state /Off, the current state being state.
+func setButtonAppearances(widget *PdfAnnotationWidget, rect PdfRectangle, onState, state, background, mark string) error {
+	on, err := makeFieldAppearance(rect, background+mark, nil)
+	if err != nil {
+		return err
+	}
+	off, err := makeFieldAppearance(rect, background, nil)
+	if err != nil {
+		return err
+	}
+	states := MakeDict()
+	states.Set(PdfObjectName(onState), on.ToPdfObject())
+	states.Set("Off", off.ToPdfObject())
+	ap := MakeDict()
+	ap.Set("N", states)
+	widget.AP = ap
+	widget.AS = MakeName(state)
+	return nil
+}
+
+// makeFieldAppearance returns an appearance stream of the size of the rectangle.
+func makeFieldAppearance(rect PdfRectangle, content string, resources *PdfPageResources) (*XObjectForm, error) {
+	xform := NewXObjectForm()
+	xform.BBox = MakeArrayFromFloats([]float64{0, 0, rect.Urx - rect.Llx, rect.Ury - rect.Lly})
+	xform.Resources = resources
+	encoder := NewFlateEncoder()
+	err := xform.SetContentStream([]byte(content), encoder)
+	if err != nil {
+		return nil, err
+	}
+	xform.Filter = encoder
+	return xform, nil
+}
+
+// makeAppearanceDict returns the appearance dictionary with the normal appearance.
+func makeAppearanceDict(ap *XObjectForm) *PdfObjectDictionary {
+	dict := MakeDict()
+	dict.Set("N", ap.ToPdfObject())
+	return dict
+}
+
+// fieldBorder returns the content drawing the white background and black border of the field widgets.
+func fieldBorder(rect PdfRectangle) string {
+	width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+	return fmt.Sprintf("1 g\n0 0 %.4f %.4f re f\n0 G\n1 w\n0.5 0.5 %.4f %.4f re S\n", width, height, width-1, height-1)
+}
+
+// checkMark returns the content drawing the check mark of a checked check box of the size.
+func checkMark(width, height float64) string {
+	return fmt.Sprintf("q\n0 G\n%.4f w\n1 J\n1 j\n%.4f %.4f m\n%.4f %.4f l\n%.4f %.4f l\nS\nQ\n",
+		0.1*math.Min(width, height), 0.2*width, 0.5*height, 0.42*width, 0.25*height, 0.8*width, 0.78*height)
+}
+
+// radioBackground returns the content drawing the white background and black border of a radio button of the size.
+func radioBackground(width, height float64) string {
+	r := math.Min(width, height) / 2
+	return "1 g\n" + circlePath(width/2, height/2, r) + "f\n0 G\n1 w\n" + circlePath(width/2, height/2, r-0.5) + "S\n"
+}
+
+// radioDot returns the content drawing the dot of a selected radio button of the size.
+func radioDot(width, height float64) string {
+	return "0 g\n" + circlePath(width/2, height/2, math.Min(width, height)/4) + "f\n"
+}
+
+// circlePath returns the path of the circle, made of four Bezier curves.
+func circlePath(cx, cy, r float64) string {
+	k := 0.5523 * r
+	return fmt.Sprintf("%.4f %.4f m\n"+
+		"%.4f %.4f %.4f %.4f %.4f %.4f c\n%.4f %.4f %.4f %.4f %.4f %.4f c\n"+
+		"%.4f %.4f %.4f %.4f %.4f %.4f c\n%.4f %.4f %.4f %.4f %.4f %.4f c\n",
+		cx+r, cy,
+		cx+r, cy+k, cx+k, cy+r, cx, cy+r,
+		cx-k, cy+r, cx-r, cy+k, cx-r, cy,
+		cx-r, cy-k, cx-k, cy-r, cx, cy-r,
+		cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+}
@@ -0,0 +1,222 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestAddFormFields(t *testing.T) {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 300, Ury: 300}
+	page.Resources = NewPdfPageResources()
+
+	form := NewPdfAcroForm()
+	_, err := form.AddTextField(page, "name", PdfRectangle{Llx: 10, Lly: 250, Urx: 210, Ury: 270},
+		TextFieldOptions{Value: "Jane Doe", MaxLen: 40, Flags: FieldFlagRequired})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, err = form.AddCheckboxField(page, "agree", PdfRectangle{Llx: 10, Lly: 220, Urx: 22, Ury: 232},
+		CheckboxFieldOptions{Checked: true})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	group, err := form.AddRadioGroupField("size", RadioGroupFieldOptions{Value: "M"})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for i, value := range []string{"S", "M", "L"} {
+		x := 10 + 20*float64(i)
+		err = form.AddRadioButton(group, page, PdfRectangle{Llx: x, Lly: 190, Urx: x + 12, Ury: 202}, value)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	_, err = form.AddComboboxField(page, "country", PdfRectangle{Llx: 10, Lly: 160, Urx: 110, Ury: 180},
+		ComboboxFieldOptions{Options: []string{"France", "Germany"}, Value: "Germany"})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, err = form.AddSignatureField(page, "signature", PdfRectangle{Llx: 10, Lly: 80, Urx: 160, Ury: 140})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// Field names are unique.
+	_, err = form.AddTextField(page, "name", PdfRectangle{Llx: 10, Lly: 10, Urx: 50, Ury: 30}, TextFieldOptions{})
+	if err == nil {
+		t.Errorf("Missing error for duplicate field name")
+	}
+	if err = form.AddRadioButton(group, page, PdfRectangle{Llx: 0, Lly: 0, Urx: 10, Ury: 10}, "Off"); err == nil {
+		t.Errorf("Missing error for invalid radio button value")
+	}
+	if len(page.Annotations) != 7 {
+		t.Fatalf("Wrong number of widgets %d", len(page.Annotations))
+	}
+
+	if err = w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = w.SetForms(form); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if reader.AcroForm == nil || reader.AcroForm.DR == nil || !reader.AcroForm.DR.HasFontByName("Helv") {
+		t.Fatalf("Missing form default resources")
+	}
+	fields := map[string]*PdfField{}
+	for _, field := range reader.AcroForm.AllFields() {
+		fields[field.GetFullName()] = field
+	}
+	for name, expected := range map[string]struct{ ft, v string }{
+		"name":      {"Tx", "(Jane Doe)"},
+		"agree":     {"Btn", "/Yes"},
+		"size":      {"Btn", "/M"},
+		"country":   {"Ch", "(Germany)"},
+		"signature": {"Sig", ""},
+	} {
+		field, has := fields[name]
+		if !has {
+			t.Errorf("Missing field %s", name)
+			continue
+		}
+		if field.GetFieldType() != expected.ft {
+			t.Errorf("Field %s: wrong type %s", name, field.GetFieldType())
+		}
+		v := ""
+		if field.V != nil {
+			v = TraceToDirectObject(field.V).DefaultWriteString()
+		}
+		if v != expected.v {
+			t.Errorf("Field %s: wrong value %s", name, v)
+		}
+	}
+	if ff, ok := fields["size"].Ff.(*PdfObjectInteger); !ok || int64(*ff)&FieldFlagRadio == 0 {
+		t.Errorf("Wrong radio button flags %v", fields["size"].Ff)
+	}
+
+	// The widgets are on the page, with appearances.  The selected radio button is in its on state.
+	page, err = reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(page.Annotations) != 7 {
+		t.Fatalf("Wrong number of widgets %d", len(page.Annotations))
+	}
+	if len(fields["size"].KidsA) != 3 {
+		t.Fatalf("Wrong number of radio buttons %d", len(fields["size"].KidsA))
+	}
+	for i, state := range []string{"Off", "M", "Off"} {
+		widget := fields["size"].KidsA[i]
+		if as, ok := TraceToDirectObject(widget.AS).(*PdfObjectName); !ok || string(*as) != state {
+			t.Errorf("Radio button %d: wrong state %v", i, widget.AS)
+		}
+		ap, ok := TraceToDirectObject(widget.AP).(*PdfObjectDictionary)
+		if !ok {
+			t.Fatalf("Radio button %d: missing appearance", i)
+		}
+		states, ok := TraceToDirectObject(ap.Get("N")).(*PdfObjectDictionary)
+		if !ok || states.Get("Off") == nil || len(states.Keys()) != 2 {
+			t.Errorf("Radio button %d: wrong appearance states %v", i, ap.Get("N"))
+		}
+	}
+
+	ap, ok := TraceToDirectObject(fields["name"].KidsA[0].AP).(*PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Missing text field appearance")
+	}
+	stream, ok := TraceToDirectObject(ap.Get("N")).(*PdfObjectStream)
+	if !ok {
+		t.Fatalf("Wrong text field appearance %v", ap.Get("N"))
+	}
+	content, err := DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(string(content), "/Tx BMC") || !strings.Contains(string(content), "(Jane Doe) Tj") {
+		t.Errorf("Wrong text field appearance %q", content)
+	}
+}
+
+func TestTextFieldAppearance(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 300, Ury: 300}
+	form := NewPdfAcroForm()
+	rect := PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 50}
+
+	// Passwords are masked, multiline text is shown on successive lines.
+	field, err := form.AddTextField(page, "password", rect, TextFieldOptions{Value: "secret", Flags: FieldFlagPassword})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	content := fieldAppearanceContent(t, field)
+	if !strings.Contains(content, "(******) Tj") || strings.Contains(content, "secret") {
+		t.Errorf("Wrong password appearance %q", content)
+	}
+
+	field, err = form.AddTextField(page, "address", rect,
+		TextFieldOptions{Value: "1 Main St\nSpringfield", FontSize: 10, Flags: FieldFlagMultiline})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	content = fieldAppearanceContent(t, field)
+	if !strings.Contains(content, "(1 Main St) Tj\nT*\n(Springfield) Tj") || !strings.Contains(content, "/Helv 10 Tf") {
+		t.Errorf("Wrong multiline appearance %q", content)
+	}
+	if da, ok := field.DA.(*PdfObjectString); !ok || string(*da) != "/Helv 10 Tf 0 g" {
+		t.Errorf("Wrong default appearance %v", field.DA)
+	}
+
+	// Comb fields show a character in each cell, divided in the border color.
+	field, err = form.AddTextField(page, "zip", rect, TextFieldOptions{Value: "123", MaxLen: 5, Flags: FieldFlagComb})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	content = fieldAppearanceContent(t, field)
+	for _, expected := range []string{"0 G\n1 w\n20.0000 0 m\n20.0000 50.0000 l\n", "80.0000 0 m\n", "(3) Tj"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Wrong comb appearance %q, missing %q", content, expected)
+		}
+	}
+	if strings.Count(content, " Tj") != 3 || strings.Contains(content, "100.0000 0 m") {
+		t.Errorf("Wrong comb appearance %q", content)
+	}
+	if _, err = form.AddTextField(page, "zip2", rect, TextFieldOptions{Value: "123456", MaxLen: 5}); err == nil {
+		t.Errorf("Value longer than MaxLen accepted")
+	}
+
+	// Right-justified comb text fills the last cells.
+	ta := &textAppearance{fontSize: 10, quadding: 2, comb: 5}
+	w := ta.textWidth("1") * 10 / 1000
+	if content := ta.content(rect, "1"); !strings.Contains(content, fmt.Sprintf("%.4f ", 80+(20-w)/2)) {
+		t.Errorf("Wrong right-justified comb appearance %q", content)
+	}
+}
+
+// fieldAppearanceContent returns the content of the normal appearance of the widget of the field.
+func fieldAppearanceContent(t *testing.T, field *PdfField) string {
+	ap := field.KidsA[0].AP.(*PdfObjectDictionary)
+	content, err := DecodeStream(ap.Get("N").(*PdfObjectStream))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return string(content)
+}
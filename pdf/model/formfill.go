@@ -0,0 +1,787 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// Leading of multiline text and list boxes, relative to the font size.
+const fieldLeading = 1.15
+
+// fieldWidget is a widget annotation of a field whose value is set: its entries, and its new appearance dictionary
+// and appearance state, nil if unchanged.
+type fieldWidget struct {
+	Rect, MK, AP, DA PdfObject
+
+	newAP, newAS PdfObject
+}
+
+// SetFieldValue sets the value of the terminal field with the fully qualified name and regenerates the appearances
+// of its widgets.  The value of text fields and choice fields is the text, that of check boxes and radio buttons
+// the name of the appearance state of the selected widget, or Off.  The document is then written with a PdfWriter.
+func (this *PdfReader) SetFieldValue(name, value string) error {
+	if this.AcroForm == nil {
+		return errors.New("No form")
+	}
+	var field *PdfField
+	for _, f := range this.AcroForm.AllFields() {
+		if f.GetFullName() == name {
+			field = f
+			break
+		}
+	}
+	if field == nil {
+		common.Log.Debug("ERROR: Field %q not found", name)
+		return errors.New("Field not found")
+	}
+
+	annots := []*PdfAnnotationWidget{}
+	widgets := []*fieldWidget{}
+	for _, annot := range field.KidsA {
+		if widget, ok := annot.GetContext().(*PdfAnnotationWidget); ok {
+			annots = append(annots, widget)
+			widgets = append(widgets, &fieldWidget{Rect: widget.Rect, MK: widget.MK, AP: widget.AP,
+				DA: widget.primitive.PdfObject.(*PdfObjectDictionary).Get("DA")})
+		}
+	}
+	resolve := func(obj PdfObject) PdfObject {
+		return traceDirect(this, obj)
+	}
+	v, err := this.AcroForm.makeFieldValue(field.inherited, widgets, value, resolve)
+	if err != nil {
+		return err
+	}
+
+	field.V = v
+	for i, widget := range annots {
+		if widgets[i].newAP != nil {
+			widget.AP = widgets[i].newAP
+		}
+		if widgets[i].newAS != nil {
+			widget.AS = widgets[i].newAS
+		}
+	}
+	return nil
+}
+
+// SetFieldValue stages setting the value of the terminal field with the fully qualified name, and the regenerated
+// appearances of its widgets, as PdfReader.SetFieldValue.
+func (tx *PdfTransaction) SetFieldValue(name, value string) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+	reader := tx.reader
+	if reader.AcroForm == nil {
+		return errors.New("No form")
+	}
+	resolve := func(obj PdfObject) PdfObject {
+		return traceDirect(reader, obj)
+	}
+
+	acroForm, _ := resolve(reader.catalog.Get("AcroForm")).(*PdfObjectDictionary)
+	if acroForm == nil {
+		return errors.New("No form")
+	}
+	fields, _ := resolve(acroForm.Get("Fields")).(*PdfObjectArray)
+	container, widgetObjs := findFieldObject(reader, fields, "", name, 0)
+	if container == nil {
+		common.Log.Debug("ERROR: Field %q not found", name)
+		return errors.New("Field not found")
+	}
+
+	widgets := []*fieldWidget{}
+	for _, obj := range widgetObjs {
+		dict := obj.PdfObject.(*PdfObjectDictionary)
+		widgets = append(widgets, &fieldWidget{Rect: dict.Get("Rect"), MK: dict.Get("MK"), AP: dict.Get("AP"),
+			DA: dict.Get("DA")})
+	}
+	attr := func(key PdfObjectName) PdfObject {
+		dict, _ := container.PdfObject.(*PdfObjectDictionary)
+		for depth := 0; dict != nil && depth < 32; depth++ {
+			if obj := dict.Get(key); obj != nil {
+				return resolve(obj)
+			}
+			dict, _ = resolve(dict.Get("Parent")).(*PdfObjectDictionary)
+		}
+		return nil
+	}
+	v, err := reader.AcroForm.makeFieldValue(attr, widgets, value, resolve)
+	if err != nil {
+		return err
+	}
+
+	staged, err := tx.GetObject(container.ObjectNumber)
+	if err != nil {
+		return err
+	}
+	staged.(*PdfIndirectObject).PdfObject.(*PdfObjectDictionary).Set("V", v)
+	for i, obj := range widgetObjs {
+		staged, err := tx.GetObject(obj.ObjectNumber)
+		if err != nil {
+			return err
+		}
+		dict := staged.(*PdfIndirectObject).PdfObject.(*PdfObjectDictionary)
+		dict.SetIfNotNil("AP", widgets[i].newAP)
+		dict.SetIfNotNil("AS", widgets[i].newAS)
+	}
+	return nil
+}
+
+// findFieldObject returns the dictionary of the terminal field with the fully qualified name among the fields of
+// the array, whose parent has the name parentName, and the dictionaries of its widget annotations.
+func findFieldObject(reader *PdfReader, fields *PdfObjectArray, parentName, name string,
+	depth int) (*PdfIndirectObject, []*PdfIndirectObject) {
+	if fields == nil || depth > 32 {
+		return nil, nil
+	}
+	for _, obj := range *fields {
+		obj, err := reader.traceToObject(obj)
+		if err != nil {
+			continue
+		}
+		container, ok := obj.(*PdfIndirectObject)
+		if !ok {
+			continue
+		}
+		dict, ok := container.PdfObject.(*PdfObjectDictionary)
+		if !ok {
+			continue
+		}
+
+		fullName := parentName
+		if t, ok := traceDirect(reader, dict.Get("T")).(*PdfObjectString); ok {
+			if fullName != "" {
+				fullName += "."
+			}
+			fullName += t.Decoded()
+		}
+
+		// The widgets are the kids without field entries, or the field itself if merged.
+		kidFields := PdfObjectArray{}
+		widgets := []*PdfIndirectObject{}
+		if subtype, ok := traceDirect(reader, dict.Get("Subtype")).(*PdfObjectName); ok && *subtype == "Widget" {
+			widgets = append(widgets, container)
+		}
+		if kids, ok := traceDirect(reader, dict.Get("Kids")).(*PdfObjectArray); ok {
+			for _, kid := range *kids {
+				kidObj, err := reader.traceToObject(kid)
+				if err != nil {
+					continue
+				}
+				kidContainer, ok := kidObj.(*PdfIndirectObject)
+				if !ok {
+					continue
+				}
+				kidDict, ok := kidContainer.PdfObject.(*PdfObjectDictionary)
+				if !ok {
+					continue
+				}
+				if kidDict.Get("T") == nil && kidDict.Get("FT") == nil {
+					widgets = append(widgets, kidContainer)
+				} else {
+					kidFields = append(kidFields, kidContainer)
+				}
+			}
+		}
+
+		if len(kidFields) > 0 {
+			if field, fieldWidgets := findFieldObject(reader, &kidFields, fullName, name, depth+1); field != nil {
+				return field, fieldWidgets
+			}
+		} else if fullName == name {
+			return container, widgets
+		}
+	}
+	return nil, nil
+}
+
+// inherited returns the entry of the field dictionary with the key, inherited from the ancestors of the field if
+// not set.
+func (this *PdfField) inherited(key PdfObjectName) PdfObject {
+	for field := this; field != nil; field = field.Parent {
+		var obj PdfObject
+		switch key {
+		case "FT":
+			if field.FT != nil {
+				obj = field.FT
+			}
+		case "Ff":
+			obj = field.Ff
+		case "V":
+			obj = field.V
+		case "DA":
+			obj = field.DA
+		case "Q":
+			obj = field.Q
+		default:
+			obj = field.primitive.PdfObject.(*PdfObjectDictionary).Get(key)
+		}
+		if obj != nil {
+			return TraceToDirectObject(obj)
+		}
+	}
+	return nil
+}
+
+// makeFieldValue returns the value of the field with the entries attr(key) for the value, and sets the new
+// appearances of its widgets.
+func (this *PdfAcroForm) makeFieldValue(attr func(key PdfObjectName) PdfObject, widgets []*fieldWidget, value string,
+	resolve func(obj PdfObject) PdfObject) (PdfObject, error) {
+	fieldType := ""
+	if ft, ok := attr("FT").(*PdfObjectName); ok {
+		fieldType = string(*ft)
+	}
+	flags := int64(0)
+	if ff, ok := attr("Ff").(*PdfObjectInteger); ok {
+		flags = int64(*ff)
+	}
+
+	switch fieldType {
+	case "Tx":
+		maxLen := int64(0)
+		if n, ok := attr("MaxLen").(*PdfObjectInteger); ok {
+			maxLen = int64(*n)
+		}
+		if maxLen > 0 && int64(len([]rune(value))) > maxLen {
+			common.Log.Debug("ERROR: Value %q longer than MaxLen %d", value, maxLen)
+			return nil, errors.New("Value too long")
+		}
+		text := value
+		if flags&FieldFlagPassword != 0 {
+			text = strings.Repeat("*", len([]rune(text)))
+		}
+		for _, widget := range widgets {
+			ta, resources := this.widgetTextAppearance(attr, widget, resolve)
+			ta.multiline = flags&FieldFlagMultiline != 0
+			if flags&FieldFlagComb != 0 && flags&(FieldFlagMultiline|FieldFlagPassword) == 0 {
+				ta.comb = int(maxLen)
+				// The cells are divided in the border color.
+				if mk, ok := resolve(widget.MK).(*PdfObjectDictionary); ok {
+					if bc, ok := resolve(mk.Get("BC")).(*PdfObjectArray); ok {
+						ta.divider = colorOperator(bc, true, resolve)
+					}
+				}
+			}
+			err := widget.setAppearance(func(rect PdfRectangle) string { return ta.content(rect, text) }, resources, resolve)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return MakeTextString(value), nil
+
+	case "Ch":
+		options, displayed := choiceOptions(attr("Opt"), resolve)
+		selected := -1
+		for i, option := range options {
+			if option == value {
+				selected = i
+				break
+			}
+		}
+		combo := flags&FieldFlagCombo != 0
+		if selected < 0 && value != "" && !(combo && flags&FieldFlagEdit != 0) {
+			common.Log.Debug("ERROR: Value %q not an option", value)
+			return nil, errors.New("Invalid choice value")
+		}
+		text := value
+		if selected >= 0 {
+			text = displayed[selected]
+		}
+		for _, widget := range widgets {
+			ta, resources := this.widgetTextAppearance(attr, widget, resolve)
+			content := func(rect PdfRectangle) string { return ta.content(rect, text) }
+			if !combo {
+				content = func(rect PdfRectangle) string { return ta.listContent(rect, displayed, selected) }
+			}
+			if err := widget.setAppearance(content, resources, resolve); err != nil {
+				return nil, err
+			}
+		}
+		return MakeTextString(value), nil
+
+	case "Btn":
+		if flags&FieldFlagPushbutton != 0 {
+			return nil, errors.New("Push buttons have no value")
+		}
+		if value == "" {
+			value = "Off"
+		}
+		// The value must be the on state of a widget, unless none has appearance states.
+		states := make([]map[string]bool, len(widgets))
+		valid := value == "Off"
+		withStates := false
+		for i, widget := range widgets {
+			states[i] = map[string]bool{}
+			if ap, ok := resolve(widget.AP).(*PdfObjectDictionary); ok {
+				if n, ok := resolve(ap.Get("N")).(*PdfObjectDictionary); ok {
+					withStates = true
+					for _, key := range n.Keys() {
+						states[i][string(key)] = true
+					}
+				}
+			}
+			valid = valid || states[i][value]
+		}
+		if !valid && withStates {
+			common.Log.Debug("ERROR: Value %q not a button state", value)
+			return nil, errors.New("Invalid button state")
+		}
+
+		for i, widget := range widgets {
+			state := "Off"
+			if states[i][value] {
+				state = value
+			}
+			if len(states[i]) == 0 && flags&FieldFlagRadio == 0 {
+				// Check box without appearance states, given the usual ones.
+				onState := value
+				if onState == "Off" {
+					onState = "Yes"
+				}
+				state = value
+				rect, err := widget.rect(resolve)
+				if err != nil {
+					return nil, err
+				}
+				width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+				annot := NewPdfAnnotationWidget()
+				err = setButtonAppearances(annot, *rect, onState, state, fieldBorder(*rect), checkMark(width, height))
+				if err != nil {
+					return nil, err
+				}
+				widget.newAP = annot.AP
+			}
+			widget.newAS = MakeName(state)
+		}
+		return MakeName(value), nil
+	}
+
+	common.Log.Debug("ERROR: Cannot set the value of a field of type %q", fieldType)
+	return nil, errors.New("Unsupported field type")
+}
+
+// widgetTextAppearance returns the text layout of the widget from the default appearance (DA) and quadding (Q) of
+// the field or form, and the resources with the font.  Fonts not in the default resources of the form are replaced
+// by Helvetica.
+func (this *PdfAcroForm) widgetTextAppearance(attr func(key PdfObjectName) PdfObject, widget *fieldWidget,
+	resolve func(obj PdfObject) PdfObject) (*textAppearance, *PdfPageResources) {
+	da := ""
+	if s, ok := resolve(widget.DA).(*PdfObjectString); ok {
+		da = string(*s)
+	} else if s, ok := attr("DA").(*PdfObjectString); ok {
+		da = string(*s)
+	} else if this.DA != nil {
+		da = string(*this.DA)
+	}
+	ta := parseDefaultAppearance(da)
+
+	if q, ok := attr("Q").(*PdfObjectInteger); ok {
+		ta.quadding = int64(*q)
+	} else if this.Q != nil {
+		ta.quadding = int64(*this.Q)
+	}
+
+	var fontObj PdfObject
+	if this.DR != nil && ta.fontName != "" {
+		if obj, has := this.DR.GetFontByName(ta.fontName); has {
+			fontObj = obj
+			if font, err := NewPdfFontFromPdfObject(resolve(obj)); err == nil {
+				ta.font = font
+			}
+			if dict, ok := resolve(obj).(*PdfObjectDictionary); ok {
+				if baseFont, ok := resolve(dict.Get("BaseFont")).(*PdfObjectName); ok {
+					ta.standard = getStandardFontMetrics(string(*baseFont))
+				}
+			}
+		}
+	}
+	if fontObj == nil {
+		ta.fontName = formFontName
+		fontObj = fonts.NewFontHelvetica().ToPdfObject()
+	}
+	resources := NewPdfPageResources()
+	resources.SetFontByName(ta.fontName, fontObj)
+	return ta, resources
+}
+
+// rect returns the rectangle of the widget.
+func (widget *fieldWidget) rect(resolve func(obj PdfObject) PdfObject) (*PdfRectangle, error) {
+	arr, ok := resolve(widget.Rect).(*PdfObjectArray)
+	if !ok {
+		return nil, errors.New("Widget without rectangle")
+	}
+	return NewPdfRectangle(*arr)
+}
+
+// setAppearance sets the new normal appearance of the widget, with the background and border of its appearance
+// characteristics (MK) and the content of the text.
+func (widget *fieldWidget) setAppearance(content func(rect PdfRectangle) string, resources *PdfPageResources,
+	resolve func(obj PdfObject) PdfObject) error {
+	rect, err := widget.rect(resolve)
+	if err != nil {
+		return err
+	}
+	mk, _ := resolve(widget.MK).(*PdfObjectDictionary)
+	ap, err := makeFieldAppearance(*rect, widgetBorder(*rect, mk, resolve)+content(*rect), resources)
+	if err != nil {
+		return err
+	}
+	widget.newAP = makeAppearanceDict(ap)
+	return nil
+}
+
+// widgetBorder returns the content drawing the background and border of the widget in the colors of its appearance
+// characteristics, if any.
+func widgetBorder(rect PdfRectangle, mk *PdfObjectDictionary, resolve func(obj PdfObject) PdfObject) string {
+	if mk == nil {
+		return ""
+	}
+	width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+	var buf bytes.Buffer
+	if bg, ok := resolve(mk.Get("BG")).(*PdfObjectArray); ok {
+		if op := colorOperator(bg, false, resolve); op != "" {
+			fmt.Fprintf(&buf, "%s\n0 0 %.4f %.4f re f\n", op, width, height)
+		}
+	}
+	if bc, ok := resolve(mk.Get("BC")).(*PdfObjectArray); ok {
+		if op := colorOperator(bc, true, resolve); op != "" {
+			fmt.Fprintf(&buf, "%s\n1 w\n0.5 0.5 %.4f %.4f re S\n", op, width-1, height-1)
+		}
+	}
+	return buf.String()
+}
+
+// colorOperator returns the operator setting the color of the array (gray, RGB or CMYK) for filling or stroking,
+// empty if transparent.
+func colorOperator(arr *PdfObjectArray, stroke bool, resolve func(obj PdfObject) PdfObject) string {
+	ops := map[int]string{1: "g", 3: "rg", 4: "k"}
+	op, ok := ops[len(*arr)]
+	if !ok {
+		return ""
+	}
+	if stroke {
+		op = strings.ToUpper(op)
+	}
+	components := []string{}
+	for _, obj := range *arr {
+		val, err := getNumberAsFloat(resolve(obj))
+		if err != nil {
+			return ""
+		}
+		components = append(components, fmt.Sprintf("%.4f", val))
+	}
+	return strings.Join(components, " ") + " " + op
+}
+
+// choiceOptions returns the export values and displayed texts of the options (Opt) of a choice field.
+func choiceOptions(opt PdfObject, resolve func(obj PdfObject) PdfObject) ([]string, []string) {
+	values := []string{}
+	displayed := []string{}
+	arr, ok := resolve(opt).(*PdfObjectArray)
+	if !ok {
+		return values, displayed
+	}
+	for _, obj := range *arr {
+		switch t := resolve(obj).(type) {
+		case *PdfObjectString:
+			values = append(values, t.Decoded())
+			displayed = append(displayed, t.Decoded())
+		case *PdfObjectArray:
+			if len(*t) != 2 {
+				continue
+			}
+			value, ok1 := resolve((*t)[0]).(*PdfObjectString)
+			text, ok2 := resolve((*t)[1]).(*PdfObjectString)
+			if ok1 && ok2 {
+				values = append(values, value.Decoded())
+				displayed = append(displayed, text.Decoded())
+			}
+		}
+	}
+	return values, displayed
+}
+
+// textAppearance is the layout of the text of a variable text field widget.
+type textAppearance struct {
+	// The font, its metrics if available, and the size, 0 for auto size.
+	fontName PdfObjectName
+	font     fonts.Font
+	standard fonts.Font
+	fontSize float64
+
+	// The operators of the default appearance other than Tf, e.g. setting the color.
+	operators string
+
+	// 0 for left-justified text, 1 for centered and 2 for right-justified.
+	quadding int64
+
+	multiline bool
+
+	// The number of cells of comb fields, 0 otherwise, and the stroking color operator of the dividers between the
+	// cells, none if empty.
+	comb    int
+	divider string
+}
+
+// parseDefaultAppearance returns the text layout of the default appearance string (DA), e.g. "/Helv 0 Tf 0 g".
+func parseDefaultAppearance(da string) *textAppearance {
+	ta := &textAppearance{}
+	tokens := strings.Fields(da)
+	others := []string{}
+	for i := 0; i < len(tokens); i++ {
+		if i+2 < len(tokens) && tokens[i+2] == "Tf" && strings.HasPrefix(tokens[i], "/") {
+			ta.fontName = PdfObjectName(tokens[i][1:])
+			ta.fontSize, _ = strconv.ParseFloat(tokens[i+1], 64)
+			i += 2
+			continue
+		}
+		others = append(others, tokens[i])
+	}
+	ta.operators = strings.Join(others, " ")
+	return ta
+}
+
+// getStandardFontMetrics returns the standard font with the base font name, nil if not a standard text font.
+func getStandardFontMetrics(baseFont string) fonts.Font {
+	switch baseFont {
+	case "Helvetica":
+		return fonts.NewFontHelvetica()
+	case "Helvetica-Bold":
+		return fonts.NewFontHelveticaBold()
+	case "Helvetica-Oblique":
+		return fonts.NewFontHelveticaOblique()
+	case "Helvetica-BoldOblique":
+		return fonts.NewFontHelveticaBoldOblique()
+	case "Times-Roman":
+		return fonts.NewFontTimesRoman()
+	case "Times-Bold":
+		return fonts.NewFontTimesBold()
+	case "Times-Italic":
+		return fonts.NewFontTimesItalic()
+	case "Times-BoldItalic":
+		return fonts.NewFontTimesBoldItalic()
+	case "Courier":
+		return fonts.NewFontCourier()
+	case "Courier-Bold":
+		return fonts.NewFontCourierBold()
+	case "Courier-Oblique":
+		return fonts.NewFontCourierOblique()
+	case "Courier-BoldOblique":
+		return fonts.NewFontCourierBoldOblique()
+	}
+	return nil
+}
+
+// textWidth returns the width of the text in thousandths of the font size, with the metrics of the font, of the
+// standard font of its name or of Helvetica.
+func (ta *textAppearance) textWidth(text string) float64 {
+	encoder := textencoding.NewWinAnsiTextEncoder()
+	helvetica := fonts.NewFontHelvetica()
+	width := 0.0
+	for _, r := range text {
+		glyph, found := encoder.RuneToGlyph(r)
+		if !found {
+			continue
+		}
+		for _, font := range []fonts.Font{ta.font, ta.standard, helvetica} {
+			if font == nil {
+				continue
+			}
+			if metrics, found := font.GetGlyphCharMetrics(glyph); found {
+				width += metrics.Wx
+				break
+			}
+		}
+	}
+	return width
+}
+
+// wrapLines returns the lines of the text, wrapped at spaces to the width (thousandths of the font size).
+func (ta *textAppearance) wrapLines(text string, width float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			if line != "" && ta.textWidth(line+" "+word) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// lineOffset returns the horizontal position of a line of the width in the field of the width, by the quadding.
+func (ta *textAppearance) lineOffset(lineWidth, fieldWidth float64) float64 {
+	switch ta.quadding {
+	case 1:
+		return (fieldWidth - lineWidth) / 2
+	case 2:
+		return fieldWidth - 2 - lineWidth
+	}
+	return 2
+}
+
+// beginText returns the content beginning the text object with the font and operators of the appearance.
+func (ta *textAppearance) beginText(fontSize float64) string {
+	s := fmt.Sprintf("BT\n/%s %g Tf\n", ta.fontName, fontSize)
+	if ta.operators != "" {
+		s += ta.operators + "\n"
+	}
+	return s
+}
+
+// content returns the content showing the text in a field widget of the rectangle, within a marked content
+// sequence (/Tx BMC) clipped to the inside of the border.
+func (ta *textAppearance) content(rect PdfRectangle, text string) string {
+	width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+	encoder := textencoding.NewWinAnsiTextEncoder()
+
+	var buf bytes.Buffer
+	if ta.comb > 1 && ta.divider != "" {
+		cell := width / float64(ta.comb)
+		fmt.Fprintf(&buf, "q\n%s\n1 w\n", ta.divider)
+		for i := 1; i < ta.comb; i++ {
+			fmt.Fprintf(&buf, "%.4f 0 m\n%.4f %.4f l\n", float64(i)*cell, float64(i)*cell, height)
+		}
+		buf.WriteString("S\nQ\n")
+	}
+	buf.WriteString("/Tx BMC\nq\n")
+	fmt.Fprintf(&buf, "1 1 %.4f %.4f re W n\n", width-2, height-2)
+	if text != "" {
+		fontSize := ta.fontSize
+		switch {
+		case ta.multiline:
+			if fontSize <= 0 {
+				fontSize = defaultFieldFontSize
+			}
+			// Lines from the top, wrapped to the width.
+			lines := ta.wrapLines(text, (width-4)*1000/fontSize)
+			leading := fieldLeading * fontSize
+			buf.WriteString(ta.beginText(fontSize))
+			fmt.Fprintf(&buf, "%.4f TL\n", leading)
+			x := 0.0
+			for i, line := range lines {
+				lineX := ta.lineOffset(ta.textWidth(line)*fontSize/1000, width)
+				if i == 0 {
+					fmt.Fprintf(&buf, "%.4f %.4f Td\n", lineX, height-2-0.8*fontSize)
+				} else if ta.quadding == 0 {
+					buf.WriteString("T*\n")
+				} else {
+					fmt.Fprintf(&buf, "%.4f %.4f Td\n", lineX-x, -leading)
+				}
+				x = lineX
+				fmt.Fprintf(&buf, "%s Tj\n", MakeString(encoder.Encode(line)).DefaultWriteString())
+			}
+		case ta.comb > 0:
+			// A character centered in each cell, starting from the first, middle or last cells by quadding.
+			cell := width / float64(ta.comb)
+			runes := []rune(text)
+			first := 0
+			switch ta.quadding {
+			case 1:
+				first = (ta.comb - len(runes)) / 2
+			case 2:
+				first = ta.comb - len(runes)
+			}
+			if first < 0 {
+				first = 0
+			}
+			if fontSize <= 0 {
+				fontSize = (height - 4) / fieldLeading
+				if maxWidth := ta.textWidth("W") / 1000; maxWidth*fontSize > cell {
+					fontSize = cell / maxWidth
+				}
+			}
+			buf.WriteString(ta.beginText(fontSize))
+			x := 0.0
+			for i, r := range runes {
+				charX := float64(first+i)*cell + (cell-ta.textWidth(string(r))*fontSize/1000)/2
+				if i == 0 {
+					fmt.Fprintf(&buf, "%.4f %.4f Td\n", charX, (height-0.718*fontSize)/2)
+				} else {
+					fmt.Fprintf(&buf, "%.4f 0 Td\n", charX-x)
+				}
+				x = charX
+				fmt.Fprintf(&buf, "%s Tj\n", MakeString(encoder.Encode(string(r))).DefaultWriteString())
+			}
+		default:
+			// Auto size fits the height, and the width if the text is too long.
+			textWidth := ta.textWidth(text)
+			if fontSize <= 0 {
+				fontSize = (height - 4) / fieldLeading
+				if textWidth*fontSize/1000 > width-4 && textWidth > 0 {
+					fontSize = (width - 4) * 1000 / textWidth
+				}
+			}
+			// Centered vertically on the cap height of Helvetica.
+			buf.WriteString(ta.beginText(fontSize))
+			fmt.Fprintf(&buf, "%.4f %.4f Td\n", ta.lineOffset(textWidth*fontSize/1000, width),
+				(height-0.718*fontSize)/2)
+			fmt.Fprintf(&buf, "%s Tj\n", MakeString(encoder.Encode(text)).DefaultWriteString())
+		}
+		buf.WriteString("ET\n")
+	}
+	buf.WriteString("Q\nEMC\n")
+	return buf.String()
+}
+
+// listContent returns the content showing the options of a list box widget of the rectangle from the top, the
+// selected one (if not -1) highlighted.
+func (ta *textAppearance) listContent(rect PdfRectangle, options []string, selected int) string {
+	width, height := rect.Urx-rect.Llx, rect.Ury-rect.Lly
+	fontSize := ta.fontSize
+	if fontSize <= 0 {
+		fontSize = defaultFieldFontSize
+	}
+	leading := fieldLeading * fontSize
+	encoder := textencoding.NewWinAnsiTextEncoder()
+
+	var buf bytes.Buffer
+	if ta.comb > 1 && ta.divider != "" {
+		cell := width / float64(ta.comb)
+		fmt.Fprintf(&buf, "q\n%s\n1 w\n", ta.divider)
+		for i := 1; i < ta.comb; i++ {
+			fmt.Fprintf(&buf, "%.4f 0 m\n%.4f %.4f l\n", float64(i)*cell, float64(i)*cell, height)
+		}
+		buf.WriteString("S\nQ\n")
+	}
+	buf.WriteString("/Tx BMC\nq\n")
+	fmt.Fprintf(&buf, "1 1 %.4f %.4f re W n\n", width-2, height-2)
+	if selected >= 0 {
+		fmt.Fprintf(&buf, "0.6 0.75 0.85 rg\n1 %.4f %.4f %.4f re f\n",
+			height-1-float64(selected+1)*leading, width-2, leading)
+	}
+	if len(options) > 0 {
+		buf.WriteString(ta.beginText(fontSize))
+		fmt.Fprintf(&buf, "%.4f TL\n2 %.4f Td\n", leading, height-1-leading+0.25*fontSize)
+		for i, option := range options {
+			if i > 0 {
+				buf.WriteString("T*\n")
+			}
+			fmt.Fprintf(&buf, "%s Tj\n", MakeString(encoder.Encode(option)).DefaultWriteString())
+		}
+		buf.WriteString("ET\n")
+	}
+	buf.WriteString("Q\nEMC\n")
+	return buf.String()
+}
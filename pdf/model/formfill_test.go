@@ -0,0 +1,179 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// makeFillTestPdf returns a document with a form: a centered text field in blue Times, a comb field and a list box
+// with merged widgets, and a check box.
+func makeFillTestPdf() []byte {
+	return testutils.MakePdf([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [6 0 R 7 0 R 9 0 R 10 0 R] >>",
+		"<< /Fields [5 0 R 7 0 R 8 0 R 10 0 R] /DR << /Font << /Helv 11 0 R /TiRo 12 0 R >> >> /DA (/Helv 0 Tf 0 g) >>",
+		"<< /FT /Tx /T (name) /Q 1 /DA (/TiRo 10 Tf 0 0 1 rg) /Kids [6 0 R] >>",
+		"<< /Type /Annot /Subtype /Widget /Rect [0 0 100 20] /Parent 5 0 R /P 3 0 R /MK << /BG [1] /BC [0] >> >>",
+		"<< /FT /Tx /T (code) /Ff 16777216 /MaxLen 4 /Type /Annot /Subtype /Widget /Rect [0 30 80 50] /P 3 0 R >>",
+		"<< /FT /Btn /T (agree) /V /Off /Kids [9 0 R] >>",
+		"<< /Type /Annot /Subtype /Widget /Rect [0 60 10 70] /Parent 8 0 R /P 3 0 R " +
+			"/AP << /N << /On 13 0 R /Off 13 0 R >> >> /AS /Off >>",
+		"<< /FT /Ch /T (color) /Opt [(red) [(g) (green)]] /Type /Annot /Subtype /Widget /Rect [0 80 100 120] /P 3 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Times-Roman >>",
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 10 10] /Length 0 >>\nstream\n\nendstream",
+	})
+}
+
+// setFillTestValues sets the values of the fields of the test document, and checks the invalid values.
+func setFillTestValues(t *testing.T, setFieldValue func(name, value string) error) {
+	for _, tc := range []struct{ name, value string }{
+		{"name", "Alice"},
+		{"code", "1234"},
+		{"agree", "On"},
+		{"color", "g"},
+	} {
+		if err := setFieldValue(tc.name, tc.value); err != nil {
+			t.Fatalf("Error setting %s: %v", tc.name, err)
+		}
+	}
+	for _, tc := range []struct{ name, value string }{
+		{"missing", "value"},
+		{"code", "12345"},
+		{"agree", "Yes"},
+		{"color", "blue"},
+	} {
+		if err := setFieldValue(tc.name, tc.value); err == nil {
+			t.Errorf("Missing error setting %s to %q", tc.name, tc.value)
+		}
+	}
+}
+
+// checkFillTestValues checks the values and appearances of the filled test document.
+func checkFillTestValues(t *testing.T, data []byte) {
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	fields := map[string]*PdfField{}
+	for _, field := range reader.AcroForm.AllFields() {
+		fields[field.GetFullName()] = field
+	}
+	for name, expected := range map[string]string{"name": "(Alice)", "code": "(1234)", "agree": "/On", "color": "(g)"} {
+		field, has := fields[name]
+		if !has {
+			t.Fatalf("Missing field %s", name)
+		}
+		if v := TraceToDirectObject(field.V); v == nil || v.DefaultWriteString() != expected {
+			t.Errorf("Field %s: wrong value %v, expected %s", name, v, expected)
+		}
+	}
+
+	content := func(name string) string {
+		if len(fields[name].KidsA) != 1 {
+			t.Fatalf("Field %s: wrong number of widgets %d", name, len(fields[name].KidsA))
+		}
+		widget := fields[name].KidsA[0]
+		ap, ok := TraceToDirectObject(widget.AP).(*PdfObjectDictionary)
+		if !ok {
+			t.Fatalf("Field %s: missing appearance", name)
+		}
+		stream, ok := TraceToDirectObject(ap.Get("N")).(*PdfObjectStream)
+		if !ok {
+			t.Fatalf("Field %s: wrong appearance %v", name, ap.Get("N"))
+		}
+		data, err := DecodeStream(stream)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		return string(data)
+	}
+
+	// Centered in the font and color of the default appearance, with the border of the widget.
+	ta := &textAppearance{standard: fonts.NewFontTimesRoman()}
+	x := (100 - ta.textWidth("Alice")*10/1000) / 2
+	name := content("name")
+	for _, expected := range []string{"/TiRo 10 Tf\n0 0 1 rg\n", fmt.Sprintf("%.4f %.4f Td\n(Alice) Tj", x, (20-7.18)/2),
+		"1.0000 g\n0 0 100.0000 20.0000 re f\n"} {
+		if !strings.Contains(name, expected) {
+			t.Errorf("Wrong text field appearance %q, missing %q", name, expected)
+		}
+	}
+	// A character in each of the cells of the comb field.
+	if code := content("code"); strings.Count(code, " Tj") != 4 || !strings.Contains(code, "(4) Tj") {
+		t.Errorf("Wrong comb field appearance %q", code)
+	}
+	// The displayed text of the selected option highlighted.
+	if color := content("color"); !strings.Contains(color, "0.6 0.75 0.85 rg") || !strings.Contains(color, "(green) Tj") {
+		t.Errorf("Wrong list box appearance %q", color)
+	}
+	if as, ok := TraceToDirectObject(fields["agree"].KidsA[0].AS).(*PdfObjectName); !ok || *as != "On" {
+		t.Errorf("Wrong check box state %v", fields["agree"].KidsA[0].AS)
+	}
+}
+
+func TestSetFieldValue(t *testing.T) {
+	reader, err := NewPdfReader(bytes.NewReader(makeFillTestPdf()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	setFillTestValues(t, reader.SetFieldValue)
+
+	w := NewPdfWriter()
+	page, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = w.SetForms(reader.AcroForm); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkFillTestValues(t, buf.Bytes())
+}
+
+func TestTransactionSetFieldValue(t *testing.T) {
+	original := makeFillTestPdf()
+	reader, err := NewPdfReader(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	tx, err := reader.Begin()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	setFillTestValues(t, tx.SetFieldValue)
+
+	// The loaded document is not modified.
+	for _, field := range reader.AcroForm.AllFields() {
+		if field.GetFullName() == "name" && field.V != nil {
+			t.Errorf("Field of the reader modified")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = tx.Commit(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), original) {
+		t.Fatalf("Not an incremental update")
+	}
+	checkFillTestValues(t, buf.Bytes())
+}
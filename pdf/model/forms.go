@@ -212,6 +212,9 @@ type PdfField struct {
 	DS PdfObject
 	RV PdfObject
 
+	// Signature fields: the fields locked when the signature is applied (see PdfSignatureFieldLock).
+	Lock PdfObject
+
 	primitive *PdfIndirectObject
 }
 
@@ -272,6 +275,16 @@ func (r *PdfReader) newPdfFieldFromIndirectObject(container *PdfIndirectObject,
 	field.DS = d.Get("DS")
 	field.RV = d.Get("RV")
 
+	// Signature fields:
+	field.Lock = d.Get("Lock")
+
+	// Field type specific entries not in the model.
+	for _, key := range []PdfObjectName{"MaxLen", "Opt", "TI", "I"} {
+		if obj := d.Get(key); obj != nil {
+			field.setEntry(key, obj)
+		}
+	}
+
 	// In a non-terminal field, the Kids array shall refer to field dictionaries that are immediate descendants of this field.
 	// In a terminal field, the Kids array ordinarily shall refer to one or more separate widget annotations that are associated
 	// with this field. However, if there is only one associated widget annotation, and its contents have been merged into the field
@@ -303,12 +316,13 @@ func (r *PdfReader) newPdfFieldFromIndirectObject(container *PdfIndirectObject,
 			if err != nil {
 				return nil, err
 			}
-			widget, ok := annot.GetContext().(*PdfAnnotationWidget)
+			_, ok := annot.GetContext().(*PdfAnnotationWidget)
 			if !ok {
 				return nil, fmt.Errorf("Invalid widget")
 			}
 
-			widget.Parent = field.GetContainingPdfObject()
+			// The field and the widget share the dictionary.
+			field.primitive = container
 			field.KidsA = append(field.KidsA, annot)
 			return field, nil
 		}
@@ -324,7 +338,6 @@ func (r *PdfReader) newPdfFieldFromIndirectObject(container *PdfIndirectObject,
 			return nil, fmt.Errorf("Kids not an array (%T)", obj)
 		}
 
-		field.KidsF = []PdfModel{}
 		for _, obj := range *fieldArray {
 			obj, err := r.traceToObject(obj)
 			if err != nil {
@@ -336,6 +349,21 @@ func (r *PdfReader) newPdfFieldFromIndirectObject(container *PdfIndirectObject,
 				return nil, fmt.Errorf("Not an indirect object (form field)")
 			}
 
+			// The widget annotations of a terminal field, i.e. widgets without field entries.
+			if kid, ok := container.PdfObject.(*PdfObjectDictionary); ok && kid.Get("T") == nil && kid.Get("FT") == nil {
+				if subtype, ok := TraceToDirectObject(kid.Get("Subtype")).(*PdfObjectName); ok && *subtype == "Widget" {
+					annot, err := r.newPdfAnnotationFromIndirectObject(container)
+					if err != nil {
+						return nil, err
+					}
+					if widget, ok := annot.GetContext().(*PdfAnnotationWidget); ok {
+						widget.Parent = field.GetContainingPdfObject()
+						field.KidsA = append(field.KidsA, annot)
+						continue
+					}
+				}
+			}
+
 			childField, err := r.newPdfFieldFromIndirectObject(container, field)
 			if err != nil {
 				return nil, err
@@ -373,12 +401,17 @@ func (this *PdfField) ToPdfObject() PdfObject {
 	}
 	if this.KidsA != nil {
 		common.Log.Trace("KidsA: %+v", this.KidsA)
-		_, hasKids := dict.Get("Kids").(*PdfObjectArray)
-		if !hasKids {
-			dict.Set("Kids", &PdfObjectArray{})
-		}
-		arr := dict.Get("Kids").(*PdfObjectArray)
 		for _, child := range this.KidsA {
+			// A widget merged into the field dictionary is not a kid.
+			if child.GetContainingPdfObject() == container {
+				child.GetContext().ToPdfObject()
+				continue
+			}
+			_, hasKids := dict.Get("Kids").(*PdfObjectArray)
+			if !hasKids {
+				dict.Set("Kids", &PdfObjectArray{})
+			}
+			arr := dict.Get("Kids").(*PdfObjectArray)
 			*arr = append(*arr, child.GetContext().ToPdfObject())
 		}
 	}
@@ -415,5 +448,8 @@ func (this *PdfField) ToPdfObject() PdfObject {
 	dict.SetIfNotNil("DS", this.DS)
 	dict.SetIfNotNil("RV", this.RV)
 
+	// Signature fields:
+	dict.SetIfNotNil("Lock", this.Lock)
+
 	return container
 }
@@ -235,8 +235,9 @@ type DefaultImageHandler struct{}
 func (this DefaultImageHandler) NewImageFromGoImage(goimg goimage.Image) (*Image, error) {
 	// Speed up jpeg encoding by converting to RGBA first.
 	// Will not be required once the golang image/jpeg package is optimized.
+	// The colors are not premultiplied by the alpha, as expected with a soft mask (SMask).
 	b := goimg.Bounds()
-	m := goimage.NewRGBA(goimage.Rect(0, 0, b.Dx(), b.Dy()))
+	m := goimage.NewNRGBA(goimage.Rect(0, 0, b.Dx(), b.Dy()))
 	draw.Draw(m, m.Bounds(), goimg, b.Min, draw.Src)
 
 	alphaData := []byte{}
@@ -6,7 +6,13 @@
 package model
 
 import (
+	"bytes"
+	goimage "image"
+	gocolor "image/color"
+	"reflect"
 	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
 )
 
 func TestImageResampling(t *testing.T) {
@@ -64,3 +70,61 @@ func TestImageResampling(t *testing.T) {
 		t.Errorf("Value != 64 (%d)", img.Data[1])
 	}
 }
+
+func TestImageAlphaFromGoImage(t *testing.T) {
+	// A half transparent red pixel and an opaque blue pixel.
+	goimg := goimage.NewNRGBA(goimage.Rect(0, 0, 2, 1))
+	goimg.Set(0, 0, gocolor.NRGBA{R: 255, A: 128})
+	goimg.Set(1, 0, gocolor.NRGBA{B: 255, A: 255})
+
+	img, err := DefaultImageHandler{}.NewImageFromGoImage(goimg)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The colors are not premultiplied by the alpha.
+	if !bytes.Equal(img.Data, []byte{255, 0, 0, 0, 0, 255}) || !bytes.Equal(img.alphaData, []byte{128, 255}) {
+		t.Errorf("Wrong data %v alpha %v", img.Data, img.alphaData)
+	}
+
+	// The soft mask is not DCT encoded with the image.
+	encoder := NewDCTEncoder()
+	encoder.Width, encoder.Height = 2, 1
+	ximg, err := NewXObjectImageFromImage(img, nil, encoder)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	smask, ok := TraceToDirectObject(ximg.SMask).(*PdfObjectStream)
+	if !ok {
+		t.Fatalf("SMask missing")
+	}
+	alpha, err := DecodeStream(smask)
+	if err != nil || !bytes.Equal(alpha, []byte{128, 255}) {
+		t.Errorf("Wrong SMask data %v (%v)", alpha, err)
+	}
+}
+
+func TestImageColorKeyMask(t *testing.T) {
+	img := &Image{Width: 2, Height: 1, BitsPerComponent: 8, ColorComponents: 3, Data: []byte{0, 255, 0, 255, 0, 0}}
+	ximg, err := NewXObjectImageFromImage(img, nil, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	invalid := [][]int{{0, 10, 240, 255}, {0, 10, 240, 256, 0, 10}, {10, 0, 240, 255, 0, 10}}
+	for _, ranges := range invalid {
+		if err := ximg.SetColorKeyMask(ranges); err == nil {
+			t.Errorf("Invalid mask %v accepted", ranges)
+		}
+	}
+	if err := ximg.SetColorKeyMask([]int{0, 10, 240, 255, 0, 10}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	loaded, err := NewXObjectImageFromStream(ximg.ToPdfObject().(*PdfObjectStream))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	ranges, err := loaded.GetColorKeyMask()
+	if err != nil || !reflect.DeepEqual(ranges, []int{0, 10, 240, 255, 0, 10}) {
+		t.Errorf("Wrong mask %v (%v)", ranges, err)
+	}
+}
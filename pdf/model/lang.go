@@ -0,0 +1,141 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// GetLanguage returns the natural language of the text of the document (Lang entry of the catalog), a language tag
+// such as "en-US", or "" if not specified.
+func (this *PdfReader) GetLanguage() (string, error) {
+	if this.requiresDecryption() {
+		return "", errors.New("File need to be decrypted first")
+	}
+
+	obj, err := this.traceToObject(this.catalog.Get("Lang"))
+	if err != nil {
+		return "", err
+	}
+	return getLanguageString(obj), nil
+}
+
+// SetLanguage sets the natural language of the text of the document (Lang entry of the catalog) to a language tag
+// (BCP 47) such as "en-US", or removes it if empty.  The language is required by accessibility standards (PDF/UA)
+// and used by screen readers and text extraction.
+func (this *PdfWriter) SetLanguage(lang string) error {
+	if lang == "" {
+		this.catalog.Remove("Lang")
+		return nil
+	}
+	if !IsValidLanguageTag(lang) {
+		common.Log.Debug("ERROR: Invalid language tag %q", lang)
+		return ErrRangeError
+	}
+	this.catalog.Set("Lang", MakeTextString(lang))
+	return nil
+}
+
+// SetLanguage sets the natural language of the text of the document in the update, or removes it if empty.
+func (tx *PdfTransaction) SetLanguage(lang string) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+	if lang != "" && !IsValidLanguageTag(lang) {
+		common.Log.Debug("ERROR: Invalid language tag %q", lang)
+		return ErrRangeError
+	}
+
+	catalog, err := tx.getCatalog()
+	if err != nil {
+		return err
+	}
+	if lang == "" {
+		catalog.Remove("Lang")
+	} else {
+		catalog.Set("Lang", MakeTextString(lang))
+	}
+	return nil
+}
+
+// GetStructElementLanguage returns the language of the content of a structure element: its Lang entry, or the
+// language of the closest ancestor (P) specifying one, or the language of the document.
+func (this *PdfReader) GetStructElementLanguage(elem PdfObject) (string, error) {
+	visited := map[PdfObject]bool{}
+	for elem != nil && !visited[elem] {
+		visited[elem] = true
+		obj, err := this.traceToObject(elem)
+		if err != nil {
+			return "", err
+		}
+		dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+		if !ok {
+			break
+		}
+		if typ, ok := TraceToDirectObject(dict.Get("Type")).(*PdfObjectName); ok && *typ == "StructTreeRoot" {
+			break
+		}
+		if lang := dict.Get("Lang"); lang != nil {
+			obj, err := this.traceToObject(lang)
+			if err != nil {
+				return "", err
+			}
+			return getLanguageString(obj), nil
+		}
+		elem = dict.Get("P")
+	}
+	return this.GetLanguage()
+}
+
+// SetStructElementLanguage sets the language of the content of a structure element dictionary, overriding the
+// language of its ancestors and of the document, or removes it if empty.  The same applies to the property lists of
+// marked content sequences (e.g. /Span << /Lang (de) >> BDC).
+func SetStructElementLanguage(elem *PdfObjectDictionary, lang string) error {
+	if lang == "" {
+		elem.Remove("Lang")
+		return nil
+	}
+	if !IsValidLanguageTag(lang) {
+		common.Log.Debug("ERROR: Invalid language tag %q", lang)
+		return ErrRangeError
+	}
+	elem.Set("Lang", MakeTextString(lang))
+	return nil
+}
+
+// IsValidLanguageTag returns true if lang is well formed as a language tag (BCP 47): subtags of 1 to 8 letters and
+// digits separated by hyphens, starting with a primary language subtag of letters, e.g. "en", "en-US" or
+// "zh-Hant-TW".  The subtags are not checked against the language subtag registry.
+func IsValidLanguageTag(lang string) bool {
+	if lang == "" {
+		return false
+	}
+	for i, subtag := range strings.Split(lang, "-") {
+		if len(subtag) < 1 || len(subtag) > 8 {
+			return false
+		}
+		for _, c := range subtag {
+			isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+			if !isLetter && (i == 0 || c < '0' || c > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getLanguageString returns the decoded text of a Lang entry, or "" if not a string.
+func getLanguageString(obj PdfObject) string {
+	str, ok := TraceToDirectObject(obj).(*PdfObjectString)
+	if !ok {
+		return ""
+	}
+	return DecodeTextString(string(*str))
+}
@@ -0,0 +1,95 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+)
+
+func TestLanguageTags(t *testing.T) {
+	for _, lang := range []string{"en", "en-US", "zh-Hant-TW", "de-CH-1996", "x-private"} {
+		if !IsValidLanguageTag(lang) {
+			t.Errorf("Valid tag %q rejected", lang)
+		}
+	}
+	for _, lang := range []string{"", "en_US", "1en", "en--US", "en-", "toolongtag", "fr-ÿ"} {
+		if IsValidLanguageTag(lang) {
+			t.Errorf("Invalid tag %q accepted", lang)
+		}
+	}
+}
+
+func TestDocumentLanguage(t *testing.T) {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+	page.Resources = NewPdfPageResources()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := w.SetLanguage("en US"); err == nil {
+		t.Errorf("Invalid language accepted")
+	}
+	if err := w.SetLanguage("en-GB"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if lang, err := reader.GetLanguage(); err != nil || lang != "en-GB" {
+		t.Fatalf("Wrong language %q (%v)", lang, err)
+	}
+
+	// Changed in an incremental update.
+	data := updateObjects(t, buf.Bytes(), func(tx *PdfTransaction) {
+		if err := tx.SetLanguage("fr-CA"); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	})
+	reader, err = NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if lang, err := reader.GetLanguage(); err != nil || lang != "fr-CA" {
+		t.Errorf("Wrong updated language %q (%v)", lang, err)
+	}
+}
+
+func TestStructElementLanguage(t *testing.T) {
+	data := testutils.MakePdf([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Lang (en) /StructTreeRoot 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /StructTreeRoot /K [5 0 R] >>",
+		"<< /Type /StructElem /S /Sect /P 4 0 R /K [6 0 R 7 0 R] >>",
+		"<< /Type /StructElem /S /P /P 5 0 R /Lang (de) /K [8 0 R] >>",
+		"<< /Type /StructElem /S /P /P 5 0 R >>",
+		"<< /Type /StructElem /S /Span /P 6 0 R >>",
+	})
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := map[int]string{5: "en", 6: "de", 7: "en", 8: "de"}
+	for num, exp := range expected {
+		elem, err := reader.GetIndirectObjectByNumber(num)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if lang, err := reader.GetStructElementLanguage(elem); err != nil || lang != exp {
+			t.Errorf("Object %d: language %q != %q (%v)", num, lang, exp, err)
+		}
+	}
+}
@@ -0,0 +1,512 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// SetLinearized sets whether the document is written linearized, for Fast Web View (Annex F of the PDF
+// specification): the document catalog and the objects of the first page are written first, followed by the objects
+// of each other page in order and the objects shared by several pages, with hint tables giving their location, so
+// that viewers can display the first page, and any page requested, before the whole file is downloaded.  Not
+// supported with FlushPages.
+func (this *PdfWriter) SetLinearized(linearized bool) {
+	this.linearized = linearized
+}
+
+// linearLayout is the order of the objects of a linearized file, by part (F.3 Linearized PDF Document Structure).
+type linearLayout struct {
+	// Document catalog and other document-level objects (part 4).
+	docObjects []PdfObject
+	// First page section (part 6), the page object first.
+	firstPage []PdfObject
+	// Sections of the other pages (part 7), each page object first.
+	pageSections [][]PdfObject
+	// Objects shared by several pages other than the first (part 8).
+	shared []PdfObject
+	// Other objects (part 9).
+	others []PdfObject
+
+	// The objects of the first page section and of the shared objects section referenced by the other pages.
+	sharedRefs [][]PdfObject
+}
+
+// writeLinearized writes the document linearized, once complete.
+func (this *PdfWriter) writeLinearized(w io.Writer) error {
+	if this.output != nil {
+		return errors.New("Linearized output not supported with FlushPages")
+	}
+	pages, err := collectTreePages(this.pages)
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return errors.New("No pages to linearize")
+	}
+	if this.ids == nil {
+		this.generateIds()
+	}
+	layout := this.layoutLinearized(pages)
+
+	// The objects of parts 7 to 9 are numbered first, so that the first-page cross-reference table covers the
+	// objects of the first part, numbered after them.
+	second := []PdfObject{}
+	for _, section := range layout.pageSections {
+		second = append(second, section...)
+	}
+	second = append(second, layout.shared...)
+	second = append(second, layout.others...)
+	linDict := MakeIndirectObject(MakeDict())
+	hint := &PdfObjectStream{PdfObjectDictionary: MakeDict()}
+	first := []PdfObject{linDict}
+	first = append(first, layout.docObjects...)
+	first = append(first, hint)
+	first = append(first, layout.firstPage...)
+	this.objects = append(second, first...)
+	this.updateObjectNumbers()
+	numSecond := len(second)
+
+	data := map[PdfObject][]byte{}
+	for idx, obj := range this.objects {
+		if obj == linDict || obj == hint {
+			continue
+		}
+		if this.crypter != nil && obj != this.encryptObj {
+			err := this.crypter.Encrypt(obj, int64(idx+1), 0)
+			if err != nil {
+				common.Log.Debug("ERROR: Failed encrypting (%s)", err)
+				return err
+			}
+		}
+		data[obj] = this.objectBytes(idx+1, obj)
+	}
+
+	this.startOutput(w)
+	headerLen := this.offset()
+	linLen := int64(len(linearizationDict(numSecond+1, 0, 0, 0, 0, 0, 0, 0)))
+	firstXrefLen := int64(len(this.firstPageXref(numSecond, nil, 0)))
+
+	// Offsets of the objects, as if the hint stream was not present, as in the hint tables.
+	offsets := map[PdfObject]int64{}
+	offset := headerLen + linLen + firstXrefLen
+	for _, obj := range layout.docObjects {
+		offsets[obj] = offset
+		offset += int64(len(data[obj]))
+	}
+	hintOffset := offset
+	for _, objs := range [][]PdfObject{layout.firstPage, second} {
+		for _, obj := range objs {
+			offsets[obj] = offset
+			offset += int64(len(data[obj]))
+		}
+	}
+	firstPageEnd := hintOffset
+	for _, obj := range layout.firstPage {
+		firstPageEnd += int64(len(data[obj]))
+	}
+	mainXrefOffset := offset
+
+	hintData, sharedOffset := buildHintTables(layout, data, offsets)
+	hint.Stream = hintData
+	hint.Set("Length", MakeInteger(int64(len(hintData))))
+	hint.Set("S", MakeInteger(int64(sharedOffset)))
+	hintNum := numSecond + 2 + len(layout.docObjects)
+	if this.crypter != nil {
+		err := this.crypter.Encrypt(hint, int64(hintNum), 0)
+		if err != nil {
+			common.Log.Debug("ERROR: Failed encrypting (%s)", err)
+			return err
+		}
+	}
+	data[hint] = this.objectBytes(hintNum, hint)
+	hintLen := int64(len(data[hint]))
+
+	// The actual offsets of the objects after the hint stream.
+	for obj, off := range offsets {
+		if off >= hintOffset {
+			offsets[obj] = off + hintLen
+		}
+	}
+	offsets[linDict] = headerLen
+	offsets[hint] = hintOffset
+	firstPageEnd += hintLen
+	mainXrefOffset += hintLen
+
+	mainXref := fmt.Sprintf("xref\n0 %d", numSecond+1)
+	var xref bytes.Buffer
+	xref.WriteString(mainXref)
+	xref.WriteString("\n")
+	xref.WriteString(fmt.Sprintf("%.10d %.5d f\r\n", 0, 65535))
+	for _, obj := range second {
+		xref.WriteString(fmt.Sprintf("%.10d %.5d n\r\n", offsets[obj], 0))
+	}
+	xref.WriteString(fmt.Sprintf("trailer\n<< /Size %d >>\nstartxref\n%d\n%%%%EOF\n", numSecond+1, headerLen+linLen))
+	fileLen := mainXrefOffset + int64(xref.Len())
+
+	firstPageNum, _ := objectNumber(layout.firstPage[0])
+	this.writer.WriteString(linearizationDict(numSecond+1, fileLen, hintOffset, hintLen,
+		int64(firstPageNum), firstPageEnd, len(pages), mainXrefOffset+int64(len(mainXref))))
+	this.writer.WriteString(this.firstPageXref(numSecond, offsets, mainXrefOffset))
+	for _, obj := range first[1:] {
+		this.writer.Write(data[obj])
+	}
+	for _, obj := range second {
+		this.writer.Write(data[obj])
+	}
+	this.writer.Write(xref.Bytes())
+	return this.writer.Flush()
+}
+
+// layoutLinearized assigns the objects of the document to the parts of the linearized file.
+func (this *PdfWriter) layoutLinearized(pages []*PdfIndirectObject) *linearLayout {
+	isObject := map[PdfObject]bool{}
+	for _, obj := range this.objects {
+		isObject[obj] = true
+	}
+
+	layout := &linearLayout{}
+	assigned := map[PdfObject]bool{}
+	layout.docObjects = append(layout.docObjects, this.root)
+	assigned[this.root] = true
+	if this.encryptObj != nil {
+		layout.docObjects = append(layout.docObjects, this.encryptObj)
+		assigned[this.encryptObj] = true
+	}
+
+	inFirstPage := map[PdfObject]bool{}
+	for _, obj := range pageObjects(pages[0], isObject) {
+		if !assigned[obj] {
+			layout.firstPage = append(layout.firstPage, obj)
+			assigned[obj] = true
+			inFirstPage[obj] = true
+		}
+	}
+
+	// The objects of the other pages, by number of pages using them.
+	pageObjs := [][]PdfObject{}
+	users := map[PdfObject]int{}
+	for _, page := range pages[1:] {
+		objs := pageObjects(page, isObject)
+		pageObjs = append(pageObjs, objs)
+		for _, obj := range objs {
+			users[obj]++
+		}
+	}
+	isShared := map[PdfObject]bool{}
+	for _, objs := range pageObjs {
+		section := []PdfObject{}
+		for _, obj := range objs {
+			if assigned[obj] {
+				continue
+			}
+			if users[obj] > 1 {
+				isShared[obj] = true
+				continue
+			}
+			section = append(section, obj)
+			assigned[obj] = true
+		}
+		layout.pageSections = append(layout.pageSections, section)
+	}
+	for _, objs := range pageObjs {
+		for _, obj := range objs {
+			if isShared[obj] && !assigned[obj] {
+				layout.shared = append(layout.shared, obj)
+				assigned[obj] = true
+			}
+		}
+	}
+	for _, objs := range pageObjs {
+		refs := []PdfObject{}
+		for _, obj := range objs {
+			if inFirstPage[obj] || isShared[obj] {
+				refs = append(refs, obj)
+			}
+		}
+		layout.sharedRefs = append(layout.sharedRefs, refs)
+	}
+
+	for _, obj := range this.objects {
+		if !assigned[obj] {
+			layout.others = append(layout.others, obj)
+		}
+	}
+	return layout
+}
+
+// pageObjects returns the page object and the objects it uses, in the order they are referenced: the objects
+// referenced from the page, except its parents and the other pages (e.g. destinations of links), the page tree and
+// the catalog.  Only the objects written are returned.
+func pageObjects(page *PdfIndirectObject, isObject map[PdfObject]bool) []PdfObject {
+	objs := []PdfObject{page}
+	visited := map[PdfObject]bool{page: true}
+
+	var walk func(obj PdfObject)
+	walk = func(obj PdfObject) {
+		switch t := obj.(type) {
+		case *PdfIndirectObject:
+			if visited[t] || !isObject[t] || isDocumentNode(t.PdfObject) {
+				return
+			}
+			visited[t] = true
+			objs = append(objs, t)
+			walk(t.PdfObject)
+		case *PdfObjectStream:
+			if visited[t] || !isObject[t] {
+				return
+			}
+			visited[t] = true
+			objs = append(objs, t)
+			walk(t.PdfObjectDictionary)
+		case *PdfObjectDictionary:
+			for _, key := range t.Keys() {
+				if key != "Parent" {
+					walk(t.Get(key))
+				}
+			}
+		case *PdfObjectArray:
+			for _, item := range *t {
+				walk(item)
+			}
+		}
+	}
+	walk(page.PdfObject)
+	return objs
+}
+
+// isDocumentNode returns true for the dictionaries of pages, page tree nodes and the catalog.
+func isDocumentNode(obj PdfObject) bool {
+	dict, ok := obj.(*PdfObjectDictionary)
+	if !ok {
+		return false
+	}
+	name, ok := dict.Get("Type").(*PdfObjectName)
+	return ok && (*name == "Page" || *name == "Pages" || *name == "Catalog")
+}
+
+// objectBytes returns the serialization of the object with the number.
+func (this *PdfWriter) objectBytes(num int, obj PdfObject) []byte {
+	var buf bytes.Buffer
+	saved := this.writer
+	this.writer = bufio.NewWriter(&buf)
+	this.writeObject(num, obj)
+	this.writer.Flush()
+	this.writer = saved
+	return buf.Bytes()
+}
+
+// linearizationDict returns the linearization parameter dictionary object, of fixed length.
+func linearizationDict(num int, fileLen, hintOffset, hintLen, firstPageObj, firstPageEnd int64, numPages int,
+	mainXrefEntries int64) string {
+	return fmt.Sprintf("%d 0 obj\n<< /Linearized 1 /L %10d /H [%10d %10d] /O %10d /E %10d /N %10d /T %10d >>\nendobj\n",
+		num, fileLen, hintOffset, hintLen, firstPageObj, firstPageEnd, numPages, mainXrefEntries)
+}
+
+// firstPageXref returns the first-page cross-reference table and trailer, of fixed length, for the objects of the
+// first part numbered after the numSecond objects of the second part.
+func (this *PdfWriter) firstPageXref(numSecond int, offsets map[PdfObject]int64, mainXrefOffset int64) string {
+	first := this.objects[numSecond:]
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("xref\n%d %d\n", numSecond+1, len(first)))
+	for _, obj := range first {
+		buf.WriteString(fmt.Sprintf("%.10d %.5d n\r\n", offsets[obj], 0))
+	}
+
+	trailer := MakeDict()
+	trailer.Set("Size", MakeInteger(int64(len(this.objects)+1)))
+	trailer.Set("Info", this.infoObj)
+	trailer.Set("Root", this.root)
+	if this.crypter != nil {
+		trailer.Set("Encrypt", this.encryptObj)
+	}
+	trailer.Set("ID", this.ids)
+	str := trailer.DefaultWriteString()
+	buf.WriteString("trailer\n")
+	buf.WriteString(str[:len(str)-2])
+	buf.WriteString(fmt.Sprintf("/Prev %10d>>\nstartxref\n0\n%%%%EOF\n", mainXrefOffset))
+	return buf.String()
+}
+
+// buildHintTables returns the data of the primary hint stream, with the page offset hint table and the shared object
+// hint table (F.4 Hint Tables), and the offset of the shared object hint table in it.  Offsets are those of the file
+// without the hint stream.
+func buildHintTables(layout *linearLayout, data map[PdfObject][]byte, offsets map[PdfObject]int64) ([]byte, int) {
+	sectionLen := func(objs []PdfObject) int64 {
+		n := int64(0)
+		for _, obj := range objs {
+			n += int64(len(data[obj]))
+		}
+		return n
+	}
+	sections := append([][]PdfObject{layout.firstPage}, layout.pageSections...)
+
+	// Shared object identifiers: the objects of the first page, then the objects of the shared section.
+	sharedIDs := map[PdfObject]int{}
+	sharedObjs := append(append([]PdfObject{}, layout.firstPage...), layout.shared...)
+	for i, obj := range sharedObjs {
+		sharedIDs[obj] = i
+	}
+
+	minObjs, maxObjs := len(sections[0]), len(sections[0])
+	minLen, maxLen := sectionLen(sections[0]), sectionLen(sections[0])
+	maxRefs, maxID := 0, 0
+	for i, section := range sections {
+		minObjs, maxObjs = minInt(minObjs, len(section)), maxInt(maxObjs, len(section))
+		n := sectionLen(section)
+		if n < minLen {
+			minLen = n
+		}
+		if n > maxLen {
+			maxLen = n
+		}
+		if i > 0 {
+			refs := layout.sharedRefs[i-1]
+			maxRefs = maxInt(maxRefs, len(refs))
+			for _, obj := range refs {
+				maxID = maxInt(maxID, sharedIDs[obj])
+			}
+		}
+	}
+	objBits, lenBits := bitLength(int64(maxObjs-minObjs)), bitLength(maxLen-minLen)
+
+	// Page offset hint table.
+	bw := &bitWriter{}
+	bw.write(int64(minObjs), 32)
+	bw.write(offsets[layout.firstPage[0]], 32)
+	bw.write(int64(objBits), 16)
+	bw.write(minLen, 32)
+	bw.write(int64(lenBits), 16)
+	// Content stream offsets and lengths, given as the page lengths as done by most writers.
+	bw.write(0, 32)
+	bw.write(0, 16)
+	bw.write(minLen, 32)
+	bw.write(int64(lenBits), 16)
+	refBits, idBits := bitLength(int64(maxRefs)), bitLength(int64(maxID))
+	bw.write(int64(refBits), 16)
+	bw.write(int64(idBits), 16)
+	bw.write(0, 16)
+	bw.write(1, 16)
+
+	for _, section := range sections {
+		bw.write(int64(len(section)-minObjs), objBits)
+	}
+	bw.flush()
+	for _, section := range sections {
+		bw.write(sectionLen(section)-minLen, lenBits)
+	}
+	bw.flush()
+	bw.write(0, refBits)
+	for _, refs := range layout.sharedRefs {
+		bw.write(int64(len(refs)), refBits)
+	}
+	bw.flush()
+	for _, refs := range layout.sharedRefs {
+		for _, obj := range refs {
+			bw.write(int64(sharedIDs[obj]), idBits)
+		}
+	}
+	bw.flush()
+	for _, section := range sections {
+		bw.write(sectionLen(section)-minLen, lenBits)
+	}
+	bw.flush()
+	sharedOffset := bw.buf.Len()
+
+	// Shared object hint table, with a group per object.
+	minShared, maxShared := int64(len(data[sharedObjs[0]])), int64(len(data[sharedObjs[0]]))
+	for _, obj := range sharedObjs {
+		n := int64(len(data[obj]))
+		if n < minShared {
+			minShared = n
+		}
+		if n > maxShared {
+			maxShared = n
+		}
+	}
+	sharedBits := bitLength(maxShared - minShared)
+	if len(layout.shared) > 0 {
+		num, _ := objectNumber(layout.shared[0])
+		bw.write(int64(num), 32)
+		bw.write(offsets[layout.shared[0]], 32)
+	} else {
+		bw.write(0, 32)
+		bw.write(0, 32)
+	}
+	bw.write(int64(len(layout.firstPage)), 32)
+	bw.write(int64(len(sharedObjs)), 32)
+	bw.write(0, 16)
+	bw.write(minShared, 32)
+	bw.write(int64(sharedBits), 16)
+	for _, obj := range sharedObjs {
+		bw.write(int64(len(data[obj]))-minShared, sharedBits)
+	}
+	bw.flush()
+	for range sharedObjs {
+		// No MD5 signature.
+		bw.write(0, 1)
+	}
+	bw.flush()
+
+	return bw.buf.Bytes(), sharedOffset
+}
+
+// bitWriter writes the values of the hint tables as bit fields, most significant bit first.
+type bitWriter struct {
+	buf  bytes.Buffer
+	cur  byte
+	bits uint
+}
+
+// write writes the value on n bits.
+func (bw *bitWriter) write(val int64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		bw.cur = bw.cur<<1 | byte((val>>uint(i))&1)
+		bw.bits++
+		if bw.bits == 8 {
+			bw.buf.WriteByte(bw.cur)
+			bw.cur, bw.bits = 0, 0
+		}
+	}
+}
+
+// flush pads the last byte with zero bits.
+func (bw *bitWriter) flush() {
+	if bw.bits > 0 {
+		bw.buf.WriteByte(bw.cur << (8 - bw.bits))
+		bw.cur, bw.bits = 0, 0
+	}
+}
+
+// bitLength returns the number of bits needed to represent the value.
+func bitLength(val int64) int {
+	n := 0
+	for ; val > 0; val >>= 1 {
+		n++
+	}
+	return n
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeLinearizedPages writes a linearized document of 3 pages, the last two sharing an image.
+func writeLinearizedPages(t *testing.T, encrypt bool) []byte {
+	w := NewPdfWriter()
+	w.SetLinearized(true)
+	if encrypt {
+		if err := w.Encrypt([]byte("user"), []byte("owner"), nil); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+
+	newImage := func(val byte) *XObjectImage {
+		img := &Image{Width: 1, Height: 1, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{val}}
+		ximg, err := NewXObjectImageFromImage(img, NewPdfColorspaceDeviceGray(), nil)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		return ximg
+	}
+	shared := newImage(0x80)
+	for i := 0; i < 3; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: float64(100 + i), Ury: 100}
+		page.Resources = NewPdfPageResources()
+		ximg := shared
+		if i == 0 {
+			ximg = newImage(0)
+		}
+		if err := page.Resources.SetXObjectImageByName("Im1", ximg); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if err := page.SetContentStreams([]string{"100 0 0 100 0 0 cm /Im1 Do"}, nil); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// linearizationParams returns the integer entries of the linearization parameter dictionary of the file.
+func linearizationParams(t *testing.T, data []byte) map[string]int64 {
+	re := regexp.MustCompile(`^%PDF-1\.\d\n%[^\n]*\n\d+ 0 obj\n<< /Linearized 1 /L +(\d+) /H \[ *(\d+) +(\d+)\] ` +
+		`/O +(\d+) /E +(\d+) /N +(\d+) /T +(\d+) >>`)
+	m := re.FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("No linearization dictionary at the start of the file")
+	}
+	params := map[string]int64{}
+	for i, key := range []string{"L", "H0", "H1", "O", "E", "N", "T"} {
+		params[key], _ = strconv.ParseInt(string(m[i+1]), 10, 64)
+	}
+	return params
+}
+
+// objectOffsets returns the offsets of the objects of the cross-reference sections of the file, by object number.
+func objectOffsets(t *testing.T, data []byte) map[int]int64 {
+	offsets := map[int]int64{}
+	sectionRe := regexp.MustCompile(`xref\n(\d+) (\d+)\n`)
+	for _, m := range sectionRe.FindAllSubmatchIndex(data, -1) {
+		first, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		count, _ := strconv.Atoi(string(data[m[4]:m[5]]))
+		pos := m[1]
+		for i := 0; i < count; i++ {
+			entry := string(data[pos : pos+20])
+			pos += 20
+			if entry[17] != 'n' {
+				continue
+			}
+			offset, err := strconv.ParseInt(entry[:10], 10, 64)
+			if err != nil {
+				t.Fatalf("Invalid xref entry %q", entry)
+			}
+			offsets[first+i] = offset
+		}
+	}
+	return offsets
+}
+
+func TestWriteLinearized(t *testing.T) {
+	data := writeLinearizedPages(t, false)
+	params := linearizationParams(t, data)
+	if params["L"] != int64(len(data)) {
+		t.Errorf("Wrong file length %d, expected %d", params["L"], len(data))
+	}
+	if params["N"] != 3 {
+		t.Errorf("Wrong number of pages %d", params["N"])
+	}
+
+	offsets := objectOffsets(t, data)
+	for num, offset := range offsets {
+		prefix := fmt.Sprintf("%d 0 obj", num)
+		if !bytes.HasPrefix(data[offset:], []byte(prefix)) {
+			t.Fatalf("Object %d not at offset %d", num, offset)
+		}
+	}
+
+	// The first page object, ending before E, and the hint stream.
+	pageOffset, has := offsets[int(params["O"])]
+	if !has || pageOffset >= params["E"] {
+		t.Fatalf("First page object %d not in the first page section", params["O"])
+	}
+	if !bytes.HasPrefix(data[pageOffset:], []byte(fmt.Sprintf("%d 0 obj\n<</Type /Page/", params["O"]))) {
+		t.Errorf("Object %d not a page", params["O"])
+	}
+	hint := data[params["H0"] : params["H0"]+params["H1"]]
+	if !bytes.Contains(hint, []byte("stream")) || !bytes.HasSuffix(hint, []byte("endobj\n")) {
+		t.Errorf("Invalid hint stream %q", hint)
+	}
+
+	// The main cross-reference table at T, the last startxref pointing at the first-page cross-reference table.
+	if !bytes.HasPrefix(data[params["T"]:], []byte("\n0000000000 65535 f")) {
+		t.Errorf("Main cross-reference table not at %d", params["T"])
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("No startxref at the end of the file")
+	}
+	startxref, _ := strconv.ParseInt(string(m[1]), 10, 64)
+	if !bytes.HasPrefix(data[startxref:], []byte("xref\n")) || startxref > pageOffset {
+		t.Errorf("Wrong startxref %d", startxref)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(reader.PageList) != 3 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	for i, page := range reader.PageList {
+		if page.MediaBox.Urx != float64(100+i) {
+			t.Errorf("Page %d out of order (%v)", i, page.MediaBox)
+		}
+	}
+}
+
+func TestWriteLinearizedEncrypted(t *testing.T) {
+	data := writeLinearizedPages(t, true)
+	params := linearizationParams(t, data)
+	if params["L"] != int64(len(data)) {
+		t.Errorf("Wrong file length %d, expected %d", params["L"], len(data))
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if ok, err := reader.Decrypt([]byte("user")); err != nil || !ok {
+		t.Fatalf("Failed decrypting: %v", err)
+	}
+	if len(reader.PageList) != 3 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	contents, err := reader.PageList[1].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.HasPrefix(contents, "100 0 0 100 0 0 cm /Im1 Do") {
+		t.Errorf("Wrong page contents %q", contents)
+	}
+}
@@ -0,0 +1,21 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package lint validates the structure of PDF documents: the required entries of the dictionaries of each type, the
+// integrity of the page tree (Kids and Parent links, page counts) and the pages referenced by the annotations.  The
+// findings identify the objects concerned, e.g. to check the documents generated by an application in its tests or
+// continuous integration.
+//
+// Example: failing a test on the structural problems of a generated document.
+//
+//	var buf bytes.Buffer
+//	err := pdfWriter.Write(&buf)
+//	...
+//	findings, err := lint.Check(bytes.NewReader(buf.Bytes()))
+//	...
+//	for _, f := range findings {
+//		t.Errorf("%s", f)
+//	}
+package lint
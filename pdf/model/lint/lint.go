@@ -0,0 +1,391 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+)
+
+// Kind is a kind of structural problem.
+type Kind string
+
+// Kinds of findings.
+const (
+	KindRequiredKey Kind = "RequiredKey" // A required entry of a dictionary is missing.
+	KindType        Kind = "Type"        // An object is not of the type expected where it is used.
+	KindReference   Kind = "Reference"   // A reference to an object missing from the document.
+	KindParent      Kind = "Parent"      // A Parent entry not referencing the parent node in the page tree.
+	KindPageTree    Kind = "PageTree"    // The page tree is not a tree, or its page counts are wrong.
+	KindAnnotation  Kind = "Annotation"  // An annotation referencing an object which is not a page of the document.
+)
+
+// Finding is a structural problem of a document.
+type Finding struct {
+	Kind Kind
+	// Number of the object with the problem, 0 for the trailer or direct objects.
+	Object      int64
+	Description string
+}
+
+func (f Finding) String() string {
+	if f.Object > 0 {
+		return fmt.Sprintf("%s: object %d: %s", f.Kind, f.Object, f.Description)
+	}
+	return fmt.Sprintf("%s: %s", f.Kind, f.Description)
+}
+
+// requiredKeys are the entries required in the dictionaries by Type, or by Type and Subtype as "Type/Subtype".  The
+// entries of the page tree nodes are checked with the page tree.
+var requiredKeys = map[string][]core.PdfObjectName{
+	"Catalog":           {"Pages"},
+	"Annot":             {"Subtype", "Rect"},
+	"Font":              {"Subtype"},
+	"Font/Type0":        {"BaseFont", "Encoding", "DescendantFonts"},
+	"Font/Type1":        {"BaseFont"},
+	"Font/MMType1":      {"BaseFont"},
+	"Font/TrueType":     {"BaseFont"},
+	"Font/Type3":        {"FontBBox", "FontMatrix", "CharProcs", "Encoding", "FirstChar", "LastChar", "Widths"},
+	"Font/CIDFontType0": {"BaseFont", "CIDSystemInfo", "FontDescriptor"},
+	"Font/CIDFontType2": {"BaseFont", "CIDSystemInfo", "FontDescriptor"},
+	"FontDescriptor":    {"FontName", "Flags", "ItalicAngle"},
+	"XObject/Image":     {"Width", "Height"},
+	"XObject/Form":      {"BBox"},
+	"Action":            {"S"},
+	"OCG":               {"Name"},
+	"ObjStm":            {"N", "First"},
+	"XRef":              {"Size", "W"},
+	"Sig":               {"Filter", "Contents"},
+	"Mask":              {"S"},
+	"Group":             {"S"},
+	"StructElem":        {"S", "P"},
+	"OutputIntent":      {"S", "OutputConditionIdentifier"},
+	"Metadata":          {"Subtype"},
+}
+
+// Check validates the structure of the document read from rs, and returns the findings, empty if none.  The document
+// is parsed independently of any PdfReader, whose loading fixes some of the problems (e.g. Parent entries).
+// Encrypted documents are decrypted with an empty user password, an error is returned if it is not valid.
+func Check(rs io.ReadSeeker) ([]Finding, error) {
+	parser, err := core.NewParser(rs)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := parser.IsEncrypted()
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		ok, err := parser.Decrypt([]byte(""))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("Unable to decrypt the document with an empty password")
+		}
+	}
+
+	c := &checker{parser: parser, pages: map[int64]bool{}, checked: map[int64]bool{}}
+	c.checkObjects()
+	c.checkCatalog()
+	for _, num := range c.pageList {
+		c.checkAnnotations(num)
+	}
+	return c.findings, nil
+}
+
+// checker checks the structure of a document.
+type checker struct {
+	parser   *core.PdfParser
+	findings []Finding
+
+	pageObjects []int64        // Page objects of the document, by object number.
+	pageList    []int64        // Pages of the page tree, in order.
+	pages       map[int64]bool // Pages of the page tree.
+	checked     map[int64]bool // Objects whose required entries were checked.
+}
+
+// add adds a finding of the kind for the object.
+func (c *checker) add(kind Kind, num int64, format string, args ...interface{}) {
+	c.findings = append(c.findings, Finding{Kind: kind, Object: num, Description: fmt.Sprintf(format, args...)})
+}
+
+// lookup returns the direct object of obj, traced if it is a reference, with its object number (0 if direct).  The
+// dictionary of indirect objects and streams is returned as the object, with the stream.  The object is nil for
+// references to missing objects, and for objects which cannot be parsed.
+func (c *checker) lookup(obj core.PdfObject) (core.PdfObject, *core.PdfObjectStream, int64) {
+	var num int64
+	if ref, ok := obj.(*core.PdfObjectReference); ok {
+		num = ref.ObjectNumber
+		var err error
+		obj, err = c.parser.LookupByReference(*ref)
+		if err != nil {
+			common.Log.Debug("Unable to look up object %d: %v", num, err)
+			return nil, nil, num
+		}
+	}
+	switch t := obj.(type) {
+	case *core.PdfIndirectObject:
+		return t.PdfObject, nil, num
+	case *core.PdfObjectStream:
+		return t.PdfObjectDictionary, t, num
+	case *core.PdfObjectNull:
+		return nil, nil, num
+	}
+	return obj, nil, num
+}
+
+// lookupDict returns the dictionary of obj with its object number, adding a finding if it is not a dictionary.
+// what describes the object for the findings.
+func (c *checker) lookupDict(obj core.PdfObject, from int64, what string) (*core.PdfObjectDictionary, int64) {
+	val, _, num := c.lookup(obj)
+	if val == nil {
+		if num > 0 {
+			c.add(KindReference, from, "%s references missing object %d", what, num)
+		} else {
+			c.add(KindType, from, "%s is null", what)
+		}
+		return nil, num
+	}
+	dict, ok := val.(*core.PdfObjectDictionary)
+	if !ok {
+		if num > 0 {
+			from = num
+		}
+		c.add(KindType, from, "%s is not a dictionary", what)
+		return nil, num
+	}
+	return dict, num
+}
+
+// name returns the name value of the entry of the dictionary, empty if not a name.
+func (c *checker) name(dict *core.PdfObjectDictionary, key core.PdfObjectName) string {
+	val, _, _ := c.lookup(dict.Get(key))
+	if name, ok := val.(*core.PdfObjectName); ok {
+		return string(*name)
+	}
+	return ""
+}
+
+// has returns true if the dictionary has the entry, not null.
+func has(dict *core.PdfObjectDictionary, key core.PdfObjectName) bool {
+	val := dict.Get(key)
+	_, isNull := val.(*core.PdfObjectNull)
+	return val != nil && !isNull
+}
+
+// checkObjects checks the required entries of the dictionaries of all the objects of the document.
+func (c *checker) checkObjects() {
+	for _, num := range c.parser.GetObjectNums() {
+		obj, err := c.parser.LookupByNumber(num)
+		if err != nil {
+			c.add(KindReference, int64(num), "Object cannot be parsed: %v", err)
+			continue
+		}
+		val, stream, _ := c.lookup(obj)
+		dict, ok := val.(*core.PdfObjectDictionary)
+		if !ok {
+			continue
+		}
+		typ := c.name(dict, "Type")
+		if typ == "" && stream != nil {
+			// The Type of XObjects is optional.
+			if subtype := c.name(dict, "Subtype"); subtype == "Image" || subtype == "Form" {
+				typ = "XObject"
+			}
+		}
+		if typ == "Page" {
+			c.pageObjects = append(c.pageObjects, int64(num))
+		}
+		c.checkRequiredKeys(int64(num), dict, typ)
+	}
+}
+
+// checkRequiredKeys checks the required entries of a dictionary of the type, and of its subtype.
+func (c *checker) checkRequiredKeys(num int64, dict *core.PdfObjectDictionary, typ string) {
+	if typ == "" {
+		return
+	}
+	if num > 0 {
+		if c.checked[num] {
+			return
+		}
+		c.checked[num] = true
+	}
+	keys := requiredKeys[typ]
+	if subtype := c.name(dict, "Subtype"); subtype != "" {
+		keys = append(keys[:len(keys):len(keys)], requiredKeys[typ+"/"+subtype]...)
+	}
+	for _, key := range keys {
+		if !has(dict, key) {
+			c.add(KindRequiredKey, num, "%s without %s", typ, key)
+		}
+	}
+}
+
+// checkCatalog checks the trailer, the catalog and the page tree.
+func (c *checker) checkCatalog() {
+	trailer := c.parser.GetTrailer()
+	if !has(trailer, "Size") {
+		c.add(KindRequiredKey, 0, "Trailer without Size")
+	}
+	if !has(trailer, "Root") {
+		c.add(KindRequiredKey, 0, "Trailer without Root")
+		return
+	}
+	catalog, num := c.lookupDict(trailer.Get("Root"), 0, "Root")
+	if catalog == nil {
+		return
+	}
+	if typ := c.name(catalog, "Type"); typ != "Catalog" {
+		c.add(KindType, num, "Catalog of type %q", typ)
+	}
+	if !has(catalog, "Pages") {
+		return
+	}
+
+	visited := map[int64]bool{}
+	c.checkPageTreeNode(catalog.Get("Pages"), num, 0, false, false, visited)
+	for _, page := range c.pageObjects {
+		if !c.pages[page] {
+			c.add(KindPageTree, page, "Page not in the page tree")
+		}
+	}
+}
+
+// checkPageTreeNode checks a node of the page tree and its descendants, and returns the number of pages found.  from
+// is the object number of the parent node, or of the catalog for the root node (parent 0).  mediaBox and resources are
+// true if the node inherits these entries.
+func (c *checker) checkPageTreeNode(obj core.PdfObject, from, parent int64, mediaBox, resources bool,
+	visited map[int64]bool) int {
+	if _, ok := obj.(*core.PdfObjectReference); !ok {
+		c.add(KindPageTree, from, "Page tree node not an indirect object")
+		return 0
+	}
+	node, num := c.lookupDict(obj, from, "Page tree node")
+	if node == nil {
+		return 0
+	}
+	if visited[num] {
+		c.add(KindPageTree, num, "Page tree node referenced several times")
+		return 0
+	}
+	visited[num] = true
+
+	// The root node has no parent.
+	parentRef, isRef := node.Get("Parent").(*core.PdfObjectReference)
+	switch {
+	case parent == 0 && has(node, "Parent"):
+		c.add(KindParent, num, "Root page tree node with a Parent")
+	case parent != 0 && !has(node, "Parent"):
+		c.add(KindParent, num, "Page tree node without Parent")
+	case parent != 0 && (!isRef || parentRef.ObjectNumber != parent):
+		c.add(KindParent, num, "Parent %s instead of object %d", node.Get("Parent").DefaultWriteString(), parent)
+	}
+	mediaBox = mediaBox || has(node, "MediaBox")
+	resources = resources || has(node, "Resources")
+
+	switch typ := c.name(node, "Type"); typ {
+	case "Page":
+		if !mediaBox {
+			c.add(KindRequiredKey, num, "Page without MediaBox")
+		}
+		if !resources {
+			c.add(KindRequiredKey, num, "Page without Resources")
+		}
+		c.pages[num] = true
+		c.pageList = append(c.pageList, num)
+		return 1
+	case "Pages":
+		val, _, _ := c.lookup(node.Get("Kids"))
+		kids, ok := val.(*core.PdfObjectArray)
+		if !ok {
+			c.add(KindRequiredKey, num, "Pages without Kids")
+			return 0
+		}
+		count := 0
+		for _, kid := range *kids {
+			count += c.checkPageTreeNode(kid, num, num, mediaBox, resources, visited)
+		}
+		val, _, _ = c.lookup(node.Get("Count"))
+		if n, ok := val.(*core.PdfObjectInteger); !ok {
+			c.add(KindRequiredKey, num, "Pages without Count")
+		} else if int(*n) != count {
+			c.add(KindPageTree, num, "Count %d, %d pages found", *n, count)
+		}
+		return count
+	case "":
+		c.add(KindRequiredKey, num, "Page tree node without Type")
+	default:
+		c.add(KindType, num, "Page tree node of type %s", typ)
+	}
+	return 0
+}
+
+// checkAnnotations checks the annotations of a page: their required entries, and the pages referenced by their P
+// entry and their destinations.
+func (c *checker) checkAnnotations(pageNum int64) {
+	page, _, _ := c.lookup(&core.PdfObjectReference{ObjectNumber: pageNum})
+	pageDict := page.(*core.PdfObjectDictionary)
+	if !has(pageDict, "Annots") {
+		return
+	}
+	val, _, _ := c.lookup(pageDict.Get("Annots"))
+	annots, ok := val.(*core.PdfObjectArray)
+	if !ok {
+		c.add(KindType, pageNum, "Annots is not an array")
+		return
+	}
+
+	for _, obj := range *annots {
+		annot, num := c.lookupDict(obj, pageNum, "Annotation")
+		if annot == nil {
+			continue
+		}
+		// The Type of annotations is optional.
+		c.checkRequiredKeys(num, annot, "Annot")
+		from := num
+		if from == 0 {
+			from = pageNum
+		}
+
+		if has(annot, "P") {
+			ref, ok := annot.Get("P").(*core.PdfObjectReference)
+			switch {
+			case !ok || !c.pages[ref.ObjectNumber]:
+				c.add(KindAnnotation, from, "P %s is not a page of the document", annot.Get("P").DefaultWriteString())
+			case ref.ObjectNumber != pageNum:
+				c.add(KindAnnotation, from, "P references page object %d instead of %d", ref.ObjectNumber, pageNum)
+			}
+		}
+
+		c.checkDestination(from, annot.Get("Dest"))
+		if action, _, _ := c.lookup(annot.Get("A")); action != nil {
+			if dict, ok := action.(*core.PdfObjectDictionary); ok && c.name(dict, "S") == "GoTo" {
+				c.checkDestination(from, dict.Get("D"))
+			}
+		}
+	}
+}
+
+// checkDestination checks that an explicit destination references a page of the document.  Named destinations are
+// not resolved.
+func (c *checker) checkDestination(from int64, obj core.PdfObject) {
+	val, _, _ := c.lookup(obj)
+	dest, ok := val.(*core.PdfObjectArray)
+	if !ok || len(*dest) == 0 {
+		return
+	}
+	// Destinations to other documents (remote go-to) have page numbers instead.
+	ref, ok := (*dest)[0].(*core.PdfObjectReference)
+	if ok && !c.pages[ref.ObjectNumber] {
+		c.add(KindAnnotation, from, "Destination to object %d, not a page of the document", ref.ObjectNumber)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+func TestCheck(t *testing.T) {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		// Page 3 twice, and a wrong Count.
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 3 0 R] /Count 3 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << >> /Annots [6 0 R 7 0 R] >>",
+		// Wrong Parent, without Resources.
+		"<< /Type /Page /Parent 1 0 R >>",
+		// Not in the page tree.
+		"<< /Type /Page /Parent 2 0 R /Resources << >> >>",
+		// Not on the page of P, with a destination to a page not in the page tree.
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /P 4 0 R /Dest [5 0 R /Fit] >>",
+		// Without Type and Rect, P referencing a missing object.
+		"<< /Subtype /Text /P 9 0 R >>",
+		// Without BaseFont.
+		"<< /Type /Font /Subtype /Type1 >>",
+	}
+	findings, err := Check(bytes.NewReader(testutils.MakePdf(objects)))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[fmt.Sprintf("%s %d", f.Kind, f.Object)]++
+	}
+	expected := map[string]int{
+		"RequiredKey 4": 1, "RequiredKey 7": 1, "RequiredKey 8": 1,
+		"PageTree 2": 1, "PageTree 3": 1, "PageTree 5": 1,
+		"Parent 4":     1,
+		"Annotation 6": 2, "Annotation 7": 1,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Wrong findings %v", findings)
+	}
+}
+
+func TestCheckValid(t *testing.T) {
+	w := model.NewPdfWriter()
+	for i := 0; i < 3; i++ {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+		page.Resources = model.NewPdfPageResources()
+		page.AddContentStreamByString("0 0 100 100 re f")
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	findings, err := Check(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("Findings in a valid document %v", findings)
+	}
+}
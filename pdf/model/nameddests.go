@@ -0,0 +1,93 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Named destinations (12.3.2.3 Named Destinations): the destinations of the links and outline items can be names,
+// defined in the Dests dictionary of the catalog (PDF 1.1) or in the Dests name tree of the names dictionary.
+
+// GetNamedDestinations returns the named destinations of the document by name, from the Dests name tree and the
+// Dests dictionary of the catalog.  The destinations are explicit destinations, arrays with the page object first,
+// the D entries of the destination dictionaries being resolved.
+func (this *PdfReader) GetNamedDestinations() (map[string]PdfObject, error) {
+	entries, err := this.loadNameTree("Dests")
+	if err != nil {
+		return nil, err
+	}
+	obj, err := this.traceToObject(this.catalog.Get("Dests"))
+	if err != nil {
+		return nil, err
+	}
+	if dests, ok := TraceToDirectObject(obj).(*PdfObjectDictionary); ok {
+		for _, key := range dests.Keys() {
+			entries[string(key)], err = this.traceToObject(dests.Get(key))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	dests := map[string]PdfObject{}
+	for name, dest := range entries {
+		if dict, ok := TraceToDirectObject(dest).(*PdfObjectDictionary); ok {
+			dest, err = this.traceToObject(dict.Get("D"))
+			if err != nil {
+				return nil, err
+			}
+		}
+		arr, ok := TraceToDirectObject(dest).(*PdfObjectArray)
+		if !ok || len(*arr) == 0 {
+			common.Log.Debug("Invalid named destination %s (%T)", name, dest)
+			continue
+		}
+		err = this.traverseObjectData(arr)
+		if err != nil {
+			return nil, err
+		}
+		dests[name] = arr
+	}
+	return dests, nil
+}
+
+// AddNamedDestination adds a named destination to the document, in the Dests name tree.  The destination is an
+// explicit destination, whose page must be added to the document with AddPage.
+func (this *PdfWriter) AddNamedDestination(name string, dest PdfObject) {
+	if this.namedDests == nil {
+		this.namedDests = map[string]PdfObject{}
+	}
+	this.namedDests[name] = dest
+}
+
+// Sets the Dests name tree in the names dictionary of the catalog.
+func (this *PdfWriter) writeNamedDestinations() error {
+	sorted := []string{}
+	for name := range this.namedDests {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	names := MakeArray()
+	for _, name := range sorted {
+		dest := this.namedDests[name]
+		if arr, ok := TraceToDirectObject(dest).(*PdfObjectArray); ok && len(*arr) > 0 {
+			if _, isPage := (*arr)[0].(*PdfIndirectObject); isPage && !this.hasObject((*arr)[0]) {
+				common.Log.Debug("ERROR: Named destination %s page not added to the document", name)
+				continue
+			}
+		}
+		*names = append(*names, MakeString(name), dest)
+	}
+	if len(*names) == 0 {
+		return nil
+	}
+	return this.setNameTree("Dests", names)
+}
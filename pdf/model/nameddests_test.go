@@ -0,0 +1,60 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestNamedDestinations(t *testing.T) {
+	w := NewPdfWriter()
+	pages := []*PdfPage{}
+	for i := 0; i < 2; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 100}
+		page.Resources = NewPdfPageResources()
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		pages = append(pages, page)
+	}
+	w.AddNamedDestination("end", core.MakeArray(pages[1].GetPageAsIndirectObject(), core.MakeName("Fit")))
+	w.AddNamedDestination("start", core.MakeArray(pages[0].GetPageAsIndirectObject(), core.MakeName("Fit")))
+	// Not added to the document.
+	w.AddNamedDestination("other", core.MakeArray(NewPdfPage().GetPageAsIndirectObject(), core.MakeName("Fit")))
+
+	var buf bytes.Buffer
+	err := w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	dests, err := reader.GetNamedDestinations()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(dests) != 2 {
+		t.Fatalf("Wrong named destinations %v", dests)
+	}
+	for name, num := range map[string]int{"start": 1, "end": 2} {
+		page, err := reader.GetPageAsIndirectObject(num)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		arr, ok := dests[name].(*core.PdfObjectArray)
+		if !ok || (*arr)[0] != page {
+			t.Errorf("Wrong destination %s: %v", name, dests[name])
+		}
+	}
+}
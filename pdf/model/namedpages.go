@@ -0,0 +1,229 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Named pages (12.7.6 Named Pages): the Pages name tree of the names dictionary maps names to pages of the document,
+// and the Templates name tree to template pages, which are not in the page tree and not displayed.
+
+// GetNamedPages returns the pages of the document by their name in the Pages name tree.
+func (this *PdfReader) GetNamedPages() (map[string]*PdfPage, error) {
+	entries, err := this.loadNameTree("Pages")
+	if err != nil {
+		return nil, err
+	}
+
+	pages := map[string]*PdfPage{}
+	for name, obj := range entries {
+		found := false
+		for i, pageObj := range this.pageList {
+			if pageObj == obj {
+				pages[name] = this.PageList[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			common.Log.Debug("Named page %s not in the page tree", name)
+		}
+	}
+	return pages, nil
+}
+
+// GetPageTemplates returns the template pages of the document by their name in the Templates name tree.
+func (this *PdfReader) GetPageTemplates() (map[string]*PdfPage, error) {
+	entries, err := this.loadNameTree("Templates")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := map[string]*PdfPage{}
+	for name, obj := range entries {
+		ind, ok := obj.(*PdfIndirectObject)
+		if !ok {
+			common.Log.Debug("ERROR: Template page %s not an indirect object (%T)", name, obj)
+			return nil, ErrTypeError
+		}
+		dict, ok := ind.PdfObject.(*PdfObjectDictionary)
+		if !ok {
+			common.Log.Debug("ERROR: Template page %s not a dictionary (%T)", name, ind.PdfObject)
+			return nil, ErrTypeError
+		}
+		err := this.traverseObjectData(ind)
+		if err != nil {
+			return nil, err
+		}
+		page, err := this.newPdfPageFromDict(dict)
+		if err != nil {
+			return nil, err
+		}
+		page.setContainer(ind)
+		templates[name] = page
+	}
+	return templates, nil
+}
+
+// Loads the entries of a name tree of the names dictionary of the catalog, with the values traced to their objects.
+func (this *PdfReader) loadNameTree(key PdfObjectName) (map[string]PdfObject, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	entries := map[string]PdfObject{}
+	obj, err := this.traceToObject(this.catalog.Get("Names"))
+	if err != nil {
+		return nil, err
+	}
+	namesDict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		return entries, nil
+	}
+	obj, err = this.traceToObject(namesDict.Get(key))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := TraceToDirectObject(obj).(*PdfObjectDictionary); !ok {
+		return entries, nil
+	}
+
+	visited := map[PdfObject]bool{}
+	var load func(obj PdfObject) error
+	load = func(obj PdfObject) error {
+		node, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+		if !ok {
+			common.Log.Debug("ERROR: Invalid name tree node (%T)", obj)
+			return ErrTypeError
+		}
+		if visited[node] {
+			common.Log.Debug("ERROR: Circular reference in name tree")
+			return errors.New("Circular reference in name tree")
+		}
+		visited[node] = true
+
+		if names, ok := TraceToDirectObject(node.Get("Names")).(*PdfObjectArray); ok {
+			for i := 0; i+1 < len(*names); i += 2 {
+				name, ok := TraceToDirectObject((*names)[i]).(*PdfObjectString)
+				if !ok {
+					common.Log.Debug("ERROR: Invalid name tree key (%T)", (*names)[i])
+					return ErrTypeError
+				}
+				value, err := this.traceToObject((*names)[i+1])
+				if err != nil {
+					return err
+				}
+				entries[string(*name)] = value
+			}
+		}
+
+		if kids, ok := TraceToDirectObject(node.Get("Kids")).(*PdfObjectArray); ok {
+			for _, kid := range *kids {
+				obj, err := this.traceToObject(kid)
+				if err != nil {
+					return err
+				}
+				err = load(obj)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	err = load(obj)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddNamedPage names a page of the document in the Pages name tree.  The page must be added to the document with
+// AddPage.
+func (this *PdfWriter) AddNamedPage(name string, page *PdfPage) {
+	if this.namedPages == nil {
+		this.namedPages = map[string]*PdfPage{}
+	}
+	this.namedPages[name] = page
+}
+
+// AddPageTemplate adds a template page to the document, in the Templates name tree.  Template pages are not added to
+// the page tree, and are not displayed.  Applications instantiate them, e.g. the spawnPageFromTemplate JavaScript
+// method.
+func (this *PdfWriter) AddPageTemplate(name string, page *PdfPage) {
+	if this.pageTemplates == nil {
+		this.pageTemplates = map[string]*PdfPage{}
+	}
+	this.pageTemplates[name] = page
+}
+
+// Sets the Pages and Templates name trees in the names dictionary of the catalog.
+func (this *PdfWriter) writeNamedPages() error {
+	names := MakeArray()
+	for _, name := range sortedPageNames(this.namedPages) {
+		pageObj := this.namedPages[name].GetPageAsIndirectObject()
+		if !this.hasObject(pageObj) {
+			common.Log.Debug("ERROR: Named page %s not added to the document", name)
+			return errors.New("Named page not in the document")
+		}
+		*names = append(*names, MakeString(name), pageObj)
+	}
+	if len(*names) > 0 {
+		err := this.setNameTree("Pages", names)
+		if err != nil {
+			return err
+		}
+	}
+
+	names = MakeArray()
+	for _, name := range sortedPageNames(this.pageTemplates) {
+		page := this.pageTemplates[name]
+		// Not in the page tree: the inherited attributes are copied.
+		obj := page.ToPdfObject()
+		dict := page.pageDict
+		err := inheritPageFields(dict)
+		if err != nil {
+			return err
+		}
+		dict.Set("Type", MakeName("Template"))
+		dict.Remove("Parent")
+		*names = append(*names, MakeString(name), obj)
+	}
+	if len(*names) > 0 {
+		return this.setNameTree("Templates", names)
+	}
+	return nil
+}
+
+// Returns the names of the pages, sorted as required in name trees.
+func sortedPageNames(pages map[string]*PdfPage) []string {
+	names := []string{}
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sets a name tree with the sorted names and values in the names dictionary of the catalog.
+func (this *PdfWriter) setNameTree(key PdfObjectName, names *PdfObjectArray) error {
+	tree := MakeDict()
+	tree.Set("Names", names)
+	namesDict, ok := TraceToDirectObject(this.catalog.Get("Names")).(*PdfObjectDictionary)
+	if !ok {
+		namesDict = MakeDict()
+		this.catalog.Set("Names", namesDict)
+	}
+	namesDict.Set(key, tree)
+
+	return this.addObjects(namesDict)
+}
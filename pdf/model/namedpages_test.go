@@ -0,0 +1,85 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNamedPages(t *testing.T) {
+	w := NewPdfWriter()
+	pages := []*PdfPage{}
+	for i := 0; i < 3; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100 + float64(i), Ury: 100}
+		page.Resources = NewPdfPageResources()
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		pages = append(pages, page)
+	}
+	w.AddNamedPage("summary", pages[2])
+	w.AddNamedPage("cover", pages[0])
+
+	template := NewPdfPage()
+	template.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 50}
+	template.Resources = NewPdfPageResources()
+	err := template.SetContentStreams([]string{"0 0 200 50 re f"}, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w.AddPageTemplate("form", template)
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The template is not in the page tree.
+	if n, _ := reader.GetNumPages(); n != 3 {
+		t.Errorf("Wrong number of pages %d", n)
+	}
+
+	named, err := reader.GetNamedPages()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(named) != 2 {
+		t.Fatalf("Wrong named pages %v", named)
+	}
+	for name, num := range map[string]int{"cover": 1, "summary": 3} {
+		page, _ := reader.GetPage(num)
+		if named[name] != page {
+			t.Errorf("Named page %s not page %d", name, num)
+		}
+	}
+
+	templates, err := reader.GetPageTemplates()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, ok := templates["form"]
+	if len(templates) != 1 || !ok {
+		t.Fatalf("Wrong templates %v", templates)
+	}
+	if page.MediaBox == nil || page.MediaBox.Urx != 200 || page.MediaBox.Ury != 50 {
+		t.Errorf("Wrong template media box %v", page.MediaBox)
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if content != "0 0 200 50 re f" {
+		t.Errorf("Wrong template content %q", content)
+	}
+}
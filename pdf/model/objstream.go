@@ -0,0 +1,252 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfXrefMode is the form of the cross-reference information of the output file.
+type PdfXrefMode int
+
+const (
+	// Cross-reference table, the objects being written uncompressed (default).
+	XrefModeTable PdfXrefMode = iota
+	// Cross-reference stream, the objects other than streams being compressed in object streams.  Requires PDF 1.5.
+	XrefModeStream
+	// Hybrid-reference file, for PDF 1.4 targets: a cross-reference table for the objects readers of PDF 1.4 need,
+	// written uncompressed, and a cross-reference stream for the objects only reachable through the logical
+	// structure, compressed in object streams, which these readers ignore.  The version is not raised.
+	XrefModeHybrid
+)
+
+// Maximum number of objects of an object stream.
+const objectStreamSize = 100
+
+// SetXrefMode sets the form of the cross-reference information of the output file, XrefModeTable by default.  Object
+// streams and cross-reference streams make documents with many small objects much smaller.  Not applied to
+// linearized output, and the objects already written with FlushPages are not compressed.
+func (this *PdfWriter) SetXrefMode(mode PdfXrefMode) {
+	this.xrefMode = mode
+}
+
+// compressedObject is the location of an object in an object stream.
+type compressedObject struct {
+	stream *PdfObjectStream
+	index  int
+}
+
+// makeObjectStreams compresses the objects to compress in object streams, added to the objects written, and returns
+// the location of the objects compressed.
+func (this *PdfWriter) makeObjectStreams() (map[PdfObject]compressedObject, error) {
+	compressed := map[PdfObject]compressedObject{}
+	if this.xrefMode != XrefModeStream && this.xrefMode != XrefModeHybrid {
+		return compressed, nil
+	}
+
+	var needed map[PdfObject]bool
+	if this.xrefMode == XrefModeHybrid {
+		needed = this.reachableObjects()
+	}
+	candidates := []*PdfIndirectObject{}
+	for idx, obj := range this.objects {
+		io, ok := obj.(*PdfIndirectObject)
+		if !ok || obj == this.encryptObj || needed[obj] {
+			continue
+		}
+		if idx < len(this.offsets) && this.offsets[idx] != 0 {
+			continue
+		}
+		if dict, ok := io.PdfObject.(*PdfObjectDictionary); ok {
+			// Signatures are located in the file by their byte ranges.
+			if name, ok := dict.Get("Type").(*PdfObjectName); ok && (*name == "Sig" || *name == "DocTimeStamp") {
+				continue
+			}
+		}
+		candidates = append(candidates, io)
+	}
+
+	this.updateObjectNumbers()
+	for start := 0; start < len(candidates); start += objectStreamSize {
+		end := start + objectStreamSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+		stream := &PdfObjectStream{PdfObjectDictionary: MakeDict()}
+		var header, body bytes.Buffer
+		for i, io := range candidates[start:end] {
+			header.WriteString(fmt.Sprintf("%d %d ", io.ObjectNumber, body.Len()))
+			body.WriteString(io.PdfObject.DefaultWriteString())
+			body.WriteString("\n")
+			compressed[io] = compressedObject{stream, i}
+			if this.crypter != nil {
+				// Encrypted with the object stream.
+				this.crypter.EncryptedObjects[io] = true
+			}
+		}
+
+		data, err := NewFlateEncoder().EncodeBytes(append(header.Bytes(), body.Bytes()...))
+		if err != nil {
+			common.Log.Debug("ERROR: Failed compressing object stream: %v", err)
+			return nil, err
+		}
+		stream.Set("Type", MakeName("ObjStm"))
+		stream.Set("N", MakeInteger(int64(end-start)))
+		stream.Set("First", MakeInteger(int64(header.Len())))
+		stream.Set("Filter", MakeName("FlateDecode"))
+		stream.Set("Length", MakeInteger(int64(len(data))))
+		stream.Stream = data
+		this.addObject(stream)
+	}
+	this.updateObjectNumbers()
+	return compressed, nil
+}
+
+// reachableObjects returns the objects written reachable from the trailer without going through the logical
+// structure, needed by the readers of PDF 1.4.
+func (this *PdfWriter) reachableObjects() map[PdfObject]bool {
+	reached := map[PdfObject]bool{}
+	stack := []PdfObject{this.root, this.infoObj}
+	if this.encryptObj != nil {
+		stack = append(stack, this.encryptObj)
+	}
+	for len(stack) > 0 {
+		obj := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch t := obj.(type) {
+		case *PdfIndirectObject:
+			if reached[t] || !this.hasObject(t) {
+				continue
+			}
+			reached[t] = true
+			stack = append(stack, t.PdfObject)
+		case *PdfObjectStream:
+			if reached[t] || !this.hasObject(t) {
+				continue
+			}
+			reached[t] = true
+			stack = append(stack, t.PdfObjectDictionary)
+		case *PdfObjectDictionary:
+			for _, key := range t.Keys() {
+				if t == this.catalog && key == "StructTreeRoot" {
+					continue
+				}
+				stack = append(stack, t.Get(key))
+			}
+		case *PdfObjectArray:
+			stack = append(stack, *t...)
+		}
+	}
+	return reached
+}
+
+// writeXrefStream writes the cross-reference stream of the objects, with the trailer entries, and returns its offset.
+// With only, the stream only has entries for the objects compressed, for hybrid-reference files.
+func (this *PdfWriter) writeXrefStream(compressed map[PdfObject]compressedObject, trailer *PdfObjectDictionary,
+	only bool) (int64, error) {
+	stream := &PdfObjectStream{PdfObjectDictionary: MakeDict()}
+	this.addObject(stream)
+	this.updateObjectNumbers()
+	offset := this.offset()
+	this.offsets = append(this.offsets, offset)
+
+	// Field widths: type, offset or object stream number, generation or index in the object stream.
+	maxField := int64(len(this.objects))
+	for _, off := range this.offsets {
+		if off > maxField {
+			maxField = off
+		}
+	}
+	width := 1
+	for ; maxField >= 1<<uint(8*width); width++ {
+	}
+	var data bytes.Buffer
+	writeField := func(val int64, n int) {
+		for i := n - 1; i >= 0; i-- {
+			data.WriteByte(byte(val >> uint(8*i)))
+		}
+	}
+
+	index := PdfObjectArray{}
+	runStart, runLen := 0, 0
+	if !only {
+		writeField(0, 1)
+		writeField(0, width)
+		writeField(0xffff, 2)
+		index = append(index, MakeInteger(0), MakeInteger(int64(len(this.objects)+1)))
+	}
+	for idx, obj := range this.objects {
+		entry, isCompressed := compressed[obj]
+		switch {
+		case isCompressed:
+			writeField(2, 1)
+			writeField(entry.stream.ObjectNumber, width)
+			writeField(int64(entry.index), 2)
+		case only:
+			continue
+		default:
+			writeField(1, 1)
+			writeField(this.offsets[idx], width)
+			writeField(0, 2)
+		}
+		if only {
+			// Subsections of consecutive objects.
+			if runLen > 0 && runStart+runLen == idx+1 {
+				runLen++
+				continue
+			}
+			if runLen > 0 {
+				index = append(index, MakeInteger(int64(runStart)), MakeInteger(int64(runLen)))
+			}
+			runStart, runLen = idx+1, 1
+		}
+	}
+	if runLen > 0 {
+		index = append(index, MakeInteger(int64(runStart)), MakeInteger(int64(runLen)))
+	}
+
+	encoded, err := NewFlateEncoder().EncodeBytes(data.Bytes())
+	if err != nil {
+		common.Log.Debug("ERROR: Failed compressing the cross-reference stream: %v", err)
+		return 0, err
+	}
+	for _, key := range trailer.Keys() {
+		stream.Set(key, trailer.Get(key))
+	}
+	stream.Set("Type", MakeName("XRef"))
+	stream.Set("Size", MakeInteger(int64(len(this.objects)+1)))
+	stream.Set("Index", &index)
+	stream.Set("W", MakeArray(MakeInteger(1), MakeInteger(int64(width)), MakeInteger(2)))
+	stream.Set("Filter", MakeName("FlateDecode"))
+	stream.Set("Length", MakeInteger(int64(len(encoded))))
+	stream.Stream = encoded
+
+	// Cross-reference streams are not encrypted.
+	this.writeObject(len(this.objects), stream)
+	return offset, nil
+}
+
+// writeXrefStreamTrailer writes the cross-reference stream of all the objects, ending the file.
+func (this *PdfWriter) writeXrefStreamTrailer(compressed map[PdfObject]compressedObject) error {
+	trailer := MakeDict()
+	trailer.Set("Info", this.infoObj)
+	trailer.Set("Root", this.root)
+	if this.crypter != nil {
+		trailer.Set("Encrypt", this.encryptObj)
+		trailer.Set("ID", this.ids)
+	}
+	offset, err := this.writeXrefStream(compressed, trailer, false)
+	if err != nil {
+		return err
+	}
+	this.writer.WriteString(fmt.Sprintf("startxref\n%d\n", offset))
+	this.writer.WriteString("%%EOF\n")
+	return this.writer.Flush()
+}
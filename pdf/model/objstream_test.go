@@ -0,0 +1,136 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+)
+
+// writeXrefModePdf writes a tagged document of 20 pages with the cross-reference mode.
+func writeXrefModePdf(t *testing.T, mode PdfXrefMode, encrypt bool) []byte {
+	w := NewPdfWriter()
+	w.SetXrefMode(mode)
+	if encrypt {
+		if err := w.Encrypt([]byte("user"), []byte("owner"), nil); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	root := NewPdfStructTreeRoot()
+	doc := NewPdfStructElement("Document")
+	root.K = append(root.K, doc)
+	for i := 0; i < 20; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: float64(100 + i), Ury: 100}
+		page.Resources = NewPdfPageResources()
+		if err := page.SetContentStreams([]string{"/P <</MCID 0>> BDC 0 0 10 10 re f EMC"}, nil); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		elem := NewPdfStructElement("P")
+		elem.Alt = "Square"
+		elem.AddMarkedContent(page, root.NewMCID(page))
+		doc.AddKid(elem)
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	w.SetStructTreeRoot(root)
+	var buf bytes.Buffer
+	if err := w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// checkXrefModePdf checks the document written by writeXrefModePdf can be read back.
+func checkXrefModePdf(t *testing.T, data []byte, password string) {
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if password != "" {
+		if ok, err := reader.Decrypt([]byte(password)); err != nil || !ok {
+			t.Fatalf("Failed decrypting: %v", err)
+		}
+	}
+	if len(reader.PageList) != 20 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	for i, page := range reader.PageList {
+		if page.MediaBox.Urx != float64(100+i) {
+			t.Errorf("Page %d out of order (%v)", i, page.MediaBox)
+		}
+	}
+	root, err := reader.GetStructTreeRoot()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if root == nil || len(root.K) != 1 || len(root.K[0].Kids) != 20 {
+		t.Fatalf("Wrong structure tree %v", root)
+	}
+	if elem := root.K[0].Kids[19].Element; elem == nil || elem.Alt != "Square" {
+		t.Errorf("Wrong element %v", elem)
+	}
+}
+
+func TestWriteXrefStream(t *testing.T) {
+	table := writeXrefModePdf(t, XrefModeTable, false)
+	data := writeXrefModePdf(t, XrefModeStream, false)
+	if len(data) >= len(table) {
+		t.Errorf("Output not smaller with object streams (%d >= %d)", len(data), len(table))
+	}
+	if bytes.Contains(data, []byte("xref\r\n")) || !bytes.Contains(data, []byte("/Type /XRef")) ||
+		!bytes.Contains(data, []byte("/Type /ObjStm")) {
+		t.Errorf("No cross-reference stream or object streams")
+	}
+	if bytes.Contains(data, []byte("/Type /Catalog")) {
+		t.Errorf("Catalog not compressed")
+	}
+	checkXrefModePdf(t, data, "")
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if version := reader.catalog.Get("Version"); version == nil || version.String() != "1.5" {
+		t.Errorf("Wrong version %v", version)
+	}
+}
+
+func TestWriteXrefStreamEncrypted(t *testing.T) {
+	data := writeXrefModePdf(t, XrefModeStream, true)
+	checkXrefModePdf(t, data, "user")
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if _, err := reader.Decrypt([]byte("user")); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	contents, err := reader.PageList[3].GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.HasPrefix([]byte(contents), []byte("/P <</MCID 0>> BDC")) {
+		t.Errorf("Wrong contents %q", contents)
+	}
+}
+
+func TestWriteXrefHybrid(t *testing.T) {
+	data := writeXrefModePdf(t, XrefModeHybrid, false)
+	if !bytes.Contains(data, []byte("xref\r\n")) || !bytes.Contains(data, []byte("/XRefStm ")) {
+		t.Errorf("No cross-reference table with a cross-reference stream")
+	}
+	// The pages are readable without the cross-reference stream, unlike the structure elements.
+	if !bytes.Contains(data, []byte("/Type /Catalog")) || !bytes.Contains(data, []byte("/Type /Page/")) {
+		t.Errorf("Pages compressed")
+	}
+	if bytes.Contains(data, []byte("/Alt ")) {
+		t.Errorf("Structure elements not compressed")
+	}
+	checkXrefModePdf(t, data, "")
+}
@@ -0,0 +1,19 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package optimize reduces the size of PDF documents, e.g. for sending them by email, by downsampling and
+// recompressing their images.
+//
+// Example: downsampling the images of a document to 150 DPI, recompressed as JPEG.
+//
+//	opts := optimize.ImageOptions{TargetDPI: 150, Encoding: optimize.ImageEncodingDCT, Quality: 70}
+//	n, err := optimize.RecompressImages(pdfReader, opts)
+//	...
+//	// Write the pages of pdfReader with a PdfWriter.
+//
+// Images lists the images drawn on the pages, with their resolution at their largest placement:
+//
+//	images, err := optimize.Images(pdfReader)
+package optimize
@@ -0,0 +1,335 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// ImageEncoding is the encoding of the images recompressed.
+type ImageEncoding int
+
+const (
+	// The encoding of the original image: DCT (JPEG) for DCT images, Flate for the others.  The images not
+	// downsampled are left unchanged.
+	ImageEncodingAuto ImageEncoding = iota
+	// DCT (JPEG), lossy, for photographs.  The images DCT cannot encode, e.g. with an Indexed or CMYK colorspace, are
+	// Flate encoded.
+	ImageEncodingDCT
+	// Flate with PNG predictors, lossless.
+	ImageEncodingFlate
+	// CCITT Group 4, lossless, for bilevel images such as scanned text, the other images being Flate encoded.
+	ImageEncodingCCITT
+)
+
+// ImageOptions are the options of the recompression of images.
+type ImageOptions struct {
+	// The resolution in pixels per inch the images are downsampled to, at their largest placement on the pages.  The
+	// images of lower resolution are not upsampled.  No downsampling if 0.
+	TargetDPI float64
+
+	// The images are only downsampled if their resolution exceeds TargetDPI by this factor (e.g. 1.5), so that images
+	// slightly above the target are not degraded for little gain.  1 if lower.
+	Threshold float64
+
+	// The encoding of the images recompressed.
+	Encoding ImageEncoding
+
+	// The quality of the DCT encoding, from 1 to 100, core.DefaultJPEGQuality if 0.
+	Quality int
+}
+
+// Image is an image XObject drawn on the pages of a document.
+type Image struct {
+	// The stream of the image, modified in place when recompressed.
+	Stream *core.PdfObjectStream
+
+	// The size of the image in pixels.
+	Width  int
+	Height int
+
+	// The numbers of the pages drawing the image, from 1.
+	Pages []int
+
+	// The horizontal and vertical resolutions of the image in pixels per inch, along its width and height, at its
+	// largest placement on the pages.
+	XDPI float64
+	YDPI float64
+}
+
+// Images returns the image XObjects drawn on the pages of the document loaded by reader, including those drawn in
+// Form XObjects, in the order they are first drawn.  Inline images and images that cannot be decoded are not
+// included.
+func Images(reader *model.PdfReader) ([]*Image, error) {
+	images := []*Image{}
+	byStream := map[*core.PdfObjectStream]*Image{}
+	for i, page := range reader.PageList {
+		e, err := extractor.New(page)
+		if err != nil {
+			return nil, err
+		}
+		marks, err := e.ExtractImages()
+		if err != nil {
+			return nil, err
+		}
+		for _, mark := range marks {
+			if mark.Stream == nil {
+				continue
+			}
+			img, has := byStream[mark.Stream]
+			if !has {
+				img = &Image{Stream: mark.Stream, Width: int(mark.Image.Width), Height: int(mark.Image.Height)}
+				byStream[mark.Stream] = img
+				images = append(images, img)
+			}
+			if len(img.Pages) == 0 || img.Pages[len(img.Pages)-1] != i+1 {
+				img.Pages = append(img.Pages, i+1)
+			}
+
+			// The size of the placement in inches.
+			width := mark.CTM.ScalingFactorX() * e.UserUnit() / 72
+			height := mark.CTM.ScalingFactorY() * e.UserUnit() / 72
+			if width > 0 {
+				if dpi := float64(img.Width) / width; img.XDPI == 0 || dpi < img.XDPI {
+					img.XDPI = dpi
+				}
+			}
+			if height > 0 {
+				if dpi := float64(img.Height) / height; img.YDPI == 0 || dpi < img.YDPI {
+					img.YDPI = dpi
+				}
+			}
+		}
+	}
+	return images, nil
+}
+
+// RecompressImages downsamples and recompresses the images drawn on the pages of the document loaded by reader (see
+// RecompressImage), modifying them in place, and returns the number of images changed.  The pages of the reader can
+// then be written with a PdfWriter.
+func RecompressImages(reader *model.PdfReader, opts ImageOptions) (int, error) {
+	images, err := Images(reader)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, img := range images {
+		changed, err := RecompressImage(img, opts)
+		if err != nil {
+			return count, err
+		}
+		if changed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// RecompressImage downsamples the image to the target resolution of the options and encodes it with the encoding of
+// the options, replacing the data of its stream if smaller.  Returns whether the image was changed.  Image masks,
+// images that cannot be decoded and images whose soft mask has a Matte entry, which requires the same size, are not
+// downsampled.
+func RecompressImage(img *Image, opts ImageOptions) (bool, error) {
+	ximg, err := model.NewXObjectImageFromStream(img.Stream)
+	if err != nil {
+		return false, err
+	}
+	if isMask, ok := core.TraceToDirectObject(ximg.ImageMask).(*core.PdfObjectBool); ok && bool(*isMask) {
+		return false, nil
+	}
+	if ximg.ColorSpace == nil || ximg.BitsPerComponent == nil {
+		common.Log.Debug("Image without colorspace or bits per component skipped")
+		return false, nil
+	}
+
+	scaleX, scaleY := 1.0, 1.0
+	threshold := math.Max(opts.Threshold, 1)
+	if opts.TargetDPI > 0 && !hasMatte(ximg) {
+		if img.XDPI > opts.TargetDPI*threshold {
+			scaleX = opts.TargetDPI / img.XDPI
+		}
+		if img.YDPI > opts.TargetDPI*threshold {
+			scaleY = opts.TargetDPI / img.YDPI
+		}
+	}
+	downsample := scaleX < 1 || scaleY < 1
+	if !downsample && opts.Encoding == ImageEncodingAuto {
+		return false, nil
+	}
+
+	decoded, err := ximg.ToImage()
+	if err != nil {
+		common.Log.Debug("Image not decoded, skipped: %v", err)
+		return false, nil
+	}
+	samples := &imageSamples{
+		data:   decoded.Data,
+		width:  int(decoded.Width),
+		height: int(decoded.Height),
+		comps:  decoded.ColorComponents,
+		bpc:    int(decoded.BitsPerComponent),
+	}
+	if samples.comps < 1 || samples.width < 1 || samples.height < 1 ||
+		len(samples.data) < samples.rowBytes()*samples.height {
+		common.Log.Debug("ERROR: Image data too short (%d bytes)", len(samples.data))
+		return false, errors.New("Image data too short")
+	}
+
+	if downsample {
+		width := int(math.Max(1, math.Round(float64(samples.width)*scaleX)))
+		height := int(math.Max(1, math.Round(float64(samples.height)*scaleY)))
+		_, isIndexed := ximg.ColorSpace.(*model.PdfColorspaceSpecialIndexed)
+		samples = samples.downsample(width, height, isIndexed)
+	}
+
+	encoder := imageEncoder(ximg, samples, opts)
+	encoded, err := encoder.EncodeBytes(samples.data)
+	if err != nil {
+		common.Log.Debug("ERROR: Failed encoding image: %v", err)
+		return false, err
+	}
+	if len(encoded) >= len(img.Stream.Stream) {
+		common.Log.Debug("Image not recompressed, not smaller (%d >= %d)", len(encoded), len(img.Stream.Stream))
+		return false, nil
+	}
+
+	width, height := int64(samples.width), int64(samples.height)
+	ximg.Width, ximg.Height = &width, &height
+	ximg.Filter = encoder
+	ximg.Stream = encoded
+	ximg.ToPdfObject()
+
+	img.XDPI *= float64(samples.width) / float64(img.Width)
+	img.YDPI *= float64(samples.height) / float64(img.Height)
+	img.Width, img.Height = samples.width, samples.height
+	return true, nil
+}
+
+// imageEncoder returns the encoder of the image samples with the options.
+func imageEncoder(ximg *model.XObjectImage, samples *imageSamples, opts ImageOptions) core.StreamEncoder {
+	encoding := opts.Encoding
+	if encoding == ImageEncodingAuto {
+		encoding = ImageEncodingFlate
+		if ximg.Filter.GetFilterName() == core.StreamEncodingFilterNameDCT {
+			encoding = ImageEncodingDCT
+		}
+	}
+
+	_, isIndexed := ximg.ColorSpace.(*model.PdfColorspaceSpecialIndexed)
+	switch {
+	case encoding == ImageEncodingDCT && samples.bpc == 8 && (samples.comps == 1 || samples.comps == 3) &&
+		!isIndexed && ximg.Mask == nil:
+		// Not with color key masks, which need the exact colors.
+		encoder := core.NewDCTEncoder()
+		encoder.Width, encoder.Height = samples.width, samples.height
+		encoder.ColorComponents = samples.comps
+		encoder.BitsPerComponent = samples.bpc
+		if opts.Quality > 0 {
+			encoder.Quality = opts.Quality
+		}
+		return encoder
+	case encoding == ImageEncodingCCITT && samples.bpc == 1 && samples.comps == 1:
+		// The samples are encoded unchanged (0 bits are black), so that the Decode array of the image still applies.
+		encoder := core.NewCCITTFaxEncoder()
+		encoder.K = -1
+		encoder.Columns, encoder.Rows = samples.width, samples.height
+		return encoder
+	}
+	encoder := core.NewFlateEncoder()
+	encoder.Predictor = 15
+	encoder.Columns = samples.width
+	encoder.Colors = samples.comps
+	encoder.BitsPerComponent = samples.bpc
+	return encoder
+}
+
+// hasMatte returns true if the soft mask of the image has a Matte entry.
+func hasMatte(ximg *model.XObjectImage) bool {
+	smask, ok := core.TraceToDirectObject(ximg.SMask).(*core.PdfObjectStream)
+	return ok && smask.Get("Matte") != nil
+}
+
+// imageSamples are the samples of an image, each row starting at a byte boundary.
+type imageSamples struct {
+	data          []byte
+	width, height int
+	comps, bpc    int
+}
+
+// rowBytes returns the number of bytes of a row.
+func (s *imageSamples) rowBytes() int {
+	return (s.width*s.comps*s.bpc + 7) / 8
+}
+
+// get returns the sample of the component of the pixel.
+func (s *imageSamples) get(x, y, comp int) uint32 {
+	bit := (x*s.comps + comp) * s.bpc
+	off := y*s.rowBytes() + bit/8
+	switch s.bpc {
+	case 8:
+		return uint32(s.data[off])
+	case 16:
+		return uint32(s.data[off])<<8 | uint32(s.data[off+1])
+	}
+	shift := uint(8 - s.bpc - bit%8)
+	return uint32(s.data[off]>>shift) & (1<<uint(s.bpc) - 1)
+}
+
+// set sets the sample of the component of the pixel, initially 0.
+func (s *imageSamples) set(x, y, comp int, val uint32) {
+	bit := (x*s.comps + comp) * s.bpc
+	off := y*s.rowBytes() + bit/8
+	switch s.bpc {
+	case 8:
+		s.data[off] = byte(val)
+	case 16:
+		s.data[off], s.data[off+1] = byte(val>>8), byte(val)
+	default:
+		s.data[off] |= byte(val << uint(8-s.bpc-bit%8))
+	}
+}
+
+// downsample returns the samples downsampled to the size, averaging the samples of the pixels covered by each new
+// pixel, or taking the first of them for indexed colors, which cannot be averaged.
+func (s *imageSamples) downsample(width, height int, indexed bool) *imageSamples {
+	out := &imageSamples{width: width, height: height, comps: s.comps, bpc: s.bpc}
+	out.data = make([]byte, out.rowBytes()*height)
+	for y := 0; y < height; y++ {
+		y0 := y * s.height / height
+		y1 := (y + 1) * s.height / height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := x * s.width / width
+			x1 := (x + 1) * s.width / width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			for comp := 0; comp < s.comps; comp++ {
+				if indexed {
+					out.set(x, y, comp, s.get(x0, y0, comp))
+					continue
+				}
+				sum := uint64(0)
+				for sy := y0; sy < y1; sy++ {
+					for sx := x0; sx < x1; sx++ {
+						sum += uint64(s.get(sx, sy, comp))
+					}
+				}
+				n := uint64((x1 - x0) * (y1 - y0))
+				out.set(x, y, comp, uint32((sum+n/2)/n))
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// makeTestReader writes pages drawing the images with the contents and returns the reader of the document written.
+func makeTestReader(t *testing.T, images map[core.PdfObjectName]*model.XObjectImage, contents []string) *model.PdfReader {
+	w := model.NewPdfWriter()
+	for _, content := range contents {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+		page.Resources = model.NewPdfPageResources()
+		for name, ximg := range images {
+			if err := page.Resources.SetXObjectImageByName(name, ximg); err != nil {
+				t.Fatalf("Error: %v", err)
+			}
+		}
+		if err := page.SetContentStreams([]string{content}, nil); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return reader
+}
+
+// makeNoiseImage returns an RGB image of pseudo-random colors, which compresses poorly with Flate.
+func makeNoiseImage(t *testing.T, width, height int) *model.XObjectImage {
+	img := &model.Image{Width: int64(width), Height: int64(height), BitsPerComponent: 8, ColorComponents: 3}
+	img.Data = make([]byte, width*height*3)
+	seed := uint32(1)
+	for i := range img.Data {
+		seed = seed*1103515245 + 12345
+		img.Data[i] = byte(seed >> 16)
+	}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewFlateEncoder())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return ximg
+}
+
+func TestRecompressImages(t *testing.T) {
+	ximg := makeNoiseImage(t, 600, 400)
+	// Drawn 2 inches wide on the first page and 1 inch wide on the second.
+	reader := makeTestReader(t, map[core.PdfObjectName]*model.XObjectImage{"Im1": ximg},
+		[]string{"q 144 0 0 96 0 0 cm /Im1 Do Q", "q 72 0 0 48 0 0 cm /Im1 Do Q"})
+
+	images, err := Images(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(images) != 1 {
+		t.Fatalf("Wrong number of images %d", len(images))
+	}
+	img := images[0]
+	if len(img.Pages) != 2 || math.Abs(img.XDPI-300) > 1e-6 || math.Abs(img.YDPI-300) > 1e-6 {
+		t.Fatalf("Wrong image %+v", img)
+	}
+	size := len(img.Stream.Stream)
+
+	// Not downsampled below the threshold, and left unchanged.
+	changed, err := RecompressImage(img, ImageOptions{TargetDPI: 250, Threshold: 1.5})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if changed {
+		t.Errorf("Image changed below the threshold")
+	}
+
+	count, err := RecompressImages(reader, ImageOptions{TargetDPI: 150, Encoding: ImageEncodingDCT, Quality: 60})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Wrong number of images recompressed %d", count)
+	}
+	if len(img.Stream.Stream) >= size {
+		t.Errorf("Image not smaller (%d >= %d)", len(img.Stream.Stream), size)
+	}
+
+	images, err = Images(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if images[0].Width != 300 || images[0].Height != 200 || math.Abs(images[0].XDPI-150) > 1e-6 {
+		t.Errorf("Wrong image %+v", images[0])
+	}
+	if filter, ok := images[0].Stream.Get("Filter").(*core.PdfObjectName); !ok || *filter != "DCTDecode" {
+		t.Errorf("Wrong filter %v", images[0].Stream.Get("Filter"))
+	}
+}
+
+func TestRecompressImageCCITT(t *testing.T) {
+	// A bilevel image like scanned text: black bars on a white background (0 bits are black).
+	img := &model.Image{Width: 64, Height: 64, BitsPerComponent: 1, ColorComponents: 1}
+	img.Data = bytes.Repeat([]byte{0xff}, 8*64)
+	for y := 8; y < 56; y += 8 {
+		for x := 1; x < 7; x++ {
+			img.Data[y*8+x] = byte(y * 7)
+			img.Data[(y+1)*8+x] = 0
+		}
+	}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader := makeTestReader(t, map[core.PdfObjectName]*model.XObjectImage{"Im1": ximg},
+		[]string{"q 72 0 0 72 0 0 cm /Im1 Do Q"})
+
+	count, err := RecompressImages(reader, ImageOptions{Encoding: ImageEncodingCCITT})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Wrong number of images recompressed %d", count)
+	}
+	images, err := Images(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	stream := images[0].Stream
+	if filter, ok := stream.Get("Filter").(*core.PdfObjectName); !ok || *filter != "CCITTFaxDecode" {
+		t.Fatalf("Wrong filter %v", stream.Get("Filter"))
+	}
+	decoded, err := core.DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(decoded, img.Data) {
+		t.Errorf("Wrong decoded image")
+	}
+}
+
+func TestDownsampleSamples(t *testing.T) {
+	// 1 bit per component, rows of 3 pixels padded to a byte.
+	s := &imageSamples{data: []byte{0xc0, 0xe0, 0x20, 0x00}, width: 3, height: 4, comps: 1, bpc: 1}
+	out := s.downsample(2, 2, false)
+	if !bytes.Equal(out.data, []byte{0xc0, 0x00}) {
+		t.Errorf("Wrong samples % x", out.data)
+	}
+
+	// Indexed colors are not averaged.
+	s = &imageSamples{data: []byte{1, 3, 5, 7}, width: 2, height: 2, comps: 1, bpc: 8}
+	if out := s.downsample(1, 1, true); out.data[0] != 1 {
+		t.Errorf("Wrong indexed sample %d", out.data[0])
+	}
+	if out := s.downsample(1, 1, false); out.data[0] != 4 {
+		t.Errorf("Wrong averaged sample %d", out.data[0])
+	}
+}
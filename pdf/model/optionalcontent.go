@@ -0,0 +1,256 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Maximum nesting depth of visibility expressions, guarding against cyclic references.
+const maxVisibilityExpressionDepth = 32
+
+// OptionalContentState is the state (ON or OFF) of the optional content groups of a document, determining the
+// visibility of optional content: content associated with an optional content group (OCG) or membership dictionary
+// (OCMD), e.g. the layers of CAD drawings.
+type OptionalContentState struct {
+	// The state of the groups, by group dictionary.
+	groups map[*PdfObjectDictionary]bool
+
+	// The state of the groups not listed in the configuration.
+	baseState bool
+}
+
+// NewOptionalContentState returns the state of the optional content groups of the default configuration (D) of
+// the optional content properties dictionary (OCProperties of the catalog), or with all groups ON if nil.
+func NewOptionalContentState(ocProperties PdfObject) (*OptionalContentState, error) {
+	state := &OptionalContentState{groups: map[*PdfObjectDictionary]bool{}, baseState: true}
+	if ocProperties == nil {
+		return state, nil
+	}
+	if _, isNull := TraceToDirectObject(ocProperties).(*PdfObjectNull); isNull {
+		return state, nil
+	}
+	props, ok := TraceToDirectObject(ocProperties).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ERROR: Invalid optional content properties (%T)", ocProperties)
+		return nil, ErrTypeError
+	}
+
+	config, _ := TraceToDirectObject(props.Get("D")).(*PdfObjectDictionary)
+	if config != nil {
+		if base, ok := TraceToDirectObject(config.Get("BaseState")).(*PdfObjectName); ok && *base == "OFF" {
+			state.baseState = false
+		}
+	}
+	if ocgs, ok := TraceToDirectObject(props.Get("OCGs")).(*PdfObjectArray); ok {
+		for _, ocg := range *ocgs {
+			if group, ok := TraceToDirectObject(ocg).(*PdfObjectDictionary); ok {
+				state.groups[group] = state.baseState
+			}
+		}
+	}
+	if config != nil {
+		for _, key := range []PdfObjectName{"ON", "OFF"} {
+			arr, ok := TraceToDirectObject(config.Get(key)).(*PdfObjectArray)
+			if !ok {
+				continue
+			}
+			for _, ocg := range *arr {
+				if group, ok := TraceToDirectObject(ocg).(*PdfObjectDictionary); ok {
+					state.groups[group] = key == "ON"
+				}
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// GetOptionalContentState returns the state of the optional content groups of the document in its default
+// configuration.
+func (this *PdfReader) GetOptionalContentState() (*OptionalContentState, error) {
+	if this.catalog.Get("OCProperties") == nil {
+		return NewOptionalContentState(nil)
+	}
+	ocProperties, err := this.GetOCProperties()
+	if err != nil {
+		return nil, err
+	}
+	return NewOptionalContentState(ocProperties)
+}
+
+// SetGroupVisible sets the state of an optional content group.
+func (this *OptionalContentState) SetGroupVisible(ocg PdfObject, visible bool) error {
+	group, ok := TraceToDirectObject(ocg).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ERROR: Invalid optional content group (%T)", ocg)
+		return ErrTypeError
+	}
+	this.groups[group] = visible
+	return nil
+}
+
+// IsGroupVisible returns true if an optional content group is ON.
+func (this *OptionalContentState) IsGroupVisible(ocg PdfObject) bool {
+	group, ok := TraceToDirectObject(ocg).(*PdfObjectDictionary)
+	if !ok {
+		return true
+	}
+	if visible, has := this.groups[group]; has {
+		return visible
+	}
+	return this.baseState
+}
+
+// IsVisible returns true if the content associated with an optional content group or membership dictionary (the
+// OC entry of XObjects and annotations, or the property list of OC marked content) is visible.  The visibility
+// of a membership dictionary is determined by its visibility expression (VE) if present, otherwise by its groups
+// (OCGs) and visibility policy (P).
+func (this *OptionalContentState) IsVisible(oc PdfObject) (bool, error) {
+	if oc == nil {
+		return true, nil
+	}
+	dict, ok := TraceToDirectObject(oc).(*PdfObjectDictionary)
+	if !ok {
+		if _, isNull := TraceToDirectObject(oc).(*PdfObjectNull); isNull {
+			return true, nil
+		}
+		common.Log.Debug("ERROR: Invalid optional content (%T)", oc)
+		return false, ErrTypeError
+	}
+
+	if name, ok := TraceToDirectObject(dict.Get("Type")).(*PdfObjectName); !ok || *name != "OCMD" {
+		return this.IsGroupVisible(dict), nil
+	}
+
+	if ve := dict.Get("VE"); ve != nil {
+		if _, isNull := TraceToDirectObject(ve).(*PdfObjectNull); !isNull {
+			return this.evalVisibilityExpression(ve, 0)
+		}
+	}
+
+	// The groups of the membership dictionary: a single group or an array, null entries being ignored.
+	groups := []*PdfObjectDictionary{}
+	switch t := TraceToDirectObject(dict.Get("OCGs")).(type) {
+	case *PdfObjectDictionary:
+		groups = append(groups, t)
+	case *PdfObjectArray:
+		for _, ocg := range *t {
+			if group, ok := TraceToDirectObject(ocg).(*PdfObjectDictionary); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+	if len(groups) == 0 {
+		return true, nil
+	}
+
+	on := 0
+	for _, group := range groups {
+		if this.IsGroupVisible(group) {
+			on++
+		}
+	}
+	policy := PdfObjectName("AnyOn")
+	if p, ok := TraceToDirectObject(dict.Get("P")).(*PdfObjectName); ok {
+		policy = *p
+	}
+	switch policy {
+	case "AllOn":
+		return on == len(groups), nil
+	case "AnyOff":
+		return on < len(groups), nil
+	case "AllOff":
+		return on == 0, nil
+	case "AnyOn":
+	default:
+		common.Log.Debug("Invalid visibility policy %s, using AnyOn", policy)
+	}
+	return on > 0, nil
+}
+
+// evalVisibilityExpression evaluates a visibility expression: an optional content group, or an array with an
+// operator (And, Or, Not) followed by its operands, which are groups or visibility expressions.
+func (this *OptionalContentState) evalVisibilityExpression(ve PdfObject, depth int) (bool, error) {
+	if depth >= maxVisibilityExpressionDepth {
+		common.Log.Debug("ERROR: Visibility expression nested too deep")
+		return false, errors.New("Visibility expression recursion limit exceeded")
+	}
+
+	switch t := TraceToDirectObject(ve).(type) {
+	case *PdfObjectDictionary:
+		return this.IsGroupVisible(t), nil
+	case *PdfObjectArray:
+		if len(*t) == 0 {
+			common.Log.Debug("ERROR: Empty visibility expression")
+			return false, ErrInvalidAttribute
+		}
+		op, ok := TraceToDirectObject((*t)[0]).(*PdfObjectName)
+		if !ok {
+			common.Log.Debug("ERROR: Invalid visibility expression operator (%T)", (*t)[0])
+			return false, ErrTypeError
+		}
+		operands := (*t)[1:]
+		switch *op {
+		case "Not":
+			if len(operands) != 1 {
+				common.Log.Debug("ERROR: Not visibility expression with %d operands", len(operands))
+				return false, ErrRangeError
+			}
+			visible, err := this.evalVisibilityExpression(operands[0], depth+1)
+			return !visible, err
+		case "And", "Or":
+			if len(operands) == 0 {
+				common.Log.Debug("ERROR: %s visibility expression without operands", *op)
+				return false, ErrRangeError
+			}
+			result := *op == "And"
+			for _, operand := range operands {
+				visible, err := this.evalVisibilityExpression(operand, depth+1)
+				if err != nil {
+					return false, err
+				}
+				if *op == "And" {
+					result = result && visible
+				} else {
+					result = result || visible
+				}
+			}
+			return result, nil
+		}
+		common.Log.Debug("ERROR: Invalid visibility expression operator %s", *op)
+		return false, ErrInvalidAttribute
+	}
+
+	common.Log.Debug("ERROR: Invalid visibility expression (%T)", ve)
+	return false, ErrTypeError
+}
+
+// IsMarkedContentVisible returns true if the content of a marked content sequence is visible.  Only OC sequences
+// are optional: their property list is an optional content group or membership dictionary, or the name of one
+// in the Properties resources.
+func (this *OptionalContentState) IsMarkedContentVisible(tag PdfObjectName, properties PdfObject,
+	resources *PdfPageResources) (bool, error) {
+	if tag != "OC" || properties == nil {
+		return true, nil
+	}
+	if name, ok := properties.(*PdfObjectName); ok {
+		if resources == nil {
+			common.Log.Debug("ERROR: Optional content %s without resources", *name)
+			return false, ErrRequiredAttributeMissing
+		}
+		dict, ok := TraceToDirectObject(resources.Properties).(*PdfObjectDictionary)
+		if !ok || dict.Get(*name) == nil {
+			common.Log.Debug("ERROR: Optional content %s not found in the resources", *name)
+			return false, ErrRequiredAttributeMissing
+		}
+		properties = dict.Get(*name)
+	}
+	return this.IsVisible(properties)
+}
@@ -0,0 +1,119 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// makeOCG returns an optional content group.
+func makeOCG(name string) *PdfIndirectObject {
+	dict := MakeDict()
+	dict.Set("Type", MakeName("OCG"))
+	dict.Set("Name", MakeString(name))
+	return MakeIndirectObject(dict)
+}
+
+// makeOCMD returns an optional content membership dictionary with the entries.
+func makeOCMD(entries map[PdfObjectName]PdfObject) *PdfObjectDictionary {
+	dict := MakeDict()
+	dict.Set("Type", MakeName("OCMD"))
+	for key, val := range entries {
+		dict.Set(key, val)
+	}
+	return dict
+}
+
+func TestOptionalContentVisibility(t *testing.T) {
+	walls, doors, text := makeOCG("Walls"), makeOCG("Doors"), makeOCG("Text")
+	config := MakeDict()
+	config.Set("OFF", MakeArray(doors))
+	props := MakeDict()
+	props.Set("OCGs", MakeArray(walls, doors, text))
+	props.Set("D", config)
+
+	state, err := NewOptionalContentState(props)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !state.IsGroupVisible(walls) || state.IsGroupVisible(doors) {
+		t.Fatalf("Wrong group states")
+	}
+
+	tests := []struct {
+		oc       PdfObject
+		expected bool
+	}{
+		{nil, true},
+		{walls, true},
+		{doors, false},
+		{makeOCMD(map[PdfObjectName]PdfObject{"OCGs": MakeArray(walls, doors)}), true},
+		{makeOCMD(map[PdfObjectName]PdfObject{"OCGs": MakeArray(walls, doors), "P": MakeName("AllOn")}), false},
+		{makeOCMD(map[PdfObjectName]PdfObject{"OCGs": MakeArray(walls, doors), "P": MakeName("AnyOff")}), true},
+		{makeOCMD(map[PdfObjectName]PdfObject{"OCGs": doors, "P": MakeName("AllOff")}), true},
+		{makeOCMD(map[PdfObjectName]PdfObject{"OCGs": MakeArray(MakeNull())}), true},
+		// The visibility expression takes precedence over the groups.
+		{makeOCMD(map[PdfObjectName]PdfObject{
+			"OCGs": walls,
+			"VE":   MakeArray(MakeName("Not"), walls),
+		}), false},
+		{makeOCMD(map[PdfObjectName]PdfObject{
+			"VE": MakeArray(MakeName("And"), walls, MakeArray(MakeName("Not"), doors)),
+		}), true},
+		{makeOCMD(map[PdfObjectName]PdfObject{
+			"VE": MakeArray(MakeName("Or"), doors, MakeArray(MakeName("And"), text, MakeArray(MakeName("Not"), walls))),
+		}), false},
+	}
+	for i, test := range tests {
+		visible, err := state.IsVisible(test.oc)
+		if err != nil {
+			t.Errorf("Test %d: error %v", i, err)
+			continue
+		}
+		if visible != test.expected {
+			t.Errorf("Test %d: visible %v, expected %v", i, visible, test.expected)
+		}
+	}
+
+	// Invalid expressions.
+	invalid := []PdfObject{
+		makeOCMD(map[PdfObjectName]PdfObject{"VE": MakeArray(MakeName("Xor"), walls)}),
+		makeOCMD(map[PdfObjectName]PdfObject{"VE": MakeArray(MakeName("Not"), walls, doors)}),
+		makeOCMD(map[PdfObjectName]PdfObject{"VE": MakeArray()}),
+	}
+	for i, oc := range invalid {
+		if _, err := state.IsVisible(oc); err == nil {
+			t.Errorf("Invalid expression %d evaluated", i)
+		}
+	}
+
+	// Marked content referring to the Properties resources.
+	ocmd := makeOCMD(map[PdfObjectName]PdfObject{"VE": MakeArray(MakeName("Or"), doors, text)})
+	resources := NewPdfPageResources()
+	properties := MakeDict()
+	properties.Set("MC0", ocmd)
+	resources.Properties = properties
+	visible, err := state.IsMarkedContentVisible("OC", MakeName("MC0"), resources)
+	if err != nil || !visible {
+		t.Errorf("Marked content not visible (%v)", err)
+	}
+	err = state.SetGroupVisible(text, false)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	visible, err = state.IsMarkedContentVisible("OC", MakeName("MC0"), resources)
+	if err != nil || visible {
+		t.Errorf("Marked content visible (%v)", err)
+	}
+	if _, err = state.IsMarkedContentVisible("OC", MakeName("MC1"), resources); err == nil {
+		t.Errorf("Missing properties resolved")
+	}
+	if visible, _ = state.IsMarkedContentVisible("Span", MakeName("MC1"), resources); !visible {
+		t.Errorf("Marked content other than OC not visible")
+	}
+}
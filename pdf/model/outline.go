@@ -0,0 +1,245 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Outline item flags (Table 153 - pp. 376 - 377).
+const (
+	outlineItemItalic = 1
+	outlineItemBold   = 2
+)
+
+// Outline is the document outline (bookmarks) as a tree of items which can be traversed and modified directly,
+// unlike the outline dictionaries of PdfOutline.  It is loaded with PdfReader.GetOutline and written with
+// PdfWriter.SetOutline.
+type Outline struct {
+	// The top level items.
+	Items []*OutlineItem
+}
+
+// OutlineItem is an item of the document outline with its children.
+type OutlineItem struct {
+	Title string
+
+	// The destination: an explicit destination array with the page object, or the name (name or string) of a
+	// named destination.  Nil if none.
+	Dest PdfObject
+
+	// The action performed when the item is activated (action dictionary), nil if none.
+	Action PdfObject
+
+	// Whether the item is open, showing its children.
+	Open bool
+
+	// The color of the title, nil for black.
+	Color *PdfColorDeviceRGB
+
+	// The style of the title.
+	Italic bool
+	Bold   bool
+
+	Items []*OutlineItem
+}
+
+// NewOutline returns an empty outline.
+func NewOutline() *Outline {
+	return &Outline{}
+}
+
+// NewOutlineItem returns an outline item with the title and the destination.
+func NewOutlineItem(title string, dest PdfObject) *OutlineItem {
+	return &OutlineItem{Title: title, Dest: dest}
+}
+
+// Walk calls fn for the items of the outline in depth-first order, with their level (0 for the top level items).
+// The children of the items for which fn returns false are skipped.
+func (this *Outline) Walk(fn func(item *OutlineItem, level int) bool) {
+	walkOutlineItems(this.Items, 0, fn)
+}
+
+func walkOutlineItems(items []*OutlineItem, level int, fn func(item *OutlineItem, level int) bool) {
+	for _, item := range items {
+		if fn(item, level) {
+			walkOutlineItems(item.Items, level+1, fn)
+		}
+	}
+}
+
+// MapDestinations rewrites the destinations of the items with fn, e.g. for the pages copied to another document.
+// The destinations of the GoTo actions are rewritten as well, in copies of the actions.  fn returns false for the
+// destinations to remove, and the items left with neither destination, action nor children are removed.
+func (this *Outline) MapDestinations(fn func(dest PdfObject) (PdfObject, bool)) {
+	this.Items = mapOutlineItems(this.Items, fn)
+}
+
+func mapOutlineItems(items []*OutlineItem, fn func(dest PdfObject) (PdfObject, bool)) []*OutlineItem {
+	mapped := []*OutlineItem{}
+	for _, item := range items {
+		item.Items = mapOutlineItems(item.Items, fn)
+		if item.Dest != nil {
+			dest, ok := fn(item.Dest)
+			if !ok {
+				dest = nil
+			}
+			item.Dest = dest
+		}
+		if action, ok := TraceToDirectObject(item.Action).(*PdfObjectDictionary); ok {
+			if s, ok := TraceToDirectObject(action.Get("S")).(*PdfObjectName); ok && *s == "GoTo" {
+				if dest, ok := fn(action.Get("D")); ok {
+					copied := MakeDict()
+					copied.Merge(action)
+					copied.Set("D", dest)
+					item.Action = copied
+				} else {
+					item.Action = nil
+				}
+			}
+		}
+		if item.Dest == nil && item.Action == nil && len(item.Items) == 0 {
+			common.Log.Trace("Outline item %q removed", item.Title)
+			continue
+		}
+		mapped = append(mapped, item)
+	}
+	return mapped
+}
+
+// GetOutline loads the document outline, empty if the document has none.  The destinations are as in the
+// document: the explicit destinations refer to the page objects of the reader.
+func (this *PdfReader) GetOutline() (*Outline, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	outline := NewOutline()
+	if this.outlineTree != nil {
+		visited := map[*PdfOutlineTreeNode]bool{}
+		var err error
+		outline.Items, err = loadOutlineItems(this.outlineTree.First, visited)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outline, nil
+}
+
+// Loads the items from the node and its next siblings.
+func loadOutlineItems(node *PdfOutlineTreeNode, visited map[*PdfOutlineTreeNode]bool) ([]*OutlineItem, error) {
+	items := []*OutlineItem{}
+	for node != nil {
+		if visited[node] {
+			common.Log.Debug("ERROR: Circular reference in outline tree")
+			return nil, errors.New("Circular reference in outline tree")
+		}
+		visited[node] = true
+
+		outlineItem := node.GetOutlineItem()
+		if outlineItem == nil {
+			common.Log.Debug("ERROR: Outline node not an item (%T)", node.context)
+			return nil, ErrTypeError
+		}
+
+		item := &OutlineItem{Dest: outlineItem.Dest, Action: outlineItem.A}
+		if outlineItem.Title != nil {
+			item.Title = outlineItem.Title.Decoded()
+		}
+		item.Open = outlineItem.Count != nil && *outlineItem.Count > 0
+		if arr, ok := TraceToDirectObject(outlineItem.C).(*PdfObjectArray); ok {
+			if c, err := arr.ToFloat64Array(); err == nil && len(c) == 3 {
+				item.Color = NewPdfColorDeviceRGB(c[0], c[1], c[2])
+			} else {
+				common.Log.Debug("Invalid outline item color %v", arr)
+			}
+		}
+		if outlineItem.F != nil {
+			if flags, err := getNumberAsInt64(TraceToDirectObject(outlineItem.F)); err == nil {
+				item.Italic = flags&outlineItemItalic != 0
+				item.Bold = flags&outlineItemBold != 0
+			}
+		}
+
+		var err error
+		item.Items, err = loadOutlineItems(node.First, visited)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		node = outlineItem.Next
+	}
+	return items, nil
+}
+
+// SetOutline sets the document outline, written with the document.  The destinations of the items must refer to
+// the pages added to the writer, or to named destinations.  An empty outline removes the outline.
+func (this *PdfWriter) SetOutline(outline *Outline) {
+	if outline == nil || len(outline.Items) == 0 {
+		this.AddOutlineTree(nil)
+		return
+	}
+	this.AddOutlineTree(&outline.ToOutlineTree().PdfOutlineTreeNode)
+}
+
+// ToOutlineTree returns the outline dictionaries of the outline.
+func (this *Outline) ToOutlineTree() *PdfOutline {
+	tree := NewPdfOutlineTree()
+	count := linkOutlineItems(&tree.PdfOutlineTreeNode, this.Items)
+	tree.Count = &count
+	return tree
+}
+
+// Links new outline item dictionaries of the items as children of the parent, and returns the number of the items
+// visible when the parent is open.
+func linkOutlineItems(parent *PdfOutlineTreeNode, items []*OutlineItem) int64 {
+	visible := int64(0)
+	var prev *PdfOutlineItem
+	for _, item := range items {
+		outlineItem := NewPdfOutlineItem()
+		outlineItem.Title = MakeTextString(item.Title)
+		outlineItem.Dest = item.Dest
+		outlineItem.A = item.Action
+		if item.Color != nil {
+			outlineItem.C = MakeArrayFromFloats([]float64{item.Color.R(), item.Color.G(), item.Color.B()})
+		}
+		flags := int64(0)
+		if item.Italic {
+			flags |= outlineItemItalic
+		}
+		if item.Bold {
+			flags |= outlineItemBold
+		}
+		if flags != 0 {
+			outlineItem.F = MakeInteger(flags)
+		}
+
+		outlineItem.Parent = parent
+		if prev != nil {
+			prev.Next = &outlineItem.PdfOutlineTreeNode
+			outlineItem.Prev = &prev.PdfOutlineTreeNode
+		} else {
+			parent.First = &outlineItem.PdfOutlineTreeNode
+		}
+		parent.Last = &outlineItem.PdfOutlineTreeNode
+		prev = outlineItem
+
+		visible++
+		if children := linkOutlineItems(&outlineItem.PdfOutlineTreeNode, item.Items); children > 0 {
+			count := children
+			if item.Open {
+				visible += children
+			} else {
+				count = -children
+			}
+			outlineItem.Count = &count
+		}
+	}
+	return visible
+}
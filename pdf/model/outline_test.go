@@ -0,0 +1,153 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// describeOutline returns the items of the outline with their level, page index (or destination), action, state
+// and style, one per line.
+func describeOutline(reader *PdfReader, outline *Outline) string {
+	pageIndex := func(dest PdfObject) string {
+		arr, ok := TraceToDirectObject(dest).(*PdfObjectArray)
+		if !ok || len(*arr) == 0 {
+			return fmt.Sprint(dest)
+		}
+		for i, page := range reader.pageList {
+			if page == (*arr)[0] {
+				return fmt.Sprint(i)
+			}
+		}
+		return "?"
+	}
+
+	lines := []string{}
+	outline.Walk(func(item *OutlineItem, level int) bool {
+		line := fmt.Sprintf("%s%s", strings.Repeat("  ", level), item.Title)
+		if item.Dest != nil {
+			line += " dest " + pageIndex(item.Dest)
+		}
+		if action, ok := TraceToDirectObject(item.Action).(*PdfObjectDictionary); ok {
+			line += fmt.Sprintf(" action %s", action.Get("S"))
+			if d := action.Get("D"); d != nil {
+				line += " " + pageIndex(d)
+			}
+		}
+		if item.Open {
+			line += " open"
+		}
+		if item.Color != nil {
+			line += fmt.Sprintf(" color %g %g %g", item.Color.R(), item.Color.G(), item.Color.B())
+		}
+		if item.Bold {
+			line += " bold"
+		}
+		if item.Italic {
+			line += " italic"
+		}
+		lines = append(lines, line)
+		return true
+	})
+	return strings.Join(lines, "\n")
+}
+
+func TestOutline(t *testing.T) {
+	w := NewPdfWriter()
+	pages := []*PdfPage{}
+	for i := 0; i < 3; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 100}
+		page.Resources = NewPdfPageResources()
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		pages = append(pages, page)
+	}
+	pageDest := func(i int) PdfObject {
+		return MakeArray(pages[i].GetPageAsIndirectObject(), MakeName("Fit"))
+	}
+
+	chapter := NewOutlineItem("Chapter", pageDest(0))
+	chapter.Open = true
+	chapter.Bold = true
+	chapter.Color = NewPdfColorDeviceRGB(1, 0, 0)
+	section := NewOutlineItem("Section", nil)
+	goTo := MakeDict()
+	goTo.Set("S", MakeName("GoTo"))
+	goTo.Set("D", pageDest(1))
+	section.Action = goTo
+	section.Italic = true
+	section.Items = []*OutlineItem{NewOutlineItem("Subsection", pageDest(2))}
+	chapter.Items = []*OutlineItem{section}
+	uri := MakeDict()
+	uri.Set("S", MakeName("URI"))
+	uri.Set("URI", MakeString("https://unidoc.io"))
+	link := &OutlineItem{Title: "Web site", Action: uri}
+
+	outline := NewOutline()
+	outline.Items = []*OutlineItem{chapter, NewOutlineItem("Appendix", pageDest(2)), link}
+	w.SetOutline(outline)
+
+	var buf bytes.Buffer
+	err := w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	outline, err = reader.GetOutline()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	expected := `Chapter dest 0 open color 1 0 0 bold
+  Section action GoTo 1 italic
+    Subsection dest 2
+Appendix dest 2
+Web site action URI`
+	if str := describeOutline(reader, outline); str != expected {
+		t.Errorf("Wrong outline:\n%s", str)
+	}
+	// The open chapter and its section, the appendix and the link.
+	outlines, ok := TraceToDirectObject(reader.GetOutlineTree().GetContainingPdfObject()).(*PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Missing outlines")
+	}
+	if count, ok := outlines.Get("Count").(*PdfObjectInteger); !ok || *count != 4 {
+		t.Errorf("Wrong outline count %v", outlines.Get("Count"))
+	}
+	action := outline.Items[0].Items[0].Action
+
+	// Destinations to the last page removed, as when the other pages are copied to a new document: the items
+	// without destination are removed.
+	outline.MapDestinations(func(dest PdfObject) (PdfObject, bool) {
+		arr, ok := TraceToDirectObject(dest).(*PdfObjectArray)
+		if !ok || (*arr)[0] == reader.pageList[2] {
+			return nil, false
+		}
+		return MakeArray(reader.pageList[1], MakeName("FitH"), MakeFloat(10)), true
+	})
+	expected = `Chapter dest 1 open color 1 0 0 bold
+  Section action GoTo 1 italic
+Web site action URI`
+	if str := describeOutline(reader, outline); str != expected {
+		t.Errorf("Wrong mapped outline:\n%s", str)
+	}
+	// The action of the document is not modified.
+	d, ok := TraceToDirectObject(TraceToDirectObject(action).(*PdfObjectDictionary).Get("D")).(*PdfObjectArray)
+	if !ok || len(*d) != 2 {
+		t.Errorf("Action modified %v", d)
+	}
+}
@@ -51,14 +51,13 @@ func NewPdfOutline() *PdfOutline {
 	container.PdfObject = MakeDict()
 
 	outline.primitive = container
+	outline.context = outline
 
 	return outline
 }
 
 func NewPdfOutlineTree() *PdfOutline {
-	outlineTree := NewPdfOutline()
-	outlineTree.context = &outlineTree
-	return outlineTree
+	return NewPdfOutline()
 }
 
 func NewPdfOutlineItem() *PdfOutlineItem {
@@ -68,21 +67,21 @@ func NewPdfOutlineItem() *PdfOutlineItem {
 	container.PdfObject = MakeDict()
 
 	outlineItem.primitive = container
+	outlineItem.context = outlineItem
 	return outlineItem
 }
 
 func NewOutlineBookmark(title string, page *PdfIndirectObject) *PdfOutlineItem {
-	bookmark := PdfOutlineItem{}
-	bookmark.context = &bookmark
+	bookmark := NewPdfOutlineItem()
 
-	bookmark.Title = MakeString(title)
+	bookmark.Title = MakeTextString(title)
 
 	destArray := PdfObjectArray{}
 	destArray = append(destArray, page)
 	destArray = append(destArray, MakeName("Fit"))
 	bookmark.Dest = &destArray
 
-	return &bookmark
+	return bookmark
 }
 
 // Does not traverse the tree.
@@ -217,6 +216,12 @@ func (n *PdfOutlineTreeNode) getOuter() PdfModel {
 	return nil
 }
 
+// GetOutlineItem returns the outline item of the tree node, nil for the outline dictionary (root of the tree).
+func (n *PdfOutlineTreeNode) GetOutlineItem() *PdfOutlineItem {
+	item, _ := n.context.(*PdfOutlineItem)
+	return item
+}
+
 func (this *PdfOutlineTreeNode) GetContainingPdfObject() PdfObject {
 	return this.getOuter().GetContainingPdfObject()
 }
@@ -249,6 +254,10 @@ func (this *PdfOutline) ToPdfObject() PdfObject {
 		dict.Set("Parent", this.Parent.getOuter().GetContainingPdfObject())
 	}
 
+	if this.Count != nil {
+		dict.Set("Count", MakeInteger(*this.Count))
+	}
+
 	return container
 }
 
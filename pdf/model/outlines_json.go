@@ -0,0 +1,193 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfOutlineJSONItem is an outline item in the JSON form of the document outline, for editing the outline in
+// external tools.  The destinations are positions on pages, by index.
+type PdfOutlineJSONItem struct {
+	Title string `json:"title"`
+
+	// Index of the destination page (from 0), -1 if the item has no destination in the document.
+	Page int `json:"page"`
+
+	// Position of the upper left corner of the window on the page, and zoom factor.  Unchanged if not set.
+	Left *float64 `json:"left,omitempty"`
+	Top  *float64 `json:"top,omitempty"`
+	Zoom *float64 `json:"zoom,omitempty"`
+
+	// Whether the children of the item are hidden.
+	Closed bool `json:"closed,omitempty"`
+
+	Items []*PdfOutlineJSONItem `json:"items,omitempty"`
+}
+
+// GetOutlinesJSON returns the outline of the document in JSON form: the list of the top level items, with their
+// children.  The destinations of the items (or of their GoTo actions), including named destinations, are resolved
+// to the index of their page.
+func (this *PdfReader) GetOutlinesJSON() ([]byte, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	items := []*PdfOutlineJSONItem{}
+	if this.outlineTree != nil {
+		visited := map[*PdfOutlineTreeNode]bool{}
+		var err error
+		items, err = this.outlineItemsToJSON(this.outlineTree.First, visited)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(items, "", "  ")
+}
+
+// Converts the items from the node and its next siblings to the JSON form.
+func (this *PdfReader) outlineItemsToJSON(node *PdfOutlineTreeNode,
+	visited map[*PdfOutlineTreeNode]bool) ([]*PdfOutlineJSONItem, error) {
+	items := []*PdfOutlineJSONItem{}
+	for node != nil {
+		if visited[node] {
+			common.Log.Debug("ERROR: Circular reference in outline tree")
+			return nil, errors.New("Circular reference in outline tree")
+		}
+		visited[node] = true
+
+		outlineItem, ok := node.context.(*PdfOutlineItem)
+		if !ok {
+			common.Log.Debug("ERROR: Outline node not an item (%T)", node.context)
+			return nil, ErrTypeError
+		}
+
+		item := &PdfOutlineJSONItem{Page: -1}
+		if outlineItem.Title != nil {
+			item.Title = outlineItem.Title.Decoded()
+		}
+		if outlineItem.Count != nil && *outlineItem.Count < 0 {
+			item.Closed = true
+		}
+
+		dest := outlineItem.Dest
+		if action, ok := TraceToDirectObject(outlineItem.A).(*PdfObjectDictionary); ok && dest == nil {
+			if s, ok := action.Get("S").(*PdfObjectName); ok && *s == "GoTo" {
+				dest = action.Get("D")
+			}
+		}
+		if dest != nil {
+			err := this.setOutlineJSONDest(item, dest)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		children, err := this.outlineItemsToJSON(node.First, visited)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) > 0 {
+			item.Items = children
+		}
+		items = append(items, item)
+		node = outlineItem.Next
+	}
+	return items, nil
+}
+
+// Sets the page and the position of the destination on the JSON item, with named destinations resolved.
+func (this *PdfReader) setOutlineJSONDest(item *PdfOutlineJSONItem, dest PdfObject) error {
+	d, err := this.ResolveDestination(dest)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		common.Log.Debug("Outline item %q destination not resolved (%T)", item.Title, dest)
+		return nil
+	}
+	item.Page = this.GetPageNumber(d.Page) - 1
+
+	if d.Fit == "XYZ" {
+		item.Left, item.Top, item.Zoom = d.Params[0], d.Params[1], d.Params[2]
+	}
+	return nil
+}
+
+// NewOutlineTreeFromJSON creates an outline tree from its JSON form, with the destinations on the pages of the
+// document by index.  The tree can be added to a document with PdfWriter.AddOutlineTree.
+func NewOutlineTreeFromJSON(data []byte, pages []*PdfPage) (*PdfOutline, error) {
+	items := []*PdfOutlineJSONItem{}
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := NewPdfOutlineTree()
+	count, err := addOutlineJSONItems(&tree.PdfOutlineTreeNode, items, pages)
+	if err != nil {
+		return nil, err
+	}
+	tree.Count = &count
+	return tree, nil
+}
+
+// Appends the JSON items as children of the parent, and returns the number of the items visible when the parent
+// is open.
+func addOutlineJSONItems(parent *PdfOutlineTreeNode, items []*PdfOutlineJSONItem, pages []*PdfPage) (int64, error) {
+	visible := int64(0)
+	var prev *PdfOutlineItem
+	for _, item := range items {
+		outlineItem := NewPdfOutlineItem()
+		outlineItem.Title = MakeTextString(item.Title)
+
+		if item.Page >= len(pages) {
+			common.Log.Debug("ERROR: Outline item %q page %d out of range", item.Title, item.Page)
+			return 0, ErrRangeError
+		}
+		if item.Page >= 0 {
+			dest := MakeArray(pages[item.Page].GetPageAsIndirectObject(), MakeName("XYZ"))
+			for _, value := range []*float64{item.Left, item.Top, item.Zoom} {
+				if value != nil {
+					dest.Append(MakeFloat(*value))
+				} else {
+					dest.Append(MakeNull())
+				}
+			}
+			outlineItem.Dest = dest
+		}
+
+		outlineItem.Parent = parent
+		if prev != nil {
+			prev.Next = &outlineItem.PdfOutlineTreeNode
+			outlineItem.Prev = &prev.PdfOutlineTreeNode
+		} else {
+			parent.First = &outlineItem.PdfOutlineTreeNode
+		}
+		parent.Last = &outlineItem.PdfOutlineTreeNode
+		prev = outlineItem
+
+		children, err := addOutlineJSONItems(&outlineItem.PdfOutlineTreeNode, item.Items, pages)
+		if err != nil {
+			return 0, err
+		}
+		visible++
+		if children > 0 {
+			count := children
+			if item.Closed {
+				count = -children
+			} else {
+				visible += children
+			}
+			outlineItem.Count = &count
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,79 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOutlinesJSON(t *testing.T) {
+	input := `[
+  {"title": "Introduction", "page": 0, "left": 0, "top": 100, "zoom": 1.5},
+  {"title": "Détails", "page": 1, "closed": true, "items": [
+    {"title": "First", "page": 1, "top": 50},
+    {"title": "Second", "page": 2, "items": [{"title": "Deep", "page": -1}]}
+  ]}
+]`
+
+	w := NewPdfWriter()
+	pages := []*PdfPage{}
+	for i := 0; i < 3; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 100}
+		page.Resources = NewPdfPageResources()
+		err := w.AddPage(page)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		pages = append(pages, page)
+	}
+
+	tree, err := NewOutlineTreeFromJSON([]byte(input), pages)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// Both top level items, the closed item's children are hidden.
+	if tree.Count == nil || *tree.Count != 2 {
+		t.Errorf("Wrong outline count %v", tree.Count)
+	}
+	w.AddOutlineTree(&tree.PdfOutlineTreeNode)
+
+	if _, err := NewOutlineTreeFromJSON([]byte(`[{"title": "Out", "page": 3}]`), pages); err == nil {
+		t.Errorf("Page out of range not detected")
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	output, err := reader.GetOutlinesJSON()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The same outline, back from the document.
+	var expected, items []*PdfOutlineJSONItem
+	err = json.Unmarshal([]byte(input), &expected)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = json.Unmarshal(output, &items)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Wrong outline JSON %s", output)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Output intent subtypes.
+const (
+	OutputIntentPDFA1 = "GTS_PDFA1" // PDF/A (all parts).
+	OutputIntentPDFX  = "GTS_PDFX"  // PDF/X.
+)
+
+// PdfOutputIntent is an output intent of the document (section 14.11.5 of the PDF specification), describing the
+// color characteristics of the output device the document is intended for.
+type PdfOutputIntent struct {
+	// Subtype of the output intent (S), e.g. OutputIntentPDFA1.
+	S string
+	// Identifier of the intended output condition, e.g. "sRGB IEC61966-2.1".
+	OutputConditionIdentifier string
+	// Human readable description of the output condition (optional).
+	OutputCondition string
+	// Registry of the output condition identifier, e.g. "http://www.color.org" (optional).
+	RegistryName string
+	// Additional information on the output condition (optional).
+	Info string
+	// ICC profile of the output condition (DestOutputProfile), nil if not embedded.
+	DestOutputProfile []byte
+	// Number of color components of the ICC profile: 1 (gray), 3 (RGB) or 4 (CMYK).
+	N int
+}
+
+// GetOutputIntents returns the output intents of the document (OutputIntents entry of the catalog), with their ICC
+// profiles decoded.  Empty if the document has none.
+func (this *PdfReader) GetOutputIntents() ([]*PdfOutputIntent, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	intents := []*PdfOutputIntent{}
+	arr, ok := traceDirect(this, this.catalog.Get("OutputIntents")).(*PdfObjectArray)
+	if !ok {
+		return intents, nil
+	}
+	for _, obj := range *arr {
+		dict, ok := traceDirect(this, obj).(*PdfObjectDictionary)
+		if !ok {
+			common.Log.Debug("Output intent not a dictionary (%T)", obj)
+			continue
+		}
+		intent := &PdfOutputIntent{}
+		if name, ok := traceDirect(this, dict.Get("S")).(*PdfObjectName); ok {
+			intent.S = string(*name)
+		}
+		for key, field := range map[PdfObjectName]*string{
+			"OutputConditionIdentifier": &intent.OutputConditionIdentifier,
+			"OutputCondition":           &intent.OutputCondition,
+			"RegistryName":              &intent.RegistryName,
+			"Info":                      &intent.Info,
+		} {
+			if str, ok := GetTextString(traceDirect(this, dict.Get(key))); ok {
+				*field = str
+			}
+		}
+		if stream, ok := traceDirect(this, dict.Get("DestOutputProfile")).(*PdfObjectStream); ok {
+			data, err := DecodeStream(stream)
+			if err != nil {
+				common.Log.Debug("ERROR: Unable to decode the output profile: %v", err)
+				return nil, err
+			}
+			intent.DestOutputProfile = data
+			if n, ok := traceDirect(this, stream.PdfObjectDictionary.Get("N")).(*PdfObjectInteger); ok {
+				intent.N = int(*n)
+			}
+		}
+		intents = append(intents, intent)
+	}
+	return intents, nil
+}
+
+// AddOutputIntent adds an output intent to the document, e.g. the PDF/A output intent with the ICC profile of the
+// colors of the document.
+func (this *PdfWriter) AddOutputIntent(intent *PdfOutputIntent) error {
+	if intent.S == "" || intent.OutputConditionIdentifier == "" {
+		common.Log.Debug("ERROR: Output intent without subtype or output condition identifier")
+		return ErrRequiredAttributeMissing
+	}
+
+	dict := MakeDict()
+	dict.Set("Type", MakeName("OutputIntent"))
+	dict.Set("S", MakeName(intent.S))
+	dict.Set("OutputConditionIdentifier", MakeTextString(intent.OutputConditionIdentifier))
+	if intent.OutputCondition != "" {
+		dict.Set("OutputCondition", MakeTextString(intent.OutputCondition))
+	}
+	if intent.RegistryName != "" {
+		dict.Set("RegistryName", MakeTextString(intent.RegistryName))
+	}
+	if intent.Info != "" {
+		dict.Set("Info", MakeTextString(intent.Info))
+	}
+	if intent.DestOutputProfile != nil {
+		if intent.N != 1 && intent.N != 3 && intent.N != 4 {
+			common.Log.Debug("ERROR: Invalid number of components of the output profile: %d", intent.N)
+			return ErrRangeError
+		}
+		stream, err := MakeStream(intent.DestOutputProfile, NewFlateEncoder())
+		if err != nil {
+			return err
+		}
+		stream.PdfObjectDictionary.Set("N", MakeInteger(int64(intent.N)))
+		dict.Set("DestOutputProfile", stream)
+	}
+
+	arr, ok := this.catalog.Get("OutputIntents").(*PdfObjectArray)
+	if !ok {
+		arr = &PdfObjectArray{}
+		this.catalog.Set("OutputIntents", arr)
+	}
+	arr.Append(dict)
+	return this.addObjects(dict)
+}
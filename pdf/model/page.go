@@ -97,7 +97,8 @@ func (reader *PdfReader) newPdfPageFromDict(p *PdfObjectDictionary) (*PdfPage, e
 	if !ok {
 		return nil, errors.New("Missing/Invalid Page dictionary Type")
 	}
-	if *pType != "Page" {
+	// Template pages (not in the page tree) can have the type Template.
+	if *pType != "Page" && *pType != "Template" {
 		return nil, errors.New("Page dictionary Type != Page")
 	}
 
@@ -270,6 +271,15 @@ func (reader *PdfReader) newPdfPageFromDict(p *PdfObjectDictionary) (*PdfPage, e
 		page.Metadata = obj
 	}
 	if obj := d.Get("PieceInfo"); obj != nil {
+		// Resolved to access the private data of the applications.
+		obj, err := reader.traceToObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		err = reader.traverseObjectData(obj)
+		if err != nil {
+			return nil, err
+		}
 		page.PieceInfo = obj
 	}
 	if obj := d.Get("StructParents"); obj != nil {
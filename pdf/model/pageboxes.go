@@ -0,0 +1,180 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Page boundaries (14.11.2 Page Boundaries): the media box is the extent of the physical medium, the crop box the
+// region of the page displayed or printed, the bleed box the region to which the content is clipped in production,
+// the trim box the dimensions of the finished page after trimming, and the art box the extent of the meaningful
+// content.  The crop box defaults to the media box and the other boxes to the crop box, and all are intersected
+// with the media box.
+
+// Tolerance of the comparisons of the coordinates of page boxes.
+const pageBoxTolerance = 1e-6
+
+// GetCropBox returns the crop box of the page, of the page or inherited from the page tree, intersected with the
+// media box.  It is the media box if not set.
+func (this *PdfPage) GetCropBox() (*PdfRectangle, error) {
+	mediaBox, err := this.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+	mediaBox = normalizeRect(mediaBox)
+
+	cropBox := this.CropBox
+	if cropBox == nil {
+		cropBox, err = this.getInheritedBox("CropBox")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cropBox == nil {
+		return mediaBox, nil
+	}
+	return intersectPageBox(cropBox, mediaBox), nil
+}
+
+// GetBleedBox returns the bleed box of the page intersected with the media box, the crop box if not set.
+func (this *PdfPage) GetBleedBox() (*PdfRectangle, error) {
+	return this.getBoundaryBox(this.BleedBox)
+}
+
+// GetTrimBox returns the trim box of the page intersected with the media box, the crop box if not set.
+func (this *PdfPage) GetTrimBox() (*PdfRectangle, error) {
+	return this.getBoundaryBox(this.TrimBox)
+}
+
+// GetArtBox returns the art box of the page intersected with the media box, the crop box if not set.
+func (this *PdfPage) GetArtBox() (*PdfRectangle, error) {
+	return this.getBoundaryBox(this.ArtBox)
+}
+
+// Returns the boundary box intersected with the media box, or the crop box if nil.
+func (this *PdfPage) getBoundaryBox(box *PdfRectangle) (*PdfRectangle, error) {
+	if box == nil {
+		return this.GetCropBox()
+	}
+	mediaBox, err := this.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+	return intersectPageBox(box, normalizeRect(mediaBox)), nil
+}
+
+// Returns the box inherited from the ancestors of the page in the page tree, nil if not set.
+func (this *PdfPage) getInheritedBox(key PdfObjectName) (*PdfRectangle, error) {
+	visited := map[PdfObject]bool{}
+	node := this.Parent
+	for node != nil && !visited[node] {
+		visited[node] = true
+		dict, ok := TraceToDirectObject(node).(*PdfObjectDictionary)
+		if !ok {
+			return nil, errors.New("Invalid parent object")
+		}
+		if obj := dict.Get(key); obj != nil {
+			arr, ok := TraceToDirectObject(obj).(*PdfObjectArray)
+			if !ok {
+				common.Log.Debug("ERROR: Invalid %s (%T)", key, obj)
+				return nil, ErrTypeError
+			}
+			return NewPdfRectangle(*arr)
+		}
+		node = dict.Get("Parent")
+	}
+	return nil, nil
+}
+
+// SetMediaBox sets the media box of the page.  Returns ErrInvalidPageBox if the rectangle is empty.
+func (this *PdfPage) SetMediaBox(box PdfRectangle) error {
+	rect := normalizeRect(&box)
+	if rect.Urx-rect.Llx <= pageBoxTolerance || rect.Ury-rect.Lly <= pageBoxTolerance {
+		common.Log.Debug("ERROR: Empty media box %v", box)
+		return ErrInvalidPageBox
+	}
+	this.MediaBox = rect
+	return nil
+}
+
+// SetCropBox sets the crop box of the page, nil to remove it.  Returns ErrInvalidPageBox if the rectangle is empty
+// or not within the media box.
+func (this *PdfPage) SetCropBox(box *PdfRectangle) error {
+	return this.setBoundaryBox(&this.CropBox, box, "crop")
+}
+
+// SetBleedBox sets the bleed box of the page, nil to remove it.  Returns ErrInvalidPageBox if the rectangle is empty
+// or not within the media box.
+func (this *PdfPage) SetBleedBox(box *PdfRectangle) error {
+	return this.setBoundaryBox(&this.BleedBox, box, "bleed")
+}
+
+// SetTrimBox sets the trim box of the page, nil to remove it.  Returns ErrInvalidPageBox if the rectangle is empty
+// or not within the media box.
+func (this *PdfPage) SetTrimBox(box *PdfRectangle) error {
+	return this.setBoundaryBox(&this.TrimBox, box, "trim")
+}
+
+// SetArtBox sets the art box of the page, nil to remove it.  Returns ErrInvalidPageBox if the rectangle is empty or
+// not within the media box.
+func (this *PdfPage) SetArtBox(box *PdfRectangle) error {
+	return this.setBoundaryBox(&this.ArtBox, box, "art")
+}
+
+// Sets the field of a boundary box to the box normalized, after checking that it is within the media box.
+func (this *PdfPage) setBoundaryBox(field **PdfRectangle, box *PdfRectangle, name string) error {
+	if box == nil {
+		*field = nil
+		return nil
+	}
+	rect := normalizeRect(box)
+	if rect.Urx-rect.Llx <= pageBoxTolerance || rect.Ury-rect.Lly <= pageBoxTolerance {
+		common.Log.Debug("ERROR: Empty %s box %v", name, *box)
+		return ErrInvalidPageBox
+	}
+	mediaBox, err := this.GetMediaBox()
+	if err != nil {
+		return err
+	}
+	mediaBox = normalizeRect(mediaBox)
+	if rect.Llx < mediaBox.Llx-pageBoxTolerance || rect.Lly < mediaBox.Lly-pageBoxTolerance ||
+		rect.Urx > mediaBox.Urx+pageBoxTolerance || rect.Ury > mediaBox.Ury+pageBoxTolerance {
+		common.Log.Debug("ERROR: The %s box %v is not within the media box %v", name, *rect, *mediaBox)
+		return ErrInvalidPageBox
+	}
+	*field = rect
+	return nil
+}
+
+// Returns the rectangle with the lower left and upper right corners, as rectangles can be specified by any two
+// diagonally opposite corners (7.9.5 Rectangles).
+func normalizeRect(r *PdfRectangle) *PdfRectangle {
+	return &PdfRectangle{
+		Llx: math.Min(r.Llx, r.Urx),
+		Lly: math.Min(r.Lly, r.Ury),
+		Urx: math.Max(r.Llx, r.Urx),
+		Ury: math.Max(r.Lly, r.Ury),
+	}
+}
+
+// Returns the box intersected with the (normalized) media box, the media box if they do not intersect.
+func intersectPageBox(box, mediaBox *PdfRectangle) *PdfRectangle {
+	rect := normalizeRect(box)
+	rect.Llx = math.Max(rect.Llx, mediaBox.Llx)
+	rect.Lly = math.Max(rect.Lly, mediaBox.Lly)
+	rect.Urx = math.Min(rect.Urx, mediaBox.Urx)
+	rect.Ury = math.Min(rect.Ury, mediaBox.Ury)
+	if rect.Urx <= rect.Llx || rect.Ury <= rect.Lly {
+		common.Log.Debug("Page box %v outside of the media box %v", *box, *mediaBox)
+		return mediaBox
+	}
+	return rect
+}
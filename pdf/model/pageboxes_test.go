@@ -0,0 +1,86 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestPageBoxes(t *testing.T) {
+	// Media and crop boxes inherited from the page tree, the crop box partly outside of the media box.
+	parent := core.MakeDict()
+	parent.Set("MediaBox", core.MakeArrayFromFloats([]float64{0, 0, 612, 792}))
+	parent.Set("CropBox", core.MakeArrayFromFloats([]float64{-10, 10, 600, 780}))
+	page := NewPdfPage()
+	page.Parent = &core.PdfIndirectObject{PdfObject: parent}
+
+	cropBox, err := page.GetCropBox()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if *cropBox != (PdfRectangle{Llx: 0, Lly: 10, Urx: 600, Ury: 780}) {
+		t.Errorf("Wrong crop box %v", *cropBox)
+	}
+	for _, get := range []func() (*PdfRectangle, error){page.GetBleedBox, page.GetTrimBox, page.GetArtBox} {
+		box, err := get()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if *box != *cropBox {
+			t.Errorf("Box %v not defaulting to the crop box", *box)
+		}
+	}
+
+	// Specified by the upper left and lower right corners.
+	err = page.SetTrimBox(&PdfRectangle{Llx: 20, Lly: 700, Urx: 500, Ury: 30})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	trimBox, err := page.GetTrimBox()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if *trimBox != (PdfRectangle{Llx: 20, Lly: 30, Urx: 500, Ury: 700}) {
+		t.Errorf("Wrong trim box %v", *trimBox)
+	}
+
+	// Outside of the media box or empty.
+	if err := page.SetCropBox(&PdfRectangle{Llx: 0, Lly: 0, Urx: 700, Ury: 792}); err != ErrInvalidPageBox {
+		t.Errorf("Crop box outside of the media box: %v", err)
+	}
+	if err := page.SetBleedBox(&PdfRectangle{Llx: 10, Lly: 10, Urx: 10, Ury: 100}); err != ErrInvalidPageBox {
+		t.Errorf("Empty bleed box: %v", err)
+	}
+	if err := page.SetMediaBox(PdfRectangle{}); err != ErrInvalidPageBox {
+		t.Errorf("Empty media box: %v", err)
+	}
+	if page.CropBox != nil || page.BleedBox != nil || page.MediaBox != nil {
+		t.Errorf("Invalid boxes set")
+	}
+
+	// The media box set on the page takes precedence over the page tree.
+	err = page.SetMediaBox(PdfRectangle{Llx: 0, Lly: 0, Urx: 400, Ury: 400})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	cropBox, err = page.GetCropBox()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if *cropBox != (PdfRectangle{Llx: 0, Lly: 10, Urx: 400, Ury: 400}) {
+		t.Errorf("Wrong crop box %v", *cropBox)
+	}
+	err = page.SetArtBox(&PdfRectangle{Llx: 50, Lly: 50, Urx: 350, Ury: 350})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = page.SetArtBox(nil)
+	if err != nil || page.ArtBox != nil {
+		t.Errorf("Art box not removed")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// ToXObjectForm converts the page to a Form XObject, with the page content and resources, and the visible region of
+// the page (crop box or media box) as bounding box.  The form matrix maps the page as displayed, i.e. rotated and
+// scaled by the user unit, to the rectangle from (0, 0) to the displayed size of the page in points, so that drawing
+// the form (Do) places the page with its lower left corner at the origin of the current coordinates, e.g. as a
+// letterhead under the content of another page, or scaled for N-up layouts.  The annotations of the page are not
+// included.
+func (this *PdfPage) ToXObjectForm() (*XObjectForm, error) {
+	bbox := this.CropBox
+	if bbox == nil {
+		var err error
+		bbox, err = this.GetMediaBox()
+		if err != nil {
+			return nil, err
+		}
+	}
+	w, h := bbox.Urx-bbox.Llx, bbox.Ury-bbox.Lly
+	if w <= 0 || h <= 0 {
+		common.Log.Debug("ERROR: Invalid page size %fx%f", w, h)
+		return nil, errors.New("Invalid page size")
+	}
+
+	resources, err := this.getResources()
+	if err != nil {
+		return nil, err
+	}
+	content, err := this.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+
+	// The page rotated clockwise, from the lower left corner of the box, as a, b, c, d, e, f of the matrix
+	// [a b 0 c d 0 e f 1], then translated by the lower left corner and scaled by the user unit.
+	m := [6]float64{1, 0, 0, 1, 0, 0}
+	rotate := int64(0)
+	if this.Rotate != nil {
+		rotate = (*this.Rotate%360 + 360) % 360
+	}
+	switch rotate {
+	case 90:
+		m = [6]float64{0, -1, 1, 0, 0, w}
+	case 180:
+		m = [6]float64{-1, 0, 0, -1, w, h}
+	case 270:
+		m = [6]float64{0, 1, -1, 0, h, 0}
+	}
+	m[4] -= m[0]*bbox.Llx + m[2]*bbox.Lly
+	m[5] -= m[1]*bbox.Llx + m[3]*bbox.Lly
+	unit := this.GetUserUnit()
+	for i := range m {
+		m[i] *= unit
+	}
+
+	xform := NewXObjectForm()
+	xform.BBox = bbox.ToPdfObject()
+	xform.Matrix = MakeArrayFromFloats(m[:])
+	xform.Resources = resources
+	xform.Group = this.Group
+	encoder := NewFlateEncoder()
+	err = xform.SetContentStream([]byte(content), encoder)
+	if err != nil {
+		return nil, err
+	}
+	xform.Filter = encoder
+	return xform, nil
+}
+
+// ImportPageAsXObject converts the page pageNum (starting from 1) of the document to a Form XObject, as by
+// PdfPage.ToXObjectForm, to draw it on pages of other documents.
+func ImportPageAsXObject(reader *PdfReader, pageNum int) (*XObjectForm, error) {
+	page, err := reader.GetPage(pageNum)
+	if err != nil {
+		return nil, err
+	}
+	return page.ToXObjectForm()
+}
@@ -0,0 +1,118 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+func TestImportPageAsXObject(t *testing.T) {
+	w := NewPdfWriter()
+	rotate := int64(90)
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 300, Ury: 200}
+	page.CropBox = &PdfRectangle{Llx: 10, Lly: 20, Urx: 210, Ury: 120}
+	page.Rotate = &rotate
+	page.Resources = NewPdfPageResources()
+	err := page.AddFont("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = page.SetContentStreams([]string{"BT /F1 10 Tf (Letterhead) Tj ET", "10 20 50 50 re f"}, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err = w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	xform, err := ImportPageAsXObject(reader, 1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	xform.ToPdfObject()
+	content, err := xform.GetContentStream()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The content streams of the page, joined.
+	if !strings.HasPrefix(string(content), "BT /F1 10 Tf (Letterhead) Tj ET 10 20 50 50 re f") {
+		t.Errorf("Wrong form content %q", content)
+	}
+	if _, found := xform.Resources.GetFontByName("F1"); !found {
+		t.Errorf("Missing font resource")
+	}
+	bbox, err := NewPdfRectangle(*xform.BBox.(*PdfObjectArray))
+	if err != nil || *bbox != *page.CropBox {
+		t.Errorf("Wrong bounding box %v", xform.BBox)
+	}
+
+	// The corners of the crop box are mapped to the page as displayed, rotated clockwise: 100x200 points.
+	m, err := xform.Matrix.(*PdfObjectArray).ToFloat64Array()
+	if err != nil || len(m) != 6 {
+		t.Fatalf("Wrong matrix %v", xform.Matrix)
+	}
+	transform := func(x, y float64) [2]float64 {
+		return [2]float64{m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]}
+	}
+	for _, c := range []struct{ user, form [2]float64 }{
+		{[2]float64{10, 120}, [2]float64{100, 200}},
+		{[2]float64{210, 120}, [2]float64{100, 0}},
+		{[2]float64{10, 20}, [2]float64{0, 200}},
+		{[2]float64{210, 20}, [2]float64{0, 0}},
+	} {
+		if p := transform(c.user[0], c.user[1]); p != c.form {
+			t.Errorf("%v mapped to %v, expected %v", c.user, p, c.form)
+		}
+	}
+
+	if _, err = ImportPageAsXObject(reader, 2); err == nil {
+		t.Errorf("Missing error for invalid page number")
+	}
+}
+
+func TestPageToXObjectFormUserUnit(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: -50, Lly: -50, Urx: 50, Ury: 50}
+	page.UserUnit = MakeFloat(2)
+	err := page.SetContentStreams([]string{"0 0 10 10 re f"}, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	xform, err := page.ToXObjectForm()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	m, err := xform.Matrix.(*PdfObjectArray).ToFloat64Array()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := []float64{2, 0, 0, 2, 100, 100}
+	for i := range expected {
+		if m[i] != expected[i] {
+			t.Fatalf("Wrong matrix %v, expected %v", m, expected)
+		}
+	}
+	if xform.Resources != nil {
+		t.Errorf("Unexpected resources %v", xform.Resources)
+	}
+}
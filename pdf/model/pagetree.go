@@ -0,0 +1,171 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// DefaultPageTreeFanout is the maximum number of kids of the nodes of the page trees written by default.
+const DefaultPageTreeFanout = 32
+
+// SetPageTreeFanout sets the maximum number of kids of the nodes of the page tree written, DefaultPageTreeFanout by
+// default.  The pages are grouped under intermediate nodes as needed, see BalancePageTree.  With a fanout of 0, all
+// the pages are kids of the root node.  Not applied if pages were written with FlushPages.
+func (this *PdfWriter) SetPageTreeFanout(fanout int) {
+	this.pageTreeFanout = fanout
+}
+
+// balancePageTree balances the page tree written, if needed.
+func (this *PdfWriter) balancePageTree() error {
+	count, ok := this.pages.PdfObject.(*PdfObjectDictionary).Get("Count").(*PdfObjectInteger)
+	if !ok || this.pageTreeFanout < 2 || int(*count) <= this.pageTreeFanout {
+		return nil
+	}
+	if this.flushedPages > 0 {
+		common.Log.Debug("Page tree not balanced, %d pages already written", this.flushedPages)
+		return nil
+	}
+	nodes, err := BalancePageTree(this.pages, this.pageTreeFanout)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		this.addObject(node)
+	}
+	return nil
+}
+
+// BalancePageTree rebuilds the page tree of the root node as a balanced tree, for faster page access: the pages are
+// kept in order and grouped under intermediate Pages nodes of fanout kids at most, as few levels deep as possible.
+// Nested nodes of the tree are flattened, their inheritable attributes (Resources, MediaBox, CropBox and Rotate) being
+// copied to the pages.  Returns the intermediate nodes created, to be added for writing.  With a fanout lower than 2,
+// all the pages become kids of the root node.
+func BalancePageTree(root *PdfIndirectObject, fanout int) ([]*PdfIndirectObject, error) {
+	rootDict, ok := root.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return nil, errors.New("Invalid page tree root (not a dict)")
+	}
+	pages, err := collectTreePages(root)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []*PdfIndirectObject{}
+	level := pages
+	counts := make([]int64, len(pages))
+	for i := range counts {
+		counts[i] = 1
+	}
+	for fanout >= 2 && len(level) > fanout {
+		next := []*PdfIndirectObject{}
+		nextCounts := []int64{}
+		for start := 0; start < len(level); start += fanout {
+			end := start + fanout
+			if end > len(level) {
+				end = len(level)
+			}
+			node, count := makePageTreeNode(level[start:end], counts[start:end])
+			nodes = append(nodes, node)
+			next = append(next, node)
+			nextCounts = append(nextCounts, count)
+		}
+		level = next
+		counts = nextCounts
+	}
+
+	kids := PdfObjectArray{}
+	for _, kid := range level {
+		kid.PdfObject.(*PdfObjectDictionary).Set("Parent", root)
+		kids = append(kids, kid)
+	}
+	rootDict.Set("Kids", &kids)
+	rootDict.Set("Count", MakeInteger(int64(len(pages))))
+	return nodes, nil
+}
+
+// makePageTreeNode returns an intermediate Pages node with the kids, and the number of pages under it.
+func makePageTreeNode(kids []*PdfIndirectObject, counts []int64) (*PdfIndirectObject, int64) {
+	node := &PdfIndirectObject{}
+	dict := MakeDict()
+	dict.Set("Type", MakeName("Pages"))
+	arr := PdfObjectArray{}
+	total := int64(0)
+	for i, kid := range kids {
+		kid.PdfObject.(*PdfObjectDictionary).Set("Parent", node)
+		arr = append(arr, kid)
+		total += counts[i]
+	}
+	dict.Set("Kids", &arr)
+	dict.Set("Count", MakeInteger(total))
+	node.PdfObject = dict
+	return node, total
+}
+
+// inheritableFields are the page attributes inherited from the nodes of the page tree.
+var inheritableFields = []PdfObjectName{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// treeNode is a node of a page tree to walk, with the attributes its pages inherit.
+type treeNode struct {
+	obj       *PdfIndirectObject
+	inherited map[PdfObjectName]PdfObject
+}
+
+// collectTreePages returns the pages under the node of a page tree in order, with the attributes they inherit copied
+// to them.  The tree is walked without recursion, as pathological trees can be thousands of nodes deep.
+func collectTreePages(root *PdfIndirectObject) ([]*PdfIndirectObject, error) {
+	pages := []*PdfIndirectObject{}
+	visited := map[*PdfIndirectObject]bool{}
+	stack := []treeNode{{obj: root, inherited: map[PdfObjectName]PdfObject{}}}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[node.obj] {
+			common.Log.Debug("Cyclic page tree, node skipped")
+			continue
+		}
+		visited[node.obj] = true
+
+		dict, ok := node.obj.PdfObject.(*PdfObjectDictionary)
+		if !ok {
+			return nil, errors.New("Invalid page tree node (not a dict)")
+		}
+		if name, ok := TraceToDirectObject(dict.Get("Type")).(*PdfObjectName); ok && *name == "Page" {
+			for _, field := range inheritableFields {
+				if dict.Get(field) == nil && node.inherited[field] != nil {
+					dict.Set(field, node.inherited[field])
+				}
+			}
+			pages = append(pages, node.obj)
+			continue
+		}
+
+		kids, ok := TraceToDirectObject(dict.Get("Kids")).(*PdfObjectArray)
+		if !ok {
+			common.Log.Debug("ERROR: Page tree node without Kids skipped")
+			continue
+		}
+		inherited := map[PdfObjectName]PdfObject{}
+		for _, field := range inheritableFields {
+			inherited[field] = node.inherited[field]
+			if obj := dict.Get(field); obj != nil {
+				inherited[field] = obj
+			}
+		}
+		// Pushed in reverse to be popped in order.
+		for i := len(*kids) - 1; i >= 0; i-- {
+			kid, ok := (*kids)[i].(*PdfIndirectObject)
+			if !ok {
+				return nil, errors.New("Invalid page tree kid (not an indirect object)")
+			}
+			stack = append(stack, treeNode{obj: kid, inherited: inherited})
+		}
+	}
+	return pages, nil
+}
@@ -0,0 +1,118 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestBalancePageTree(t *testing.T) {
+	// A pathological tree: each node has a page and the next node as kids, with the media box inherited from the root.
+	root := &PdfIndirectObject{}
+	node := root
+	var parent *PdfIndirectObject
+	pages := []*PdfIndirectObject{}
+	for i := 0; i < 1000; i++ {
+		page := MakeIndirectObject(MakeDict())
+		page.PdfObject.(*PdfObjectDictionary).Set("Type", MakeName("Page"))
+		page.PdfObject.(*PdfObjectDictionary).Set("Parent", node)
+		pages = append(pages, page)
+
+		next := &PdfIndirectObject{}
+		dict := MakeDict()
+		dict.Set("Type", MakeName("Pages"))
+		dict.Set("Kids", MakeArray(page, next))
+		dict.Set("Count", MakeInteger(int64(1000-i)))
+		if parent != nil {
+			dict.Set("Parent", parent)
+		}
+		node.PdfObject = dict
+		parent = node
+		node = next
+	}
+	node.PdfObject = MakeDict()
+	node.PdfObject.(*PdfObjectDictionary).Set("Type", MakeName("Pages"))
+	node.PdfObject.(*PdfObjectDictionary).Set("Kids", MakeArray())
+	node.PdfObject.(*PdfObjectDictionary).Set("Parent", parent)
+	root.PdfObject.(*PdfObjectDictionary).Set("MediaBox", MakeArrayFromFloats([]float64{0, 0, 612, 792}))
+
+	nodes, err := BalancePageTree(root, 10)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// 100 nodes of 10 pages, under 10 nodes of 10 nodes.
+	if len(nodes) != 110 {
+		t.Fatalf("Wrong number of nodes %d", len(nodes))
+	}
+	rootDict := root.PdfObject.(*PdfObjectDictionary)
+	if kids := rootDict.Get("Kids").(*PdfObjectArray); len(*kids) != 10 {
+		t.Fatalf("Wrong root kids %d", len(*kids))
+	}
+	if count := rootDict.Get("Count").(*PdfObjectInteger); *count != 1000 {
+		t.Errorf("Wrong count %d", *count)
+	}
+
+	collected, err := collectTreePages(root)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(collected) != 1000 {
+		t.Fatalf("Wrong number of pages %d", len(collected))
+	}
+	for i, page := range collected {
+		if page != pages[i] {
+			t.Fatalf("Page %d out of order", i)
+		}
+		dict := page.PdfObject.(*PdfObjectDictionary)
+		if dict.Get("MediaBox") == nil {
+			t.Fatalf("Page %d missing the inherited media box", i)
+		}
+		parent := dict.Get("Parent").(*PdfIndirectObject)
+		grandParent := parent.PdfObject.(*PdfObjectDictionary).Get("Parent")
+		if grandParent == nil || grandParent.(*PdfIndirectObject).PdfObject.(*PdfObjectDictionary).Get("Parent") != root {
+			t.Fatalf("Page %d at the wrong depth", i)
+		}
+	}
+}
+
+func TestWriteBalancedPageTree(t *testing.T) {
+	w := NewPdfWriter()
+	w.SetPageTreeFanout(4)
+	for i := 0; i < 10; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: float64(100 + i), Ury: 100}
+		page.Resources = NewPdfPageResources()
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(reader.PageList) != 10 {
+		t.Fatalf("Wrong number of pages %d", len(reader.PageList))
+	}
+	for i, page := range reader.PageList {
+		if page.MediaBox.Urx != float64(100+i) {
+			t.Errorf("Page %d out of order (%v)", i, page.MediaBox)
+		}
+	}
+	pagesDict := traceDirect(reader, reader.catalog.Get("Pages")).(*PdfObjectDictionary)
+	kids := traceDirect(reader, pagesDict.Get("Kids")).(*PdfObjectArray)
+	// 3 nodes of 4, 4 and 2 pages.
+	if len(*kids) != 3 {
+		t.Errorf("Wrong root kids %s", kids)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/xmp"
+)
+
+// ConvertOptions are the options of the conversion to PDF/A.
+type ConvertOptions struct {
+	// Output intent added if the document has no PDF/A output intent with an ICC profile.  Defaults to the sRGB
+	// color space, with a built-in ICC profile.
+	OutputIntent *model.PdfOutputIntent
+}
+
+// Convert returns a writer with the pages of the document loaded by reader, converted to the PDF/A level where
+// feasible, and the violations remaining after the conversion:
+//   - the document is written without encryption (the reader must have been decrypted),
+//   - the XMP metadata is added or updated with the PDF/A identification, and the document information dictionary
+//     is written in sync with it,
+//   - a PDF/A output intent is added if missing,
+//   - the PDF version is set to the version the level is based on.
+//
+// Fonts which are not embedded and, for PDF/A-1, the use of transparency are not converted, and are returned as
+// violations.  Only the pages and the metadata of the document are written, like Sanitize of the redactor package.
+// Opts can be nil for the default options.
+func Convert(reader *model.PdfReader, level Level, opts *ConvertOptions) (*model.PdfWriter, []Violation, error) {
+	if level.Part() == 0 {
+		return nil, nil, errors.New("Unsupported PDF/A level")
+	}
+	if opts == nil {
+		opts = &ConvertOptions{}
+	}
+
+	// Checked before adding the pages to the writer, which can modify them.
+	v := newValidator(reader, level)
+	err := v.checkPages()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w := model.NewPdfWriter()
+	if level == PDFA1B {
+		w.SetVersion(1, 4)
+	} else {
+		w.SetVersion(1, 7)
+	}
+	for _, page := range reader.PageList {
+		err := w.AddPage(page)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = convertMetadata(reader, &w, level)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = convertOutputIntent(reader, &w, level, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &w, v.violations, nil
+}
+
+// convertMetadata writes the XMP metadata of the document with the PDF/A identification, completed with the Info
+// entries missing from it and in sync with Info.
+func convertMetadata(reader *model.PdfReader, w *model.PdfWriter, level Level) error {
+	meta, err := xmp.Load(reader)
+	if err != nil {
+		common.Log.Debug("Invalid XMP metadata replaced: %v", err)
+		meta = xmp.New()
+	}
+	info, err := reader.GetDocInfo()
+	if err != nil {
+		return err
+	}
+	// The Producer and Creator written by default also need XMP equivalents.
+	for key, val := range w.GetDocInfo() {
+		if info[key] == "" {
+			info[key] = val
+		}
+	}
+	missing := map[string]string{}
+	for key, val := range meta.DocInfo() {
+		if val == "" && info[key] != "" {
+			missing[key] = info[key]
+		}
+	}
+	meta.SetDocInfo(missing)
+
+	meta.PDFAID = xmp.PDFAIdentification{Part: level.Part(), Conformance: level.Conformance()}
+	return xmp.Write(w, meta)
+}
+
+// convertOutputIntent adds the PDF/A output intent of the document, or of the options if none.
+func convertOutputIntent(reader *model.PdfReader, w *model.PdfWriter, level Level, opts *ConvertOptions) error {
+	intents, err := reader.GetOutputIntents()
+	if err != nil {
+		return err
+	}
+	for _, intent := range intents {
+		if intent.S != model.OutputIntentPDFA1 || intent.DestOutputProfile == nil {
+			continue
+		}
+		v := newValidator(reader, level)
+		v.checkProfile(intent)
+		if len(v.violations) == 0 {
+			return w.AddOutputIntent(intent)
+		}
+		common.Log.Debug("Output intent %s not kept: %v", intent.OutputConditionIdentifier, v.violations)
+	}
+
+	intent := model.PdfOutputIntent{
+		OutputConditionIdentifier: srgbIdentifier,
+		RegistryName:              srgbRegistry,
+		Info:                      srgbIdentifier,
+		DestOutputProfile:         srgbProfile,
+		N:                         3,
+	}
+	if opts.OutputIntent != nil {
+		intent = *opts.OutputIntent
+	}
+	intent.S = model.OutputIntentPDFA1
+	return w.AddOutputIntent(&intent)
+}
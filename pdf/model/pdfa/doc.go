@@ -0,0 +1,22 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package pdfa validates documents against the PDF/A-1b and PDF/A-2b archiving standards (ISO 19005), and converts
+// documents to them where feasible.
+//
+// Example: converting a document to PDF/A-2b.
+//
+//	w, violations, err := pdfa.Convert(pdfReader, pdfa.PDFA2B, nil)
+//	...
+//	for _, v := range violations {
+//		// Not converted, e.g. fonts not embedded.
+//		fmt.Println(v)
+//	}
+//	err = w.Write(outputFile)
+//
+// Validate reports the violations of a document:
+//
+//	violations, err := pdfa.Validate(pdfReader, pdfa.PDFA1B)
+package pdfa
@@ -0,0 +1,102 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// The output condition of the sRGB output intent.
+const (
+	srgbIdentifier = "sRGB IEC61966-2.1"
+	srgbRegistry   = "http://www.color.org"
+)
+
+// srgbProfile is an ICC profile (version 2.1, as allowed by PDF/A-1) of the sRGB color space.
+var srgbProfile = makeSRGBProfile()
+
+// makeSRGBProfile builds a matrix/TRC display profile of the sRGB color space, with the primaries adapted to the
+// D50 illuminant of the profile connection space.
+func makeSRGBProfile() []byte {
+	s15Fixed16 := func(vals ...float64) []byte {
+		b := make([]byte, 4*len(vals))
+		for i, v := range vals {
+			binary.BigEndian.PutUint32(b[4*i:], uint32(int32(math.Floor(v*65536+0.5))))
+		}
+		return b
+	}
+	xyz := func(x, y, z float64) []byte {
+		return append([]byte("XYZ \x00\x00\x00\x00"), s15Fixed16(x, y, z)...)
+	}
+
+	desc := []byte("desc\x00\x00\x00\x00")
+	desc = append(desc, 0, 0, 0, byte(len(srgbIdentifier)+1))
+	desc = append(desc, srgbIdentifier+"\x00"...)
+	desc = append(desc, make([]byte, 4+4+2+1+67)...) // No Unicode and ScriptCode descriptions.
+
+	curve := []byte("curv\x00\x00\x00\x00")
+	const points = 1024
+	curve = append(curve, 0, 0, points>>8, points&0xff)
+	for i := 0; i < points; i++ {
+		v := float64(i) / (points - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		curve = append(curve, byte(uint16(v*65535+0.5)>>8), byte(uint16(v*65535+0.5)))
+	}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc},
+		{"cprt", []byte("text\x00\x00\x00\x00No copyright, use freely\x00")},
+		{"wtpt", xyz(0.9642, 1.0, 0.8249)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	// The tag data follows the tag table, 4-byte aligned, the curve being shared by the TRC tags.
+	var table, data bytes.Buffer
+	offset := 128 + 4 + 12*len(tags)
+	offsets := map[*byte]int{}
+	for _, tag := range tags {
+		start, shared := offsets[&tag.data[0]]
+		if !shared {
+			start = offset + data.Len()
+			offsets[&tag.data[0]] = start
+			data.Write(tag.data)
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+		}
+		table.WriteString(tag.sig)
+		binary.Write(&table, binary.BigEndian, uint32(start))
+		binary.Write(&table, binary.BigEndian, uint32(len(tag.data)))
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(offset+data.Len()))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000) // Version 2.1.
+	copy(header[12:], "mntrRGB XYZ ")
+	for i, v := range []uint16{2017, 1, 1, 0, 0, 0} {
+		binary.BigEndian.PutUint16(header[24+2*i:], v)
+	}
+	copy(header[36:], "acsp")
+	copy(header[68:], s15Fixed16(0.9642, 1.0, 0.8249))
+
+	profile := append(header, 0, 0, 0, byte(len(tags)))
+	profile = append(profile, table.Bytes()...)
+	return append(profile, data.Bytes()...)
+}
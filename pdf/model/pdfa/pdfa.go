@@ -0,0 +1,419 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/xmp"
+)
+
+// Level is a conformance level of the PDF/A standard.
+type Level int
+
+// PDF/A conformance levels supported.
+const (
+	PDFA1B Level = iota + 1 // PDF/A-1b (ISO 19005-1, level B: visual appearance).
+	PDFA2B                  // PDF/A-2b (ISO 19005-2, level B).
+)
+
+// Part returns the part of the PDF/A standard of the level.
+func (level Level) Part() int {
+	switch level {
+	case PDFA1B:
+		return 1
+	case PDFA2B:
+		return 2
+	}
+	return 0
+}
+
+// Conformance returns the conformance of the level within its part, as identified in the XMP metadata.
+func (level Level) Conformance() string {
+	return "B"
+}
+
+func (level Level) String() string {
+	return fmt.Sprintf("PDF/A-%db", level.Part())
+}
+
+// Rule is a group of requirements of the PDF/A standard.
+type Rule string
+
+// Rules checked.
+const (
+	RuleEncryption     Rule = "Encryption"     // The document shall not be encrypted.
+	RuleMetadata       Rule = "Metadata"       // The XMP metadata shall be present and in sync with Info.
+	RuleIdentification Rule = "Identification" // The XMP metadata shall identify the PDF/A level.
+	RuleOutputIntent   Rule = "OutputIntent"   // A PDF/A output intent shall have an ICC profile.
+	RuleFontEmbedding  Rule = "FontEmbedding"  // The fonts shall be embedded.
+	RuleTransparency   Rule = "Transparency"   // PDF/A-1 does not allow transparency.
+)
+
+// Violation is a requirement of the PDF/A standard not met by a document.
+type Violation struct {
+	Rule Rule
+	// Number of the page where the violation was found, 0 for the document level rules.
+	Page        int
+	Description string
+}
+
+func (v Violation) String() string {
+	if v.Page > 0 {
+		return fmt.Sprintf("%s: page %d: %s", v.Rule, v.Page, v.Description)
+	}
+	return fmt.Sprintf("%s: %s", v.Rule, v.Description)
+}
+
+// Validate checks the document loaded by reader against the requirements of the PDF/A level, and returns the
+// violations found, empty if none.  Encrypted documents must be decrypted first.
+//
+// The checks cover the main requirements of the level B: no encryption, XMP metadata with PDF/A identification and
+// in sync with the document information dictionary, PDF/A output intent with an ICC profile, embedded fonts and,
+// for PDF/A-1, no transparency.  They are not exhaustive: a document without violations may still not conform.
+func Validate(reader *model.PdfReader, level Level) ([]Violation, error) {
+	if level.Part() == 0 {
+		return nil, errors.New("Unsupported PDF/A level")
+	}
+	v := newValidator(reader, level)
+
+	trailer, err := reader.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+	if trailer.Get("Encrypt") != nil {
+		v.add(RuleEncryption, 0, "The document is encrypted")
+	}
+
+	err = v.checkMetadata()
+	if err != nil {
+		return nil, err
+	}
+	err = v.checkOutputIntents()
+	if err != nil {
+		return nil, err
+	}
+	err = v.checkPages()
+	if err != nil {
+		return nil, err
+	}
+	return v.violations, nil
+}
+
+// validator checks documents against PDF/A requirements.
+type validator struct {
+	reader     *model.PdfReader
+	level      Level
+	violations []Violation
+
+	// The fonts, graphics states and XObjects already checked, which can be shared by pages.
+	checked map[core.PdfObject]bool
+}
+
+func newValidator(reader *model.PdfReader, level Level) *validator {
+	return &validator{reader: reader, level: level, checked: map[core.PdfObject]bool{}}
+}
+
+// add adds a violation of the rule.
+func (v *validator) add(rule Rule, page int, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Rule: rule, Page: page, Description: fmt.Sprintf(format, args...)})
+}
+
+// checkMetadata checks the PDF/A identification of the XMP metadata, and its equivalence with the Info entries.
+func (v *validator) checkMetadata() error {
+	data, err := v.reader.GetMetadata()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		v.add(RuleMetadata, 0, "The document has no XMP metadata")
+		v.add(RuleIdentification, 0, "The document is not identified as %s", v.level)
+		return nil
+	}
+	meta, err := xmp.Parse(data)
+	if err != nil {
+		v.add(RuleMetadata, 0, "Invalid XMP metadata: %v", err)
+		return nil
+	}
+	if meta.PDFAID.Part != v.level.Part() || meta.PDFAID.Conformance != v.level.Conformance() {
+		v.add(RuleIdentification, 0, "The document is identified as part %d conformance %q, not %s",
+			meta.PDFAID.Part, meta.PDFAID.Conformance, v.level)
+	}
+
+	info, err := v.reader.GetDocInfo()
+	if err != nil {
+		return err
+	}
+	equivalents := meta.DocInfo()
+	for _, key := range []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer", "CreationDate",
+		"ModDate"} {
+		if info[key] != "" && !equivalentInfo(key, info[key], equivalents[key]) {
+			v.add(RuleMetadata, 0, "Info %s %q not equivalent to the XMP metadata (%q)", key, info[key],
+				equivalents[key])
+		}
+	}
+	return nil
+}
+
+// equivalentInfo returns true if the value of the Info entry with the key is equivalent to the value derived from
+// the XMP metadata: the same text, or the same time for dates.
+func equivalentInfo(key, info, meta string) bool {
+	if key != "CreationDate" && key != "ModDate" {
+		return info == meta
+	}
+	infoDate, err := model.NewPdfDate(info)
+	if err != nil {
+		return false
+	}
+	metaDate, err := model.NewPdfDate(meta)
+	if err != nil {
+		return false
+	}
+	return infoDate.ToGoTime().Equal(metaDate.ToGoTime())
+}
+
+// checkOutputIntents checks the presence and ICC profiles of the PDF/A output intents.
+func (v *validator) checkOutputIntents() error {
+	intents, err := v.reader.GetOutputIntents()
+	if err != nil {
+		return err
+	}
+	var profile []byte
+	for _, intent := range intents {
+		if intent.S != model.OutputIntentPDFA1 || intent.DestOutputProfile == nil {
+			continue
+		}
+		if profile != nil {
+			// PDF/A-1 allows a single PDF/A output intent, PDF/A-2 several with the same profile.
+			if v.level == PDFA1B || !bytes.Equal(profile, intent.DestOutputProfile) {
+				v.add(RuleOutputIntent, 0, "Several PDF/A output intents with different profiles")
+			}
+			continue
+		}
+		profile = intent.DestOutputProfile
+		v.checkProfile(intent)
+	}
+	if profile == nil {
+		v.add(RuleOutputIntent, 0, "No PDF/A output intent with an ICC profile")
+	}
+	return nil
+}
+
+// checkProfile checks the ICC profile of an output intent: a valid header, a version allowed by the level, and a
+// color space with the number of components of the output intent.
+func (v *validator) checkProfile(intent *model.PdfOutputIntent) {
+	profile := intent.DestOutputProfile
+	if len(profile) < 128 || string(profile[36:40]) != "acsp" {
+		v.add(RuleOutputIntent, 0, "Invalid ICC profile of the output intent")
+		return
+	}
+	if v.level == PDFA1B && profile[8] > 2 {
+		v.add(RuleOutputIntent, 0, "ICC profile version %d not allowed by %s", profile[8], v.level)
+	}
+	components := map[string]int{"GRAY": 1, "RGB ": 3, "CMYK": 4}[string(profile[16:20])]
+	if components == 0 || components != intent.N {
+		v.add(RuleOutputIntent, 0, "ICC profile color space %q with %d components", profile[16:20], intent.N)
+	}
+}
+
+// checkPages checks the fonts and transparency of the pages.
+func (v *validator) checkPages() error {
+	for i, page := range v.reader.PageList {
+		err := v.checkPage(i+1, page)
+		if err != nil {
+			common.Log.Debug("Unable to check page %d: %v", i+1, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPage checks the resources of a page and the appearances of its annotations.
+func (v *validator) checkPage(num int, page *model.PdfPage) error {
+	if v.level == PDFA1B && isTransparencyGroup(page.Group) {
+		v.add(RuleTransparency, num, "Page transparency group")
+	}
+	if page.Resources != nil {
+		err := v.checkResources(num, page.Resources.ToPdfObject())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, annot := range page.Annotations {
+		ap, ok := core.TraceToDirectObject(annot.AP).(*core.PdfObjectDictionary)
+		if !ok {
+			continue
+		}
+		for _, key := range ap.Keys() {
+			appearances := []core.PdfObject{ap.Get(key)}
+			if states, ok := core.TraceToDirectObject(ap.Get(key)).(*core.PdfObjectDictionary); ok {
+				appearances = nil
+				for _, state := range states.Keys() {
+					appearances = append(appearances, states.Get(state))
+				}
+			}
+			for _, obj := range appearances {
+				if stream, ok := core.TraceToDirectObject(obj).(*core.PdfObjectStream); ok {
+					err := v.checkForm(num, stream)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkResources checks the fonts, graphics states and XObjects of a resource dictionary.
+func (v *validator) checkResources(num int, resources core.PdfObject) error {
+	dict, ok := core.TraceToDirectObject(resources).(*core.PdfObjectDictionary)
+	if !ok {
+		return nil
+	}
+
+	for _, font := range v.uncheckedEntries(dict.Get("Font")) {
+		v.checkFont(num, font)
+	}
+	if v.level == PDFA1B {
+		for _, gs := range v.uncheckedEntries(dict.Get("ExtGState")) {
+			v.checkExtGState(num, gs)
+		}
+	}
+	for _, obj := range v.uncheckedEntries(dict.Get("XObject")) {
+		stream, ok := obj.(*core.PdfObjectStream)
+		if !ok {
+			continue
+		}
+		subtype, _ := core.TraceToDirectObject(stream.PdfObjectDictionary.Get("Subtype")).(*core.PdfObjectName)
+		if subtype == nil {
+			continue
+		}
+		switch *subtype {
+		case "Image":
+			if v.level == PDFA1B && stream.PdfObjectDictionary.Get("SMask") != nil {
+				v.add(RuleTransparency, num, "Image with a soft mask")
+			}
+		case "Form":
+			err := v.checkForm(num, stream)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// uncheckedEntries returns the values of a resource category dictionary not checked yet, marking them checked.
+func (v *validator) uncheckedEntries(obj core.PdfObject) []core.PdfObject {
+	dict, ok := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary)
+	if !ok {
+		return nil
+	}
+	entries := []core.PdfObject{}
+	for _, key := range dict.Keys() {
+		entry := core.TraceToDirectObject(dict.Get(key))
+		if _, isNull := entry.(*core.PdfObjectNull); entry == nil || isNull || v.checked[entry] {
+			continue
+		}
+		v.checked[entry] = true
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// checkForm checks the transparency group and the resources of a Form XObject.
+func (v *validator) checkForm(num int, stream *core.PdfObjectStream) error {
+	v.checked[stream] = true
+	if v.level == PDFA1B && isTransparencyGroup(stream.PdfObjectDictionary.Get("Group")) {
+		v.add(RuleTransparency, num, "Form XObject transparency group")
+	}
+	return v.checkResources(num, stream.PdfObjectDictionary.Get("Resources"))
+}
+
+// checkFont checks that a font program is embedded in the font, except for Type 3 fonts which are defined by
+// content streams.
+func (v *validator) checkFont(num int, obj core.PdfObject) {
+	dict, ok := obj.(*core.PdfObjectDictionary)
+	if !ok {
+		return
+	}
+	name := "(unnamed)"
+	if baseFont, ok := core.TraceToDirectObject(dict.Get("BaseFont")).(*core.PdfObjectName); ok {
+		name = string(*baseFont)
+	}
+	if subtype, ok := core.TraceToDirectObject(dict.Get("Subtype")).(*core.PdfObjectName); ok && *subtype == "Type3" {
+		err := v.checkResources(num, dict.Get("Resources"))
+		if err != nil {
+			common.Log.Debug("Unable to check the resources of font %s: %v", name, err)
+		}
+		return
+	}
+
+	// The font descriptor of Type0 fonts is that of their descendant CIDFont.
+	if arr, ok := core.TraceToDirectObject(dict.Get("DescendantFonts")).(*core.PdfObjectArray); ok && len(*arr) > 0 {
+		if descendant, ok := core.TraceToDirectObject((*arr)[0]).(*core.PdfObjectDictionary); ok {
+			dict = descendant
+		}
+	}
+	descriptor, ok := core.TraceToDirectObject(dict.Get("FontDescriptor")).(*core.PdfObjectDictionary)
+	if ok {
+		for _, key := range []core.PdfObjectName{"FontFile", "FontFile2", "FontFile3"} {
+			if _, ok := core.TraceToDirectObject(descriptor.Get(key)).(*core.PdfObjectStream); ok {
+				return
+			}
+		}
+	}
+	v.add(RuleFontEmbedding, num, "Font %s is not embedded", name)
+}
+
+// checkExtGState checks that a graphics state does not use transparency: soft masks, constant alpha or blend modes
+// other than Normal (PDF/A-1).
+func (v *validator) checkExtGState(num int, obj core.PdfObject) {
+	dict, ok := obj.(*core.PdfObjectDictionary)
+	if !ok {
+		return
+	}
+	if smask := core.TraceToDirectObject(dict.Get("SMask")); smask != nil {
+		if name, ok := smask.(*core.PdfObjectName); !ok || *name != "None" {
+			v.add(RuleTransparency, num, "Graphics state with a soft mask")
+		}
+	}
+	for _, key := range []core.PdfObjectName{"CA", "ca"} {
+		var alpha float64
+		switch val := core.TraceToDirectObject(dict.Get(key)).(type) {
+		case *core.PdfObjectFloat:
+			alpha = float64(*val)
+		case *core.PdfObjectInteger:
+			alpha = float64(*val)
+		default:
+			continue
+		}
+		if alpha != 1 {
+			v.add(RuleTransparency, num, "Graphics state with constant alpha %s %g", key, alpha)
+		}
+	}
+	if bm, ok := core.TraceToDirectObject(dict.Get("BM")).(*core.PdfObjectName); ok &&
+		*bm != "Normal" && *bm != "Compatible" {
+		v.add(RuleTransparency, num, "Graphics state with blend mode %s", *bm)
+	}
+}
+
+// isTransparencyGroup returns true if obj is a transparency group attributes dictionary.
+func isTransparencyGroup(obj core.PdfObject) bool {
+	dict, ok := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary)
+	if !ok {
+		return false
+	}
+	s, ok := core.TraceToDirectObject(dict.Get("S")).(*core.PdfObjectName)
+	return ok && *s == "Transparency"
+}
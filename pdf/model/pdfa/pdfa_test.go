@@ -0,0 +1,121 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/xmp"
+)
+
+// countRules returns the number of violations by rule.
+func countRules(violations []Violation) map[Rule]int {
+	counts := map[Rule]int{}
+	for _, v := range violations {
+		counts[v.Rule]++
+	}
+	return counts
+}
+
+var testObjects = []string{
+	"<< /Type /Catalog /Pages 3 0 R >>",
+	"<< /Title (Report) /Producer (Test producer) /CreationDate (D:20170304102030Z) >>",
+	"<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 2 >>",
+	"<< /Type /Page /Parent 3 0 R /MediaBox [0 0 612 792] /Contents 6 0 R " +
+		"/Resources << /Font << /F1 7 0 R /F2 8 0 R >> /ExtGState << /GS1 << /ca 0.5 >> >> " +
+		"/XObject << /Im1 10 0 R >> >> >>",
+	"<< /Type /Page /Parent 3 0 R /MediaBox [0 0 612 792] /Contents 6 0 R " +
+		"/Resources << /Font << /F1 7 0 R >> >> >>",
+	"<< /Length 33 >>\nstream\nBT /F1 12 Tf 10 10 Td (Hi) Tj ET\nendstream",
+	"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	"<< /Type /Font /Subtype /TrueType /BaseFont /Embedded /FontDescriptor 9 0 R >>",
+	"<< /Type /FontDescriptor /FontName /Embedded /FontFile2 11 0 R >>",
+	"<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 " +
+		"/SMask 12 0 R /Length 1 >>\nstream\n\x80\nendstream",
+	"<< /Length 4 >>\nstream\ntrue\nendstream",
+	"<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 " +
+		"/Length 1 >>\nstream\n\x80\nendstream",
+}
+
+func TestValidate(t *testing.T) {
+	reader, err := model.NewPdfReader(bytes.NewReader(testutils.MakePdfWithTrailer(testObjects, "/Info 2 0 R")))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	violations, err := Validate(reader, PDFA1B)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := map[Rule]int{RuleMetadata: 1, RuleIdentification: 1, RuleOutputIntent: 1, RuleFontEmbedding: 1,
+		RuleTransparency: 2}
+	if counts := countRules(violations); fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Wrong PDF/A-1b violations %v", violations)
+	}
+
+	// Transparency allowed by PDF/A-2.
+	violations, err = Validate(reader, PDFA2B)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	delete(expected, RuleTransparency)
+	if counts := countRules(violations); fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Wrong PDF/A-2b violations %v", violations)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	reader, err := model.NewPdfReader(bytes.NewReader(testutils.MakePdfWithTrailer(testObjects, "/Info 2 0 R")))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w, violations, err := Convert(reader, PDFA1B, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := map[Rule]int{RuleFontEmbedding: 1, RuleTransparency: 2}
+	if counts := countRules(violations); fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Wrong remaining violations %v", violations)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	converted, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	violations, err = Validate(converted, PDFA1B)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, v := range violations {
+		if v.Rule != RuleFontEmbedding && v.Rule != RuleTransparency {
+			t.Errorf("Not converted: %v", v)
+		}
+	}
+
+	meta, err := xmp.Load(converted)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if meta.DublinCore.Title != "Report" || meta.PDF.Producer != "Test producer" || meta.Basic.CreateDate.IsZero() {
+		t.Errorf("Wrong metadata %+v", meta)
+	}
+	intents, err := converted.GetOutputIntents()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(intents) != 1 || intents[0].OutputConditionIdentifier != srgbIdentifier || intents[0].N != 3 ||
+		!bytes.Equal(intents[0].DestOutputProfile, srgbProfile) {
+		t.Errorf("Wrong output intents %+v", intents)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfPieceData is the private data of an application in a page-piece dictionary (14.5 Page-Piece Dictionaries).
+// Applications use it to keep their own state of the page, form XObject or document they modified.
+type PdfPieceData struct {
+	// Time of the last modification of the data.
+	LastModified time.Time
+
+	// The private data, any object.  Optional.
+	Private PdfObject
+}
+
+// Loads the data of the applications from a page-piece dictionary, with the references resolved.
+func newPieceInfoFromObject(obj PdfObject) (map[string]*PdfPieceData, error) {
+	pieces := map[string]*PdfPieceData{}
+	if obj == nil {
+		return pieces, nil
+	}
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ERROR: PieceInfo not a dictionary (%T)", obj)
+		return nil, ErrTypeError
+	}
+
+	for _, key := range dict.Keys() {
+		dataDict, ok := TraceToDirectObject(dict.Get(key)).(*PdfObjectDictionary)
+		if !ok {
+			common.Log.Debug("ERROR: PieceInfo data of %s not a dictionary", key)
+			return nil, ErrTypeError
+		}
+
+		data := &PdfPieceData{}
+		if str, ok := TraceToDirectObject(dataDict.Get("LastModified")).(*PdfObjectString); ok {
+			modTime, err := time.Parse(pdfDateLayout, string(*str))
+			if err != nil {
+				// Not a complete date, keeping the zero time.
+				common.Log.Debug("Invalid PieceInfo LastModified date %s", *str)
+			} else {
+				data.LastModified = modTime
+			}
+		}
+		data.Private = dataDict.Get("Private")
+		pieces[string(key)] = data
+	}
+	return pieces, nil
+}
+
+// Sets the data of an application in the page-piece dictionary, or removes it if the data is nil.  Returns the
+// updated dictionary, created if the object is not a dictionary, or nil if it has no data left.
+func setPieceData(obj PdfObject, application string, data *PdfPieceData) *PdfObjectDictionary {
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok || dict == nil {
+		dict = MakeDict()
+	}
+
+	if data == nil {
+		dict.Remove(PdfObjectName(application))
+		if len(dict.Keys()) == 0 {
+			return nil
+		}
+		return dict
+	}
+
+	dataDict := MakeDict()
+	dataDict.Set("LastModified", MakeString(data.LastModified.Format(pdfDateLayout)))
+	dataDict.SetIfNotNil("Private", data.Private)
+	dict.Set(PdfObjectName(application), dataDict)
+	return dict
+}
+
+// GetPieceInfo returns the private data of the applications which modified the page, by application name.
+func (this *PdfPage) GetPieceInfo() (map[string]*PdfPieceData, error) {
+	return newPieceInfoFromObject(this.PieceInfo)
+}
+
+// SetPieceData sets the private data of an application on the page, or removes it if the data is nil.  The last
+// modification time of the page is updated as required, if the data is more recent.
+func (this *PdfPage) SetPieceData(application string, data *PdfPieceData) error {
+	if dict := setPieceData(this.PieceInfo, application, data); dict != nil {
+		this.PieceInfo = dict
+	} else {
+		this.PieceInfo = nil
+	}
+	if data == nil {
+		return nil
+	}
+
+	date, err := NewPdfDate(data.LastModified.Format(pdfDateLayout))
+	if err != nil {
+		return err
+	}
+	if this.LastModified == nil || date.toTime().After(this.LastModified.toTime()) {
+		this.LastModified = &date
+	}
+	return nil
+}
+
+// GetPieceInfo returns the private data of the applications which modified the form, by application name.
+func (xform *XObjectForm) GetPieceInfo() (map[string]*PdfPieceData, error) {
+	return newPieceInfoFromObject(xform.PieceInfo)
+}
+
+// SetPieceData sets the private data of an application on the form, or removes it if the data is nil.  The last
+// modification time of the form is updated as required, if the data is more recent.
+func (xform *XObjectForm) SetPieceData(application string, data *PdfPieceData) {
+	if dict := setPieceData(xform.PieceInfo, application, data); dict != nil {
+		xform.PieceInfo = dict
+	} else {
+		xform.PieceInfo = nil
+	}
+	if data == nil {
+		return
+	}
+
+	if str, ok := TraceToDirectObject(xform.LastModified).(*PdfObjectString); ok {
+		modTime, err := time.Parse(pdfDateLayout, string(*str))
+		if err == nil && !data.LastModified.After(modTime) {
+			return
+		}
+	}
+	xform.LastModified = MakeString(data.LastModified.Format(pdfDateLayout))
+}
+
+// GetPieceInfo returns the private data of the applications which modified the document, by application name.
+func (this *PdfReader) GetPieceInfo() (map[string]*PdfPieceData, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	obj, err := this.traceToObject(this.catalog.Get("PieceInfo"))
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return map[string]*PdfPieceData{}, nil
+	}
+	err = this.traverseObjectData(obj)
+	if err != nil {
+		return nil, err
+	}
+	return newPieceInfoFromObject(obj)
+}
+
+// SetPieceData sets the private data of an application on the document, or removes it if the data is nil.
+func (this *PdfWriter) SetPieceData(application string, data *PdfPieceData) {
+	if data == nil && this.pieceInfo == nil {
+		return
+	}
+	this.pieceInfo = setPieceData(this.pieceInfo, application, data)
+}
+
+// Sets the page-piece dictionary of the document in the catalog, with the applications sorted by name.
+func (this *PdfWriter) writePieceInfo() error {
+	keys := this.pieceInfo.Keys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	dict := MakeDict()
+	for _, key := range keys {
+		dict.Set(key, this.pieceInfo.Get(key))
+	}
+
+	this.catalog.Set("PieceInfo", dict)
+	return this.addObjects(dict)
+}
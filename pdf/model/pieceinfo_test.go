@@ -0,0 +1,91 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestPieceInfo(t *testing.T) {
+	modTime := time.Date(2018, 3, 4, 10, 20, 30, 0, time.UTC)
+	private := MakeDict()
+	private.Set("Layout", MakeName("TwoColumns"))
+	private.Set("Items", MakeArrayFromIntegers([]int{1, 2, 3}))
+
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 100}
+	page.Resources = NewPdfPageResources()
+	err := page.SetPieceData("Editor", &PdfPieceData{LastModified: modTime, Private: MakeIndirectObject(private)})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = page.SetPieceData("Other", &PdfPieceData{LastModified: modTime.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The page was last modified with the most recent data.
+	if page.LastModified == nil || !page.LastModified.toTime().Equal(modTime) {
+		t.Errorf("Wrong page modification time %v", page.LastModified)
+	}
+	err = page.SetPieceData("Other", nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = w.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w.SetPieceData("Assembler", &PdfPieceData{LastModified: modTime, Private: MakeString("state")})
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err = reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	pieces, err := page.GetPieceInfo()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(pieces) != 1 || pieces["Editor"] == nil {
+		t.Fatalf("Wrong page piece data %v", pieces)
+	}
+	data := pieces["Editor"]
+	if !data.LastModified.Equal(modTime) {
+		t.Errorf("Wrong modification time %v", data.LastModified)
+	}
+	dict, ok := TraceToDirectObject(data.Private).(*PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Private data not a dictionary (%T)", data.Private)
+	}
+	if name, ok := dict.Get("Layout").(*PdfObjectName); !ok || *name != "TwoColumns" {
+		t.Errorf("Wrong private data %s", dict)
+	}
+	if items, ok := dict.Get("Items").(*PdfObjectArray); !ok || len(*items) != 3 {
+		t.Errorf("Wrong private data %s", dict)
+	}
+
+	pieces, err = reader.GetPieceInfo()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if data := pieces["Assembler"]; len(pieces) != 1 || data == nil || data.Private.String() != "state" {
+		t.Errorf("Wrong document piece data %v", pieces)
+	}
+}
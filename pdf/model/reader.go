@@ -6,6 +6,8 @@
 package model
 
 import (
+	"crypto"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -32,22 +34,72 @@ type PdfReader struct {
 
 	// For tracking traversal (cache).
 	traversed map[PdfObject]bool
+
+	// Report of the repair of the document in recovery mode, nil if not repaired.
+	repairReport *RepairReport
+}
+
+// readerOptions are the options of the readers created by the NewPdfReader functions.
+type readerOptions struct {
+	// Minimum length of the lazy streams, 0 if disabled.
+	lazyStreamLength int64
+	// Recovery mode: repair the broken documents.
+	repair bool
+	// Lenient mode: tolerate the common bugs of the producers of malformed documents.
+	lenient bool
 }
 
 // NewPdfReader returns a new PdfReader for an input io.ReadSeeker interface. Can be used to read PDF from
 // memory or file. Immediately loads and traverses the PDF structure including pages and page contents (if
-// not encrypted).
+// not encrypted).  Documents where only the embedded files are encrypted are loaded as well, and need to be
+// decrypted for accessing the embedded files.
 func NewPdfReader(rs io.ReadSeeker) (*PdfReader, error) {
+	return newPdfReader(rs, readerOptions{})
+}
+
+// NewPdfReaderLazy returns a new PdfReader leaving the data of the streams of minLength bytes or more in the file
+// until accessed, e.g. for processing large scanned documents without loading all their images in memory.  rs needs
+// to be an io.ReaderAt as well, and kept open while the document is used (see core.PdfParser.SetLazyStreams).
+func NewPdfReaderLazy(rs io.ReadSeeker, minLength int64) (*PdfReader, error) {
+	return newPdfReader(rs, readerOptions{lazyStreamLength: minLength})
+}
+
+// NewPdfReaderWithRepair returns a new PdfReader in recovery mode: if the cross references of the document are broken
+// or its trailer is missing, or its structure cannot be loaded with them, the document is repaired by scanning the
+// file for its objects (see core.PdfParser.Repair).  What was reconstructed is reported by RepairReport.
+func NewPdfReaderWithRepair(rs io.ReadSeeker) (*PdfReader, error) {
+	return newPdfReader(rs, readerOptions{repair: true})
+}
+
+// NewPdfReaderLenient returns a new PdfReader parsing the document in lenient mode, tolerating the common bugs of the
+// producers of malformed documents (see core.NewParserLenient).  The problems worked around are reported by
+// ParseDiagnostics.
+func NewPdfReaderLenient(rs io.ReadSeeker) (*PdfReader, error) {
+	return newPdfReader(rs, readerOptions{lenient: true})
+}
+
+func newPdfReader(rs io.ReadSeeker, opts readerOptions) (*PdfReader, error) {
 	pdfReader := &PdfReader{}
 	pdfReader.traversed = map[PdfObject]bool{}
 
 	pdfReader.modelManager = NewModelManager()
 
 	// Create the parser, loads the cross reference table and trailer.
-	parser, err := NewParser(rs)
+	var parser *PdfParser
+	var err error
+	if opts.repair {
+		parser, pdfReader.repairReport, err = NewParserWithRepair(rs)
+	} else if opts.lenient {
+		parser, err = NewParserLenient(rs)
+	} else {
+		parser, err = NewParser(rs)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if err := parser.SetLazyStreams(opts.lazyStreamLength); err != nil {
+		return nil, err
+	}
 	pdfReader.parser = parser
 
 	isEncrypted, err := pdfReader.IsEncrypted()
@@ -55,9 +107,19 @@ func NewPdfReader(rs io.ReadSeeker) (*PdfReader, error) {
 		return nil, err
 	}
 
-	// Load pdf doc structure if not encrypted.
-	if !isEncrypted {
+	// Load pdf doc structure if not encrypted, or if only the embedded files are encrypted.
+	if !isEncrypted || pdfReader.parser.GetCrypter().EncryptsEmbeddedFilesOnly() {
 		err = pdfReader.loadStructure()
+		if err != nil && opts.repair && pdfReader.repairReport == nil {
+			// Broken cross references, not detected when loading them.
+			common.Log.Debug("Failed loading the structure, repairing the document: %v", err)
+			pdfReader.repairReport, err = parser.Repair()
+			if err == nil {
+				pdfReader.traversed = map[PdfObject]bool{}
+				pdfReader.modelManager = NewModelManager()
+				err = pdfReader.loadStructure()
+			}
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -66,6 +128,18 @@ func NewPdfReader(rs io.ReadSeeker) (*PdfReader, error) {
 	return pdfReader, nil
 }
 
+// ParseDiagnostics returns the problems of the document worked around when parsing it in lenient mode (see
+// NewPdfReaderLenient), in the order found.
+func (this *PdfReader) ParseDiagnostics() []ParseDiagnostic {
+	return this.parser.Diagnostics()
+}
+
+// RepairReport returns the report of what was reconstructed when repairing the document in recovery mode (see
+// NewPdfReaderWithRepair), nil if the document was not repaired.
+func (this *PdfReader) RepairReport() *RepairReport {
+	return this.repairReport
+}
+
 // IsEncrypted returns true if the PDF file is encrypted.
 func (this *PdfReader) IsEncrypted() (bool, error) {
 	return this.parser.IsEncrypted()
@@ -121,6 +195,36 @@ func (this *PdfReader) Decrypt(password []byte) (bool, error) {
 	return true, nil
 }
 
+// DecryptWithCertificate decrypts a PDF file encrypted with the public-key security handler (Adobe.PubSec) with a
+// recipient certificate and its private key.  Returns true if successful, false if the certificate is not a
+// recipient of the document.  The permissions granted to the recipient are then available through the crypter.
+func (this *PdfReader) DecryptWithCertificate(cert *x509.Certificate, key crypto.PrivateKey) (bool, error) {
+	success, err := this.parser.DecryptWithCertificate(cert, key)
+	if err != nil {
+		return false, err
+	}
+	if !success {
+		return false, nil
+	}
+
+	err = this.loadStructure()
+	if err != nil {
+		common.Log.Debug("ERROR: Fail to load structure (%s)", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CheckAccessRightsWithCertificate checks the access rights and permissions granted to a recipient certificate of a
+// PDF file encrypted with the public-key security handler.
+//
+// The bool flag indicates that the recipient can access and view the file.
+func (this *PdfReader) CheckAccessRightsWithCertificate(cert *x509.Certificate, key crypto.PrivateKey) (bool,
+	AccessPermissions, error) {
+	return this.parser.CheckAccessRightsWithCertificate(cert, key)
+}
+
 // CheckAccessRights checks access rights and permissions for a specified password.  If either user/owner
 // password is specified,  full rights are granted, otherwise the access rights are specified by the
 // Permissions flag.
@@ -132,9 +236,44 @@ func (this *PdfReader) CheckAccessRights(password []byte) (bool, AccessPermissio
 	return this.parser.CheckAccessRights(password)
 }
 
+// GetAccessPermissions returns the access permissions granted for the document: full access if it is not
+// encrypted or was decrypted with the owner password, otherwise the permissions of the document for the user (or
+// of the recipient for the public-key security handler).  Applications should honor these permissions, see
+// CheckPermission.
+func (this *PdfReader) GetAccessPermissions() (AccessPermissions, error) {
+	crypter := this.parser.GetCrypter()
+	if crypter != nil && !this.parser.IsAuthenticated() && !crypter.EncryptsEmbeddedFilesOnly() {
+		return AccessPermissions{}, errors.New("File need to be decrypted first")
+	}
+	return this.parser.GetAccessPermissions(), nil
+}
+
+// CheckPermission returns ErrPermissionDenied if an operation on the document is not allowed by its access
+// permissions.
+func (this *PdfReader) CheckPermission(p Permission) error {
+	perms, err := this.GetAccessPermissions()
+	if err != nil {
+		return err
+	}
+	if !perms.Allows(p) {
+		common.Log.Debug("ERROR: %s not allowed by the document permissions", p)
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// Returns true if the document is encrypted and needs to be decrypted before accessing its content.  Documents
+// where only the embedded files are encrypted can be accessed without decrypting.
+func (this *PdfReader) requiresDecryption() bool {
+	crypter := this.parser.GetCrypter()
+	return crypter != nil && !this.parser.IsAuthenticated() && !crypter.EncryptsEmbeddedFilesOnly()
+}
+
 // Loads the structure of the pdf file: pages, outlines, etc.
 func (this *PdfReader) loadStructure() error {
-	if this.parser.GetCrypter() != nil && !this.parser.IsAuthenticated() {
+	defer common.StartTiming(common.TimingLoadStructure)()
+
+	if this.requiresDecryption() {
 		return fmt.Errorf("File need to be decrypted first")
 	}
 
@@ -197,6 +336,7 @@ func (this *PdfReader) loadStructure() error {
 	this.pages = pages
 	this.pageCount = int(*pageCount)
 	this.pageList = []*PdfIndirectObject{}
+	this.PageList = []*PdfPage{}
 
 	traversedPageNodes := map[PdfObject]bool{}
 	err = this.buildPageList(ppages, nil, traversedPageNodes)
@@ -257,7 +397,7 @@ func (this *PdfReader) traceToObject(obj PdfObject) (PdfObject, error) {
 }
 
 func (this *PdfReader) loadOutlines() (*PdfOutlineTreeNode, error) {
-	if this.parser.GetCrypter() != nil && !this.parser.IsAuthenticated() {
+	if this.requiresDecryption() {
 		return nil, fmt.Errorf("File need to be decrypted first")
 	}
 
@@ -430,7 +570,7 @@ func (this *PdfReader) GetOutlinesFlattened() ([]*PdfOutlineTreeNode, []string,
 
 		if item, isItem := node.context.(*PdfOutlineItem); isItem {
 			*outlineList = append(*outlineList, &item.PdfOutlineTreeNode)
-			title := strings.Repeat(" ", depth*2) + string(*item.Title)
+			title := strings.Repeat(" ", depth*2) + item.Title.Decoded()
 			*titleList = append(*titleList, title)
 			if item.Next != nil {
 				flattenFunc(item.Next, outlineList, titleList, depth)
@@ -449,7 +589,7 @@ func (this *PdfReader) GetOutlinesFlattened() ([]*PdfOutlineTreeNode, []string,
 
 // loadForms loads the AcroForm.
 func (this *PdfReader) loadForms() (*PdfAcroForm, error) {
-	if this.parser.GetCrypter() != nil && !this.parser.IsAuthenticated() {
+	if this.requiresDecryption() {
 		return nil, fmt.Errorf("File need to be decrypted first")
 	}
 
@@ -596,7 +736,7 @@ func (this *PdfReader) buildPageList(node *PdfIndirectObject, parent *PdfIndirec
 
 // GetNumPages returns the number of pages in the document.
 func (this *PdfReader) GetNumPages() (int, error) {
-	if this.parser.GetCrypter() != nil && !this.parser.IsAuthenticated() {
+	if this.requiresDecryption() {
 		return 0, fmt.Errorf("File need to be decrypted first")
 	}
 	return len(this.pageList), nil
@@ -702,7 +842,7 @@ func (this *PdfReader) traverseObjectData(o PdfObject) error {
 
 // GetPageAsIndirectObject returns an indirect object containing the page dictionary for a specified page number.
 func (this *PdfReader) GetPageAsIndirectObject(pageNumber int) (PdfObject, error) {
-	if this.parser.GetCrypter() != nil && !this.parser.IsAuthenticated() {
+	if this.requiresDecryption() {
 		return nil, fmt.Errorf("File needs to be decrypted first")
 	}
 	if len(this.pageList) < pageNumber {
@@ -723,7 +863,9 @@ func (this *PdfReader) GetPageAsIndirectObject(pageNumber int) (PdfObject, error
 
 // GetPage returns the PdfPage model for the specified page number.
 func (this *PdfReader) GetPage(pageNumber int) (*PdfPage, error) {
-	if this.parser.GetCrypter() != nil && !this.parser.IsAuthenticated() {
+	defer common.StartTiming(common.TimingLoadPage)()
+
+	if this.requiresDecryption() {
 		return nil, fmt.Errorf("File needs to be decrypted first")
 	}
 	if len(this.pageList) < pageNumber {
@@ -775,6 +917,12 @@ func (r *PdfReader) GetObjectNums() []int {
 	return r.parser.GetObjectNums()
 }
 
+// Objects returns an iterator over the indirect objects of the document, with their classification (see
+// core.ObjectIterator).  The objects already loaded by the reader are shared, with their references resolved.
+func (this *PdfReader) Objects() *ObjectIterator {
+	return this.parser.Objects()
+}
+
 // GetIndirectObjectByNumber retrieves and returns a specific PdfObject by object number.
 func (this *PdfReader) GetIndirectObjectByNumber(number int) (PdfObject, error) {
 	obj, err := this.parser.LookupByNumber(number)
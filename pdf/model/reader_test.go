@@ -0,0 +1,102 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReaderWithRepair(t *testing.T) {
+	w := NewPdfWriter()
+	for i := 0; i < 2; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+		page.Resources = NewPdfPageResources()
+		page.AddContentStreamByString("0 0 100 100 re f")
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data := buf.Bytes()
+
+	reader, err := NewPdfReaderWithRepair(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if reader.RepairReport() != nil {
+		t.Errorf("Valid document repaired: %s", reader.RepairReport())
+	}
+
+	// Truncated before the cross references and trailer.
+	data = data[:bytes.LastIndex(data, []byte("\nxref"))+1]
+	if _, err := NewPdfReader(bytes.NewReader(data)); err == nil {
+		t.Fatalf("Truncated document read without repair")
+	}
+	reader, err = NewPdfReaderWithRepair(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	report := reader.RepairReport()
+	if report == nil || report.Trailers != 0 || report.InvalidObjects != 0 || !report.CatalogRecovered {
+		t.Errorf("Wrong report %+v", report)
+	}
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if numPages != 2 {
+		t.Errorf("Wrong number of pages %d", numPages)
+	}
+	if _, err := reader.GetPage(2); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+}
+
+func TestReaderLenient(t *testing.T) {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+	page.Resources = NewPdfPageResources()
+	page.AddContentStreamByString("0 0 100 100 re f")
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// Wrong content stream length, and no space in the reference to the pages, without moving the objects.
+	data := bytes.Replace(buf.Bytes(), []byte("/Length 16>>"), []byte("/Length 10>>"), 1)
+	data = bytes.Replace(data, []byte("/Pages 3 0 R/"), []byte("/Pages  3 0R/"), 1)
+	if _, err := NewPdfReader(bytes.NewReader(data)); err == nil {
+		t.Fatalf("Malformed document read in strict mode")
+	}
+
+	reader, err := NewPdfReaderLenient(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err = reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	contents, err := page.GetContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(contents) == 0 || contents[0] != "0 0 100 100 re f" {
+		t.Errorf("Wrong contents %q", contents)
+	}
+	if diags := reader.ParseDiagnostics(); len(diags) != 2 {
+		t.Errorf("Wrong diagnostics %v", diags)
+	}
+}
@@ -0,0 +1,357 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// contentRedactor removes the glyphs and images within the regions from content streams.
+type contentRedactor struct {
+	regions []region
+
+	// The glyphs to remove by content stream (see formsKey) and operation index.
+	glyphs map[string]map[int][]extractor.TextMark
+
+	// Resources whose XObject dictionary was replaced by a copy, which can be modified.
+	copied map[*model.PdfPageResources]bool
+
+	// The content streams being redacted, from the page to the innermost Form XObject.
+	streams []*redactedStream
+}
+
+// redactedStream is the redaction of a content stream, of the page or of a Form XObject.
+type redactedStream struct {
+	// The names of the Form XObjects containing the content stream, and its resources.
+	forms     []core.PdfObjectName
+	resources *model.PdfPageResources
+
+	// The operations kept, the images removed, and whether any content was removed.
+	result  contentstream.ContentStreamOperations
+	removed map[core.PdfObjectName]bool
+	changed bool
+}
+
+// formsKey returns the key of the content stream of nested Form XObjects.
+func formsKey(forms []core.PdfObjectName) string {
+	names := []string{}
+	for _, name := range forms {
+		names = append(names, string(name))
+	}
+	return strings.Join(names, "/")
+}
+
+// redact returns the operations of the page contents without the content within the regions.  The content is
+// located with the CTM of the content stream processor, as for the extraction of the glyphs.  Images and Form
+// XObjects removed or replaced are removed or replaced in the resources, which are modified in place: the resources
+// must not be shared with other pages.
+func (red *contentRedactor) redact(contents string, resources *model.PdfPageResources) (
+	contentstream.ContentStreamOperations, error) {
+	cstreamParser := contentstream.NewContentStreamParser(contents)
+	operations, err := cstreamParser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &redactedStream{resources: resources, removed: map[core.PdfObjectName]bool{}}
+	red.streams = []*redactedStream{page}
+	processor := contentstream.NewContentStreamProcessor(*operations)
+	err = processor.ProcessWithForms(resources, red.addHandlers)
+	if err != nil {
+		return nil, err
+	}
+	return page.result, nil
+}
+
+// addHandlers registers the handler redacting a content stream.  Forms outside of the regions are skipped.  The
+// forms with content removed are replaced in the resources by a redacted copy once processed, as the form may be
+// drawn elsewhere.
+func (red *contentRedactor) addHandlers(processor *contentstream.ContentStreamProcessor,
+	form *contentstream.DrawnForm) (func() error, error) {
+	stream := red.streams[len(red.streams)-1]
+	var parent *redactedStream
+	if form != nil {
+		parent = stream
+		// Forms without resources use the resources of the content stream drawing them.
+		stream = &redactedStream{resources: form.Resources, removed: map[core.PdfObjectName]bool{}}
+		stream.forms = append(append([]core.PdfObjectName{}, parent.forms...), form.Name)
+
+		// Forms outside of the regions are kept, unless they contain glyphs to remove, e.g. with a missing or
+		// invalid bounding box.
+		if arr, ok := core.TraceToDirectObject(form.XObject.BBox).(*core.PdfObjectArray); ok {
+			bbox, err := model.NewPdfRectangle(*arr)
+			ctm := processor.GetGraphicsState().CTM
+			if err == nil && !rectInRegions(transformRect(*bbox, ctm), red.regions) && !red.hasGlyphs(stream.forms) {
+				return nil, contentstream.ErrSkipForm
+			}
+		}
+		red.streams = append(red.streams, stream)
+	}
+
+	glyphs := red.glyphs[formsKey(stream.forms)]
+	unit := model.PdfRectangle{Urx: 1, Ury: 1}
+	index := 0
+	processor.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+			marks, hasGlyphs := glyphs[index]
+			index++
+			switch op.Operand {
+			case "Tj", "'", "\"", "TJ":
+				if hasGlyphs {
+					stream.result = append(stream.result, redactText(op, marks)...)
+					stream.changed = true
+					return nil
+				}
+			case "BI":
+				if rectInRegions(transformRect(unit, gs.CTM), red.regions) {
+					stream.changed = true
+					return nil
+				}
+			case "Do":
+				if len(op.Params) != 1 {
+					break
+				}
+				name, ok := op.Params[0].(*core.PdfObjectName)
+				if !ok {
+					break
+				}
+				_, xtype := resources.GetXObjectByName(*name)
+				if xtype == model.XObjectTypeImage && rectInRegions(transformRect(unit, gs.CTM), red.regions) {
+					stream.removed[*name] = true
+					stream.changed = true
+					return nil
+				}
+			}
+			stream.result = append(stream.result, op)
+			return nil
+		})
+
+	return func() error {
+		return red.finish(stream, form, parent)
+	}, nil
+}
+
+// finish removes the resources of the images removed from the content stream, unless still used, and replaces the
+// form drawn by a redacted copy in the resources of the parent content stream if content was removed.
+func (red *contentRedactor) finish(stream *redactedStream, form *contentstream.DrawnForm,
+	parent *redactedStream) error {
+	for _, op := range stream.result {
+		if op.Operand == "Do" && len(op.Params) == 1 {
+			if name, ok := op.Params[0].(*core.PdfObjectName); ok {
+				delete(stream.removed, *name)
+			}
+		}
+	}
+	for name := range stream.removed {
+		xobjects, err := red.getXObjects(stream.resources)
+		if err != nil {
+			return err
+		}
+		xobjects.Remove(name)
+	}
+	if form == nil {
+		return nil
+	}
+
+	red.streams = red.streams[:len(red.streams)-1]
+	if !stream.changed {
+		return nil
+	}
+
+	// The redacted copy of the form.
+	formStream, err := core.MakeStream(stream.result.Bytes(), core.NewFlateEncoder())
+	if err != nil {
+		return err
+	}
+	original := form.XObject.GetContainingPdfObject().(*core.PdfObjectStream)
+	for _, key := range original.PdfObjectDictionary.Keys() {
+		switch key {
+		case "Length", "Filter", "DecodeParms":
+		default:
+			formStream.PdfObjectDictionary.Set(key, original.PdfObjectDictionary.Get(key))
+		}
+	}
+	if form.XObject.Resources != nil {
+		formStream.PdfObjectDictionary.Set("Resources", stream.resources.ToPdfObject())
+	}
+
+	xobjects, err := red.getXObjects(parent.resources)
+	if err != nil {
+		return err
+	}
+	xobjects.Set(form.Name, formStream)
+	parent.changed = true
+	return nil
+}
+
+// hasGlyphs returns true if glyphs are removed from the content stream of the forms or of forms nested in them.
+func (red *contentRedactor) hasGlyphs(forms []core.PdfObjectName) bool {
+	key := formsKey(forms)
+	for k := range red.glyphs {
+		if k == key || strings.HasPrefix(k, key+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// getXObjects returns the XObject dictionary of the resources, replaced by a copy the first time, so the dictionary
+// can be modified without affecting other resources sharing it.
+func (red *contentRedactor) getXObjects(resources *model.PdfPageResources) (*core.PdfObjectDictionary, error) {
+	if red.copied == nil {
+		red.copied = map[*model.PdfPageResources]bool{}
+	}
+	dict, ok := core.TraceToDirectObject(resources.XObject).(*core.PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("Invalid XObject resources (%T)", resources.XObject)
+		return nil, errors.New("Type check error")
+	}
+	if !red.copied[resources] {
+		copied := core.MakeDict()
+		copied.Merge(dict)
+		resources.XObject = copied
+		red.copied[resources] = true
+		dict = copied
+	}
+	return dict, nil
+}
+
+// redactText rewrites a text showing operation without the glyphs of the marks.  The glyphs are replaced by
+// position adjustments in a TJ array, so the other glyphs keep their position.
+func redactText(op *contentstream.ContentStreamOperation, marks []extractor.TextMark) []*contentstream.ContentStreamOperation {
+	ops := []*contentstream.ContentStreamOperation{}
+	var elements []core.PdfObject
+	switch op.Operand {
+	case "Tj":
+		elements = op.Params
+	case "'":
+		ops = append(ops, &contentstream.ContentStreamOperation{Operand: "T*"})
+		elements = op.Params
+	case "\"":
+		if len(op.Params) != 3 {
+			return []*contentstream.ContentStreamOperation{op}
+		}
+		ops = append(ops,
+			&contentstream.ContentStreamOperation{Operand: "Tw", Params: op.Params[0:1]},
+			&contentstream.ContentStreamOperation{Operand: "Tc", Params: op.Params[1:2]},
+			&contentstream.ContentStreamOperation{Operand: "T*"})
+		// The string is the third operand, the marks are located by operand index.
+		elements = []core.PdfObject{core.MakeNull(), core.MakeNull(), op.Params[2]}
+	case "TJ":
+		if len(op.Params) != 1 {
+			return []*contentstream.ContentStreamOperation{op}
+		}
+		arr, ok := op.Params[0].(*core.PdfObjectArray)
+		if !ok {
+			return []*contentstream.ContentStreamOperation{op}
+		}
+		elements = *arr
+	}
+
+	// The removed character codes by string and offset.
+	removed := map[[2]int]extractor.TextMark{}
+	for _, mark := range marks {
+		removed[[2]int{mark.StringIndex, mark.Offset}] = mark
+	}
+
+	arr := core.MakeArray()
+	var adjust float64
+	hasAdjust := false
+	flushAdjust := func() {
+		if hasAdjust {
+			arr.Append(core.MakeFloat(adjust))
+		}
+		adjust, hasAdjust = 0, false
+	}
+	for i, obj := range elements {
+		switch v := obj.(type) {
+		case *core.PdfObjectString:
+			kept := []byte{}
+			data := []byte(*v)
+			for offset := 0; offset < len(data); {
+				mark, has := removed[[2]int{i, offset}]
+				if !has {
+					kept = append(kept, data[offset])
+					offset++
+					continue
+				}
+				if len(kept) > 0 {
+					flushAdjust()
+					arr.Append(core.MakeString(string(kept)))
+					kept = []byte{}
+				}
+				adjust -= mark.Displacement
+				hasAdjust = true
+				offset += len(mark.CharCodes)
+			}
+			if len(kept) > 0 {
+				flushAdjust()
+				arr.Append(core.MakeString(string(kept)))
+			}
+		case *core.PdfObjectFloat:
+			adjust += float64(*v)
+			hasAdjust = true
+		case *core.PdfObjectInteger:
+			adjust += float64(*v)
+			hasAdjust = true
+		}
+	}
+	flushAdjust()
+
+	return append(ops, &contentstream.ContentStreamOperation{Operand: "TJ", Params: []core.PdfObject{arr}})
+}
+
+// transformRect returns the bounding box of a rectangle transformed by a matrix.
+func transformRect(rect model.PdfRectangle, m contentstream.Matrix) model.PdfRectangle {
+	x, y := m.Transform(rect.Llx, rect.Lly)
+	bbox := model.PdfRectangle{Llx: x, Lly: y, Urx: x, Ury: y}
+	for _, c := range [3][2]float64{{rect.Urx, rect.Lly}, {rect.Urx, rect.Ury}, {rect.Llx, rect.Ury}} {
+		x, y := m.Transform(c[0], c[1])
+		bbox.Llx = math.Min(bbox.Llx, x)
+		bbox.Lly = math.Min(bbox.Lly, y)
+		bbox.Urx = math.Max(bbox.Urx, x)
+		bbox.Ury = math.Max(bbox.Ury, y)
+	}
+	return bbox
+}
+
+// getMarkFont returns the font dictionary of a glyph, from the resources of the page or of the Form XObjects
+// containing it.
+func getMarkFont(resources *model.PdfPageResources, mark extractor.TextMark) *core.PdfObjectDictionary {
+	for _, name := range mark.Location.Forms {
+		if resources == nil {
+			return nil
+		}
+		stream, xtype := resources.GetXObjectByName(name)
+		if xtype != model.XObjectTypeForm {
+			return nil
+		}
+		xform, err := model.NewXObjectFormFromStream(stream)
+		if err != nil {
+			return nil
+		}
+		if xform.Resources != nil {
+			resources = xform.Resources
+		}
+	}
+	if resources == nil {
+		return nil
+	}
+	obj, found := resources.GetFontByName(core.PdfObjectName(mark.FontName))
+	if !found {
+		return nil
+	}
+	dict, _ := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary)
+	return dict
+}
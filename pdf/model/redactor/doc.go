@@ -0,0 +1,24 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package redactor removes content from the pages of PDF documents.  Unlike drawing boxes over the content, the
+// text showing and image drawing operations within the redacted regions are removed from the content streams, so
+// the content cannot be recovered from the document.
+//
+// Example: redacting a name in all pages of a document.
+//
+//	r := redactor.New(pdfReader)
+//	r.AddSearchText("John Doe")
+//	err := r.Redact()
+//	...
+//	// Write the pages of pdfReader with a PdfWriter.
+//
+// Sanitize removes the information which is not visible on the pages, such as metadata, embedded files, JavaScript
+// and hidden layers, writing a document with a single revision:
+//
+//	w, err := redactor.Sanitize(pdfReader)
+//	...
+//	err = w.Write(outputFile)
+package redactor
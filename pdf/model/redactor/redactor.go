@@ -0,0 +1,306 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"errors"
+	"math"
+	"regexp"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Glyphs are redacted when the overlap of their bounding box and a region exceeds this fraction of the glyph size in
+// both directions, so neighbouring glyphs touching a region, e.g. due to kerning, are kept.
+const glyphOverlapTolerance = 0.1
+
+// Redactor removes the text and images within regions of the pages of a document.  The pages of the reader are
+// modified in place and can be written with a PdfWriter.
+type Redactor struct {
+	// The color of the boxes drawn over the redacted regions, or nil to leave the regions blank.  Black by default.
+	FillColor *model.PdfColorDeviceRGB
+
+	// Whether the Redact annotations of the pages are applied: their regions are redacted and the annotations
+	// removed.  The interior color (IC) of the annotations is used for the boxes if set.
+	ApplyAnnotations bool
+
+	reader  *model.PdfReader
+	regions map[int][]model.PdfRectangle
+	terms   []*regexp.Regexp
+	texts   []string
+}
+
+// region is a region of a page to redact and the color of the box drawn over it.
+type region struct {
+	rect  model.PdfRectangle
+	color *model.PdfColorDeviceRGB
+}
+
+// New returns a Redactor for the pages of the document loaded by reader.
+func New(reader *model.PdfReader) *Redactor {
+	return &Redactor{
+		FillColor: model.NewPdfColorDeviceRGB(0, 0, 0),
+		reader:    reader,
+		regions:   map[int][]model.PdfRectangle{},
+	}
+}
+
+// AddRegion adds a region of a page (1-based number) to redact, in page coordinates.
+func (r *Redactor) AddRegion(pageNum int, rect model.PdfRectangle) error {
+	if pageNum < 1 || pageNum > len(r.reader.PageList) {
+		common.Log.Debug("Page number out of range (%d)", pageNum)
+		return errors.New("Range check error")
+	}
+	r.regions[pageNum] = append(r.regions[pageNum], rect)
+	return nil
+}
+
+// AddSearchTerm adds a regular expression to redact in all pages: the regions of its matches in the page text are
+// redacted.  See extractor.Extractor.Search.
+func (r *Redactor) AddSearchTerm(re *regexp.Regexp) {
+	r.terms = append(r.terms, re)
+}
+
+// AddSearchText adds a literal text to redact in all pages, with any whitespace matching any whitespace.
+func (r *Redactor) AddSearchText(text string) {
+	r.texts = append(r.texts, text)
+}
+
+// Redact removes the content within the regions from the pages.  Text showing operations are rewritten without the
+// glyphs within the regions, keeping the position of the other glyphs, and images and inline images overlapping
+// the regions are removed.  Form XObjects containing redacted content are replaced by redacted copies.  The ToUnicode
+// mappings of the fonts of the redacted glyphs are reduced to the character codes still shown in the document, and
+// the resources no longer used by the redacted pages are removed.
+func (r *Redactor) Redact() error {
+	fonts := fontUsage{}
+	for i, page := range r.reader.PageList {
+		regions, err := r.getRegions(i+1, page)
+		if err != nil {
+			return err
+		}
+		if len(regions) == 0 {
+			continue
+		}
+		err = redactPage(page, regions, fonts)
+		if err != nil {
+			common.Log.Debug("Unable to redact page %d: %v", i+1, err)
+			return err
+		}
+	}
+
+	if len(fonts) == 0 {
+		return nil
+	}
+	return fonts.scrubToUnicode(r.reader.PageList)
+}
+
+// getRegions returns the regions to redact on a page: the added regions, the matches of the search terms and the
+// Redact annotations if applied.
+func (r *Redactor) getRegions(pageNum int, page *model.PdfPage) ([]region, error) {
+	regions := []region{}
+	for _, rect := range r.regions[pageNum] {
+		regions = append(regions, region{rect: rect, color: r.FillColor})
+	}
+
+	if len(r.terms) > 0 || len(r.texts) > 0 {
+		e, err := extractor.New(page)
+		if err != nil {
+			return nil, err
+		}
+		matches := []extractor.TextMatch{}
+		for _, re := range r.terms {
+			termMatches, err := e.Search(re)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, termMatches...)
+		}
+		for _, text := range r.texts {
+			textMatches, err := e.SearchText(text)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, textMatches...)
+		}
+		for _, match := range matches {
+			for _, quad := range match.Quads {
+				rect := model.PdfRectangle{Llx: quad[0].X, Lly: quad[0].Y, Urx: quad[0].X, Ury: quad[0].Y}
+				for _, p := range quad[1:] {
+					rect.Llx = math.Min(rect.Llx, p.X)
+					rect.Lly = math.Min(rect.Lly, p.Y)
+					rect.Urx = math.Max(rect.Urx, p.X)
+					rect.Ury = math.Max(rect.Ury, p.Y)
+				}
+				regions = append(regions, region{rect: rect, color: r.FillColor})
+			}
+		}
+	}
+
+	if r.ApplyAnnotations {
+		annotations := []*model.PdfAnnotation{}
+		for _, annot := range page.Annotations {
+			redact, ok := annot.GetContext().(*model.PdfAnnotationRedact)
+			if !ok {
+				annotations = append(annotations, annot)
+				continue
+			}
+			annotRegions, err := getAnnotationRegions(redact, r.FillColor)
+			if err != nil {
+				return nil, err
+			}
+			regions = append(regions, annotRegions...)
+		}
+		page.Annotations = annotations
+	}
+
+	return regions, nil
+}
+
+// getAnnotationRegions returns the regions of a Redact annotation: the quadrilaterals of its QuadPoints, or its
+// Rect.
+func getAnnotationRegions(annot *model.PdfAnnotationRedact, fillColor *model.PdfColorDeviceRGB) ([]region, error) {
+	color := fillColor
+	if ic, ok := core.TraceToDirectObject(annot.IC).(*core.PdfObjectArray); ok && len(*ic) == 3 {
+		vals, err := ic.ToFloat64Array()
+		if err == nil {
+			color = model.NewPdfColorDeviceRGB(vals[0], vals[1], vals[2])
+		}
+	}
+
+	regions := []region{}
+	if qp, ok := core.TraceToDirectObject(annot.QuadPoints).(*core.PdfObjectArray); ok && len(*qp) >= 8 {
+		vals, err := qp.ToFloat64Array()
+		if err != nil {
+			common.Log.Debug("Invalid QuadPoints: %v", err)
+			return nil, err
+		}
+		for i := 0; i+8 <= len(vals); i += 8 {
+			rect := model.PdfRectangle{Llx: vals[i], Lly: vals[i+1], Urx: vals[i], Ury: vals[i+1]}
+			for j := i + 2; j < i+8; j += 2 {
+				rect.Llx = math.Min(rect.Llx, vals[j])
+				rect.Lly = math.Min(rect.Lly, vals[j+1])
+				rect.Urx = math.Max(rect.Urx, vals[j])
+				rect.Ury = math.Max(rect.Ury, vals[j+1])
+			}
+			regions = append(regions, region{rect: rect, color: color})
+		}
+		return regions, nil
+	}
+
+	arr, ok := core.TraceToDirectObject(annot.Rect).(*core.PdfObjectArray)
+	if !ok {
+		common.Log.Debug("Redact annotation without region")
+		return regions, nil
+	}
+	rect, err := model.NewPdfRectangle(*arr)
+	if err != nil {
+		return nil, err
+	}
+	return append(regions, region{rect: *rect, color: color}), nil
+}
+
+// redactPage removes the content within the regions from the page, and draws the boxes over the regions.
+func redactPage(page *model.PdfPage, regions []region, fonts fontUsage) error {
+	e, err := extractor.New(page)
+	if err != nil {
+		return err
+	}
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		return err
+	}
+
+	// The glyphs to remove, by content stream and operation.
+	glyphs := map[string]map[int][]extractor.TextMark{}
+	for _, mark := range marks {
+		if !glyphInRegions(mark.BBox, regions) {
+			continue
+		}
+		key := formsKey(mark.Location.Forms)
+		if glyphs[key] == nil {
+			glyphs[key] = map[int][]extractor.TextMark{}
+		}
+		glyphs[key][mark.Location.Operation] = append(glyphs[key][mark.Location.Operation], mark)
+
+		if font := getMarkFont(page.Resources, mark); font != nil {
+			fonts.add(font)
+		}
+	}
+
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	if page.Resources == nil {
+		page.Resources = model.NewPdfPageResources()
+	}
+	red := &contentRedactor{regions: regions, glyphs: glyphs}
+	operations, err := red.redact(contents, page.Resources)
+	if err != nil {
+		return err
+	}
+
+	// The boxes are drawn over the content, which is wrapped in q/Q to restore the initial graphics state.
+	ops := contentstream.ContentStreamOperations{&contentstream.ContentStreamOperation{Operand: "q"}}
+	ops = append(ops, operations...)
+	ops = append(ops, &contentstream.ContentStreamOperation{Operand: "Q"})
+	for _, reg := range regions {
+		if reg.color == nil {
+			continue
+		}
+		rect := reg.rect
+		ops = append(ops,
+			&contentstream.ContentStreamOperation{Operand: "q"},
+			&contentstream.ContentStreamOperation{Operand: "rg", Params: makeParams(reg.color.R(), reg.color.G(), reg.color.B())},
+			&contentstream.ContentStreamOperation{Operand: "re", Params: makeParams(rect.Llx, rect.Lly, rect.Urx-rect.Llx, rect.Ury-rect.Lly)},
+			&contentstream.ContentStreamOperation{Operand: "f"},
+			&contentstream.ContentStreamOperation{Operand: "Q"})
+	}
+
+	err = page.SetContentStreams([]string{string(ops.Bytes())}, core.NewFlateEncoder())
+	if err != nil {
+		return err
+	}
+	_, err = contentstream.RemoveUnusedResources(page)
+	return err
+}
+
+// glyphInRegions returns true if the bounding box of a glyph overlaps a region, see glyphOverlapTolerance.  Glyphs
+// without width or height are redacted if they are within or touch a region.
+func glyphInRegions(bbox model.PdfRectangle, regions []region) bool {
+	w, h := bbox.Urx-bbox.Llx, bbox.Ury-bbox.Lly
+	for _, reg := range regions {
+		ox := math.Min(bbox.Urx, reg.rect.Urx) - math.Max(bbox.Llx, reg.rect.Llx)
+		oy := math.Min(bbox.Ury, reg.rect.Ury) - math.Max(bbox.Lly, reg.rect.Lly)
+		if ox >= glyphOverlapTolerance*w && oy >= glyphOverlapTolerance*h && (ox > 0 || w == 0) && (oy > 0 || h == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// rectInRegions returns true if the rectangle overlaps a region.
+func rectInRegions(rect model.PdfRectangle, regions []region) bool {
+	for _, reg := range regions {
+		if rect.Llx < reg.rect.Urx && reg.rect.Llx < rect.Urx && rect.Lly < reg.rect.Ury && reg.rect.Lly < rect.Ury {
+			return true
+		}
+	}
+	return false
+}
+
+// makeParams makes the number operands of an operation.
+func makeParams(vals ...float64) []core.PdfObject {
+	params := []core.PdfObject{}
+	for _, val := range vals {
+		params = append(params, core.MakeFloat(val))
+	}
+	return params
+}
@@ -0,0 +1,243 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+const testContents = `
+BT
+/F1 10 Tf
+100 700 Td
+(Name: John Doe, Esq.) Tj
+0 -20 Td
+[(Phone:) -250 (555) 10 (-1234)] TJ
+ET
+q 50 0 0 50 300 300 cm /Im1 Do Q
+q 20 0 0 20 10 10 cm /Im2 Do Q
+q 1 0 0 1 100 500 cm /Fm1 Do Q
+`
+
+const testToUnicode = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Test def
+1 begincodespacerange
+<00> <FF>
+endcodespacerange
+1 beginbfrange
+<20> <7E> <0020>
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end
+`
+
+// makeTestImage returns a 1x1 gray image XObject stream.
+func makeTestImage() *core.PdfObjectStream {
+	stream, _ := core.MakeStream([]byte{0x80}, nil)
+	stream.Set("Type", core.MakeName("XObject"))
+	stream.Set("Subtype", core.MakeName("Image"))
+	stream.Set("Width", core.MakeInteger(1))
+	stream.Set("Height", core.MakeInteger(1))
+	stream.Set("ColorSpace", core.MakeName("DeviceGray"))
+	stream.Set("BitsPerComponent", core.MakeInteger(8))
+	return stream
+}
+
+// makeTestReader returns a reader with a page showing text with a font with a ToUnicode CMap, two images and a
+// form.
+func makeTestReader(t *testing.T) *model.PdfReader {
+	font := fonts.NewFontHelvetica().ToPdfObject()
+	toUnicode, err := core.MakeStream([]byte(testToUnicode), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	core.TraceToDirectObject(font).(*core.PdfObjectDictionary).Set("ToUnicode", toUnicode)
+
+	resources := model.NewPdfPageResources()
+	err = resources.SetFontByName("F1", font)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetXObjectByName("Im1", makeTestImage())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetXObjectByName("Im2", makeTestImage())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	xform := model.NewXObjectForm()
+	xform.BBox = core.MakeArrayFromFloats([]float64{0, 0, 200, 20})
+	err = xform.SetContentStream([]byte("BT /F1 10 Tf 0 5 Td (Form secret) Tj ET"), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = resources.SetXObjectFormByName("Fm1", xform)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	page := model.NewPdfPage()
+	page.Resources = resources
+	err = page.SetContentStreams([]string{testContents}, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return &model.PdfReader{PageList: []*model.PdfPage{page}}
+}
+
+// extractMarks returns the text marks of a page by text.
+func extractMarks(t *testing.T, page *model.PdfPage) (string, []extractor.TextMark) {
+	e, err := extractor.New(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	marks, err := e.ExtractTextMarks()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	text := ""
+	for _, mark := range marks {
+		text += mark.Text
+	}
+	return text, marks
+}
+
+func TestRedact(t *testing.T) {
+	reader := makeTestReader(t)
+	page := reader.PageList[0]
+	_, before := extractMarks(t, page)
+	form, _ := page.Resources.GetXObjectByName("Fm1")
+
+	r := New(reader)
+	r.AddSearchText("John Doe")
+	r.AddSearchTerm(regexp.MustCompile(`555`))
+	r.AddSearchTerm(regexp.MustCompile(`secret`))
+	err := r.AddRegion(1, model.PdfRectangle{Llx: 290, Lly: 290, Urx: 360, Ury: 360})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = r.AddRegion(2, model.PdfRectangle{})
+	if err == nil {
+		t.Errorf("Region added to a missing page")
+	}
+	err = r.Redact()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	text, after := extractMarks(t, page)
+	if text != "Name: , Esq.Phone:-1234Form " {
+		t.Errorf("Wrong redacted text %q", text)
+	}
+
+	// The remaining glyphs keep their position.
+	for _, mark := range after {
+		if mark.Text != "E" && mark.Text != "4" {
+			continue
+		}
+		for _, orig := range before {
+			if orig.Text == mark.Text && math.Abs(orig.Origin.Y-mark.Origin.Y) < 1e-6 &&
+				math.Abs(orig.Origin.X-mark.Origin.X) > 1e-6 {
+				t.Errorf("Glyph %s moved from %v to %v", mark.Text, orig.Origin, mark.Origin)
+			}
+		}
+	}
+
+	// The image within the region is removed, the other kept.
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if strings.Contains(contents, "/Im1 Do") || !strings.Contains(contents, "/Im2 Do") {
+		t.Errorf("Wrong images drawn: %q", contents)
+	}
+	if _, xtype := page.Resources.GetXObjectByName("Im1"); xtype != model.XObjectTypeUndefined {
+		t.Errorf("Redacted image still in the resources")
+	}
+
+	// The form is replaced by a copy, the original is not modified.
+	redacted, _ := page.Resources.GetXObjectByName("Fm1")
+	if redacted == form {
+		t.Errorf("Redacted form not replaced")
+	}
+	xform, err := model.NewXObjectFormFromStream(form)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data, err := xform.GetContentStream()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(string(data), "secret") {
+		t.Errorf("Original form modified: %q", data)
+	}
+
+	// The ToUnicode CMap only maps the codes still shown.
+	obj, _ := page.Resources.GetFontByName("F1")
+	stream, ok := core.TraceToDirectObject(obj).(*core.PdfObjectDictionary).Get("ToUnicode").(*core.PdfObjectStream)
+	if !ok {
+		t.Fatalf("Missing ToUnicode")
+	}
+	data, err = core.DecodeStream(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if strings.Contains(string(data), "<4A>") || !strings.Contains(string(data), "<4E> <004E>") {
+		t.Errorf("Wrong ToUnicode CMap %q", data)
+	}
+}
+
+func TestRedactAnnotations(t *testing.T) {
+	reader := makeTestReader(t)
+	page := reader.PageList[0]
+
+	annot := model.NewPdfAnnotationRedact()
+	annot.Rect = core.MakeArrayFromFloats([]float64{0, 0, 40, 40})
+	annot.IC = core.MakeArrayFromFloats([]float64{1, 0, 0})
+	page.Annotations = append(page.Annotations, annot.PdfAnnotation)
+
+	r := New(reader)
+	r.ApplyAnnotations = true
+	err := r.Redact()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if len(page.Annotations) != 0 {
+		t.Errorf("Redact annotation not removed")
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if strings.Contains(contents, "/Im2 Do") || !strings.Contains(contents, "/Im1 Do") {
+		t.Errorf("Wrong images drawn: %q", contents)
+	}
+	if _, xtype := page.Resources.GetXObjectByName("Im2"); xtype != model.XObjectTypeUndefined {
+		t.Errorf("Redacted image still in the resources")
+	}
+	if !strings.Contains(contents, "1.000000 0.000000 0.000000 rg") {
+		t.Errorf("Missing redaction box: %q", contents)
+	}
+	text, _ := extractMarks(t, page)
+	if text != "Name: John Doe, Esq.Phone:555-1234Form secret" {
+		t.Errorf("Wrong text %q", text)
+	}
+}
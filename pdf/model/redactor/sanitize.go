@@ -0,0 +1,333 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// maxActionDepth is the maximum length of the chains of actions (Next entries) checked for JavaScript.
+const maxActionDepth = 20
+
+// Sanitize returns a writer with the pages of the document loaded by reader, stripped of the information which is
+// not part of their visible content, for publishing the document:
+//   - the document information dictionary and the XMP metadata of the document, pages and XObjects,
+//   - the embedded files, file attachment and multimedia annotations,
+//   - the JavaScript and additional actions of the pages and annotations,
+//   - the content of the optional content groups (layers) hidden in the default configuration, and the
+//     annotations in them,
+//   - the page thumbnails and the private data of applications (PieceInfo).
+//
+// Only the pages are written, so the document catalog (with its outlines, forms, name trees and open action) and
+// the revisions of incremental updates are not carried over: the written document has a single revision.  The
+// pages of the reader are modified in place.
+func Sanitize(reader *model.PdfReader) (*model.PdfWriter, error) {
+	state, err := reader.GetOptionalContentState()
+	if err != nil {
+		return nil, err
+	}
+	s := &sanitizer{state: state, forms: map[*core.PdfObjectStream]bool{}}
+
+	w := model.NewPdfWriter()
+	for i, page := range reader.PageList {
+		err := s.sanitizePage(page)
+		if err != nil {
+			common.Log.Debug("Unable to sanitize page %d: %v", i+1, err)
+			return nil, err
+		}
+		err = w.AddPage(page)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &w, nil
+}
+
+// sanitizer removes the hidden content and the metadata from pages.
+type sanitizer struct {
+	state *model.OptionalContentState
+
+	// The Form XObjects already sanitized, which can be shared by pages.
+	forms map[*core.PdfObjectStream]bool
+}
+
+// sanitizePage removes the metadata, actions, hidden content and unsafe annotations from a page.
+func (s *sanitizer) sanitizePage(page *model.PdfPage) error {
+	page.Thumb = nil
+	page.AA = nil
+	page.Metadata = nil
+	page.PieceInfo = nil
+	dict := page.GetPageDict()
+	for _, key := range []core.PdfObjectName{"Thumb", "AA", "Metadata", "PieceInfo"} {
+		dict.Remove(key)
+	}
+
+	annotations := []*model.PdfAnnotation{}
+	for _, annot := range page.Annotations {
+		keep, err := s.sanitizeAnnotation(annot)
+		if err != nil {
+			return err
+		}
+		if keep {
+			annotations = append(annotations, annot)
+		}
+	}
+	if page.Annotations != nil {
+		page.Annotations = annotations
+	}
+
+	if page.Contents == nil {
+		return nil
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	operations, changed, err := s.sanitizeContent(contents, page.Resources, 0)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	err = page.SetContentStreams([]string{string(operations.Bytes())}, core.NewFlateEncoder())
+	if err != nil {
+		return err
+	}
+	_, err = contentstream.RemoveUnusedResources(page)
+	return err
+}
+
+// sanitizeAnnotation removes the JavaScript and additional actions of an annotation.  Returns false if the
+// annotation is to be removed: file attachment and multimedia annotations, and hidden annotations.
+func (s *sanitizer) sanitizeAnnotation(annot *model.PdfAnnotation) (bool, error) {
+	switch annot.GetContext().(type) {
+	case *model.PdfAnnotationFileAttachment, *model.PdfAnnotationSound, *model.PdfAnnotationMovie,
+		*model.PdfAnnotationScreen:
+		return false, nil
+	}
+	if annot.OC != nil {
+		visible, err := s.state.IsVisible(annot.OC)
+		if err != nil {
+			return false, err
+		}
+		if !visible {
+			return false, nil
+		}
+	}
+
+	dict, ok := core.TraceToDirectObject(annot.GetContainingPdfObject()).(*core.PdfObjectDictionary)
+	if !ok {
+		return true, nil
+	}
+	switch a := annot.GetContext().(type) {
+	case *model.PdfAnnotationLink:
+		if hasJavaScript(a.A, 0) {
+			a.A = nil
+			dict.Remove("A")
+		}
+	case *model.PdfAnnotationWidget:
+		if hasJavaScript(a.A, 0) {
+			a.A = nil
+			dict.Remove("A")
+		}
+		a.AA = nil
+		dict.Remove("AA")
+
+		// The actions of the form fields the widget belongs to.
+		field, _ := core.TraceToDirectObject(a.Parent).(*core.PdfObjectDictionary)
+		for depth := 0; field != nil && depth < maxActionDepth; depth++ {
+			field.Remove("AA")
+			field, _ = core.TraceToDirectObject(field.Get("Parent")).(*core.PdfObjectDictionary)
+		}
+	}
+	return true, nil
+}
+
+// hasJavaScript returns true if an action, or an action following it, is a JavaScript action or a rendition action
+// with JavaScript.
+func hasJavaScript(action core.PdfObject, depth int) bool {
+	if depth >= maxActionDepth {
+		common.Log.Debug("Actions nested too deep")
+		return true
+	}
+	switch t := core.TraceToDirectObject(action).(type) {
+	case *core.PdfObjectDictionary:
+		if s, ok := core.TraceToDirectObject(t.Get("S")).(*core.PdfObjectName); ok && *s == "JavaScript" {
+			return true
+		}
+		if t.Get("JS") != nil {
+			return true
+		}
+		return hasJavaScript(t.Get("Next"), depth+1)
+	case *core.PdfObjectArray:
+		for _, next := range *t {
+			if hasJavaScript(next, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sanitizeContent returns the operations of a content stream without the hidden optional content: the marked
+// content sequences of hidden groups and the XObjects with hidden OC entries.  The Form XObjects drawn are
+// sanitized in place, and the Metadata and PieceInfo entries removed from the XObjects.  Returns true if the
+// content stream was changed.
+func (s *sanitizer) sanitizeContent(contents string, resources *model.PdfPageResources,
+	depth int) (contentstream.ContentStreamOperations, bool, error) {
+	operations, err := contentstream.NewContentStreamParser(contents).Parse()
+	if err != nil {
+		return nil, false, err
+	}
+
+	changed := false
+	// The nesting level of marked content, and the level of the hidden sequence being removed (0 if none).
+	level, hiddenLevel := 0, 0
+	result := contentstream.ContentStreamOperations{}
+	for _, op := range *operations {
+		switch op.Operand {
+		case "BMC", "BDC":
+			level++
+			if hiddenLevel == 0 && op.Operand == "BDC" && len(op.Params) == 2 {
+				tag, _ := op.Params[0].(*core.PdfObjectName)
+				if tag != nil {
+					visible, err := s.state.IsMarkedContentVisible(*tag, op.Params[1], resources)
+					if err != nil {
+						common.Log.Debug("Invalid optional content: %v", err)
+					} else if !visible {
+						hiddenLevel = level
+					}
+				}
+			}
+		case "EMC":
+			if level > 0 {
+				level--
+			}
+			if hiddenLevel > 0 && level < hiddenLevel {
+				hiddenLevel = 0
+				changed = true
+				continue
+			}
+		case "Do":
+			if hiddenLevel > 0 || len(op.Params) != 1 || resources == nil {
+				break
+			}
+			name, ok := op.Params[0].(*core.PdfObjectName)
+			if !ok {
+				break
+			}
+			visible, err := s.sanitizeXObject(*name, resources, depth)
+			if err != nil {
+				return nil, false, err
+			}
+			if !visible {
+				changed = true
+				continue
+			}
+		}
+
+		// The graphics state is kept balanced when removing hidden content.
+		if hiddenLevel > 0 && op.Operand != "q" && op.Operand != "Q" {
+			changed = true
+			continue
+		}
+		result = append(result, op)
+	}
+	return result, changed, nil
+}
+
+// sanitizeXObject removes the metadata of an XObject and the hidden content of a Form XObject.  Returns false if
+// the XObject is hidden.
+func (s *sanitizer) sanitizeXObject(name core.PdfObjectName, resources *model.PdfPageResources,
+	depth int) (bool, error) {
+	stream, xtype := resources.GetXObjectByName(name)
+	if stream == nil {
+		return true, nil
+	}
+	if oc := stream.PdfObjectDictionary.Get("OC"); oc != nil {
+		visible, err := s.state.IsVisible(oc)
+		if err != nil {
+			return false, err
+		}
+		if !visible {
+			return false, nil
+		}
+	}
+	stream.PdfObjectDictionary.Remove("Metadata")
+	stream.PdfObjectDictionary.Remove("PieceInfo")
+
+	if xtype != model.XObjectTypeForm || s.forms[stream] {
+		return true, nil
+	}
+	s.forms[stream] = true
+	if depth >= contentstream.MaxFormDepth {
+		common.Log.Debug("Form XObjects nested too deep")
+		return false, errors.New("Form XObject recursion limit exceeded")
+	}
+
+	xform, err := model.NewXObjectFormFromStream(stream)
+	if err != nil {
+		return false, err
+	}
+	content, err := xform.GetContentStream()
+	if err != nil {
+		return false, err
+	}
+	// Forms without resources use the resources of the content stream drawing them.
+	formResources := resources
+	if xform.Resources != nil {
+		formResources = xform.Resources
+	}
+	operations, changed, err := s.sanitizeContent(string(content), formResources, depth+1)
+	if err != nil || !changed {
+		return true, err
+	}
+
+	// The form stream is replaced in place, as it is sanitized the same way wherever it is used.
+	formStream, err := core.MakeStream(operations.Bytes(), core.NewFlateEncoder())
+	if err != nil {
+		return false, err
+	}
+	for _, key := range []core.PdfObjectName{"Filter", "DecodeParms", "Length"} {
+		stream.PdfObjectDictionary.Remove(key)
+		if val := formStream.PdfObjectDictionary.Get(key); val != nil {
+			stream.PdfObjectDictionary.Set(key, val)
+		}
+	}
+	stream.Stream = formStream.Stream
+
+	if xform.Resources == nil {
+		return true, nil
+	}
+	// The resources of the hidden content are removed from a copy of the form resources, which can be shared.
+	resourcesDict, ok := core.TraceToDirectObject(stream.PdfObjectDictionary.Get("Resources")).(*core.PdfObjectDictionary)
+	if !ok {
+		return true, nil
+	}
+	copied := core.MakeDict()
+	copied.Merge(resourcesDict)
+	formPage := model.NewPdfPage()
+	formPage.Resources, err = model.NewPdfPageResourcesFromDict(copied)
+	if err != nil {
+		return false, err
+	}
+	err = formPage.SetContentStreams([]string{string(operations.Bytes())}, nil)
+	if err != nil {
+		return false, err
+	}
+	_, err = contentstream.RemoveUnusedResources(formPage)
+	if err != nil {
+		return false, err
+	}
+	stream.PdfObjectDictionary.Set("Resources", formPage.Resources.ToPdfObject())
+	return true, nil
+}
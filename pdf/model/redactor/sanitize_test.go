@@ -0,0 +1,129 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// makeTestStream returns a stream object with its data.
+func makeTestStream(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// makeTestPdfWithUpdate returns a document with the objects, the second being the document information dictionary,
+// and an incremental update replacing the object numbered 1 (the catalog).
+func makeTestPdfWithUpdate(objects []string, catalogUpdate string) []byte {
+	buf := bytes.NewBuffer(testutils.MakePdfWithTrailer(objects, "/Info 2 0 R"))
+	xrefOffset := bytes.LastIndex(buf.Bytes(), []byte("\nxref\n")) + 1
+
+	updateOffset := buf.Len()
+	buf.WriteString(fmt.Sprintf("1 0 obj\n%s\nendobj\n", catalogUpdate))
+	updateXrefOffset := buf.Len()
+	buf.WriteString(fmt.Sprintf("xref\n1 1\n%.10d 00000 n\r\n", updateOffset))
+	buf.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R /Info 2 0 R /Prev %d >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, xrefOffset, updateXrefOffset))
+	return buf.Bytes()
+}
+
+func TestSanitize(t *testing.T) {
+	contents := "BT /F1 12 Tf 100 700 Td (Visible text) Tj ET\n" +
+		"/OC /L1 BDC q BT /F1 12 Tf 100 600 Td (Hidden text) Tj ET Q EMC\n" +
+		"/OC /L2 BDC BT /F1 12 Tf 100 500 Td (Layer text) Tj ET EMC\n" +
+		"q 50 0 0 50 300 300 cm /Im1 Do Q\n" +
+		"q 1 0 0 1 100 400 cm /Fm1 Do Q\n"
+	objects := []string{
+		// 1: catalog, replaced by the update.
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		// 2: document information.
+		"<< /Title (Secret title) /Author (Jane Roe) >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 3 0 R /MediaBox [0 0 612 792] /Contents 5 0 R /Resources 6 0 R " +
+			"/Metadata 14 0 R /Thumb 15 0 R /AA << /O 16 0 R >> /Annots [17 0 R 18 0 R 19 0 R] >>",
+		makeTestStream("", contents),
+		"<< /Font << /F1 7 0 R >> /XObject << /Im1 8 0 R /Fm1 9 0 R >> /Properties << /L1 10 0 R /L2 11 0 R >> >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		makeTestStream("/Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray "+
+			"/BitsPerComponent 8 /OC 10 0 R", "\x80"),
+		makeTestStream("/Type /XObject /Subtype /Form /BBox [0 0 200 20] /Resources 6 0 R /Metadata 14 0 R",
+			"/OC /L1 BDC BT /F1 10 Tf 0 5 Td (Hidden form text) Tj ET EMC BT /F1 10 Tf 0 5 Td (Form text) Tj ET"),
+		"<< /Type /OCG /Name (Hidden layer) >>",
+		"<< /Type /OCG /Name (Visible layer) >>",
+		// 12: embedded file.
+		makeTestStream("/Type /EmbeddedFile", "attached data"),
+		"<< /Type /Filespec /F (data.txt) /EF << /F 12 0 R >> >>",
+		makeTestStream("/Type /Metadata /Subtype /XML", "<x:xmpmeta>secret</x:xmpmeta>"),
+		makeTestStream("/Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8", "\x80"),
+		"<< /S /JavaScript /JS (app.alert('open')) >>",
+		"<< /Type /Annot /Subtype /FileAttachment /Rect [0 0 10 10] /FS 13 0 R >>",
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (http://example.com) " +
+			"/Next 16 0 R >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [20 20 30 30] /OC 10 0 R /A << /S /URI /URI (http://example.com) >> >>",
+	}
+	catalog := "<< /Type /Catalog /Pages 3 0 R /Metadata 14 0 R /OpenAction 16 0 R " +
+		"/Names << /EmbeddedFiles << /Names [(data.txt) 13 0 R] >> /JavaScript << /Names [(js) 16 0 R] >> >> " +
+		"/OCProperties << /OCGs [10 0 R 11 0 R] /D << /OFF [10 0 R] >> >> >>"
+	data := makeTestPdfWithUpdate(objects, catalog)
+
+	reader, err := model.NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	w, err := Sanitize(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	err = w.Write(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	output := buf.String()
+
+	if strings.Count(output, "%%EOF") != 1 || strings.Contains(output, "/Prev") {
+		t.Errorf("More than one revision written")
+	}
+	for _, removed := range []string{"Secret title", "Jane Roe", "/Metadata", "/Thumb", "/JavaScript", "/JS",
+		"/OpenAction", "/EmbeddedFile", "/FileAttachment", "/OCProperties", "Hidden layer", "/AA"} {
+		if strings.Contains(output, removed) {
+			t.Errorf("%s not removed", removed)
+		}
+	}
+
+	sanitized, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err := sanitized.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	text, _ := extractMarks(t, page)
+	// The text is followed by the watermark of unlicensed copies.
+	if !strings.HasPrefix(text, "Visible textLayer textForm text") || strings.Contains(text, "Hidden") {
+		t.Errorf("Wrong text %q", text)
+	}
+	if _, xtype := page.Resources.GetXObjectByName("Im1"); xtype != model.XObjectTypeUndefined {
+		t.Errorf("Hidden image still in the resources")
+	}
+	if len(page.Annotations) != 1 {
+		t.Fatalf("Wrong annotations %v", page.Annotations)
+	}
+	link, ok := page.Annotations[0].GetContext().(*model.PdfAnnotationLink)
+	if !ok || link.A != nil {
+		t.Errorf("Link with JavaScript action not cleaned: %v", page.Annotations[0])
+	}
+	if _, ok := core.TraceToDirectObject(page.GetPageDict().Get("Resources")).(*core.PdfObjectDictionary); !ok {
+		t.Errorf("Missing resources")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Maximum number of entries of a bfchar section of a CMap.
+const maxBfcharEntries = 100
+
+// fontUsage is the set of font dictionaries of redacted glyphs.
+type fontUsage map[*core.PdfObjectDictionary]bool
+
+// add adds a font to the set if it has a ToUnicode mapping, which can reveal the redacted text.
+func (fonts fontUsage) add(font *core.PdfObjectDictionary) {
+	if font.Get("ToUnicode") != nil {
+		fonts[font] = true
+	}
+}
+
+// scrubToUnicode replaces the ToUnicode CMaps of the fonts by CMaps only mapping the character codes still shown
+// in the pages.
+func (fonts fontUsage) scrubToUnicode(pages []*model.PdfPage) error {
+	// The text of the character codes shown with each font.
+	used := map[*core.PdfObjectDictionary]map[string]string{}
+	for font := range fonts {
+		used[font] = map[string]string{}
+	}
+	for _, page := range pages {
+		e, err := extractor.New(page)
+		if err != nil {
+			return err
+		}
+		marks, err := e.ExtractTextMarks()
+		if err != nil {
+			return err
+		}
+		for _, mark := range marks {
+			font := getMarkFont(page.Resources, mark)
+			if font == nil || !fonts[font] {
+				continue
+			}
+			used[font][string(mark.CharCodes)] = mark.Text
+		}
+	}
+
+	for font, codes := range used {
+		stream, err := core.MakeStream(makeToUnicode(codes), core.NewFlateEncoder())
+		if err != nil {
+			return err
+		}
+		font.Set("ToUnicode", stream)
+	}
+	return nil
+}
+
+// makeToUnicode returns a ToUnicode CMap mapping the character codes to their text.
+func makeToUnicode(codes map[string]string) []byte {
+	keys := []string{}
+	codeLen := 1
+	for code, text := range codes {
+		if text == "" {
+			continue
+		}
+		keys = append(keys, code)
+		if len(code) > codeLen {
+			codeLen = len(code)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n")
+	buf.WriteString("12 dict begin\n")
+	buf.WriteString("begincmap\n")
+	buf.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	buf.WriteString("/CMapName /Adobe-Identity-UCS def\n")
+	buf.WriteString("/CMapType 2 def\n")
+	if codeLen == 1 {
+		buf.WriteString("1 begincodespacerange\n<00> <FF>\nendcodespacerange\n")
+	} else {
+		buf.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	}
+	for i := 0; i < len(keys); i += maxBfcharEntries {
+		chunk := keys[i:]
+		if len(chunk) > maxBfcharEntries {
+			chunk = chunk[:maxBfcharEntries]
+		}
+		fmt.Fprintf(&buf, "%d beginbfchar\n", len(chunk))
+		for _, code := range chunk {
+			fmt.Fprintf(&buf, "<%X> <", []byte(code))
+			for _, v := range utf16.Encode([]rune(codes[code])) {
+				fmt.Fprintf(&buf, "%04X", v)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+	}
+	buf.WriteString("endcmap\n")
+	buf.WriteString("CMapName currentdict /CMap defineresource pop\n")
+	buf.WriteString("end\nend\n")
+	return buf.Bytes()
+}
@@ -286,6 +286,79 @@ func (r *PdfPageResources) SetColorspaceByName(keyName PdfObjectName, cs PdfColo
 	return nil
 }
 
+// GetDefaultGray returns the DefaultGray colorspace, which replaces DeviceGray for content drawn with the resources.
+func (r *PdfPageResources) GetDefaultGray() (PdfColorspace, bool) {
+	return r.GetColorspaceByName("DefaultGray")
+}
+
+// SetDefaultGray sets the DefaultGray colorspace (typically ICCBased) which replaces DeviceGray.
+// The colorspace must have a single color component.
+func (r *PdfPageResources) SetDefaultGray(cs PdfColorspace) error {
+	return r.setDefaultColorspace("DefaultGray", 1, cs)
+}
+
+// GetDefaultRGB returns the DefaultRGB colorspace, which replaces DeviceRGB for content drawn with the resources.
+func (r *PdfPageResources) GetDefaultRGB() (PdfColorspace, bool) {
+	return r.GetColorspaceByName("DefaultRGB")
+}
+
+// SetDefaultRGB sets the DefaultRGB colorspace (typically ICCBased) which replaces DeviceRGB.
+// The colorspace must have 3 color components.
+func (r *PdfPageResources) SetDefaultRGB(cs PdfColorspace) error {
+	return r.setDefaultColorspace("DefaultRGB", 3, cs)
+}
+
+// GetDefaultCMYK returns the DefaultCMYK colorspace, which replaces DeviceCMYK for content drawn with the resources.
+func (r *PdfPageResources) GetDefaultCMYK() (PdfColorspace, bool) {
+	return r.GetColorspaceByName("DefaultCMYK")
+}
+
+// SetDefaultCMYK sets the DefaultCMYK colorspace (typically ICCBased) which replaces DeviceCMYK.
+// The colorspace must have 4 color components.
+func (r *PdfPageResources) SetDefaultCMYK(cs PdfColorspace) error {
+	return r.setDefaultColorspace("DefaultCMYK", 4, cs)
+}
+
+func (r *PdfPageResources) setDefaultColorspace(keyName PdfObjectName, numComponents int, cs PdfColorspace) error {
+	switch cs.(type) {
+	case *PdfColorspaceDeviceGray, *PdfColorspaceDeviceRGB, *PdfColorspaceDeviceCMYK,
+		*PdfColorspaceSpecialPattern, *PdfColorspaceSpecialIndexed:
+		common.Log.Debug("ERROR: Invalid %s colorspace: %T", keyName, cs)
+		return ErrTypeError
+	}
+	if cs.GetNumComponents() != numComponents {
+		common.Log.Debug("ERROR: %s colorspace with %d components (expected %d)", keyName, cs.GetNumComponents(),
+			numComponents)
+		return ErrRangeError
+	}
+
+	return r.SetColorspaceByName(keyName, cs)
+}
+
+// ResolveDeviceColorspace returns the default colorspace (DefaultGray, DefaultRGB, DefaultCMYK) replacing the device
+// colorspace cs if defined in the resources, otherwise returns cs unchanged.  Colors specified in device colorspaces
+// are interpreted in the corresponding default colorspace when defined.
+func (r *PdfPageResources) ResolveDeviceColorspace(cs PdfColorspace) PdfColorspace {
+	if r == nil {
+		return cs
+	}
+
+	var def PdfColorspace
+	var has bool
+	switch cs.(type) {
+	case *PdfColorspaceDeviceGray:
+		def, has = r.GetDefaultGray()
+	case *PdfColorspaceDeviceRGB:
+		def, has = r.GetDefaultRGB()
+	case *PdfColorspaceDeviceCMYK:
+		def, has = r.GetDefaultCMYK()
+	}
+	if !has || def == nil || def.GetNumComponents() != cs.GetNumComponents() {
+		return cs
+	}
+	return def
+}
+
 // Check if an XObject with a specified keyName is defined.
 func (r *PdfPageResources) HasXObjectByName(keyName PdfObjectName) bool {
 	obj, _ := r.GetXObjectByName(keyName)
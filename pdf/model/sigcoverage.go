@@ -0,0 +1,696 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// SignatureStatus is the assessment of the modifications of a signed document.
+type SignatureStatus int
+
+const (
+	// The signature covers the whole file.
+	SignatureIntact SignatureStatus = iota
+	// The document was updated after signing, with changes allowed by the permissions of the signatures (MDP).
+	SignatureAllowedChanges
+	// The document was updated after signing, with changes not allowed.
+	SignatureTampered
+	// The ByteRange of the signature does not cover a revision of the file except the signature value.
+	SignatureInvalidByteRange
+)
+
+// String returns the name of the status.
+func (status SignatureStatus) String() string {
+	switch status {
+	case SignatureIntact:
+		return "Intact"
+	case SignatureAllowedChanges:
+		return "AllowedChanges"
+	case SignatureTampered:
+		return "Tampered"
+	case SignatureInvalidByteRange:
+		return "InvalidByteRange"
+	}
+	return fmt.Sprintf("SignatureStatus(%d)", int(status))
+}
+
+// SignatureChangeKind is the kind of an object changed by an update made after signing.
+type SignatureChangeKind string
+
+const (
+	// Document security store (validation data), allowed at all permission levels.
+	SignatureChangeDSS SignatureChangeKind = "DSS"
+	// Document timestamps, allowed at all permission levels.
+	SignatureChangeTimestamp SignatureChangeKind = "Timestamp"
+	// Document information dictionary and metadata stream, allowed at all permission levels.
+	SignatureChangeMetadata SignatureChangeKind = "Metadata"
+	// Signature fields and signatures, allowed from permission level 2.
+	SignatureChangeSignature SignatureChangeKind = "Signature"
+	// Form fields values and appearances, allowed from permission level 2.
+	SignatureChangeFormField SignatureChangeKind = "FormField"
+	// Annotations other than widgets, allowed at permission level 3.
+	SignatureChangeAnnotation SignatureChangeKind = "Annotation"
+	// Pages, contents, resources and other objects, never allowed.
+	SignatureChangeOther SignatureChangeKind = "Other"
+)
+
+// minPermission returns the lowest permission level (P of DocMDP) allowing the kind of change, 4 if never allowed.
+func (kind SignatureChangeKind) minPermission() int64 {
+	switch kind {
+	case SignatureChangeDSS, SignatureChangeTimestamp, SignatureChangeMetadata:
+		return 1
+	case SignatureChangeSignature, SignatureChangeFormField:
+		return 2
+	case SignatureChangeAnnotation:
+		return 3
+	}
+	return 4
+}
+
+// SignatureChange is an object added, modified or removed by an update made after signing.
+type SignatureChange struct {
+	ObjectNumber int
+	Kind         SignatureChangeKind
+
+	// The object was added or removed, otherwise modified.
+	Added, Removed bool
+
+	// Fully qualified name of the form field of the object, if any.
+	Field string
+
+	Allowed bool
+}
+
+// SignatureCoverage is the analysis of the part of a document covered by a signature and of the updates made
+// after signing.  The signature value itself is not verified.
+type SignatureCoverage struct {
+	// Fully qualified name of the signature field.
+	Signature string
+
+	// The ByteRange of the signature, and the end of the signed revision in the file.
+	ByteRange []int
+	End       int
+
+	// The signature is a certification signature (DocMDP).
+	Certification bool
+
+	// The permission level (P of DocMDP) for changes made after signing: 1 for no changes except DSS and document
+	// timestamps, 2 for filling forms and signing, 3 for annotations too.  The level of the certification signature
+	// of the document, lowered by the field lock of the signature if any, 3 if none.
+	Permission int64
+
+	Changes []SignatureChange
+	Status  SignatureStatus
+}
+
+// GetSignatureCoverage analyzes the signed revisions of the document: checks that the ByteRange of each signature
+// covers the file from its beginning to the end of a revision, except the signature value, and classifies the
+// changes made by later incremental updates as allowed or not by the permissions of the signatures (DocMDP and
+// FieldMDP), i.e. DSS and document timestamps, filling forms and signing, and annotations.  The signature values
+// are not verified.
+func (this *PdfReader) GetSignatureCoverage() ([]*SignatureCoverage, error) {
+	coverages := []*SignatureCoverage{}
+	if this.AcroForm == nil {
+		return coverages, nil
+	}
+
+	var buf bytes.Buffer
+	_, err := this.parser.WriteOriginal(&buf)
+	if err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+
+	// The certification signature of the document and its permissions.
+	var certSig *PdfObjectDictionary
+	certP := int64(3)
+	if perms, ok := traceDirect(this, this.catalog.Get("Perms")).(*PdfObjectDictionary); ok {
+		if sig, ok := traceDirect(this, perms.Get("DocMDP")).(*PdfObjectDictionary); ok {
+			certSig = sig
+			certP = getDocMDPPermission(this, sig)
+		}
+	}
+
+	for _, sigField := range this.AcroForm.AllFields() {
+		if !sigField.IsSigned() {
+			continue
+		}
+		sig := TraceToDirectObject(sigField.V).(*PdfObjectDictionary)
+		if name, ok := TraceToDirectObject(sig.Get("Type")).(*PdfObjectName); ok && *name == "DocTimeStamp" {
+			// Document timestamps do not restrict changes.
+			continue
+		}
+
+		coverage := &SignatureCoverage{
+			Signature:     sigField.GetFullName(),
+			Certification: sig == certSig,
+			Permission:    certP,
+		}
+		coverages = append(coverages, coverage)
+
+		lock, err := sigField.GetLock()
+		if err != nil {
+			return nil, err
+		}
+		if lock != nil && lock.P > 0 && lock.P < coverage.Permission {
+			coverage.Permission = lock.P
+		}
+
+		coverage.ByteRange, coverage.End = checkByteRange(data, sig)
+		if coverage.End < 0 {
+			common.Log.Debug("Invalid ByteRange of signature %s", coverage.Signature)
+			coverage.Status = SignatureInvalidByteRange
+			continue
+		}
+		if coverage.End == len(data) {
+			coverage.Status = SignatureIntact
+			continue
+		}
+
+		revision, err := NewPdfReader(bytes.NewReader(data[:coverage.End]))
+		if err != nil {
+			common.Log.Debug("ERROR: Unable to load signed revision: %v", err)
+			coverage.Status = SignatureInvalidByteRange
+			continue
+		}
+		sigNum, _ := objectNumber(sigField.V)
+		diff := newRevisionDiff(revision, this)
+		coverage.Changes = diff.changes(coverage.Signature, sigNum, lock, coverage.Permission)
+		coverage.Status = SignatureAllowedChanges
+		for _, change := range coverage.Changes {
+			if !change.Allowed {
+				coverage.Status = SignatureTampered
+				break
+			}
+		}
+	}
+
+	return coverages, nil
+}
+
+// getDocMDPPermission returns the permission level of the DocMDP transform of a certification signature, 2 if not
+// specified.
+func getDocMDPPermission(reader *PdfReader, sig *PdfObjectDictionary) int64 {
+	refs, ok := traceDirect(reader, sig.Get("Reference")).(*PdfObjectArray)
+	if !ok {
+		return 2
+	}
+	for _, obj := range *refs {
+		ref, ok := traceDirect(reader, obj).(*PdfObjectDictionary)
+		if !ok {
+			continue
+		}
+		if method, ok := traceDirect(reader, ref.Get("TransformMethod")).(*PdfObjectName); !ok || *method != "DocMDP" {
+			continue
+		}
+		if params, ok := traceDirect(reader, ref.Get("TransformParams")).(*PdfObjectDictionary); ok {
+			if p, ok := traceDirect(reader, params.Get("P")).(*PdfObjectInteger); ok && *p >= 1 && *p <= 3 {
+				return int64(*p)
+			}
+		}
+	}
+	return 2
+}
+
+// checkByteRange checks that the ByteRange of the signature covers the file from its beginning to the end of a
+// revision (%%EOF), except the signature value (Contents hex string).  Returns the byte range and the end of the
+// signed revision, or -1 if the ByteRange is invalid.
+func checkByteRange(data []byte, sig *PdfObjectDictionary) ([]int, int) {
+	arr, ok := TraceToDirectObject(sig.Get("ByteRange")).(*PdfObjectArray)
+	if !ok {
+		return nil, -1
+	}
+	vals, err := arr.ToIntegerArray()
+	if err != nil || len(vals) != 4 {
+		return vals, -1
+	}
+	if vals[0] != 0 || vals[1] < 0 || vals[2] < vals[1] || vals[3] < 0 || vals[2]+vals[3] > len(data) {
+		return vals, -1
+	}
+	end := vals[2] + vals[3]
+	if !bytes.HasSuffix(bytes.TrimRight(data[:end], " \t\r\n\f\x00"), []byte("%%EOF")) {
+		return vals, -1
+	}
+
+	// The gap is the signature value, and nothing else.
+	contents, ok := TraceToDirectObject(sig.Get("Contents")).(*PdfObjectString)
+	if !ok {
+		return vals, -1
+	}
+	gap := data[vals[1]:vals[2]]
+	if len(gap) < 2 || gap[0] != '<' || gap[len(gap)-1] != '>' {
+		return vals, -1
+	}
+	digits := bytes.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\f' {
+			return -1
+		}
+		return r
+	}, gap[1:len(gap)-1])
+	if len(digits)%2 != 0 {
+		digits = append(digits, '0')
+	}
+	value, err := hex.DecodeString(string(digits))
+	if err != nil || string(value) != string(*contents) {
+		return vals, -1
+	}
+	return vals, end
+}
+
+// revisionDiff compares a signed revision of a document with the current one.
+type revisionDiff struct {
+	signed, current *PdfReader
+
+	// The kinds of the objects of the current (or signed) revision, from the objects referencing them.
+	kinds map[int]SignatureChangeKind
+}
+
+func newRevisionDiff(signed, current *PdfReader) *revisionDiff {
+	return &revisionDiff{signed: signed, current: current}
+}
+
+// changes returns the objects changed in the current revision, for a signature with the field name, the number of
+// the signature dictionary object, the field lock and permission level.
+func (diff *revisionDiff) changes(sigName string, sigNum int, lock *PdfSignatureFieldLock, permission int64) []SignatureChange {
+	diff.kinds = map[int]SignatureChangeKind{}
+	diff.collectKinds(diff.signed)
+	diff.collectKinds(diff.current)
+
+	nums := map[int]bool{}
+	for _, num := range diff.signed.GetObjectNums() {
+		nums[num] = true
+	}
+	for _, num := range diff.current.GetObjectNums() {
+		nums[num] = true
+	}
+	sorted := []int{}
+	for num := range nums {
+		sorted = append(sorted, num)
+	}
+	sort.Ints(sorted)
+
+	changes := []SignatureChange{}
+	for _, num := range sorted {
+		oldObj := lookupObject(diff.signed, num)
+		newObj := lookupObject(diff.current, num)
+		if oldObj == nil && newObj == nil {
+			continue
+		}
+		if oldObj != nil && newObj != nil && shallowObjectsEqual(oldObj, newObj) {
+			continue
+		}
+		obj := newObj
+		if obj == nil {
+			obj = oldObj
+		}
+		if name, ok := getDictType(obj); ok && (name == "XRef" || name == "ObjStm") {
+			// Cross-reference and object streams are containers of other objects.
+			continue
+		}
+
+		change := SignatureChange{ObjectNumber: num, Added: oldObj == nil, Removed: newObj == nil}
+		change.Kind = diff.kindOf(num, oldObj, newObj)
+		reader := diff.current
+		if newObj == nil {
+			reader = diff.signed
+		}
+		change.Field = diff.fieldName(reader, obj)
+		change.Allowed = change.Kind.minPermission() <= permission
+		if num == sigNum || change.Field != "" && (change.Field == sigName || lock != nil && lock.Locks(change.Field)) {
+			// The signature, its field and the fields locked by the signature are not to be changed.
+			change.Allowed = false
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// kindOf returns the kind of the object changed between the revisions.
+func (diff *revisionDiff) kindOf(num int, oldObj, newObj PdfObject) SignatureChangeKind {
+	trailer := diff.current.parser.GetTrailer()
+	if objNum, ok := objectNumber(trailer.Get("Info")); ok && objNum == num {
+		return SignatureChangeMetadata
+	}
+	if objNum, ok := objectNumber(trailer.Get("Root")); ok && objNum == num {
+		return diff.keysKind(oldObj, newObj, map[PdfObjectName]SignatureChangeKind{
+			"DSS":        SignatureChangeDSS,
+			"Extensions": SignatureChangeDSS,
+			"Metadata":   SignatureChangeMetadata,
+			"AcroForm":   SignatureChangeFormField,
+		})
+	}
+	if objNum, ok := objectNumber(diff.current.catalog.Get("AcroForm")); ok && objNum == num {
+		return diff.keysKind(oldObj, newObj, map[PdfObjectName]SignatureChangeKind{
+			"Fields":          "",
+			"SigFlags":        SignatureChangeSignature,
+			"DR":              SignatureChangeFormField,
+			"DA":              SignatureChangeFormField,
+			"NeedAppearances": SignatureChangeFormField,
+		})
+	}
+	if name, ok := getDictType(newObj); ok && name == "Page" {
+		if name, ok := getDictType(oldObj); ok && name == "Page" {
+			return diff.keysKind(oldObj, newObj, map[PdfObjectName]SignatureChangeKind{"Annots": ""})
+		}
+	}
+	if kind, has := diff.kinds[num]; has {
+		return kind
+	}
+	return SignatureChangeOther
+}
+
+// keysKind returns the kind of the changes of the dictionary, from the kinds of its changed entries: the kinds of
+// the objects added to or removed from the arrays of the entries of kind "", and the most restrictive kind.
+func (diff *revisionDiff) keysKind(oldObj, newObj PdfObject, keyKinds map[PdfObjectName]SignatureChangeKind) SignatureChangeKind {
+	oldDict, _ := TraceToDirectObject(oldObj).(*PdfObjectDictionary)
+	newDict, _ := TraceToDirectObject(newObj).(*PdfObjectDictionary)
+	if oldDict == nil || newDict == nil {
+		return SignatureChangeOther
+	}
+
+	kind := SignatureChangeKind("")
+	update := func(k SignatureChangeKind) {
+		if kind == "" || k.minPermission() > kind.minPermission() {
+			kind = k
+		}
+	}
+	keys := map[PdfObjectName]bool{}
+	for _, key := range oldDict.Keys() {
+		keys[key] = true
+	}
+	for _, key := range newDict.Keys() {
+		keys[key] = true
+	}
+	for key := range keys {
+		oldVal, newVal := oldDict.Get(key), newDict.Get(key)
+		if shallowObjectsEqual(oldVal, newVal) {
+			continue
+		}
+		keyKind, has := keyKinds[key]
+		if !has {
+			return SignatureChangeOther
+		}
+		if keyKind != "" {
+			update(keyKind)
+			continue
+		}
+
+		// The objects added to or removed from the array.
+		oldNums, newNums := arrayObjectNumbers(oldVal), arrayObjectNumbers(newVal)
+		for num := range oldNums {
+			if !newNums[num] {
+				update(diff.objectKind(num))
+			}
+		}
+		for num := range newNums {
+			if !oldNums[num] {
+				update(diff.objectKind(num))
+			}
+		}
+	}
+	if kind == "" {
+		// Reordered.
+		return SignatureChangeFormField
+	}
+	return kind
+}
+
+// objectKind returns the kind of an object referenced from an array of the catalog, AcroForm or a page.
+func (diff *revisionDiff) objectKind(num int) SignatureChangeKind {
+	if kind, has := diff.kinds[num]; has {
+		return kind
+	}
+	return SignatureChangeOther
+}
+
+// collectKinds sets the kinds of the objects of the revision from their dictionaries: signatures, form fields,
+// widgets and annotations, and of the objects referenced from them (e.g. appearance streams) and from the DSS.
+func (diff *revisionDiff) collectKinds(reader *PdfReader) {
+	// The objects with a kind of their own.
+	owners := map[int]SignatureChangeKind{}
+	for _, num := range reader.GetObjectNums() {
+		if _, has := diff.kinds[num]; has {
+			continue
+		}
+		if kind := getObjectKind(reader, lookupObject(reader, num)); kind != "" {
+			diff.kinds[num] = kind
+			owners[num] = kind
+		}
+	}
+
+	// The objects referenced from them, in the order of precedence of the kinds.
+	visited := map[int]bool{}
+	mark := func(obj PdfObject, kind SignatureChangeKind) {
+		walkObjectNumbers(reader, obj, visited, func(num int) {
+			if _, has := diff.kinds[num]; !has {
+				diff.kinds[num] = kind
+			}
+		})
+	}
+	mark(reader.catalog.Get("DSS"), SignatureChangeDSS)
+	mark(reader.catalog.Get("Metadata"), SignatureChangeMetadata)
+	for _, kind := range []SignatureChangeKind{SignatureChangeTimestamp, SignatureChangeSignature,
+		SignatureChangeFormField, SignatureChangeAnnotation} {
+		nums := []int{}
+		for num, k := range owners {
+			if k == kind {
+				nums = append(nums, num)
+			}
+		}
+		sort.Ints(nums)
+		for _, num := range nums {
+			visited[num] = false
+			mark(lookupObject(reader, num), kind)
+		}
+	}
+}
+
+// getObjectKind returns the kind of an object from its dictionary, or "" if not a signature, form field, widget or
+// annotation.
+func getObjectKind(reader *PdfReader, obj PdfObject) SignatureChangeKind {
+	dict, ok := traceDirect(reader, obj).(*PdfObjectDictionary)
+	if !ok {
+		return ""
+	}
+	if name, ok := traceDirect(reader, dict.Get("Type")).(*PdfObjectName); ok {
+		switch *name {
+		case "DocTimeStamp":
+			return SignatureChangeTimestamp
+		case "Sig":
+			return SignatureChangeSignature
+		}
+	}
+
+	// Form fields and widgets, from the type of the field.
+	if subtype, ok := traceDirect(reader, dict.Get("Subtype")).(*PdfObjectName); (ok && *subtype == "Widget") ||
+		dict.Get("FT") != nil || dict.Get("T") != nil {
+		for field, depth := dict, 0; field != nil && depth < 32; depth++ {
+			if ft, ok := traceDirect(reader, field.Get("FT")).(*PdfObjectName); ok {
+				if *ft != "Sig" {
+					return SignatureChangeFormField
+				}
+				if v, ok := traceDirect(reader, field.Get("V")).(*PdfObjectDictionary); ok {
+					if name, ok := traceDirect(reader, v.Get("Type")).(*PdfObjectName); ok && *name == "DocTimeStamp" {
+						return SignatureChangeTimestamp
+					}
+				}
+				return SignatureChangeSignature
+			}
+			field, _ = traceDirect(reader, field.Get("Parent")).(*PdfObjectDictionary)
+		}
+		return SignatureChangeFormField
+	}
+
+	if dict.Get("Subtype") != nil && dict.Get("Rect") != nil {
+		return SignatureChangeAnnotation
+	}
+	return ""
+}
+
+// fieldName returns the fully qualified name of the form field of a field or widget object, or "" if none.
+func (diff *revisionDiff) fieldName(reader *PdfReader, obj PdfObject) string {
+	dict, ok := traceDirect(reader, obj).(*PdfObjectDictionary)
+	if !ok {
+		return ""
+	}
+	if subtype, ok := traceDirect(reader, dict.Get("Subtype")).(*PdfObjectName); !(ok && *subtype == "Widget") &&
+		dict.Get("FT") == nil && dict.Get("T") == nil {
+		return ""
+	}
+	name := ""
+	for field, depth := dict, 0; field != nil && depth < 32; depth++ {
+		if t, ok := traceDirect(reader, field.Get("T")).(*PdfObjectString); ok {
+			if name == "" {
+				name = t.Decoded()
+			} else {
+				name = t.Decoded() + "." + name
+			}
+		}
+		field, _ = traceDirect(reader, field.Get("Parent")).(*PdfObjectDictionary)
+	}
+	return name
+}
+
+// traceDirect returns the direct object of obj, resolving the references with the reader, or nil if unresolved.
+func traceDirect(reader *PdfReader, obj PdfObject) PdfObject {
+	obj, err := reader.traceToObject(obj)
+	if err != nil {
+		return nil
+	}
+	return TraceToDirectObject(obj)
+}
+
+// lookupObject returns the object of the revision with the number, or nil if none.
+func lookupObject(reader *PdfReader, num int) PdfObject {
+	obj, err := reader.parser.LookupByNumber(num)
+	if err != nil {
+		return nil
+	}
+	switch obj.(type) {
+	case *PdfIndirectObject, *PdfObjectStream:
+		return obj
+	}
+	return nil
+}
+
+// getDictType returns the Type of the dictionary of an object.
+func getDictType(obj PdfObject) (string, bool) {
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		return "", false
+	}
+	name, ok := TraceToDirectObject(dict.Get("Type")).(*PdfObjectName)
+	if !ok {
+		return "", false
+	}
+	return string(*name), true
+}
+
+// objectNumber returns the object number of an indirect object, stream or reference.
+func objectNumber(obj PdfObject) (int, bool) {
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		return int(t.ObjectNumber), true
+	case *PdfObjectStream:
+		return int(t.ObjectNumber), true
+	case *PdfObjectReference:
+		return int(t.ObjectNumber), true
+	}
+	return 0, false
+}
+
+// arrayObjectNumbers returns the numbers of the objects referenced by the elements of an array.
+func arrayObjectNumbers(obj PdfObject) map[int]bool {
+	nums := map[int]bool{}
+	if arr, ok := TraceToDirectObject(obj).(*PdfObjectArray); ok {
+		for _, elem := range *arr {
+			if num, ok := objectNumber(elem); ok {
+				nums[num] = true
+			}
+		}
+	}
+	return nums
+}
+
+// walkObjectNumbers calls f with the numbers of the objects referenced from obj, recursively, except through the
+// Parent and P entries and the pages and catalog.
+func walkObjectNumbers(reader *PdfReader, obj PdfObject, visited map[int]bool, f func(num int)) {
+	if num, ok := objectNumber(obj); ok {
+		if visited[num] {
+			return
+		}
+		visited[num] = true
+		if _, isRef := obj.(*PdfObjectReference); isRef {
+			obj = lookupObject(reader, num)
+		}
+		if name, ok := getDictType(obj); ok && (name == "Page" || name == "Pages" || name == "Catalog") {
+			return
+		}
+		f(num)
+	}
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		walkObjectNumbers(reader, t.PdfObject, visited, f)
+	case *PdfObjectStream:
+		walkObjectNumbers(reader, t.PdfObjectDictionary, visited, f)
+	case *PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			if key != "Parent" && key != "P" {
+				walkObjectNumbers(reader, t.Get(key), visited, f)
+			}
+		}
+	case *PdfObjectArray:
+		for _, elem := range *t {
+			walkObjectNumbers(reader, elem, visited, f)
+		}
+	}
+}
+
+// shallowObjectsEqual returns true if two objects are equal, the objects they reference being compared by object
+// number.  An object whose stream data cannot be read is not equal to any object, so that it is reported as changed.
+func shallowObjectsEqual(obj1, obj2 PdfObject) bool {
+	str1, err := shallowObjectString(obj1)
+	if err != nil {
+		return false
+	}
+	str2, err := shallowObjectString(obj2)
+	return err == nil && str1 == str2
+}
+
+// shallowObjectString returns a string representation of an object with the objects it references written as
+// references, for comparing objects across revisions.
+func shallowObjectString(obj PdfObject) (string, error) {
+	var buf bytes.Buffer
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		writeShallowObjectString(&buf, t.PdfObject)
+	case *PdfObjectStream:
+		writeShallowObjectString(&buf, t.PdfObjectDictionary)
+		// The data of lazy streams is compared as well.
+		if err := t.Load(); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "stream%q", t.Stream)
+	default:
+		writeShallowObjectString(&buf, obj)
+	}
+	return buf.String(), nil
+}
+
+func writeShallowObjectString(buf *bytes.Buffer, obj PdfObject) {
+	if num, ok := objectNumber(obj); ok {
+		fmt.Fprintf(buf, "%d R", num)
+		return
+	}
+	switch t := obj.(type) {
+	case nil:
+		buf.WriteString("null")
+	case *PdfObjectDictionary:
+		buf.WriteString("<<")
+		for _, key := range t.Keys() {
+			buf.WriteString(key.DefaultWriteString())
+			buf.WriteString(" ")
+			writeShallowObjectString(buf, t.Get(key))
+		}
+		buf.WriteString(">>")
+	case *PdfObjectArray:
+		buf.WriteString("[")
+		for _, elem := range *t {
+			writeShallowObjectString(buf, elem)
+			buf.WriteString(" ")
+		}
+		buf.WriteString("]")
+	default:
+		buf.WriteString(obj.DefaultWriteString())
+	}
+}
@@ -0,0 +1,235 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+)
+
+// makeCoveredTestPdf returns a signed document with two text fields and a signature locking the Name field, whose
+// ByteRange covers the whole file except the signature value.  Certified with the permission level p if not 0.
+func makeCoveredTestPdf(p int) []byte {
+	catalog := "<< /Type /Catalog /Pages 2 0 R /AcroForm 4 0 R >>"
+	sig := "<< /Type /Sig /ByteRange [0 0000000000 0000000000 0000000000] /Contents <0102030400000000> >>"
+	if p > 0 {
+		catalog = "<< /Type /Catalog /Pages 2 0 R /AcroForm 4 0 R /Perms << /DocMDP 8 0 R >> >>"
+		sig = fmt.Sprintf("<< /Type /Sig /ByteRange [0 0000000000 0000000000 0000000000] /Contents <0102030400000000> "+
+			"/Reference [<< /Type /SigRef /TransformMethod /DocMDP /TransformParams << /P %d /V /1.2 >> >>] >>", p)
+	}
+	data := testutils.MakePdf([]string{
+		catalog,
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 9 0 R >>",
+		"<< /Fields [5 0 R 6 0 R 7 0 R] /SigFlags 3 >>",
+		"<< /FT /Tx /T (Name) /V (Alice) >>",
+		"<< /FT /Tx /T (Notes) /V (None) >>",
+		"<< /FT /Sig /T (Sig1) /V 8 0 R /Lock << /Type /SigFieldLock /Action /Include /Fields [(Name)] >> >>",
+		sig,
+		"<< /Length 8 >>\nstream\n0 0 m 1 l\nendstream",
+	})
+	start := bytes.Index(data, []byte("<0102"))
+	end := bytes.Index(data[start:], []byte(">")) + start + 1
+	byteRange := fmt.Sprintf("[0 %.10d %.10d %.10d]", start, end, len(data)-end)
+	return bytes.Replace(data, []byte("[0 0000000000 0000000000 0000000000]"), []byte(byteRange), 1)
+}
+
+// updateObjects returns the document updated incrementally by the function modifying the transaction.
+func updateObjects(t *testing.T, data []byte, update func(tx *PdfTransaction)) []byte {
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	tx, err := reader.Begin()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	update(tx)
+	out := &bytes.Buffer{}
+	if err = tx.Commit(out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return out.Bytes()
+}
+
+// getTxDict returns the dictionary of an object staged for modification in the transaction.
+func getTxDict(t *testing.T, tx *PdfTransaction, objNum int64) *PdfObjectDictionary {
+	obj, err := tx.GetObject(objNum)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return obj.(*PdfIndirectObject).PdfObject.(*PdfObjectDictionary)
+}
+
+// truncatedReader is a document truncated after it is parsed: the data of its lazy streams cannot be read.
+type truncatedReader struct {
+	*bytes.Reader
+}
+
+func (r truncatedReader) ReadAt(p []byte, off int64) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+// getCoverage returns the coverage of the single signature of a document.
+func getCoverage(t *testing.T, data []byte) *SignatureCoverage {
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	coverages, err := reader.GetSignatureCoverage()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(coverages) != 1 || coverages[0].Signature != "Sig1" {
+		t.Fatalf("Wrong signatures %v", coverages)
+	}
+	return coverages[0]
+}
+
+func TestSignatureCoverageIntact(t *testing.T) {
+	data := makeCoveredTestPdf(0)
+	coverage := getCoverage(t, data)
+	if coverage.Status != SignatureIntact || coverage.End != len(data) || coverage.Permission != 3 ||
+		coverage.Certification {
+		t.Errorf("Wrong coverage %+v", coverage)
+	}
+
+	// The gap must be the signature value, and the signed range end at the end of a revision.
+	byteRange := func(data []byte, i int, val int) []byte {
+		start := bytes.Index(data, []byte("/ByteRange [0 ")) + len("/ByteRange [0 ") + 11*i
+		copy(data[start:], fmt.Sprintf("%.10d", val))
+		return data
+	}
+	data = byteRange(makeCoveredTestPdf(0), 0, 10)
+	if coverage := getCoverage(t, data); coverage.Status != SignatureInvalidByteRange {
+		t.Errorf("Wrong status %v", coverage.Status)
+	}
+	data = makeCoveredTestPdf(0)
+	data = byteRange(data, 2, coverage.ByteRange[3]-3)
+	if coverage := getCoverage(t, data); coverage.Status != SignatureInvalidByteRange {
+		t.Errorf("Wrong status %v", coverage.Status)
+	}
+}
+
+func TestSignatureCoverageChanges(t *testing.T) {
+	type expectedChange struct {
+		num     int
+		kind    SignatureChangeKind
+		allowed bool
+	}
+	testcases := []struct {
+		name     string
+		p        int
+		update   func(t *testing.T, tx *PdfTransaction)
+		status   SignatureStatus
+		expected []expectedChange
+	}{
+		{"Form filling", 0, func(t *testing.T, tx *PdfTransaction) {
+			getTxDict(t, tx, 6).Set("V", MakeString("Updated"))
+		}, SignatureAllowedChanges, []expectedChange{{6, SignatureChangeFormField, true}}},
+		{"Locked field", 0, func(t *testing.T, tx *PdfTransaction) {
+			getTxDict(t, tx, 5).Set("V", MakeString("Mallory"))
+		}, SignatureTampered, []expectedChange{{5, SignatureChangeFormField, false}}},
+		{"Form filling not permitted", 1, func(t *testing.T, tx *PdfTransaction) {
+			getTxDict(t, tx, 6).Set("V", MakeString("Updated"))
+		}, SignatureTampered, []expectedChange{{6, SignatureChangeFormField, false}}},
+		{"Page content", 3, func(t *testing.T, tx *PdfTransaction) {
+			obj, err := tx.GetObject(9)
+			if err != nil {
+				t.Fatalf("Error: %v", err)
+			}
+			stream := obj.(*PdfObjectStream)
+			stream.Stream = []byte("0 0 m 2 l")
+			stream.Set("Length", MakeInteger(int64(len(stream.Stream))))
+		}, SignatureTampered, []expectedChange{{9, SignatureChangeOther, false}}},
+		{"Annotation", 0, func(t *testing.T, tx *PdfTransaction) {
+			annot := MakeIndirectObject(MakeDict())
+			annot.PdfObject.(*PdfObjectDictionary).Set("Type", MakeName("Annot"))
+			annot.PdfObject.(*PdfObjectDictionary).Set("Subtype", MakeName("Text"))
+			annot.PdfObject.(*PdfObjectDictionary).Set("Rect", MakeArrayFromFloats([]float64{0, 0, 10, 10}))
+			getTxDict(t, tx, 3).Set("Annots", MakeArray(annot))
+		}, SignatureAllowedChanges, []expectedChange{{3, SignatureChangeAnnotation, true},
+			{10, SignatureChangeAnnotation, true}}},
+		{"Annotation not permitted", 2, func(t *testing.T, tx *PdfTransaction) {
+			annot := MakeIndirectObject(MakeDict())
+			annot.PdfObject.(*PdfObjectDictionary).Set("Subtype", MakeName("Text"))
+			annot.PdfObject.(*PdfObjectDictionary).Set("Rect", MakeArrayFromFloats([]float64{0, 0, 10, 10}))
+			getTxDict(t, tx, 3).Set("Annots", MakeArray(annot))
+		}, SignatureTampered, []expectedChange{{3, SignatureChangeAnnotation, false},
+			{10, SignatureChangeAnnotation, false}}},
+		{"DSS", 1, func(t *testing.T, tx *PdfTransaction) {
+			cert := MakeIndirectObject(MakeString("certificate"))
+			dss := MakeDict()
+			dss.Set("Certs", MakeArray(cert))
+			getTxDict(t, tx, 1).Set("DSS", MakeIndirectObject(dss))
+		}, SignatureAllowedChanges, []expectedChange{{1, SignatureChangeDSS, true},
+			{10, SignatureChangeDSS, true}, {11, SignatureChangeDSS, true}}},
+		{"Catalog", 3, func(t *testing.T, tx *PdfTransaction) {
+			getTxDict(t, tx, 1).Set("OpenAction", MakeArray(MakeName("Fit")))
+		}, SignatureTampered, []expectedChange{{1, SignatureChangeOther, false}}},
+		{"Signature value", 3, func(t *testing.T, tx *PdfTransaction) {
+			getTxDict(t, tx, 8).Set("Name", MakeString("Mallory"))
+		}, SignatureTampered, []expectedChange{{8, SignatureChangeSignature, false}}},
+	}
+
+	for _, tc := range testcases {
+		data := updateObjects(t, makeCoveredTestPdf(tc.p), func(tx *PdfTransaction) { tc.update(t, tx) })
+		coverage := getCoverage(t, data)
+		if coverage.Status != tc.status {
+			t.Errorf("%s: wrong status %v", tc.name, coverage.Status)
+		}
+		if coverage.Certification != (tc.p > 0) {
+			t.Errorf("%s: wrong certification", tc.name)
+		}
+		if len(coverage.Changes) != len(tc.expected) {
+			t.Errorf("%s: wrong changes %+v", tc.name, coverage.Changes)
+			continue
+		}
+		for i, exp := range tc.expected {
+			change := coverage.Changes[i]
+			if change.ObjectNumber != exp.num || change.Kind != exp.kind || change.Allowed != exp.allowed {
+				t.Errorf("%s: change %+v, expected %+v", tc.name, change, exp)
+			}
+		}
+	}
+}
+
+func TestSignatureCoverageTruncatedStream(t *testing.T) {
+	data := updateObjects(t, makeCoveredTestPdf(0), func(tx *PdfTransaction) {
+		getTxDict(t, tx, 6).Set("V", MakeString("Updated"))
+	})
+	reader, err := NewPdfReaderLazy(truncatedReader{bytes.NewReader(data)}, 1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The page content, which cannot be read, is reported as changed.
+	coverages, err := reader.GetSignatureCoverage()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(coverages) != 1 || coverages[0].Status != SignatureTampered {
+		t.Fatalf("Wrong coverages %v", coverages)
+	}
+	changes := coverages[0].Changes
+	if len(changes) != 2 || changes[1].ObjectNumber != 9 || changes[1].Allowed {
+		t.Errorf("Wrong changes %+v", changes)
+	}
+
+	// Two streams which cannot be read are not equal either.
+	other, err := NewPdfReaderLazy(truncatedReader{bytes.NewReader(data)}, 1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if shallowObjectsEqual(lookupObject(reader, 9), lookupObject(other, 9)) {
+		t.Errorf("Unreadable streams equal")
+	}
+}
@@ -0,0 +1,436 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// FieldFlagReadOnly is the field flag (Ff) making a field read-only.
+const FieldFlagReadOnly = 1
+
+// SigFieldLockAction specifies the fields locked by a signature field lock.
+type SigFieldLockAction string
+
+const (
+	// All the fields of the document are locked.
+	SigFieldLockAll SigFieldLockAction = "All"
+	// Only the listed fields are locked.
+	SigFieldLockInclude SigFieldLockAction = "Include"
+	// All the fields except the listed ones are locked.
+	SigFieldLockExclude SigFieldLockAction = "Exclude"
+)
+
+// PdfSignatureFieldLock is the signature field lock dictionary (SigFieldLock, the Lock entry of signature fields),
+// specifying the fields which become read-only when the signature field is signed.
+type PdfSignatureFieldLock struct {
+	Action SigFieldLockAction
+
+	// Fully qualified names of the fields, for the Include and Exclude actions.
+	Fields []string
+
+	// Access permissions granted for the document once signed (1-3, see DocMDP), or 0 if not specified.
+	P int64
+}
+
+// NewPdfSignatureFieldLockFromPdfObject loads a signature field lock dictionary.
+func NewPdfSignatureFieldLockFromPdfObject(obj PdfObject) (*PdfSignatureFieldLock, error) {
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ERROR: Invalid signature field lock (%T)", obj)
+		return nil, ErrTypeError
+	}
+
+	lock := &PdfSignatureFieldLock{}
+	action, ok := TraceToDirectObject(dict.Get("Action")).(*PdfObjectName)
+	if !ok {
+		common.Log.Debug("ERROR: Signature field lock missing Action")
+		return nil, ErrRequiredAttributeMissing
+	}
+	lock.Action = SigFieldLockAction(*action)
+	switch lock.Action {
+	case SigFieldLockAll, SigFieldLockInclude, SigFieldLockExclude:
+	default:
+		common.Log.Debug("ERROR: Invalid signature field lock action %s", lock.Action)
+		return nil, ErrInvalidAttribute
+	}
+
+	if arr, ok := TraceToDirectObject(dict.Get("Fields")).(*PdfObjectArray); ok {
+		for _, obj := range *arr {
+			name, ok := TraceToDirectObject(obj).(*PdfObjectString)
+			if !ok {
+				common.Log.Debug("ERROR: Invalid locked field name (%T)", obj)
+				return nil, ErrTypeError
+			}
+			lock.Fields = append(lock.Fields, string(*name))
+		}
+	}
+
+	if p, ok := TraceToDirectObject(dict.Get("P")).(*PdfObjectInteger); ok {
+		lock.P = int64(*p)
+	}
+
+	return lock, nil
+}
+
+// ToPdfObject returns the signature field lock dictionary.
+func (this *PdfSignatureFieldLock) ToPdfObject() PdfObject {
+	dict := MakeDict()
+	dict.Set("Type", MakeName("SigFieldLock"))
+	dict.Set("Action", MakeName(string(this.Action)))
+	if this.Action != SigFieldLockAll {
+		arr := MakeArray()
+		for _, name := range this.Fields {
+			arr.Append(MakeString(name))
+		}
+		dict.Set("Fields", arr)
+	}
+	if this.P != 0 {
+		dict.Set("P", MakeInteger(this.P))
+	}
+	return dict
+}
+
+// Locks returns true if the field with the fully qualified name is locked.
+func (this *PdfSignatureFieldLock) Locks(fieldName string) bool {
+	if this.Action == SigFieldLockAll {
+		return true
+	}
+	listed := false
+	for _, name := range this.Fields {
+		if name == fieldName {
+			listed = true
+			break
+		}
+	}
+	return listed == (this.Action == SigFieldLockInclude)
+}
+
+// GetFullName returns the fully qualified name of the field: the partial names of the field and its ancestors,
+// separated by periods.
+func (this *PdfField) GetFullName() string {
+	name := ""
+	if t, ok := TraceToDirectObject(this.T).(*PdfObjectString); ok {
+		name = t.Decoded()
+	}
+	if this.Parent != nil {
+		if parentName := this.Parent.GetFullName(); parentName != "" {
+			if name == "" {
+				return parentName
+			}
+			return parentName + "." + name
+		}
+	}
+	return name
+}
+
+// GetFieldType returns the field type (FT), inherited from the ancestors of the field if not set.
+func (this *PdfField) GetFieldType() string {
+	for field := this; field != nil; field = field.Parent {
+		if field.FT != nil {
+			return string(*field.FT)
+		}
+	}
+	return ""
+}
+
+// IsSigned returns true if the field is a signature field with a signature value.
+func (this *PdfField) IsSigned() bool {
+	if this.GetFieldType() != "Sig" {
+		return false
+	}
+	_, ok := TraceToDirectObject(this.V).(*PdfObjectDictionary)
+	return ok
+}
+
+// GetLock returns the signature field lock of a signature field, or nil if the field does not lock other fields.
+func (this *PdfField) GetLock() (*PdfSignatureFieldLock, error) {
+	if this.Lock == nil {
+		return nil, nil
+	}
+	if _, isNull := TraceToDirectObject(this.Lock).(*PdfObjectNull); isNull {
+		return nil, nil
+	}
+	return NewPdfSignatureFieldLockFromPdfObject(this.Lock)
+}
+
+// SetLock sets the fields locked when the signature field is signed, see PdfAcroForm.ApplySignatureLocks.
+func (this *PdfField) SetLock(lock *PdfSignatureFieldLock) {
+	if lock == nil {
+		this.Lock = nil
+		return
+	}
+	this.Lock = MakeIndirectObject(lock.ToPdfObject())
+}
+
+// IsReadOnly returns true if the read-only field flag is set on the field or inherited from its ancestors.
+func (this *PdfField) IsReadOnly() bool {
+	for field := this; field != nil; field = field.Parent {
+		if field.Ff != nil {
+			ff, ok := TraceToDirectObject(field.Ff).(*PdfObjectInteger)
+			return ok && int64(*ff)&FieldFlagReadOnly != 0
+		}
+	}
+	return false
+}
+
+// setReadOnly sets the read-only field flag, keeping the other flags.
+func (this *PdfField) setReadOnly() {
+	var flags int64
+	for field := this; field != nil; field = field.Parent {
+		if ff, ok := TraceToDirectObject(field.Ff).(*PdfObjectInteger); ok {
+			flags = int64(*ff)
+			break
+		}
+	}
+	this.Ff = MakeInteger(flags | FieldFlagReadOnly)
+}
+
+// AllFields returns the terminal fields of the form, i.e. the fields without descendant fields.
+func (this *PdfAcroForm) AllFields() []*PdfField {
+	fields := []*PdfField{}
+	if this.Fields == nil {
+		return fields
+	}
+	var collect func(field *PdfField)
+	collect = func(field *PdfField) {
+		terminal := true
+		for _, kid := range field.KidsF {
+			if kidField, ok := kid.(*PdfField); ok {
+				terminal = false
+				collect(kidField)
+			}
+		}
+		if terminal {
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range *this.Fields {
+		collect(field)
+	}
+	return fields
+}
+
+// IsFieldLocked returns true if the field with the fully qualified name is locked by a signed signature field.
+func (this *PdfAcroForm) IsFieldLocked(fieldName string) (bool, error) {
+	for _, field := range this.AllFields() {
+		if !field.IsSigned() {
+			continue
+		}
+		lock, err := field.GetLock()
+		if err != nil {
+			return false, err
+		}
+		if lock != nil && lock.Locks(fieldName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ApplySignatureLocks makes the fields locked by the signed signature fields read-only.  To be called after
+// applying a signature, before writing the form.  Returns the fields made read-only.
+func (this *PdfAcroForm) ApplySignatureLocks() ([]*PdfField, error) {
+	locked := []*PdfField{}
+	for _, field := range this.AllFields() {
+		if field.IsReadOnly() {
+			continue
+		}
+		isLocked, err := this.IsFieldLocked(field.GetFullName())
+		if err != nil {
+			return nil, err
+		}
+		if isLocked {
+			field.setReadOnly()
+			locked = append(locked, field)
+		}
+	}
+	return locked, nil
+}
+
+// LockedFieldChange is a modification of a field locked by a signature, made by an update of the document after
+// the signature was applied.
+type LockedFieldChange struct {
+	// Fully qualified name of the signature field.
+	Signature string
+
+	// Fully qualified name of the locked field.
+	Field string
+}
+
+// GetLockedFieldChanges returns the locked fields whose value or flags were changed, or which were removed, by
+// incremental updates made after the signatures locking them were applied.  The revision of the document signed by
+// a signature is the part of the file covered by its ByteRange.  The signatures themselves are not verified.
+func (this *PdfReader) GetLockedFieldChanges() ([]LockedFieldChange, error) {
+	changes := []LockedFieldChange{}
+	if this.AcroForm == nil {
+		return changes, nil
+	}
+
+	var data []byte
+	for _, sigField := range this.AcroForm.AllFields() {
+		if !sigField.IsSigned() {
+			continue
+		}
+		lock, err := sigField.GetLock()
+		if err != nil {
+			return nil, err
+		}
+		if lock == nil {
+			continue
+		}
+
+		if data == nil {
+			var buf bytes.Buffer
+			_, err = this.parser.WriteOriginal(&buf)
+			if err != nil {
+				return nil, err
+			}
+			data = buf.Bytes()
+		}
+		revision, err := getSignedRevision(data, sigField)
+		if err != nil {
+			return nil, err
+		}
+		if revision == nil || revision.AcroForm == nil {
+			continue
+		}
+
+		// The locked fields of the signed revision, compared with the current ones.
+		current := map[string]*PdfField{}
+		for _, field := range this.AcroForm.AllFields() {
+			current[field.GetFullName()] = field
+		}
+		for _, field := range revision.AcroForm.AllFields() {
+			name := field.GetFullName()
+			if name == sigField.GetFullName() || !lock.Locks(name) {
+				continue
+			}
+			if other, has := current[name]; !has || !fieldStateEqual(field, other) {
+				changes = append(changes, LockedFieldChange{Signature: sigField.GetFullName(), Field: name})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// getSignedRevision loads the revision of the document signed by a signature field, or returns nil if the
+// signature covers the whole file.
+func getSignedRevision(data []byte, sigField *PdfField) (*PdfReader, error) {
+	sig := TraceToDirectObject(sigField.V).(*PdfObjectDictionary)
+	arr, ok := TraceToDirectObject(sig.Get("ByteRange")).(*PdfObjectArray)
+	if !ok || len(*arr) == 0 || len(*arr)%2 != 0 {
+		common.Log.Debug("ERROR: Invalid ByteRange of signature %s", sigField.GetFullName())
+		return nil, ErrInvalidAttribute
+	}
+	vals, err := arr.ToIntegerArray()
+	if err != nil {
+		return nil, err
+	}
+	end := 0
+	for i := 0; i < len(vals); i += 2 {
+		if vals[i] < 0 || vals[i+1] < 0 || vals[i]+vals[i+1] > len(data) {
+			common.Log.Debug("ERROR: ByteRange of signature %s out of range", sigField.GetFullName())
+			return nil, ErrRangeError
+		}
+		if vals[i]+vals[i+1] > end {
+			end = vals[i] + vals[i+1]
+		}
+	}
+	if end == len(data) {
+		return nil, nil
+	}
+
+	revision, err := NewPdfReader(bytes.NewReader(data[:end]))
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to load signed revision: %v", err)
+		return nil, errors.New("Unable to load signed revision")
+	}
+	return revision, nil
+}
+
+// fieldStateEqual returns true if two fields have the same value and flags.
+func fieldStateEqual(field1, field2 *PdfField) bool {
+	return objectsEqual(field1.V, field2.V) && objectsEqual(field1.Ff, field2.Ff)
+}
+
+// objectsEqual returns true if two objects are equal, including the content of the objects they reference.  An
+// object whose stream data, or the stream data of an object it references, cannot be read is not equal to any
+// object, so that it is reported as changed.
+func objectsEqual(obj1, obj2 PdfObject) bool {
+	str1, err := objectString(obj1)
+	if err != nil {
+		return false
+	}
+	str2, err := objectString(obj2)
+	return err == nil && str1 == str2
+}
+
+// objectString returns a string representation of an object, including the content of the referenced objects
+// (unlike DefaultWriteString), for comparing objects across revisions.
+func objectString(obj PdfObject) (string, error) {
+	var buf bytes.Buffer
+	if err := writeObjectString(&buf, obj, map[PdfObject]bool{}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeObjectString(buf *bytes.Buffer, obj PdfObject, visited map[PdfObject]bool) error {
+	switch t := obj.(type) {
+	case nil:
+		buf.WriteString("null")
+	case *PdfIndirectObject:
+		if visited[t] {
+			fmt.Fprintf(buf, "%d R", t.ObjectNumber)
+			return nil
+		}
+		visited[t] = true
+		return writeObjectString(buf, t.PdfObject, visited)
+	case *PdfObjectStream:
+		if visited[t] {
+			fmt.Fprintf(buf, "%d R", t.ObjectNumber)
+			return nil
+		}
+		visited[t] = true
+		if err := writeObjectString(buf, t.PdfObjectDictionary, visited); err != nil {
+			return err
+		}
+		// The data of lazy streams is compared as well.
+		if err := t.Load(); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "stream%q", t.Stream)
+	case *PdfObjectDictionary:
+		buf.WriteString("<<")
+		for _, key := range t.Keys() {
+			buf.WriteString(key.DefaultWriteString())
+			buf.WriteString(" ")
+			if err := writeObjectString(buf, t.Get(key), visited); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(">>")
+	case *PdfObjectArray:
+		buf.WriteString("[")
+		for _, elem := range *t {
+			if err := writeObjectString(buf, elem, visited); err != nil {
+				return err
+			}
+			buf.WriteString(" ")
+		}
+		buf.WriteString("]")
+	default:
+		buf.WriteString(obj.DefaultWriteString())
+	}
+	return nil
+}
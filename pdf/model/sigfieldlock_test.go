@@ -0,0 +1,157 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+)
+
+// makeSignedTestPdf returns a document with two text fields and a signature field locking the Name field, whose
+// ByteRange covers the whole file.
+func makeSignedTestPdf() []byte {
+	data := testutils.MakePdf([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Fields [5 0 R 6 0 R 7 0 R] /SigFlags 3 >>",
+		"<< /FT /Tx /T (Name) /V (Alice) >>",
+		"<< /FT /Tx /T (Notes) /V (None) >>",
+		"<< /FT /Sig /T (Sig1) /V 8 0 R /Lock << /Type /SigFieldLock /Action /Include /Fields [(Name)] >> >>",
+		"<< /Type /Sig /ByteRange [0 0000000000 0 0] /Contents <00> >>",
+	})
+	return bytes.Replace(data, []byte("0000000000 0 0]"), []byte(fmt.Sprintf("%.10d 0 0]", len(data))), 1)
+}
+
+// updateField returns the document with the value of a field object changed by an incremental update.
+func updateField(t *testing.T, data []byte, objNum int64, value string) []byte {
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	tx, err := reader.Begin()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	obj, err := tx.GetObject(objNum)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	obj.(*PdfIndirectObject).PdfObject.(*PdfObjectDictionary).Set("V", MakeString(value))
+	out := &bytes.Buffer{}
+	err = tx.Commit(out)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return out.Bytes()
+}
+
+// getLockedFieldChanges returns the locked field changes of a document.
+func getLockedFieldChanges(t *testing.T, data []byte) []LockedFieldChange {
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	changes, err := reader.GetLockedFieldChanges()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return changes
+}
+
+func TestSignatureFieldLock(t *testing.T) {
+	lock := &PdfSignatureFieldLock{Action: SigFieldLockExclude, Fields: []string{"a.b"}}
+	loaded, err := NewPdfSignatureFieldLockFromPdfObject(lock.ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if loaded.Action != SigFieldLockExclude || len(loaded.Fields) != 1 || loaded.Fields[0] != "a.b" {
+		t.Fatalf("Wrong lock %+v", loaded)
+	}
+	if loaded.Locks("a.b") || !loaded.Locks("a") {
+		t.Errorf("Wrong locked fields")
+	}
+
+	_, err = NewPdfSignatureFieldLockFromPdfObject(MakeDict())
+	if err == nil {
+		t.Errorf("Lock without Action loaded")
+	}
+}
+
+func TestApplySignatureLocks(t *testing.T) {
+	reader, err := NewPdfReader(bytes.NewReader(makeSignedTestPdf()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	locked, err := reader.AcroForm.ApplySignatureLocks()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(locked) != 1 || locked[0].GetFullName() != "Name" {
+		t.Fatalf("Wrong locked fields %v", locked)
+	}
+	for _, field := range reader.AcroForm.AllFields() {
+		if field.IsReadOnly() != (field.GetFullName() == "Name") {
+			t.Errorf("Wrong read-only flag of %s", field.GetFullName())
+		}
+	}
+
+	// Unsigned signature fields do not lock fields.
+	sigField := reader.AcroForm.AllFields()[2]
+	sigField.V = nil
+	isLocked, err := reader.AcroForm.IsFieldLocked("Name")
+	if err != nil || isLocked {
+		t.Errorf("Field locked by unsigned signature (%v)", err)
+	}
+}
+
+func TestLockedFieldChanges(t *testing.T) {
+	data := makeSignedTestPdf()
+	if changes := getLockedFieldChanges(t, data); len(changes) != 0 {
+		t.Fatalf("Unexpected changes %v", changes)
+	}
+
+	// Changing a field not locked.
+	data = updateField(t, data, 6, "Updated")
+	if changes := getLockedFieldChanges(t, data); len(changes) != 0 {
+		t.Fatalf("Unexpected changes %v", changes)
+	}
+
+	// Changing the locked field.
+	data = updateField(t, data, 5, "Mallory")
+	changes := getLockedFieldChanges(t, data)
+	if len(changes) != 1 || changes[0].Signature != "Sig1" || changes[0].Field != "Name" {
+		t.Fatalf("Wrong changes %v", changes)
+	}
+}
+
+func TestLockedFieldChangesTruncatedStream(t *testing.T) {
+	// A field value which cannot be read is reported as changed, even if it cannot be read in both revisions.
+	data := makeCoveredTestPdf(0)
+	var streams []PdfObject
+	for i := 0; i < 2; i++ {
+		reader, err := NewPdfReaderLazy(truncatedReader{bytes.NewReader(data)}, 1)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		streams = append(streams, lookupObject(reader, 9))
+	}
+	if fieldStateEqual(&PdfField{V: streams[0]}, &PdfField{V: streams[1]}) {
+		t.Errorf("Unreadable field values equal")
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !fieldStateEqual(&PdfField{V: lookupObject(reader, 9)}, &PdfField{V: lookupObject(reader, 9)}) {
+		t.Errorf("Field values not equal")
+	}
+}
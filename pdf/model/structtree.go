@@ -0,0 +1,488 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// Logical structure (14.7 Logical Structure): the structure tree of tagged documents organizes their content into
+// structure elements (paragraphs, headings, figures...), referring to the marked content sequences of the content
+// streams (/P << /MCID 0 >> BDC ... EMC) by marked-content identifier (MCID), and to annotations.  The parent tree
+// maps back the content of each page (StructParents key) and each annotation (StructParent key) to the elements.
+
+// Maximum depth of the structure trees loaded.
+const maxStructTreeDepth = 100
+
+// PdfStructTreeRoot is the root of the structure tree of a tagged document (StructTreeRoot entry of the catalog).
+type PdfStructTreeRoot struct {
+	// The top-level structure elements, usually a single Document element.
+	K []*PdfStructElement
+	// Maps the custom structure types used to standard structure types, e.g. "Title" to "H1".
+	RoleMap map[string]string
+
+	// The parent tree keys of the pages and the next key to assign.
+	pageKeys map[*PdfPage]int
+	nextKey  int
+	// The structure elements of the objects (annotations) by parent tree key.
+	objectKeys map[int]*PdfStructElement
+	// The next MCID to assign in the content of the pages.
+	nextMCIDs map[*PdfPage]int
+
+	container *PdfIndirectObject
+}
+
+// PdfStructElement is a structure element of the structure tree.
+type PdfStructElement struct {
+	// Structure type (S), a standard type such as "P", "H1", "Figure" or "Table", or a custom type mapped to a
+	// standard type by the role map.
+	S string
+	// Optional title (T), language (Lang), alternate description (Alt, required for figures by accessibility
+	// standards), replacement text (ActualText), expansion of an abbreviation (E) and identifier (ID).
+	T, Lang, Alt, ActualText, E, ID string
+
+	// The content of the element, in order.
+	Kids []*PdfStructKid
+
+	container *PdfIndirectObject
+}
+
+// PdfStructKid is an item of the content of a structure element: either a child structure element, or a marked
+// content sequence of a page, or an object of a page such as an annotation.
+type PdfStructKid struct {
+	Element *PdfStructElement
+
+	// The page of the marked content or object.
+	Page *PdfPage
+	// The marked-content identifier of the marked content sequence in the content of the page, -1 for objects.
+	MCID int
+	// The object, e.g. the dictionary of an annotation.
+	Object PdfObject
+}
+
+// NewPdfStructTreeRoot returns an empty structure tree.
+func NewPdfStructTreeRoot() *PdfStructTreeRoot {
+	return &PdfStructTreeRoot{
+		RoleMap:    map[string]string{},
+		pageKeys:   map[*PdfPage]int{},
+		objectKeys: map[int]*PdfStructElement{},
+		nextMCIDs:  map[*PdfPage]int{},
+		container:  MakeIndirectObject(MakeDict()),
+	}
+}
+
+// NewPdfStructElement returns a structure element of the structure type, without content.
+func NewPdfStructElement(structType string) *PdfStructElement {
+	return &PdfStructElement{S: structType, container: MakeIndirectObject(MakeDict())}
+}
+
+// AddKid adds a child structure element at the end of the content of the element.
+func (elem *PdfStructElement) AddKid(kid *PdfStructElement) {
+	elem.Kids = append(elem.Kids, &PdfStructKid{Element: kid, MCID: -1})
+}
+
+// AddMarkedContent adds a marked content sequence of the page, by its MCID, at the end of the content of the
+// element.  See PdfStructTreeRoot.NewMCID for getting the MCID.
+func (elem *PdfStructElement) AddMarkedContent(page *PdfPage, mcid int) {
+	elem.Kids = append(elem.Kids, &PdfStructKid{Page: page, MCID: mcid})
+}
+
+// NewMCID returns a new marked-content identifier for a marked content sequence of the page, e.g. for
+// `/P << /MCID 0 >> BDC ... EMC`, and assigns the parent tree key of the page (StructParents entry) if not done
+// yet.  The page should not have been written yet.
+func (root *PdfStructTreeRoot) NewMCID(page *PdfPage) int {
+	root.pageKey(page)
+	mcid := root.nextMCIDs[page]
+	root.nextMCIDs[page] = mcid + 1
+	return mcid
+}
+
+// pageKey returns the parent tree key of the page, assigning it if needed.
+func (root *PdfStructTreeRoot) pageKey(page *PdfPage) int {
+	if key, has := root.pageKeys[page]; has {
+		return key
+	}
+	key := root.nextKey
+	root.nextKey++
+	root.pageKeys[page] = key
+	page.StructParents = MakeInteger(int64(key))
+	page.GetPageDict().Set("StructParents", page.StructParents)
+	return key
+}
+
+// AddObject adds an object of the page, such as an annotation, at the end of the content of the element, and sets
+// the parent tree key of the object (StructParent entry).  The object must be a dictionary, directly or in an
+// indirect object, e.g. the containing object of an annotation.
+func (root *PdfStructTreeRoot) AddObject(elem *PdfStructElement, page *PdfPage, obj PdfObject) error {
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ERROR: Structure content object not a dictionary (%T)", obj)
+		return ErrTypeError
+	}
+	key := root.nextKey
+	root.nextKey++
+	root.objectKeys[key] = elem
+	dict.Set("StructParent", MakeInteger(int64(key)))
+	elem.Kids = append(elem.Kids, &PdfStructKid{Page: page, MCID: -1, Object: obj})
+	return nil
+}
+
+// ToPdfObject returns the structure tree root dictionary, with the structure elements and the parent tree.
+func (root *PdfStructTreeRoot) ToPdfObject() PdfObject {
+	dict := root.container.PdfObject.(*PdfObjectDictionary)
+	dict.Set("Type", MakeName("StructTreeRoot"))
+
+	// Elements of the marked content sequences by page key and MCID.
+	contents := map[int]map[int]*PdfStructElement{}
+	kids := &PdfObjectArray{}
+	for _, elem := range root.K {
+		kids.Append(elem.toPdfObject(root.container, root, contents, 0))
+	}
+	dict.Set("K", kids)
+
+	for _, key := range root.pageKeys {
+		if contents[key] == nil {
+			contents[key] = map[int]*PdfStructElement{}
+		}
+	}
+	nums := &PdfObjectArray{}
+	keys := []int{}
+	for key := range contents {
+		keys = append(keys, key)
+	}
+	for key := range root.objectKeys {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	for _, key := range keys {
+		nums.Append(MakeInteger(int64(key)))
+		if elem, isObject := root.objectKeys[key]; isObject {
+			nums.Append(elem.container)
+			continue
+		}
+		maxMCID := -1
+		for mcid := range contents[key] {
+			if mcid > maxMCID {
+				maxMCID = mcid
+			}
+		}
+		elems := &PdfObjectArray{}
+		for mcid := 0; mcid <= maxMCID; mcid++ {
+			if elem, has := contents[key][mcid]; has {
+				elems.Append(elem.container)
+			} else {
+				elems.Append(MakeNull())
+			}
+		}
+		nums.Append(elems)
+	}
+	parentTree := MakeDict()
+	parentTree.Set("Nums", nums)
+	dict.Set("ParentTree", MakeIndirectObject(parentTree))
+	dict.Set("ParentTreeNextKey", MakeInteger(int64(root.nextKey)))
+
+	if len(root.RoleMap) > 0 {
+		customTypes := []string{}
+		for custom := range root.RoleMap {
+			customTypes = append(customTypes, custom)
+		}
+		sort.Strings(customTypes)
+		roleMap := MakeDict()
+		for _, custom := range customTypes {
+			roleMap.Set(PdfObjectName(custom), MakeName(root.RoleMap[custom]))
+		}
+		dict.Set("RoleMap", roleMap)
+	} else {
+		dict.Remove("RoleMap")
+	}
+	return root.container
+}
+
+// toPdfObject returns the structure element dictionary, recording the elements of the marked content sequences by
+// parent tree key of their page and MCID.
+func (elem *PdfStructElement) toPdfObject(parent PdfObject, root *PdfStructTreeRoot,
+	contents map[int]map[int]*PdfStructElement, depth int) PdfObject {
+	dict := elem.container.PdfObject.(*PdfObjectDictionary)
+	dict.Set("Type", MakeName("StructElem"))
+	dict.Set("S", MakeName(elem.S))
+	dict.Set("P", parent)
+	for key, val := range map[PdfObjectName]string{"T": elem.T, "Lang": elem.Lang, "Alt": elem.Alt,
+		"ActualText": elem.ActualText, "E": elem.E, "ID": elem.ID} {
+		if val == "" {
+			dict.Remove(key)
+		} else {
+			dict.Set(key, MakeTextString(val))
+		}
+	}
+
+	// The page of the element is that of its first page content, whose MCIDs can be written as integers.
+	var pg *PdfPage
+	for _, kid := range elem.Kids {
+		if kid.Element == nil && kid.Page != nil {
+			pg = kid.Page
+			break
+		}
+	}
+	if pg != nil {
+		dict.Set("Pg", pg.GetPageAsIndirectObject())
+	} else {
+		dict.Remove("Pg")
+	}
+
+	kids := &PdfObjectArray{}
+	for _, kid := range elem.Kids {
+		switch {
+		case kid.Element != nil:
+			if depth >= maxStructTreeDepth {
+				common.Log.Debug("ERROR: Structure tree too deep, element %s skipped", kid.Element.S)
+				continue
+			}
+			kids.Append(kid.Element.toPdfObject(elem.container, root, contents, depth+1))
+		case kid.Object != nil:
+			objr := MakeDict()
+			objr.Set("Type", MakeName("OBJR"))
+			objr.Set("Obj", kid.Object)
+			if kid.Page != nil {
+				objr.Set("Pg", kid.Page.GetPageAsIndirectObject())
+			}
+			kids.Append(objr)
+		case kid.Page != nil:
+			key := root.pageKey(kid.Page)
+			if contents[key] == nil {
+				contents[key] = map[int]*PdfStructElement{}
+			}
+			contents[key][kid.MCID] = elem
+			if kid.Page == pg {
+				kids.Append(MakeInteger(int64(kid.MCID)))
+				continue
+			}
+			mcr := MakeDict()
+			mcr.Set("Type", MakeName("MCR"))
+			mcr.Set("Pg", kid.Page.GetPageAsIndirectObject())
+			mcr.Set("MCID", MakeInteger(int64(kid.MCID)))
+			kids.Append(mcr)
+		}
+	}
+	if len(*kids) == 1 {
+		dict.Set("K", (*kids)[0])
+	} else {
+		dict.Set("K", kids)
+	}
+	return elem.container
+}
+
+// GetStructTreeRoot returns the structure tree of the document, for editing it, or nil if the document is not
+// tagged.  The marked content sequences in content streams other than those of the pages (Stm entries) are not
+// loaded, and are dropped if the tree is written.
+func (this *PdfReader) GetStructTreeRoot() (*PdfStructTreeRoot, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	obj, err := this.traceToObject(this.catalog.Get("StructTreeRoot"))
+	if err != nil {
+		return nil, err
+	}
+	ind, ok := obj.(*PdfIndirectObject)
+	if !ok {
+		return nil, nil
+	}
+	dict, ok := ind.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return nil, nil
+	}
+
+	root := NewPdfStructTreeRoot()
+	root.container = ind
+	if roleMap, ok := traceDirect(this, dict.Get("RoleMap")).(*PdfObjectDictionary); ok {
+		for _, key := range roleMap.Keys() {
+			if name, ok := traceDirect(this, roleMap.Get(key)).(*PdfObjectName); ok {
+				root.RoleMap[string(key)] = string(*name)
+			}
+		}
+	}
+	for i, page := range this.PageList {
+		if key, err := getNumberAsInt64(traceDirect(this, page.StructParents)); err == nil {
+			root.pageKeys[page] = int(key)
+			if int(key) >= root.nextKey {
+				root.nextKey = int(key) + 1
+			}
+		} else if page.StructParents != nil {
+			common.Log.Debug("Invalid StructParents of page %d", i+1)
+		}
+	}
+	if next, err := getNumberAsInt64(traceDirect(this, dict.Get("ParentTreeNextKey"))); err == nil &&
+		int(next) > root.nextKey {
+		root.nextKey = int(next)
+	}
+
+	l := &structTreeLoader{reader: this, root: root, loaded: map[PdfObject]bool{}}
+	for _, kid := range l.kids(dict.Get("K")) {
+		elem, err := l.loadElement(kid, 0)
+		if err != nil {
+			return nil, err
+		}
+		if elem != nil {
+			root.K = append(root.K, elem)
+		}
+	}
+	return root, nil
+}
+
+// structTreeLoader loads the structure elements of a structure tree.
+type structTreeLoader struct {
+	reader *PdfReader
+	root   *PdfStructTreeRoot
+	loaded map[PdfObject]bool
+}
+
+// kids returns the items of the K entry of a structure element, a single item or an array.
+func (l *structTreeLoader) kids(obj PdfObject) []PdfObject {
+	if arr, ok := traceDirect(l.reader, obj).(*PdfObjectArray); ok {
+		return *arr
+	}
+	if obj == nil {
+		return nil
+	}
+	return []PdfObject{obj}
+}
+
+// page returns the page of the Pg entry of a dictionary, or nil if none or not in the page tree.
+func (l *structTreeLoader) page(dict *PdfObjectDictionary) *PdfPage {
+	obj, err := l.reader.traceToObject(dict.Get("Pg"))
+	if err != nil || obj == nil {
+		return nil
+	}
+	for i, pageObj := range l.reader.pageList {
+		if pageObj == obj {
+			return l.reader.PageList[i]
+		}
+	}
+	common.Log.Debug("Structure content page not in the page tree")
+	return nil
+}
+
+// loadElement loads a structure element, or returns nil if obj is not a structure element.
+func (l *structTreeLoader) loadElement(obj PdfObject, depth int) (*PdfStructElement, error) {
+	if depth > maxStructTreeDepth {
+		common.Log.Debug("ERROR: Structure tree too deep")
+		return nil, errors.New("Structure tree recursion limit exceeded")
+	}
+	traced, err := l.reader.traceToObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	ind, ok := traced.(*PdfIndirectObject)
+	if !ok || l.loaded[ind] {
+		return nil, nil
+	}
+	l.loaded[ind] = true
+	dict, ok := ind.PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		return nil, nil
+	}
+	s, ok := traceDirect(l.reader, dict.Get("S")).(*PdfObjectName)
+	if !ok {
+		common.Log.Debug("Structure element without type")
+		return nil, nil
+	}
+
+	elem := &PdfStructElement{S: string(*s), container: ind}
+	for key, field := range map[PdfObjectName]*string{"T": &elem.T, "Lang": &elem.Lang, "Alt": &elem.Alt,
+		"ActualText": &elem.ActualText, "E": &elem.E, "ID": &elem.ID} {
+		if val, ok := GetTextString(traceDirect(l.reader, dict.Get(key))); ok {
+			*field = val
+		}
+	}
+	pg := l.page(dict)
+
+	for _, kid := range l.kids(dict.Get("K")) {
+		kidObj := traceDirect(l.reader, kid)
+		switch k := kidObj.(type) {
+		case *PdfObjectInteger:
+			if pg == nil {
+				common.Log.Debug("Marked content without page skipped")
+				continue
+			}
+			elem.addLoadedMarkedContent(l.root, pg, int(*k))
+		case *PdfObjectDictionary:
+			typ, _ := traceDirect(l.reader, k.Get("Type")).(*PdfObjectName)
+			switch {
+			case typ == nil || (*typ != "MCR" && *typ != "OBJR"):
+				err := l.loadChild(elem, kid, depth)
+				if err != nil {
+					return nil, err
+				}
+			case *typ == "MCR":
+				if k.Get("Stm") != nil {
+					common.Log.Debug("Marked content of a stream skipped")
+					continue
+				}
+				mcid, err := getNumberAsInt64(traceDirect(l.reader, k.Get("MCID")))
+				page := l.page(k)
+				if page == nil {
+					page = pg
+				}
+				if err != nil || page == nil {
+					common.Log.Debug("Invalid marked content reference skipped")
+					continue
+				}
+				elem.addLoadedMarkedContent(l.root, page, int(mcid))
+			case *typ == "OBJR":
+				page := l.page(k)
+				if page == nil {
+					page = pg
+				}
+				target, err := l.reader.traceToObject(k.Get("Obj"))
+				if err != nil {
+					return nil, err
+				}
+				elem.Kids = append(elem.Kids, &PdfStructKid{Page: page, MCID: -1, Object: target})
+				if d, ok := TraceToDirectObject(target).(*PdfObjectDictionary); ok {
+					if key, err := getNumberAsInt64(traceDirect(l.reader, d.Get("StructParent"))); err == nil {
+						l.root.objectKeys[int(key)] = elem
+						if int(key) >= l.root.nextKey {
+							l.root.nextKey = int(key) + 1
+						}
+					}
+				}
+			}
+		}
+	}
+	return elem, nil
+}
+
+// loadChild loads a child structure element of elem.
+func (l *structTreeLoader) loadChild(elem *PdfStructElement, obj PdfObject, depth int) error {
+	child, err := l.loadElement(obj, depth+1)
+	if err != nil {
+		return err
+	}
+	if child != nil {
+		elem.AddKid(child)
+	}
+	return nil
+}
+
+// addLoadedMarkedContent adds a marked content sequence loaded from a document, so that the new MCIDs of its page
+// follow it.
+func (elem *PdfStructElement) addLoadedMarkedContent(root *PdfStructTreeRoot, page *PdfPage, mcid int) {
+	elem.AddMarkedContent(page, mcid)
+	if mcid >= root.nextMCIDs[page] {
+		root.nextMCIDs[page] = mcid + 1
+	}
+	root.pageKey(page)
+}
+
+// SetStructTreeRoot sets the structure tree of the document, marking it as tagged (MarkInfo entry of the catalog).
+// The tree is written with the document, and the pages with content in the tree must be added to the writer.
+func (this *PdfWriter) SetStructTreeRoot(root *PdfStructTreeRoot) {
+	this.structTreeRoot = root
+}
@@ -0,0 +1,126 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// writeTestTaggedPdf writes the pages with the structure tree and returns the reader of the document written.
+func writeTestTaggedPdf(t *testing.T, pages []*PdfPage, root *PdfStructTreeRoot) *PdfReader {
+	w := NewPdfWriter()
+	for _, page := range pages {
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+	}
+	w.SetStructTreeRoot(root)
+	var buf bytes.Buffer
+	if err := w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return reader
+}
+
+func TestStructTree(t *testing.T) {
+	pages := []*PdfPage{}
+	for i := 0; i < 2; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+		page.Resources = NewPdfPageResources()
+		pages = append(pages, page)
+	}
+	link := NewPdfAnnotationLink()
+	link.Rect = MakeArrayFromFloats([]float64{0, 0, 100, 20})
+	pages[1].Annotations = append(pages[1].Annotations, link.PdfAnnotation)
+
+	root := NewPdfStructTreeRoot()
+	root.RoleMap["Title"] = "H1"
+	doc := NewPdfStructElement("Document")
+	root.K = append(root.K, doc)
+	title := NewPdfStructElement("Title")
+	title.AddMarkedContent(pages[0], root.NewMCID(pages[0]))
+	figure := NewPdfStructElement("Figure")
+	figure.Alt = "Chart"
+	figure.AddMarkedContent(pages[0], root.NewMCID(pages[0]))
+	figure.AddMarkedContent(pages[1], root.NewMCID(pages[1]))
+	linkElem := NewPdfStructElement("Link")
+	if err := root.AddObject(linkElem, pages[1], link.GetContainingPdfObject()); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	doc.AddKid(title)
+	doc.AddKid(figure)
+	doc.AddKid(linkElem)
+
+	reader := writeTestTaggedPdf(t, pages, root)
+	loaded, err := reader.GetStructTreeRoot()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if loaded == nil || len(loaded.K) != 1 || loaded.RoleMap["Title"] != "H1" {
+		t.Fatalf("Wrong structure tree %+v", loaded)
+	}
+	kids := loaded.K[0].Kids
+	if loaded.K[0].S != "Document" || len(kids) != 3 || kids[0].Element.S != "Title" ||
+		kids[1].Element.Alt != "Chart" || kids[2].Element.S != "Link" {
+		t.Fatalf("Wrong elements %+v", loaded.K[0])
+	}
+	figureKids := kids[1].Element.Kids
+	if len(figureKids) != 2 || figureKids[0].Page != reader.PageList[0] || figureKids[0].MCID != 1 ||
+		figureKids[1].Page != reader.PageList[1] || figureKids[1].MCID != 0 {
+		t.Errorf("Wrong marked content %+v %+v", figureKids[0], figureKids[1])
+	}
+	linkKids := kids[2].Element.Kids
+	if len(linkKids) != 1 || linkKids[0].Object == nil || linkKids[0].Page != reader.PageList[1] {
+		t.Errorf("Wrong link object %+v", linkKids)
+	}
+
+	// Edited: the new MCIDs and keys follow the existing ones.
+	if mcid := loaded.NewMCID(reader.PageList[0]); mcid != 2 {
+		t.Errorf("Wrong new MCID %d", mcid)
+	}
+	if mcid := loaded.NewMCID(reader.PageList[1]); mcid != 1 {
+		t.Errorf("Wrong new MCID %d", mcid)
+	}
+	para := NewPdfStructElement("P")
+	para.AddMarkedContent(reader.PageList[0], 2)
+	loaded.K[0].AddKid(para)
+
+	reader = writeTestTaggedPdf(t, reader.PageList, loaded)
+	catalog, err := reader.GetTrailer()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	rootObj := traceDirect(reader, catalog.Get("Root")).(*PdfObjectDictionary)
+	structRoot := traceDirect(reader, rootObj.Get("StructTreeRoot")).(*PdfObjectDictionary)
+	parentTree := traceDirect(reader, structRoot.Get("ParentTree")).(*PdfObjectDictionary)
+	nums := traceDirect(reader, parentTree.Get("Nums")).(*PdfObjectArray)
+	// Pages 0 and 1, and the link annotation.
+	if len(*nums) != 6 {
+		t.Fatalf("Wrong parent tree %s", nums)
+	}
+	page0 := traceDirect(reader, (*nums)[1]).(*PdfObjectArray)
+	if len(*page0) != 3 {
+		t.Errorf("Wrong page parent tree entry %s", page0)
+	}
+	if next, ok := traceDirect(reader, structRoot.Get("ParentTreeNextKey")).(*PdfObjectInteger); !ok || *next != 3 {
+		t.Errorf("Wrong next key %v", structRoot.Get("ParentTreeNextKey"))
+	}
+	loaded, err = reader.GetStructTreeRoot()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if kids := loaded.K[0].Kids; len(kids) != 4 || kids[3].Element.S != "P" {
+		t.Errorf("Edited element not written")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfTabOrder is the order in which the annotations of a page, e.g. the widgets of form fields, are navigated with
+// the keyboard (the Tabs entry of the page).
+type PdfTabOrder string
+
+const (
+	// The order of the annotations of the page, unless the viewer applies its own.
+	TabOrderNone PdfTabOrder = ""
+	// Rows from top to bottom, each from left to right.
+	TabOrderRow PdfTabOrder = "R"
+	// Columns from left to right, each from top to bottom.
+	TabOrderColumn PdfTabOrder = "C"
+	// The order of the logical structure of the document.
+	TabOrderStructure PdfTabOrder = "S"
+)
+
+// GetTabOrder returns the tab order of the page.
+func (this *PdfPage) GetTabOrder() PdfTabOrder {
+	name, ok := TraceToDirectObject(this.Tabs).(*PdfObjectName)
+	if !ok {
+		return TabOrderNone
+	}
+	return PdfTabOrder(*name)
+}
+
+// SetTabOrder sets the tab order of the page.  The order of the annotations is not changed, see OrderAnnotations.
+func (this *PdfPage) SetTabOrder(order PdfTabOrder) {
+	if order == TabOrderNone {
+		this.Tabs = nil
+		return
+	}
+	this.Tabs = MakeName(string(order))
+}
+
+// SetAnnotationOrder moves the annotations of the page in the order given, before the other annotations, for the
+// pages navigated in the order of their annotations (TabOrderNone).  The annotations must be annotations of the page.
+func (this *PdfPage) SetAnnotationOrder(annots []*PdfAnnotation) error {
+	index := map[*PdfAnnotation]int{}
+	for i, annot := range this.Annotations {
+		index[annot] = i
+	}
+	ordered := make([]*PdfAnnotation, 0, len(this.Annotations))
+	moved := map[*PdfAnnotation]bool{}
+	for _, annot := range annots {
+		if _, has := index[annot]; !has {
+			common.Log.Debug("ERROR: Annotation not on the page: %v", annot)
+			return errors.New("Annotation not on the page")
+		}
+		if !moved[annot] {
+			moved[annot] = true
+			ordered = append(ordered, annot)
+		}
+	}
+	for _, annot := range this.Annotations {
+		if !moved[annot] {
+			ordered = append(ordered, annot)
+		}
+	}
+	this.Annotations = ordered
+	return nil
+}
+
+// OrderAnnotations sorts the annotations of the page by their position in the tab order, row or column, and sets the
+// tab order of the page, so that viewers not applying the tab order also navigate the annotations in that order.
+// Annotations are in the same row when their vertical centers are within the height of the first of the row (the
+// width for columns).  Annotations without a valid rectangle are moved last.
+func (this *PdfPage) OrderAnnotations(order PdfTabOrder) error {
+	if order != TabOrderRow && order != TabOrderColumn {
+		return errors.New("Annotations only ordered by row or column")
+	}
+
+	type placedAnnot struct {
+		annot *PdfAnnotation
+		rect  *PdfRectangle
+	}
+	placed := []placedAnnot{}
+	unplaced := []*PdfAnnotation{}
+	for _, annot := range this.Annotations {
+		arr, ok := TraceToDirectObject(annot.Rect).(*PdfObjectArray)
+		if !ok {
+			unplaced = append(unplaced, annot)
+			continue
+		}
+		rect, err := NewPdfRectangle(*arr)
+		if err != nil {
+			unplaced = append(unplaced, annot)
+			continue
+		}
+		placed = append(placed, placedAnnot{annot, rect})
+	}
+
+	// The position across the lines (from the top or the left), the extent of the lines and the position along them.
+	across := func(r *PdfRectangle) (float64, float64, float64) {
+		if order == TabOrderRow {
+			return -r.Ury, r.Ury - r.Lly, r.Llx
+		}
+		return r.Llx, r.Urx - r.Llx, -r.Ury
+	}
+	sort.SliceStable(placed, func(i, j int) bool {
+		pi, _, _ := across(placed[i].rect)
+		pj, _, _ := across(placed[j].rect)
+		return pi < pj
+	})
+
+	// Grouped in lines, then each line sorted along it.
+	line := make([]int, len(placed))
+	for i, start := 0, 0; i < len(placed); i++ {
+		startPos, extent, _ := across(placed[start].rect)
+		pos, size, _ := across(placed[i].rect)
+		if center := pos + size/2; center > startPos+extent {
+			start = i
+		}
+		line[i] = start
+	}
+	indices := make([]int, len(placed))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		i, j := indices[a], indices[b]
+		if line[i] != line[j] {
+			return line[i] < line[j]
+		}
+		_, _, ai := across(placed[i].rect)
+		_, _, aj := across(placed[j].rect)
+		return ai < aj
+	})
+
+	annots := make([]*PdfAnnotation, 0, len(this.Annotations))
+	for _, i := range indices {
+		annots = append(annots, placed[i].annot)
+	}
+	this.Annotations = append(annots, unplaced...)
+	this.SetTabOrder(order)
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestOrderAnnotations(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+	page.Resources = NewPdfPageResources()
+	// Two rows of two widgets, the second slightly lower in the first row, added out of order.
+	rects := map[string][]float64{
+		"b": {300, 695, 400, 715},
+		"d": {300, 600, 400, 620},
+		"a": {50, 700, 150, 720},
+		"c": {50, 600, 150, 620},
+	}
+	annots := map[string]*PdfAnnotation{}
+	for _, name := range []string{"b", "d", "a", "c"} {
+		annot := NewPdfAnnotationWidget().PdfAnnotation
+		annot.Rect = MakeArrayFromFloats(rects[name])
+		annot.NM = MakeString(name)
+		annots[name] = annot
+		page.Annotations = append(page.Annotations, annot)
+	}
+	noRect := NewPdfAnnotationWidget().PdfAnnotation
+	noRect.NM = MakeString("x")
+	page.Annotations = append([]*PdfAnnotation{noRect}, page.Annotations...)
+
+	names := func() string {
+		s := ""
+		for _, annot := range page.Annotations {
+			s += string(*annot.NM.(*PdfObjectString))
+		}
+		return s
+	}
+
+	if err := page.OrderAnnotations(TabOrderRow); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if names() != "abcdx" || page.GetTabOrder() != TabOrderRow {
+		t.Errorf("Wrong row order %s %q", names(), page.GetTabOrder())
+	}
+	if err := page.OrderAnnotations(TabOrderColumn); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if names() != "acbdx" || page.GetTabOrder() != TabOrderColumn {
+		t.Errorf("Wrong column order %s %q", names(), page.GetTabOrder())
+	}
+	if err := page.OrderAnnotations(TabOrderStructure); err == nil {
+		t.Errorf("Structure order should not be sorted")
+	}
+
+	if err := page.SetAnnotationOrder([]*PdfAnnotation{annots["d"], annots["c"]}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if names() != "dcabx" {
+		t.Errorf("Wrong explicit order %s", names())
+	}
+	if err := page.SetAnnotationOrder([]*PdfAnnotation{NewPdfAnnotationWidget().PdfAnnotation}); err == nil {
+		t.Errorf("Annotation not on the page should fail")
+	}
+
+	// The tab order is written and read.
+	w := NewPdfWriter()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&writeSeeker{buf: &buf}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if order := reader.PageList[0].GetTabOrder(); order != TabOrderColumn {
+		t.Errorf("Wrong tab order read %q", order)
+	}
+	if version, ok := reader.catalog.Get("Version").(*PdfObjectName); !ok || *version != "1.5" {
+		t.Errorf("Wrong version %v", reader.catalog.Get("Version"))
+	}
+}
@@ -5,7 +5,38 @@
 
 package textencoding
 
-import "github.com/unidoc/unidoc/common"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// GlyphToRune returns the rune of the glyph name, from the Adobe Glyph List or the uniXXXX and uXXXX[XX] forms,
+// ignoring the suffix of variants (e.g. a.sc).
+func GlyphToRune(glyph string) (rune, bool) {
+	if i := strings.IndexByte(glyph, '.'); i > 0 {
+		glyph = glyph[:i]
+	}
+	if r, found := glyphlistGlyphToRuneMap[glyph]; found {
+		return r, true
+	}
+
+	hexDigits := ""
+	switch {
+	case strings.HasPrefix(glyph, "uni") && len(glyph) == 7:
+		hexDigits = glyph[3:]
+	case strings.HasPrefix(glyph, "u") && len(glyph) >= 5 && len(glyph) <= 7:
+		hexDigits = glyph[1:]
+	default:
+		return 0, false
+	}
+	code, err := strconv.ParseUint(hexDigits, 16, 32)
+	if err != nil || code > 0x10ffff || code >= 0xd800 && code <= 0xdfff {
+		return 0, false
+	}
+	return rune(code), true
+}
 
 func glyphToRune(glyph string, glyphToRuneMap map[string]rune) (rune, bool) {
 	ucode, found := glyphToRuneMap[glyph]
@@ -0,0 +1,478 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+var (
+	oidSignedData         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidAttrTimestampToken = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+)
+
+// The object identifiers of the digest algorithms supported in timestamp requests.
+var timestampHashOIDs = map[crypto.Hash]asn1.ObjectIdentifier{
+	crypto.SHA1:   {1, 3, 14, 3, 2, 26},
+	crypto.SHA256: {2, 16, 840, 1, 101, 3, 4, 2, 1},
+	crypto.SHA384: {2, 16, 840, 1, 101, 3, 4, 2, 2},
+	crypto.SHA512: {2, 16, 840, 1, 101, 3, 4, 2, 3},
+}
+
+// Timestamper obtains RFC 3161 timestamp tokens from a time stamping authority (TSA).
+type Timestamper interface {
+	// Timestamp returns the DER encoded timestamp token (a CMS ContentInfo) of the digest computed with the hash.
+	Timestamp(digest []byte, hash crypto.Hash) ([]byte, error)
+}
+
+// HTTPTimestamper is a Timestamper requesting the tokens from a TSA over HTTP, as specified by RFC 3161.
+type HTTPTimestamper struct {
+	URL string
+
+	// The client sending the requests, e.g. with authentication, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// NewHTTPTimestamper returns a timestamper requesting the tokens from the TSA at the URL.
+func NewHTTPTimestamper(url string) *HTTPTimestamper {
+	return &HTTPTimestamper{URL: url}
+}
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timestampRequest struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timestampResponse struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+// Timestamp requests a timestamp token of the digest from the TSA, with a random nonce and the certificate of the
+// TSA.  The digest and nonce of the token are checked, the signature of the TSA is not verified.
+func (ts *HTTPTimestamper) Timestamp(digest []byte, hash crypto.Hash) ([]byte, error) {
+	req, nonce, err := makeTimestampRequest(digest, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	client := ts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(ts.URL, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		common.Log.Debug("ERROR: TSA response status %s", resp.Status)
+		return nil, fmt.Errorf("TSA response status %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := parseTimestampResponse(data)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ParseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if info.Hash != hash || !bytes.Equal(info.Digest, digest) {
+		common.Log.Debug("ERROR: Timestamp token of another digest")
+		return nil, errors.New("Timestamp token digest mismatch")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		common.Log.Debug("ERROR: Timestamp token nonce %v, expected %v", info.Nonce, nonce)
+		return nil, errors.New("Timestamp token nonce mismatch")
+	}
+	return token, nil
+}
+
+// makeTimestampRequest returns the DER encoded timestamp request (TimeStampReq) of the digest and its nonce.
+func makeTimestampRequest(digest []byte, hash crypto.Hash) ([]byte, *big.Int, error) {
+	oid, has := timestampHashOIDs[hash]
+	if !has {
+		common.Log.Debug("ERROR: Unsupported timestamp hash %v", hash)
+		return nil, nil, errors.New("Unsupported hash")
+	}
+	if len(digest) != hash.Size() {
+		return nil, nil, errors.New("Invalid digest length")
+	}
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := timestampRequest{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oid, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	}
+	data, err := asn1.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, nonce, nil
+}
+
+// parseTimestampResponse returns the timestamp token of the DER encoded response (TimeStampResp) of a TSA.
+func parseTimestampResponse(data []byte) ([]byte, error) {
+	var resp timestampResponse
+	if _, err := asn1.Unmarshal(data, &resp); err != nil {
+		common.Log.Debug("ERROR: Invalid timestamp response: %v", err)
+		return nil, err
+	}
+	// Granted (0) or granted with modifications (1).
+	if resp.Status.Status != 0 && resp.Status.Status != 1 {
+		common.Log.Debug("ERROR: Timestamp request rejected, status %d %v", resp.Status.Status, resp.Status.StatusString)
+		return nil, fmt.Errorf("Timestamp request rejected (status %d): %s", resp.Status.Status,
+			strings.Join(resp.Status.StatusString, ", "))
+	}
+	if len(resp.Token.FullBytes) == 0 {
+		return nil, errors.New("No timestamp token in response")
+	}
+	return resp.Token.FullBytes, nil
+}
+
+// TimestampInfo is the content of a timestamp token (TSTInfo).
+type TimestampInfo struct {
+	Time         time.Time
+	Policy       asn1.ObjectIdentifier
+	SerialNumber *big.Int
+
+	// The timestamped digest and the hash that computed it.
+	Hash   crypto.Hash
+	Digest []byte
+
+	// The nonce of the request, nil if none.
+	Nonce *big.Int
+}
+
+// ParseTimestampToken returns the content of a DER encoded timestamp token.  The signature of the TSA is not
+// verified.
+func ParseTimestampToken(token []byte) (*TimestampInfo, error) {
+	signedData, err := parseSignedData(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(signedData) < 3 {
+		return nil, errors.New("Invalid timestamp token")
+	}
+
+	// The encapsulated content: its type and the TSTInfo in an explicitly tagged octet string.
+	_, encap, err := asn1Elements(signedData[2].FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	var contentType asn1.ObjectIdentifier
+	if len(encap) < 2 {
+		return nil, errors.New("Invalid timestamp token")
+	}
+	if _, err = asn1.Unmarshal(encap[0].FullBytes, &contentType); err != nil {
+		return nil, err
+	}
+	if !contentType.Equal(oidTSTInfo) {
+		common.Log.Debug("ERROR: Timestamp token content type %v", contentType)
+		return nil, errors.New("Not a timestamp token")
+	}
+	var content []byte
+	if _, err = asn1.Unmarshal(encap[1].Bytes, &content); err != nil {
+		return nil, err
+	}
+
+	_, elems, err := asn1Elements(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) < 5 {
+		return nil, errors.New("Invalid timestamp token info")
+	}
+	info := &TimestampInfo{}
+	var imprint messageImprint
+	if _, err = asn1.Unmarshal(elems[1].FullBytes, &info.Policy); err != nil {
+		return nil, err
+	}
+	if _, err = asn1.Unmarshal(elems[2].FullBytes, &imprint); err != nil {
+		return nil, err
+	}
+	if _, err = asn1.Unmarshal(elems[3].FullBytes, &info.SerialNumber); err != nil {
+		return nil, err
+	}
+	if _, err = asn1.UnmarshalWithParams(elems[4].FullBytes, &info.Time, "generalized"); err != nil {
+		return nil, err
+	}
+	info.Digest = imprint.HashedMessage
+	for hash, oid := range timestampHashOIDs {
+		if oid.Equal(imprint.HashAlgorithm.Algorithm) {
+			info.Hash = hash
+		}
+	}
+
+	// Optional accuracy and ordering, followed by the nonce.
+	for _, elem := range elems[5:] {
+		if elem.Class == asn1.ClassUniversal && elem.Tag == asn1.TagInteger {
+			if _, err = asn1.Unmarshal(elem.FullBytes, &info.Nonce); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return info, nil
+}
+
+// AddSignatureTimestamp returns the DER encoded CMS signature with a timestamp token of its signature value, obtained
+// from the timestamper, in the unsigned attributes of its first signer (a signature timestamp, CAdES-T).  The
+// signature may be followed by the zero padding of the Contents of a signature dictionary.  The returned signature is
+// longer, the space reserved for the signature value in the document must account for the token.
+func AddSignatureTimestamp(signature []byte, ts Timestamper) ([]byte, error) {
+	_, contentInfo, err := asn1Elements(signature)
+	if err != nil {
+		return nil, err
+	}
+	signedData, err := parseSignedData(signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(signedData) < 4 {
+		return nil, errors.New("Invalid signature")
+	}
+
+	signerInfos := signedData[len(signedData)-1]
+	if signerInfos.Class != asn1.ClassUniversal || signerInfos.Tag != asn1.TagSet {
+		return nil, errors.New("Invalid signature")
+	}
+	_, signers, err := asn1Elements(signerInfos.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("No signer")
+	}
+	_, signer, err := asn1Elements(signers[0].FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(signer) < 4 {
+		return nil, errors.New("Invalid signature")
+	}
+
+	// The signature value is the first octet string after the version, identifier and digest algorithm.
+	var value []byte
+	for _, elem := range signer[3:] {
+		if elem.Class == asn1.ClassUniversal && elem.Tag == asn1.TagOctetString {
+			value = elem.Bytes
+			break
+		}
+	}
+	if value == nil {
+		return nil, errors.New("No signature value")
+	}
+	digest := sha256.Sum256(value)
+	token, err := ts.Timestamp(digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	attr, err := asn1.Marshal(struct {
+		Type   asn1.ObjectIdentifier
+		Values []asn1.RawValue `asn1:"set"`
+	}{oidAttrTimestampToken, []asn1.RawValue{{FullBytes: token}}})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []asn1.RawValue{{FullBytes: attr}}
+	last := signer[len(signer)-1]
+	if last.Class == asn1.ClassContextSpecific && last.Tag == 1 {
+		_, existing, err := asn1Elements(last.FullBytes)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(existing, attrs...)
+		signer = signer[:len(signer)-1]
+	}
+
+	// Encode again from the innermost changed value outwards.
+	unsigned, err := asn1Constructed(asn1.ClassContextSpecific, 1, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if signers[0], err = asn1Constructed(asn1.ClassUniversal, asn1.TagSequence, append(signer, unsigned)); err != nil {
+		return nil, err
+	}
+	if signedData[len(signedData)-1], err = asn1Constructed(asn1.ClassUniversal, asn1.TagSet, signers); err != nil {
+		return nil, err
+	}
+	sd, err := asn1Constructed(asn1.ClassUniversal, asn1.TagSequence, signedData)
+	if err != nil {
+		return nil, err
+	}
+	if contentInfo[1], err = asn1Constructed(asn1.ClassContextSpecific, 0, []asn1.RawValue{sd}); err != nil {
+		return nil, err
+	}
+	ci, err := asn1Constructed(asn1.ClassUniversal, asn1.TagSequence, contentInfo)
+	if err != nil {
+		return nil, err
+	}
+	return ci.FullBytes, nil
+}
+
+// parseSignedData returns the elements of the SignedData of a DER encoded CMS ContentInfo.  Trailing data is ignored.
+func parseSignedData(data []byte) ([]asn1.RawValue, error) {
+	_, contentInfo, err := asn1Elements(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(contentInfo) < 2 {
+		return nil, errors.New("Invalid CMS content")
+	}
+	var contentType asn1.ObjectIdentifier
+	if _, err = asn1.Unmarshal(contentInfo[0].FullBytes, &contentType); err != nil {
+		return nil, err
+	}
+	if !contentType.Equal(oidSignedData) {
+		common.Log.Debug("ERROR: CMS content type %v", contentType)
+		return nil, errors.New("Not a CMS signed data")
+	}
+	_, signedData, err := asn1Elements(contentInfo[1].Bytes)
+	return signedData, err
+}
+
+// asn1Elements returns the first DER encoded value of the data, constructed, and its elements.
+func asn1Elements(data []byte) (asn1.RawValue, []asn1.RawValue, error) {
+	var outer asn1.RawValue
+	if _, err := asn1.Unmarshal(data, &outer); err != nil {
+		common.Log.Debug("ERROR: Invalid DER data: %v", err)
+		return outer, nil, err
+	}
+	if !outer.IsCompound {
+		return outer, nil, errors.New("DER value not constructed")
+	}
+
+	elems := []asn1.RawValue{}
+	rest := outer.Bytes
+	for len(rest) > 0 {
+		var elem asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &elem)
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid DER element: %v", err)
+			return outer, nil, err
+		}
+		elems = append(elems, elem)
+	}
+	return outer, elems, nil
+}
+
+// asn1Constructed returns the constructed value of the class and tag with the elements.
+func asn1Constructed(class, tag int, elems []asn1.RawValue) (asn1.RawValue, error) {
+	content := []byte{}
+	for _, elem := range elems {
+		content = append(content, elem.FullBytes...)
+	}
+	value := asn1.RawValue{Class: class, Tag: tag, IsCompound: true, Bytes: content}
+	full, err := asn1.Marshal(value)
+	if err != nil {
+		return value, err
+	}
+	value.FullBytes = full
+	return value, nil
+}
+
+// docTimestampSize is the space reserved for the timestamp token of a document timestamp, in bytes.
+const docTimestampSize = 16384
+
+// docTimestampSigner signs documents with document timestamps obtained from a timestamper.
+type docTimestampSigner struct {
+	ts Timestamper
+}
+
+func (s *docTimestampSigner) InitSignature(sig *PdfObjectDictionary) error {
+	sig.Set("Type", MakeName("DocTimeStamp"))
+	sig.Set("Filter", MakeName("Adobe.PPKLite"))
+	sig.Set("SubFilter", MakeName("ETSI.RFC3161"))
+	return nil
+}
+
+func (s *docTimestampSigner) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return s.ts.Timestamp(digest[:], crypto.SHA256)
+}
+
+func (s *docTimestampSigner) MaxSize() int {
+	return docTimestampSize
+}
+
+// CommitDocumentTimestamp commits the transaction like Commit, with a document timestamp signature (DocTimeStamp,
+// ETSI.RFC3161) of the whole updated document: an invisible signature field on the first page, whose value is the
+// timestamp token obtained from the timestamper.  Adding the validation data of the signatures of the document with
+// SetDSS in the same transaction extends their validity (PAdES-LTA).
+func (tx *PdfTransaction) CommitDocumentTimestamp(w io.Writer, ts Timestamper) error {
+	return tx.commitSigned(w, "Timestamp", &docTimestampSigner{ts})
+}
+
+// getEntry returns the value of the key of a staged dictionary for modification: the staged copy of an indirect
+// value, the direct value, or a new value made by create if missing.
+func (tx *PdfTransaction) getEntry(dict *PdfObjectDictionary, key PdfObjectName, create func() PdfObject) (PdfObject,
+	error) {
+	switch t := dict.Get(key).(type) {
+	case nil:
+		val := create()
+		dict.Set(key, val)
+		return val, nil
+	case *PdfObjectReference:
+		obj, err := tx.GetObject(t.ObjectNumber)
+		if err != nil {
+			return nil, err
+		}
+		ind, ok := obj.(*PdfIndirectObject)
+		if !ok {
+			return nil, ErrTypeError
+		}
+		return ind.PdfObject, nil
+	default:
+		return t, nil
+	}
+}
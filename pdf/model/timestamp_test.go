@@ -0,0 +1,287 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// makeTestTimestampToken returns an unsigned timestamp token of the SHA-256 digest with the nonce.
+func makeTestTimestampToken(t *testing.T, digest []byte, nonce *big.Int) []byte {
+	marshal := func(val interface{}) asn1.RawValue {
+		data, err := asn1.Marshal(val)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		return asn1.RawValue{FullBytes: data}
+	}
+	constructed := func(class, tag int, elems ...asn1.RawValue) asn1.RawValue {
+		val, err := asn1Constructed(class, tag, elems)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		return val
+	}
+
+	info := constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(1),
+		marshal(asn1.ObjectIdentifier{1, 2, 3}),
+		marshal(messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: timestampHashOIDs[crypto.SHA256]},
+			HashedMessage: digest,
+		}),
+		marshal(big.NewInt(42)),
+		marshal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		marshal(nonce))
+	encap := constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(oidTSTInfo),
+		constructed(asn1.ClassContextSpecific, 0, marshal(info.FullBytes)))
+	signedData := constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(3),
+		constructed(asn1.ClassUniversal, asn1.TagSet),
+		encap,
+		constructed(asn1.ClassUniversal, asn1.TagSet))
+	return constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(oidSignedData),
+		constructed(asn1.ClassContextSpecific, 0, signedData)).FullBytes
+}
+
+// testTimestamper returns test tokens and records the timestamped digests.
+type testTimestamper struct {
+	t       *testing.T
+	digests [][]byte
+}
+
+func (ts *testTimestamper) Timestamp(digest []byte, hash crypto.Hash) ([]byte, error) {
+	ts.digests = append(ts.digests, digest)
+	return makeTestTimestampToken(ts.t, digest, big.NewInt(1)), nil
+}
+
+func TestHTTPTimestamper(t *testing.T) {
+	status := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/timestamp-query" {
+			t.Errorf("Wrong content type %q", r.Header.Get("Content-Type"))
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		var req timestampRequest
+		if _, err := asn1.Unmarshal(data, &req); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if req.Version != 1 || !req.CertReq || req.Nonce == nil ||
+			!req.MessageImprint.HashAlgorithm.Algorithm.Equal(timestampHashOIDs[crypto.SHA256]) {
+			t.Errorf("Wrong request %+v", req)
+		}
+
+		resp := timestampResponse{Status: pkiStatusInfo{Status: status}}
+		if status == 0 {
+			resp.Token = asn1.RawValue{FullBytes: makeTestTimestampToken(t, req.MessageImprint.HashedMessage, req.Nonce)}
+		} else {
+			resp.Status.StatusString = []string{"Bad request"}
+		}
+		data, err := asn1.Marshal(resp)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	digest := sha256.Sum256([]byte("data"))
+	ts := NewHTTPTimestamper(server.URL)
+	token, err := ts.Timestamp(digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	info, err := ParseTimestampToken(token)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if info.Hash != crypto.SHA256 || !bytes.Equal(info.Digest, digest[:]) || info.SerialNumber.Int64() != 42 ||
+		!info.Time.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Wrong token info %+v", info)
+	}
+
+	// Rejected request.
+	status = 2
+	if _, err := ts.Timestamp(digest[:], crypto.SHA256); err == nil {
+		t.Errorf("Rejected request not reported")
+	}
+}
+
+func TestAddSignatureTimestamp(t *testing.T) {
+	marshal := func(val interface{}) asn1.RawValue {
+		data, err := asn1.Marshal(val)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		return asn1.RawValue{FullBytes: data}
+	}
+	constructed := func(class, tag int, elems ...asn1.RawValue) asn1.RawValue {
+		val, _ := asn1Constructed(class, tag, elems)
+		return val
+	}
+	algorithm := marshal(pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 3}})
+	signer := constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(1),
+		constructed(asn1.ClassUniversal, asn1.TagSequence, marshal("issuer"), marshal(7)),
+		algorithm,
+		algorithm,
+		marshal([]byte("signature value")))
+	signedData := constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(1),
+		constructed(asn1.ClassUniversal, asn1.TagSet, algorithm),
+		constructed(asn1.ClassUniversal, asn1.TagSequence, marshal(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1})),
+		constructed(asn1.ClassUniversal, asn1.TagSet, signer))
+	signature := constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(oidSignedData),
+		constructed(asn1.ClassContextSpecific, 0, signedData)).FullBytes
+	// Padded as in a signature dictionary.
+	signature = append(signature, make([]byte, 32)...)
+
+	ts := &testTimestamper{t: t}
+	timestamped, err := AddSignatureTimestamp(signature, ts)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := sha256.Sum256([]byte("signature value"))
+	if len(ts.digests) != 1 || !bytes.Equal(ts.digests[0], expected[:]) {
+		t.Errorf("Wrong timestamped digest")
+	}
+
+	// The token is the unsigned attribute of the signer.
+	elems, err := parseSignedData(timestamped)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, signers, _ := asn1Elements(elems[3].FullBytes)
+	_, signerElems, _ := asn1Elements(signers[0].FullBytes)
+	if len(signerElems) != 6 {
+		t.Fatalf("Unsigned attributes not added")
+	}
+	_, attrs, _ := asn1Elements(signerElems[5].FullBytes)
+	var attr struct {
+		Type   asn1.ObjectIdentifier
+		Values []asn1.RawValue `asn1:"set"`
+	}
+	if _, err := asn1.Unmarshal(attrs[0].FullBytes, &attr); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !attr.Type.Equal(oidAttrTimestampToken) || len(attr.Values) != 1 {
+		t.Fatalf("Wrong attribute %v", attr.Type)
+	}
+	info, err := ParseTimestampToken(attr.Values[0].FullBytes)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(info.Digest, expected[:]) {
+		t.Errorf("Wrong token digest")
+	}
+
+	// A truncated signer.
+	signer = constructed(asn1.ClassUniversal, asn1.TagSequence, marshal(1), algorithm)
+	signedData = constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(1),
+		constructed(asn1.ClassUniversal, asn1.TagSet, algorithm),
+		constructed(asn1.ClassUniversal, asn1.TagSequence, marshal(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1})),
+		constructed(asn1.ClassUniversal, asn1.TagSet, signer))
+	signature = constructed(asn1.ClassUniversal, asn1.TagSequence,
+		marshal(oidSignedData),
+		constructed(asn1.ClassContextSpecific, 0, signedData)).FullBytes
+	if _, err = AddSignatureTimestamp(signature, ts); err == nil {
+		t.Errorf("Missing error for a truncated signer")
+	}
+}
+
+func TestDocumentTimestamp(t *testing.T) {
+	data := makeCoveredTestPdf(0)
+	reader, err := NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	tx, err := reader.Begin()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	dss := NewPdfDSS()
+	dss.AddValidationData([]byte{1, 2, 3, 4, 0, 0, 0, 0}, [][]byte{[]byte("cert1"), []byte("cert2")},
+		[][]byte{[]byte("ocsp")}, nil)
+	dss.AddValidationData(nil, [][]byte{[]byte("cert2")}, nil, [][]byte{[]byte("crl")})
+	if err = tx.SetDSS(dss); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	ts := &testTimestamper{t: t}
+	var buf bytes.Buffer
+	if err = tx.CommitDocumentTimestamp(&buf, ts); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data = buf.Bytes()
+
+	reader, err = NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var sig *PdfObjectDictionary
+	for _, field := range reader.AcroForm.AllFields() {
+		if field.GetFullName() == "Timestamp1" {
+			sig, _ = TraceToDirectObject(field.V).(*PdfObjectDictionary)
+		}
+	}
+	if sig == nil {
+		t.Fatalf("Timestamp field not found")
+	}
+
+	// The timestamp covers the whole document, and the token is the timestamp of the signed ranges.
+	byteRange, end := checkByteRange(data, sig)
+	if end != len(data) {
+		t.Fatalf("Wrong ByteRange %v", byteRange)
+	}
+	h := sha256.New()
+	h.Write(data[:byteRange[1]])
+	h.Write(data[byteRange[2]:])
+	if len(ts.digests) != 1 || !bytes.Equal(ts.digests[0], h.Sum(nil)) {
+		t.Errorf("Wrong timestamped digest")
+	}
+	contents := []byte(*sig.Get("Contents").(*PdfObjectString))
+	if info, err := ParseTimestampToken(contents); err != nil || !bytes.Equal(info.Digest, ts.digests[0]) {
+		t.Errorf("Wrong token in Contents: %v", err)
+	}
+
+	// The existing signature allows the validation data and the timestamp.
+	coverage := getCoverage(t, data)
+	if coverage.Status != SignatureAllowedChanges {
+		t.Errorf("Wrong status %v: %+v", coverage.Status, coverage.Changes)
+	}
+
+	loaded, err := reader.GetDSS()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if loaded == nil || len(loaded.Certs) != 2 || len(loaded.OCSPs) != 1 || string(loaded.CRLs[0]) != "crl" {
+		t.Fatalf("Wrong DSS %+v", loaded)
+	}
+	vri := loaded.VRI[GetVRIKey([]byte{1, 2, 3, 4, 0, 0, 0, 0})]
+	if vri == nil || len(vri.Certs) != 2 || string(vri.OCSPs[0]) != "ocsp" || len(vri.CRLs) != 0 {
+		t.Errorf("Wrong VRI %+v", loaded.VRI)
+	}
+	// Shared streams.
+	if bytes.Count(data, []byte("/FlateDecode")) != 4 {
+		t.Errorf("DSS streams not shared")
+	}
+}
@@ -0,0 +1,369 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfTransaction stages modifications of a loaded document in a changeset.  The changes are either written out as
+// an incremental update of the original file (Commit) or discarded (Rollback).  The document loaded by the
+// PdfReader is not affected by the changes staged in the transaction.
+//
+// Example:
+//   tx, err := reader.Begin()
+//   obj, err := tx.GetObject(pageObjNum)  // Copy of the page object to modify.
+//   ...
+//   err = tx.Commit(w)                    // Or tx.Rollback() to discard the changes.
+type PdfTransaction struct {
+	reader *PdfReader
+
+	// Staged objects by object number: modified copies of existing objects and new objects.
+	changes map[int64]PdfObject
+
+	// Generation numbers of the existing objects in the changeset.
+	generations map[int64]int64
+
+	// Objects removed by the transaction.
+	removed map[int64]bool
+
+	// Next free object number.
+	nextObjNum int64
+
+	closed bool
+}
+
+// Begin starts a new transaction for editing the document.  Transactions are not supported for encrypted documents.
+func (this *PdfReader) Begin() (*PdfTransaction, error) {
+	isEncrypted, err := this.IsEncrypted()
+	if err != nil {
+		return nil, err
+	}
+	if isEncrypted {
+		common.Log.Debug("ERROR: Transactions not supported for encrypted documents")
+		return nil, errors.New("Transactions not supported for encrypted documents")
+	}
+
+	trailer, err := this.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &PdfTransaction{}
+	tx.reader = this
+	tx.changes = map[int64]PdfObject{}
+	tx.generations = map[int64]int64{}
+	tx.removed = map[int64]bool{}
+
+	if size, ok := TraceToDirectObject(trailer.Get("Size")).(*PdfObjectInteger); ok {
+		tx.nextObjNum = int64(*size)
+	}
+	for _, num := range this.GetObjectNums() {
+		if int64(num) >= tx.nextObjNum {
+			tx.nextObjNum = int64(num) + 1
+		}
+	}
+	if tx.nextObjNum < 1 {
+		tx.nextObjNum = 1
+	}
+
+	return tx, nil
+}
+
+// GetObject returns a copy of the indirect object (*PdfIndirectObject or *PdfObjectStream) with the specified object
+// number for modification.  The copy is staged in the changeset, i.e. the modifications made to it are written out
+// on Commit.  Subsequent calls return the same copy.
+func (tx *PdfTransaction) GetObject(objNum int64) (PdfObject, error) {
+	if tx.closed {
+		return nil, errors.New("Transaction closed")
+	}
+	if obj, has := tx.changes[objNum]; has {
+		return obj, nil
+	}
+	if tx.removed[objNum] {
+		common.Log.Debug("ERROR: Object %d removed in transaction", objNum)
+		return nil, errors.New("Object removed")
+	}
+
+	obj, err := tx.reader.GetIndirectObjectByNumber(int(objNum))
+	if err != nil {
+		return nil, err
+	}
+
+	var staged PdfObject
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		ind := &PdfIndirectObject{}
+		ind.PdfObjectReference = t.PdfObjectReference
+		ind.PdfObject = copyObject(t.PdfObject)
+		staged = ind
+	case *PdfObjectStream:
+		if err := t.Load(); err != nil {
+			return nil, err
+		}
+		stream := &PdfObjectStream{}
+		stream.PdfObjectReference = t.PdfObjectReference
+		stream.PdfObjectDictionary = copyObject(t.PdfObjectDictionary).(*PdfObjectDictionary)
+		stream.Stream = append([]byte{}, t.Stream...)
+		staged = stream
+	default:
+		common.Log.Debug("ERROR: Object %d not found (%T)", objNum, obj)
+		return nil, errors.New("Object not found")
+	}
+
+	tx.changes[objNum] = staged
+	tx.generations[objNum] = getGenerationNumber(obj)
+	return staged, nil
+}
+
+// AddObject adds a new indirect object (*PdfIndirectObject or *PdfObjectStream) to the changeset and assigns it an
+// object number.  New indirect objects referenced from the staged objects are added automatically on Commit.
+func (tx *PdfTransaction) AddObject(obj PdfObject) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+
+	switch obj.(type) {
+	case *PdfIndirectObject, *PdfObjectStream:
+	default:
+		common.Log.Debug("ERROR: Not an indirect object (%T)", obj)
+		return ErrTypeError
+	}
+
+	tx.addObject(obj)
+	return nil
+}
+
+// addObject assigns the next free object number to an indirect or stream object and stages it.
+func (tx *PdfTransaction) addObject(obj PdfObject) {
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		t.ObjectNumber = tx.nextObjNum
+		t.GenerationNumber = 0
+	case *PdfObjectStream:
+		t.ObjectNumber = tx.nextObjNum
+		t.GenerationNumber = 0
+	}
+
+	tx.changes[tx.nextObjNum] = obj
+	tx.nextObjNum++
+}
+
+// RemoveObject marks the object with the specified number as free in the update.
+func (tx *PdfTransaction) RemoveObject(objNum int64) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+
+	if _, has := tx.changes[objNum]; !has {
+		obj, err := tx.reader.GetIndirectObjectByNumber(int(objNum))
+		if err != nil {
+			return err
+		}
+		tx.generations[objNum] = getGenerationNumber(obj)
+	}
+	delete(tx.changes, objNum)
+	tx.removed[objNum] = true
+	return nil
+}
+
+// Rollback discards all changes staged in the transaction and closes it.
+func (tx *PdfTransaction) Rollback() {
+	tx.changes = map[int64]PdfObject{}
+	tx.removed = map[int64]bool{}
+	tx.closed = true
+}
+
+// Commit writes the original document followed by an incremental update containing the staged changes to w,
+// and closes the transaction.
+func (tx *PdfTransaction) Commit(w io.Writer) error {
+	if tx.closed {
+		return errors.New("Transaction closed")
+	}
+	defer func() { tx.closed = true }()
+
+	trailer, err := tx.reader.GetTrailer()
+	if err != nil {
+		return err
+	}
+
+	// Assign numbers to new objects referenced from the staged objects.
+	nums := []int64{}
+	for num := range tx.changes {
+		nums = append(nums, num)
+	}
+	visited := map[PdfObject]bool{}
+	for _, num := range nums {
+		tx.addReferencedObjects(tx.changes[num], visited)
+	}
+
+	buf := &bytes.Buffer{}
+	_, err = tx.reader.parser.WriteOriginal(buf)
+	if err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+
+	offsets := map[int64]int64{}
+	nums = []int64{}
+	for num := range tx.changes {
+		nums = append(nums, num)
+	}
+	for num := range tx.removed {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+
+	for _, num := range nums {
+		obj, has := tx.changes[num]
+		if !has {
+			continue
+		}
+		offsets[num] = int64(buf.Len())
+		gen := tx.generations[num]
+
+		switch t := obj.(type) {
+		case *PdfIndirectObject:
+			buf.WriteString(fmt.Sprintf("%d %d obj\n", num, gen))
+			buf.WriteString(t.PdfObject.DefaultWriteString())
+			buf.WriteString("\nendobj\n")
+		case *PdfObjectStream:
+			t.PdfObjectDictionary.Set("Length", MakeInteger(int64(len(t.Stream))))
+			buf.WriteString(fmt.Sprintf("%d %d obj\n", num, gen))
+			buf.WriteString(t.PdfObjectDictionary.DefaultWriteString())
+			buf.WriteString("\nstream\n")
+			buf.Write(t.Stream)
+			buf.WriteString("\nendstream\nendobj\n")
+		}
+	}
+
+	// Cross reference section with a subsection for each run of consecutive object numbers.
+	xrefOffset := int64(buf.Len())
+	buf.WriteString("xref\r\n")
+	for i := 0; i < len(nums); {
+		j := i + 1
+		for j < len(nums) && nums[j] == nums[j-1]+1 {
+			j++
+		}
+		buf.WriteString(fmt.Sprintf("%d %d\r\n", nums[i], j-i))
+		for _, num := range nums[i:j] {
+			if tx.removed[num] {
+				buf.WriteString(fmt.Sprintf("%.10d %.5d f\r\n", 0, tx.generations[num]+1))
+			} else {
+				buf.WriteString(fmt.Sprintf("%.10d %.5d n\r\n", offsets[num], tx.generations[num]))
+			}
+		}
+		i = j
+	}
+
+	newTrailer := MakeDict()
+	for _, key := range []PdfObjectName{"Root", "Info", "ID"} {
+		if val := trailer.Get(key); val != nil {
+			newTrailer.Set(key, val)
+		}
+	}
+	newTrailer.Set("Size", MakeInteger(tx.nextObjNum))
+	newTrailer.Set("Prev", MakeInteger(tx.reader.parser.GetXrefOffset()))
+	buf.WriteString("trailer\n")
+	buf.WriteString(newTrailer.DefaultWriteString())
+	buf.WriteString("\n")
+	buf.WriteString(fmt.Sprintf("startxref\n%d\n", xrefOffset))
+	buf.WriteString("%%EOF\n")
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// addReferencedObjects adds the new (not yet numbered) indirect objects referenced from obj to the changeset.
+func (tx *PdfTransaction) addReferencedObjects(obj PdfObject, visited map[PdfObject]bool) {
+	if visited[obj] {
+		return
+	}
+
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		if t.ObjectNumber == 0 {
+			tx.addObject(t)
+		} else if tx.changes[t.ObjectNumber] != t {
+			// Unchanged object of the original document.
+			return
+		}
+		visited[obj] = true
+		tx.addReferencedObjects(t.PdfObject, visited)
+	case *PdfObjectStream:
+		if t.ObjectNumber == 0 {
+			tx.addObject(t)
+		} else if tx.changes[t.ObjectNumber] != t {
+			return
+		}
+		visited[obj] = true
+		tx.addReferencedObjects(t.PdfObjectDictionary, visited)
+	case *PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			tx.addReferencedObjects(t.Get(key), visited)
+		}
+	case *PdfObjectArray:
+		for _, val := range *t {
+			tx.addReferencedObjects(val, visited)
+		}
+	}
+}
+
+// getGenerationNumber returns the generation number of an indirect or stream object.
+func getGenerationNumber(obj PdfObject) int64 {
+	switch t := obj.(type) {
+	case *PdfIndirectObject:
+		return t.GenerationNumber
+	case *PdfObjectStream:
+		return t.GenerationNumber
+	}
+	return 0
+}
+
+// copyObject returns a deep copy of a direct object.  Indirect objects and references contained in the object
+// are not copied, i.e. remain references to the same objects.
+func copyObject(obj PdfObject) PdfObject {
+	switch t := obj.(type) {
+	case *PdfObjectDictionary:
+		dict := MakeDict()
+		for _, key := range t.Keys() {
+			dict.Set(key, copyObject(t.Get(key)))
+		}
+		return dict
+	case *PdfObjectArray:
+		arr := PdfObjectArray{}
+		for _, val := range *t {
+			arr = append(arr, copyObject(val))
+		}
+		return &arr
+	case *PdfObjectInteger:
+		val := *t
+		return &val
+	case *PdfObjectFloat:
+		val := *t
+		return &val
+	case *PdfObjectBool:
+		val := *t
+		return &val
+	case *PdfObjectName:
+		val := *t
+		return &val
+	case *PdfObjectString:
+		val := *t
+		return &val
+	case *PdfObjectReference:
+		val := *t
+		return &val
+	}
+	return obj
+}
@@ -0,0 +1,126 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/internal/testutils"
+)
+
+// makeTestPdf returns a minimal single page PDF document.
+func makeTestPdf() []byte {
+	return testutils.MakePdf([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	})
+}
+
+func TestTransactionCommit(t *testing.T) {
+	original := makeTestPdf()
+	reader, err := NewPdfReader(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	tx, err := reader.Begin()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	obj, err := tx.GetObject(3)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	pageDict, ok := obj.(*PdfIndirectObject).PdfObject.(*PdfObjectDictionary)
+	if !ok {
+		t.Fatalf("Page not a dictionary")
+	}
+	pageDict.Set("Rotate", MakeInteger(90))
+
+	// New object referenced from the page, numbered on commit.
+	info := &PdfIndirectObject{}
+	info.PdfObject = MakeDict()
+	info.PdfObject.(*PdfObjectDictionary).Set("Note", MakeString("added"))
+	pageDict.Set("PieceInfo", info)
+
+	// The loaded document is not affected.
+	page, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if page.Rotate != nil {
+		t.Fatalf("Original document modified")
+	}
+
+	out := &bytes.Buffer{}
+	err = tx.Commit(out)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), original) {
+		t.Fatalf("Output does not start with the original document")
+	}
+	if info.ObjectNumber != 4 {
+		t.Fatalf("Unexpected object number of new object: %d", info.ObjectNumber)
+	}
+
+	reader2, err := NewPdfReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err = reader2.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if page.Rotate == nil || *page.Rotate != 90 {
+		t.Fatalf("Rotate not updated: %v", page.Rotate)
+	}
+	obj, err = reader2.GetIndirectObjectByNumber(4)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	ind, ok := obj.(*PdfIndirectObject)
+	if !ok {
+		t.Fatalf("New object missing (%T)", obj)
+	}
+	dict, ok := ind.PdfObject.(*PdfObjectDictionary)
+	if !ok || dict.Get("Note") == nil {
+		t.Fatalf("New object invalid: %v", ind.PdfObject)
+	}
+
+	// The transaction is closed.
+	if err := tx.Commit(out); err == nil {
+		t.Fatalf("Commit of closed transaction should fail")
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	reader, err := NewPdfReader(bytes.NewReader(makeTestPdf()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	tx, err := reader.Begin()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	_, err = tx.GetObject(3)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	tx.Rollback()
+
+	if _, err := tx.GetObject(3); err == nil {
+		t.Fatalf("Rolled back transaction should be closed")
+	}
+	if err := tx.Commit(&bytes.Buffer{}); err == nil {
+		t.Fatalf("Commit of rolled back transaction should fail")
+	}
+}
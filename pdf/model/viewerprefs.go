@@ -0,0 +1,229 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+)
+
+// PdfDuplex is the paper handling option of the print dialog for the document.
+type PdfDuplex string
+
+const (
+	DuplexNone          PdfDuplex = ""
+	DuplexSimplex       PdfDuplex = "Simplex"
+	DuplexFlipShortEdge PdfDuplex = "DuplexFlipShortEdge"
+	DuplexFlipLongEdge  PdfDuplex = "DuplexFlipLongEdge"
+)
+
+// PdfViewerPreferences represents the print related entries of the viewer preferences of a document (12.2 Viewer
+// Preferences), the presets of the print dialog used by print automation systems.  The other viewer preferences of
+// a document read are kept as is.
+type PdfViewerPreferences struct {
+	// Page scaling of the print dialog: "None" for printing at actual size, "AppDefault", or "" if not set.
+	PrintScaling string
+
+	// Whether the paper tray is chosen by the page size, instead of the printer settings.  Unset if nil.
+	PickTrayByPDFSize *bool
+
+	// Number of copies to print (1 to 5), 0 if not set.
+	NumCopies int
+
+	// Page ranges to print, as pairs of first and last page numbers (from 1).
+	PrintPageRange []int
+
+	// Paper handling of duplex printers.
+	Duplex PdfDuplex
+
+	// The viewer preferences dictionary read, with the other entries.
+	dict *PdfObjectDictionary
+}
+
+// NewPdfViewerPreferences returns empty viewer preferences.
+func NewPdfViewerPreferences() *PdfViewerPreferences {
+	return &PdfViewerPreferences{}
+}
+
+// Loads the viewer preferences from their dictionary.
+func newPdfViewerPreferencesFromObject(obj PdfObject) (*PdfViewerPreferences, error) {
+	prefs := NewPdfViewerPreferences()
+	if obj == nil {
+		return prefs, nil
+	}
+	dict, ok := TraceToDirectObject(obj).(*PdfObjectDictionary)
+	if !ok {
+		common.Log.Debug("ERROR: ViewerPreferences not a dictionary (%T)", obj)
+		return nil, ErrTypeError
+	}
+	prefs.dict = dict
+
+	if name, ok := TraceToDirectObject(dict.Get("PrintScaling")).(*PdfObjectName); ok {
+		prefs.PrintScaling = string(*name)
+	}
+	if b, ok := TraceToDirectObject(dict.Get("PickTrayByPDFSize")).(*PdfObjectBool); ok {
+		pick := bool(*b)
+		prefs.PickTrayByPDFSize = &pick
+	}
+	if n, ok := TraceToDirectObject(dict.Get("NumCopies")).(*PdfObjectInteger); ok {
+		prefs.NumCopies = int(*n)
+	}
+	if arr, ok := TraceToDirectObject(dict.Get("PrintPageRange")).(*PdfObjectArray); ok {
+		pageRange, err := arr.ToIntegerArray()
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid PrintPageRange %s", arr)
+			return nil, err
+		}
+		prefs.PrintPageRange = pageRange
+	}
+	if name, ok := TraceToDirectObject(dict.Get("Duplex")).(*PdfObjectName); ok {
+		prefs.Duplex = PdfDuplex(*name)
+	}
+	return prefs, nil
+}
+
+// ToPdfObject returns the viewer preferences dictionary, with the other entries of the preferences read.
+func (this *PdfViewerPreferences) ToPdfObject() PdfObject {
+	dict := MakeDict()
+	if this.dict != nil {
+		for _, key := range this.dict.Keys() {
+			dict.Set(key, this.dict.Get(key))
+		}
+	}
+	for _, key := range []PdfObjectName{"PrintScaling", "PickTrayByPDFSize", "NumCopies", "PrintPageRange", "Duplex"} {
+		dict.Remove(key)
+	}
+
+	if this.PrintScaling != "" {
+		dict.Set("PrintScaling", MakeName(this.PrintScaling))
+	}
+	if this.PickTrayByPDFSize != nil {
+		dict.Set("PickTrayByPDFSize", MakeBool(*this.PickTrayByPDFSize))
+	}
+	if this.NumCopies > 0 {
+		dict.Set("NumCopies", MakeInteger(int64(this.NumCopies)))
+	}
+	if len(this.PrintPageRange) > 0 {
+		dict.Set("PrintPageRange", MakeArrayFromIntegers(this.PrintPageRange))
+	}
+	if this.Duplex != DuplexNone {
+		dict.Set("Duplex", MakeName(string(this.Duplex)))
+	}
+	return dict
+}
+
+// validate checks the print entries of the viewer preferences.
+func (this *PdfViewerPreferences) validate() error {
+	if this.PrintScaling != "" && this.PrintScaling != "None" && this.PrintScaling != "AppDefault" {
+		common.Log.Debug("ERROR: Invalid PrintScaling %s", this.PrintScaling)
+		return ErrRangeError
+	}
+	if this.NumCopies < 0 || this.NumCopies > 5 {
+		common.Log.Debug("ERROR: NumCopies %d out of range", this.NumCopies)
+		return ErrRangeError
+	}
+	if len(this.PrintPageRange)%2 != 0 {
+		common.Log.Debug("ERROR: PrintPageRange %v not made of pairs", this.PrintPageRange)
+		return ErrRangeError
+	}
+	for i := 0; i < len(this.PrintPageRange); i += 2 {
+		first, last := this.PrintPageRange[i], this.PrintPageRange[i+1]
+		if first < 1 || last < first {
+			common.Log.Debug("ERROR: Invalid PrintPageRange %d-%d", first, last)
+			return ErrRangeError
+		}
+	}
+	switch this.Duplex {
+	case DuplexNone, DuplexSimplex, DuplexFlipShortEdge, DuplexFlipLongEdge:
+	default:
+		common.Log.Debug("ERROR: Invalid Duplex %s", this.Duplex)
+		return ErrRangeError
+	}
+	return nil
+}
+
+// GetViewerPreferences returns the viewer preferences of the document, empty if not set.
+func (this *PdfReader) GetViewerPreferences() (*PdfViewerPreferences, error) {
+	if this.requiresDecryption() {
+		return nil, errors.New("File need to be decrypted first")
+	}
+
+	obj, err := this.traceToObject(this.catalog.Get("ViewerPreferences"))
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return NewPdfViewerPreferences(), nil
+	}
+	err = this.traverseObjectData(obj)
+	if err != nil {
+		return nil, err
+	}
+	return newPdfViewerPreferencesFromObject(obj)
+}
+
+// SetViewerPreferences sets the viewer preferences of the document.  The PrintScaling entry requires PDF 1.6, and
+// the other print entries PDF 1.7: the version of the output is raised as needed.
+func (this *PdfWriter) SetViewerPreferences(prefs *PdfViewerPreferences) error {
+	if prefs == nil {
+		this.catalog.Remove("ViewerPreferences")
+		return nil
+	}
+	err := prefs.validate()
+	if err != nil {
+		return err
+	}
+
+	if prefs.PickTrayByPDFSize != nil || prefs.NumCopies > 0 || len(prefs.PrintPageRange) > 0 ||
+		prefs.Duplex != DuplexNone {
+		this.requireVersion(1, 7)
+	} else if prefs.PrintScaling != "" {
+		this.requireVersion(1, 6)
+	}
+
+	obj := prefs.ToPdfObject()
+	this.catalog.Set("ViewerPreferences", obj)
+	return this.addObjects(obj)
+}
+
+// requireVersion raises the PDF version of the output to the version, if lower.
+func (this *PdfWriter) requireVersion(majorVersion, minorVersion int) {
+	if this.majorVersion < majorVersion || (this.majorVersion == majorVersion && this.minorVersion < minorVersion) {
+		this.majorVersion = majorVersion
+		this.minorVersion = minorVersion
+	}
+}
+
+// GetUserUnit returns the size of the default user space unit of the page, in multiples of 1/72 inch (1 if not
+// set).  Pages larger than the maximum page size of 14400 units (200 inches) use larger units.
+func (this *PdfPage) GetUserUnit() float64 {
+	if this.UserUnit == nil {
+		return 1
+	}
+	unit, err := getNumberAsFloat(TraceToDirectObject(this.UserUnit))
+	if err != nil || unit <= 0 {
+		common.Log.Debug("Invalid UserUnit %v", this.UserUnit)
+		return 1
+	}
+	return unit
+}
+
+// SetUserUnit sets the size of the default user space unit of the page, in multiples of 1/72 inch.  Requires PDF
+// 1.6: the version of the output is raised when the page is added to a writer.
+func (this *PdfPage) SetUserUnit(unit float64) error {
+	if unit <= 0 {
+		common.Log.Debug("ERROR: Invalid UserUnit %f", unit)
+		return ErrRangeError
+	}
+	if unit == 1 {
+		this.UserUnit = nil
+		return nil
+	}
+	this.UserUnit = MakeFloat(unit)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestViewerPreferences(t *testing.T) {
+	w := NewPdfWriter()
+	// A 1000x500 inch banner.
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 7200, Ury: 3600}
+	page.Resources = NewPdfPageResources()
+	err := page.SetUserUnit(10)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = w.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	invalid := []*PdfViewerPreferences{
+		{NumCopies: 6},
+		{PrintPageRange: []int{1, 2, 3}},
+		{PrintPageRange: []int{2, 1}},
+		{PrintScaling: "Fit"},
+		{Duplex: "Duplex"},
+	}
+	for _, prefs := range invalid {
+		if err := w.SetViewerPreferences(prefs); err == nil {
+			t.Errorf("Invalid viewer preferences not detected: %+v", prefs)
+		}
+	}
+
+	pick := true
+	prefs := NewPdfViewerPreferences()
+	prefs.PrintScaling = "None"
+	prefs.PickTrayByPDFSize = &pick
+	prefs.NumCopies = 2
+	prefs.PrintPageRange = []int{1, 1}
+	prefs.Duplex = DuplexFlipLongEdge
+	err = w.SetViewerPreferences(prefs)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.7")) {
+		t.Errorf("Wrong version %q", buf.Bytes()[:8])
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	read, err := reader.GetViewerPreferences()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if read.PrintScaling != "None" || read.PickTrayByPDFSize == nil || !*read.PickTrayByPDFSize ||
+		read.NumCopies != 2 || !reflect.DeepEqual(read.PrintPageRange, []int{1, 1}) ||
+		read.Duplex != DuplexFlipLongEdge {
+		t.Errorf("Wrong viewer preferences %+v", read)
+	}
+
+	readPage, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if unit := readPage.GetUserUnit(); unit != 10 {
+		t.Errorf("Wrong user unit %f", unit)
+	}
+	if err := readPage.SetUserUnit(0); err == nil {
+		t.Errorf("Invalid user unit not detected")
+	}
+}
+
+func TestUserUnitVersion(t *testing.T) {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 14400, Ury: 14400}
+	page.Resources = NewPdfPageResources()
+	page.SetUserUnit(2)
+	err := w.AddPage(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = w.Write(&writeSeeker{buf: &buf})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.6")) {
+		t.Errorf("Wrong version %q", buf.Bytes()[:8])
+	}
+}
@@ -0,0 +1,260 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// Watermark is the content of a watermark: either an image or a text line.
+type Watermark struct {
+	Image *XObjectImage
+	Text  string
+}
+
+// WatermarkOptions specifies the appearance and placement of a watermark on a page.
+type WatermarkOptions struct {
+	// Opacity from 0 (invisible) to 1 (opaque).
+	Opacity float64
+
+	// Counterclockwise rotation in degrees around the center of the watermark.
+	Rotation float64
+
+	// Width of an image watermark in points, or 0 for the width of the image in pixels.  The aspect ratio of the
+	// image is kept.
+	Width float64
+
+	// Font of a text watermark (WinAnsi encoded), Helvetica if nil.
+	Font fonts.Font
+	// Font size of a text watermark.
+	FontSize float64
+	// Color of a text watermark, black if nil.
+	Color *PdfColorDeviceRGB
+
+	// Whether the watermark is repeated over the whole page, with the spacing (in points) between the repetitions.
+	// Otherwise, the watermark is centered on the page.
+	Tile        bool
+	TileSpacing float64
+
+	// Whether the watermark is drawn below the page content rather than above it.
+	Below bool
+}
+
+// NewWatermarkOptions returns the default watermark options: semi-transparent, centered above the content, with
+// 48 point text.
+func NewWatermarkOptions() WatermarkOptions {
+	return WatermarkOptions{
+		Opacity:     0.5,
+		FontSize:    48,
+		TileSpacing: 36,
+	}
+}
+
+// AddWatermark adds a watermark to the page.  The watermark resources are added to copies of the page resource
+// dictionaries (possibly inherited or shared with other pages), with names not used by the page.  When drawn above
+// the content, the existing content streams are wrapped in q/Q so the graphics state they leave does not affect
+// the watermark.
+func (this *PdfPage) AddWatermark(wm *Watermark, opt WatermarkOptions) error {
+	if wm == nil || (wm.Image == nil && wm.Text == "") {
+		common.Log.Debug("ERROR: Empty watermark")
+		return ErrRequiredAttributeMissing
+	}
+	if opt.Opacity < 0 || opt.Opacity > 1 {
+		common.Log.Debug("ERROR: Watermark opacity out of range (%f)", opt.Opacity)
+		return ErrRangeError
+	}
+
+	bbox, err := this.GetMediaBox()
+	if err != nil {
+		return err
+	}
+
+	resources, err := this.getResources()
+	if err != nil {
+		return err
+	}
+	if resources == nil {
+		resources = NewPdfPageResources()
+	}
+	this.Resources = resources
+
+	// The alpha of the watermark.
+	extGStates, err := copyResourceDict(&resources.ExtGState)
+	if err != nil {
+		return err
+	}
+	gsName := getUnusedResourceName(extGStates, "GSwm")
+	gs := MakeDict()
+	gs.Set("Type", MakeName("ExtGState"))
+	gs.Set("CA", MakeFloat(opt.Opacity))
+	gs.Set("ca", MakeFloat(opt.Opacity))
+	extGStates.Set(gsName, gs)
+
+	// The content drawing the watermark centered at the origin, and its size.
+	var content string
+	var width, height float64
+	if wm.Image != nil {
+		if wm.Image.Width == nil || wm.Image.Height == nil || *wm.Image.Width <= 0 || *wm.Image.Height <= 0 {
+			common.Log.Debug("ERROR: Invalid watermark image size")
+			return ErrInvalidAttribute
+		}
+		xobjects, err := copyResourceDict(&resources.XObject)
+		if err != nil {
+			return err
+		}
+		imgName := getUnusedResourceName(xobjects, "Imwm")
+		xobjects.Set(imgName, wm.Image.ToPdfObject())
+
+		width = opt.Width
+		if width <= 0 {
+			width = float64(*wm.Image.Width)
+		}
+		height = width * float64(*wm.Image.Height) / float64(*wm.Image.Width)
+		content = fmt.Sprintf("%.4f 0 0 %.4f %.4f %.4f cm /%s Do\n", width, height, -width/2, -height/2, imgName)
+	} else {
+		if opt.FontSize <= 0 {
+			common.Log.Debug("ERROR: Invalid watermark font size (%f)", opt.FontSize)
+			return ErrRangeError
+		}
+		font := opt.Font
+		if font == nil {
+			font = fonts.NewFontHelvetica()
+		}
+		fontDicts, err := copyResourceDict(&resources.Font)
+		if err != nil {
+			return err
+		}
+		fontName := getUnusedResourceName(fontDicts, "Fwm")
+		fontDicts.Set(fontName, font.ToPdfObject())
+
+		encoder := textencoding.NewWinAnsiTextEncoder()
+		for _, r := range wm.Text {
+			glyph, found := encoder.RuneToGlyph(r)
+			if !found {
+				common.Log.Debug("ERROR: Rune %q not supported by the watermark encoding", r)
+				return errors.New("Unsupported rune in text encoding")
+			}
+			metrics, found := font.GetGlyphCharMetrics(glyph)
+			if !found {
+				common.Log.Debug("ERROR: Glyph %s not found in the watermark font", glyph)
+				return errors.New("Unsupported text glyph")
+			}
+			width += metrics.Wx * opt.FontSize / 1000
+		}
+		height = opt.FontSize
+
+		color := opt.Color
+		if color == nil {
+			color = NewPdfColorDeviceRGB(0, 0, 0)
+		}
+		// The baseline is placed so the center of the capital letters is at the origin.
+		content = fmt.Sprintf("%.4f %.4f %.4f rg BT /%s %.4f Tf %.4f %.4f Td %s Tj ET\n",
+			color.R(), color.G(), color.B(), fontName, opt.FontSize, -width/2, -0.35*opt.FontSize,
+			MakeString(encoder.Encode(wm.Text)).DefaultWriteString())
+	}
+
+	// The centers of the watermarks: the center of the page, or a grid covering the page.
+	cx, cy := (bbox.Llx+bbox.Urx)/2, (bbox.Lly+bbox.Ury)/2
+	centers := [][2]float64{{cx, cy}}
+	if opt.Tile {
+		// The grid is large enough for the rotated watermarks to cover the page corners.
+		stepX, stepY := width+opt.TileSpacing, height+opt.TileSpacing
+		if stepX <= 0 || stepY <= 0 {
+			common.Log.Debug("ERROR: Invalid watermark tile spacing (%f)", opt.TileSpacing)
+			return ErrRangeError
+		}
+		radius := math.Hypot(bbox.Urx-bbox.Llx, bbox.Ury-bbox.Lly)/2 + math.Hypot(width, height)
+		nx, ny := int(math.Ceil(radius/stepX)), int(math.Ceil(radius/stepY))
+		centers = nil
+		for j := -ny; j <= ny; j++ {
+			for i := -nx; i <= nx; i++ {
+				centers = append(centers, [2]float64{cx + float64(i)*stepX, cy + float64(j)*stepY})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("q\n")
+	fmt.Fprintf(&buf, "/%s gs\n", gsName)
+	if opt.Tile {
+		fmt.Fprintf(&buf, "%.4f %.4f %.4f %.4f re W n\n", bbox.Llx, bbox.Lly, bbox.Urx-bbox.Llx, bbox.Ury-bbox.Lly)
+	}
+	sin, cos := math.Sincos(opt.Rotation * math.Pi / 180)
+	for _, c := range centers {
+		// The tiles are laid out in the rotated coordinate system around the page center.
+		x, y := c[0]-cx, c[1]-cy
+		x, y = cx+x*cos-y*sin, cy+x*sin+y*cos
+		fmt.Fprintf(&buf, "q %.4f %.4f %.4f %.4f %.4f %.4f cm ", cos, sin, -sin, cos, x, y)
+		buf.WriteString(content)
+		buf.WriteString("Q\n")
+	}
+	buf.WriteString("Q\n")
+
+	contents := []PdfObject{}
+	if this.Contents != nil {
+		if arr, ok := TraceToDirectObject(this.Contents).(*PdfObjectArray); ok {
+			contents = append(contents, *arr...)
+		} else {
+			contents = append(contents, this.Contents)
+		}
+	}
+	wmStream := makeContentStream(buf.String())
+	if opt.Below {
+		contents = append([]PdfObject{wmStream}, contents...)
+	} else if len(contents) > 0 {
+		contents = append([]PdfObject{makeContentStream("q\n")}, contents...)
+		contents = append(contents, makeContentStream("\nQ\n"), wmStream)
+	} else {
+		contents = append(contents, wmStream)
+	}
+	arr := PdfObjectArray(contents)
+	this.Contents = &arr
+
+	return nil
+}
+
+// copyResourceDict replaces a resource dictionary (e.g. Font) by a shallow copy, creating it if missing, so entries
+// can be added to it without affecting other pages.
+func copyResourceDict(obj *PdfObject) (*PdfObjectDictionary, error) {
+	dict := MakeDict()
+	if *obj != nil {
+		orig, ok := TraceToDirectObject(*obj).(*PdfObjectDictionary)
+		if !ok {
+			common.Log.Debug("ERROR: Invalid resource dictionary (%T)", *obj)
+			return nil, ErrTypeError
+		}
+		dict.Merge(orig)
+	}
+	*obj = dict
+	return dict, nil
+}
+
+// getUnusedResourceName returns the first name with the prefix and a number not used in a resource dictionary.
+func getUnusedResourceName(dict *PdfObjectDictionary, prefix string) PdfObjectName {
+	for i := 0; ; i++ {
+		name := PdfObjectName(fmt.Sprintf("%s%d", prefix, i))
+		if dict.Get(name) == nil {
+			return name
+		}
+	}
+}
+
+// makeContentStream returns an unencoded content stream.
+func makeContentStream(content string) *PdfObjectStream {
+	stream := &PdfObjectStream{}
+	stream.PdfObjectDictionary = MakeDict()
+	stream.PdfObjectDictionary.Set("Length", MakeInteger(int64(len(content))))
+	stream.Stream = []byte(content)
+	return stream
+}
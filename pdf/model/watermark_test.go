@@ -0,0 +1,129 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// makeWatermarkTestPage returns a page whose content leaves a scaling transform, with a font resource dictionary
+// shared with other pages.
+func makeWatermarkTestPage(t *testing.T, fontDict *PdfObjectDictionary) *PdfPage {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 100}
+	page.Resources = NewPdfPageResources()
+	page.Resources.Font = fontDict
+	err := page.SetContentStreams([]string{"2 0 0 2 0 0 cm BT /F1 10 Tf (Text) Tj ET"}, nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return page
+}
+
+func TestAddTextWatermark(t *testing.T) {
+	fontDict := MakeDict()
+	fontDict.Set("F1", fonts.NewFontHelvetica().ToPdfObject())
+	page := makeWatermarkTestPage(t, fontDict)
+
+	opt := NewWatermarkOptions()
+	opt.Opacity = 0.3
+	opt.Rotation = 45
+	opt.Color = NewPdfColorDeviceRGB(1, 0, 0)
+	err := page.AddWatermark(&Watermark{Text: "DRAFT"}, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The existing content is wrapped in q/Q, followed by the watermark.
+	streams, err := page.GetContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(streams) != 4 || streams[0] != "q\n" || streams[2] != "\nQ\n" {
+		t.Fatalf("Wrong content streams %q", streams)
+	}
+	if !strings.Contains(streams[3], "/GSwm0 gs") || !strings.Contains(streams[3], "/Fwm0 48.0000 Tf") ||
+		!strings.Contains(streams[3], "(DRAFT) Tj") || !strings.Contains(streams[3], "0.7071 0.7071 -0.7071 0.7071") {
+		t.Errorf("Wrong watermark content %q", streams[3])
+	}
+
+	// The resources are added to copies of the dictionaries.
+	if fontDict.Get("Fwm0") != nil {
+		t.Errorf("Shared font dictionary modified")
+	}
+	if _, found := page.Resources.GetFontByName("F1"); !found {
+		t.Errorf("Existing font resource lost")
+	}
+	if _, found := page.Resources.GetFontByName("Fwm0"); !found {
+		t.Errorf("Missing watermark font resource")
+	}
+	gs, found := page.Resources.GetExtGState("GSwm0")
+	if !found {
+		t.Fatalf("Missing watermark graphics state")
+	}
+	if ca, ok := TraceToDirectObject(gs).(*PdfObjectDictionary).Get("ca").(*PdfObjectFloat); !ok || float64(*ca) != 0.3 {
+		t.Errorf("Wrong watermark opacity %v", gs)
+	}
+
+	// A second watermark uses other resource names.
+	err = page.AddWatermark(&Watermark{Text: "COPY"}, NewWatermarkOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if _, found := page.Resources.GetFontByName("Fwm1"); !found {
+		t.Errorf("Missing second watermark font resource")
+	}
+}
+
+func TestAddImageWatermark(t *testing.T) {
+	page := makeWatermarkTestPage(t, MakeDict())
+	img := &Image{Width: 2, Height: 1, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{0, 255}}
+	ximg, err := NewXObjectImageFromImage(img, NewPdfColorspaceDeviceGray(), nil)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	opt := NewWatermarkOptions()
+	opt.Width = 40
+	opt.Tile = true
+	opt.Below = true
+	err = page.AddWatermark(&Watermark{Image: ximg}, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The watermark is drawn first, clipped to the page and repeated.
+	streams, err := page.GetContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(streams) != 2 || !strings.HasPrefix(streams[1], "2 0 0 2 0 0 cm") {
+		t.Fatalf("Wrong content streams %q", streams)
+	}
+	if !strings.Contains(streams[0], "0.0000 0.0000 200.0000 100.0000 re W n") {
+		t.Errorf("Watermark not clipped %q", streams[0])
+	}
+	if n := strings.Count(streams[0], "40.0000 0 0 20.0000 -20.0000 -10.0000 cm /Imwm0 Do"); n < 8 {
+		t.Errorf("Watermark not tiled (%d)", n)
+	}
+	if !page.HasXObjectByName("Imwm0") {
+		t.Errorf("Missing watermark image resource")
+	}
+
+	err = page.AddWatermark(&Watermark{}, opt)
+	if err == nil {
+		t.Errorf("Empty watermark added")
+	}
+	opt.Opacity = 2
+	err = page.AddWatermark(&Watermark{Image: ximg}, opt)
+	if err == nil {
+		t.Errorf("Watermark with invalid opacity added")
+	}
+}
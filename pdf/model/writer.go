@@ -15,7 +15,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"time"
 
 	"github.com/unidoc/unidoc/common"
@@ -51,6 +50,7 @@ type PdfWriter struct {
 	objects     []PdfObject
 	objectsMap  map[PdfObject]bool // Quick lookup table.
 	writer      *bufio.Writer
+	output      *countingWriter
 	outlines    []*PdfIndirectObject
 	outlineTree *PdfOutlineTreeNode
 	catalog     *PdfObjectDictionary
@@ -76,6 +76,37 @@ type PdfWriter struct {
 
 	// Forms.
 	acroForm *PdfAcroForm
+
+	// Logical structure.
+	structTreeRoot *PdfStructTreeRoot
+
+	// Embedded files.
+	embeddedFiles []*EmbeddedFile
+
+	// Named and template pages.
+	namedPages    map[string]*PdfPage
+	pageTemplates map[string]*PdfPage
+
+	// Named destinations.
+	namedDests map[string]PdfObject
+
+	// Page-piece dictionary of the document.
+	pieceInfo *PdfObjectDictionary
+
+	// Maximum number of kids of the page tree nodes.
+	pageTreeFanout int
+
+	// Whether the document is written linearized.
+	linearized bool
+
+	// The form of the cross-reference information.
+	xrefMode PdfXrefMode
+
+	// The offsets of the objects written by index, 0 if not written yet, and the numbers of objects and pages
+	// already flushed.
+	offsets        []int64
+	flushedObjects int
+	flushedPages   int
 }
 
 func NewPdfWriter() PdfWriter {
@@ -90,6 +121,8 @@ func NewPdfWriter() PdfWriter {
 	w.majorVersion = 1
 	w.minorVersion = 3
 
+	w.pageTreeFanout = DefaultPageTreeFanout
+
 	// Creation info.
 	infoDict := MakeDict()
 	infoDict.Set("Producer", MakeString(getPdfProducer()))
@@ -148,6 +181,19 @@ func (this *PdfWriter) SetOCProperties(ocProperties PdfObject) error {
 	return nil
 }
 
+// SetMetadata sets the XMP metadata stream of the document.  The stream is not compressed, so the metadata can be
+// read by applications not parsing PDF, as recommended for PDF/A.
+func (this *PdfWriter) SetMetadata(xmp []byte) error {
+	stream, err := MakeStream(xmp, nil)
+	if err != nil {
+		return err
+	}
+	stream.PdfObjectDictionary.Set("Type", MakeName("Metadata"))
+	stream.PdfObjectDictionary.Set("Subtype", MakeName("XML"))
+	this.catalog.Set("Metadata", stream)
+	return this.addObjects(stream)
+}
+
 func (this *PdfWriter) hasObject(obj PdfObject) bool {
 	// Check if already added.
 	for _, o := range this.objects {
@@ -290,34 +336,22 @@ func (this *PdfWriter) AddPage(page *PdfPage) error {
 	}
 
 	// Copy inherited fields if missing.
-	inheritedFields := []PdfObjectName{"Resources", "MediaBox", "CropBox", "Rotate"}
-	parent, hasParent := pDict.Get("Parent").(*PdfIndirectObject)
-	common.Log.Trace("Page Parent: %T (%v)", pDict.Get("Parent"), hasParent)
-	for hasParent {
-		common.Log.Trace("Page Parent: %T", parent)
-		parentDict, ok := parent.PdfObject.(*PdfObjectDictionary)
-		if !ok {
-			return errors.New("Invalid Parent object")
-		}
-		for _, field := range inheritedFields {
-			common.Log.Trace("Field %s", field)
-			if pDict.Get(field) != nil {
-				common.Log.Trace("- page has already")
-				continue
-			}
-
-			if obj := parentDict.Get(field); obj != nil {
-				// Parent has the field.  Inherit, pass to the new page.
-				common.Log.Trace("Inheriting field %s", field)
-				pDict.Set(field, obj)
-			}
-		}
-		parent, hasParent = parentDict.Get("Parent").(*PdfIndirectObject)
-		common.Log.Trace("Next parent: %T", parentDict.Get("Parent"))
+	err := inheritPageFields(pDict)
+	if err != nil {
+		return err
 	}
 
 	common.Log.Trace("Traversal done")
 
+	if page.GetUserUnit() != 1 {
+		// UserUnit requires PDF 1.6.
+		this.requireVersion(1, 6)
+	}
+	if page.Tabs != nil {
+		// Tabs requires PDF 1.5.
+		this.requireVersion(1, 5)
+	}
+
 	// Update the dictionary.
 	// Reuses the input object, updating the fields.
 	pDict.Set("Parent", this.pages)
@@ -345,7 +379,7 @@ func (this *PdfWriter) AddPage(page *PdfPage) error {
 
 
 	// Traverse the page and record all object references.
-	err := this.addObjects(pDict)
+	err = this.addObjects(pDict)
 	if err != nil {
 		return err
 	}
@@ -353,6 +387,37 @@ func (this *PdfWriter) AddPage(page *PdfPage) error {
 	return nil
 }
 
+// Copies the fields inherited from the ancestors of the page in the page tree, if missing from the page.
+func inheritPageFields(pDict *PdfObjectDictionary) error {
+	inheritedFields := []PdfObjectName{"Resources", "MediaBox", "CropBox", "Rotate"}
+	parent, hasParent := pDict.Get("Parent").(*PdfIndirectObject)
+	common.Log.Trace("Page Parent: %T (%v)", pDict.Get("Parent"), hasParent)
+	for hasParent {
+		common.Log.Trace("Page Parent: %T", parent)
+		parentDict, ok := parent.PdfObject.(*PdfObjectDictionary)
+		if !ok {
+			return errors.New("Invalid Parent object")
+		}
+		for _, field := range inheritedFields {
+			common.Log.Trace("Field %s", field)
+			if pDict.Get(field) != nil {
+				common.Log.Trace("- page has already")
+				continue
+			}
+
+			if obj := parentDict.Get(field); obj != nil {
+				// Parent has the field.  Inherit, pass to the new page.
+				common.Log.Trace("Inheriting field %s", field)
+				pDict.Set(field, obj)
+			}
+		}
+		parent, hasParent = parentDict.Get("Parent").(*PdfIndirectObject)
+		common.Log.Trace("Next parent: %T", parentDict.Get("Parent"))
+	}
+
+	return nil
+}
+
 func procPage(p *PdfPage) {
 	lk := license.GetLicenseKey()
 	if lk != nil && lk.IsLicensed() {
@@ -447,11 +512,17 @@ func (this *PdfWriter) writeObject(num int, obj PdfObject) {
 	// XXX/TODO: Add a default encoder if Filter not specified?
 	// Still need to make sure is encrypted.
 	if pobj, isStream := obj.(*PdfObjectStream); isStream {
+		// The data of lazy streams is copied from the file.
+		r, err := pobj.RawReader()
+		if err != nil {
+			common.Log.Debug("ERROR: Failed reading stream %d: %v", num, err)
+			r = strings.NewReader("")
+		}
 		outStr := fmt.Sprintf("%d 0 obj\n", num)
 		outStr += pobj.PdfObjectDictionary.DefaultWriteString()
 		outStr += "\nstream\n"
 		this.writer.WriteString(outStr)
-		this.writer.Write(pobj.Stream)
+		io.Copy(this.writer, r)
 		this.writer.WriteString("\nendstream\nendobj\n")
 		return
 	}
@@ -474,11 +545,23 @@ func (this *PdfWriter) updateObjectNumbers() {
 	}
 }
 
+// EncryptOptions are the options of the encryption of the output file.
 type EncryptOptions struct {
+	// Permissions granted to the users opening the file with the user password, e.g. FullAccessPermissions() or
+	// AccessPermissions{Printing: true, FullPrintQuality: true}.  The owner password grants full access.
 	Permissions AccessPermissions
+
+	// Encrypt only the embedded files (with AES-128), the other content of the document can be read without
+	// password.  Sets the PDF version to 1.6 at least.
+	EmbeddedFilesOnly bool
+
+	// Leave the metadata streams (XMP) unencrypted, e.g. for indexing by search engines, and encrypt the other
+	// content with AES-128.  Sets the PDF version to 1.6 at least.
+	UnencryptedMetadata bool
 }
 
-// Encrypt the output file with a specified user/owner password.
+// Encrypt the output file with a specified user/owner password.  All permissions are granted to the user if
+// options is nil.
 func (this *PdfWriter) Encrypt(userPass, ownerPass []byte, options *EncryptOptions) error {
 	crypter := PdfCrypt{}
 	this.crypter = &crypter
@@ -496,21 +579,43 @@ func (this *PdfWriter) Encrypt(userPass, ownerPass []byte, options *EncryptOptio
 	crypter.EncryptMetadata = true
 	if options != nil {
 		crypter.P = int(options.Permissions.GetP())
+		if options.EmbeddedFilesOnly {
+			// Crypt filters (V4): the embedded file streams are encrypted with the standard filter, other
+			// streams and strings are left unchanged.
+			crypter.V = 4
+			crypter.R = 4
+			crypter.CryptFilters = CryptFilters{}
+			crypter.CryptFilters["StdCF"] = CryptFilter{Cfm: "AESV2", Length: 16}
+			crypter.CryptFilters["Identity"] = CryptFilter{}
+			crypter.StreamFilter = "Identity"
+			crypter.StringFilter = "Identity"
+			crypter.EmbeddedFileFilter = "StdCF"
+
+			// Embedded file crypt filters (EFF) require PDF 1.6.
+			if this.majorVersion == 1 && this.minorVersion < 6 {
+				this.minorVersion = 6
+			}
+		} else if options.UnencryptedMetadata {
+			// Crypt filters (V4): the metadata streams are left unchanged, other streams and strings are
+			// encrypted with the standard filter.
+			crypter.V = 4
+			crypter.R = 4
+			crypter.EncryptMetadata = false
+			crypter.CryptFilters = CryptFilters{}
+			crypter.CryptFilters["StdCF"] = CryptFilter{Cfm: "AESV2", Length: 16}
+			crypter.CryptFilters["Identity"] = CryptFilter{}
+			crypter.StreamFilter = "StdCF"
+			crypter.StringFilter = "StdCF"
+			crypter.EmbeddedFileFilter = "StdCF"
+
+			// AES crypt filters require PDF 1.6.
+			if this.majorVersion == 1 && this.minorVersion < 6 {
+				this.minorVersion = 6
+			}
+		}
 	}
 
-	// Prepare the ID object for the trailer.
-	hashcode := md5.Sum([]byte(time.Now().Format(time.RFC850)))
-	id0 := PdfObjectString(hashcode[:])
-	b := make([]byte, 100)
-	rand.Read(b)
-	hashcode = md5.Sum(b)
-	id1 := PdfObjectString(hashcode[:])
-	common.Log.Trace("Random b: % x", b)
-
-	this.ids = &PdfObjectArray{&id0, &id1}
-	common.Log.Trace("Gen Id 0: % x", id0)
-
-	crypter.Id0 = string(id0)
+	crypter.Id0 = this.generateIds()
 
 	// Make the O and U objects.
 	O, err := crypter.Alg3(userPass, ownerPass)
@@ -538,6 +643,26 @@ func (this *PdfWriter) Encrypt(userPass, ownerPass []byte, options *EncryptOptio
 	encDict.Set("Length", MakeInteger(int64(crypter.Length)))
 	encDict.Set("O", &O)
 	encDict.Set("U", &U)
+	if crypter.V >= 4 {
+		filter := crypter.CryptFilters[crypter.EmbeddedFileFilter]
+		filterDict := MakeDict()
+		filterDict.Set("Type", MakeName("CryptFilter"))
+		filterDict.Set("CFM", MakeName(filter.Cfm))
+		if crypter.EncryptsEmbeddedFilesOnly() {
+			// The embedded files are decrypted when opened (EFOpen).
+			filterDict.Set("AuthEvent", MakeName("EFOpen"))
+		}
+		filterDict.Set("Length", MakeInteger(int64(filter.Length)))
+		cf := MakeDict()
+		cf.Set(PdfObjectName(crypter.EmbeddedFileFilter), filterDict)
+		encDict.Set("CF", cf)
+		encDict.Set("StmF", MakeName(crypter.StreamFilter))
+		encDict.Set("StrF", MakeName(crypter.StringFilter))
+		encDict.Set("EFF", MakeName(crypter.EmbeddedFileFilter))
+		if !crypter.EncryptMetadata {
+			encDict.Set("EncryptMetadata", MakeBool(false))
+		}
+	}
 	this.encryptDict = encDict
 
 	// Make an object to contain it.
@@ -548,8 +673,86 @@ func (this *PdfWriter) Encrypt(userPass, ownerPass []byte, options *EncryptOptio
 	return nil
 }
 
-// Write the pdf out.
-func (this *PdfWriter) Write(ws io.WriteSeeker) error {
+// EncryptForRecipients encrypts the output file for a list of recipient certificates with the public-key security
+// handler (Adobe.PubSec, AES-128).  Each recipient opens the file with the private key of its certificate (RSA)
+// and is granted its own permissions.  Sets the PDF version to 1.6 at least.
+func (this *PdfWriter) EncryptForRecipients(recipients []PubSecRecipient) error {
+	crypter := PdfCrypt{}
+	crypter.EncryptedObjects = map[PdfObject]bool{}
+	crypter.Filter = "Adobe.PubSec"
+	crypter.Subfilter = "adbe.pkcs7.s5"
+	crypter.P = -1
+	crypter.V = 4
+	crypter.Length = 128
+	crypter.EncryptMetadata = true
+	crypter.CryptFilters = CryptFilters{}
+	crypter.CryptFilters["DefaultCryptFilter"] = CryptFilter{Cfm: "AESV2", Length: 16}
+	crypter.CryptFilters["Identity"] = CryptFilter{}
+	crypter.StreamFilter = "DefaultCryptFilter"
+	crypter.StringFilter = "DefaultCryptFilter"
+	crypter.EmbeddedFileFilter = "DefaultCryptFilter"
+
+	if err := crypter.InitPubSec(recipients); err != nil {
+		common.Log.Debug("ERROR: Error enveloping the encryption key (%s)", err)
+		return err
+	}
+	crypter.Id0 = this.generateIds()
+
+	// AES crypt filters require PDF 1.6.
+	if this.majorVersion == 1 && this.minorVersion < 6 {
+		this.minorVersion = 6
+	}
+
+	// Generate the encryption dictionary.  The Recipients are in the crypt filter (adbe.pkcs7.s5).
+	var recipientStrings PdfObjectArray
+	for _, recipient := range crypter.Recipients {
+		recipientStrings = append(recipientStrings, MakeString(string(recipient)))
+	}
+	filterDict := MakeDict()
+	filterDict.Set("Type", MakeName("CryptFilter"))
+	filterDict.Set("CFM", MakeName("AESV2"))
+	filterDict.Set("Length", MakeInteger(128))
+	filterDict.Set("Recipients", &recipientStrings)
+	filterDict.Set("EncryptMetadata", MakeBool(true))
+	cf := MakeDict()
+	cf.Set("DefaultCryptFilter", filterDict)
+
+	encDict := MakeDict()
+	encDict.Set("Filter", MakeName(crypter.Filter))
+	encDict.Set("SubFilter", MakeName(crypter.Subfilter))
+	encDict.Set("V", MakeInteger(int64(crypter.V)))
+	encDict.Set("Length", MakeInteger(int64(crypter.Length)))
+	encDict.Set("CF", cf)
+	encDict.Set("StmF", MakeName(crypter.StreamFilter))
+	encDict.Set("StrF", MakeName(crypter.StringFilter))
+	this.encryptDict = encDict
+	this.crypter = &crypter
+
+	io := MakeIndirectObject(encDict)
+	this.encryptObj = io
+	this.addObject(io)
+
+	return nil
+}
+
+// Generates the ID of the file for the trailer, returns the first (permanent) identifier.
+func (this *PdfWriter) generateIds() string {
+	hashcode := md5.Sum([]byte(time.Now().Format(time.RFC850)))
+	id0 := PdfObjectString(hashcode[:])
+	b := make([]byte, 100)
+	rand.Read(b)
+	hashcode = md5.Sum(b)
+	id1 := PdfObjectString(hashcode[:])
+	common.Log.Trace("Random b: % x", b)
+
+	this.ids = &PdfObjectArray{&id0, &id1}
+	common.Log.Trace("Gen Id 0: % x", id0)
+	return string(id0)
+}
+
+// Write the pdf out.  If pages were already written with FlushPages, w must be the same writer.
+func (this *PdfWriter) Write(w io.Writer) error {
+	defer common.StartTiming(common.TimingWrite)()
 	common.Log.Trace("Write()")
 
 	lk := license.GetLicenseKey()
@@ -558,6 +761,12 @@ func (this *PdfWriter) Write(ws io.WriteSeeker) error {
 		fmt.Printf("To get rid of the watermark - Please get a license on https://unidoc.io\n")
 	}
 
+	// Page tree.
+	err := this.balancePageTree()
+	if err != nil {
+		return err
+	}
+
 	// Outlines.
 	if this.outlineTree != nil {
 		common.Log.Trace("OutlineTree: %+v", this.outlineTree)
@@ -570,6 +779,19 @@ func (this *PdfWriter) Write(ws io.WriteSeeker) error {
 		}
 	}
 
+	// Logical structure.
+	if this.structTreeRoot != nil {
+		root := this.structTreeRoot.ToPdfObject()
+		this.catalog.Set("StructTreeRoot", root)
+		markInfo := MakeDict()
+		markInfo.Set("Marked", MakeBool(true))
+		this.catalog.Set("MarkInfo", markInfo)
+		err := this.addObjects(root)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Form fields.
 	if this.acroForm != nil {
 		common.Log.Trace("Writing acro forms")
@@ -582,6 +804,38 @@ func (this *PdfWriter) Write(ws io.WriteSeeker) error {
 		}
 	}
 
+	// Embedded files.
+	if len(this.embeddedFiles) > 0 {
+		err := this.writeEmbeddedFiles()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Named pages.
+	if len(this.namedPages) > 0 || len(this.pageTemplates) > 0 {
+		err := this.writeNamedPages()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Named destinations.
+	if len(this.namedDests) > 0 {
+		err := this.writeNamedDestinations()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Private data of the applications.
+	if this.pieceInfo != nil {
+		err := this.writePieceInfo()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check pending objects prior to write.
 	for pendingObj, pendingObjDict := range this.pendingObjects {
 		if !this.hasObject(pendingObj) {
@@ -596,51 +850,61 @@ func (this *PdfWriter) Write(ws io.WriteSeeker) error {
 			}
 		}
 	}
+	if this.xrefMode == XrefModeStream && !this.linearized {
+		this.requireVersion(1, 5)
+	}
 	// Set version in the catalog.
 	this.catalog.Set("Version", MakeName(fmt.Sprintf("%d.%d", this.majorVersion, this.minorVersion)))
 
-	w := bufio.NewWriter(ws)
-	this.writer = w
-
-	w.WriteString(fmt.Sprintf("%%PDF-%d.%d\n", this.majorVersion, this.minorVersion))
-	w.WriteString("%âãÏÓ\n")
-	w.Flush()
-
-	this.updateObjectNumbers()
+	if this.linearized {
+		return this.writeLinearized(w)
+	}
 
-	offsets := []int64{}
+	if this.output == nil {
+		this.startOutput(w)
+	}
 
-	// Write objects
-	common.Log.Trace("Writing %d obj", len(this.objects))
-	for idx, obj := range this.objects {
-		common.Log.Trace("Writing %d", idx)
-		this.writer.Flush()
-		offset, _ := ws.Seek(0, os.SEEK_CUR)
-		offsets = append(offsets, offset)
+	// The objects compressed in object streams, if enabled.
+	compressed, err := this.makeObjectStreams()
+	if err != nil {
+		return err
+	}
+	deferred := map[PdfObject]bool{}
+	for obj := range compressed {
+		deferred[obj] = true
+	}
 
-		// Encrypt prior to writing.
-		// Encrypt dictionary should not be encrypted.
-		if this.crypter != nil && obj != this.encryptObj {
-			err := this.crypter.Encrypt(obj, int64(idx+1), 0)
-			if err != nil {
-				common.Log.Debug("ERROR: Failed encrypting (%s)", err)
-				return err
-			}
+	// The objects not written yet, including the document-level objects deferred when flushing pages.
+	err = this.writeObjects(0, deferred)
+	if err != nil {
+		return err
+	}
 
+	if this.xrefMode == XrefModeStream {
+		return this.writeXrefStreamTrailer(compressed)
+	}
+	xrefStmOffset := int64(0)
+	if this.xrefMode == XrefModeHybrid && len(compressed) > 0 {
+		xrefStmOffset, err = this.writeXrefStream(compressed, MakeDict(), true)
+		if err != nil {
+			return err
 		}
-		this.writeObject(idx+1, obj)
 	}
-	w.Flush()
 
-	xrefOffset, _ := ws.Seek(0, os.SEEK_CUR)
+	xrefOffset := this.offset()
 	// Write xref table.
 	this.writer.WriteString("xref\r\n")
 	outStr := fmt.Sprintf("%d %d\r\n", 0, len(this.objects)+1)
 	this.writer.WriteString(outStr)
 	outStr = fmt.Sprintf("%.10d %.5d f\r\n", 0, 65535)
 	this.writer.WriteString(outStr)
-	for _, offset := range offsets {
-		outStr = fmt.Sprintf("%.10d %.5d n\r\n", offset, 0)
+	for idx, offset := range this.offsets {
+		if _, isCompressed := compressed[this.objects[idx]]; isCompressed {
+			// Listed in the cross-reference stream.
+			outStr = fmt.Sprintf("%.10d %.5d f\r\n", 0, 0)
+		} else {
+			outStr = fmt.Sprintf("%.10d %.5d n\r\n", offset, 0)
+		}
 		this.writer.WriteString(outStr)
 	}
 
@@ -655,6 +919,9 @@ func (this *PdfWriter) Write(ws io.WriteSeeker) error {
 		trailer.Set("ID", this.ids)
 		common.Log.Trace("Ids: %s", this.ids)
 	}
+	if xrefStmOffset != 0 {
+		trailer.Set("XRefStm", MakeInteger(xrefStmOffset))
+	}
 	this.writer.WriteString("trailer\n")
 	this.writer.WriteString(trailer.DefaultWriteString())
 	this.writer.WriteString("\n")
@@ -663,7 +930,109 @@ func (this *PdfWriter) Write(ws io.WriteSeeker) error {
 	outStr = fmt.Sprintf("startxref\n%d\n", xrefOffset)
 	this.writer.WriteString(outStr)
 	this.writer.WriteString("%%EOF\n")
-	w.Flush()
 
+	return this.writer.Flush()
+}
+
+// FlushPages writes the pages added so far and the objects they use to w, so that large documents can be output
+// while being generated, without keeping the content of all their pages in memory.  The first call writes the header
+// of the document, the following calls and Write must be given the same writer.  Encryption must be set up before.
+//
+// The objects written, e.g. resources also used by pages added later, must not be modified afterwards, and the data
+// of the content streams of the pages written is released.  The document-level objects, such as the page tree, forms
+// and outlines, are written by Write.
+func (this *PdfWriter) FlushPages(w io.Writer) error {
+	if this.output == nil {
+		this.startOutput(w)
+	}
+
+	deferred := map[PdfObject]bool{this.root: true, this.pages: true, this.infoObj: true}
+	err := this.writeObjects(this.flushedObjects, deferred)
+	if err != nil {
+		return err
+	}
+	this.flushedObjects = len(this.objects)
+
+	if kids, ok := this.pages.PdfObject.(*PdfObjectDictionary).Get("Kids").(*PdfObjectArray); ok {
+		for _, kid := range (*kids)[this.flushedPages:] {
+			releasePageContents(kid)
+		}
+		this.flushedPages = len(*kids)
+	}
+
+	return this.writer.Flush()
+}
+
+// releasePageContents releases the data of the content streams of a page written.
+func releasePageContents(page PdfObject) {
+	pageDict, ok := TraceToDirectObject(page).(*PdfObjectDictionary)
+	if !ok {
+		return
+	}
+	contents := []PdfObject{pageDict.Get("Contents")}
+	if arr, isArray := TraceToDirectObject(pageDict.Get("Contents")).(*PdfObjectArray); isArray {
+		contents = *arr
+	}
+	for _, obj := range contents {
+		if stream, isStream := obj.(*PdfObjectStream); isStream {
+			stream.Stream = nil
+		}
+	}
+}
+
+// startOutput writes the header of the document to w.
+func (this *PdfWriter) startOutput(w io.Writer) {
+	this.output = &countingWriter{w: w}
+	this.writer = bufio.NewWriter(this.output)
+
+	this.writer.WriteString(fmt.Sprintf("%%PDF-%d.%d\n", this.majorVersion, this.minorVersion))
+	this.writer.WriteString("%âãÏÓ\n")
+}
+
+// offset returns the current offset in the output.
+func (this *PdfWriter) offset() int64 {
+	return this.output.n + int64(this.writer.Buffered())
+}
+
+// writeObjects writes the objects from the index start which are not written yet, except the deferred ones.
+func (this *PdfWriter) writeObjects(start int, deferred map[PdfObject]bool) error {
+	this.updateObjectNumbers()
+	for len(this.offsets) < len(this.objects) {
+		this.offsets = append(this.offsets, 0)
+	}
+
+	common.Log.Trace("Writing %d obj", len(this.objects)-start)
+	for idx := start; idx < len(this.objects); idx++ {
+		obj := this.objects[idx]
+		if this.offsets[idx] != 0 || deferred[obj] {
+			continue
+		}
+		common.Log.Trace("Writing %d", idx)
+		this.offsets[idx] = this.offset()
+
+		// Encrypt prior to writing.
+		// Encrypt dictionary should not be encrypted.
+		if this.crypter != nil && obj != this.encryptObj {
+			err := this.crypter.Encrypt(obj, int64(idx+1), 0)
+			if err != nil {
+				common.Log.Debug("ERROR: Failed encrypting (%s)", err)
+				return err
+			}
+
+		}
+		this.writeObject(idx+1, obj)
+	}
 	return nil
 }
+
+// countingWriter counts the bytes written, for the offsets of the cross-reference table.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,414 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unidoc/unidoc/pdf/core"
+)
+
+func TestFlushPages(t *testing.T) {
+	makePage := func(text string) *PdfPage {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+		page.Resources = NewPdfPageResources()
+		page.AddContentStreamByString("BT /F1 12 Tf 10 10 Td (" + text + ") Tj ET")
+		return page
+	}
+
+	var buf bytes.Buffer
+	w := NewPdfWriter()
+	first := makePage("first")
+	content := first.Contents.(*core.PdfObjectStream)
+	if err := w.AddPage(first); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := w.FlushPages(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// The page is written and its content released, the catalog is not written yet.
+	if !strings.HasPrefix(buf.String(), "%PDF-1.3") || !strings.Contains(buf.String(), "(first) Tj") {
+		t.Errorf("Page not written: %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "/Catalog") {
+		t.Errorf("Catalog written before the end")
+	}
+	if content.Stream != nil {
+		t.Errorf("Page content not released")
+	}
+
+	if err := w.AddPage(makePage("second")); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if numPages, _ := reader.GetNumPages(); numPages != 2 {
+		t.Fatalf("%d pages, expected 2", numPages)
+	}
+	page, err := reader.GetPage(2)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(contents, "(second) Tj") {
+		t.Errorf("Wrong contents %q", contents)
+	}
+}
+
+func TestDocInfo(t *testing.T) {
+	w := NewPdfWriter()
+	w.SetDocInfo("Title", "Rapport annuel – 2019")
+	w.SetDocInfo("Author", "Zoë")
+	w.SetDocInfo("Creator", "")
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+	page.Resources = NewPdfPageResources()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	info, err := reader.GetDocInfo()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if info["Title"] != "Rapport annuel – 2019" || info["Author"] != "Zoë" || info["Producer"] == "" {
+		t.Errorf("Wrong info %v", info)
+	}
+	if _, has := info["Creator"]; has {
+		t.Errorf("Creator not removed")
+	}
+}
+
+// makeTestCertificate returns a self-signed RSA certificate and its private key.
+func makeTestCertificate(t *testing.T, name string, serial int64) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return cert, key
+}
+
+func TestEncryptForRecipients(t *testing.T) {
+	alice, aliceKey := makeTestCertificate(t, "Alice", 1)
+	bob, bobKey := makeTestCertificate(t, "Bob", 2)
+	eve, eveKey := makeTestCertificate(t, "Eve", 3)
+
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+	page.Resources = NewPdfPageResources()
+	page.AddContentStreamByString("BT /F1 12 Tf 10 10 Td (secret) Tj ET")
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err := w.EncryptForRecipients([]core.PubSecRecipient{
+		{Certificate: alice, Permissions: core.AccessPermissions{Printing: true, Modify: true}},
+		{Certificate: bob, Permissions: core.AccessPermissions{Printing: true}},
+	})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if strings.Contains(buf.String(), "(secret) Tj") {
+		t.Fatalf("Content not encrypted")
+	}
+
+	// Not a recipient.
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if ok, err := reader.DecryptWithCertificate(eve, eveKey); ok || err != nil {
+		t.Fatalf("Decrypted by non-recipient (%v)", err)
+	}
+	if ok, _ := reader.Decrypt([]byte("")); ok {
+		t.Fatalf("Decrypted with password")
+	}
+
+	for _, tc := range []struct {
+		cert   *x509.Certificate
+		key    *rsa.PrivateKey
+		modify bool
+	}{{alice, aliceKey, true}, {bob, bobKey, false}} {
+		reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		ok, perms, err := reader.CheckAccessRightsWithCertificate(tc.cert, tc.key)
+		if err != nil || !ok {
+			t.Fatalf("No access (%v)", err)
+		}
+		if !perms.Printing || perms.Modify != tc.modify || perms.Annotate {
+			t.Errorf("Wrong permissions %+v", perms)
+		}
+		if ok, err := reader.DecryptWithCertificate(tc.cert, tc.key); err != nil || !ok {
+			t.Fatalf("Decryption failed (%v)", err)
+		}
+		page, err := reader.GetPage(1)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		contents, err := page.GetAllContentStreams()
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if !strings.Contains(contents, "(secret) Tj") {
+			t.Errorf("Wrong contents %q", contents)
+		}
+	}
+}
+
+func TestEncryptPermissions(t *testing.T) {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+	page.Resources = NewPdfPageResources()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	options := &EncryptOptions{Permissions: core.AccessPermissions{Printing: true, ExtractGraphics: true}}
+	if err := w.Encrypt([]byte("user"), []byte("owner"), options); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if _, err := reader.GetAccessPermissions(); err == nil {
+		t.Errorf("Permissions reported before decryption")
+	}
+	if ok, err := reader.Decrypt([]byte("user")); err != nil || !ok {
+		t.Fatalf("Decryption failed (%v)", err)
+	}
+	perms, err := reader.GetAccessPermissions()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if perms != options.Permissions {
+		t.Errorf("Wrong permissions %+v", perms)
+	}
+	if err := reader.CheckPermission(core.PermissionCopy); err != nil {
+		t.Errorf("Copy denied: %v", err)
+	}
+	if err := reader.CheckPermission(core.PermissionModify); err != ErrPermissionDenied {
+		t.Errorf("Modify allowed (%v)", err)
+	}
+
+	// The owner has full access.
+	reader, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if ok, err := reader.Decrypt([]byte("owner")); err != nil || !ok {
+		t.Fatalf("Decryption failed (%v)", err)
+	}
+	if err := reader.CheckPermission(core.PermissionModify); err != nil {
+		t.Errorf("Modify denied to owner: %v", err)
+	}
+}
+
+func TestEncryptUnencryptedMetadata(t *testing.T) {
+	xmp := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><dc:title>Indexed title</dc:title></x:xmpmeta>`)
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+	page.Resources = NewPdfPageResources()
+	page.AddContentStreamByString("BT /F1 12 Tf 10 10 Td (secret) Tj ET")
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := w.SetMetadata(xmp); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := w.Encrypt([]byte("user"), []byte("owner"), &EncryptOptions{UnencryptedMetadata: true}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// The metadata can be read without decrypting, the content is encrypted.
+	if !bytes.Contains(buf.Bytes(), xmp) {
+		t.Errorf("Metadata encrypted")
+	}
+	if strings.Contains(buf.String(), "(secret) Tj") {
+		t.Errorf("Content not encrypted")
+	}
+
+	reader, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	crypter := reader.parser.GetCrypter()
+	if crypter == nil || crypter.EncryptMetadata || crypter.V != 4 {
+		t.Fatalf("Wrong crypter %+v", crypter)
+	}
+	if ok, err := reader.Decrypt([]byte("user")); err != nil || !ok {
+		t.Fatalf("Decryption failed (%v)", err)
+	}
+	metadata, err := reader.traceToObject(reader.catalog.Get("Metadata"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	stream, ok := metadata.(*core.PdfObjectStream)
+	if !ok || !bytes.Equal(stream.Stream, xmp) {
+		t.Errorf("Wrong metadata %v", metadata)
+	}
+	page, err = reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(contents, "(secret) Tj") {
+		t.Errorf("Wrong contents %q", contents)
+	}
+}
+
+func TestLazyStreams(t *testing.T) {
+	img := &Image{Width: 100, Height: 100, BitsPerComponent: 8, ColorComponents: 1}
+	img.Data = make([]byte, 100*100)
+	for i := range img.Data {
+		img.Data[i] = byte(i * 7)
+	}
+	ximg, err := NewXObjectImageFromImage(img, nil, core.NewFlateEncoder())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	makeDocument := func(encrypt bool) []byte {
+		w := NewPdfWriter()
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Llx: 0, Lly: 0, Urx: 200, Ury: 200}
+		page.Resources = NewPdfPageResources()
+		if err := page.Resources.SetXObjectImageByName("Im1", ximg); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		page.AddContentStreamByString("q 100 0 0 100 0 0 cm /Im1 Do Q")
+		if err := w.AddPage(page); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if encrypt {
+			if err := w.Encrypt([]byte("user"), []byte("owner"), nil); err != nil {
+				t.Fatalf("Error: %v", err)
+			}
+		}
+		var buf bytes.Buffer
+		if err := w.Write(&buf); err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		return buf.Bytes()
+	}
+	// imageStream returns the image stream of the first page of a document read with lazy streams.
+	imageStream := func(data []byte, password string) (*PdfReader, *core.PdfObjectStream) {
+		reader, err := NewPdfReaderLazy(bytes.NewReader(data), 100)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if password != "" {
+			if ok, err := reader.Decrypt([]byte(password)); err != nil || !ok {
+				t.Fatalf("Decryption failed (%v)", err)
+			}
+		}
+		page, err := reader.GetPage(1)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		stream, _ := page.Resources.GetXObjectByName("Im1")
+		if stream == nil || stream.IsLoaded() {
+			t.Fatalf("Image stream not lazy: %v", stream)
+		}
+		return reader, stream
+	}
+
+	reader, stream := imageStream(makeDocument(false), "")
+	r, err := core.NewDecodeReader(stream)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	decoded, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !bytes.Equal(decoded, img.Data) {
+		t.Errorf("Wrong image data")
+	}
+
+	// Written copying the data from the file.
+	w := NewPdfWriter()
+	page, _ := reader.GetPage(1)
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if stream.IsLoaded() {
+		t.Errorf("Image stream loaded by writing")
+	}
+	_, stream = imageStream(buf.Bytes(), "")
+	if decoded, err := core.DecodeStream(stream); err != nil || !bytes.Equal(decoded, img.Data) {
+		t.Errorf("Wrong image data written (%v)", err)
+	}
+
+	// Decrypted when read.
+	_, stream = imageStream(makeDocument(true), "user")
+	if decoded, err := core.DecodeStream(stream); err != nil || !bytes.Equal(decoded, img.Data) {
+		t.Errorf("Wrong decrypted image data (%v)", err)
+	}
+}
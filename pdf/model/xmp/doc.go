@@ -0,0 +1,19 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package xmp reads and writes the XMP metadata of PDF documents (the Metadata stream of the document catalog).
+// The Dublin Core, XMP Basic, Adobe PDF and PDF/A identification properties are parsed into typed structures,
+// while the other properties are kept as they are when the metadata is written back.  The metadata can be kept in
+// sync with the document information dictionary (Info), as required by PDF/A.
+//
+// Example: updating the title of a document.
+//
+//	meta, err := xmp.Load(pdfReader)
+//	...
+//	meta.DublinCore.Title = "Annual report"
+//	meta.Basic.ModifyDate = time.Now()
+//	// Add the pages to pdfWriter, then:
+//	err = xmp.Write(&pdfWriter, meta)
+package xmp
@@ -0,0 +1,390 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// DublinCore contains the Dublin Core properties (dc namespace) describing the document.
+type DublinCore struct {
+	// Title of the document (the default language alternative of dc:title).
+	Title string
+	// Authors of the document (dc:creator).
+	Creators []string
+	// Description of the document (the default language alternative of dc:description).
+	Description string
+	// Keywords of the document (dc:subject).
+	Subject []string
+	// Media type of the document, application/pdf (dc:format).
+	Format string
+}
+
+// Basic contains the XMP Basic properties (xmp namespace).
+type Basic struct {
+	// The application which created the original document (xmp:CreatorTool).
+	CreatorTool string
+	// Dates of the creation and last modification of the document, and of the last change of the metadata.
+	// Zero if not set.
+	CreateDate   time.Time
+	ModifyDate   time.Time
+	MetadataDate time.Time
+}
+
+// PDFProperties contains the Adobe PDF properties (pdf namespace).
+type PDFProperties struct {
+	// The application which converted the document to PDF (pdf:Producer).
+	Producer string
+	// Keywords of the document (pdf:Keywords).
+	Keywords string
+	// The PDF version of the document, e.g. "1.4" (pdf:PDFVersion).
+	PDFVersion string
+	// Whether the document has been trapped: "True", "False" or "Unknown" (pdf:Trapped).
+	Trapped string
+}
+
+// PDFAIdentification contains the PDF/A identification properties (pdfaid namespace) of documents conforming to
+// PDF/A.
+type PDFAIdentification struct {
+	// The part of the PDF/A standard, e.g. 1 for PDF/A-1 (pdfaid:part).  0 if not set.
+	Part int
+	// The conformance level, e.g. "B" for PDF/A-1b (pdfaid:conformance).
+	Conformance string
+	// The amendment of the part (pdfaid:amd).
+	Amendment string
+}
+
+// Metadata is the XMP metadata of a document.
+type Metadata struct {
+	DublinCore DublinCore
+	Basic      Basic
+	PDF        PDFProperties
+	PDFAID     PDFAIdentification
+
+	// The other properties, kept as they are.
+	other []*node
+
+	// The prefixes of the namespaces declared in the parsed metadata.
+	prefixes map[string]string
+}
+
+// New returns empty metadata, with the dc:format of PDF documents.
+func New() *Metadata {
+	m := &Metadata{prefixes: map[string]string{}}
+	m.DublinCore.Format = "application/pdf"
+	return m
+}
+
+// Parse parses an XMP packet, e.g. the contents of the Metadata stream of a document (see
+// model.PdfReader.GetMetadata).
+func Parse(data []byte) (*Metadata, error) {
+	root, prefixes, err := parseNodes(data)
+	if err != nil {
+		return nil, err
+	}
+	rdf := root.find(nsRDF, "RDF")
+	if rdf == nil {
+		common.Log.Debug("ERROR: XMP without rdf:RDF")
+		return nil, errors.New("Missing rdf:RDF element")
+	}
+
+	m := &Metadata{prefixes: prefixes}
+	for _, desc := range rdf.children {
+		if desc.name.Space != nsRDF || desc.name.Local != "Description" {
+			continue
+		}
+		// Simple properties can be written as attributes of the description.
+		for _, attr := range desc.attrs {
+			if attr.Name.Space == nsRDF || attr.Name.Space == "" {
+				continue
+			}
+			m.setProperty(&node{name: attr.Name, text: attr.Value})
+		}
+		for _, prop := range desc.children {
+			m.setProperty(prop)
+		}
+	}
+	return m, nil
+}
+
+// setProperty sets a property of the typed structures, or keeps it in the other properties.
+func (m *Metadata) setProperty(prop *node) {
+	value := strings.TrimSpace(prop.text)
+	switch prop.name {
+	case xml.Name{Space: nsDC, Local: "title"}:
+		m.DublinCore.Title = langAlternative(prop)
+	case xml.Name{Space: nsDC, Local: "creator"}:
+		m.DublinCore.Creators = arrayItems(prop)
+	case xml.Name{Space: nsDC, Local: "description"}:
+		m.DublinCore.Description = langAlternative(prop)
+	case xml.Name{Space: nsDC, Local: "subject"}:
+		m.DublinCore.Subject = arrayItems(prop)
+	case xml.Name{Space: nsDC, Local: "format"}:
+		m.DublinCore.Format = value
+	case xml.Name{Space: nsXMP, Local: "CreatorTool"}:
+		m.Basic.CreatorTool = value
+	case xml.Name{Space: nsXMP, Local: "CreateDate"}:
+		m.Basic.CreateDate = parseDate(value)
+	case xml.Name{Space: nsXMP, Local: "ModifyDate"}:
+		m.Basic.ModifyDate = parseDate(value)
+	case xml.Name{Space: nsXMP, Local: "MetadataDate"}:
+		m.Basic.MetadataDate = parseDate(value)
+	case xml.Name{Space: nsPDF, Local: "Producer"}:
+		m.PDF.Producer = value
+	case xml.Name{Space: nsPDF, Local: "Keywords"}:
+		m.PDF.Keywords = value
+	case xml.Name{Space: nsPDF, Local: "PDFVersion"}:
+		m.PDF.PDFVersion = value
+	case xml.Name{Space: nsPDF, Local: "Trapped"}:
+		m.PDF.Trapped = value
+	case xml.Name{Space: nsPDFAID, Local: "part"}:
+		part, err := strconv.Atoi(value)
+		if err != nil {
+			common.Log.Debug("Invalid pdfaid:part %q", value)
+		}
+		m.PDFAID.Part = part
+	case xml.Name{Space: nsPDFAID, Local: "conformance"}:
+		m.PDFAID.Conformance = value
+	case xml.Name{Space: nsPDFAID, Local: "amd"}:
+		m.PDFAID.Amendment = value
+	default:
+		m.other = append(m.other, prop)
+	}
+}
+
+// langAlternative returns the default (x-default) or first alternative of a language alternative (rdf:Alt).
+func langAlternative(prop *node) string {
+	alt := prop.find(nsRDF, "Alt")
+	if alt == nil {
+		return strings.TrimSpace(prop.text)
+	}
+	value := ""
+	for i, li := range alt.children {
+		if lang, _ := li.attr(nsXML, "lang"); lang == "x-default" || i == 0 {
+			value = li.text
+		}
+	}
+	return value
+}
+
+// arrayItems returns the items of an ordered (rdf:Seq) or unordered (rdf:Bag) array.
+func arrayItems(prop *node) []string {
+	items := []string{}
+	for _, array := range prop.children {
+		if array.name.Space != nsRDF || (array.name.Local != "Seq" && array.name.Local != "Bag") {
+			continue
+		}
+		for _, li := range array.children {
+			items = append(items, li.text)
+		}
+	}
+	if len(items) == 0 && strings.TrimSpace(prop.text) != "" {
+		items = append(items, strings.TrimSpace(prop.text))
+	}
+	return items
+}
+
+// XMP date layouts, from the most to the least precise (see XMP Part 1, 7.3.2).
+var dateLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseDate parses an XMP date, or returns the zero time if invalid.
+func parseDate(value string) time.Time {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	common.Log.Debug("Invalid XMP date %q", value)
+	return time.Time{}
+}
+
+// Marshal returns the metadata serialized as an XMP packet, for the Metadata stream of a document (see
+// model.PdfWriter.SetMetadata).  The properties are written in a single rdf:Description.
+func (m *Metadata) Marshal() ([]byte, error) {
+	props := []*node{}
+	addText := func(space, local, value string) {
+		if value != "" {
+			props = append(props, &node{name: xml.Name{Space: space, Local: local}, text: value})
+		}
+	}
+	addArray := func(space, local, arrayType string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		array := &node{name: xml.Name{Space: nsRDF, Local: arrayType}}
+		for _, item := range items {
+			array.children = append(array.children, &node{name: xml.Name{Space: nsRDF, Local: "li"}, text: item})
+		}
+		props = append(props, &node{name: xml.Name{Space: space, Local: local}, children: []*node{array}})
+	}
+	addAlt := func(space, local, value string) {
+		if value == "" {
+			return
+		}
+		li := &node{name: xml.Name{Space: nsRDF, Local: "li"}, text: value,
+			attrs: []xml.Attr{{Name: xml.Name{Space: nsXML, Local: "lang"}, Value: "x-default"}}}
+		alt := &node{name: xml.Name{Space: nsRDF, Local: "Alt"}, children: []*node{li}}
+		props = append(props, &node{name: xml.Name{Space: space, Local: local}, children: []*node{alt}})
+	}
+	addDate := func(local string, t time.Time) {
+		if !t.IsZero() {
+			addText(nsXMP, local, t.Format(time.RFC3339))
+		}
+	}
+
+	dc := m.DublinCore
+	addAlt(nsDC, "title", dc.Title)
+	addArray(nsDC, "creator", "Seq", dc.Creators)
+	addAlt(nsDC, "description", dc.Description)
+	addArray(nsDC, "subject", "Bag", dc.Subject)
+	addText(nsDC, "format", dc.Format)
+	addText(nsXMP, "CreatorTool", m.Basic.CreatorTool)
+	addDate("CreateDate", m.Basic.CreateDate)
+	addDate("ModifyDate", m.Basic.ModifyDate)
+	addDate("MetadataDate", m.Basic.MetadataDate)
+	addText(nsPDF, "Producer", m.PDF.Producer)
+	addText(nsPDF, "Keywords", m.PDF.Keywords)
+	addText(nsPDF, "PDFVersion", m.PDF.PDFVersion)
+	addText(nsPDF, "Trapped", m.PDF.Trapped)
+	if m.PDFAID.Part > 0 {
+		addText(nsPDFAID, "part", strconv.Itoa(m.PDFAID.Part))
+	}
+	addText(nsPDFAID, "conformance", m.PDFAID.Conformance)
+	addText(nsPDFAID, "amd", m.PDFAID.Amendment)
+	props = append(props, m.other...)
+
+	desc := &node{
+		name:     xml.Name{Space: nsRDF, Local: "Description"},
+		attrs:    []xml.Attr{{Name: xml.Name{Space: nsRDF, Local: "about"}}},
+		children: props,
+	}
+	spaces := map[string]bool{nsX: true}
+	desc.namespaces(spaces)
+	w := newNodeWriter(spaces, m.prefixes)
+
+	w.buf.WriteString(packetHeader)
+	w.buf.WriteString("<x:xmpmeta" + w.declarations() + ">\n")
+	rdf := &node{name: xml.Name{Space: nsRDF, Local: "RDF"}, children: []*node{desc}}
+	w.write(rdf, 1)
+	w.buf.WriteString("</x:xmpmeta>\n")
+	w.buf.WriteString(packetTrailer)
+	return w.buf.Bytes(), nil
+}
+
+// DocInfo returns the entries of the document information dictionary (Info) equivalent to the metadata: Title,
+// Author, Subject, Keywords, Creator, Producer, CreationDate and ModDate.  The entries without a value in the
+// metadata are empty.
+func (m *Metadata) DocInfo() map[string]string {
+	info := map[string]string{
+		"Title":    m.DublinCore.Title,
+		"Author":   strings.Join(m.DublinCore.Creators, "; "),
+		"Subject":  m.DublinCore.Description,
+		"Keywords": m.PDF.Keywords,
+		"Creator":  m.Basic.CreatorTool,
+		"Producer": m.PDF.Producer,
+	}
+	for key, t := range map[string]time.Time{"CreationDate": m.Basic.CreateDate, "ModDate": m.Basic.ModifyDate} {
+		info[key] = ""
+		if !t.IsZero() {
+			date := model.NewPdfDateFromTime(t)
+			info[key] = string(*date.ToPdfObject().(*core.PdfObjectString))
+		}
+	}
+	return info
+}
+
+// SetDocInfo sets the metadata equivalent to the entries of a document information dictionary, e.g. from
+// model.PdfReader.GetDocInfo.  See DocInfo for the entries used; the other entries are ignored.
+func (m *Metadata) SetDocInfo(info map[string]string) {
+	if title, has := info["Title"]; has {
+		m.DublinCore.Title = title
+	}
+	if author, has := info["Author"]; has {
+		m.DublinCore.Creators = nil
+		if author != "" {
+			m.DublinCore.Creators = []string{author}
+		}
+	}
+	if subject, has := info["Subject"]; has {
+		m.DublinCore.Description = subject
+	}
+	if keywords, has := info["Keywords"]; has {
+		m.PDF.Keywords = keywords
+	}
+	if creator, has := info["Creator"]; has {
+		m.Basic.CreatorTool = creator
+	}
+	if producer, has := info["Producer"]; has {
+		m.PDF.Producer = producer
+	}
+	for key, t := range map[string]*time.Time{"CreationDate": &m.Basic.CreateDate, "ModDate": &m.Basic.ModifyDate} {
+		str, has := info[key]
+		if !has {
+			continue
+		}
+		date, err := model.NewPdfDate(str)
+		if err != nil {
+			common.Log.Debug("Invalid %s date %q", key, str)
+			continue
+		}
+		*t = date.ToGoTime()
+	}
+}
+
+// Load returns the XMP metadata of the document loaded by reader.  Documents without a Metadata stream get new
+// metadata from their document information dictionary.
+func Load(reader *model.PdfReader) (*Metadata, error) {
+	data, err := reader.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		return Parse(data)
+	}
+	info, err := reader.GetDocInfo()
+	if err != nil {
+		return nil, err
+	}
+	m := New()
+	m.SetDocInfo(info)
+	return m, nil
+}
+
+// Write sets the metadata as the Metadata stream of the document written by w, and the entries of its document
+// information dictionary to the values in the metadata (see DocInfo), so both are in sync.  The Info entries
+// without a value in the metadata are left as they are.
+func Write(w *model.PdfWriter, m *Metadata) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	err = w.SetMetadata(data)
+	if err != nil {
+		return err
+	}
+	for key, value := range m.DocInfo() {
+		if value != "" {
+			w.SetDocInfo(key, value)
+		}
+	}
+	return nil
+}
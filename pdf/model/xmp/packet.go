@@ -0,0 +1,235 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+)
+
+// The namespaces of the XMP packet and of the schemas parsed.
+const (
+	nsX      = "adobe:ns:meta/"
+	nsRDF    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXML    = "http://www.w3.org/XML/1998/namespace"
+	nsDC     = "http://purl.org/dc/elements/1.1/"
+	nsXMP    = "http://ns.adobe.com/xap/1.0/"
+	nsPDF    = "http://ns.adobe.com/pdf/1.3/"
+	nsPDFAID = "http://www.aiim.org/pdfa/ns/id/"
+)
+
+// The usual prefixes of the namespaces.
+var defaultPrefixes = map[string]string{
+	nsX:      "x",
+	nsRDF:    "rdf",
+	nsXML:    "xml",
+	nsDC:     "dc",
+	nsXMP:    "xmp",
+	nsPDF:    "pdf",
+	nsPDFAID: "pdfaid",
+}
+
+// The XMP packet header and trailer.
+const (
+	packetHeader  = "<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n"
+	packetTrailer = "<?xpacket end=\"w\"?>"
+)
+
+// node is an XML element, with the namespaces of its name and attributes resolved.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+// parseNodes parses an XML document into its root element, and returns the prefixes declared by namespace.
+func parseNodes(data []byte) (*node, map[string]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	prefixes := map[string]string{}
+
+	var root *node
+	stack := []*node{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid XMP: %v", err)
+			return nil, nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					if _, has := prefixes[attr.Value]; !has {
+						prefixes[attr.Value] = attr.Name.Local
+					}
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+				default:
+					n.attrs = append(n.attrs, attr)
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, nil, errors.New("Missing XMP root element")
+	}
+	return root, prefixes, nil
+}
+
+// find returns the first descendant of the node (or the node itself) with the name, or nil if none.
+func (n *node) find(space, local string) *node {
+	if n.name.Space == space && n.name.Local == local {
+		return n
+	}
+	for _, child := range n.children {
+		if found := child.find(space, local); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr returns the value of the attribute with the name, and whether it is present.
+func (n *node) attr(space, local string) (string, bool) {
+	for _, attr := range n.attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// namespaces adds the namespaces of the names of the node, its attributes and descendants.
+func (n *node) namespaces(spaces map[string]bool) {
+	spaces[n.name.Space] = true
+	for _, attr := range n.attrs {
+		if attr.Name.Space != "" {
+			spaces[attr.Name.Space] = true
+		}
+	}
+	for _, child := range n.children {
+		child.namespaces(spaces)
+	}
+}
+
+// nodeWriter writes nodes with the prefixes of their namespaces.
+type nodeWriter struct {
+	buf      bytes.Buffer
+	prefixes map[string]string
+}
+
+// newNodeWriter returns a writer with prefixes for the namespaces, preferring the declared prefixes, then the usual
+// ones, and generating the others.
+func newNodeWriter(spaces map[string]bool, declared map[string]string) *nodeWriter {
+	w := &nodeWriter{prefixes: map[string]string{}}
+	used := map[string]bool{}
+	sorted := []string{}
+	for space := range spaces {
+		sorted = append(sorted, space)
+	}
+	sort.Strings(sorted)
+
+	for _, space := range sorted {
+		prefix, has := defaultPrefixes[space]
+		if !has {
+			prefix, has = declared[space]
+		}
+		if has && !used[prefix] {
+			w.prefixes[space] = prefix
+			used[prefix] = true
+		}
+	}
+	for i, space := range sorted {
+		if _, has := w.prefixes[space]; has {
+			continue
+		}
+		prefix := fmt.Sprintf("ns%d", i+1)
+		for used[prefix] {
+			prefix += "_"
+		}
+		w.prefixes[space] = prefix
+		used[prefix] = true
+	}
+	return w
+}
+
+// qname returns the qualified name of an element or attribute.
+func (w *nodeWriter) qname(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return w.prefixes[name.Space] + ":" + name.Local
+}
+
+// declarations returns the namespace declarations of the prefixes, except the xml namespace.
+func (w *nodeWriter) declarations() string {
+	decls := []string{}
+	for space, prefix := range w.prefixes {
+		if space == nsXML || space == "" {
+			continue
+		}
+		decls = append(decls, fmt.Sprintf(" xmlns:%s=\"%s\"", prefix, escape(space)))
+	}
+	sort.Strings(decls)
+	return strings.Join(decls, "")
+}
+
+// write writes the node indented by the depth.
+func (w *nodeWriter) write(n *node, depth int) {
+	indent := strings.Repeat(" ", depth)
+	w.buf.WriteString(indent + "<" + w.qname(n.name))
+	for _, attr := range n.attrs {
+		w.buf.WriteString(fmt.Sprintf(" %s=\"%s\"", w.qname(attr.Name), escape(attr.Value)))
+	}
+	if len(n.children) == 0 {
+		if n.text == "" {
+			w.buf.WriteString("/>\n")
+			return
+		}
+		w.buf.WriteString(">" + escape(n.text) + "</" + w.qname(n.name) + ">\n")
+		return
+	}
+	w.buf.WriteString(">\n")
+	for _, child := range n.children {
+		w.write(child, depth+1)
+	}
+	w.buf.WriteString(indent + "</" + w.qname(n.name) + ">\n")
+}
+
+// escape escapes the XML special characters of text.
+func escape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
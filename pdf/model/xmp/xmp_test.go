@@ -0,0 +1,156 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+const testXMP = `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <dc:format>application/pdf</dc:format>
+   <dc:title><rdf:Alt>
+    <rdf:li xml:lang="fr">Rapport</rdf:li>
+    <rdf:li xml:lang="x-default">Report</rdf:li>
+   </rdf:Alt></dc:title>
+   <dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li><rdf:li>John Roe</rdf:li></rdf:Seq></dc:creator>
+   <dc:subject><rdf:Bag><rdf:li>finance</rdf:li><rdf:li>2017</rdf:li></rdf:Bag></dc:subject>
+  </rdf:Description>
+  <rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:pdf="http://ns.adobe.com/pdf/1.3/" xmlns:mm="http://ns.adobe.com/xap/1.0/mm/"
+    xmp:CreatorTool="Writer" pdf:Producer="Converter 1.0" mm:DocumentID="uuid:1234">
+   <xmp:CreateDate>2017-03-04T10:20:30+01:00</xmp:CreateDate>
+   <xmp:ModifyDate>2017-03-05</xmp:ModifyDate>
+  </rdf:Description>
+  <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+   <pdfaid:part>1</pdfaid:part>
+   <pdfaid:conformance>B</pdfaid:conformance>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func TestParseMetadata(t *testing.T) {
+	m, err := Parse([]byte(testXMP))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	dc := DublinCore{
+		Title:    "Report",
+		Creators: []string{"Jane Doe", "John Roe"},
+		Subject:  []string{"finance", "2017"},
+		Format:   "application/pdf",
+	}
+	if !reflect.DeepEqual(m.DublinCore, dc) {
+		t.Errorf("Wrong Dublin Core %+v", m.DublinCore)
+	}
+	created := time.Date(2017, 3, 4, 10, 20, 30, 0, time.FixedZone("", 3600))
+	if m.Basic.CreatorTool != "Writer" || !m.Basic.CreateDate.Equal(created) ||
+		!m.Basic.ModifyDate.Equal(time.Date(2017, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Wrong XMP Basic %+v", m.Basic)
+	}
+	if m.PDF.Producer != "Converter 1.0" || m.PDFAID.Part != 1 || m.PDFAID.Conformance != "B" {
+		t.Errorf("Wrong PDF properties %+v %+v", m.PDF, m.PDFAID)
+	}
+
+	// Modified and written back, keeping the other properties.
+	m.DublinCore.Title = "Annual <report>"
+	m.PDF.Keywords = "finance, 2017"
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), packetHeader) || !strings.HasSuffix(string(data), packetTrailer) {
+		t.Errorf("Missing packet wrapper")
+	}
+	if !strings.Contains(string(data), `xmlns:mm="http://ns.adobe.com/xap/1.0/mm/"`) {
+		t.Errorf("Declared prefix not kept")
+	}
+	m2, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Error: %v\n%s", err, data)
+	}
+	if m2.DublinCore.Title != "Annual <report>" || m2.PDF.Keywords != "finance, 2017" ||
+		!reflect.DeepEqual(m2.DublinCore.Creators, dc.Creators) || !m2.Basic.CreateDate.Equal(created) ||
+		m2.PDFAID.Part != 1 {
+		t.Errorf("Wrong metadata written back\n%s", data)
+	}
+	if len(m2.other) != 1 || m2.other[0].name.Local != "DocumentID" || m2.other[0].text != "uuid:1234" {
+		t.Errorf("Other property not kept\n%s", data)
+	}
+}
+
+func TestDocInfoSync(t *testing.T) {
+	m := New()
+	m.SetDocInfo(map[string]string{
+		"Title":        "Report",
+		"Author":       "Jane Doe",
+		"Producer":     "Converter 1.0",
+		"CreationDate": "D:20170304102030+01'00'",
+		"Trapped":      "True",
+	})
+	if m.DublinCore.Title != "Report" || len(m.DublinCore.Creators) != 1 || m.PDF.Producer != "Converter 1.0" {
+		t.Errorf("Wrong metadata %+v", m)
+	}
+	created := time.Date(2017, 3, 4, 10, 20, 30, 0, time.FixedZone("", 3600))
+	if !m.Basic.CreateDate.Equal(created) {
+		t.Errorf("Wrong creation date %v", m.Basic.CreateDate)
+	}
+
+	m.DublinCore.Creators = append(m.DublinCore.Creators, "John Roe")
+	info := m.DocInfo()
+	if info["Author"] != "Jane Doe; John Roe" || info["CreationDate"] != "D:20170304102030+01'00'" ||
+		info["ModDate"] != "" {
+		t.Errorf("Wrong Info %v", info)
+	}
+}
+
+func TestWriteMetadata(t *testing.T) {
+	w := model.NewPdfWriter()
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+	page.Resources = model.NewPdfPageResources()
+	if err := w.AddPage(page); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	m := New()
+	m.DublinCore.Title = "Report"
+	m.Basic.ModifyDate = time.Date(2017, 3, 5, 12, 0, 0, 0, time.UTC)
+	if err := Write(&w, m); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	loaded, err := Load(reader)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if loaded.DublinCore.Title != "Report" || !loaded.Basic.ModifyDate.Equal(m.Basic.ModifyDate) {
+		t.Errorf("Wrong metadata %+v", loaded)
+	}
+	info, err := reader.GetDocInfo()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if info["Title"] != "Report" || info["ModDate"] != "D:20170305120000+00'00'" {
+		t.Errorf("Info not in sync %v", info)
+	}
+}
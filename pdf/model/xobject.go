@@ -51,6 +51,11 @@ func NewXObjectForm() *XObjectForm {
 // Build the Form XObject from a stream object.
 // XXX: Should this be exposed? Consider different access points.
 func NewXObjectFormFromStream(stream *PdfObjectStream) (*XObjectForm, error) {
+	// The data of the stream is loaded if lazy.
+	if err := stream.Load(); err != nil {
+		return nil, err
+	}
+
 	form := &XObjectForm{}
 	form.primitive = stream
 
@@ -281,9 +286,22 @@ func UpdateXObjectImageFromImage(xobjIn *XObjectImage, img *Image, cs PdfColorsp
 		// Add the alpha channel information as a stencil mask (SMask).
 		// Has same width and height as original and stored in same
 		// bits per component (1 component, hence the DeviceGray channel).
+		// The alpha channel is not a color image: not DCT encoded with the image, which expects its colors.
+		smaskEncoder := encoder
+		if _, isDCT := encoder.(*DCTEncoder); isDCT {
+			smaskEncoder = NewFlateEncoder()
+		}
+		if flate, isFlate := encoder.(*FlateEncoder); isFlate && flate.Predictor > 1 {
+			// The predictor of the image is for its color components.
+			smaskFlate := NewFlateEncoder()
+			smaskFlate.Predictor = flate.Predictor
+			smaskFlate.Columns = flate.Columns
+			smaskFlate.BitsPerComponent = flate.BitsPerComponent
+			smaskEncoder = smaskFlate
+		}
 		smask := NewXObjectImage()
-		smask.Filter = encoder
-		encoded, err := encoder.EncodeBytes(img.alphaData)
+		smask.Filter = smaskEncoder
+		encoded, err := smaskEncoder.EncodeBytes(img.alphaData)
 		if err != nil {
 			common.Log.Debug("Error with encoding: %v", err)
 			return nil, err
@@ -369,6 +387,11 @@ func toGray(matte *PdfObjectArray) (float64, error) {
 // Build the image xobject from a stream object.
 // An image dictionary is the dictionary portion of a stream object representing an image XObject.
 func NewXObjectImageFromStream(stream *PdfObjectStream) (*XObjectImage, error) {
+	// The data of the stream is loaded if lazy.
+	if err := stream.Load(); err != nil {
+		return nil, err
+	}
+
 	img := &XObjectImage{}
 	img.primitive = stream
 
@@ -538,6 +561,54 @@ func (ximg *XObjectImage) ToImage() (*Image, error) {
 	return image, nil
 }
 
+// SetColorKeyMask sets a color key mask (Mask array) on the image: pixels whose color components all lie in the
+// ranges are not painted, e.g. the background color of legacy graphics.  The ranges are pairs of the minimum and
+// maximum sample values for each color component, [min1 max1 ... minn maxn], with sample values from 0 to
+// 2^BitsPerComponent-1 (palette indices for Indexed colorspaces).  Replaces a stencil mask set in Mask.  Nil ranges
+// remove the mask.
+func (ximg *XObjectImage) SetColorKeyMask(ranges []int) error {
+	if ranges == nil {
+		ximg.Mask = nil
+		return nil
+	}
+	if ximg.ColorSpace == nil || ximg.BitsPerComponent == nil {
+		return errors.New("Colorspace or bits per component missing")
+	}
+	numComps := ximg.ColorSpace.GetNumComponents()
+	if len(ranges) != 2*numComps {
+		common.Log.Debug("ERROR: Color key mask of %d values for %d components", len(ranges), numComps)
+		return ErrRangeError
+	}
+	maxVal := 1<<uint(*ximg.BitsPerComponent) - 1
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] < 0 || ranges[i] > ranges[i+1] || ranges[i+1] > maxVal {
+			common.Log.Debug("ERROR: Invalid color key mask range %d-%d", ranges[i], ranges[i+1])
+			return ErrRangeError
+		}
+	}
+	ximg.Mask = MakeArrayFromIntegers(ranges)
+	return nil
+}
+
+// GetColorKeyMask returns the ranges of the color key mask of the image (Mask array), pairs of minimum and maximum
+// sample values for each color component, or nil if the image has no color key mask.
+func (ximg *XObjectImage) GetColorKeyMask() ([]int, error) {
+	arr, ok := TraceToDirectObject(ximg.Mask).(*PdfObjectArray)
+	if !ok {
+		return nil, nil
+	}
+	ranges, err := arr.ToIntegerArray()
+	if err != nil {
+		common.Log.Debug("ERROR: Invalid Mask array %s", arr)
+		return nil, err
+	}
+	if len(ranges)%2 != 0 {
+		common.Log.Debug("ERROR: Mask array of odd length %d", len(ranges))
+		return nil, ErrRangeError
+	}
+	return ranges, nil
+}
+
 func (ximg *XObjectImage) GetContainingPdfObject() PdfObject {
 	return ximg.primitive
 }
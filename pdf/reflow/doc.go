@@ -0,0 +1,21 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package reflow re-lays out the text of PDF pages into a target width for reading on small screens, producing a
+// derived PDF document (via the creator) or HTML.  The paragraphs of the pages are found with the extractor and
+// rendered in order with standard fonts, keeping their relative sizes and bold/italic styles.  Images, vector
+// graphics and the original layout are not reproduced.
+//
+// The reflow is experimental: paragraphs are taken in content stream order, so multi-column layouts and pages with
+// floating elements may not be reflowed in reading order.
+//
+// Example:
+//
+//	paragraphs, err := reflow.ExtractParagraphs(pages)
+//	...
+//	c, err := reflow.ToPDF(paragraphs, reflow.DefaultOptions())
+//	...
+//	err = c.WriteToFile("reflowed.pdf")
+package reflow
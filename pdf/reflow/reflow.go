@@ -0,0 +1,213 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package reflow
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/creator"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+// Limits of the font size of paragraphs relative to the body text.
+const (
+	minScale = 0.8
+	maxScale = 2.0
+)
+
+// Relative font sizes from which paragraphs are considered headings.
+const (
+	heading1Scale = 1.5
+	heading2Scale = 1.15
+)
+
+// Options are the layout options of the reflowed output.
+type Options struct {
+	// The size of the output pages in points.  Only the width applies to HTML output.
+	PageWidth  float64
+	PageHeight float64
+
+	// The margin around the text in points.
+	Margin float64
+
+	// The font size of the body text in points.  Other paragraphs, e.g. headings, keep their size relative to the
+	// body text.
+	FontSize float64
+
+	// The line height relative to the font size.
+	LineHeight float64
+}
+
+// DefaultOptions returns options for a small screen: 320x480 point pages with 16 point margins and 12 point text.
+func DefaultOptions() Options {
+	return Options{
+		PageWidth:  320,
+		PageHeight: 480,
+		Margin:     16,
+		FontSize:   12,
+		LineHeight: 1.3,
+	}
+}
+
+// Paragraph is a paragraph of text to reflow.
+type Paragraph struct {
+	// The text of the paragraph.
+	Text string
+
+	// The font size relative to the body text of the document.
+	Scale float64
+
+	// The heading level (1 or 2) for paragraphs with larger text than the body text, or 0.
+	Heading int
+
+	// The style of the text.
+	Bold   bool
+	Italic bool
+
+	// The index of the page the paragraph is on, starting from 0.
+	Page int
+}
+
+// ExtractParagraphs extracts the paragraphs of the pages for reflowing.  The body text size of the document is the
+// font size of most of the text, and the sizes of the paragraphs are given relative to it.
+func ExtractParagraphs(pages []*model.PdfPage) ([]Paragraph, error) {
+	textParagraphs := []extractor.TextParagraph{}
+	pageIndices := []int{}
+	for i, page := range pages {
+		e, err := extractor.New(page)
+		if err != nil {
+			return nil, err
+		}
+		pageParagraphs, err := e.Paragraphs()
+		if err != nil {
+			common.Log.Debug("Unable to extract paragraphs of page %d: %v", i+1, err)
+			return nil, err
+		}
+		for _, p := range pageParagraphs {
+			textParagraphs = append(textParagraphs, p)
+			pageIndices = append(pageIndices, i)
+		}
+	}
+
+	// The body text size, by number of characters.
+	counts := map[float64]int{}
+	bodySize := 0.0
+	for _, p := range textParagraphs {
+		size := math.Floor(p.FontSize*10+0.5) / 10
+		counts[size] += len(p.Text)
+		if counts[size] > counts[bodySize] {
+			bodySize = size
+		}
+	}
+
+	paragraphs := []Paragraph{}
+	for i, p := range textParagraphs {
+		scale := 1.0
+		if bodySize > 0 {
+			scale = math.Max(minScale, math.Min(maxScale, p.FontSize/bodySize))
+		}
+		heading := 0
+		if scale >= heading1Scale {
+			heading = 1
+		} else if scale >= heading2Scale {
+			heading = 2
+		}
+		paragraphs = append(paragraphs, Paragraph{
+			Text:    p.Text,
+			Scale:   scale,
+			Heading: heading,
+			Bold:    p.Bold,
+			Italic:  p.Italic,
+			Page:    pageIndices[i],
+		})
+	}
+
+	return paragraphs, nil
+}
+
+// ToPDF lays out the paragraphs on pages with the size and margins of the options.  The returned creator can be
+// used to write the reflowed document.
+func ToPDF(paragraphs []Paragraph, opts Options) (*creator.Creator, error) {
+	c := creator.New()
+	c.SetPageSize(creator.PageSize{opts.PageWidth, opts.PageHeight})
+	c.SetPageMargins(opts.Margin, opts.Margin, opts.Margin, opts.Margin)
+
+	for i, paragraph := range paragraphs {
+		fontSize := opts.FontSize * paragraph.Scale
+
+		p := creator.NewParagraph(paragraph.Text)
+		p.SetFont(getFont(paragraph.Bold || paragraph.Heading > 0, paragraph.Italic))
+		p.SetFontSize(fontSize)
+		p.SetLineHeight(opts.LineHeight)
+
+		top := 0.0
+		if paragraph.Heading > 0 && i > 0 {
+			top = fontSize
+		}
+		p.SetMargins(0, 0, top, 0.5*opts.FontSize*opts.LineHeight)
+
+		err := c.Draw(p)
+		if err != nil {
+			common.Log.Debug("Unable to draw paragraph %d: %v", i, err)
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// getFont returns the Helvetica font with the style.
+func getFont(bold, italic bool) fonts.Font {
+	switch {
+	case bold && italic:
+		return fonts.NewFontHelveticaBoldOblique()
+	case bold:
+		return fonts.NewFontHelveticaBold()
+	case italic:
+		return fonts.NewFontHelveticaOblique()
+	}
+	return fonts.NewFontHelvetica()
+}
+
+// WriteHTML writes the paragraphs as an HTML document with the width, margins and font size of the options.
+// Headings are written as h1 and h2 elements.
+func WriteHTML(w io.Writer, paragraphs []Paragraph, opts Options) error {
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<style>body { max-width: %.0fpx; margin: 0 auto; padding: %.0fpx; "+
+		"font-family: Helvetica, Arial, sans-serif; font-size: %.1fpx; line-height: %.2f; }</style>\n",
+		opts.PageWidth-2*opts.Margin, opts.Margin, opts.FontSize, opts.LineHeight)
+	b.WriteString("</head>\n<body>\n")
+
+	for _, paragraph := range paragraphs {
+		tag := "p"
+		if paragraph.Heading > 0 {
+			tag = fmt.Sprintf("h%d", paragraph.Heading)
+		}
+
+		text := html.EscapeString(paragraph.Text)
+		if paragraph.Italic {
+			text = "<i>" + text + "</i>"
+		}
+		if paragraph.Bold && paragraph.Heading == 0 {
+			text = "<b>" + text + "</b>"
+		}
+		fmt.Fprintf(&b, "<%s style=\"font-size: %.2fem\">%s</%s>\n", tag, paragraph.Scale, text, tag)
+	}
+
+	b.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
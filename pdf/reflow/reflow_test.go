@@ -0,0 +1,127 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package reflow
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+)
+
+const testContents = `
+BT
+/F2 24 Tf
+72 700 Td
+(Reflow & Test) Tj
+/F1 12 Tf
+0 -40 Td
+(The first paragraph of the page is long enough to be wrapped on a small screen.) Tj
+0 -14 Td
+(It continues on a second line.) Tj
+0 -40 Td
+(Second paragraph.) Tj
+ET
+`
+
+func makeTestPage(t *testing.T) *model.PdfPage {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 612, Ury: 792}
+	page.Resources = model.NewPdfPageResources()
+	err := page.Resources.SetFontByName("F1", fonts.NewFontHelvetica().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = page.Resources.SetFontByName("F2", fonts.NewFontHelveticaBold().ToPdfObject())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page.AddContentStreamByString(testContents)
+	return page
+}
+
+func TestReflow(t *testing.T) {
+	paragraphs, err := ExtractParagraphs([]*model.PdfPage{makeTestPage(t)})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(paragraphs) != 3 {
+		t.Fatalf("Incorrect number of paragraphs: %d", len(paragraphs))
+	}
+	if paragraphs[0].Heading != 1 || paragraphs[0].Scale != maxScale || !paragraphs[0].Bold {
+		t.Errorf("Incorrect heading: %+v", paragraphs[0])
+	}
+	if paragraphs[1].Heading != 0 || paragraphs[1].Scale != 1 ||
+		!strings.HasSuffix(paragraphs[1].Text, "screen. It continues on a second line.") {
+		t.Errorf("Incorrect paragraph: %+v", paragraphs[1])
+	}
+
+	// HTML.
+	var buf bytes.Buffer
+	err = WriteHTML(&buf, paragraphs, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "<h1 style=\"font-size: 2.00em\">Reflow &amp; Test</h1>") ||
+		!strings.Contains(out, "<p style=\"font-size: 1.00em\">Second paragraph.</p>") {
+		t.Errorf("Incorrect HTML output:\n%s", out)
+	}
+
+	// PDF.
+	opts := DefaultOptions()
+	c, err := ToPDF(paragraphs, opts)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	f, err := ioutil.TempFile("", "reflow")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	err = c.Write(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	page, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if box, err := page.GetMediaBox(); err != nil || box.Urx != opts.PageWidth {
+		t.Errorf("Incorrect page size: %+v", box)
+	}
+	e, err := extractor.New(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	lines, err := e.Lines()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(lines) < 5 || lines[0].Text != "Reflow & Test" {
+		t.Fatalf("Incorrect reflowed lines: %d", len(lines))
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line.Text, "Unlicensed") {
+			// License watermark.
+			continue
+		}
+		if line.BBox.Urx > opts.PageWidth-opts.Margin+1 {
+			t.Errorf("Line exceeds the page width: %q %+v", line.Text, line.BBox)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// PageSpace converts coordinates between the coordinate systems of a page:
+//   - user space: the default coordinates of the page content, in which extracted text and images and annotation
+//     rectangles are, with the y axis pointing up,
+//   - display space: the visible region of the page (crop box or media box) rotated as displayed, in points (1/72
+//     inch, accounting for the user unit) from its top left corner, with the y axis pointing down,
+//   - pixel space: the pixels of the page rendered at a resolution, as by RenderPage, i.e. display space scaled by
+//     DPI/72.
+type PageSpace struct {
+	// The transformation from user space to display space, and its inverse.
+	toDisplay   contentstream.Matrix
+	fromDisplay contentstream.Matrix
+
+	// The size of the page in display space.
+	width, height float64
+}
+
+// NewPageSpace returns the coordinate systems of the page.  Returns an error if the page has no valid page box.
+func NewPageSpace(page *model.PdfPage) (*PageSpace, error) {
+	bbox := page.CropBox
+	if bbox == nil {
+		var err error
+		bbox, err = page.GetMediaBox()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Scaled to points, with the y axis pointing down, and rotated clockwise.
+	unit := page.GetUserUnit()
+	w, h := (bbox.Urx-bbox.Llx)*unit, (bbox.Ury-bbox.Lly)*unit
+	if w <= 0 || h <= 0 {
+		common.Log.Debug("ERROR: Invalid page size %fx%f", w, h)
+		return nil, errors.New("Invalid page size")
+	}
+	m := contentstream.TranslationMatrix(-bbox.Llx, -bbox.Lly).
+		Mult(contentstream.ScaleMatrix(unit, -unit)).
+		Mult(contentstream.TranslationMatrix(0, h))
+	rotate := int64(0)
+	if page.Rotate != nil {
+		rotate = (*page.Rotate%360 + 360) % 360
+	}
+	switch rotate {
+	case 90:
+		m = m.Mult(contentstream.NewMatrix(0, 1, -1, 0, h, 0))
+		w, h = h, w
+	case 180:
+		m = m.Mult(contentstream.NewMatrix(-1, 0, 0, -1, w, h))
+	case 270:
+		m = m.Mult(contentstream.NewMatrix(0, -1, 1, 0, 0, w))
+		w, h = h, w
+	}
+
+	inverse, _ := m.Inverse()
+	return &PageSpace{toDisplay: m, fromDisplay: inverse, width: w, height: h}, nil
+}
+
+// DisplaySize returns the width and the height of the page as displayed, in points.
+func (s *PageSpace) DisplaySize() (float64, float64) {
+	return s.width, s.height
+}
+
+// PixelSize returns the width and the height of the image of the page rendered at the resolution.
+func (s *PageSpace) PixelSize(dpi float64) (int, int) {
+	scale := dpi / 72
+	return int(math.Ceil(s.width*scale - 1e-6)), int(math.Ceil(s.height*scale - 1e-6))
+}
+
+// DisplayMatrix returns the transformation from user space to display space.
+func (s *PageSpace) DisplayMatrix() contentstream.Matrix {
+	return s.toDisplay
+}
+
+// PixelMatrix returns the transformation from user space to pixel space at the resolution.
+func (s *PageSpace) PixelMatrix(dpi float64) contentstream.Matrix {
+	return s.toDisplay.Mult(contentstream.ScaleMatrix(dpi/72, dpi/72))
+}
+
+// UserToDisplay converts a point in user space to display space.
+func (s *PageSpace) UserToDisplay(x, y float64) (float64, float64) {
+	return s.toDisplay.Transform(x, y)
+}
+
+// DisplayToUser converts a point in display space to user space.
+func (s *PageSpace) DisplayToUser(x, y float64) (float64, float64) {
+	return s.fromDisplay.Transform(x, y)
+}
+
+// UserToPixels converts a point in user space to pixel space at the resolution.
+func (s *PageSpace) UserToPixels(x, y, dpi float64) (float64, float64) {
+	x, y = s.toDisplay.Transform(x, y)
+	return x * dpi / 72, y * dpi / 72
+}
+
+// PixelsToUser converts a point in pixel space at the resolution to user space.
+func (s *PageSpace) PixelsToUser(x, y, dpi float64) (float64, float64) {
+	return s.fromDisplay.Transform(x*72/dpi, y*72/dpi)
+}
+
+// UserRectToDisplay converts a rectangle in user space, e.g. the bounding box of extracted text, to display space.
+// The result is the bounding box of the transformed rectangle, with Lly the top and Ury the bottom of the region.
+func (s *PageSpace) UserRectToDisplay(r model.PdfRectangle) model.PdfRectangle {
+	return transformRect(r, s.toDisplay)
+}
+
+// DisplayRectToUser converts a rectangle in display space to user space, e.g. for the rectangle of an annotation.
+func (s *PageSpace) DisplayRectToUser(r model.PdfRectangle) model.PdfRectangle {
+	return transformRect(r, s.fromDisplay)
+}
+
+// UserRectToPixels converts a rectangle in user space to the pixels it covers at the resolution, e.g. for cropping
+// the rendered region of extracted text.
+func (s *PageSpace) UserRectToPixels(r model.PdfRectangle, dpi float64) image.Rectangle {
+	d := transformRect(r, s.PixelMatrix(dpi))
+	return image.Rect(int(math.Floor(d.Llx+1e-6)), int(math.Floor(d.Lly+1e-6)),
+		int(math.Ceil(d.Urx-1e-6)), int(math.Ceil(d.Ury-1e-6)))
+}
+
+// PixelRectToUser converts a rectangle of pixels at the resolution to user space, e.g. for a region selected on
+// the rendered page.
+func (s *PageSpace) PixelRectToUser(r image.Rectangle, dpi float64) model.PdfRectangle {
+	scale := 72 / dpi
+	d := model.PdfRectangle{
+		Llx: float64(r.Min.X) * scale, Lly: float64(r.Min.Y) * scale,
+		Urx: float64(r.Max.X) * scale, Ury: float64(r.Max.Y) * scale,
+	}
+	return transformRect(d, s.fromDisplay)
+}
+
+// transformRect returns the bounding box of the rectangle transformed by the matrix.
+func transformRect(r model.PdfRectangle, m contentstream.Matrix) model.PdfRectangle {
+	out := model.PdfRectangle{Llx: math.Inf(1), Lly: math.Inf(1), Urx: math.Inf(-1), Ury: math.Inf(-1)}
+	for _, p := range [][2]float64{{r.Llx, r.Lly}, {r.Urx, r.Lly}, {r.Urx, r.Ury}, {r.Llx, r.Ury}} {
+		x, y := m.Transform(p[0], p[1])
+		out.Llx = math.Min(out.Llx, x)
+		out.Lly = math.Min(out.Lly, y)
+		out.Urx = math.Max(out.Urx, x)
+		out.Ury = math.Max(out.Ury, y)
+	}
+	return out
+}
@@ -0,0 +1,12 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+//
+// Package render rasterizes PDF pages to images, e.g. for generating thumbnails, and encodes them as PNG, JPEG or
+// (multi-page) TIFF.
+// Currently paths (filled, stroked and clipping) and images are rendered, text and shadings are not.
+// PageSpace converts coordinates between user space, the page as displayed and the rendered pixels.
+//
+package render
@@ -0,0 +1,216 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Format is the file format of encoded images.
+type Format int
+
+const (
+	FormatPNG Format = iota
+	FormatJPEG
+	FormatTIFF
+)
+
+// Encode encodes the image in the format.  JPEG does not support transparency: transparent images are composed on
+// the background color of the options, or on white if none.
+func Encode(w io.Writer, img image.Image, format Format, opt Options) error {
+	switch format {
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatJPEG:
+		if !isOpaque(img) {
+			bg := opt.Background
+			if bg == nil {
+				bg = color.White
+			}
+			flat := image.NewRGBA(img.Bounds())
+			draw.Draw(flat, flat.Rect, image.NewUniform(bg), image.ZP, draw.Src)
+			draw.Draw(flat, flat.Rect, img, img.Bounds().Min, draw.Over)
+			img = flat
+		}
+		quality := opt.JPEGQuality
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case FormatTIFF:
+		return EncodeTIFF(w, []image.Image{img})
+	}
+
+	common.Log.Debug("ERROR: Unsupported image format %d", format)
+	return errors.New("Unsupported image format")
+}
+
+// RenderPageTo renders the page and writes the image encoded in the format.
+func RenderPageTo(w io.Writer, page *model.PdfPage, format Format, opt Options) error {
+	img, err := RenderPage(page, opt)
+	if err != nil {
+		return err
+	}
+	return Encode(w, img, format, opt)
+}
+
+// RenderPagesToTIFF renders the pages and writes them as a multi-page TIFF file.
+func RenderPagesToTIFF(w io.Writer, pages []*model.PdfPage, opt Options) error {
+	imgs := []image.Image{}
+	for _, page := range pages {
+		img, err := RenderPage(page, opt)
+		if err != nil {
+			return err
+		}
+		imgs = append(imgs, img)
+	}
+	return EncodeTIFF(w, imgs)
+}
+
+// TIFF field types and tags.
+const (
+	tiffShort = 3
+	tiffLong  = 4
+
+	tiffImageWidth                = 256
+	tiffImageLength               = 257
+	tiffBitsPerSample             = 258
+	tiffCompression               = 259
+	tiffPhotometricInterpretation = 262
+	tiffStripOffsets              = 273
+	tiffSamplesPerPixel           = 277
+	tiffRowsPerStrip              = 278
+	tiffStripByteCounts           = 279
+	tiffPlanarConfiguration       = 284
+	tiffPageNumber                = 297
+	tiffExtraSamples              = 338
+)
+
+// tiffEntry is an entry of a TIFF image file directory, with a value fitting in 4 bytes or the offset of the value.
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value uint32
+}
+
+// EncodeTIFF encodes the images as the pages of a TIFF file, with 8 bit RGB samples (and an alpha channel for
+// images which are not opaque), compressed with Deflate.
+func EncodeTIFF(w io.Writer, imgs []image.Image) error {
+	if len(imgs) == 0 {
+		common.Log.Debug("ERROR: No images to encode")
+		return errors.New("No images")
+	}
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("II")
+	binary.Write(&buf, le, uint16(42))
+	// Position of the offset of the next image file directory, patched when written.
+	nextIFD := buf.Len()
+	binary.Write(&buf, le, uint32(0))
+
+	for i, img := range imgs {
+		b := img.Bounds()
+		width, height := b.Dx(), b.Dy()
+		samples := 3
+		if !isOpaque(img) {
+			samples = 4
+		}
+
+		// Rows of samples, with the alpha premultiplied (associated).
+		rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+		var data bytes.Buffer
+		zw := zlib.NewWriter(&data)
+		for y := 0; y < height; y++ {
+			row := rgba.Pix[y*rgba.Stride : y*rgba.Stride+4*width]
+			if samples == 4 {
+				zw.Write(row)
+				continue
+			}
+			for x := 0; x < width; x++ {
+				zw.Write(row[4*x : 4*x+3])
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		stripOffset := buf.Len()
+		buf.Write(data.Bytes())
+		if buf.Len()%2 != 0 {
+			buf.WriteByte(0)
+		}
+		bitsOffset := buf.Len()
+		for j := 0; j < samples; j++ {
+			binary.Write(&buf, le, uint16(8))
+		}
+
+		entries := []tiffEntry{
+			{tiffImageWidth, tiffLong, 1, uint32(width)},
+			{tiffImageLength, tiffLong, 1, uint32(height)},
+			{tiffBitsPerSample, tiffShort, uint32(samples), uint32(bitsOffset)},
+			{tiffCompression, tiffShort, 1, 8},
+			{tiffPhotometricInterpretation, tiffShort, 1, 2},
+			{tiffStripOffsets, tiffLong, 1, uint32(stripOffset)},
+			{tiffSamplesPerPixel, tiffShort, 1, uint32(samples)},
+			{tiffRowsPerStrip, tiffLong, 1, uint32(height)},
+			{tiffStripByteCounts, tiffLong, 1, uint32(data.Len())},
+			{tiffPlanarConfiguration, tiffShort, 1, 1},
+			// Page number and total number of pages, as two shorts.
+			{tiffPageNumber, tiffShort, 2, uint32(i) | uint32(len(imgs))<<16},
+		}
+		if samples == 4 {
+			// Associated alpha.
+			entries = append(entries, tiffEntry{tiffExtraSamples, tiffShort, 1, 1})
+		}
+
+		// The image file directory, linked from the previous one.
+		ifdOffset := buf.Len()
+		le.PutUint32(buf.Bytes()[nextIFD:], uint32(ifdOffset))
+		binary.Write(&buf, le, uint16(len(entries)))
+		for _, e := range entries {
+			binary.Write(&buf, le, e.tag)
+			binary.Write(&buf, le, e.typ)
+			binary.Write(&buf, le, e.count)
+			if e.typ == tiffShort && e.count == 1 {
+				// Short values are stored in the first bytes of the value field.
+				binary.Write(&buf, le, uint16(e.value))
+				binary.Write(&buf, le, uint16(0))
+			} else {
+				binary.Write(&buf, le, e.value)
+			}
+		}
+		nextIFD = buf.Len()
+		binary.Write(&buf, le, uint32(0))
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// isOpaque returns true if the image has no transparent pixels.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface {
+		Opaque() bool
+	}); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"image"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/extractor"
+)
+
+// polyline is a flattened subpath in device space.
+type polyline struct {
+	points []draw.Point
+	closed bool
+}
+
+// fillMask returns the coverage of the filled path, limited to its bounding box within the image, or nil if it
+// does not cover any pixels.
+func (r *renderer) fillMask(path []extractor.PathSegment) *image.Alpha {
+	rect := r.pathBounds(path, 0)
+	if rect.Empty() {
+		return nil
+	}
+	z := r.rasterizer
+	z.Reset(rect.Dx(), rect.Dy())
+	z.DrawOp = xdraw.Src
+	ox, oy := float64(rect.Min.X), float64(rect.Min.Y)
+	pt := func(p draw.Point) (float32, float32) {
+		return float32(p.X - ox), float32(p.Y - oy)
+	}
+
+	// Filling implicitly closes the open subpaths.
+	open := false
+	for _, seg := range path {
+		switch seg.Type {
+		case extractor.PathMoveTo:
+			if open {
+				z.ClosePath()
+			}
+			z.MoveTo(pt(seg.Points[0]))
+			open = true
+		case extractor.PathLineTo:
+			if open {
+				z.LineTo(pt(seg.Points[0]))
+			}
+		case extractor.PathCurveTo:
+			if open {
+				x1, y1 := pt(seg.Points[0])
+				x2, y2 := pt(seg.Points[1])
+				x3, y3 := pt(seg.Points[2])
+				z.CubeTo(x1, y1, x2, y2, x3, y3)
+			}
+		case extractor.PathClose:
+			if open {
+				z.ClosePath()
+			}
+		}
+	}
+	if open {
+		z.ClosePath()
+	}
+
+	mask := image.NewAlpha(rect)
+	z.Draw(mask, rect, image.Opaque, image.ZP)
+	r.finishMask(mask)
+	return mask
+}
+
+// strokeMask returns the coverage of the stroked path, limited to its bounding box within the image, or nil if it
+// does not cover any pixels.  The line width, cap style and dash pattern are applied, and all joins are round.
+func (r *renderer) strokeMask(path []extractor.PathSegment, gs contentstream.GraphicsState,
+	ctm contentstream.Matrix) *image.Alpha {
+	// The line width and dash lengths scaled by the geometric mean of the scaling of the transformation.  A width
+	// of 0 denotes the thinnest line that can be rendered.
+	scale := math.Sqrt(math.Abs(ctm[0]*ctm[3] - ctm[1]*ctm[2]))
+	width := math.Max(gs.LineWidth*scale, 1)
+	hw := width / 2
+
+	rect := r.pathBounds(path, hw*math.Sqrt2)
+	if rect.Empty() {
+		return nil
+	}
+
+	lines := flattenPath(path)
+	if len(gs.DashArray) > 0 {
+		dashes := make([]float64, len(gs.DashArray))
+		total := float64(0.0)
+		for i, d := range gs.DashArray {
+			dashes[i] = d * scale
+			total += dashes[i]
+		}
+		if total > 0 {
+			lines = dashPolylines(lines, dashes, gs.DashPhase*scale)
+		}
+	}
+
+	z := r.rasterizer
+	z.Reset(rect.Dx(), rect.Dy())
+	z.DrawOp = xdraw.Src
+	ox, oy := float64(rect.Min.X), float64(rect.Min.Y)
+	addPolygon := func(points []draw.Point) {
+		// All polygons are added in the same orientation, so their overlaps do not cancel out.
+		area := float64(0.0)
+		for i, p := range points {
+			q := points[(i+1)%len(points)]
+			area += p.X*q.Y - q.X*p.Y
+		}
+		if area < 0 {
+			reversed := make([]draw.Point, len(points))
+			for i, p := range points {
+				reversed[len(points)-1-i] = p
+			}
+			points = reversed
+		}
+		z.MoveTo(float32(points[0].X-ox), float32(points[0].Y-oy))
+		for _, p := range points[1:] {
+			z.LineTo(float32(p.X-ox), float32(p.Y-oy))
+		}
+		z.ClosePath()
+	}
+	addDisc := func(c draw.Point) {
+		n := int(math.Max(8, math.Min(64, math.Ceil(hw*2))))
+		points := make([]draw.Point, n)
+		for i := range points {
+			sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+			points[i] = draw.NewPoint(c.X+hw*cos, c.Y+hw*sin)
+		}
+		addPolygon(points)
+	}
+
+	for _, line := range lines {
+		points := line.points
+		if len(points) < 2 {
+			continue
+		}
+		last := len(points) - 2
+		for i := 0; i <= last; i++ {
+			p, q := points[i], points[i+1]
+			dx, dy := q.X-p.X, q.Y-p.Y
+			length := math.Hypot(dx, dy)
+			if length == 0 {
+				continue
+			}
+			dx, dy = dx/length*hw, dy/length*hw
+			if gs.LineCap == 2 && !line.closed {
+				// Projecting square caps extend the ends by half the line width.
+				if i == 0 {
+					p = draw.NewPoint(p.X-dx, p.Y-dy)
+				}
+				if i == last {
+					q = draw.NewPoint(q.X+dx, q.Y+dy)
+				}
+			}
+			addPolygon([]draw.Point{
+				draw.NewPoint(p.X-dy, p.Y+dx),
+				draw.NewPoint(q.X-dy, q.Y+dx),
+				draw.NewPoint(q.X+dy, q.Y-dx),
+				draw.NewPoint(p.X+dy, p.Y-dx),
+			})
+		}
+
+		// Joins, and round caps.
+		if width > 2 {
+			for i := 1; i <= last; i++ {
+				addDisc(points[i])
+			}
+		}
+		if line.closed || (gs.LineCap == 1 && width > 1) {
+			addDisc(points[0])
+			addDisc(points[len(points)-1])
+		}
+	}
+
+	mask := image.NewAlpha(rect)
+	z.Draw(mask, rect, image.Opaque, image.ZP)
+	r.finishMask(mask)
+	return mask
+}
+
+// finishMask applies the anti-aliasing option: without anti-aliasing pixels are painted if at least half covered.
+func (r *renderer) finishMask(mask *image.Alpha) {
+	if r.opt.AntiAlias {
+		return
+	}
+	for i, a := range mask.Pix {
+		if a >= 0x80 {
+			mask.Pix[i] = 0xff
+		} else {
+			mask.Pix[i] = 0
+		}
+	}
+}
+
+// pathBounds returns the pixels covered by the bounding box of the path extended by margin, within the image.
+func (r *renderer) pathBounds(path []extractor.PathSegment, margin float64) image.Rectangle {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, seg := range path {
+		for _, p := range seg.Points {
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	if minX > maxX {
+		return image.Rectangle{}
+	}
+	bounds := r.dst.Bounds()
+	clamp := func(v float64, lo, hi int) int {
+		return int(math.Max(float64(lo), math.Min(float64(hi), v)))
+	}
+	rect := image.Rect(
+		clamp(math.Floor(minX-margin)-1, bounds.Min.X, bounds.Max.X),
+		clamp(math.Floor(minY-margin)-1, bounds.Min.Y, bounds.Max.Y),
+		clamp(math.Ceil(maxX+margin)+1, bounds.Min.X, bounds.Max.X),
+		clamp(math.Ceil(maxY+margin)+1, bounds.Min.Y, bounds.Max.Y),
+	)
+	return rect
+}
+
+// applyMask multiplies the coverage of the mask by the clipping mask, which covers the whole image.
+func applyMask(mask *image.Alpha, clip *image.Alpha) {
+	b := mask.Rect.Intersect(clip.Rect)
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+			i := mask.PixOffset(x, y)
+			if !(image.Point{x, y}).In(b) {
+				mask.Pix[i] = 0
+				continue
+			}
+			mask.Pix[i] = uint8(uint32(mask.Pix[i]) * uint32(clip.Pix[clip.PixOffset(x, y)]) / 0xff)
+		}
+	}
+}
+
+// flattenPath converts the path to polylines, approximating curves by line segments.
+func flattenPath(path []extractor.PathSegment) []polyline {
+	lines := []polyline{}
+	cur := -1
+	for _, seg := range path {
+		switch seg.Type {
+		case extractor.PathMoveTo:
+			lines = append(lines, polyline{points: []draw.Point{seg.Points[0]}})
+			cur = len(lines) - 1
+		case extractor.PathLineTo:
+			if cur >= 0 {
+				lines[cur].points = append(lines[cur].points, seg.Points[0])
+			}
+		case extractor.PathCurveTo:
+			if cur < 0 {
+				break
+			}
+			points := lines[cur].points
+			p0 := points[len(points)-1]
+			p1, p2, p3 := seg.Points[0], seg.Points[1], seg.Points[2]
+			// Segments of about 2 pixels, based on the length of the control polygon.
+			length := math.Hypot(p1.X-p0.X, p1.Y-p0.Y) + math.Hypot(p2.X-p1.X, p2.Y-p1.Y) +
+				math.Hypot(p3.X-p2.X, p3.Y-p2.Y)
+			n := int(math.Max(1, math.Min(100, math.Ceil(length/2))))
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+				points = append(points, draw.NewPoint(
+					a*p0.X+b*p1.X+c*p2.X+d*p3.X,
+					a*p0.Y+b*p1.Y+c*p2.Y+d*p3.Y))
+			}
+			lines[cur].points = points
+		case extractor.PathClose:
+			if cur < 0 {
+				break
+			}
+			head := lines[cur].points[0]
+			lines[cur].points = append(lines[cur].points, head)
+			lines[cur].closed = true
+			// Segments following the close start a new subpath at the same point.
+			lines = append(lines, polyline{points: []draw.Point{head}})
+			cur = len(lines) - 1
+		}
+	}
+	return lines
+}
+
+// dashPolylines splits the polylines into the dashes of the dash pattern, starting at the phase.
+func dashPolylines(lines []polyline, dashes []float64, phase float64) []polyline {
+	total := float64(0.0)
+	for _, d := range dashes {
+		total += d
+	}
+
+	dashed := []polyline{}
+	for _, line := range lines {
+		// The dash pattern restarts for each subpath.
+		idx := 0
+		remaining := dashes[0]
+		for offset := math.Mod(phase, total); offset > 0; {
+			if offset < remaining {
+				remaining -= offset
+				break
+			}
+			offset -= remaining
+			idx = (idx + 1) % len(dashes)
+			remaining = dashes[idx]
+		}
+
+		// The index of the dash being drawn, -1 in the gaps.
+		cur := -1
+		if idx%2 == 0 {
+			dashed = append(dashed, polyline{points: []draw.Point{line.points[0]}})
+			cur = len(dashed) - 1
+		}
+		for i := 1; i < len(line.points); i++ {
+			p, q := line.points[i-1], line.points[i]
+			length := math.Hypot(q.X-p.X, q.Y-p.Y)
+			pos := float64(0.0)
+			for length-pos > remaining {
+				pos += remaining
+				t := pos / length
+				m := draw.NewPoint(p.X+(q.X-p.X)*t, p.Y+(q.Y-p.Y)*t)
+				if cur >= 0 {
+					dashed[cur].points = append(dashed[cur].points, m)
+					cur = -1
+				} else {
+					dashed = append(dashed, polyline{points: []draw.Point{m}})
+					cur = len(dashed) - 1
+				}
+				idx = (idx + 1) % len(dashes)
+				remaining = dashes[idx]
+			}
+			remaining -= length - pos
+			if cur >= 0 {
+				dashed[cur].points = append(dashed[cur].points, q)
+			}
+		}
+	}
+	return dashed
+}
@@ -0,0 +1,410 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/vector"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/contentstream/draw"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/extractor"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Options specifies the resolution and appearance of rendered pages, and the encoding of the images.
+type Options struct {
+	// Resolution in dots per inch, 72 for one pixel per point.
+	DPI float64
+
+	// Whether the edges of paths are anti-aliased and images are smoothly interpolated.  Otherwise pixels are either
+	// fully painted or not, and images are resampled with nearest neighbor interpolation.
+	AntiAlias bool
+
+	// Color of the page background, or nil for a transparent background.
+	Background color.Color
+
+	// Quality of JPEG encoding from 1 to 100.
+	JPEGQuality int
+}
+
+// NewOptions returns the default options: 72 DPI, anti-aliased, on a white background.
+func NewOptions() Options {
+	return Options{
+		DPI:         72,
+		AntiAlias:   true,
+		Background:  color.White,
+		JPEGQuality: 90,
+	}
+}
+
+// renderer paints the contents of a page on an image.
+type renderer struct {
+	dst *image.RGBA
+	opt Options
+
+	// The transformation from page space to device space.
+	base contentstream.Matrix
+
+	// The clipping mask in device space and its stack (q/Q), nil if not clipped.
+	clip      *image.Alpha
+	clipStack []*image.Alpha
+
+	// The path under construction in device space, its current point, and the pending clipping operator (W or W*).
+	path        []extractor.PathSegment
+	current     draw.Point
+	subpathHead draw.Point
+	clipping    bool
+
+	// Decoded images by stream, as images are commonly drawn repeatedly.
+	images map[*core.PdfObjectStream]*extractor.ImageMark
+
+	// Rasterizer reused for painting paths.
+	rasterizer *vector.Rasterizer
+}
+
+// RenderPage renders the visible region of the page (crop box or media box), rotated as displayed.  The size of
+// the image is determined by the resolution of the options and the physical size of the page, accounting for the
+// user unit of oversized pages.
+func RenderPage(page *model.PdfPage, opt Options) (*image.RGBA, error) {
+	if opt.DPI <= 0 {
+		common.Log.Debug("ERROR: Invalid resolution %f", opt.DPI)
+		return nil, errors.New("Range check error")
+	}
+
+	space, err := NewPageSpace(page)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+
+	// Page space to device space: the pixel space of the page at the resolution.
+	width, height := space.PixelSize(opt.DPI)
+	r := &renderer{
+		dst:        image.NewRGBA(image.Rect(0, 0, width, height)),
+		opt:        opt,
+		base:       space.PixelMatrix(opt.DPI),
+		images:     map[*core.PdfObjectStream]*extractor.ImageMark{},
+		rasterizer: vector.NewRasterizer(width, height),
+	}
+	if opt.Background != nil {
+		xdraw.Draw(r.dst, r.dst.Bounds(), image.NewUniform(opt.Background), image.ZP, xdraw.Src)
+	}
+
+	operations, err := contentstream.NewContentStreamParser(contents).Parse()
+	if err != nil {
+		return nil, err
+	}
+	processor := contentstream.NewContentStreamProcessor(*operations)
+	err = processor.ProcessWithForms(page.Resources, r.addHandlers)
+	if err != nil {
+		return nil, err
+	}
+	return r.dst, nil
+}
+
+// addHandlers registers the handler painting a content stream.  Forms are clipped to their bounding box, within the
+// clipping region of the Do operation, which is restored once the form is processed.
+func (r *renderer) addHandlers(processor *contentstream.ContentStreamProcessor,
+	form *contentstream.DrawnForm) (func() error, error) {
+	processor.AddHandler(contentstream.HandlerConditionEnumAllOperands, "", r.handleOperation)
+	if form == nil {
+		return nil, nil
+	}
+
+	savedClip, savedStack := r.clip, r.clipStack
+	if bbox, ok := core.TraceToDirectObject(form.XObject.BBox).(*core.PdfObjectArray); ok {
+		rect, err := model.NewPdfRectangle(*bbox)
+		if err == nil {
+			ctm := processor.GetGraphicsState().CTM.Mult(r.base)
+			r.path = nil
+			r.moveTo(transform(ctm, rect.Llx, rect.Lly))
+			r.lineTo(transform(ctm, rect.Urx, rect.Lly))
+			r.lineTo(transform(ctm, rect.Urx, rect.Ury))
+			r.lineTo(transform(ctm, rect.Llx, rect.Ury))
+			r.closePath()
+			r.intersectClip(r.fillMask(r.path))
+			r.path = nil
+		}
+	}
+	r.clipStack = nil
+	return func() error {
+		r.clip, r.clipStack = savedClip, savedStack
+		return nil
+	}, nil
+}
+
+// handleOperation paints or updates the state for a single content stream operation.
+func (r *renderer) handleOperation(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+	resources *model.PdfPageResources) error {
+	ctm := gs.CTM.Mult(r.base)
+
+	switch op.Operand {
+	case "q":
+		r.clipStack = append(r.clipStack, r.clip)
+	case "Q":
+		if len(r.clipStack) > 0 {
+			r.clip = r.clipStack[len(r.clipStack)-1]
+			r.clipStack = r.clipStack[:len(r.clipStack)-1]
+		}
+	case "m", "l":
+		vals, err := getOperands(op, 2)
+		if err != nil {
+			return nil
+		}
+		p := transform(ctm, vals[0], vals[1])
+		if op.Operand == "m" {
+			r.moveTo(p)
+		} else {
+			r.lineTo(p)
+		}
+	case "c", "v", "y":
+		n := 6
+		if op.Operand != "c" {
+			n = 4
+		}
+		vals, err := getOperands(op, n)
+		if err != nil {
+			return nil
+		}
+		points := []draw.Point{}
+		for i := 0; i < n; i += 2 {
+			points = append(points, transform(ctm, vals[i], vals[i+1]))
+		}
+		switch op.Operand {
+		case "v":
+			// The first control point is the current point.
+			points = append([]draw.Point{r.current}, points...)
+		case "y":
+			// The second control point is the end point.
+			points = append(points, points[1])
+		}
+		r.path = append(r.path, extractor.PathSegment{Type: extractor.PathCurveTo, Points: points})
+		r.current = points[2]
+	case "h":
+		r.closePath()
+	case "re":
+		vals, err := getOperands(op, 4)
+		if err != nil {
+			return nil
+		}
+		x, y, w, h := vals[0], vals[1], vals[2], vals[3]
+		r.moveTo(transform(ctm, x, y))
+		r.lineTo(transform(ctm, x+w, y))
+		r.lineTo(transform(ctm, x+w, y+h))
+		r.lineTo(transform(ctm, x, y+h))
+		r.closePath()
+	case "W", "W*":
+		r.clipping = true
+	case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+		r.paintPath(op.Operand, gs, ctm)
+	case "BI":
+		if len(op.Params) != 1 {
+			return nil
+		}
+		iimg, ok := op.Params[0].(*contentstream.ContentStreamInlineImage)
+		if !ok {
+			return nil
+		}
+		mark, err := extractor.NewInlineImageMark(iimg, resources)
+		if err != nil {
+			common.Log.Debug("Skipping inline image: %v", err)
+			return nil
+		}
+		r.drawImage(mark, gs, ctm)
+	case "Do":
+		if len(op.Params) != 1 {
+			return nil
+		}
+		name, ok := op.Params[0].(*core.PdfObjectName)
+		if !ok {
+			return nil
+		}
+		stream, xtype := resources.GetXObjectByName(*name)
+		if xtype != model.XObjectTypeImage {
+			return nil
+		}
+		mark, has := r.images[stream]
+		if !has {
+			var err error
+			mark, err = extractor.NewImageMark(stream)
+			if err != nil {
+				common.Log.Debug("Skipping image %s: %v", *name, err)
+			}
+			r.images[stream] = mark
+		}
+		if mark != nil {
+			r.drawImage(mark, gs, ctm)
+		}
+	}
+
+	return nil
+}
+
+func (r *renderer) moveTo(p draw.Point) {
+	r.path = append(r.path, extractor.PathSegment{Type: extractor.PathMoveTo, Points: []draw.Point{p}})
+	r.current = p
+	r.subpathHead = p
+}
+
+func (r *renderer) lineTo(p draw.Point) {
+	r.path = append(r.path, extractor.PathSegment{Type: extractor.PathLineTo, Points: []draw.Point{p}})
+	r.current = p
+}
+
+func (r *renderer) closePath() {
+	r.path = append(r.path, extractor.PathSegment{Type: extractor.PathClose})
+	r.current = r.subpathHead
+}
+
+// paintPath ends the current path with the painting operator, filling and stroking it and applying a pending
+// clipping operator.  The even-odd rule is not supported and treated as the nonzero winding number rule.
+func (r *renderer) paintPath(operand string, gs contentstream.GraphicsState, ctm contentstream.Matrix) {
+	if operand == "s" || operand == "b" || operand == "b*" {
+		r.closePath()
+	}
+
+	if len(r.path) > 0 {
+		switch operand {
+		case "f", "F", "f*", "B", "B*", "b", "b*":
+			if col, ok := getColor(gs.ColorspaceNonStroking, gs.ColorNonStroking, gs.FillAlpha); ok {
+				r.fill(r.fillMask(r.path), col)
+			}
+		}
+		switch operand {
+		case "S", "s", "B", "B*", "b", "b*":
+			if col, ok := getColor(gs.ColorspaceStroking, gs.ColorStroking, gs.StrokeAlpha); ok {
+				r.fill(r.strokeMask(r.path, gs, ctm), col)
+			}
+		}
+	}
+
+	if r.clipping && len(r.path) > 0 {
+		r.intersectClip(r.fillMask(r.path))
+	}
+
+	r.path = nil
+	r.clipping = false
+}
+
+// fill paints the color through the mask, within the clipping region.
+func (r *renderer) fill(mask *image.Alpha, col color.Color) {
+	if mask == nil {
+		return
+	}
+	if r.clip != nil {
+		applyMask(mask, r.clip)
+	}
+	xdraw.DrawMask(r.dst, mask.Rect, image.NewUniform(col), image.ZP, mask, mask.Rect.Min, xdraw.Over)
+}
+
+// intersectClip intersects the clipping region with the mask.
+func (r *renderer) intersectClip(mask *image.Alpha) {
+	clip := image.NewAlpha(r.dst.Bounds())
+	if mask != nil {
+		xdraw.Draw(clip, mask.Rect, mask, mask.Rect.Min, xdraw.Src)
+		if r.clip != nil {
+			applyMask(clip, r.clip)
+		}
+	}
+	r.clip = clip
+}
+
+// drawImage draws an image placed with the transformation ctm from the unit square to device space.
+func (r *renderer) drawImage(mark *extractor.ImageMark, gs contentstream.GraphicsState, ctm contentstream.Matrix) {
+	m := *mark
+	m.Place(ctm, gs)
+	img, err := m.ToGoImage()
+	if err != nil {
+		common.Log.Debug("Skipping image: %v", err)
+		return
+	}
+	if ctm[0]*ctm[3]-ctm[1]*ctm[2] == 0 {
+		return
+	}
+
+	// Image space to device space: the first row of the image is at the top of the unit square.
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	s2d := f64.Aff3{
+		ctm[0] / w, -ctm[2] / h, ctm[2] + ctm[4],
+		ctm[1] / w, -ctm[3] / h, ctm[3] + ctm[5],
+	}
+
+	opts := &xdraw.Options{}
+	if r.clip != nil {
+		opts.DstMask = r.clip
+	}
+	if gs.FillAlpha < 1 {
+		opts.SrcMask = image.NewUniform(color.Alpha{A: toByte(gs.FillAlpha)})
+	}
+	var interp xdraw.Transformer = xdraw.NearestNeighbor
+	if r.opt.AntiAlias {
+		interp = xdraw.ApproxBiLinear
+	}
+	interp.Transform(r.dst, s2d, img, b, xdraw.Over, opts)
+}
+
+// transform transforms a point in user space to device space.
+func transform(m contentstream.Matrix, x, y float64) draw.Point {
+	px, py := m.Transform(x, y)
+	return draw.NewPoint(px, py)
+}
+
+// getOperands returns the n numeric operands of the operation.
+func getOperands(op *contentstream.ContentStreamOperation, n int) ([]float64, error) {
+	if len(op.Params) != n {
+		common.Log.Debug("%s: invalid number of operands (%d)", op.Operand, len(op.Params))
+		return nil, errors.New("Invalid number of operands")
+	}
+	vals := []float64{}
+	for _, param := range op.Params {
+		switch t := core.TraceToDirectObject(param).(type) {
+		case *core.PdfObjectFloat:
+			vals = append(vals, float64(*t))
+		case *core.PdfObjectInteger:
+			vals = append(vals, float64(*t))
+		default:
+			common.Log.Debug("%s: invalid operand (%T)", op.Operand, param)
+			return nil, errors.New("Type check error")
+		}
+	}
+	return vals, nil
+}
+
+// getColor returns the color in the colorspace converted to RGB with the alpha.  Returns false if the color cannot
+// be converted, e.g. for patterns.
+func getColor(cs model.PdfColorspace, col model.PdfColor, alpha float64) (color.Color, bool) {
+	if cs == nil || col == nil {
+		return nil, false
+	}
+	rgb, err := cs.ColorToRGB(col)
+	if err != nil {
+		common.Log.Debug("Color conversion to RGB failed: %v", err)
+		return nil, false
+	}
+	c, ok := rgb.(*model.PdfColorDeviceRGB)
+	if !ok {
+		return nil, false
+	}
+	return color.NRGBA{R: toByte(c.R()), G: toByte(c.G()), B: toByte(c.B()), A: toByte(alpha)}, true
+}
+
+// toByte converts a value in the range 0-1 to 0-255.
+func toByte(val float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Floor(val*255+0.5))))
+}
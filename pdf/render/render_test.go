@@ -0,0 +1,301 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+
+	"golang.org/x/image/tiff"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// makeTestPage returns a 100x50 page with the content.
+func makeTestPage(t *testing.T, content string) *model.PdfPage {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 100, Ury: 50}
+	page.Resources = model.NewPdfPageResources()
+	err := page.SetContentStreams([]string{content}, core.NewRawEncoder())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return page
+}
+
+// checkColor checks the color of a pixel.
+func checkColor(t *testing.T, img image.Image, x, y int, expected color.NRGBA) {
+	c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	if c != expected {
+		t.Errorf("Pixel %d,%d: %v, expected %v", x, y, c, expected)
+	}
+}
+
+var (
+	white       = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	red         = color.NRGBA{0xff, 0, 0, 0xff}
+	blue        = color.NRGBA{0, 0, 0xff, 0xff}
+	transparent = color.NRGBA{}
+)
+
+func TestRenderPaths(t *testing.T) {
+	// A red rectangle in the lower left quarter, and a blue 4pt line along the top, clipped to the right half.
+	page := makeTestPage(t, "1 0 0 rg 0 0 50 25 re f q 50 0 50 50 re W n 0 0 1 RG 4 w 0 45 m 100 45 l S Q")
+
+	img, err := RenderPage(page, NewOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 100, 50) {
+		t.Fatalf("Wrong image size %v", img.Bounds())
+	}
+	checkColor(t, img, 10, 40, red)
+	checkColor(t, img, 49, 25, red)
+	checkColor(t, img, 10, 10, white)
+	checkColor(t, img, 75, 5, blue)
+	checkColor(t, img, 25, 5, white)
+	checkColor(t, img, 75, 10, white)
+
+	// Double resolution, transparent background.
+	opt := NewOptions()
+	opt.DPI = 144
+	opt.Background = nil
+	img, err = RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 200, 100) {
+		t.Fatalf("Wrong image size %v", img.Bounds())
+	}
+	checkColor(t, img, 20, 80, red)
+	checkColor(t, img, 20, 20, transparent)
+	checkColor(t, img, 150, 10, blue)
+
+	// Page units of 3 points.
+	err = page.SetUserUnit(3)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	img, err = RenderPage(page, NewOptions())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 300, 150) {
+		t.Fatalf("Wrong image size %v", img.Bounds())
+	}
+	checkColor(t, img, 30, 120, red)
+	checkColor(t, img, 225, 15, blue)
+}
+
+func TestRenderAntiAlias(t *testing.T) {
+	// A triangle with edges crossing pixels.
+	page := makeTestPage(t, "0 0 1 rg 10 10 m 90 20 l 10 40 l f")
+
+	count := func(img *image.RGBA) (partial int) {
+		for i := 0; i < len(img.Pix); i += 4 {
+			if b := img.Pix[i+2]; b != 0 && b != 0xff || img.Pix[i] != 0 && img.Pix[i] != 0xff {
+				partial++
+			}
+		}
+		return partial
+	}
+
+	opt := NewOptions()
+	img, err := RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if count(img) == 0 {
+		t.Errorf("Edges not anti-aliased")
+	}
+
+	opt.AntiAlias = false
+	img, err = RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if n := count(img); n != 0 {
+		t.Errorf("Anti-aliased pixels without anti-aliasing (%d)", n)
+	}
+	checkColor(t, img, 20, 25, blue)
+}
+
+func TestRenderImageAndRotation(t *testing.T) {
+	page := makeTestPage(t, "q 100 0 0 50 0 0 cm /Im0 Do Q")
+	// Red on the left, blue on the right.
+	img := &model.Image{Width: 2, Height: 1, BitsPerComponent: 8, ColorComponents: 3, Data: []byte{255, 0, 0, 0, 0, 255}}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	err = page.AddImageResource("Im0", ximg)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	opt := NewOptions()
+	opt.AntiAlias = false
+	out, err := RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkColor(t, out, 10, 25, red)
+	checkColor(t, out, 90, 25, blue)
+
+	// Rotated clockwise by 90 degrees: the left of the page is at the top.
+	rotate := int64(90)
+	page.Rotate = &rotate
+	out, err = RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if out.Bounds() != image.Rect(0, 0, 50, 100) {
+		t.Fatalf("Wrong image size %v", out.Bounds())
+	}
+	checkColor(t, out, 25, 10, red)
+	checkColor(t, out, 25, 90, blue)
+}
+
+func TestRenderEncode(t *testing.T) {
+	page := makeTestPage(t, "1 0 0 rg 0 0 100 25 re f")
+	opt := NewOptions()
+	opt.Background = nil
+
+	var buf bytes.Buffer
+	err := RenderPageTo(&buf, page, FormatPNG, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkColor(t, img, 10, 40, red)
+	checkColor(t, img, 10, 10, transparent)
+
+	// JPEG composes transparent images on white.
+	buf.Reset()
+	err = RenderPageTo(&buf, page, FormatJPEG, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	img, err = jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if r, g, b, _ := img.At(10, 10).RGBA(); r>>8 < 0xf0 || g>>8 < 0xf0 || b>>8 < 0xf0 {
+		t.Errorf("Transparent background not white in JPEG (%d %d %d)", r>>8, g>>8, b>>8)
+	}
+
+	// Multi-page TIFF, with a transparent and an opaque page.
+	buf.Reset()
+	err = RenderPagesToTIFF(&buf, []*model.PdfPage{page, page}, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data := buf.Bytes()
+	img, err = tiff.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkColor(t, img, 10, 40, red)
+	checkColor(t, img, 10, 10, transparent)
+
+	// Count the image file directories.
+	pages := 0
+	for offset := binary.LittleEndian.Uint32(data[4:]); offset != 0; pages++ {
+		n := binary.LittleEndian.Uint16(data[offset:])
+		offset = binary.LittleEndian.Uint32(data[int(offset)+2+12*int(n):])
+	}
+	if pages != 2 {
+		t.Errorf("Wrong number of TIFF pages %d", pages)
+	}
+
+	buf.Reset()
+	opaque := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0xff
+	}
+	err = Encode(&buf, opaque, FormatTIFF, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	img, err = tiff.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	checkColor(t, img, 2, 1, white)
+}
+
+func TestPageSpace(t *testing.T) {
+	page := makeTestPage(t, "")
+	page.CropBox = &model.PdfRectangle{Llx: 10, Lly: 10, Urx: 90, Ury: 50}
+	rotate := int64(90)
+	page.Rotate = &rotate
+	err := page.SetUserUnit(2)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	space, err := NewPageSpace(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if w, h := space.DisplaySize(); w != 80 || h != 160 {
+		t.Errorf("Wrong display size %fx%f", w, h)
+	}
+	if w, h := space.PixelSize(144); w != 160 || h != 320 {
+		t.Errorf("Wrong pixel size %dx%d", w, h)
+	}
+
+	// Rotated clockwise: the top left corner of the crop box is at the top right.
+	if x, y := space.UserToDisplay(10, 50); x != 80 || y != 0 {
+		t.Errorf("Wrong display point %f,%f", x, y)
+	}
+	if x, y := space.UserToPixels(90, 10, 144); x != 0 || y != 320 {
+		t.Errorf("Wrong pixel point %f,%f", x, y)
+	}
+	if x, y := space.PixelsToUser(80, 160, 144); math.Abs(x-50) > 1e-9 || math.Abs(y-30) > 1e-9 {
+		t.Errorf("Wrong user point %f,%f", x, y)
+	}
+
+	r := model.PdfRectangle{Llx: 20, Lly: 30, Urx: 40, Ury: 35}
+	if d := space.UserRectToDisplay(r); d != (model.PdfRectangle{Llx: 40, Lly: 20, Urx: 50, Ury: 60}) {
+		t.Errorf("Wrong display rectangle %+v", d)
+	}
+	px := space.UserRectToPixels(r, 72)
+	if px != image.Rect(40, 20, 50, 60) {
+		t.Errorf("Wrong pixel rectangle %v", px)
+	}
+	if u := space.PixelRectToUser(px, 72); u != r {
+		t.Errorf("Wrong user rectangle %+v", u)
+	}
+
+	// The rendering and the pixel space match.
+	page = makeTestPage(t, "1 0 0 rg 20 30 20 5 re f")
+	page.Rotate = &rotate
+	opt := NewOptions()
+	opt.AntiAlias = false
+	out, err := RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	space, err = NewPageSpace(page)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	px = space.UserRectToPixels(r, opt.DPI)
+	checkColor(t, out, px.Min.X, px.Min.Y, red)
+	checkColor(t, out, px.Max.X-1, px.Max.Y-1, red)
+	checkColor(t, out, px.Max.X, px.Max.Y, white)
+}
@@ -0,0 +1,17 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+//
+// Package svg converts SVG images to PDF content, as Form XObjects which can be drawn on pages, e.g. for stamping
+// vector logos.
+//
+// A useful subset of SVG 1.1 is supported: paths, basic shapes (rect, circle, ellipse, line, polyline, polygon),
+// groups, use references, transforms, fills and strokes (colors, opacity, fill rules, line caps, joins and dashes),
+// and text drawn with the standard fonts.  Gradients are painted with the color of their first stop, and
+// clipping, masks, filters, patterns and images are ignored.
+//
+// SVG user units (px) are converted to points at 96 pixels per inch, as in CSS.
+//
+package svg
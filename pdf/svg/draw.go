@@ -0,0 +1,599 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/model/fonts"
+	"github.com/unidoc/unidoc/pdf/model/textencoding"
+)
+
+// Elements which are not drawn where they are: definitions, referenced elements and metadata.
+var skippedElements = map[string]bool{
+	"defs":           true,
+	"symbol":         true,
+	"clipPath":       true,
+	"mask":           true,
+	"marker":         true,
+	"pattern":        true,
+	"linearGradient": true,
+	"radialGradient": true,
+	"filter":         true,
+	"style":          true,
+	"script":         true,
+	"title":          true,
+	"desc":           true,
+	"metadata":       true,
+	"image":          true,
+	"foreignObject":  true,
+}
+
+// Maximum nesting of use references.
+const maxUseDepth = 16
+
+// drawer draws the elements of an SVG image to a content stream, with the resources they use.
+type drawer struct {
+	svg       *SVG
+	cc        *contentstream.ContentCreator
+	resources *model.PdfPageResources
+
+	// The font and graphics state resources by base font and by opacities.
+	fonts      map[string]core.PdfObjectName
+	extGStates map[[2]float64]core.PdfObjectName
+
+	// The size of the current viewport (user units), the reference of percentages.
+	viewWidth, viewHeight float64
+
+	// Depth of the use references being drawn.
+	useDepth int
+}
+
+// newDrawer creates a drawer of the SVG image.
+func newDrawer(svg *SVG) *drawer {
+	return &drawer{
+		svg:        svg,
+		cc:         contentstream.NewContentCreator(),
+		resources:  model.NewPdfPageResources(),
+		fonts:      map[string]core.PdfObjectName{},
+		extGStates: map[[2]float64]core.PdfObjectName{},
+		viewWidth:  svg.width,
+		viewHeight: svg.height,
+	}
+}
+
+// diagonal returns the normalized diagonal of the viewport, the reference of the percentages of lengths which are
+// neither horizontal nor vertical.
+func (d *drawer) diagonal() float64 {
+	return math.Sqrt((d.viewWidth*d.viewWidth + d.viewHeight*d.viewHeight) / 2)
+}
+
+// addOp adds an operation to the content stream.
+func (d *drawer) addOp(operand string, params ...core.PdfObject) {
+	ops := d.cc.Operations()
+	*ops = append(*ops, &contentstream.ContentStreamOperation{Operand: operand, Params: params})
+}
+
+// drawImage draws the image, in points from the lower left corner of its viewport, clipped to the viewport.
+func (d *drawer) drawImage() error {
+	svg := d.svg
+	d.cc.Add_q()
+	// From user units, with the y axis downwards.
+	d.cc.Add_cm(pointsPerPixel, 0, 0, -pointsPerPixel, 0, svg.Height())
+	d.cc.Add_re(0, 0, svg.width, svg.height).Add_W().Add_n()
+
+	d.applyViewBox(svg.root, svg.width, svg.height)
+	s := d.computeStyle(defaultStyle(), svg.root)
+	err := d.drawChildren(svg.root, s)
+	if err != nil {
+		return err
+	}
+	d.cc.Add_Q()
+	return nil
+}
+
+// applyViewBox transforms the user space to the viewBox of the element, in a viewport of the size, as specified by
+// its preserveAspectRatio attribute.  The viewport size is set to the viewBox size.
+func (d *drawer) applyViewBox(elem *element, width, height float64) {
+	d.viewWidth, d.viewHeight = width, height
+	viewBox := parseNumbers(elem.attrs["viewBox"])
+	if len(viewBox) != 4 || viewBox[2] <= 0 || viewBox[3] <= 0 {
+		return
+	}
+	minX, minY, vw, vh := viewBox[0], viewBox[1], viewBox[2], viewBox[3]
+	d.viewWidth, d.viewHeight = vw, vh
+
+	sx, sy := width/vw, height/vh
+	fields := strings.Fields(elem.attrs["preserveAspectRatio"])
+	align, slice := "xMidYMid", false
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if len(fields) > 1 {
+		slice = fields[1] == "slice"
+	}
+	var tx, ty float64
+	if align != "none" {
+		scale := math.Min(sx, sy)
+		if slice {
+			scale = math.Max(sx, sy)
+		}
+		sx, sy = scale, scale
+		if strings.Contains(align, "xMid") {
+			tx = (width - vw*scale) / 2
+		} else if strings.Contains(align, "xMax") {
+			tx = width - vw*scale
+		}
+		if strings.Contains(align, "YMid") {
+			ty = (height - vh*scale) / 2
+		} else if strings.Contains(align, "YMax") {
+			ty = height - vh*scale
+		}
+	}
+	d.cc.Add_cm(sx, 0, 0, sy, tx-minX*sx, ty-minY*sy)
+}
+
+// drawChildren draws the child elements of the element, with its style.
+func (d *drawer) drawChildren(elem *element, s style) error {
+	for _, child := range elem.children {
+		err := d.drawElement(child, s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawElement draws the element and its descendants, with the style of its parent.
+func (d *drawer) drawElement(elem *element, parent style) error {
+	if elem.name == textNode || skippedElements[elem.name] {
+		return nil
+	}
+	props := elem.properties()
+	if props["display"] == "none" {
+		return nil
+	}
+	s := d.computeStyle(parent, elem)
+
+	d.cc.Add_q()
+	if transform, has := elem.attrs["transform"]; has {
+		m := parseTransform(transform)
+		d.cc.Add_cm(m[0], m[1], m[2], m[3], m[4], m[5])
+	}
+
+	var err error
+	switch elem.name {
+	case "g", "a", "switch":
+		err = d.drawChildren(elem, s)
+	case "svg":
+		err = d.drawNestedSVG(elem, s)
+	case "use":
+		err = d.drawUse(elem, s)
+	case "path":
+		d.paintPath(parsePath(elem.attrs["d"]), s)
+	case "rect":
+		x := d.length(elem, "x", d.viewWidth)
+		y := d.length(elem, "y", d.viewHeight)
+		width := d.length(elem, "width", d.viewWidth)
+		height := d.length(elem, "height", d.viewHeight)
+		if width <= 0 || height <= 0 {
+			break
+		}
+		rx, hasRx := parseLength(elem.attrs["rx"], d.viewWidth, s.fontSize)
+		ry, hasRy := parseLength(elem.attrs["ry"], d.viewHeight, s.fontSize)
+		if !hasRx {
+			rx = ry
+		}
+		if !hasRy {
+			ry = rx
+		}
+		p := &path{}
+		p.roundedRect(x, y, width, height, math.Min(rx, width/2), math.Min(ry, height/2))
+		d.paintPath(p, s)
+	case "circle":
+		r := d.length(elem, "r", d.diagonal())
+		if r <= 0 {
+			break
+		}
+		p := &path{}
+		p.ellipse(d.length(elem, "cx", d.viewWidth), d.length(elem, "cy", d.viewHeight), r, r)
+		d.paintPath(p, s)
+	case "ellipse":
+		rx := d.length(elem, "rx", d.viewWidth)
+		ry := d.length(elem, "ry", d.viewHeight)
+		if rx <= 0 || ry <= 0 {
+			break
+		}
+		p := &path{}
+		p.ellipse(d.length(elem, "cx", d.viewWidth), d.length(elem, "cy", d.viewHeight), rx, ry)
+		d.paintPath(p, s)
+	case "line":
+		p := &path{}
+		p.moveTo(d.length(elem, "x1", d.viewWidth), d.length(elem, "y1", d.viewHeight))
+		p.lineTo(d.length(elem, "x2", d.viewWidth), d.length(elem, "y2", d.viewHeight))
+		s.fill = paint{none: true}
+		d.paintPath(p, s)
+	case "polyline", "polygon":
+		points := parseNumbers(elem.attrs["points"])
+		if len(points) < 4 {
+			break
+		}
+		p := &path{}
+		p.moveTo(points[0], points[1])
+		for i := 2; i+1 < len(points); i += 2 {
+			p.lineTo(points[i], points[i+1])
+		}
+		if elem.name == "polygon" {
+			p.close()
+		}
+		d.paintPath(p, s)
+	case "text":
+		err = d.drawText(elem, s)
+	default:
+		common.Log.Debug("Unsupported SVG element %s", elem.name)
+	}
+	d.cc.Add_Q()
+	return err
+}
+
+// length returns the length of the attribute of the element, with percentages of the reference, or 0 if not
+// specified or invalid.
+func (d *drawer) length(elem *element, name string, ref float64) float64 {
+	value, ok := parseLength(elem.attrs[name], ref, defaultFontSize)
+	if !ok {
+		return 0
+	}
+	return value
+}
+
+// drawNestedSVG draws a nested svg element, in its viewport.
+func (d *drawer) drawNestedSVG(elem *element, s style) error {
+	x := d.length(elem, "x", d.viewWidth)
+	y := d.length(elem, "y", d.viewHeight)
+	width, ok := parseLength(elem.attrs["width"], d.viewWidth, s.fontSize)
+	if !ok {
+		width = d.viewWidth
+	}
+	height, ok := parseLength(elem.attrs["height"], d.viewHeight, s.fontSize)
+	if !ok {
+		height = d.viewHeight
+	}
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	viewWidth, viewHeight := d.viewWidth, d.viewHeight
+	d.cc.Add_re(x, y, width, height).Add_W().Add_n()
+	d.cc.Add_cm(1, 0, 0, 1, x, y)
+	d.applyViewBox(elem, width, height)
+	err := d.drawChildren(elem, s)
+	d.viewWidth, d.viewHeight = viewWidth, viewHeight
+	return err
+}
+
+// drawUse draws the element referenced by a use element, translated to its position.  Referenced symbols are
+// drawn as groups.
+func (d *drawer) drawUse(elem *element, s style) error {
+	id := strings.TrimPrefix(elem.attrs["href"], "#")
+	target, has := d.svg.ids[id]
+	if !has {
+		common.Log.Debug("SVG use reference %q not found", elem.attrs["href"])
+		return nil
+	}
+	if d.useDepth >= maxUseDepth {
+		common.Log.Debug("SVG use references nested too deep (%q)", id)
+		return nil
+	}
+	d.useDepth++
+	defer func() { d.useDepth-- }()
+
+	d.cc.Add_cm(1, 0, 0, 1, d.length(elem, "x", d.viewWidth), d.length(elem, "y", d.viewHeight))
+	if target.name == "symbol" {
+		return d.drawChildren(target, d.computeStyle(s, target))
+	}
+	return d.drawElement(target, s)
+}
+
+// setOpacity sets the fill and stroke opacities, with a graphics state resource.
+func (d *drawer) setOpacity(fill, stroke float64) {
+	if fill >= 1 && stroke >= 1 {
+		return
+	}
+	key := [2]float64{fill, stroke}
+	name, has := d.extGStates[key]
+	if !has {
+		name = core.PdfObjectName(fmt.Sprintf("GS%d", len(d.extGStates)+1))
+		gs := core.MakeDict()
+		gs.Set("Type", core.MakeName("ExtGState"))
+		gs.Set("ca", core.MakeFloat(fill))
+		gs.Set("CA", core.MakeFloat(stroke))
+		d.resources.AddExtGState(name, gs)
+		d.extGStates[key] = name
+	}
+	d.cc.Add_gs(name)
+}
+
+// Values of the line cap and join styles of the J and j operators.
+var (
+	lineCaps  = map[string]int64{"butt": 0, "round": 1, "square": 2}
+	lineJoins = map[string]int64{"miter": 0, "round": 1, "bevel": 2}
+)
+
+// paintPath fills and strokes the path with the style.
+func (d *drawer) paintPath(p *path, s style) {
+	fill := !s.fill.none
+	stroke := !s.stroke.none && s.strokeWidth > 0
+	if len(p.segments) == 0 || s.visibility != "visible" || (!fill && !stroke) {
+		return
+	}
+
+	d.setOpacity(s.fillOpacity*s.opacity, s.strokeOpacity*s.opacity)
+	if fill {
+		d.cc.Add_rg(s.fill.color.r, s.fill.color.g, s.fill.color.b)
+	}
+	if stroke {
+		d.cc.Add_RG(s.stroke.color.r, s.stroke.color.g, s.stroke.color.b)
+		d.cc.Add_w(s.strokeWidth)
+		d.addOp("J", core.MakeInteger(lineCaps[s.lineCap]))
+		d.addOp("j", core.MakeInteger(lineJoins[s.lineJoin]))
+		d.cc.Add_M(s.miterLimit)
+		if len(s.dashArray) > 0 {
+			dashes := s.dashArray
+			if len(dashes)%2 == 1 {
+				// Odd dash arrays are repeated to an even number of values.
+				dashes = append(append([]float64{}, dashes...), dashes...)
+			}
+			d.addOp("d", core.MakeArrayFromFloats(dashes), core.MakeFloat(s.dashOffset))
+		}
+	}
+
+	p.draw(d.cc)
+	evenOdd := s.fillRule == "evenodd"
+	switch {
+	case fill && stroke && evenOdd:
+		d.cc.Add_B_starred()
+	case fill && stroke:
+		d.cc.Add_B()
+	case fill && evenOdd:
+		d.cc.Add_f_starred()
+	case fill:
+		d.cc.Add_f()
+	default:
+		d.cc.Add_S()
+	}
+}
+
+// textRun is a run of text of a text element, with its style and position.
+type textRun struct {
+	text  string
+	style style
+
+	// Absolute position, if specified, and relative shift.
+	x, y     *float64
+	dx, dy   float64
+	font     fonts.Font
+	fontName core.PdfObjectName
+	width    float64
+}
+
+// drawText draws a text element and its tspan children, with the standard fonts.  The runs of text are drawn one
+// after the other, from the positions specified by their x and y attributes.
+func (d *drawer) drawText(elem *element, s style) error {
+	runs := d.textRuns(elem, s, nil)
+
+	// Collapse the white space, at the start and end of the text and between the runs.
+	space := true
+	for _, run := range runs {
+		text := strings.Join(strings.Fields(run.text), " ")
+		if text == "" {
+			if len(run.text) > 0 && !space {
+				run.text = " "
+				space = true
+			} else {
+				run.text = ""
+			}
+			continue
+		}
+		if xmlSpace(run.text[0]) && !space {
+			text = " " + text
+		}
+		if xmlSpace(run.text[len(run.text)-1]) {
+			text += " "
+		}
+		run.text = text
+		space = strings.HasSuffix(text, " ")
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		runs[i].text = strings.TrimRight(runs[i].text, " ")
+		if runs[i].text != "" {
+			break
+		}
+	}
+
+	encoder := textencoding.NewWinAnsiTextEncoder()
+	for _, run := range runs {
+		run.font, run.fontName = d.registerFont(run.style)
+		for _, r := range run.text {
+			glyph, found := encoder.RuneToGlyph(r)
+			if !found {
+				common.Log.Debug("SVG text glyph not found for rune %q", r)
+				continue
+			}
+			metrics, found := run.font.GetGlyphCharMetrics(glyph)
+			if !found {
+				continue
+			}
+			run.width += run.style.fontSize * metrics.Wx / 1000
+		}
+	}
+
+	// The runs are positioned in chunks, starting at absolute x positions, anchored as a whole.
+	x, y := 0.0, 0.0
+	for i := 0; i < len(runs); {
+		j := i + 1
+		for j < len(runs) && runs[j].x == nil {
+			j++
+		}
+		width := 0.0
+		for _, run := range runs[i:j] {
+			width += run.dx + run.width
+		}
+		if runs[i].x != nil {
+			x = *runs[i].x
+		}
+		switch runs[i].style.textAnchor {
+		case "middle":
+			x -= width / 2
+		case "end":
+			x -= width
+		}
+
+		for _, run := range runs[i:j] {
+			if run.y != nil {
+				y = *run.y
+			}
+			x += run.dx
+			y += run.dy
+			if run.text != "" && !run.style.fill.none && run.style.visibility == "visible" {
+				d.cc.Add_q()
+				d.setOpacity(run.style.fillOpacity*run.style.opacity, 1)
+				fill := run.style.fill.color
+				d.cc.Add_rg(fill.r, fill.g, fill.b)
+				d.cc.Add_BT()
+				d.cc.Add_Tf(run.fontName, run.style.fontSize)
+				// The glyphs upright in the user space, with the y axis downwards.
+				d.cc.Add_Tm(1, 0, 0, -1, x, y)
+				d.cc.Add_Tj(core.PdfObjectString(encoder.Encode(run.text)))
+				d.cc.Add_ET()
+				d.cc.Add_Q()
+			}
+			x += run.width
+		}
+		i = j
+	}
+	return nil
+}
+
+// xmlSpace returns whether the byte is XML white space.
+func xmlSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// textRuns returns the runs of text of the element and its tspan descendants, in document order.
+func (d *drawer) textRuns(elem *element, s style, runs []*textRun) []*textRun {
+	first := true
+	for _, child := range elem.children {
+		switch child.name {
+		case textNode:
+			run := &textRun{text: child.text, style: s}
+			if first {
+				d.setTextPosition(run, elem)
+			}
+			runs = append(runs, run)
+			first = false
+		case "tspan", "a":
+			props := child.properties()
+			if props["display"] == "none" {
+				continue
+			}
+			childStyle := d.computeStyle(s, child)
+			n := len(runs)
+			runs = d.textRuns(child, childStyle, runs)
+			if first && len(runs) > n {
+				d.setTextPosition(runs[n], elem)
+			}
+			first = first && len(runs) == n
+		}
+	}
+	return runs
+}
+
+// setTextPosition sets the position of the first run of the text of the element, from its x, y, dx and dy
+// attributes (only the first values of their lists).
+func (d *drawer) setTextPosition(run *textRun, elem *element) {
+	value := func(name string, ref float64) (float64, bool) {
+		fields := splitList(elem.attrs[name])
+		if len(fields) == 0 {
+			return 0, false
+		}
+		return parseLength(fields[0], ref, run.style.fontSize)
+	}
+	if x, ok := value("x", d.viewWidth); ok && run.x == nil {
+		run.x = &x
+	}
+	if y, ok := value("y", d.viewHeight); ok && run.y == nil {
+		run.y = &y
+	}
+	if dx, ok := value("dx", d.viewWidth); ok {
+		run.dx += dx
+	}
+	if dy, ok := value("dy", d.viewHeight); ok {
+		run.dy += dy
+	}
+}
+
+// registerFont returns the standard font matching the font family, weight and style, and its resource name.
+func (d *drawer) registerFont(s style) (fonts.Font, core.PdfObjectName) {
+	family := strings.ToLower(s.fontFamily)
+	bold := s.fontWeight == "bold" || s.fontWeight == "bolder"
+	if weight, err := strconv.Atoi(s.fontWeight); err == nil && weight >= 600 {
+		bold = true
+	}
+	italic := s.fontStyle == "italic" || s.fontStyle == "oblique"
+
+	var font fonts.Font
+	var baseFont string
+	switch {
+	case strings.Contains(family, "courier") || strings.Contains(family, "mono"):
+		switch {
+		case bold && italic:
+			font, baseFont = fonts.NewFontCourierBoldOblique(), "Courier-BoldOblique"
+		case bold:
+			font, baseFont = fonts.NewFontCourierBold(), "Courier-Bold"
+		case italic:
+			font, baseFont = fonts.NewFontCourierOblique(), "Courier-Oblique"
+		default:
+			font, baseFont = fonts.NewFontCourier(), "Courier"
+		}
+	case (strings.Contains(family, "serif") && !strings.Contains(family, "sans")) || strings.Contains(family, "times"):
+		switch {
+		case bold && italic:
+			font, baseFont = fonts.NewFontTimesBoldItalic(), "Times-BoldItalic"
+		case bold:
+			font, baseFont = fonts.NewFontTimesBold(), "Times-Bold"
+		case italic:
+			font, baseFont = fonts.NewFontTimesItalic(), "Times-Italic"
+		default:
+			font, baseFont = fonts.NewFontTimesRoman(), "Times-Roman"
+		}
+	default:
+		switch {
+		case bold && italic:
+			font, baseFont = fonts.NewFontHelveticaBoldOblique(), "Helvetica-BoldOblique"
+		case bold:
+			font, baseFont = fonts.NewFontHelveticaBold(), "Helvetica-Bold"
+		case italic:
+			font, baseFont = fonts.NewFontHelveticaOblique(), "Helvetica-Oblique"
+		default:
+			font, baseFont = fonts.NewFontHelvetica(), "Helvetica"
+		}
+	}
+
+	name, has := d.fonts[baseFont]
+	if !has {
+		name = core.PdfObjectName(fmt.Sprintf("F%d", len(d.fonts)+1))
+		d.resources.SetFontByName(name, font.ToPdfObject())
+		d.fonts[baseFont] = name
+	}
+	return font, name
+}
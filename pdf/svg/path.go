@@ -0,0 +1,416 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package svg
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+)
+
+// Control point distance of cubic Bézier curves approximating quarter ellipses, relative to the radius.
+const kappa = 0.5522847498
+
+// pathSegment is a segment of a path: a move (m), a line (l), a cubic Bézier curve (c) or a close (h), with the
+// points in user units.
+type pathSegment struct {
+	op     byte
+	points []float64
+}
+
+// path is a path in user units, made of lines and cubic Bézier curves.
+type path struct {
+	segments []pathSegment
+
+	// Current and start point of the current subpath.
+	x, y           float64
+	startX, startY float64
+}
+
+func (p *path) moveTo(x, y float64) {
+	p.segments = append(p.segments, pathSegment{op: 'm', points: []float64{x, y}})
+	p.x, p.y = x, y
+	p.startX, p.startY = x, y
+}
+
+func (p *path) lineTo(x, y float64) {
+	p.segments = append(p.segments, pathSegment{op: 'l', points: []float64{x, y}})
+	p.x, p.y = x, y
+}
+
+func (p *path) curveTo(x1, y1, x2, y2, x, y float64) {
+	p.segments = append(p.segments, pathSegment{op: 'c', points: []float64{x1, y1, x2, y2, x, y}})
+	p.x, p.y = x, y
+}
+
+func (p *path) close() {
+	p.segments = append(p.segments, pathSegment{op: 'h'})
+	p.x, p.y = p.startX, p.startY
+}
+
+// quadTo adds a quadratic Bézier curve, as the equivalent cubic curve.
+func (p *path) quadTo(x1, y1, x, y float64) {
+	p.curveTo(p.x+2.0/3*(x1-p.x), p.y+2.0/3*(y1-p.y), x+2.0/3*(x1-x), y+2.0/3*(y1-y), x, y)
+}
+
+// arcTo adds an elliptical arc to (x, y), with the radii, the rotation of the x axis of the ellipse (degrees), and
+// the large arc and sweep flags of the SVG arc command (F.6 Elliptical arc implementation notes).  The arc is
+// approximated by cubic Bézier curves of up to 90 degrees each.
+func (p *path) arcTo(rx, ry, rotation float64, largeArc, sweep bool, x, y float64) {
+	x1, y1 := p.x, p.y
+	if x1 == x && y1 == y {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.lineTo(x, y)
+		return
+	}
+
+	// Conversion from the endpoint to the center parameterization.
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (x1-x)/2, (y1-y)/2
+	x1p := cos*dx + sin*dy
+	y1p := -sin*dx + cos*dy
+
+	// Radii too small to reach the endpoint are scaled up.
+	lambda := x1p*x1p/(rx*rx) + y1p*y1p/(ry*ry)
+	if lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	coef := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * y1p / ry
+	cyp := -coef * ry * x1p / rx
+	cx := cos*cxp - sin*cyp + (x1+x)/2
+	cy := sin*cxp + cos*cyp + (y1+y)/2
+
+	theta1 := math.Atan2((y1p-cyp)/ry, (x1p-cxp)/rx)
+	theta2 := math.Atan2((-y1p-cyp)/ry, (-x1p-cxp)/rx)
+	delta := theta2 - theta1
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// The point and the derivative of the ellipse at the angle.
+	point := func(t float64) (float64, float64) {
+		return cx + rx*math.Cos(t)*cos - ry*math.Sin(t)*sin, cy + rx*math.Cos(t)*sin + ry*math.Sin(t)*cos
+	}
+	derivative := func(t float64) (float64, float64) {
+		return -rx*math.Sin(t)*cos - ry*math.Cos(t)*sin, -rx*math.Sin(t)*sin + ry*math.Cos(t)*cos
+	}
+
+	n := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	if n < 1 {
+		n = 1
+	}
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	for i := 0; i < n; i++ {
+		t1 := theta1 + float64(i)*step
+		t2 := t1 + step
+		px1, py1 := point(t1)
+		dx1, dy1 := derivative(t1)
+		px2, py2 := point(t2)
+		dx2, dy2 := derivative(t2)
+		if i == n-1 {
+			px2, py2 = x, y
+		}
+		p.curveTo(px1+k*dx1, py1+k*dy1, px2-k*dx2, py2-k*dy2, px2, py2)
+	}
+}
+
+// ellipse adds a closed ellipse centered at (cx, cy) with the radii.
+func (p *path) ellipse(cx, cy, rx, ry float64) {
+	kx, ky := kappa*rx, kappa*ry
+	p.moveTo(cx+rx, cy)
+	p.curveTo(cx+rx, cy+ky, cx+kx, cy+ry, cx, cy+ry)
+	p.curveTo(cx-kx, cy+ry, cx-rx, cy+ky, cx-rx, cy)
+	p.curveTo(cx-rx, cy-ky, cx-kx, cy-ry, cx, cy-ry)
+	p.curveTo(cx+kx, cy-ry, cx+rx, cy-ky, cx+rx, cy)
+	p.close()
+}
+
+// roundedRect adds a closed rectangle with corners rounded with the radii.
+func (p *path) roundedRect(x, y, width, height, rx, ry float64) {
+	if rx <= 0 || ry <= 0 {
+		p.moveTo(x, y)
+		p.lineTo(x+width, y)
+		p.lineTo(x+width, y+height)
+		p.lineTo(x, y+height)
+		p.close()
+		return
+	}
+	p.moveTo(x+rx, y)
+	p.lineTo(x+width-rx, y)
+	p.arcTo(rx, ry, 0, false, true, x+width, y+ry)
+	p.lineTo(x+width, y+height-ry)
+	p.arcTo(rx, ry, 0, false, true, x+width-rx, y+height)
+	p.lineTo(x+rx, y+height)
+	p.arcTo(rx, ry, 0, false, true, x, y+height-ry)
+	p.lineTo(x, y+ry)
+	p.arcTo(rx, ry, 0, false, true, x+rx, y)
+	p.close()
+}
+
+// draw adds the path construction operators of the path to the content stream.
+func (p *path) draw(cc *contentstream.ContentCreator) {
+	for _, seg := range p.segments {
+		pts := seg.points
+		switch seg.op {
+		case 'm':
+			cc.Add_m(pts[0], pts[1])
+		case 'l':
+			cc.Add_l(pts[0], pts[1])
+		case 'c':
+			cc.Add_c(pts[0], pts[1], pts[2], pts[3], pts[4], pts[5])
+		case 'h':
+			cc.Add_h()
+		}
+	}
+}
+
+// scanner scans the numbers, flags and commands of path data and number lists.
+type scanner struct {
+	s   string
+	pos int
+}
+
+// skipSpace skips the white space and at most one comma.
+func (sc *scanner) skipSpace() {
+	comma := false
+	for sc.pos < len(sc.s) {
+		switch c := sc.s[sc.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == ',' && !comma:
+			comma = true
+		default:
+			return
+		}
+		sc.pos++
+	}
+}
+
+// done returns whether the end of the data is reached, after the white space.
+func (sc *scanner) done() bool {
+	sc.skipSpace()
+	return sc.pos >= len(sc.s)
+}
+
+// number scans a number, e.g. "-1.5e3".  A number ends at the second decimal point, so that "1.5.5" is two numbers.
+func (sc *scanner) number() (float64, bool) {
+	sc.skipSpace()
+	start := sc.pos
+	i := sc.pos
+	if i < len(sc.s) && (sc.s[i] == '+' || sc.s[i] == '-') {
+		i++
+	}
+	digits := false
+	for i < len(sc.s) && sc.s[i] >= '0' && sc.s[i] <= '9' {
+		i++
+		digits = true
+	}
+	if i < len(sc.s) && sc.s[i] == '.' {
+		i++
+		for i < len(sc.s) && sc.s[i] >= '0' && sc.s[i] <= '9' {
+			i++
+			digits = true
+		}
+	}
+	if !digits {
+		return 0, false
+	}
+	if i < len(sc.s) && (sc.s[i] == 'e' || sc.s[i] == 'E') {
+		j := i + 1
+		if j < len(sc.s) && (sc.s[j] == '+' || sc.s[j] == '-') {
+			j++
+		}
+		if j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+			for j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	value, err := strconv.ParseFloat(sc.s[start:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	sc.pos = i
+	return value, true
+}
+
+// numbers scans n numbers.
+func (sc *scanner) numbers(n int) ([]float64, bool) {
+	values := make([]float64, n)
+	for i := range values {
+		var ok bool
+		values[i], ok = sc.number()
+		if !ok {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// flag scans an arc flag, a single 0 or 1 which can be followed by a number without a separator.
+func (sc *scanner) flag() (bool, bool) {
+	sc.skipSpace()
+	if sc.pos >= len(sc.s) || (sc.s[sc.pos] != '0' && sc.s[sc.pos] != '1') {
+		return false, false
+	}
+	sc.pos++
+	return sc.s[sc.pos-1] == '1', true
+}
+
+// Number of the parameters of the path commands.
+var pathCommandParams = map[byte]int{
+	'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0,
+}
+
+// parsePath parses path data (8.3 Path data).  As specified for errors in path data, the path is parsed up to the
+// first error.
+func parsePath(data string) *path {
+	p := &path{}
+	sc := &scanner{s: data}
+
+	var cmd byte
+	// The second control point of the previous cubic or quadratic curve, for the smooth curve commands.
+	var ctrlX, ctrlY float64
+	var prev byte
+	for !sc.done() {
+		c := sc.s[sc.pos]
+		if _, isCmd := pathCommandParams[upper(c)]; isCmd {
+			cmd = c
+			sc.pos++
+		} else if cmd == 0 || upper(cmd) == 'Z' {
+			common.Log.Debug("Invalid SVG path data at %d: %q", sc.pos, data)
+			break
+		} else if cmd == 'M' {
+			// Implicit repetitions of moves are lines.
+			cmd = 'L'
+		} else if cmd == 'm' {
+			cmd = 'l'
+		}
+		if len(p.segments) == 0 && upper(cmd) != 'M' {
+			common.Log.Debug("SVG path data not starting with a move: %q", data)
+			break
+		}
+
+		var args []float64
+		var large, sweep, ok bool
+		if upper(cmd) == 'A' {
+			// The flags of arcs are scanned separately, as they need no separators (e.g. "a1 1 0 014 4").
+			var radii, end []float64
+			radii, ok = sc.numbers(3)
+			if ok {
+				large, ok = sc.flag()
+			}
+			if ok {
+				sweep, ok = sc.flag()
+			}
+			if ok {
+				end, ok = sc.numbers(2)
+			}
+			args = append(append(radii, 0, 0), end...)
+		} else {
+			args, ok = sc.numbers(pathCommandParams[upper(cmd)])
+		}
+		if !ok {
+			common.Log.Debug("Invalid SVG path data at %d: %q", sc.pos, data)
+			break
+		}
+
+		// Relative coordinates are relative to the current point.
+		x0, y0 := p.x, p.y
+		rel := cmd >= 'a'
+		abs := func(i int) (float64, float64) {
+			if rel {
+				return x0 + args[i], y0 + args[i+1]
+			}
+			return args[i], args[i+1]
+		}
+
+		// The reflection of the previous control point, if the previous command is a curve of the same kind.
+		reflect := func(kinds string) (float64, float64) {
+			for i := 0; i < len(kinds); i++ {
+				if upper(prev) == kinds[i] {
+					return 2*x0 - ctrlX, 2*y0 - ctrlY
+				}
+			}
+			return x0, y0
+		}
+
+		switch upper(cmd) {
+		case 'M':
+			x, y := abs(0)
+			p.moveTo(x, y)
+		case 'L':
+			x, y := abs(0)
+			p.lineTo(x, y)
+		case 'H':
+			x := args[0]
+			if rel {
+				x += x0
+			}
+			p.lineTo(x, y0)
+		case 'V':
+			y := args[0]
+			if rel {
+				y += y0
+			}
+			p.lineTo(x0, y)
+		case 'C':
+			x1, y1 := abs(0)
+			x2, y2 := abs(2)
+			x, y := abs(4)
+			p.curveTo(x1, y1, x2, y2, x, y)
+			ctrlX, ctrlY = x2, y2
+		case 'S':
+			x1, y1 := reflect("CS")
+			x2, y2 := abs(0)
+			x, y := abs(2)
+			p.curveTo(x1, y1, x2, y2, x, y)
+			ctrlX, ctrlY = x2, y2
+		case 'Q':
+			x1, y1 := abs(0)
+			x, y := abs(2)
+			p.quadTo(x1, y1, x, y)
+			ctrlX, ctrlY = x1, y1
+		case 'T':
+			x1, y1 := reflect("QT")
+			x, y := abs(0)
+			p.quadTo(x1, y1, x, y)
+			ctrlX, ctrlY = x1, y1
+		case 'A':
+			x, y := abs(5)
+			p.arcTo(args[0], args[1], args[2], large, sweep, x, y)
+		case 'Z':
+			p.close()
+		}
+		prev = cmd
+	}
+	return p
+}
+
+// upper returns the upper case of the ASCII letter.
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,492 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/contentstream"
+)
+
+// Default font size (px), as in CSS.
+const defaultFontSize = 16
+
+// rgbColor is a color with components from 0 to 1.
+type rgbColor struct {
+	r, g, b float64
+}
+
+// paint is the paint of fills or strokes: a color or none.
+type paint struct {
+	none  bool
+	color rgbColor
+}
+
+// style is the computed style of an element, with the properties inherited from its ancestors.
+type style struct {
+	fill, stroke               paint
+	fillOpacity, strokeOpacity float64
+	fillRule                   string
+	strokeWidth                float64
+	lineCap, lineJoin          string
+	miterLimit                 float64
+	dashArray                  []float64
+	dashOffset                 float64
+	color                      rgbColor
+	fontSize                   float64
+	fontFamily                 string
+	fontWeight, fontStyle      string
+	textAnchor                 string
+	visibility                 string
+
+	// Group opacity, which is not inherited but applied to the descendants, approximated by multiplying it into
+	// the opacity of the descendants.
+	opacity float64
+}
+
+// defaultStyle returns the initial values of the properties.
+func defaultStyle() style {
+	return style{
+		fill:          paint{},
+		stroke:        paint{none: true},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		fillRule:      "nonzero",
+		strokeWidth:   1,
+		lineCap:       "butt",
+		lineJoin:      "miter",
+		miterLimit:    4,
+		fontSize:      defaultFontSize,
+		fontFamily:    "serif",
+		fontWeight:    "normal",
+		fontStyle:     "normal",
+		textAnchor:    "start",
+		visibility:    "visible",
+		opacity:       1,
+	}
+}
+
+// properties returns the style properties of the element: its presentation attributes, overridden by the
+// declarations of its style attribute.
+func (elem *element) properties() map[string]string {
+	props := map[string]string{}
+	for name, value := range elem.attrs {
+		props[name] = strings.TrimSpace(value)
+	}
+	for _, decl := range strings.Split(elem.attrs["style"], ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		value = strings.TrimSpace(strings.TrimSuffix(value, "!important"))
+		props[strings.TrimSpace(parts[0])] = value
+	}
+	return props
+}
+
+// computeStyle returns the style of the element from the style of its parent.
+func (d *drawer) computeStyle(parent style, elem *element) style {
+	s := parent
+	props := elem.properties()
+	get := func(name string) (string, bool) {
+		value, has := props[name]
+		if !has || value == "" || value == "inherit" {
+			return "", false
+		}
+		return value, true
+	}
+
+	// The font size and color first, as other properties are relative to them.
+	if v, ok := get("font-size"); ok {
+		if size, ok := parseLength(v, parent.fontSize, parent.fontSize); ok && size >= 0 {
+			s.fontSize = size
+		}
+	}
+	if v, ok := get("color"); ok {
+		if c, ok := parseColor(v, parent.color); ok {
+			s.color = c
+		}
+	}
+
+	if v, ok := get("fill"); ok {
+		if p, ok := d.parsePaint(v, s.color); ok {
+			s.fill = p
+		}
+	}
+	if v, ok := get("stroke"); ok {
+		if p, ok := d.parsePaint(v, s.color); ok {
+			s.stroke = p
+		}
+	}
+	if v, ok := get("fill-opacity"); ok {
+		s.fillOpacity = parseOpacity(v, s.fillOpacity)
+	}
+	if v, ok := get("stroke-opacity"); ok {
+		s.strokeOpacity = parseOpacity(v, s.strokeOpacity)
+	}
+	if v, ok := get("opacity"); ok {
+		s.opacity *= parseOpacity(v, 1)
+	}
+	if v, ok := get("fill-rule"); ok && (v == "nonzero" || v == "evenodd") {
+		s.fillRule = v
+	}
+	if v, ok := get("stroke-width"); ok {
+		if w, ok := parseLength(v, d.diagonal(), s.fontSize); ok && w >= 0 {
+			s.strokeWidth = w
+		}
+	}
+	if v, ok := get("stroke-linecap"); ok && (v == "butt" || v == "round" || v == "square") {
+		s.lineCap = v
+	}
+	if v, ok := get("stroke-linejoin"); ok && (v == "miter" || v == "round" || v == "bevel") {
+		s.lineJoin = v
+	}
+	if v, ok := get("stroke-miterlimit"); ok {
+		if limit, err := strconv.ParseFloat(v, 64); err == nil && limit >= 1 {
+			s.miterLimit = limit
+		}
+	}
+	if v, ok := get("stroke-dasharray"); ok {
+		s.dashArray = nil
+		if v != "none" {
+			dashes := []float64{}
+			valid := true
+			for _, field := range splitList(v) {
+				dash, ok := parseLength(field, d.diagonal(), s.fontSize)
+				if !ok || dash < 0 {
+					valid = false
+					break
+				}
+				dashes = append(dashes, dash)
+			}
+			sum := 0.0
+			for _, dash := range dashes {
+				sum += dash
+			}
+			if valid && sum > 0 {
+				s.dashArray = dashes
+			}
+		}
+	}
+	if v, ok := get("stroke-dashoffset"); ok {
+		if offset, ok := parseLength(v, d.diagonal(), s.fontSize); ok {
+			s.dashOffset = offset
+		}
+	}
+	if v, ok := get("font-family"); ok {
+		s.fontFamily = v
+	}
+	if v, ok := get("font-weight"); ok {
+		s.fontWeight = v
+	}
+	if v, ok := get("font-style"); ok {
+		s.fontStyle = v
+	}
+	if v, ok := get("text-anchor"); ok && (v == "start" || v == "middle" || v == "end") {
+		s.textAnchor = v
+	}
+	if v, ok := get("visibility"); ok {
+		s.visibility = v
+	}
+	return s
+}
+
+// parsePaint parses a paint specification: none, a color, currentColor or a gradient reference (url(#id)), painted
+// with the color of its first stop, or with the fallback following the reference if not found.
+func (d *drawer) parsePaint(s string, current rgbColor) (paint, bool) {
+	if s == "none" {
+		return paint{none: true}, true
+	}
+	if strings.HasPrefix(s, "url(") {
+		end := strings.Index(s, ")")
+		if end < 0 {
+			return paint{}, false
+		}
+		id := strings.TrimPrefix(strings.Trim(strings.TrimSpace(s[4:end]), `"'`), "#")
+		if c, ok := d.gradientColor(id); ok {
+			return paint{color: c}, true
+		}
+		fallback := strings.TrimSpace(s[end+1:])
+		if fallback == "" {
+			return paint{none: true}, true
+		}
+		return d.parsePaint(fallback, current)
+	}
+	c, ok := parseColor(s, current)
+	if !ok {
+		return paint{}, false
+	}
+	return paint{color: c}, true
+}
+
+// gradientColor returns the color of the first stop of the gradient with the id, following the references to the
+// gradients it inherits its stops from.
+func (d *drawer) gradientColor(id string) (rgbColor, bool) {
+	for i := 0; i < 8; i++ {
+		gradient, has := d.svg.ids[id]
+		if !has {
+			return rgbColor{}, false
+		}
+		for _, child := range gradient.children {
+			if child.name != "stop" {
+				continue
+			}
+			props := child.properties()
+			c, ok := parseColor(props["stop-color"], rgbColor{})
+			if !ok {
+				c = rgbColor{}
+			}
+			return c, true
+		}
+		id = strings.TrimPrefix(gradient.attrs["href"], "#")
+	}
+	return rgbColor{}, false
+}
+
+// parseColor parses a color: #rgb, #rrggbb, rgb(r, g, b) with numbers or percentages, a color keyword or
+// currentColor.
+func parseColor(s string, current rgbColor) (rgbColor, bool) {
+	s = strings.TrimSpace(s)
+	if s == "currentColor" {
+		return current, true
+	}
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return rgbColor{}, false
+		}
+		value, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return rgbColor{}, false
+		}
+		return rgbColor{
+			r: float64(value>>16&0xff) / 255,
+			g: float64(value>>8&0xff) / 255,
+			b: float64(value&0xff) / 255,
+		}, true
+	}
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "rgb(") && strings.HasSuffix(lower, ")") {
+		fields := splitList(s[4 : len(s)-1])
+		if len(fields) != 3 {
+			return rgbColor{}, false
+		}
+		components := [3]float64{}
+		for i, field := range fields {
+			var value float64
+			var err error
+			if strings.HasSuffix(field, "%") {
+				value, err = strconv.ParseFloat(strings.TrimSuffix(field, "%"), 64)
+				value = value / 100
+			} else {
+				value, err = strconv.ParseFloat(field, 64)
+				value = value / 255
+			}
+			if err != nil {
+				return rgbColor{}, false
+			}
+			components[i] = math.Max(0, math.Min(1, value))
+		}
+		return rgbColor{components[0], components[1], components[2]}, true
+	}
+	if value, has := colorKeywords[lower]; has {
+		return rgbColor{
+			r: float64(value>>16&0xff) / 255,
+			g: float64(value>>8&0xff) / 255,
+			b: float64(value&0xff) / 255,
+		}, true
+	}
+	common.Log.Debug("Unsupported SVG color %q", s)
+	return rgbColor{}, false
+}
+
+// Color keywords (common subset of the SVG color keywords).
+var colorKeywords = map[string]uint32{
+	"black":     0x000000,
+	"silver":    0xc0c0c0,
+	"gray":      0x808080,
+	"grey":      0x808080,
+	"white":     0xffffff,
+	"maroon":    0x800000,
+	"red":       0xff0000,
+	"purple":    0x800080,
+	"fuchsia":   0xff00ff,
+	"magenta":   0xff00ff,
+	"green":     0x008000,
+	"lime":      0x00ff00,
+	"olive":     0x808000,
+	"yellow":    0xffff00,
+	"navy":      0x000080,
+	"blue":      0x0000ff,
+	"teal":      0x008080,
+	"aqua":      0x00ffff,
+	"cyan":      0x00ffff,
+	"orange":    0xffa500,
+	"brown":     0xa52a2a,
+	"pink":      0xffc0cb,
+	"gold":      0xffd700,
+	"indigo":    0x4b0082,
+	"violet":    0xee82ee,
+	"darkgray":  0xa9a9a9,
+	"darkgrey":  0xa9a9a9,
+	"lightgray": 0xd3d3d3,
+	"lightgrey": 0xd3d3d3,
+	"darkred":   0x8b0000,
+	"darkgreen": 0x006400,
+	"darkblue":  0x00008b,
+	"lightblue": 0xadd8e6,
+	"skyblue":   0x87ceeb,
+	"steelblue": 0x4682b4,
+	"crimson":   0xdc143c,
+	"tomato":    0xff6347,
+	"coral":     0xff7f50,
+	"salmon":    0xfa8072,
+	"khaki":     0xf0e68c,
+	"beige":     0xf5f5dc,
+	"ivory":     0xfffff0,
+	"tan":       0xd2b48c,
+	"chocolate": 0xd2691e,
+	"sienna":    0xa0522d,
+	"turquoise": 0x40e0d0,
+	"orchid":    0xda70d6,
+	"plum":      0xdda0dd,
+}
+
+// parseOpacity parses an opacity, a number or a percentage clamped to [0, 1], or returns the default if invalid.
+func parseOpacity(s string, def float64) float64 {
+	var value float64
+	var err error
+	if strings.HasSuffix(s, "%") {
+		value, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		value = value / 100
+	} else {
+		value, err = strconv.ParseFloat(s, 64)
+	}
+	if err != nil {
+		return def
+	}
+	return math.Max(0, math.Min(1, value))
+}
+
+// Size of the length units in user units (px).
+var lengthUnits = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 96.0 / 72,
+	"pc": 16,
+	"mm": 96 / 25.4,
+	"cm": 96 / 2.54,
+	"in": 96,
+}
+
+// parseLength parses a length in user units, with percentages of the reference length and em units of the font
+// size.
+func parseLength(s string, ref, fontSize float64) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	unit := strings.TrimLeft(s, "+-.0123456789eE")
+	// The em and ex units start with the letter of exponents (e.g. "2em" vs "1e2").
+	if unit != "" && len(unit) < len(s) && s[len(s)-len(unit)-1] == 'e' {
+		unit = s[len(s)-len(unit)-1:]
+	}
+	value, err := strconv.ParseFloat(s[:len(s)-len(unit)], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch unit {
+	case "%":
+		return value * ref / 100, true
+	case "em":
+		return value * fontSize, true
+	case "ex":
+		return value * fontSize / 2, true
+	}
+	scale, known := lengthUnits[unit]
+	if !known {
+		return 0, false
+	}
+	return value * scale, true
+}
+
+// splitList splits a list of values separated by commas and/or white space.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// parseNumbers parses a list of numbers separated by commas and/or white space, as in the viewBox and points
+// attributes.  Parsing stops at the first invalid number.
+func parseNumbers(s string) []float64 {
+	sc := &scanner{s: s}
+	numbers := []float64{}
+	for {
+		value, ok := sc.number()
+		if !ok {
+			break
+		}
+		numbers = append(numbers, value)
+	}
+	return numbers
+}
+
+// parseTransform parses a transform list: matrix, translate, scale, rotate, skewX and skewY transforms, applied
+// from the last to the first.  Returns the identity if invalid.
+func parseTransform(s string) contentstream.Matrix {
+	m := contentstream.IdentityMatrix()
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		open := strings.Index(rest, "(")
+		end := strings.Index(rest, ")")
+		if open < 0 || end < open {
+			common.Log.Debug("Invalid SVG transform %q", s)
+			return contentstream.IdentityMatrix()
+		}
+		name := strings.TrimSpace(rest[:open])
+		args := parseNumbers(rest[open+1 : end])
+		rest = strings.TrimLeft(rest[end+1:], " \t\r\n,")
+
+		var t contentstream.Matrix
+		switch {
+		case name == "matrix" && len(args) == 6:
+			t = contentstream.NewMatrix(args[0], args[1], args[2], args[3], args[4], args[5])
+		case name == "translate" && len(args) == 1:
+			t = contentstream.TranslationMatrix(args[0], 0)
+		case name == "translate" && len(args) == 2:
+			t = contentstream.TranslationMatrix(args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			t = contentstream.ScaleMatrix(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			t = contentstream.ScaleMatrix(args[0], args[1])
+		case name == "rotate" && len(args) == 1:
+			t = contentstream.RotationMatrix(args[0])
+		case name == "rotate" && len(args) == 3:
+			// Rotation about the point (cx, cy).
+			t = contentstream.TranslationMatrix(-args[1], -args[2]).
+				Mult(contentstream.RotationMatrix(args[0])).
+				Mult(contentstream.TranslationMatrix(args[1], args[2]))
+		case name == "skewX" && len(args) == 1:
+			t = contentstream.NewMatrix(1, 0, math.Tan(args[0]*math.Pi/180), 1, 0, 0)
+		case name == "skewY" && len(args) == 1:
+			t = contentstream.NewMatrix(1, math.Tan(args[0]*math.Pi/180), 0, 1, 0, 0)
+		default:
+			common.Log.Debug("Invalid SVG transform %q", s)
+			return contentstream.IdentityMatrix()
+		}
+		// The transforms of the list are nested: the last one is applied first.
+		m = t.Mult(m)
+	}
+	return m
+}
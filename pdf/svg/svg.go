@@ -0,0 +1,170 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/unidoc/unidoc/common"
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+)
+
+// Points per SVG user unit (px): 72 points and 96 pixels per inch.
+const pointsPerPixel = 0.75
+
+// Default size of the viewport when not specified.
+const (
+	defaultWidth  = 300
+	defaultHeight = 150
+)
+
+// element is an element of the SVG document, with its attributes and children.
+type element struct {
+	name     string
+	attrs    map[string]string
+	children []*element
+
+	// The character data of text nodes.
+	text string
+}
+
+// Name of the text nodes, which are children of the elements with their character data.
+const textNode = "#text"
+
+// SVG is a parsed SVG image.
+type SVG struct {
+	root *element
+
+	// The elements by id, for references.
+	ids map[string]*element
+
+	// The size of the viewport (user units).
+	width, height float64
+}
+
+// Parse parses the SVG image.
+func Parse(data []byte) (*SVG, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	svg := &SVG{ids: map[string]*element{}}
+	stack := []*element{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid SVG: %v", err)
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			elem := &element{name: t.Name.Local, attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				// Namespaced attributes (e.g. xlink:href) are used by their local name.
+				elem.attrs[attr.Name.Local] = attr.Value
+			}
+			if id, has := elem.attrs["id"]; has {
+				svg.ids[id] = elem
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, elem)
+			} else if svg.root == nil {
+				svg.root = elem
+			}
+			stack = append(stack, elem)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &element{name: textNode, text: string(t)})
+			}
+		}
+	}
+
+	if svg.root == nil || svg.root.name != "svg" {
+		common.Log.Debug("ERROR: Missing svg root element")
+		return nil, errors.New("Not an SVG image")
+	}
+
+	// The viewport size, from the viewBox if not specified.
+	viewBox := parseNumbers(svg.root.attrs["viewBox"])
+	svg.width, svg.height = defaultWidth, defaultHeight
+	if len(viewBox) == 4 {
+		svg.width, svg.height = viewBox[2], viewBox[3]
+	}
+	if w, ok := parseLength(svg.root.attrs["width"], svg.width, defaultFontSize); ok && w > 0 {
+		svg.width = w
+	}
+	if h, ok := parseLength(svg.root.attrs["height"], svg.height, defaultFontSize); ok && h > 0 {
+		svg.height = h
+	}
+	return svg, nil
+}
+
+// Width returns the width of the image in points.
+func (svg *SVG) Width() float64 {
+	return svg.width * pointsPerPixel
+}
+
+// Height returns the height of the image in points.
+func (svg *SVG) Height() float64 {
+	return svg.height * pointsPerPixel
+}
+
+// ToXObjectForm converts the image to a Form XObject, with its bounding box from (0, 0) to its size in points.
+func (svg *SVG) ToXObjectForm() (*model.XObjectForm, error) {
+	d := newDrawer(svg)
+	err := d.drawImage()
+	if err != nil {
+		return nil, err
+	}
+
+	xform := model.NewXObjectForm()
+	xform.BBox = core.MakeArrayFromFloats([]float64{0, 0, svg.Width(), svg.Height()})
+	xform.Resources = d.resources
+	encoder := core.NewFlateEncoder()
+	err = xform.SetContentStream(d.cc.Bytes(), encoder)
+	if err != nil {
+		return nil, err
+	}
+	xform.Filter = encoder
+	return xform, nil
+}
+
+// NewXObjectForm converts the SVG image to a Form XObject, with its bounding box from (0, 0) to its size in points.
+func NewXObjectForm(data []byte) (*model.XObjectForm, error) {
+	svg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return svg.ToXObjectForm()
+}
+
+// Returns the text of the element and its descendants (e.g. tspan), with the white space collapsed.
+func (elem *element) textContent() string {
+	var text func(elem *element) string
+	text = func(elem *element) string {
+		s := elem.text
+		for _, child := range elem.children {
+			s += text(child)
+		}
+		return s
+	}
+	return strings.Join(strings.Fields(text(elem)), " ")
+}
@@ -0,0 +1,226 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package svg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unidoc/pdf/core"
+	"github.com/unidoc/unidoc/pdf/model"
+	"github.com/unidoc/unidoc/pdf/render"
+)
+
+func TestParsePath(t *testing.T) {
+	// Implicit lines after moves, numbers without separators, relative and smooth commands.
+	p := parsePath("M10,20 30 40l.5.5-1e1 0H5v-5ZM0 0q10 0 10 10t10 10s10 0 10 10")
+	ops := ""
+	for _, seg := range p.segments {
+		ops += string(seg.op)
+	}
+	if ops != "mlllllhmccc" {
+		t.Fatalf("Wrong path segments %q", ops)
+	}
+	expected := [][]float64{
+		{10, 20}, {30, 40}, {30.5, 40.5}, {20.5, 40.5}, {5, 40.5}, {5, 35.5}, nil, {0, 0},
+	}
+	for i, points := range expected {
+		if !floatsEqual(p.segments[i].points, points) {
+			t.Errorf("Segment %d: %v, expected %v", i, p.segments[i].points, points)
+		}
+	}
+	// The quadratic curve as a cubic, and the reflected control point of the smooth quadratic (20, 20).
+	if !floatsEqual(p.segments[8].points, []float64{20.0 / 3, 0, 10, 10.0 / 3, 10, 10}) {
+		t.Errorf("Wrong quadratic curve %v", p.segments[8].points)
+	}
+	if !floatsEqual(p.segments[9].points, []float64{10, 10 + 20.0/3, 20 - 20.0/3, 20, 20, 20}) {
+		t.Errorf("Wrong smooth quadratic curve %v", p.segments[9].points)
+	}
+
+	// Parsing stops at errors.
+	p = parsePath("M0 0L10 10L20 x30 30")
+	if len(p.segments) != 2 {
+		t.Errorf("Wrong number of segments %d", len(p.segments))
+	}
+}
+
+func TestParseArc(t *testing.T) {
+	// Half circle of radius 10 from (0, 0) to (20, 0), with flags without separators.
+	p := parsePath("M0 0a10 10 0 0120 0")
+	if len(p.segments) != 3 {
+		t.Fatalf("Wrong number of segments %d", len(p.segments))
+	}
+	// Through the top (at y = -10, with the y axis downwards).
+	mid := p.segments[1].points
+	if math.Abs(mid[4]-10) > 1e-9 || math.Abs(mid[5]+10) > 1e-9 {
+		t.Errorf("Wrong arc midpoint (%f, %f)", mid[4], mid[5])
+	}
+	end := p.segments[2].points
+	if end[4] != 20 || end[5] != 0 {
+		t.Errorf("Wrong arc end point (%f, %f)", end[4], end[5])
+	}
+
+	// Radii too small are scaled up, to the same half circle.
+	p = parsePath("M0 0A1 1 0 0 1 20 0")
+	if mid := p.segments[1].points; math.Abs(mid[5]+10) > 1e-9 {
+		t.Errorf("Wrong scaled arc midpoint (%f, %f)", mid[4], mid[5])
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	colors := map[string]rgbColor{
+		"#f00":               {1, 0, 0},
+		"#0000FF":            {0, 0, 1},
+		"rgb(255, 0, 0)":     {1, 0, 0},
+		"rgb(0%, 100%, 50%)": {0, 1, 0.5},
+		"white":              {1, 1, 1},
+		"currentColor":       {0, 1, 0},
+	}
+	for s, expected := range colors {
+		c, ok := parseColor(s, rgbColor{0, 1, 0})
+		if !ok || c != expected {
+			t.Errorf("Color %q: %v (%t), expected %v", s, c, ok, expected)
+		}
+	}
+	if _, ok := parseColor("#12", rgbColor{}); ok {
+		t.Errorf("Invalid color parsed")
+	}
+
+	lengths := map[string]float64{
+		"10":     10,
+		"10px":   10,
+		"1in":    96,
+		"72pt":   96,
+		"2em":    24,
+		"1e1":    10,
+		"50%":    50,
+		"25.4mm": 96,
+	}
+	for s, expected := range lengths {
+		l, ok := parseLength(s, 100, 12)
+		if !ok || math.Abs(l-expected) > 1e-9 {
+			t.Errorf("Length %q: %f (%t), expected %f", s, l, ok, expected)
+		}
+	}
+
+	// Translation applied after the scaling, and rotation about a point.
+	m := parseTransform("translate(10, 20) scale(2)")
+	if x, y := m.Transform(1, 1); x != 12 || y != 22 {
+		t.Errorf("Wrong transform (%f, %f)", x, y)
+	}
+	m = parseTransform("rotate(90 10 10)")
+	if x, y := m.Transform(20, 10); math.Abs(x-10) > 1e-9 || math.Abs(y-20) > 1e-9 {
+		t.Errorf("Wrong rotation (%f, %f)", x, y)
+	}
+
+	// The style attribute overrides the presentation attributes, and opacity is inherited multiplied.
+	svg, err := Parse([]byte(`<svg><g opacity="0.5" fill="red"><rect fill="blue" style="fill: lime; opacity: .5"/></g></svg>`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	d := newDrawer(svg)
+	g := svg.root.children[0]
+	s := d.computeStyle(d.computeStyle(defaultStyle(), g), g.children[0])
+	if s.fill.color != (rgbColor{0, 1, 0}) || s.opacity != 0.25 {
+		t.Errorf("Wrong style fill %v opacity %f", s.fill, s.opacity)
+	}
+}
+
+// Rendering of an image with a viewBox, at 96 DPI (1 pixel per SVG pixel).
+const testSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"
+     width="100" height="50" viewBox="0 0 200 100">
+  <defs>
+    <linearGradient id="grad"><stop offset="0" stop-color="#00f"/><stop offset="1" stop-color="#fff"/></linearGradient>
+    <circle id="dot" r="10"/>
+  </defs>
+  <rect width="100" height="100" fill="red"/>
+  <g transform="translate(100 0)">
+    <rect x="10" y="10" width="80" height="80" rx="10" fill="url(#grad)" stroke="black" stroke-width="4"/>
+  </g>
+  <use xlink:href="#dot" x="50" y="50" fill="lime"/>
+  <text x="0" y="95" font-family="Helvetica" font-size="10">Logo &amp; text</text>
+</svg>`
+
+func TestXObjectForm(t *testing.T) {
+	svg, err := Parse([]byte(testSVG))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if svg.Width() != 75 || svg.Height() != 37.5 {
+		t.Fatalf("Wrong size %fx%f", svg.Width(), svg.Height())
+	}
+
+	xform, err := svg.ToXObjectForm()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	xform.ToPdfObject()
+	content, err := xform.GetContentStream()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, op := range []string{"/F1 10.000000 Tf", "(Logo & text) Tj", "0.000000 1.000000 0.000000 rg"} {
+		if !strings.Contains(string(content), op) {
+			t.Errorf("Missing %q in content:\n%s", op, content)
+		}
+	}
+	if _, has := xform.Resources.GetFontByName("F1"); !has {
+		t.Errorf("Missing font resource")
+	}
+
+	// Drawn on a page of the size of the image.
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 0, Lly: 0, Urx: 75, Ury: 37.5}
+	page.Resources = model.NewPdfPageResources()
+	page.Resources.SetXObjectFormByName("Logo", xform)
+	err = page.SetContentStreams([]string{"/Logo Do"}, core.NewRawEncoder())
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	opt := render.NewOptions()
+	opt.DPI = 96
+	opt.AntiAlias = false
+	img, err := render.RenderPage(page, opt)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 100, 50) {
+		t.Fatalf("Wrong image size %v", img.Bounds())
+	}
+
+	checkColor(t, img, 5, 5, color.NRGBA{0xff, 0, 0, 0xff})
+	checkColor(t, img, 25, 25, color.NRGBA{0, 0xff, 0, 0xff})
+	checkColor(t, img, 75, 25, color.NRGBA{0, 0, 0xff, 0xff})
+	// The stroke of the rounded rectangle, and its rounded corner.
+	checkColor(t, img, 75, 5, color.NRGBA{0, 0, 0, 0xff})
+	checkColor(t, img, 55, 5, color.NRGBA{0xff, 0xff, 0xff, 0xff})
+}
+
+// checkColor checks the color of a pixel.
+func checkColor(t *testing.T, img image.Image, x, y int, expected color.NRGBA) {
+	c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	if c != expected {
+		t.Errorf("Pixel %d,%d: %v, expected %v", x, y, c, expected)
+	}
+}
+
+// floatsEqual returns whether the numbers are equal, within rounding errors.
+func floatsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}